@@ -280,10 +280,13 @@ fi
 
 COORDINATOR_SVC="wonder-mesh-net"
 
-log_info "Creating join token..."
+WORKER_PODS=$(kubectl get pod -n ${NAMESPACE} -l app=worker -o jsonpath='{.items[*].metadata.name}')
+WORKER_COUNT=$(echo ${WORKER_PODS} | wc -w)
+
+log_info "Creating join token for ${WORKER_COUNT} workers..."
 JOIN_TOKEN_RESPONSE=$(kubectl exec -n ${NAMESPACE} ${DEPLOYER_POD} -- curl -s \
     -H "Authorization: Bearer ${ACCESS_TOKEN}" \
-    "http://${COORDINATOR_SVC}/coordinator/api/v1/join-token")
+    "http://${COORDINATOR_SVC}/coordinator/api/v1/join-token?max_uses=${WORKER_COUNT}")
 
 JOIN_TOKEN=$(echo "${JOIN_TOKEN_RESPONSE}" | sed -n 's/.*"token":"\([^"]*\)".*/\1/p')
 log_info "Join Token: ${JOIN_TOKEN}"
@@ -294,7 +297,6 @@ if [ -z "${JOIN_TOKEN}" ]; then
 fi
 log_info "Join token created."
 
-WORKER_PODS=$(kubectl get pod -n ${NAMESPACE} -l app=worker -o jsonpath='{.items[*].metadata.name}')
 WORKER1_POD=""
 WORKER1_IP=""
 
@@ -338,7 +340,7 @@ log_info "Creating API key for deployer..."
 API_KEY_RESPONSE=$(kubectl exec -n ${NAMESPACE} ${DEPLOYER_POD} -- curl -s -X POST \
     -H "Authorization: Bearer ${ACCESS_TOKEN}" \
     -H "Content-Type: application/json" \
-    -d '{"name": "deployer-key", "expires_in": "24h"}' \
+    -d '{"name": "deployer-key", "expires_in": "24h", "scopes": ["nodes:read", "deployer:join"]}' \
     "http://${COORDINATOR_SVC}/coordinator/api/v1/api-keys")
 
 API_KEY=$(echo "${API_KEY_RESPONSE}" | sed -n 's/.*"key":"\([^"]*\)".*/\1/p')
@@ -0,0 +1,239 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+// accessFlags holds the flags shared by the access subcommands.
+var accessFlags struct {
+	coordinatorURL string
+	token          string
+}
+
+// NewAccessCmd creates the access command for just-in-time access requests.
+func NewAccessCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "access",
+		Short: "Request and approve temporary access to services",
+		Long: `Request temporary access to a published service, and approve, deny, or
+revoke such requests. Approved access is removed automatically when it expires.
+
+Requesting and listing work with an API key. Approving, denying, and revoking
+require a user session token of the wonder net owner.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&accessFlags.coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.PersistentFlags().StringVar(&accessFlags.token, "token", "", "Session token or API key (env: WONDER_TOKEN or WONDER_API_KEY)")
+
+	cmd.AddCommand(newAccessRequestCmd())
+	cmd.AddCommand(newAccessListCmd())
+	for _, decision := range []string{"approve", "deny", "revoke"} {
+		cmd.AddCommand(newAccessDecisionCmd(decision))
+	}
+	return cmd
+}
+
+// newAccessRequestCmd creates the access request subcommand.
+func newAccessRequestCmd() *cobra.Command {
+	var input wondersdk.CreateAccessRequestInput
+	var serviceName string
+
+	cmd := &cobra.Command{
+		Use:   "request",
+		Short: "Request temporary access to a service",
+		Long: `Request temporary access to a service for a subject: "*" (all untagged nodes),
+"tag:<name>", or "node:<id>". The duration starts when the owner approves.
+
+Example:
+  wonder access request --service postgres --subject node:12 --requester alice \
+    --duration 4h --reason "debug slow queries"`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newAccessClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			input.ServiceID, err = resolveServiceID(ctx, client, serviceName)
+			if err != nil {
+				return err
+			}
+
+			req, err := client.RequestAccess(ctx, "", input)
+			if err != nil {
+				return fmt.Errorf("request access: %w", err)
+			}
+			return output.Print(os.Stdout, format, req, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Access request %s is pending approval\n", req.ID)
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&serviceName, "service", "", "Service name (required)")
+	cmd.Flags().StringVar(&input.Subject, "subject", "", "Subject to grant access to (required)")
+	cmd.Flags().StringVar(&input.Requester, "requester", "", "Who is asking, for the audit trail (required)")
+	cmd.Flags().StringVar(&input.Reason, "reason", "", "Why access is needed")
+	cmd.Flags().DurationVar(&input.Duration, "duration", time.Hour, "How long access lasts once approved")
+	_ = cmd.MarkFlagRequired("service")
+	_ = cmd.MarkFlagRequired("subject")
+	_ = cmd.MarkFlagRequired("requester")
+	return cmd
+}
+
+// newAccessListCmd creates the access list subcommand.
+func newAccessListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List access requests",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newAccessClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			requests, err := client.ListAccessRequests(ctx, "")
+			if err != nil {
+				return fmt.Errorf("list access requests: %w", err)
+			}
+			return output.Print(os.Stdout, format, requests, func(out io.Writer) error {
+				if len(requests) == 0 {
+					_, err := fmt.Fprintln(out, "No access requests")
+					return err
+				}
+
+				w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tSERVICE\tSUBJECT\tREQUESTER\tDURATION\tSTATUS\tEXPIRES")
+				for _, req := range requests {
+					expires := "-"
+					if req.ExpiresAt != nil {
+						expires = req.ExpiresAt.Local().Format(time.RFC3339)
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", req.ID, req.ServiceName, req.Subject, req.Requester, req.Duration, req.Status, expires)
+				}
+				return w.Flush()
+			})
+		},
+	}
+}
+
+// newAccessDecisionCmd creates the approve, deny, or revoke subcommand.
+func newAccessDecisionCmd(decision string) *cobra.Command {
+	return &cobra.Command{
+		Use:   decision + " <request-id>",
+		Short: strings.ToUpper(decision[:1]) + decision[1:] + " an access request",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newAccessClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			req, err := client.DecideAccessRequest(ctx, "", args[0], decision)
+			if err != nil {
+				return fmt.Errorf("%s access request: %w", decision, err)
+			}
+
+			return output.Print(os.Stdout, format, req, func(w io.Writer) error {
+				fmt.Fprintf(w, "Access request %s is %s\n", req.ID, req.Status)
+				if req.ExpiresAt != nil && req.Status == "approved" {
+					fmt.Fprintf(w, "Access expires at %s\n", req.ExpiresAt.Local().Format(time.RFC3339))
+				}
+				return nil
+			})
+		},
+	}
+}
+
+// newAccessClient creates an SDK client from the access command flags.
+func newAccessClient() (*wondersdk.Client, error) {
+	return newTokenClient(accessFlags.coordinatorURL, accessFlags.token)
+}
+
+// newTokenClient creates an SDK client for commands that accept either a
+// session token or an API key, falling back to WONDER_TOKEN, then
+// WONDER_API_KEY, then the active profile's API key, and then the login
+// saved by wonder auth login when token is empty. The active profile also
+// supplies the coordinator URL if it is empty, and the default wonder net.
+func newTokenClient(coordinatorURL, token string) (*wondersdk.Client, error) {
+	_, profile, err := activeProfile()
+	if err != nil {
+		return nil, err
+	}
+	if coordinatorURL == "" {
+		coordinatorURL = profile.CoordinatorURL
+	}
+	if coordinatorURL == "" {
+		return nil, fmt.Errorf("--coordinator-url or a profile with a coordinator URL is required")
+	}
+	if token == "" {
+		token = os.Getenv("WONDER_TOKEN")
+	}
+	if token == "" {
+		token = os.Getenv("WONDER_API_KEY")
+	}
+	if token == "" {
+		token = profile.Token
+	}
+	if token == "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		saved, err := savedAccessToken(ctx)
+		if errors.Is(err, errNotLoggedIn) {
+			return nil, fmt.Errorf("--token, WONDER_TOKEN, WONDER_API_KEY, or wonder auth login is required")
+		}
+		if err != nil {
+			return nil, err
+		}
+		token = saved
+	}
+	return wondersdk.NewClientWithOptions(strings.TrimRight(coordinatorURL, "/")+"/coordinator", token, wondersdk.ClientOptions{
+		Retry:   wondersdk.DefaultRetryPolicy,
+		Network: profile.Network,
+	}), nil
+}
+
+// resolveServiceID looks up a service by name.
+func resolveServiceID(ctx context.Context, client *wondersdk.Client, name string) (string, error) {
+	services, err := client.ListServices(ctx, "")
+	if err != nil {
+		return "", fmt.Errorf("list services: %w", err)
+	}
+	for _, svc := range services {
+		if svc.Name == name {
+			return svc.ID, nil
+		}
+	}
+	return "", fmt.Errorf("service %q not found", name)
+}
@@ -0,0 +1,663 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+// defaultAdminProfile is the profile used when neither --profile nor
+// WONDER_ADMIN_PROFILE names one.
+const defaultAdminProfile = "default"
+
+// adminFlags holds the flags shared by the admin subcommands.
+var adminFlags struct {
+	profile    string
+	url        string
+	adminToken string
+}
+
+// adminProfile is a coordinator and its admin token, saved by wonder admin
+// login.
+type adminProfile struct {
+	URL        string `json:"url"`
+	AdminToken string `json:"admin_token"`
+}
+
+// adminProfiles is the content of the admin credentials file.
+type adminProfiles struct {
+	Profiles map[string]adminProfile `json:"profiles"`
+}
+
+// adminWonderNet is a wonder net as listed by the admin API.
+type adminWonderNet struct {
+	ID               string `json:"id"`
+	OwnerID          string `json:"owner_id"`
+	DisplayName      string `json:"display_name"`
+	MeshType         string `json:"mesh_type"`
+	NodeNameTemplate string `json:"node_name_template,omitempty"`
+	PublicURL        string `json:"public_url,omitempty"`
+	CreatedAt        string `json:"created_at"`
+}
+
+// adminNode is a node with the wonder net it belongs to.
+type adminNode struct {
+	wondersdk.Node
+	WonderNetID string `json:"wonder_net_id"`
+	MeshType    string `json:"mesh_type,omitempty"`
+}
+
+// adminJoinToken is a join token issued through the admin API.
+type adminJoinToken struct {
+	Token        string   `json:"token"`
+	ExpiresIn    int      `json:"expires_in"`
+	JTI          string   `json:"jti"`
+	MaxUses      int      `json:"max_uses"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
+}
+
+// adminClient calls the coordinator's admin API.
+type adminClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewAdminCmd creates the admin command for operating a coordinator
+// through its admin API.
+func NewAdminCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "admin",
+		Short: "Administer the wonder nets of a coordinator through its admin API",
+		Long: `Administer the wonder nets, nodes, and join tokens of every tenant through
+the coordinator's admin API. The coordinator must run with --enable-admin-api.
+
+The coordinator URL and admin token come from --url and --admin-token, else
+from ADMIN_API_AUTH_TOKEN for the token, else from a profile saved with
+wonder admin login in ~/.wonder/admin.json. --profile (env:
+WONDER_ADMIN_PROFILE) selects the profile, "default" unless set, so that one
+machine can administer several coordinators:
+
+  wonder admin login --url https://mesh.example.com --admin-token ...
+  wonder admin login --profile staging --url https://staging.example.com --admin-token ...
+  wonder admin wonder-nets list --profile staging`,
+	}
+
+	cmd.PersistentFlags().StringVar(&adminFlags.profile, "profile", "", "Saved admin profile to use (env: WONDER_ADMIN_PROFILE, default \"default\")")
+	cmd.PersistentFlags().StringVar(&adminFlags.url, "url", "", "Coordinator URL, overriding the profile")
+	cmd.PersistentFlags().StringVar(&adminFlags.adminToken, "admin-token", "", "Admin API token, overriding the profile (env: ADMIN_API_AUTH_TOKEN)")
+
+	cmd.AddCommand(newAdminLoginCmd())
+	cmd.AddCommand(newAdminLogoutCmd())
+	cmd.AddCommand(newAdminWonderNetsCmd())
+	cmd.AddCommand(newAdminNodesCmd())
+	cmd.AddCommand(newAdminTokensCmd())
+	return cmd
+}
+
+// newAdminLoginCmd creates the admin login subcommand.
+func newAdminLoginCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "login",
+		Short: "Save a coordinator URL and admin token as a profile",
+		Long: `Check the admin token against the coordinator and save it with the URL as
+the profile named by --profile, replacing an earlier one. The profiles are
+saved to ~/.wonder/admin.json, readable only by the current user.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := adminProfileName()
+			token := adminFlags.adminToken
+			if token == "" {
+				token = os.Getenv("ADMIN_API_AUTH_TOKEN")
+			}
+			if adminFlags.url == "" || token == "" {
+				return fmt.Errorf("--url and --admin-token (or ADMIN_API_AUTH_TOKEN) are required")
+			}
+
+			client := newAdminClient(adminFlags.url, token)
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			if err := client.do(ctx, http.MethodGet, "/wonder-nets", url.Values{"limit": {"1"}}, nil, nil); err != nil {
+				return fmt.Errorf("check admin token: %w", err)
+			}
+
+			profiles, err := loadAdminProfiles()
+			if err != nil {
+				return err
+			}
+			profiles.Profiles[name] = adminProfile{URL: client.baseURL, AdminToken: token}
+			if err := saveAdminProfiles(profiles); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Saved admin profile %q for %s.\n", name, client.baseURL)
+			return nil
+		},
+	}
+}
+
+// newAdminLogoutCmd creates the admin logout subcommand.
+func newAdminLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "Remove a saved admin profile",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := adminProfileName()
+			profiles, err := loadAdminProfiles()
+			if err != nil {
+				return err
+			}
+			if _, ok := profiles.Profiles[name]; !ok {
+				return fmt.Errorf("no admin profile %q", name)
+			}
+			delete(profiles.Profiles, name)
+			if err := saveAdminProfiles(profiles); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Removed admin profile %q.\n", name)
+			return nil
+		},
+	}
+}
+
+// newAdminWonderNetsCmd creates the admin wonder-nets command group.
+func newAdminWonderNetsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "wonder-nets",
+		Short: "List, create, and delete wonder nets",
+	}
+	cmd.AddCommand(newAdminWonderNetsListCmd())
+	cmd.AddCommand(newAdminWonderNetsCreateCmd())
+	cmd.AddCommand(newAdminWonderNetsDeleteCmd())
+	return cmd
+}
+
+// newAdminWonderNetsListCmd creates the admin wonder-nets list subcommand.
+func newAdminWonderNetsListCmd() *cobra.Command {
+	var ownerID, meshType string
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List the wonder nets of all owners",
+		Long: `List every wonder net, newest first, following the admin API's pages.
+
+Example:
+  wonder admin wonder-nets list --owner 5b0c1f2e-... -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := resolveAdminClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			wonderNets, err := client.listWonderNets(ctx, ownerID, meshType)
+			if err != nil {
+				return fmt.Errorf("list wonder nets: %w", err)
+			}
+			return output.Print(os.Stdout, format, wonderNets, func(w io.Writer) error {
+				return printAdminWonderNets(w, wonderNets)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&ownerID, "owner", "", "Only list the wonder nets of this owner ID")
+	cmd.Flags().StringVar(&meshType, "mesh-type", "", "Only list wonder nets of this mesh type")
+	return cmd
+}
+
+// newAdminWonderNetsCreateCmd creates the admin wonder-nets create subcommand.
+func newAdminWonderNetsCreateCmd() *cobra.Command {
+	var ownerID, name string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a wonder net for an owner",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if ownerID == "" {
+				return fmt.Errorf("--owner is required")
+			}
+			client, err := resolveAdminClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			var wonderNet adminWonderNet
+			body := map[string]string{"owner_id": ownerID, "display_name": name}
+			if err := client.do(ctx, http.MethodPost, "/wonder-nets", nil, body, &wonderNet); err != nil {
+				return fmt.Errorf("create wonder net: %w", err)
+			}
+			return output.Print(os.Stdout, format, wonderNet, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Created wonder net %s (%s) for %s\n", wonderNet.ID, wonderNet.DisplayName, wonderNet.OwnerID)
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&ownerID, "owner", "", "Owner ID, the Keycloak subject of the owning user (required)")
+	cmd.Flags().StringVar(&name, "name", "", "Display name")
+	return cmd
+}
+
+// newAdminWonderNetsDeleteCmd creates the admin wonder-nets delete subcommand.
+func newAdminWonderNetsDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "delete <wonder-net-id>",
+		Short: "Delete a wonder net with its nodes, keys, and services",
+		Long: `Delete a wonder net as its owner would: its nodes are removed from the mesh
+and its API keys, join tokens, services, members, and other settings are
+deleted. An owner's default wonder net cannot be deleted.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client, err := resolveAdminClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			if err := client.do(ctx, http.MethodDelete, "/wonder-nets/"+url.PathEscape(args[0]), nil, nil, nil); err != nil {
+				return fmt.Errorf("delete wonder net: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Deleted wonder net %s.\n", args[0])
+			return nil
+		},
+	}
+}
+
+// newAdminNodesCmd creates the admin nodes command group.
+func newAdminNodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "List the nodes of all wonder nets",
+	}
+	cmd.AddCommand(newAdminNodesListCmd())
+	return cmd
+}
+
+// newAdminNodesListCmd creates the admin nodes list subcommand.
+func newAdminNodesListCmd() *cobra.Command {
+	var wonderNetID, meshType string
+	var onlineOnly bool
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List nodes across wonder nets",
+		Long: `List the nodes of every wonder net, or of one with --wonder-net. Wonder
+nets whose nodes could not be listed are reported on stderr.
+
+Example:
+  wonder admin nodes list --wonder-net 8f14e45f-... --online`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := resolveAdminClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+			defer cancel()
+
+			nodes, err := client.listNodes(ctx, wonderNetID, meshType, onlineOnly)
+			if err != nil {
+				return fmt.Errorf("list nodes: %w", err)
+			}
+			return output.Print(os.Stdout, format, nodes, func(w io.Writer) error {
+				return printAdminNodes(w, nodes)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&wonderNetID, "wonder-net", "", "Only list the nodes of this wonder net ID")
+	cmd.Flags().StringVar(&meshType, "mesh-type", "", "Only list the nodes of wonder nets of this mesh type")
+	cmd.Flags().BoolVar(&onlineOnly, "online", false, "Only list online nodes")
+	return cmd
+}
+
+// newAdminTokensCmd creates the admin tokens command group.
+func newAdminTokensCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "tokens",
+		Short: "Issue join tokens for any wonder net",
+	}
+	cmd.AddCommand(newAdminTokensIssueCmd())
+	return cmd
+}
+
+// newAdminTokensIssueCmd creates the admin tokens issue subcommand.
+func newAdminTokensIssueCmd() *cobra.Command {
+	var maxUses int
+	var allowedCIDRs []string
+
+	cmd := &cobra.Command{
+		Use:   "issue <wonder-net-id>",
+		Short: "Issue a join token for a wonder net",
+		Long: `Issue a join token for a wonder net, for wonder worker join. Only the token
+is printed, so that it can be captured by a script:
+
+  wonder worker join "$(wonder admin tokens issue 8f14e45f-...)"`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := resolveAdminClient()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			query := url.Values{"allowed_cidr": allowedCIDRs}
+			if maxUses > 0 {
+				query.Set("max_uses", strconv.Itoa(maxUses))
+			}
+			var token adminJoinToken
+			if err := client.do(ctx, http.MethodPost, "/wonder-nets/"+url.PathEscape(args[0])+"/join-token", query, nil, &token); err != nil {
+				return fmt.Errorf("issue join token: %w", err)
+			}
+			return output.Print(os.Stdout, format, token, func(w io.Writer) error {
+				_, err := fmt.Fprintln(w, token.Token)
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().IntVar(&maxUses, "max-uses", 0, "Number of workers that may join with the token (default: the coordinator's)")
+	cmd.Flags().StringArrayVar(&allowedCIDRs, "allowed-cidr", nil, "Only accept joins from this address range (repeatable)")
+	return cmd
+}
+
+// adminProfileName returns the profile selected with --profile or
+// WONDER_ADMIN_PROFILE.
+func adminProfileName() string {
+	if adminFlags.profile != "" {
+		return adminFlags.profile
+	}
+	if name := os.Getenv("WONDER_ADMIN_PROFILE"); name != "" {
+		return name
+	}
+	return defaultAdminProfile
+}
+
+// resolveAdminClient returns a client for the coordinator and admin token
+// given by the flags, the environment, and the selected profile, in that
+// order.
+func resolveAdminClient() (*adminClient, error) {
+	profiles, err := loadAdminProfiles()
+	if err != nil {
+		return nil, err
+	}
+	name := adminProfileName()
+	profile, ok := profiles.Profiles[name]
+	if !ok && name != defaultAdminProfile {
+		return nil, fmt.Errorf("no admin profile %q, run wonder admin login --profile %s", name, name)
+	}
+
+	baseURL := adminFlags.url
+	if baseURL == "" {
+		baseURL = profile.URL
+	}
+	token := adminFlags.adminToken
+	if token == "" {
+		token = os.Getenv("ADMIN_API_AUTH_TOKEN")
+	}
+	if token == "" {
+		token = profile.AdminToken
+	}
+	if baseURL == "" || token == "" {
+		return nil, fmt.Errorf("pass --url and --admin-token, or run wonder admin login")
+	}
+	return newAdminClient(baseURL, token), nil
+}
+
+// adminProfilesPath returns where the admin profiles are saved,
+// ~/.wonder/admin.json.
+func adminProfilesPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get admin profiles path: %w", err)
+	}
+	return filepath.Join(home, ".wonder", "admin.json"), nil
+}
+
+// loadAdminProfiles reads the saved admin profiles, returning none if the
+// file does not exist.
+func loadAdminProfiles() (*adminProfiles, error) {
+	profiles := &adminProfiles{Profiles: map[string]adminProfile{}}
+	path, err := adminProfilesPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return profiles, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read admin profiles: %w", err)
+	}
+	if err := json.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("parse admin profiles %s: %w", path, err)
+	}
+	if profiles.Profiles == nil {
+		profiles.Profiles = map[string]adminProfile{}
+	}
+	return profiles, nil
+}
+
+// saveAdminProfiles writes the admin profiles readable only by the current
+// user, as they hold admin tokens.
+func saveAdminProfiles(profiles *adminProfiles) error {
+	path, err := adminProfilesPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(profiles, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode admin profiles: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("save admin profiles: %w", err)
+	}
+	return nil
+}
+
+func newAdminClient(baseURL, token string) *adminClient {
+	return &adminClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      token,
+		httpClient: http.DefaultClient,
+	}
+}
+
+// do sends a request to the admin API endpoint path, encoding body as JSON
+// if it is not nil and decoding the response into out if it is not nil.
+func (c *adminClient) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	endpoint := c.baseURL + "/coordinator/admin/api/v1" + path
+	if len(query) > 0 {
+		endpoint += "?" + query.Encode()
+	}
+
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, endpoint, reqBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(resp.Body)
+		if resp.StatusCode == http.StatusNotFound && strings.HasPrefix(string(respBody), "404 page not found") {
+			return fmt.Errorf("admin API not found, is the coordinator running with --enable-admin-api?")
+		}
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// listWonderNets lists the wonder nets matching the filters, following the
+// pages of the listing.
+func (c *adminClient) listWonderNets(ctx context.Context, ownerID, meshType string) ([]adminWonderNet, error) {
+	query := url.Values{"limit": {"1000"}}
+	if ownerID != "" {
+		query.Set("owner_id", ownerID)
+	}
+	if meshType != "" {
+		query.Set("mesh_type", meshType)
+	}
+
+	var wonderNets []adminWonderNet
+	for {
+		var page struct {
+			WonderNets []adminWonderNet `json:"wonder_nets"`
+			NextCursor string           `json:"next_cursor"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/wonder-nets", query, nil, &page); err != nil {
+			return nil, err
+		}
+		wonderNets = append(wonderNets, page.WonderNets...)
+		if page.NextCursor == "" {
+			return wonderNets, nil
+		}
+		query.Set("cursor", page.NextCursor)
+	}
+}
+
+// listNodes lists the nodes of one wonder net if wonderNetID is set, else of
+// all wonder nets of meshType, or of all wonder nets if it is empty too.
+// Wonder nets whose nodes could not be listed are reported on stderr.
+func (c *adminClient) listNodes(ctx context.Context, wonderNetID, meshType string, onlineOnly bool) ([]adminNode, error) {
+	var nodes []adminNode
+	if wonderNetID != "" {
+		query := url.Values{}
+		if onlineOnly {
+			query.Set("online", "true")
+		}
+		var resp struct {
+			Nodes []wondersdk.Node `json:"nodes"`
+		}
+		if err := c.do(ctx, http.MethodGet, "/wonder-nets/"+url.PathEscape(wonderNetID)+"/nodes", query, nil, &resp); err != nil {
+			return nil, err
+		}
+		for _, node := range resp.Nodes {
+			nodes = append(nodes, adminNode{Node: node, WonderNetID: wonderNetID})
+		}
+		return nodes, nil
+	}
+
+	query := url.Values{}
+	if meshType != "" {
+		query.Set("mesh_type", meshType)
+	}
+	var resp struct {
+		Nodes  []adminNode `json:"nodes"`
+		Errors []string    `json:"errors"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/nodes", query, nil, &resp); err != nil {
+		return nil, err
+	}
+	for _, msg := range resp.Errors {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", msg)
+	}
+	for _, node := range resp.Nodes {
+		if onlineOnly && !node.Online {
+			continue
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes, nil
+}
+
+func printAdminWonderNets(out io.Writer, wonderNets []adminWonderNet) error {
+	if len(wonderNets) == 0 {
+		_, err := fmt.Fprintln(out, "No wonder nets")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tOWNER\tNAME\tMESH TYPE\tCREATED")
+	for _, wn := range wonderNets {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", wn.ID, wn.OwnerID, wn.DisplayName, wn.MeshType, wn.CreatedAt)
+	}
+	return w.Flush()
+}
+
+func printAdminNodes(out io.Writer, nodes []adminNode) error {
+	if len(nodes) == 0 {
+		_, err := fmt.Fprintln(out, "No nodes")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "WONDER NET\tID\tNAME\tADDRESSES\tONLINE\tLAST SEEN")
+	for _, node := range nodes {
+		lastSeen := node.LastSeen
+		if lastSeen == "" {
+			lastSeen = "-"
+		}
+		fmt.Fprintf(w, "%s\t%d\t%s\t%s\t%t\t%s\n", node.WonderNetID, node.ID, node.Name, strings.Join(node.Addresses, ","), node.Online, lastSeen)
+	}
+	return w.Flush()
+}
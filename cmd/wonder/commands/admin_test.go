@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestAdminClientListWonderNets(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/coordinator/admin/api/v1/wonder-nets" || r.Header.Get("Authorization") != "Bearer admin-secret" {
+			http.Error(w, "unexpected request", http.StatusUnauthorized)
+			return
+		}
+		if r.URL.Query().Get("owner_id") != "alice" {
+			http.Error(w, "missing owner_id", http.StatusBadRequest)
+			return
+		}
+		page := map[string]any{"wonder_nets": []adminWonderNet{{ID: "wn-1"}}, "next_cursor": "next"}
+		if r.URL.Query().Get("cursor") == "next" {
+			page = map[string]any{"wonder_nets": []adminWonderNet{{ID: "wn-2"}}}
+		}
+		_ = json.NewEncoder(w).Encode(page)
+	}))
+	defer server.Close()
+
+	wonderNets, err := newAdminClient(server.URL+"/", "admin-secret").listWonderNets(context.Background(), "alice", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(wonderNets) != 2 || wonderNets[0].ID != "wn-1" || wonderNets[1].ID != "wn-2" {
+		t.Errorf("listWonderNets() = %+v, want wn-1 and wn-2 from both pages", wonderNets)
+	}
+}
+
+func TestResolveAdminClient(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("ADMIN_API_AUTH_TOKEN", "")
+	t.Setenv("WONDER_ADMIN_PROFILE", "")
+	if err := saveAdminProfiles(&adminProfiles{Profiles: map[string]adminProfile{
+		"default": {URL: "https://mesh.example.com", AdminToken: "default-token"},
+		"staging": {URL: "https://staging.example.com", AdminToken: "staging-token"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		profile   string
+		url       string
+		token     string
+		env       string
+		wantURL   string
+		wantToken string
+		wantErr   bool
+	}{
+		{name: "default profile", wantURL: "https://mesh.example.com", wantToken: "default-token"},
+		{name: "named profile", profile: "staging", wantURL: "https://staging.example.com", wantToken: "staging-token"},
+		{name: "flags override", profile: "staging", url: "http://localhost:9080", token: "flag-token", wantURL: "http://localhost:9080", wantToken: "flag-token"},
+		{name: "env token", env: "env-token", wantURL: "https://mesh.example.com", wantToken: "env-token"},
+		{name: "unknown profile", profile: "prod", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			adminFlags.profile, adminFlags.url, adminFlags.adminToken = tt.profile, tt.url, tt.token
+			t.Cleanup(func() { adminFlags.profile, adminFlags.url, adminFlags.adminToken = "", "", "" })
+			t.Setenv("ADMIN_API_AUTH_TOKEN", tt.env)
+
+			client, err := resolveAdminClient()
+			if tt.wantErr {
+				if err == nil {
+					t.Error("resolveAdminClient() error = nil, want an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if client.baseURL != tt.wantURL || client.token != tt.wantToken {
+				t.Errorf("resolveAdminClient() = %s with %s, want %s with %s", client.baseURL, client.token, tt.wantURL, tt.wantToken)
+			}
+		})
+	}
+}
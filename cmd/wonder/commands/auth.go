@@ -0,0 +1,443 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+const (
+	deviceCodeGrantType = "urn:ietf:params:oauth:grant-type:device_code"
+	// defaultDevicePollInterval is used when Keycloak does not say how often
+	// to poll for the device token.
+	defaultDevicePollInterval = 5
+	// tokenExpiryLeeway refreshes a saved token this long before it expires.
+	tokenExpiryLeeway = 30 * time.Second
+)
+
+// devicePollUnit is the unit of the device flow poll interval, shortened by
+// tests.
+var devicePollUnit = time.Second
+
+var (
+	errNotLoggedIn         = errors.New("not logged in, run wonder auth login --device")
+	errDeviceAccessDenied  = errors.New("login was denied")
+	errDeviceCodeExpired   = errors.New("login code expired before it was confirmed")
+	errLoginRefreshExpired = errors.New("saved login expired, run wonder auth login --device again")
+)
+
+// authLogin is a Keycloak login saved by wonder auth login.
+type authLogin struct {
+	KeycloakURL  string    `json:"keycloak_url"`
+	Realm        string    `json:"realm"`
+	ClientID     string    `json:"client_id"`
+	AccessToken  string    `json:"access_token"`
+	RefreshToken string    `json:"refresh_token,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// deviceAuthorization is Keycloak's response to a device authorization
+// request (RFC 8628).
+type deviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// keycloakTokenResponse is a response of the Keycloak token endpoint,
+// either tokens or an OAuth error.
+type keycloakTokenResponse struct {
+	AccessToken      string `json:"access_token"`
+	RefreshToken     string `json:"refresh_token"`
+	ExpiresIn        int    `json:"expires_in"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// keycloakClient calls the OpenID Connect endpoints of a Keycloak realm as
+// a public client.
+type keycloakClient struct {
+	baseURL    string
+	realm      string
+	clientID   string
+	httpClient *http.Client
+}
+
+// NewAuthCmd creates the auth command for logging in to Keycloak.
+func NewAuthCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Log in to obtain a Keycloak token for the coordinator API",
+	}
+	cmd.AddCommand(newAuthLoginCmd())
+	cmd.AddCommand(newAuthTokenCmd())
+	cmd.AddCommand(newAuthLogoutCmd())
+	return cmd
+}
+
+// newAuthLoginCmd creates the auth login subcommand.
+func newAuthLoginCmd() *cobra.Command {
+	var device bool
+	var keycloakURL, realm, clientID string
+
+	cmd := &cobra.Command{
+		Use:   "login",
+		Short: "Log in to Keycloak with the device authorization grant",
+		Long: `Log in to Keycloak and save the token for the coordinator API commands.
+
+With --device, the OAuth 2.0 device authorization grant is used: the command
+prints a URL and a code to confirm in a browser on any machine, so it works on
+headless hosts and over SSH. The Keycloak client (--client-id) must be a public
+client with "OAuth 2.0 Device Authorization Grant" enabled, and the coordinator
+must accept it as an audience (KEYCLOAK_AUDIENCES).
+
+The login is saved to ~/.wonder/auth.json, or ~/.wonder/auth-<profile>.json
+with a profile (see wonder profile), and refreshed as needed. Commands that
+take --token use it when neither --token nor WONDER_TOKEN or WONDER_API_KEY
+is set. The profile's Keycloak URL is used when --keycloak-url and
+WONDER_KEYCLOAK_URL are not set.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if !device {
+				return fmt.Errorf("only --device login is supported")
+			}
+			if keycloakURL == "" {
+				keycloakURL = os.Getenv("WONDER_KEYCLOAK_URL")
+			}
+			if keycloakURL == "" {
+				_, profile, err := activeProfile()
+				if err != nil {
+					return err
+				}
+				keycloakURL = profile.KeycloakURL
+			}
+			if keycloakURL == "" {
+				return fmt.Errorf("--keycloak-url, WONDER_KEYCLOAK_URL, or a profile with a Keycloak URL is required")
+			}
+
+			client := newKeycloakClient(keycloakURL, realm, clientID)
+			ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+			defer cancel()
+
+			login, err := client.deviceLogin(ctx, os.Stderr)
+			if err != nil {
+				return err
+			}
+			if err := saveAuthLogin(login); err != nil {
+				return err
+			}
+			fmt.Fprintln(os.Stderr, "Logged in.")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&device, "device", false, "Use the device authorization grant, confirming the login in a browser on another machine")
+	cmd.Flags().StringVar(&keycloakURL, "keycloak-url", "", "Keycloak base URL, e.g. https://auth.example.com (env: WONDER_KEYCLOAK_URL, default: the profile's)")
+	cmd.Flags().StringVar(&realm, "realm", "wonder", "Keycloak realm")
+	cmd.Flags().StringVar(&clientID, "client-id", "wonder-cli", "Keycloak public client with the device authorization grant enabled")
+	return cmd
+}
+
+// newAuthTokenCmd creates the auth token subcommand.
+func newAuthTokenCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "token",
+		Short: "Print the saved access token, refreshing it if it expired",
+		Long: `Print the access token of the saved login, refreshing it first if it
+expired, e.g. for WONDER_TOKEN=$(wonder auth token) or curl.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			token, err := savedAccessToken(ctx)
+			if err != nil {
+				return err
+			}
+			fmt.Println(token)
+			return nil
+		},
+	}
+}
+
+// newAuthLogoutCmd creates the auth logout subcommand.
+func newAuthLogoutCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "logout",
+		Short: "End the Keycloak session and remove the saved login",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			login, err := loadAuthLogin()
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+			client := newKeycloakClient(login.KeycloakURL, login.Realm, login.ClientID)
+			if err := client.logout(ctx, login.RefreshToken); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: end Keycloak session: %v\n", err)
+			}
+
+			path, err := authLoginPath()
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil {
+				return fmt.Errorf("remove saved login: %w", err)
+			}
+			fmt.Fprintln(os.Stderr, "Logged out.")
+			return nil
+		},
+	}
+}
+
+func newKeycloakClient(baseURL, realm, clientID string) *keycloakClient {
+	return &keycloakClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		realm:      realm,
+		clientID:   clientID,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+func (c *keycloakClient) endpoint(path string) string {
+	return fmt.Sprintf("%s/realms/%s/protocol/openid-connect/%s", c.baseURL, url.PathEscape(c.realm), path)
+}
+
+// deviceLogin runs the device authorization grant, telling the user on w
+// where to confirm the login, and returns the login once confirmed.
+func (c *keycloakClient) deviceLogin(ctx context.Context, w io.Writer) (*authLogin, error) {
+	var auth deviceAuthorization
+	status, err := c.postForm(ctx, "auth/device", url.Values{"scope": {"openid profile email"}}, &auth)
+	if err != nil {
+		return nil, fmt.Errorf("start device login: %w", err)
+	}
+	if status != http.StatusOK || auth.DeviceCode == "" {
+		return nil, fmt.Errorf("start device login: status %d, is the device authorization grant enabled for client %s?", status, c.clientID)
+	}
+
+	fmt.Fprintf(w, "To log in, open %s and enter the code %s\n", auth.VerificationURI, auth.UserCode)
+	if auth.VerificationURIComplete != "" {
+		fmt.Fprintf(w, "or open %s\n", auth.VerificationURIComplete)
+	}
+	fmt.Fprintln(w, "Waiting for the login to be confirmed...")
+
+	interval := auth.Interval
+	if interval <= 0 {
+		interval = defaultDevicePollInterval
+	}
+	if auth.ExpiresIn > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, time.Duration(auth.ExpiresIn)*time.Second)
+		defer cancel()
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil, errDeviceCodeExpired
+		case <-time.After(time.Duration(interval) * devicePollUnit):
+		}
+
+		var token keycloakTokenResponse
+		status, err := c.postForm(ctx, "token", url.Values{
+			"grant_type":  {deviceCodeGrantType},
+			"device_code": {auth.DeviceCode},
+		}, &token)
+		if err != nil {
+			if ctx.Err() != nil {
+				return nil, errDeviceCodeExpired
+			}
+			return nil, fmt.Errorf("poll device login: %w", err)
+		}
+
+		switch {
+		case status == http.StatusOK && token.AccessToken != "":
+			return c.loginFromToken(&token, time.Now()), nil
+		case token.Error == "authorization_pending":
+		case token.Error == "slow_down":
+			interval += 5
+		case token.Error == "access_denied":
+			return nil, errDeviceAccessDenied
+		case token.Error == "expired_token":
+			return nil, errDeviceCodeExpired
+		default:
+			return nil, fmt.Errorf("poll device login: status %d: %s %s", status, token.Error, token.ErrorDescription)
+		}
+	}
+}
+
+// refresh exchanges the refresh token of login for new tokens.
+func (c *keycloakClient) refresh(ctx context.Context, login *authLogin) (*authLogin, error) {
+	if login.RefreshToken == "" {
+		return nil, errLoginRefreshExpired
+	}
+
+	var token keycloakTokenResponse
+	status, err := c.postForm(ctx, "token", url.Values{
+		"grant_type":    {"refresh_token"},
+		"refresh_token": {login.RefreshToken},
+	}, &token)
+	if err != nil {
+		return nil, fmt.Errorf("refresh login: %w", err)
+	}
+	if token.Error == "invalid_grant" {
+		return nil, errLoginRefreshExpired
+	}
+	if status != http.StatusOK || token.AccessToken == "" {
+		return nil, fmt.Errorf("refresh login: status %d: %s %s", status, token.Error, token.ErrorDescription)
+	}
+	return c.loginFromToken(&token, time.Now()), nil
+}
+
+// logout ends the Keycloak session of a refresh token.
+func (c *keycloakClient) logout(ctx context.Context, refreshToken string) error {
+	if refreshToken == "" {
+		return nil
+	}
+	status, err := c.postForm(ctx, "logout", url.Values{"refresh_token": {refreshToken}}, nil)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK && status != http.StatusNoContent {
+		return fmt.Errorf("status %d", status)
+	}
+	return nil
+}
+
+func (c *keycloakClient) loginFromToken(token *keycloakTokenResponse, now time.Time) *authLogin {
+	return &authLogin{
+		KeycloakURL:  c.baseURL,
+		Realm:        c.realm,
+		ClientID:     c.clientID,
+		AccessToken:  token.AccessToken,
+		RefreshToken: token.RefreshToken,
+		ExpiresAt:    now.Add(time.Duration(token.ExpiresIn) * time.Second),
+	}
+}
+
+// postForm posts form, with the client ID added, to a realm endpoint and
+// decodes the JSON response into out, whatever its status.
+func (c *keycloakClient) postForm(ctx context.Context, path string, form url.Values, out any) (int, error) {
+	form.Set("client_id", c.clientID)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.endpoint(path), strings.NewReader(form.Encode()))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, fmt.Errorf("read response: %w", err)
+	}
+	if out != nil && len(body) > 0 && strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(body, out); err != nil {
+			return 0, fmt.Errorf("parse response: %w", err)
+		}
+	}
+	return resp.StatusCode, nil
+}
+
+// savedAccessToken returns the access token of the saved login, refreshing
+// and saving it first if it is about to expire.
+func savedAccessToken(ctx context.Context) (string, error) {
+	login, err := loadAuthLogin()
+	if err != nil {
+		return "", err
+	}
+	if time.Now().Add(tokenExpiryLeeway).Before(login.ExpiresAt) {
+		return login.AccessToken, nil
+	}
+
+	client := newKeycloakClient(login.KeycloakURL, login.Realm, login.ClientID)
+	refreshed, err := client.refresh(ctx, login)
+	if err != nil {
+		return "", err
+	}
+	if err := saveAuthLogin(refreshed); err != nil {
+		return "", err
+	}
+	return refreshed.AccessToken, nil
+}
+
+// authLoginPath returns where the login of the active profile is saved.
+func authLoginPath() (string, error) {
+	name, _, err := activeProfile()
+	if err != nil {
+		return "", err
+	}
+	return profileAuthLoginPath(name)
+}
+
+// profileAuthLoginPath returns where the login of the named profile is
+// saved, ~/.wonder/auth-<name>.json, or ~/.wonder/auth.json without a
+// profile.
+func profileAuthLoginPath(name string) (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get saved login path: %w", err)
+	}
+	if name == "" {
+		return filepath.Join(home, ".wonder", "auth.json"), nil
+	}
+	return filepath.Join(home, ".wonder", "auth-"+name+".json"), nil
+}
+
+func loadAuthLogin() (*authLogin, error) {
+	path, err := authLoginPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil, errNotLoggedIn
+		}
+		return nil, fmt.Errorf("read saved login: %w", err)
+	}
+	var login authLogin
+	if err := json.Unmarshal(data, &login); err != nil {
+		return nil, fmt.Errorf("parse saved login %s: %w", path, err)
+	}
+	return &login, nil
+}
+
+// saveAuthLogin writes the login readable only by the current user, as it
+// holds a refresh token.
+func saveAuthLogin(login *authLogin) error {
+	path, err := authLoginPath()
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	data, err := json.MarshalIndent(login, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode login: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("save login: %w", err)
+	}
+	return nil
+}
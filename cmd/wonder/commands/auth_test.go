@@ -0,0 +1,168 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// fakeKeycloak serves the device authorization and token endpoints of the
+// realm "wonder". The device token is issued after pending polls, or the
+// login is answered with deviceError.
+func fakeKeycloak(t *testing.T, pending int, deviceError string) *httptest.Server {
+	t.Helper()
+	devicePollUnit = time.Millisecond
+	t.Cleanup(func() { devicePollUnit = time.Second })
+
+	writeJSON := func(w http.ResponseWriter, status int, body any) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_ = json.NewEncoder(w).Encode(body)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /realms/wonder/protocol/openid-connect/auth/device", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("client_id") != "wonder-cli" {
+			writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "unauthorized_client"})
+			return
+		}
+		writeJSON(w, http.StatusOK, deviceAuthorization{
+			DeviceCode:      "device-1",
+			UserCode:        "ABCD-EFGH",
+			VerificationURI: "https://auth.example.com/device",
+			ExpiresIn:       60,
+			Interval:        1,
+		})
+	})
+	mux.HandleFunc("POST /realms/wonder/protocol/openid-connect/token", func(w http.ResponseWriter, r *http.Request) {
+		switch r.FormValue("grant_type") {
+		case deviceCodeGrantType:
+			if pending > 0 {
+				pending--
+				writeJSON(w, http.StatusBadRequest, keycloakTokenResponse{Error: "authorization_pending"})
+				return
+			}
+			if deviceError != "" {
+				writeJSON(w, http.StatusBadRequest, keycloakTokenResponse{Error: deviceError})
+				return
+			}
+			writeJSON(w, http.StatusOK, keycloakTokenResponse{AccessToken: "access-1", RefreshToken: "refresh-1", ExpiresIn: 300})
+		case "refresh_token":
+			if r.FormValue("refresh_token") != "refresh-1" {
+				writeJSON(w, http.StatusBadRequest, keycloakTokenResponse{Error: "invalid_grant"})
+				return
+			}
+			writeJSON(w, http.StatusOK, keycloakTokenResponse{AccessToken: "access-2", RefreshToken: "refresh-2", ExpiresIn: 300})
+		default:
+			writeJSON(w, http.StatusBadRequest, keycloakTokenResponse{Error: "unsupported_grant_type"})
+		}
+	})
+
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestDeviceLogin(t *testing.T) {
+	tests := []struct {
+		name        string
+		pending     int
+		deviceError string
+		wantErr     error
+	}{
+		{name: "confirmed after polling", pending: 2},
+		{name: "denied", deviceError: "access_denied", wantErr: errDeviceAccessDenied},
+		{name: "code expired", pending: 1, deviceError: "expired_token", wantErr: errDeviceCodeExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := fakeKeycloak(t, tt.pending, tt.deviceError)
+			client := newKeycloakClient(server.URL+"/", "wonder", "wonder-cli")
+
+			login, err := client.deviceLogin(context.Background(), io.Discard)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("deviceLogin() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("deviceLogin() error = %v", err)
+			}
+			if login.AccessToken != "access-1" || login.RefreshToken != "refresh-1" {
+				t.Errorf("deviceLogin() tokens = %q, %q, want access-1, refresh-1", login.AccessToken, login.RefreshToken)
+			}
+			if login.KeycloakURL != server.URL || login.Realm != "wonder" || login.ClientID != "wonder-cli" {
+				t.Errorf("deviceLogin() = %+v, want the client's Keycloak URL, realm, and client ID", login)
+			}
+		})
+	}
+}
+
+func TestSavedAccessToken(t *testing.T) {
+	server := fakeKeycloak(t, 0, "")
+
+	tests := []struct {
+		name         string
+		expiresAt    time.Time
+		refreshToken string
+		want         string
+		wantErr      error
+	}{
+		{name: "valid token", expiresAt: time.Now().Add(time.Hour), refreshToken: "refresh-1", want: "access-1"},
+		{name: "expired token is refreshed", expiresAt: time.Now().Add(-time.Minute), refreshToken: "refresh-1", want: "access-2"},
+		{name: "expired refresh token", expiresAt: time.Now().Add(-time.Minute), refreshToken: "revoked", wantErr: errLoginRefreshExpired},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("HOME", t.TempDir())
+			err := saveAuthLogin(&authLogin{
+				KeycloakURL:  server.URL,
+				Realm:        "wonder",
+				ClientID:     "wonder-cli",
+				AccessToken:  "access-1",
+				RefreshToken: tt.refreshToken,
+				ExpiresAt:    tt.expiresAt,
+			})
+			if err != nil {
+				t.Fatalf("saveAuthLogin() error = %v", err)
+			}
+
+			got, err := savedAccessToken(context.Background())
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("savedAccessToken() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("savedAccessToken() error = %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("savedAccessToken() = %q, want %q", got, tt.want)
+			}
+
+			saved, err := loadAuthLogin()
+			if err != nil {
+				t.Fatalf("loadAuthLogin() error = %v", err)
+			}
+			if saved.AccessToken != tt.want {
+				t.Errorf("saved access token = %q, want %q", saved.AccessToken, tt.want)
+			}
+		})
+	}
+}
+
+func TestSavedAccessTokenNotLoggedIn(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	if _, err := savedAccessToken(context.Background()); !errors.Is(err, errNotLoggedIn) {
+		t.Errorf("savedAccessToken() error = %v, want %v", err, errNotLoggedIn)
+	}
+}
@@ -1,13 +1,23 @@
 package commands
 
 import (
+	"context"
+	"fmt"
 	"log/slog"
+	"net"
+	"net/mail"
 	"os"
+	"os/signal"
 	"strings"
+	"sync"
+	"syscall"
+	"time"
 
+	"github.com/fsnotify/fsnotify"
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator"
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
 )
 
 // NewCoordinatorCmd creates the coordinator subcommand that runs the
@@ -20,40 +30,169 @@ func NewCoordinatorCmd() *cobra.Command {
 		Run:   runCoordinator,
 	}
 
+	cmd.AddCommand(newCoordinatorProfileCmd())
+	cmd.AddCommand(newCoordinatorLogLevelCmd())
+	cmd.AddCommand(newCoordinatorInitCmd())
+
 	cmd.Flags().String("listen", ":9080", "Coordinator listen address")
 	cmd.Flags().String("public-url", "http://localhost:9080", "Public URL for callbacks")
 	cmd.Flags().String("db-driver", "sqlite", "Database driver (sqlite or postgres)")
 	cmd.Flags().String("db-dsn", "", "Database connection string")
+	cmd.Flags().Int("db-max-open-conns", 0, "Maximum open Postgres connections (0 uses the default of 25)")
+	cmd.Flags().Int("db-max-idle-conns", 0, "Maximum idle Postgres connections (0 uses the default of 5)")
+	cmd.Flags().Duration("db-conn-max-lifetime", 0, "Close database connections after this age (0 uses the driver default)")
+	cmd.Flags().Duration("db-conn-max-idle-time", 0, "Close database connections idle for this long (0 keeps them)")
+	cmd.Flags().String("mesh-backend", coordinator.MeshBackendHeadscale, "Control plane nodes join: headscale, or tailscale for an existing tailnet (API key from TAILSCALE_API_KEY)")
+	cmd.Flags().String("tailscale-tailnet", "-", "Tailnet name with --mesh-backend=tailscale, or - for the tailnet of the API key")
+	cmd.Flags().String("auth-mode", coordinator.AuthModeKeycloak, "Identity provider: keycloak, or embedded for built-in local, GitHub, and Google logins")
+	cmd.Flags().String("github-client-id", "", "GitHub OAuth app client ID for embedded auth logins (secret from GITHUB_CLIENT_SECRET)")
+	cmd.Flags().String("google-client-id", "", "Google OAuth client ID for embedded auth logins (secret from GOOGLE_CLIENT_SECRET)")
+	cmd.Flags().StringArray("auth-allowed-emails", nil, "Email addresses, or @domain for a whole domain, allowed to log in with GitHub or Google (repeatable)")
 	cmd.Flags().Bool("enable-admin-api", false, "Enable admin API endpoints")
+	cmd.Flags().StringArray("trusted-proxies", nil, "CIDRs of reverse proxies whose X-Forwarded-For gives the client address (repeatable)")
+	cmd.Flags().StringArray("cors-allowed-origins", nil, "Origins (scheme://host[:port], or *) of browser dashboards allowed to call /coordinator/api/ (repeatable; CORS disabled when empty)")
+	cmd.Flags().Bool("cors-allow-credentials", false, "Let allowed CORS origins send cookies and read responses to credentialed requests")
+	cmd.Flags().Duration("cors-max-age", 10*time.Minute, "How long browsers may cache a CORS preflight response")
+	cmd.Flags().Duration("request-timeout", coordinator.DefaultRequestTimeout, "Maximum time to handle an API request before answering 504 (0 disables)")
+	cmd.Flags().Duration("admin-request-timeout", coordinator.DefaultAdminRequestTimeout, "Maximum time to handle an admin API request before answering 504 (0 disables)")
 	cmd.Flags().StringArray("privileged-networks", nil, "Headscale usernames with hub-spoke access to all WonderNets (repeatable)")
 	cmd.Flags().Bool("use-tagged-acl", false, "Use constant-size tag-based ACL policy (recommended for many WonderNets)")
 	cmd.Flags().Bool("strict-privileged-tags", false, "Fail startup if any privileged node cannot be tagged (tagged-ACL mode only)")
+	cmd.Flags().String("mesh-proxy-listen", "", "Serve the SOCKS5/HTTP CONNECT mesh proxy for deployers on this address, e.g. :1080 (disabled when empty)")
+	cmd.Flags().String("mesh-proxy-upstream", "direct", "How the mesh proxy reaches nodes: direct, or socks5://host:port of a tailscaled in a privileged network")
+	cmd.Flags().String("dns-records-path", "", "Headscale dns.extra_records_path to publish wonder net DNS names to (DNS management disabled when empty)")
+	cmd.Flags().String("dns-base-domain", "", "Domain that wonder net DNS domains must be subdomains of, e.g. the Headscale base_domain")
+	cmd.Flags().Int("quota-max-nodes", 0, "Default maximum nodes per WonderNet (0 is unlimited)")
+	cmd.Flags().Int("quota-max-api-keys", 0, "Default maximum API keys per WonderNet (0 is unlimited)")
+	cmd.Flags().Int("quota-authkeys-per-hour", 0, "Default maximum mesh authkeys issued per WonderNet per hour (0 is unlimited)")
+	cmd.Flags().Int("exec-session-retention-days", coordinator.DefaultExecSessionRetentionDays, "Days to keep recorded exec sessions (0 keeps them until their WonderNet is deleted)")
+	cmd.Flags().Int("exec-session-max-output-bytes", coordinator.DefaultExecSessionMaxOutputBytes, "Bytes of stdout and of stderr kept per recorded exec session")
+	cmd.Flags().String("smtp-addr", "", "SMTP server (host:port) for email notifications; empty disables them")
+	cmd.Flags().String("smtp-username", "", "SMTP username, if the server requires authentication (password from SMTP_PASSWORD)")
+	cmd.Flags().String("smtp-from", "", "Sender address of email notifications")
+	cmd.Flags().Duration("node-offline-after", coordinator.DefaultNodeOfflineAfter, "How long a node is offline before node.offline webhook and notification events fire (0 fires them right away)")
+	cmd.Flags().Bool("webhook-allow-http", false, "Allow webhook URLs with plain http instead of requiring https")
+	cmd.Flags().String("log-level", "info", "Log level (debug, info, warn, or error)")
+	cmd.Flags().String("log-format", "text", "Log format (text or json)")
+	cmd.Flags().String("log-output", "stderr", "Log output: stderr, stdout, syslog, syslog://host:port, syslog+tcp://host:port, or a file path")
+	cmd.Flags().String("tls-autocert-cache-dir", "", "Serve HTTPS with Let's Encrypt certificates cached in this directory, for the public URL and WonderNet custom domains")
+	cmd.Flags().Bool("fixtures", false, "Populate the coordinator with synthetic WonderNets and in-memory nodes for development (never in production)")
+	cmd.Flags().Int("fixture-wonder-nets", coordinator.DefaultFixtureWonderNets, "Number of synthetic WonderNets created with --fixtures")
+	cmd.Flags().Int("fixture-nodes-per-wonder-net", coordinator.DefaultFixtureNodesPerWonderNet, "Number of synthetic nodes per WonderNet with --fixtures")
+	cmd.Flags().Bool("strict", isReleaseBuild(), "Refuse to start while production hardening checks fail (default true in release builds)")
 
 	_ = viper.BindPFlag("coordinator.listen", cmd.Flags().Lookup("listen"))
 	_ = viper.BindPFlag("coordinator.public_url", cmd.Flags().Lookup("public-url"))
 	_ = viper.BindPFlag("coordinator.database_driver", cmd.Flags().Lookup("db-driver"))
 	_ = viper.BindPFlag("coordinator.database_dsn", cmd.Flags().Lookup("db-dsn"))
+	_ = viper.BindPFlag("coordinator.database_max_open_conns", cmd.Flags().Lookup("db-max-open-conns"))
+	_ = viper.BindPFlag("coordinator.database_max_idle_conns", cmd.Flags().Lookup("db-max-idle-conns"))
+	_ = viper.BindPFlag("coordinator.database_conn_max_lifetime", cmd.Flags().Lookup("db-conn-max-lifetime"))
+	_ = viper.BindPFlag("coordinator.database_conn_max_idle_time", cmd.Flags().Lookup("db-conn-max-idle-time"))
+	_ = viper.BindPFlag("coordinator.mesh_backend", cmd.Flags().Lookup("mesh-backend"))
+	_ = viper.BindPFlag("coordinator.tailscale_tailnet", cmd.Flags().Lookup("tailscale-tailnet"))
+	_ = viper.BindPFlag("coordinator.auth_mode", cmd.Flags().Lookup("auth-mode"))
+	_ = viper.BindPFlag("coordinator.github_client_id", cmd.Flags().Lookup("github-client-id"))
+	_ = viper.BindPFlag("coordinator.google_client_id", cmd.Flags().Lookup("google-client-id"))
+	_ = viper.BindPFlag("coordinator.auth_allowed_emails", cmd.Flags().Lookup("auth-allowed-emails"))
 	_ = viper.BindPFlag("coordinator.enable_admin_api", cmd.Flags().Lookup("enable-admin-api"))
+	_ = viper.BindPFlag("coordinator.trusted_proxies", cmd.Flags().Lookup("trusted-proxies"))
+	_ = viper.BindPFlag("coordinator.cors_allowed_origins", cmd.Flags().Lookup("cors-allowed-origins"))
+	_ = viper.BindPFlag("coordinator.cors_allow_credentials", cmd.Flags().Lookup("cors-allow-credentials"))
+	_ = viper.BindPFlag("coordinator.cors_max_age", cmd.Flags().Lookup("cors-max-age"))
+	_ = viper.BindPFlag("coordinator.request_timeout", cmd.Flags().Lookup("request-timeout"))
+	_ = viper.BindPFlag("coordinator.admin_request_timeout", cmd.Flags().Lookup("admin-request-timeout"))
 	_ = viper.BindPFlag("coordinator.privileged_networks", cmd.Flags().Lookup("privileged-networks"))
 	_ = viper.BindPFlag("coordinator.use_tagged_acl", cmd.Flags().Lookup("use-tagged-acl"))
 	_ = viper.BindPFlag("coordinator.strict_privileged_tags", cmd.Flags().Lookup("strict-privileged-tags"))
+	_ = viper.BindPFlag("coordinator.mesh_proxy_listen", cmd.Flags().Lookup("mesh-proxy-listen"))
+	_ = viper.BindPFlag("coordinator.mesh_proxy_upstream", cmd.Flags().Lookup("mesh-proxy-upstream"))
+	_ = viper.BindPFlag("coordinator.dns_records_path", cmd.Flags().Lookup("dns-records-path"))
+	_ = viper.BindPFlag("coordinator.dns_base_domain", cmd.Flags().Lookup("dns-base-domain"))
+	_ = viper.BindPFlag("coordinator.quota_max_nodes", cmd.Flags().Lookup("quota-max-nodes"))
+	_ = viper.BindPFlag("coordinator.quota_max_api_keys", cmd.Flags().Lookup("quota-max-api-keys"))
+	_ = viper.BindPFlag("coordinator.quota_authkeys_per_hour", cmd.Flags().Lookup("quota-authkeys-per-hour"))
+	_ = viper.BindPFlag("coordinator.exec_session_retention_days", cmd.Flags().Lookup("exec-session-retention-days"))
+	_ = viper.BindPFlag("coordinator.exec_session_max_output_bytes", cmd.Flags().Lookup("exec-session-max-output-bytes"))
+	_ = viper.BindPFlag("coordinator.smtp_addr", cmd.Flags().Lookup("smtp-addr"))
+	_ = viper.BindPFlag("coordinator.smtp_username", cmd.Flags().Lookup("smtp-username"))
+	_ = viper.BindPFlag("coordinator.smtp_from", cmd.Flags().Lookup("smtp-from"))
+	_ = viper.BindPFlag("coordinator.node_offline_after", cmd.Flags().Lookup("node-offline-after"))
+	_ = viper.BindPFlag("coordinator.webhook_allow_http", cmd.Flags().Lookup("webhook-allow-http"))
+	_ = viper.BindPFlag("coordinator.log_level", cmd.Flags().Lookup("log-level"))
+	_ = viper.BindPFlag("coordinator.log_format", cmd.Flags().Lookup("log-format"))
+	_ = viper.BindPFlag("coordinator.log_output", cmd.Flags().Lookup("log-output"))
+	_ = viper.BindPFlag("coordinator.tls_autocert_cache_dir", cmd.Flags().Lookup("tls-autocert-cache-dir"))
+	_ = viper.BindPFlag("coordinator.fixtures", cmd.Flags().Lookup("fixtures"))
+	_ = viper.BindPFlag("coordinator.fixture_wonder_nets", cmd.Flags().Lookup("fixture-wonder-nets"))
+	_ = viper.BindPFlag("coordinator.fixture_nodes_per_wonder_net", cmd.Flags().Lookup("fixture-nodes-per-wonder-net"))
+	_ = viper.BindPFlag("coordinator.strict", cmd.Flags().Lookup("strict"))
 
 	_ = viper.BindEnv("coordinator.listen", "LISTEN")
 	_ = viper.BindEnv("coordinator.public_url", "PUBLIC_URL")
 	_ = viper.BindEnv("coordinator.jwt_secret", "JWT_SECRET")
 	_ = viper.BindEnv("coordinator.database_driver", "DB_DRIVER")
 	_ = viper.BindEnv("coordinator.database_dsn", "DB_DSN")
+	_ = viper.BindEnv("coordinator.database_max_open_conns", "DB_MAX_OPEN_CONNS")
+	_ = viper.BindEnv("coordinator.database_max_idle_conns", "DB_MAX_IDLE_CONNS")
+	_ = viper.BindEnv("coordinator.database_conn_max_lifetime", "DB_CONN_MAX_LIFETIME")
+	_ = viper.BindEnv("coordinator.database_conn_max_idle_time", "DB_CONN_MAX_IDLE_TIME")
 	_ = viper.BindEnv("coordinator.headscale_url", "HEADSCALE_URL")
 	_ = viper.BindEnv("coordinator.headscale_unix_socket", "HEADSCALE_UNIX_SOCKET")
+	_ = viper.BindEnv("coordinator.mesh_backend", "MESH_BACKEND")
+	_ = viper.BindEnv("coordinator.tailscale_api_key", "TAILSCALE_API_KEY")
+	_ = viper.BindEnv("coordinator.tailscale_tailnet", "TAILSCALE_TAILNET")
+	_ = viper.BindEnv("coordinator.tailscale_api_url", "TAILSCALE_API_URL")
+	_ = viper.BindEnv("coordinator.auth_mode", "AUTH_MODE")
+	_ = viper.BindEnv("coordinator.github_client_id", "GITHUB_CLIENT_ID")
+	_ = viper.BindEnv("coordinator.github_client_secret", "GITHUB_CLIENT_SECRET")
+	_ = viper.BindEnv("coordinator.google_client_id", "GOOGLE_CLIENT_ID")
+	_ = viper.BindEnv("coordinator.google_client_secret", "GOOGLE_CLIENT_SECRET")
+	_ = viper.BindEnv("coordinator.auth_allowed_emails", "AUTH_ALLOWED_EMAILS")
 	_ = viper.BindEnv("coordinator.keycloak_url", "KEYCLOAK_URL")
 	_ = viper.BindEnv("coordinator.keycloak_realm", "KEYCLOAK_REALM")
 	_ = viper.BindEnv("coordinator.keycloak_client_id", "KEYCLOAK_CLIENT_ID")
 	_ = viper.BindEnv("coordinator.keycloak_client_secret", "KEYCLOAK_CLIENT_SECRET")
+	_ = viper.BindEnv("coordinator.keycloak_audiences", "KEYCLOAK_AUDIENCES")
+	_ = viper.BindEnv("coordinator.keycloak_trusted_issuers", "KEYCLOAK_TRUSTED_ISSUERS")
 	_ = viper.BindEnv("coordinator.enable_admin_api", "ENABLE_ADMIN_API")
 	_ = viper.BindEnv("coordinator.admin_api_auth_token", "ADMIN_API_AUTH_TOKEN")
+	_ = viper.BindEnv("coordinator.trusted_proxies", "TRUSTED_PROXIES")
+	_ = viper.BindEnv("coordinator.cors_allowed_origins", "CORS_ALLOWED_ORIGINS")
+	_ = viper.BindEnv("coordinator.cors_allow_credentials", "CORS_ALLOW_CREDENTIALS")
+	_ = viper.BindEnv("coordinator.cors_max_age", "CORS_MAX_AGE")
+	_ = viper.BindEnv("coordinator.request_timeout", "REQUEST_TIMEOUT")
+	_ = viper.BindEnv("coordinator.admin_request_timeout", "ADMIN_REQUEST_TIMEOUT")
 	_ = viper.BindEnv("coordinator.privileged_networks", "PRIVILEGED_NETWORKS")
 	_ = viper.BindEnv("coordinator.use_tagged_acl", "USE_TAGGED_ACL")
 	_ = viper.BindEnv("coordinator.strict_privileged_tags", "STRICT_PRIVILEGED_TAGS")
+	_ = viper.BindEnv("coordinator.mesh_proxy_listen", "MESH_PROXY_LISTEN")
+	_ = viper.BindEnv("coordinator.mesh_proxy_upstream", "MESH_PROXY_UPSTREAM")
+	_ = viper.BindEnv("coordinator.dns_records_path", "DNS_RECORDS_PATH")
+	_ = viper.BindEnv("coordinator.dns_base_domain", "DNS_BASE_DOMAIN")
+	_ = viper.BindEnv("coordinator.quota_max_nodes", "QUOTA_MAX_NODES")
+	_ = viper.BindEnv("coordinator.quota_max_api_keys", "QUOTA_MAX_API_KEYS")
+	_ = viper.BindEnv("coordinator.quota_authkeys_per_hour", "QUOTA_AUTHKEYS_PER_HOUR")
+	_ = viper.BindEnv("coordinator.exec_session_retention_days", "EXEC_SESSION_RETENTION_DAYS")
+	_ = viper.BindEnv("coordinator.exec_session_max_output_bytes", "EXEC_SESSION_MAX_OUTPUT_BYTES")
+	_ = viper.BindEnv("coordinator.smtp_addr", "SMTP_ADDR")
+	_ = viper.BindEnv("coordinator.smtp_username", "SMTP_USERNAME")
+	_ = viper.BindEnv("coordinator.smtp_password", "SMTP_PASSWORD")
+	_ = viper.BindEnv("coordinator.smtp_from", "SMTP_FROM")
+	_ = viper.BindEnv("coordinator.node_offline_after", "NODE_OFFLINE_AFTER")
+	_ = viper.BindEnv("coordinator.webhook_allow_http", "WEBHOOK_ALLOW_HTTP")
+	_ = viper.BindEnv("coordinator.log_level", "LOG_LEVEL")
+	_ = viper.BindEnv("coordinator.log_format", "LOG_FORMAT")
+	_ = viper.BindEnv("coordinator.log_output", "LOG_OUTPUT")
+	_ = viper.BindEnv("coordinator.log_max_size_mb", "LOG_MAX_SIZE_MB")
+	_ = viper.BindEnv("coordinator.log_max_backups", "LOG_MAX_BACKUPS")
+	_ = viper.BindEnv("coordinator.log_max_age_days", "LOG_MAX_AGE_DAYS")
+	_ = viper.BindEnv("coordinator.tls_autocert_cache_dir", "TLS_AUTOCERT_CACHE_DIR")
+	_ = viper.BindEnv("coordinator.tls_autocert_email", "TLS_AUTOCERT_EMAIL")
+	_ = viper.BindEnv("coordinator.fixtures", "FIXTURES")
+	_ = viper.BindEnv("coordinator.fixture_wonder_nets", "FIXTURE_WONDER_NETS")
+	_ = viper.BindEnv("coordinator.fixture_nodes_per_wonder_net", "FIXTURE_NODES_PER_WONDER_NET")
+	_ = viper.BindEnv("coordinator.strict", "STRICT")
 
 	return cmd
 }
@@ -61,31 +200,14 @@ func NewCoordinatorCmd() *cobra.Command {
 // runCoordinator initializes and starts the coordinator server
 // using configuration from flags and environment variables.
 func runCoordinator(cmd *cobra.Command, args []string) {
-	var cfg coordinator.Config
-	cfg.Listen = viper.GetString("coordinator.listen")
-	cfg.PublicURL = viper.GetString("coordinator.public_url")
-	cfg.JWTSecret = viper.GetString("coordinator.jwt_secret")
-	cfg.DatabaseDriver = viper.GetString("coordinator.database_driver")
-	cfg.DatabaseDSN = viper.GetString("coordinator.database_dsn")
-	cfg.HeadscaleURL = viper.GetString("coordinator.headscale_url")
-	cfg.HeadscaleUnixSocket = viper.GetString("coordinator.headscale_unix_socket")
-	cfg.KeycloakURL = viper.GetString("coordinator.keycloak_url")
-	cfg.KeycloakRealm = viper.GetString("coordinator.keycloak_realm")
-	cfg.KeycloakClientID = viper.GetString("coordinator.keycloak_client_id")
-	cfg.KeycloakClientSecret = viper.GetString("coordinator.keycloak_client_secret")
-	cfg.EnableAdminAPI = viper.GetBool("coordinator.enable_admin_api")
-	cfg.AdminAPIAuthToken = viper.GetString("coordinator.admin_api_auth_token")
+	cfg := loadCoordinatorConfig()
 
-	cfg.PrivilegedNetworks = parseStringSlice(viper.Get("coordinator.privileged_networks"))
-	cfg.UseTaggedACL = viper.GetBool("coordinator.use_tagged_acl")
-	cfg.StrictPrivilegedTags = viper.GetBool("coordinator.strict_privileged_tags")
-
-	if cfg.HeadscaleURL == "" {
-		cfg.HeadscaleURL = coordinator.DefaultHeadscaleURL
-	}
-	if cfg.HeadscaleUnixSocket == "" {
-		cfg.HeadscaleUnixSocket = coordinator.DefaultHeadscaleUnixSocket
+	logCloser, err := logging.Setup(cfg.LoggingConfig(), "wonder-coordinator")
+	if err != nil {
+		slog.Error("set up logging", "error", err)
+		os.Exit(1)
 	}
+	defer func() { _ = logCloser.Close() }()
 
 	if cfg.JWTSecret == "" {
 		slog.Error("JWT_SECRET environment variable is required")
@@ -93,13 +215,45 @@ func runCoordinator(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
-	if cfg.KeycloakURL == "" {
-		slog.Error("KEYCLOAK_URL environment variable is required")
+	switch cfg.AuthMode {
+	case coordinator.AuthModeKeycloak:
+		if cfg.KeycloakURL == "" {
+			slog.Error("KEYCLOAK_URL environment variable is required")
+			os.Exit(1)
+		}
+
+		if cfg.KeycloakClientSecret == "" {
+			slog.Error("KEYCLOAK_CLIENT_SECRET environment variable is required")
+			os.Exit(1)
+		}
+	case coordinator.AuthModeEmbedded:
+		if cfg.GitHubClientID != "" && cfg.GitHubClientSecret == "" {
+			slog.Error("GITHUB_CLIENT_SECRET environment variable is required with a GitHub client ID")
+			os.Exit(1)
+		}
+		if cfg.GoogleClientID != "" && cfg.GoogleClientSecret == "" {
+			slog.Error("GOOGLE_CLIENT_SECRET environment variable is required with a Google client ID")
+			os.Exit(1)
+		}
+		if (cfg.GitHubClientID != "" || cfg.GoogleClientID != "") && len(cfg.AuthAllowedEmails) == 0 {
+			slog.Error("--auth-allowed-emails is required with GitHub or Google logins")
+			os.Exit(1)
+		}
+	default:
+		slog.Error("unknown auth mode", "auth_mode", cfg.AuthMode, "want", []string{coordinator.AuthModeKeycloak, coordinator.AuthModeEmbedded})
 		os.Exit(1)
 	}
 
-	if cfg.KeycloakClientSecret == "" {
-		slog.Error("KEYCLOAK_CLIENT_SECRET environment variable is required")
+	switch cfg.MeshBackend {
+	case coordinator.MeshBackendHeadscale:
+	case coordinator.MeshBackendTailscale:
+		if cfg.TailscaleAPIKey == "" {
+			slog.Error("TAILSCALE_API_KEY environment variable is required with the tailscale mesh backend")
+			os.Exit(1)
+		}
+		slog.Info("using the Tailscale control plane", "tailnet", cfg.TailscaleTailnet)
+	default:
+		slog.Error("unknown mesh backend", "mesh_backend", cfg.MeshBackend, "want", []string{coordinator.MeshBackendHeadscale, coordinator.MeshBackendTailscale})
 		os.Exit(1)
 	}
 
@@ -115,6 +269,33 @@ func runCoordinator(cmd *cobra.Command, args []string) {
 		slog.Info("admin API enabled")
 	}
 
+	if cfg.QuotaMaxNodes < 0 || cfg.QuotaMaxAPIKeys < 0 || cfg.QuotaAuthKeysPerHour < 0 {
+		slog.Error("quotas must not be negative")
+		os.Exit(1)
+	}
+
+	if cfg.SMTPAddr != "" {
+		if _, _, err := net.SplitHostPort(cfg.SMTPAddr); err != nil {
+			slog.Error("SMTP_ADDR must be host:port", "error", err)
+			os.Exit(1)
+		}
+		if _, err := mail.ParseAddress(cfg.SMTPFrom); err != nil {
+			slog.Error("SMTP_FROM must be an email address when SMTP_ADDR is set", "error", err)
+			os.Exit(1)
+		}
+		slog.Info("email notifications enabled", "smtp_addr", cfg.SMTPAddr)
+	}
+
+	if cfg.Strict {
+		if violations := coordinator.StrictViolations(&cfg); len(violations) > 0 {
+			for _, v := range violations {
+				slog.Error("strict mode check failed", "violation", v)
+			}
+			slog.Error("refusing to start in strict mode; fix the settings above or pass --strict=false", "violations", len(violations))
+			os.Exit(1)
+		}
+	}
+
 	if len(cfg.PrivilegedNetworks) > 0 {
 		slog.Info("privileged networks configured", "networks", cfg.PrivilegedNetworks, "use_tagged_acl", cfg.UseTaggedACL)
 	}
@@ -125,17 +306,153 @@ func runCoordinator(cmd *cobra.Command, args []string) {
 		os.Exit(1)
 	}
 
+	watchCoordinatorConfig(server)
+
 	if err := server.Run(); err != nil {
 		slog.Error("shutdown error", "error", err)
 	}
 }
 
+// loadCoordinatorConfig reads the coordinator configuration from viper and
+// fills in defaults.
+func loadCoordinatorConfig() coordinator.Config {
+	var cfg coordinator.Config
+	cfg.Listen = viper.GetString("coordinator.listen")
+	cfg.PublicURL = viper.GetString("coordinator.public_url")
+	cfg.JWTSecret = viper.GetString("coordinator.jwt_secret")
+	cfg.DatabaseDriver = viper.GetString("coordinator.database_driver")
+	cfg.DatabaseDSN = viper.GetString("coordinator.database_dsn")
+	cfg.DatabaseMaxOpenConns = viper.GetInt("coordinator.database_max_open_conns")
+	cfg.DatabaseMaxIdleConns = viper.GetInt("coordinator.database_max_idle_conns")
+	cfg.DatabaseConnMaxLifetime = viper.GetDuration("coordinator.database_conn_max_lifetime")
+	cfg.DatabaseConnMaxIdleTime = viper.GetDuration("coordinator.database_conn_max_idle_time")
+	cfg.MeshBackend = viper.GetString("coordinator.mesh_backend")
+	cfg.TailscaleAPIKey = viper.GetString("coordinator.tailscale_api_key")
+	cfg.TailscaleTailnet = viper.GetString("coordinator.tailscale_tailnet")
+	cfg.TailscaleAPIURL = viper.GetString("coordinator.tailscale_api_url")
+	cfg.HeadscaleURL = viper.GetString("coordinator.headscale_url")
+	cfg.HeadscaleUnixSocket = viper.GetString("coordinator.headscale_unix_socket")
+	cfg.AuthMode = viper.GetString("coordinator.auth_mode")
+	cfg.GitHubClientID = viper.GetString("coordinator.github_client_id")
+	cfg.GitHubClientSecret = viper.GetString("coordinator.github_client_secret")
+	cfg.GoogleClientID = viper.GetString("coordinator.google_client_id")
+	cfg.GoogleClientSecret = viper.GetString("coordinator.google_client_secret")
+	cfg.AuthAllowedEmails = parseStringSlice(viper.Get("coordinator.auth_allowed_emails"))
+	cfg.KeycloakURL = viper.GetString("coordinator.keycloak_url")
+	cfg.KeycloakRealm = viper.GetString("coordinator.keycloak_realm")
+	cfg.KeycloakClientID = viper.GetString("coordinator.keycloak_client_id")
+	cfg.KeycloakClientSecret = viper.GetString("coordinator.keycloak_client_secret")
+	cfg.KeycloakAudiences = parseStringSlice(viper.Get("coordinator.keycloak_audiences"))
+	cfg.KeycloakTrustedIssuers = parseStringSlice(viper.Get("coordinator.keycloak_trusted_issuers"))
+	cfg.EnableAdminAPI = viper.GetBool("coordinator.enable_admin_api")
+	cfg.AdminAPIAuthToken = viper.GetString("coordinator.admin_api_auth_token")
+	cfg.TrustedProxies = parseStringSlice(viper.Get("coordinator.trusted_proxies"))
+	cfg.CORSAllowedOrigins = parseStringSlice(viper.Get("coordinator.cors_allowed_origins"))
+	cfg.CORSAllowCredentials = viper.GetBool("coordinator.cors_allow_credentials")
+	cfg.CORSMaxAge = viper.GetDuration("coordinator.cors_max_age")
+	cfg.RequestTimeout = viper.GetDuration("coordinator.request_timeout")
+	cfg.AdminRequestTimeout = viper.GetDuration("coordinator.admin_request_timeout")
+
+	cfg.PrivilegedNetworks = parseStringSlice(viper.Get("coordinator.privileged_networks"))
+	cfg.UseTaggedACL = viper.GetBool("coordinator.use_tagged_acl")
+	cfg.StrictPrivilegedTags = viper.GetBool("coordinator.strict_privileged_tags")
+
+	cfg.MeshProxyListen = viper.GetString("coordinator.mesh_proxy_listen")
+	cfg.MeshProxyUpstream = viper.GetString("coordinator.mesh_proxy_upstream")
+	cfg.DNSRecordsPath = viper.GetString("coordinator.dns_records_path")
+	cfg.DNSBaseDomain = viper.GetString("coordinator.dns_base_domain")
+
+	cfg.QuotaMaxNodes = viper.GetInt("coordinator.quota_max_nodes")
+	cfg.QuotaMaxAPIKeys = viper.GetInt("coordinator.quota_max_api_keys")
+	cfg.QuotaAuthKeysPerHour = viper.GetInt("coordinator.quota_authkeys_per_hour")
+	cfg.ExecSessionRetentionDays = viper.GetInt("coordinator.exec_session_retention_days")
+	cfg.ExecSessionMaxOutputBytes = viper.GetInt("coordinator.exec_session_max_output_bytes")
+	cfg.SMTPAddr = viper.GetString("coordinator.smtp_addr")
+	cfg.SMTPUsername = viper.GetString("coordinator.smtp_username")
+	cfg.SMTPPassword = viper.GetString("coordinator.smtp_password")
+	cfg.SMTPFrom = viper.GetString("coordinator.smtp_from")
+	cfg.NodeOfflineAfter = viper.GetDuration("coordinator.node_offline_after")
+	cfg.WebhookAllowHTTP = viper.GetBool("coordinator.webhook_allow_http")
+
+	cfg.LogLevel = viper.GetString("coordinator.log_level")
+	cfg.LogFormat = viper.GetString("coordinator.log_format")
+	cfg.LogOutput = viper.GetString("coordinator.log_output")
+	cfg.LogMaxSizeMB = viper.GetInt("coordinator.log_max_size_mb")
+	cfg.LogMaxBackups = viper.GetInt("coordinator.log_max_backups")
+	cfg.LogMaxAgeDays = viper.GetInt("coordinator.log_max_age_days")
+
+	cfg.TLSAutocertCacheDir = viper.GetString("coordinator.tls_autocert_cache_dir")
+	cfg.TLSAutocertEmail = viper.GetString("coordinator.tls_autocert_email")
+
+	cfg.Fixtures = viper.GetBool("coordinator.fixtures")
+	cfg.FixtureWonderNets = viper.GetInt("coordinator.fixture_wonder_nets")
+	cfg.FixtureNodesPerWonderNet = viper.GetInt("coordinator.fixture_nodes_per_wonder_net")
+
+	cfg.Strict = viper.GetBool("coordinator.strict")
+
+	if cfg.HeadscaleURL == "" {
+		cfg.HeadscaleURL = coordinator.DefaultHeadscaleURL
+	}
+	if cfg.HeadscaleUnixSocket == "" {
+		cfg.HeadscaleUnixSocket = coordinator.DefaultHeadscaleUnixSocket
+	}
+	return cfg
+}
+
+// watchCoordinatorConfig reloads the coordinator configuration on SIGHUP
+// and, when a config file is in use, whenever the file changes. Only the
+// reloadable settings take effect; see coordinator.Server.Reload.
+func watchCoordinatorConfig(server *coordinator.Server) {
+	var mu sync.Mutex
+	reload := func(trigger string, reread bool) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		if reread && viper.ConfigFileUsed() != "" {
+			if err := viper.ReadInConfig(); err != nil {
+				slog.Error("read config file", "file", viper.ConfigFileUsed(), "error", err)
+				return
+			}
+		}
+
+		cfg := loadCoordinatorConfig()
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		if err := server.Reload(ctx, &cfg); err != nil {
+			slog.Error("reload coordinator config", "trigger", trigger, "error", err)
+		}
+	}
+
+	if file := viper.ConfigFileUsed(); file != "" {
+		viper.OnConfigChange(func(fsnotify.Event) {
+			reload("file change", false)
+		})
+		viper.WatchConfig()
+		slog.Info("watching config file for changes", "file", file)
+	}
+
+	sigCh := make(chan os.Signal, 1)
+	signal.Notify(sigCh, syscall.SIGHUP)
+	go func() {
+		for range sigCh {
+			reload("SIGHUP", true)
+		}
+	}()
+}
+
 // parseStringSlice converts a viper value to []string.
-// Handles []string from cobra StringArray flags and comma-separated string from env vars.
+// Handles []string from cobra StringArray flags, lists from the config file,
+// and comma-separated string from env vars.
 func parseStringSlice(val any) []string {
 	switch v := val.(type) {
 	case []string:
 		return v
+	case []any:
+		result := make([]string, 0, len(v))
+		for _, n := range v {
+			result = append(result, fmt.Sprint(n))
+		}
+		return result
 	case string:
 		var result []string
 		for _, n := range strings.Split(v, ",") {
@@ -0,0 +1,634 @@
+package commands
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+	"golang.org/x/term"
+)
+
+const (
+	defaultKeycloakRealm    = "wonder"
+	defaultKeycloakClientID = "wonder-mesh-net"
+	defaultCLIClientID      = "wonder-cli"
+)
+
+// errKeycloakNotFound is returned by the Keycloak admin client for 404
+// responses.
+var errKeycloakNotFound = errors.New("not found")
+
+// coordinatorInitFlags holds the command-line flags for coordinator init.
+var coordinatorInitFlags struct {
+	publicURL             string
+	listen                string
+	dbDriver              string
+	dbDSN                 string
+	keycloakURL           string
+	keycloakRealm         string
+	keycloakClientID      string
+	keycloakClientSecret  string
+	keycloakAdminUser     string
+	keycloakAdminPassword string
+	cliClientID           string
+	enableAdminAPI        bool
+	nonInteractive        bool
+	force                 bool
+	skipChecks            bool
+}
+
+// coordinatorFileConfig is the config file written by coordinator init, in
+// the layout the coordinator reads with --config.
+type coordinatorFileConfig struct {
+	Coordinator coordinatorFileSettings `yaml:"coordinator"`
+}
+
+type coordinatorFileSettings struct {
+	Listen               string   `yaml:"listen"`
+	PublicURL            string   `yaml:"public_url"`
+	JWTSecret            string   `yaml:"jwt_secret"`
+	DatabaseDriver       string   `yaml:"database_driver"`
+	DatabaseDSN          string   `yaml:"database_dsn,omitempty"`
+	KeycloakURL          string   `yaml:"keycloak_url"`
+	KeycloakRealm        string   `yaml:"keycloak_realm"`
+	KeycloakClientID     string   `yaml:"keycloak_client_id"`
+	KeycloakClientSecret string   `yaml:"keycloak_client_secret"`
+	KeycloakAudiences    []string `yaml:"keycloak_audiences,omitempty"`
+	EnableAdminAPI       bool     `yaml:"enable_admin_api,omitempty"`
+	AdminAPIAuthToken    string   `yaml:"admin_api_auth_token,omitempty"`
+}
+
+// newCoordinatorInitCmd creates the init subcommand that writes a first
+// coordinator config file.
+func newCoordinatorInitCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "init",
+		Short: "Create a coordinator config file and set up Keycloak",
+		Long: `Create a working coordinator config file without editing YAML by hand.
+
+init asks for the public URL and the Keycloak server, generates the JWT secret
+(and the admin API token with --enable-admin-api), checks that Keycloak and the
+public URL can be reached, and writes the config to --config (default
+~/.wonder/config.yaml) with mode 0600. Start the coordinator with:
+
+  wonder coordinator --config <file>
+
+Given a Keycloak admin account (--keycloak-admin-user, KEYCLOAK_ADMIN and
+KEYCLOAK_ADMIN_PASSWORD), init creates the realm if it does not exist, the
+confidential coordinator client with its redirect URI and the service account
+role for looking up invited users, and the public wonder-cli client used by
+wonder auth login --device. Existing clients are kept and their secret is
+read. Without an admin account, give the secret of an existing client with
+--keycloak-client-secret or KEYCLOAK_CLIENT_SECRET.
+
+Values not given as flags are asked for on a terminal; with --non-interactive
+a missing value is an error instead.`,
+		Args: cobra.NoArgs,
+		RunE: runCoordinatorInit,
+	}
+
+	cmd.Flags().StringVar(&coordinatorInitFlags.publicURL, "public-url", "", "Public URL of the coordinator, e.g. https://mesh.example.com")
+	cmd.Flags().StringVar(&coordinatorInitFlags.listen, "listen", ":9080", "Coordinator listen address")
+	cmd.Flags().StringVar(&coordinatorInitFlags.dbDriver, "db-driver", "sqlite", "Database driver (sqlite or postgres)")
+	cmd.Flags().StringVar(&coordinatorInitFlags.dbDSN, "db-dsn", "", "Database connection string (required for postgres)")
+	cmd.Flags().StringVar(&coordinatorInitFlags.keycloakURL, "keycloak-url", "", "Keycloak base URL, e.g. https://auth.example.com")
+	cmd.Flags().StringVar(&coordinatorInitFlags.keycloakRealm, "keycloak-realm", defaultKeycloakRealm, "Keycloak realm")
+	cmd.Flags().StringVar(&coordinatorInitFlags.keycloakClientID, "keycloak-client-id", defaultKeycloakClientID, "Keycloak client of the coordinator")
+	cmd.Flags().StringVar(&coordinatorInitFlags.keycloakClientSecret, "keycloak-client-secret", "", "Secret of an existing coordinator client (env: KEYCLOAK_CLIENT_SECRET)")
+	cmd.Flags().StringVar(&coordinatorInitFlags.keycloakAdminUser, "keycloak-admin-user", "", "Keycloak admin user to set up the realm and clients with (env: KEYCLOAK_ADMIN)")
+	cmd.Flags().StringVar(&coordinatorInitFlags.cliClientID, "cli-client-id", defaultCLIClientID, "Public Keycloak client for wonder auth login --device")
+	cmd.Flags().BoolVar(&coordinatorInitFlags.enableAdminAPI, "enable-admin-api", false, "Enable the admin API with a generated token")
+	cmd.Flags().BoolVar(&coordinatorInitFlags.nonInteractive, "non-interactive", false, "Never prompt for input; fail instead")
+	cmd.Flags().BoolVar(&coordinatorInitFlags.force, "force", false, "Overwrite an existing config file")
+	cmd.Flags().BoolVar(&coordinatorInitFlags.skipChecks, "skip-checks", false, "Do not check that Keycloak and the public URL can be reached")
+	return cmd
+}
+
+func runCoordinatorInit(cmd *cobra.Command, args []string) error {
+	flags := &coordinatorInitFlags
+	if flags.keycloakClientSecret == "" {
+		flags.keycloakClientSecret = os.Getenv("KEYCLOAK_CLIENT_SECRET")
+	}
+	if flags.keycloakAdminUser == "" {
+		flags.keycloakAdminUser = os.Getenv("KEYCLOAK_ADMIN")
+	}
+	flags.keycloakAdminPassword = os.Getenv("KEYCLOAK_ADMIN_PASSWORD")
+
+	file, err := coordinatorInitFile(cmd)
+	if err != nil {
+		return err
+	}
+	if _, err := os.Stat(file); err == nil && !flags.force {
+		return fmt.Errorf("%s already exists, pass --force to overwrite it", file)
+	}
+
+	p := newPrompter(!flags.nonInteractive && term.IsTerminal(int(os.Stdin.Fd())))
+	if err := p.ask(&flags.publicURL, "Public URL of the coordinator", "http://localhost:9080"); err != nil {
+		return err
+	}
+	publicURL, err := normalizePublicURL(flags.publicURL)
+	if err != nil {
+		return err
+	}
+	if err := p.ask(&flags.keycloakURL, "Keycloak URL", ""); err != nil {
+		return err
+	}
+	keycloakURL, err := normalizePublicURL(flags.keycloakURL)
+	if err != nil {
+		return fmt.Errorf("keycloak URL: %w", err)
+	}
+	if flags.dbDriver != "sqlite" && flags.dbDriver != "postgres" {
+		return fmt.Errorf("--db-driver must be sqlite or postgres")
+	}
+	if flags.dbDriver == "postgres" {
+		if err := p.ask(&flags.dbDSN, "Postgres connection string", ""); err != nil {
+			return err
+		}
+	}
+
+	if flags.keycloakClientSecret == "" && flags.keycloakAdminUser == "" {
+		if err := p.askOptional(&flags.keycloakAdminUser, "Keycloak admin user to create the realm and clients (empty to use an existing client)"); err != nil {
+			return err
+		}
+	}
+	if flags.keycloakAdminUser != "" {
+		if err := p.askSecret(&flags.keycloakAdminPassword, "Keycloak admin password"); err != nil {
+			return err
+		}
+	} else if err := p.askSecret(&flags.keycloakClientSecret, "Secret of Keycloak client "+flags.keycloakClientID); err != nil {
+		return err
+	}
+
+	settings := coordinatorFileSettings{
+		Listen:               flags.listen,
+		PublicURL:            publicURL,
+		DatabaseDriver:       flags.dbDriver,
+		DatabaseDSN:          flags.dbDSN,
+		KeycloakURL:          keycloakURL,
+		KeycloakRealm:        flags.keycloakRealm,
+		KeycloakClientID:     flags.keycloakClientID,
+		KeycloakClientSecret: flags.keycloakClientSecret,
+		KeycloakAudiences:    []string{flags.cliClientID},
+		EnableAdminAPI:       flags.enableAdminAPI,
+	}
+	if settings.JWTSecret, err = randomHex(32); err != nil {
+		return err
+	}
+	if flags.enableAdminAPI {
+		if settings.AdminAPIAuthToken, err = randomHex(32); err != nil {
+			return err
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 2*time.Minute)
+	defer cancel()
+
+	if flags.keycloakAdminUser != "" {
+		kc, err := newKeycloakAdminClient(ctx, keycloakURL, flags.keycloakAdminUser, flags.keycloakAdminPassword)
+		if err != nil {
+			return err
+		}
+		secret, err := kc.provision(ctx, flags.keycloakRealm, flags.keycloakClientID, flags.cliClientID, publicURL)
+		if err != nil {
+			return err
+		}
+		settings.KeycloakClientSecret = secret
+	}
+
+	if !flags.skipChecks {
+		if err := checkKeycloakRealm(ctx, keycloakURL, flags.keycloakRealm); err != nil {
+			return fmt.Errorf("%w (pass --skip-checks to write the config anyway)", err)
+		}
+		fmt.Fprintf(os.Stderr, "Keycloak realm %s: ok\n", flags.keycloakRealm)
+		for _, warning := range checkPublicURL(ctx, publicURL) {
+			fmt.Fprintln(os.Stderr, "Warning:", warning)
+		}
+	}
+
+	if err := writeCoordinatorConfig(file, coordinatorFileConfig{Coordinator: settings}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %s\n", file)
+	if settings.AdminAPIAuthToken != "" {
+		fmt.Fprintln(os.Stderr, "The admin API token is in the config file as admin_api_auth_token; use it with wonder admin login.")
+	}
+	fmt.Fprintln(os.Stderr, "Start the coordinator with:")
+	fmt.Fprintf(os.Stderr, "  wonder coordinator --config %s\n", file)
+	return nil
+}
+
+// coordinatorInitFile returns the file named by the global --config flag,
+// or ~/.wonder/config.yaml, the file the coordinator reads by default.
+func coordinatorInitFile(cmd *cobra.Command) (string, error) {
+	if flag := cmd.Flag("config"); flag != nil && flag.Value.String() != "" {
+		return flag.Value.String(), nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".wonder", "config.yaml"), nil
+}
+
+// writeCoordinatorConfig writes config to file with mode 0600, as it holds
+// secrets.
+func writeCoordinatorConfig(file string, config coordinatorFileConfig) error {
+	data, err := yaml.Marshal(config)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(file), 0700); err != nil {
+		return fmt.Errorf("create config directory: %w", err)
+	}
+	if err := os.WriteFile(file, data, 0600); err != nil {
+		return fmt.Errorf("write config: %w", err)
+	}
+	return os.Chmod(file, 0600)
+}
+
+// normalizePublicURL checks that rawURL is an absolute http or https URL
+// and strips a trailing slash.
+func normalizePublicURL(rawURL string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("%q is not an http or https URL", rawURL)
+	}
+	return strings.TrimSuffix(u.String(), "/"), nil
+}
+
+// checkKeycloakRealm checks that the realm's OpenID configuration can be
+// fetched.
+func checkKeycloakRealm(ctx context.Context, keycloakURL, realm string) error {
+	endpoint := fmt.Sprintf("%s/realms/%s/.well-known/openid-configuration", keycloakURL, url.PathEscape(realm))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := (&http.Client{Timeout: 10 * time.Second}).Do(req)
+	if err != nil {
+		return fmt.Errorf("reach Keycloak: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("keycloak realm %s: status %d", realm, resp.StatusCode)
+	}
+	return nil
+}
+
+// checkPublicURL returns warnings about a public URL that nodes and
+// browsers may not reach: a host that does not resolve, a port nothing
+// listens on yet, or plain HTTP, which strict mode refuses on other hosts
+// than loopback.
+func checkPublicURL(ctx context.Context, publicURL string) []string {
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return []string{err.Error()}
+	}
+
+	var warnings []string
+	host := u.Hostname()
+	if u.Scheme == "http" && !isLoopbackHost(host) {
+		warnings = append(warnings, fmt.Sprintf("%s is plain HTTP; join tokens and logins would cross the network unencrypted, and strict mode refuses to start", publicURL))
+	}
+
+	addrs, err := net.DefaultResolver.LookupHost(ctx, host)
+	if err != nil {
+		return append(warnings, fmt.Sprintf("%s does not resolve: %v", host, err))
+	}
+	port := u.Port()
+	if port == "" {
+		port = map[string]string{"http": "80", "https": "443"}[u.Scheme]
+	}
+	conn, err := (&net.Dialer{Timeout: 5 * time.Second}).DialContext(ctx, "tcp", net.JoinHostPort(host, port))
+	if err != nil {
+		return append(warnings, fmt.Sprintf("nothing answers on %s (%s) yet: %v; make sure it reaches the coordinator's listen address once it runs", net.JoinHostPort(host, port), strings.Join(addrs, ", "), err))
+	}
+	_ = conn.Close()
+	return warnings
+}
+
+func isLoopbackHost(host string) bool {
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// randomHex returns n random bytes, hex encoded.
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("generate random secret: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// prompter asks for missing values on a terminal.
+type prompter struct {
+	interactive bool
+	reader      *bufio.Reader
+}
+
+func newPrompter(interactive bool) *prompter {
+	return &prompter{interactive: interactive, reader: bufio.NewReader(os.Stdin)}
+}
+
+// ask asks for *value unless it is set, offering def. Without a terminal,
+// the default is used, and a missing value without one is an error.
+func (p *prompter) ask(value *string, label, def string) error {
+	if *value != "" {
+		return nil
+	}
+	if !p.interactive {
+		if def == "" {
+			return fmt.Errorf("%s is required", strings.ToLower(label))
+		}
+		*value = def
+		return nil
+	}
+	for *value == "" {
+		if def != "" {
+			fmt.Fprintf(os.Stderr, "%s [%s]: ", label, def)
+		} else {
+			fmt.Fprintf(os.Stderr, "%s: ", label)
+		}
+		line, err := p.reader.ReadString('\n')
+		if err != nil && line == "" {
+			return fmt.Errorf("read %s: %w", strings.ToLower(label), err)
+		}
+		*value = strings.TrimSpace(line)
+		if *value == "" {
+			*value = def
+		}
+	}
+	return nil
+}
+
+// askOptional asks for *value unless it is set or there is no terminal; an
+// empty answer leaves it empty.
+func (p *prompter) askOptional(value *string, label string) error {
+	if *value != "" || !p.interactive {
+		return nil
+	}
+	fmt.Fprintf(os.Stderr, "%s: ", label)
+	line, err := p.reader.ReadString('\n')
+	if err != nil && line == "" {
+		return fmt.Errorf("read %s: %w", strings.ToLower(label), err)
+	}
+	*value = strings.TrimSpace(line)
+	return nil
+}
+
+// askSecret asks for *value without echoing it, unless it is set.
+func (p *prompter) askSecret(value *string, label string) error {
+	if *value != "" {
+		return nil
+	}
+	if !p.interactive {
+		return fmt.Errorf("%s is required", strings.ToLower(label))
+	}
+	for *value == "" {
+		fmt.Fprintf(os.Stderr, "%s: ", label)
+		secret, err := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if err != nil {
+			return fmt.Errorf("read %s: %w", strings.ToLower(label), err)
+		}
+		*value = strings.TrimSpace(string(secret))
+	}
+	return nil
+}
+
+// keycloakAdminClient calls the Keycloak admin REST API as an admin of the
+// master realm.
+type keycloakAdminClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// newKeycloakAdminClient logs in to the master realm with the admin-cli
+// client.
+func newKeycloakAdminClient(ctx context.Context, keycloakURL, user, password string) (*keycloakAdminClient, error) {
+	httpClient := &http.Client{Timeout: 30 * time.Second}
+	form := url.Values{
+		"grant_type": {"password"},
+		"client_id":  {"admin-cli"},
+		"username":   {user},
+		"password":   {password},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, keycloakURL+"/realms/master/protocol/openid-connect/token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("reach Keycloak: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return nil, fmt.Errorf("log in to Keycloak as %s: status %d: %s", user, resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&token); err != nil {
+		return nil, fmt.Errorf("decode Keycloak token: %w", err)
+	}
+	return &keycloakAdminClient{baseURL: keycloakURL, token: token.AccessToken, httpClient: httpClient}, nil
+}
+
+// provision creates what the coordinator needs in realm, keeping what
+// already exists, and returns the secret of the coordinator client.
+func (c *keycloakAdminClient) provision(ctx context.Context, realm, clientID, cliClientID, publicURL string) (string, error) {
+	realmPath := "/admin/realms/" + url.PathEscape(realm)
+	err := c.do(ctx, http.MethodGet, realmPath, nil, nil)
+	switch {
+	case errors.Is(err, errKeycloakNotFound):
+		if err := c.do(ctx, http.MethodPost, "/admin/realms", map[string]any{"realm": realm, "enabled": true}, nil); err != nil {
+			return "", fmt.Errorf("create realm %s: %w", realm, err)
+		}
+		fmt.Fprintf(os.Stderr, "Created Keycloak realm %s\n", realm)
+	case err != nil:
+		return "", fmt.Errorf("get realm %s: %w", realm, err)
+	}
+
+	id, created, err := c.ensureClient(ctx, realmPath, map[string]any{
+		"clientId":                  clientID,
+		"name":                      "Wonder Mesh Net",
+		"enabled":                   true,
+		"publicClient":              false,
+		"standardFlowEnabled":       true,
+		"directAccessGrantsEnabled": false,
+		"serviceAccountsEnabled":    true,
+		"protocol":                  "openid-connect",
+		"redirectUris":              []string{publicURL + "/coordinator/oidc/callback"},
+		"webOrigins":                []string{publicURL},
+		"attributes": map[string]string{
+			"post.logout.redirect.uris":  publicURL + "/*",
+			"pkce.code.challenge.method": "S256",
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+	if created {
+		if err := c.grantViewUsers(ctx, realmPath, id); err != nil {
+			return "", err
+		}
+	}
+
+	if _, _, err := c.ensureClient(ctx, realmPath, map[string]any{
+		"clientId":                  cliClientID,
+		"name":                      "Wonder Mesh Net CLI",
+		"enabled":                   true,
+		"publicClient":              true,
+		"standardFlowEnabled":       false,
+		"directAccessGrantsEnabled": false,
+		"protocol":                  "openid-connect",
+		"attributes": map[string]string{
+			"oauth2.device.authorization.grant.enabled": "true",
+		},
+	}); err != nil {
+		return "", err
+	}
+
+	var secret struct {
+		Value string `json:"value"`
+	}
+	if err := c.do(ctx, http.MethodGet, realmPath+"/clients/"+id+"/client-secret", nil, &secret); err != nil {
+		return "", fmt.Errorf("get secret of client %s: %w", clientID, err)
+	}
+	if secret.Value == "" {
+		return "", fmt.Errorf("client %s has no secret; is it a confidential client?", clientID)
+	}
+	return secret.Value, nil
+}
+
+// ensureClient creates the client unless one with its clientId exists, and
+// returns the client's internal ID and whether it was created.
+func (c *keycloakAdminClient) ensureClient(ctx context.Context, realmPath string, client map[string]any) (string, bool, error) {
+	clientID := client["clientId"].(string)
+	id, err := c.clientUUID(ctx, realmPath, clientID)
+	if err == nil {
+		fmt.Fprintf(os.Stderr, "Keycloak client %s exists, keeping it\n", clientID)
+		return id, false, nil
+	}
+	if !errors.Is(err, errKeycloakNotFound) {
+		return "", false, err
+	}
+
+	if err := c.do(ctx, http.MethodPost, realmPath+"/clients", client, nil); err != nil {
+		return "", false, fmt.Errorf("create client %s: %w", clientID, err)
+	}
+	id, err = c.clientUUID(ctx, realmPath, clientID)
+	if err != nil {
+		return "", false, err
+	}
+	fmt.Fprintf(os.Stderr, "Created Keycloak client %s\n", clientID)
+	return id, true, nil
+}
+
+// clientUUID returns the internal ID of the client with clientID.
+func (c *keycloakAdminClient) clientUUID(ctx context.Context, realmPath, clientID string) (string, error) {
+	var clients []struct {
+		ID       string `json:"id"`
+		ClientID string `json:"clientId"`
+	}
+	if err := c.do(ctx, http.MethodGet, realmPath+"/clients?"+url.Values{"clientId": {clientID}}.Encode(), nil, &clients); err != nil {
+		return "", fmt.Errorf("look up client %s: %w", clientID, err)
+	}
+	for _, client := range clients {
+		if client.ClientID == clientID {
+			return client.ID, nil
+		}
+	}
+	return "", fmt.Errorf("client %s: %w", clientID, errKeycloakNotFound)
+}
+
+// grantViewUsers gives the service account of a client the
+// realm-management view-users role, which the coordinator needs to look up
+// the users it invites to wonder nets.
+func (c *keycloakAdminClient) grantViewUsers(ctx context.Context, realmPath, clientUUID string) error {
+	var user struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodGet, realmPath+"/clients/"+clientUUID+"/service-account-user", nil, &user); err != nil {
+		return fmt.Errorf("get service account: %w", err)
+	}
+	managementID, err := c.clientUUID(ctx, realmPath, "realm-management")
+	if err != nil {
+		return err
+	}
+	var role map[string]any
+	if err := c.do(ctx, http.MethodGet, realmPath+"/clients/"+managementID+"/roles/view-users", nil, &role); err != nil {
+		return fmt.Errorf("get view-users role: %w", err)
+	}
+	if err := c.do(ctx, http.MethodPost, realmPath+"/users/"+user.ID+"/role-mappings/clients/"+managementID, []map[string]any{role}, nil); err != nil {
+		return fmt.Errorf("grant view-users role: %w", err)
+	}
+	return nil
+}
+
+// do sends a request to the admin API and decodes a JSON response into out
+// if it is not nil. A 404 response returns errKeycloakNotFound.
+func (c *keycloakAdminClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reqBody io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return err
+		}
+		reqBody = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reqBody)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+c.token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact Keycloak: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return errKeycloakNotFound
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 4<<10))
+		return fmt.Errorf("status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+	if out == nil {
+		return nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,191 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/spf13/viper"
+)
+
+// fakeKeycloakAdmin serves the parts of the Keycloak admin API that
+// coordinator init uses, starting without the realm.
+type fakeKeycloakAdmin struct {
+	mu        sync.Mutex
+	realm     bool
+	clients   map[string]map[string]any
+	roleGrant []map[string]any
+}
+
+func (k *fakeKeycloakAdmin) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	k.mu.Lock()
+	defer k.mu.Unlock()
+
+	if r.URL.Path == "/realms/master/protocol/openid-connect/token" {
+		if r.FormValue("username") != "admin" || r.FormValue("password") != "pw" {
+			http.Error(w, "invalid credentials", http.StatusUnauthorized)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "admin-token"})
+		return
+	}
+	if r.Header.Get("Authorization") != "Bearer admin-token" {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	switch path := r.URL.Path; {
+	case r.Method == http.MethodPost && path == "/admin/realms":
+		k.realm = true
+	case path == "/admin/realms/wonder":
+		if !k.realm {
+			http.NotFound(w, r)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"realm": "wonder"})
+	case r.Method == http.MethodGet && path == "/admin/realms/wonder/clients":
+		var found []map[string]any
+		if client, ok := k.clients[r.URL.Query().Get("clientId")]; ok {
+			found = append(found, client)
+		}
+		_ = json.NewEncoder(w).Encode(found)
+	case r.Method == http.MethodPost && path == "/admin/realms/wonder/clients":
+		var client map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&client)
+		client["id"] = "uuid-" + client["clientId"].(string)
+		k.clients[client["clientId"].(string)] = client
+		w.WriteHeader(http.StatusCreated)
+	case path == "/admin/realms/wonder/clients/uuid-wonder-mesh-net/client-secret":
+		_ = json.NewEncoder(w).Encode(map[string]string{"value": "generated-secret"})
+	case path == "/admin/realms/wonder/clients/uuid-wonder-mesh-net/service-account-user":
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "sa-user"})
+	case path == "/admin/realms/wonder/clients/uuid-realm-management/roles/view-users":
+		_ = json.NewEncoder(w).Encode(map[string]string{"id": "role-1", "name": "view-users"})
+	case r.Method == http.MethodPost && path == "/admin/realms/wonder/users/sa-user/role-mappings/clients/uuid-realm-management":
+		_ = json.NewDecoder(r.Body).Decode(&k.roleGrant)
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func TestKeycloakAdminClientProvision(t *testing.T) {
+	keycloak := &fakeKeycloakAdmin{clients: map[string]map[string]any{
+		"realm-management": {"id": "uuid-realm-management", "clientId": "realm-management"},
+	}}
+	server := httptest.NewServer(keycloak)
+	defer server.Close()
+	ctx := context.Background()
+
+	if _, err := newKeycloakAdminClient(ctx, server.URL, "admin", "wrong"); err == nil {
+		t.Error("newKeycloakAdminClient() error = nil, want an error for a wrong password")
+	}
+
+	kc, err := newKeycloakAdminClient(ctx, server.URL, "admin", "pw")
+	if err != nil {
+		t.Fatal(err)
+	}
+	secret, err := kc.provision(ctx, "wonder", "wonder-mesh-net", "wonder-cli", "https://mesh.example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if secret != "generated-secret" {
+		t.Errorf("provision() = %q, want the client secret", secret)
+	}
+	if !keycloak.realm {
+		t.Error("realm was not created")
+	}
+	coordinatorClient := keycloak.clients["wonder-mesh-net"]
+	if uris, _ := coordinatorClient["redirectUris"].([]any); len(uris) != 1 || uris[0] != "https://mesh.example.com/coordinator/oidc/callback" {
+		t.Errorf("coordinator client redirectUris = %v, want the OIDC callback", coordinatorClient["redirectUris"])
+	}
+	cliClient := keycloak.clients["wonder-cli"]
+	if cliClient["publicClient"] != true {
+		t.Errorf("wonder-cli client = %v, want a public client", cliClient)
+	}
+	if len(keycloak.roleGrant) != 1 || keycloak.roleGrant[0]["name"] != "view-users" {
+		t.Errorf("service account roles = %v, want view-users", keycloak.roleGrant)
+	}
+
+	// Running again keeps the existing clients and reads the secret.
+	keycloak.roleGrant = nil
+	if secret, err := kc.provision(ctx, "wonder", "wonder-mesh-net", "wonder-cli", "https://mesh.example.com"); err != nil || secret != "generated-secret" {
+		t.Errorf("second provision() = %q, %v, want the existing secret", secret, err)
+	}
+	if keycloak.roleGrant != nil {
+		t.Error("second provision() granted roles again")
+	}
+}
+
+func TestWriteCoordinatorConfig(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "wonder", "config.yaml")
+	err := writeCoordinatorConfig(file, coordinatorFileConfig{Coordinator: coordinatorFileSettings{
+		Listen:               ":9080",
+		PublicURL:            "https://mesh.example.com",
+		JWTSecret:            strings.Repeat("ab", 32),
+		DatabaseDriver:       "sqlite",
+		KeycloakURL:          "https://auth.example.com",
+		KeycloakRealm:        "wonder",
+		KeycloakClientID:     "wonder-mesh-net",
+		KeycloakClientSecret: "secret",
+		KeycloakAudiences:    []string{"wonder-cli"},
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	info, err := os.Stat(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if perm := info.Mode().Perm(); perm != 0600 {
+		t.Errorf("config mode = %o, want 0600", perm)
+	}
+
+	// The coordinator must read back what init wrote.
+	v := viper.New()
+	v.SetConfigFile(file)
+	if err := v.ReadInConfig(); err != nil {
+		t.Fatal(err)
+	}
+	for key, want := range map[string]string{
+		"coordinator.public_url":             "https://mesh.example.com",
+		"coordinator.keycloak_client_secret": "secret",
+		"coordinator.database_driver":        "sqlite",
+	} {
+		if got := v.GetString(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+	if got := parseStringSlice(v.Get("coordinator.keycloak_audiences")); len(got) != 1 || got[0] != "wonder-cli" {
+		t.Errorf("keycloak_audiences = %v, want [wonder-cli]", got)
+	}
+	if v.IsSet("coordinator.admin_api_auth_token") {
+		t.Error("admin_api_auth_token is set without the admin API")
+	}
+}
+
+func TestNormalizePublicURL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    string
+		wantErr bool
+	}{
+		{in: "https://mesh.example.com/", want: "https://mesh.example.com"},
+		{in: " http://localhost:9080 ", want: "http://localhost:9080"},
+		{in: "mesh.example.com", wantErr: true},
+		{in: "ftp://mesh.example.com", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := normalizePublicURL(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("normalizePublicURL(%q) = %q, %v, want %q, error %v", tt.in, got, err, tt.want, tt.wantErr)
+		}
+	}
+}
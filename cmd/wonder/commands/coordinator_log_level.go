@@ -0,0 +1,111 @@
+package commands
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+)
+
+// newCoordinatorLogLevelCmd creates the log-level subcommand that shows or
+// changes the log level of a running coordinator via its admin API.
+func newCoordinatorLogLevelCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "log-level [debug|info|warn|error]",
+		Short: "Show or change the log level of a running coordinator",
+		Long: `Show or change the log level of a running coordinator without a restart.
+
+The new level lasts until the coordinator restarts or a config reload
+changes log_level. The coordinator must run with --enable-admin-api. The
+admin token is read from --admin-token or the ADMIN_API_AUTH_TOKEN
+environment variable.
+
+Examples:
+  wonder coordinator log-level
+  wonder coordinator log-level debug --url https://mesh.example.com`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runCoordinatorLogLevel,
+	}
+
+	cmd.Flags().String("url", "http://localhost:9080", "Coordinator URL")
+	cmd.Flags().String("admin-token", "", "Admin API token (defaults to ADMIN_API_AUTH_TOKEN)")
+
+	return cmd
+}
+
+func runCoordinatorLogLevel(cmd *cobra.Command, args []string) error {
+	format, err := output.FromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	baseURL, _ := cmd.Flags().GetString("url")
+	token, _ := cmd.Flags().GetString("admin-token")
+
+	if token == "" {
+		_ = viper.BindEnv("coordinator.admin_api_auth_token", "ADMIN_API_AUTH_TOKEN")
+		token = viper.GetString("coordinator.admin_api_auth_token")
+	}
+	if token == "" {
+		return fmt.Errorf("admin token is required: pass --admin-token or set ADMIN_API_AUTH_TOKEN")
+	}
+
+	method := http.MethodGet
+	var body io.Reader
+	if len(args) == 1 {
+		reqBody, err := json.Marshal(map[string]string{"level": args[0]})
+		if err != nil {
+			return err
+		}
+		method = http.MethodPut
+		body = bytes.NewReader(reqBody)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), 30*time.Second)
+	defer cancel()
+
+	url := strings.TrimRight(baseURL, "/") + "/coordinator/admin/api/v1/log-level"
+	req, err := http.NewRequestWithContext(ctx, method, url, body)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		respBody, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("log level: status %d: %s", resp.StatusCode, strings.TrimSpace(string(respBody)))
+	}
+
+	var result struct {
+		Level string `json:"level"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return output.Print(os.Stdout, format, result, func(w io.Writer) error {
+		if method == http.MethodPut {
+			_, err := fmt.Fprintf(w, "Log level set to %s\n", result.Level)
+			return err
+		}
+		_, err := fmt.Fprintln(w, result.Level)
+		return err
+	})
+}
@@ -0,0 +1,136 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+)
+
+var profileTypes = []string{"cpu", "heap", "allocs", "goroutine", "block", "mutex", "threadcreate", "trace"}
+
+// newCoordinatorProfileCmd creates the profile subcommand that captures a
+// runtime profile from a running coordinator via its admin-guarded pprof endpoints.
+func newCoordinatorProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Capture a CPU or memory profile from a running coordinator",
+		Long: `Capture a runtime profile from a running coordinator.
+
+The coordinator must run with --enable-admin-api. The admin token is read
+from --admin-token or the ADMIN_API_AUTH_TOKEN environment variable.
+
+Examples:
+  wonder coordinator profile --url https://mesh.example.com --type cpu --seconds 30
+  wonder coordinator profile --type heap -o heap.pprof
+
+Inspect the result with: go tool pprof <file>`,
+		Args: cobra.NoArgs,
+		RunE: runCoordinatorProfile,
+	}
+
+	cmd.Flags().String("url", "http://localhost:9080", "Coordinator URL")
+	cmd.Flags().String("admin-token", "", "Admin API token (defaults to ADMIN_API_AUTH_TOKEN)")
+	cmd.Flags().String("type", "cpu", "Profile type: "+strings.Join(profileTypes, ", "))
+	cmd.Flags().Int("seconds", 30, "Sampling duration for cpu and trace profiles")
+	cmd.Flags().StringP("output", "o", "", "Output file (defaults to <type>-<timestamp>.pprof)")
+
+	return cmd
+}
+
+func runCoordinatorProfile(cmd *cobra.Command, args []string) error {
+	baseURL, _ := cmd.Flags().GetString("url")
+	token, _ := cmd.Flags().GetString("admin-token")
+	profileType, _ := cmd.Flags().GetString("type")
+	seconds, _ := cmd.Flags().GetInt("seconds")
+	output, _ := cmd.Flags().GetString("output")
+
+	if token == "" {
+		_ = viper.BindEnv("coordinator.admin_api_auth_token", "ADMIN_API_AUTH_TOKEN")
+		token = viper.GetString("coordinator.admin_api_auth_token")
+	}
+	if token == "" {
+		return fmt.Errorf("admin token is required: pass --admin-token or set ADMIN_API_AUTH_TOKEN")
+	}
+
+	endpoint, err := profileEndpoint(profileType)
+	if err != nil {
+		return err
+	}
+
+	timeout := 30 * time.Second
+	query := ""
+	if profileType == "cpu" || profileType == "trace" {
+		if seconds <= 0 {
+			return fmt.Errorf("--seconds must be positive")
+		}
+		query = fmt.Sprintf("?seconds=%d", seconds)
+		timeout += time.Duration(seconds) * time.Second
+	}
+
+	if output == "" {
+		ext := "pprof"
+		if profileType == "trace" {
+			ext = "out"
+		}
+		output = fmt.Sprintf("%s-%s.%s", profileType, time.Now().Format("20060102-150405"), ext)
+	}
+
+	ctx, cancel := context.WithTimeout(cmd.Context(), timeout)
+	defer cancel()
+
+	url := strings.TrimRight(baseURL, "/") + "/coordinator/debug/pprof/" + endpoint + query
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	if profileType == "cpu" || profileType == "trace" {
+		fmt.Printf("Capturing %s profile for %ds...\n", profileType, seconds)
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("capture profile: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	f, err := os.Create(output)
+	if err != nil {
+		return fmt.Errorf("create output file: %w", err)
+	}
+	n, err := io.Copy(f, resp.Body)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("write profile: %w", err)
+	}
+
+	fmt.Printf("Wrote %s profile to %s (%d bytes)\n", profileType, output, n)
+	return nil
+}
+
+// profileEndpoint maps a profile type to its pprof endpoint name.
+func profileEndpoint(profileType string) (string, error) {
+	switch profileType {
+	case "cpu":
+		return "profile", nil
+	case "heap", "allocs", "goroutine", "block", "mutex", "threadcreate", "trace":
+		return profileType, nil
+	default:
+		return "", fmt.Errorf("unknown profile type %q, want one of: %s", profileType, strings.Join(profileTypes, ", "))
+	}
+}
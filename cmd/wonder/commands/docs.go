@@ -0,0 +1,256 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+// CommandSchema describes a command of the wonder CLI, its flags, and its
+// subcommands in machine-readable form.
+type CommandSchema struct {
+	Name        string          `json:"name"`
+	Path        string          `json:"path"`
+	Use         string          `json:"use"`
+	Short       string          `json:"short,omitempty"`
+	Long        string          `json:"long,omitempty"`
+	Aliases     []string        `json:"aliases,omitempty"`
+	Runnable    bool            `json:"runnable"`
+	Flags       []FlagSchema    `json:"flags,omitempty"`
+	Inherited   []FlagSchema    `json:"inherited_flags,omitempty"`
+	Subcommands []CommandSchema `json:"subcommands,omitempty"`
+}
+
+// FlagSchema describes a command-line flag.
+type FlagSchema struct {
+	Name      string `json:"name"`
+	Shorthand string `json:"shorthand,omitempty"`
+	Type      string `json:"type"`
+	Default   string `json:"default,omitempty"`
+	Usage     string `json:"usage"`
+	Required  bool   `json:"required,omitempty"`
+}
+
+// NewDocsCmd creates the docs command that generates reference documentation
+// from the command tree.
+func NewDocsCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:    "docs",
+		Short:  "Generate CLI reference documentation",
+		Hidden: true,
+	}
+	cmd.AddCommand(newDocsGenerateCmd())
+	return cmd
+}
+
+// newDocsGenerateCmd creates the docs generate subcommand.
+func newDocsGenerateCmd() *cobra.Command {
+	var outputDir string
+
+	cmd := &cobra.Command{
+		Use:   "generate",
+		Short: "Write man pages and a JSON command schema",
+		Long: `Write a man page for every command and a JSON schema of the whole command
+tree to the output directory. The man pages go to man1/ and the schema to
+commands.json.
+
+Example:
+  wonder docs generate --output-dir docs/cli`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			root := cmd.Root()
+
+			manDir := filepath.Join(outputDir, "man1")
+			if err := os.MkdirAll(manDir, 0o755); err != nil {
+				return fmt.Errorf("create man page directory: %w", err)
+			}
+			if err := writeManPages(root, manDir); err != nil {
+				return err
+			}
+
+			data, err := json.MarshalIndent(BuildCommandSchema(root), "", "  ")
+			if err != nil {
+				return fmt.Errorf("encode command schema: %w", err)
+			}
+			schemaPath := filepath.Join(outputDir, "commands.json")
+			if err := os.WriteFile(schemaPath, append(data, '\n'), 0o644); err != nil {
+				return fmt.Errorf("write command schema: %w", err)
+			}
+
+			fmt.Printf("Wrote man pages to %s and command schema to %s\n", manDir, schemaPath)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&outputDir, "output-dir", "docs/cli", "Directory to write the documentation to")
+	return cmd
+}
+
+// BuildCommandSchema describes cmd and its visible subcommands. Hidden and
+// deprecated commands and flags are left out, as are cobra's generated help
+// and completion commands.
+func BuildCommandSchema(cmd *cobra.Command) CommandSchema {
+	schema := CommandSchema{
+		Name:      cmd.Name(),
+		Path:      cmd.CommandPath(),
+		Use:       cmd.UseLine(),
+		Short:     cmd.Short,
+		Long:      cmd.Long,
+		Aliases:   cmd.Aliases,
+		Runnable:  cmd.Runnable(),
+		Flags:     flagSchemas(cmd.NonInheritedFlags()),
+		Inherited: flagSchemas(cmd.InheritedFlags()),
+	}
+	for _, sub := range documentedCommands(cmd) {
+		schema.Subcommands = append(schema.Subcommands, BuildCommandSchema(sub))
+	}
+	return schema
+}
+
+// documentedCommands returns the subcommands of cmd that belong in the
+// reference documentation, sorted by name.
+func documentedCommands(cmd *cobra.Command) []*cobra.Command {
+	var subs []*cobra.Command
+	for _, sub := range cmd.Commands() {
+		if !sub.IsAvailableCommand() || sub.IsAdditionalHelpTopicCommand() || sub.Name() == "completion" {
+			continue
+		}
+		subs = append(subs, sub)
+	}
+	sort.Slice(subs, func(i, j int) bool { return subs[i].Name() < subs[j].Name() })
+	return subs
+}
+
+func flagSchemas(flags *pflag.FlagSet) []FlagSchema {
+	var result []FlagSchema
+	flags.VisitAll(func(f *pflag.Flag) {
+		if f.Hidden || f.Deprecated != "" || f.Name == "help" {
+			return
+		}
+		_, required := f.Annotations[cobra.BashCompOneRequiredFlag]
+		result = append(result, FlagSchema{
+			Name:      f.Name,
+			Shorthand: f.Shorthand,
+			Type:      f.Value.Type(),
+			Default:   f.DefValue,
+			Usage:     f.Usage,
+			Required:  required,
+		})
+	})
+	return result
+}
+
+// writeManPages writes a section 1 man page for cmd and each of its
+// documented subcommands to dir.
+func writeManPages(cmd *cobra.Command, dir string) error {
+	name := manPageName(cmd)
+	path := filepath.Join(dir, name+".1")
+	if err := os.WriteFile(path, renderManPage(cmd), 0o644); err != nil {
+		return fmt.Errorf("write man page %s: %w", name, err)
+	}
+	for _, sub := range documentedCommands(cmd) {
+		if err := writeManPages(sub, dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// renderManPage renders the man page of cmd in roff. The page carries no
+// date so that regenerating it from an unchanged tree gives the same output.
+func renderManPage(cmd *cobra.Command) []byte {
+	var buf bytes.Buffer
+	name := manPageName(cmd)
+
+	fmt.Fprintf(&buf, ".TH %q \"1\" \"\" \"wonder %s\" \"Wonder Mesh Net Manual\"\n", strings.ToUpper(name), version)
+
+	buf.WriteString(".SH NAME\n")
+	fmt.Fprintf(&buf, "%s \\- %s\n", roffEscape(name), roffEscape(cmd.Short))
+
+	buf.WriteString(".SH SYNOPSIS\n")
+	fmt.Fprintf(&buf, "\\fB%s\\fP\n", roffEscape(cmd.UseLine()))
+
+	description := cmd.Long
+	if description == "" {
+		description = cmd.Short
+	}
+	buf.WriteString(".SH DESCRIPTION\n.nf\n")
+	buf.WriteString(roffText(description))
+	buf.WriteString(".fi\n")
+
+	writeManFlags(&buf, "OPTIONS", flagSchemas(cmd.NonInheritedFlags()))
+	writeManFlags(&buf, "OPTIONS INHERITED FROM PARENT COMMANDS", flagSchemas(cmd.InheritedFlags()))
+
+	var seeAlso []string
+	if cmd.HasParent() {
+		seeAlso = append(seeAlso, manPageName(cmd.Parent()))
+	}
+	for _, sub := range documentedCommands(cmd) {
+		seeAlso = append(seeAlso, manPageName(sub))
+	}
+	if len(seeAlso) > 0 {
+		buf.WriteString(".SH SEE ALSO\n")
+		for i, ref := range seeAlso {
+			if i > 0 {
+				buf.WriteString(",\n")
+			}
+			fmt.Fprintf(&buf, "\\fB%s\\fP(1)", roffEscape(ref))
+		}
+		buf.WriteString("\n")
+	}
+	return buf.Bytes()
+}
+
+func writeManFlags(buf *bytes.Buffer, section string, flags []FlagSchema) {
+	if len(flags) == 0 {
+		return
+	}
+	fmt.Fprintf(buf, ".SH %s\n", section)
+	for _, f := range flags {
+		buf.WriteString(".TP\n")
+		if f.Shorthand != "" {
+			fmt.Fprintf(buf, "\\fB\\-%s\\fP, ", roffEscape(f.Shorthand))
+		}
+		fmt.Fprintf(buf, "\\fB\\-\\-%s\\fP", roffEscape(f.Name))
+		if f.Type != "bool" {
+			fmt.Fprintf(buf, " \\fI%s\\fP", roffEscape(f.Type))
+		}
+		buf.WriteString("\n")
+		usage := f.Usage
+		if f.Default != "" && f.Type != "bool" {
+			usage += fmt.Sprintf(" (default %s)", f.Default)
+		}
+		buf.WriteString(roffText(usage))
+	}
+}
+
+// manPageName returns the man page name of cmd, such as "wonder-worker-join".
+func manPageName(cmd *cobra.Command) string {
+	return strings.ReplaceAll(cmd.CommandPath(), " ", "-")
+}
+
+// roffText escapes multi-line text for roff, guarding lines that would
+// otherwise be read as requests.
+func roffText(text string) string {
+	var b strings.Builder
+	for line := range strings.SplitSeq(strings.TrimRight(text, "\n"), "\n") {
+		line = roffEscape(line)
+		if strings.HasPrefix(line, ".") || strings.HasPrefix(line, "'") {
+			line = "\\&" + line
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+func roffEscape(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\e`)
+	return strings.ReplaceAll(s, "-", `\-`)
+}
@@ -0,0 +1,82 @@
+package commands
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+func TestBuildCommandSchema(t *testing.T) {
+	root := &cobra.Command{Use: "wonder", Short: "Wonder Mesh Net CLI"}
+	root.PersistentFlags().String("config", "", "config file")
+
+	child := &cobra.Command{Use: "join", Short: "Join", Run: func(*cobra.Command, []string) {}}
+	child.Flags().StringP("name", "n", "default", "node name")
+	_ = child.MarkFlagRequired("name")
+	child.Flags().String("legacy", "", "old flag")
+	_ = child.Flags().MarkDeprecated("legacy", "use --name")
+
+	hidden := &cobra.Command{Use: "secret", Hidden: true, Run: func(*cobra.Command, []string) {}}
+	root.AddCommand(child, hidden)
+
+	schema := BuildCommandSchema(root)
+	if len(schema.Subcommands) != 1 {
+		t.Fatalf("BuildCommandSchema() subcommands = %d, want 1", len(schema.Subcommands))
+	}
+
+	join := schema.Subcommands[0]
+	if join.Path != "wonder join" {
+		t.Errorf("join.Path = %q, want %q", join.Path, "wonder join")
+	}
+	if !join.Runnable {
+		t.Errorf("join.Runnable = false, want true")
+	}
+	if len(join.Flags) != 1 {
+		t.Fatalf("join.Flags = %v, want only --name", join.Flags)
+	}
+	want := FlagSchema{Name: "name", Shorthand: "n", Type: "string", Default: "default", Usage: "node name", Required: true}
+	if join.Flags[0] != want {
+		t.Errorf("join.Flags[0] = %+v, want %+v", join.Flags[0], want)
+	}
+	if len(join.Inherited) != 1 || join.Inherited[0].Name != "config" {
+		t.Errorf("join.Inherited = %v, want --config", join.Inherited)
+	}
+}
+
+func TestRoffText(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain\n"},
+		{"a-b", "a\\-b\n"},
+		{`back\slash`, "back\\eslash\n"},
+		{".starts with dot\n'quote", "\\&.starts with dot\n\\&'quote\n"},
+	}
+
+	for _, tt := range tests {
+		if got := roffText(tt.in); got != tt.want {
+			t.Errorf("roffText(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestRenderManPage(t *testing.T) {
+	root := &cobra.Command{Use: "wonder", Short: "Wonder Mesh Net CLI"}
+	child := &cobra.Command{Use: "join", Short: "Join the mesh", Run: func(*cobra.Command, []string) {}}
+	child.Flags().Bool("force", false, "overwrite state")
+	root.AddCommand(child)
+
+	page := string(renderManPage(child))
+	for _, want := range []string{
+		`.TH "WONDER-JOIN" "1"`,
+		"wonder\\-join \\- Join the mesh",
+		"\\fB\\-\\-force\\fP\n",
+		"\\fBwonder\\fP(1)",
+	} {
+		if !strings.Contains(page, want) {
+			t.Errorf("renderManPage() missing %q in:\n%s", want, page)
+		}
+	}
+}
@@ -0,0 +1,281 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+// nodesFlags holds the flags shared by the nodes subcommands.
+var nodesFlags struct {
+	coordinatorURL string
+	token          string
+}
+
+// NewNodesCmd creates the nodes command for listing the nodes of a wonder net.
+func NewNodesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "nodes",
+		Short: "List, rename, and approve the nodes of a wonder net",
+	}
+
+	cmd.PersistentFlags().StringVar(&nodesFlags.coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.PersistentFlags().StringVar(&nodesFlags.token, "token", "", "Session token or API key (env: WONDER_TOKEN or WONDER_API_KEY)")
+
+	cmd.AddCommand(newNodesListCmd())
+	cmd.AddCommand(newNodesPendingCmd())
+	cmd.AddCommand(newNodesApproveCmd())
+	cmd.AddCommand(newNodesRenameCmd())
+	return cmd
+}
+
+// newNodesListCmd creates the nodes list subcommand.
+func newNodesListCmd() *cobra.Command {
+	var onlineOnly bool
+	var ipFamily string
+	var opts wondersdk.ListNodesOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List nodes",
+		Long: `List the nodes of the wonder net with their addresses, whether they are
+online, and whether their worker sent a recent heartbeat.
+
+--os and --tag narrow the listing to nodes whose worker reported that
+operating system, or that carry that tag, and --search to nodes whose name
+or hostname contains the text. --sort orders the nodes by id (the default),
+name, or last_seen, prefixed with - for descending order. --ip-family ipv4 or ipv6 shows
+only the addresses of that family in the table.
+
+With --output json or yaml, each node has the fields of the coordinator's
+node listing: id, name, hostname, ip_addresses, ip (the ipv4 and ipv6
+address), online, last_seen, created_at, tags, os, tailscale_version, and
+health.
+
+Example:
+  wonder nodes list --coordinator-url https://coordinator.example.com --online --os linux --tag worker -o json`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			family, err := wondersdk.ParseIPFamily(ipFamily)
+			if err != nil {
+				return err
+			}
+			client, err := newTokenClient(nodesFlags.coordinatorURL, nodesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			if onlineOnly {
+				opts.Online = &onlineOnly
+			}
+			nodes, err := client.ListNodesWithOptions(ctx, "", opts)
+			if err != nil {
+				return fmt.Errorf("list nodes: %w", err)
+			}
+			return output.Print(os.Stdout, format, nodes, func(w io.Writer) error {
+				return printNodes(w, nodes, family)
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&onlineOnly, "online", false, "Only list online nodes")
+	cmd.Flags().StringVar(&opts.OS, "os", "", "Only list nodes running this operating system, e.g. linux")
+	cmd.Flags().StringVar(&opts.Tag, "tag", "", "Only list nodes with this tag, e.g. worker")
+	cmd.Flags().StringVar(&opts.Search, "search", "", "Only list nodes whose name or hostname contains this text")
+	cmd.Flags().StringVar(&opts.Sort, "sort", "", "Order by id, name, or last_seen; prefix with - for descending")
+	cmd.Flags().StringVar(&ipFamily, "ip-family", "auto", "Addresses to show: auto (all), ipv4, or ipv6")
+	return cmd
+}
+
+// newNodesPendingCmd creates the nodes pending subcommand.
+func newNodesPendingCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "pending",
+		Short: "List nodes waiting for approval",
+		Long: `List the nodes that registered with a wonder net requiring node approval
+and wait for the owner to approve them. Until then they cannot reach, or be
+reached by, the other nodes of the wonder net.
+
+Node approval is turned on with
+  PATCH /coordinator/api/v1/wonder-nets/{id} {"require_node_approval": true}`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newTokenClient(nodesFlags.coordinatorURL, nodesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			nodes, err := client.ListPendingNodes(ctx, "")
+			if err != nil {
+				return fmt.Errorf("list pending nodes: %w", err)
+			}
+			return output.Print(os.Stdout, format, nodes, func(w io.Writer) error {
+				return printPendingNodes(w, nodes)
+			})
+		},
+	}
+}
+
+// newNodesApproveCmd creates the nodes approve subcommand.
+func newNodesApproveCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "approve <node-id>",
+		Short: "Approve a node waiting for approval",
+		Long: `Approve a pending node so that it joins the wonder net's ACL rules.
+Only the owner of the wonder net can approve nodes. To turn a node away
+instead, decommission it.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid node id %q", args[0])
+			}
+			client, err := newTokenClient(nodesFlags.coordinatorURL, nodesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			approval, err := client.ApproveNode(ctx, "", id)
+			if err != nil {
+				return fmt.Errorf("approve node: %w", err)
+			}
+			return output.Print(os.Stdout, format, approval, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Node %d is %s\n", approval.NodeID, approval.Status)
+				return err
+			})
+		},
+	}
+}
+
+// newNodesRenameCmd creates the nodes rename subcommand.
+func newNodesRenameCmd() *cobra.Command {
+	var reset bool
+
+	cmd := &cobra.Command{
+		Use:   "rename <node-id> [name]",
+		Short: "Rename a node",
+		Long: `Set the name a node is known by in the mesh. The name must be a valid DNS
+label (lowercase letters, digits, and hyphens) that no other node of the
+wonder net has. The device's own hostname is not changed.
+
+Nodes that are not renamed are named after their hostname, formatted by the
+wonder net's node_name_template, with -2, -3, and so on appended when names
+collide. Use --reset to return a renamed node to that policy.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if reset == (len(args) == 2) {
+				return fmt.Errorf("pass either a name or --reset")
+			}
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			id, err := strconv.ParseUint(args[0], 10, 64)
+			if err != nil {
+				return fmt.Errorf("invalid node id %q", args[0])
+			}
+			var name string
+			if len(args) == 2 {
+				name = args[1]
+			}
+			client, err := newTokenClient(nodesFlags.coordinatorURL, nodesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			node, err := client.RenameNode(ctx, "", id, name)
+			if err != nil {
+				return fmt.Errorf("rename node: %w", err)
+			}
+			return output.Print(os.Stdout, format, node, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "Node %d is now %s\n", node.ID, node.Name)
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().BoolVar(&reset, "reset", false, "Return the node to the wonder net's naming policy")
+	return cmd
+}
+
+// printPendingNodes prints pending nodes as a table.
+func printPendingNodes(out io.Writer, nodes []wondersdk.PendingNode) error {
+	if len(nodes) == 0 {
+		_, err := fmt.Fprintln(out, "No pending nodes")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tHOSTNAME\tADDRESSES\tONLINE\tSEEN")
+	for _, node := range nodes {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\n", node.ID, node.Name, node.Hostname, strings.Join(node.Addresses, ","), node.Online, node.SeenAt.Format(time.RFC3339))
+	}
+	return w.Flush()
+}
+
+// printNodes prints nodes as a table, with only their addresses of the IP
+// family unless it is auto.
+func printNodes(out io.Writer, nodes []wondersdk.Node, family wondersdk.IPFamily) error {
+	if len(nodes) == 0 {
+		_, err := fmt.Fprintln(out, "No nodes")
+		return err
+	}
+
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tADDRESSES\tOS\tONLINE\tHEALTHY\tLAST SEEN")
+	for _, node := range nodes {
+		healthy := "-"
+		if node.Health != nil {
+			healthy = fmt.Sprint(node.Health.Healthy)
+		}
+		lastSeen := node.LastSeen
+		if lastSeen == "" {
+			lastSeen = "-"
+		}
+		nodeOS := node.OS
+		if nodeOS == "" {
+			nodeOS = "-"
+		}
+		addresses := strings.Join(node.Addresses, ",")
+		if family != wondersdk.IPFamilyAuto {
+			addresses = wondersdk.SelectAddress(node.Addresses, family)
+		}
+		if addresses == "" {
+			addresses = "-"
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%t\t%s\t%s\n", node.ID, node.Name, addresses, nodeOS, node.Online, healthy, lastSeen)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,118 @@
+// Package output prints command results in the format chosen with the
+// global --output flag: a human-readable table, JSON, or YAML.
+package output
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/spf13/cobra"
+	"go.yaml.in/yaml/v3"
+)
+
+// Format is an output format accepted by --output.
+type Format string
+
+const (
+	Table Format = "table"
+	JSON  Format = "json"
+	YAML  Format = "yaml"
+)
+
+// FlagName is the name of the global flag selecting the output format.
+const FlagName = "output"
+
+// Formats lists the accepted formats, for help text and shell completion.
+var Formats = []string{string(Table), string(JSON), string(YAML)}
+
+// AddFlag registers the --output flag, with shell completion of its values,
+// as a persistent flag of root.
+func AddFlag(root *cobra.Command) {
+	root.PersistentFlags().StringP(FlagName, "o", string(Table), "Output format: table, json, or yaml")
+	_ = root.RegisterFlagCompletionFunc(FlagName, func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+		return Formats, cobra.ShellCompDirectiveNoFileComp
+	})
+}
+
+// Parse validates an output format name.
+func Parse(name string) (Format, error) {
+	switch Format(name) {
+	case Table, JSON, YAML:
+		return Format(name), nil
+	case "":
+		return Table, nil
+	default:
+		return "", fmt.Errorf("unknown output format %q: use table, json, or yaml", name)
+	}
+}
+
+// FromCommand returns the format chosen with --output for cmd, or Table if
+// the command has no such flag.
+func FromCommand(cmd *cobra.Command) (Format, error) {
+	flag := cmd.Flags().Lookup(FlagName)
+	if flag == nil {
+		return Table, nil
+	}
+	return Parse(flag.Value.String())
+}
+
+// Print writes v to w in format. For Table it calls table instead, which
+// prints the human-readable form. JSON and YAML use the json field names of
+// v, so both formats share one schema, and a nil slice is written as an
+// empty list.
+func Print(w io.Writer, format Format, v any, table func(w io.Writer) error) error {
+	if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice && rv.IsNil() {
+		v = []any{}
+	}
+
+	switch format {
+	case JSON:
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case YAML:
+		return printYAML(w, v)
+	default:
+		return table(w)
+	}
+}
+
+// printYAML converts v to YAML through its JSON form, keeping the field
+// names and order of the JSON output.
+func printYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	var node yaml.Node
+	if err := yaml.Unmarshal(data, &node); err != nil {
+		return err
+	}
+	blockStyle(&node)
+
+	var buf bytes.Buffer
+	enc := yaml.NewEncoder(&buf)
+	enc.SetIndent(2)
+	if err := enc.Encode(&node); err != nil {
+		return err
+	}
+	if err := enc.Close(); err != nil {
+		return err
+	}
+	_, err = w.Write(buf.Bytes())
+	return err
+}
+
+// blockStyle drops the flow style and quoting that JSON syntax gives the
+// nodes, so the YAML encoder picks its usual block style. Strings that
+// would read as another type stay quoted.
+func blockStyle(node *yaml.Node) {
+	node.Style = 0
+	for _, child := range node.Content {
+		blockStyle(child)
+	}
+}
@@ -0,0 +1,72 @@
+package output
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    Format
+		wantErr bool
+	}{
+		{name: "", want: Table},
+		{name: "table", want: Table},
+		{name: "json", want: JSON},
+		{name: "yaml", want: YAML},
+		{name: "xml", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Parse(tt.name)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse(%q) error = %v, wantErr %v", tt.name, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("Parse(%q) = %q, want %q", tt.name, got, tt.want)
+			}
+		})
+	}
+}
+
+type testItem struct {
+	Name    string   `json:"name"`
+	Enabled string   `json:"enabled"`
+	Tags    []string `json:"tags,omitempty"`
+}
+
+func TestPrint(t *testing.T) {
+	item := testItem{Name: "web", Enabled: "true", Tags: []string{"a", "b"}}
+	table := func(w io.Writer) error {
+		_, err := io.WriteString(w, "NAME\nweb\n")
+		return err
+	}
+
+	tests := []struct {
+		name   string
+		format Format
+		v      any
+		want   string
+	}{
+		{name: "table", format: Table, v: item, want: "NAME\nweb\n"},
+		{name: "json", format: JSON, v: item, want: "{\n  \"name\": \"web\",\n  \"enabled\": \"true\",\n  \"tags\": [\n    \"a\",\n    \"b\"\n  ]\n}\n"},
+		{name: "yaml keeps json names and order", format: YAML, v: item, want: "name: web\nenabled: \"true\"\ntags:\n  - a\n  - b\n"},
+		{name: "nil list as json", format: JSON, v: []testItem(nil), want: "[]\n"},
+		{name: "nil list as yaml", format: YAML, v: []testItem(nil), want: "[]\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			if err := Print(&buf, tt.format, tt.v, table); err != nil {
+				t.Fatalf("Print() error = %v", err)
+			}
+			if got := buf.String(); got != tt.want {
+				t.Errorf("Print() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
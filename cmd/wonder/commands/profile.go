@@ -0,0 +1,412 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"text/tabwriter"
+
+	"github.com/spf13/cobra"
+	"github.com/spf13/viper"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"go.yaml.in/yaml/v3"
+)
+
+// profileFlag holds the global --profile flag.
+var profileFlag string
+
+// profileNamePattern restricts profile names to what is safe in a file
+// name, as each profile keeps its login in a file of its own.
+var profileNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]*$`)
+
+// cliProfile is a named coordinator with the credentials and wonder net to
+// use with it.
+type cliProfile struct {
+	CoordinatorURL string `yaml:"coordinator_url,omitempty"`
+	Token          string `yaml:"token,omitempty"`
+	Network        string `yaml:"network,omitempty"`
+	KeycloakURL    string `yaml:"keycloak_url,omitempty"`
+}
+
+// cliProfiles are the profiles saved in the config file and the one used
+// when --profile is not given.
+type cliProfiles struct {
+	Current  string                `yaml:"current_profile,omitempty"`
+	Profiles map[string]cliProfile `yaml:"profiles,omitempty"`
+}
+
+// profileListEntry is a profile as printed by wonder profile list. The
+// token is only reported as set or not.
+type profileListEntry struct {
+	Name           string `json:"name"`
+	Current        bool   `json:"current"`
+	CoordinatorURL string `json:"coordinator_url,omitempty"`
+	Network        string `json:"network,omitempty"`
+	KeycloakURL    string `json:"keycloak_url,omitempty"`
+	HasToken       bool   `json:"has_token"`
+}
+
+// AddProfileFlag registers the --profile flag, with shell completion of the
+// saved profiles, as a persistent flag of root.
+func AddProfileFlag(root *cobra.Command) {
+	root.PersistentFlags().StringVar(&profileFlag, "profile", "", "Profile from the config file to use (env: WONDER_PROFILE, default: the current profile)")
+	_ = root.RegisterFlagCompletionFunc("profile", completeProfileNames)
+}
+
+// NewProfileCmd creates the profile command for managing the coordinators
+// the CLI talks to.
+func NewProfileCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "profile",
+		Short: "Manage profiles for talking to several coordinators",
+		Long: `Manage named profiles in the config file (default ~/.wonder/config.yaml).
+A profile holds a coordinator URL, optionally an API key, a default wonder
+net, and the Keycloak URL for wonder auth login. Commands that take
+--coordinator-url and --token use the profile selected with --profile (env:
+WONDER_PROFILE), or else the current one set with wonder profile use, for
+whatever the flags and environment do not set.
+
+Each profile keeps its own wonder auth login, so switching between them does
+not require logging in again:
+
+  wonder profile set staging --coordinator-url https://staging.example.com \
+    --keycloak-url https://auth.staging.example.com --network lab
+  wonder profile set prod --coordinator-url https://mesh.example.com
+  wonder auth login --device --profile staging
+  wonder profile use prod
+  wonder nodes list --profile staging`,
+	}
+	cmd.AddCommand(newProfileSetCmd())
+	cmd.AddCommand(newProfileUseCmd())
+	cmd.AddCommand(newProfileListCmd())
+	cmd.AddCommand(newProfileDeleteCmd())
+	return cmd
+}
+
+// newProfileSetCmd creates the profile set subcommand.
+func newProfileSetCmd() *cobra.Command {
+	var values cliProfile
+
+	cmd := &cobra.Command{
+		Use:   "set <name>",
+		Short: "Create a profile or change its settings",
+		Long: `Create a profile, or change the settings of an existing one given by the
+flags, leaving the others as they are; pass an empty value to clear one. The
+first profile becomes the current one.
+
+--token saves an API key in the config file, which is written readable only
+by the current user. Without it, the profile uses its wonder auth login.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProfileNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			if !profileNamePattern.MatchString(name) {
+				return fmt.Errorf("invalid profile name %q: use letters, digits, '-', and '_'", name)
+			}
+			profiles, err := loadCLIProfiles()
+			if err != nil {
+				return err
+			}
+
+			profile := profiles.Profiles[name]
+			if cmd.Flags().Changed("coordinator-url") {
+				profile.CoordinatorURL = values.CoordinatorURL
+				if profile.CoordinatorURL != "" {
+					if profile.CoordinatorURL, err = normalizePublicURL(profile.CoordinatorURL); err != nil {
+						return fmt.Errorf("--coordinator-url: %w", err)
+					}
+				}
+			}
+			if cmd.Flags().Changed("token") {
+				profile.Token = values.Token
+			}
+			if cmd.Flags().Changed("network") {
+				profile.Network = values.Network
+			}
+			if cmd.Flags().Changed("keycloak-url") {
+				profile.KeycloakURL = values.KeycloakURL
+			}
+			profiles.Profiles[name] = profile
+			if profiles.Current == "" {
+				profiles.Current = name
+			}
+			if err := saveCLIProfiles(profiles); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Saved profile %s.\n", name)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&values.CoordinatorURL, "coordinator-url", "", "Coordinator URL")
+	cmd.Flags().StringVar(&values.Token, "token", "", "API key to use instead of the profile's wonder auth login")
+	cmd.Flags().StringVar(&values.Network, "network", "", "Wonder net ID or display name to use instead of your default wonder net")
+	cmd.Flags().StringVar(&values.KeycloakURL, "keycloak-url", "", "Keycloak base URL for wonder auth login")
+	return cmd
+}
+
+// newProfileUseCmd creates the profile use subcommand.
+func newProfileUseCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use <name>",
+		Short:             "Make a profile the current one",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProfileNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			profiles, err := loadCLIProfiles()
+			if err != nil {
+				return err
+			}
+			if _, ok := profiles.Profiles[args[0]]; !ok {
+				return fmt.Errorf("no profile %q, run wonder profile set %s", args[0], args[0])
+			}
+			profiles.Current = args[0]
+			if err := saveCLIProfiles(profiles); err != nil {
+				return err
+			}
+			fmt.Fprintf(os.Stderr, "Using profile %s.\n", args[0])
+			return nil
+		},
+	}
+}
+
+// newProfileListCmd creates the profile list subcommand.
+func newProfileListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List the profiles, marking the current one",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			profiles, err := loadCLIProfiles()
+			if err != nil {
+				return err
+			}
+
+			entries := make([]profileListEntry, 0, len(profiles.Profiles))
+			for _, name := range profiles.names() {
+				profile := profiles.Profiles[name]
+				entries = append(entries, profileListEntry{
+					Name:           name,
+					Current:        name == profiles.Current,
+					CoordinatorURL: profile.CoordinatorURL,
+					Network:        profile.Network,
+					KeycloakURL:    profile.KeycloakURL,
+					HasToken:       profile.Token != "",
+				})
+			}
+			return output.Print(os.Stdout, format, entries, func(out io.Writer) error {
+				if len(entries) == 0 {
+					_, err := fmt.Fprintln(out, "No profiles, create one with wonder profile set")
+					return err
+				}
+
+				w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "CURRENT\tNAME\tCOORDINATOR\tNETWORK")
+				for _, entry := range entries {
+					current := ""
+					if entry.Current {
+						current = "*"
+					}
+					network := entry.Network
+					if network == "" {
+						network = "-"
+					}
+					fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, entry.Name, entry.CoordinatorURL, network)
+				}
+				return w.Flush()
+			})
+		},
+	}
+}
+
+// newProfileDeleteCmd creates the profile delete subcommand.
+func newProfileDeleteCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:               "delete <name>",
+		Short:             "Delete a profile and its saved login",
+		Long:              `Delete a profile and the wonder auth login saved for it, without ending the Keycloak session; run wonder auth logout --profile <name> first for that.`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: completeProfileNames,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			name := args[0]
+			profiles, err := loadCLIProfiles()
+			if err != nil {
+				return err
+			}
+			if _, ok := profiles.Profiles[name]; !ok {
+				return fmt.Errorf("no profile %q", name)
+			}
+			delete(profiles.Profiles, name)
+			if profiles.Current == name {
+				profiles.Current = ""
+			}
+			if err := saveCLIProfiles(profiles); err != nil {
+				return err
+			}
+
+			path, err := profileAuthLoginPath(name)
+			if err != nil {
+				return err
+			}
+			if err := os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+				return fmt.Errorf("remove saved login: %w", err)
+			}
+			fmt.Fprintf(os.Stderr, "Deleted profile %s.\n", name)
+			return nil
+		},
+	}
+}
+
+// completeProfileNames completes the names of the saved profiles.
+func completeProfileNames(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	profiles, err := loadCLIProfiles()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	return profiles.names(), cobra.ShellCompDirectiveNoFileComp
+}
+
+// names returns the profile names in order.
+func (p *cliProfiles) names() []string {
+	names := make([]string, 0, len(p.Profiles))
+	for name := range p.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// activeProfile returns the profile selected with --profile or
+// WONDER_PROFILE, or else the current one. It returns an empty name and
+// profile if none is selected.
+func activeProfile() (string, cliProfile, error) {
+	profiles, err := loadCLIProfiles()
+	if err != nil {
+		return "", cliProfile{}, err
+	}
+	name := profileFlag
+	if name == "" {
+		name = os.Getenv("WONDER_PROFILE")
+	}
+	if name == "" {
+		name = profiles.Current
+	}
+	if name == "" {
+		return "", cliProfile{}, nil
+	}
+	profile, ok := profiles.Profiles[name]
+	if !ok {
+		return "", cliProfile{}, fmt.Errorf("no profile %q, run wonder profile set %s", name, name)
+	}
+	return name, profile, nil
+}
+
+// cliConfigPath returns the config file the CLI read, or
+// ~/.wonder/config.yaml if there was none.
+func cliConfigPath() (string, error) {
+	if file := viper.ConfigFileUsed(); file != "" {
+		return file, nil
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get config path: %w", err)
+	}
+	return filepath.Join(home, ".wonder", "config.yaml"), nil
+}
+
+// loadCLIProfiles reads the profiles from the config file, returning none if
+// the file does not exist.
+func loadCLIProfiles() (*cliProfiles, error) {
+	profiles := &cliProfiles{}
+	path, err := cliConfigPath()
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("read config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, profiles); err != nil {
+		return nil, fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if profiles.Profiles == nil {
+		profiles.Profiles = map[string]cliProfile{}
+	}
+	return profiles, nil
+}
+
+// saveCLIProfiles writes the profiles to the config file, keeping its other
+// settings, such as those of wonder coordinator init. The file is written
+// readable only by the current user, as profiles may hold API keys.
+func saveCLIProfiles(profiles *cliProfiles) error {
+	path, err := cliConfigPath()
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return fmt.Errorf("read config: %w", err)
+	}
+
+	var doc yaml.Node
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return fmt.Errorf("parse config %s: %w", path, err)
+	}
+	if doc.Kind == 0 {
+		doc = yaml.Node{Kind: yaml.DocumentNode, Content: []*yaml.Node{{Kind: yaml.MappingNode, Tag: "!!map"}}}
+	}
+	root := doc.Content[0]
+	if root.Kind != yaml.MappingNode {
+		return fmt.Errorf("parse config %s: not a mapping", path)
+	}
+	if err := setYAMLKey(root, "current_profile", profiles.Current, profiles.Current == ""); err != nil {
+		return err
+	}
+	if err := setYAMLKey(root, "profiles", profiles.Profiles, len(profiles.Profiles) == 0); err != nil {
+		return err
+	}
+
+	data, err = yaml.Marshal(&doc)
+	if err != nil {
+		return fmt.Errorf("encode config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("create %s: %w", filepath.Dir(path), err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("save config: %w", err)
+	}
+	return os.Chmod(path, 0o600)
+}
+
+// setYAMLKey sets key of mapping to value, or removes it if remove is true.
+func setYAMLKey(mapping *yaml.Node, key string, value any, remove bool) error {
+	for i := 0; i+1 < len(mapping.Content); i += 2 {
+		if mapping.Content[i].Value != key {
+			continue
+		}
+		if remove {
+			mapping.Content = append(mapping.Content[:i], mapping.Content[i+2:]...)
+			return nil
+		}
+		return mapping.Content[i+1].Encode(value)
+	}
+	if remove {
+		return nil
+	}
+
+	var node yaml.Node
+	if err := node.Encode(value); err != nil {
+		return fmt.Errorf("encode %s: %w", key, err)
+	}
+	mapping.Content = append(mapping.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!str", Value: key}, &node)
+	return nil
+}
@@ -0,0 +1,117 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+func TestSaveCLIProfilesKeepsOtherSettings(t *testing.T) {
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	file := filepath.Join(home, ".wonder", "config.yaml")
+	if err := writeCoordinatorConfig(file, coordinatorFileConfig{}); err != nil {
+		t.Fatal(err)
+	}
+	before, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := saveCLIProfiles(&cliProfiles{Current: "prod", Profiles: map[string]cliProfile{
+		"prod": {CoordinatorURL: "https://mesh.example.com", Token: "wmn_prod"},
+	}}); err != nil {
+		t.Fatalf("saveCLIProfiles() error = %v", err)
+	}
+
+	after, err := os.ReadFile(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(string(after), string(before)) {
+		t.Errorf("config after saving profiles =\n%s\nwant it to start with the coordinator settings\n%s", after, before)
+	}
+	profiles, err := loadCLIProfiles()
+	if err != nil {
+		t.Fatalf("loadCLIProfiles() error = %v", err)
+	}
+	if profiles.Current != "prod" || profiles.Profiles["prod"].Token != "wmn_prod" {
+		t.Errorf("loadCLIProfiles() = %+v, want the saved prod profile", profiles)
+	}
+
+	if err := saveCLIProfiles(&cliProfiles{}); err != nil {
+		t.Fatalf("saveCLIProfiles() error = %v", err)
+	}
+	if cleared, _ := os.ReadFile(file); string(cleared) != string(before) {
+		t.Errorf("config after removing profiles =\n%s\nwant\n%s", cleared, before)
+	}
+}
+
+func TestNewTokenClientUsesProfile(t *testing.T) {
+	var gotAuth, gotNetwork string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuth, gotNetwork = r.Header.Get("Authorization"), r.Header.Get("X-Wonder-Net")
+		_ = json.NewEncoder(w).Encode(map[string]any{"nodes": []wondersdk.Node{}})
+	}))
+	defer server.Close()
+
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WONDER_TOKEN", "")
+	t.Setenv("WONDER_API_KEY", "")
+	t.Setenv("WONDER_PROFILE", "")
+	if err := saveCLIProfiles(&cliProfiles{Current: "prod", Profiles: map[string]cliProfile{
+		"prod":    {CoordinatorURL: "https://mesh.invalid", Token: "wmn_prod"},
+		"staging": {CoordinatorURL: server.URL, Network: "lab"},
+	}}); err != nil {
+		t.Fatal(err)
+	}
+	// staging has no API key, so it uses the login saved for it.
+	profileFlag = "staging"
+	defer func() { profileFlag = "" }()
+	if err := saveAuthLogin(&authLogin{AccessToken: "staging-session", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+
+	client, err := newTokenClient("", "")
+	if err != nil {
+		t.Fatalf("newTokenClient() error = %v", err)
+	}
+	if _, err := client.ListNodes(context.Background(), ""); err != nil {
+		t.Fatalf("ListNodes() error = %v", err)
+	}
+	if gotAuth != "Bearer staging-session" || gotNetwork != "lab" {
+		t.Errorf("request had Authorization %q and X-Wonder-Net %q, want staging's login and network", gotAuth, gotNetwork)
+	}
+
+	// The current profile, prod, has no saved login of its own.
+	profileFlag = ""
+	if _, err := loadAuthLogin(); !errors.Is(err, errNotLoggedIn) {
+		t.Errorf("loadAuthLogin() for prod error = %v, want %v", err, errNotLoggedIn)
+	}
+	if _, err := newTokenClient("", ""); err != nil {
+		t.Errorf("newTokenClient() for prod error = %v, want its API key to be used", err)
+	}
+}
+
+func TestActiveProfileUnknown(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+	t.Setenv("WONDER_PROFILE", "missing")
+	if _, _, err := activeProfile(); err == nil {
+		t.Error("activeProfile() succeeded for a missing profile, want error")
+	}
+
+	t.Setenv("WONDER_PROFILE", "")
+	name, _, err := activeProfile()
+	if err != nil || name != "" {
+		t.Errorf("activeProfile() without profiles = %q, %v, want no profile", name, err)
+	}
+}
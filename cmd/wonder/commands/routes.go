@@ -0,0 +1,118 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+// routesFlags holds the flags shared by the routes subcommands.
+var routesFlags struct {
+	coordinatorURL string
+	token          string
+}
+
+// NewRoutesCmd creates the routes command for approving subnet routers and
+// exit nodes.
+func NewRoutesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "routes",
+		Short: "Approve subnet routes and exit nodes",
+		Long: `List the subnet and exit routes advertised by the nodes of a wonder net, and
+approve or reject them. A node serves an advertised route only once it is
+approved. Approving either exit route of a node approves both address families.
+
+Listing works with an API key. Approving and rejecting require a user session
+token of the wonder net owner.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&routesFlags.coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.PersistentFlags().StringVar(&routesFlags.token, "token", "", "Session token or API key (env: WONDER_TOKEN or WONDER_API_KEY)")
+
+	cmd.AddCommand(newRoutesListCmd())
+	cmd.AddCommand(newRoutesUpdateCmd("approve", "Allow a node to serve a route", (*wondersdk.Client).ApproveRoute))
+	cmd.AddCommand(newRoutesUpdateCmd("reject", "Stop a node from serving a route", (*wondersdk.Client).RejectRoute))
+	return cmd
+}
+
+// newRoutesListCmd creates the routes list subcommand.
+func newRoutesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List advertised routes",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newTokenClient(routesFlags.coordinatorURL, routesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			routes, err := client.ListRoutes(ctx, "")
+			if err != nil {
+				return fmt.Errorf("list routes: %w", err)
+			}
+			return output.Print(os.Stdout, format, routes, func(out io.Writer) error {
+				if len(routes) == 0 {
+					_, err := fmt.Fprintln(out, "No routes advertised")
+					return err
+				}
+
+				w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+				fmt.Fprintln(w, "ID\tNODE\tPREFIX\tEXIT\tAPPROVED\tSERVING")
+				for _, route := range routes {
+					fmt.Fprintf(w, "%s\t%s\t%s\t%t\t%t\t%t\n", route.ID, route.NodeName, route.Prefix, route.ExitNode, route.Approved, route.Serving)
+				}
+				return w.Flush()
+			})
+		},
+	}
+}
+
+// newRoutesUpdateCmd creates the approve or reject subcommand.
+func newRoutesUpdateCmd(action, short string, update func(*wondersdk.Client, context.Context, string, string) (*wondersdk.Route, error)) *cobra.Command {
+	return &cobra.Command{
+		Use:   action + " <route-id>",
+		Short: short,
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			client, err := newTokenClient(routesFlags.coordinatorURL, routesFlags.token)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			route, err := update(client, ctx, "", args[0])
+			if err != nil {
+				return fmt.Errorf("%s route: %w", action, err)
+			}
+
+			return output.Print(os.Stdout, format, route, func(w io.Writer) error {
+				state := "not approved"
+				if route.Approved {
+					state = "approved"
+				}
+				_, err := fmt.Fprintf(w, "Route %s on %s is %s\n", route.Prefix, route.NodeName, state)
+				return err
+			})
+		},
+	}
+}
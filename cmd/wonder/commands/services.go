@@ -0,0 +1,113 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+// servicesFlags holds the flags shared by the services subcommands.
+var servicesFlags struct {
+	coordinatorURL string
+	apiKey         string
+}
+
+// NewServicesCmd creates the services command for inspecting the services
+// a wonder net publishes.
+func NewServicesCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "services",
+		Short: "Manage published services",
+		Long: `Inspect the services published in a wonder net. A service is a port on a
+node; only the subjects granted access to a service can reach it.`,
+	}
+
+	cmd.PersistentFlags().StringVar(&servicesFlags.coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.PersistentFlags().StringVar(&servicesFlags.apiKey, "api-key", "", "API key of the wonder net (env: WONDER_API_KEY)")
+
+	cmd.AddCommand(newServicesListCmd())
+	return cmd
+}
+
+// newServicesListCmd creates the services list subcommand.
+func newServicesListCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List published services",
+		Long: `List the services published in the wonder net of the API key, with the
+subjects that may reach each of them.
+
+Example:
+  wonder services list --coordinator-url https://coordinator.example.com --api-key wmn_...`,
+		RunE: runServicesList,
+	}
+}
+
+// runServicesList fetches the services from the coordinator and prints them
+// as a table, or in the format chosen with --output.
+func runServicesList(cmd *cobra.Command, args []string) error {
+	format, err := output.FromCommand(cmd)
+	if err != nil {
+		return err
+	}
+	_, profile, err := activeProfile()
+	if err != nil {
+		return err
+	}
+	coordinatorURL := servicesFlags.coordinatorURL
+	if coordinatorURL == "" {
+		coordinatorURL = profile.CoordinatorURL
+	}
+	if coordinatorURL == "" {
+		return fmt.Errorf("--coordinator-url or a profile with a coordinator URL is required")
+	}
+	apiKey := servicesFlags.apiKey
+	if apiKey == "" {
+		apiKey = os.Getenv("WONDER_API_KEY")
+	}
+	if apiKey == "" {
+		apiKey = profile.Token
+	}
+	if apiKey == "" {
+		return fmt.Errorf("--api-key, WONDER_API_KEY, or a profile with a token is required")
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+
+	client := wondersdk.NewClient(strings.TrimRight(coordinatorURL, "/")+"/coordinator", apiKey)
+	services, err := client.ListServices(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list services: %w", err)
+	}
+
+	return output.Print(os.Stdout, format, services, func(out io.Writer) error {
+		if len(services) == 0 {
+			_, err := fmt.Fprintln(out, "No services published")
+			return err
+		}
+
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tNODE\tPORT\tACCESS")
+		for _, svc := range services {
+			subjects := make([]string, len(svc.Grants))
+			for i, grant := range svc.Grants {
+				subjects[i] = grant.Subject
+			}
+			access := strings.Join(subjects, ",")
+			if access == "" {
+				access = "-"
+			}
+			fmt.Fprintf(w, "%s\t%s\t%d/%s\t%s\n", svc.Name, svc.NodeID, svc.Port, svc.Protocol, access)
+		}
+		return w.Flush()
+	})
+}
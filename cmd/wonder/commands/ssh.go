@@ -0,0 +1,417 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+	"golang.org/x/net/proxy"
+	"golang.org/x/term"
+)
+
+const (
+	sshDialTimeout        = 30 * time.Second
+	sshWindowPollInterval = 500 * time.Millisecond
+)
+
+// sshFlags holds the flags of the ssh command.
+type sshFlags struct {
+	coordinatorURL string
+	token          string
+	user           string
+	port           int
+	identityFile   string
+	socks5Proxy    string
+	configFile     string
+	knownHostsFile string
+	ipFamily       string
+}
+
+// NewSSHCmd creates the ssh command that opens a shell on a node over the mesh.
+func NewSSHCmd() *cobra.Command {
+	var flags sshFlags
+
+	cmd := &cobra.Command{
+		Use:   "ssh <node> [command...]",
+		Short: "Open an SSH session to a node over the mesh",
+		Long: `Open an interactive SSH session to a node, or run a command on it. The node
+is looked up by name or ID through the coordinator and reached on its mesh
+address, either directly or through a SOCKS5 proxy such as the one tailscaled
+offers in userspace networking mode.
+
+Per-node settings are read from ~/.wonder/ssh_config, which uses the layout of
+OpenSSH's ssh_config with the keywords User, Port, IdentityFile, and
+SOCKS5Proxy. Flags take precedence over the file.
+
+Keys from ssh-agent and the identity file are offered first, then a password
+is asked for. Host keys are remembered in ~/.wonder/known_hosts on first
+connect, and a changed host key aborts the connection.
+
+The node's IPv4 mesh address is used if it has one; --ip-family ipv6
+connects over IPv6 instead.
+
+Example:
+  wonder ssh --coordinator-url https://coordinator.example.com web-1
+  wonder ssh -l root --socks5 localhost:1055 web-1 uptime`,
+		Args: cobra.MinimumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runSSH(cmd.Context(), flags, args[0], args[1:])
+		},
+	}
+	cmd.Flags().SetInterspersed(false)
+
+	cmd.Flags().StringVar(&flags.coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.Flags().StringVar(&flags.token, "token", "", "Session token or API key (env: WONDER_TOKEN or WONDER_API_KEY)")
+	cmd.Flags().StringVarP(&flags.user, "user", "l", "", "Remote user (default: ssh_config, then the local user)")
+	cmd.Flags().IntVarP(&flags.port, "port", "p", 0, "Remote port (default: ssh_config, then 22)")
+	cmd.Flags().StringVarP(&flags.identityFile, "identity", "i", "", "Private key file")
+	cmd.Flags().StringVar(&flags.socks5Proxy, "socks5", "", "SOCKS5 proxy to reach the mesh through, e.g. localhost:1055")
+	cmd.Flags().StringVar(&flags.configFile, "ssh-config", "", "Per-node settings file (default ~/.wonder/ssh_config)")
+	cmd.Flags().StringVar(&flags.knownHostsFile, "known-hosts", "", "Known host keys file (default ~/.wonder/known_hosts)")
+	cmd.Flags().StringVar(&flags.ipFamily, "ip-family", "auto", "Mesh address to connect to: auto (IPv4 if available), ipv4, or ipv6")
+	return cmd
+}
+
+// runSSH resolves the node, connects to it, and runs a shell or command.
+func runSSH(ctx context.Context, flags sshFlags, nodeName string, command []string) error {
+	family, err := wondersdk.ParseIPFamily(flags.ipFamily)
+	if err != nil {
+		return err
+	}
+	configFile := flags.configFile
+	if configFile == "" {
+		if configFile, err = defaultSSHConfigPath(); err != nil {
+			return err
+		}
+	}
+	config, err := loadSSHConfig(configFile)
+	if err != nil {
+		return err
+	}
+	settings := mergeSSHSettings(flags, config.Lookup(nodeName))
+
+	client, err := newTokenClient(flags.coordinatorURL, flags.token)
+	if err != nil {
+		return err
+	}
+	lookupCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	nodes, err := client.ListNodes(lookupCtx, "")
+	cancel()
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	node, err := resolveSSHNode(nodes, nodeName)
+	if err != nil {
+		return err
+	}
+	if !node.Online {
+		fmt.Fprintf(os.Stderr, "Warning: node %s is offline\n", node.Name)
+	}
+	host, err := node.Address(family)
+	if err != nil {
+		return err
+	}
+	addr := net.JoinHostPort(host, strconv.Itoa(settings.Port))
+
+	knownHostsFile := flags.knownHostsFile
+	if knownHostsFile == "" {
+		knownHostsFile = filepath.Join(filepath.Dir(configFile), "known_hosts")
+	}
+	hostKeyCallback, err := knownHostsCallback(knownHostsFile)
+	if err != nil {
+		return err
+	}
+
+	auth, closeAgent := sshAuthMethods(settings.IdentityFile)
+	defer closeAgent()
+
+	conn, err := dialSSH(ctx, settings.SOCKS5Proxy, addr)
+	if err != nil {
+		return fmt.Errorf("connect to %s (%s): %w", node.Name, addr, err)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            settings.User,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("ssh handshake with %s: %w", node.Name, err)
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+	defer func() { _ = sshClient.Close() }()
+
+	exitCode, err := runSSHSession(sshClient, strings.Join(command, " "))
+	if err != nil {
+		return err
+	}
+	if exitCode != 0 {
+		os.Exit(exitCode)
+	}
+	return nil
+}
+
+// mergeSSHSettings applies the defaults and flag overrides to the settings
+// read from the ssh config file.
+func mergeSSHSettings(flags sshFlags, settings sshHostConfig) sshHostConfig {
+	if flags.user != "" {
+		settings.User = flags.user
+	}
+	if settings.User == "" {
+		if u, err := user.Current(); err == nil {
+			settings.User = u.Username
+		}
+	}
+	if flags.port != 0 {
+		settings.Port = flags.port
+	}
+	if settings.Port == 0 {
+		settings.Port = 22
+	}
+	if flags.identityFile != "" {
+		settings.IdentityFile = flags.identityFile
+	}
+	if flags.socks5Proxy != "" {
+		settings.SOCKS5Proxy = flags.socks5Proxy
+	}
+	return settings
+}
+
+// resolveSSHNode finds a node by name, falling back to its numeric ID.
+func resolveSSHNode(nodes []wondersdk.Node, name string) (*wondersdk.Node, error) {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i], nil
+		}
+	}
+	if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+		for i := range nodes {
+			if nodes[i].ID == id {
+				return &nodes[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("node %q not found", name)
+}
+
+// dialSSH opens a TCP connection to addr, through socks5Proxy if set.
+func dialSSH(ctx context.Context, socks5Proxy, addr string) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, sshDialTimeout)
+	defer cancel()
+
+	if socks5Proxy == "" {
+		var d net.Dialer
+		return d.DialContext(ctx, "tcp", addr)
+	}
+
+	dialer, err := proxy.SOCKS5("tcp", socks5Proxy, nil, &net.Dialer{})
+	if err != nil {
+		return nil, fmt.Errorf("create SOCKS5 dialer: %w", err)
+	}
+	if contextDialer, ok := dialer.(proxy.ContextDialer); ok {
+		return contextDialer.DialContext(ctx, "tcp", addr)
+	}
+	return dialer.Dial("tcp", addr)
+}
+
+// sshAuthMethods returns the authentication methods to try: keys held by
+// ssh-agent, the identity file or the default keys in ~/.ssh, and finally a
+// password prompt. The returned function closes the agent connection.
+func sshAuthMethods(identityFile string) ([]ssh.AuthMethod, func()) {
+	var methods []ssh.AuthMethod
+	closeAgent := func() {}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		if conn, err := net.Dial("unix", sock); err == nil {
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+			closeAgent = func() { _ = conn.Close() }
+		}
+	}
+
+	identityFiles := []string{identityFile}
+	if identityFile == "" {
+		identityFiles = []string{"~/.ssh/id_ed25519", "~/.ssh/id_ecdsa", "~/.ssh/id_rsa"}
+	}
+	var signers []ssh.Signer
+	for _, file := range identityFiles {
+		signer, err := loadSSHSigner(expandHome(file))
+		if err != nil {
+			if identityFile != "" || !errors.Is(err, os.ErrNotExist) {
+				fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+			}
+			continue
+		}
+		signers = append(signers, signer)
+	}
+	if len(signers) > 0 {
+		methods = append(methods, ssh.PublicKeys(signers...))
+	}
+
+	if term.IsTerminal(int(os.Stdin.Fd())) {
+		methods = append(methods, ssh.PasswordCallback(func() (string, error) {
+			fmt.Fprint(os.Stderr, "Password: ")
+			password, err := term.ReadPassword(int(os.Stdin.Fd()))
+			fmt.Fprintln(os.Stderr)
+			return string(password), err
+		}))
+	}
+	return methods, closeAgent
+}
+
+// loadSSHSigner reads a private key, asking for its passphrase if needed.
+func loadSSHSigner(filename string) (ssh.Signer, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("read identity file: %w", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(data)
+	var missing *ssh.PassphraseMissingError
+	if errors.As(err, &missing) && term.IsTerminal(int(os.Stdin.Fd())) {
+		fmt.Fprintf(os.Stderr, "Enter passphrase for %s: ", filename)
+		passphrase, readErr := term.ReadPassword(int(os.Stdin.Fd()))
+		fmt.Fprintln(os.Stderr)
+		if readErr != nil {
+			return nil, fmt.Errorf("read passphrase: %w", readErr)
+		}
+		signer, err = ssh.ParsePrivateKeyWithPassphrase(data, passphrase)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("parse identity file %s: %w", filename, err)
+	}
+	return signer, nil
+}
+
+// knownHostsCallback verifies host keys against a known_hosts file and
+// records the key of a host seen for the first time. A host whose key
+// differs from the recorded one is rejected.
+func knownHostsCallback(filename string) (ssh.HostKeyCallback, error) {
+	if err := os.MkdirAll(filepath.Dir(filename), 0o700); err != nil {
+		return nil, fmt.Errorf("create known hosts directory: %w", err)
+	}
+	f, err := os.OpenFile(filename, os.O_CREATE|os.O_RDONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("open known hosts: %w", err)
+	}
+	_ = f.Close()
+
+	check, err := knownhosts.New(filename)
+	if err != nil {
+		return nil, fmt.Errorf("load known hosts: %w", err)
+	}
+
+	return func(hostname string, remote net.Addr, key ssh.PublicKey) error {
+		err := check(hostname, remote, key)
+		var keyErr *knownhosts.KeyError
+		if !errors.As(err, &keyErr) || len(keyErr.Want) > 0 {
+			return err
+		}
+
+		f, err := os.OpenFile(filename, os.O_APPEND|os.O_WRONLY, 0o600)
+		if err != nil {
+			return fmt.Errorf("open known hosts: %w", err)
+		}
+		defer func() { _ = f.Close() }()
+		if _, err := fmt.Fprintln(f, knownhosts.Line([]string{knownhosts.Normalize(hostname)}, key)); err != nil {
+			return fmt.Errorf("record host key: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Permanently added %s (%s) to %s\n", hostname, key.Type(), filename)
+		return nil
+	}, nil
+}
+
+// runSSHSession runs command on the client, or an interactive shell if
+// command is empty, and returns the remote exit code. A PTY is requested
+// when stdin is a terminal, which is then put in raw mode for the session.
+func runSSHSession(client *ssh.Client, command string) (int, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("open session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	session.Stdin = os.Stdin
+	session.Stdout = os.Stdout
+	session.Stderr = os.Stderr
+
+	fd := int(os.Stdin.Fd())
+	if term.IsTerminal(fd) {
+		width, height, err := term.GetSize(fd)
+		if err != nil {
+			width, height = 80, 24
+		}
+		termType := os.Getenv("TERM")
+		if termType == "" {
+			termType = "xterm-256color"
+		}
+		if err := session.RequestPty(termType, height, width, ssh.TerminalModes{ssh.ECHO: 1}); err != nil {
+			return 0, fmt.Errorf("request pty: %w", err)
+		}
+
+		state, err := term.MakeRaw(fd)
+		if err != nil {
+			return 0, fmt.Errorf("set terminal raw mode: %w", err)
+		}
+		defer func() { _ = term.Restore(fd, state) }()
+
+		done := make(chan struct{})
+		defer close(done)
+		go watchWindowSize(session, fd, width, height, done)
+	}
+
+	if command == "" {
+		if err := session.Shell(); err != nil {
+			return 0, fmt.Errorf("start shell: %w", err)
+		}
+		err = session.Wait()
+	} else {
+		err = session.Run(command)
+	}
+
+	var exitErr *ssh.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitStatus(), nil
+	}
+	var missingErr *ssh.ExitMissingError
+	if errors.As(err, &missingErr) {
+		return 255, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("ssh session: %w", err)
+	}
+	return 0, nil
+}
+
+// watchWindowSize forwards terminal size changes to the session until done
+// is closed. The size is polled so that this works the same on every
+// platform.
+func watchWindowSize(session *ssh.Session, fd, width, height int, done <-chan struct{}) {
+	ticker := time.NewTicker(sshWindowPollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-ticker.C:
+			w, h, err := term.GetSize(fd)
+			if err != nil || (w == width && h == height) {
+				continue
+			}
+			width, height = w, h
+			_ = session.WindowChange(height, width)
+		}
+	}
+}
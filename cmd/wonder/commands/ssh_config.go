@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// sshHostConfig holds the per-node settings for wonder ssh. Zero values mean
+// the setting was not given.
+type sshHostConfig struct {
+	User         string
+	Port         int
+	IdentityFile string
+	SOCKS5Proxy  string
+}
+
+// sshConfigBlock is a Host block of the ssh config file.
+type sshConfigBlock struct {
+	patterns []string
+	config   sshHostConfig
+}
+
+// sshConfig is a parsed ~/.wonder/ssh_config file. It follows the layout of
+// OpenSSH's ssh_config: settings are grouped under "Host" lines listing node
+// name patterns, and for each setting the first matching block that sets it
+// wins. Supported keywords are User, Port, IdentityFile, and SOCKS5Proxy.
+//
+//	Host db-*
+//	    User postgres
+//	    IdentityFile ~/.ssh/db_ed25519
+//
+//	Host *
+//	    User ubuntu
+//	    SOCKS5Proxy localhost:1055
+type sshConfig struct {
+	blocks []sshConfigBlock
+}
+
+// defaultSSHConfigPath returns ~/.wonder/ssh_config.
+func defaultSSHConfigPath() (string, error) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("get home directory: %w", err)
+	}
+	return filepath.Join(home, ".wonder", "ssh_config"), nil
+}
+
+// loadSSHConfig reads an ssh config file. A missing file yields an empty config.
+func loadSSHConfig(filename string) (*sshConfig, error) {
+	f, err := os.Open(filename)
+	if errors.Is(err, os.ErrNotExist) {
+		return &sshConfig{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("open ssh config: %w", err)
+	}
+	defer func() { _ = f.Close() }()
+
+	config, err := parseSSHConfig(f)
+	if err != nil {
+		return nil, fmt.Errorf("parse %s: %w", filename, err)
+	}
+	return config, nil
+}
+
+// parseSSHConfig parses the ssh config format described on sshConfig.
+// Keywords are case-insensitive and may be separated from their value by
+// whitespace or "=". Settings before the first Host line apply to all nodes.
+func parseSSHConfig(r io.Reader) (*sshConfig, error) {
+	config := &sshConfig{blocks: []sshConfigBlock{{patterns: []string{"*"}}}}
+	current := &config.blocks[0]
+
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields := strings.FieldsFunc(line, func(r rune) bool {
+			return r == ' ' || r == '\t' || r == '='
+		})
+		keyword := strings.ToLower(fields[0])
+		value := strings.Join(fields[1:], " ")
+		if value == "" {
+			return nil, fmt.Errorf("line %d: missing value for %s", lineNo, fields[0])
+		}
+
+		switch keyword {
+		case "host":
+			config.blocks = append(config.blocks, sshConfigBlock{patterns: strings.Fields(value)})
+			current = &config.blocks[len(config.blocks)-1]
+		case "user":
+			current.config.User = value
+		case "port":
+			port, err := strconv.Atoi(value)
+			if err != nil || port < 1 || port > 65535 {
+				return nil, fmt.Errorf("line %d: invalid port %q", lineNo, value)
+			}
+			current.config.Port = port
+		case "identityfile":
+			current.config.IdentityFile = value
+		case "socks5proxy":
+			current.config.SOCKS5Proxy = value
+		default:
+			return nil, fmt.Errorf("line %d: unknown keyword %q", lineNo, keyword)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return config, nil
+}
+
+// Lookup returns the settings for a node. For each setting, the first block
+// whose patterns match the node name and that sets it wins.
+func (c *sshConfig) Lookup(node string) sshHostConfig {
+	var result sshHostConfig
+	for _, block := range c.blocks {
+		if !matchSSHHost(block.patterns, node) {
+			continue
+		}
+		if result.User == "" {
+			result.User = block.config.User
+		}
+		if result.Port == 0 {
+			result.Port = block.config.Port
+		}
+		if result.IdentityFile == "" {
+			result.IdentityFile = block.config.IdentityFile
+		}
+		if result.SOCKS5Proxy == "" {
+			result.SOCKS5Proxy = block.config.SOCKS5Proxy
+		}
+	}
+	return result
+}
+
+// matchSSHHost reports whether node matches any of the glob patterns.
+// A pattern starting with "!" excludes the node even if another pattern
+// matches.
+func matchSSHHost(patterns []string, node string) bool {
+	matched := false
+	for _, pattern := range patterns {
+		negate := strings.HasPrefix(pattern, "!")
+		pattern = strings.TrimPrefix(pattern, "!")
+		ok, err := path.Match(pattern, node)
+		if err != nil || !ok {
+			continue
+		}
+		if negate {
+			return false
+		}
+		matched = true
+	}
+	return matched
+}
+
+// expandHome replaces a leading "~/" in p with the home directory.
+func expandHome(p string) string {
+	if !strings.HasPrefix(p, "~/") {
+		return p
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return p
+	}
+	return filepath.Join(home, p[2:])
+}
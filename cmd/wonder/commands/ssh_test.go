@@ -0,0 +1,137 @@
+package commands
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"net"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+	"golang.org/x/crypto/ssh"
+)
+
+func TestSSHConfigLookup(t *testing.T) {
+	config, err := parseSSHConfig(strings.NewReader(`
+# defaults for every node
+SOCKS5Proxy localhost:1055
+
+Host db-* !db-legacy
+    User postgres
+    IdentityFile=~/.ssh/db_ed25519
+
+Host *
+    User ubuntu
+    Port 2222
+`))
+	if err != nil {
+		t.Fatalf("parseSSHConfig() error = %v", err)
+	}
+
+	tests := []struct {
+		node string
+		want sshHostConfig
+	}{
+		{node: "db-1", want: sshHostConfig{User: "postgres", Port: 2222, IdentityFile: "~/.ssh/db_ed25519", SOCKS5Proxy: "localhost:1055"}},
+		{node: "db-legacy", want: sshHostConfig{User: "ubuntu", Port: 2222, SOCKS5Proxy: "localhost:1055"}},
+		{node: "web-1", want: sshHostConfig{User: "ubuntu", Port: 2222, SOCKS5Proxy: "localhost:1055"}},
+	}
+
+	for _, tt := range tests {
+		if got := config.Lookup(tt.node); got != tt.want {
+			t.Errorf("Lookup(%q) = %+v, want %+v", tt.node, got, tt.want)
+		}
+	}
+}
+
+func TestParseSSHConfig_Errors(t *testing.T) {
+	tests := []string{
+		"Port 0",
+		"Port ssh",
+		"User",
+		"ProxyJump bastion",
+	}
+
+	for _, input := range tests {
+		if _, err := parseSSHConfig(strings.NewReader(input)); err == nil {
+			t.Errorf("parseSSHConfig(%q) error = nil, want error", input)
+		}
+	}
+}
+
+func TestResolveSSHNode(t *testing.T) {
+	nodes := []wondersdk.Node{
+		{ID: 1, Name: "web-1", Addresses: []string{"fd7a:115c:a1e0::1", "100.64.0.1"}},
+		{ID: 2, Name: "3", Addresses: []string{"100.64.0.2"}},
+		{ID: 3, Name: "db-1"},
+	}
+
+	tests := []struct {
+		name     string
+		wantID   uint64
+		wantAddr string
+		wantErr  bool
+	}{
+		{name: "web-1", wantID: 1, wantAddr: "100.64.0.1"},
+		{name: "3", wantID: 2, wantAddr: "100.64.0.2"},
+		{name: "1", wantID: 1, wantAddr: "100.64.0.1"},
+		{name: "db-1", wantID: 3, wantErr: true},
+		{name: "missing", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		node, err := resolveSSHNode(nodes, tt.name)
+		if err == nil {
+			if node.ID != tt.wantID {
+				t.Errorf("resolveSSHNode(%q) = node %d, want %d", tt.name, node.ID, tt.wantID)
+			}
+			var addr string
+			addr, err = node.Address(wondersdk.IPFamilyAuto)
+			if err == nil && addr != tt.wantAddr {
+				t.Errorf("Address(%q) = %q, want %q", tt.name, addr, tt.wantAddr)
+			}
+		}
+		if (err != nil) != tt.wantErr {
+			t.Errorf("resolve %q error = %v, wantErr %v", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestKnownHostsCallback(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "wonder", "known_hosts")
+	remote := &net.TCPAddr{IP: net.ParseIP("100.64.0.1"), Port: 22}
+
+	first := newTestHostKey(t)
+	callback, err := knownHostsCallback(filename)
+	if err != nil {
+		t.Fatalf("knownHostsCallback() error = %v", err)
+	}
+	if err := callback("100.64.0.1:22", remote, first); err != nil {
+		t.Fatalf("first connect error = %v, want host key recorded", err)
+	}
+
+	callback, err = knownHostsCallback(filename)
+	if err != nil {
+		t.Fatalf("knownHostsCallback() error = %v", err)
+	}
+	if err := callback("100.64.0.1:22", remote, first); err != nil {
+		t.Errorf("reconnect with same key error = %v, want nil", err)
+	}
+	if err := callback("100.64.0.1:22", remote, newTestHostKey(t)); err == nil {
+		t.Errorf("reconnect with changed key error = nil, want error")
+	}
+}
+
+func newTestHostKey(t *testing.T) ssh.PublicKey {
+	t.Helper()
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("convert key: %v", err)
+	}
+	return key
+}
@@ -0,0 +1,378 @@
+package commands
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/skip2/go-qrcode"
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+	"github.com/strrl/wonder-mesh-net/pkg/apikey"
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+const (
+	tokenTypeJoin   = "join token"
+	tokenTypeAPIKey = "api key"
+
+	tokenStatusActive    = "active"
+	tokenStatusExpired   = "expired"
+	tokenStatusRevoked   = "revoked"
+	tokenStatusNoNetwork = "wonder net not found"
+)
+
+// tokenInspection is what wonder token inspect reports about a join token
+// or API key, and the schema of its JSON and YAML output. Status is only set
+// when the coordinator was asked.
+type tokenInspection struct {
+	Type           string     `json:"type"`
+	Fingerprint    string     `json:"fingerprint,omitempty"`
+	KeyPrefix      string     `json:"key_prefix,omitempty"`
+	Issuer         string     `json:"issuer,omitempty"`
+	CoordinatorURL string     `json:"coordinator_url,omitempty"`
+	WonderNetID    string     `json:"wonder_net_id,omitempty"`
+	IssuedAt       *time.Time `json:"issued_at,omitempty"`
+	ExpiresAt      *time.Time `json:"expires_at,omitempty"`
+	Expired        bool       `json:"expired"`
+	Status         string     `json:"status,omitempty"`
+	StatusDetail   string     `json:"status_detail,omitempty"`
+}
+
+// NewTokenCmd creates the token command for creating join tokens and
+// inspecting join tokens and API keys.
+func NewTokenCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "token",
+		Short: "Create join tokens and inspect join tokens and API keys",
+	}
+	cmd.AddCommand(newTokenCreateCmd())
+	cmd.AddCommand(newTokenInspectCmd())
+	return cmd
+}
+
+// newTokenCreateCmd creates the token create subcommand.
+func newTokenCreateCmd() *cobra.Command {
+	var coordinatorURL, sessionToken string
+	var opts wondersdk.CreateJoinTokenOptions
+	var showQR, cloudInit bool
+	var cloudInitOS, binaryURL string
+
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a join token for wonder worker join",
+		Long: `Create a join token for a wonder net. Only the token is printed, so that it
+can be captured by a script:
+
+  wonder worker join "$(wonder token create --coordinator-url https://wonder.example.com)"
+
+The token is valid for the wonder net's default TTL, 8 hours unless its
+owner changed it, or for --ttl, up to the wonder net's maximum TTL.
+
+With --qr, a QR code of the join payload, a wonder://join URI with the
+coordinator URL and the token, is printed above the token, for phones and
+kiosk machines that scan it instead of typing the token. wonder worker join
+also accepts the scanned payload in place of the token.
+
+With --cloud-init, a cloud-config is printed instead, to paste as the
+user-data of a new VM. At first boot it installs Tailscale and the wonder
+binary from --binary-url, where {arch} becomes amd64 or arm64, and runs
+wonder worker install with the token, so the VM joins the mesh and keeps the
+worker daemon running across reboots. --os selects the OS family of the
+image: debian (Debian and Ubuntu) or rhel (RHEL, Rocky, AlmaLinux, and
+Fedora). The VM must boot before the token expires, and each VM uses one of
+its --max-uses:
+
+  wonder token create --cloud-init --max-uses 5 \
+    --binary-url 'https://downloads.example.com/wonder-linux-{arch}' > user-data.yaml`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if cloudInit {
+				if err := validateCloudInitOptions(binaryURL, cloudInitOS); err != nil {
+					return err
+				}
+			}
+			client, err := newTokenClient(coordinatorURL, sessionToken)
+			if err != nil {
+				return err
+			}
+
+			ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+			defer cancel()
+
+			token, err := client.CreateJoinToken(ctx, "", opts)
+			if err != nil {
+				return fmt.Errorf("create join token: %w", err)
+			}
+			return output.Print(os.Stdout, format, token, func(w io.Writer) error {
+				if cloudInit {
+					userData, err := renderCloudInit(token.Token, binaryURL, cloudInitOS)
+					if err != nil {
+						return err
+					}
+					_, err = io.WriteString(w, userData)
+					return err
+				}
+				if showQR {
+					if err := printJoinTokenQR(w, token.Token); err != nil {
+						return err
+					}
+				}
+				_, err := fmt.Fprintln(w, token.Token)
+				return err
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the profile's)")
+	cmd.Flags().StringVar(&sessionToken, "token", "", "Session token or API key with the tokens:create scope (env: WONDER_TOKEN or WONDER_API_KEY)")
+	cmd.Flags().StringVar(&opts.Network, "network", "", "Wonder net ID or display name (default: the profile's, else your default wonder net)")
+	cmd.Flags().DurationVar(&opts.TTL, "ttl", 0, "How long the token is valid, e.g. 72h (default: the wonder net's default TTL)")
+	cmd.Flags().IntVar(&opts.MaxUses, "max-uses", 0, "Number of workers that may join with the token (default 1)")
+	cmd.Flags().StringArrayVar(&opts.AllowedCIDRs, "allowed-cidr", nil, "Only accept joins from this address range (repeatable)")
+	cmd.Flags().BoolVar(&showQR, "qr", false, "Also print a QR code of the join payload to scan on the enrolling device")
+	cmd.Flags().BoolVar(&cloudInit, "cloud-init", false, "Print a cloud-config that joins a new VM at first boot instead of the token")
+	cmd.Flags().StringVar(&cloudInitOS, "os", cloudInitOSDebian, "OS family of the VM image with --cloud-init: debian or rhel")
+	cmd.Flags().StringVar(&binaryURL, "binary-url", "", "URL the VM downloads the wonder binary from with --cloud-init; {arch} becomes amd64 or arm64")
+	cmd.MarkFlagsMutuallyExclusive("qr", "cloud-init")
+	return cmd
+}
+
+// printJoinTokenQR prints a QR code of a join token's join payload, with
+// the coordinator URL from the token's claims.
+func printJoinTokenQR(w io.Writer, token string) error {
+	claims, err := jointoken.ParseUnsafe(token)
+	if err != nil {
+		return err
+	}
+	qr, err := qrcode.New(jointoken.Payload(claims.CoordinatorURL, token), qrcode.Low)
+	if err != nil {
+		return fmt.Errorf("encode QR code: %w", err)
+	}
+	_, err = io.WriteString(w, qr.ToSmallString(false))
+	return err
+}
+
+// newTokenInspectCmd creates the token inspect subcommand.
+func newTokenInspectCmd() *cobra.Command {
+	var coordinatorURL, sessionToken string
+	var jsonOutput bool
+
+	cmd := &cobra.Command{
+		Use:   "inspect <join-token|api-key|api-key-prefix>",
+		Short: "Decode a join token or API key and check whether it is still valid",
+		Long: `Decode a join token or API key locally and print its claims, expiry, and
+fingerprint. The fingerprint is the start of the SHA-256 hash of the token, so
+it can be shared in place of the token itself. Join token signatures are not
+verified, as that needs the coordinator's signing key.
+
+With a session token (--token or WONDER_TOKEN), the coordinator is also asked
+whether the token is still valid. An API key, or just its prefix, is looked up
+in all of your wonder nets; a key that is no longer listed has been revoked.
+Join tokens cannot be revoked one by one, so for them the check is that their
+wonder net still exists.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			if jsonOutput {
+				format = output.JSON
+			}
+
+			inspection, err := inspectToken(args[0], time.Now())
+			if err != nil {
+				return err
+			}
+
+			if sessionToken == "" {
+				sessionToken = os.Getenv("WONDER_TOKEN")
+			}
+			if coordinatorURL == "" {
+				coordinatorURL = inspection.CoordinatorURL
+			}
+			if sessionToken != "" && coordinatorURL != "" {
+				client := wondersdk.NewClient(strings.TrimRight(coordinatorURL, "/")+"/coordinator", sessionToken)
+				ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+				defer cancel()
+				if err := checkTokenStatus(ctx, client, inspection, time.Now()); err != nil {
+					return fmt.Errorf("check token status: %w", err)
+				}
+			}
+
+			return output.Print(os.Stdout, format, inspection, func(w io.Writer) error {
+				return printTokenInspection(w, inspection)
+			})
+		},
+	}
+
+	cmd.Flags().StringVar(&coordinatorURL, "coordinator-url", "", "Coordinator URL for the status check (default: from the join token)")
+	cmd.Flags().StringVar(&sessionToken, "token", "", "Session token for the status check (env: WONDER_TOKEN)")
+	cmd.Flags().BoolVar(&jsonOutput, "json", false, "Print the result as JSON")
+	_ = cmd.Flags().MarkDeprecated("json", "use --output json instead")
+	return cmd
+}
+
+// inspectToken decodes a join token, full API key, or API key prefix
+// without contacting the coordinator.
+func inspectToken(raw string, now time.Time) (*tokenInspection, error) {
+	raw = strings.TrimSpace(raw)
+	if apikey.IsAPIKey(raw) {
+		return inspectAPIKey(raw)
+	}
+
+	claims, err := jointoken.ParseUnsafe(raw)
+	if err != nil {
+		return nil, fmt.Errorf("not an API key or join token: %w", err)
+	}
+
+	inspection := &tokenInspection{
+		Type:           tokenTypeJoin,
+		Fingerprint:    tokenFingerprint(raw),
+		Issuer:         claims.Issuer,
+		CoordinatorURL: claims.CoordinatorURL,
+		WonderNetID:    claims.WonderNetID,
+	}
+	if claims.IssuedAt != nil {
+		inspection.IssuedAt = &claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		inspection.ExpiresAt = &claims.ExpiresAt.Time
+		inspection.Expired = !now.Before(claims.ExpiresAt.Time)
+	}
+	return inspection, nil
+}
+
+// inspectAPIKey decodes a full API key or the prefix shown by the
+// coordinator, with or without the trailing "...".
+func inspectAPIKey(raw string) (*tokenInspection, error) {
+	prefix := strings.TrimSuffix(raw, "...")
+	if len(prefix) < apikey.PrefixDisplayLength {
+		return nil, fmt.Errorf("API key prefix must be at least %d characters", apikey.PrefixDisplayLength)
+	}
+	if _, err := hex.DecodeString(strings.TrimPrefix(prefix, apikey.Prefix)); err != nil {
+		return nil, fmt.Errorf("API key must be %q followed by hex characters", apikey.Prefix)
+	}
+
+	inspection := &tokenInspection{
+		Type:      tokenTypeAPIKey,
+		KeyPrefix: prefix[:apikey.PrefixDisplayLength] + "...",
+	}
+	if len(prefix) == len(apikey.Prefix)+2*apikey.KeyLength {
+		inspection.Fingerprint = tokenFingerprint(prefix)
+	}
+	return inspection, nil
+}
+
+// tokenFingerprint returns the first 16 hex characters of the SHA-256 hash
+// of a token. For API keys this is the start of the hash the coordinator
+// stores.
+func tokenFingerprint(raw string) string {
+	sum := sha256.Sum256([]byte(raw))
+	return "sha256:" + hex.EncodeToString(sum[:])[:16]
+}
+
+// checkTokenStatus asks the coordinator whether the inspected token is
+// still valid and records the answer in inspection.
+func checkTokenStatus(ctx context.Context, client *wondersdk.Client, inspection *tokenInspection, now time.Time) error {
+	wonderNets, err := client.ListWonderNets(ctx, "")
+	if err != nil {
+		return fmt.Errorf("list wonder nets: %w", err)
+	}
+
+	if inspection.Type == tokenTypeJoin {
+		for _, wn := range wonderNets {
+			if wn.ID != inspection.WonderNetID {
+				continue
+			}
+			inspection.Status = tokenStatusActive
+			if inspection.Expired {
+				inspection.Status = tokenStatusExpired
+			}
+			inspection.StatusDetail = "wonder net " + wn.DisplayName + " exists"
+			return nil
+		}
+		inspection.Status = tokenStatusNoNetwork
+		inspection.StatusDetail = "the wonder net was deleted or is not yours"
+		return nil
+	}
+
+	for _, wn := range wonderNets {
+		keys, err := client.ListAPIKeys(ctx, "", wn.ID)
+		if err != nil {
+			return fmt.Errorf("list API keys of %s: %w", wn.DisplayName, err)
+		}
+		for _, key := range keys {
+			if key.KeyPrefix != inspection.KeyPrefix {
+				continue
+			}
+			inspection.WonderNetID = wn.ID
+			inspection.ExpiresAt = key.ExpiresAt
+			inspection.Expired = key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)
+			inspection.Status = tokenStatusActive
+			if inspection.Expired {
+				inspection.Status = tokenStatusExpired
+			}
+			inspection.StatusDetail = fmt.Sprintf("key %q in wonder net %s", key.Name, wn.DisplayName)
+			if key.Stale {
+				inspection.StatusDetail += ", unused for 90 days"
+			}
+			return nil
+		}
+	}
+	inspection.Status = tokenStatusRevoked
+	inspection.StatusDetail = "no key with this prefix in your wonder nets"
+	return nil
+}
+
+// printTokenInspection prints an inspection as aligned key-value lines.
+func printTokenInspection(out io.Writer, inspection *tokenInspection) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	field := func(name, value string) {
+		if value != "" {
+			fmt.Fprintf(w, "%s:\t%s\n", name, value)
+		}
+	}
+	timeField := func(name string, t *time.Time) {
+		if t != nil {
+			field(name, t.Local().Format(time.RFC3339))
+		}
+	}
+
+	field("Type", inspection.Type)
+	field("Key prefix", inspection.KeyPrefix)
+	field("Fingerprint", inspection.Fingerprint)
+	field("Issuer", inspection.Issuer)
+	field("Coordinator", inspection.CoordinatorURL)
+	field("Wonder net", inspection.WonderNetID)
+	timeField("Issued", inspection.IssuedAt)
+	timeField("Expires", inspection.ExpiresAt)
+	if inspection.ExpiresAt != nil {
+		field("Expired", fmt.Sprint(inspection.Expired))
+	}
+	if inspection.Type == tokenTypeJoin {
+		field("Signature", "not verified (needs the coordinator's signing key)")
+	}
+
+	switch {
+	case inspection.Status == "":
+		field("Status", "not checked (needs --token or WONDER_TOKEN, and --coordinator-url for API keys)")
+	case inspection.StatusDetail != "":
+		field("Status", inspection.Status+" ("+inspection.StatusDetail+")")
+	default:
+		field("Status", inspection.Status)
+	}
+	return w.Flush()
+}
@@ -0,0 +1,109 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+)
+
+// OS families wonder token create --cloud-init writes user-data for.
+const (
+	cloudInitOSDebian = "debian"
+	cloudInitOSRHEL   = "rhel"
+)
+
+// cloudInitArchPlaceholder in a --binary-url is replaced at boot with the
+// Go architecture of the VM, amd64 or arm64.
+const cloudInitArchPlaceholder = "{arch}"
+
+// cloudInitJoinScript is where the user-data writes the script that joins
+// the VM. The script removes itself after a successful join, since it
+// holds the join token.
+const cloudInitJoinScript = "/usr/local/sbin/wonder-join"
+
+// renderCloudInit returns a cloud-config that installs Tailscale and the
+// wonder binary from binaryURL, then joins the VM with token through
+// wonder worker install, which also enables tailscaled and the worker
+// daemon at boot.
+func renderCloudInit(token, binaryURL, osFamily string) (string, error) {
+	if err := validateCloudInitOptions(binaryURL, osFamily); err != nil {
+		return "", err
+	}
+
+	// RHEL images ship curl-minimal, which installing curl would conflict
+	// with, so only Debian images get packages.
+	var packages []string
+	if osFamily == cloudInitOSDebian {
+		packages = []string{"ca-certificates", "curl"}
+	}
+
+	var b strings.Builder
+	b.WriteString("#cloud-config\n")
+	if claims, err := jointoken.ParseUnsafe(token); err == nil {
+		fmt.Fprintf(&b, "# Joins this VM to wonder net %s at %s on first boot.\n", claims.WonderNetID, claims.CoordinatorURL)
+		if claims.ExpiresAt != nil {
+			fmt.Fprintf(&b, "# The join token expires at %s; boot the VM before then.\n", claims.ExpiresAt.UTC().Format(time.RFC3339))
+		}
+	}
+	if len(packages) > 0 {
+		b.WriteString("package_update: true\npackages:\n")
+		for _, p := range packages {
+			fmt.Fprintf(&b, "  - %s\n", p)
+		}
+	}
+
+	downloadURL := strings.ReplaceAll(binaryURL, cloudInitArchPlaceholder, "${arch}")
+	script := []string{
+		"#!/bin/sh",
+		"set -eu",
+		"export HOME=/root",
+		`case "$(uname -m)" in`,
+		"  x86_64) arch=amd64 ;;",
+		"  aarch64|arm64) arch=arm64 ;;",
+		`  *) echo "unsupported architecture: $(uname -m)" >&2; exit 1 ;;`,
+		"esac",
+		"curl -fsSL https://tailscale.com/install.sh | sh",
+		fmt.Sprintf(`curl -fsSL -o /usr/local/bin/wonder "%s"`, downloadURL),
+		"chmod 0755 /usr/local/bin/wonder",
+		"/usr/local/bin/wonder worker install --non-interactive " + shellQuote(token),
+		`rm -f "$0"`,
+	}
+
+	b.WriteString("write_files:\n")
+	fmt.Fprintf(&b, "  - path: %s\n", cloudInitJoinScript)
+	b.WriteString("    permissions: \"0700\"\n")
+	b.WriteString("    content: |\n")
+	for _, line := range script {
+		fmt.Fprintf(&b, "      %s\n", line)
+	}
+	b.WriteString("runcmd:\n")
+	fmt.Fprintf(&b, "  - [%s]\n", cloudInitJoinScript)
+	return b.String(), nil
+}
+
+// validateCloudInitOptions checks the OS family, and that binaryURL is an
+// http(s) URL that can be put in double quotes in the join script.
+func validateCloudInitOptions(binaryURL, osFamily string) error {
+	if osFamily != cloudInitOSDebian && osFamily != cloudInitOSRHEL {
+		return fmt.Errorf("unknown OS family %q, want %s or %s", osFamily, cloudInitOSDebian, cloudInitOSRHEL)
+	}
+	if binaryURL == "" {
+		return fmt.Errorf("--binary-url is required with --cloud-init, e.g. https://downloads.example.com/wonder-linux-%s", cloudInitArchPlaceholder)
+	}
+	u, err := url.Parse(binaryURL)
+	if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+		return fmt.Errorf("--binary-url must be an http or https URL")
+	}
+	if strings.ContainsAny(binaryURL, "\"$`\\\n") {
+		return fmt.Errorf("--binary-url must not contain quotes, $, backticks, backslashes, or newlines")
+	}
+	return nil
+}
+
+// shellQuote quotes s as a single word for sh.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
@@ -0,0 +1,207 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/apikey"
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
+)
+
+func TestInspectToken_JoinToken(t *testing.T) {
+	generator := jointoken.NewGenerator("test-signing-key-that-is-32-bytes!", "https://wonder.example.com")
+	token, err := generator.Generate("net-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name        string
+		now         time.Time
+		wantExpired bool
+	}{
+		{name: "valid", now: time.Now()},
+		{name: "expired", now: time.Now().Add(2 * time.Hour), wantExpired: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inspectToken(token, tt.now)
+			if err != nil {
+				t.Fatalf("inspectToken() error = %v", err)
+			}
+			if got.Type != tokenTypeJoin || got.WonderNetID != "net-1" || got.CoordinatorURL != "https://wonder.example.com" {
+				t.Errorf("inspectToken() = %+v, want join token for net-1", got)
+			}
+			if got.Expired != tt.wantExpired {
+				t.Errorf("inspectToken().Expired = %v, want %v", got.Expired, tt.wantExpired)
+			}
+			if !strings.HasPrefix(got.Fingerprint, "sha256:") || len(got.Fingerprint) != len("sha256:")+16 {
+				t.Errorf("inspectToken().Fingerprint = %q, want sha256 prefix with 16 hex characters", got.Fingerprint)
+			}
+		})
+	}
+}
+
+func TestInspectToken_APIKey(t *testing.T) {
+	key, err := apikey.Generate()
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	tests := []struct {
+		name            string
+		raw             string
+		wantFingerprint string
+		wantErr         bool
+	}{
+		{name: "full key", raw: key.Raw, wantFingerprint: "sha256:" + key.Hash[:16]},
+		{name: "displayed prefix", raw: key.Prefix},
+		{name: "prefix without dots", raw: strings.TrimSuffix(key.Prefix, "...")},
+		{name: "too short", raw: "wmn_abc", wantErr: true},
+		{name: "not hex", raw: "wmn_zzzzzzzz", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := inspectToken(tt.raw, time.Now())
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("inspectToken(%q) error = nil, want error", tt.raw)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("inspectToken(%q) error = %v", tt.raw, err)
+			}
+			if got.Type != tokenTypeAPIKey || got.KeyPrefix != key.Prefix {
+				t.Errorf("inspectToken(%q) = %+v, want API key with prefix %s", tt.raw, got, key.Prefix)
+			}
+			if got.Fingerprint != tt.wantFingerprint {
+				t.Errorf("inspectToken(%q).Fingerprint = %q, want %q", tt.raw, got.Fingerprint, tt.wantFingerprint)
+			}
+		})
+	}
+}
+
+func TestInspectToken_Invalid(t *testing.T) {
+	if _, err := inspectToken("not-a-token", time.Now()); err == nil {
+		t.Error("inspectToken() error = nil, want error")
+	}
+}
+
+func TestCheckTokenStatus(t *testing.T) {
+	expired := time.Now().Add(-time.Hour)
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /coordinator/api/v1/wonder-nets", func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]wondersdk.WonderNet{{ID: "net-1", DisplayName: "home"}})
+	})
+	mux.HandleFunc("GET /coordinator/api/v1/api-keys", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("network") != "net-1" {
+			t.Errorf("network = %q, want net-1", r.URL.Query().Get("network"))
+		}
+		_ = json.NewEncoder(w).Encode([]wondersdk.APIKey{
+			{Name: "ci", KeyPrefix: "wmn_aaaaaaaa..."},
+			{Name: "old", KeyPrefix: "wmn_bbbbbbbb...", ExpiresAt: &expired},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+	client := wondersdk.NewClient(server.URL+"/coordinator", "session")
+
+	tests := []struct {
+		name       string
+		inspection tokenInspection
+		wantStatus string
+	}{
+		{name: "active key", inspection: tokenInspection{Type: tokenTypeAPIKey, KeyPrefix: "wmn_aaaaaaaa..."}, wantStatus: tokenStatusActive},
+		{name: "expired key", inspection: tokenInspection{Type: tokenTypeAPIKey, KeyPrefix: "wmn_bbbbbbbb..."}, wantStatus: tokenStatusExpired},
+		{name: "revoked key", inspection: tokenInspection{Type: tokenTypeAPIKey, KeyPrefix: "wmn_cccccccc..."}, wantStatus: tokenStatusRevoked},
+		{name: "join token", inspection: tokenInspection{Type: tokenTypeJoin, WonderNetID: "net-1"}, wantStatus: tokenStatusActive},
+		{name: "join token of deleted net", inspection: tokenInspection{Type: tokenTypeJoin, WonderNetID: "net-2"}, wantStatus: tokenStatusNoNetwork},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inspection := tt.inspection
+			if err := checkTokenStatus(context.Background(), client, &inspection, time.Now()); err != nil {
+				t.Fatalf("checkTokenStatus() error = %v", err)
+			}
+			if inspection.Status != tt.wantStatus {
+				t.Errorf("checkTokenStatus() status = %q, want %q", inspection.Status, tt.wantStatus)
+			}
+		})
+	}
+}
+
+func TestPrintJoinTokenQR(t *testing.T) {
+	generator := jointoken.NewGenerator("test-signing-key-that-is-32-bytes!", "https://wonder.example.com")
+	token, err := generator.Generate("net-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	var out strings.Builder
+	if err := printJoinTokenQR(&out, token); err != nil {
+		t.Fatalf("printJoinTokenQR() error = %v", err)
+	}
+	lines := strings.Split(strings.TrimRight(out.String(), "\n"), "\n")
+	// A QR code is square and each text line holds two rows of modules.
+	if len(lines) < 10 || len([]rune(lines[0])) < 2*len(lines)-4 {
+		t.Errorf("printJoinTokenQR() printed %d lines of %d runes, want a QR code", len(lines), len([]rune(lines[0])))
+	}
+
+	if err := printJoinTokenQR(&out, "not-a-token"); err == nil {
+		t.Error("printJoinTokenQR(invalid) error = nil, want error")
+	}
+}
+
+func TestRenderCloudInit(t *testing.T) {
+	generator := jointoken.NewGenerator("test-signing-key-that-is-32-bytes!", "https://wonder.example.com")
+	token, err := generator.Generate("net-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+
+	userData, err := renderCloudInit(token, "https://downloads.example.com/wonder-linux-{arch}", cloudInitOSDebian)
+	if err != nil {
+		t.Fatalf("renderCloudInit() error = %v", err)
+	}
+	for _, want := range []string{
+		"#cloud-config\n",
+		"# Joins this VM to wonder net net-1 at https://wonder.example.com on first boot.\n",
+		"  - curl\n",
+		`      curl -fsSL -o /usr/local/bin/wonder "https://downloads.example.com/wonder-linux-${arch}"` + "\n",
+		"      /usr/local/bin/wonder worker install --non-interactive '" + token + "'\n",
+		"  - [" + cloudInitJoinScript + "]\n",
+	} {
+		if !strings.Contains(userData, want) {
+			t.Errorf("renderCloudInit() is missing %q:\n%s", want, userData)
+		}
+	}
+
+	rhel, err := renderCloudInit(token, "https://downloads.example.com/wonder", cloudInitOSRHEL)
+	if err != nil {
+		t.Fatalf("renderCloudInit(rhel) error = %v", err)
+	}
+	if strings.Contains(rhel, "packages:") {
+		t.Errorf("renderCloudInit(rhel) installs packages:\n%s", rhel)
+	}
+
+	for _, tt := range []struct{ binaryURL, osFamily string }{
+		{"", cloudInitOSDebian},
+		{"ftp://downloads.example.com/wonder", cloudInitOSDebian},
+		{"https://downloads.example.com/$(reboot)", cloudInitOSDebian},
+		{"https://downloads.example.com/wonder", "windows"},
+	} {
+		if _, err := renderCloudInit(token, tt.binaryURL, tt.osFamily); err == nil {
+			t.Errorf("renderCloudInit(%q, %q) error = nil, want an error", tt.binaryURL, tt.osFamily)
+		}
+	}
+}
@@ -2,8 +2,11 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
 )
 
 var (
@@ -11,14 +14,33 @@ var (
 	gitSHA  = "unknown"
 )
 
+// versionInfo is the JSON and YAML output of wonder version.
+type versionInfo struct {
+	Version string `json:"version"`
+	GitSHA  string `json:"git_sha"`
+}
+
 // NewVersionCmd creates the version subcommand that prints
 // the wonder binary version and git commit SHA.
 func NewVersionCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "version",
 		Short: "Print version information",
-		Run: func(cmd *cobra.Command, args []string) {
-			fmt.Printf("wonder %s (%s)\n", version, gitSHA)
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+			return output.Print(os.Stdout, format, versionInfo{Version: version, GitSHA: gitSHA}, func(w io.Writer) error {
+				_, err := fmt.Fprintf(w, "wonder %s (%s)\n", version, gitSHA)
+				return err
+			})
 		},
 	}
 }
+
+// isReleaseBuild reports whether the binary was built from a release tag,
+// rather than by go build ("dev") or make on an untagged commit.
+func isReleaseBuild() bool {
+	return version != "dev" && version != "untagged"
+}
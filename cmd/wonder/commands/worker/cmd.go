@@ -16,6 +16,10 @@ func NewWorkerCmd() *cobra.Command {
 	cmd.AddCommand(newJoinCmd())
 	cmd.AddCommand(newStatusCmd())
 	cmd.AddCommand(newLeaveCmd())
+	cmd.AddCommand(newDaemonCmd())
+	cmd.AddCommand(newRepairCmd())
+	cmd.AddCommand(newInstallCmd())
+	cmd.AddCommand(newContainerCmd())
 
 	return cmd
 }
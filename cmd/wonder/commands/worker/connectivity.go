@@ -0,0 +1,134 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os/exec"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// netcheckInterval is how often the DERP latencies sent with
+	// heartbeats are measured again. A netcheck probes every DERP region,
+	// so it does not run with every heartbeat.
+	netcheckInterval = 10 * time.Minute
+
+	netcheckTimeout = 15 * time.Second
+)
+
+// connectivity is how this machine reaches the mesh: its home DERP region,
+// its latency in milliseconds to each DERP region keyed by region ID, and
+// the path to each peer.
+type connectivity struct {
+	DERPRegion    string             `json:"derp_region,omitempty"`
+	DERPLatencyMs map[string]float64 `json:"derp_latency_ms,omitempty"`
+	Peers         []peerConnection   `json:"peers,omitempty"`
+}
+
+// peerConnection is the path to a peer. Endpoint is the peer's UDP address
+// while traffic flows directly; otherwise it is relayed through the DERP
+// region Relay.
+type peerConnection struct {
+	Addresses []string `json:"addresses"`
+	Direct    bool     `json:"direct"`
+	Relay     string   `json:"relay,omitempty"`
+	Endpoint  string   `json:"endpoint,omitempty"`
+}
+
+// netcheckReport is the subset of `tailscale netcheck --format=json` sent
+// with heartbeats. RegionLatency is keyed by DERP region ID.
+type netcheckReport struct {
+	RegionLatency map[string]time.Duration `json:"RegionLatency"`
+}
+
+// derpLatencies caches the last netcheck for netcheckInterval.
+var derpLatencies struct {
+	sync.Mutex
+	latencyMs  map[string]float64
+	measuredAt time.Time
+}
+
+// collectConnectivity reads this machine's connectivity from tailscaled,
+// or returns nil if tailscaled is not running or cannot be queried.
+func collectConnectivity(ctx context.Context) *connectivity {
+	status, err := readTailscaleStatus(ctx)
+	if err != nil {
+		slog.Debug("read tailscale status for heartbeat", "error", err)
+	}
+	if status == nil {
+		return nil
+	}
+	c := connectivityFromStatus(status)
+	c.DERPLatencyMs = measureDERPLatencies(ctx)
+	return c
+}
+
+// connectivityFromStatus returns the DERP region and peer paths of a
+// tailscaled status. Peers that are offline are left out.
+func connectivityFromStatus(status *tailscaleStatus) *connectivity {
+	c := &connectivity{}
+	if status.Self != nil {
+		c.DERPRegion = status.Self.Relay
+	}
+	for _, peer := range status.Peer {
+		if !peer.Online || len(peer.TailscaleIPs) == 0 {
+			continue
+		}
+		c.Peers = append(c.Peers, peerConnection{
+			Addresses: peer.TailscaleIPs,
+			Direct:    peer.CurAddr != "",
+			Relay:     peer.Relay,
+			Endpoint:  peer.CurAddr,
+		})
+	}
+	return c
+}
+
+// measureDERPLatencies returns the DERP latencies of the last netcheck,
+// running a new one once netcheckInterval has passed. It returns nil if
+// netcheck fails.
+func measureDERPLatencies(ctx context.Context) map[string]float64 {
+	derpLatencies.Lock()
+	defer derpLatencies.Unlock()
+
+	if time.Since(derpLatencies.measuredAt) < netcheckInterval {
+		return derpLatencies.latencyMs
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, netcheckTimeout)
+	defer cancel()
+	out, err := exec.CommandContext(ctx, "tailscale", "netcheck", "--format=json").Output()
+	var latencyMs map[string]float64
+	if err == nil {
+		latencyMs, err = parseNetcheck(out)
+	}
+	if err != nil {
+		slog.Debug("run tailscale netcheck", "error", err)
+	}
+	derpLatencies.latencyMs = latencyMs
+	derpLatencies.measuredAt = time.Now()
+	return latencyMs
+}
+
+// parseNetcheck returns the latency to each DERP region in a netcheck
+// report, in milliseconds keyed by region ID.
+func parseNetcheck(data []byte) (map[string]float64, error) {
+	var report netcheckReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, err
+	}
+	if len(report.RegionLatency) == 0 {
+		return nil, nil
+	}
+	latencyMs := make(map[string]float64, len(report.RegionLatency))
+	for region, latency := range report.RegionLatency {
+		if _, err := strconv.Atoi(region); err != nil {
+			continue
+		}
+		latencyMs[region] = float64(latency.Microseconds()) / 1000
+	}
+	return latencyMs, nil
+}
@@ -0,0 +1,48 @@
+package worker
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestConnectivityFromStatus(t *testing.T) {
+	raw := `{
+		"BackendState": "Running",
+		"Self": {"HostName": "worker-1", "Online": true, "Relay": "fra"},
+		"Peer": {
+			"nodekey:a": {"HostName": "worker-2", "TailscaleIPs": ["100.64.0.2"], "Online": true, "Relay": "fra", "CurAddr": "192.0.2.10:41641"},
+			"nodekey:b": {"HostName": "worker-3", "TailscaleIPs": ["100.64.0.3"], "Online": false, "Relay": "ams"}
+		}
+	}`
+	var status tailscaleStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+
+	got := connectivityFromStatus(&status)
+	if got.DERPRegion != "fra" {
+		t.Errorf("DERPRegion = %q, want %q", got.DERPRegion, "fra")
+	}
+	if len(got.Peers) != 1 {
+		t.Fatalf("Peers = %+v, want only the online peer", got.Peers)
+	}
+	if peer := got.Peers[0]; peer.Addresses[0] != "100.64.0.2" || !peer.Direct || peer.Endpoint != "192.0.2.10:41641" {
+		t.Errorf("Peers[0] = %+v, want a direct path to 100.64.0.2", peer)
+	}
+}
+
+func TestParseNetcheck(t *testing.T) {
+	raw := `{"UDP": true, "PreferredDERP": 4, "RegionLatency": {"1": 48250000, "4": 12500000}}`
+
+	got, err := parseNetcheck([]byte(raw))
+	if err != nil {
+		t.Fatalf("parseNetcheck() error = %v", err)
+	}
+	if len(got) != 2 || got["1"] != 48.25 || got["4"] != 12.5 {
+		t.Errorf("parseNetcheck() = %v, want 48.25ms to region 1 and 12.5ms to region 4", got)
+	}
+
+	if got, err := parseNetcheck([]byte(`{"UDP": false}`)); err != nil || got != nil {
+		t.Errorf("parseNetcheck() = %v, %v, want no latencies", got, err)
+	}
+}
@@ -0,0 +1,348 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
+const (
+	// joinTokenSecretPath and apiKeySecretPath are where the container
+	// command looks for its join secrets when no environment variable names
+	// them, matching Docker and Kubernetes secret mounts.
+	joinTokenSecretPath = "/run/secrets/wonder-join-token"
+	apiKeySecretPath    = "/run/secrets/wonder-api-key"
+
+	// defaultContainerStateDir keeps tailscaled's state and the worker
+	// credentials; mount a volume there to keep the node across restarts.
+	defaultContainerStateDir = "/var/lib/wonder"
+
+	// defaultProxyListen serves SOCKS5 and HTTP proxying on one port, as
+	// tailscaled does when both listen on the same address.
+	defaultProxyListen = ":1055"
+)
+
+// containerFlags holds the command-line flags for the container command.
+// Each falls back to an environment variable, as containers are usually
+// configured through their environment.
+var containerFlags struct {
+	coordinatorURL  string
+	stateDir        string
+	tun             string
+	socks5Listen    string
+	httpProxyListen string
+	logLevel        string
+	logFormat       string
+}
+
+// newContainerCmd creates the container subcommand, the entrypoint of the
+// worker image.
+func newContainerCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "container",
+		Short: "Run the worker as a container entrypoint",
+		Long: `Run tailscaled and the worker in the foreground, as the entrypoint of the
+worker container image. It needs neither a TUN device nor NET_ADMIN: tailscaled
+uses userspace networking and serves a SOCKS5 and HTTP proxy into the mesh, so
+the worker can run as a sidecar next to applications that reach mesh nodes
+through the proxy.
+
+On first start the worker joins with a join token or an API key, read from
+WONDER_JOIN_TOKEN or WONDER_API_KEY, from the file named by
+WONDER_JOIN_TOKEN_FILE or WONDER_API_KEY_FILE, or from the secret mounts
+/run/secrets/wonder-join-token and /run/secrets/wonder-api-key. Joining with an
+API key also needs --coordinator-url (WONDER_COORDINATOR_URL).
+
+tailscaled's state and the worker credentials are kept in --state-dir
+(WONDER_STATE_DIR). Mount a volume there so that a restarted container keeps
+its node instead of joining again. A worker joined with a join token then
+sends heartbeats like wonder worker daemon, and renews its login with --renew
+(WONDER_RENEW=true).
+
+The proxy listens on --socks5-listen (WONDER_SOCKS5_LISTEN) and
+--http-proxy-listen (WONDER_HTTP_PROXY_LISTEN), both :1055 by default. Set
+--tun (WONDER_TUN) to a device name such as tailscale0 to use kernel
+networking instead, which needs /dev/net/tun and NET_ADMIN.
+
+The command exits when tailscaled exits or on SIGINT or SIGTERM, stopping
+tailscaled first.`,
+		RunE: runContainer,
+	}
+
+	cmd.Flags().StringVar(&containerFlags.coordinatorURL, "coordinator-url", "", "Coordinator URL, required with an API key (env: WONDER_COORDINATOR_URL)")
+	cmd.Flags().StringVar(&containerFlags.stateDir, "state-dir", "", "Directory of tailscaled state and worker credentials (env: WONDER_STATE_DIR, default "+defaultContainerStateDir+")")
+	cmd.Flags().StringVar(&containerFlags.tun, "tun", "", "tailscaled --tun value (env: WONDER_TUN, default userspace-networking)")
+	cmd.Flags().StringVar(&containerFlags.socks5Listen, "socks5-listen", "", "SOCKS5 proxy listen address (env: WONDER_SOCKS5_LISTEN, default "+defaultProxyListen+")")
+	cmd.Flags().StringVar(&containerFlags.httpProxyListen, "http-proxy-listen", "", "HTTP proxy listen address (env: WONDER_HTTP_PROXY_LISTEN, default "+defaultProxyListen+")")
+	cmd.Flags().DurationVar(&daemonFlags.interval, "interval", time.Minute, "Time between heartbeats")
+	cmd.Flags().StringVar(&daemonFlags.wipeCommand, "wipe-command", "", "Command run with sh when the node is decommissioned with a wipe")
+	cmd.Flags().DurationVar(&daemonFlags.wipeTimeout, "wipe-timeout", 30*time.Minute, "Time the wipe command may run")
+	cmd.Flags().BoolVar(&daemonFlags.renew, "renew", false, "Log in again with a new authkey when tailscale needs a login (env: WONDER_RENEW)")
+	cmd.Flags().StringVar(&containerFlags.logLevel, "log-level", "info", "Log level (debug, info, warn, or error)")
+	cmd.Flags().StringVar(&containerFlags.logFormat, "log-format", "text", "Log format (text or json)")
+	return cmd
+}
+
+// runContainer starts tailscaled, joins the mesh unless the state directory
+// holds credentials, and sends heartbeats until tailscaled exits or the
+// container is stopped.
+func runContainer(cmd *cobra.Command, args []string) error {
+	if daemonFlags.interval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+	if daemonFlags.wipeTimeout <= 0 {
+		return fmt.Errorf("--wipe-timeout must be positive")
+	}
+
+	logCloser, err := logging.Setup(logging.Config{
+		Level:  containerFlags.logLevel,
+		Format: containerFlags.logFormat,
+		Output: "stderr",
+	}, "wonder-worker")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logCloser.Close() }()
+
+	if !inContainer() {
+		slog.Warn("not running in a container, tailscaled still uses the container settings")
+	}
+
+	stateDir := flagOrEnv(containerFlags.stateDir, "WONDER_STATE_DIR", defaultContainerStateDir)
+	if err := os.MkdirAll(stateDir, 0700); err != nil {
+		return fmt.Errorf("create state directory: %w", err)
+	}
+	credentialsFile = filepath.Join(stateDir, "worker.json")
+	joinFlags.nonInteractive = true
+	if !daemonFlags.renew {
+		daemonFlags.renew, _ = strconv.ParseBool(os.Getenv("WONDER_RENEW"))
+	}
+
+	ctx, stop := daemonContext()
+	defer stop()
+
+	tailscaledCtx, stopTailscaled := context.WithCancel(ctx)
+	defer stopTailscaled()
+
+	tailscaled := exec.CommandContext(tailscaledCtx, "tailscaled", containerTailscaledArgs(
+		stateDir,
+		flagOrEnv(containerFlags.tun, "WONDER_TUN", "userspace-networking"),
+		flagOrEnv(containerFlags.socks5Listen, "WONDER_SOCKS5_LISTEN", defaultProxyListen),
+		flagOrEnv(containerFlags.httpProxyListen, "WONDER_HTTP_PROXY_LISTEN", defaultProxyListen),
+	)...)
+	tailscaled.Stdout = os.Stderr
+	tailscaled.Stderr = os.Stderr
+	// Interrupt tailscaled so that it shuts down cleanly, killing it only
+	// if it has not exited after WaitDelay.
+	tailscaled.Cancel = func() error { return tailscaled.Process.Signal(os.Interrupt) }
+	tailscaled.WaitDelay = 15 * time.Second
+	if err := os.MkdirAll(filepath.Dir(tailscaledSocketPath), 0755); err != nil {
+		return fmt.Errorf("create tailscaled socket directory: %w", err)
+	}
+	if err := tailscaled.Start(); err != nil {
+		return fmt.Errorf("start tailscaled: %w", err)
+	}
+
+	exited := make(chan struct{})
+	var tailscaledErr error
+	go func() {
+		tailscaledErr = tailscaled.Wait()
+		close(exited)
+	}()
+
+	// Stop the worker as soon as tailscaled exits, so that the container
+	// exits too and its restart policy applies.
+	workerCtx, cancelWorker := context.WithCancel(ctx)
+	defer cancelWorker()
+	go func() {
+		select {
+		case <-exited:
+			cancelWorker()
+		case <-workerCtx.Done():
+		}
+	}()
+
+	err = runContainerWorker(workerCtx, flagOrEnv(containerFlags.coordinatorURL, "WONDER_COORDINATOR_URL", ""))
+
+	select {
+	case <-exited:
+		if ctx.Err() == nil {
+			return fmt.Errorf("tailscaled exited: %v", tailscaledErr)
+		}
+	default:
+		stopTailscaled()
+		<-exited
+	}
+	return err
+}
+
+// runContainerWorker waits for tailscaled, joins if needed, and sends
+// heartbeats until ctx is done.
+func runContainerWorker(ctx context.Context, coordinatorURL string) error {
+	if err := waitForTailscaled(ctx, 30*time.Second); err != nil {
+		return err
+	}
+
+	creds, err := loadCredentials()
+	switch {
+	case err == nil:
+		slog.Info("already joined", "coordinator", creds.CoordinatorURL)
+	case os.IsNotExist(err):
+		if err := containerJoin(coordinatorURL); err != nil {
+			return err
+		}
+		if creds, err = loadCredentials(); err != nil {
+			return fmt.Errorf("load credentials after join: %w", err)
+		}
+	default:
+		return fmt.Errorf("%w (remove it to join again)", err)
+	}
+
+	if creds.WorkerToken == "" {
+		slog.Info("joined with an API key, heartbeats are disabled")
+		<-ctx.Done()
+		return nil
+	}
+	runHeartbeats(ctx, creds)
+	return nil
+}
+
+// containerJoin joins the mesh with the join token or API key given to the
+// container.
+func containerJoin(coordinatorURL string) error {
+	token, err := containerSecret("WONDER_JOIN_TOKEN", joinTokenSecretPath)
+	if err != nil {
+		return err
+	}
+	apiKey, err := containerSecret("WONDER_API_KEY", apiKeySecretPath)
+	if err != nil {
+		return err
+	}
+
+	joinFlags.coordinatorURL = coordinatorURL
+	switch {
+	case token != "":
+		return runTokenJoin(token)
+	case apiKey != "":
+		return runAPIKeyJoin(apiKey)
+	default:
+		return errors.New("not joined and no join token or API key given, set WONDER_JOIN_TOKEN or WONDER_API_KEY, or mount one of them as a secret")
+	}
+}
+
+// waitForTailscaled waits until tailscaled's socket exists.
+func waitForTailscaled(ctx context.Context, timeout time.Duration) error {
+	ticker := time.NewTicker(100 * time.Millisecond)
+	defer ticker.Stop()
+	deadline := time.After(timeout)
+	for {
+		if _, err := os.Stat(tailscaledSocketPath); err == nil {
+			return nil
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-deadline:
+			return fmt.Errorf("tailscaled socket not ready after %s", timeout)
+		case <-ticker.C:
+		}
+	}
+}
+
+// containerTailscaledArgs returns the arguments tailscaled runs with in a
+// container. An empty proxy address disables that proxy.
+func containerTailscaledArgs(stateDir, tun, socks5Listen, httpProxyListen string) []string {
+	args := []string{
+		"--state=" + filepath.Join(stateDir, "tailscaled.state"),
+		"--socket=" + tailscaledSocketPath,
+		"--tun=" + tun,
+	}
+	if socks5Listen != "" {
+		args = append(args, "--socks5-server="+socks5Listen)
+	}
+	if httpProxyListen != "" {
+		args = append(args, "--outbound-http-proxy-listen="+httpProxyListen)
+	}
+	return args
+}
+
+// containerSecret returns the secret in the environment variable name, in
+// the file named by name_FILE, or in the secret mount at mountPath, in that
+// order. It returns an empty string if none is set.
+func containerSecret(name, mountPath string) (string, error) {
+	if value := os.Getenv(name); value != "" {
+		return strings.TrimSpace(value), nil
+	}
+	path := os.Getenv(name + "_FILE")
+	if path == "" {
+		if _, err := os.Stat(mountPath); err != nil {
+			return "", nil
+		}
+		path = mountPath
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", name, err)
+	}
+	return strings.TrimSpace(string(data)), nil
+}
+
+// flagOrEnv returns the flag value if set, else the environment variable,
+// else def.
+func flagOrEnv(flag, env, def string) string {
+	if flag != "" {
+		return flag
+	}
+	if value := os.Getenv(env); value != "" {
+		return value
+	}
+	return def
+}
+
+// inContainer reports whether this process runs in a container, going by
+// the marker files of Docker and Podman, the environment of Kubernetes and
+// systemd-nspawn, and the cgroup of PID 1.
+func inContainer() bool {
+	for _, path := range []string{"/.dockerenv", "/run/.containerenv"} {
+		if _, err := os.Stat(path); err == nil {
+			return true
+		}
+	}
+	if os.Getenv("KUBERNETES_SERVICE_HOST") != "" || os.Getenv("container") != "" {
+		return true
+	}
+	cgroup, err := os.ReadFile("/proc/1/cgroup")
+	return err == nil && containerCgroup(cgroup)
+}
+
+// containerCgroup reports whether the contents of /proc/1/cgroup name a
+// container runtime.
+func containerCgroup(cgroup []byte) bool {
+	for _, runtime := range []string{"docker", "kubepods", "containerd", "libpod", "lxc"} {
+		if bytes.Contains(cgroup, []byte(runtime)) {
+			return true
+		}
+	}
+	return false
+}
+
+// needsUserspaceNetworking reports whether tailscaled must run without a
+// TUN device, as in a container that was not given /dev/net/tun.
+func needsUserspaceNetworking() bool {
+	if !inContainer() {
+		return false
+	}
+	_, err := os.Stat("/dev/net/tun")
+	return err != nil
+}
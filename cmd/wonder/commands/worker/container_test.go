@@ -0,0 +1,81 @@
+package worker
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestContainerTailscaledArgs(t *testing.T) {
+	got := containerTailscaledArgs("/var/lib/wonder", "userspace-networking", ":1055", "")
+	want := []string{
+		"--state=/var/lib/wonder/tailscaled.state",
+		"--socket=" + tailscaledSocketPath,
+		"--tun=userspace-networking",
+		"--socks5-server=:1055",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("containerTailscaledArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestContainerSecret(t *testing.T) {
+	dir := t.TempDir()
+	mount := filepath.Join(dir, "mounted")
+	file := filepath.Join(dir, "file")
+	if err := os.WriteFile(mount, []byte("from-mount\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("from-file\n"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name      string
+		env       string
+		envFile   string
+		mountPath string
+		want      string
+	}{
+		{"env wins", "from-env", file, mount, "from-env"},
+		{"file variable", "", file, mount, "from-file"},
+		{"secret mount", "", "", mount, "from-mount"},
+		{"unset", "", "", filepath.Join(dir, "missing"), ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("WONDER_TEST_SECRET", tt.env)
+			t.Setenv("WONDER_TEST_SECRET_FILE", tt.envFile)
+			got, err := containerSecret("WONDER_TEST_SECRET", tt.mountPath)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tt.want {
+				t.Errorf("containerSecret() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestContainerCgroup(t *testing.T) {
+	tests := []struct {
+		cgroup string
+		want   bool
+	}{
+		{"0::/\n", false},
+		{"0::/init.scope\n", false},
+		{"12:memory:/docker/3f2a9c\n", true},
+		{"0::/kubepods.slice/kubepods-besteffort.slice/cri-containerd-ab12.scope\n", true},
+		{"0::/machine.slice/libpod-4e1f.scope\n", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.cgroup, func(t *testing.T) {
+			if got := containerCgroup([]byte(tt.cgroup)); got != tt.want {
+				t.Errorf("containerCgroup(%q) = %v, want %v", tt.cgroup, got, tt.want)
+			}
+		})
+	}
+}
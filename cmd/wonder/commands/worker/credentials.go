@@ -2,27 +2,70 @@ package worker
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/url"
 	"os"
 	"path/filepath"
 	"time"
 )
 
+// credentialsVersion is the schema version of the credentials file written
+// by this build. Files without a version field are version 1.
+const credentialsVersion = 2
+
+var (
+	// errCorruptCredentials is returned when the credentials file is not
+	// valid JSON.
+	errCorruptCredentials = errors.New("credentials file is corrupt")
+	// errInvalidCredentials is returned when the credentials file parses but
+	// misses required fields.
+	errInvalidCredentials = errors.New("credentials file is invalid")
+)
+
 // credentials stores the worker's mesh network connection information
 // persisted to disk after a successful join. The data is stored as JSON
 // in the user's home directory at ~/.wonder/worker.json.
 type credentials struct {
+	// Version is the schema version the file was written with.
+	Version int `json:"version"`
 	// User is the Headscale username assigned to this worker node.
 	User string `json:"user"`
 	// CoordinatorURL is the base URL of the Wonder Mesh Net coordinator server.
-	CoordinatorURL string `json:"coordinatorURL"`
+	CoordinatorURL string `json:"coordinator_url"`
 	// JoinedAt records the timestamp when this worker joined the mesh.
 	JoinedAt time.Time `json:"joined_at"`
+	// WorkerToken authenticates heartbeats sent by wonder worker daemon.
+	// Workers joined with an API key have none.
+	WorkerToken string `json:"worker_token,omitempty"`
+	// SSHCAPublicKey is the wonder net's SSH CA key, installed for sshd by
+	// install --ssh-ca. Workers joined with older coordinators have none.
+	SSHCAPublicKey string `json:"ssh_ca_public_key,omitempty"`
+}
+
+// credentialsMigrations upgrade the raw JSON fields of a credentials file
+// from the version of the key to the next one.
+var credentialsMigrations = map[int]func(raw map[string]any){
+	// Version 2 renamed coordinatorURL to coordinator_url, matching the
+	// other fields.
+	1: func(raw map[string]any) {
+		if v, ok := raw["coordinatorURL"]; ok {
+			raw["coordinator_url"] = v
+			delete(raw, "coordinatorURL")
+		}
+	},
 }
 
+// credentialsFile overrides the credentials path, set with the daemon's
+// --credentials flag when it runs as a system service under another user.
+var credentialsFile string
+
 // getCredentialsPath returns the filesystem path where worker credentials
 // are stored, typically ~/.wonder/worker.json.
 func getCredentialsPath() (string, error) {
+	if credentialsFile != "" {
+		return credentialsFile, nil
+	}
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return "", fmt.Errorf("get credentials path: %w", err)
@@ -30,8 +73,10 @@ func getCredentialsPath() (string, error) {
 	return filepath.Join(home, ".wonder", "worker.json"), nil
 }
 
-// loadCredentials reads and parses the credentials file from disk.
-// Returns an error if the file does not exist or cannot be parsed.
+// loadCredentials reads and parses the credentials file from disk,
+// migrating and rewriting it if it was written by an older version.
+// Returns an error if the file does not exist, cannot be parsed, or fails
+// the integrity checks.
 func loadCredentials() (*credentials, error) {
 	credentialPath, err := getCredentialsPath()
 	if err != nil {
@@ -42,16 +87,80 @@ func loadCredentials() (*credentials, error) {
 		return nil, err
 	}
 
-	var creds credentials
-	if err := json.Unmarshal(data, &creds); err != nil {
+	creds, fromVersion, err := decodeCredentials(data)
+	if err != nil {
+		return nil, err
+	}
+	if err := creds.validate(); err != nil {
 		return nil, err
 	}
 
-	return &creds, nil
+	if fromVersion < credentialsVersion {
+		if err := saveCredentials(creds); err != nil {
+			fmt.Printf("Warning: save migrated credentials: %v\n", err)
+		}
+	}
+	return creds, nil
+}
+
+// decodeCredentials parses a credentials file of any known version and
+// migrates it to the current schema. It also returns the version the file
+// was written with.
+func decodeCredentials(data []byte) (*credentials, int, error) {
+	var raw map[string]any
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return nil, 0, fmt.Errorf("%w: %v", errCorruptCredentials, err)
+	}
+
+	version := 1
+	if v, ok := raw["version"]; ok {
+		f, ok := v.(float64)
+		if !ok || f < 1 || f != float64(int(f)) {
+			return nil, 0, fmt.Errorf("%w: invalid version %v", errCorruptCredentials, v)
+		}
+		version = int(f)
+	}
+	if version > credentialsVersion {
+		return nil, version, fmt.Errorf("credentials file has version %d, but this wonder only knows up to version %d; upgrade wonder", version, credentialsVersion)
+	}
+
+	for v := version; v < credentialsVersion; v++ {
+		credentialsMigrations[v](raw)
+	}
+	raw["version"] = credentialsVersion
+
+	migrated, err := json.Marshal(raw)
+	if err != nil {
+		return nil, version, err
+	}
+	var creds credentials
+	if err := json.Unmarshal(migrated, &creds); err != nil {
+		return nil, version, fmt.Errorf("%w: %v", errCorruptCredentials, err)
+	}
+	return &creds, version, nil
+}
+
+// validate checks that the fields needed to use the credentials are set.
+func (c *credentials) validate() error {
+	if c.User == "" {
+		return fmt.Errorf("%w: user is missing", errInvalidCredentials)
+	}
+	if c.CoordinatorURL == "" {
+		return fmt.Errorf("%w: coordinator_url is missing", errInvalidCredentials)
+	}
+	u, err := url.Parse(c.CoordinatorURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return fmt.Errorf("%w: coordinator_url %q is not an http(s) URL", errInvalidCredentials, c.CoordinatorURL)
+	}
+	if c.JoinedAt.IsZero() {
+		return fmt.Errorf("%w: joined_at is missing", errInvalidCredentials)
+	}
+	return nil
 }
 
-// saveCredentials persists the credentials to disk, creating the parent
-// directory if necessary with restricted permissions (0700 for dir, 0600 for file).
+// saveCredentials persists the credentials to disk with the current schema
+// version, creating the parent directory if necessary with restricted
+// permissions (0700 for dir, 0600 for file).
 func saveCredentials(creds *credentials) error {
 	credentialPath, err := getCredentialsPath()
 	if err != nil {
@@ -62,6 +171,7 @@ func saveCredentials(creds *credentials) error {
 		return fmt.Errorf("create credentials directory: %w", err)
 	}
 
+	creds.Version = credentialsVersion
 	data, err := json.MarshalIndent(creds, "", "  ")
 	if err != nil {
 		return err
@@ -0,0 +1,129 @@
+package worker
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDecodeCredentials(t *testing.T) {
+	tests := []struct {
+		name            string
+		data            string
+		wantVersion     int
+		wantCoordinator string
+		wantErr         error
+	}{
+		{
+			name:            "unversioned file is migrated",
+			data:            `{"user":"u1","coordinatorURL":"https://wonder.example.com","joined_at":"2025-01-02T03:04:05Z"}`,
+			wantVersion:     1,
+			wantCoordinator: "https://wonder.example.com",
+		},
+		{
+			name:            "current version",
+			data:            `{"version":2,"user":"u1","coordinator_url":"https://wonder.example.com","joined_at":"2025-01-02T03:04:05Z"}`,
+			wantVersion:     2,
+			wantCoordinator: "https://wonder.example.com",
+		},
+		{
+			name:    "not JSON",
+			data:    `{"user":`,
+			wantErr: errCorruptCredentials,
+		},
+		{
+			name:    "invalid version",
+			data:    `{"version":"two"}`,
+			wantErr: errCorruptCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, version, err := decodeCredentials([]byte(tt.data))
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("decodeCredentials() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("decodeCredentials() error = %v", err)
+			}
+			if version != tt.wantVersion {
+				t.Errorf("decodeCredentials() version = %d, want %d", version, tt.wantVersion)
+			}
+			if creds.Version != credentialsVersion {
+				t.Errorf("decodeCredentials().Version = %d, want %d", creds.Version, credentialsVersion)
+			}
+			if creds.CoordinatorURL != tt.wantCoordinator {
+				t.Errorf("decodeCredentials().CoordinatorURL = %q, want %q", creds.CoordinatorURL, tt.wantCoordinator)
+			}
+		})
+	}
+}
+
+func TestDecodeCredentials_NewerVersion(t *testing.T) {
+	_, _, err := decodeCredentials([]byte(`{"version":99,"user":"u1"}`))
+	if err == nil || errors.Is(err, errCorruptCredentials) {
+		t.Errorf("decodeCredentials() error = %v, want an upgrade error", err)
+	}
+}
+
+func TestRepairCredentials(t *testing.T) {
+	modTime := time.Date(2025, 1, 2, 3, 4, 5, 0, time.UTC)
+	controlURL := func() string { return "https://wonder.example.com/" }
+
+	tests := []struct {
+		name      string
+		data      string
+		wantFixes int
+		wantErr   error
+	}{
+		{
+			name: "healthy file",
+			data: `{"version":2,"user":"u1","coordinator_url":"https://wonder.example.com","joined_at":"2025-01-02T03:04:05Z"}`,
+		},
+		{
+			name:      "old version with trailing slash",
+			data:      `{"user":"u1","coordinatorURL":"https://wonder.example.com/","joined_at":"2025-01-02T03:04:05Z"}`,
+			wantFixes: 2,
+		},
+		{
+			name:      "missing coordinator and join time",
+			data:      `{"version":2,"user":"u1"}`,
+			wantFixes: 2,
+		},
+		{
+			name:    "missing user",
+			data:    `{"version":2,"coordinator_url":"https://wonder.example.com","joined_at":"2025-01-02T03:04:05Z"}`,
+			wantErr: errInvalidCredentials,
+		},
+		{
+			name:    "not JSON",
+			data:    `garbage`,
+			wantErr: errCorruptCredentials,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			creds, fixes, err := repairCredentials([]byte(tt.data), modTime, controlURL)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("repairCredentials() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("repairCredentials() error = %v", err)
+			}
+			if len(fixes) != tt.wantFixes {
+				t.Errorf("repairCredentials() fixes = %v, want %d fixes", fixes, tt.wantFixes)
+			}
+			if creds.CoordinatorURL != "https://wonder.example.com" {
+				t.Errorf("repairCredentials().CoordinatorURL = %q, want %q", creds.CoordinatorURL, "https://wonder.example.com")
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
+// daemonFlags holds the command-line flags for the daemon command.
+var daemonFlags struct {
+	interval    time.Duration
+	logLevel    string
+	logFormat   string
+	logOutput   string
+	wipeCommand string
+	wipeTimeout time.Duration
+	renew       bool
+}
+
+// newDaemonCmd creates the daemon subcommand that keeps reporting this
+// worker's health to the coordinator.
+func newDaemonCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "daemon",
+		Short: "Report worker health to the coordinator",
+		Long: `Run in the foreground and send a heartbeat to the coordinator at a fixed
+interval, until interrupted. Each heartbeat reports the OS, tailscale version,
+and disk and memory usage of this machine, and is shown as the node's health
+in node listings. A node counts as unhealthy once it misses a few heartbeats.
+
+Requires a worker joined with a join token, which stores the worker token
+used to authenticate heartbeats.
+
+When the node is decommissioned with a wipe, the coordinator answers a
+heartbeat with a wipe request. The daemon then runs --wipe-command with sh
+(cmd.exe on Windows), with WONDER_DECOMMISSION_ID set, and reports its exit
+status and output. A successful wipe lets the coordinator remove the node, and
+the daemon stops.
+
+When tailscale needs a login, because the authkey expired before it was used
+or the node key expired, the daemon gets a new authkey from the coordinator
+with the worker token and logs in again, as wonder worker join --renew does.
+Enable this with --renew. The coordinator only renews the login of a node that
+is offline, and never of a node that was removed or decommissioned.
+
+wonder worker install runs the daemon as a system service, passing
+--credentials so that it finds the credentials of the user who joined.
+
+Logs go to stderr by default. Use --log-output to write them to a rotated
+file or to syslog, e.g. syslog://logs.example.com:514.`,
+		RunE: runDaemon,
+	}
+
+	cmd.Flags().DurationVar(&daemonFlags.interval, "interval", time.Minute, "Time between heartbeats")
+	cmd.Flags().StringVar(&daemonFlags.logLevel, "log-level", "info", "Log level (debug, info, warn, or error)")
+	cmd.Flags().StringVar(&daemonFlags.logFormat, "log-format", "text", "Log format (text or json)")
+	cmd.Flags().StringVar(&daemonFlags.wipeCommand, "wipe-command", "", "Command run with sh when the node is decommissioned with a wipe")
+	cmd.Flags().DurationVar(&daemonFlags.wipeTimeout, "wipe-timeout", 30*time.Minute, "Time the wipe command may run")
+	cmd.Flags().BoolVar(&daemonFlags.renew, "renew", false, "Log in again with a new authkey when tailscale needs a login")
+	cmd.Flags().StringVar(&daemonFlags.logOutput, "log-output", "stderr", "Log output: stderr, stdout, syslog, syslog://host:port, syslog+tcp://host:port, or a file path")
+	cmd.Flags().StringVar(&credentialsFile, "credentials", "", "Path of the credentials file written by join (default ~/.wonder/worker.json)")
+	return cmd
+}
+
+// runDaemon sends heartbeats until SIGINT or SIGTERM. Failed heartbeats are
+// logged and retried at the next interval.
+func runDaemon(cmd *cobra.Command, args []string) error {
+	if daemonFlags.interval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+	if daemonFlags.wipeTimeout <= 0 {
+		return fmt.Errorf("--wipe-timeout must be positive")
+	}
+
+	creds, err := loadCredentials()
+	if os.IsNotExist(err) {
+		return fmt.Errorf("not joined to any mesh, run wonder worker join first")
+	}
+	if err != nil {
+		return fmt.Errorf("%w (run wonder worker repair to fix it)", err)
+	}
+	if creds.WorkerToken == "" {
+		return fmt.Errorf("no worker token stored, join again with a join token to enable heartbeats")
+	}
+
+	logCloser, err := logging.Setup(logging.Config{
+		Level:  daemonFlags.logLevel,
+		Format: daemonFlags.logFormat,
+		Output: daemonFlags.logOutput,
+	}, "wonder-worker")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = logCloser.Close() }()
+
+	ctx, stop := daemonContext()
+	defer stop()
+
+	runHeartbeats(ctx, creds)
+	return nil
+}
+
+// runHeartbeats sends a heartbeat every daemonFlags.interval until ctx is
+// done or the node is wiped, renewing the login first if it is enabled.
+func runHeartbeats(ctx context.Context, creds *credentials) {
+	slog.Info("sending heartbeats", "coordinator", creds.CoordinatorURL, "interval", daemonFlags.interval)
+
+	ticker := time.NewTicker(daemonFlags.interval)
+	defer ticker.Stop()
+	for {
+		if daemonFlags.renew {
+			renewIfLoggedOut(ctx, creds)
+		}
+		resp, err := sendHeartbeat(ctx, creds)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Error("heartbeat failed", "error", err)
+			}
+		} else {
+			slog.Debug("heartbeat sent")
+		}
+		if resp != nil && resp.Wipe != nil && handleWipe(ctx, creds, resp.Wipe) {
+			slog.Info("stopped")
+			return
+		}
+
+		select {
+		case <-ctx.Done():
+			slog.Info("stopped")
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendHeartbeat collects this machine's health and posts it to the
+// coordinator's worker heartbeat endpoint. It returns the coordinator's
+// response if it has work for this worker.
+func sendHeartbeat(ctx context.Context, creds *credentials) (*heartbeatResponse, error) {
+	reqBody, err := json.Marshal(collectHeartbeat(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		creds.CoordinatorURL+"/coordinator/api/v1/worker/heartbeat", bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+creds.WorkerToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusNoContent:
+		return nil, nil
+	case http.StatusOK:
+		var hbResp heartbeatResponse
+		if err := json.NewDecoder(resp.Body).Decode(&hbResp); err != nil {
+			return nil, fmt.Errorf("decode heartbeat response: %w", err)
+		}
+		return &hbResp, nil
+	default:
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("coordinator returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+}
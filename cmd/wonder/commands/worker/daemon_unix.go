@@ -0,0 +1,39 @@
+//go:build !windows
+
+package worker
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+)
+
+// daemonContext returns a context canceled on SIGINT or SIGTERM, which
+// systemd and launchd send to stop the service.
+func daemonContext() (context.Context, context.CancelFunc) {
+	return signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+}
+
+// shellCommand runs command with sh in its own process group, and kills
+// the whole group when ctx is done, so that children of sh do not outlive
+// it.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	cmd := exec.CommandContext(ctx, "/bin/sh", "-c", command)
+	cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	cmd.Cancel = func() error {
+		return syscall.Kill(-cmd.Process.Pid, syscall.SIGKILL)
+	}
+	return cmd
+}
+
+// diskStats returns the size and free space of the root file system, or
+// zeros if it cannot be read.
+func diskStats() (total, free int64) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs("/", &stat); err != nil {
+		return 0, 0
+	}
+	return int64(stat.Blocks * uint64(stat.Bsize)), int64(stat.Bavail * uint64(stat.Bsize))
+}
@@ -0,0 +1,80 @@
+//go:build windows
+
+package worker
+
+import (
+	"context"
+	"os"
+	"os/exec"
+	"os/signal"
+
+	"golang.org/x/sys/windows"
+	"golang.org/x/sys/windows/svc"
+)
+
+// daemonContext returns a context canceled when the daemon is asked to
+// stop. Started by the service control manager, the daemon must report its
+// state to it, or it is killed as unresponsive; otherwise Ctrl+C stops it.
+func daemonContext() (context.Context, context.CancelFunc) {
+	inService, err := svc.IsWindowsService()
+	if err != nil || !inService {
+		return signal.NotifyContext(context.Background(), os.Interrupt)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		defer cancel()
+		_ = svc.Run(windowsServiceName, &serviceHandler{ctx: ctx, stop: cancel})
+	}()
+	return ctx, cancel
+}
+
+// serviceHandler reports the daemon as running until the service control
+// manager stops it or the daemon stops on its own.
+type serviceHandler struct {
+	ctx  context.Context
+	stop context.CancelFunc
+}
+
+func (h *serviceHandler) Execute(args []string, requests <-chan svc.ChangeRequest, status chan<- svc.Status) (bool, uint32) {
+	status <- svc.Status{State: svc.Running, Accepts: svc.AcceptStop | svc.AcceptShutdown}
+	for {
+		select {
+		case <-h.ctx.Done():
+			status <- svc.Status{State: svc.StopPending}
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				status <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				status <- svc.Status{State: svc.StopPending}
+				h.stop()
+				return false, 0
+			}
+		}
+	}
+}
+
+// shellCommand runs command with cmd.exe.
+func shellCommand(ctx context.Context, command string) *exec.Cmd {
+	return exec.CommandContext(ctx, "cmd.exe", "/C", command)
+}
+
+// diskStats returns the size and free space of the system drive, or zeros
+// if it cannot be read.
+func diskStats() (total, free int64) {
+	drive := os.Getenv("SystemDrive")
+	if drive == "" {
+		drive = "C:"
+	}
+	path, err := windows.UTF16PtrFromString(drive + `\`)
+	if err != nil {
+		return 0, 0
+	}
+	var available, size, totalFree uint64
+	if err := windows.GetDiskFreeSpaceEx(path, &available, &size, &totalFree); err != nil {
+		return 0, 0
+	}
+	return int64(size), int64(available)
+}
@@ -0,0 +1,604 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
+)
+
+// Doctor check results.
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "fail"
+	checkSkip = "skip"
+)
+
+const (
+	// defaultSTUNPort is used for DERP nodes that do not set a STUN port.
+	defaultSTUNPort = 3478
+	// maxSTUNServers limits how many DERP regions the NAT check asks, one
+	// after the other on the same socket.
+	maxSTUNServers = 3
+	// doctorProbeTimeout bounds each HTTP probe of the doctor.
+	doctorProbeTimeout = 5 * time.Second
+)
+
+// Paths from this machine to a peer.
+const (
+	peerPathDirect  = "direct"
+	peerPathRelayed = "relayed"
+	peerPathOffline = "offline"
+)
+
+// doctorReport is the JSON and YAML output of wonder net doctor.
+type doctorReport struct {
+	CoordinatorURL string        `json:"coordinator_url,omitempty"`
+	Checks         []doctorCheck `json:"checks"`
+	DERP           []derpProbe   `json:"derp"`
+	NAT            *natReport    `json:"nat,omitempty"`
+	Peers          []peerPath    `json:"peers"`
+}
+
+// doctorCheck is the result of one check, with how to fix a failure.
+type doctorCheck struct {
+	Name   string `json:"name"`
+	Status string `json:"status"`
+	Detail string `json:"detail,omitempty"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// derpProbe is the result of an HTTPS request to a DERP region's probe
+// endpoint.
+type derpProbe struct {
+	Region    string `json:"region"`
+	Host      string `json:"host"`
+	LatencyMS int64  `json:"latency_ms,omitempty"`
+	Error     string `json:"error,omitempty"`
+}
+
+// natReport is what STUN servers saw of one local UDP socket.
+type natReport struct {
+	Type            string   `json:"type"`
+	MappedAddresses []string `json:"mapped_addresses,omitempty"`
+	STUNServers     []string `json:"stun_servers"`
+}
+
+// peerPath is how traffic from this machine reaches a peer: directly to
+// Endpoint, or relayed through the peer's home DERP region.
+type peerPath struct {
+	Name          string     `json:"name"`
+	Addresses     []string   `json:"ip_addresses"`
+	Online        bool       `json:"online"`
+	Path          string     `json:"path"`
+	Endpoint      string     `json:"endpoint,omitempty"`
+	Relay         string     `json:"relay,omitempty"`
+	LastHandshake *time.Time `json:"last_handshake,omitempty"`
+}
+
+// derpMap is the subset of tailscaled's DERP map used by wonder net doctor.
+type derpMap struct {
+	Regions map[int]*derpRegion `json:"Regions"`
+}
+
+// derpRegion is a DERP region with its relay and STUN servers.
+type derpRegion struct {
+	RegionID   int         `json:"RegionID"`
+	RegionCode string      `json:"RegionCode"`
+	Nodes      []*derpNode `json:"Nodes"`
+}
+
+// derpNode is a server of a DERP region. A STUNPort of 0 means the default
+// port and -1 disables STUN; a DERPPort of 0 means 443.
+type derpNode struct {
+	HostName string `json:"HostName"`
+	IPv4     string `json:"IPv4"`
+	STUNPort int    `json:"STUNPort"`
+	DERPPort int    `json:"DERPPort"`
+	STUNOnly bool   `json:"STUNOnly"`
+}
+
+// NewNetCmd creates the net command group for diagnosing this machine's
+// mesh connectivity.
+func NewNetCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "net",
+		Short: "Mesh network diagnostics",
+		Long:  `Commands for diagnosing this machine's connectivity to Wonder Mesh Net.`,
+	}
+
+	cmd.AddCommand(newDoctorCmd())
+	return cmd
+}
+
+// newDoctorCmd creates the doctor subcommand that checks every hop between
+// this machine, the coordinator, and its peers.
+func newDoctorCmd() *cobra.Command {
+	var (
+		coordinatorURL string
+		stunServers    []string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check end-to-end mesh connectivity",
+		Long: `Check end-to-end connectivity and print a report for troubleshooting.
+
+The checks are:
+  coordinator  the coordinator answers its health check and the local clock agrees with it
+  headscale    Headscale answers its health check through the coordinator's proxy
+  tailscaled   the local tailscaled is running and logged in
+  derp         the DERP relays of tailscaled's DERP map answer over HTTPS
+  nat          the NAT type, from the addresses STUN servers see for one UDP socket
+  peers        whether each peer is reached directly or relayed through DERP
+
+The coordinator defaults to the one this machine joined. The STUN servers
+default to those of the first DERP regions; --stun-server sets them, which
+also allows the NAT check while tailscaled is not running. Peers that have
+not exchanged traffic yet show as relayed until a connection is attempted,
+e.g. with tailscale ping.
+
+Use --output json for a report to attach to bug reports. The command exits
+with an error if a check failed.`,
+		SilenceUsage: true,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			format, err := output.FromCommand(cmd)
+			if err != nil {
+				return err
+			}
+
+			if coordinatorURL == "" {
+				if creds, err := loadCredentials(); err == nil {
+					coordinatorURL = creds.CoordinatorURL
+				}
+			}
+
+			report := runDoctor(cmd.Context(), strings.TrimRight(coordinatorURL, "/"), stunServers)
+			if err := output.Print(os.Stdout, format, report, func(w io.Writer) error {
+				return printDoctorReport(w, report)
+			}); err != nil {
+				return err
+			}
+
+			var failed []string
+			for _, check := range report.Checks {
+				if check.Status == checkFail {
+					failed = append(failed, check.Name)
+				}
+			}
+			if len(failed) > 0 {
+				return fmt.Errorf("failed checks: %s", strings.Join(failed, ", "))
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&coordinatorURL, "coordinator-url", "", "Coordinator URL (default: the coordinator this machine joined)")
+	cmd.Flags().StringSliceVar(&stunServers, "stun-server", nil, "STUN server as host:port for the NAT check (repeatable)")
+	return cmd
+}
+
+// runDoctor runs all checks. Checks that depend on a missing coordinator URL
+// or tailscaled are skipped.
+func runDoctor(ctx context.Context, coordinatorURL string, stunServers []string) *doctorReport {
+	report := &doctorReport{CoordinatorURL: coordinatorURL}
+
+	if coordinatorURL == "" {
+		for _, name := range []string{"coordinator", "headscale"} {
+			report.Checks = append(report.Checks, doctorCheck{
+				Name:   name,
+				Status: checkSkip,
+				Detail: "no coordinator URL",
+				Fix:    "pass --coordinator-url or join with wonder worker join",
+			})
+		}
+	} else {
+		report.Checks = append(report.Checks, checkDoctorCoordinator(coordinatorURL), checkHeadscale(ctx, coordinatorURL))
+	}
+
+	statusCtx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	status, statusErr := readTailscaleStatus(statusCtx)
+	var derps *derpMap
+	if status != nil {
+		var m derpMap
+		if _, err := getTailscaleLocalAPI(statusCtx, "derpmap", &m); err == nil {
+			derps = &m
+		}
+	}
+	cancel()
+	report.Checks = append(report.Checks, checkTailscaled(status, statusErr))
+
+	derpCheck := doctorCheck{Name: "derp", Status: checkSkip, Detail: "no DERP map, tailscaled is not running"}
+	if derps != nil {
+		report.DERP = probeDERP(ctx, derps)
+		derpCheck = summarizeDERP(report.DERP)
+	}
+	report.Checks = append(report.Checks, derpCheck)
+
+	if len(stunServers) == 0 && derps != nil {
+		stunServers = stunTargets(derps, maxSTUNServers)
+	}
+	natCheck := doctorCheck{Name: "nat", Status: checkSkip, Detail: "no STUN servers", Fix: "start tailscaled or pass --stun-server"}
+	if len(stunServers) > 0 {
+		report.NAT = checkNAT(ctx, stunServers)
+		natCheck = summarizeNAT(report.NAT)
+	}
+	report.Checks = append(report.Checks, natCheck)
+
+	peersCheck := doctorCheck{Name: "peers", Status: checkSkip, Detail: "tailscaled is not running"}
+	if status != nil {
+		report.Peers = peerPaths(status)
+		peersCheck = summarizePeers(report.Peers)
+	}
+	report.Checks = append(report.Checks, peersCheck)
+
+	return report
+}
+
+// checkDoctorCoordinator runs the coordinator preflight check of
+// wonder worker join.
+func checkDoctorCoordinator(coordinatorURL string) doctorCheck {
+	detail, err := checkCoordinator(coordinatorURL)
+	if err != nil {
+		var pe *preflightError
+		if errors.As(err, &pe) {
+			return doctorCheck{Name: "coordinator", Status: checkFail, Detail: pe.problem, Fix: pe.fix}
+		}
+		return doctorCheck{Name: "coordinator", Status: checkFail, Detail: err.Error()}
+	}
+	return doctorCheck{Name: "coordinator", Status: checkOK, Detail: detail}
+}
+
+// checkHeadscale checks Headscale's health endpoint, which the coordinator
+// proxies along with the rest of the Tailscale control protocol.
+func checkHeadscale(ctx context.Context, coordinatorURL string) doctorCheck {
+	check := doctorCheck{Name: "headscale"}
+
+	ctx, cancel := context.WithTimeout(ctx, doctorProbeTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, coordinatorURL+"/health", nil)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+
+	start := time.Now()
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		check.Status, check.Detail = checkFail, err.Error()
+		return check
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		var health struct {
+			Status string `json:"status"`
+		}
+		_ = json.NewDecoder(io.LimitReader(resp.Body, 1024)).Decode(&health)
+		check.Status = checkFail
+		check.Detail = strings.TrimSpace("health check returned " + resp.Status + " " + health.Status)
+		check.Fix = "the coordinator cannot reach Headscale or Headscale cannot reach its database; contact the coordinator operator"
+		return check
+	}
+	check.Status = checkOK
+	check.Detail = fmt.Sprintf("healthy in %s", time.Since(start).Round(time.Millisecond))
+	return check
+}
+
+// checkTailscaled checks that tailscaled is running and logged in.
+func checkTailscaled(status *tailscaleStatus, err error) doctorCheck {
+	check := doctorCheck{Name: "tailscaled"}
+	switch {
+	case err != nil:
+		check.Status, check.Detail = checkFail, err.Error()
+		check.Fix = "make sure tailscaled is running"
+	case status == nil:
+		check.Status, check.Detail = checkFail, "tailscaled is not running"
+		check.Fix = "start tailscaled, e.g. sudo systemctl start tailscaled"
+	case status.BackendState != "Running":
+		check.Status, check.Detail = checkFail, "state "+status.BackendState
+		check.Fix = "join the mesh with wonder worker join"
+	default:
+		check.Status = checkOK
+		check.Detail = "Running, " + orNone(strings.Join(status.TailscaleIPs, ", "))
+	}
+	return check
+}
+
+// probeDERP requests the probe endpoint of the first relay of each DERP
+// region in parallel, and returns the results in region order.
+func probeDERP(ctx context.Context, m *derpMap) []derpProbe {
+	ids := make([]int, 0, len(m.Regions))
+	for id := range m.Regions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	probes := make([]derpProbe, 0, len(ids))
+	var urls []string
+	for _, id := range ids {
+		region := m.Regions[id]
+		for _, node := range region.Nodes {
+			if node.STUNOnly {
+				continue
+			}
+			host := node.HostName
+			if node.DERPPort != 0 {
+				host = net.JoinHostPort(host, strconv.Itoa(node.DERPPort))
+			}
+			probes = append(probes, derpProbe{Region: region.RegionCode, Host: host})
+			urls = append(urls, "https://"+host+"/derp/probe")
+			break
+		}
+	}
+
+	client := &http.Client{Timeout: doctorProbeTimeout}
+	var wg sync.WaitGroup
+	for i := range probes {
+		wg.Go(func() {
+			req, err := http.NewRequestWithContext(ctx, http.MethodGet, urls[i], nil)
+			if err != nil {
+				probes[i].Error = err.Error()
+				return
+			}
+			start := time.Now()
+			resp, err := client.Do(req)
+			if err != nil {
+				probes[i].Error = err.Error()
+				return
+			}
+			_ = resp.Body.Close()
+			if resp.StatusCode != http.StatusOK {
+				probes[i].Error = resp.Status
+				return
+			}
+			probes[i].LatencyMS = max(time.Since(start).Milliseconds(), 1)
+		})
+	}
+	wg.Wait()
+	return probes
+}
+
+// summarizeDERP passes if any DERP region is reachable, as tailscaled only
+// needs its home region, and warns about the unreachable ones.
+func summarizeDERP(probes []derpProbe) doctorCheck {
+	check := doctorCheck{Name: "derp"}
+	var reachable, unreachable []string
+	for _, p := range probes {
+		if p.Error == "" {
+			reachable = append(reachable, p.Region)
+		} else {
+			unreachable = append(unreachable, p.Region)
+		}
+	}
+
+	switch {
+	case len(probes) == 0:
+		check.Status, check.Detail = checkFail, "the DERP map has no relays"
+		check.Fix = "check the derp section of the Headscale configuration"
+	case len(reachable) == 0:
+		check.Status, check.Detail = checkFail, "no DERP region is reachable"
+		check.Fix = "allow outbound HTTPS to the DERP relays; without them peers behind NAT cannot connect"
+	case len(unreachable) > 0:
+		check.Status = checkWarn
+		check.Detail = fmt.Sprintf("%d of %d regions reachable, not %s", len(reachable), len(probes), strings.Join(unreachable, ", "))
+	default:
+		check.Status = checkOK
+		check.Detail = fmt.Sprintf("%d regions reachable", len(reachable))
+	}
+	return check
+}
+
+// stunTargets returns the STUN servers of the first n DERP regions that
+// have one.
+func stunTargets(m *derpMap, n int) []string {
+	ids := make([]int, 0, len(m.Regions))
+	for id := range m.Regions {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+
+	var targets []string
+	for _, id := range ids {
+		if len(targets) == n {
+			break
+		}
+		for _, node := range m.Regions[id].Nodes {
+			if node.STUNPort < 0 {
+				continue
+			}
+			host := node.IPv4
+			if host == "" {
+				host = node.HostName
+			}
+			port := node.STUNPort
+			if port == 0 {
+				port = defaultSTUNPort
+			}
+			targets = append(targets, net.JoinHostPort(host, strconv.Itoa(port)))
+			break
+		}
+	}
+	return targets
+}
+
+// checkNAT asks each STUN server for the address it sees for one local UDP
+// socket and derives the NAT type from the answers.
+func checkNAT(ctx context.Context, servers []string) *natReport {
+	report := &natReport{STUNServers: servers, Type: natUDPBlocked}
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		return report
+	}
+	defer func() { _ = conn.Close() }()
+
+	var mapped []netip.AddrPort
+	for _, server := range servers {
+		addr, err := net.ResolveUDPAddr("udp4", server)
+		if err != nil {
+			continue
+		}
+		m, _, err := stunProbe(ctx, conn, addr)
+		if err != nil {
+			continue
+		}
+		mapped = append(mapped, m)
+		report.MappedAddresses = append(report.MappedAddresses, m.String())
+	}
+
+	report.Type = classifyNAT(mapped, localAddrs())
+	return report
+}
+
+// localAddrs returns the addresses of this machine's interfaces.
+func localAddrs() []netip.Addr {
+	addrs, err := net.InterfaceAddrs()
+	if err != nil {
+		return nil
+	}
+	var local []netip.Addr
+	for _, a := range addrs {
+		if prefix, err := netip.ParsePrefix(a.String()); err == nil {
+			local = append(local, prefix.Addr())
+		}
+	}
+	return local
+}
+
+// summarizeNAT fails if UDP is blocked, since peers then always need DERP,
+// and warns about NATs that make direct connections unlikely.
+func summarizeNAT(nat *natReport) doctorCheck {
+	check := doctorCheck{Name: "nat", Detail: nat.Type}
+	switch nat.Type {
+	case natNone, natEndpointIndependent:
+		check.Status = checkOK
+	case natEndpointDependent:
+		check.Status = checkWarn
+		check.Fix = "the NAT changes the mapping per destination; direct connections may fail and use DERP instead"
+	case natUDPBlocked:
+		check.Status = checkFail
+		check.Fix = "allow outbound UDP; without it all traffic is relayed over DERP"
+	default:
+		check.Status = checkWarn
+		check.Detail = "only one STUN server answered"
+	}
+	if len(nat.MappedAddresses) > 0 {
+		check.Detail += ", seen as " + strings.Join(nat.MappedAddresses, ", ")
+	}
+	return check
+}
+
+// peerPaths returns the path to each peer, sorted by name.
+func peerPaths(status *tailscaleStatus) []peerPath {
+	paths := make([]peerPath, 0, len(status.Peer))
+	for _, peer := range status.Peer {
+		p := peerPath{
+			Name:      peer.HostName,
+			Addresses: peer.TailscaleIPs,
+			Online:    peer.Online,
+		}
+		if !peer.LastHandshake.IsZero() {
+			handshake := peer.LastHandshake
+			p.LastHandshake = &handshake
+		}
+		switch {
+		case !peer.Online:
+			p.Path = peerPathOffline
+		case peer.CurAddr != "":
+			p.Path = peerPathDirect
+			p.Endpoint = peer.CurAddr
+		default:
+			p.Path = peerPathRelayed
+			p.Relay = peer.Relay
+		}
+		paths = append(paths, p)
+	}
+	slices.SortFunc(paths, func(a, b peerPath) int { return strings.Compare(a.Name, b.Name) })
+	return paths
+}
+
+// summarizePeers warns if any online peer is only reached through DERP.
+func summarizePeers(paths []peerPath) doctorCheck {
+	check := doctorCheck{Name: "peers", Status: checkOK}
+	counts := make(map[string]int)
+	for _, p := range paths {
+		counts[p.Path]++
+	}
+	check.Detail = fmt.Sprintf("%d direct, %d relayed, %d offline", counts[peerPathDirect], counts[peerPathRelayed], counts[peerPathOffline])
+	if counts[peerPathRelayed] > 0 {
+		check.Status = checkWarn
+	}
+	return check
+}
+
+// printDoctorReport prints the human-readable doctor report.
+func printDoctorReport(out io.Writer, report *doctorReport) error {
+	w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "CHECK\tSTATUS\tDETAIL")
+	for _, check := range report.Checks {
+		fmt.Fprintf(w, "%s\t%s\t%s\n", check.Name, check.Status, check.Detail)
+	}
+	if err := w.Flush(); err != nil {
+		return err
+	}
+
+	for _, check := range report.Checks {
+		if check.Fix != "" && check.Status != checkOK {
+			fmt.Fprintf(out, "\nTo fix %s:\n  %s\n", check.Name, strings.ReplaceAll(check.Fix, "\n", "\n  "))
+		}
+	}
+
+	if len(report.DERP) > 0 {
+		fmt.Fprintln(out, "\nDERP Regions")
+		w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "REGION\tHOST\tLATENCY\tERROR")
+		for _, p := range report.DERP {
+			latency, errText := "-", "-"
+			if p.Error == "" {
+				latency = fmt.Sprintf("%dms", p.LatencyMS)
+			} else {
+				errText = p.Error
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Region, p.Host, latency, errText)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+
+	if len(report.Peers) > 0 {
+		fmt.Fprintln(out, "\nPeers")
+		w = tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "NAME\tADDRESSES\tPATH\tVIA")
+		for _, p := range report.Peers {
+			via := "-"
+			switch p.Path {
+			case peerPathDirect:
+				via = p.Endpoint
+			case peerPathRelayed:
+				via = "DERP " + orNone(p.Relay)
+			}
+			fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", p.Name, strings.Join(p.Addresses, ","), p.Path, via)
+		}
+		if err := w.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,78 @@
+package worker
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+)
+
+func TestPeerPaths(t *testing.T) {
+	raw := `{
+		"BackendState": "Running",
+		"Peer": {
+			"nodekey:a": {"HostName": "worker-2", "TailscaleIPs": ["100.64.0.2"], "Online": true, "Relay": "fra", "CurAddr": "192.0.2.10:41641"},
+			"nodekey:b": {"HostName": "worker-1", "TailscaleIPs": ["100.64.0.1"], "Online": true, "Relay": "ams"},
+			"nodekey:c": {"HostName": "worker-3", "Online": false, "Relay": "ams"}
+		}
+	}`
+	var status tailscaleStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+
+	got := peerPaths(&status)
+	want := []peerPath{
+		{Name: "worker-1", Addresses: []string{"100.64.0.1"}, Online: true, Path: peerPathRelayed, Relay: "ams"},
+		{Name: "worker-2", Addresses: []string{"100.64.0.2"}, Online: true, Path: peerPathDirect, Endpoint: "192.0.2.10:41641"},
+		{Name: "worker-3", Path: peerPathOffline},
+	}
+	if !slices.EqualFunc(got, want, func(a, b peerPath) bool {
+		return a.Name == b.Name && slices.Equal(a.Addresses, b.Addresses) && a.Online == b.Online &&
+			a.Path == b.Path && a.Endpoint == b.Endpoint && a.Relay == b.Relay
+	}) {
+		t.Errorf("peerPaths() = %+v, want %+v", got, want)
+	}
+
+	if check := summarizePeers(got); check.Status != checkWarn || check.Detail != "1 direct, 1 relayed, 1 offline" {
+		t.Errorf("summarizePeers() = %+v, want a warning for the relayed peer", check)
+	}
+}
+
+func TestSTUNTargets(t *testing.T) {
+	m := &derpMap{Regions: map[int]*derpRegion{
+		3: {RegionID: 3, Nodes: []*derpNode{{HostName: "derp3.example.com"}}},
+		1: {RegionID: 1, Nodes: []*derpNode{{HostName: "derp1.example.com", IPv4: "192.0.2.1", STUNPort: 3479}}},
+		2: {RegionID: 2, Nodes: []*derpNode{
+			{HostName: "derp2a.example.com", STUNPort: -1},
+			{HostName: "derp2b.example.com"},
+		}},
+		4: {RegionID: 4, Nodes: []*derpNode{{HostName: "derp4.example.com"}}},
+	}}
+
+	got := stunTargets(m, 3)
+	want := []string{"192.0.2.1:3479", "derp2b.example.com:3478", "derp3.example.com:3478"}
+	if !slices.Equal(got, want) {
+		t.Errorf("stunTargets() = %v, want %v", got, want)
+	}
+}
+
+func TestSummarizeDERP(t *testing.T) {
+	tests := []struct {
+		name   string
+		probes []derpProbe
+		want   string
+	}{
+		{name: "no relays", probes: nil, want: checkFail},
+		{name: "all reachable", probes: []derpProbe{{Region: "fra", LatencyMS: 12}, {Region: "ams", LatencyMS: 20}}, want: checkOK},
+		{name: "some unreachable", probes: []derpProbe{{Region: "fra", LatencyMS: 12}, {Region: "ams", Error: "timeout"}}, want: checkWarn},
+		{name: "none reachable", probes: []derpProbe{{Region: "ams", Error: "timeout"}}, want: checkFail},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := summarizeDERP(tt.probes); got.Status != tt.want {
+				t.Errorf("summarizeDERP() = %+v, want status %q", got, tt.want)
+			}
+		})
+	}
+}
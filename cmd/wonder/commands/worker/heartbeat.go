@@ -0,0 +1,110 @@
+package worker
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+)
+
+// heartbeat is the health report sent to the coordinator, which stores it
+// for the node the worker token is bound to. Connectivity is nil when
+// tailscaled cannot be queried.
+type heartbeat struct {
+	OS                   string        `json:"os"`
+	TailscaleVersion     string        `json:"tailscale_version"`
+	DiskTotalBytes       int64         `json:"disk_total_bytes"`
+	DiskFreeBytes        int64         `json:"disk_free_bytes"`
+	MemoryTotalBytes     int64         `json:"memory_total_bytes"`
+	MemoryAvailableBytes int64         `json:"memory_available_bytes"`
+	Connectivity         *connectivity `json:"connectivity,omitempty"`
+}
+
+// collectHeartbeat gathers this machine's health. Stats that cannot be read
+// are reported as zero.
+func collectHeartbeat(ctx context.Context) *heartbeat {
+	hb := &heartbeat{
+		OS:               osName(),
+		TailscaleVersion: tailscaleVersion(),
+	}
+
+	hb.DiskTotalBytes, hb.DiskFreeBytes = diskStats()
+	hb.MemoryTotalBytes, hb.MemoryAvailableBytes = memoryStats()
+	hb.Connectivity = collectConnectivity(ctx)
+	return hb
+}
+
+// osName returns the distribution name from /etc/os-release, or the Go
+// operating system name where there is none.
+func osName() string {
+	f, err := os.Open("/etc/os-release")
+	if err != nil {
+		return runtime.GOOS
+	}
+	defer func() { _ = f.Close() }()
+
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		if value, ok := strings.CutPrefix(scanner.Text(), "PRETTY_NAME="); ok {
+			return strings.Trim(value, `"'`)
+		}
+	}
+	return runtime.GOOS
+}
+
+// tailscaleVersion returns the first line of `tailscale version`, or an
+// empty string if it cannot be run.
+func tailscaleVersion() string {
+	out, err := exec.Command("tailscale", "version").Output()
+	if err != nil {
+		return ""
+	}
+	return strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+}
+
+// memoryStats returns the total and available memory in bytes. On macOS
+// only the total is known.
+func memoryStats() (total, available int64) {
+	if runtime.GOOS == "darwin" {
+		out, err := exec.Command("sysctl", "-n", "hw.memsize").Output()
+		if err != nil {
+			return 0, 0
+		}
+		total, _ = strconv.ParseInt(strings.TrimSpace(string(out)), 10, 64)
+		return total, 0
+	}
+
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return 0, 0
+	}
+	defer func() { _ = f.Close() }()
+	return parseMeminfo(f)
+}
+
+// parseMeminfo reads MemTotal and MemAvailable, given in kB, from the
+// contents of /proc/meminfo.
+func parseMeminfo(r io.Reader) (total, available int64) {
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		kb, err := strconv.ParseInt(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		switch fields[0] {
+		case "MemTotal:":
+			total = kb * 1024
+		case "MemAvailable:":
+			available = kb * 1024
+		}
+	}
+	return total, available
+}
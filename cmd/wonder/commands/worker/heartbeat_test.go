@@ -0,0 +1,23 @@
+package worker
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseMeminfo(t *testing.T) {
+	meminfo := `MemTotal:        8038716 kB
+MemFree:          512340 kB
+MemAvailable:    4019356 kB
+Buffers:          120044 kB
+HugePages_Total:       0
+`
+
+	total, available := parseMeminfo(strings.NewReader(meminfo))
+	if total != 8038716*1024 {
+		t.Errorf("parseMeminfo() total = %d, want %d", total, 8038716*1024)
+	}
+	if available != 4019356*1024 {
+		t.Errorf("parseMeminfo() available = %d, want %d", available, 4019356*1024)
+	}
+}
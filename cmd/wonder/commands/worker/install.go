@@ -0,0 +1,408 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// Names of the worker service on each platform.
+const (
+	systemdUnitName    = "wonder-worker.service"
+	systemdUnitPath    = "/etc/systemd/system/" + systemdUnitName
+	launchdLabel       = "com.wonder-mesh-net.worker"
+	launchdPlistPath   = "/Library/LaunchDaemons/" + launchdLabel + ".plist"
+	launchdLogPath     = "/var/log/wonder-worker.log"
+	windowsServiceName = "WonderWorker"
+	serviceDisplayName = "Wonder Mesh Net worker"
+
+	// tailscaledPlistPath is where tailscaled install-system-daemon puts
+	// the launchd job of the open source tailscaled on macOS.
+	tailscaledPlistPath = "/Library/LaunchDaemons/com.tailscale.tailscaled.plist"
+	// windowsTailscaleService is the service installed by Tailscale for
+	// Windows.
+	windowsTailscaleService = "Tailscale"
+)
+
+var installFlags struct {
+	dryRun      bool
+	interval    time.Duration
+	wipeCommand string
+	renew       bool
+	sshCA       bool
+}
+
+// newInstallCmd creates the install subcommand that joins this device and
+// keeps it in the mesh across reboots.
+func newInstallCmd() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "install [token]",
+		Short: "Join the mesh and install the worker as a system service",
+		Long: `Set up this machine as a worker that stays in the mesh across reboots.
+
+install enables tailscaled at boot, joins the mesh with a join token or an API
+key like wonder worker join (or reuses the credentials of an earlier join when
+neither is given), and installs wonder worker daemon as a system service that
+starts on boot:
+  Linux    systemd unit ` + systemdUnitPath + `
+  macOS    launchd daemon ` + launchdPlistPath + `
+  Windows  service ` + windowsServiceName + `, created with sc.exe
+
+The service runs as root (LocalSystem on Windows) and reads the credentials
+written by the join from ~/.wonder/worker.json of the user running install.
+Run install from an administrator shell on Windows; elsewhere it uses sudo.
+
+Workers joined with an API key have no worker token to send heartbeats with,
+so for them only tailscaled is enabled.
+
+With --ssh-ca (Linux and macOS), sshd is configured to accept user
+certificates signed by the wonder net's SSH CA, which deployers get from
+POST /coordinator/api/v1/ssh-cert: the CA key is written to
+` + sshCAKeyPath + ` and ` + sshdDropInPath + `
+sets TrustedUserCAKeys to it.
+
+Use --dry-run to print the service definition without changing anything.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: runInstall,
+	}
+
+	cmd.Flags().StringVar(&joinFlags.coordinatorURL, "coordinator-url", "", "Override the coordinator URL from the token (required with --api-key)")
+	cmd.Flags().StringVar(&joinFlags.apiKey, "api-key", "", "Join with an API key instead of a join token (env: WONDER_API_KEY)")
+	cmd.Flags().BoolVar(&joinFlags.nonInteractive, "non-interactive", false, "Never prompt for input; fail instead")
+	cmd.Flags().BoolVar(&joinFlags.skipPreflight, "skip-preflight", false, "Skip local prerequisite checks before joining")
+	cmd.Flags().BoolVar(&joinFlags.forceReauth, "force-reauth", false, "Register again even if already logged in to this login server")
+	cmd.Flags().BoolVar(&joinFlags.switchServer, "switch", false, "Move this machine off another control server, backing up its preferences")
+	cmd.Flags().BoolVar(&installFlags.dryRun, "dry-run", false, "Print the service definition instead of installing it")
+	cmd.Flags().DurationVar(&installFlags.interval, "interval", time.Minute, "Time between heartbeats of the installed daemon")
+	cmd.Flags().StringVar(&installFlags.wipeCommand, "wipe-command", "", "Command the installed daemon runs with sh when the node is decommissioned with a wipe")
+	cmd.Flags().BoolVar(&installFlags.renew, "renew", false, "Have the installed daemon log in again with a new authkey when tailscale needs a login")
+	cmd.Flags().BoolVar(&installFlags.sshCA, "ssh-ca", false, "Configure sshd to accept user certificates signed by the wonder net's SSH CA")
+
+	return cmd
+}
+
+// runInstall enables tailscaled, joins if a token or API key is given, and
+// installs the daemon service for the current platform.
+func runInstall(cmd *cobra.Command, args []string) error {
+	if installFlags.interval < 5*time.Second {
+		return fmt.Errorf("--interval must be at least 5s")
+	}
+	switch runtime.GOOS {
+	case "linux", "darwin", "windows":
+	default:
+		return fmt.Errorf("installing a service is not supported on %s, run wonder worker daemon with your init system instead", runtime.GOOS)
+	}
+
+	exe, err := os.Executable()
+	if err != nil {
+		return fmt.Errorf("locate wonder executable: %w", err)
+	}
+	if resolved, err := filepath.EvalSymlinks(exe); err == nil {
+		exe = resolved
+	}
+	credentialPath, err := getCredentialsPath()
+	if err != nil {
+		return err
+	}
+	if credentialPath, err = filepath.Abs(credentialPath); err != nil {
+		return err
+	}
+	daemonArgs := workerDaemonArgs(credentialPath, installFlags.interval, installFlags.wipeCommand, installFlags.renew)
+
+	if installFlags.dryRun {
+		printServiceDefinition(runtime.GOOS, exe, daemonArgs)
+		if installFlags.sshCA {
+			fmt.Printf("# %s\n%s", sshdDropInPath, renderSSHDDropIn())
+		}
+		return nil
+	}
+
+	if err := enableTailscaled(); err != nil {
+		return err
+	}
+
+	apiKey := joinFlags.apiKey
+	if apiKey == "" {
+		apiKey = os.Getenv("WONDER_API_KEY")
+	}
+	if len(args) > 0 || apiKey != "" {
+		if err := runJoin(cmd, args); err != nil {
+			return err
+		}
+	}
+
+	creds, err := loadCredentials()
+	if os.IsNotExist(err) {
+		return fmt.Errorf("not joined to any mesh, pass a join token or --api-key")
+	}
+	if err != nil {
+		return fmt.Errorf("%w (run wonder worker repair to fix it)", err)
+	}
+	if installFlags.sshCA {
+		if err := configureSSHCA(runtime.GOOS, creds.SSHCAPublicKey); err != nil {
+			return err
+		}
+		fmt.Println("sshd accepts user certificates of the wonder net's SSH CA.")
+	}
+	if creds.WorkerToken == "" {
+		fmt.Println("tailscaled starts on boot. Joined with an API key, so there is no worker token for heartbeats and the daemon service is not installed.")
+		return nil
+	}
+
+	if err := installWorkerService(runtime.GOOS, exe, daemonArgs); err != nil {
+		return err
+	}
+	fmt.Println("Installed the wonder worker daemon as a system service; it starts on boot.")
+	return nil
+}
+
+// workerDaemonArgs returns the arguments the service passes to wonder.
+func workerDaemonArgs(credentialPath string, interval time.Duration, wipeCommand string, renew bool) []string {
+	args := []string{"worker", "daemon", "--credentials", credentialPath, "--interval", interval.String()}
+	if wipeCommand != "" {
+		args = append(args, "--wipe-command", wipeCommand)
+	}
+	if renew {
+		args = append(args, "--renew")
+	}
+	return args
+}
+
+// printServiceDefinition prints what installWorkerService would install.
+func printServiceDefinition(goos, exe string, args []string) {
+	switch goos {
+	case "linux":
+		fmt.Printf("# %s\n%s", systemdUnitPath, renderSystemdUnit(exe, args))
+	case "darwin":
+		fmt.Printf("<!-- %s -->\n%s", launchdPlistPath, renderLaunchdPlist(exe, args))
+	case "windows":
+		fmt.Println("sc.exe " + windowsCommandLine(windowsServiceCreateArgs(exe, args)))
+	}
+}
+
+// enableTailscaled makes the tailscale daemon start on boot and starts it.
+func enableTailscaled() error {
+	switch runtime.GOOS {
+	case "linux":
+		if err := runPrivileged("systemctl", "enable", "--now", "tailscaled"); err != nil {
+			return fmt.Errorf("enable tailscaled: %w; install Tailscale from its packages, which ship tailscaled.service", err)
+		}
+	case "darwin":
+		// The Tailscale app starts its own network extension at login and
+		// has no tailscaled binary; only the open source daemon needs a job.
+		if _, err := exec.LookPath("tailscaled"); err != nil {
+			return nil
+		}
+		if _, err := os.Stat(tailscaledPlistPath); err == nil {
+			return nil
+		}
+		if err := runPrivileged("tailscaled", "install-system-daemon"); err != nil {
+			return fmt.Errorf("install tailscaled launch daemon: %w", err)
+		}
+	case "windows":
+		if err := runPrivileged("sc.exe", "config", windowsTailscaleService, "start=", "auto"); err != nil {
+			return fmt.Errorf("enable %s service: %w; is Tailscale for Windows installed?", windowsTailscaleService, err)
+		}
+		// Fails if the service is already running.
+		_ = runPrivileged("sc.exe", "start", windowsTailscaleService)
+	}
+	return nil
+}
+
+// installWorkerService writes the service definition for goos, replacing
+// an earlier one, and (re)starts the service.
+func installWorkerService(goos, exe string, args []string) error {
+	switch goos {
+	case "linux":
+		if err := writeSystemFile(systemdUnitPath, renderSystemdUnit(exe, args)); err != nil {
+			return err
+		}
+		for _, systemctlArgs := range [][]string{
+			{"daemon-reload"},
+			{"enable", systemdUnitName},
+			{"restart", systemdUnitName},
+		} {
+			if err := runPrivileged("systemctl", systemctlArgs...); err != nil {
+				return fmt.Errorf("systemctl %s: %w", strings.Join(systemctlArgs, " "), err)
+			}
+		}
+	case "darwin":
+		if err := writeSystemFile(launchdPlistPath, renderLaunchdPlist(exe, args)); err != nil {
+			return err
+		}
+		// Unload an earlier version of the job; fails if there is none.
+		_ = runPrivileged("launchctl", "bootout", "system/"+launchdLabel)
+		if err := runPrivileged("launchctl", "bootstrap", "system", launchdPlistPath); err != nil {
+			return fmt.Errorf("load launch daemon: %w", err)
+		}
+	case "windows":
+		// Remove an earlier version of the service; fails if there is none.
+		_ = runPrivileged("sc.exe", "stop", windowsServiceName)
+		_ = runPrivileged("sc.exe", "delete", windowsServiceName)
+		if err := runPrivileged("sc.exe", windowsServiceCreateArgs(exe, args)...); err != nil {
+			return fmt.Errorf("create service: %w", err)
+		}
+		if err := runPrivileged("sc.exe", "failure", windowsServiceName, "reset=", "86400", "actions=", "restart/10000"); err != nil {
+			return fmt.Errorf("configure service restarts: %w", err)
+		}
+		if err := runPrivileged("sc.exe", "start", windowsServiceName); err != nil {
+			return fmt.Errorf("start service: %w", err)
+		}
+	default:
+		return fmt.Errorf("installing a service is not supported on %s", goos)
+	}
+	return nil
+}
+
+// renderSystemdUnit returns a systemd unit running exe with args, restarted
+// unless it exits cleanly, as after a wipe.
+func renderSystemdUnit(exe string, args []string) string {
+	words := make([]string, 0, len(args)+1)
+	for _, arg := range append([]string{exe}, args...) {
+		words = append(words, systemdQuote(arg))
+	}
+
+	return `[Unit]
+Description=` + serviceDisplayName + `
+Wants=network-online.target tailscaled.service
+After=network-online.target tailscaled.service
+
+[Service]
+ExecStart=` + strings.Join(words, " ") + `
+Restart=on-failure
+RestartSec=10
+
+[Install]
+WantedBy=multi-user.target
+`
+}
+
+// systemdQuote quotes a word of an ExecStart line. Specifiers (%) and
+// variables ($) are escaped so that they are passed literally.
+func systemdQuote(s string) string {
+	s = strings.ReplaceAll(s, "%", "%%")
+	s = strings.ReplaceAll(s, "$", "$$")
+	if s != "" && !strings.ContainsAny(s, " \t\"'\\;") {
+		return s
+	}
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	return `"` + s + `"`
+}
+
+// renderLaunchdPlist returns a launchd job running exe with args at boot,
+// restarted unless it exits cleanly, as after a wipe.
+func renderLaunchdPlist(exe string, args []string) string {
+	var b strings.Builder
+	b.WriteString(`<?xml version="1.0" encoding="UTF-8"?>
+<!DOCTYPE plist PUBLIC "-//Apple//DTD PLIST 1.0//EN" "http://www.apple.com/DTDs/PropertyList-1.0.dtd">
+<plist version="1.0">
+<dict>
+	<key>Label</key>
+	<string>` + launchdLabel + `</string>
+	<key>ProgramArguments</key>
+	<array>
+`)
+	for _, arg := range append([]string{exe}, args...) {
+		b.WriteString("\t\t<string>")
+		_ = xml.EscapeText(&b, []byte(arg))
+		b.WriteString("</string>\n")
+	}
+	b.WriteString(`	</array>
+	<key>RunAtLoad</key>
+	<true/>
+	<key>KeepAlive</key>
+	<dict>
+		<key>SuccessfulExit</key>
+		<false/>
+	</dict>
+	<key>StandardErrorPath</key>
+	<string>` + launchdLogPath + `</string>
+</dict>
+</plist>
+`)
+	return b.String()
+}
+
+// windowsServiceCreateArgs returns the sc.exe arguments creating an
+// automatically started service running exe with args.
+func windowsServiceCreateArgs(exe string, args []string) []string {
+	return []string{
+		"create", windowsServiceName,
+		"binPath=", windowsCommandLine(append([]string{exe}, args...)),
+		"start=", "auto",
+		"DisplayName=", serviceDisplayName,
+	}
+}
+
+// windowsCommandLine joins args into a command line that Windows programs
+// split back into the same arguments, quoting like syscall.EscapeArg.
+func windowsCommandLine(args []string) string {
+	quoted := make([]string, len(args))
+	for i, arg := range args {
+		quoted[i] = windowsQuote(arg)
+	}
+	return strings.Join(quoted, " ")
+}
+
+func windowsQuote(s string) string {
+	if s != "" && !strings.ContainsAny(s, " \t\"") {
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteByte('"')
+	backslashes := 0
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch c {
+		case '\\':
+			backslashes++
+			continue
+		case '"':
+			// Backslashes before a quote are escaped, and so is the quote.
+			b.WriteString(strings.Repeat(`\`, 2*backslashes+1))
+		default:
+			b.WriteString(strings.Repeat(`\`, backslashes))
+		}
+		b.WriteByte(c)
+		backslashes = 0
+	}
+	// Backslashes before the closing quote are escaped.
+	b.WriteString(strings.Repeat(`\`, 2*backslashes))
+	b.WriteByte('"')
+	return b.String()
+}
+
+// writeSystemFile writes content to a root-owned path with tee.
+func writeSystemFile(path, content string) error {
+	cmd := privilegedCommand("tee", path)
+	cmd.Stdin = strings.NewReader(content)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("write %s: %w: %s", path, err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
+
+// runPrivileged runs a command as root, including its output in the error
+// if it fails.
+func runPrivileged(name string, args ...string) error {
+	out, err := privilegedCommand(name, args...).CombinedOutput()
+	if err != nil {
+		if msg := strings.TrimSpace(string(out)); msg != "" {
+			return fmt.Errorf("%w: %s", err, msg)
+		}
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,99 @@
+package worker
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWorkerDaemonArgs(t *testing.T) {
+	got := workerDaemonArgs("/home/alice/.wonder/worker.json", 2*time.Minute, "rm -rf /srv/data", true)
+	want := []string{
+		"worker", "daemon",
+		"--credentials", "/home/alice/.wonder/worker.json",
+		"--interval", "2m0s",
+		"--wipe-command", "rm -rf /srv/data",
+		"--renew",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("workerDaemonArgs() = %q, want %q", got, want)
+	}
+}
+
+func TestRenderSystemdUnit(t *testing.T) {
+	unit := renderSystemdUnit("/usr/local/bin/wonder", []string{"worker", "daemon", "--wipe-command", `echo "100%" $HOME`})
+
+	wantExec := `ExecStart=/usr/local/bin/wonder worker daemon --wipe-command "echo \"100%%\" $$HOME"` + "\n"
+	if !strings.Contains(unit, wantExec) {
+		t.Errorf("renderSystemdUnit() = %q, want line %q", unit, wantExec)
+	}
+	for _, want := range []string{"After=network-online.target tailscaled.service\n", "Restart=on-failure\n", "WantedBy=multi-user.target\n"} {
+		if !strings.Contains(unit, want) {
+			t.Errorf("renderSystemdUnit() = %q, want line %q", unit, want)
+		}
+	}
+}
+
+func TestRenderLaunchdPlist(t *testing.T) {
+	plist := renderLaunchdPlist("/usr/local/bin/wonder", []string{"worker", "daemon", "--wipe-command", "a < b && c"})
+
+	for _, want := range []string{
+		"<string>" + launchdLabel + "</string>",
+		"\t\t<string>/usr/local/bin/wonder</string>\n\t\t<string>worker</string>\n",
+		"<string>a &lt; b &amp;&amp; c</string>",
+		"<key>SuccessfulExit</key>\n\t\t<false/>",
+	} {
+		if !strings.Contains(plist, want) {
+			t.Errorf("renderLaunchdPlist() = %q, want %q", plist, want)
+		}
+	}
+}
+
+func TestWindowsQuote(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want string
+	}{
+		{`worker`, `worker`},
+		{``, `""`},
+		{`C:\Program Files\wonder.exe`, `"C:\Program Files\wonder.exe"`},
+		{`C:\Users\alice\`, `C:\Users\alice\`},
+		{`C:\Users\a b\`, `"C:\Users\a b\\"`},
+		{`say "hi"`, `"say \"hi\""`},
+		{`a\"b`, `"a\\\"b"`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.arg, func(t *testing.T) {
+			if got := windowsQuote(tt.arg); got != tt.want {
+				t.Errorf("windowsQuote(%q) = %s, want %s", tt.arg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSSHDIncludesDropIns(t *testing.T) {
+	tests := []struct {
+		config string
+		want   bool
+	}{
+		{"Include /etc/ssh/sshd_config.d/*.conf\nPermitRootLogin no\n", true},
+		{"include sshd_config.d/*.conf\n", true},
+		{"#Include /etc/ssh/sshd_config.d/*.conf\n", false},
+		{"Include /etc/ssh/other.d/*.conf\n", false},
+		{"PasswordAuthentication yes\n", false},
+	}
+	for _, tt := range tests {
+		if got := sshdIncludesDropIns(tt.config); got != tt.want {
+			t.Errorf("sshdIncludesDropIns(%q) = %v, want %v", tt.config, got, tt.want)
+		}
+	}
+}
+
+func TestRenderSSHDDropIn(t *testing.T) {
+	want := "TrustedUserCAKeys " + sshCAKeyPath + "\n"
+	if got := renderSSHDDropIn(); !strings.HasSuffix(got, want) {
+		t.Errorf("renderSSHDDropIn() = %q, want it to end with %q", got, want)
+	}
+}
@@ -41,34 +41,107 @@ func normalizeURL(rawURL string) string {
 
 var joinFlags struct {
 	coordinatorURL string
+	apiKey         string
+	nonInteractive bool
+	skipPreflight  bool
+	forceReauth    bool
+	switchServer   bool
+	renew          bool
 }
 
 // newJoinCmd creates the join subcommand that connects this device
-// to the Wonder Mesh Net using a join token.
+// to the Wonder Mesh Net using a join token or an API key.
 func newJoinCmd() *cobra.Command {
 	cmd := &cobra.Command{
-		Use:   "join <token>",
+		Use:   "join [token]",
 		Short: "Join the mesh network",
 		Long: `Join the Wonder Mesh Net using a join token.
 
 Get a join token from your coordinator dashboard, then run:
   wonder worker join <token>
 
+The wonder://join payload scanned from a join token QR code is accepted in
+place of the token.
+
 If the coordinator URL embedded in the token is not reachable (e.g., localhost
-from inside a container), use --coordinator-url to override it.`,
-		Args: cobra.ExactArgs(1),
+from inside a container), use --coordinator-url to override it.
+
+For unattended provisioning (e.g., cloud-init), join with an API key instead
+of a token. The API key can also be set with the WONDER_API_KEY environment
+variable to keep it out of the process list:
+  wonder worker join --coordinator-url https://wonder.example.com \
+    --api-key wmn_... --non-interactive
+
+With --non-interactive, the command never prompts (sudo runs with -n) and
+exits non-zero if any step fails.
+
+Before contacting the coordinator, join checks that Tailscale is installed
+and recent enough, that the coordinator is reachable, that the local clock
+agrees with it, and that this machine is not already connected to another
+control server. Use --skip-preflight to bypass these checks.
+
+If tailscaled is already logged in, join refuses to continue unless told how
+to handle it:
+  --force-reauth  register again with the same login server
+  --switch        leave another control server (e.g., a Tailscale tailnet);
+                  the current preferences are saved to ~/.wonder first
+
+Authkeys expire after 24 hours, and tailscale asks for a new login when the
+node key expires. A worker joined with a join token can then log in again
+without a new token, using the worker token stored on join:
+  wonder worker join --renew
+
+wonder worker daemon does this by itself when tailscale needs a login.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: runJoin,
 	}
 
-	cmd.Flags().StringVar(&joinFlags.coordinatorURL, "coordinator-url", "", "Override the coordinator URL from the token")
+	cmd.Flags().StringVar(&joinFlags.coordinatorURL, "coordinator-url", "", "Override the coordinator URL from the token (required with --api-key)")
+	cmd.Flags().StringVar(&joinFlags.apiKey, "api-key", "", "Join with an API key instead of a join token (env: WONDER_API_KEY)")
+	cmd.Flags().BoolVar(&joinFlags.nonInteractive, "non-interactive", false, "Never prompt for input; fail instead")
+	cmd.Flags().BoolVar(&joinFlags.skipPreflight, "skip-preflight", false, "Skip local prerequisite checks before joining")
+	cmd.Flags().BoolVar(&joinFlags.forceReauth, "force-reauth", false, "Register again even if already logged in to this login server")
+	cmd.Flags().BoolVar(&joinFlags.switchServer, "switch", false, "Move this machine off another control server, backing up its preferences")
+	cmd.Flags().BoolVar(&joinFlags.renew, "renew", false, "Log in again with a new authkey, using the credentials stored on join")
 
 	return cmd
 }
 
-// runJoin performs token-based join by exchanging the JWT token
-// with the coordinator for mesh credentials.
+// runJoin joins the mesh with a join token or, if --api-key is set, an API
+// key. With --renew, it logs in again with the stored credentials.
 func runJoin(cmd *cobra.Command, args []string) error {
+	apiKey := joinFlags.apiKey
+	if apiKey == "" {
+		apiKey = os.Getenv("WONDER_API_KEY")
+	}
+
+	switch {
+	case joinFlags.renew && (apiKey != "" || len(args) > 0):
+		return fmt.Errorf("--renew uses the stored credentials; pass no join token or --api-key")
+	case joinFlags.renew:
+		return runRenewJoin()
+	case apiKey != "" && len(args) > 0:
+		return fmt.Errorf("pass either a join token or --api-key, not both")
+	case apiKey != "":
+		return runAPIKeyJoin(apiKey)
+	case len(args) == 0:
+		return fmt.Errorf("a join token or --api-key is required")
+	}
+
 	token := args[0]
+	if jointoken.IsPayload(token) {
+		_, payloadToken, err := jointoken.ParsePayload(token)
+		if err != nil {
+			return err
+		}
+		token = payloadToken
+	}
+	return runTokenJoin(token)
+}
+
+// runTokenJoin performs token-based join by exchanging the JWT token
+// with the coordinator for mesh credentials.
+func runTokenJoin(token string) error {
 	info, err := jointoken.GetJoinInfo(token)
 	if err != nil {
 		return fmt.Errorf("invalid token: %w", err)
@@ -86,6 +159,12 @@ func runJoin(cmd *cobra.Command, args []string) error {
 	}
 	coordinatorURL = normalizeURL(coordinatorURL)
 
+	if !joinFlags.skipPreflight {
+		if err := runPreflight(coordinatorURL, normalizeURL(info.CoordinatorURL)); err != nil {
+			return err
+		}
+	}
+
 	reqBody, _ := json.Marshal(map[string]string{"token": token})
 	resp, err := http.Post(
 		coordinatorURL+"/coordinator/api/v1/worker/join",
@@ -110,10 +189,54 @@ func runJoin(cmd *cobra.Command, args []string) error {
 	return completeJoin(&result, coordinatorURL)
 }
 
+// runAPIKeyJoin exchanges an API key for mesh credentials through the
+// coordinator's deployer join endpoint, without a browser or join token.
+func runAPIKeyJoin(apiKey string) error {
+	if joinFlags.coordinatorURL == "" {
+		return fmt.Errorf("--coordinator-url is required with --api-key")
+	}
+	coordinatorURL := normalizeURL(joinFlags.coordinatorURL)
+
+	fmt.Println("Joining Wonder Mesh Net with API key...")
+
+	if !joinFlags.skipPreflight {
+		if err := runPreflight(coordinatorURL, coordinatorURL); err != nil {
+			return err
+		}
+	}
+
+	req, err := http.NewRequest(http.MethodPost, coordinatorURL+"/coordinator/api/v1/deployer/join", nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+apiKey)
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("join: %s", strings.TrimSpace(string(body)))
+	}
+
+	var result joinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+
+	return completeJoin(&result, coordinatorURL)
+}
+
 // joinResponse represents the response from the coordinator's join endpoint.
 type joinResponse struct {
 	MeshType                string                   `json:"mesh_type"`
 	TailscaleConnectionInfo *tailscaleConnectionInfo `json:"tailscale_connection_info,omitempty"`
+	WorkerToken             string                   `json:"worker_token,omitempty"`
+	SSHCAPublicKey          string                   `json:"ssh_ca_public_key,omitempty"`
 }
 
 // tailscaleConnectionInfo contains the credentials for joining a Tailscale/Headscale mesh.
@@ -177,6 +300,8 @@ func completeJoin(resp *joinResponse, coordinator string) error {
 			User:           info.HeadscaleUser,
 			CoordinatorURL: coordinator,
 			JoinedAt:       time.Now(),
+			WorkerToken:    resp.WorkerToken,
+			SSHCAPublicKey: resp.SSHCAPublicKey,
 		}
 		if err := saveCredentials(creds); err != nil {
 			fmt.Printf("Warning: save credentials: %v\n", err)
@@ -194,7 +319,7 @@ func completeJoin(resp *joinResponse, coordinator string) error {
 
 // ensureTailscaledRunning starts tailscaled if it's not already running.
 func ensureTailscaledRunning() error {
-	socketPath := "/var/run/tailscale/tailscaled.sock"
+	socketPath := tailscaledSocketPath
 	if _, err := os.Stat(socketPath); err == nil {
 		return nil
 	}
@@ -205,14 +330,13 @@ func ensureTailscaledRunning() error {
 		"--state=/var/lib/tailscale/tailscaled.state",
 		"--socket=" + socketPath,
 	}
-
-	var cmd *exec.Cmd
-	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
-		cmd = exec.Command("tailscaled", args...)
-	} else {
-		cmd = exec.Command("sudo", append([]string{"tailscaled"}, args...)...)
+	if needsUserspaceNetworking() {
+		// Containers without a TUN device can still reach the mesh, but
+		// only through tailscaled's proxies.
+		args = append(args, "--tun=userspace-networking")
 	}
 
+	cmd := privilegedCommand("tailscaled", args...)
 	cmd.Stdout = os.Stdout
 	cmd.Stderr = os.Stderr
 
@@ -230,25 +354,59 @@ func ensureTailscaledRunning() error {
 	return fmt.Errorf("tailscaled socket not ready after 3 seconds")
 }
 
+// privilegedCommand builds a command that runs name as root, using sudo when
+// the current user is not root. In non-interactive mode sudo is run with -n
+// so a missing credential fails instead of prompting.
+func privilegedCommand(name string, args ...string) *exec.Cmd {
+	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
+		return exec.Command(name, args...)
+	}
+	sudoArgs := []string{name}
+	if joinFlags.nonInteractive {
+		sudoArgs = []string{"-n", name}
+	}
+	return exec.Command("sudo", append(sudoArgs, args...)...)
+}
+
 // runTailscaleUp executes the tailscale up command with the provided
 // login server and auth key to connect this device to the mesh network.
+// An existing login is only replaced with --force-reauth or --switch; when
+// switching from another control server, its preferences are backed up and
+// reset so that settings such as exit nodes do not carry over.
 func runTailscaleUp(headscaleURL, authkey string) error {
 	if err := ensureTailscaledRunning(); err != nil {
 		return err
 	}
 
-	var tailscaleCmd *exec.Cmd
-	args := []string{"up", "--login-server=" + headscaleURL, "--authkey=" + authkey}
+	prefs, rawPrefs, err := readTailscalePrefs()
+	if err != nil {
+		return err
+	}
+	state := classifyLogin(prefs, headscaleURL)
+	if err := checkLoginState(state, prefs); err != nil {
+		return err
+	}
 
-	if runtime.GOOS == "windows" || os.Geteuid() == 0 {
-		tailscaleCmd = exec.Command("tailscale", args...)
-	} else {
-		tailscaleCmd = exec.Command("sudo", append([]string{"tailscale"}, args...)...)
+	args := []string{"up", "--login-server=" + headscaleURL, "--authkey=" + authkey}
+	switch state {
+	case loginSameServer:
+		args = append(args, "--force-reauth")
+	case loginOtherServer:
+		backupPath, err := backupTailscalePrefs(rawPrefs)
+		if err != nil {
+			return err
+		}
+		fmt.Printf("Saved previous Tailscale preferences (%s) to %s\n", prefs.ControlURL, backupPath)
+		args = append(args, "--force-reauth", "--reset")
 	}
 
+	tailscaleCmd := privilegedCommand("tailscale", args...)
+
 	tailscaleCmd.Stdout = os.Stdout
 	tailscaleCmd.Stderr = os.Stderr
-	tailscaleCmd.Stdin = os.Stdin
+	if !joinFlags.nonInteractive {
+		tailscaleCmd.Stdin = os.Stdin
+	}
 
 	if err := tailscaleCmd.Run(); err != nil {
 		return fmt.Errorf("connect to mesh: %w", err)
@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"os/exec"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// minTailscaleVersion is the oldest Tailscale client that the bundled
+	// Headscale release still accepts.
+	minTailscaleVersion = "1.62.0"
+
+	// maxClockSkew is how far the local clock may drift from the coordinator
+	// before join tokens and TLS certificates start failing validation.
+	maxClockSkew = 5 * time.Minute
+)
+
+// preflightError is a failed preflight check together with how to fix it.
+type preflightError struct {
+	problem string
+	fix     string
+}
+
+func (e *preflightError) Error() string {
+	return fmt.Sprintf("%s\n\nTo fix:\n  %s", e.problem, strings.ReplaceAll(e.fix, "\n", "\n  "))
+}
+
+// runPreflight verifies local prerequisites before contacting the
+// coordinator, so that failures come with a specific fix instead of
+// surfacing deep inside the join flow. publicURL is the coordinator URL the
+// mesh login server is expected to share a host with; it differs from
+// coordinatorURL when --coordinator-url overrides the URL in a join token.
+func runPreflight(coordinatorURL, publicURL string) error {
+	fmt.Println("Running preflight checks...")
+
+	checks := []struct {
+		name string
+		run  func() (string, error)
+	}{
+		{"tailscale installed", checkTailscaleVersion},
+		{"coordinator reachable", func() (string, error) { return checkCoordinator(coordinatorURL) }},
+		{"existing tailscale login", func() (string, error) { return checkExistingLogin(coordinatorURL, publicURL) }},
+	}
+
+	for _, check := range checks {
+		detail, err := check.run()
+		if err != nil {
+			return fmt.Errorf("preflight check %q failed: %w", check.name, err)
+		}
+		fmt.Printf("  %s: ok (%s)\n", check.name, detail)
+	}
+	return nil
+}
+
+// checkTailscaleVersion verifies the tailscale binaries are installed and
+// that the client is new enough for Headscale.
+func checkTailscaleVersion() (string, error) {
+	if err := checkTailscaleInstalled(); err != nil {
+		return "", err
+	}
+
+	out, err := exec.Command("tailscale", "version").Output()
+	if err != nil {
+		return "", &preflightError{
+			problem: fmt.Sprintf("could not determine tailscale version: %v", err),
+			fix:     "reinstall Tailscale: https://tailscale.com/download",
+		}
+	}
+
+	version := strings.TrimSpace(strings.SplitN(string(out), "\n", 2)[0])
+	if compareVersions(version, minTailscaleVersion) < 0 {
+		return "", &preflightError{
+			problem: fmt.Sprintf("tailscale %s is too old, need %s or newer", version, minTailscaleVersion),
+			fix:     "upgrade Tailscale: https://tailscale.com/download",
+		}
+	}
+	return version, nil
+}
+
+// checkCoordinator verifies the coordinator is reachable over the network and
+// that the local clock agrees with it.
+func checkCoordinator(coordinatorURL string) (string, error) {
+	client := &http.Client{Timeout: 10 * time.Second}
+	resp, err := client.Get(coordinatorURL + "/coordinator/health")
+	if err != nil {
+		port := "443"
+		if u, parseErr := url.Parse(coordinatorURL); parseErr == nil {
+			if p := u.Port(); p != "" {
+				port = p
+			} else if u.Scheme == "http" {
+				port = "80"
+			}
+		}
+		return "", &preflightError{
+			problem: fmt.Sprintf("cannot reach coordinator at %s: %v", coordinatorURL, err),
+			fix: fmt.Sprintf("check DNS and that outbound TCP port %s is allowed by your firewall or proxy\n"+
+				"if the URL in the token is not reachable from this machine, pass --coordinator-url", port),
+		}
+	}
+	_ = resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return "", &preflightError{
+			problem: fmt.Sprintf("coordinator at %s is unhealthy: status %d", coordinatorURL, resp.StatusCode),
+			fix:     "retry later, or contact the coordinator operator",
+		}
+	}
+
+	serverTime, err := http.ParseTime(resp.Header.Get("Date"))
+	if err != nil {
+		return "clock not checked, no Date header", nil
+	}
+	if skew := clockSkew(time.Now(), serverTime); skew > maxClockSkew {
+		return "", &preflightError{
+			problem: fmt.Sprintf("local clock is off by %s from the coordinator", skew.Round(time.Second)),
+			fix:     "enable time synchronization, e.g. sudo timedatectl set-ntp true",
+		}
+	}
+	return coordinatorURL, nil
+}
+
+// clockSkew returns the absolute difference between two clocks.
+func clockSkew(local, remote time.Time) time.Duration {
+	skew := local.Sub(remote)
+	if skew < 0 {
+		skew = -skew
+	}
+	return skew
+}
+
+// checkExistingLogin fails if tailscaled is already logged in and the
+// flags do not say how to handle it, so that no join key is minted for a
+// join that would fail or move this machine off its current tailnet.
+func checkExistingLogin(coordinatorURL, publicURL string) (string, error) {
+	prefs, _, err := readTailscalePrefs()
+	if err != nil {
+		return "", &preflightError{
+			problem: err.Error(),
+			fix:     "make sure tailscaled is running and that you can run sudo",
+		}
+	}
+
+	state := classifyLogin(prefs, coordinatorURL, publicURL)
+	if err := checkLoginState(state, prefs); err != nil {
+		return "", err
+	}
+	switch state {
+	case loginSameServer:
+		return "re-authenticating with " + prefs.ControlURL, nil
+	case loginOtherServer:
+		return "switching from " + prefs.ControlURL, nil
+	default:
+		return "not logged in", nil
+	}
+}
+
+// sameHost reports whether two URLs point at the same host, ignoring scheme,
+// default ports, and paths.
+func sameHost(a, b string) bool {
+	ua, errA := url.Parse(a)
+	ub, errB := url.Parse(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+	return strings.EqualFold(ua.Hostname(), ub.Hostname())
+}
+
+// compareVersions compares dotted numeric versions such as "1.76.1",
+// ignoring any suffix after the numeric part (e.g. "-t1234abcd").
+// It returns -1, 0, or 1.
+func compareVersions(a, b string) int {
+	pa, pb := versionParts(a), versionParts(b)
+	for i := 0; i < len(pa) || i < len(pb); i++ {
+		var x, y int
+		if i < len(pa) {
+			x = pa[i]
+		}
+		if i < len(pb) {
+			y = pb[i]
+		}
+		if x != y {
+			if x < y {
+				return -1
+			}
+			return 1
+		}
+	}
+	return 0
+}
+
+func versionParts(version string) []int {
+	version = strings.TrimPrefix(version, "v")
+	if i := strings.IndexAny(version, "-+ "); i >= 0 {
+		version = version[:i]
+	}
+
+	var parts []int
+	for _, field := range strings.Split(version, ".") {
+		n, err := strconv.Atoi(field)
+		if err != nil {
+			break
+		}
+		parts = append(parts, n)
+	}
+	return parts
+}
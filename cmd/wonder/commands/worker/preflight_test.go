@@ -0,0 +1,133 @@
+package worker
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCompareVersions(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected int
+	}{
+		{
+			name:     "equal",
+			a:        "1.62.0",
+			b:        "1.62.0",
+			expected: 0,
+		},
+		{
+			name:     "older minor",
+			a:        "1.58.2",
+			b:        "1.62.0",
+			expected: -1,
+		},
+		{
+			name:     "newer minor compared numerically",
+			a:        "1.100.0",
+			b:        "1.62.0",
+			expected: 1,
+		},
+		{
+			name:     "missing patch",
+			a:        "1.62",
+			b:        "1.62.0",
+			expected: 0,
+		},
+		{
+			name:     "build suffix ignored",
+			a:        "1.76.1-t1234abcd",
+			b:        "1.62.0",
+			expected: 1,
+		},
+		{
+			name:     "v prefix",
+			a:        "v1.60.0",
+			b:        "1.62.0",
+			expected: -1,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := compareVersions(tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("compareVersions(%q, %q) = %d, want %d", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestClockSkew(t *testing.T) {
+	now := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		remote   time.Time
+		expected time.Duration
+	}{
+		{
+			name:     "in sync",
+			remote:   now,
+			expected: 0,
+		},
+		{
+			name:     "local ahead",
+			remote:   now.Add(-10 * time.Minute),
+			expected: 10 * time.Minute,
+		},
+		{
+			name:     "local behind",
+			remote:   now.Add(3 * time.Minute),
+			expected: 3 * time.Minute,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := clockSkew(now, tt.remote)
+			if result != tt.expected {
+				t.Errorf("clockSkew() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
+
+func TestSameHost(t *testing.T) {
+	tests := []struct {
+		name     string
+		a        string
+		b        string
+		expected bool
+	}{
+		{
+			name:     "same host different scheme and port",
+			a:        "https://wonder.example.com",
+			b:        "http://wonder.example.com:9080",
+			expected: true,
+		},
+		{
+			name:     "case insensitive",
+			a:        "https://Wonder.Example.com",
+			b:        "https://wonder.example.com",
+			expected: true,
+		},
+		{
+			name:     "different host",
+			a:        "https://controlplane.tailscale.com",
+			b:        "https://wonder.example.com",
+			expected: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := sameHost(tt.a, tt.b)
+			if result != tt.expected {
+				t.Errorf("sameHost(%q, %q) = %v, want %v", tt.a, tt.b, result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,109 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// backendStateNeedsLogin is tailscaled's backend state while it has no
+// valid login, e.g. because the authkey expired before it was used or the
+// node key expired.
+const backendStateNeedsLogin = "NeedsLogin"
+
+// runRenewJoin logs tailscaled in again with a new authkey obtained with the
+// stored worker token, without a new join token.
+func runRenewJoin() error {
+	creds, err := loadCredentials()
+	if os.IsNotExist(err) {
+		return fmt.Errorf("not joined to any mesh, run wonder worker join with a join token first")
+	}
+	if err != nil {
+		return fmt.Errorf("%w (run wonder worker repair to fix it)", err)
+	}
+	if creds.WorkerToken == "" {
+		return fmt.Errorf("no worker token stored, join again with a join token to enable renewal")
+	}
+	if err := checkTailscaleInstalled(); err != nil {
+		return err
+	}
+
+	fmt.Println("Renewing Wonder Mesh Net login...")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	info, err := renewAuthkey(ctx, creds)
+	if err != nil {
+		return err
+	}
+
+	// The machine is still registered with the same login server, so
+	// replacing that login is the point of renewing.
+	joinFlags.forceReauth = true
+	return runTailscaleUp(info.LoginServer, info.Authkey)
+}
+
+// renewAuthkey asks the coordinator for a new authkey for this worker,
+// authenticated by its worker token.
+func renewAuthkey(ctx context.Context, creds *credentials) (*tailscaleConnectionInfo, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		creds.CoordinatorURL+"/coordinator/api/v1/worker/renew", nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+creds.WorkerToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("renew: coordinator returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var result joinResponse
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return nil, fmt.Errorf("decode response: %w", err)
+	}
+	if result.MeshType != "tailscale" {
+		return nil, fmt.Errorf("unsupported mesh type: %s", result.MeshType)
+	}
+	info := result.TailscaleConnectionInfo
+	if info == nil || info.LoginServer == "" || info.Authkey == "" {
+		return nil, fmt.Errorf("missing tailscale connection info from coordinator")
+	}
+	return info, nil
+}
+
+// renewIfLoggedOut logs tailscaled in again with a new authkey when it
+// needs a login. It is called by the daemon before each heartbeat.
+func renewIfLoggedOut(ctx context.Context, creds *credentials) {
+	status, err := readTailscaleStatus(ctx)
+	if err != nil || status == nil || status.BackendState != backendStateNeedsLogin {
+		return
+	}
+
+	slog.Warn("tailscale needs a login, renewing authkey")
+	info, err := renewAuthkey(ctx, creds)
+	if err != nil {
+		slog.Error("renew authkey", "error", err)
+		return
+	}
+
+	joinFlags.nonInteractive = true
+	joinFlags.forceReauth = true
+	if err := runTailscaleUp(info.LoginServer, info.Authkey); err != nil {
+		slog.Error("log in with renewed authkey", "error", err)
+		return
+	}
+	slog.Info("logged in with renewed authkey")
+}
@@ -0,0 +1,38 @@
+package worker
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestRenewAuthkey(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/coordinator/api/v1/worker/renew" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		if gotAuth != "Bearer worker-token" {
+			http.Error(w, "invalid worker token", http.StatusUnauthorized)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"mesh_type":"tailscale","tailscale_connection_info":{"login_server":"https://hs.example.com","authkey":"new-key","headscale_user":"wn-1"}}`))
+	}))
+	defer server.Close()
+
+	info, err := renewAuthkey(context.Background(), &credentials{CoordinatorURL: server.URL, WorkerToken: "worker-token"})
+	if err != nil {
+		t.Fatalf("renewAuthkey() error = %v", err)
+	}
+	if info.Authkey != "new-key" || info.LoginServer != "https://hs.example.com" {
+		t.Errorf("renewAuthkey() = %+v", info)
+	}
+
+	if _, err := renewAuthkey(context.Background(), &credentials{CoordinatorURL: server.URL, WorkerToken: "revoked"}); err == nil {
+		t.Error("renewAuthkey(invalid token) error = nil, want error")
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// newRepairCmd creates the repair subcommand that checks the credentials
+// file and fixes what it can.
+func newRepairCmd() *cobra.Command {
+	return &cobra.Command{
+		Use:   "repair",
+		Short: "Check and repair the worker credentials file",
+		Long: `Check ~/.wonder/worker.json and fix what can be fixed: migrate it to the
+current schema version, normalize the coordinator URL or restore it from the
+tailscale login server, and restore a missing join time from the file's
+modification time.
+
+A file that cannot be repaired is moved aside to worker.json.corrupt-<time>
+so the worker can join again.`,
+		RunE: runRepair,
+	}
+}
+
+// runRepair repairs the credentials file in place, or moves it aside if it
+// is beyond repair.
+func runRepair(cmd *cobra.Command, args []string) error {
+	credentialPath, err := getCredentialsPath()
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(credentialPath)
+	if os.IsNotExist(err) {
+		fmt.Println("No worker credentials found, nothing to repair")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	data, err := os.ReadFile(credentialPath)
+	if err != nil {
+		return err
+	}
+
+	creds, fixes, err := repairCredentials(data, info.ModTime(), tailscaleControlURL)
+	if errors.Is(err, errCorruptCredentials) || errors.Is(err, errInvalidCredentials) {
+		corruptPath := fmt.Sprintf("%s.corrupt-%s", credentialPath, time.Now().UTC().Format("20060102T150405Z"))
+		if renameErr := os.Rename(credentialPath, corruptPath); renameErr != nil {
+			return fmt.Errorf("move unrepairable credentials aside: %w", renameErr)
+		}
+		return fmt.Errorf("%w; moved it to %s, join again with wonder worker join", err, corruptPath)
+	}
+	if err != nil {
+		return err
+	}
+
+	if len(fixes) == 0 {
+		fmt.Printf("Credentials OK (version %d)\n", creds.Version)
+		return nil
+	}
+	if err := saveCredentials(creds); err != nil {
+		return fmt.Errorf("save repaired credentials: %w", err)
+	}
+	fmt.Println("Repaired credentials:")
+	for _, fix := range fixes {
+		fmt.Printf("  - %s\n", fix)
+	}
+	return nil
+}
+
+// repairCredentials migrates and checks a credentials file, filling in
+// what can be recovered. It returns the repaired credentials and a
+// description of each fix. controlURL is only called when the coordinator
+// URL has to be restored, as reading it may need sudo.
+func repairCredentials(data []byte, modTime time.Time, controlURL func() string) (*credentials, []string, error) {
+	creds, fromVersion, err := decodeCredentials(data)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var fixes []string
+	if fromVersion < credentialsVersion {
+		fixes = append(fixes, fmt.Sprintf("migrated from version %d to %d", fromVersion, credentialsVersion))
+	}
+
+	if creds.CoordinatorURL != "" && creds.CoordinatorURL != normalizeURL(creds.CoordinatorURL) {
+		creds.CoordinatorURL = normalizeURL(creds.CoordinatorURL)
+		fixes = append(fixes, "normalized coordinator_url to "+creds.CoordinatorURL)
+	}
+	if creds.CoordinatorURL == "" {
+		if url := normalizeURL(controlURL()); url != "" {
+			creds.CoordinatorURL = url
+			fixes = append(fixes, "restored coordinator_url from the tailscale login server: "+url)
+		}
+	}
+	if creds.JoinedAt.IsZero() {
+		creds.JoinedAt = modTime
+		fixes = append(fixes, "restored joined_at from the file's modification time")
+	}
+
+	if err := creds.validate(); err != nil {
+		return nil, nil, err
+	}
+	return creds, fixes, nil
+}
+
+// tailscaleControlURL returns the control server tailscaled is logged in
+// to, which is the coordinator for a joined worker, or an empty string.
+func tailscaleControlURL() string {
+	prefs, _, err := readTailscalePrefs()
+	if err != nil || prefs == nil || prefs.LoggedOut {
+		return ""
+	}
+	return prefs.ControlURL
+}
@@ -0,0 +1,88 @@
+package worker
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Files written by install --ssh-ca.
+const (
+	sshdConfigPath = "/etc/ssh/sshd_config"
+	sshdDropInDir  = "/etc/ssh/sshd_config.d"
+	sshdDropInPath = sshdDropInDir + "/wonder-mesh-net.conf"
+	sshCAKeyPath   = "/etc/ssh/wonder-mesh-net-user-ca.pub"
+)
+
+// renderSSHDDropIn returns the sshd configuration trusting user
+// certificates signed by the wonder net's SSH CA.
+func renderSSHDDropIn() string {
+	return `# Written by wonder worker install --ssh-ca: accept user certificates
+# signed by the wonder net's SSH CA (POST /coordinator/api/v1/ssh-cert).
+TrustedUserCAKeys ` + sshCAKeyPath + "\n"
+}
+
+// sshdIncludesDropIns reports whether an sshd_config reads the files in
+// sshdDropInDir, as the default configuration of current distributions
+// and macOS does.
+func sshdIncludesDropIns(config string) bool {
+	scanner := bufio.NewScanner(strings.NewReader(config))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 || !strings.EqualFold(fields[0], "Include") {
+			continue
+		}
+		for _, pattern := range fields[1:] {
+			if !filepath.IsAbs(pattern) {
+				pattern = filepath.Join("/etc/ssh", pattern)
+			}
+			if ok, _ := filepath.Match(pattern, sshdDropInPath); ok {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// configureSSHCA makes sshd trust user certificates of the wonder net's SSH
+// CA, whose key the coordinator returned on join. The configuration is
+// checked with sshd -t before sshd is reloaded, and removed if it fails.
+func configureSSHCA(goos, caKey string) error {
+	if goos != "linux" && goos != "darwin" {
+		return fmt.Errorf("--ssh-ca is not supported on %s", goos)
+	}
+	if caKey == "" {
+		return fmt.Errorf("the coordinator returned no SSH CA key on join; join again with a current coordinator to use --ssh-ca")
+	}
+	config, err := os.ReadFile(sshdConfigPath)
+	if err != nil {
+		return fmt.Errorf("read sshd config: %w; is OpenSSH server installed?", err)
+	}
+	if !sshdIncludesDropIns(string(config)) {
+		return fmt.Errorf("%s does not include %s/*.conf; add TrustedUserCAKeys %s to it by hand", sshdConfigPath, sshdDropInDir, sshCAKeyPath)
+	}
+
+	if err := writeSystemFile(sshCAKeyPath, caKey+"\n"); err != nil {
+		return err
+	}
+	if err := writeSystemFile(sshdDropInPath, renderSSHDDropIn()); err != nil {
+		return err
+	}
+	if err := runPrivileged("sshd", "-t"); err != nil {
+		_ = runPrivileged("rm", "-f", sshdDropInPath)
+		return fmt.Errorf("sshd rejected the configuration, removed %s: %w", sshdDropInPath, err)
+	}
+
+	// launchd starts sshd per connection on macOS, so new logins already
+	// read the configuration. The unit is ssh on Debian and sshd elsewhere.
+	if goos == "linux" {
+		if err := runPrivileged("systemctl", "reload", "ssh"); err != nil {
+			if err := runPrivileged("systemctl", "reload", "sshd"); err != nil {
+				return fmt.Errorf("reload sshd: %w", err)
+			}
+		}
+	}
+	return nil
+}
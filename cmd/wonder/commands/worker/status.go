@@ -1,38 +1,133 @@
 package worker
 
 import (
+	"context"
 	"fmt"
+	"io"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/spf13/cobra"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
 )
 
+// workerStatus is the JSON and YAML output of wonder worker status.
+type workerStatus struct {
+	Joined         bool       `json:"joined"`
+	User           string     `json:"user,omitempty"`
+	CoordinatorURL string     `json:"coordinator_url,omitempty"`
+	JoinedAt       *time.Time `json:"joined_at,omitempty"`
+	// Heartbeats is true if the worker has a worker token, which the
+	// daemon needs to send heartbeats.
+	Heartbeats bool `json:"heartbeats"`
+	// Mesh is the live connectivity reported by tailscaled, nil if
+	// tailscaled is not running or could not be queried.
+	Mesh      *meshStatus `json:"mesh,omitempty"`
+	MeshError string      `json:"mesh_error,omitempty"`
+}
+
 // newStatusCmd creates the status subcommand that displays the current
 // worker node connection status and mesh network information.
 func newStatusCmd() *cobra.Command {
 	return &cobra.Command{
 		Use:   "status",
 		Short: "Show worker status",
-		Long:  `Show current worker status and connection information.`,
-		RunE:  runStatus,
+		Long: `Show current worker status and connection information.
+
+Besides the stored join credentials, the live connection state is read from
+the local tailscaled: its backend state, the assigned mesh IPs, the home DERP
+region, how many peers are online or reached directly, and the most recent
+handshake with any peer.
+
+Use --output json or yaml for machine-readable output.`,
+		RunE: runStatus,
 	}
 }
 
 // runStatus loads and displays the locally stored credentials including
-// user, coordinator URL, and join timestamp.
+// user, coordinator URL, and join timestamp, together with the live
+// connection state from tailscaled.
 func runStatus(cmd *cobra.Command, args []string) error {
-	creds, err := loadCredentials()
+	format, err := output.FromCommand(cmd)
 	if err != nil {
-		fmt.Println("Not joined to any mesh")
-		fmt.Println("\nTo join, run:")
-		fmt.Println("  wonder worker join --coordinator https://your-coordinator.example.com")
+		return err
+	}
+
+	creds, err := loadCredentials()
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("%w (run wonder worker repair to fix it)", err)
+	}
+
+	status := workerStatus{}
+	if creds != nil {
+		status = workerStatus{
+			Joined:         true,
+			User:           creds.User,
+			CoordinatorURL: creds.CoordinatorURL,
+			JoinedAt:       &creds.JoinedAt,
+			Heartbeats:     creds.WorkerToken != "",
+		}
+
+		ctx, cancel := context.WithTimeout(cmd.Context(), 5*time.Second)
+		defer cancel()
+		tsStatus, err := readTailscaleStatus(ctx)
+		switch {
+		case err != nil:
+			status.MeshError = err.Error()
+		case tsStatus != nil:
+			status.Mesh = summarizeMeshStatus(tsStatus)
+		}
+	}
+
+	return output.Print(os.Stdout, format, status, func(w io.Writer) error {
+		printStatus(w, status)
 		return nil
+	})
+}
+
+// printStatus prints the human-readable worker status.
+func printStatus(w io.Writer, status workerStatus) {
+	if !status.Joined {
+		fmt.Fprintln(w, "Not joined to any mesh")
+		fmt.Fprintln(w, "\nTo join, run:")
+		fmt.Fprintln(w, "  wonder worker join --coordinator https://your-coordinator.example.com")
+		return
 	}
 
-	fmt.Println("Worker Status")
-	fmt.Printf("  User: %s\n", creds.User)
-	fmt.Printf("  Coordinator: %s\n", creds.CoordinatorURL)
-	fmt.Printf("  Joined: %s\n", creds.JoinedAt.Format(time.RFC3339))
+	fmt.Fprintln(w, "Worker Status")
+	fmt.Fprintf(w, "  User: %s\n", status.User)
+	fmt.Fprintf(w, "  Coordinator: %s\n", status.CoordinatorURL)
+	fmt.Fprintf(w, "  Joined: %s\n", status.JoinedAt.Format(time.RFC3339))
+	if status.Heartbeats {
+		fmt.Fprintln(w, "  Heartbeats: available (run wonder worker daemon)")
+	} else {
+		fmt.Fprintln(w, "  Heartbeats: unavailable (join with a join token to enable)")
+	}
 
-	return nil
+	fmt.Fprintln(w, "\nMesh Connection")
+	switch {
+	case status.MeshError != "":
+		fmt.Fprintf(w, "  Unknown: %s\n", status.MeshError)
+	case status.Mesh == nil:
+		fmt.Fprintln(w, "  tailscaled is not running")
+	default:
+		mesh := status.Mesh
+		fmt.Fprintf(w, "  State: %s\n", mesh.State)
+		fmt.Fprintf(w, "  Addresses: %s\n", orNone(strings.Join(mesh.Addresses, ", ")))
+		fmt.Fprintf(w, "  DERP region: %s\n", orNone(mesh.DERPRegion))
+		fmt.Fprintf(w, "  Peers: %d (%d online, %d direct)\n", mesh.Peers, mesh.OnlinePeers, mesh.DirectPeers)
+		if mesh.LastHandshake != nil {
+			fmt.Fprintf(w, "  Last handshake: %s\n", mesh.LastHandshake.Format(time.RFC3339))
+		} else {
+			fmt.Fprintln(w, "  Last handshake: never")
+		}
+	}
+}
+
+func orNone(s string) string {
+	if s == "" {
+		return "none"
+	}
+	return s
 }
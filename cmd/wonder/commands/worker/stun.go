@@ -0,0 +1,211 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net"
+	"net/netip"
+	"time"
+)
+
+// STUN (RFC 5389) binding requests, enough to learn the address a NAT maps
+// this machine's UDP socket to.
+const (
+	stunBindingRequest       = 0x0001
+	stunBindingSuccess       = 0x0101
+	stunMagicCookie          = 0x2112a442
+	stunAttrMappedAddress    = 0x0001
+	stunAttrXorMappedAddress = 0x0020
+	stunHeaderSize           = 20
+
+	// stunAttempts is how often a binding request is sent before a STUN
+	// server counts as unreachable, as UDP may drop single packets.
+	stunAttempts = 3
+	stunTimeout  = time.Second
+)
+
+// NAT types reported by wonder net doctor.
+const (
+	// natNone means the mapped address is one of this machine's own
+	// addresses.
+	natNone = "none"
+	// natEndpointIndependent means the NAT maps the socket to the same
+	// address for every destination, so peers can usually connect directly.
+	natEndpointIndependent = "endpoint-independent"
+	// natEndpointDependent means the mapping changes with the destination
+	// (symmetric NAT), so connections to peers often need a DERP relay.
+	natEndpointDependent = "endpoint-dependent"
+	// natUDPBlocked means no STUN server answered.
+	natUDPBlocked = "udp-blocked"
+	// natUnknown means only one STUN server answered, which is not enough
+	// to compare mappings.
+	natUnknown = "unknown"
+)
+
+var errSTUNMalformed = errors.New("malformed stun response")
+
+// newSTUNRequest returns a binding request and its transaction ID.
+func newSTUNRequest() ([]byte, [12]byte, error) {
+	var txID [12]byte
+	if _, err := rand.Read(txID[:]); err != nil {
+		return nil, txID, err
+	}
+
+	req := make([]byte, stunHeaderSize)
+	binary.BigEndian.PutUint16(req[0:2], stunBindingRequest)
+	binary.BigEndian.PutUint32(req[4:8], stunMagicCookie)
+	copy(req[8:20], txID[:])
+	return req, txID, nil
+}
+
+// parseSTUNResponse returns the mapped address of a binding success
+// response to the request with txID. XOR-MAPPED-ADDRESS is preferred over
+// the legacy MAPPED-ADDRESS.
+func parseSTUNResponse(b []byte, txID [12]byte) (netip.AddrPort, error) {
+	if len(b) < stunHeaderSize ||
+		binary.BigEndian.Uint16(b[0:2]) != stunBindingSuccess ||
+		binary.BigEndian.Uint32(b[4:8]) != stunMagicCookie {
+		return netip.AddrPort{}, errSTUNMalformed
+	}
+	if !bytes.Equal(b[8:20], txID[:]) {
+		return netip.AddrPort{}, fmt.Errorf("%w: transaction id mismatch", errSTUNMalformed)
+	}
+	length := int(binary.BigEndian.Uint16(b[2:4]))
+	if stunHeaderSize+length > len(b) {
+		return netip.AddrPort{}, fmt.Errorf("%w: truncated", errSTUNMalformed)
+	}
+
+	var mapped netip.AddrPort
+	attrs := b[stunHeaderSize : stunHeaderSize+length]
+	for len(attrs) >= 4 {
+		attrType := binary.BigEndian.Uint16(attrs[0:2])
+		attrLen := int(binary.BigEndian.Uint16(attrs[2:4]))
+		if 4+attrLen > len(attrs) {
+			return netip.AddrPort{}, fmt.Errorf("%w: truncated attribute", errSTUNMalformed)
+		}
+		value := attrs[4 : 4+attrLen]
+
+		switch attrType {
+		case stunAttrXorMappedAddress:
+			if addr, ok := decodeSTUNAddress(value, b[4:20]); ok {
+				return addr, nil
+			}
+		case stunAttrMappedAddress:
+			if addr, ok := decodeSTUNAddress(value, nil); ok {
+				mapped = addr
+			}
+		}
+
+		// Attributes are padded to four bytes.
+		next := 4 + (attrLen+3)&^3
+		if next > len(attrs) {
+			break
+		}
+		attrs = attrs[next:]
+	}
+
+	if !mapped.IsValid() {
+		return netip.AddrPort{}, fmt.Errorf("%w: no mapped address", errSTUNMalformed)
+	}
+	return mapped, nil
+}
+
+// decodeSTUNAddress decodes a (XOR-)MAPPED-ADDRESS value. For XOR-MAPPED-
+// ADDRESS, xorKey is the magic cookie followed by the transaction ID.
+func decodeSTUNAddress(value, xorKey []byte) (netip.AddrPort, bool) {
+	if len(value) < 4 {
+		return netip.AddrPort{}, false
+	}
+
+	var addrLen int
+	switch value[1] {
+	case 0x01:
+		addrLen = 4
+	case 0x02:
+		addrLen = 16
+	default:
+		return netip.AddrPort{}, false
+	}
+	if len(value) < 4+addrLen {
+		return netip.AddrPort{}, false
+	}
+
+	port := binary.BigEndian.Uint16(value[2:4])
+	ip := bytes.Clone(value[4 : 4+addrLen])
+	if xorKey != nil {
+		port ^= uint16(stunMagicCookie >> 16)
+		for i := range ip {
+			ip[i] ^= xorKey[i]
+		}
+	}
+
+	addr, ok := netip.AddrFromSlice(ip)
+	if !ok {
+		return netip.AddrPort{}, false
+	}
+	return netip.AddrPortFrom(addr.Unmap(), port), true
+}
+
+// stunProbe sends binding requests from conn to server until one is
+// answered, and returns the mapped address and the round trip time.
+func stunProbe(ctx context.Context, conn *net.UDPConn, server *net.UDPAddr) (netip.AddrPort, time.Duration, error) {
+	req, txID, err := newSTUNRequest()
+	if err != nil {
+		return netip.AddrPort{}, 0, err
+	}
+
+	buf := make([]byte, 1500)
+	for attempt := 0; attempt < stunAttempts; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return netip.AddrPort{}, 0, err
+		}
+
+		start := time.Now()
+		if _, err := conn.WriteToUDP(req, server); err != nil {
+			return netip.AddrPort{}, 0, err
+		}
+		_ = conn.SetReadDeadline(start.Add(stunTimeout))
+		for {
+			n, from, err := conn.ReadFromUDP(buf)
+			if err != nil {
+				break
+			}
+			if !from.IP.Equal(server.IP) {
+				continue
+			}
+			mapped, err := parseSTUNResponse(buf[:n], txID)
+			if err != nil {
+				continue
+			}
+			return mapped, time.Since(start), nil
+		}
+	}
+	return netip.AddrPort{}, 0, fmt.Errorf("no response from %s", server)
+}
+
+// classifyNAT derives the NAT type from the addresses STUN servers saw for
+// one local UDP socket. local lists this machine's own addresses.
+func classifyNAT(mapped []netip.AddrPort, local []netip.Addr) string {
+	if len(mapped) == 0 {
+		return natUDPBlocked
+	}
+
+	for _, addr := range local {
+		if addr == mapped[0].Addr() {
+			return natNone
+		}
+	}
+	if len(mapped) == 1 {
+		return natUnknown
+	}
+	for _, m := range mapped[1:] {
+		if m != mapped[0] {
+			return natEndpointDependent
+		}
+	}
+	return natEndpointIndependent
+}
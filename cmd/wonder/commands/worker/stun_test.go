@@ -0,0 +1,141 @@
+package worker
+
+import (
+	"context"
+	"encoding/binary"
+	"net"
+	"net/netip"
+	"testing"
+)
+
+// stunResponse builds a binding success response for txID that maps to
+// mapped, as an XOR-MAPPED-ADDRESS if xor is set.
+func stunResponse(txID [12]byte, mapped netip.AddrPort, xor bool) []byte {
+	ip := mapped.Addr().AsSlice()
+	value := make([]byte, 4+len(ip))
+	value[1] = 0x01
+	if mapped.Addr().Is6() {
+		value[1] = 0x02
+	}
+	port := mapped.Port()
+	attrType := uint16(stunAttrMappedAddress)
+	if xor {
+		attrType = stunAttrXorMappedAddress
+		port ^= uint16(stunMagicCookie >> 16)
+		key := binary.BigEndian.AppendUint32(nil, stunMagicCookie)
+		key = append(key, txID[:]...)
+		for i := range ip {
+			ip[i] ^= key[i]
+		}
+	}
+	binary.BigEndian.PutUint16(value[2:4], port)
+	copy(value[4:], ip)
+
+	b := make([]byte, stunHeaderSize, stunHeaderSize+4+len(value))
+	binary.BigEndian.PutUint16(b[0:2], stunBindingSuccess)
+	binary.BigEndian.PutUint16(b[2:4], uint16(4+len(value)))
+	binary.BigEndian.PutUint32(b[4:8], stunMagicCookie)
+	copy(b[8:20], txID[:])
+	b = binary.BigEndian.AppendUint16(b, attrType)
+	b = binary.BigEndian.AppendUint16(b, uint16(len(value)))
+	return append(b, value...)
+}
+
+func TestParseSTUNResponse(t *testing.T) {
+	txID := [12]byte{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12}
+	otherTxID := [12]byte{12}
+	v4 := netip.MustParseAddrPort("203.0.113.7:41641")
+	v6 := netip.MustParseAddrPort("[2001:db8::7]:41641")
+
+	tests := []struct {
+		name    string
+		resp    []byte
+		want    netip.AddrPort
+		wantErr bool
+	}{
+		{name: "xor-mapped IPv4", resp: stunResponse(txID, v4, true), want: v4},
+		{name: "xor-mapped IPv6", resp: stunResponse(txID, v6, true), want: v6},
+		{name: "legacy mapped address", resp: stunResponse(txID, v4, false), want: v4},
+		{name: "other transaction", resp: stunResponse(otherTxID, v4, true), wantErr: true},
+		{name: "truncated", resp: stunResponse(txID, v4, true)[:24], wantErr: true},
+		{name: "too short", resp: []byte{0x01, 0x01}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseSTUNResponse(tt.resp, txID)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseSTUNResponse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseSTUNResponse() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSTUNProbe(t *testing.T) {
+	server, err := net.ListenUDP("udp4", &net.UDPAddr{IP: net.IPv4(127, 0, 0, 1)})
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = server.Close() }()
+
+	mapped := netip.MustParseAddrPort("198.51.100.1:1234")
+	go func() {
+		buf := make([]byte, 1500)
+		for {
+			n, from, err := server.ReadFromUDP(buf)
+			if err != nil {
+				return
+			}
+			if n < stunHeaderSize {
+				continue
+			}
+			var txID [12]byte
+			copy(txID[:], buf[8:20])
+			_, _ = server.WriteToUDP(stunResponse(txID, mapped, true), from)
+		}
+	}()
+
+	conn, err := net.ListenUDP("udp4", nil)
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+
+	got, _, err := stunProbe(context.Background(), conn, server.LocalAddr().(*net.UDPAddr))
+	if err != nil {
+		t.Fatalf("stunProbe() error = %v", err)
+	}
+	if got != mapped {
+		t.Errorf("stunProbe() = %v, want %v", got, mapped)
+	}
+}
+
+func TestClassifyNAT(t *testing.T) {
+	a := netip.MustParseAddrPort("203.0.113.7:41641")
+	b := netip.MustParseAddrPort("203.0.113.7:50000")
+	local := []netip.Addr{netip.MustParseAddr("192.168.1.10")}
+
+	tests := []struct {
+		name   string
+		mapped []netip.AddrPort
+		local  []netip.Addr
+		want   string
+	}{
+		{name: "no answers", mapped: nil, local: local, want: natUDPBlocked},
+		{name: "one answer", mapped: []netip.AddrPort{a}, local: local, want: natUnknown},
+		{name: "same mapping", mapped: []netip.AddrPort{a, a, a}, local: local, want: natEndpointIndependent},
+		{name: "mapping per destination", mapped: []netip.AddrPort{a, b}, local: local, want: natEndpointDependent},
+		{name: "public address", mapped: []netip.AddrPort{a}, local: []netip.Addr{a.Addr()}, want: natNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyNAT(tt.mapped, tt.local); got != tt.want {
+				t.Errorf("classifyNAT() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package worker
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const tailscaledSocketPath = "/var/run/tailscale/tailscaled.sock"
+
+// tailscaleLogin describes how tailscaled's current login relates to the
+// login server this machine is joining.
+type tailscaleLogin int
+
+const (
+	// loginNone means tailscaled is not running or not logged in.
+	loginNone tailscaleLogin = iota
+	// loginSameServer means tailscaled is already logged in to the login server.
+	loginSameServer
+	// loginOtherServer means tailscaled is logged in to a different control server.
+	loginOtherServer
+)
+
+// tailscalePrefs is the subset of `tailscale debug prefs` output used to
+// detect an existing login.
+type tailscalePrefs struct {
+	ControlURL  string `json:"ControlURL"`
+	WantRunning bool   `json:"WantRunning"`
+	LoggedOut   bool   `json:"LoggedOut"`
+}
+
+// readTailscalePrefs returns the current tailscaled preferences along with
+// their raw JSON, or nil if tailscaled is not running.
+func readTailscalePrefs() (*tailscalePrefs, []byte, error) {
+	if _, err := os.Stat(tailscaledSocketPath); err != nil {
+		return nil, nil, nil
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := privilegedCommand("tailscale", "debug", "prefs")
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, nil, fmt.Errorf("read tailscale prefs: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	var prefs tailscalePrefs
+	if err := json.Unmarshal(stdout.Bytes(), &prefs); err != nil {
+		return nil, nil, fmt.Errorf("decode tailscale prefs: %w", err)
+	}
+	return &prefs, stdout.Bytes(), nil
+}
+
+// classifyLogin compares tailscaled's control server with the URLs the login
+// server is known by.
+func classifyLogin(prefs *tailscalePrefs, loginServers ...string) tailscaleLogin {
+	if prefs == nil || prefs.LoggedOut || prefs.ControlURL == "" {
+		return loginNone
+	}
+	for _, server := range loginServers {
+		if server != "" && sameHost(prefs.ControlURL, server) {
+			return loginSameServer
+		}
+	}
+	return loginOtherServer
+}
+
+// checkLoginState refuses to replace an existing login unless the user asked
+// for it with --force-reauth or --switch.
+func checkLoginState(state tailscaleLogin, prefs *tailscalePrefs) error {
+	switch state {
+	case loginSameServer:
+		if joinFlags.forceReauth {
+			return nil
+		}
+		return &preflightError{
+			problem: fmt.Sprintf("this machine is already logged in to %s", prefs.ControlURL),
+			fix: "to register it again with the new credentials, re-run with --force-reauth\n" +
+				"to keep the existing registration, there is nothing to do",
+		}
+	case loginOtherServer:
+		if joinFlags.switchServer {
+			return nil
+		}
+		return &preflightError{
+			problem: fmt.Sprintf("this machine is already connected to %s", prefs.ControlURL),
+			fix: "to move it to Wonder Mesh Net, re-run with --switch (current preferences are backed up first)\n" +
+				"or disconnect it yourself with: sudo tailscale logout",
+		}
+	}
+	return nil
+}
+
+// backupTailscalePrefs writes the raw tailscaled preferences next to the
+// worker credentials so a previous login can be restored after --switch.
+func backupTailscalePrefs(raw []byte) (string, error) {
+	credentialPath, err := getCredentialsPath()
+	if err != nil {
+		return "", err
+	}
+
+	dir := filepath.Dir(credentialPath)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("create backup directory: %w", err)
+	}
+
+	backupPath := filepath.Join(dir, fmt.Sprintf("tailscale-prefs-%s.json", time.Now().UTC().Format("20060102T150405Z")))
+	if err := os.WriteFile(backupPath, raw, 0600); err != nil {
+		return "", fmt.Errorf("write tailscale prefs backup: %w", err)
+	}
+	return backupPath, nil
+}
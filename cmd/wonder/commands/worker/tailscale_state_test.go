@@ -0,0 +1,46 @@
+package worker
+
+import "testing"
+
+func TestClassifyLogin(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefs    *tailscalePrefs
+		expected tailscaleLogin
+	}{
+		{
+			name:     "tailscaled not running",
+			prefs:    nil,
+			expected: loginNone,
+		},
+		{
+			name:     "logged out",
+			prefs:    &tailscalePrefs{ControlURL: "https://controlplane.tailscale.com", LoggedOut: true},
+			expected: loginNone,
+		},
+		{
+			name:     "same login server",
+			prefs:    &tailscalePrefs{ControlURL: "https://wonder.example.com", WantRunning: true},
+			expected: loginSameServer,
+		},
+		{
+			name:     "other control server",
+			prefs:    &tailscalePrefs{ControlURL: "https://controlplane.tailscale.com", WantRunning: true},
+			expected: loginOtherServer,
+		},
+		{
+			name:     "other control server while stopped",
+			prefs:    &tailscalePrefs{ControlURL: "https://controlplane.tailscale.com"},
+			expected: loginOtherServer,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := classifyLogin(tt.prefs, "http://localhost:9080", "https://wonder.example.com")
+			if result != tt.expected {
+				t.Errorf("classifyLogin() = %v, want %v", result, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,136 @@
+package worker
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// tailscaleLocalAPIURL is the base URL of tailscaled's LocalAPI, which
+// serves `tailscale status --json` among others. The host is ignored;
+// requests go to the socket.
+const tailscaleLocalAPIURL = "http://local-tailscaled.sock/localapi/v0/"
+
+// tailscaleStatus is the subset of the LocalAPI status used by
+// wonder worker status.
+type tailscaleStatus struct {
+	BackendState string                    `json:"BackendState"`
+	TailscaleIPs []string                  `json:"TailscaleIPs"`
+	Self         *tailscalePeer            `json:"Self"`
+	Peer         map[string]*tailscalePeer `json:"Peer"`
+}
+
+// tailscalePeer is the subset of a LocalAPI peer status used by
+// wonder worker status. Relay is the peer's home DERP region, and CurAddr
+// is set while traffic to the peer flows directly rather than over DERP.
+type tailscalePeer struct {
+	HostName      string    `json:"HostName"`
+	TailscaleIPs  []string  `json:"TailscaleIPs"`
+	Online        bool      `json:"Online"`
+	Relay         string    `json:"Relay"`
+	CurAddr       string    `json:"CurAddr"`
+	LastHandshake time.Time `json:"LastHandshake"`
+}
+
+// meshStatus is the live connectivity of this machine as reported by
+// tailscaled.
+type meshStatus struct {
+	// State is tailscaled's backend state, e.g. Running or NeedsLogin.
+	State      string   `json:"state"`
+	Addresses  []string `json:"addresses"`
+	DERPRegion string   `json:"derp_region,omitempty"`
+	Peers      int      `json:"peers"`
+	// OnlinePeers are peers connected to the coordinator; DirectPeers are
+	// peers this machine currently reaches without a DERP relay.
+	OnlinePeers int `json:"online_peers"`
+	DirectPeers int `json:"direct_peers"`
+	// LastHandshake is the most recent WireGuard handshake with any peer.
+	LastHandshake *time.Time `json:"last_handshake,omitempty"`
+}
+
+// readTailscaleStatus queries tailscaled's LocalAPI over its socket, or
+// returns nil if tailscaled is not running. Status is readable without
+// root.
+func readTailscaleStatus(ctx context.Context) (*tailscaleStatus, error) {
+	var status tailscaleStatus
+	running, err := getTailscaleLocalAPI(ctx, "status", &status)
+	if err != nil || !running {
+		return nil, err
+	}
+	return &status, nil
+}
+
+// getTailscaleLocalAPI decodes the JSON response of a LocalAPI endpoint into
+// out. It reports false without an error if tailscaled is not running.
+func getTailscaleLocalAPI(ctx context.Context, endpoint string, out any) (bool, error) {
+	if _, err := os.Stat(tailscaledSocketPath); err != nil {
+		return false, nil
+	}
+
+	client := &http.Client{
+		Transport: &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", tailscaledSocketPath)
+			},
+		},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, tailscaleLocalAPIURL+endpoint, nil)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Sec-Tailscale", "localapi")
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return false, fmt.Errorf("query tailscaled: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return false, fmt.Errorf("query tailscaled: %s: %s", resp.Status, strings.TrimSpace(string(body)))
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode tailscaled %s: %w", endpoint, err)
+	}
+	return true, nil
+}
+
+// summarizeMeshStatus reduces tailscaled's status to the fields shown by
+// wonder worker status.
+func summarizeMeshStatus(status *tailscaleStatus) *meshStatus {
+	mesh := &meshStatus{
+		State:     status.BackendState,
+		Addresses: status.TailscaleIPs,
+		Peers:     len(status.Peer),
+	}
+	if status.Self != nil {
+		mesh.DERPRegion = status.Self.Relay
+	}
+
+	var lastHandshake time.Time
+	for _, peer := range status.Peer {
+		if peer.Online {
+			mesh.OnlinePeers++
+		}
+		if peer.CurAddr != "" {
+			mesh.DirectPeers++
+		}
+		if peer.LastHandshake.After(lastHandshake) {
+			lastHandshake = peer.LastHandshake
+		}
+	}
+	if !lastHandshake.IsZero() {
+		mesh.LastHandshake = &lastHandshake
+	}
+	return mesh
+}
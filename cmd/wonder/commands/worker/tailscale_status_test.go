@@ -0,0 +1,50 @@
+package worker
+
+import (
+	"encoding/json"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestSummarizeMeshStatus(t *testing.T) {
+	raw := `{
+		"BackendState": "Running",
+		"TailscaleIPs": ["100.64.0.5", "fd7a:115c:a1e0::5"],
+		"Self": {"HostName": "worker-1", "Online": true, "Relay": "fra"},
+		"Peer": {
+			"nodekey:a": {"HostName": "worker-2", "Online": true, "Relay": "fra", "CurAddr": "192.0.2.10:41641", "LastHandshake": "2026-01-02T03:04:05Z"},
+			"nodekey:b": {"HostName": "worker-3", "Online": true, "Relay": "ams", "CurAddr": "", "LastHandshake": "2026-01-02T03:00:00Z"},
+			"nodekey:c": {"HostName": "worker-4", "Online": false, "Relay": "ams", "LastHandshake": "0001-01-01T00:00:00Z"}
+		}
+	}`
+	var status tailscaleStatus
+	if err := json.Unmarshal([]byte(raw), &status); err != nil {
+		t.Fatalf("decode status: %v", err)
+	}
+
+	got := summarizeMeshStatus(&status)
+	if got.State != "Running" {
+		t.Errorf("State = %q, want %q", got.State, "Running")
+	}
+	if want := []string{"100.64.0.5", "fd7a:115c:a1e0::5"}; !slices.Equal(got.Addresses, want) {
+		t.Errorf("Addresses = %v, want %v", got.Addresses, want)
+	}
+	if got.DERPRegion != "fra" {
+		t.Errorf("DERPRegion = %q, want %q", got.DERPRegion, "fra")
+	}
+	if got.Peers != 3 || got.OnlinePeers != 2 || got.DirectPeers != 1 {
+		t.Errorf("Peers, OnlinePeers, DirectPeers = %d, %d, %d, want 3, 2, 1", got.Peers, got.OnlinePeers, got.DirectPeers)
+	}
+	wantHandshake := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	if got.LastHandshake == nil || !got.LastHandshake.Equal(wantHandshake) {
+		t.Errorf("LastHandshake = %v, want %v", got.LastHandshake, wantHandshake)
+	}
+}
+
+func TestSummarizeMeshStatusWithoutPeers(t *testing.T) {
+	got := summarizeMeshStatus(&tailscaleStatus{BackendState: "NeedsLogin"})
+	if got.Peers != 0 || got.LastHandshake != nil || got.DERPRegion != "" {
+		t.Errorf("summarizeMeshStatus() = %+v, want no peers, handshake, or DERP region", got)
+	}
+}
@@ -0,0 +1,119 @@
+package worker
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+// maxWipeOutputBytes caps the wipe command output sent to the coordinator;
+// longer output keeps its end.
+const maxWipeOutputBytes = 64 << 10
+
+// heartbeatResponse is the coordinator's answer to a heartbeat when it has
+// work for this worker.
+type heartbeatResponse struct {
+	Wipe *wipeInstruction `json:"wipe,omitempty"`
+}
+
+// wipeInstruction asks the worker to run its wipe command because its node
+// is being decommissioned.
+type wipeInstruction struct {
+	DecommissionID string `json:"decommission_id"`
+}
+
+// handleWipe runs the wipe command for a decommission of this node and
+// reports the result. It returns true once a successful wipe was reported,
+// after which the coordinator removes the node and the daemon should stop.
+// If the report fails, the coordinator asks again with the next heartbeat,
+// so wipe commands should be safe to run more than once.
+func handleWipe(ctx context.Context, creds *credentials, wipe *wipeInstruction) bool {
+	slog.Warn("node is being decommissioned, running wipe command", "decommission_id", wipe.DecommissionID)
+
+	success, output := runWipe(ctx, daemonFlags.wipeCommand, daemonFlags.wipeTimeout, wipe.DecommissionID)
+	if err := reportWipeResult(ctx, creds, wipe.DecommissionID, success, output); err != nil {
+		slog.Error("report wipe result", "decommission_id", wipe.DecommissionID, "error", err)
+		return false
+	}
+	if !success {
+		slog.Error("wipe command failed, the node stays cordoned", "decommission_id", wipe.DecommissionID)
+		return false
+	}
+	slog.Info("wipe reported, the node is being removed from the mesh", "decommission_id", wipe.DecommissionID)
+	return true
+}
+
+// runWipe runs command with the shell, passing the decommission ID in the
+// WONDER_DECOMMISSION_ID environment variable. It returns whether the
+// command succeeded within timeout, and the end of its combined output.
+func runWipe(ctx context.Context, command string, timeout time.Duration, decommissionID string) (bool, string) {
+	if command == "" {
+		return false, "no wipe command configured, start the daemon with --wipe-command"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	cmd := shellCommand(ctx, command)
+	cmd.Env = append(os.Environ(), "WONDER_DECOMMISSION_ID="+decommissionID)
+	out, err := cmd.CombinedOutput()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("timed out after %s", timeout)
+	}
+	if err != nil {
+		out = append(out, fmt.Sprintf("\nwipe command failed: %v\n", err)...)
+	}
+	return err == nil, tailOutput(out, maxWipeOutputBytes)
+}
+
+// tailOutput returns the last max bytes of out.
+func tailOutput(out []byte, max int) string {
+	if len(out) > max {
+		out = out[len(out)-max:]
+	}
+	return string(out)
+}
+
+// reportWipeResult posts the result of a wipe command to the coordinator's
+// worker wipe result endpoint.
+func reportWipeResult(ctx context.Context, creds *credentials, decommissionID string, success bool, output string) error {
+	reqBody, err := json.Marshal(map[string]any{
+		"decommission_id": decommissionID,
+		"success":         success,
+		"output":          output,
+	})
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost,
+		creds.CoordinatorURL+"/coordinator/api/v1/worker/wipe-result", bytes.NewReader(reqBody))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+creds.WorkerToken)
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("contact coordinator: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode != http.StatusNoContent {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("coordinator returned %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+	return nil
+}
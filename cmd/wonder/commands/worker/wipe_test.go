@@ -0,0 +1,44 @@
+package worker
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRunWipe(t *testing.T) {
+	tests := []struct {
+		name        string
+		command     string
+		timeout     time.Duration
+		wantSuccess bool
+		wantOutput  string
+	}{
+		{"success", `echo "wiping $WONDER_DECOMMISSION_ID"`, time.Minute, true, "wiping dec-1"},
+		{"failure", "echo partial; exit 3", time.Minute, false, "exit status 3"},
+		{"timeout", "sleep 5", 50 * time.Millisecond, false, "timed out"},
+		{"not configured", "", time.Minute, false, "--wipe-command"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			success, output := runWipe(context.Background(), tt.command, tt.timeout, "dec-1")
+			if success != tt.wantSuccess {
+				t.Errorf("runWipe() success = %v, want %v (output %q)", success, tt.wantSuccess, output)
+			}
+			if !strings.Contains(output, tt.wantOutput) {
+				t.Errorf("runWipe() output = %q, want it to contain %q", output, tt.wantOutput)
+			}
+		})
+	}
+}
+
+func TestTailOutput(t *testing.T) {
+	if got := tailOutput([]byte("short"), 10); got != "short" {
+		t.Errorf("tailOutput() = %q, want %q", got, "short")
+	}
+	if got := tailOutput([]byte("0123456789"), 4); got != "6789" {
+		t.Errorf("tailOutput() = %q, want %q", got, "6789")
+	}
+}
@@ -7,6 +7,7 @@ import (
 	"github.com/spf13/cobra"
 	"github.com/spf13/viper"
 	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands"
+	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/output"
 	"github.com/strrl/wonder-mesh-net/cmd/wonder/commands/worker"
 )
 
@@ -56,10 +57,23 @@ func main() {
 	cobra.OnInitialize(initConfig(&configFile))
 
 	rootCmd.PersistentFlags().StringVar(&configFile, "config", "", "config file (default is $HOME/.wonder/config.yaml)")
+	output.AddFlag(rootCmd)
+	commands.AddProfileFlag(rootCmd)
 
 	rootCmd.AddCommand(commands.NewVersionCmd())
 	rootCmd.AddCommand(commands.NewCoordinatorCmd())
+	rootCmd.AddCommand(commands.NewNodesCmd())
+	rootCmd.AddCommand(commands.NewServicesCmd())
+	rootCmd.AddCommand(commands.NewAccessCmd())
+	rootCmd.AddCommand(commands.NewRoutesCmd())
+	rootCmd.AddCommand(commands.NewSSHCmd())
+	rootCmd.AddCommand(commands.NewTokenCmd())
+	rootCmd.AddCommand(commands.NewAuthCmd())
+	rootCmd.AddCommand(commands.NewProfileCmd())
+	rootCmd.AddCommand(commands.NewAdminCmd())
 	rootCmd.AddCommand(worker.NewWorkerCmd())
+	rootCmd.AddCommand(worker.NewNetCmd())
+	rootCmd.AddCommand(commands.NewDocsCmd())
 
 	if err := rootCmd.Execute(); err != nil {
 		fmt.Fprintln(os.Stderr, err)
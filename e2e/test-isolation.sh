@@ -108,7 +108,7 @@ log_info "User 1 access token obtained: ${ACCESS_TOKEN_USER1:0:50}..."
 log_info "Creating join token for User 1..."
 JOIN_TOKEN_RESPONSE_USER1=$(docker exec deployer curl -s \
     -H "Authorization: Bearer $ACCESS_TOKEN_USER1" \
-    "http://nginx/coordinator/api/v1/join-token")
+    "http://nginx/coordinator/api/v1/join-token?max_uses=3")
 
 JOIN_TOKEN_USER1=$(echo "$JOIN_TOKEN_RESPONSE_USER1" | sed -n 's/.*"token":"\([^"]*\)".*/\1/p')
 if [ -z "$JOIN_TOKEN_USER1" ]; then
@@ -163,7 +163,7 @@ log_info "User 2 access token obtained: ${ACCESS_TOKEN_USER2:0:50}..."
 log_info "Creating join token for User 2..."
 JOIN_TOKEN_RESPONSE_USER2=$(docker exec deployer curl -s \
     -H "Authorization: Bearer $ACCESS_TOKEN_USER2" \
-    "http://nginx/coordinator/api/v1/join-token")
+    "http://nginx/coordinator/api/v1/join-token?max_uses=2")
 
 JOIN_TOKEN_USER2=$(echo "$JOIN_TOKEN_RESPONSE_USER2" | sed -n 's/.*"token":"\([^"]*\)".*/\1/p')
 if [ -z "$JOIN_TOKEN_USER2" ]; then
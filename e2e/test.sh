@@ -115,7 +115,7 @@ log_info "Access token obtained: ${ACCESS_TOKEN:0:50}..."
 log_info "Creating join token..."
 JOIN_TOKEN_RESPONSE=$(docker exec deployer curl -s \
     -H "Authorization: Bearer $ACCESS_TOKEN" \
-    "http://nginx/coordinator/api/v1/join-token")
+    "http://nginx/coordinator/api/v1/join-token?max_uses=3")
 
 JOIN_TOKEN=$(echo "$JOIN_TOKEN_RESPONSE" | sed -n 's/.*"token":"\([^"]*\)".*/\1/p')
 if [ -z "$JOIN_TOKEN" ]; then
@@ -268,7 +268,7 @@ log_info "Creating API key for deployer..."
 API_KEY_RESPONSE=$(docker exec deployer curl -s -X POST \
     -H "Authorization: Bearer $ACCESS_TOKEN" \
     -H "Content-Type: application/json" \
-    -d '{"name": "deployer-key", "expires_in": "24h"}' \
+    -d '{"name": "deployer-key", "expires_in": "24h", "scopes": ["nodes:read", "deployer:join"]}' \
     "http://nginx/coordinator/api/v1/api-keys")
 
 API_KEY=$(echo "$API_KEY_RESPONSE" | sed -n 's/.*"key":"\([^"]*\)".*/\1/p')
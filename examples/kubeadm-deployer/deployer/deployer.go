@@ -1,8 +1,10 @@
 package deployer
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -10,13 +12,20 @@ import (
 	"net/http"
 	"os"
 	"regexp"
+	"slices"
 	"strings"
 	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
 )
 
 const (
 	kubeVersion    = "1.31"
 	podNetworkCIDR = "10.244.0.0/16"
+
+	// sshCertTTL is how long the SSH certificate of a deployment is valid,
+	// enough for installing packages and joining every node.
+	sshCertTTL = 2 * time.Hour
 )
 
 // Config holds the deployer configuration
@@ -27,6 +36,26 @@ type Config struct {
 	SSHUser        string
 	SSHPassword    string
 	SOCKS5Addr     string
+	// SOCKS5APIKey authenticates to the coordinator's mesh proxy when
+	// SOCKS5Addr points at it.
+	SOCKS5APIKey string
+	// SSHCertAPIKey, if set, is an API key with the nodes:write scope used
+	// to get a short-lived SSH certificate for SSHUser from the wonder net's
+	// SSH CA. The nodes are then logged in to with it instead of
+	// SSHPassword; they must have been installed with wonder worker install
+	// --ssh-ca.
+	SSHCertAPIKey string
+
+	// ControlPlaneSelector picks the control plane nodes. More than one
+	// control plane gets stacked etcd. If zero, the first node is the only
+	// control plane.
+	ControlPlaneSelector Selector
+	// WorkerSelector picks the worker nodes among the remaining nodes. If
+	// zero, all of them are workers.
+	WorkerSelector Selector
+	// IPFamily is the family of the mesh addresses the nodes are reached
+	// on. The zero value prefers IPv4.
+	IPFamily wondersdk.IPFamily
 }
 
 // Node represents a node in the mesh
@@ -38,19 +67,24 @@ type Node struct {
 	LastSeen  string   `json:"last_seen,omitempty"`
 }
 
+// clusterNode is a mesh node with a role in the cluster.
+type clusterNode struct {
+	name string
+	// tailscaleIP is used for SSH connectivity via the SOCKS5 proxy.
+	tailscaleIP string
+	// internalIP (Docker network) is used by kubeadm.
+	internalIP string
+}
+
 // Deployer orchestrates Kubernetes cluster bootstrap
 type Deployer struct {
 	config      Config
 	httpClient  *http.Client
 	sshExecutor *SSHExecutor
 
-	// Tailscale IPs - used for SSH connectivity via SOCKS5 proxy
-	controlPlaneTailscaleIP string
-	workerTailscaleIPs      []string
-
-	// Internal IPs (Docker network) - used for kubeadm control plane
-	controlPlaneInternalIP string
-	workerInternalIPs      []string
+	// controlPlanes[0] runs kubeadm init; the others join as control planes.
+	controlPlanes []clusterNode
+	workers       []clusterNode
 
 	kubeconfig string
 }
@@ -68,13 +102,23 @@ func NewDeployer(config Config) (*Deployer, error) {
 	}
 
 	sshConfig := SSHConfig{
-		User:       config.SSHUser,
-		Password:   config.SSHPassword,
-		SOCKS5Addr: config.SOCKS5Addr,
-		Timeout:    30 * time.Second,
+		User:         config.SSHUser,
+		Password:     config.SSHPassword,
+		SOCKS5Addr:   config.SOCKS5Addr,
+		SOCKS5APIKey: config.SOCKS5APIKey,
+		Timeout:      30 * time.Second,
+	}
+	if config.SSHCertAPIKey != "" {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		cert, err := wondersdk.NewClient(config.CoordinatorURL, config.SSHCertAPIKey).NewSSHCertSigner(ctx, "", config.SSHUser, sshCertTTL)
+		if err != nil {
+			return nil, fmt.Errorf("get SSH certificate: %w", err)
+		}
+		sshConfig.Certificate = cert
 	}
 
-	executor, err := NewSSHExecutor(sshConfig)
+	executor, err := NewSSHExecutor(wondersdk.NewClient(config.CoordinatorURL, ""), sshConfig)
 	if err != nil {
 		return nil, fmt.Errorf("create SSH executor: %w", err)
 	}
@@ -86,8 +130,25 @@ func NewDeployer(config Config) (*Deployer, error) {
 	}, nil
 }
 
-// Run executes the full deployment flow
+// ErrNoCluster is returned by Scale when none of the control plane nodes
+// runs a cluster yet.
+var ErrNoCluster = errors.New("no existing cluster found on the control plane nodes; run the deployer without scale first")
+
+// Run executes the full deployment flow. If one of the control plane nodes
+// already runs a cluster, it is reused as in Scale instead of running
+// kubeadm init again.
 func (d *Deployer) Run(ctx context.Context) error {
+	return d.deploy(ctx, false)
+}
+
+// Scale joins the selected nodes that are not yet part of the existing
+// cluster, such as workers that joined the mesh after the first deployment.
+// It returns ErrNoCluster if there is no cluster to scale.
+func (d *Deployer) Scale(ctx context.Context) error {
+	return d.deploy(ctx, true)
+}
+
+func (d *Deployer) deploy(ctx context.Context, requireCluster bool) error {
 	slog.Info("starting Kubernetes cluster deployment")
 
 	if err := d.healthCheck(ctx); err != nil {
@@ -112,28 +173,52 @@ func (d *Deployer) Run(ctx context.Context) error {
 		return fmt.Errorf("discover internal IPs: %w", err)
 	}
 
-	if err := d.installPrerequisites(ctx); err != nil {
-		return fmt.Errorf("install prerequisites: %w", err)
+	existing, err := d.findExistingCluster(ctx)
+	if err != nil {
+		return fmt.Errorf("detect existing cluster: %w", err)
+	}
+	if !existing && requireCluster {
+		return ErrNoCluster
 	}
 
-	joinCommand, err := d.initControlPlane(ctx)
-	if err != nil {
-		return fmt.Errorf("init control plane: %w", err)
+	plan := joinPlan{
+		controlPlanes: d.controlPlanes[1:],
+		workers:       d.workers,
+		expectedNodes: len(d.allNodes()),
 	}
+	if existing {
+		plan, err = d.prepareScale(ctx)
+		if err != nil {
+			return err
+		}
+	} else {
+		if err := d.installPrerequisites(ctx, d.allNodes()); err != nil {
+			return fmt.Errorf("install prerequisites: %w", err)
+		}
+
+		plan.joinCommand, plan.certificateKey, err = d.initControlPlane(ctx)
+		if err != nil {
+			return fmt.Errorf("init control plane: %w", err)
+		}
 
-	if err := d.installFlannel(ctx); err != nil {
-		return fmt.Errorf("install CNI: %w", err)
+		if err := d.installFlannel(ctx); err != nil {
+			return fmt.Errorf("install CNI: %w", err)
+		}
+
+		if err := d.patchCoreDNS(ctx); err != nil {
+			return fmt.Errorf("patch CoreDNS: %w", err)
+		}
 	}
 
-	if err := d.patchCoreDNS(ctx); err != nil {
-		return fmt.Errorf("patch CoreDNS: %w", err)
+	if err := d.joinControlPlanes(ctx, plan.controlPlanes, plan.joinCommand, plan.certificateKey); err != nil {
+		return fmt.Errorf("join control planes: %w", err)
 	}
 
-	if err := d.joinWorkers(ctx, joinCommand); err != nil {
+	if err := d.joinWorkers(ctx, plan.workers, plan.joinCommand); err != nil {
 		return fmt.Errorf("join workers: %w", err)
 	}
 
-	if err := d.waitForCluster(ctx, 5*time.Minute); err != nil {
+	if err := d.waitForCluster(ctx, plan.expectedNodes, 5*time.Minute); err != nil {
 		return fmt.Errorf("wait for cluster: %w", err)
 	}
 
@@ -142,14 +227,137 @@ func (d *Deployer) Run(ctx context.Context) error {
 	}
 
 	fmt.Println("\n=== Deployment Complete ===")
-	fmt.Printf("Control Plane: %s (internal), %s (tailscale)\n", d.controlPlaneInternalIP, d.controlPlaneTailscaleIP)
-	fmt.Printf("Workers: %v (internal)\n", d.workerInternalIPs)
+	for _, cp := range d.controlPlanes {
+		fmt.Printf("Control Plane: %s, %s (internal), %s (tailscale)\n", cp.name, cp.internalIP, cp.tailscaleIP)
+	}
+	fmt.Printf("Workers: %v (internal)\n", d.GetWorkerIPs())
+	if existing {
+		fmt.Printf("Newly joined: %d control plane(s), %d worker(s)\n", len(plan.controlPlanes), len(plan.workers))
+	}
 	fmt.Println("\nTo access the cluster from the deployer:")
 	fmt.Printf("  kubectl --kubeconfig /tmp/kubeconfig get nodes\n")
 
 	return nil
 }
 
+// findExistingCluster reports whether one of the control plane nodes
+// already runs a cluster, detected by /etc/kubernetes/admin.conf. That node
+// is moved to the front of d.controlPlanes so that it runs kubectl and hands
+// out join credentials.
+func (d *Deployer) findExistingCluster(ctx context.Context) (bool, error) {
+	for i, cp := range d.controlPlanes {
+		result, err := d.sshExecutor.RunOnNode(ctx, cp.tailscaleIP, "test -f /etc/kubernetes/admin.conf")
+		if err != nil {
+			return false, fmt.Errorf("node %s: %w", cp.name, err)
+		}
+		if result.ExitCode != 0 {
+			continue
+		}
+
+		slog.Info("found existing cluster, skipping kubeadm init", "control_plane", cp.name)
+		d.controlPlanes[0], d.controlPlanes[i] = d.controlPlanes[i], d.controlPlanes[0]
+		return true, nil
+	}
+	return false, nil
+}
+
+// joinPlan lists the nodes to join to the cluster and how.
+type joinPlan struct {
+	joinCommand    string
+	certificateKey string
+	controlPlanes  []clusterNode
+	workers        []clusterNode
+	// expectedNodes is the number of Ready nodes once all have joined.
+	expectedNodes int
+}
+
+// prepareScale plans joining the selected nodes that are not in the
+// existing cluster yet, with fresh join credentials, after installing the
+// prerequisites on those nodes.
+func (d *Deployer) prepareScale(ctx context.Context) (joinPlan, error) {
+	members, err := d.clusterMemberIPs(ctx)
+	if err != nil {
+		return joinPlan{}, fmt.Errorf("list cluster nodes: %w", err)
+	}
+
+	var plan joinPlan
+	plan.controlPlanes, err = d.filterNewNodes(ctx, d.controlPlanes[1:], members)
+	if err != nil {
+		return joinPlan{}, err
+	}
+	plan.workers, err = d.filterNewNodes(ctx, d.workers, members)
+	if err != nil {
+		return joinPlan{}, err
+	}
+	plan.expectedNodes = len(members) + len(plan.controlPlanes) + len(plan.workers)
+	slog.Info("nodes to join the existing cluster",
+		"members", len(members),
+		"control_planes", nodeIPs(plan.controlPlanes),
+		"workers", nodeIPs(plan.workers),
+	)
+
+	if err := d.installPrerequisites(ctx, append(slices.Clone(plan.controlPlanes), plan.workers...)); err != nil {
+		return joinPlan{}, fmt.Errorf("install prerequisites: %w", err)
+	}
+
+	result, err := d.sshExecutor.RunOnNode(ctx, d.initNode().tailscaleIP, "set -e\n"+clusterCredentialsScript(len(plan.controlPlanes) > 0))
+	if err != nil {
+		return joinPlan{}, fmt.Errorf("create join command: %w", err)
+	}
+	if result.ExitCode != 0 {
+		return joinPlan{}, fmt.Errorf("create join command: exit code %d, stderr: %s", result.ExitCode, result.Stderr)
+	}
+	plan.joinCommand, plan.certificateKey = d.parseClusterCredentials(result.Stdout)
+	if plan.joinCommand == "" {
+		return joinPlan{}, fmt.Errorf("create join command: no join command in output")
+	}
+
+	return plan, nil
+}
+
+// clusterMemberIPs returns the internal IPs of the nodes registered in the
+// existing cluster.
+func (d *Deployer) clusterMemberIPs(ctx context.Context) (map[string]bool, error) {
+	result, err := d.sshExecutor.RunOnNode(ctx, d.initNode().tailscaleIP,
+		`kubectl --kubeconfig /etc/kubernetes/admin.conf get nodes -o jsonpath='{range .items[*]}{.status.addresses[?(@.type=="InternalIP")].address}{"\n"}{end}'`)
+	if err != nil {
+		return nil, err
+	}
+	if result.ExitCode != 0 {
+		return nil, fmt.Errorf("exit code %d, stderr: %s", result.ExitCode, result.Stderr)
+	}
+
+	members := make(map[string]bool)
+	for _, ip := range strings.Fields(result.Stdout) {
+		members[ip] = true
+	}
+	return members, nil
+}
+
+// filterNewNodes returns the nodes that have not joined a cluster: they are
+// not registered in it and have no /etc/kubernetes/kubelet.conf. A node
+// with a kubelet.conf that the cluster does not know is skipped with a
+// warning, as joining it again needs a reset first.
+func (d *Deployer) filterNewNodes(ctx context.Context, nodes []clusterNode, members map[string]bool) ([]clusterNode, error) {
+	var fresh []clusterNode
+	for _, node := range nodes {
+		if members[node.internalIP] {
+			continue
+		}
+
+		result, err := d.sshExecutor.RunOnNode(ctx, node.tailscaleIP, "test -f /etc/kubernetes/kubelet.conf")
+		if err != nil {
+			return nil, fmt.Errorf("node %s: %w", node.name, err)
+		}
+		if result.ExitCode == 0 {
+			slog.Warn("skipping node that was joined to a cluster but is not registered in this one; reset it to join again", "name", node.name)
+			continue
+		}
+		fresh = append(fresh, node)
+	}
+	return fresh, nil
+}
+
 // SaveKubeconfig saves the admin kubeconfig to a file
 func (d *Deployer) SaveKubeconfig(path string) error {
 	if d.kubeconfig == "" {
@@ -164,14 +372,22 @@ func (d *Deployer) SaveKubeconfig(path string) error {
 	return nil
 }
 
-// GetControlPlaneIP returns the control plane internal IP
+// GetControlPlaneIP returns the internal IP of the control plane that
+// initialized the cluster
 func (d *Deployer) GetControlPlaneIP() string {
-	return d.controlPlaneInternalIP
+	if len(d.controlPlanes) == 0 {
+		return ""
+	}
+	return d.controlPlanes[0].internalIP
 }
 
 // GetWorkerIPs returns the worker internal IPs
 func (d *Deployer) GetWorkerIPs() []string {
-	return d.workerInternalIPs
+	ips := make([]string, len(d.workers))
+	for i, worker := range d.workers {
+		ips[i] = worker.internalIP
+	}
+	return ips
 }
 
 // GetKubeconfig returns the admin kubeconfig
@@ -185,8 +401,7 @@ func (d *Deployer) GetKubeconfig() string {
 // intentionally tearing down a cluster.
 func (d *Deployer) Reset(ctx context.Context) error {
 	// Use Tailscale IPs for SSH access
-	allTailscaleIPs := append([]string{d.controlPlaneTailscaleIP}, d.workerTailscaleIPs...)
-	for _, ip := range allTailscaleIPs {
+	for _, ip := range d.tailscaleIPs() {
 		if err := d.resetNode(ctx, ip); err != nil {
 			slog.Warn("reset node", "node", ip, "error", err)
 		}
@@ -194,6 +409,26 @@ func (d *Deployer) Reset(ctx context.Context) error {
 	return nil
 }
 
+// initNode returns the control plane that runs kubeadm init and kubectl.
+func (d *Deployer) initNode() clusterNode {
+	return d.controlPlanes[0]
+}
+
+// allNodes returns the control planes followed by the workers.
+func (d *Deployer) allNodes() []clusterNode {
+	return append(slices.Clone(d.controlPlanes), d.workers...)
+}
+
+// tailscaleIPs returns the Tailscale IPs of all nodes, for SSH access.
+func (d *Deployer) tailscaleIPs() []string {
+	nodes := d.allNodes()
+	ips := make([]string, len(nodes))
+	for i, node := range nodes {
+		ips[i] = node.tailscaleIP
+	}
+	return ips
+}
+
 // healthCheck verifies the coordinator is reachable.
 func (d *Deployer) healthCheck(ctx context.Context) error {
 	req, err := http.NewRequestWithContext(ctx, http.MethodGet, d.config.CoordinatorURL+"/health", nil)
@@ -272,113 +507,98 @@ func (d *Deployer) discoverNodes(ctx context.Context) ([]Node, error) {
 	return online, nil
 }
 
-// selectIPv4 returns the first IPv4 address from the list.
-// Headscale/Tailscale may return both IPv4 and IPv6, and the ordering is not
-// guaranteed. IPv6 literals without brackets break SSH address formatting, so
-// we prefer IPv4. Falls back to the first address if no IPv4 is found.
-func selectIPv4(addresses []string) string {
-	for _, addr := range addresses {
-		ip := net.ParseIP(addr)
-		if ip != nil && ip.To4() != nil {
-			return addr
-		}
-	}
-	if len(addresses) > 0 {
-		return addresses[0]
-	}
-	return ""
-}
-
-// selectNodes assigns roles to mesh nodes for Kubernetes cluster deployment.
-// The first node in the slice becomes the control plane; remaining nodes become workers.
-// It extracts IPv4 addresses from each node's Tailscale addresses for SSH connectivity.
-//
-// The function populates:
-//   - d.controlPlaneTailscaleIP: the control plane node's IPv4 address
-//   - d.workerTailscaleIPs: slice of worker nodes' IPv4 addresses
+// selectNodes assigns roles to mesh nodes for Kubernetes cluster deployment
+// using the configured selectors (see assignRoles). Each node is reached
+// over SSH on its Tailscale address of the configured IP family.
 //
-// Returns an error if fewer than 1 node is provided or if the control plane node
-// has no valid IPv4 address. Worker nodes without IPv4 addresses are silently skipped.
+// Returns an error if no node is provided, a selector matches nothing, or a
+// control plane node has no address of the family. Worker nodes without
+// one are skipped.
 func (d *Deployer) selectNodes(nodes []Node) error {
-	if len(nodes) < 1 {
-		return fmt.Errorf("at least 1 node required, found %d", len(nodes))
+	controlPlanes, workers, err := assignRoles(nodes, d.config.ControlPlaneSelector, d.config.WorkerSelector)
+	if err != nil {
+		return err
 	}
 
-	d.controlPlaneTailscaleIP = selectIPv4(nodes[0].Addresses)
-	if d.controlPlaneTailscaleIP == "" {
-		return fmt.Errorf("control plane node has no IP address")
+	d.controlPlanes = make([]clusterNode, 0, len(controlPlanes))
+	for _, node := range controlPlanes {
+		addr := wondersdk.SelectAddress(node.Addresses, d.config.IPFamily)
+		if addr == "" {
+			return fmt.Errorf("control plane node %s has no %s address", node.Name, cmp.Or(string(d.config.IPFamily), "IP"))
+		}
+		d.controlPlanes = append(d.controlPlanes, clusterNode{name: node.Name, tailscaleIP: addr})
+	}
+	if len(d.controlPlanes) > 1 && len(d.controlPlanes)%2 == 0 {
+		slog.Warn("an even number of control planes tolerates no more etcd failures than one less", "control_planes", len(d.controlPlanes))
 	}
 
-	d.workerTailscaleIPs = make([]string, 0, len(nodes)-1)
-	for i := 1; i < len(nodes); i++ {
-		addr := selectIPv4(nodes[i].Addresses)
-		if addr != "" {
-			d.workerTailscaleIPs = append(d.workerTailscaleIPs, addr)
+	d.workers = make([]clusterNode, 0, len(workers))
+	for _, node := range workers {
+		addr := wondersdk.SelectAddress(node.Addresses, d.config.IPFamily)
+		if addr == "" {
+			slog.Warn("skipping worker without address", "name", node.Name, "ip_family", cmp.Or(string(d.config.IPFamily), "IP"))
+			continue
 		}
+		d.workers = append(d.workers, clusterNode{name: node.Name, tailscaleIP: addr})
 	}
 
 	slog.Info("node selection (Tailscale IPs for SSH)",
-		"control_plane", d.controlPlaneTailscaleIP,
-		"workers", d.workerTailscaleIPs,
+		"control_planes", nodeIPs(d.controlPlanes),
+		"workers", nodeIPs(d.workers),
 	)
 
 	return nil
 }
 
+// nodeIPs lists "name (tailscale IP)" for logging.
+func nodeIPs(nodes []clusterNode) []string {
+	ips := make([]string, len(nodes))
+	for i, node := range nodes {
+		ips[i] = fmt.Sprintf("%s (%s)", node.name, node.tailscaleIP)
+	}
+	return ips
+}
+
 func (d *Deployer) waitForSSH(ctx context.Context, timeout time.Duration) error {
 	slog.Info("waiting for SSH connectivity")
 
-	allIPs := append([]string{d.controlPlaneTailscaleIP}, d.workerTailscaleIPs...)
-	return d.sshExecutor.WaitForAllNodes(ctx, allIPs, timeout)
+	return d.sshExecutor.WaitForAllNodes(ctx, d.tailscaleIPs(), timeout)
 }
 
 // discoverInternalIPs queries each node for its Docker network IP (eth0)
 func (d *Deployer) discoverInternalIPs(ctx context.Context) error {
 	slog.Info("discovering internal IPs for kubeadm")
 
-	// Get control plane internal IP
-	result, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP,
-		"ip -4 addr show eth0 | grep -oP '(?<=inet\\s)\\d+(\\.\\d+){3}'")
-	if err != nil {
-		return fmt.Errorf("get control plane internal IP: %w", err)
-	}
-	d.controlPlaneInternalIP = strings.TrimSpace(result.Stdout)
-	if d.controlPlaneInternalIP == "" {
-		return fmt.Errorf("control plane has no eth0 IP")
-	}
-
-	// Get worker internal IPs
-	d.workerInternalIPs = make([]string, 0, len(d.workerTailscaleIPs))
-	for _, tailscaleIP := range d.workerTailscaleIPs {
-		result, err := d.sshExecutor.RunOnNode(ctx, tailscaleIP,
-			"ip -4 addr show eth0 | grep -oP '(?<=inet\\s)\\d+(\\.\\d+){3}'")
-		if err != nil {
-			return fmt.Errorf("get worker internal IP for %s: %w", tailscaleIP, err)
-		}
-		internalIP := strings.TrimSpace(result.Stdout)
-		if internalIP == "" {
-			return fmt.Errorf("worker %s has no eth0 IP", tailscaleIP)
+	for _, nodes := range [][]clusterNode{d.controlPlanes, d.workers} {
+		for i := range nodes {
+			result, err := d.sshExecutor.RunOnNode(ctx, nodes[i].tailscaleIP,
+				"ip -4 addr show eth0 | grep -oP '(?<=inet\\s)\\d+(\\.\\d+){3}'")
+			if err != nil {
+				return fmt.Errorf("get internal IP for %s: %w", nodes[i].name, err)
+			}
+			nodes[i].internalIP = strings.TrimSpace(result.Stdout)
+			if nodes[i].internalIP == "" {
+				return fmt.Errorf("node %s has no eth0 IP", nodes[i].name)
+			}
 		}
-		d.workerInternalIPs = append(d.workerInternalIPs, internalIP)
 	}
 
 	slog.Info("discovered internal IPs (Docker network for kubeadm)",
-		"control_plane", d.controlPlaneInternalIP,
-		"workers", d.workerInternalIPs,
+		"control_plane", d.GetControlPlaneIP(),
+		"workers", d.GetWorkerIPs(),
 	)
 
 	return nil
 }
 
-func (d *Deployer) installPrerequisites(ctx context.Context) error {
-	slog.Info("installing prerequisites on all nodes")
+func (d *Deployer) installPrerequisites(ctx context.Context, nodes []clusterNode) error {
+	slog.Info("installing prerequisites", "nodes", len(nodes))
 
 	// Use Tailscale IPs for SSH access
-	allTailscaleIPs := append([]string{d.controlPlaneTailscaleIP}, d.workerTailscaleIPs...)
-	for idx, ip := range allTailscaleIPs {
-		slog.Info("installing on node", "node", ip, "index", idx+1, "total", len(allTailscaleIPs))
-		if err := d.installOnNode(ctx, ip); err != nil {
-			return fmt.Errorf("node %s: %w", ip, err)
+	for idx, node := range nodes {
+		slog.Info("installing on node", "node", node.tailscaleIP, "index", idx+1, "total", len(nodes))
+		if err := d.installOnNode(ctx, node.tailscaleIP); err != nil {
+			return fmt.Errorf("node %s: %w", node.name, err)
 		}
 	}
 	return nil
@@ -550,10 +770,23 @@ echo "kubeadm installed successfully"
 	return nil
 }
 
-func (d *Deployer) initControlPlane(ctx context.Context) (string, error) {
+// initControlPlane runs kubeadm init on the first control plane and returns
+// the worker join command. With more than one control plane, it also uploads
+// the control plane certificates and returns the key that other control
+// planes need to join with stacked etcd.
+func (d *Deployer) initControlPlane(ctx context.Context) (joinCommand, certificateKey string, err error) {
+	cp := d.initNode()
 	slog.Info("initializing control plane",
-		"ssh", d.controlPlaneTailscaleIP,
-		"apiserver", d.controlPlaneInternalIP)
+		"ssh", cp.tailscaleIP,
+		"apiserver", cp.internalIP)
+
+	// Joining control planes need a shared endpoint. The first control
+	// plane's address is used, as the demo has no load balancer in front of
+	// the API servers.
+	controlPlaneEndpoint := ""
+	if len(d.controlPlanes) > 1 {
+		controlPlaneEndpoint = "controlPlaneEndpoint: " + net.JoinHostPort(cp.internalIP, "6443")
+	}
 
 	// Use internal IP for kubeadm (Docker network), SSH via Tailscale
 	// Use kubeadm config file to configure kube-proxy to skip conntrack settings
@@ -572,6 +805,7 @@ nodeRegistration:
 ---
 apiVersion: kubeadm.k8s.io/v1beta4
 kind: ClusterConfiguration
+%s
 networking:
   podSubnet: %s
 ---
@@ -586,50 +820,79 @@ kubeadm init --config /tmp/kubeadm-config.yaml 2>&1
 mkdir -p /root/.kube
 cp /etc/kubernetes/admin.conf /root/.kube/config
 chown root:root /root/.kube/config
-
-echo "=== KUBECONFIG ==="
-cat /root/.kube/config
-echo "=== END KUBECONFIG ==="
-
-echo "=== JOIN COMMAND ==="
-kubeadm token create --print-join-command
-echo "=== END JOIN COMMAND ==="
-`, d.controlPlaneInternalIP, podNetworkCIDR)
+%s`, cp.internalIP, controlPlaneEndpoint, podNetworkCIDR, clusterCredentialsScript(len(d.controlPlanes) > 1))
 
 	// SSH via Tailscale IP
-	result, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, initCmd)
+	result, err := d.sshExecutor.RunOnNode(ctx, cp.tailscaleIP, initCmd)
 	if err != nil {
-		return "", fmt.Errorf("kubeadm init: %w", err)
+		return "", "", fmt.Errorf("kubeadm init: %w", err)
 	}
 	if result.ExitCode != 0 {
-		return "", fmt.Errorf("kubeadm init: exit code %d, stderr: %s", result.ExitCode, result.Stderr)
+		return "", "", fmt.Errorf("kubeadm init: exit code %d, stderr: %s", result.ExitCode, result.Stderr)
 	}
 
-	kubeconfigRe := regexp.MustCompile(`=== KUBECONFIG ===\n([\s\S]*?)=== END KUBECONFIG ===`)
-	if match := kubeconfigRe.FindStringSubmatch(result.Stdout); len(match) > 1 {
-		d.kubeconfig = strings.TrimSpace(match[1])
-	}
-
-	var joinCommand string
-	joinCmdRe := regexp.MustCompile(`=== JOIN COMMAND ===\n([\s\S]*?)=== END JOIN COMMAND ===`)
-	if match := joinCmdRe.FindStringSubmatch(result.Stdout); len(match) > 1 {
-		joinCommand = strings.TrimSpace(match[1])
-	}
+	joinCommand, certificateKey = d.parseClusterCredentials(result.Stdout)
 
 	slog.Info("control plane initialized",
-		"node", d.controlPlaneInternalIP,
+		"node", cp.internalIP,
 		"has_kubeconfig", d.kubeconfig != "",
 		"has_join_command", joinCommand != "",
+		"has_certificate_key", certificateKey != "",
 	)
 
-	return joinCommand, nil
+	return joinCommand, certificateKey, nil
+}
+
+// clusterCredentialsScript prints the admin kubeconfig, a fresh join
+// command, and with uploadCerts the key of freshly uploaded control plane
+// certificates, in the markers parseClusterCredentials reads.
+func clusterCredentialsScript(uploadCerts bool) string {
+	script := `
+echo "=== KUBECONFIG ==="
+cat /etc/kubernetes/admin.conf
+echo "=== END KUBECONFIG ==="
+
+echo "=== JOIN COMMAND ==="
+kubeadm token create --print-join-command
+echo "=== END JOIN COMMAND ==="
+`
+	if uploadCerts {
+		script += `
+echo "=== CERTIFICATE KEY ==="
+kubeadm init phase upload-certs --upload-certs 2>/dev/null | tail -n 1
+echo "=== END CERTIFICATE KEY ==="
+`
+	}
+	return script
+}
+
+var (
+	kubeconfigRe     = regexp.MustCompile(`=== KUBECONFIG ===\n([\s\S]*?)=== END KUBECONFIG ===`)
+	joinCommandRe    = regexp.MustCompile(`=== JOIN COMMAND ===\n([\s\S]*?)=== END JOIN COMMAND ===`)
+	certificateKeyRe = regexp.MustCompile(`=== CERTIFICATE KEY ===\n([\s\S]*?)=== END CERTIFICATE KEY ===`)
+)
+
+// parseClusterCredentials reads the output of clusterCredentialsScript,
+// keeping the kubeconfig and returning the join command and certificate key.
+func (d *Deployer) parseClusterCredentials(stdout string) (joinCommand, certificateKey string) {
+	if match := kubeconfigRe.FindStringSubmatch(stdout); len(match) > 1 {
+		d.kubeconfig = strings.TrimSpace(match[1])
+	}
+	if match := joinCommandRe.FindStringSubmatch(stdout); len(match) > 1 {
+		joinCommand = strings.TrimSpace(match[1])
+	}
+	if match := certificateKeyRe.FindStringSubmatch(stdout); len(match) > 1 {
+		certificateKey = strings.TrimSpace(match[1])
+	}
+	return joinCommand, certificateKey
 }
 
 // installFlannel installs Flannel CNI.
 // Flannel is used because it works well in containerized environments
 // without requiring BPF filesystem access.
 func (d *Deployer) installFlannel(ctx context.Context) error {
-	slog.Info("installing Flannel CNI", "node", d.controlPlaneInternalIP)
+	cp := d.initNode()
+	slog.Info("installing Flannel CNI", "node", cp.internalIP)
 
 	// With internal Docker IPs, standard Flannel setup works without hacks
 	installCmd := `
@@ -656,7 +919,7 @@ echo "Flannel CNI installed"
 `
 
 	// SSH via Tailscale IP
-	result, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, installCmd)
+	result, err := d.sshExecutor.RunOnNode(ctx, cp.tailscaleIP, installCmd)
 	if err != nil {
 		return fmt.Errorf("flannel install: %w", err)
 	}
@@ -665,7 +928,7 @@ echo "Flannel CNI installed"
 			result.ExitCode, result.Stdout, result.Stderr)
 	}
 
-	slog.Info("Flannel CNI installed", "node", d.controlPlaneInternalIP)
+	slog.Info("Flannel CNI installed", "node", cp.internalIP)
 	return nil
 }
 
@@ -673,7 +936,8 @@ echo "Flannel CNI installed"
 // This fixes a loop detection issue in containerized environments where
 // /etc/resolv.conf points to localhost or creates a DNS loop.
 func (d *Deployer) patchCoreDNS(ctx context.Context) error {
-	slog.Info("patching CoreDNS to use external DNS", "node", d.controlPlaneInternalIP)
+	cp := d.initNode()
+	slog.Info("patching CoreDNS to use external DNS", "node", cp.internalIP)
 
 	patchCmd := `
 set -e
@@ -697,7 +961,7 @@ kubectl rollout restart deployment coredns -n kube-system
 echo "CoreDNS patched successfully"
 `
 
-	result, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, patchCmd)
+	result, err := d.sshExecutor.RunOnNode(ctx, cp.tailscaleIP, patchCmd)
 	if err != nil {
 		return fmt.Errorf("patch CoreDNS: %w", err)
 	}
@@ -706,44 +970,81 @@ echo "CoreDNS patched successfully"
 			result.ExitCode, result.Stdout, result.Stderr)
 	}
 
-	slog.Info("CoreDNS patched", "node", d.controlPlaneInternalIP)
+	slog.Info("CoreDNS patched", "node", cp.internalIP)
+	return nil
+}
+
+// joinControlPlanes joins control planes with stacked etcd, using the
+// certificates uploaded with certificateKey.
+func (d *Deployer) joinControlPlanes(ctx context.Context, others []clusterNode, joinCommand, certificateKey string) error {
+	if len(others) == 0 {
+		return nil
+	}
+	if certificateKey == "" {
+		return fmt.Errorf("no certificate key for joining control planes")
+	}
+
+	slog.Info("joining control plane nodes", "count", len(others))
+
+	for idx, cp := range others {
+		slog.Info("joining control plane", "name", cp.name, "ssh", cp.tailscaleIP, "index", idx+1, "total", len(others))
+
+		joinCmd := fmt.Sprintf(`
+set -e
+%s --control-plane --certificate-key %s --apiserver-advertise-address %s --ignore-preflight-errors=all 2>&1
+
+mkdir -p /root/.kube
+cp /etc/kubernetes/admin.conf /root/.kube/config
+`, joinCommand, certificateKey, cp.internalIP)
+
+		if err := d.runJoin(ctx, cp, joinCmd); err != nil {
+			return err
+		}
+		slog.Info("control plane joined", "name", cp.name)
+	}
+
 	return nil
 }
 
-func (d *Deployer) joinWorkers(ctx context.Context, joinCommand string) error {
-	if len(d.workerTailscaleIPs) == 0 {
+func (d *Deployer) joinWorkers(ctx context.Context, workers []clusterNode, joinCommand string) error {
+	if len(workers) == 0 {
 		slog.Info("no worker nodes to join")
 		return nil
 	}
 
-	slog.Info("joining worker nodes", "count", len(d.workerTailscaleIPs))
+	slog.Info("joining worker nodes", "count", len(workers))
 
-	// SSH via Tailscale IPs
-	for idx, tailscaleIP := range d.workerTailscaleIPs {
-		slog.Info("joining worker", "ssh", tailscaleIP, "index", idx+1, "total", len(d.workerTailscaleIPs))
+	for idx, worker := range workers {
+		slog.Info("joining worker", "name", worker.name, "ssh", worker.tailscaleIP, "index", idx+1, "total", len(workers))
 
 		joinCmd := fmt.Sprintf(`
 set -e
 %s --ignore-preflight-errors=all 2>&1
 `, joinCommand)
 
-		result, err := d.sshExecutor.RunOnNode(ctx, tailscaleIP, joinCmd)
-		if err != nil {
-			return fmt.Errorf("worker %s: %w", tailscaleIP, err)
+		if err := d.runJoin(ctx, worker, joinCmd); err != nil {
+			return err
 		}
-		if result.ExitCode != 0 {
-			return fmt.Errorf("worker %s: exit code %d, output: %s, stderr: %s",
-				tailscaleIP, result.ExitCode, result.Stdout, result.Stderr)
-		}
-
-		slog.Info("worker joined", "node", tailscaleIP)
+		slog.Info("worker joined", "name", worker.name)
 	}
 
 	return nil
 }
 
-func (d *Deployer) waitForCluster(ctx context.Context, timeout time.Duration) error {
-	expectedNodes := 1 + len(d.workerTailscaleIPs)
+// runJoin runs a kubeadm join script on a node, via its Tailscale IP.
+func (d *Deployer) runJoin(ctx context.Context, node clusterNode, joinCmd string) error {
+	result, err := d.sshExecutor.RunOnNode(ctx, node.tailscaleIP, joinCmd)
+	if err != nil {
+		return fmt.Errorf("node %s: %w", node.name, err)
+	}
+	if result.ExitCode != 0 {
+		return fmt.Errorf("node %s: exit code %d, output: %s, stderr: %s",
+			node.name, result.ExitCode, result.Stdout, result.Stderr)
+	}
+	return nil
+}
+
+func (d *Deployer) waitForCluster(ctx context.Context, expectedNodes int, timeout time.Duration) error {
 	slog.Info("waiting for nodes", "expected", expectedNodes, "timeout", timeout)
 
 	deadline := time.Now().Add(timeout)
@@ -753,7 +1054,7 @@ func (d *Deployer) waitForCluster(ctx context.Context, timeout time.Duration) er
 		checkCmd := `kubectl get nodes --no-headers 2>/dev/null | grep -c " Ready " || echo 0`
 
 		// SSH via Tailscale IP
-		result, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, checkCmd)
+		result, err := d.sshExecutor.RunOnNode(ctx, d.initNode().tailscaleIP, checkCmd)
 		if err == nil && result.ExitCode == 0 {
 			count := 0
 			fmt.Sscanf(strings.TrimSpace(result.Stdout), "%d", &count)
@@ -778,23 +1079,24 @@ func (d *Deployer) waitForCluster(ctx context.Context, timeout time.Duration) er
 
 func (d *Deployer) verifyCluster(ctx context.Context) error {
 	slog.Info("verifying cluster")
+	cpIP := d.initNode().tailscaleIP
 
 	// SSH via Tailscale IP
-	nodesResult, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, "kubectl get nodes -o wide")
+	nodesResult, err := d.sshExecutor.RunOnNode(ctx, cpIP, "kubectl get nodes -o wide")
 	if err != nil {
 		return fmt.Errorf("get nodes: %w", err)
 	}
 	fmt.Println("\n=== Kubernetes Nodes ===")
 	fmt.Println(nodesResult.Stdout)
 
-	podsResult, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP, "kubectl get pods -n kube-system -o wide")
+	podsResult, err := d.sshExecutor.RunOnNode(ctx, cpIP, "kubectl get pods -n kube-system -o wide")
 	if err != nil {
 		return fmt.Errorf("get pods: %w", err)
 	}
 	fmt.Println("\n=== kube-system Pods ===")
 	fmt.Println(podsResult.Stdout)
 
-	flannelResult, err := d.sshExecutor.RunOnNode(ctx, d.controlPlaneTailscaleIP,
+	flannelResult, err := d.sshExecutor.RunOnNode(ctx, cpIP,
 		"kubectl get pods -n kube-flannel -o wide")
 	if err != nil {
 		slog.Warn("get flannel pods", "error", err)
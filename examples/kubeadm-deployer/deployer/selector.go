@@ -0,0 +1,161 @@
+package deployer
+
+import (
+	"fmt"
+	"net"
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// selectorKeys are the node fields a selector term can match.
+var selectorKeys = []string{"name", "id", "address"}
+
+// selectorTerm matches one node field against a value.
+type selectorTerm struct {
+	key   string
+	value string
+}
+
+// Selector picks nodes by their fields. It is a comma-separated list of
+// key=value terms that must all match:
+//
+//   - name=<glob>: the node name, with * and ? wildcards (e.g. name=cp-*)
+//   - id=<id>: the node ID
+//   - address=<ip or CIDR>: one of the node's mesh addresses
+//
+// The zero Selector matches every node.
+type Selector struct {
+	raw   string
+	terms []selectorTerm
+}
+
+// ParseSelector parses a selector such as "name=cp-*,address=100.64.0.0/24".
+// An empty string gives the zero Selector.
+func ParseSelector(s string) (Selector, error) {
+	sel := Selector{raw: s}
+	if strings.TrimSpace(s) == "" {
+		return sel, nil
+	}
+
+	for _, part := range strings.Split(s, ",") {
+		key, value, ok := strings.Cut(strings.TrimSpace(part), "=")
+		if !ok || value == "" {
+			return Selector{}, fmt.Errorf("selector term %q must be key=value", part)
+		}
+		if !slices.Contains(selectorKeys, key) {
+			return Selector{}, fmt.Errorf("selector key %q is not supported, use one of %s", key, strings.Join(selectorKeys, ", "))
+		}
+		if key == "name" {
+			if _, err := path.Match(value, ""); err != nil {
+				return Selector{}, fmt.Errorf("selector %q: invalid name pattern: %w", part, err)
+			}
+		}
+		if key == "address" && net.ParseIP(value) == nil {
+			if _, _, err := net.ParseCIDR(value); err != nil {
+				return Selector{}, fmt.Errorf("selector %q: address must be an IP or CIDR", part)
+			}
+		}
+		sel.terms = append(sel.terms, selectorTerm{key: key, value: value})
+	}
+	return sel, nil
+}
+
+// IsZero reports whether the selector has no terms.
+func (s Selector) IsZero() bool {
+	return len(s.terms) == 0
+}
+
+// String returns the selector as it was given.
+func (s Selector) String() string {
+	return s.raw
+}
+
+// Matches reports whether the node matches all terms of the selector.
+func (s Selector) Matches(node Node) bool {
+	for _, term := range s.terms {
+		if !term.matches(node) {
+			return false
+		}
+	}
+	return true
+}
+
+func (t selectorTerm) matches(node Node) bool {
+	switch t.key {
+	case "name":
+		ok, _ := path.Match(t.value, node.Name)
+		return ok
+	case "id":
+		return t.value == strconv.FormatUint(node.ID, 10)
+	case "address":
+		_, network, err := net.ParseCIDR(t.value)
+		for _, addr := range node.Addresses {
+			ip := net.ParseIP(addr)
+			if ip == nil {
+				continue
+			}
+			if err == nil && network.Contains(ip) {
+				return true
+			}
+			if err != nil && ip.Equal(net.ParseIP(t.value)) {
+				return true
+			}
+		}
+		return false
+	default:
+		return false
+	}
+}
+
+// assignRoles splits the nodes into control planes and workers.
+//
+// Without a control plane selector the first node is the only control
+// plane, as in a single-node control plane setup. Without a worker selector
+// every node that is not a control plane is a worker. A node matched by both
+// selectors is a control plane. Selectors that match no node are an error,
+// so a typo does not silently deploy a different cluster.
+func assignRoles(nodes []Node, controlPlaneSelector, workerSelector Selector) (controlPlanes, workers []Node, err error) {
+	if len(nodes) == 0 {
+		return nil, nil, fmt.Errorf("at least 1 node required, found 0")
+	}
+
+	if controlPlaneSelector.IsZero() {
+		controlPlanes = nodes[:1]
+	} else {
+		for _, node := range nodes {
+			if controlPlaneSelector.Matches(node) {
+				controlPlanes = append(controlPlanes, node)
+			}
+		}
+		if len(controlPlanes) == 0 {
+			return nil, nil, fmt.Errorf("control plane selector %q matches none of the %d online nodes (%s)",
+				controlPlaneSelector, len(nodes), nodeNames(nodes))
+		}
+	}
+
+	for _, node := range nodes {
+		if slices.ContainsFunc(controlPlanes, func(cp Node) bool { return cp.ID == node.ID }) {
+			continue
+		}
+		if workerSelector.Matches(node) {
+			workers = append(workers, node)
+		}
+	}
+	if !workerSelector.IsZero() && len(workers) == 0 {
+		return nil, nil, fmt.Errorf("worker selector %q matches none of the %d online nodes that are not control planes (%s)",
+			workerSelector, len(nodes)-len(controlPlanes), nodeNames(nodes))
+	}
+
+	return controlPlanes, workers, nil
+}
+
+// nodeNames lists node names for error messages.
+func nodeNames(nodes []Node) string {
+	names := make([]string, len(nodes))
+	for i, node := range nodes {
+		names[i] = node.Name
+	}
+	return strings.Join(names, ", ")
+}
@@ -0,0 +1,123 @@
+package deployer
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestParseSelector(t *testing.T) {
+	tests := []struct {
+		in      string
+		wantErr bool
+	}{
+		{in: ""},
+		{in: "name=cp-*"},
+		{in: "name=cp-*, address=100.64.0.0/10"},
+		{in: "id=3"},
+		{in: "address=100.64.0.1"},
+		{in: "tag=worker", wantErr: true},
+		{in: "name", wantErr: true},
+		{in: "name=", wantErr: true},
+		{in: "name=[", wantErr: true},
+		{in: "address=not-an-ip", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.in, func(t *testing.T) {
+			_, err := ParseSelector(tt.in)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ParseSelector(%q) error = %v, wantErr %v", tt.in, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAssignRoles(t *testing.T) {
+	nodes := []Node{
+		{ID: 1, Name: "cp-1", Addresses: []string{"100.64.0.1"}},
+		{ID: 2, Name: "cp-2", Addresses: []string{"100.64.0.2"}},
+		{ID: 3, Name: "worker-1", Addresses: []string{"100.64.1.1"}},
+		{ID: 4, Name: "worker-2", Addresses: []string{"100.64.1.2", "fd7a::4"}},
+	}
+
+	tests := []struct {
+		name              string
+		controlPlane      string
+		worker            string
+		wantControlPlanes []string
+		wantWorkers       []string
+		wantErr           bool
+	}{
+		{
+			name:              "defaults",
+			wantControlPlanes: []string{"cp-1"},
+			wantWorkers:       []string{"cp-2", "worker-1", "worker-2"},
+		},
+		{
+			name:              "stacked control planes",
+			controlPlane:      "name=cp-*",
+			wantControlPlanes: []string{"cp-1", "cp-2"},
+			wantWorkers:       []string{"worker-1", "worker-2"},
+		},
+		{
+			name:              "worker subset by CIDR",
+			controlPlane:      "id=1",
+			worker:            "address=100.64.1.0/24,name=*-2",
+			wantControlPlanes: []string{"cp-1"},
+			wantWorkers:       []string{"worker-2"},
+		},
+		{
+			name:              "control plane wins over worker",
+			controlPlane:      "name=cp-1",
+			worker:            "name=*",
+			wantControlPlanes: []string{"cp-1"},
+			wantWorkers:       []string{"cp-2", "worker-1", "worker-2"},
+		},
+		{
+			name:         "control plane selector matches nothing",
+			controlPlane: "name=master-*",
+			wantErr:      true,
+		},
+		{
+			name:         "worker selector only matches control planes",
+			controlPlane: "name=cp-*",
+			worker:       "name=cp-*",
+			wantErr:      true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cpSel, err := ParseSelector(tt.controlPlane)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) error = %v", tt.controlPlane, err)
+			}
+			workerSel, err := ParseSelector(tt.worker)
+			if err != nil {
+				t.Fatalf("ParseSelector(%q) error = %v", tt.worker, err)
+			}
+
+			controlPlanes, workers, err := assignRoles(nodes, cpSel, workerSel)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("assignRoles() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if got := names(controlPlanes); !slices.Equal(got, tt.wantControlPlanes) {
+				t.Errorf("control planes = %v, want %v", got, tt.wantControlPlanes)
+			}
+			if got := names(workers); !slices.Equal(got, tt.wantWorkers) {
+				t.Errorf("workers = %v, want %v", got, tt.wantWorkers)
+			}
+		})
+	}
+}
+
+func names(nodes []Node) []string {
+	var out []string
+	for _, node := range nodes {
+		out = append(out, node.Name)
+	}
+	return out
+}
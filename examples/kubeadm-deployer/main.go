@@ -10,6 +10,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/strrl/wonder-mesh-net/examples/kubeadm-deployer/deployer"
+	"github.com/strrl/wonder-mesh-net/pkg/wondersdk"
 )
 
 var (
@@ -17,6 +18,14 @@ var (
 	adminToken     string
 	wonderNetID    string
 	verbose        bool
+
+	controlPlaneSelector string
+	workerSelector       string
+
+	socks5Addr    string
+	socks5APIKey  string
+	sshCertAPIKey string
+	ipFamily      string
 )
 
 func main() {
@@ -31,32 +40,69 @@ distributed compute.
 The deployer will:
 1. Discover online nodes via Wonder Mesh Net API
 2. Install containerd and kubeadm on all nodes
-3. Initialize the control plane on the first node
+3. Initialize the control plane on the first node, or on the first node
+   matching --control-plane-selector
 4. Install Flannel CNI (v0.26.7)
-5. Join remaining nodes as workers
+5. Join further control plane nodes with stacked etcd
+6. Join remaining nodes, or those matching --worker-selector, as workers
+
+If a control plane node already runs a cluster, steps 3 and 4 are skipped
+and only nodes not yet in the cluster are joined, as with "scale".
+
+Selectors are comma-separated key=value terms that must all match:
+name=<glob>, id=<node id>, or address=<ip or CIDR>. A selector that
+matches no online node stops the deployment.
 
 Prerequisites:
 - Wonder Mesh Net coordinator running with admin API enabled and workers joined
 - Admin API auth token and wonder net ID
-- Tailscale SOCKS5 proxy running (userspace networking)`,
-		RunE: runDeploy,
+- Tailscale SOCKS5 proxy running (userspace networking), or the
+  coordinator's mesh proxy with --socks5-addr and --socks5-api-key`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeploy((*deployer.Deployer).Run)
+		},
 	}
 
-	rootCmd.Flags().StringVar(&coordinatorURL, "coordinator-url", "", "Wonder Mesh Net coordinator URL (required)")
-	rootCmd.Flags().StringVar(&adminToken, "admin-token", "", "Admin API auth token (required)")
-	rootCmd.Flags().StringVar(&wonderNetID, "wonder-net-id", "", "Wonder net ID to deploy into (required)")
-	rootCmd.Flags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	scaleCmd := &cobra.Command{
+		Use:   "scale",
+		Short: "Join new mesh nodes to an existing cluster",
+		Long: `Join the selected online nodes that are not yet part of the cluster, for
+example workers that joined the mesh after the first deployment. The
+cluster is found by /etc/kubernetes/admin.conf on a control plane node;
+kubeadm init is never run. Nodes already in the cluster are left alone.
+
+Running kubeadm-deployer without scale does the same when it finds an
+existing cluster, and initializes a new one otherwise.`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			return runDeploy((*deployer.Deployer).Scale)
+		},
+	}
+	rootCmd.AddCommand(scaleCmd)
+
+	rootCmd.PersistentFlags().StringVar(&coordinatorURL, "coordinator-url", "", "Wonder Mesh Net coordinator URL (required)")
+	rootCmd.PersistentFlags().StringVar(&adminToken, "admin-token", "", "Admin API auth token (required)")
+	rootCmd.PersistentFlags().StringVar(&wonderNetID, "wonder-net-id", "", "Wonder net ID to deploy into (required)")
+	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Enable verbose logging")
+	rootCmd.PersistentFlags().StringVar(&controlPlaneSelector, "control-plane-selector", "", "Nodes to make control planes, e.g. name=cp-* (default: the first node)")
+	rootCmd.PersistentFlags().StringVar(&workerSelector, "worker-selector", "", "Nodes to make workers, e.g. address=100.64.0.0/24 (default: all other nodes)")
+
+	rootCmd.PersistentFlags().StringVar(&socks5Addr, "socks5-addr", "localhost:1080", "SOCKS5 proxy used to reach the nodes over the mesh")
+	rootCmd.PersistentFlags().StringVar(&socks5APIKey, "socks5-api-key", "", "Wonder net API key for the coordinator's mesh proxy, sent as the SOCKS5 password")
+	rootCmd.PersistentFlags().StringVar(&sshCertAPIKey, "ssh-cert-api-key", "", "Wonder net API key with nodes:write to log in with a short-lived certificate of the wonder net's SSH CA instead of a password")
+	rootCmd.PersistentFlags().StringVar(&ipFamily, "ip-family", "auto", "Mesh addresses to reach the nodes on: auto (IPv4 if available), ipv4, or ipv6")
 
-	rootCmd.MarkFlagRequired("coordinator-url")
-	rootCmd.MarkFlagRequired("admin-token")
-	rootCmd.MarkFlagRequired("wonder-net-id")
+	rootCmd.MarkPersistentFlagRequired("coordinator-url")
+	rootCmd.MarkPersistentFlagRequired("admin-token")
+	rootCmd.MarkPersistentFlagRequired("wonder-net-id")
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
 
-func runDeploy(cmd *cobra.Command, args []string) error {
+// runDeploy sets up logging and a deployer from the flags, then runs deploy
+// with it until done or interrupted.
+func runDeploy(deploy func(*deployer.Deployer, context.Context) error) error {
 	logLevel := slog.LevelInfo
 	if verbose {
 		logLevel = slog.LevelDebug
@@ -76,16 +122,35 @@ func runDeploy(cmd *cobra.Command, args []string) error {
 		cancel()
 	}()
 
+	cpSelector, err := deployer.ParseSelector(controlPlaneSelector)
+	if err != nil {
+		return fmt.Errorf("--control-plane-selector: %w", err)
+	}
+	wSelector, err := deployer.ParseSelector(workerSelector)
+	if err != nil {
+		return fmt.Errorf("--worker-selector: %w", err)
+	}
+	family, err := wondersdk.ParseIPFamily(ipFamily)
+	if err != nil {
+		return fmt.Errorf("--ip-family: %w", err)
+	}
+
 	d, err := deployer.NewDeployer(deployer.Config{
-		CoordinatorURL: coordinatorURL,
-		AdminToken:     adminToken,
-		WonderNetID:    wonderNetID,
+		CoordinatorURL:       coordinatorURL,
+		AdminToken:           adminToken,
+		WonderNetID:          wonderNetID,
+		SOCKS5Addr:           socks5Addr,
+		SOCKS5APIKey:         socks5APIKey,
+		SSHCertAPIKey:        sshCertAPIKey,
+		ControlPlaneSelector: cpSelector,
+		WorkerSelector:       wSelector,
+		IPFamily:             family,
 	})
 	if err != nil {
 		return fmt.Errorf("create deployer: %w", err)
 	}
 
-	if err := d.Run(ctx); err != nil {
+	if err := deploy(d, ctx); err != nil {
 		return fmt.Errorf("deployment: %w", err)
 	}
 
@@ -77,7 +77,7 @@ log_info "Wonder net created: $WONDER_NET_ID"
 log_info "Creating join token via Admin API..."
 JOIN_TOKEN_RESPONSE=$(docker exec kubeadm-deployer curl -s -X POST \
     -H "Authorization: Bearer $ADMIN_TOKEN" \
-    "http://nginx/coordinator/admin/api/v1/wonder-nets/$WONDER_NET_ID/join-token")
+    "http://nginx/coordinator/admin/api/v1/wonder-nets/$WONDER_NET_ID/join-token?max_uses=3")
 
 JOIN_TOKEN=$(echo "$JOIN_TOKEN_RESPONSE" | jq -r '.token // empty')
 if [ -z "$JOIN_TOKEN" ]; then
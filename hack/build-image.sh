@@ -30,6 +30,7 @@ if [ -z "${IMAGE_TAG:-}" ]; then
 fi
 
 IMAGE_NAME="${IMAGE_NAME:-ghcr.io/strrl/wonder-mesh-net}"
+DOCKERFILE="${DOCKERFILE:-Dockerfile}"
 PLATFORM="${PLATFORM:-}"
 
 echo "Version: ${VERSION}"
@@ -39,7 +40,7 @@ echo "Git SHA: ${GIT_SHA}"
 docker buildx inspect multiarch >/dev/null 2>&1 || \
     docker buildx create --use --name multiarch --driver docker-container
 
-BUILD_ARGS="--build-arg VERSION=${VERSION} --build-arg GIT_SHA=${GIT_SHA}"
+BUILD_ARGS="-f ${DOCKERFILE} --build-arg VERSION=${VERSION} --build-arg GIT_SHA=${GIT_SHA}"
 
 if [ -n "${PLATFORM}" ]; then
     echo "Building ${IMAGE_NAME}:${IMAGE_TAG} for ${PLATFORM}..."
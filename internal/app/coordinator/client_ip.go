@@ -0,0 +1,63 @@
+package coordinator
+
+import (
+	"context"
+	"net/http"
+	"net/netip"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/controller"
+)
+
+// withClientIP adds the address of the client to the request context, for
+// the allowlists of API keys and join tokens.
+func (s *Server) withClientIP(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ip := clientIP(r, s.trustedProxies)
+		ctx := context.WithValue(r.Context(), controller.ContextKeyClientIP, ip)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// clientIP returns the address of the client that sent r. It is the peer
+// address unless that is a trusted proxy; then X-Forwarded-For is read from
+// the right, skipping the addresses of trusted proxies, so that a client
+// cannot choose its address by sending the header itself. It returns the
+// zero Addr if the address cannot be determined.
+func clientIP(r *http.Request, trustedProxies []netip.Prefix) netip.Addr {
+	peer, err := netip.ParseAddrPort(r.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}
+	}
+	ip := peer.Addr().Unmap()
+	if !isTrustedProxy(ip, trustedProxies) {
+		return ip
+	}
+
+	hops := r.Header.Values("X-Forwarded-For")
+	for i := len(hops) - 1; i >= 0; i-- {
+		entries := strings.Split(hops[i], ",")
+		for j := len(entries) - 1; j >= 0; j-- {
+			hop, err := netip.ParseAddr(strings.TrimSpace(entries[j]))
+			if err != nil {
+				return netip.Addr{}
+			}
+			hop = hop.Unmap()
+			if !isTrustedProxy(hop, trustedProxies) {
+				return hop
+			}
+			ip = hop
+		}
+	}
+	// Every hop is a trusted proxy: the request came from inside.
+	return ip
+}
+
+func isTrustedProxy(ip netip.Addr, trustedProxies []netip.Prefix) bool {
+	for _, prefix := range trustedProxies {
+		if prefix.Contains(ip) {
+			return true
+		}
+	}
+	return false
+}
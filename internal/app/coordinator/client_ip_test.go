@@ -0,0 +1,41 @@
+package coordinator
+
+import (
+	"net/http/httptest"
+	"net/netip"
+	"testing"
+)
+
+func TestClientIP(t *testing.T) {
+	trusted := []netip.Prefix{netip.MustParsePrefix("10.0.0.0/8")}
+	tests := []struct {
+		name         string
+		remoteAddr   string
+		forwardedFor []string
+		want         string
+	}{
+		{name: "direct", remoteAddr: "203.0.113.7:4321", want: "203.0.113.7"},
+		{name: "untrusted peer ignores header", remoteAddr: "203.0.113.7:4321", forwardedFor: []string{"198.51.100.1"}, want: "203.0.113.7"},
+		{name: "trusted proxy", remoteAddr: "10.0.0.2:4321", forwardedFor: []string{"198.51.100.1"}, want: "198.51.100.1"},
+		{name: "spoofed leftmost entry", remoteAddr: "10.0.0.2:4321", forwardedFor: []string{"1.2.3.4, 198.51.100.1"}, want: "198.51.100.1"},
+		{name: "proxy chain", remoteAddr: "10.0.0.2:4321", forwardedFor: []string{"198.51.100.1", "10.0.0.3"}, want: "198.51.100.1"},
+		{name: "all trusted", remoteAddr: "10.0.0.2:4321", forwardedFor: []string{"10.0.0.3"}, want: "10.0.0.3"},
+		{name: "trusted proxy without header", remoteAddr: "10.0.0.2:4321", want: "10.0.0.2"},
+		{name: "ipv4-mapped peer", remoteAddr: "[::ffff:203.0.113.7]:4321", want: "203.0.113.7"},
+		{name: "malformed header", remoteAddr: "10.0.0.2:4321", forwardedFor: []string{"not-an-ip"}, want: "invalid IP"},
+		{name: "malformed peer", remoteAddr: "pipe", want: "invalid IP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest("GET", "/", nil)
+			r.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.forwardedFor {
+				r.Header.Add("X-Forwarded-For", v)
+			}
+			if got := clientIP(r, trusted).String(); got != tt.want {
+				t.Errorf("clientIP() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
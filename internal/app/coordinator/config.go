@@ -1,5 +1,11 @@
 package coordinator
 
+import (
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
 // Config holds configuration for the coordinator server.
 type Config struct {
 	// Listen is the address the coordinator HTTP server binds to (e.g., ":9080").
@@ -13,12 +19,48 @@ type Config struct {
 	DatabaseDriver string `mapstructure:"database_driver"`
 	// DatabaseDSN is the database connection string.
 	DatabaseDSN string `mapstructure:"database_dsn"`
+	// DatabaseMaxOpenConns and DatabaseMaxIdleConns size the Postgres
+	// connection pool. Zero keeps the defaults of 25 and 5; SQLite always
+	// uses a single connection.
+	DatabaseMaxOpenConns int `mapstructure:"database_max_open_conns"`
+	DatabaseMaxIdleConns int `mapstructure:"database_max_idle_conns"`
+	// DatabaseConnMaxLifetime and DatabaseConnMaxIdleTime close pooled
+	// connections after this age or idle time. Zero keeps the driver default.
+	DatabaseConnMaxLifetime time.Duration `mapstructure:"database_conn_max_lifetime"`
+	DatabaseConnMaxIdleTime time.Duration `mapstructure:"database_conn_max_idle_time"`
+
+	// MeshBackend selects the control plane nodes join: MeshBackendHeadscale,
+	// the default, or MeshBackendTailscale, an existing tailnet on the
+	// Tailscale control plane managed through the Tailscale API, where the
+	// Headscale settings are unused.
+	MeshBackend string `mapstructure:"mesh_backend"`
+	// TailscaleAPIKey is an API access token of an admin of the tailnet
+	// TailscaleTailnet, or "-" for the tailnet of the token. TailscaleAPIURL
+	// overrides the Tailscale API URL.
+	TailscaleAPIKey  string `mapstructure:"tailscale_api_key"`
+	TailscaleTailnet string `mapstructure:"tailscale_tailnet"`
+	TailscaleAPIURL  string `mapstructure:"tailscale_api_url"`
 
 	// HeadscaleURL is the HTTP URL of the Headscale server (e.g., "http://headscale:8080").
 	HeadscaleURL string `mapstructure:"headscale_url"`
 	// HeadscaleUnixSocket is the path to Headscale Unix socket (e.g., "/var/run/headscale/headscale.sock").
 	HeadscaleUnixSocket string `mapstructure:"headscale_unix_socket"`
 
+	// AuthMode selects how users log in: AuthModeKeycloak, the default, or
+	// AuthModeEmbedded, where the coordinator is its own identity provider
+	// and the Keycloak settings are unused.
+	AuthMode string `mapstructure:"auth_mode"`
+	// GitHubClientID and GitHubClientSecret, of a GitHub OAuth app, and
+	// GoogleClientID and GoogleClientSecret, of a Google OAuth client, let
+	// users of the embedded identity provider log in with GitHub or Google.
+	// AuthAllowedEmails lists the email addresses, or domains written as
+	// "@example.com", of the users who may.
+	GitHubClientID     string   `mapstructure:"github_client_id"`
+	GitHubClientSecret string   `mapstructure:"github_client_secret"`
+	GoogleClientID     string   `mapstructure:"google_client_id"`
+	GoogleClientSecret string   `mapstructure:"google_client_secret"`
+	AuthAllowedEmails  []string `mapstructure:"auth_allowed_emails"`
+
 	// KeycloakURL is the base URL of the Keycloak server (e.g., "https://auth.example.com").
 	KeycloakURL string `mapstructure:"keycloak_url"`
 	// KeycloakRealm is the Keycloak realm for user authentication (e.g., "wonder-mesh").
@@ -27,6 +69,15 @@ type Config struct {
 	KeycloakClientID string `mapstructure:"keycloak_client_id"`
 	// KeycloakClientSecret is the OIDC client secret for the coordinator (used for token exchange).
 	KeycloakClientSecret string `mapstructure:"keycloak_client_secret"`
+	// KeycloakAudiences are accepted as token audiences in addition to
+	// KeycloakClientID, e.g. the client IDs of CLIs that call the API.
+	KeycloakAudiences []string `mapstructure:"keycloak_audiences"`
+	// KeycloakTrustedIssuers are token issuers accepted in addition to the
+	// configured realm, such as the realm's external URL when the
+	// coordinator reaches Keycloak internally, or another realm. Each entry is
+	// an issuer URL, whose keys are fetched from its standard Keycloak JWKS
+	// path, or "issuer=jwks_url". Their tokens must have the same audiences.
+	KeycloakTrustedIssuers []string `mapstructure:"keycloak_trusted_issuers"`
 
 	// EnableAdminAPI enables the admin API endpoints (disabled by default).
 	EnableAdminAPI bool `mapstructure:"enable_admin_api"`
@@ -34,6 +85,30 @@ type Config struct {
 	// Required if EnableAdminAPI is true. Must be at least 32 characters.
 	AdminAPIAuthToken string `mapstructure:"admin_api_auth_token"`
 
+	// TrustedProxies are the networks of the reverse proxies in front of the
+	// coordinator, such as the ingress. For requests from them, the client
+	// address is taken from X-Forwarded-For; otherwise it is the peer
+	// address. It is checked against the allowed ranges of API keys and
+	// join tokens.
+	TrustedProxies []string `mapstructure:"trusted_proxies"`
+
+	// CORSAllowedOrigins are the origins, as scheme://host[:port] or * for
+	// any, of browser dashboards allowed to call /coordinator/api/. Empty
+	// disables CORS. CORSAllowCredentials lets them send cookies, which *
+	// does not allow. CORSMaxAge is how long browsers may cache a
+	// preflight response.
+	CORSAllowedOrigins   []string      `mapstructure:"cors_allowed_origins"`
+	CORSAllowCredentials bool          `mapstructure:"cors_allow_credentials"`
+	CORSMaxAge           time.Duration `mapstructure:"cors_max_age"`
+
+	// RequestTimeout bounds the handling of each coordinator API, login, and
+	// OIDC request, and AdminRequestTimeout of each admin API request,
+	// including their Headscale, Keycloak, and database calls. Requests
+	// that run out of time fail with 504 Gateway Timeout. Zero disables the
+	// limit; node watches are never limited.
+	RequestTimeout      time.Duration `mapstructure:"request_timeout"`
+	AdminRequestTimeout time.Duration `mapstructure:"admin_request_timeout"`
+
 	// PrivilegedNetworks is the list of Headscale usernames that have access to all
 	// WonderNets (hub-spoke ACL model). When empty, pure isolation policy is used.
 	PrivilegedNetworks []string
@@ -50,11 +125,133 @@ type Config struct {
 	// tagged nodes are still applied, and the constant-size policy remains
 	// correct. Only relevant when UseTaggedACL is true.
 	StrictPrivilegedTags bool `mapstructure:"strict_privileged_tags"`
+
+	// MeshProxyListen enables the mesh proxy on this address, through which
+	// deployers reach the nodes of their wonder net over SOCKS5 or HTTP
+	// CONNECT, authenticated by API key. MeshProxyUpstream is how the
+	// coordinator reaches nodes: "direct" (the default) when its host is on
+	// the mesh, or socks5://host:port for the SOCKS5 proxy of a userspace
+	// tailscaled logged in to a privileged network.
+	MeshProxyListen   string `mapstructure:"mesh_proxy_listen"`
+	MeshProxyUpstream string `mapstructure:"mesh_proxy_upstream"`
+
+	// DNSRecordsPath enables wonder net DNS management. The coordinator
+	// writes node names and extra records to this file, which Headscale
+	// must read as dns.extra_records_path. DNSBaseDomain, when set, is the
+	// domain every wonder net domain must be a subdomain of.
+	DNSRecordsPath string `mapstructure:"dns_records_path"`
+	DNSBaseDomain  string `mapstructure:"dns_base_domain"`
+
+	// QuotaMaxNodes, QuotaMaxAPIKeys, and QuotaAuthKeysPerHour are the
+	// default quotas of every wonder net; the admin API overrides them per
+	// wonder net. Zero means unlimited. They are reloadable.
+	QuotaMaxNodes        int `mapstructure:"quota_max_nodes"`
+	QuotaMaxAPIKeys      int `mapstructure:"quota_max_api_keys"`
+	QuotaAuthKeysPerHour int `mapstructure:"quota_authkeys_per_hour"`
+
+	// ExecSessionRetentionDays is how long the exec sessions reported by
+	// clients that run commands on nodes are kept; zero keeps them until
+	// their wonder net is deleted. ExecSessionMaxOutputBytes caps the stdout
+	// and stderr kept per session.
+	ExecSessionRetentionDays  int `mapstructure:"exec_session_retention_days"`
+	ExecSessionMaxOutputBytes int `mapstructure:"exec_session_max_output_bytes"`
+
+	// SMTPAddr, as host:port, enables email notification channels, sent
+	// from SMTPFrom. SMTPUsername and SMTPPassword, if set, authenticate
+	// with PLAIN auth, which requires a TLS connection unless the server is
+	// on localhost.
+	SMTPAddr     string `mapstructure:"smtp_addr"`
+	SMTPUsername string `mapstructure:"smtp_username"`
+	SMTPPassword string `mapstructure:"smtp_password"`
+	SMTPFrom     string `mapstructure:"smtp_from"`
+
+	// NodeOfflineAfter is how long a node is offline before node.offline
+	// webhook and notification events fire for it, so that restarts and
+	// short outages do not send one. Zero sends them right away.
+	NodeOfflineAfter time.Duration `mapstructure:"node_offline_after"`
+
+	// WebhookAllowHTTP lets webhooks use plain http URLs. They must use
+	// https otherwise. Either way, deliveries only go to public addresses.
+	WebhookAllowHTTP bool `mapstructure:"webhook_allow_http"`
+
+	// LogLevel is the minimum level logged (debug, info, warn, or error).
+	// It is reloadable and can also be changed through the admin API.
+	LogLevel string `mapstructure:"log_level"`
+	// LogFormat is text or json.
+	LogFormat string `mapstructure:"log_format"`
+	// LogOutput is stderr, stdout, syslog, syslog://host:port,
+	// syslog+tcp://host:port, or a file path.
+	LogOutput string `mapstructure:"log_output"`
+	// LogMaxSizeMB, LogMaxBackups, and LogMaxAgeDays control rotation when
+	// LogOutput is a file.
+	LogMaxSizeMB  int `mapstructure:"log_max_size_mb"`
+	LogMaxBackups int `mapstructure:"log_max_backups"`
+	LogMaxAgeDays int `mapstructure:"log_max_age_days"`
+
+	// TLSAutocertCacheDir enables serving HTTPS on Listen with certificates
+	// obtained from Let's Encrypt for the public URL and the custom public
+	// URLs of wonder nets, cached in this directory. Empty serves plain
+	// HTTP, with TLS terminated by the ingress.
+	TLSAutocertCacheDir string `mapstructure:"tls_autocert_cache_dir"`
+	// TLSAutocertEmail is the ACME account contact for expiry notices.
+	TLSAutocertEmail string `mapstructure:"tls_autocert_email"`
+
+	// Fixtures populates the coordinator with FixtureWonderNets synthetic
+	// wonder nets of FixtureNodesPerWonderNet in-memory nodes each, for
+	// exercising the UI and admin API in development. Never enable it in
+	// production.
+	Fixtures                 bool `mapstructure:"fixtures"`
+	FixtureWonderNets        int  `mapstructure:"fixture_wonder_nets"`
+	FixtureNodesPerWonderNet int  `mapstructure:"fixture_nodes_per_wonder_net"`
+
+	// Strict refuses to start the coordinator while any production hardening
+	// check fails, such as a weak JWT secret or a plain HTTP public URL; see
+	// StrictViolations. It defaults to true in release builds.
+	Strict bool `mapstructure:"strict"`
 }
 
+// LoggingConfig returns the logging settings of the coordinator.
+func (c *Config) LoggingConfig() logging.Config {
+	return logging.Config{
+		Level:      c.LogLevel,
+		Format:     c.LogFormat,
+		Output:     c.LogOutput,
+		MaxSizeMB:  c.LogMaxSizeMB,
+		MaxBackups: c.LogMaxBackups,
+		MaxAgeDays: c.LogMaxAgeDays,
+	}
+}
+
+// Mesh backends.
+const (
+	// MeshBackendHeadscale runs the mesh on the embedded Headscale.
+	MeshBackendHeadscale = "headscale"
+	// MeshBackendTailscale runs the mesh on an existing tailnet of the
+	// Tailscale control plane. Owner-defined ACL rules and service grants
+	// are not available.
+	MeshBackendTailscale = "tailscale"
+)
+
+// Auth modes.
+const (
+	// AuthModeKeycloak logs users in with a Keycloak realm.
+	AuthModeKeycloak = "keycloak"
+	// AuthModeEmbedded logs users in with the embedded identity provider:
+	// local users with passwords, and optionally GitHub and Google.
+	AuthModeEmbedded = "embedded"
+)
+
 const (
 	DefaultCoordinatorDataDir  = "/data/coordinator"
 	DefaultDatabaseDSN         = "file:/data/coordinator/coordinator.db?_journal_mode=WAL&_busy_timeout=5000"
 	DefaultHeadscaleURL        = "http://127.0.0.1:8080"
 	DefaultHeadscaleUnixSocket = "/var/run/headscale/headscale.sock"
+
+	DefaultFixtureWonderNets        = 300
+	DefaultFixtureNodesPerWonderNet = 20
+
+	DefaultExecSessionRetentionDays  = 30
+	DefaultExecSessionMaxOutputBytes = 1 << 20
+
+	DefaultNodeOfflineAfter = time.Hour
 )
@@ -0,0 +1,202 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// AccessRequestController handles just-in-time access request endpoints.
+type AccessRequestController struct {
+	accessRequestService *service.AccessRequestService
+}
+
+// NewAccessRequestController creates a new AccessRequestController.
+func NewAccessRequestController(accessRequestService *service.AccessRequestService) *AccessRequestController {
+	return &AccessRequestController{
+		accessRequestService: accessRequestService,
+	}
+}
+
+// CreateAccessRequestRequest is the request body for requesting temporary
+// access to a service. Subject is "*", "tag:<name>", or "node:<id>";
+// Duration is a Go duration such as "4h".
+type CreateAccessRequestRequest struct {
+	ServiceID string `json:"service_id"`
+	Subject   string `json:"subject"`
+	Requester string `json:"requester"`
+	Reason    string `json:"reason,omitempty"`
+	Duration  string `json:"duration"`
+}
+
+// AccessRequestResponse represents an access request in JSON responses.
+type AccessRequestResponse struct {
+	ID          string     `json:"id"`
+	ServiceID   string     `json:"service_id"`
+	ServiceName string     `json:"service_name"`
+	Subject     string     `json:"subject"`
+	Requester   string     `json:"requester"`
+	Reason      string     `json:"reason,omitempty"`
+	Duration    string     `json:"duration"`
+	Status      string     `json:"status"`
+	DecidedBy   string     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// HandleCreate handles POST /api/v1/access-requests requests.
+func (c *AccessRequestController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateAccessRequestRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		http.Error(w, "invalid duration format", http.StatusBadRequest)
+		return
+	}
+
+	accessRequest, err := c.accessRequestService.RequestAccess(r.Context(), wonderNet, req.ServiceID, req.Subject, req.Requester, req.Reason, duration)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			http.Error(w, "service not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidAccessRequest):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			slog.Error("create access request", "error", err)
+			http.Error(w, "create access request", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(accessRequestResponse(accessRequest))
+}
+
+// HandleList handles GET /api/v1/access-requests requests.
+func (c *AccessRequestController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	requests, err := c.accessRequestService.ListRequests(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list access requests", "error", err)
+		http.Error(w, "list access requests", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]AccessRequestResponse, len(requests))
+	for i, accessRequest := range requests {
+		response[i] = accessRequestResponse(accessRequest)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleApprove handles POST /api/v1/access-requests/{id}/approve requests.
+func (c *AccessRequestController) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	c.handleDecision(w, r, "approve access request", c.accessRequestService.Approve)
+}
+
+// HandleDeny handles POST /api/v1/access-requests/{id}/deny requests.
+func (c *AccessRequestController) HandleDeny(w http.ResponseWriter, r *http.Request) {
+	c.handleDecision(w, r, "deny access request", c.accessRequestService.Deny)
+}
+
+// HandleRevoke handles POST /api/v1/access-requests/{id}/revoke requests.
+func (c *AccessRequestController) HandleRevoke(w http.ResponseWriter, r *http.Request) {
+	c.handleDecision(w, r, "revoke access request", func(ctx context.Context, wonderNet *repository.WonderNet, requestID, _ string) (*repository.AccessRequest, error) {
+		return c.accessRequestService.Revoke(ctx, wonderNet, requestID)
+	})
+}
+
+// handleDecision runs an owner decision on the access request in the path
+// and records the authenticated user as the decider.
+func (c *AccessRequestController) handleDecision(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	decide func(ctx context.Context, wonderNet *repository.WonderNet, requestID, decidedBy string) (*repository.AccessRequest, error),
+) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	requestID := r.PathValue("id")
+	if requestID == "" {
+		http.Error(w, "missing access request id", http.StatusBadRequest)
+		return
+	}
+
+	decidedBy := claims.Email
+	if decidedBy == "" {
+		decidedBy = claims.Subject
+	}
+
+	accessRequest, err := decide(r.Context(), wonderNet, requestID, decidedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAccessRequestNotFound):
+			http.Error(w, "access request not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrAccessRequestNotPending), errors.Is(err, service.ErrAccessRequestNotActive):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrServiceGrantExists):
+			http.Error(w, "subject already has access to the service", http.StatusConflict)
+		case errors.Is(err, service.ErrServiceNotFound):
+			http.Error(w, "service not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidService):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			slog.Error(action, "id", requestID, "error", err)
+			http.Error(w, action, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(accessRequestResponse(accessRequest))
+}
+
+func accessRequestResponse(req *repository.AccessRequest) AccessRequestResponse {
+	return AccessRequestResponse{
+		ID:          req.ID,
+		ServiceID:   req.ServiceID,
+		ServiceName: req.ServiceName,
+		Subject:     req.Subject,
+		Requester:   req.Requester,
+		Reason:      req.Reason,
+		Duration:    req.Duration.String(),
+		Status:      req.Status,
+		DecidedBy:   req.DecidedBy,
+		DecidedAt:   req.DecidedAt,
+		ExpiresAt:   req.ExpiresAt,
+		EndedAt:     req.EndedAt,
+		CreatedAt:   req.CreatedAt,
+	}
+}
@@ -0,0 +1,118 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+)
+
+// ACLController handles the per-wonder-net ACL rules endpoints.
+type ACLController struct {
+	wonderNetService *service.WonderNetService
+}
+
+// NewACLController creates a new ACLController.
+func NewACLController(wonderNetService *service.WonderNetService) *ACLController {
+	return &ACLController{wonderNetService: wonderNetService}
+}
+
+// ACLRulesRequest is the request body for replacing a wonder net's ACL rules.
+type ACLRulesRequest struct {
+	Rules []headscale.ACLRule `json:"rules"`
+}
+
+// ACLRulesResponse lists a wonder net's ACL rules. Tags maps each tag used
+// in the rules to the Headscale tag that nodes must advertise to match it.
+type ACLRulesResponse struct {
+	Rules []headscale.ACLRule `json:"rules"`
+	Tags  map[string]string   `json:"tags,omitempty"`
+}
+
+// HandleGet handles GET /api/v1/acl requests.
+func (c *ACLController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := c.wonderNetService.GetACLRules(wonderNet)
+	if err != nil {
+		slog.Error("get acl rules", "wonder_net_id", wonderNet.ID, "error", err)
+		http.Error(w, "get acl rules", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(aclRulesResponse(wonderNet, rules))
+}
+
+// HandlePut handles PUT /api/v1/acl requests. The submitted rules replace
+// the wonder net's current rules.
+func (c *ACLController) HandlePut(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req ACLRulesRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.wonderNetService.SetACLRules(r.Context(), wonderNet, req.Rules); err != nil {
+		if errors.Is(err, service.ErrInvalidACLRules) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedByMeshBackend) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		slog.Error("set acl rules", "wonder_net_id", wonderNet.ID, "error", err)
+		http.Error(w, "set acl rules", http.StatusInternalServerError)
+		return
+	}
+
+	rules := req.Rules
+	if rules == nil {
+		rules = []headscale.ACLRule{}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(aclRulesResponse(wonderNet, rules))
+}
+
+func aclRulesResponse(wonderNet *repository.WonderNet, rules []headscale.ACLRule) ACLRulesResponse {
+	response := ACLRulesResponse{Rules: rules}
+	addTag := func(selector string) {
+		name, ok := strings.CutPrefix(selector, "tag:")
+		if !ok {
+			return
+		}
+		if response.Tags == nil {
+			response.Tags = make(map[string]string)
+		}
+		response.Tags[selector] = headscale.WonderNetTag(wonderNet.HeadscaleUser, name)
+	}
+
+	for _, rule := range rules {
+		for _, src := range rule.Sources {
+			addTag(src)
+		}
+		for _, dst := range rule.Destinations {
+			if i := strings.LastIndex(dst, ":"); i > 0 {
+				addTag(dst[:i])
+			}
+		}
+	}
+	return response
+}
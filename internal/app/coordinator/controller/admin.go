@@ -1,50 +1,114 @@
 package controller
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"sort"
+	"strconv"
 	"time"
 
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
 )
 
 // WonderNetResponse represents a wonder net in JSON responses.
 type WonderNetResponse struct {
-	ID          string `json:"id"`
-	OwnerID     string `json:"owner_id"`
-	DisplayName string `json:"display_name"`
-	MeshType    string `json:"mesh_type"`
-	CreatedAt   string `json:"created_at"`
+	ID               string `json:"id"`
+	OwnerID          string `json:"owner_id"`
+	DisplayName      string `json:"display_name"`
+	MeshType         string `json:"mesh_type"`
+	NodeNameTemplate string `json:"node_name_template,omitempty"`
+	PublicURL        string `json:"public_url,omitempty"`
+	CreatedAt        string `json:"created_at"`
 }
 
 // WonderNetListResponse represents the response for listing wonder nets.
+// NextCursor is set on paginated listings when more results are available.
 type WonderNetListResponse struct {
 	WonderNets []WonderNetResponse `json:"wonder_nets"`
 	Count      int                 `json:"count"`
+	NextCursor string              `json:"next_cursor,omitempty"`
 }
 
+const (
+	defaultWonderNetPageSize = 100
+	maxWonderNetPageSize     = 1000
+)
+
 // AdminNodeResponse extends NodeResponse with wonder net info.
 type AdminNodeResponse struct {
 	NodeResponse
 	WonderNetID string `json:"wonder_net_id"`
+	MeshType    string `json:"mesh_type"`
 }
 
 // AdminNodeListResponse represents the response for listing nodes across all wonder nets.
+// Count and NextCursor are those of NodeListResponse, and ByMeshType counts
+// the matching nodes per mesh type.
 type AdminNodeListResponse struct {
-	Nodes  []AdminNodeResponse `json:"nodes"`
-	Count  int                 `json:"count"`
-	Errors []string            `json:"errors,omitempty"`
+	Nodes      []AdminNodeResponse `json:"nodes"`
+	Count      int                 `json:"count"`
+	NextCursor string              `json:"next_cursor,omitempty"`
+	ByMeshType map[string]int      `json:"by_mesh_type"`
+	Errors     []string            `json:"errors,omitempty"`
+}
+
+// MeshTypeSummaryResponse rolls up the wonder nets and nodes of one mesh type.
+type MeshTypeSummaryResponse struct {
+	MeshType    string `json:"mesh_type"`
+	WonderNets  int    `json:"wonder_nets"`
+	Nodes       int    `json:"nodes"`
+	OnlineNodes int    `json:"online_nodes"`
+}
+
+// QuotaRequest is the request body for overriding the quotas of a wonder
+// net. A zero limit means unlimited.
+type QuotaRequest struct {
+	MaxNodes        int `json:"max_nodes"`
+	MaxAPIKeys      int `json:"max_api_keys"`
+	AuthKeysPerHour int `json:"authkeys_per_hour"`
+}
+
+// QuotaResponse represents the quotas in effect for a wonder net.
+// Overridden is false while the wonder net uses the coordinator defaults.
+type QuotaResponse struct {
+	WonderNetID     string     `json:"wonder_net_id"`
+	MaxNodes        int        `json:"max_nodes"`
+	MaxAPIKeys      int        `json:"max_api_keys"`
+	AuthKeysPerHour int        `json:"authkeys_per_hour"`
+	Overridden      bool       `json:"overridden"`
+	UpdatedAt       *time.Time `json:"updated_at,omitempty"`
+}
+
+// StaleNodesResponse lists the nodes a node expiry after Days days would
+// remove from a wonder net.
+type StaleNodesResponse struct {
+	WonderNetID string         `json:"wonder_net_id"`
+	Days        int            `json:"days"`
+	Nodes       []NodeResponse `json:"nodes"`
+	Count       int            `json:"count"`
+}
+
+// MeshTypeSummaryListResponse represents the response for the per-mesh-type rollup.
+// Errors lists wonder nets whose nodes could not be counted.
+type MeshTypeSummaryListResponse struct {
+	MeshTypes []MeshTypeSummaryResponse `json:"mesh_types"`
+	Errors    []string                  `json:"errors,omitempty"`
 }
 
 // AdminController handles admin API endpoints.
 type AdminController struct {
-	wonderNetService *service.WonderNetService
-	nodesService     *service.NodesService
-	workerService    *service.WorkerService
-	apiKeyService    *service.APIKeyService
-	meshBackend      meshbackend.MeshBackend
+	wonderNetService  *service.WonderNetService
+	nodesService      *service.NodesService
+	workerService     *service.WorkerService
+	apiKeyService     *service.APIKeyService
+	quotaService      *service.QuotaService
+	nodeExpiryService *service.NodeExpiryService
+	meshBackend       meshbackend.MeshBackend
 }
 
 // NewAdminController creates a new AdminController.
@@ -53,34 +117,94 @@ func NewAdminController(
 	nodesService *service.NodesService,
 	workerService *service.WorkerService,
 	apiKeyService *service.APIKeyService,
+	quotaService *service.QuotaService,
+	nodeExpiryService *service.NodeExpiryService,
 	meshBackend meshbackend.MeshBackend,
 ) *AdminController {
 	return &AdminController{
-		wonderNetService: wonderNetService,
-		nodesService:     nodesService,
-		workerService:    workerService,
-		apiKeyService:    apiKeyService,
-		meshBackend:      meshBackend,
+		wonderNetService:  wonderNetService,
+		nodesService:      nodesService,
+		workerService:     workerService,
+		apiKeyService:     apiKeyService,
+		quotaService:      quotaService,
+		nodeExpiryService: nodeExpiryService,
+		meshBackend:       meshBackend,
 	}
 }
 
 // HandleListWonderNets handles GET /admin/api/v1/wonder-nets requests.
+//
+// Results are paginated by cursor. Supported query parameters:
+//   - limit: page size, 1-1000 (default 100)
+//   - cursor: next_cursor from the previous page
+//   - owner_id, mesh_type: exact-match filters
+//   - created_after, created_before: RFC 3339 creation time range
+//   - sort: created_at or -created_at (default -created_at, newest first)
 func (c *AdminController) HandleListWonderNets(w http.ResponseWriter, r *http.Request) {
-	wonderNets, err := c.wonderNetService.ListAllWonderNets(r.Context())
+	query := r.URL.Query()
+
+	opts := repository.WonderNetListOptions{
+		OwnerID:  query.Get("owner_id"),
+		MeshType: query.Get("mesh_type"),
+		Limit:    defaultWonderNetPageSize,
+	}
+
+	if v := query.Get("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit < 1 || limit > maxWonderNetPageSize {
+			http.Error(w, "limit must be between 1 and 1000", http.StatusBadRequest)
+			return
+		}
+		opts.Limit = limit
+	}
+
+	if v := query.Get("created_after"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid created_after format", http.StatusBadRequest)
+			return
+		}
+		opts.CreatedAfter = t
+	}
+	if v := query.Get("created_before"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			http.Error(w, "invalid created_before format", http.StatusBadRequest)
+			return
+		}
+		opts.CreatedBefore = t
+	}
+
+	switch query.Get("sort") {
+	case "", "-created_at":
+	case "created_at":
+		opts.Ascending = true
+	default:
+		http.Error(w, "sort must be created_at or -created_at", http.StatusBadRequest)
+		return
+	}
+
+	page, err := c.wonderNetService.ListWonderNetsPage(r.Context(), opts, query.Get("cursor"))
 	if err != nil {
-		slog.Error("list all wonder nets", "error", err)
+		if errors.Is(err, service.ErrInvalidCursor) {
+			http.Error(w, "invalid cursor", http.StatusBadRequest)
+			return
+		}
+		slog.Error("list wonder nets page", "error", err)
 		http.Error(w, "list wonder nets", http.StatusInternalServerError)
 		return
 	}
 
-	result := make([]WonderNetResponse, len(wonderNets))
-	for i, wn := range wonderNets {
+	result := make([]WonderNetResponse, len(page.WonderNets))
+	for i, wn := range page.WonderNets {
 		result[i] = WonderNetResponse{
-			ID:          wn.ID,
-			OwnerID:     wn.OwnerID,
-			DisplayName: wn.DisplayName,
-			MeshType:    wn.MeshType,
-			CreatedAt:   wn.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			ID:               wn.ID,
+			OwnerID:          wn.OwnerID,
+			DisplayName:      wn.DisplayName,
+			MeshType:         wn.MeshType,
+			NodeNameTemplate: wn.NodeNameTemplate,
+			PublicURL:        wn.PublicURL,
+			CreatedAt:        wn.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		}
 	}
 
@@ -88,6 +212,7 @@ func (c *AdminController) HandleListWonderNets(w http.ResponseWriter, r *http.Re
 	_ = json.NewEncoder(w).Encode(WonderNetListResponse{
 		WonderNets: result,
 		Count:      len(result),
+		NextCursor: page.NextCursor,
 	})
 }
 
@@ -109,11 +234,13 @@ func (c *AdminController) HandleListWonderNetsByUser(w http.ResponseWriter, r *h
 	result := make([]WonderNetResponse, len(wonderNets))
 	for i, wn := range wonderNets {
 		result[i] = WonderNetResponse{
-			ID:          wn.ID,
-			OwnerID:     wn.OwnerID,
-			DisplayName: wn.DisplayName,
-			MeshType:    wn.MeshType,
-			CreatedAt:   wn.CreatedAt.Format("2006-01-02T15:04:05Z"),
+			ID:               wn.ID,
+			OwnerID:          wn.OwnerID,
+			DisplayName:      wn.DisplayName,
+			MeshType:         wn.MeshType,
+			NodeNameTemplate: wn.NodeNameTemplate,
+			PublicURL:        wn.PublicURL,
+			CreatedAt:        wn.CreatedAt.Format("2006-01-02T15:04:05Z"),
 		}
 	}
 
@@ -125,6 +252,7 @@ func (c *AdminController) HandleListWonderNetsByUser(w http.ResponseWriter, r *h
 }
 
 // HandleListWonderNetNodes handles GET /admin/api/v1/wonder-nets/{id}/nodes requests.
+// It accepts the same filters, sort, and pages as the user nodes endpoint.
 func (c *AdminController) HandleListWonderNetNodes(w http.ResponseWriter, r *http.Request) {
 	wonderNetID := r.PathValue("id")
 	if wonderNetID == "" {
@@ -132,6 +260,17 @@ func (c *AdminController) HandleListWonderNetNodes(w http.ResponseWriter, r *htt
 		return
 	}
 
+	filter, err := parseNodeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pageOpts, err := parseNodePage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	wonderNet, err := c.wonderNetService.GetWonderNetByID(r.Context(), wonderNetID)
 	if err != nil {
 		slog.Error("get wonder net", "error", err, "id", wonderNetID)
@@ -149,68 +288,156 @@ func (c *AdminController) HandleListWonderNetNodes(w http.ResponseWriter, r *htt
 		http.Error(w, "internal error", http.StatusInternalServerError)
 		return
 	}
+	page, err := service.PageNodes(service.FilterNodes(nodes, filter), pageOpts)
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
 
-	result := make([]NodeResponse, len(nodes))
-	for i, node := range nodes {
-		result[i] = NodeResponse{
-			ID:      node.ID,
-			Name:    node.Name,
-			IPAddrs: node.IPAddrs,
-			Online:  node.Online,
-		}
-		if node.LastSeen != nil {
-			result[i].LastSeen = node.LastSeen.Format("2006-01-02T15:04:05Z")
+	response := nodeListResponse(page.Nodes)
+	response.NextCursor = page.NextCursor
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleListAllNodes handles GET /admin/api/v1/nodes requests.
+// The optional mesh_type query parameter limits the listing to wonder nets
+// of that mesh type. It accepts the same filters, sort, and pages as the
+// user nodes endpoint; ByMeshType counts the matching nodes of all pages.
+func (c *AdminController) HandleListAllNodes(w http.ResponseWriter, r *http.Request) {
+	filter, err := parseNodeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	pageOpts, err := parseNodePage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	nodes, wonderNets, errors, err := c.listAllNodes(r.Context(), r.URL.Query().Get("mesh_type"))
+	if err != nil {
+		slog.Error("list wonder nets page", "error", err)
+		http.Error(w, "list wonder nets", http.StatusInternalServerError)
+		return
+	}
+
+	nodes = service.FilterNodes(nodes, filter)
+	byMeshType := make(map[string]int)
+	for _, node := range nodes {
+		byMeshType[wonderNets[node].MeshType]++
+	}
+
+	page, err := service.PageNodes(nodes, pageOpts)
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
+	result := make([]AdminNodeResponse, len(page.Nodes))
+	for i, node := range page.Nodes {
+		result[i] = AdminNodeResponse{
+			NodeResponse: nodeResponse(node),
+			WonderNetID:  wonderNets[node].ID,
+			MeshType:     wonderNets[node].MeshType,
 		}
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(NodeListResponse{
-		Nodes: result,
-		Count: len(result),
+	_ = json.NewEncoder(w).Encode(AdminNodeListResponse{
+		Nodes:      result,
+		Count:      len(result),
+		NextCursor: page.NextCursor,
+		ByMeshType: byMeshType,
+		Errors:     errors,
 	})
 }
 
-// HandleListAllNodes handles GET /admin/api/v1/nodes requests.
-func (c *AdminController) HandleListAllNodes(w http.ResponseWriter, r *http.Request) {
-	wonderNets, err := c.wonderNetService.ListAllWonderNets(r.Context())
+// HandleMeshTypeSummary handles GET /admin/api/v1/mesh-types requests.
+// It counts wonder nets, nodes, and online nodes per mesh type.
+func (c *AdminController) HandleMeshTypeSummary(w http.ResponseWriter, r *http.Request) {
+	wonderNets, err := c.wonderNetService.CountWonderNetsByMeshType(r.Context())
+	if err != nil {
+		slog.Error("count wonder nets by mesh type", "error", err)
+		http.Error(w, "count wonder nets", http.StatusInternalServerError)
+		return
+	}
+
+	nodes, wonderNetOf, errors, err := c.listAllNodes(r.Context(), "")
 	if err != nil {
-		slog.Error("list all wonder nets", "error", err)
+		slog.Error("list wonder nets page", "error", err)
 		http.Error(w, "list wonder nets", http.StatusInternalServerError)
 		return
 	}
 
-	var result []AdminNodeResponse
+	summaries := make(map[string]*MeshTypeSummaryResponse)
+	summary := func(meshType string) *MeshTypeSummaryResponse {
+		if summaries[meshType] == nil {
+			summaries[meshType] = &MeshTypeSummaryResponse{MeshType: meshType}
+		}
+		return summaries[meshType]
+	}
+	for meshType, count := range wonderNets {
+		summary(meshType).WonderNets = count
+	}
+	for _, node := range nodes {
+		s := summary(wonderNetOf[node].MeshType)
+		s.Nodes++
+		if node.Online {
+			s.OnlineNodes++
+		}
+	}
+
+	response := MeshTypeSummaryListResponse{
+		MeshTypes: make([]MeshTypeSummaryResponse, 0, len(summaries)),
+		Errors:    errors,
+	}
+	for _, s := range summaries {
+		response.MeshTypes = append(response.MeshTypes, *s)
+	}
+	sort.Slice(response.MeshTypes, func(i, j int) bool {
+		return response.MeshTypes[i].MeshType < response.MeshTypes[j].MeshType
+	})
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// listAllNodes lists the nodes of every wonder net, or of the wonder nets of
+// one mesh type if meshType is set, with the wonder net of each node. Wonder
+// nets whose nodes cannot be listed are skipped and reported in the returned
+// error strings.
+func (c *AdminController) listAllNodes(ctx context.Context, meshType string) ([]*service.Node, map[*service.Node]*repository.WonderNet, []string, error) {
+	var result []*service.Node
+	wonderNetOf := make(map[*service.Node]*repository.WonderNet)
 	var errors []string
-	for _, wn := range wonderNets {
-		nodes, err := c.nodesService.ListNodes(r.Context(), wn)
+	opts := repository.WonderNetListOptions{Limit: maxWonderNetPageSize, MeshType: meshType}
+	cursor := ""
+	for {
+		page, err := c.wonderNetService.ListWonderNetsPage(ctx, opts, cursor)
 		if err != nil {
-			slog.Warn("list nodes for wonder net", "error", err, "wonder_net_id", wn.ID)
-			errors = append(errors, "wonder_net "+wn.ID+": "+err.Error())
-			continue
+			return nil, nil, nil, err
 		}
-		for _, node := range nodes {
-			resp := AdminNodeResponse{
-				NodeResponse: NodeResponse{
-					ID:      node.ID,
-					Name:    node.Name,
-					IPAddrs: node.IPAddrs,
-					Online:  node.Online,
-				},
-				WonderNetID: wn.ID,
+
+		for _, wn := range page.WonderNets {
+			nodes, err := c.nodesService.ListNodes(ctx, wn)
+			if err != nil {
+				slog.Warn("list nodes for wonder net", "error", err, "wonder_net_id", wn.ID)
+				errors = append(errors, "wonder_net "+wn.ID+": "+err.Error())
+				continue
 			}
-			if node.LastSeen != nil {
-				resp.LastSeen = node.LastSeen.Format("2006-01-02T15:04:05Z")
+			for _, node := range nodes {
+				result = append(result, node)
+				wonderNetOf[node] = wn
 			}
-			result = append(result, resp)
 		}
-	}
 
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(AdminNodeListResponse{
-		Nodes:  result,
-		Count:  len(result),
-		Errors: errors,
-	})
+		if page.NextCursor == "" {
+			return result, wonderNetOf, errors, nil
+		}
+		cursor = page.NextCursor
+	}
 }
 
 // AdminCreateWonderNetRequest represents the request to create a wonder net.
@@ -255,7 +482,78 @@ func (c *AdminController) HandleAdminCreateWonderNet(w http.ResponseWriter, r *h
 	})
 }
 
-// HandleAdminCreateJoinToken handles POST /admin/api/v1/wonder-nets/{id}/join-token requests.
+// HandleAdminDeleteWonderNet handles DELETE /admin/api/v1/wonder-nets/{id}
+// requests, deleting the wonder net as its owner would. An owner's default
+// wonder net cannot be deleted while it is their default.
+func (c *AdminController) HandleAdminDeleteWonderNet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := c.adminWonderNet(w, r)
+	if wonderNet == nil {
+		return
+	}
+
+	if err := c.wonderNetService.DeleteWonderNet(r.Context(), wonderNet.OwnerID, wonderNet.ID); err != nil {
+		if errors.Is(err, service.ErrNoWonderNet) {
+			http.Error(w, "wonder net not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrDeleteDefaultWonderNet) {
+			http.Error(w, "cannot delete the default wonder net", http.StatusConflict)
+			return
+		}
+		slog.Error("delete wonder net", "error", err, "id", wonderNet.ID)
+		http.Error(w, "delete wonder net", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// AdminSetPublicURLRequest sets the custom public URL of a wonder net.
+type AdminSetPublicURLRequest struct {
+	PublicURL string `json:"public_url"`
+}
+
+// HandleSetPublicURL handles PUT /admin/api/v1/wonder-nets/{id}/public-url
+// requests. An empty public_url removes the custom domain.
+func (c *AdminController) HandleSetPublicURL(w http.ResponseWriter, r *http.Request) {
+	var req AdminSetPublicURLRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	wonderNet, err := c.wonderNetService.SetPublicURL(r.Context(), r.PathValue("id"), req.PublicURL)
+	switch {
+	case errors.Is(err, service.ErrInvalidPublicURL):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	case errors.Is(err, service.ErrPublicURLInUse):
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	case errors.Is(err, service.ErrNoWonderNet):
+		http.Error(w, "wonder net not found", http.StatusNotFound)
+		return
+	case err != nil:
+		slog.Error("set wonder net public URL", "error", err)
+		http.Error(w, "set public URL", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(WonderNetResponse{
+		ID:               wonderNet.ID,
+		OwnerID:          wonderNet.OwnerID,
+		DisplayName:      wonderNet.DisplayName,
+		MeshType:         wonderNet.MeshType,
+		NodeNameTemplate: wonderNet.NodeNameTemplate,
+		PublicURL:        wonderNet.PublicURL,
+		CreatedAt:        wonderNet.CreatedAt.Format("2006-01-02T15:04:05Z"),
+	})
+}
+
+// HandleAdminCreateJoinToken handles POST /admin/api/v1/wonder-nets/{id}/join-token
+// requests, taking the same ttl, max_uses, and allowed_cidr parameters as
+// HandleCreateJoinToken.
 func (c *AdminController) HandleAdminCreateJoinToken(w http.ResponseWriter, r *http.Request) {
 	wonderNetID := r.PathValue("id")
 	if wonderNetID == "" {
@@ -274,18 +572,7 @@ func (c *AdminController) HandleAdminCreateJoinToken(w http.ResponseWriter, r *h
 		return
 	}
 
-	token, err := c.workerService.GenerateJoinToken(r.Context(), wonderNet, 8*time.Hour)
-	if err != nil {
-		slog.Error("generate join token", "error", err)
-		http.Error(w, "generate join token", http.StatusInternalServerError)
-		return
-	}
-
-	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(JoinTokenResponse{
-		Token:     token,
-		ExpiresIn: 28800,
-	})
+	writeJoinToken(w, r, c.workerService, wonderNet, "admin")
 }
 
 // HandleAdminCreateAPIKey handles POST /admin/api/v1/wonder-nets/{id}/api-keys requests.
@@ -333,8 +620,16 @@ func (c *AdminController) HandleAdminCreateAPIKey(w http.ResponseWriter, r *http
 		expiresAt = &t
 	}
 
-	details, err := c.apiKeyService.CreateAPIKey(r.Context(), wonderNet.ID, req.Name, expiresAt)
+	details, err := c.apiKeyService.CreateAPIKey(r.Context(), wonderNet.ID, req.Name, expiresAt, req.Scopes, req.AllowedCIDRs)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyScope) || errors.Is(err, service.ErrInvalidAllowedCIDR) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		slog.Error("create api key", "error", err)
 		http.Error(w, "create api key", http.StatusInternalServerError)
 		return
@@ -343,11 +638,13 @@ func (c *AdminController) HandleAdminCreateAPIKey(w http.ResponseWriter, r *http
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(CreateAPIKeyResponse{
-		ID:        details.ID,
-		Name:      details.Name,
-		Key:       details.Key,
-		KeyPrefix: details.KeyPrefix,
-		ExpiresAt: details.ExpiresAt,
+		ID:           details.ID,
+		Name:         details.Name,
+		Key:          details.Key,
+		KeyPrefix:    details.KeyPrefix,
+		ExpiresAt:    details.ExpiresAt,
+		Scopes:       details.Scopes,
+		AllowedCIDRs: details.AllowedCIDRs,
 	})
 }
 
@@ -370,10 +667,21 @@ func (c *AdminController) HandleAdminDeployerJoin(w http.ResponseWriter, r *http
 		return
 	}
 
+	if err := c.quotaService.ReserveAuthKey(r.Context(), wonderNet); err != nil {
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		slog.Error("check quotas", "error", err)
+		http.Error(w, "check quotas", http.StatusInternalServerError)
+		return
+	}
+
 	metadata, err := c.meshBackend.CreateJoinCredentials(r.Context(), wonderNet.HeadscaleUser, meshbackend.JoinOptions{
-		TTL:       100 * 365 * 24 * time.Hour,
-		Reusable:  true,
-		Ephemeral: false,
+		TTL:        100 * 365 * 24 * time.Hour,
+		Reusable:   true,
+		Ephemeral:  false,
+		ControlURL: wonderNet.PublicURL,
 	})
 	if err != nil {
 		slog.Error("create join credentials", "error", err)
@@ -449,23 +757,17 @@ func (c *AdminController) HandleGetNode(w http.ResponseWriter, r *http.Request)
 
 	node, err := c.nodesService.GetNode(r.Context(), wonderNet.HeadscaleUser, nodeID)
 	if err != nil {
+		if errors.Is(err, service.ErrNodeNotFound) {
+			http.Error(w, "node not found", http.StatusNotFound)
+			return
+		}
 		slog.Error("get node", "error", err, "wonder_net_id", wonderNetID, "node_id", nodeID)
 		http.Error(w, "get node", http.StatusInternalServerError)
 		return
 	}
 
-	resp := NodeResponse{
-		ID:      node.ID,
-		Name:    node.Name,
-		IPAddrs: node.IPAddrs,
-		Online:  node.Online,
-	}
-	if node.LastSeen != nil {
-		resp.LastSeen = node.LastSeen.Format("2006-01-02T15:04:05Z")
-	}
-
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(resp)
+	_ = json.NewEncoder(w).Encode(nodeDetailResponse(node))
 }
 
 // HandleDeleteNode handles DELETE /admin/api/v1/wonder-nets/{id}/nodes/{node_id} requests.
@@ -493,7 +795,7 @@ func (c *AdminController) HandleDeleteNode(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	err = c.nodesService.DeleteNode(r.Context(), wonderNet.HeadscaleUser, nodeID)
+	err = c.nodesService.DeleteNode(r.Context(), wonderNet, nodeID)
 	if err != nil {
 		slog.Error("delete node", "error", err, "wonder_net_id", wonderNetID, "node_id", nodeID)
 		http.Error(w, "delete node", http.StatusInternalServerError)
@@ -502,3 +804,153 @@ func (c *AdminController) HandleDeleteNode(w http.ResponseWriter, r *http.Reques
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// HandleListStaleNodes handles GET /admin/api/v1/wonder-nets/{id}/stale-nodes
+// requests, a dry run of the node expiry: it lists the nodes that have been
+// offline for longer than the days query parameter, or the wonder net's own
+// node expiry if it is not given, without removing them.
+func (c *AdminController) HandleListStaleNodes(w http.ResponseWriter, r *http.Request) {
+	wonderNet := c.adminWonderNet(w, r)
+	if wonderNet == nil {
+		return
+	}
+
+	days := wonderNet.NodeExpiryDays
+	if v := r.URL.Query().Get("days"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid days", http.StatusBadRequest)
+			return
+		}
+		days = n
+	}
+	if days == 0 {
+		http.Error(w, "days is required for wonder nets without a node expiry", http.StatusBadRequest)
+		return
+	}
+
+	nodes, err := c.nodeExpiryService.StaleNodes(r.Context(), wonderNet, days)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidNodeExpiry) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("list stale nodes", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "list stale nodes", http.StatusInternalServerError)
+		return
+	}
+
+	resp := StaleNodesResponse{
+		WonderNetID: wonderNet.ID,
+		Days:        days,
+		Nodes:       make([]NodeResponse, 0, len(nodes)),
+		Count:       len(nodes),
+	}
+	for _, node := range nodes {
+		resp.Nodes = append(resp.Nodes, nodeResponse(node))
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// HandleGetQuotas handles GET /admin/api/v1/wonder-nets/{id}/quotas requests.
+func (c *AdminController) HandleGetQuotas(w http.ResponseWriter, r *http.Request) {
+	wonderNet := c.adminWonderNet(w, r)
+	if wonderNet == nil {
+		return
+	}
+
+	quotas, err := c.quotaService.GetQuotas(r.Context(), wonderNet.ID)
+	if err != nil {
+		slog.Error("get quotas", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "get quotas", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(quotaResponse(wonderNet.ID, quotas))
+}
+
+// HandleSetQuotas handles PUT /admin/api/v1/wonder-nets/{id}/quotas requests,
+// replacing the coordinator defaults for the wonder net.
+func (c *AdminController) HandleSetQuotas(w http.ResponseWriter, r *http.Request) {
+	wonderNet := c.adminWonderNet(w, r)
+	if wonderNet == nil {
+		return
+	}
+
+	var req QuotaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	quotas, err := c.quotaService.SetQuotas(r.Context(), wonderNet.ID, service.Quotas{
+		MaxNodes:        req.MaxNodes,
+		MaxAPIKeys:      req.MaxAPIKeys,
+		AuthKeysPerHour: req.AuthKeysPerHour,
+	})
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuota) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("set quotas", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "set quotas", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(quotaResponse(wonderNet.ID, quotas))
+}
+
+// HandleResetQuotas handles DELETE /admin/api/v1/wonder-nets/{id}/quotas
+// requests, returning the wonder net to the coordinator defaults.
+func (c *AdminController) HandleResetQuotas(w http.ResponseWriter, r *http.Request) {
+	wonderNet := c.adminWonderNet(w, r)
+	if wonderNet == nil {
+		return
+	}
+
+	if err := c.quotaService.ResetQuotas(r.Context(), wonderNet.ID); err != nil {
+		slog.Error("reset quotas", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "reset quotas", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// adminWonderNet returns the wonder net named by the id path value, or
+// writes an error response and returns nil.
+func (c *AdminController) adminWonderNet(w http.ResponseWriter, r *http.Request) *repository.WonderNet {
+	wonderNetID := r.PathValue("id")
+	if wonderNetID == "" {
+		http.Error(w, "wonder net id required", http.StatusBadRequest)
+		return nil
+	}
+
+	wonderNet, err := c.wonderNetService.GetWonderNetByID(r.Context(), wonderNetID)
+	if err != nil {
+		slog.Error("get wonder net", "error", err, "id", wonderNetID)
+		http.Error(w, "get wonder net", http.StatusInternalServerError)
+		return nil
+	}
+	if wonderNet == nil {
+		http.Error(w, "wonder net not found", http.StatusNotFound)
+		return nil
+	}
+	return wonderNet
+}
+
+func quotaResponse(wonderNetID string, quotas *service.WonderNetQuotas) QuotaResponse {
+	return QuotaResponse{
+		WonderNetID:     wonderNetID,
+		MaxNodes:        quotas.MaxNodes,
+		MaxAPIKeys:      quotas.MaxAPIKeys,
+		AuthKeysPerHour: quotas.AuthKeysPerHour,
+		Overridden:      quotas.Overridden,
+		UpdatedAt:       quotas.UpdatedAt,
+	}
+}
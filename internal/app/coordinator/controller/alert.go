@@ -0,0 +1,300 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// AlertController handles alert rule, alert state, and silence endpoints.
+type AlertController struct {
+	alertService *service.AlertService
+}
+
+// NewAlertController creates a new AlertController.
+func NewAlertController(alertService *service.AlertService) *AlertController {
+	return &AlertController{
+		alertService: alertService,
+	}
+}
+
+// CreateAlertRuleRequest is the request body for creating an alert rule.
+type CreateAlertRuleRequest struct {
+	Name      string `json:"name"`
+	Kind      string `json:"kind"`
+	Threshold string `json:"threshold"`
+}
+
+// AlertRuleResponse represents an alert rule in JSON responses.
+type AlertRuleResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Kind      string    `json:"kind"`
+	Threshold string    `json:"threshold"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// AlertResponse represents a firing alert in JSON responses.
+type AlertResponse struct {
+	RuleID   string    `json:"rule_id"`
+	RuleName string    `json:"rule_name"`
+	Kind     string    `json:"kind"`
+	NodeID   uint64    `json:"node_id"`
+	NodeName string    `json:"node_name"`
+	StartsAt time.Time `json:"starts_at"`
+	Silenced bool      `json:"silenced"`
+}
+
+// CreateAlertSilenceRequest is the request body for creating an alert silence.
+type CreateAlertSilenceRequest struct {
+	RuleID   string `json:"rule_id,omitempty"`
+	NodeName string `json:"node_name,omitempty"`
+	Reason   string `json:"reason,omitempty"`
+	Duration string `json:"duration"`
+}
+
+// AlertSilenceResponse represents an alert silence in JSON responses.
+type AlertSilenceResponse struct {
+	ID        string    `json:"id"`
+	RuleID    string    `json:"rule_id,omitempty"`
+	NodeName  string    `json:"node_name,omitempty"`
+	Reason    string    `json:"reason,omitempty"`
+	EndsAt    time.Time `json:"ends_at"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandleCreateRule handles POST /api/v1/alert-rules requests.
+func (c *AlertController) HandleCreateRule(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateAlertRuleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	threshold, err := time.ParseDuration(req.Threshold)
+	if err != nil {
+		http.Error(w, "invalid threshold format", http.StatusBadRequest)
+		return
+	}
+	if threshold < time.Minute {
+		http.Error(w, "threshold must be at least 1m", http.StatusBadRequest)
+		return
+	}
+
+	rule, err := c.alertService.CreateRule(r.Context(), wonderNet.ID, req.Name, req.Kind, threshold)
+	if err != nil {
+		if errors.Is(err, service.ErrUnsupportedAlertKind) {
+			http.Error(w, "unsupported alert kind", http.StatusBadRequest)
+			return
+		}
+		slog.Error("create alert rule", "error", err)
+		http.Error(w, "create alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(AlertRuleResponse{
+		ID:        rule.ID,
+		Name:      rule.Name,
+		Kind:      rule.Kind,
+		Threshold: rule.Threshold.String(),
+		CreatedAt: rule.CreatedAt,
+	})
+}
+
+// HandleListRules handles GET /api/v1/alert-rules requests.
+func (c *AlertController) HandleListRules(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	rules, err := c.alertService.ListRules(r.Context(), wonderNet.ID)
+	if err != nil {
+		slog.Error("list alert rules", "error", err)
+		http.Error(w, "list alert rules", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]AlertRuleResponse, len(rules))
+	for i, rule := range rules {
+		response[i] = AlertRuleResponse{
+			ID:        rule.ID,
+			Name:      rule.Name,
+			Kind:      rule.Kind,
+			Threshold: rule.Threshold.String(),
+			CreatedAt: rule.CreatedAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteRule handles DELETE /api/v1/alert-rules/{id} requests.
+func (c *AlertController) HandleDeleteRule(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	ruleID := r.PathValue("id")
+	if ruleID == "" {
+		http.Error(w, "missing rule id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.alertService.DeleteRule(r.Context(), wonderNet.ID, ruleID); err != nil {
+		if errors.Is(err, service.ErrAlertRuleNotFound) {
+			http.Error(w, "alert rule not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete alert rule", "error", err)
+		http.Error(w, "delete alert rule", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListAlerts handles GET /api/v1/alerts requests.
+func (c *AlertController) HandleListAlerts(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	alerts := c.alertService.ListAlerts(wonderNet.ID)
+	response := make([]AlertResponse, len(alerts))
+	for i, alert := range alerts {
+		response[i] = AlertResponse{
+			RuleID:   alert.RuleID,
+			RuleName: alert.RuleName,
+			Kind:     alert.Kind,
+			NodeID:   alert.NodeID,
+			NodeName: alert.NodeName,
+			StartsAt: alert.StartsAt,
+			Silenced: alert.Silenced,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleCreateSilence handles POST /api/v1/alert-silences requests.
+func (c *AlertController) HandleCreateSilence(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateAlertSilenceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	duration, err := time.ParseDuration(req.Duration)
+	if err != nil {
+		http.Error(w, "invalid duration format", http.StatusBadRequest)
+		return
+	}
+	if duration <= 0 {
+		http.Error(w, "duration must be a positive duration", http.StatusBadRequest)
+		return
+	}
+
+	silence, err := c.alertService.CreateSilence(r.Context(), wonderNet.ID, req.RuleID, req.NodeName, req.Reason, time.Now().Add(duration))
+	if err != nil {
+		if errors.Is(err, service.ErrAlertRuleNotFound) {
+			http.Error(w, "alert rule not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("create alert silence", "error", err)
+		http.Error(w, "create alert silence", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(alertSilenceResponse(silence))
+}
+
+// HandleListSilences handles GET /api/v1/alert-silences requests.
+func (c *AlertController) HandleListSilences(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	silences, err := c.alertService.ListSilences(r.Context(), wonderNet.ID)
+	if err != nil {
+		slog.Error("list alert silences", "error", err)
+		http.Error(w, "list alert silences", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]AlertSilenceResponse, len(silences))
+	for i, silence := range silences {
+		response[i] = alertSilenceResponse(silence)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDeleteSilence handles DELETE /api/v1/alert-silences/{id} requests.
+func (c *AlertController) HandleDeleteSilence(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	silenceID := r.PathValue("id")
+	if silenceID == "" {
+		http.Error(w, "missing silence id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.alertService.DeleteSilence(r.Context(), wonderNet.ID, silenceID); err != nil {
+		if errors.Is(err, service.ErrAlertSilenceNotFound) {
+			http.Error(w, "alert silence not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete alert silence", "error", err)
+		http.Error(w, "delete alert silence", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func alertSilenceResponse(silence *repository.AlertSilence) AlertSilenceResponse {
+	return AlertSilenceResponse{
+		ID:        silence.ID,
+		RuleID:    silence.RuleID,
+		NodeName:  silence.NodeName,
+		Reason:    silence.Reason,
+		EndsAt:    silence.EndsAt,
+		CreatedAt: silence.CreatedAt,
+	}
+}
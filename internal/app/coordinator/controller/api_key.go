@@ -2,6 +2,7 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
@@ -21,19 +22,27 @@ func NewAPIKeyController(apiKeyService *service.APIKeyService) *APIKeyController
 	}
 }
 
-// CreateAPIKeyRequest is the request body for creating an API key.
+// CreateAPIKeyRequest is the request body for creating an API key. Scopes
+// defaults to ["nodes:read"], a read-only key; ["admin"] grants every
+// scope. AllowedCIDRs, such as
+// ["203.0.113.0/24"], limits the client addresses the key may be used
+// from; by default any address may use it.
 type CreateAPIKeyRequest struct {
-	Name      string `json:"name"`
-	ExpiresIn string `json:"expires_in,omitempty"`
+	Name         string   `json:"name"`
+	ExpiresIn    string   `json:"expires_in,omitempty"`
+	Scopes       []string `json:"scopes,omitempty"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
 }
 
 // CreateAPIKeyResponse is the response body for creating an API key.
 type CreateAPIKeyResponse struct {
-	ID        string     `json:"id"`
-	Name      string     `json:"name"`
-	Key       string     `json:"key"`
-	KeyPrefix string     `json:"key_prefix"`
-	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	Key          string     `json:"key"`
+	KeyPrefix    string     `json:"key_prefix"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Scopes       []string   `json:"scopes"`
+	AllowedCIDRs []string   `json:"allowed_cidrs,omitempty"`
 }
 
 // HandleCreate handles POST /api/v1/api-keys requests.
@@ -70,8 +79,16 @@ func (c *APIKeyController) HandleCreate(w http.ResponseWriter, r *http.Request)
 		expiresAt = &t
 	}
 
-	details, err := c.apiKeyService.CreateAPIKey(r.Context(), wonderNet.ID, req.Name, expiresAt)
+	details, err := c.apiKeyService.CreateAPIKey(r.Context(), wonderNet.ID, req.Name, expiresAt, req.Scopes, req.AllowedCIDRs)
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidAPIKeyScope) || errors.Is(err, service.ErrInvalidAllowedCIDR) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
 		slog.Error("create api key", "error", err)
 		http.Error(w, "create api key", http.StatusInternalServerError)
 		return
@@ -80,25 +97,47 @@ func (c *APIKeyController) HandleCreate(w http.ResponseWriter, r *http.Request)
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	_ = json.NewEncoder(w).Encode(CreateAPIKeyResponse{
-		ID:        details.ID,
-		Name:      details.Name,
-		Key:       details.Key,
-		KeyPrefix: details.KeyPrefix,
-		ExpiresAt: details.ExpiresAt,
+		ID:           details.ID,
+		Name:         details.Name,
+		Key:          details.Key,
+		KeyPrefix:    details.KeyPrefix,
+		ExpiresAt:    details.ExpiresAt,
+		Scopes:       details.Scopes,
+		AllowedCIDRs: details.AllowedCIDRs,
 	})
 }
 
-// APIKeyInfoResponse is the response for listing API keys.
+// APIKeyInfoResponse is the response for listing API keys. Stale marks keys
+// unused for 90 days, which are candidates for revocation, and Expiring
+// keys that expire within 7 days and should be rotated. Rotated keys have
+// DeprecatedAt and the ID of the key that replaces them as SuccessorID.
 type APIKeyInfoResponse struct {
-	ID         string     `json:"id"`
-	Name       string     `json:"name"`
-	KeyPrefix  string     `json:"key_prefix"`
-	CreatedAt  time.Time  `json:"created_at"`
-	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
-	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	ID           string                        `json:"id"`
+	Name         string                        `json:"name"`
+	KeyPrefix    string                        `json:"key_prefix"`
+	CreatedAt    time.Time                     `json:"created_at"`
+	LastUsedAt   *time.Time                    `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time                    `json:"expires_at,omitempty"`
+	Scopes       []string                      `json:"scopes"`
+	AllowedCIDRs []string                      `json:"allowed_cidrs,omitempty"`
+	RequestCount int64                         `json:"request_count"`
+	Endpoints    []APIKeyEndpointUsageResponse `json:"endpoints,omitempty"`
+	Stale        bool                          `json:"stale"`
+	Expiring     bool                          `json:"expiring"`
+	DeprecatedAt *time.Time                    `json:"deprecated_at,omitempty"`
+	SuccessorID  string                        `json:"successor_id,omitempty"`
+}
+
+// APIKeyEndpointUsageResponse counts the requests an API key made to one endpoint.
+type APIKeyEndpointUsageResponse struct {
+	Endpoint     string    `json:"endpoint"`
+	RequestCount int64     `json:"request_count"`
+	LastUsedAt   time.Time `json:"last_used_at"`
 }
 
-// HandleList handles GET /api/v1/api-keys requests.
+// HandleList handles GET /api/v1/api-keys requests. The expiring_within
+// parameter, such as 168h, lists only the keys that expire within it and
+// have not been rotated.
 func (c *APIKeyController) HandleList(w http.ResponseWriter, r *http.Request) {
 	wonderNet := WonderNetFromContext(r)
 	if wonderNet == nil {
@@ -106,7 +145,17 @@ func (c *APIKeyController) HandleList(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	keys, err := c.apiKeyService.ListAPIKeys(r.Context(), wonderNet.ID)
+	var expiringWithin time.Duration
+	if v := r.URL.Query().Get("expiring_within"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "expiring_within must be a positive duration", http.StatusBadRequest)
+			return
+		}
+		expiringWithin = d
+	}
+
+	keys, err := c.apiKeyService.ListAPIKeys(r.Context(), wonderNet.ID, expiringWithin)
 	if err != nil {
 		slog.Error("list api keys", "error", err)
 		http.Error(w, "list api keys", http.StatusInternalServerError)
@@ -115,13 +164,29 @@ func (c *APIKeyController) HandleList(w http.ResponseWriter, r *http.Request) {
 
 	response := make([]APIKeyInfoResponse, len(keys))
 	for i, key := range keys {
+		endpoints := make([]APIKeyEndpointUsageResponse, len(key.Endpoints))
+		for j, e := range key.Endpoints {
+			endpoints[j] = APIKeyEndpointUsageResponse{
+				Endpoint:     e.Endpoint,
+				RequestCount: e.RequestCount,
+				LastUsedAt:   e.LastUsedAt,
+			}
+		}
 		response[i] = APIKeyInfoResponse{
-			ID:         key.ID,
-			Name:       key.Name,
-			KeyPrefix:  key.KeyPrefix,
-			CreatedAt:  key.CreatedAt,
-			LastUsedAt: key.LastUsedAt,
-			ExpiresAt:  key.ExpiresAt,
+			ID:           key.ID,
+			Name:         key.Name,
+			KeyPrefix:    key.KeyPrefix,
+			CreatedAt:    key.CreatedAt,
+			LastUsedAt:   key.LastUsedAt,
+			ExpiresAt:    key.ExpiresAt,
+			Scopes:       key.Scopes,
+			AllowedCIDRs: key.AllowedCIDRs,
+			RequestCount: key.RequestCount,
+			Endpoints:    endpoints,
+			Stale:        key.Stale,
+			Expiring:     key.Expiring,
+			DeprecatedAt: key.DeprecatedAt,
+			SuccessorID:  key.SuccessorID,
 		}
 	}
 
@@ -156,3 +221,82 @@ func (c *APIKeyController) HandleDelete(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(http.StatusNoContent)
 }
+
+// RotateAPIKeyRequest is the request body for rotating an API key. Overlap
+// is how long the rotated key keeps working, 24h by default and at most
+// 720h; 0s revokes it at once.
+type RotateAPIKeyRequest struct {
+	Overlap string `json:"overlap,omitempty"`
+}
+
+// RotateAPIKeyResponse is the response body for rotating an API key: the
+// successor, shown once like a created key, and when the rotated key stops
+// working.
+type RotateAPIKeyResponse struct {
+	CreateAPIKeyResponse
+	RotatedKeyID        string    `json:"rotated_key_id"`
+	RotatedKeyExpiresAt time.Time `json:"rotated_key_expires_at"`
+}
+
+// HandleRotate handles POST /api/v1/api-keys/{id}/rotate requests.
+func (c *APIKeyController) HandleRotate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	keyID := r.PathValue("id")
+	if keyID == "" {
+		http.Error(w, "missing key id", http.StatusBadRequest)
+		return
+	}
+
+	var req RotateAPIKeyRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+	overlap := service.DefaultAPIKeyRotationOverlap
+	if req.Overlap != "" {
+		d, err := time.ParseDuration(req.Overlap)
+		if err != nil || d < 0 || d > service.MaxAPIKeyRotationOverlap {
+			http.Error(w, "overlap must be a duration between 0s and 720h", http.StatusBadRequest)
+			return
+		}
+		overlap = d
+	}
+
+	rotation, err := c.apiKeyService.RotateAPIKey(r.Context(), wonderNet.ID, keyID, overlap)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrAPIKeyNotFound):
+			http.Error(w, "api key not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrAPIKeyRotated), errors.Is(err, service.ErrAPIKeyExpired):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("rotate api key", "error", err)
+			http.Error(w, "rotate api key", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	successor := rotation.Successor
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(RotateAPIKeyResponse{
+		CreateAPIKeyResponse: CreateAPIKeyResponse{
+			ID:           successor.ID,
+			Name:         successor.Name,
+			Key:          successor.Key,
+			KeyPrefix:    successor.KeyPrefix,
+			ExpiresAt:    successor.ExpiresAt,
+			Scopes:       successor.Scopes,
+			AllowedCIDRs: successor.AllowedCIDRs,
+		},
+		RotatedKeyID:        keyID,
+		RotatedKeyExpiresAt: rotation.RotatedKeyExpiresAt,
+	})
+}
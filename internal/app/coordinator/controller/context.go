@@ -2,6 +2,7 @@ package controller
 
 import (
 	"net/http"
+	"net/netip"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 )
@@ -11,7 +12,11 @@ type contextKey string
 
 // Context keys for request context values.
 const (
-	ContextKeyWonderNet contextKey = "wonder_net"
+	ContextKeyWonderNet     contextKey = "wonder_net"
+	ContextKeyWonderNetRole contextKey = "wonder_net_role"
+	ContextKeyHostWonderNet contextKey = "host_wonder_net"
+	ContextKeyAPIKey        contextKey = "api_key"
+	ContextKeyClientIP      contextKey = "client_ip"
 )
 
 // WonderNetFromContext retrieves the WonderNet from the request context.
@@ -22,3 +27,44 @@ func WonderNetFromContext(r *http.Request) *repository.WonderNet {
 	}
 	return nil
 }
+
+// WonderNetRoleFromContext retrieves the caller's role in the WonderNet from
+// the request context, or an empty string if it was not set.
+func WonderNetRoleFromContext(r *http.Request) string {
+	role, _ := r.Context().Value(ContextKeyWonderNetRole).(string)
+	return role
+}
+
+// APIKeyFromContext retrieves the API key that authenticated the request, or
+// nil for requests authenticated otherwise.
+func APIKeyFromContext(r *http.Request) *repository.APIKey {
+	if key, ok := r.Context().Value(ContextKeyAPIKey).(*repository.APIKey); ok {
+		return key
+	}
+	return nil
+}
+
+// ClientIPFromContext retrieves the address of the client that sent the
+// request, or the zero Addr if it is unknown.
+func ClientIPFromContext(r *http.Request) netip.Addr {
+	ip, _ := r.Context().Value(ContextKeyClientIP).(netip.Addr)
+	return ip
+}
+
+// HostWonderNetFromContext retrieves the WonderNet whose custom public URL
+// received the request, or nil for requests to the coordinator's own URL.
+func HostWonderNetFromContext(r *http.Request) *repository.WonderNet {
+	if wn, ok := r.Context().Value(ContextKeyHostWonderNet).(*repository.WonderNet); ok {
+		return wn
+	}
+	return nil
+}
+
+// hostWonderNetID returns the ID of the request's host wonder net, or an
+// empty string.
+func hostWonderNetID(r *http.Request) string {
+	if wn := HostWonderNetFromContext(r); wn != nil {
+		return wn.ID
+	}
+	return ""
+}
@@ -2,22 +2,28 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
 	"time"
 
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
 )
 
 // DeployerController handles third-party PaaS deployer integration.
 type DeployerController struct {
-	meshBackend meshbackend.MeshBackend
+	meshBackend  meshbackend.MeshBackend
+	quotaService *service.QuotaService
+	sshCAService *service.SSHCAService
 }
 
 // NewDeployerController creates a new DeployerController.
-func NewDeployerController(meshBackend meshbackend.MeshBackend) *DeployerController {
+func NewDeployerController(meshBackend meshbackend.MeshBackend, quotaService *service.QuotaService, sshCAService *service.SSHCAService) *DeployerController {
 	return &DeployerController{
-		meshBackend: meshBackend,
+		meshBackend:  meshBackend,
+		quotaService: quotaService,
+		sshCAService: sshCAService,
 	}
 }
 
@@ -31,10 +37,21 @@ func (c *DeployerController) HandleDeployerJoin(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	if err := c.quotaService.ReserveAuthKey(r.Context(), wonderNet); err != nil {
+		if errors.Is(err, service.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+			return
+		}
+		slog.Error("check quotas", "error", err)
+		http.Error(w, "check quotas", http.StatusInternalServerError)
+		return
+	}
+
 	metadata, err := c.meshBackend.CreateJoinCredentials(r.Context(), wonderNet.HeadscaleUser, meshbackend.JoinOptions{
-		TTL:       24 * time.Hour,
-		Reusable:  false,
-		Ephemeral: false,
+		TTL:        24 * time.Hour,
+		Reusable:   false,
+		Ephemeral:  false,
+		ControlURL: wonderNet.PublicURL,
 	})
 	if err != nil {
 		slog.Error("create join credentials", "error", err)
@@ -70,6 +87,13 @@ func (c *DeployerController) HandleDeployerJoin(w http.ResponseWriter, r *http.R
 		return
 	}
 
+	caKey, err := c.sshCAService.PublicKey(wonderNet.ID)
+	if err != nil {
+		slog.Error("get ssh ca key", "error", err)
+		http.Error(w, "get ssh ca key", http.StatusInternalServerError)
+		return
+	}
+
 	resp := JoinCredentialsResponse{
 		MeshType: meshType,
 		TailscaleConnectionInfo: &TailscaleConnectionInfo{
@@ -77,6 +101,7 @@ func (c *DeployerController) HandleDeployerJoin(w http.ResponseWriter, r *http.R
 			Authkey:       authkey,
 			HeadscaleUser: headscaleUser,
 		},
+		SSHCAPublicKey: caKey,
 	}
 
 	w.Header().Set("Content-Type", "application/json")
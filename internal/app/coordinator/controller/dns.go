@@ -0,0 +1,221 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// DNSController handles wonder net DNS endpoints.
+type DNSController struct {
+	dnsService *service.DNSService
+}
+
+// NewDNSController creates a new DNSController.
+func NewDNSController(dnsService *service.DNSService) *DNSController {
+	return &DNSController{
+		dnsService: dnsService,
+	}
+}
+
+// SetDNSDomainRequest is the request body for setting a wonder net's DNS domain.
+type SetDNSDomainRequest struct {
+	Domain string `json:"domain"`
+}
+
+// CreateDNSRecordRequest is the request body for creating a DNS record. Name
+// is relative to the wonder net's domain.
+type CreateDNSRecordRequest struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// DNSResponse is the DNS configuration of a wonder net.
+type DNSResponse struct {
+	Domain     string              `json:"domain,omitempty"`
+	BaseDomain string              `json:"base_domain,omitempty"`
+	Nodes      []DNSNameResponse   `json:"nodes"`
+	Records    []DNSRecordResponse `json:"records"`
+}
+
+// DNSNameResponse is the name of a node in JSON responses.
+type DNSNameResponse struct {
+	Node      string   `json:"node"`
+	FQDN      string   `json:"fqdn"`
+	Addresses []string `json:"ip_addresses"`
+}
+
+// DNSRecordResponse represents a DNS record in JSON responses.
+type DNSRecordResponse struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	FQDN      string    `json:"fqdn,omitempty"`
+	Type      string    `json:"type"`
+	Value     string    `json:"value"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandleGet handles GET /api/v1/dns requests.
+func (c *DNSController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+	c.writeDNS(w, r, wonderNet)
+}
+
+// HandleSetDomain handles POST /api/v1/dns requests.
+func (c *DNSController) HandleSetDomain(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req SetDNSDomainRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if _, err := c.dnsService.SetDomain(r.Context(), wonderNet.ID, req.Domain); err != nil {
+		switch {
+		case errors.Is(err, service.ErrDNSDisabled):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		case errors.Is(err, service.ErrInvalidDNSDomain):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrDNSDomainTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("set dns domain", "error", err)
+			http.Error(w, "set dns domain", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	c.writeDNS(w, r, wonderNet)
+}
+
+// HandleCreateRecord handles POST /api/v1/dns/records requests.
+func (c *DNSController) HandleCreateRecord(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateDNSRecordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createdBy := claims.Email
+	if createdBy == "" {
+		createdBy = claims.Subject
+	}
+
+	record, err := c.dnsService.CreateRecord(r.Context(), wonderNet.ID, req.Name, req.Type, req.Value, createdBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrDNSDisabled):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		case errors.Is(err, service.ErrInvalidDNSRecord):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrDNSDomainNotSet):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("create dns record", "error", err)
+			http.Error(w, "create dns record", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(dnsRecordResponse(record, ""))
+}
+
+// HandleDeleteRecord handles DELETE /api/v1/dns/records/{id} requests.
+func (c *DNSController) HandleDeleteRecord(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	recordID := r.PathValue("id")
+	if recordID == "" {
+		http.Error(w, "missing dns record id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.dnsService.DeleteRecord(r.Context(), wonderNet.ID, recordID); err != nil {
+		switch {
+		case errors.Is(err, service.ErrDNSDisabled):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		case errors.Is(err, service.ErrDNSRecordNotFound):
+			http.Error(w, "dns record not found", http.StatusNotFound)
+		default:
+			slog.Error("delete dns record", "error", err)
+			http.Error(w, "delete dns record", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func (c *DNSController) writeDNS(w http.ResponseWriter, r *http.Request, wonderNet *repository.WonderNet) {
+	dns, err := c.dnsService.GetDNS(r.Context(), wonderNet)
+	if err != nil {
+		if errors.Is(err, service.ErrDNSDisabled) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		slog.Error("get dns", "error", err)
+		http.Error(w, "get dns", http.StatusInternalServerError)
+		return
+	}
+
+	response := DNSResponse{
+		Domain:     dns.Domain,
+		BaseDomain: dns.BaseDomain,
+		Nodes:      make([]DNSNameResponse, len(dns.Nodes)),
+		Records:    make([]DNSRecordResponse, len(dns.Records)),
+	}
+	for i, name := range dns.Nodes {
+		response.Nodes[i] = DNSNameResponse{Node: name.Node, FQDN: name.FQDN, Addresses: name.Addresses}
+	}
+	for i, record := range dns.Records {
+		response.Records[i] = dnsRecordResponse(record, dns.Domain)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func dnsRecordResponse(record *repository.DNSRecord, domain string) DNSRecordResponse {
+	response := DNSRecordResponse{
+		ID:        record.ID,
+		Name:      record.Name,
+		Type:      record.Type,
+		Value:     record.Value,
+		CreatedBy: record.CreatedBy,
+		CreatedAt: record.CreatedAt,
+	}
+	if domain != "" {
+		response.FQDN = record.Name + "." + domain
+	}
+	return response
+}
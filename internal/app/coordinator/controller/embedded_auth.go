@@ -0,0 +1,396 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"html/template"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// EmbeddedAuthPath is the path of the embedded identity provider's
+// endpoints, which with the public URL is the issuer of its tokens.
+const EmbeddedAuthPath = "/coordinator/auth"
+
+// loginPage is the login form of the embedded identity provider.
+var loginPage = template.Must(template.New("login").Parse(`<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<meta name="viewport" content="width=device-width, initial-scale=1">
+<title>Log in - Wonder Mesh Net</title>
+<style>
+body { font-family: system-ui, sans-serif; max-width: 22rem; margin: 4rem auto; padding: 0 1rem; }
+label, input, button, a.provider { display: block; width: 100%; box-sizing: border-box; margin-top: .5rem; }
+input, button, a.provider { padding: .5rem; font-size: 1rem; }
+a.provider { text-align: center; border: 1px solid #888; color: inherit; text-decoration: none; }
+.error { color: #b00020; }
+</style>
+</head>
+<body>
+<h1>Wonder Mesh Net</h1>
+{{if .Error}}<p class="error">{{.Error}}</p>{{end}}
+<form method="post" action="/coordinator/auth/login">
+<input type="hidden" name="redirect_to" value="{{.RedirectTo}}">
+<label for="username">Username</label>
+<input id="username" name="username" autocomplete="username" required autofocus>
+<label for="password">Password</label>
+<input id="password" name="password" type="password" autocomplete="current-password" required>
+<button type="submit">Log in</button>
+</form>
+{{range .Providers}}<a class="provider" href="/coordinator/auth/{{.}}/login?redirect_to={{$.RedirectTo}}">Log in with {{.}}</a>
+{{end}}</body>
+</html>
+`))
+
+type loginPageData struct {
+	Error      string
+	RedirectTo string
+	Providers  []string
+}
+
+// EmbeddedAuthController handles the login, token, and key endpoints of the
+// embedded identity provider, and the admin endpoints managing its users.
+// Logins start web sessions of the OIDC service, so logout and session
+// authentication work as with Keycloak.
+type EmbeddedAuthController struct {
+	authService  *service.EmbeddedAuthService
+	oidcService  *service.OIDCService
+	publicURL    string
+	secureCookie bool
+}
+
+// NewEmbeddedAuthController creates a new EmbeddedAuthController.
+func NewEmbeddedAuthController(
+	authService *service.EmbeddedAuthService,
+	oidcService *service.OIDCService,
+	publicURL string,
+	secureCookie bool,
+) *EmbeddedAuthController {
+	return &EmbeddedAuthController{
+		authService:  authService,
+		oidcService:  oidcService,
+		publicURL:    publicURL,
+		secureCookie: secureCookie,
+	}
+}
+
+// HandleLoginPage serves the login form.
+// GET /coordinator/oidc/login
+func (c *EmbeddedAuthController) HandleLoginPage(w http.ResponseWriter, r *http.Request) {
+	c.renderLoginPage(w, http.StatusOK, "", r.URL.Query().Get("redirect_to"))
+}
+
+// HandlePasswordLogin logs a local user in with the login form.
+// POST /coordinator/auth/login
+func (c *EmbeddedAuthController) HandlePasswordLogin(w http.ResponseWriter, r *http.Request) {
+	redirectTo := r.PostFormValue("redirect_to")
+	tokenResp, claims, err := c.authService.PasswordLogin(r.Context(), r.PostFormValue("username"), r.PostFormValue("password"), ClientIPFromContext(r))
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		slog.Warn("embedded login throttled", "username", r.PostFormValue("username"), "client_ip", ClientIPFromContext(r))
+		setRetryAfter(w, throttled.RetryAfter)
+		c.renderLoginPage(w, http.StatusTooManyRequests, "Too many failed logins. Try again later.", redirectTo)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		slog.Info("embedded login failed", "username", r.PostFormValue("username"), "client_ip", ClientIPFromContext(r))
+		c.renderLoginPage(w, http.StatusUnauthorized, "Invalid username or password.", redirectTo)
+		return
+	}
+	if err != nil {
+		slog.Error("embedded password login", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	c.startSession(w, r, tokenResp, claims, redirectTo)
+}
+
+// HandleProviderLogin redirects to a provider to log in there.
+// GET /coordinator/auth/{provider}/login
+func (c *EmbeddedAuthController) HandleProviderLogin(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+	authURL, err := c.authService.ProviderAuthURL(provider, c.providerRedirectURI(provider), r.URL.Query().Get("redirect_to"))
+	if errors.Is(err, service.ErrUnknownProvider) {
+		http.NotFound(w, r)
+		return
+	}
+	if err != nil {
+		slog.Error("start provider login", "provider", provider, "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	http.Redirect(w, r, authURL, http.StatusFound)
+}
+
+// HandleProviderCallback completes a login at a provider.
+// GET /coordinator/auth/{provider}/callback?code=xxx&state=xxx
+func (c *EmbeddedAuthController) HandleProviderCallback(w http.ResponseWriter, r *http.Request) {
+	provider := r.PathValue("provider")
+	query := r.URL.Query()
+	if errorParam := query.Get("error"); errorParam != "" {
+		slog.Warn("provider login error", "provider", provider, "error", errorParam, "description", query.Get("error_description"))
+		c.renderLoginPage(w, http.StatusBadRequest, "Login with "+provider+" failed.", "")
+		return
+	}
+
+	tokenResp, claims, redirectTo, err := c.authService.ProviderCallback(r.Context(), provider, query.Get("state"), query.Get("code"), c.providerRedirectURI(provider))
+	if errors.Is(err, service.ErrInvalidState) || errors.Is(err, service.ErrStateExpired) {
+		http.Error(w, "invalid or expired state", http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrEmailNotAllowed) {
+		slog.Warn("provider login rejected", "provider", provider, "error", err)
+		c.renderLoginPage(w, http.StatusForbidden, "Your account is not allowed to log in to this coordinator.", "")
+		return
+	}
+	if err != nil {
+		slog.Error("provider login", "provider", provider, "error", err)
+		http.Error(w, "login failed", http.StatusInternalServerError)
+		return
+	}
+	c.startSession(w, r, tokenResp, claims, redirectTo)
+}
+
+// HandleToken issues a token to a local user with the OAuth 2.0 resource
+// owner password grant, for the CLI and scripts.
+// POST /coordinator/auth/token
+func (c *EmbeddedAuthController) HandleToken(w http.ResponseWriter, r *http.Request) {
+	if grantType := r.PostFormValue("grant_type"); grantType != "password" {
+		writeOAuthError(w, "unsupported_grant_type", "only the password grant is supported")
+		return
+	}
+	tokenResp, _, err := c.authService.PasswordLogin(r.Context(), r.PostFormValue("username"), r.PostFormValue("password"), ClientIPFromContext(r))
+	var throttled *service.LoginThrottledError
+	if errors.As(err, &throttled) {
+		slog.Warn("embedded token request throttled", "username", r.PostFormValue("username"), "client_ip", ClientIPFromContext(r))
+		setRetryAfter(w, throttled.RetryAfter)
+		http.Error(w, err.Error(), http.StatusTooManyRequests)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidCredentials) {
+		slog.Info("embedded token request failed", "username", r.PostFormValue("username"), "client_ip", ClientIPFromContext(r))
+		writeOAuthError(w, "invalid_grant", err.Error())
+		return
+	}
+	if err != nil {
+		slog.Error("embedded token request", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(tokenResp)
+}
+
+// HandleJWKS serves the public key of the identity provider's tokens.
+// GET /coordinator/auth/jwks
+func (c *EmbeddedAuthController) HandleJWKS(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(c.authService.Signer().KeySet())
+}
+
+// HandleDiscovery serves the OpenID Connect discovery document, so other
+// services can validate the identity provider's tokens.
+// GET /coordinator/auth/.well-known/openid-configuration
+func (c *EmbeddedAuthController) HandleDiscovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"issuer":                                c.authService.Signer().Issuer(),
+		"jwks_uri":                              c.publicURL + EmbeddedAuthPath + "/jwks",
+		"token_endpoint":                        c.publicURL + EmbeddedAuthPath + "/token",
+		"grant_types_supported":                 []string{"password"},
+		"id_token_signing_alg_values_supported": []string{"EdDSA"},
+	})
+}
+
+// CreateUserRequest is the request body for creating a local user.
+type CreateUserRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+	Email    string `json:"email,omitempty"`
+	Name     string `json:"name,omitempty"`
+}
+
+// SetPasswordRequest is the request body for replacing a local user's
+// password.
+type SetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// UserResponse represents a user of the embedded identity provider in JSON
+// responses. ID is the subject of the user's tokens.
+type UserResponse struct {
+	ID        string    `json:"id"`
+	Provider  string    `json:"provider"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email,omitempty"`
+	Name      string    `json:"name,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandleCreateUser handles POST /coordinator/admin/api/v1/users requests.
+func (c *EmbeddedAuthController) HandleCreateUser(w http.ResponseWriter, r *http.Request) {
+	var req CreateUserRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	user, err := c.authService.CreateLocalUser(r.Context(), req.Username, req.Password, req.Email, req.Name)
+	if errors.Is(err, service.ErrInvalidUser) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if errors.Is(err, service.ErrUserExists) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+	if err != nil {
+		slog.Error("create user", "error", err)
+		http.Error(w, "create user", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(userResponse(user))
+}
+
+// HandleListUsers handles GET /coordinator/admin/api/v1/users requests.
+func (c *EmbeddedAuthController) HandleListUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := c.authService.ListUsers(r.Context())
+	if err != nil {
+		slog.Error("list users", "error", err)
+		http.Error(w, "list users", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]UserResponse, len(users))
+	for i, user := range users {
+		response[i] = userResponse(user)
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleSetPassword handles PUT /coordinator/admin/api/v1/users/{user_id}/password requests.
+func (c *EmbeddedAuthController) HandleSetPassword(w http.ResponseWriter, r *http.Request) {
+	var req SetPasswordRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := c.authService.SetPassword(r.Context(), r.PathValue("user_id"), req.Password)
+	if errors.Is(err, service.ErrUserNotFound) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if errors.Is(err, service.ErrInvalidUser) {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+	if err != nil {
+		slog.Error("set user password", "error", err)
+		http.Error(w, "set user password", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleDeleteUser handles DELETE /coordinator/admin/api/v1/users/{user_id} requests.
+func (c *EmbeddedAuthController) HandleDeleteUser(w http.ResponseWriter, r *http.Request) {
+	err := c.authService.DeleteUser(r.Context(), r.PathValue("user_id"))
+	if errors.Is(err, service.ErrUserNotFound) {
+		http.Error(w, "user not found", http.StatusNotFound)
+		return
+	}
+	if err != nil {
+		slog.Error("delete user", "error", err)
+		http.Error(w, "delete user", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// startSession stores a login's token in a new web session and redirects to
+// redirectTo, or the UI if it is not a safe path.
+func (c *EmbeddedAuthController) startSession(w http.ResponseWriter, r *http.Request, tokenResp *service.TokenResponse, claims *jwtauth.Claims, redirectTo string) {
+	slog.Info("embedded login successful", "sub", claims.Subject, "username", claims.PreferredUsername, "email", claims.Email)
+
+	sessionID, sessionTTL, err := c.oidcService.CreateSession(claims.Subject, tokenResp.AccessToken, "", tokenResp.ExpiresIn)
+	if err != nil {
+		slog.Error("create session", "error", err)
+		http.Error(w, "internal error", http.StatusInternalServerError)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{
+		Name:     c.oidcService.GetSessionCookieName(),
+		Value:    sessionID,
+		Path:     "/",
+		HttpOnly: true,
+		Secure:   c.secureCookie,
+		SameSite: http.SameSiteLaxMode,
+		MaxAge:   int(sessionTTL / time.Second),
+	})
+
+	if !isSafeRedirectPath(redirectTo) {
+		redirectTo = defaultPostLoginRedirect
+	}
+	http.Redirect(w, r, redirectTo, http.StatusFound)
+}
+
+func (c *EmbeddedAuthController) renderLoginPage(w http.ResponseWriter, status int, message, redirectTo string) {
+	if !isSafeRedirectPath(redirectTo) {
+		redirectTo = ""
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_ = loginPage.Execute(w, loginPageData{
+		Error:      message,
+		RedirectTo: redirectTo,
+		Providers:  c.authService.Providers(),
+	})
+}
+
+// providerRedirectURI returns the callback URL registered with a provider.
+func (c *EmbeddedAuthController) providerRedirectURI(provider string) string {
+	return c.publicURL + EmbeddedAuthPath + "/" + url.PathEscape(provider) + "/callback"
+}
+
+// writeOAuthError writes an OAuth 2.0 token endpoint error response.
+// setRetryAfter tells the client to wait d, rounded up to whole seconds,
+// before retrying.
+func setRetryAfter(w http.ResponseWriter, d time.Duration) {
+	w.Header().Set("Retry-After", strconv.Itoa(int((d+time.Second-1)/time.Second)))
+}
+
+func writeOAuthError(w http.ResponseWriter, code, description string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusBadRequest)
+	_ = json.NewEncoder(w).Encode(map[string]string{
+		"error":             code,
+		"error_description": description,
+	})
+}
+
+func userResponse(user *repository.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Provider:  user.Provider,
+		Username:  user.Username,
+		Email:     user.Email,
+		Name:      user.Name,
+		CreatedAt: user.CreatedAt,
+	}
+}
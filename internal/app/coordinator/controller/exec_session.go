@@ -0,0 +1,191 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// ExecSessionController handles the exec session audit trail endpoints.
+type ExecSessionController struct {
+	execSessionService *service.ExecSessionService
+}
+
+// NewExecSessionController creates a new ExecSessionController.
+func NewExecSessionController(execSessionService *service.ExecSessionService) *ExecSessionController {
+	return &ExecSessionController{execSessionService: execSessionService}
+}
+
+// RecordExecSessionRequest is a command run on a node, reported by the
+// client that ran it. At least one of NodeID, NodeName, and Address
+// identifies the node. DurationMs is the run time in milliseconds.
+type RecordExecSessionRequest struct {
+	NodeID     string    `json:"node_id,omitempty"`
+	NodeName   string    `json:"node_name,omitempty"`
+	Address    string    `json:"address,omitempty"`
+	Command    string    `json:"command"`
+	Stdout     string    `json:"stdout"`
+	Stderr     string    `json:"stderr"`
+	ExitCode   int       `json:"exit_code"`
+	Error      string    `json:"error,omitempty"`
+	StartedAt  time.Time `json:"started_at"`
+	DurationMs int64     `json:"duration_ms"`
+}
+
+// ExecSessionResponse represents an exec session in JSON responses.
+type ExecSessionResponse struct {
+	ID              string    `json:"id"`
+	NodeID          string    `json:"node_id,omitempty"`
+	NodeName        string    `json:"node_name,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	Command         string    `json:"command"`
+	Stdout          string    `json:"stdout"`
+	Stderr          string    `json:"stderr"`
+	OutputTruncated bool      `json:"output_truncated"`
+	ExitCode        int       `json:"exit_code"`
+	Error           string    `json:"error,omitempty"`
+	RecordedBy      string    `json:"recorded_by"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMs      int64     `json:"duration_ms"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+// HandleRecord handles POST /api/v1/exec-sessions requests.
+func (c *ExecSessionController) HandleRecord(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req RecordExecSessionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	var recordedBy string
+	if claims := jwtauth.ClaimsFromContext(r.Context()); claims != nil {
+		recordedBy = claims.Email
+		if recordedBy == "" {
+			recordedBy = claims.Subject
+		}
+	} else if key := APIKeyFromContext(r); key != nil {
+		recordedBy = "api-key:" + key.Name
+	}
+
+	session, err := c.execSessionService.Record(r.Context(), wonderNet, service.ExecSessionInput{
+		NodeID:    req.NodeID,
+		NodeName:  req.NodeName,
+		Address:   req.Address,
+		Command:   req.Command,
+		Stdout:    req.Stdout,
+		Stderr:    req.Stderr,
+		ExitCode:  req.ExitCode,
+		Error:     req.Error,
+		StartedAt: req.StartedAt,
+		Duration:  time.Duration(req.DurationMs) * time.Millisecond,
+	}, recordedBy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExecSession) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("record exec session", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "record exec session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(execSessionResponse(session))
+}
+
+// HandleList handles GET /api/v1/exec-sessions requests. The optional limit
+// query parameter, 1-1000 (default 100), bounds how many of the latest
+// sessions are returned.
+func (c *ExecSessionController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var limit int
+	if v := r.URL.Query().Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		limit = n
+	}
+
+	sessions, err := c.execSessionService.List(r.Context(), wonderNet, limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExecSession) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("list exec sessions", "error", err)
+		http.Error(w, "list exec sessions", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]ExecSessionResponse, len(sessions))
+	for i, session := range sessions {
+		response[i] = execSessionResponse(session)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGet handles GET /api/v1/exec-sessions/{id} requests.
+func (c *ExecSessionController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	session, err := c.execSessionService.Get(r.Context(), wonderNet, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrExecSessionNotFound) {
+			http.Error(w, "exec session not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get exec session", "error", err)
+		http.Error(w, "get exec session", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(execSessionResponse(session))
+}
+
+func execSessionResponse(s *repository.ExecSession) ExecSessionResponse {
+	return ExecSessionResponse{
+		ID:              s.ID,
+		NodeID:          s.NodeID,
+		NodeName:        s.NodeName,
+		Address:         s.Address,
+		Command:         s.Command,
+		Stdout:          s.Stdout,
+		Stderr:          s.Stderr,
+		OutputTruncated: s.OutputTruncated,
+		ExitCode:        s.ExitCode,
+		Error:           s.Error,
+		RecordedBy:      s.RecordedBy,
+		StartedAt:       s.StartedAt,
+		DurationMs:      s.Duration.Milliseconds(),
+		CreatedAt:       s.CreatedAt,
+	}
+}
@@ -1,30 +1,96 @@
 package controller
 
 import (
+	"context"
 	"fmt"
+	"log/slog"
 	"net/http"
+	"strings"
+	"time"
 
-	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
 )
 
+const backendHealthTimeout = 5 * time.Second
+
+// DatabasePinger checks that the coordinator database is reachable.
+type DatabasePinger interface {
+	Ping(ctx context.Context) error
+}
+
+// CircuitBreaker reports the state of the circuit breaker around Headscale
+// calls.
+type CircuitBreaker interface {
+	State() string
+}
+
 // HealthController provides readiness checks for the coordinator service.
 type HealthController struct {
-	headscaleClient v1.HeadscaleServiceClient
+	database         DatabasePinger
+	headscaleBreaker CircuitBreaker
+	backends         []meshbackend.MeshBackend
 }
 
-// NewHealthController creates a new HealthController.
-func NewHealthController(headscaleClient v1.HeadscaleServiceClient) *HealthController {
-	return &HealthController{headscaleClient: headscaleClient}
+// NewHealthController creates a new HealthController that checks the
+// database, the Headscale circuit breaker, and each of the given mesh
+// backends.
+func NewHealthController(database DatabasePinger, headscaleBreaker CircuitBreaker, backends ...meshbackend.MeshBackend) *HealthController {
+	return &HealthController{database: database, headscaleBreaker: headscaleBreaker, backends: backends}
 }
 
 // ServeHTTP handles GET /health requests.
+//
+// The response lists the state of the database and every mesh backend, one
+// "database: ok" or "<mesh type>: ok" line each ("unhealthy" on failure),
+// and is 503 if any check fails. While the Headscale circuit breaker is not
+// closed, a "headscale: degraded (circuit <state>)" line is added. Failure
+// details are logged rather than returned, as the endpoint is
+// unauthenticated.
 func (c *HealthController) ServeHTTP(w http.ResponseWriter, r *http.Request) {
-	ctx := r.Context()
-	_, err := c.headscaleClient.ListUsers(ctx, &v1.ListUsersRequest{})
-	if err != nil {
-		http.Error(w, "headscale unhealthy", http.StatusServiceUnavailable)
+	healthy := true
+	var lines strings.Builder
+	if err := checkDatabase(r.Context(), c.database); err != nil {
+		slog.Warn("database health check", "error", err)
+		healthy = false
+		_, _ = fmt.Fprint(&lines, "database: unhealthy\n")
+	} else {
+		_, _ = fmt.Fprint(&lines, "database: ok\n")
+	}
+	if c.headscaleBreaker != nil {
+		if state := c.headscaleBreaker.State(); state != headscale.CircuitClosed {
+			_, _ = fmt.Fprintf(&lines, "headscale: degraded (circuit %s)\n", state)
+		}
+	}
+	for _, backend := range c.backends {
+		meshType := backend.MeshType()
+		if err := checkBackend(r.Context(), backend); err != nil {
+			slog.Warn("mesh backend health check", "mesh_type", meshType, "error", err)
+			healthy = false
+			_, _ = fmt.Fprintf(&lines, "%s: unhealthy\n", meshType)
+			continue
+		}
+		_, _ = fmt.Fprintf(&lines, "%s: ok\n", meshType)
+	}
+
+	w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+	if !healthy {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprint(w, "unhealthy\n"+lines.String())
 		return
 	}
 	w.WriteHeader(http.StatusOK)
-	_, _ = fmt.Fprintln(w, "ok")
+	_, _ = fmt.Fprint(w, "ok\n"+lines.String())
+}
+
+func checkDatabase(ctx context.Context, database DatabasePinger) error {
+	ctx, cancel := context.WithTimeout(ctx, backendHealthTimeout)
+	defer cancel()
+	return database.Ping(ctx)
+}
+
+func checkBackend(ctx context.Context, backend meshbackend.MeshBackend) error {
+	ctx, cancel := context.WithTimeout(ctx, backendHealthTimeout)
+	defer cancel()
+	return backend.Healthy(ctx)
 }
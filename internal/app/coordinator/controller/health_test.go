@@ -0,0 +1,100 @@
+package controller
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+type fakeHealthBackend struct {
+	meshbackend.MeshBackend
+	meshType meshbackend.MeshType
+	err      error
+}
+
+func (b *fakeHealthBackend) MeshType() meshbackend.MeshType { return b.meshType }
+
+func (b *fakeHealthBackend) Healthy(ctx context.Context) error { return b.err }
+
+type fakeBreaker struct {
+	state string
+}
+
+func (b *fakeBreaker) State() string { return b.state }
+
+type fakeDatabase struct {
+	err error
+}
+
+func (d *fakeDatabase) Ping(ctx context.Context) error { return d.err }
+
+func TestHealthController(t *testing.T) {
+	tests := []struct {
+		name       string
+		database   DatabasePinger
+		breaker    CircuitBreaker
+		backends   []meshbackend.MeshBackend
+		wantStatus int
+		wantBody   string
+	}{
+		{
+			name:       "all healthy",
+			database:   &fakeDatabase{},
+			backends:   []meshbackend.MeshBackend{&fakeHealthBackend{meshType: meshbackend.MeshTypeTailscale}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ok\ndatabase: ok\ntailscale: ok\n",
+		},
+		{
+			name:     "one backend failing",
+			database: &fakeDatabase{},
+			backends: []meshbackend.MeshBackend{
+				&fakeHealthBackend{meshType: meshbackend.MeshTypeTailscale},
+				&fakeHealthBackend{meshType: meshbackend.MeshTypeNetbird, err: errors.New("connection refused")},
+			},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unhealthy\ndatabase: ok\ntailscale: ok\nnetbird: unhealthy\n",
+		},
+		{
+			name:       "database unreachable",
+			database:   &fakeDatabase{err: errors.New("connection refused")},
+			backends:   []meshbackend.MeshBackend{&fakeHealthBackend{meshType: meshbackend.MeshTypeTailscale}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unhealthy\ndatabase: unhealthy\ntailscale: ok\n",
+		},
+		{
+			name:       "headscale circuit closed",
+			database:   &fakeDatabase{},
+			breaker:    &fakeBreaker{state: headscale.CircuitClosed},
+			backends:   []meshbackend.MeshBackend{&fakeHealthBackend{meshType: meshbackend.MeshTypeTailscale}},
+			wantStatus: http.StatusOK,
+			wantBody:   "ok\ndatabase: ok\ntailscale: ok\n",
+		},
+		{
+			name:       "headscale circuit open",
+			database:   &fakeDatabase{},
+			breaker:    &fakeBreaker{state: headscale.CircuitOpen},
+			backends:   []meshbackend.MeshBackend{&fakeHealthBackend{meshType: meshbackend.MeshTypeTailscale, err: errors.New("circuit breaker open")}},
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   "unhealthy\ndatabase: ok\nheadscale: degraded (circuit open)\ntailscale: unhealthy\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			NewHealthController(tt.database, tt.breaker, tt.backends...).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/coordinator/health", nil))
+
+			if rec.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := rec.Body.String(); got != tt.wantBody {
+				t.Errorf("body = %q, want %q", got, tt.wantBody)
+			}
+		})
+	}
+}
@@ -0,0 +1,85 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// IntrospectionController handles token introspection for integrations.
+type IntrospectionController struct {
+	introspectionService *service.TokenIntrospectionService
+}
+
+// NewIntrospectionController creates a new IntrospectionController.
+func NewIntrospectionController(introspectionService *service.TokenIntrospectionService) *IntrospectionController {
+	return &IntrospectionController{introspectionService: introspectionService}
+}
+
+// IntrospectionResponse is an RFC 7662 introspection response. Inactive
+// tokens only set Active. Scope is the space-separated scopes of an API
+// key; Exp and Iat are Unix times, and Exp is omitted for tokens that do
+// not expire. JTI, MaxUses, and Uses are set for join tokens.
+type IntrospectionResponse struct {
+	Active      bool   `json:"active"`
+	TokenType   string `json:"token_type,omitempty"`
+	Scope       string `json:"scope,omitempty"`
+	WonderNetID string `json:"wonder_net_id,omitempty"`
+	Exp         int64  `json:"exp,omitempty"`
+	Iat         int64  `json:"iat,omitempty"`
+	JTI         string `json:"jti,omitempty"`
+	MaxUses     int    `json:"max_uses,omitempty"`
+	Uses        int    `json:"uses,omitempty"`
+}
+
+// HandleIntrospect handles POST /api/v1/introspect requests. The token is
+// passed as the token form parameter, as in RFC 7662; token_type_hint is
+// accepted and ignored, since API keys and join tokens are told apart by
+// their format.
+func (c *IntrospectionController) HandleIntrospect(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	token := strings.TrimSpace(r.PostForm.Get("token"))
+	if token == "" {
+		http.Error(w, "token required", http.StatusBadRequest)
+		return
+	}
+
+	result, err := c.introspectionService.Introspect(r.Context(), wonderNet, token)
+	if err != nil {
+		slog.Error("introspect token", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "introspect token", http.StatusInternalServerError)
+		return
+	}
+
+	response := IntrospectionResponse{Active: result.Active}
+	if result.Active {
+		response.TokenType = result.TokenType
+		response.Scope = strings.Join(result.Scopes, " ")
+		response.WonderNetID = result.WonderNetID
+		response.JTI = result.JTI
+		response.MaxUses = result.MaxUses
+		response.Uses = result.Uses
+		if !result.IssuedAt.IsZero() {
+			response.Iat = result.IssuedAt.Unix()
+		}
+		if result.ExpiresAt != nil {
+			response.Exp = result.ExpiresAt.Unix()
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	_ = json.NewEncoder(w).Encode(response)
+}
@@ -2,11 +2,18 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"io"
 	"log/slog"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/skip2/go-qrcode"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
 )
 
 // JoinTokenController handles join token creation for workers.
@@ -22,15 +29,39 @@ func NewJoinTokenController(workerService *service.WorkerService) *JoinTokenCont
 }
 
 // JoinTokenResponse represents the response body for creating a join token.
+// JTI identifies the token for GET /api/v1/join-token/{jti}.
 type JoinTokenResponse struct {
-	Token     string `json:"token"`
-	ExpiresIn int    `json:"expires_in"`
+	Token        string   `json:"token"`
+	ExpiresIn    int      `json:"expires_in"`
+	JTI          string   `json:"jti"`
+	MaxUses      int      `json:"max_uses"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
 }
 
-// HandleCreateJoinToken handles GET /api/v1/join-token requests.
-// Creates a JWT join token for worker nodes.
+// JoinTokenStatusResponse reports how often a join token was used.
+type JoinTokenStatusResponse struct {
+	JTI          string     `json:"jti"`
+	MaxUses      int        `json:"max_uses"`
+	Uses         int        `json:"uses"`
+	Remaining    int        `json:"remaining"`
+	Expired      bool       `json:"expired"`
+	CreatedBy    string     `json:"created_by,omitempty"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ExpiresAt    time.Time  `json:"expires_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	AllowedCIDRs []string   `json:"allowed_cidrs,omitempty"`
+}
+
+// HandleCreateJoinToken handles GET and POST /api/v1/join-token requests.
+// Creates a JWT join token for worker nodes. The optional ttl parameter, a
+// duration such as 72h, sets how long the token is valid, within the wonder
+// net's join token policy (default 8h). max_uses sets how many workers may
+// join with it (default 1), and each allowed_cidr parameter adds an address
+// range workers may join from. POST requests may also send the parameters
+// as a form body. Tokens created with an API key record the key's name as
+// their creator.
 func (c *JoinTokenController) HandleCreateJoinToken(w http.ResponseWriter, r *http.Request) {
-	if r.Method != http.MethodGet {
+	if r.Method != http.MethodGet && r.Method != http.MethodPost {
 		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
@@ -41,16 +72,176 @@ func (c *JoinTokenController) HandleCreateJoinToken(w http.ResponseWriter, r *ht
 		return
 	}
 
-	token, err := c.workerService.GenerateJoinToken(r.Context(), wonderNet, 8*time.Hour)
+	createdBy, ok := joinTokenCreator(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+	writeJoinToken(w, r, c.workerService, wonderNet, createdBy)
+}
+
+// joinTokenQRSize is the width and height of join token QR code PNGs.
+const joinTokenQRSize = 384
+
+// HandleJoinTokenQR handles GET /api/v1/join-token/qr requests.
+// It creates a join token like HandleCreateJoinToken, with the same query
+// parameters, and returns a QR code of its join payload, the coordinator
+// URL and token as a wonder://join URI, for devices that scan it instead
+// of typing the token. The QR code is a PNG, or text for terminals with
+// format=ascii. The token's jti is in the X-Join-Token-JTI header.
+func (c *JoinTokenController) HandleJoinTokenQR(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	createdBy, ok := joinTokenCreator(r)
+	if !ok {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	format := r.URL.Query().Get("format")
+	if format != "" && format != "png" && format != "ascii" {
+		http.Error(w, "invalid format, want png or ascii", http.StatusBadRequest)
+		return
+	}
+
+	token, ok := issueJoinToken(w, r, c.workerService, wonderNet, createdBy)
+	if !ok {
+		return
+	}
+
+	qr, err := qrcode.New(jointoken.Payload(token.CoordinatorURL, token.Token), qrcode.Low)
 	if err != nil {
-		slog.Error("generate join token", "error", err)
-		http.Error(w, "generate join token", http.StatusInternalServerError)
+		slog.Error("encode join token QR code", "error", err)
+		http.Error(w, "encode QR code", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Cache-Control", "no-store")
+	w.Header().Set("X-Join-Token-JTI", token.JTI)
+	if format == "ascii" {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		_, _ = io.WriteString(w, qr.ToSmallString(false))
+		return
+	}
+	png, err := qr.PNG(joinTokenQRSize)
+	if err != nil {
+		slog.Error("render join token QR code", "error", err)
+		http.Error(w, "render QR code", http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "image/png")
+	_, _ = w.Write(png)
+}
+
+// joinTokenCreator returns who creates a join token with the request: the
+// user's email or subject, or the name of the API key.
+func joinTokenCreator(r *http.Request) (string, bool) {
+	if claims := jwtauth.ClaimsFromContext(r.Context()); claims != nil {
+		if claims.Email != "" {
+			return claims.Email, true
+		}
+		return claims.Subject, true
+	}
+	if key := APIKeyFromContext(r); key != nil {
+		return "api-key:" + key.Name, true
+	}
+	return "", false
+}
+
+// HandleGetJoinTokenStatus handles GET /api/v1/join-token/{jti} requests.
+func (c *JoinTokenController) HandleGetJoinTokenStatus(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	token, err := c.workerService.JoinTokenStatus(r.Context(), wonderNet, r.PathValue("jti"))
+	if err != nil {
+		if errors.Is(err, service.ErrJoinTokenNotFound) {
+			http.Error(w, "join token not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get join token status", "error", err)
+		http.Error(w, "get join token status", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(JoinTokenStatusResponse{
+		JTI:          token.JTI,
+		MaxUses:      token.MaxUses,
+		Uses:         token.Uses,
+		Remaining:    max(token.MaxUses-token.Uses, 0),
+		Expired:      !time.Now().Before(token.ExpiresAt),
+		CreatedBy:    token.CreatedBy,
+		CreatedAt:    token.CreatedAt,
+		ExpiresAt:    token.ExpiresAt,
+		LastUsedAt:   token.LastUsedAt,
+		AllowedCIDRs: token.AllowedCIDRs,
+	})
+}
+
+// writeJoinToken creates a join token for the wonder net with the ttl,
+// max_uses, and allowed_cidr parameters and writes it as a JoinTokenResponse.
+func writeJoinToken(w http.ResponseWriter, r *http.Request, workerService *service.WorkerService, wonderNet *repository.WonderNet, createdBy string) {
+	token, ok := issueJoinToken(w, r, workerService, wonderNet, createdBy)
+	if !ok {
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	_ = json.NewEncoder(w).Encode(JoinTokenResponse{
-		Token:     token,
-		ExpiresIn: 28800,
+		Token:        token.Token,
+		ExpiresIn:    int(token.TTL.Seconds()),
+		JTI:          token.JTI,
+		MaxUses:      token.MaxUses,
+		AllowedCIDRs: token.AllowedCIDRs,
 	})
 }
+
+// issueJoinToken creates a join token for the wonder net with the ttl,
+// max_uses, and allowed_cidr parameters. If that fails, it writes the error
+// response and returns false.
+func issueJoinToken(w http.ResponseWriter, r *http.Request, workerService *service.WorkerService, wonderNet *repository.WonderNet, createdBy string) (*service.IssuedJoinToken, bool) {
+	if err := r.ParseForm(); err != nil {
+		http.Error(w, "invalid parameters", http.StatusBadRequest)
+		return nil, false
+	}
+
+	var ttl time.Duration
+	if v := r.Form.Get("ttl"); v != "" {
+		d, err := time.ParseDuration(v)
+		if err != nil || d <= 0 {
+			http.Error(w, "invalid ttl, want a positive duration such as 72h", http.StatusBadRequest)
+			return nil, false
+		}
+		ttl = d
+	}
+
+	var maxUses int
+	if v := r.Form.Get("max_uses"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil {
+			http.Error(w, "invalid max_uses", http.StatusBadRequest)
+			return nil, false
+		}
+		maxUses = n
+	}
+
+	token, err := workerService.GenerateJoinToken(r.Context(), wonderNet, createdBy, ttl, maxUses, r.Form["allowed_cidr"])
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidJoinTokenUses) || errors.Is(err, service.ErrInvalidJoinTokenTTL) || errors.Is(err, service.ErrInvalidAllowedCIDR) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return nil, false
+		}
+		slog.Error("generate join token", "error", err)
+		http.Error(w, "generate join token", http.StatusInternalServerError)
+		return nil, false
+	}
+	return token, true
+}
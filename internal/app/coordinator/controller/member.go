@@ -0,0 +1,308 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// MemberController handles the endpoints for sharing a wonder net with other
+// users: its members and invites, and the invites addressed to the caller.
+type MemberController struct {
+	memberService *service.MemberService
+}
+
+// NewMemberController creates a new MemberController.
+func NewMemberController(memberService *service.MemberService) *MemberController {
+	return &MemberController{
+		memberService: memberService,
+	}
+}
+
+// CreateInviteRequest is the request body for inviting a user by email.
+// Role is owner, member, or read_only.
+type CreateInviteRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// UpdateMemberRequest is the request body for changing a member's role.
+type UpdateMemberRequest struct {
+	Role string `json:"role"`
+}
+
+// MemberResponse represents a wonder net member in JSON responses.
+type MemberResponse struct {
+	UserID    string    `json:"user_id"`
+	Email     string    `json:"email,omitempty"`
+	Role      string    `json:"role"`
+	AddedBy   string    `json:"added_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// InviteResponse represents a wonder net invite in JSON responses.
+type InviteResponse struct {
+	ID          string    `json:"id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	InvitedBy   string    `json:"invited_by"`
+	Status      string    `json:"status"`
+	CreatedAt   time.Time `json:"created_at"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// HandleListMembers handles GET /api/v1/members requests.
+func (c *MemberController) HandleListMembers(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	members, err := c.memberService.ListMembers(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list members", "error", err)
+		http.Error(w, "list members", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]MemberResponse, len(members))
+	for i, m := range members {
+		response[i] = memberResponse(m)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleUpdateMember handles PUT /api/v1/members/{user_id} requests.
+func (c *MemberController) HandleUpdateMember(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req UpdateMemberRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	err := c.memberService.UpdateMemberRole(r.Context(), wonderNet, WonderNetRoleFromContext(r), r.PathValue("user_id"), req.Role)
+	if err != nil {
+		writeMemberError(w, err, "update member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleRemoveMember handles DELETE /api/v1/members/{user_id} requests.
+// Members may remove themselves to leave the wonder net.
+func (c *MemberController) HandleRemoveMember(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	err := c.memberService.RemoveMember(r.Context(), wonderNet, claims.Subject, WonderNetRoleFromContext(r), r.PathValue("user_id"))
+	if err != nil {
+		writeMemberError(w, err, "remove member")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleCreateInvite handles POST /api/v1/members/invites requests.
+func (c *MemberController) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateInviteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	invitedBy := claims.Email
+	if invitedBy == "" {
+		invitedBy = claims.Subject
+	}
+
+	inv, err := c.memberService.Invite(r.Context(), wonderNet, WonderNetRoleFromContext(r), invitedBy, req.Email, req.Role)
+	if err != nil {
+		writeMemberError(w, err, "invite member")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(inviteResponse(inv))
+}
+
+// HandleListInvites handles GET /api/v1/members/invites requests.
+func (c *MemberController) HandleListInvites(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	invites, err := c.memberService.ListInvites(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list invites", "error", err)
+		http.Error(w, "list invites", http.StatusInternalServerError)
+		return
+	}
+	writeInvites(w, invites)
+}
+
+// HandleRevokeInvite handles DELETE /api/v1/members/invites/{id} requests.
+func (c *MemberController) HandleRevokeInvite(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := c.memberService.RevokeInvite(r.Context(), wonderNet, WonderNetRoleFromContext(r), r.PathValue("id")); err != nil {
+		writeMemberError(w, err, "revoke invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListMyInvites handles GET /api/v1/invites requests, listing the
+// pending invites addressed to the caller.
+func (c *MemberController) HandleListMyInvites(w http.ResponseWriter, r *http.Request) {
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	invites, err := c.memberService.ListInvitesForUser(r.Context(), claims.Subject)
+	if err != nil {
+		slog.Error("list invites", "error", err)
+		http.Error(w, "list invites", http.StatusInternalServerError)
+		return
+	}
+	writeInvites(w, invites)
+}
+
+// HandleAcceptInvite handles POST /api/v1/invites/{id}/accept requests.
+// It responds with the wonder net the caller joined.
+func (c *MemberController) HandleAcceptInvite(w http.ResponseWriter, r *http.Request) {
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	wonderNet, err := c.memberService.AcceptInvite(r.Context(), claims.Subject, r.PathValue("id"))
+	if err != nil {
+		writeMemberError(w, err, "accept invite")
+		return
+	}
+
+	role, err := c.memberService.Role(r.Context(), wonderNet, claims.Subject)
+	if err != nil {
+		slog.Error("get wonder net role", "error", err)
+		http.Error(w, "accept invite", http.StatusInternalServerError)
+		return
+	}
+	resp := userWonderNetResponse(wonderNet)
+	resp.IsDefault = false
+	resp.Role = role
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// HandleDeclineInvite handles POST /api/v1/invites/{id}/decline requests.
+func (c *MemberController) HandleDeclineInvite(w http.ResponseWriter, r *http.Request) {
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	if err := c.memberService.DeclineInvite(r.Context(), claims.Subject, r.PathValue("id")); err != nil {
+		writeMemberError(w, err, "decline invite")
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// writeMemberError maps member service errors to HTTP responses.
+func writeMemberError(w http.ResponseWriter, err error, action string) {
+	switch {
+	case errors.Is(err, service.ErrNotWonderNetOwner):
+		http.Error(w, err.Error(), http.StatusForbidden)
+	case errors.Is(err, service.ErrMemberNotFound):
+		http.Error(w, "member not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrInviteNotFound):
+		http.Error(w, "invite not found", http.StatusNotFound)
+	case errors.Is(err, service.ErrUserNotFound):
+		http.Error(w, "no user with this email", http.StatusNotFound)
+	case errors.Is(err, service.ErrAlreadyMember),
+		errors.Is(err, service.ErrInvitePending),
+		errors.Is(err, service.ErrInviteNotPending):
+		http.Error(w, err.Error(), http.StatusConflict)
+	case errors.Is(err, service.ErrInvalidMember):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	default:
+		slog.Error(action, "error", err)
+		http.Error(w, action, http.StatusInternalServerError)
+	}
+}
+
+func writeInvites(w http.ResponseWriter, invites []*repository.WonderNetInvite) {
+	response := make([]InviteResponse, len(invites))
+	for i, inv := range invites {
+		response[i] = inviteResponse(inv)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func memberResponse(m *repository.WonderNetMember) MemberResponse {
+	return MemberResponse{
+		UserID:    m.UserID,
+		Email:     m.Email,
+		Role:      m.Role,
+		AddedBy:   m.AddedBy,
+		CreatedAt: m.CreatedAt,
+	}
+}
+
+func inviteResponse(inv *repository.WonderNetInvite) InviteResponse {
+	return InviteResponse{
+		ID:          inv.ID,
+		WonderNetID: inv.WonderNetID,
+		Email:       inv.Email,
+		Role:        inv.Role,
+		InvitedBy:   inv.InvitedBy,
+		Status:      inv.Status,
+		CreatedAt:   inv.CreatedAt,
+		ExpiresAt:   inv.ExpiresAt,
+	}
+}
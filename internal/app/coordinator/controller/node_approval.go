@@ -0,0 +1,126 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// NodeApprovalController handles the endpoints for nodes that wait for
+// approval in wonder nets that require node approval.
+type NodeApprovalController struct {
+	nodeApprovalService *service.NodeApprovalService
+}
+
+// NewNodeApprovalController creates a new NodeApprovalController.
+func NewNodeApprovalController(nodeApprovalService *service.NodeApprovalService) *NodeApprovalController {
+	return &NodeApprovalController{nodeApprovalService: nodeApprovalService}
+}
+
+// PendingNodeResponse represents a node waiting for approval in JSON responses.
+type PendingNodeResponse struct {
+	ID       uint64    `json:"id"`
+	Name     string    `json:"name"`
+	Hostname string    `json:"hostname,omitempty"`
+	IPAddrs  []string  `json:"ip_addresses"`
+	Online   bool      `json:"online"`
+	SeenAt   time.Time `json:"seen_at"`
+}
+
+// NodeApprovalResponse represents the approval of a node in JSON responses.
+type NodeApprovalResponse struct {
+	NodeID    uint64     `json:"node_id"`
+	Status    string     `json:"status"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// HandleListPending handles GET /api/v1/nodes/pending requests.
+func (c *NodeApprovalController) HandleListPending(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	nodes, err := c.nodeApprovalService.ListPending(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list pending nodes", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "list pending nodes", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]PendingNodeResponse, len(nodes))
+	for i, node := range nodes {
+		response[i] = PendingNodeResponse{
+			ID:       node.ID,
+			Name:     node.Name,
+			Hostname: node.Hostname,
+			IPAddrs:  node.IPAddrs,
+			Online:   node.Online,
+			SeenAt:   node.SeenAt,
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleApprove handles POST /api/v1/nodes/{id}/approve requests.
+func (c *NodeApprovalController) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	nodeID := r.PathValue("id")
+	if nodeID == "" {
+		http.Error(w, "node id required", http.StatusBadRequest)
+		return
+	}
+
+	decidedBy := claims.Email
+	if decidedBy == "" {
+		decidedBy = claims.Subject
+	}
+
+	approval, err := c.nodeApprovalService.Approve(r.Context(), wonderNet, WonderNetRoleFromContext(r), nodeID, decidedBy)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNotWonderNetOwner):
+			http.Error(w, err.Error(), http.StatusForbidden)
+		case errors.Is(err, service.ErrNodeNotFound):
+			http.Error(w, "node not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNodeNotPending):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("approve node", "error", err, "wonder_net_id", wonderNet.ID, "node_id", nodeID)
+			http.Error(w, "approve node", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(nodeApprovalResponse(approval))
+}
+
+func nodeApprovalResponse(approval *repository.NodeApproval) NodeApprovalResponse {
+	response := NodeApprovalResponse{
+		Status:    approval.Status,
+		DecidedBy: approval.DecidedBy,
+		DecidedAt: approval.DecidedAt,
+	}
+	if id, err := strconv.ParseUint(approval.NodeID, 10, 64); err == nil {
+		response.NodeID = id
+	}
+	return response
+}
@@ -0,0 +1,236 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// NodeDecommissionController handles node decommission endpoints, including
+// the wipe results reported by workers.
+type NodeDecommissionController struct {
+	decommissionService *service.NodeDecommissionService
+	workerService       *service.WorkerService
+}
+
+// NewNodeDecommissionController creates a new NodeDecommissionController.
+func NewNodeDecommissionController(decommissionService *service.NodeDecommissionService, workerService *service.WorkerService) *NodeDecommissionController {
+	return &NodeDecommissionController{
+		decommissionService: decommissionService,
+		workerService:       workerService,
+	}
+}
+
+// DecommissionNodeRequest is the request body for decommissioning a node.
+// Wipe has the node's worker run its configured wipe job before the node is
+// removed.
+type DecommissionNodeRequest struct {
+	Wipe   bool   `json:"wipe"`
+	Reason string `json:"reason,omitempty"`
+}
+
+// WipeResultRequest is the result of a wipe job reported by a worker.
+type WipeResultRequest struct {
+	DecommissionID string `json:"decommission_id"`
+	Success        bool   `json:"success"`
+	Output         string `json:"output"`
+}
+
+// NodeDecommissionResponse represents a node decommission in JSON responses.
+type NodeDecommissionResponse struct {
+	ID          string     `json:"id"`
+	NodeID      string     `json:"node_id"`
+	NodeName    string     `json:"node_name"`
+	Hostname    string     `json:"hostname,omitempty"`
+	Addresses   []string   `json:"addresses"`
+	OS          string     `json:"os,omitempty"`
+	LastSeenAt  *time.Time `json:"last_seen_at,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	Reason      string     `json:"reason,omitempty"`
+	Status      string     `json:"status"`
+	WipeStatus  string     `json:"wipe_status"`
+	WipeOutput  string     `json:"wipe_output,omitempty"`
+	Error       string     `json:"error,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+	CompletedAt *time.Time `json:"completed_at,omitempty"`
+}
+
+// HandleDecommission handles POST /api/v1/nodes/{id}/decommission requests.
+// It responds 200 with the completed decommission, or 202 while the node's
+// worker runs the wipe job.
+func (c *NodeDecommissionController) HandleDecommission(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	nodeID := r.PathValue("id")
+	if nodeID == "" {
+		http.Error(w, "node id required", http.StatusBadRequest)
+		return
+	}
+
+	var req DecommissionNodeRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "invalid request body", http.StatusBadRequest)
+			return
+		}
+	}
+
+	requestedBy := claims.Email
+	if requestedBy == "" {
+		requestedBy = claims.Subject
+	}
+
+	d, err := c.decommissionService.Decommission(r.Context(), wonderNet, nodeID, requestedBy, req.Reason, req.Wipe)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNodeNotFound):
+			http.Error(w, "node not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNodeDecommissionInProgress):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrInvalidNodeDecommission):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		default:
+			slog.Error("decommission node", "error", err, "wonder_net_id", wonderNet.ID, "node_id", nodeID)
+			http.Error(w, "decommission node", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	if d.Status == repository.NodeDecommissionInProgress {
+		w.WriteHeader(http.StatusAccepted)
+	}
+	_ = json.NewEncoder(w).Encode(nodeDecommissionResponse(d))
+}
+
+// HandleList handles GET /api/v1/node-decommissions requests.
+func (c *NodeDecommissionController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	decommissions, err := c.decommissionService.ListDecommissions(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list node decommissions", "error", err)
+		http.Error(w, "list node decommissions", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]NodeDecommissionResponse, len(decommissions))
+	for i, d := range decommissions {
+		response[i] = nodeDecommissionResponse(d)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGet handles GET /api/v1/node-decommissions/{id} requests.
+func (c *NodeDecommissionController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	d, err := c.decommissionService.GetDecommission(r.Context(), wonderNet, r.PathValue("id"))
+	if err != nil {
+		if errors.Is(err, service.ErrNodeDecommissionNotFound) {
+			http.Error(w, "node decommission not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("get node decommission", "error", err)
+		http.Error(w, "get node decommission", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(nodeDecommissionResponse(d))
+}
+
+// HandleWipeResult handles POST /api/v1/worker/wipe-result requests.
+// This endpoint authenticates with the worker token returned on join,
+// passed as a Bearer token.
+func (c *NodeDecommissionController) HandleWipeResult(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "worker token required", http.StatusUnauthorized)
+		return
+	}
+
+	var req WipeResultRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.DecommissionID == "" {
+		http.Error(w, "decommission_id required", http.StatusBadRequest)
+		return
+	}
+
+	worker, err := c.workerService.AuthenticateWorker(r.Context(), token, hostWonderNetID(r), ClientIPFromContext(r))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidToken) {
+			http.Error(w, "invalid worker token", http.StatusUnauthorized)
+			return
+		}
+		slog.Error("authenticate worker", "error", err)
+		http.Error(w, "authenticate worker", http.StatusInternalServerError)
+		return
+	}
+
+	if worker.NodeID == "" {
+		http.Error(w, "worker token is not bound to a node", http.StatusNotFound)
+		return
+	}
+
+	_, err = c.decommissionService.CompleteWipe(r.Context(), worker.WonderNet, worker.NodeID, req.DecommissionID, req.Success, req.Output)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrNodeDecommissionNotFound):
+			http.Error(w, "node decommission not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNodeWipeNotPending):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("complete node wipe", "error", err, "id", req.DecommissionID)
+			http.Error(w, "complete node wipe", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func nodeDecommissionResponse(d *repository.NodeDecommission) NodeDecommissionResponse {
+	return NodeDecommissionResponse{
+		ID:          d.ID,
+		NodeID:      d.NodeID,
+		NodeName:    d.NodeName,
+		Hostname:    d.Hostname,
+		Addresses:   nonNilStrings(d.Addresses),
+		OS:          d.OS,
+		LastSeenAt:  d.LastSeenAt,
+		RequestedBy: d.RequestedBy,
+		Reason:      d.Reason,
+		Status:      d.Status,
+		WipeStatus:  d.WipeStatus,
+		WipeOutput:  d.WipeOutput,
+		Error:       d.Error,
+		CreatedAt:   d.CreatedAt,
+		CompletedAt: d.CompletedAt,
+	}
+}
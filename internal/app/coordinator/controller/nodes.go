@@ -2,42 +2,115 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"net/netip"
+	"strconv"
+	"time"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
 )
 
-// NodeResponse represents a mesh network node in JSON responses.
+// NodeResponse represents a mesh network node in JSON responses. IPAddrs
+// lists all mesh addresses of the node, and IP has one of each IP family.
+// CreatedAt is when the node registered with the mesh. OS and
+// TailscaleVersion are those the node's worker last reported.
 type NodeResponse struct {
-	ID       uint64   `json:"id"`
-	Name     string   `json:"name"`
-	IPAddrs  []string `json:"ip_addresses"`
-	Online   bool     `json:"online"`
-	LastSeen string   `json:"last_seen,omitempty"`
+	ID               uint64         `json:"id"`
+	Name             string         `json:"name"`
+	Hostname         string         `json:"hostname,omitempty"`
+	IPAddrs          []string       `json:"ip_addresses"`
+	IP               NodeIPResponse `json:"ip"`
+	Online           bool           `json:"online"`
+	LastSeen         string         `json:"last_seen,omitempty"`
+	CreatedAt        string         `json:"created_at,omitempty"`
+	Tags             []string       `json:"tags,omitempty"`
+	OS               string         `json:"os,omitempty"`
+	TailscaleVersion string         `json:"tailscale_version,omitempty"`
+	// Health is omitted for nodes whose worker never sent a heartbeat.
+	Health *NodeHealthResponse `json:"health,omitempty"`
 }
 
-// NodeListResponse represents the response for listing nodes.
+// NodeIPResponse holds a node's mesh address of each IP family. Either is
+// omitted if the node has no address of that family.
+type NodeIPResponse struct {
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+// NodeHealthResponse is the health a node's worker last reported. Healthy
+// is false once the report is older than the heartbeat timeout.
+type NodeHealthResponse struct {
+	Healthy              bool   `json:"healthy"`
+	OS                   string `json:"os"`
+	TailscaleVersion     string `json:"tailscale_version"`
+	DiskTotalBytes       int64  `json:"disk_total_bytes"`
+	DiskFreeBytes        int64  `json:"disk_free_bytes"`
+	MemoryTotalBytes     int64  `json:"memory_total_bytes"`
+	MemoryAvailableBytes int64  `json:"memory_available_bytes"`
+	ReportedAt           string `json:"reported_at"`
+}
+
+// NodeDetailResponse represents a single node with its routing state.
+// PrimaryRoutes are the approved routes the mesh currently sends through
+// the node.
+type NodeDetailResponse struct {
+	NodeResponse
+	AdvertisedRoutes []string `json:"advertised_routes"`
+	ApprovedRoutes   []string `json:"approved_routes"`
+	PrimaryRoutes    []string `json:"primary_routes"`
+	ExitNode         bool     `json:"exit_node"`
+}
+
+// NodeListResponse represents the response for listing nodes. Count is the
+// number of nodes in this response, and NextCursor is set when a limit cut
+// the listing short.
 type NodeListResponse struct {
-	Nodes []NodeResponse `json:"nodes"`
-	Count int            `json:"count"`
+	Nodes      []NodeResponse `json:"nodes"`
+	Count      int            `json:"count"`
+	NextCursor string         `json:"next_cursor,omitempty"`
+}
+
+const maxNodePageSize = 1000
+
+// RenameNodeRequest represents the request body for renaming a node. An
+// empty name returns the node to the wonder net's naming policy.
+type RenameNodeRequest struct {
+	Name *string `json:"name"`
 }
 
-// NodesController handles node listing.
+// NodesController handles node listing and renaming.
 type NodesController struct {
-	nodesService *service.NodesService
+	nodesService      *service.NodesService
+	nodeNamingService *service.NodeNamingService
+	nodeWatcher       *service.NodeWatcher
+	draining          <-chan struct{}
 }
 
-// NewNodesController creates a new NodesController.
-func NewNodesController(nodesService *service.NodesService) *NodesController {
+// NewNodesController creates a new NodesController. Node watches end with
+// a "server_restarting" event once draining is closed, when the coordinator
+// shuts down.
+func NewNodesController(nodesService *service.NodesService, nodeNamingService *service.NodeNamingService, nodeWatcher *service.NodeWatcher, draining <-chan struct{}) *NodesController {
 	return &NodesController{
-		nodesService: nodesService,
+		nodesService:      nodesService,
+		nodeNamingService: nodeNamingService,
+		nodeWatcher:       nodeWatcher,
+		draining:          draining,
 	}
 }
 
 // HandleListNodes handles GET /api/v1/nodes requests.
 // This endpoint requires JWT authentication - the wonder net is expected to be
 // set in the request context by the JWT middleware.
+// Optional query parameters: online=all|true|false, last_seen_within=<duration>,
+// healthy=all|true|false, os=<os>, tag=<tag>, and search=<part of the name or
+// hostname>. sort=id|name|last_seen, prefixed with - for descending order,
+// orders the listing, by ID by default; limit (1-1000) pages it, continued
+// with the next_cursor of the previous page as cursor. Without limit, all
+// nodes are returned.
 func (c *NodesController) HandleListNodes(w http.ResponseWriter, r *http.Request) {
 	wonderNet := WonderNetFromContext(r)
 	if wonderNet == nil {
@@ -45,29 +118,365 @@ func (c *NodesController) HandleListNodes(w http.ResponseWriter, r *http.Request
 		return
 	}
 
+	filter, err := parseNodeFilter(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	pageOpts, err := parseNodePage(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	nodes, err := c.nodesService.ListNodes(r.Context(), wonderNet)
 	if err != nil {
 		slog.Error("list nodes", "error", err)
 		http.Error(w, "list nodes", http.StatusInternalServerError)
 		return
 	}
+	page, err := service.PageNodes(service.FilterNodes(nodes, filter), pageOpts)
+	if err != nil {
+		http.Error(w, "invalid cursor", http.StatusBadRequest)
+		return
+	}
 
-	result := make([]NodeResponse, len(nodes))
-	for i, node := range nodes {
-		result[i] = NodeResponse{
-			ID:      node.ID,
-			Name:    node.Name,
-			IPAddrs: node.IPAddrs,
-			Online:  node.Online,
+	response := nodeListResponse(page.Nodes)
+	response.NextCursor = page.NextCursor
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleGetNode handles GET /api/v1/nodes/{id} requests.
+func (c *NodesController) HandleGetNode(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	nodeID := r.PathValue("id")
+	if nodeID == "" {
+		http.Error(w, "node id required", http.StatusBadRequest)
+		return
+	}
+
+	node, err := c.nodesService.GetNode(r.Context(), wonderNet.HeadscaleUser, nodeID)
+	if err != nil {
+		if errors.Is(err, service.ErrNodeNotFound) {
+			http.Error(w, "node not found", http.StatusNotFound)
+			return
 		}
-		if node.LastSeen != nil {
-			result[i].LastSeen = node.LastSeen.Format("2006-01-02T15:04:05Z")
+		slog.Error("get node", "error", err, "wonder_net_id", wonderNet.ID, "node_id", nodeID)
+		http.Error(w, "get node", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(nodeDetailResponse(node))
+}
+
+const (
+	nodeWatchKeepAlive = 15 * time.Second
+	// nodeWatchRestartRetry is how long watchers are asked to wait before
+	// reconnecting to a coordinator that is shutting down.
+	nodeWatchRestartRetry = 5 * time.Second
+)
+
+// ServerRestartingResponse is the data of the "server_restarting" event
+// that ends node watches when the coordinator shuts down.
+type ServerRestartingResponse struct {
+	RetryAfterSeconds int `json:"retry_after_seconds"`
+}
+
+// HandleRenameNode handles PATCH /api/v1/nodes/{id} requests. The new
+// name is set in the mesh and kept by the node naming reconciler. Names
+// must be valid DNS labels and unique within the wonder net.
+func (c *NodesController) HandleRenameNode(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var renamedBy string
+	if claims := jwtauth.ClaimsFromContext(r.Context()); claims != nil {
+		renamedBy = claims.Email
+		if renamedBy == "" {
+			renamedBy = claims.Subject
 		}
+	} else if key := APIKeyFromContext(r); key != nil {
+		renamedBy = "api-key:" + key.Name
+	}
+
+	nodeID := r.PathValue("id")
+	if nodeID == "" {
+		http.Error(w, "node id required", http.StatusBadRequest)
+		return
+	}
+
+	var req RenameNodeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Name == nil {
+		http.Error(w, "name is required", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.nodeNamingService.Rename(r.Context(), wonderNet, nodeID, *req.Name, renamedBy); err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidNodeName):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrNodeNotFound):
+			http.Error(w, "node not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrNodeNameTaken):
+			http.Error(w, err.Error(), http.StatusConflict)
+		default:
+			slog.Error("rename node", "error", err, "wonder_net_id", wonderNet.ID, "node_id", nodeID)
+			http.Error(w, "rename node", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	node, err := c.nodesService.GetNode(r.Context(), wonderNet.HeadscaleUser, nodeID)
+	if err != nil {
+		slog.Error("get renamed node", "error", err, "wonder_net_id", wonderNet.ID, "node_id", nodeID)
+		http.Error(w, "get node", http.StatusInternalServerError)
+		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
-	_ = json.NewEncoder(w).Encode(NodeListResponse{
+	_ = json.NewEncoder(w).Encode(nodeDetailResponse(node))
+}
+
+// HandleWatchNodes handles GET /api/v1/nodes/watch requests.
+// It streams node topology changes as Server-Sent Events. The stream starts
+// with a "snapshot" event holding the full node list, followed by "join",
+// "leave", "online", and "offline" events whose data is the affected node.
+// Changes are detected by the node watcher, which polls the mesh once per
+// wonder net for all streams, so events may lag by several seconds.
+// When the coordinator shuts down, the stream ends with a "server_restarting"
+// event telling the client when to reconnect.
+func (c *NodesController) HandleWatchNodes(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	listings, unsubscribe := c.nodeWatcher.Subscribe(wonderNet.ID)
+	defer unsubscribe()
+
+	nodes, err := c.nodesService.ListNodes(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list nodes", "error", err)
+		http.Error(w, "list nodes", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Disable response buffering in nginx, which fronts the coordinator in
+	// most deployments.
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	rc := http.NewResponseController(w)
+	if err := writeServerSentEvent(w, "snapshot", nodeListResponse(nodes)); err != nil {
+		return
+	}
+	if err := rc.Flush(); err != nil {
+		slog.Error("flush node watch stream", "error", err)
+		return
+	}
+
+	keepAlive := time.NewTicker(nodeWatchKeepAlive)
+	defer keepAlive.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-c.draining:
+			_ = writeServerSentEvent(w, "server_restarting", ServerRestartingResponse{
+				RetryAfterSeconds: int(nodeWatchRestartRetry / time.Second),
+			})
+			_ = rc.Flush()
+			return
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keep-alive\n\n"); err != nil {
+				return
+			}
+		case current := <-listings:
+			for _, event := range service.DiffNodes(nodes, current) {
+				if err := writeServerSentEvent(w, string(event.Type), nodeResponse(event.Node)); err != nil {
+					return
+				}
+			}
+			nodes = current
+		}
+		if err := rc.Flush(); err != nil {
+			return
+		}
+	}
+}
+
+// writeServerSentEvent writes one SSE event with JSON-encoded data.
+func writeServerSentEvent(w http.ResponseWriter, event string, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event, payload)
+	return err
+}
+
+func nodeResponse(node *service.Node) NodeResponse {
+	resp := NodeResponse{
+		ID:               node.ID,
+		Name:             node.Name,
+		Hostname:         node.Hostname,
+		IPAddrs:          node.IPAddrs,
+		IP:               nodeIPResponse(node.IPAddrs),
+		Online:           node.Online,
+		Tags:             node.Tags,
+		OS:               node.OS,
+		TailscaleVersion: node.TailscaleVersion,
+	}
+	if node.LastSeen != nil {
+		resp.LastSeen = node.LastSeen.Format("2006-01-02T15:04:05Z")
+	}
+	if node.CreatedAt != nil {
+		resp.CreatedAt = node.CreatedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	if node.Health != nil {
+		resp.Health = &NodeHealthResponse{
+			Healthy:              node.Health.Healthy,
+			OS:                   node.Health.OS,
+			TailscaleVersion:     node.Health.TailscaleVersion,
+			DiskTotalBytes:       node.Health.DiskTotalBytes,
+			DiskFreeBytes:        node.Health.DiskFreeBytes,
+			MemoryTotalBytes:     node.Health.MemoryTotalBytes,
+			MemoryAvailableBytes: node.Health.MemoryAvailableBytes,
+			ReportedAt:           node.Health.ReportedAt.UTC().Format("2006-01-02T15:04:05Z"),
+		}
+	}
+	return resp
+}
+
+// nodeIPResponse sorts mesh addresses by IP family, keeping the first of
+// each. IPv4-mapped IPv6 addresses count as IPv4.
+func nodeIPResponse(addrs []string) NodeIPResponse {
+	var resp NodeIPResponse
+	for _, addr := range addrs {
+		ip, err := netip.ParseAddr(addr)
+		if err != nil {
+			continue
+		}
+		if ip.Unmap().Is4() {
+			if resp.IPv4 == "" {
+				resp.IPv4 = addr
+			}
+		} else if resp.IPv6 == "" {
+			resp.IPv6 = addr
+		}
+	}
+	return resp
+}
+
+func nodeDetailResponse(node *service.Node) NodeDetailResponse {
+	return NodeDetailResponse{
+		NodeResponse:     nodeResponse(node),
+		AdvertisedRoutes: nonNilStrings(node.AdvertisedRoutes),
+		ApprovedRoutes:   nonNilStrings(node.ApprovedRoutes),
+		PrimaryRoutes:    nonNilStrings(node.PrimaryRoutes),
+		ExitNode:         node.ExitNode,
+	}
+}
+
+// nonNilStrings returns s, or an empty slice if s is nil, so that it encodes
+// as [] rather than null.
+func nonNilStrings(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
+
+func nodeListResponse(nodes []*service.Node) NodeListResponse {
+	result := make([]NodeResponse, len(nodes))
+	for i, node := range nodes {
+		result[i] = nodeResponse(node)
+	}
+	return NodeListResponse{
 		Nodes: result,
 		Count: len(result),
-	})
+	}
+}
+
+// parseNodePage reads the sort, limit, and cursor query parameters. The
+// cursor is the next_cursor of the previous page.
+func parseNodePage(r *http.Request) (service.NodePageOptions, error) {
+	query := r.URL.Query()
+
+	sort, desc, err := service.ParseNodeSort(query.Get("sort"))
+	if err != nil {
+		return service.NodePageOptions{}, err
+	}
+	opts := service.NodePageOptions{
+		Sort:       sort,
+		Descending: desc,
+		Cursor:     query.Get("cursor"),
+	}
+	if v := query.Get("limit"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 1 || n > maxNodePageSize {
+			return opts, fmt.Errorf("limit must be between 1 and %d", maxNodePageSize)
+		}
+		opts.Limit = n
+	}
+	return opts, nil
+}
+
+// parseNodeFilter reads the online, last_seen_within, healthy, os, tag, and
+// search query parameters.
+func parseNodeFilter(r *http.Request) (service.NodeFilter, error) {
+	var filter service.NodeFilter
+	query := r.URL.Query()
+
+	switch online := query.Get("online"); online {
+	case "", "all":
+	case "true", "false":
+		v := online == "true"
+		filter.Online = &v
+	default:
+		return filter, fmt.Errorf("invalid online value %q, want all, true or false", online)
+	}
+
+	if within := query.Get("last_seen_within"); within != "" {
+		d, err := time.ParseDuration(within)
+		if err != nil || d <= 0 {
+			return filter, fmt.Errorf("invalid last_seen_within value %q", within)
+		}
+		filter.LastSeenWithin = d
+	}
+
+	switch healthy := query.Get("healthy"); healthy {
+	case "", "all":
+	case "true", "false":
+		v := healthy == "true"
+		filter.Healthy = &v
+	default:
+		return filter, fmt.Errorf("invalid healthy value %q, want all, true or false", healthy)
+	}
+
+	filter.OS = query.Get("os")
+	filter.Tag = query.Get("tag")
+	filter.Search = query.Get("search")
+
+	return filter, nil
 }
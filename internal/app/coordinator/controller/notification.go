@@ -0,0 +1,166 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// NotificationController handles notification channel endpoints.
+type NotificationController struct {
+	notificationService *service.NotificationService
+}
+
+// NewNotificationController creates a new NotificationController.
+func NewNotificationController(notificationService *service.NotificationService) *NotificationController {
+	return &NotificationController{
+		notificationService: notificationService,
+	}
+}
+
+// CreateNotificationChannelRequest is the request body for creating a
+// notification channel. Target is a comma-separated list of email addresses
+// for email channels, or the incoming webhook URL of Slack channels. Digest
+// is a duration such as "15m"; empty uses the default and "0s" sends each
+// notification on its own. An empty events list subscribes to every event.
+type CreateNotificationChannelRequest struct {
+	Kind   string   `json:"kind"`
+	Target string   `json:"target"`
+	Events []string `json:"events,omitempty"`
+	Digest string   `json:"digest,omitempty"`
+}
+
+// NotificationChannelResponse represents a notification channel in JSON
+// responses. The target of Slack channels, a secret URL, is left out.
+type NotificationChannelResponse struct {
+	ID        string    `json:"id"`
+	Kind      string    `json:"kind"`
+	Target    string    `json:"target,omitempty"`
+	Events    []string  `json:"events"`
+	Digest    string    `json:"digest"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandleCreate handles POST /api/v1/notification-channels requests.
+func (c *NotificationController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateNotificationChannelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	digest := service.DefaultNotificationDigest
+	if req.Digest != "" {
+		var err error
+		if digest, err = time.ParseDuration(req.Digest); err != nil {
+			http.Error(w, "invalid digest duration", http.StatusBadRequest)
+			return
+		}
+	}
+
+	createdBy := claims.Email
+	if createdBy == "" {
+		createdBy = claims.Subject
+	}
+
+	channel, err := c.notificationService.CreateChannel(r.Context(), wonderNet.ID, req.Kind, req.Target, req.Events, digest, createdBy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidNotificationChannel) ||
+			errors.Is(err, service.ErrUnsupportedNotificationEvent) ||
+			errors.Is(err, service.ErrEmailNotConfigured) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("create notification channel", "error", err)
+		http.Error(w, "create notification channel", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(notificationChannelResponse(channel))
+}
+
+// HandleList handles GET /api/v1/notification-channels requests.
+func (c *NotificationController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	channels, err := c.notificationService.ListChannels(r.Context(), wonderNet.ID)
+	if err != nil {
+		slog.Error("list notification channels", "error", err)
+		http.Error(w, "list notification channels", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]NotificationChannelResponse, len(channels))
+	for i, channel := range channels {
+		response[i] = notificationChannelResponse(channel)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDelete handles DELETE /api/v1/notification-channels/{id} requests.
+func (c *NotificationController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	channelID := r.PathValue("id")
+	if channelID == "" {
+		http.Error(w, "missing notification channel id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.notificationService.DeleteChannel(r.Context(), wonderNet.ID, channelID); err != nil {
+		if errors.Is(err, service.ErrNotificationChannelNotFound) {
+			http.Error(w, "notification channel not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete notification channel", "error", err)
+		http.Error(w, "delete notification channel", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func notificationChannelResponse(channel *repository.NotificationChannel) NotificationChannelResponse {
+	events := channel.Events
+	if len(events) == 0 {
+		events = service.NotificationEvents
+	}
+	response := NotificationChannelResponse{
+		ID:        channel.ID,
+		Kind:      channel.Kind,
+		Events:    events,
+		Digest:    channel.Digest.String(),
+		CreatedBy: channel.CreatedBy,
+		CreatedAt: channel.CreatedAt,
+	}
+	if channel.Kind == repository.NotificationChannelEmail {
+		response.Target = channel.Target
+	}
+	return response
+}
@@ -91,22 +91,23 @@ func (c *OIDCController) HandleCallback(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	if err := c.oidcService.ValidateState(state); err != nil {
+	codeVerifier, err := c.oidcService.ValidateState(state)
+	if err != nil {
 		slog.Warn("OIDC state validation failed", "error", err)
 		http.Error(w, "invalid or expired state", http.StatusBadRequest)
 		return
 	}
 
-	tokenResp, err := c.oidcService.ExchangeCode(r.Context(), code)
+	tokenResp, err := c.oidcService.ExchangeCode(r.Context(), code, codeVerifier)
 	if err != nil {
 		slog.Error("OIDC token exchange", "error", err)
 		http.Error(w, "token exchange failed", http.StatusInternalServerError)
 		return
 	}
 
-	claims, err := c.oidcService.ValidateIDToken(tokenResp.IDToken)
+	claims, err := c.oidcService.UserClaims(r.Context(), tokenResp)
 	if err != nil {
-		slog.Error("OIDC ID token validation", "error", err)
+		slog.Error("OIDC user claims", "error", err)
 		http.Error(w, "invalid ID token", http.StatusInternalServerError)
 		return
 	}
@@ -117,7 +118,7 @@ func (c *OIDCController) HandleCallback(w http.ResponseWriter, r *http.Request)
 		"email", claims.Email,
 	)
 
-	_, err = c.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims)
+	_, err = c.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims, "")
 	if err != nil {
 		slog.Error("resolve wonder net", "error", err)
 		http.Error(w, "internal error", http.StatusInternalServerError)
@@ -5,6 +5,7 @@ import (
 	"net/http"
 	"net/http/httputil"
 	"net/url"
+	"time"
 )
 
 // HeadscaleProxyController forwards requests to the embedded Headscale instance.
@@ -20,6 +21,15 @@ func NewHeadscaleProxyController(targetURL string) (*HeadscaleProxyController, e
 	}
 
 	proxy := httputil.NewSingleHostReverseProxy(target)
+	// Every Tailscale client long-polls Headscale through this proxy, so the
+	// default of two idle connections per host causes constant reconnects.
+	proxy.Transport = &http.Transport{
+		Proxy:                 http.ProxyFromEnvironment,
+		MaxIdleConns:          256,
+		MaxIdleConnsPerHost:   256,
+		IdleConnTimeout:       90 * time.Second,
+		ExpectContinueTimeout: time.Second,
+	}
 	originalDirector := proxy.Director
 	proxy.Director = func(req *http.Request) {
 		originalDirector(req)
@@ -0,0 +1,117 @@
+package controller
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// RoutesController handles subnet and exit route endpoints.
+type RoutesController struct {
+	routesService *service.RoutesService
+}
+
+// NewRoutesController creates a new RoutesController.
+func NewRoutesController(routesService *service.RoutesService) *RoutesController {
+	return &RoutesController{
+		routesService: routesService,
+	}
+}
+
+// RouteResponse represents an advertised route in JSON responses.
+type RouteResponse struct {
+	ID       string `json:"id"`
+	NodeID   uint64 `json:"node_id"`
+	NodeName string `json:"node_name"`
+	Prefix   string `json:"prefix"`
+	ExitNode bool   `json:"exit_node"`
+	Approved bool   `json:"approved"`
+	Serving  bool   `json:"serving"`
+}
+
+// HandleList handles GET /api/v1/routes requests.
+func (c *RoutesController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	routes, err := c.routesService.ListRoutes(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list routes", "error", err)
+		http.Error(w, "list routes", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]RouteResponse, len(routes))
+	for i, route := range routes {
+		response[i] = routeResponse(route)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleApprove handles POST /api/v1/routes/{id}/approve requests.
+func (c *RoutesController) HandleApprove(w http.ResponseWriter, r *http.Request) {
+	c.handleUpdate(w, r, "approve route", c.routesService.ApproveRoute)
+}
+
+// HandleReject handles POST /api/v1/routes/{id}/reject requests.
+func (c *RoutesController) HandleReject(w http.ResponseWriter, r *http.Request) {
+	c.handleUpdate(w, r, "reject route", c.routesService.RejectRoute)
+}
+
+func (c *RoutesController) handleUpdate(
+	w http.ResponseWriter,
+	r *http.Request,
+	action string,
+	update func(ctx context.Context, wonderNet *repository.WonderNet, routeID string) (*service.Route, error),
+) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	routeID := r.PathValue("id")
+	if routeID == "" {
+		http.Error(w, "missing route id", http.StatusBadRequest)
+		return
+	}
+
+	route, err := update(r.Context(), wonderNet, routeID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidRouteID):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrRouteNotFound):
+			http.Error(w, "route not found", http.StatusNotFound)
+		default:
+			slog.Error(action, "id", routeID, "error", err)
+			http.Error(w, action, http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(routeResponse(route))
+}
+
+func routeResponse(route *service.Route) RouteResponse {
+	return RouteResponse{
+		ID:       route.ID,
+		NodeID:   route.NodeID,
+		NodeName: route.NodeName,
+		Prefix:   route.Prefix,
+		ExitNode: route.ExitNode,
+		Approved: route.Approved,
+		Serving:  route.Serving,
+	}
+}
@@ -0,0 +1,241 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// ServicesController handles service publishing and access grant endpoints.
+type ServicesController struct {
+	serviceCatalogService *service.ServiceCatalogService
+}
+
+// NewServicesController creates a new ServicesController.
+func NewServicesController(serviceCatalogService *service.ServiceCatalogService) *ServicesController {
+	return &ServicesController{
+		serviceCatalogService: serviceCatalogService,
+	}
+}
+
+// CreateServiceRequest is the request body for publishing a service.
+type CreateServiceRequest struct {
+	Name     string `json:"name"`
+	NodeID   string `json:"node_id"`
+	Port     int    `json:"port"`
+	Protocol string `json:"protocol,omitempty"`
+}
+
+// CreateServiceGrantRequest is the request body for granting access to a
+// service. Subject is "*", "tag:<name>", or "node:<id>".
+type CreateServiceGrantRequest struct {
+	Subject string `json:"subject"`
+}
+
+// ServiceResponse represents a service in JSON responses.
+type ServiceResponse struct {
+	ID        string                 `json:"id"`
+	Name      string                 `json:"name"`
+	NodeID    string                 `json:"node_id"`
+	Port      int                    `json:"port"`
+	Protocol  string                 `json:"protocol"`
+	Grants    []ServiceGrantResponse `json:"grants"`
+	CreatedAt time.Time              `json:"created_at"`
+}
+
+// ServiceGrantResponse represents a service grant in JSON responses.
+type ServiceGrantResponse struct {
+	ID        string    `json:"id"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// HandleCreate handles POST /api/v1/services requests.
+func (c *ServicesController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateServiceRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	svc, err := c.serviceCatalogService.CreateService(r.Context(), wonderNet, req.Name, req.NodeID, req.Port, req.Protocol)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidService) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrServiceNameTaken) {
+			http.Error(w, "service name already in use", http.StatusConflict)
+			return
+		}
+		if errors.Is(err, service.ErrUnsupportedByMeshBackend) {
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+			return
+		}
+		slog.Error("create service", "error", err)
+		http.Error(w, "create service", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(serviceResponse(svc, nil))
+}
+
+// HandleList handles GET /api/v1/services requests.
+func (c *ServicesController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	services, err := c.serviceCatalogService.ListServices(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("list services", "error", err)
+		http.Error(w, "list services", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]ServiceResponse, len(services))
+	for i, svc := range services {
+		response[i] = serviceResponse(svc.Service, svc.Grants)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDelete handles DELETE /api/v1/services/{id} requests.
+func (c *ServicesController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	serviceID := r.PathValue("id")
+	if serviceID == "" {
+		http.Error(w, "missing service id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.serviceCatalogService.DeleteService(r.Context(), wonderNet, serviceID); err != nil {
+		if errors.Is(err, service.ErrServiceNotFound) {
+			http.Error(w, "service not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete service", "error", err)
+		http.Error(w, "delete service", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleCreateGrant handles POST /api/v1/services/{id}/grants requests.
+func (c *ServicesController) HandleCreateGrant(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	serviceID := r.PathValue("id")
+	if serviceID == "" {
+		http.Error(w, "missing service id", http.StatusBadRequest)
+		return
+	}
+
+	var req CreateServiceGrantRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	grant, err := c.serviceCatalogService.GrantAccess(r.Context(), wonderNet, serviceID, req.Subject)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrServiceNotFound):
+			http.Error(w, "service not found", http.StatusNotFound)
+		case errors.Is(err, service.ErrInvalidService):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+		case errors.Is(err, service.ErrServiceGrantExists):
+			http.Error(w, "service grant already exists", http.StatusConflict)
+		case errors.Is(err, service.ErrUnsupportedByMeshBackend):
+			http.Error(w, err.Error(), http.StatusNotImplemented)
+		default:
+			slog.Error("create service grant", "error", err)
+			http.Error(w, "create service grant", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(serviceGrantResponse(grant))
+}
+
+// HandleDeleteGrant handles DELETE /api/v1/services/{id}/grants/{grant_id}
+// requests.
+func (c *ServicesController) HandleDeleteGrant(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	serviceID := r.PathValue("id")
+	grantID := r.PathValue("grant_id")
+	if serviceID == "" || grantID == "" {
+		http.Error(w, "missing service or grant id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.serviceCatalogService.RevokeAccess(r.Context(), wonderNet, serviceID, grantID); err != nil {
+		if errors.Is(err, service.ErrServiceNotFound) || errors.Is(err, service.ErrServiceGrantNotFound) {
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		}
+		slog.Error("delete service grant", "error", err)
+		http.Error(w, "delete service grant", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func serviceResponse(svc *repository.Service, grants []*repository.ServiceGrant) ServiceResponse {
+	response := ServiceResponse{
+		ID:        svc.ID,
+		Name:      svc.Name,
+		NodeID:    svc.NodeID,
+		Port:      svc.Port,
+		Protocol:  svc.Protocol,
+		Grants:    make([]ServiceGrantResponse, len(grants)),
+		CreatedAt: svc.CreatedAt,
+	}
+	for i, grant := range grants {
+		response.Grants[i] = serviceGrantResponse(grant)
+	}
+	return response
+}
+
+func serviceGrantResponse(grant *repository.ServiceGrant) ServiceGrantResponse {
+	return ServiceGrantResponse{
+		ID:        grant.ID,
+		Subject:   grant.Subject,
+		CreatedAt: grant.CreatedAt,
+	}
+}
@@ -0,0 +1,135 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// SSHCAController handles the SSH certificate authority endpoints.
+type SSHCAController struct {
+	sshCAService *service.SSHCAService
+}
+
+// NewSSHCAController creates a new SSHCAController.
+func NewSSHCAController(sshCAService *service.SSHCAService) *SSHCAController {
+	return &SSHCAController{sshCAService: sshCAService}
+}
+
+// SSHCAResponse is the SSH CA key of a wonder net, as an authorized_keys
+// line for sshd's TrustedUserCAKeys.
+type SSHCAResponse struct {
+	PublicKey string `json:"public_key"`
+}
+
+// SignSSHCertRequest asks for a certificate of PublicKey, an
+// authorized_keys line, to log in as one of Principals. TTL is a duration
+// up to 24h, 1h by default.
+type SignSSHCertRequest struct {
+	PublicKey  string   `json:"public_key"`
+	Principals []string `json:"principals"`
+	TTL        string   `json:"ttl,omitempty"`
+}
+
+// SignSSHCertResponse is a signed SSH user certificate. Certificate is in
+// the format of id_ed25519-cert.pub files.
+type SignSSHCertResponse struct {
+	Certificate string    `json:"certificate"`
+	Serial      uint64    `json:"serial"`
+	KeyID       string    `json:"key_id"`
+	Principals  []string  `json:"principals"`
+	ValidAfter  time.Time `json:"valid_after"`
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// HandleGetCA handles GET /api/v1/ssh-ca requests.
+func (c *SSHCAController) HandleGetCA(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	publicKey, err := c.sshCAService.PublicKey(wonderNet.ID)
+	if err != nil {
+		slog.Error("get ssh ca key", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "get ssh ca key", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(SSHCAResponse{PublicKey: publicKey})
+}
+
+// HandleSignCert handles POST /api/v1/ssh-cert requests.
+func (c *SSHCAController) HandleSignCert(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req SignSSHCertRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	var ttl time.Duration
+	if req.TTL != "" {
+		d, err := time.ParseDuration(req.TTL)
+		if err != nil {
+			http.Error(w, "ttl must be a duration such as 1h", http.StatusBadRequest)
+			return
+		}
+		ttl = d
+	}
+
+	var keyID string
+	if claims := jwtauth.ClaimsFromContext(r.Context()); claims != nil {
+		keyID = claims.Email
+		if keyID == "" {
+			keyID = claims.Subject
+		}
+	} else if key := APIKeyFromContext(r); key != nil {
+		keyID = "api-key:" + key.Name
+	}
+
+	cert, err := c.sshCAService.SignUserCertificate(wonderNet, service.SSHCertRequest{
+		PublicKey:  req.PublicKey,
+		Principals: req.Principals,
+		TTL:        ttl,
+	}, keyID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidSSHCertRequest) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("sign ssh certificate", "error", err, "wonder_net_id", wonderNet.ID)
+		http.Error(w, "sign ssh certificate", http.StatusInternalServerError)
+		return
+	}
+	slog.Info("signed ssh certificate",
+		"wonder_net_id", wonderNet.ID,
+		"key_id", cert.KeyID,
+		"serial", cert.Serial,
+		"principals", cert.Principals,
+		"valid_before", cert.ValidBefore,
+		"client_ip", ClientIPFromContext(r),
+	)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(SignSSHCertResponse{
+		Certificate: cert.Certificate,
+		Serial:      cert.Serial,
+		KeyID:       cert.KeyID,
+		Principals:  cert.Principals,
+		ValidAfter:  cert.ValidAfter,
+		ValidBefore: cert.ValidBefore,
+	})
+}
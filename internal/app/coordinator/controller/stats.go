@@ -0,0 +1,65 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// StatsController handles the wonder net summary endpoint.
+type StatsController struct {
+	statsService *service.StatsService
+}
+
+// NewStatsController creates a new StatsController.
+func NewStatsController(statsService *service.StatsService) *StatsController {
+	return &StatsController{
+		statsService: statsService,
+	}
+}
+
+// StatsResponse holds aggregate counts for a wonder net. RecentNodes counts
+// nodes that are online or were seen in the last 24 hours; StaleAPIKeys
+// counts unexpired API keys unused for 90 days.
+type StatsResponse struct {
+	Nodes                 int `json:"nodes"`
+	OnlineNodes           int `json:"online_nodes"`
+	RecentNodes           int `json:"recent_nodes"`
+	APIKeys               int `json:"api_keys"`
+	StaleAPIKeys          int `json:"stale_api_keys"`
+	Services              int `json:"services"`
+	PendingAccessRequests int `json:"pending_access_requests"`
+	ActiveAccessRequests  int `json:"active_access_requests"`
+	FiringAlerts          int `json:"firing_alerts"`
+}
+
+// HandleGet handles GET /api/v1/stats requests.
+func (c *StatsController) HandleGet(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	stats, err := c.statsService.GetStats(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("get stats", "wonder_net_id", wonderNet.ID, "error", err)
+		http.Error(w, "get stats", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(StatsResponse{
+		Nodes:                 stats.Nodes,
+		OnlineNodes:           stats.OnlineNodes,
+		RecentNodes:           stats.RecentNodes,
+		APIKeys:               stats.APIKeys,
+		StaleAPIKeys:          stats.StaleAPIKeys,
+		Services:              stats.Services,
+		PendingAccessRequests: stats.PendingAccessRequests,
+		ActiveAccessRequests:  stats.ActiveAccessRequests,
+		FiringAlerts:          stats.FiringAlerts,
+	})
+}
@@ -0,0 +1,90 @@
+package controller
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+// NodeTopologyResponse is a node with the connectivity its worker last
+// reported: its home DERP region, its latency to each DERP region in
+// milliseconds keyed by region ID, and how it reaches the other nodes.
+// RelayBound is set when the node reaches none of its peers directly.
+// ReportedAt is omitted for nodes without a report.
+type NodeTopologyResponse struct {
+	ID            uint64                 `json:"id"`
+	Name          string                 `json:"name"`
+	Online        bool                   `json:"online"`
+	DERPRegion    string                 `json:"derp_region,omitempty"`
+	DERPLatencyMs map[string]float64     `json:"derp_latency_ms,omitempty"`
+	Peers         []TopologyPeerResponse `json:"peers"`
+	RelayBound    bool                   `json:"relay_bound"`
+	ReportedAt    string                 `json:"reported_at,omitempty"`
+}
+
+// TopologyPeerResponse is the path from a node to a peer. Endpoint is the
+// peer's UDP address on direct paths; Relay is the DERP region of relayed
+// ones.
+type TopologyPeerResponse struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"name"`
+	Direct   bool   `json:"direct"`
+	Relay    string `json:"relay,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// TopologyResponse is the connectivity map of a wonder net.
+type TopologyResponse struct {
+	Nodes []NodeTopologyResponse `json:"nodes"`
+}
+
+// HandleTopology handles GET /api/v1/nodes/topology requests.
+func (c *NodesController) HandleTopology(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	topology, err := c.nodesService.Topology(r.Context(), wonderNet)
+	if err != nil {
+		slog.Error("get node topology", "error", err)
+		http.Error(w, "get node topology", http.StatusInternalServerError)
+		return
+	}
+
+	response := TopologyResponse{Nodes: make([]NodeTopologyResponse, len(topology))}
+	for i, node := range topology {
+		response.Nodes[i] = nodeTopologyResponse(node)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func nodeTopologyResponse(node *service.NodeTopology) NodeTopologyResponse {
+	resp := NodeTopologyResponse{
+		ID:            node.ID,
+		Name:          node.Name,
+		Online:        node.Online,
+		DERPRegion:    node.DERPRegion,
+		DERPLatencyMs: node.DERPLatencyMs,
+		Peers:         make([]TopologyPeerResponse, len(node.Peers)),
+		RelayBound:    node.RelayBound,
+	}
+	for i, peer := range node.Peers {
+		resp.Peers[i] = TopologyPeerResponse{
+			ID:       peer.ID,
+			Name:     peer.Name,
+			Direct:   peer.Direct,
+			Relay:    peer.Relay,
+			Endpoint: peer.Endpoint,
+		}
+	}
+	if node.ReportedAt != nil {
+		resp.ReportedAt = node.ReportedAt.UTC().Format("2006-01-02T15:04:05Z")
+	}
+	return resp
+}
@@ -0,0 +1,206 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// WebhookController handles webhook and webhook delivery endpoints.
+type WebhookController struct {
+	webhookService *service.WebhookService
+}
+
+// NewWebhookController creates a new WebhookController.
+func NewWebhookController(webhookService *service.WebhookService) *WebhookController {
+	return &WebhookController{
+		webhookService: webhookService,
+	}
+}
+
+// CreateWebhookRequest is the request body for creating a webhook. An empty
+// events list subscribes to every event.
+type CreateWebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events,omitempty"`
+}
+
+// WebhookResponse represents a webhook in JSON responses. Secret is only
+// set in the response to creating the webhook.
+type WebhookResponse struct {
+	ID        string    `json:"id"`
+	URL       string    `json:"url"`
+	Events    []string  `json:"events"`
+	Secret    string    `json:"secret,omitempty"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// WebhookDeliveryResponse represents a webhook delivery in JSON responses.
+type WebhookDeliveryResponse struct {
+	ID             string     `json:"id"`
+	Event          string     `json:"event"`
+	Status         string     `json:"status"`
+	Attempts       int        `json:"attempts"`
+	ResponseStatus int        `json:"response_status,omitempty"`
+	LastError      string     `json:"last_error,omitempty"`
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty"`
+	CreatedAt      time.Time  `json:"created_at"`
+	DeliveredAt    *time.Time `json:"delivered_at,omitempty"`
+}
+
+// HandleCreate handles POST /api/v1/webhooks requests.
+func (c *WebhookController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateWebhookRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	createdBy := claims.Email
+	if createdBy == "" {
+		createdBy = claims.Subject
+	}
+
+	webhook, err := c.webhookService.CreateWebhook(r.Context(), wonderNet.ID, req.URL, req.Events, createdBy)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidWebhookURL) || errors.Is(err, service.ErrUnsupportedWebhookEvent) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		slog.Error("create webhook", "error", err)
+		http.Error(w, "create webhook", http.StatusInternalServerError)
+		return
+	}
+
+	response := webhookResponse(webhook)
+	response.Secret = webhook.Secret
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleList handles GET /api/v1/webhooks requests.
+func (c *WebhookController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	webhooks, err := c.webhookService.ListWebhooks(r.Context(), wonderNet.ID)
+	if err != nil {
+		slog.Error("list webhooks", "error", err)
+		http.Error(w, "list webhooks", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]WebhookResponse, len(webhooks))
+	for i, webhook := range webhooks {
+		response[i] = webhookResponse(webhook)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleDelete handles DELETE /api/v1/webhooks/{id} requests.
+func (c *WebhookController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID := r.PathValue("id")
+	if webhookID == "" {
+		http.Error(w, "missing webhook id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.webhookService.DeleteWebhook(r.Context(), wonderNet.ID, webhookID); err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			http.Error(w, "webhook not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("delete webhook", "error", err)
+		http.Error(w, "delete webhook", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// HandleListDeliveries handles GET /api/v1/webhooks/{id}/deliveries requests.
+func (c *WebhookController) HandleListDeliveries(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	if wonderNet == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	webhookID := r.PathValue("id")
+	if webhookID == "" {
+		http.Error(w, "missing webhook id", http.StatusBadRequest)
+		return
+	}
+
+	deliveries, err := c.webhookService.ListDeliveries(r.Context(), wonderNet.ID, webhookID)
+	if err != nil {
+		if errors.Is(err, service.ErrWebhookNotFound) {
+			http.Error(w, "webhook not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("list webhook deliveries", "error", err)
+		http.Error(w, "list webhook deliveries", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]WebhookDeliveryResponse, len(deliveries))
+	for i, delivery := range deliveries {
+		response[i] = WebhookDeliveryResponse{
+			ID:             delivery.ID,
+			Event:          delivery.Event,
+			Status:         delivery.Status,
+			Attempts:       delivery.Attempts,
+			ResponseStatus: delivery.ResponseStatus,
+			LastError:      delivery.LastError,
+			CreatedAt:      delivery.CreatedAt,
+			DeliveredAt:    delivery.DeliveredAt,
+		}
+		if delivery.Status == repository.WebhookDeliveryPending {
+			response[i].NextAttemptAt = &delivery.NextAttemptAt
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+func webhookResponse(webhook *repository.Webhook) WebhookResponse {
+	events := webhook.Events
+	if len(events) == 0 {
+		events = service.WebhookEvents
+	}
+	return WebhookResponse{
+		ID:        webhook.ID,
+		URL:       webhook.URL,
+		Events:    events,
+		CreatedBy: webhook.CreatedBy,
+		CreatedAt: webhook.CreatedAt,
+	}
+}
@@ -0,0 +1,353 @@
+package controller
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// WonderNetController handles endpoints for managing the caller's own wonder
+// nets. These always act on the wonder nets the caller owns, even when the
+// request selects a wonder net shared with the caller.
+type WonderNetController struct {
+	wonderNetService    *service.WonderNetService
+	nodeNamingService   *service.NodeNamingService
+	nodeApprovalService *service.NodeApprovalService
+	nodeExpiryService   *service.NodeExpiryService
+}
+
+// NewWonderNetController creates a new WonderNetController.
+func NewWonderNetController(wonderNetService *service.WonderNetService, nodeNamingService *service.NodeNamingService, nodeApprovalService *service.NodeApprovalService, nodeExpiryService *service.NodeExpiryService) *WonderNetController {
+	return &WonderNetController{
+		wonderNetService:    wonderNetService,
+		nodeNamingService:   nodeNamingService,
+		nodeApprovalService: nodeApprovalService,
+		nodeExpiryService:   nodeExpiryService,
+	}
+}
+
+// CreateWonderNetRequest is the request body for creating a wonder net.
+type CreateWonderNetRequest struct {
+	DisplayName string `json:"display_name"`
+	Default     bool   `json:"default,omitempty"`
+}
+
+// UpdateWonderNetRequest is the request body for updating a wonder net's
+// settings. Fields left out are not changed.
+type UpdateWonderNetRequest struct {
+	NodeNameTemplate    *string `json:"node_name_template"`
+	RequireNodeApproval *bool   `json:"require_node_approval"`
+	NodeExpiryDays      *int    `json:"node_expiry_days"`
+	// JoinTokenDefaultTTL and JoinTokenMaxTTL are durations such as 72h;
+	// an empty string resets them to the coordinator's.
+	JoinTokenDefaultTTL *string `json:"join_token_default_ttl"`
+	JoinTokenMaxTTL     *string `json:"join_token_max_ttl"`
+}
+
+// UserWonderNetResponse represents one of the caller's wonder nets in JSON
+// responses. Role is owner for the caller's own wonder nets, and the
+// caller's member role for wonder nets shared with them.
+// JoinTokenDefaultTTL and JoinTokenMaxTTL are the join token policy in
+// effect, including the coordinator's defaults.
+type UserWonderNetResponse struct {
+	ID                  string    `json:"id"`
+	DisplayName         string    `json:"display_name"`
+	MeshType            string    `json:"mesh_type"`
+	IsDefault           bool      `json:"is_default"`
+	NodeNameTemplate    string    `json:"node_name_template,omitempty"`
+	PublicURL           string    `json:"public_url,omitempty"`
+	RequireNodeApproval bool      `json:"require_node_approval,omitempty"`
+	NodeExpiryDays      int       `json:"node_expiry_days,omitempty"`
+	JoinTokenDefaultTTL string    `json:"join_token_default_ttl"`
+	JoinTokenMaxTTL     string    `json:"join_token_max_ttl"`
+	Role                string    `json:"role"`
+	CreatedAt           time.Time `json:"created_at"`
+}
+
+// HandleList handles GET /api/v1/wonder-nets requests. The caller's own
+// wonder nets are followed by the ones shared with the caller.
+func (c *WonderNetController) HandleList(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	wonderNets, err := c.wonderNetService.ListWonderNetsByOwner(r.Context(), claims.Subject)
+	if err != nil {
+		slog.Error("list wonder nets", "error", err)
+		http.Error(w, "list wonder nets", http.StatusInternalServerError)
+		return
+	}
+
+	shared, err := c.wonderNetService.ListSharedWonderNets(r.Context(), claims.Subject)
+	if err != nil {
+		slog.Error("list shared wonder nets", "error", err)
+		http.Error(w, "list wonder nets", http.StatusInternalServerError)
+		return
+	}
+
+	response := make([]UserWonderNetResponse, 0, len(wonderNets)+len(shared))
+	for _, wn := range wonderNets {
+		response = append(response, userWonderNetResponse(wn))
+	}
+	for _, sw := range shared {
+		resp := userWonderNetResponse(sw.WonderNet)
+		// The default flag is the owner's choice, not the member's.
+		resp.IsDefault = false
+		resp.Role = sw.Role
+		response = append(response, resp)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// HandleCreate handles POST /api/v1/wonder-nets requests.
+func (c *WonderNetController) HandleCreate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	var req CreateWonderNetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.DisplayName == "" {
+		http.Error(w, "display_name is required", http.StatusBadRequest)
+		return
+	}
+
+	created, err := c.wonderNetService.CreateWonderNet(r.Context(), claims.Subject, req.DisplayName, req.Default)
+	if err != nil {
+		if errors.Is(err, service.ErrWonderNetNameTaken) {
+			http.Error(w, "wonder net name already in use", http.StatusConflict)
+			return
+		}
+		slog.Error("create wonder net", "error", err)
+		http.Error(w, "create wonder net", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	_ = json.NewEncoder(w).Encode(userWonderNetResponse(created))
+}
+
+// HandleUpdate handles PATCH /api/v1/wonder-nets/{id} requests.
+// Setting node_name_template renames existing nodes right away; nodes that
+// join later are renamed by the background reconciler. Turning
+// require_node_approval on keeps the existing nodes active and holds nodes
+// that register afterwards until they are approved; turning it off approves
+// every pending node. Setting node_expiry_days to a number of days removes
+// nodes once they have been offline for longer; zero keeps them.
+// join_token_default_ttl and join_token_max_ttl set how long join tokens
+// are valid by default and at most; longer ttls are rejected.
+func (c *WonderNetController) HandleUpdate(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	wonderNetID := r.PathValue("id")
+	if wonderNetID == "" {
+		http.Error(w, "missing wonder net id", http.StatusBadRequest)
+		return
+	}
+
+	var req UpdateWonderNetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+
+	if req.NodeNameTemplate == nil && req.RequireNodeApproval == nil && req.NodeExpiryDays == nil &&
+		req.JoinTokenDefaultTTL == nil && req.JoinTokenMaxTTL == nil {
+		http.Error(w, "node_name_template, require_node_approval, node_expiry_days, join_token_default_ttl, or join_token_max_ttl is required", http.StatusBadRequest)
+		return
+	}
+
+	defaultTTL, err := parseOptionalTTL(req.JoinTokenDefaultTTL)
+	if err != nil {
+		http.Error(w, "invalid join_token_default_ttl, want a duration such as 8h", http.StatusBadRequest)
+		return
+	}
+	maxTTL, err := parseOptionalTTL(req.JoinTokenMaxTTL)
+	if err != nil {
+		http.Error(w, "invalid join_token_max_ttl, want a duration such as 72h", http.StatusBadRequest)
+		return
+	}
+
+	var updated *repository.WonderNet
+	if req.NodeNameTemplate != nil {
+		var err error
+		updated, err = c.wonderNetService.SetNodeNameTemplate(r.Context(), claims.Subject, wonderNetID, *req.NodeNameTemplate)
+		if err != nil {
+			writeUpdateWonderNetError(w, err)
+			return
+		}
+
+		if err := c.nodeNamingService.Apply(r.Context(), updated); err != nil {
+			slog.Warn("apply node name template", "wonder_net_id", updated.ID, "error", err)
+		}
+	}
+
+	if req.RequireNodeApproval != nil {
+		decidedBy := claims.Email
+		if decidedBy == "" {
+			decidedBy = claims.Subject
+		}
+
+		var err error
+		updated, err = c.nodeApprovalService.SetRequired(r.Context(), claims.Subject, wonderNetID, *req.RequireNodeApproval, decidedBy)
+		if err != nil {
+			writeUpdateWonderNetError(w, err)
+			return
+		}
+	}
+
+	if req.NodeExpiryDays != nil {
+		var err error
+		updated, err = c.nodeExpiryService.SetExpiryDays(r.Context(), claims.Subject, wonderNetID, *req.NodeExpiryDays)
+		if err != nil {
+			writeUpdateWonderNetError(w, err)
+			return
+		}
+	}
+
+	if defaultTTL != nil || maxTTL != nil {
+		var err error
+		updated, err = c.wonderNetService.SetJoinTokenTTLPolicy(r.Context(), claims.Subject, wonderNetID, defaultTTL, maxTTL)
+		if err != nil {
+			writeUpdateWonderNetError(w, err)
+			return
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(userWonderNetResponse(updated))
+}
+
+// parseOptionalTTL parses a join token TTL of an update request. The empty
+// string is zero.
+func parseOptionalTTL(v *string) (*time.Duration, error) {
+	if v == nil {
+		return nil, nil
+	}
+	var ttl time.Duration
+	if *v != "" {
+		d, err := time.ParseDuration(*v)
+		if err != nil || d < 0 {
+			return nil, errors.New("invalid duration")
+		}
+		ttl = d
+	}
+	return &ttl, nil
+}
+
+func writeUpdateWonderNetError(w http.ResponseWriter, err error) {
+	switch {
+	case errors.Is(err, service.ErrInvalidNodeNameTemplate), errors.Is(err, service.ErrInvalidNodeExpiry),
+		errors.Is(err, service.ErrInvalidJoinTokenTTL):
+		http.Error(w, err.Error(), http.StatusBadRequest)
+	case errors.Is(err, service.ErrNoWonderNet):
+		http.Error(w, "wonder net not found", http.StatusNotFound)
+	default:
+		slog.Error("update wonder net", "error", err)
+		http.Error(w, "update wonder net", http.StatusInternalServerError)
+	}
+}
+
+// HandleSetDefault handles PUT /api/v1/wonder-nets/{id}/default requests.
+func (c *WonderNetController) HandleSetDefault(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	wonderNetID := r.PathValue("id")
+	if wonderNetID == "" {
+		http.Error(w, "missing wonder net id", http.StatusBadRequest)
+		return
+	}
+
+	updated, err := c.wonderNetService.SetDefaultWonderNet(r.Context(), claims.Subject, wonderNetID)
+	if err != nil {
+		if errors.Is(err, service.ErrNoWonderNet) {
+			http.Error(w, "wonder net not found", http.StatusNotFound)
+			return
+		}
+		slog.Error("set default wonder net", "error", err)
+		http.Error(w, "set default wonder net", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(userWonderNetResponse(updated))
+}
+
+// HandleDelete handles DELETE /api/v1/wonder-nets/{id} requests.
+func (c *WonderNetController) HandleDelete(w http.ResponseWriter, r *http.Request) {
+	wonderNet := WonderNetFromContext(r)
+	claims := jwtauth.ClaimsFromContext(r.Context())
+	if wonderNet == nil || claims == nil {
+		http.Error(w, "authorization required", http.StatusUnauthorized)
+		return
+	}
+
+	wonderNetID := r.PathValue("id")
+	if wonderNetID == "" {
+		http.Error(w, "missing wonder net id", http.StatusBadRequest)
+		return
+	}
+
+	if err := c.wonderNetService.DeleteWonderNet(r.Context(), claims.Subject, wonderNetID); err != nil {
+		if errors.Is(err, service.ErrNoWonderNet) {
+			http.Error(w, "wonder net not found", http.StatusNotFound)
+			return
+		}
+		if errors.Is(err, service.ErrDeleteDefaultWonderNet) {
+			http.Error(w, "cannot delete the default wonder net", http.StatusConflict)
+			return
+		}
+		slog.Error("delete wonder net", "error", err)
+		http.Error(w, "delete wonder net", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+func userWonderNetResponse(wn *repository.WonderNet) UserWonderNetResponse {
+	joinTokenDefaultTTL, joinTokenMaxTTL := service.JoinTokenTTLPolicy(wn)
+	return UserWonderNetResponse{
+		ID:                  wn.ID,
+		DisplayName:         wn.DisplayName,
+		MeshType:            wn.MeshType,
+		IsDefault:           wn.IsDefault,
+		NodeNameTemplate:    wn.NodeNameTemplate,
+		PublicURL:           wn.PublicURL,
+		RequireNodeApproval: wn.RequireNodeApproval,
+		NodeExpiryDays:      wn.NodeExpiryDays,
+		JoinTokenDefaultTTL: joinTokenDefaultTTL.String(),
+		JoinTokenMaxTTL:     joinTokenMaxTTL.String(),
+		Role:                repository.RoleOwner,
+		CreatedAt:           wn.CreatedAt,
+	}
+}
@@ -2,16 +2,48 @@ package controller
 
 import (
 	"encoding/json"
+	"errors"
 	"log/slog"
 	"net/http"
+	"strings"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
 )
 
 // JoinCredentialsResponse contains credentials for joining the mesh.
+// WorkerToken is only returned to workers joining with a join token; they
+// use it to send heartbeats. SSHCAPublicKey is the wonder net's SSH CA key,
+// for wonder worker install --ssh-ca.
 type JoinCredentialsResponse struct {
 	MeshType                string                   `json:"mesh_type"`
 	TailscaleConnectionInfo *TailscaleConnectionInfo `json:"tailscale_connection_info,omitempty"`
+	WorkerToken             string                   `json:"worker_token,omitempty"`
+	SSHCAPublicKey          string                   `json:"ssh_ca_public_key,omitempty"`
+}
+
+// HeartbeatRequest is a worker's health report, stored for the node the
+// worker token is bound to. Connectivity is the node's DERP region and paths
+// to its peers, omitted if the worker could not read them.
+type HeartbeatRequest struct {
+	OS                   string                    `json:"os"`
+	TailscaleVersion     string                    `json:"tailscale_version"`
+	DiskTotalBytes       int64                     `json:"disk_total_bytes"`
+	DiskFreeBytes        int64                     `json:"disk_free_bytes"`
+	MemoryTotalBytes     int64                     `json:"memory_total_bytes"`
+	MemoryAvailableBytes int64                     `json:"memory_available_bytes"`
+	Connectivity         *service.NodeConnectivity `json:"connectivity,omitempty"`
+}
+
+// HeartbeatResponse is returned for a heartbeat when the coordinator has
+// work for the worker. Wipe asks the worker to run its wipe job for a
+// decommission of its node and report the result.
+type HeartbeatResponse struct {
+	Wipe *WipeInstruction `json:"wipe,omitempty"`
+}
+
+// WipeInstruction names the decommission a wipe job runs for.
+type WipeInstruction struct {
+	DecommissionID string `json:"decommission_id"`
 }
 
 // TailscaleConnectionInfo contains the credentials for joining a Tailscale/Headscale mesh.
@@ -23,13 +55,15 @@ type TailscaleConnectionInfo struct {
 
 // WorkerController handles worker node registration.
 type WorkerController struct {
-	workerService *service.WorkerService
+	workerService       *service.WorkerService
+	decommissionService *service.NodeDecommissionService
 }
 
 // NewWorkerController creates a new WorkerController.
-func NewWorkerController(workerService *service.WorkerService) *WorkerController {
+func NewWorkerController(workerService *service.WorkerService, decommissionService *service.NodeDecommissionService) *WorkerController {
 	return &WorkerController{
-		workerService: workerService,
+		workerService:       workerService,
+		decommissionService: decommissionService,
 	}
 }
 
@@ -49,10 +83,16 @@ func (c *WorkerController) HandleWorkerJoin(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
-	creds, err := c.workerService.ExchangeJoinToken(r.Context(), req.Token)
+	creds, err := c.workerService.ExchangeJoinToken(r.Context(), req.Token, hostWonderNetID(r), ClientIPFromContext(r))
 	if err != nil {
 		if err == service.ErrInvalidToken {
 			http.Error(w, "invalid or expired token", http.StatusUnauthorized)
+		} else if err == service.ErrJoinTokenUsed {
+			http.Error(w, "join token has no uses left; create a new one", http.StatusUnauthorized)
+		} else if errors.Is(err, service.ErrClientIPNotAllowed) {
+			http.Error(w, "join token may not be used from this address", http.StatusForbidden)
+		} else if errors.Is(err, service.ErrQuotaExceeded) {
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
 		} else {
 			slog.Error("exchange join token", "error", err)
 			http.Error(w, "exchange join token", http.StatusInternalServerError)
@@ -60,6 +100,11 @@ func (c *WorkerController) HandleWorkerJoin(w http.ResponseWriter, r *http.Reque
 		return
 	}
 
+	c.writeJoinCredentials(w, creds)
+}
+
+// writeJoinCredentials writes mesh credentials as a JoinCredentialsResponse.
+func (c *WorkerController) writeJoinCredentials(w http.ResponseWriter, creds *service.JoinCredentials) {
 	if creds.MeshType != "tailscale" {
 		slog.Error("unsupported mesh type", "mesh_type", creds.MeshType)
 		http.Error(w, "unsupported mesh type", http.StatusInternalServerError)
@@ -74,9 +119,95 @@ func (c *WorkerController) HandleWorkerJoin(w http.ResponseWriter, r *http.Reque
 			Authkey:       creds.Metadata["authkey"].(string),
 			HeadscaleUser: creds.Metadata["headscale_user"].(string),
 		},
+		WorkerToken:    creds.WorkerToken,
+		SSHCAPublicKey: creds.SSHCAPublicKey,
 	}
 
 	if err := json.NewEncoder(w).Encode(resp); err != nil {
 		slog.Error("encode worker join response", "error", err)
 	}
 }
+
+// HandleRenew handles POST /api/v1/worker/renew requests.
+// This endpoint authenticates with the worker token returned on join,
+// passed as a Bearer token, and returns new mesh credentials without a
+// worker token, so that a worker can log in again after its authkey or
+// node key expired. It responds 409 while the worker's node is online or
+// being decommissioned, or if the worker never joined with its token.
+func (c *WorkerController) HandleRenew(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "worker token required", http.StatusUnauthorized)
+		return
+	}
+
+	creds, err := c.workerService.RenewJoinCredentials(r.Context(), token, hostWonderNetID(r), ClientIPFromContext(r))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			http.Error(w, "invalid worker token", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrRenewalNotAllowed), errors.Is(err, service.ErrWorkerNotJoined):
+			http.Error(w, err.Error(), http.StatusConflict)
+		case errors.Is(err, service.ErrQuotaExceeded):
+			http.Error(w, err.Error(), http.StatusTooManyRequests)
+		default:
+			slog.Error("renew join credentials", "error", err)
+			http.Error(w, "renew join credentials", http.StatusInternalServerError)
+		}
+		return
+	}
+	c.writeJoinCredentials(w, creds)
+}
+
+// HandleHeartbeat handles POST /api/v1/worker/heartbeat requests.
+// This endpoint authenticates with the worker token returned on join,
+// passed as a Bearer token. It responds 204, or 200 with a
+// HeartbeatResponse when the node is being decommissioned with a wipe.
+func (c *WorkerController) HandleHeartbeat(w http.ResponseWriter, r *http.Request) {
+	token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok || token == "" {
+		http.Error(w, "worker token required", http.StatusUnauthorized)
+		return
+	}
+
+	var req HeartbeatRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	nodeID, err := c.workerService.RecordHeartbeat(r.Context(), token, hostWonderNetID(r), ClientIPFromContext(r), &service.Heartbeat{
+		OS:                   req.OS,
+		TailscaleVersion:     req.TailscaleVersion,
+		DiskTotalBytes:       req.DiskTotalBytes,
+		DiskFreeBytes:        req.DiskFreeBytes,
+		MemoryTotalBytes:     req.MemoryTotalBytes,
+		MemoryAvailableBytes: req.MemoryAvailableBytes,
+		Connectivity:         req.Connectivity,
+	})
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidToken):
+			http.Error(w, "invalid worker token", http.StatusUnauthorized)
+		case errors.Is(err, service.ErrNodeNotFound):
+			http.Error(w, "worker token is not bound to a node of the wonder net", http.StatusNotFound)
+		default:
+			slog.Error("record heartbeat", "error", err)
+			http.Error(w, "record heartbeat", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	wipe, err := c.decommissionService.PendingWipe(r.Context(), nodeID)
+	if err != nil {
+		slog.Error("get pending node wipe", "error", err, "node_id", nodeID)
+	}
+	if wipe == nil {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(HeartbeatResponse{
+		Wipe: &WipeInstruction{DecommissionID: wipe.ID},
+	})
+}
@@ -0,0 +1,139 @@
+package coordinator
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// corsPathPrefix is the part of the coordinator served to browsers on other
+// origins. The web UI, login flows, admin API, and Headscale proxy are
+// same-origin only.
+const corsPathPrefix = "/coordinator/api/"
+
+const (
+	corsAllowedMethods = "GET, POST, PUT, PATCH, DELETE"
+	corsAllowedHeaders = "Authorization, Content-Type, X-Wonder-Net"
+	corsExposedHeaders = "X-Join-Token-JTI"
+)
+
+// corsPolicy lets browser dashboards on other origins call the coordinator
+// API. A nil policy adds no CORS headers.
+type corsPolicy struct {
+	// origins are the allowed origins, or nil if anyOrigin is set.
+	origins     []string
+	anyOrigin   bool
+	credentials bool
+	maxAge      time.Duration
+}
+
+// newCORSPolicy returns the CORS policy for the allowed origins, or nil if
+// there are none. Origins are scheme://host[:port], or * for any origin,
+// which cannot be combined with credentials.
+func newCORSPolicy(origins []string, credentials bool, maxAge time.Duration) (*corsPolicy, error) {
+	if len(origins) == 0 {
+		return nil, nil
+	}
+	if maxAge < 0 {
+		return nil, fmt.Errorf("CORS max age %s must not be negative", maxAge)
+	}
+
+	p := &corsPolicy{credentials: credentials, maxAge: maxAge}
+	for _, origin := range origins {
+		if origin == "*" {
+			p.anyOrigin = true
+			continue
+		}
+		normalized, err := normalizeOrigin(origin)
+		if err != nil {
+			return nil, err
+		}
+		p.origins = append(p.origins, normalized)
+	}
+	if p.anyOrigin {
+		if credentials {
+			return nil, fmt.Errorf("CORS origin * cannot be combined with credentials; list the origins instead")
+		}
+		p.origins = nil
+	}
+	return p, nil
+}
+
+// normalizeOrigin checks that origin is a bare scheme://host[:port] and
+// lower-cases it, as browsers send it.
+func normalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(origin)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" ||
+		u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("invalid CORS origin %q, want scheme://host[:port] or *", origin)
+	}
+	return strings.ToLower(u.Scheme + "://" + u.Host), nil
+}
+
+// allows reports whether requests from origin may read API responses.
+func (p *corsPolicy) allows(origin string) bool {
+	return p.anyOrigin || slices.Contains(p.origins, strings.ToLower(origin))
+}
+
+// withCORS adds CORS headers to coordinator API responses for allowed
+// origins and answers their preflight requests before authentication, which
+// browsers do not send credentials with. Preflights from other origins are
+// refused.
+func (s *Server) withCORS(next http.Handler) http.Handler {
+	p := s.corsPolicy
+	if p == nil {
+		return next
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !strings.HasPrefix(r.URL.Path, corsPathPrefix) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		h := w.Header()
+		if !p.anyOrigin {
+			h.Add("Vary", "Origin")
+		}
+		origin := r.Header.Get("Origin")
+		preflight := r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != ""
+		if origin == "" {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !p.allows(origin) {
+			if preflight {
+				w.WriteHeader(http.StatusForbidden)
+				return
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if p.anyOrigin {
+			h.Set("Access-Control-Allow-Origin", "*")
+		} else {
+			h.Set("Access-Control-Allow-Origin", origin)
+		}
+		if p.credentials {
+			h.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		if !preflight {
+			h.Set("Access-Control-Expose-Headers", corsExposedHeaders)
+			next.ServeHTTP(w, r)
+			return
+		}
+		h.Add("Vary", "Access-Control-Request-Method")
+		h.Add("Vary", "Access-Control-Request-Headers")
+		h.Set("Access-Control-Allow-Methods", corsAllowedMethods)
+		h.Set("Access-Control-Allow-Headers", corsAllowedHeaders)
+		if p.maxAge > 0 {
+			h.Set("Access-Control-Max-Age", strconv.Itoa(int(p.maxAge.Seconds())))
+		}
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
@@ -0,0 +1,97 @@
+package coordinator
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func testCORSHandler(t *testing.T, origins []string, credentials bool) http.Handler {
+	t.Helper()
+	policy, err := newCORSPolicy(origins, credentials, 10*time.Minute)
+	if err != nil {
+		t.Fatalf("newCORSPolicy() error = %v", err)
+	}
+	s := &Server{corsPolicy: policy}
+	return s.withCORS(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusTeapot)
+	}))
+}
+
+func TestWithCORS_Preflight(t *testing.T) {
+	handler := testCORSHandler(t, []string{"https://Dash.example.com"}, true)
+
+	req := httptest.NewRequest(http.MethodOptions, "/coordinator/api/v1/nodes", nil)
+	req.Header.Set("Origin", "https://dash.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusNoContent {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusNoContent)
+	}
+	for header, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://dash.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Max-Age":           "600",
+		"Access-Control-Allow-Headers":     corsAllowedHeaders,
+	} {
+		if got := rec.Header().Get(header); got != want {
+			t.Errorf("%s = %q, want %q", header, got, want)
+		}
+	}
+}
+
+func TestWithCORS_DisallowedOrigin(t *testing.T) {
+	handler := testCORSHandler(t, []string{"https://dash.example.com"}, false)
+
+	req := httptest.NewRequest(http.MethodOptions, "/coordinator/api/v1/nodes", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if rec.Code != http.StatusForbidden {
+		t.Errorf("preflight status = %d, want %d", rec.Code, http.StatusForbidden)
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/coordinator/api/v1/nodes", nil)
+	req.Header.Set("Origin", "https://evil.example.com")
+	rec = httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "" {
+		t.Errorf("Access-Control-Allow-Origin = %q, want none", got)
+	}
+}
+
+func TestWithCORS_SkipsOtherPaths(t *testing.T) {
+	handler := testCORSHandler(t, []string{"*"}, false)
+
+	for _, path := range []string{"/ts2021", "/machine/register", "/coordinator/admin/api/v1/wonder-nets", "/coordinator/oidc/login"} {
+		req := httptest.NewRequest(http.MethodOptions, path, nil)
+		req.Header.Set("Origin", "https://dash.example.com")
+		req.Header.Set("Access-Control-Request-Method", http.MethodGet)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != http.StatusTeapot || rec.Header().Get("Access-Control-Allow-Origin") != "" {
+			t.Errorf("%s: status = %d with CORS headers %v, want it passed through", path, rec.Code, rec.Header())
+		}
+	}
+}
+
+func TestNewCORSPolicy_Invalid(t *testing.T) {
+	for _, tc := range []struct {
+		name        string
+		origins     []string
+		credentials bool
+	}{
+		{"path", []string{"https://dash.example.com/app"}, false},
+		{"no scheme", []string{"dash.example.com"}, false},
+		{"wildcard with credentials", []string{"*"}, true},
+	} {
+		if _, err := newCORSPolicy(tc.origins, tc.credentials, 0); err == nil {
+			t.Errorf("%s: newCORSPolicy(%v) error = nil, want error", tc.name, tc.origins)
+		}
+	}
+}
@@ -5,23 +5,342 @@ CREATE TABLE wonder_nets (
     headscale_user TEXT NOT NULL UNIQUE,
     display_name TEXT NOT NULL DEFAULT '',
     mesh_type TEXT NOT NULL DEFAULT 'tailscale',
+    is_default BOOLEAN NOT NULL DEFAULT FALSE,
+    node_name_template TEXT NOT NULL DEFAULT '',
+    acl_rules TEXT NOT NULL DEFAULT '',
+    public_url TEXT NOT NULL DEFAULT '',
+    require_node_approval BOOLEAN NOT NULL DEFAULT FALSE,
+    node_expiry_days BIGINT NOT NULL DEFAULT 0,
+    join_token_default_ttl_seconds BIGINT NOT NULL DEFAULT 0,
+    join_token_max_ttl_seconds BIGINT NOT NULL DEFAULT 0,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 CREATE INDEX idx_wonder_nets_owner_id ON wonder_nets(owner_id);
+CREATE INDEX idx_wonder_nets_created_at_id ON wonder_nets(created_at, id);
+CREATE UNIQUE INDEX idx_wonder_nets_public_url ON wonder_nets(public_url) WHERE public_url <> '';
 
 CREATE TABLE api_keys (
     id TEXT PRIMARY KEY,
-    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id),
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
     name TEXT NOT NULL DEFAULT '',
     key_hash TEXT NOT NULL UNIQUE,
     key_prefix TEXT NOT NULL,
     created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
     last_used_at TIMESTAMP,
-    expires_at TIMESTAMP
+    expires_at TIMESTAMP,
+    scopes TEXT NOT NULL DEFAULT '',
+    allowed_cidrs TEXT NOT NULL DEFAULT '',
+    deprecated_at TIMESTAMP,
+    successor_id TEXT NOT NULL DEFAULT ''
 );
 CREATE INDEX idx_api_keys_wonder_net_id ON api_keys(wonder_net_id);
 
+CREATE TABLE api_key_usage (
+    api_key_id TEXT NOT NULL REFERENCES api_keys(id) ON DELETE CASCADE,
+    endpoint TEXT NOT NULL,
+    request_count BIGINT NOT NULL DEFAULT 0,
+    last_used_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (api_key_id, endpoint)
+);
+
+CREATE TABLE alert_rules (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL DEFAULT '',
+    kind TEXT NOT NULL,
+    threshold_seconds BIGINT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_alert_rules_wonder_net_id ON alert_rules(wonder_net_id);
+
+CREATE TABLE alert_silences (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    rule_id TEXT NOT NULL DEFAULT '',
+    node_name TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    ends_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_alert_silences_wonder_net_id ON alert_silences(wonder_net_id);
+
+CREATE TABLE firing_alerts (
+    rule_id TEXT NOT NULL REFERENCES alert_rules(id) ON DELETE CASCADE,
+    node_name TEXT NOT NULL,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    node_id BIGINT NOT NULL,
+    starts_at TIMESTAMP NOT NULL,
+    silenced BOOLEAN NOT NULL DEFAULT FALSE,
+    PRIMARY KEY (rule_id, node_name)
+);
+CREATE INDEX idx_firing_alerts_wonder_net_id ON firing_alerts(wonder_net_id);
+
+CREATE TABLE services (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    node_id TEXT NOT NULL,
+    port INTEGER NOT NULL,
+    protocol TEXT NOT NULL DEFAULT 'tcp',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (wonder_net_id, name)
+);
+
+CREATE TABLE service_grants (
+    id TEXT PRIMARY KEY,
+    service_id TEXT NOT NULL REFERENCES services(id) ON DELETE CASCADE,
+    subject TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (service_id, subject)
+);
+
+CREATE TABLE access_requests (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    service_id TEXT NOT NULL,
+    service_name TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    requester TEXT NOT NULL,
+    reason TEXT NOT NULL DEFAULT '',
+    duration_seconds BIGINT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    decided_by TEXT NOT NULL DEFAULT '',
+    grant_id TEXT NOT NULL DEFAULT '',
+    decided_at TIMESTAMP,
+    expires_at TIMESTAMP,
+    ended_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_access_requests_wonder_net_id ON access_requests(wonder_net_id);
+CREATE INDEX idx_access_requests_status_expires_at ON access_requests(status, expires_at);
+
+CREATE TABLE node_heartbeats (
+    node_id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    os TEXT NOT NULL DEFAULT '',
+    tailscale_version TEXT NOT NULL DEFAULT '',
+    disk_total_bytes BIGINT NOT NULL DEFAULT 0,
+    disk_free_bytes BIGINT NOT NULL DEFAULT 0,
+    memory_total_bytes BIGINT NOT NULL DEFAULT 0,
+    memory_available_bytes BIGINT NOT NULL DEFAULT 0,
+    connectivity TEXT NOT NULL DEFAULT '',
+    reported_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_node_heartbeats_wonder_net_id ON node_heartbeats(wonder_net_id);
+
+CREATE TABLE node_decommissions (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL,
+    node_name TEXT NOT NULL DEFAULT '',
+    hostname TEXT NOT NULL DEFAULT '',
+    addresses TEXT NOT NULL DEFAULT '',
+    os TEXT NOT NULL DEFAULT '',
+    last_seen_at TIMESTAMP,
+    requested_by TEXT NOT NULL DEFAULT '',
+    reason TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'in_progress',
+    wipe_status TEXT NOT NULL DEFAULT 'skipped',
+    wipe_output TEXT NOT NULL DEFAULT '',
+    error TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    completed_at TIMESTAMP
+);
+CREATE INDEX idx_node_decommissions_wonder_net_id ON node_decommissions(wonder_net_id);
+CREATE INDEX idx_node_decommissions_node_id_status ON node_decommissions(node_id, status);
+
+CREATE TABLE wonder_net_members (
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    user_id TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    role TEXT NOT NULL,
+    added_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (wonder_net_id, user_id)
+);
+CREATE INDEX idx_wonder_net_members_user_id ON wonder_net_members(user_id);
+
+CREATE TABLE wonder_net_invites (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    email TEXT NOT NULL,
+    user_id TEXT NOT NULL,
+    role TEXT NOT NULL,
+    invited_by TEXT NOT NULL DEFAULT '',
+    status TEXT NOT NULL DEFAULT 'pending',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    decided_at TIMESTAMP
+);
+CREATE INDEX idx_wonder_net_invites_wonder_net_id ON wonder_net_invites(wonder_net_id);
+CREATE INDEX idx_wonder_net_invites_user_id ON wonder_net_invites(user_id);
+
+CREATE TABLE join_tokens (
+    jti TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    created_by TEXT NOT NULL DEFAULT '',
+    max_uses BIGINT NOT NULL,
+    uses BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    allowed_cidrs TEXT NOT NULL DEFAULT ''
+);
+CREATE INDEX idx_join_tokens_wonder_net_id ON join_tokens(wonder_net_id);
+
+CREATE TABLE worker_tokens (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    auth_key_id TEXT NOT NULL DEFAULT '',
+    node_id TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    expires_at TIMESTAMP NOT NULL,
+    last_used_at TIMESTAMP,
+    revoked_at TIMESTAMP
+);
+CREATE INDEX idx_worker_tokens_wonder_net_id ON worker_tokens(wonder_net_id);
+
+CREATE TABLE webhooks (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '',
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_webhooks_wonder_net_id ON webhooks(wonder_net_id);
+
+CREATE TABLE webhook_deliveries (
+    id TEXT PRIMARY KEY,
+    webhook_id TEXT NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    event TEXT NOT NULL,
+    payload TEXT NOT NULL,
+    status TEXT NOT NULL DEFAULT 'pending',
+    attempts BIGINT NOT NULL DEFAULT 0,
+    response_status BIGINT NOT NULL DEFAULT 0,
+    last_error TEXT NOT NULL DEFAULT '',
+    next_attempt_at TIMESTAMP NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    delivered_at TIMESTAMP
+);
+CREATE INDEX idx_webhook_deliveries_webhook_id ON webhook_deliveries(webhook_id);
+CREATE INDEX idx_webhook_deliveries_status_next_attempt_at ON webhook_deliveries(status, next_attempt_at);
+
+CREATE TABLE wonder_net_quotas (
+    wonder_net_id TEXT PRIMARY KEY REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    max_nodes BIGINT NOT NULL DEFAULT 0,
+    max_api_keys BIGINT NOT NULL DEFAULT 0,
+    authkeys_per_hour BIGINT NOT NULL DEFAULT 0,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE dns_domains (
+    wonder_net_id TEXT PRIMARY KEY REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    domain TEXT NOT NULL UNIQUE,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE dns_records (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    type TEXT NOT NULL,
+    value TEXT NOT NULL,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_dns_records_wonder_net_id ON dns_records(wonder_net_id);
+
+CREATE TABLE node_approvals (
+    node_id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    status TEXT NOT NULL,
+    decided_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    decided_at TIMESTAMP
+);
+CREATE INDEX idx_node_approvals_wonder_net_id ON node_approvals(wonder_net_id);
+
+CREATE TABLE node_names (
+    node_id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    name TEXT NOT NULL,
+    renamed_by TEXT NOT NULL DEFAULT '',
+    renamed_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_node_names_wonder_net_id ON node_names(wonder_net_id);
+
+CREATE TABLE notification_channels (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    kind TEXT NOT NULL,
+    target TEXT NOT NULL,
+    events TEXT NOT NULL DEFAULT '',
+    digest_seconds BIGINT NOT NULL DEFAULT 0,
+    created_by TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_notification_channels_wonder_net_id ON notification_channels(wonder_net_id);
+
+CREATE TABLE users (
+    id TEXT PRIMARY KEY,
+    provider TEXT NOT NULL,
+    subject TEXT NOT NULL,
+    username TEXT NOT NULL,
+    email TEXT NOT NULL DEFAULT '',
+    name TEXT NOT NULL DEFAULT '',
+    password_hash TEXT NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (provider, subject)
+);
+CREATE INDEX idx_users_email ON users(email);
+
+CREATE TABLE exec_sessions (
+    id TEXT PRIMARY KEY,
+    wonder_net_id TEXT NOT NULL REFERENCES wonder_nets(id) ON DELETE CASCADE,
+    node_id TEXT NOT NULL DEFAULT '',
+    node_name TEXT NOT NULL DEFAULT '',
+    address TEXT NOT NULL DEFAULT '',
+    command TEXT NOT NULL,
+    stdout TEXT NOT NULL DEFAULT '',
+    stderr TEXT NOT NULL DEFAULT '',
+    output_truncated BOOLEAN NOT NULL DEFAULT FALSE,
+    exit_code BIGINT NOT NULL DEFAULT 0,
+    error TEXT NOT NULL DEFAULT '',
+    recorded_by TEXT NOT NULL DEFAULT '',
+    started_at TIMESTAMP NOT NULL,
+    duration_ms BIGINT NOT NULL DEFAULT 0,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP
+);
+CREATE INDEX idx_exec_sessions_wonder_net_id_started_at ON exec_sessions(wonder_net_id, started_at);
+CREATE INDEX idx_exec_sessions_started_at ON exec_sessions(started_at);
+
 -- +goose Down
+DROP TABLE IF EXISTS exec_sessions;
+DROP TABLE IF EXISTS users;
+DROP TABLE IF EXISTS notification_channels;
+DROP TABLE IF EXISTS node_names;
+DROP TABLE IF EXISTS node_approvals;
+DROP TABLE IF EXISTS dns_records;
+DROP TABLE IF EXISTS dns_domains;
+DROP TABLE IF EXISTS wonder_net_quotas;
+DROP TABLE IF EXISTS webhook_deliveries;
+DROP TABLE IF EXISTS webhooks;
+DROP TABLE IF EXISTS worker_tokens;
+DROP TABLE IF EXISTS join_tokens;
+DROP TABLE IF EXISTS wonder_net_invites;
+DROP TABLE IF EXISTS wonder_net_members;
+DROP TABLE IF EXISTS node_decommissions;
+DROP TABLE IF EXISTS node_heartbeats;
+DROP TABLE IF EXISTS access_requests;
+DROP TABLE IF EXISTS service_grants;
+DROP TABLE IF EXISTS services;
+DROP TABLE IF EXISTS firing_alerts;
+DROP TABLE IF EXISTS alert_silences;
+DROP TABLE IF EXISTS alert_rules;
+DROP TABLE IF EXISTS api_key_usage;
 DROP TABLE IF EXISTS api_keys;
 DROP TABLE IF EXISTS wonder_nets;
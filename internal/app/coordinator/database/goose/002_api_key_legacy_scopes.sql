@@ -0,0 +1,8 @@
+-- +goose Up
+-- Keys created before scopes existed are stored without scopes and had
+-- every scope. Keys without scopes are read-only now, so give those keys
+-- the admin scope explicitly to keep their access.
+UPDATE api_keys SET scopes = 'admin' WHERE scopes = '';
+
+-- +goose Down
+UPDATE api_keys SET scopes = '' WHERE scopes = 'admin';
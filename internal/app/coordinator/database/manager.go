@@ -1,9 +1,11 @@
 package database
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
+	"strings"
 	"time"
 
 	_ "github.com/jackc/pgx/v5/stdlib"
@@ -26,6 +28,14 @@ const (
 type Config struct {
 	Driver Driver
 	DSN    string
+
+	// Pool settings override the per-driver defaults when non-zero.
+	// SQLite always uses a single connection, so MaxOpenConns and
+	// MaxIdleConns only apply to Postgres.
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
 }
 
 // Manager handles database connections and migrations
@@ -36,12 +46,17 @@ type Manager struct {
 
 // NewManager creates a new database manager and runs migrations.
 func NewManager(cfg Config) (*Manager, error) {
-	db, err := sql.Open(string(cfg.Driver), cfg.DSN)
+	dsn := cfg.DSN
+	if cfg.Driver == DriverSQLite {
+		dsn = sqliteDSN(dsn)
+	}
+
+	db, err := sql.Open(string(cfg.Driver), dsn)
 	if err != nil {
 		return nil, fmt.Errorf("open database: %w", err)
 	}
 
-	configureConnectionPool(db, cfg.Driver)
+	configureConnectionPool(db, cfg)
 
 	if err := runMigrations(db, cfg.Driver); err != nil {
 		_ = db.Close()
@@ -60,8 +75,21 @@ func NewManager(cfg Config) (*Manager, error) {
 	}, nil
 }
 
-func configureConnectionPool(db *sql.DB, driver Driver) {
-	switch driver {
+// sqliteDSN enables foreign key enforcement, which SQLite leaves off by
+// default, on every connection, so that deleting a wonder net cascades to
+// the rows referencing it as it does on Postgres.
+func sqliteDSN(dsn string) string {
+	if strings.Contains(dsn, "_foreign_keys=") || strings.Contains(dsn, "_fk=") {
+		return dsn
+	}
+	if strings.Contains(dsn, "?") {
+		return dsn + "&_foreign_keys=on"
+	}
+	return dsn + "?_foreign_keys=on"
+}
+
+func configureConnectionPool(db *sql.DB, cfg Config) {
+	switch cfg.Driver {
 	case DriverSQLite:
 		// SQLite does not handle multiple concurrent writers well.
 		// Setting MaxOpenConns to 1 prevents "database is locked" errors.
@@ -72,6 +100,19 @@ func configureConnectionPool(db *sql.DB, driver Driver) {
 		db.SetMaxOpenConns(25)
 		db.SetMaxIdleConns(5)
 		db.SetConnMaxLifetime(5 * time.Minute)
+		if cfg.MaxOpenConns > 0 {
+			db.SetMaxOpenConns(cfg.MaxOpenConns)
+		}
+		if cfg.MaxIdleConns > 0 {
+			db.SetMaxIdleConns(cfg.MaxIdleConns)
+		}
+	}
+
+	if cfg.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
 	}
 }
 
@@ -109,6 +150,11 @@ func (m *Manager) DB() *sql.DB {
 	return m.db
 }
 
+// Ping checks that the database is reachable.
+func (m *Manager) Ping(ctx context.Context) error {
+	return m.db.PingContext(ctx)
+}
+
 // Close closes the database connection
 func (m *Manager) Close() error {
 	return m.db.Close()
@@ -11,49 +11,704 @@ import (
 )
 
 type WonderNet struct {
+	ID                  string
+	OwnerID             string
+	HeadscaleUser       string
+	DisplayName         string
+	MeshType            string
+	IsDefault           bool
+	NodeNameTemplate    string
+	ACLRules            string
+	PublicURL           string
+	RequireNodeApproval bool
+	NodeExpiryDays      int64
+	// JoinTokenDefaultTTLSeconds and JoinTokenMaxTTLSeconds are zero for
+	// the coordinator defaults.
+	JoinTokenDefaultTTLSeconds int64
+	JoinTokenMaxTTLSeconds     int64
+	CreatedAt                  time.Time
+	UpdatedAt                  time.Time
+}
+
+type APIKey struct {
+	ID           string
+	WonderNetID  string
+	Name         string
+	KeyHash      string
+	KeyPrefix    string
+	CreatedAt    time.Time
+	LastUsedAt   sql.NullTime
+	ExpiresAt    sql.NullTime
+	Scopes       string
+	AllowedCidrs string
+	DeprecatedAt sql.NullTime
+	SuccessorID  string
+}
+
+type CreateWonderNetParams struct {
 	ID            string
 	OwnerID       string
 	HeadscaleUser string
 	DisplayName   string
 	MeshType      string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
 }
 
-type APIKey struct {
+type UpdateWonderNetParams struct {
+	DisplayName string
+	ID          string
+}
+
+type SetDefaultWonderNetParams struct {
+	ID      string
+	OwnerID string
+}
+
+type UpdateWonderNetNodeNameTemplateParams struct {
+	NodeNameTemplate string
+	ID               string
+}
+
+type UpdateWonderNetACLRulesParams struct {
+	ACLRules string
+	ID       string
+}
+
+type UpdateWonderNetRequireNodeApprovalParams struct {
+	RequireNodeApproval bool
+	ID                  string
+}
+
+type UpdateWonderNetPublicURLParams struct {
+	PublicURL string
+	ID        string
+}
+
+// ListWonderNetsPageParams selects one page of wonder nets ordered by
+// (created_at, id). Rows strictly after the cursor in the chosen direction
+// are returned. Empty OwnerID or MeshType match any value.
+type ListWonderNetsPageParams struct {
+	OwnerID         string
+	MeshType        string
+	CreatedAfter    time.Time
+	CreatedBefore   time.Time
+	CursorCreatedAt time.Time
+	CursorID        string
+	Limit           int32
+	Ascending       bool
+}
+
+type CreateAPIKeyParams struct {
+	ID           string
+	WonderNetID  string
+	Name         string
+	KeyHash      string
+	KeyPrefix    string
+	ExpiresAt    sql.NullTime
+	Scopes       string
+	AllowedCidrs string
+}
+
+type DeprecateAPIKeyParams struct {
+	SuccessorID string
+	ExpiresAt   sql.NullTime
+	ID          string
+}
+
+type AlertRule struct {
+	ID               string
+	WonderNetID      string
+	Name             string
+	Kind             string
+	ThresholdSeconds int64
+	CreatedAt        time.Time
+}
+
+type AlertSilence struct {
+	ID          string
+	WonderNetID string
+	RuleID      string
+	NodeName    string
+	Reason      string
+	EndsAt      time.Time
+	CreatedAt   time.Time
+}
+
+type CreateAlertRuleParams struct {
+	ID               string
+	WonderNetID      string
+	Name             string
+	Kind             string
+	ThresholdSeconds int64
+}
+
+type CreateAlertSilenceParams struct {
+	ID          string
+	WonderNetID string
+	RuleID      string
+	NodeName    string
+	Reason      string
+	EndsAt      time.Time
+}
+
+type FiringAlert struct {
+	RuleID      string
+	NodeName    string
+	WonderNetID string
+	NodeID      int64
+	StartsAt    time.Time
+	Silenced    bool
+}
+
+type UpsertFiringAlertParams struct {
+	RuleID      string
+	NodeName    string
+	WonderNetID string
+	NodeID      int64
+	StartsAt    time.Time
+	Silenced    bool
+}
+
+type DeleteFiringAlertParams struct {
+	RuleID   string
+	NodeName string
+}
+
+type Service struct {
+	ID          string
+	WonderNetID string
+	Name        string
+	NodeID      string
+	Port        int32
+	Protocol    string
+	CreatedAt   time.Time
+}
+
+type ServiceGrant struct {
+	ID        string
+	ServiceID string
+	Subject   string
+	CreatedAt time.Time
+}
+
+type CreateServiceParams struct {
 	ID          string
 	WonderNetID string
 	Name        string
-	KeyHash     string
-	KeyPrefix   string
+	NodeID      string
+	Port        int32
+	Protocol    string
+}
+
+type CreateServiceGrantParams struct {
+	ID        string
+	ServiceID string
+	Subject   string
+}
+
+type AccessRequest struct {
+	ID              string
+	WonderNetID     string
+	ServiceID       string
+	ServiceName     string
+	Subject         string
+	Requester       string
+	Reason          string
+	DurationSeconds int64
+	Status          string
+	DecidedBy       string
+	GrantID         string
+	DecidedAt       sql.NullTime
+	ExpiresAt       sql.NullTime
+	EndedAt         sql.NullTime
+	CreatedAt       time.Time
+}
+
+type CreateAccessRequestParams struct {
+	ID              string
+	WonderNetID     string
+	ServiceID       string
+	ServiceName     string
+	Subject         string
+	Requester       string
+	Reason          string
+	DurationSeconds int64
+}
+
+type DecideAccessRequestParams struct {
+	Status    string
+	DecidedBy string
+	GrantID   string
+	ExpiresAt sql.NullTime
+	ID        string
+}
+
+type EndAccessRequestParams struct {
+	Status string
+	ID     string
+}
+
+type APIKeyUsage struct {
+	APIKeyID     string
+	Endpoint     string
+	RequestCount int64
+	LastUsedAt   time.Time
+}
+
+type RecordAPIKeyUsageParams struct {
+	APIKeyID     string
+	Endpoint     string
+	RequestCount int64
+}
+
+type MeshTypeCount struct {
+	MeshType string
+	Count    int64
+}
+
+type NodeHeartbeat struct {
+	NodeID               string
+	WonderNetID          string
+	OS                   string
+	TailscaleVersion     string
+	DiskTotalBytes       int64
+	DiskFreeBytes        int64
+	MemoryTotalBytes     int64
+	MemoryAvailableBytes int64
+	Connectivity         string
+	ReportedAt           time.Time
+}
+
+type UpsertNodeHeartbeatParams struct {
+	NodeID               string
+	WonderNetID          string
+	OS                   string
+	TailscaleVersion     string
+	DiskTotalBytes       int64
+	DiskFreeBytes        int64
+	MemoryTotalBytes     int64
+	MemoryAvailableBytes int64
+	Connectivity         string
+	ReportedAt           time.Time
+}
+
+type NodeDecommission struct {
+	ID          string
+	WonderNetID string
+	NodeID      string
+	NodeName    string
+	Hostname    string
+	Addresses   string
+	OS          string
+	LastSeenAt  sql.NullTime
+	RequestedBy string
+	Reason      string
+	Status      string
+	WipeStatus  string
+	WipeOutput  string
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt sql.NullTime
+}
+
+type CreateNodeDecommissionParams struct {
+	ID          string
+	WonderNetID string
+	NodeID      string
+	NodeName    string
+	Hostname    string
+	Addresses   string
+	OS          string
+	LastSeenAt  sql.NullTime
+	RequestedBy string
+	Reason      string
+	WipeStatus  string
+}
+
+type FinishNodeWipeParams struct {
+	WipeStatus string
+	WipeOutput string
+	ID         string
+}
+
+type EndNodeDecommissionParams struct {
+	Status string
+	Error  string
+	ID     string
+}
+
+type WonderNetMember struct {
+	WonderNetID string
+	UserID      string
+	Email       string
+	Role        string
+	AddedBy     string
+	CreatedAt   time.Time
+}
+
+type AddWonderNetMemberParams struct {
+	WonderNetID string
+	UserID      string
+	Email       string
+	Role        string
+	AddedBy     string
+}
+
+type GetWonderNetMemberParams struct {
+	WonderNetID string
+	UserID      string
+}
+
+type UpdateWonderNetMemberRoleParams struct {
+	Role        string
+	WonderNetID string
+	UserID      string
+}
+
+type DeleteWonderNetMemberParams struct {
+	WonderNetID string
+	UserID      string
+}
+
+type WonderNetInvite struct {
+	ID          string
+	WonderNetID string
+	Email       string
+	UserID      string
+	Role        string
+	InvitedBy   string
+	Status      string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	DecidedAt   sql.NullTime
+}
+
+type CreateWonderNetInviteParams struct {
+	ID          string
+	WonderNetID string
+	Email       string
+	UserID      string
+	Role        string
+	InvitedBy   string
+	ExpiresAt   time.Time
+}
+
+type GetPendingWonderNetInviteParams struct {
+	WonderNetID string
+	UserID      string
+}
+
+type DecideWonderNetInviteParams struct {
+	Status string
+	ID     string
+}
+
+type JoinToken struct {
+	Jti          string
+	WonderNetID  string
+	CreatedBy    string
+	MaxUses      int64
+	Uses         int64
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	LastUsedAt   sql.NullTime
+	AllowedCidrs string
+}
+
+type CreateJoinTokenParams struct {
+	Jti          string
+	WonderNetID  string
+	CreatedBy    string
+	MaxUses      int64
+	ExpiresAt    time.Time
+	AllowedCidrs string
+}
+
+type WorkerToken struct {
+	ID          string
+	WonderNetID string
+	AuthKeyID   string
+	NodeID      string
 	CreatedAt   time.Time
+	ExpiresAt   time.Time
 	LastUsedAt  sql.NullTime
-	ExpiresAt   sql.NullTime
+	RevokedAt   sql.NullTime
 }
 
-type CreateWonderNetParams struct {
+type CreateWorkerTokenParams struct {
+	ID          string
+	WonderNetID string
+	AuthKeyID   string
+	ExpiresAt   time.Time
+}
+
+type TouchWorkerTokenParams struct {
+	NodeID    string
+	ExpiresAt time.Time
+	ID        string
+}
+
+type UpdateWorkerTokenAuthKeyParams struct {
+	AuthKeyID string
+	ID        string
+}
+
+type RevokeWorkerTokensByNodeParams struct {
+	WonderNetID string
+	NodeID      string
+	AuthKeyID   string
+}
+
+type Webhook struct {
+	ID          string
+	WonderNetID string
+	Url         string
+	Secret      string
+	Events      string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	WonderNetID    string
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int64
+	ResponseStatus int64
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    sql.NullTime
+}
+
+type CreateWebhookParams struct {
+	ID          string
+	WonderNetID string
+	Url         string
+	Secret      string
+	Events      string
+	CreatedBy   string
+}
+
+type CreateWebhookDeliveryParams struct {
 	ID            string
-	OwnerID       string
-	HeadscaleUser string
-	DisplayName   string
-	MeshType      string
+	WebhookID     string
+	WonderNetID   string
+	Event         string
+	Payload       string
+	NextAttemptAt time.Time
 }
 
-type UpdateWonderNetParams struct {
-	DisplayName string
+type RecordWebhookDeliveryAttemptParams struct {
+	Status         string
+	Attempts       int64
+	ResponseStatus int64
+	LastError      string
+	NextAttemptAt  time.Time
+	DeliveredAt    sql.NullTime
+	ID             string
+}
+
+type WonderNetQuota struct {
+	WonderNetID     string
+	MaxNodes        int64
+	MaxApiKeys      int64
+	AuthkeysPerHour int64
+	UpdatedAt       time.Time
+}
+
+type UpsertWonderNetQuotasParams struct {
+	WonderNetID     string
+	MaxNodes        int64
+	MaxApiKeys      int64
+	AuthkeysPerHour int64
+}
+
+type DNSDomain struct {
+	WonderNetID string
+	Domain      string
+	UpdatedAt   time.Time
+}
+
+type DNSRecord struct {
 	ID          string
+	WonderNetID string
+	Name        string
+	Type        string
+	Value       string
+	CreatedBy   string
+	CreatedAt   time.Time
 }
 
-type CreateAPIKeyParams struct {
+type UpsertDNSDomainParams struct {
+	WonderNetID string
+	Domain      string
+}
+
+type CreateDNSRecordParams struct {
 	ID          string
 	WonderNetID string
 	Name        string
-	KeyHash     string
-	KeyPrefix   string
-	ExpiresAt   sql.NullTime
+	Type        string
+	Value       string
+	CreatedBy   string
+}
+
+type NodeApproval struct {
+	NodeID      string
+	WonderNetID string
+	Status      string
+	DecidedBy   string
+	CreatedAt   time.Time
+	DecidedAt   sql.NullTime
+}
+
+type CreateNodeApprovalParams struct {
+	NodeID      string
+	WonderNetID string
+	Status      string
+	DecidedBy   string
+	DecidedAt   sql.NullTime
+}
+
+type DecideNodeApprovalParams struct {
+	Status    string
+	DecidedBy string
+	NodeID    string
+}
+
+type NodeName struct {
+	NodeID      string
+	WonderNetID string
+	Name        string
+	RenamedBy   string
+	RenamedAt   time.Time
+}
+
+type UpsertNodeNameParams struct {
+	NodeID      string
+	WonderNetID string
+	Name        string
+	RenamedBy   string
+}
+
+type ExecSession struct {
+	ID              string
+	WonderNetID     string
+	NodeID          string
+	NodeName        string
+	Address         string
+	Command         string
+	Stdout          string
+	Stderr          string
+	OutputTruncated bool
+	ExitCode        int64
+	Error           string
+	RecordedBy      string
+	StartedAt       time.Time
+	DurationMs      int64
+	CreatedAt       time.Time
+}
+
+type CreateExecSessionParams struct {
+	ID              string
+	WonderNetID     string
+	NodeID          string
+	NodeName        string
+	Address         string
+	Command         string
+	Stdout          string
+	Stderr          string
+	OutputTruncated bool
+	ExitCode        int64
+	Error           string
+	RecordedBy      string
+	StartedAt       time.Time
+	DurationMs      int64
+}
+
+type ListExecSessionsByWonderNetParams struct {
+	WonderNetID string
+	Limit       int32
+}
+
+type UpdateWonderNetNodeExpiryDaysParams struct {
+	NodeExpiryDays int64
+	ID             string
+}
+
+type UpdateWonderNetJoinTokenTTLParams struct {
+	JoinTokenDefaultTTLSeconds int64
+	JoinTokenMaxTTLSeconds     int64
+	ID                         string
+}
+
+type NotificationChannel struct {
+	ID            string
+	WonderNetID   string
+	Kind          string
+	Target        string
+	Events        string
+	DigestSeconds int64
+	CreatedBy     string
+	CreatedAt     time.Time
+}
+
+type CreateNotificationChannelParams struct {
+	ID            string
+	WonderNetID   string
+	Kind          string
+	Target        string
+	Events        string
+	DigestSeconds int64
+	CreatedBy     string
+}
+
+type User struct {
+	ID           string
+	Provider     string
+	Subject      string
+	Username     string
+	Email        string
+	Name         string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+type CreateUserParams struct {
+	ID           string
+	Provider     string
+	Subject      string
+	Username     string
+	Email        string
+	Name         string
+	PasswordHash string
+}
+
+type GetUserByProviderSubjectParams struct {
+	Provider string
+	Subject  string
+}
+
+type UpdateUserProfileParams struct {
+	Username string
+	Email    string
+	Name     string
+	ID       string
+}
+
+type UpdateUserPasswordHashParams struct {
+	PasswordHash string
+	ID           string
 }
 
 type Queries interface {
+	// InTx runs fn with queries of one transaction, committed if fn returns
+	// nil and rolled back otherwise. fn must not start another transaction.
+	InTx(ctx context.Context, fn func(Queries) error) error
+
 	CreateWonderNet(ctx context.Context, arg CreateWonderNetParams) error
 	GetWonderNet(ctx context.Context, id string) (WonderNet, error)
 	GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error)
@@ -61,6 +716,20 @@ type Queries interface {
 	UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams) error
 	DeleteWonderNet(ctx context.Context, id string) error
 	ListWonderNets(ctx context.Context) ([]WonderNet, error)
+	CountWonderNets(ctx context.Context) (int64, error)
+	CountWonderNetsByMeshType(ctx context.Context) ([]MeshTypeCount, error)
+	UpdateWonderNetNodeNameTemplate(ctx context.Context, arg UpdateWonderNetNodeNameTemplateParams) error
+	ListWonderNetsWithNodeNameTemplate(ctx context.Context) ([]WonderNet, error)
+	UpdateWonderNetACLRules(ctx context.Context, arg UpdateWonderNetACLRulesParams) error
+	ListWonderNetsWithACLRules(ctx context.Context) ([]WonderNet, error)
+	UpdateWonderNetPublicURL(ctx context.Context, arg UpdateWonderNetPublicURLParams) error
+	GetWonderNetByPublicURL(ctx context.Context, publicURL string) (WonderNet, error)
+	ListWonderNetsWithPublicURL(ctx context.Context) ([]WonderNet, error)
+	UpdateWonderNetRequireNodeApproval(ctx context.Context, arg UpdateWonderNetRequireNodeApprovalParams) error
+	ListWonderNetsRequiringNodeApproval(ctx context.Context) ([]WonderNet, error)
+	GetDefaultWonderNetByOwner(ctx context.Context, ownerID string) (WonderNet, error)
+	SetDefaultWonderNet(ctx context.Context, arg SetDefaultWonderNetParams) error
+	ListWonderNetsPage(ctx context.Context, arg ListWonderNetsPageParams) ([]WonderNet, error)
 
 	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error)
 	GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error)
@@ -68,297 +737,3634 @@ type Queries interface {
 	ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]APIKey, error)
 	DeleteAPIKey(ctx context.Context, id string) error
 	UpdateAPIKeyLastUsed(ctx context.Context, id string) error
+	DeprecateAPIKey(ctx context.Context, arg DeprecateAPIKeyParams) error
+
+	CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error)
+	GetAlertRuleByID(ctx context.Context, id string) (AlertRule, error)
+	ListAlertRulesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertRule, error)
+	ListAlertRules(ctx context.Context) ([]AlertRule, error)
+	DeleteAlertRule(ctx context.Context, id string) error
+
+	CreateAlertSilence(ctx context.Context, arg CreateAlertSilenceParams) (AlertSilence, error)
+	GetAlertSilenceByID(ctx context.Context, id string) (AlertSilence, error)
+	ListAlertSilencesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertSilence, error)
+	DeleteAlertSilence(ctx context.Context, id string) error
+
+	ListFiringAlerts(ctx context.Context) ([]FiringAlert, error)
+	UpsertFiringAlert(ctx context.Context, arg UpsertFiringAlertParams) error
+	DeleteFiringAlert(ctx context.Context, arg DeleteFiringAlertParams) error
+	DeleteFiringAlertsByRule(ctx context.Context, ruleID string) error
+
+	CreateService(ctx context.Context, arg CreateServiceParams) (Service, error)
+	GetServiceByID(ctx context.Context, id string) (Service, error)
+	ListServicesByWonderNet(ctx context.Context, wonderNetID string) ([]Service, error)
+	ListServices(ctx context.Context) ([]Service, error)
+	DeleteService(ctx context.Context, id string) error
+
+	CreateServiceGrant(ctx context.Context, arg CreateServiceGrantParams) (ServiceGrant, error)
+	GetServiceGrantByID(ctx context.Context, id string) (ServiceGrant, error)
+	ListServiceGrantsByWonderNet(ctx context.Context, wonderNetID string) ([]ServiceGrant, error)
+	DeleteServiceGrant(ctx context.Context, id string) error
+	DeleteServiceGrantsByService(ctx context.Context, serviceID string) error
+
+	CreateAccessRequest(ctx context.Context, arg CreateAccessRequestParams) (AccessRequest, error)
+	GetAccessRequestByID(ctx context.Context, id string) (AccessRequest, error)
+	ListAccessRequestsByWonderNet(ctx context.Context, wonderNetID string) ([]AccessRequest, error)
+	DecideAccessRequest(ctx context.Context, arg DecideAccessRequestParams) (int64, error)
+	EndAccessRequest(ctx context.Context, arg EndAccessRequestParams) (int64, error)
+	ListExpiredAccessRequests(ctx context.Context, expiresAt sql.NullTime) ([]AccessRequest, error)
+
+	RecordAPIKeyUsage(ctx context.Context, arg RecordAPIKeyUsageParams) error
+	ListAPIKeyUsageByWonderNet(ctx context.Context, wonderNetID string) ([]APIKeyUsage, error)
+	DeleteAPIKeyUsage(ctx context.Context, apiKeyID string) error
+
+	UpsertNodeHeartbeat(ctx context.Context, arg UpsertNodeHeartbeatParams) error
+	GetNodeHeartbeat(ctx context.Context, nodeID string) (NodeHeartbeat, error)
+	ListNodeHeartbeatsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeHeartbeat, error)
+	DeleteNodeHeartbeat(ctx context.Context, nodeID string) error
+
+	CreateNodeDecommission(ctx context.Context, arg CreateNodeDecommissionParams) (NodeDecommission, error)
+	GetNodeDecommissionByID(ctx context.Context, id string) (NodeDecommission, error)
+	GetActiveNodeDecommissionByNode(ctx context.Context, nodeID string) (NodeDecommission, error)
+	ListNodeDecommissionsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeDecommission, error)
+	FinishNodeWipe(ctx context.Context, arg FinishNodeWipeParams) (int64, error)
+	EndNodeDecommission(ctx context.Context, arg EndNodeDecommissionParams) (int64, error)
+	ListStaleNodeWipes(ctx context.Context, createdAt time.Time) ([]NodeDecommission, error)
+
+	AddWonderNetMember(ctx context.Context, arg AddWonderNetMemberParams) error
+	GetWonderNetMember(ctx context.Context, arg GetWonderNetMemberParams) (WonderNetMember, error)
+	ListWonderNetMembers(ctx context.Context, wonderNetID string) ([]WonderNetMember, error)
+	ListWonderNetMembershipsByUser(ctx context.Context, userID string) ([]WonderNetMember, error)
+	UpdateWonderNetMemberRole(ctx context.Context, arg UpdateWonderNetMemberRoleParams) (int64, error)
+	DeleteWonderNetMember(ctx context.Context, arg DeleteWonderNetMemberParams) (int64, error)
+
+	CreateWonderNetInvite(ctx context.Context, arg CreateWonderNetInviteParams) (WonderNetInvite, error)
+	GetWonderNetInvite(ctx context.Context, id string) (WonderNetInvite, error)
+	GetPendingWonderNetInvite(ctx context.Context, arg GetPendingWonderNetInviteParams) (WonderNetInvite, error)
+	ListPendingWonderNetInvitesByWonderNet(ctx context.Context, wonderNetID string) ([]WonderNetInvite, error)
+	ListPendingWonderNetInvitesByUser(ctx context.Context, userID string) ([]WonderNetInvite, error)
+	DecideWonderNetInvite(ctx context.Context, arg DecideWonderNetInviteParams) (int64, error)
+
+	CreateJoinToken(ctx context.Context, arg CreateJoinTokenParams) error
+	GetJoinToken(ctx context.Context, jti string) (JoinToken, error)
+	UseJoinToken(ctx context.Context, jti string) (int64, error)
+	ReleaseJoinToken(ctx context.Context, jti string) error
+
+	CreateWorkerToken(ctx context.Context, arg CreateWorkerTokenParams) error
+	GetWorkerToken(ctx context.Context, id string) (WorkerToken, error)
+	TouchWorkerToken(ctx context.Context, arg TouchWorkerTokenParams) error
+	UpdateWorkerTokenAuthKey(ctx context.Context, arg UpdateWorkerTokenAuthKeyParams) error
+	RevokeWorkerTokensByNode(ctx context.Context, arg RevokeWorkerTokensByNodeParams) error
+
+	CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error)
+	GetWebhookByID(ctx context.Context, id string) (Webhook, error)
+	ListWebhooksByWonderNet(ctx context.Context, wonderNetID string) ([]Webhook, error)
+	ListWebhooks(ctx context.Context) ([]Webhook, error)
+	DeleteWebhook(ctx context.Context, id string) error
+	CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error
+	ListDueWebhookDeliveries(ctx context.Context, nextAttemptAt time.Time) ([]WebhookDelivery, error)
+	ListWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) ([]WebhookDelivery, error)
+	RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error
+	DeleteWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) error
+
+	GetWonderNetQuotas(ctx context.Context, wonderNetID string) (WonderNetQuota, error)
+	UpsertWonderNetQuotas(ctx context.Context, arg UpsertWonderNetQuotasParams) (WonderNetQuota, error)
+	DeleteWonderNetQuotas(ctx context.Context, wonderNetID string) error
+
+	GetDNSDomain(ctx context.Context, wonderNetID string) (DNSDomain, error)
+	GetDNSDomainByName(ctx context.Context, domain string) (DNSDomain, error)
+	ListDNSDomains(ctx context.Context) ([]DNSDomain, error)
+	UpsertDNSDomain(ctx context.Context, arg UpsertDNSDomainParams) (DNSDomain, error)
+	CreateDNSRecord(ctx context.Context, arg CreateDNSRecordParams) (DNSRecord, error)
+	GetDNSRecordByID(ctx context.Context, id string) (DNSRecord, error)
+	ListDNSRecordsByWonderNet(ctx context.Context, wonderNetID string) ([]DNSRecord, error)
+	ListDNSRecords(ctx context.Context) ([]DNSRecord, error)
+	DeleteDNSRecord(ctx context.Context, id string) error
+
+	CreateNodeApproval(ctx context.Context, arg CreateNodeApprovalParams) error
+	GetNodeApproval(ctx context.Context, nodeID string) (NodeApproval, error)
+	ListNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error)
+	ListPendingNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error)
+	DecideNodeApproval(ctx context.Context, arg DecideNodeApprovalParams) error
+	DeleteNodeApproval(ctx context.Context, nodeID string) error
+
+	UpsertNodeName(ctx context.Context, arg UpsertNodeNameParams) error
+	ListNodeNamesByWonderNet(ctx context.Context, wonderNetID string) ([]NodeName, error)
+	DeleteNodeName(ctx context.Context, nodeID string) error
+
+	CreateExecSession(ctx context.Context, arg CreateExecSessionParams) (ExecSession, error)
+	GetExecSessionByID(ctx context.Context, id string) (ExecSession, error)
+	ListExecSessionsByWonderNet(ctx context.Context, arg ListExecSessionsByWonderNetParams) ([]ExecSession, error)
+	DeleteExecSessionsBefore(ctx context.Context, startedAt time.Time) (int64, error)
+
+	UpdateWonderNetNodeExpiryDays(ctx context.Context, arg UpdateWonderNetNodeExpiryDaysParams) error
+	ListWonderNetsWithNodeExpiry(ctx context.Context) ([]WonderNet, error)
+	UpdateWonderNetJoinTokenTTL(ctx context.Context, arg UpdateWonderNetJoinTokenTTLParams) error
+
+	CreateNotificationChannel(ctx context.Context, arg CreateNotificationChannelParams) (NotificationChannel, error)
+	GetNotificationChannelByID(ctx context.Context, id string) (NotificationChannel, error)
+	ListNotificationChannelsByWonderNet(ctx context.Context, wonderNetID string) ([]NotificationChannel, error)
+	ListNotificationChannels(ctx context.Context) ([]NotificationChannel, error)
+	DeleteNotificationChannel(ctx context.Context, id string) error
+
+	CreateUser(ctx context.Context, arg CreateUserParams) (User, error)
+	GetUserByID(ctx context.Context, id string) (User, error)
+	GetUserByProviderSubject(ctx context.Context, arg GetUserByProviderSubjectParams) (User, error)
+	ListUsersByEmail(ctx context.Context, email string) ([]User, error)
+	ListUsers(ctx context.Context) ([]User, error)
+	UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error
+	UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPasswordHashParams) error
+	DeleteUser(ctx context.Context, id string) error
+}
+
+func newQueries(driver Driver, db *sql.DB) (Queries, error) {
+	switch driver {
+	case DriverSQLite:
+		return &sqliteQueries{db: db, q: sqlcsqlite.New(db)}, nil
+	case DriverPostgres:
+		return &postgresQueries{db: db, q: sqlcpostgres.New(db)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+	}
+}
+
+// inTx runs fn in a transaction of db, committed if fn returns nil.
+func inTx(ctx context.Context, db *sql.DB, fn func(*sql.Tx) error) error {
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	if err := fn(tx); err != nil {
+		_ = tx.Rollback()
+		return err
+	}
+	return tx.Commit()
+}
+
+type sqliteQueries struct {
+	db *sql.DB
+	q  *sqlcsqlite.Queries
+}
+
+func (s *sqliteQueries) InTx(ctx context.Context, fn func(Queries) error) error {
+	return inTx(ctx, s.db, func(tx *sql.Tx) error {
+		return fn(&sqliteQueries{db: s.db, q: s.q.WithTx(tx)})
+	})
+}
+
+func (s *sqliteQueries) CreateWonderNet(ctx context.Context, arg CreateWonderNetParams) error {
+	return s.q.CreateWonderNet(ctx, sqlcsqlite.CreateWonderNetParams{
+		ID:            arg.ID,
+		OwnerID:       arg.OwnerID,
+		HeadscaleUser: arg.HeadscaleUser,
+		DisplayName:   arg.DisplayName,
+		MeshType:      arg.MeshType,
+	})
+}
+
+func (s *sqliteQueries) GetWonderNet(ctx context.Context, id string) (WonderNet, error) {
+	row, err := s.q.GetWonderNet(ctx, id)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return sqliteWonderNet(row), nil
+}
+
+func (s *sqliteQueries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error) {
+	row, err := s.q.GetWonderNetByHeadscaleUser(ctx, headscaleUser)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return sqliteWonderNet(row), nil
+}
+
+func (s *sqliteQueries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams) error {
+	return s.q.UpdateWonderNet(ctx, sqlcsqlite.UpdateWonderNetParams{
+		DisplayName: arg.DisplayName,
+		ID:          arg.ID,
+	})
+}
+
+func (s *sqliteQueries) DeleteWonderNet(ctx context.Context, id string) error {
+	return s.q.DeleteWonderNet(ctx, id)
+}
+
+func (s *sqliteQueries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) CountWonderNets(ctx context.Context) (int64, error) {
+	return s.q.CountWonderNets(ctx)
+}
+
+func (s *sqliteQueries) CountWonderNetsByMeshType(ctx context.Context) ([]MeshTypeCount, error) {
+	rows, err := s.q.CountWonderNetsByMeshType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]MeshTypeCount, len(rows))
+	for i, row := range rows {
+		items[i] = MeshTypeCount{MeshType: row.MeshType, Count: row.Count}
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNetNodeNameTemplate(ctx context.Context, arg UpdateWonderNetNodeNameTemplateParams) error {
+	return s.q.UpdateWonderNetNodeNameTemplate(ctx, sqlcsqlite.UpdateWonderNetNodeNameTemplateParams{
+		NodeNameTemplate: arg.NodeNameTemplate,
+		ID:               arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListWonderNetsWithNodeNameTemplate(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsWithNodeNameTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNetACLRules(ctx context.Context, arg UpdateWonderNetACLRulesParams) error {
+	return s.q.UpdateWonderNetACLRules(ctx, sqlcsqlite.UpdateWonderNetACLRulesParams{
+		AclRules: arg.ACLRules,
+		ID:       arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListWonderNetsWithACLRules(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsWithACLRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNetPublicURL(ctx context.Context, arg UpdateWonderNetPublicURLParams) error {
+	return s.q.UpdateWonderNetPublicURL(ctx, sqlcsqlite.UpdateWonderNetPublicURLParams{
+		PublicUrl: arg.PublicURL,
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) GetWonderNetByPublicURL(ctx context.Context, publicURL string) (WonderNet, error) {
+	row, err := s.q.GetWonderNetByPublicURL(ctx, publicURL)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return sqliteWonderNet(row), nil
+}
+
+func (s *sqliteQueries) ListWonderNetsWithPublicURL(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsWithPublicURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNetRequireNodeApproval(ctx context.Context, arg UpdateWonderNetRequireNodeApprovalParams) error {
+	return s.q.UpdateWonderNetRequireNodeApproval(ctx, sqlcsqlite.UpdateWonderNetRequireNodeApprovalParams{
+		RequireNodeApproval: arg.RequireNodeApproval,
+		ID:                  arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListWonderNetsRequiringNodeApproval(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsRequiringNodeApproval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) GetDefaultWonderNetByOwner(ctx context.Context, ownerID string) (WonderNet, error) {
+	row, err := s.q.GetDefaultWonderNetByOwner(ctx, ownerID)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return sqliteWonderNet(row), nil
+}
+
+func (s *sqliteQueries) SetDefaultWonderNet(ctx context.Context, arg SetDefaultWonderNetParams) error {
+	return s.q.SetDefaultWonderNet(ctx, sqlcsqlite.SetDefaultWonderNetParams{
+		ID:      arg.ID,
+		OwnerID: arg.OwnerID,
+	})
+}
+
+func (s *sqliteQueries) ListWonderNetsPage(ctx context.Context, arg ListWonderNetsPageParams) ([]WonderNet, error) {
+	var rows []sqlcsqlite.WonderNet
+	var err error
+	if arg.Ascending {
+		rows, err = s.q.ListWonderNetsPageAsc(ctx, sqlcsqlite.ListWonderNetsPageAscParams{
+			OwnerID:         arg.OwnerID,
+			MeshType:        arg.MeshType,
+			CreatedAfter:    arg.CreatedAfter,
+			CreatedBefore:   arg.CreatedBefore,
+			CursorCreatedAt: arg.CursorCreatedAt,
+			CursorID:        arg.CursorID,
+			Limit:           int64(arg.Limit),
+		})
+	} else {
+		rows, err = s.q.ListWonderNetsPageDesc(ctx, sqlcsqlite.ListWonderNetsPageDescParams{
+			OwnerID:         arg.OwnerID,
+			MeshType:        arg.MeshType,
+			CreatedAfter:    arg.CreatedAfter,
+			CreatedBefore:   arg.CreatedBefore,
+			CursorCreatedAt: arg.CursorCreatedAt,
+			CursorID:        arg.CursorID,
+			Limit:           int64(arg.Limit),
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	row, err := s.q.CreateAPIKey(ctx, sqlcsqlite.CreateAPIKeyParams{
+		ID:           arg.ID,
+		WonderNetID:  arg.WonderNetID,
+		Name:         arg.Name,
+		KeyHash:      arg.KeyHash,
+		KeyPrefix:    arg.KeyPrefix,
+		ExpiresAt:    arg.ExpiresAt,
+		Scopes:       arg.Scopes,
+		AllowedCidrs: arg.AllowedCidrs,
+	})
+	if err != nil {
+		return APIKey{}, err
+	}
+	return sqliteAPIKey(row), nil
+}
+
+func (s *sqliteQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	row, err := s.q.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return sqliteAPIKey(row), nil
+}
+
+func (s *sqliteQueries) GetAPIKeyByID(ctx context.Context, id string) (APIKey, error) {
+	row, err := s.q.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return sqliteAPIKey(row), nil
+}
+
+func (s *sqliteQueries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]APIKey, error) {
+	rows, err := s.q.ListAPIKeysByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIKey, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAPIKey(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteAPIKey(ctx context.Context, id string) error {
+	return s.q.DeleteAPIKey(ctx, id)
+}
+
+func (s *sqliteQueries) UpdateAPIKeyLastUsed(ctx context.Context, id string) error {
+	return s.q.UpdateAPIKeyLastUsed(ctx, id)
+}
+
+func (s *sqliteQueries) DeprecateAPIKey(ctx context.Context, arg DeprecateAPIKeyParams) error {
+	return s.q.DeprecateAPIKey(ctx, sqlcsqlite.DeprecateAPIKeyParams{
+		SuccessorID: arg.SuccessorID,
+		ExpiresAt:   arg.ExpiresAt,
+		ID:          arg.ID,
+	})
+}
+
+func (s *sqliteQueries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row, err := s.q.CreateAlertRule(ctx, sqlcsqlite.CreateAlertRuleParams{
+		ID:               arg.ID,
+		WonderNetID:      arg.WonderNetID,
+		Name:             arg.Name,
+		Kind:             arg.Kind,
+		ThresholdSeconds: arg.ThresholdSeconds,
+	})
+	if err != nil {
+		return AlertRule{}, err
+	}
+	return sqliteAlertRule(row), nil
+}
+
+func (s *sqliteQueries) GetAlertRuleByID(ctx context.Context, id string) (AlertRule, error) {
+	row, err := s.q.GetAlertRuleByID(ctx, id)
+	if err != nil {
+		return AlertRule{}, err
+	}
+	return sqliteAlertRule(row), nil
+}
+
+func (s *sqliteQueries) ListAlertRulesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertRule, error) {
+	rows, err := s.q.ListAlertRulesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertRule, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAlertRule(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	rows, err := s.q.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertRule, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAlertRule(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteAlertRule(ctx context.Context, id string) error {
+	return s.q.DeleteAlertRule(ctx, id)
+}
+
+func (s *sqliteQueries) CreateAlertSilence(ctx context.Context, arg CreateAlertSilenceParams) (AlertSilence, error) {
+	row, err := s.q.CreateAlertSilence(ctx, sqlcsqlite.CreateAlertSilenceParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		RuleID:      arg.RuleID,
+		NodeName:    arg.NodeName,
+		Reason:      arg.Reason,
+		EndsAt:      arg.EndsAt,
+	})
+	if err != nil {
+		return AlertSilence{}, err
+	}
+	return sqliteAlertSilence(row), nil
+}
+
+func (s *sqliteQueries) GetAlertSilenceByID(ctx context.Context, id string) (AlertSilence, error) {
+	row, err := s.q.GetAlertSilenceByID(ctx, id)
+	if err != nil {
+		return AlertSilence{}, err
+	}
+	return sqliteAlertSilence(row), nil
+}
+
+func (s *sqliteQueries) ListAlertSilencesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertSilence, error) {
+	rows, err := s.q.ListAlertSilencesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertSilence, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAlertSilence(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteAlertSilence(ctx context.Context, id string) error {
+	return s.q.DeleteAlertSilence(ctx, id)
+}
+
+func (s *sqliteQueries) ListFiringAlerts(ctx context.Context) ([]FiringAlert, error) {
+	rows, err := s.q.ListFiringAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]FiringAlert, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteFiringAlert(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpsertFiringAlert(ctx context.Context, arg UpsertFiringAlertParams) error {
+	return s.q.UpsertFiringAlert(ctx, sqlcsqlite.UpsertFiringAlertParams{
+		RuleID:      arg.RuleID,
+		NodeName:    arg.NodeName,
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		StartsAt:    arg.StartsAt,
+		Silenced:    arg.Silenced,
+	})
+}
+
+func (s *sqliteQueries) DeleteFiringAlert(ctx context.Context, arg DeleteFiringAlertParams) error {
+	return s.q.DeleteFiringAlert(ctx, sqlcsqlite.DeleteFiringAlertParams{
+		RuleID:   arg.RuleID,
+		NodeName: arg.NodeName,
+	})
+}
+
+func (s *sqliteQueries) DeleteFiringAlertsByRule(ctx context.Context, ruleID string) error {
+	return s.q.DeleteFiringAlertsByRule(ctx, ruleID)
+}
+
+func (s *sqliteQueries) CreateService(ctx context.Context, arg CreateServiceParams) (Service, error) {
+	row, err := s.q.CreateService(ctx, sqlcsqlite.CreateServiceParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		NodeID:      arg.NodeID,
+		Port:        int64(arg.Port),
+		Protocol:    arg.Protocol,
+	})
+	if err != nil {
+		return Service{}, err
+	}
+	return sqliteService(row), nil
+}
+
+func (s *sqliteQueries) GetServiceByID(ctx context.Context, id string) (Service, error) {
+	row, err := s.q.GetServiceByID(ctx, id)
+	if err != nil {
+		return Service{}, err
+	}
+	return sqliteService(row), nil
+}
+
+func (s *sqliteQueries) ListServicesByWonderNet(ctx context.Context, wonderNetID string) ([]Service, error) {
+	rows, err := s.q.ListServicesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Service, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteService(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListServices(ctx context.Context) ([]Service, error) {
+	rows, err := s.q.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Service, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteService(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteService(ctx context.Context, id string) error {
+	return s.q.DeleteService(ctx, id)
+}
+
+func (s *sqliteQueries) CreateServiceGrant(ctx context.Context, arg CreateServiceGrantParams) (ServiceGrant, error) {
+	row, err := s.q.CreateServiceGrant(ctx, sqlcsqlite.CreateServiceGrantParams{
+		ID:        arg.ID,
+		ServiceID: arg.ServiceID,
+		Subject:   arg.Subject,
+	})
+	if err != nil {
+		return ServiceGrant{}, err
+	}
+	return sqliteServiceGrant(row), nil
+}
+
+func (s *sqliteQueries) GetServiceGrantByID(ctx context.Context, id string) (ServiceGrant, error) {
+	row, err := s.q.GetServiceGrantByID(ctx, id)
+	if err != nil {
+		return ServiceGrant{}, err
+	}
+	return sqliteServiceGrant(row), nil
+}
+
+func (s *sqliteQueries) ListServiceGrantsByWonderNet(ctx context.Context, wonderNetID string) ([]ServiceGrant, error) {
+	rows, err := s.q.ListServiceGrantsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ServiceGrant, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteServiceGrant(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteServiceGrant(ctx context.Context, id string) error {
+	return s.q.DeleteServiceGrant(ctx, id)
+}
+
+func (s *sqliteQueries) DeleteServiceGrantsByService(ctx context.Context, serviceID string) error {
+	return s.q.DeleteServiceGrantsByService(ctx, serviceID)
+}
+
+func (s *sqliteQueries) CreateAccessRequest(ctx context.Context, arg CreateAccessRequestParams) (AccessRequest, error) {
+	row, err := s.q.CreateAccessRequest(ctx, sqlcsqlite.CreateAccessRequestParams{
+		ID:              arg.ID,
+		WonderNetID:     arg.WonderNetID,
+		ServiceID:       arg.ServiceID,
+		ServiceName:     arg.ServiceName,
+		Subject:         arg.Subject,
+		Requester:       arg.Requester,
+		Reason:          arg.Reason,
+		DurationSeconds: arg.DurationSeconds,
+	})
+	if err != nil {
+		return AccessRequest{}, err
+	}
+	return sqliteAccessRequest(row), nil
+}
+
+func (s *sqliteQueries) GetAccessRequestByID(ctx context.Context, id string) (AccessRequest, error) {
+	row, err := s.q.GetAccessRequestByID(ctx, id)
+	if err != nil {
+		return AccessRequest{}, err
+	}
+	return sqliteAccessRequest(row), nil
+}
+
+func (s *sqliteQueries) ListAccessRequestsByWonderNet(ctx context.Context, wonderNetID string) ([]AccessRequest, error) {
+	rows, err := s.q.ListAccessRequestsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AccessRequest, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAccessRequest(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DecideAccessRequest(ctx context.Context, arg DecideAccessRequestParams) (int64, error) {
+	return s.q.DecideAccessRequest(ctx, sqlcsqlite.DecideAccessRequestParams{
+		Status:    arg.Status,
+		DecidedBy: arg.DecidedBy,
+		GrantID:   arg.GrantID,
+		ExpiresAt: arg.ExpiresAt,
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) EndAccessRequest(ctx context.Context, arg EndAccessRequestParams) (int64, error) {
+	return s.q.EndAccessRequest(ctx, sqlcsqlite.EndAccessRequestParams{
+		Status: arg.Status,
+		ID:     arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListExpiredAccessRequests(ctx context.Context, expiresAt sql.NullTime) ([]AccessRequest, error) {
+	rows, err := s.q.ListExpiredAccessRequests(ctx, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AccessRequest, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAccessRequest(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) RecordAPIKeyUsage(ctx context.Context, arg RecordAPIKeyUsageParams) error {
+	return s.q.RecordAPIKeyUsage(ctx, sqlcsqlite.RecordAPIKeyUsageParams{
+		ApiKeyID:     arg.APIKeyID,
+		Endpoint:     arg.Endpoint,
+		RequestCount: arg.RequestCount,
+	})
+}
+
+func (s *sqliteQueries) ListAPIKeyUsageByWonderNet(ctx context.Context, wonderNetID string) ([]APIKeyUsage, error) {
+	rows, err := s.q.ListAPIKeyUsageByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIKeyUsage, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteAPIKeyUsage(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteAPIKeyUsage(ctx context.Context, apiKeyID string) error {
+	return s.q.DeleteAPIKeyUsage(ctx, apiKeyID)
+}
+
+func (s *sqliteQueries) UpsertNodeHeartbeat(ctx context.Context, arg UpsertNodeHeartbeatParams) error {
+	return s.q.UpsertNodeHeartbeat(ctx, sqlcsqlite.UpsertNodeHeartbeatParams{
+		NodeID:               arg.NodeID,
+		WonderNetID:          arg.WonderNetID,
+		Os:                   arg.OS,
+		TailscaleVersion:     arg.TailscaleVersion,
+		DiskTotalBytes:       arg.DiskTotalBytes,
+		DiskFreeBytes:        arg.DiskFreeBytes,
+		MemoryTotalBytes:     arg.MemoryTotalBytes,
+		MemoryAvailableBytes: arg.MemoryAvailableBytes,
+		Connectivity:         arg.Connectivity,
+		ReportedAt:           arg.ReportedAt,
+	})
+}
+
+func (s *sqliteQueries) GetNodeHeartbeat(ctx context.Context, nodeID string) (NodeHeartbeat, error) {
+	row, err := s.q.GetNodeHeartbeat(ctx, nodeID)
+	if err != nil {
+		return NodeHeartbeat{}, err
+	}
+	return sqliteNodeHeartbeat(row), nil
+}
+
+func (s *sqliteQueries) ListNodeHeartbeatsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeHeartbeat, error) {
+	rows, err := s.q.ListNodeHeartbeatsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeHeartbeat, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeHeartbeat(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteNodeHeartbeat(ctx context.Context, nodeID string) error {
+	return s.q.DeleteNodeHeartbeat(ctx, nodeID)
+}
+
+func (s *sqliteQueries) CreateNodeDecommission(ctx context.Context, arg CreateNodeDecommissionParams) (NodeDecommission, error) {
+	row, err := s.q.CreateNodeDecommission(ctx, sqlcsqlite.CreateNodeDecommissionParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		NodeName:    arg.NodeName,
+		Hostname:    arg.Hostname,
+		Addresses:   arg.Addresses,
+		Os:          arg.OS,
+		LastSeenAt:  arg.LastSeenAt,
+		RequestedBy: arg.RequestedBy,
+		Reason:      arg.Reason,
+		WipeStatus:  arg.WipeStatus,
+	})
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return sqliteNodeDecommission(row), nil
+}
+
+func (s *sqliteQueries) GetNodeDecommissionByID(ctx context.Context, id string) (NodeDecommission, error) {
+	row, err := s.q.GetNodeDecommissionByID(ctx, id)
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return sqliteNodeDecommission(row), nil
+}
+
+func (s *sqliteQueries) GetActiveNodeDecommissionByNode(ctx context.Context, nodeID string) (NodeDecommission, error) {
+	row, err := s.q.GetActiveNodeDecommissionByNode(ctx, nodeID)
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return sqliteNodeDecommission(row), nil
+}
+
+func (s *sqliteQueries) ListNodeDecommissionsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeDecommission, error) {
+	rows, err := s.q.ListNodeDecommissionsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeDecommission, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeDecommission(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) FinishNodeWipe(ctx context.Context, arg FinishNodeWipeParams) (int64, error) {
+	return s.q.FinishNodeWipe(ctx, sqlcsqlite.FinishNodeWipeParams{
+		WipeStatus: arg.WipeStatus,
+		WipeOutput: arg.WipeOutput,
+		ID:         arg.ID,
+	})
+}
+
+func (s *sqliteQueries) EndNodeDecommission(ctx context.Context, arg EndNodeDecommissionParams) (int64, error) {
+	return s.q.EndNodeDecommission(ctx, sqlcsqlite.EndNodeDecommissionParams{
+		Status: arg.Status,
+		Error:  arg.Error,
+		ID:     arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListStaleNodeWipes(ctx context.Context, createdAt time.Time) ([]NodeDecommission, error) {
+	rows, err := s.q.ListStaleNodeWipes(ctx, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeDecommission, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeDecommission(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) AddWonderNetMember(ctx context.Context, arg AddWonderNetMemberParams) error {
+	return s.q.AddWonderNetMember(ctx, sqlcsqlite.AddWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+		Email:       arg.Email,
+		Role:        arg.Role,
+		AddedBy:     arg.AddedBy,
+	})
+}
+
+func (s *sqliteQueries) GetWonderNetMember(ctx context.Context, arg GetWonderNetMemberParams) (WonderNetMember, error) {
+	row, err := s.q.GetWonderNetMember(ctx, sqlcsqlite.GetWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+	if err != nil {
+		return WonderNetMember{}, err
+	}
+	return sqliteWonderNetMember(row), nil
+}
+
+func (s *sqliteQueries) ListWonderNetMembers(ctx context.Context, wonderNetID string) ([]WonderNetMember, error) {
+	rows, err := s.q.ListWonderNetMembers(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetMember, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNetMember(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListWonderNetMembershipsByUser(ctx context.Context, userID string) ([]WonderNetMember, error) {
+	rows, err := s.q.ListWonderNetMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetMember, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNetMember(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateWonderNetMemberRole(ctx context.Context, arg UpdateWonderNetMemberRoleParams) (int64, error) {
+	return s.q.UpdateWonderNetMemberRole(ctx, sqlcsqlite.UpdateWonderNetMemberRoleParams{
+		Role:        arg.Role,
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+}
+
+func (s *sqliteQueries) DeleteWonderNetMember(ctx context.Context, arg DeleteWonderNetMemberParams) (int64, error) {
+	return s.q.DeleteWonderNetMember(ctx, sqlcsqlite.DeleteWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+}
+
+func (s *sqliteQueries) CreateWonderNetInvite(ctx context.Context, arg CreateWonderNetInviteParams) (WonderNetInvite, error) {
+	row, err := s.q.CreateWonderNetInvite(ctx, sqlcsqlite.CreateWonderNetInviteParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Email:       arg.Email,
+		UserID:      arg.UserID,
+		Role:        arg.Role,
+		InvitedBy:   arg.InvitedBy,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return sqliteWonderNetInvite(row), nil
+}
+
+func (s *sqliteQueries) GetWonderNetInvite(ctx context.Context, id string) (WonderNetInvite, error) {
+	row, err := s.q.GetWonderNetInvite(ctx, id)
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return sqliteWonderNetInvite(row), nil
+}
+
+func (s *sqliteQueries) GetPendingWonderNetInvite(ctx context.Context, arg GetPendingWonderNetInviteParams) (WonderNetInvite, error) {
+	row, err := s.q.GetPendingWonderNetInvite(ctx, sqlcsqlite.GetPendingWonderNetInviteParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return sqliteWonderNetInvite(row), nil
+}
+
+func (s *sqliteQueries) ListPendingWonderNetInvitesByWonderNet(ctx context.Context, wonderNetID string) ([]WonderNetInvite, error) {
+	rows, err := s.q.ListPendingWonderNetInvitesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetInvite, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNetInvite(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListPendingWonderNetInvitesByUser(ctx context.Context, userID string) ([]WonderNetInvite, error) {
+	rows, err := s.q.ListPendingWonderNetInvitesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetInvite, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNetInvite(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DecideWonderNetInvite(ctx context.Context, arg DecideWonderNetInviteParams) (int64, error) {
+	return s.q.DecideWonderNetInvite(ctx, sqlcsqlite.DecideWonderNetInviteParams{
+		Status: arg.Status,
+		ID:     arg.ID,
+	})
+}
+
+func (s *sqliteQueries) CreateJoinToken(ctx context.Context, arg CreateJoinTokenParams) error {
+	return s.q.CreateJoinToken(ctx, sqlcsqlite.CreateJoinTokenParams{
+		Jti:          arg.Jti,
+		WonderNetID:  arg.WonderNetID,
+		CreatedBy:    arg.CreatedBy,
+		MaxUses:      arg.MaxUses,
+		ExpiresAt:    arg.ExpiresAt,
+		AllowedCidrs: arg.AllowedCidrs,
+	})
+}
+
+func (s *sqliteQueries) GetJoinToken(ctx context.Context, jti string) (JoinToken, error) {
+	row, err := s.q.GetJoinToken(ctx, jti)
+	if err != nil {
+		return JoinToken{}, err
+	}
+	return sqliteJoinToken(row), nil
+}
+
+func (s *sqliteQueries) UseJoinToken(ctx context.Context, jti string) (int64, error) {
+	return s.q.UseJoinToken(ctx, jti)
+}
+
+func (s *sqliteQueries) ReleaseJoinToken(ctx context.Context, jti string) error {
+	return s.q.ReleaseJoinToken(ctx, jti)
+}
+
+func (s *sqliteQueries) CreateWorkerToken(ctx context.Context, arg CreateWorkerTokenParams) error {
+	return s.q.CreateWorkerToken(ctx, sqlcsqlite.CreateWorkerTokenParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		AuthKeyID:   arg.AuthKeyID,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+}
+
+func (s *sqliteQueries) GetWorkerToken(ctx context.Context, id string) (WorkerToken, error) {
+	row, err := s.q.GetWorkerToken(ctx, id)
+	if err != nil {
+		return WorkerToken{}, err
+	}
+	return sqliteWorkerToken(row), nil
+}
+
+func (s *sqliteQueries) TouchWorkerToken(ctx context.Context, arg TouchWorkerTokenParams) error {
+	return s.q.TouchWorkerToken(ctx, sqlcsqlite.TouchWorkerTokenParams{
+		NodeID:    arg.NodeID,
+		ExpiresAt: arg.ExpiresAt,
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) UpdateWorkerTokenAuthKey(ctx context.Context, arg UpdateWorkerTokenAuthKeyParams) error {
+	return s.q.UpdateWorkerTokenAuthKey(ctx, sqlcsqlite.UpdateWorkerTokenAuthKeyParams{
+		AuthKeyID: arg.AuthKeyID,
+		ID:        arg.ID,
+	})
+}
+
+func (s *sqliteQueries) RevokeWorkerTokensByNode(ctx context.Context, arg RevokeWorkerTokensByNodeParams) error {
+	return s.q.RevokeWorkerTokensByNode(ctx, sqlcsqlite.RevokeWorkerTokensByNodeParams{
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		AuthKeyID:   arg.AuthKeyID,
+	})
+}
+
+func (s *sqliteQueries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row, err := s.q.CreateWebhook(ctx, sqlcsqlite.CreateWebhookParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Url:         arg.Url,
+		Secret:      arg.Secret,
+		Events:      arg.Events,
+		CreatedBy:   arg.CreatedBy,
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+	return sqliteWebhook(row), nil
+}
+
+func (s *sqliteQueries) GetWebhookByID(ctx context.Context, id string) (Webhook, error) {
+	row, err := s.q.GetWebhookByID(ctx, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return sqliteWebhook(row), nil
+}
+
+func (s *sqliteQueries) ListWebhooksByWonderNet(ctx context.Context, wonderNetID string) ([]Webhook, error) {
+	rows, err := s.q.ListWebhooksByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWebhook(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := s.q.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWebhook(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteWebhook(ctx context.Context, id string) error {
+	return s.q.DeleteWebhook(ctx, id)
+}
+
+func (s *sqliteQueries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	return s.q.CreateWebhookDelivery(ctx, sqlcsqlite.CreateWebhookDeliveryParams{
+		ID:            arg.ID,
+		WebhookID:     arg.WebhookID,
+		WonderNetID:   arg.WonderNetID,
+		Event:         arg.Event,
+		Payload:       arg.Payload,
+		NextAttemptAt: arg.NextAttemptAt,
+	})
+}
+
+func (s *sqliteQueries) ListDueWebhookDeliveries(ctx context.Context, nextAttemptAt time.Time) ([]WebhookDelivery, error) {
+	rows, err := s.q.ListDueWebhookDeliveries(ctx, nextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWebhookDelivery(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) ([]WebhookDelivery, error) {
+	rows, err := s.q.ListWebhookDeliveriesByWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWebhookDelivery(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error {
+	return s.q.RecordWebhookDeliveryAttempt(ctx, sqlcsqlite.RecordWebhookDeliveryAttemptParams{
+		Status:         arg.Status,
+		Attempts:       arg.Attempts,
+		ResponseStatus: arg.ResponseStatus,
+		LastError:      arg.LastError,
+		NextAttemptAt:  arg.NextAttemptAt,
+		DeliveredAt:    arg.DeliveredAt,
+		ID:             arg.ID,
+	})
+}
+
+func (s *sqliteQueries) DeleteWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) error {
+	return s.q.DeleteWebhookDeliveriesByWebhook(ctx, webhookID)
+}
+
+func (s *sqliteQueries) GetWonderNetQuotas(ctx context.Context, wonderNetID string) (WonderNetQuota, error) {
+	row, err := s.q.GetWonderNetQuotas(ctx, wonderNetID)
+	if err != nil {
+		return WonderNetQuota{}, err
+	}
+	return sqliteWonderNetQuota(row), nil
+}
+
+func (s *sqliteQueries) UpsertWonderNetQuotas(ctx context.Context, arg UpsertWonderNetQuotasParams) (WonderNetQuota, error) {
+	row, err := s.q.UpsertWonderNetQuotas(ctx, sqlcsqlite.UpsertWonderNetQuotasParams{
+		WonderNetID:     arg.WonderNetID,
+		MaxNodes:        arg.MaxNodes,
+		MaxApiKeys:      arg.MaxApiKeys,
+		AuthkeysPerHour: arg.AuthkeysPerHour,
+	})
+	if err != nil {
+		return WonderNetQuota{}, err
+	}
+	return sqliteWonderNetQuota(row), nil
+}
+
+func (s *sqliteQueries) DeleteWonderNetQuotas(ctx context.Context, wonderNetID string) error {
+	return s.q.DeleteWonderNetQuotas(ctx, wonderNetID)
+}
+
+func (s *sqliteQueries) GetDNSDomain(ctx context.Context, wonderNetID string) (DNSDomain, error) {
+	row, err := s.q.GetDNSDomain(ctx, wonderNetID)
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return sqliteDNSDomain(row), nil
+}
+
+func (s *sqliteQueries) GetDNSDomainByName(ctx context.Context, domain string) (DNSDomain, error) {
+	row, err := s.q.GetDNSDomainByName(ctx, domain)
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return sqliteDNSDomain(row), nil
+}
+
+func (s *sqliteQueries) ListDNSDomains(ctx context.Context) ([]DNSDomain, error) {
+	rows, err := s.q.ListDNSDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSDomain, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteDNSDomain(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpsertDNSDomain(ctx context.Context, arg UpsertDNSDomainParams) (DNSDomain, error) {
+	row, err := s.q.UpsertDNSDomain(ctx, sqlcsqlite.UpsertDNSDomainParams{
+		WonderNetID: arg.WonderNetID,
+		Domain:      arg.Domain,
+	})
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return sqliteDNSDomain(row), nil
+}
+
+func (s *sqliteQueries) CreateDNSRecord(ctx context.Context, arg CreateDNSRecordParams) (DNSRecord, error) {
+	row, err := s.q.CreateDNSRecord(ctx, sqlcsqlite.CreateDNSRecordParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		Type:        arg.Type,
+		Value:       arg.Value,
+		CreatedBy:   arg.CreatedBy,
+	})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	return sqliteDNSRecord(row), nil
+}
+
+func (s *sqliteQueries) GetDNSRecordByID(ctx context.Context, id string) (DNSRecord, error) {
+	row, err := s.q.GetDNSRecordByID(ctx, id)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	return sqliteDNSRecord(row), nil
+}
+
+func (s *sqliteQueries) ListDNSRecordsByWonderNet(ctx context.Context, wonderNetID string) ([]DNSRecord, error) {
+	rows, err := s.q.ListDNSRecordsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSRecord, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteDNSRecord(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListDNSRecords(ctx context.Context) ([]DNSRecord, error) {
+	rows, err := s.q.ListDNSRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSRecord, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteDNSRecord(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteDNSRecord(ctx context.Context, id string) error {
+	return s.q.DeleteDNSRecord(ctx, id)
+}
+
+func (s *sqliteQueries) CreateNodeApproval(ctx context.Context, arg CreateNodeApprovalParams) error {
+	return s.q.CreateNodeApproval(ctx, sqlcsqlite.CreateNodeApprovalParams{
+		NodeID:      arg.NodeID,
+		WonderNetID: arg.WonderNetID,
+		Status:      arg.Status,
+		DecidedBy:   arg.DecidedBy,
+		DecidedAt:   arg.DecidedAt,
+	})
+}
+
+func (s *sqliteQueries) GetNodeApproval(ctx context.Context, nodeID string) (NodeApproval, error) {
+	row, err := s.q.GetNodeApproval(ctx, nodeID)
+	if err != nil {
+		return NodeApproval{}, err
+	}
+	return sqliteNodeApproval(row), nil
+}
+
+func (s *sqliteQueries) ListNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error) {
+	rows, err := s.q.ListNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeApproval, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeApproval(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListPendingNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error) {
+	rows, err := s.q.ListPendingNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeApproval, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeApproval(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DecideNodeApproval(ctx context.Context, arg DecideNodeApprovalParams) error {
+	return s.q.DecideNodeApproval(ctx, sqlcsqlite.DecideNodeApprovalParams{
+		Status:    arg.Status,
+		DecidedBy: arg.DecidedBy,
+		NodeID:    arg.NodeID,
+	})
+}
+
+func (s *sqliteQueries) DeleteNodeApproval(ctx context.Context, nodeID string) error {
+	return s.q.DeleteNodeApproval(ctx, nodeID)
+}
+
+func (s *sqliteQueries) UpsertNodeName(ctx context.Context, arg UpsertNodeNameParams) error {
+	return s.q.UpsertNodeName(ctx, sqlcsqlite.UpsertNodeNameParams{
+		NodeID:      arg.NodeID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		RenamedBy:   arg.RenamedBy,
+	})
+}
+
+func (s *sqliteQueries) ListNodeNamesByWonderNet(ctx context.Context, wonderNetID string) ([]NodeName, error) {
+	rows, err := s.q.ListNodeNamesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeName, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNodeName(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteNodeName(ctx context.Context, nodeID string) error {
+	return s.q.DeleteNodeName(ctx, nodeID)
+}
+
+func (s *sqliteQueries) CreateExecSession(ctx context.Context, arg CreateExecSessionParams) (ExecSession, error) {
+	row, err := s.q.CreateExecSession(ctx, sqlcsqlite.CreateExecSessionParams{
+		ID:              arg.ID,
+		WonderNetID:     arg.WonderNetID,
+		NodeID:          arg.NodeID,
+		NodeName:        arg.NodeName,
+		Address:         arg.Address,
+		Command:         arg.Command,
+		Stdout:          arg.Stdout,
+		Stderr:          arg.Stderr,
+		OutputTruncated: arg.OutputTruncated,
+		ExitCode:        arg.ExitCode,
+		Error:           arg.Error,
+		RecordedBy:      arg.RecordedBy,
+		StartedAt:       arg.StartedAt,
+		DurationMs:      arg.DurationMs,
+	})
+	if err != nil {
+		return ExecSession{}, err
+	}
+	return sqliteExecSession(row), nil
+}
+
+func (s *sqliteQueries) GetExecSessionByID(ctx context.Context, id string) (ExecSession, error) {
+	row, err := s.q.GetExecSessionByID(ctx, id)
+	if err != nil {
+		return ExecSession{}, err
+	}
+	return sqliteExecSession(row), nil
+}
+
+func (s *sqliteQueries) ListExecSessionsByWonderNet(ctx context.Context, arg ListExecSessionsByWonderNetParams) ([]ExecSession, error) {
+	rows, err := s.q.ListExecSessionsByWonderNet(ctx, sqlcsqlite.ListExecSessionsByWonderNetParams{
+		WonderNetID: arg.WonderNetID,
+		Limit:       int64(arg.Limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ExecSession, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteExecSession(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteExecSessionsBefore(ctx context.Context, startedAt time.Time) (int64, error) {
+	return s.q.DeleteExecSessionsBefore(ctx, startedAt)
+}
+
+func (s *sqliteQueries) UpdateWonderNetNodeExpiryDays(ctx context.Context, arg UpdateWonderNetNodeExpiryDaysParams) error {
+	return s.q.UpdateWonderNetNodeExpiryDays(ctx, sqlcsqlite.UpdateWonderNetNodeExpiryDaysParams{
+		NodeExpiryDays: arg.NodeExpiryDays,
+		ID:             arg.ID,
+	})
+}
+
+func (s *sqliteQueries) UpdateWonderNetJoinTokenTTL(ctx context.Context, arg UpdateWonderNetJoinTokenTTLParams) error {
+	return s.q.UpdateWonderNetJoinTokenTTL(ctx, sqlcsqlite.UpdateWonderNetJoinTokenTTLParams{
+		JoinTokenDefaultTtlSeconds: arg.JoinTokenDefaultTTLSeconds,
+		JoinTokenMaxTtlSeconds:     arg.JoinTokenMaxTTLSeconds,
+		ID:                         arg.ID,
+	})
+}
+
+func (s *sqliteQueries) ListWonderNetsWithNodeExpiry(ctx context.Context) ([]WonderNet, error) {
+	rows, err := s.q.ListWonderNetsWithNodeExpiry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteWonderNet(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) CreateNotificationChannel(ctx context.Context, arg CreateNotificationChannelParams) (NotificationChannel, error) {
+	row, err := s.q.CreateNotificationChannel(ctx, sqlcsqlite.CreateNotificationChannelParams{
+		ID:            arg.ID,
+		WonderNetID:   arg.WonderNetID,
+		Kind:          arg.Kind,
+		Target:        arg.Target,
+		Events:        arg.Events,
+		DigestSeconds: arg.DigestSeconds,
+		CreatedBy:     arg.CreatedBy,
+	})
+	if err != nil {
+		return NotificationChannel{}, err
+	}
+	return sqliteNotificationChannel(row), nil
+}
+
+func (s *sqliteQueries) GetNotificationChannelByID(ctx context.Context, id string) (NotificationChannel, error) {
+	row, err := s.q.GetNotificationChannelByID(ctx, id)
+	if err != nil {
+		return NotificationChannel{}, err
+	}
+	return sqliteNotificationChannel(row), nil
+}
+
+func (s *sqliteQueries) ListNotificationChannelsByWonderNet(ctx context.Context, wonderNetID string) ([]NotificationChannel, error) {
+	rows, err := s.q.ListNotificationChannelsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NotificationChannel, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNotificationChannel(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListNotificationChannels(ctx context.Context) ([]NotificationChannel, error) {
+	rows, err := s.q.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NotificationChannel, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteNotificationChannel(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) DeleteNotificationChannel(ctx context.Context, id string) error {
+	return s.q.DeleteNotificationChannel(ctx, id)
+}
+
+func (s *sqliteQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row, err := s.q.CreateUser(ctx, sqlcsqlite.CreateUserParams{
+		ID:           arg.ID,
+		Provider:     arg.Provider,
+		Subject:      arg.Subject,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		Name:         arg.Name,
+		PasswordHash: arg.PasswordHash,
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return sqliteUser(row), nil
+}
+
+func (s *sqliteQueries) GetUserByID(ctx context.Context, id string) (User, error) {
+	row, err := s.q.GetUserByID(ctx, id)
+	if err != nil {
+		return User{}, err
+	}
+	return sqliteUser(row), nil
+}
+
+func (s *sqliteQueries) GetUserByProviderSubject(ctx context.Context, arg GetUserByProviderSubjectParams) (User, error) {
+	row, err := s.q.GetUserByProviderSubject(ctx, sqlcsqlite.GetUserByProviderSubjectParams{
+		Provider: arg.Provider,
+		Subject:  arg.Subject,
+	})
+	if err != nil {
+		return User{}, err
+	}
+	return sqliteUser(row), nil
+}
+
+func (s *sqliteQueries) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	rows, err := s.q.ListUsersByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]User, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteUser(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := s.q.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]User, len(rows))
+	for i, row := range rows {
+		items[i] = sqliteUser(row)
+	}
+	return items, nil
+}
+
+func (s *sqliteQueries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	return s.q.UpdateUserProfile(ctx, sqlcsqlite.UpdateUserProfileParams{
+		Username: arg.Username,
+		Email:    arg.Email,
+		Name:     arg.Name,
+		ID:       arg.ID,
+	})
+}
+
+func (s *sqliteQueries) UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPasswordHashParams) error {
+	return s.q.UpdateUserPasswordHash(ctx, sqlcsqlite.UpdateUserPasswordHashParams{
+		PasswordHash: arg.PasswordHash,
+		ID:           arg.ID,
+	})
+}
+
+func (s *sqliteQueries) DeleteUser(ctx context.Context, id string) error {
+	return s.q.DeleteUser(ctx, id)
+}
+
+func sqliteWonderNet(row sqlcsqlite.WonderNet) WonderNet {
+	return WonderNet{
+		ID:                         row.ID,
+		OwnerID:                    row.OwnerID,
+		HeadscaleUser:              row.HeadscaleUser,
+		DisplayName:                row.DisplayName,
+		MeshType:                   row.MeshType,
+		IsDefault:                  row.IsDefault,
+		NodeNameTemplate:           row.NodeNameTemplate,
+		ACLRules:                   row.AclRules,
+		PublicURL:                  row.PublicUrl,
+		RequireNodeApproval:        row.RequireNodeApproval,
+		NodeExpiryDays:             row.NodeExpiryDays,
+		JoinTokenDefaultTTLSeconds: row.JoinTokenDefaultTtlSeconds,
+		JoinTokenMaxTTLSeconds:     row.JoinTokenMaxTtlSeconds,
+		CreatedAt:                  row.CreatedAt,
+		UpdatedAt:                  row.UpdatedAt,
+	}
+}
+
+func sqliteAPIKey(row sqlcsqlite.ApiKey) APIKey {
+	return APIKey{
+		ID:           row.ID,
+		WonderNetID:  row.WonderNetID,
+		Name:         row.Name,
+		KeyHash:      row.KeyHash,
+		KeyPrefix:    row.KeyPrefix,
+		CreatedAt:    row.CreatedAt,
+		LastUsedAt:   row.LastUsedAt,
+		ExpiresAt:    row.ExpiresAt,
+		Scopes:       row.Scopes,
+		AllowedCidrs: row.AllowedCidrs,
+		DeprecatedAt: row.DeprecatedAt,
+		SuccessorID:  row.SuccessorID,
+	}
+}
+
+func sqliteAlertRule(row sqlcsqlite.AlertRule) AlertRule {
+	return AlertRule{
+		ID:               row.ID,
+		WonderNetID:      row.WonderNetID,
+		Name:             row.Name,
+		Kind:             row.Kind,
+		ThresholdSeconds: row.ThresholdSeconds,
+		CreatedAt:        row.CreatedAt,
+	}
+}
+
+func sqliteAlertSilence(row sqlcsqlite.AlertSilence) AlertSilence {
+	return AlertSilence{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		RuleID:      row.RuleID,
+		NodeName:    row.NodeName,
+		Reason:      row.Reason,
+		EndsAt:      row.EndsAt,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func sqliteFiringAlert(row sqlcsqlite.FiringAlert) FiringAlert {
+	return FiringAlert{
+		RuleID:      row.RuleID,
+		NodeName:    row.NodeName,
+		WonderNetID: row.WonderNetID,
+		NodeID:      row.NodeID,
+		StartsAt:    row.StartsAt,
+		Silenced:    row.Silenced,
+	}
+}
+
+func sqliteService(row sqlcsqlite.Service) Service {
+	return Service{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		NodeID:      row.NodeID,
+		Port:        int32(row.Port),
+		Protocol:    row.Protocol,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func sqliteServiceGrant(row sqlcsqlite.ServiceGrant) ServiceGrant {
+	return ServiceGrant{
+		ID:        row.ID,
+		ServiceID: row.ServiceID,
+		Subject:   row.Subject,
+		CreatedAt: row.CreatedAt,
+	}
+}
+
+func sqliteAccessRequest(row sqlcsqlite.AccessRequest) AccessRequest {
+	return AccessRequest{
+		ID:              row.ID,
+		WonderNetID:     row.WonderNetID,
+		ServiceID:       row.ServiceID,
+		ServiceName:     row.ServiceName,
+		Subject:         row.Subject,
+		Requester:       row.Requester,
+		Reason:          row.Reason,
+		DurationSeconds: row.DurationSeconds,
+		Status:          row.Status,
+		DecidedBy:       row.DecidedBy,
+		GrantID:         row.GrantID,
+		DecidedAt:       row.DecidedAt,
+		ExpiresAt:       row.ExpiresAt,
+		EndedAt:         row.EndedAt,
+		CreatedAt:       row.CreatedAt,
+	}
+}
+
+func sqliteAPIKeyUsage(row sqlcsqlite.ApiKeyUsage) APIKeyUsage {
+	return APIKeyUsage{
+		APIKeyID:     row.ApiKeyID,
+		Endpoint:     row.Endpoint,
+		RequestCount: row.RequestCount,
+		LastUsedAt:   row.LastUsedAt,
+	}
+}
+
+func sqliteNodeHeartbeat(row sqlcsqlite.NodeHeartbeat) NodeHeartbeat {
+	return NodeHeartbeat{
+		NodeID:               row.NodeID,
+		WonderNetID:          row.WonderNetID,
+		OS:                   row.Os,
+		TailscaleVersion:     row.TailscaleVersion,
+		DiskTotalBytes:       row.DiskTotalBytes,
+		DiskFreeBytes:        row.DiskFreeBytes,
+		MemoryTotalBytes:     row.MemoryTotalBytes,
+		MemoryAvailableBytes: row.MemoryAvailableBytes,
+		Connectivity:         row.Connectivity,
+		ReportedAt:           row.ReportedAt,
+	}
+}
+
+func sqliteNodeDecommission(row sqlcsqlite.NodeDecommission) NodeDecommission {
+	return NodeDecommission{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		NodeID:      row.NodeID,
+		NodeName:    row.NodeName,
+		Hostname:    row.Hostname,
+		Addresses:   row.Addresses,
+		OS:          row.Os,
+		LastSeenAt:  row.LastSeenAt,
+		RequestedBy: row.RequestedBy,
+		Reason:      row.Reason,
+		Status:      row.Status,
+		WipeStatus:  row.WipeStatus,
+		WipeOutput:  row.WipeOutput,
+		Error:       row.Error,
+		CreatedAt:   row.CreatedAt,
+		CompletedAt: row.CompletedAt,
+	}
+}
+
+func sqliteWonderNetMember(row sqlcsqlite.WonderNetMember) WonderNetMember {
+	return WonderNetMember{
+		WonderNetID: row.WonderNetID,
+		UserID:      row.UserID,
+		Email:       row.Email,
+		Role:        row.Role,
+		AddedBy:     row.AddedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func sqliteWonderNetInvite(row sqlcsqlite.WonderNetInvite) WonderNetInvite {
+	return WonderNetInvite{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Email:       row.Email,
+		UserID:      row.UserID,
+		Role:        row.Role,
+		InvitedBy:   row.InvitedBy,
+		Status:      row.Status,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+		DecidedAt:   row.DecidedAt,
+	}
+}
+
+func sqliteJoinToken(row sqlcsqlite.JoinToken) JoinToken {
+	return JoinToken{
+		Jti:          row.Jti,
+		WonderNetID:  row.WonderNetID,
+		CreatedBy:    row.CreatedBy,
+		MaxUses:      row.MaxUses,
+		Uses:         row.Uses,
+		CreatedAt:    row.CreatedAt,
+		ExpiresAt:    row.ExpiresAt,
+		LastUsedAt:   row.LastUsedAt,
+		AllowedCidrs: row.AllowedCidrs,
+	}
+}
+
+func sqliteWorkerToken(row sqlcsqlite.WorkerToken) WorkerToken {
+	return WorkerToken{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		AuthKeyID:   row.AuthKeyID,
+		NodeID:      row.NodeID,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+		LastUsedAt:  row.LastUsedAt,
+		RevokedAt:   row.RevokedAt,
+	}
+}
+
+func sqliteWebhook(row sqlcsqlite.Webhook) Webhook {
+	return Webhook{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Url:         row.Url,
+		Secret:      row.Secret,
+		Events:      row.Events,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func sqliteWebhookDelivery(row sqlcsqlite.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             row.ID,
+		WebhookID:      row.WebhookID,
+		WonderNetID:    row.WonderNetID,
+		Event:          row.Event,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		ResponseStatus: row.ResponseStatus,
+		LastError:      row.LastError,
+		NextAttemptAt:  row.NextAttemptAt,
+		CreatedAt:      row.CreatedAt,
+		DeliveredAt:    row.DeliveredAt,
+	}
+}
+
+func sqliteWonderNetQuota(row sqlcsqlite.WonderNetQuota) WonderNetQuota {
+	return WonderNetQuota{
+		WonderNetID:     row.WonderNetID,
+		MaxNodes:        row.MaxNodes,
+		MaxApiKeys:      row.MaxApiKeys,
+		AuthkeysPerHour: row.AuthkeysPerHour,
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
+
+func sqliteDNSDomain(row sqlcsqlite.DnsDomain) DNSDomain {
+	return DNSDomain{
+		WonderNetID: row.WonderNetID,
+		Domain:      row.Domain,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func sqliteDNSRecord(row sqlcsqlite.DnsRecord) DNSRecord {
+	return DNSRecord{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		Type:        row.Type,
+		Value:       row.Value,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func sqliteNodeApproval(row sqlcsqlite.NodeApproval) NodeApproval {
+	return NodeApproval{
+		NodeID:      row.NodeID,
+		WonderNetID: row.WonderNetID,
+		Status:      row.Status,
+		DecidedBy:   row.DecidedBy,
+		CreatedAt:   row.CreatedAt,
+		DecidedAt:   row.DecidedAt,
+	}
+}
+
+func sqliteNodeName(row sqlcsqlite.NodeName) NodeName {
+	return NodeName{
+		NodeID:      row.NodeID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		RenamedBy:   row.RenamedBy,
+		RenamedAt:   row.RenamedAt,
+	}
+}
+
+func sqliteExecSession(row sqlcsqlite.ExecSession) ExecSession {
+	return ExecSession{
+		ID:              row.ID,
+		WonderNetID:     row.WonderNetID,
+		NodeID:          row.NodeID,
+		NodeName:        row.NodeName,
+		Address:         row.Address,
+		Command:         row.Command,
+		Stdout:          row.Stdout,
+		Stderr:          row.Stderr,
+		OutputTruncated: row.OutputTruncated,
+		ExitCode:        row.ExitCode,
+		Error:           row.Error,
+		RecordedBy:      row.RecordedBy,
+		StartedAt:       row.StartedAt,
+		DurationMs:      row.DurationMs,
+		CreatedAt:       row.CreatedAt,
+	}
+}
+
+func sqliteNotificationChannel(row sqlcsqlite.NotificationChannel) NotificationChannel {
+	return NotificationChannel{
+		ID:            row.ID,
+		WonderNetID:   row.WonderNetID,
+		Kind:          row.Kind,
+		Target:        row.Target,
+		Events:        row.Events,
+		DigestSeconds: row.DigestSeconds,
+		CreatedBy:     row.CreatedBy,
+		CreatedAt:     row.CreatedAt,
+	}
+}
+
+func sqliteUser(row sqlcsqlite.User) User {
+	return User{
+		ID:           row.ID,
+		Provider:     row.Provider,
+		Subject:      row.Subject,
+		Username:     row.Username,
+		Email:        row.Email,
+		Name:         row.Name,
+		PasswordHash: row.PasswordHash,
+		CreatedAt:    row.CreatedAt,
+	}
+}
+
+type postgresQueries struct {
+	db *sql.DB
+	q  *sqlcpostgres.Queries
+}
+
+func (p *postgresQueries) InTx(ctx context.Context, fn func(Queries) error) error {
+	return inTx(ctx, p.db, func(tx *sql.Tx) error {
+		return fn(&postgresQueries{db: p.db, q: p.q.WithTx(tx)})
+	})
+}
+
+func (p *postgresQueries) CreateWonderNet(ctx context.Context, arg CreateWonderNetParams) error {
+	return p.q.CreateWonderNet(ctx, sqlcpostgres.CreateWonderNetParams{
+		ID:            arg.ID,
+		OwnerID:       arg.OwnerID,
+		HeadscaleUser: arg.HeadscaleUser,
+		DisplayName:   arg.DisplayName,
+		MeshType:      arg.MeshType,
+	})
+}
+
+func (p *postgresQueries) GetWonderNet(ctx context.Context, id string) (WonderNet, error) {
+	row, err := p.q.GetWonderNet(ctx, id)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return postgresWonderNet(row), nil
+}
+
+func (p *postgresQueries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error) {
+	row, err := p.q.GetWonderNetByHeadscaleUser(ctx, headscaleUser)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return postgresWonderNet(row), nil
+}
+
+func (p *postgresQueries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams) error {
+	return p.q.UpdateWonderNet(ctx, sqlcpostgres.UpdateWonderNetParams{
+		DisplayName: arg.DisplayName,
+		ID:          arg.ID,
+	})
+}
+
+func (p *postgresQueries) DeleteWonderNet(ctx context.Context, id string) error {
+	return p.q.DeleteWonderNet(ctx, id)
+}
+
+func (p *postgresQueries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNets(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) CountWonderNets(ctx context.Context) (int64, error) {
+	return p.q.CountWonderNets(ctx)
+}
+
+func (p *postgresQueries) CountWonderNetsByMeshType(ctx context.Context) ([]MeshTypeCount, error) {
+	rows, err := p.q.CountWonderNetsByMeshType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]MeshTypeCount, len(rows))
+	for i, row := range rows {
+		items[i] = MeshTypeCount{MeshType: row.MeshType, Count: row.Count}
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNetNodeNameTemplate(ctx context.Context, arg UpdateWonderNetNodeNameTemplateParams) error {
+	return p.q.UpdateWonderNetNodeNameTemplate(ctx, sqlcpostgres.UpdateWonderNetNodeNameTemplateParams{
+		NodeNameTemplate: arg.NodeNameTemplate,
+		ID:               arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListWonderNetsWithNodeNameTemplate(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsWithNodeNameTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNetACLRules(ctx context.Context, arg UpdateWonderNetACLRulesParams) error {
+	return p.q.UpdateWonderNetACLRules(ctx, sqlcpostgres.UpdateWonderNetACLRulesParams{
+		AclRules: arg.ACLRules,
+		ID:       arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListWonderNetsWithACLRules(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsWithACLRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNetPublicURL(ctx context.Context, arg UpdateWonderNetPublicURLParams) error {
+	return p.q.UpdateWonderNetPublicURL(ctx, sqlcpostgres.UpdateWonderNetPublicURLParams{
+		PublicUrl: arg.PublicURL,
+		ID:        arg.ID,
+	})
+}
+
+func (p *postgresQueries) GetWonderNetByPublicURL(ctx context.Context, publicURL string) (WonderNet, error) {
+	row, err := p.q.GetWonderNetByPublicURL(ctx, publicURL)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return postgresWonderNet(row), nil
+}
+
+func (p *postgresQueries) ListWonderNetsWithPublicURL(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsWithPublicURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNetRequireNodeApproval(ctx context.Context, arg UpdateWonderNetRequireNodeApprovalParams) error {
+	return p.q.UpdateWonderNetRequireNodeApproval(ctx, sqlcpostgres.UpdateWonderNetRequireNodeApprovalParams{
+		RequireNodeApproval: arg.RequireNodeApproval,
+		ID:                  arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListWonderNetsRequiringNodeApproval(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsRequiringNodeApproval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) GetDefaultWonderNetByOwner(ctx context.Context, ownerID string) (WonderNet, error) {
+	row, err := p.q.GetDefaultWonderNetByOwner(ctx, ownerID)
+	if err != nil {
+		return WonderNet{}, err
+	}
+	return postgresWonderNet(row), nil
+}
+
+func (p *postgresQueries) SetDefaultWonderNet(ctx context.Context, arg SetDefaultWonderNetParams) error {
+	return p.q.SetDefaultWonderNet(ctx, sqlcpostgres.SetDefaultWonderNetParams{
+		ID:      arg.ID,
+		OwnerID: arg.OwnerID,
+	})
+}
+
+func (p *postgresQueries) ListWonderNetsPage(ctx context.Context, arg ListWonderNetsPageParams) ([]WonderNet, error) {
+	var rows []sqlcpostgres.WonderNet
+	var err error
+	if arg.Ascending {
+		rows, err = p.q.ListWonderNetsPageAsc(ctx, sqlcpostgres.ListWonderNetsPageAscParams{
+			OwnerID:         arg.OwnerID,
+			MeshType:        arg.MeshType,
+			CreatedAfter:    arg.CreatedAfter,
+			CreatedBefore:   arg.CreatedBefore,
+			CursorCreatedAt: arg.CursorCreatedAt,
+			CursorID:        arg.CursorID,
+			Limit:           arg.Limit,
+		})
+	} else {
+		rows, err = p.q.ListWonderNetsPageDesc(ctx, sqlcpostgres.ListWonderNetsPageDescParams{
+			OwnerID:         arg.OwnerID,
+			MeshType:        arg.MeshType,
+			CreatedAfter:    arg.CreatedAfter,
+			CreatedBefore:   arg.CreatedBefore,
+			CursorCreatedAt: arg.CursorCreatedAt,
+			CursorID:        arg.CursorID,
+			Limit:           arg.Limit,
+		})
+	}
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNet, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNet(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
+	row, err := p.q.CreateAPIKey(ctx, sqlcpostgres.CreateAPIKeyParams{
+		ID:           arg.ID,
+		WonderNetID:  arg.WonderNetID,
+		Name:         arg.Name,
+		KeyHash:      arg.KeyHash,
+		KeyPrefix:    arg.KeyPrefix,
+		ExpiresAt:    arg.ExpiresAt,
+		Scopes:       arg.Scopes,
+		AllowedCidrs: arg.AllowedCidrs,
+	})
+	if err != nil {
+		return APIKey{}, err
+	}
+	return postgresAPIKey(row), nil
+}
+
+func (p *postgresQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
+	row, err := p.q.GetAPIKeyByHash(ctx, keyHash)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return postgresAPIKey(row), nil
+}
+
+func (p *postgresQueries) GetAPIKeyByID(ctx context.Context, id string) (APIKey, error) {
+	row, err := p.q.GetAPIKeyByID(ctx, id)
+	if err != nil {
+		return APIKey{}, err
+	}
+	return postgresAPIKey(row), nil
+}
+
+func (p *postgresQueries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]APIKey, error) {
+	rows, err := p.q.ListAPIKeysByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIKey, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAPIKey(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteAPIKey(ctx context.Context, id string) error {
+	return p.q.DeleteAPIKey(ctx, id)
+}
+
+func (p *postgresQueries) UpdateAPIKeyLastUsed(ctx context.Context, id string) error {
+	return p.q.UpdateAPIKeyLastUsed(ctx, id)
+}
+
+func (p *postgresQueries) DeprecateAPIKey(ctx context.Context, arg DeprecateAPIKeyParams) error {
+	return p.q.DeprecateAPIKey(ctx, sqlcpostgres.DeprecateAPIKeyParams{
+		SuccessorID: arg.SuccessorID,
+		ExpiresAt:   arg.ExpiresAt,
+		ID:          arg.ID,
+	})
+}
+
+func (p *postgresQueries) CreateAlertRule(ctx context.Context, arg CreateAlertRuleParams) (AlertRule, error) {
+	row, err := p.q.CreateAlertRule(ctx, sqlcpostgres.CreateAlertRuleParams{
+		ID:               arg.ID,
+		WonderNetID:      arg.WonderNetID,
+		Name:             arg.Name,
+		Kind:             arg.Kind,
+		ThresholdSeconds: arg.ThresholdSeconds,
+	})
+	if err != nil {
+		return AlertRule{}, err
+	}
+	return postgresAlertRule(row), nil
+}
+
+func (p *postgresQueries) GetAlertRuleByID(ctx context.Context, id string) (AlertRule, error) {
+	row, err := p.q.GetAlertRuleByID(ctx, id)
+	if err != nil {
+		return AlertRule{}, err
+	}
+	return postgresAlertRule(row), nil
+}
+
+func (p *postgresQueries) ListAlertRulesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertRule, error) {
+	rows, err := p.q.ListAlertRulesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertRule, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAlertRule(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListAlertRules(ctx context.Context) ([]AlertRule, error) {
+	rows, err := p.q.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertRule, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAlertRule(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteAlertRule(ctx context.Context, id string) error {
+	return p.q.DeleteAlertRule(ctx, id)
+}
+
+func (p *postgresQueries) CreateAlertSilence(ctx context.Context, arg CreateAlertSilenceParams) (AlertSilence, error) {
+	row, err := p.q.CreateAlertSilence(ctx, sqlcpostgres.CreateAlertSilenceParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		RuleID:      arg.RuleID,
+		NodeName:    arg.NodeName,
+		Reason:      arg.Reason,
+		EndsAt:      arg.EndsAt,
+	})
+	if err != nil {
+		return AlertSilence{}, err
+	}
+	return postgresAlertSilence(row), nil
+}
+
+func (p *postgresQueries) GetAlertSilenceByID(ctx context.Context, id string) (AlertSilence, error) {
+	row, err := p.q.GetAlertSilenceByID(ctx, id)
+	if err != nil {
+		return AlertSilence{}, err
+	}
+	return postgresAlertSilence(row), nil
+}
+
+func (p *postgresQueries) ListAlertSilencesByWonderNet(ctx context.Context, wonderNetID string) ([]AlertSilence, error) {
+	rows, err := p.q.ListAlertSilencesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AlertSilence, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAlertSilence(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteAlertSilence(ctx context.Context, id string) error {
+	return p.q.DeleteAlertSilence(ctx, id)
+}
+
+func (p *postgresQueries) ListFiringAlerts(ctx context.Context) ([]FiringAlert, error) {
+	rows, err := p.q.ListFiringAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]FiringAlert, len(rows))
+	for i, row := range rows {
+		items[i] = postgresFiringAlert(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpsertFiringAlert(ctx context.Context, arg UpsertFiringAlertParams) error {
+	return p.q.UpsertFiringAlert(ctx, sqlcpostgres.UpsertFiringAlertParams{
+		RuleID:      arg.RuleID,
+		NodeName:    arg.NodeName,
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		StartsAt:    arg.StartsAt,
+		Silenced:    arg.Silenced,
+	})
+}
+
+func (p *postgresQueries) DeleteFiringAlert(ctx context.Context, arg DeleteFiringAlertParams) error {
+	return p.q.DeleteFiringAlert(ctx, sqlcpostgres.DeleteFiringAlertParams{
+		RuleID:   arg.RuleID,
+		NodeName: arg.NodeName,
+	})
+}
+
+func (p *postgresQueries) DeleteFiringAlertsByRule(ctx context.Context, ruleID string) error {
+	return p.q.DeleteFiringAlertsByRule(ctx, ruleID)
+}
+
+func (p *postgresQueries) CreateService(ctx context.Context, arg CreateServiceParams) (Service, error) {
+	row, err := p.q.CreateService(ctx, sqlcpostgres.CreateServiceParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		NodeID:      arg.NodeID,
+		Port:        arg.Port,
+		Protocol:    arg.Protocol,
+	})
+	if err != nil {
+		return Service{}, err
+	}
+	return postgresService(row), nil
+}
+
+func (p *postgresQueries) GetServiceByID(ctx context.Context, id string) (Service, error) {
+	row, err := p.q.GetServiceByID(ctx, id)
+	if err != nil {
+		return Service{}, err
+	}
+	return postgresService(row), nil
+}
+
+func (p *postgresQueries) ListServicesByWonderNet(ctx context.Context, wonderNetID string) ([]Service, error) {
+	rows, err := p.q.ListServicesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Service, len(rows))
+	for i, row := range rows {
+		items[i] = postgresService(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListServices(ctx context.Context) ([]Service, error) {
+	rows, err := p.q.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Service, len(rows))
+	for i, row := range rows {
+		items[i] = postgresService(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteService(ctx context.Context, id string) error {
+	return p.q.DeleteService(ctx, id)
+}
+
+func (p *postgresQueries) CreateServiceGrant(ctx context.Context, arg CreateServiceGrantParams) (ServiceGrant, error) {
+	row, err := p.q.CreateServiceGrant(ctx, sqlcpostgres.CreateServiceGrantParams{
+		ID:        arg.ID,
+		ServiceID: arg.ServiceID,
+		Subject:   arg.Subject,
+	})
+	if err != nil {
+		return ServiceGrant{}, err
+	}
+	return postgresServiceGrant(row), nil
+}
+
+func (p *postgresQueries) GetServiceGrantByID(ctx context.Context, id string) (ServiceGrant, error) {
+	row, err := p.q.GetServiceGrantByID(ctx, id)
+	if err != nil {
+		return ServiceGrant{}, err
+	}
+	return postgresServiceGrant(row), nil
+}
+
+func (p *postgresQueries) ListServiceGrantsByWonderNet(ctx context.Context, wonderNetID string) ([]ServiceGrant, error) {
+	rows, err := p.q.ListServiceGrantsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]ServiceGrant, len(rows))
+	for i, row := range rows {
+		items[i] = postgresServiceGrant(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteServiceGrant(ctx context.Context, id string) error {
+	return p.q.DeleteServiceGrant(ctx, id)
+}
+
+func (p *postgresQueries) DeleteServiceGrantsByService(ctx context.Context, serviceID string) error {
+	return p.q.DeleteServiceGrantsByService(ctx, serviceID)
+}
+
+func (p *postgresQueries) CreateAccessRequest(ctx context.Context, arg CreateAccessRequestParams) (AccessRequest, error) {
+	row, err := p.q.CreateAccessRequest(ctx, sqlcpostgres.CreateAccessRequestParams{
+		ID:              arg.ID,
+		WonderNetID:     arg.WonderNetID,
+		ServiceID:       arg.ServiceID,
+		ServiceName:     arg.ServiceName,
+		Subject:         arg.Subject,
+		Requester:       arg.Requester,
+		Reason:          arg.Reason,
+		DurationSeconds: arg.DurationSeconds,
+	})
+	if err != nil {
+		return AccessRequest{}, err
+	}
+	return postgresAccessRequest(row), nil
+}
+
+func (p *postgresQueries) GetAccessRequestByID(ctx context.Context, id string) (AccessRequest, error) {
+	row, err := p.q.GetAccessRequestByID(ctx, id)
+	if err != nil {
+		return AccessRequest{}, err
+	}
+	return postgresAccessRequest(row), nil
+}
+
+func (p *postgresQueries) ListAccessRequestsByWonderNet(ctx context.Context, wonderNetID string) ([]AccessRequest, error) {
+	rows, err := p.q.ListAccessRequestsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AccessRequest, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAccessRequest(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DecideAccessRequest(ctx context.Context, arg DecideAccessRequestParams) (int64, error) {
+	return p.q.DecideAccessRequest(ctx, sqlcpostgres.DecideAccessRequestParams{
+		Status:    arg.Status,
+		DecidedBy: arg.DecidedBy,
+		GrantID:   arg.GrantID,
+		ExpiresAt: arg.ExpiresAt,
+		ID:        arg.ID,
+	})
+}
+
+func (p *postgresQueries) EndAccessRequest(ctx context.Context, arg EndAccessRequestParams) (int64, error) {
+	return p.q.EndAccessRequest(ctx, sqlcpostgres.EndAccessRequestParams{
+		Status: arg.Status,
+		ID:     arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListExpiredAccessRequests(ctx context.Context, expiresAt sql.NullTime) ([]AccessRequest, error) {
+	rows, err := p.q.ListExpiredAccessRequests(ctx, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]AccessRequest, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAccessRequest(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) RecordAPIKeyUsage(ctx context.Context, arg RecordAPIKeyUsageParams) error {
+	return p.q.RecordAPIKeyUsage(ctx, sqlcpostgres.RecordAPIKeyUsageParams{
+		ApiKeyID:     arg.APIKeyID,
+		Endpoint:     arg.Endpoint,
+		RequestCount: arg.RequestCount,
+	})
+}
+
+func (p *postgresQueries) ListAPIKeyUsageByWonderNet(ctx context.Context, wonderNetID string) ([]APIKeyUsage, error) {
+	rows, err := p.q.ListAPIKeyUsageByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]APIKeyUsage, len(rows))
+	for i, row := range rows {
+		items[i] = postgresAPIKeyUsage(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteAPIKeyUsage(ctx context.Context, apiKeyID string) error {
+	return p.q.DeleteAPIKeyUsage(ctx, apiKeyID)
+}
+
+func (p *postgresQueries) UpsertNodeHeartbeat(ctx context.Context, arg UpsertNodeHeartbeatParams) error {
+	return p.q.UpsertNodeHeartbeat(ctx, sqlcpostgres.UpsertNodeHeartbeatParams{
+		NodeID:               arg.NodeID,
+		WonderNetID:          arg.WonderNetID,
+		Os:                   arg.OS,
+		TailscaleVersion:     arg.TailscaleVersion,
+		DiskTotalBytes:       arg.DiskTotalBytes,
+		DiskFreeBytes:        arg.DiskFreeBytes,
+		MemoryTotalBytes:     arg.MemoryTotalBytes,
+		MemoryAvailableBytes: arg.MemoryAvailableBytes,
+		Connectivity:         arg.Connectivity,
+		ReportedAt:           arg.ReportedAt,
+	})
+}
+
+func (p *postgresQueries) GetNodeHeartbeat(ctx context.Context, nodeID string) (NodeHeartbeat, error) {
+	row, err := p.q.GetNodeHeartbeat(ctx, nodeID)
+	if err != nil {
+		return NodeHeartbeat{}, err
+	}
+	return postgresNodeHeartbeat(row), nil
+}
+
+func (p *postgresQueries) ListNodeHeartbeatsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeHeartbeat, error) {
+	rows, err := p.q.ListNodeHeartbeatsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeHeartbeat, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeHeartbeat(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteNodeHeartbeat(ctx context.Context, nodeID string) error {
+	return p.q.DeleteNodeHeartbeat(ctx, nodeID)
+}
+
+func (p *postgresQueries) CreateNodeDecommission(ctx context.Context, arg CreateNodeDecommissionParams) (NodeDecommission, error) {
+	row, err := p.q.CreateNodeDecommission(ctx, sqlcpostgres.CreateNodeDecommissionParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		NodeName:    arg.NodeName,
+		Hostname:    arg.Hostname,
+		Addresses:   arg.Addresses,
+		Os:          arg.OS,
+		LastSeenAt:  arg.LastSeenAt,
+		RequestedBy: arg.RequestedBy,
+		Reason:      arg.Reason,
+		WipeStatus:  arg.WipeStatus,
+	})
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return postgresNodeDecommission(row), nil
+}
+
+func (p *postgresQueries) GetNodeDecommissionByID(ctx context.Context, id string) (NodeDecommission, error) {
+	row, err := p.q.GetNodeDecommissionByID(ctx, id)
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return postgresNodeDecommission(row), nil
+}
+
+func (p *postgresQueries) GetActiveNodeDecommissionByNode(ctx context.Context, nodeID string) (NodeDecommission, error) {
+	row, err := p.q.GetActiveNodeDecommissionByNode(ctx, nodeID)
+	if err != nil {
+		return NodeDecommission{}, err
+	}
+	return postgresNodeDecommission(row), nil
+}
+
+func (p *postgresQueries) ListNodeDecommissionsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeDecommission, error) {
+	rows, err := p.q.ListNodeDecommissionsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeDecommission, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeDecommission(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) FinishNodeWipe(ctx context.Context, arg FinishNodeWipeParams) (int64, error) {
+	return p.q.FinishNodeWipe(ctx, sqlcpostgres.FinishNodeWipeParams{
+		WipeStatus: arg.WipeStatus,
+		WipeOutput: arg.WipeOutput,
+		ID:         arg.ID,
+	})
+}
+
+func (p *postgresQueries) EndNodeDecommission(ctx context.Context, arg EndNodeDecommissionParams) (int64, error) {
+	return p.q.EndNodeDecommission(ctx, sqlcpostgres.EndNodeDecommissionParams{
+		Status: arg.Status,
+		Error:  arg.Error,
+		ID:     arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListStaleNodeWipes(ctx context.Context, createdAt time.Time) ([]NodeDecommission, error) {
+	rows, err := p.q.ListStaleNodeWipes(ctx, createdAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeDecommission, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeDecommission(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) AddWonderNetMember(ctx context.Context, arg AddWonderNetMemberParams) error {
+	return p.q.AddWonderNetMember(ctx, sqlcpostgres.AddWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+		Email:       arg.Email,
+		Role:        arg.Role,
+		AddedBy:     arg.AddedBy,
+	})
+}
+
+func (p *postgresQueries) GetWonderNetMember(ctx context.Context, arg GetWonderNetMemberParams) (WonderNetMember, error) {
+	row, err := p.q.GetWonderNetMember(ctx, sqlcpostgres.GetWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+	if err != nil {
+		return WonderNetMember{}, err
+	}
+	return postgresWonderNetMember(row), nil
+}
+
+func (p *postgresQueries) ListWonderNetMembers(ctx context.Context, wonderNetID string) ([]WonderNetMember, error) {
+	rows, err := p.q.ListWonderNetMembers(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetMember, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNetMember(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListWonderNetMembershipsByUser(ctx context.Context, userID string) ([]WonderNetMember, error) {
+	rows, err := p.q.ListWonderNetMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetMember, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNetMember(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpdateWonderNetMemberRole(ctx context.Context, arg UpdateWonderNetMemberRoleParams) (int64, error) {
+	return p.q.UpdateWonderNetMemberRole(ctx, sqlcpostgres.UpdateWonderNetMemberRoleParams{
+		Role:        arg.Role,
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+}
+
+func (p *postgresQueries) DeleteWonderNetMember(ctx context.Context, arg DeleteWonderNetMemberParams) (int64, error) {
+	return p.q.DeleteWonderNetMember(ctx, sqlcpostgres.DeleteWonderNetMemberParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+}
+
+func (p *postgresQueries) CreateWonderNetInvite(ctx context.Context, arg CreateWonderNetInviteParams) (WonderNetInvite, error) {
+	row, err := p.q.CreateWonderNetInvite(ctx, sqlcpostgres.CreateWonderNetInviteParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Email:       arg.Email,
+		UserID:      arg.UserID,
+		Role:        arg.Role,
+		InvitedBy:   arg.InvitedBy,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return postgresWonderNetInvite(row), nil
+}
+
+func (p *postgresQueries) GetWonderNetInvite(ctx context.Context, id string) (WonderNetInvite, error) {
+	row, err := p.q.GetWonderNetInvite(ctx, id)
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return postgresWonderNetInvite(row), nil
+}
+
+func (p *postgresQueries) GetPendingWonderNetInvite(ctx context.Context, arg GetPendingWonderNetInviteParams) (WonderNetInvite, error) {
+	row, err := p.q.GetPendingWonderNetInvite(ctx, sqlcpostgres.GetPendingWonderNetInviteParams{
+		WonderNetID: arg.WonderNetID,
+		UserID:      arg.UserID,
+	})
+	if err != nil {
+		return WonderNetInvite{}, err
+	}
+	return postgresWonderNetInvite(row), nil
+}
+
+func (p *postgresQueries) ListPendingWonderNetInvitesByWonderNet(ctx context.Context, wonderNetID string) ([]WonderNetInvite, error) {
+	rows, err := p.q.ListPendingWonderNetInvitesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetInvite, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNetInvite(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListPendingWonderNetInvitesByUser(ctx context.Context, userID string) ([]WonderNetInvite, error) {
+	rows, err := p.q.ListPendingWonderNetInvitesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WonderNetInvite, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWonderNetInvite(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DecideWonderNetInvite(ctx context.Context, arg DecideWonderNetInviteParams) (int64, error) {
+	return p.q.DecideWonderNetInvite(ctx, sqlcpostgres.DecideWonderNetInviteParams{
+		Status: arg.Status,
+		ID:     arg.ID,
+	})
+}
+
+func (p *postgresQueries) CreateJoinToken(ctx context.Context, arg CreateJoinTokenParams) error {
+	return p.q.CreateJoinToken(ctx, sqlcpostgres.CreateJoinTokenParams{
+		Jti:          arg.Jti,
+		WonderNetID:  arg.WonderNetID,
+		CreatedBy:    arg.CreatedBy,
+		MaxUses:      arg.MaxUses,
+		ExpiresAt:    arg.ExpiresAt,
+		AllowedCidrs: arg.AllowedCidrs,
+	})
+}
+
+func (p *postgresQueries) GetJoinToken(ctx context.Context, jti string) (JoinToken, error) {
+	row, err := p.q.GetJoinToken(ctx, jti)
+	if err != nil {
+		return JoinToken{}, err
+	}
+	return postgresJoinToken(row), nil
+}
+
+func (p *postgresQueries) UseJoinToken(ctx context.Context, jti string) (int64, error) {
+	return p.q.UseJoinToken(ctx, jti)
+}
+
+func (p *postgresQueries) ReleaseJoinToken(ctx context.Context, jti string) error {
+	return p.q.ReleaseJoinToken(ctx, jti)
+}
+
+func (p *postgresQueries) CreateWorkerToken(ctx context.Context, arg CreateWorkerTokenParams) error {
+	return p.q.CreateWorkerToken(ctx, sqlcpostgres.CreateWorkerTokenParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		AuthKeyID:   arg.AuthKeyID,
+		ExpiresAt:   arg.ExpiresAt,
+	})
+}
+
+func (p *postgresQueries) GetWorkerToken(ctx context.Context, id string) (WorkerToken, error) {
+	row, err := p.q.GetWorkerToken(ctx, id)
+	if err != nil {
+		return WorkerToken{}, err
+	}
+	return postgresWorkerToken(row), nil
+}
+
+func (p *postgresQueries) TouchWorkerToken(ctx context.Context, arg TouchWorkerTokenParams) error {
+	return p.q.TouchWorkerToken(ctx, sqlcpostgres.TouchWorkerTokenParams{
+		NodeID:    arg.NodeID,
+		ExpiresAt: arg.ExpiresAt,
+		ID:        arg.ID,
+	})
+}
+
+func (p *postgresQueries) UpdateWorkerTokenAuthKey(ctx context.Context, arg UpdateWorkerTokenAuthKeyParams) error {
+	return p.q.UpdateWorkerTokenAuthKey(ctx, sqlcpostgres.UpdateWorkerTokenAuthKeyParams{
+		AuthKeyID: arg.AuthKeyID,
+		ID:        arg.ID,
+	})
+}
+
+func (p *postgresQueries) RevokeWorkerTokensByNode(ctx context.Context, arg RevokeWorkerTokensByNodeParams) error {
+	return p.q.RevokeWorkerTokensByNode(ctx, sqlcpostgres.RevokeWorkerTokensByNodeParams{
+		WonderNetID: arg.WonderNetID,
+		NodeID:      arg.NodeID,
+		AuthKeyID:   arg.AuthKeyID,
+	})
+}
+
+func (p *postgresQueries) CreateWebhook(ctx context.Context, arg CreateWebhookParams) (Webhook, error) {
+	row, err := p.q.CreateWebhook(ctx, sqlcpostgres.CreateWebhookParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Url:         arg.Url,
+		Secret:      arg.Secret,
+		Events:      arg.Events,
+		CreatedBy:   arg.CreatedBy,
+	})
+	if err != nil {
+		return Webhook{}, err
+	}
+	return postgresWebhook(row), nil
+}
+
+func (p *postgresQueries) GetWebhookByID(ctx context.Context, id string) (Webhook, error) {
+	row, err := p.q.GetWebhookByID(ctx, id)
+	if err != nil {
+		return Webhook{}, err
+	}
+	return postgresWebhook(row), nil
+}
+
+func (p *postgresQueries) ListWebhooksByWonderNet(ctx context.Context, wonderNetID string) ([]Webhook, error) {
+	rows, err := p.q.ListWebhooksByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWebhook(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListWebhooks(ctx context.Context) ([]Webhook, error) {
+	rows, err := p.q.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]Webhook, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWebhook(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteWebhook(ctx context.Context, id string) error {
+	return p.q.DeleteWebhook(ctx, id)
+}
+
+func (p *postgresQueries) CreateWebhookDelivery(ctx context.Context, arg CreateWebhookDeliveryParams) error {
+	return p.q.CreateWebhookDelivery(ctx, sqlcpostgres.CreateWebhookDeliveryParams{
+		ID:            arg.ID,
+		WebhookID:     arg.WebhookID,
+		WonderNetID:   arg.WonderNetID,
+		Event:         arg.Event,
+		Payload:       arg.Payload,
+		NextAttemptAt: arg.NextAttemptAt,
+	})
+}
+
+func (p *postgresQueries) ListDueWebhookDeliveries(ctx context.Context, nextAttemptAt time.Time) ([]WebhookDelivery, error) {
+	rows, err := p.q.ListDueWebhookDeliveries(ctx, nextAttemptAt)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWebhookDelivery(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) ([]WebhookDelivery, error) {
+	rows, err := p.q.ListWebhookDeliveriesByWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]WebhookDelivery, len(rows))
+	for i, row := range rows {
+		items[i] = postgresWebhookDelivery(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) RecordWebhookDeliveryAttempt(ctx context.Context, arg RecordWebhookDeliveryAttemptParams) error {
+	return p.q.RecordWebhookDeliveryAttempt(ctx, sqlcpostgres.RecordWebhookDeliveryAttemptParams{
+		Status:         arg.Status,
+		Attempts:       arg.Attempts,
+		ResponseStatus: arg.ResponseStatus,
+		LastError:      arg.LastError,
+		NextAttemptAt:  arg.NextAttemptAt,
+		DeliveredAt:    arg.DeliveredAt,
+		ID:             arg.ID,
+	})
+}
+
+func (p *postgresQueries) DeleteWebhookDeliveriesByWebhook(ctx context.Context, webhookID string) error {
+	return p.q.DeleteWebhookDeliveriesByWebhook(ctx, webhookID)
+}
+
+func (p *postgresQueries) GetWonderNetQuotas(ctx context.Context, wonderNetID string) (WonderNetQuota, error) {
+	row, err := p.q.GetWonderNetQuotas(ctx, wonderNetID)
+	if err != nil {
+		return WonderNetQuota{}, err
+	}
+	return postgresWonderNetQuota(row), nil
+}
+
+func (p *postgresQueries) UpsertWonderNetQuotas(ctx context.Context, arg UpsertWonderNetQuotasParams) (WonderNetQuota, error) {
+	row, err := p.q.UpsertWonderNetQuotas(ctx, sqlcpostgres.UpsertWonderNetQuotasParams{
+		WonderNetID:     arg.WonderNetID,
+		MaxNodes:        arg.MaxNodes,
+		MaxApiKeys:      arg.MaxApiKeys,
+		AuthkeysPerHour: arg.AuthkeysPerHour,
+	})
+	if err != nil {
+		return WonderNetQuota{}, err
+	}
+	return postgresWonderNetQuota(row), nil
+}
+
+func (p *postgresQueries) DeleteWonderNetQuotas(ctx context.Context, wonderNetID string) error {
+	return p.q.DeleteWonderNetQuotas(ctx, wonderNetID)
+}
+
+func (p *postgresQueries) GetDNSDomain(ctx context.Context, wonderNetID string) (DNSDomain, error) {
+	row, err := p.q.GetDNSDomain(ctx, wonderNetID)
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return postgresDNSDomain(row), nil
+}
+
+func (p *postgresQueries) GetDNSDomainByName(ctx context.Context, domain string) (DNSDomain, error) {
+	row, err := p.q.GetDNSDomainByName(ctx, domain)
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return postgresDNSDomain(row), nil
+}
+
+func (p *postgresQueries) ListDNSDomains(ctx context.Context) ([]DNSDomain, error) {
+	rows, err := p.q.ListDNSDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSDomain, len(rows))
+	for i, row := range rows {
+		items[i] = postgresDNSDomain(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) UpsertDNSDomain(ctx context.Context, arg UpsertDNSDomainParams) (DNSDomain, error) {
+	row, err := p.q.UpsertDNSDomain(ctx, sqlcpostgres.UpsertDNSDomainParams{
+		WonderNetID: arg.WonderNetID,
+		Domain:      arg.Domain,
+	})
+	if err != nil {
+		return DNSDomain{}, err
+	}
+	return postgresDNSDomain(row), nil
+}
+
+func (p *postgresQueries) CreateDNSRecord(ctx context.Context, arg CreateDNSRecordParams) (DNSRecord, error) {
+	row, err := p.q.CreateDNSRecord(ctx, sqlcpostgres.CreateDNSRecordParams{
+		ID:          arg.ID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		Type:        arg.Type,
+		Value:       arg.Value,
+		CreatedBy:   arg.CreatedBy,
+	})
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	return postgresDNSRecord(row), nil
+}
+
+func (p *postgresQueries) GetDNSRecordByID(ctx context.Context, id string) (DNSRecord, error) {
+	row, err := p.q.GetDNSRecordByID(ctx, id)
+	if err != nil {
+		return DNSRecord{}, err
+	}
+	return postgresDNSRecord(row), nil
+}
+
+func (p *postgresQueries) ListDNSRecordsByWonderNet(ctx context.Context, wonderNetID string) ([]DNSRecord, error) {
+	rows, err := p.q.ListDNSRecordsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSRecord, len(rows))
+	for i, row := range rows {
+		items[i] = postgresDNSRecord(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListDNSRecords(ctx context.Context) ([]DNSRecord, error) {
+	rows, err := p.q.ListDNSRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]DNSRecord, len(rows))
+	for i, row := range rows {
+		items[i] = postgresDNSRecord(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteDNSRecord(ctx context.Context, id string) error {
+	return p.q.DeleteDNSRecord(ctx, id)
+}
+
+func (p *postgresQueries) CreateNodeApproval(ctx context.Context, arg CreateNodeApprovalParams) error {
+	return p.q.CreateNodeApproval(ctx, sqlcpostgres.CreateNodeApprovalParams{
+		NodeID:      arg.NodeID,
+		WonderNetID: arg.WonderNetID,
+		Status:      arg.Status,
+		DecidedBy:   arg.DecidedBy,
+		DecidedAt:   arg.DecidedAt,
+	})
+}
+
+func (p *postgresQueries) GetNodeApproval(ctx context.Context, nodeID string) (NodeApproval, error) {
+	row, err := p.q.GetNodeApproval(ctx, nodeID)
+	if err != nil {
+		return NodeApproval{}, err
+	}
+	return postgresNodeApproval(row), nil
+}
+
+func (p *postgresQueries) ListNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error) {
+	rows, err := p.q.ListNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeApproval, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeApproval(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) ListPendingNodeApprovalsByWonderNet(ctx context.Context, wonderNetID string) ([]NodeApproval, error) {
+	rows, err := p.q.ListPendingNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeApproval, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeApproval(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DecideNodeApproval(ctx context.Context, arg DecideNodeApprovalParams) error {
+	return p.q.DecideNodeApproval(ctx, sqlcpostgres.DecideNodeApprovalParams{
+		Status:    arg.Status,
+		DecidedBy: arg.DecidedBy,
+		NodeID:    arg.NodeID,
+	})
+}
+
+func (p *postgresQueries) DeleteNodeApproval(ctx context.Context, nodeID string) error {
+	return p.q.DeleteNodeApproval(ctx, nodeID)
+}
+
+func (p *postgresQueries) UpsertNodeName(ctx context.Context, arg UpsertNodeNameParams) error {
+	return p.q.UpsertNodeName(ctx, sqlcpostgres.UpsertNodeNameParams{
+		NodeID:      arg.NodeID,
+		WonderNetID: arg.WonderNetID,
+		Name:        arg.Name,
+		RenamedBy:   arg.RenamedBy,
+	})
 }
 
-func newQueries(driver Driver, db *sql.DB) (Queries, error) {
-	switch driver {
-	case DriverSQLite:
-		return &sqliteQueries{q: sqlcsqlite.New(db)}, nil
-	case DriverPostgres:
-		return &postgresQueries{q: sqlcpostgres.New(db)}, nil
-	default:
-		return nil, fmt.Errorf("unsupported database driver: %s", driver)
+func (p *postgresQueries) ListNodeNamesByWonderNet(ctx context.Context, wonderNetID string) ([]NodeName, error) {
+	rows, err := p.q.ListNodeNamesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NodeName, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNodeName(row)
 	}
+	return items, nil
 }
 
-type sqliteQueries struct {
-	q *sqlcsqlite.Queries
+func (p *postgresQueries) DeleteNodeName(ctx context.Context, nodeID string) error {
+	return p.q.DeleteNodeName(ctx, nodeID)
 }
 
-func (s *sqliteQueries) CreateWonderNet(ctx context.Context, arg CreateWonderNetParams) error {
-	return s.q.CreateWonderNet(ctx, sqlcsqlite.CreateWonderNetParams{
-		ID:            arg.ID,
-		OwnerID:       arg.OwnerID,
-		HeadscaleUser: arg.HeadscaleUser,
-		DisplayName:   arg.DisplayName,
-		MeshType:      arg.MeshType,
+func (p *postgresQueries) CreateExecSession(ctx context.Context, arg CreateExecSessionParams) (ExecSession, error) {
+	row, err := p.q.CreateExecSession(ctx, sqlcpostgres.CreateExecSessionParams{
+		ID:              arg.ID,
+		WonderNetID:     arg.WonderNetID,
+		NodeID:          arg.NodeID,
+		NodeName:        arg.NodeName,
+		Address:         arg.Address,
+		Command:         arg.Command,
+		Stdout:          arg.Stdout,
+		Stderr:          arg.Stderr,
+		OutputTruncated: arg.OutputTruncated,
+		ExitCode:        arg.ExitCode,
+		Error:           arg.Error,
+		RecordedBy:      arg.RecordedBy,
+		StartedAt:       arg.StartedAt,
+		DurationMs:      arg.DurationMs,
 	})
+	if err != nil {
+		return ExecSession{}, err
+	}
+	return postgresExecSession(row), nil
 }
 
-func (s *sqliteQueries) GetWonderNet(ctx context.Context, id string) (WonderNet, error) {
-	row, err := s.q.GetWonderNet(ctx, id)
+func (p *postgresQueries) GetExecSessionByID(ctx context.Context, id string) (ExecSession, error) {
+	row, err := p.q.GetExecSessionByID(ctx, id)
 	if err != nil {
-		return WonderNet{}, err
+		return ExecSession{}, err
 	}
-	return sqliteWonderNet(row), nil
+	return postgresExecSession(row), nil
 }
 
-func (s *sqliteQueries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error) {
-	row, err := s.q.GetWonderNetByHeadscaleUser(ctx, headscaleUser)
+func (p *postgresQueries) ListExecSessionsByWonderNet(ctx context.Context, arg ListExecSessionsByWonderNetParams) ([]ExecSession, error) {
+	rows, err := p.q.ListExecSessionsByWonderNet(ctx, sqlcpostgres.ListExecSessionsByWonderNetParams{
+		WonderNetID: arg.WonderNetID,
+		Limit:       arg.Limit,
+	})
 	if err != nil {
-		return WonderNet{}, err
+		return nil, err
 	}
-	return sqliteWonderNet(row), nil
+	items := make([]ExecSession, len(rows))
+	for i, row := range rows {
+		items[i] = postgresExecSession(row)
+	}
+	return items, nil
 }
 
-func (s *sqliteQueries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]WonderNet, error) {
-	rows, err := s.q.ListWonderNetsByOwner(ctx, ownerID)
+func (p *postgresQueries) DeleteExecSessionsBefore(ctx context.Context, startedAt time.Time) (int64, error) {
+	return p.q.DeleteExecSessionsBefore(ctx, startedAt)
+}
+
+func (p *postgresQueries) UpdateWonderNetNodeExpiryDays(ctx context.Context, arg UpdateWonderNetNodeExpiryDaysParams) error {
+	return p.q.UpdateWonderNetNodeExpiryDays(ctx, sqlcpostgres.UpdateWonderNetNodeExpiryDaysParams{
+		NodeExpiryDays: arg.NodeExpiryDays,
+		ID:             arg.ID,
+	})
+}
+
+func (p *postgresQueries) UpdateWonderNetJoinTokenTTL(ctx context.Context, arg UpdateWonderNetJoinTokenTTLParams) error {
+	return p.q.UpdateWonderNetJoinTokenTTL(ctx, sqlcpostgres.UpdateWonderNetJoinTokenTTLParams{
+		JoinTokenDefaultTtlSeconds: arg.JoinTokenDefaultTTLSeconds,
+		JoinTokenMaxTtlSeconds:     arg.JoinTokenMaxTTLSeconds,
+		ID:                         arg.ID,
+	})
+}
+
+func (p *postgresQueries) ListWonderNetsWithNodeExpiry(ctx context.Context) ([]WonderNet, error) {
+	rows, err := p.q.ListWonderNetsWithNodeExpiry(ctx)
 	if err != nil {
 		return nil, err
 	}
 	items := make([]WonderNet, len(rows))
 	for i, row := range rows {
-		items[i] = sqliteWonderNet(row)
+		items[i] = postgresWonderNet(row)
 	}
 	return items, nil
 }
 
-func (s *sqliteQueries) UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams) error {
-	return s.q.UpdateWonderNet(ctx, sqlcsqlite.UpdateWonderNetParams{
-		DisplayName: arg.DisplayName,
-		ID:          arg.ID,
+func (p *postgresQueries) CreateNotificationChannel(ctx context.Context, arg CreateNotificationChannelParams) (NotificationChannel, error) {
+	row, err := p.q.CreateNotificationChannel(ctx, sqlcpostgres.CreateNotificationChannelParams{
+		ID:            arg.ID,
+		WonderNetID:   arg.WonderNetID,
+		Kind:          arg.Kind,
+		Target:        arg.Target,
+		Events:        arg.Events,
+		DigestSeconds: arg.DigestSeconds,
+		CreatedBy:     arg.CreatedBy,
 	})
+	if err != nil {
+		return NotificationChannel{}, err
+	}
+	return postgresNotificationChannel(row), nil
 }
 
-func (s *sqliteQueries) DeleteWonderNet(ctx context.Context, id string) error {
-	return s.q.DeleteWonderNet(ctx, id)
+func (p *postgresQueries) GetNotificationChannelByID(ctx context.Context, id string) (NotificationChannel, error) {
+	row, err := p.q.GetNotificationChannelByID(ctx, id)
+	if err != nil {
+		return NotificationChannel{}, err
+	}
+	return postgresNotificationChannel(row), nil
 }
 
-func (s *sqliteQueries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
-	rows, err := s.q.ListWonderNets(ctx)
+func (p *postgresQueries) ListNotificationChannelsByWonderNet(ctx context.Context, wonderNetID string) ([]NotificationChannel, error) {
+	rows, err := p.q.ListNotificationChannelsByWonderNet(ctx, wonderNetID)
 	if err != nil {
 		return nil, err
 	}
-	items := make([]WonderNet, len(rows))
+	items := make([]NotificationChannel, len(rows))
 	for i, row := range rows {
-		items[i] = sqliteWonderNet(row)
+		items[i] = postgresNotificationChannel(row)
 	}
 	return items, nil
 }
 
-func (s *sqliteQueries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
-	row, err := s.q.CreateAPIKey(ctx, sqlcsqlite.CreateAPIKeyParams{
-		ID:          arg.ID,
-		WonderNetID: arg.WonderNetID,
-		Name:        arg.Name,
-		KeyHash:     arg.KeyHash,
-		KeyPrefix:   arg.KeyPrefix,
-		ExpiresAt:   arg.ExpiresAt,
+func (p *postgresQueries) ListNotificationChannels(ctx context.Context) ([]NotificationChannel, error) {
+	rows, err := p.q.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]NotificationChannel, len(rows))
+	for i, row := range rows {
+		items[i] = postgresNotificationChannel(row)
+	}
+	return items, nil
+}
+
+func (p *postgresQueries) DeleteNotificationChannel(ctx context.Context, id string) error {
+	return p.q.DeleteNotificationChannel(ctx, id)
+}
+
+func (p *postgresQueries) CreateUser(ctx context.Context, arg CreateUserParams) (User, error) {
+	row, err := p.q.CreateUser(ctx, sqlcpostgres.CreateUserParams{
+		ID:           arg.ID,
+		Provider:     arg.Provider,
+		Subject:      arg.Subject,
+		Username:     arg.Username,
+		Email:        arg.Email,
+		Name:         arg.Name,
+		PasswordHash: arg.PasswordHash,
 	})
 	if err != nil {
-		return APIKey{}, err
+		return User{}, err
 	}
-	return sqliteAPIKey(row), nil
+	return postgresUser(row), nil
 }
 
-func (s *sqliteQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
-	row, err := s.q.GetAPIKeyByHash(ctx, keyHash)
+func (p *postgresQueries) GetUserByID(ctx context.Context, id string) (User, error) {
+	row, err := p.q.GetUserByID(ctx, id)
 	if err != nil {
-		return APIKey{}, err
+		return User{}, err
 	}
-	return sqliteAPIKey(row), nil
+	return postgresUser(row), nil
 }
 
-func (s *sqliteQueries) GetAPIKeyByID(ctx context.Context, id string) (APIKey, error) {
-	row, err := s.q.GetAPIKeyByID(ctx, id)
+func (p *postgresQueries) GetUserByProviderSubject(ctx context.Context, arg GetUserByProviderSubjectParams) (User, error) {
+	row, err := p.q.GetUserByProviderSubject(ctx, sqlcpostgres.GetUserByProviderSubjectParams{
+		Provider: arg.Provider,
+		Subject:  arg.Subject,
+	})
 	if err != nil {
-		return APIKey{}, err
+		return User{}, err
 	}
-	return sqliteAPIKey(row), nil
+	return postgresUser(row), nil
 }
 
-func (s *sqliteQueries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]APIKey, error) {
-	rows, err := s.q.ListAPIKeysByWonderNet(ctx, wonderNetID)
+func (p *postgresQueries) ListUsersByEmail(ctx context.Context, email string) ([]User, error) {
+	rows, err := p.q.ListUsersByEmail(ctx, email)
 	if err != nil {
 		return nil, err
 	}
-	items := make([]APIKey, len(rows))
+	items := make([]User, len(rows))
 	for i, row := range rows {
-		items[i] = sqliteAPIKey(row)
+		items[i] = postgresUser(row)
 	}
 	return items, nil
 }
 
-func (s *sqliteQueries) DeleteAPIKey(ctx context.Context, id string) error {
-	return s.q.DeleteAPIKey(ctx, id)
+func (p *postgresQueries) ListUsers(ctx context.Context) ([]User, error) {
+	rows, err := p.q.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	items := make([]User, len(rows))
+	for i, row := range rows {
+		items[i] = postgresUser(row)
+	}
+	return items, nil
 }
 
-func (s *sqliteQueries) UpdateAPIKeyLastUsed(ctx context.Context, id string) error {
-	return s.q.UpdateAPIKeyLastUsed(ctx, id)
+func (p *postgresQueries) UpdateUserProfile(ctx context.Context, arg UpdateUserProfileParams) error {
+	return p.q.UpdateUserProfile(ctx, sqlcpostgres.UpdateUserProfileParams{
+		Username: arg.Username,
+		Email:    arg.Email,
+		Name:     arg.Name,
+		ID:       arg.ID,
+	})
 }
 
-func sqliteWonderNet(row sqlcsqlite.WonderNet) WonderNet {
+func (p *postgresQueries) UpdateUserPasswordHash(ctx context.Context, arg UpdateUserPasswordHashParams) error {
+	return p.q.UpdateUserPasswordHash(ctx, sqlcpostgres.UpdateUserPasswordHashParams{
+		PasswordHash: arg.PasswordHash,
+		ID:           arg.ID,
+	})
+}
+
+func (p *postgresQueries) DeleteUser(ctx context.Context, id string) error {
+	return p.q.DeleteUser(ctx, id)
+}
+
+func postgresWonderNet(row sqlcpostgres.WonderNet) WonderNet {
 	return WonderNet{
-		ID:            row.ID,
-		OwnerID:       row.OwnerID,
-		HeadscaleUser: row.HeadscaleUser,
-		DisplayName:   row.DisplayName,
-		MeshType:      row.MeshType,
-		CreatedAt:     row.CreatedAt,
-		UpdatedAt:     row.UpdatedAt,
+		ID:                         row.ID,
+		OwnerID:                    row.OwnerID,
+		HeadscaleUser:              row.HeadscaleUser,
+		DisplayName:                row.DisplayName,
+		MeshType:                   row.MeshType,
+		IsDefault:                  row.IsDefault,
+		NodeNameTemplate:           row.NodeNameTemplate,
+		ACLRules:                   row.AclRules,
+		PublicURL:                  row.PublicUrl,
+		RequireNodeApproval:        row.RequireNodeApproval,
+		NodeExpiryDays:             row.NodeExpiryDays,
+		JoinTokenDefaultTTLSeconds: row.JoinTokenDefaultTtlSeconds,
+		JoinTokenMaxTTLSeconds:     row.JoinTokenMaxTtlSeconds,
+		CreatedAt:                  row.CreatedAt,
+		UpdatedAt:                  row.UpdatedAt,
 	}
 }
 
-func sqliteAPIKey(row sqlcsqlite.ApiKey) APIKey {
+func postgresAPIKey(row sqlcpostgres.ApiKey) APIKey {
 	return APIKey{
+		ID:           row.ID,
+		WonderNetID:  row.WonderNetID,
+		Name:         row.Name,
+		KeyHash:      row.KeyHash,
+		KeyPrefix:    row.KeyPrefix,
+		CreatedAt:    row.CreatedAt,
+		LastUsedAt:   row.LastUsedAt,
+		ExpiresAt:    row.ExpiresAt,
+		Scopes:       row.Scopes,
+		AllowedCidrs: row.AllowedCidrs,
+		DeprecatedAt: row.DeprecatedAt,
+		SuccessorID:  row.SuccessorID,
+	}
+}
+
+func postgresAlertRule(row sqlcpostgres.AlertRule) AlertRule {
+	return AlertRule{
+		ID:               row.ID,
+		WonderNetID:      row.WonderNetID,
+		Name:             row.Name,
+		Kind:             row.Kind,
+		ThresholdSeconds: row.ThresholdSeconds,
+		CreatedAt:        row.CreatedAt,
+	}
+}
+
+func postgresAlertSilence(row sqlcpostgres.AlertSilence) AlertSilence {
+	return AlertSilence{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		RuleID:      row.RuleID,
+		NodeName:    row.NodeName,
+		Reason:      row.Reason,
+		EndsAt:      row.EndsAt,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func postgresFiringAlert(row sqlcpostgres.FiringAlert) FiringAlert {
+	return FiringAlert{
+		RuleID:      row.RuleID,
+		NodeName:    row.NodeName,
+		WonderNetID: row.WonderNetID,
+		NodeID:      row.NodeID,
+		StartsAt:    row.StartsAt,
+		Silenced:    row.Silenced,
+	}
+}
+
+func postgresService(row sqlcpostgres.Service) Service {
+	return Service{
 		ID:          row.ID,
 		WonderNetID: row.WonderNetID,
 		Name:        row.Name,
-		KeyHash:     row.KeyHash,
-		KeyPrefix:   row.KeyPrefix,
+		NodeID:      row.NodeID,
+		Port:        row.Port,
+		Protocol:    row.Protocol,
 		CreatedAt:   row.CreatedAt,
-		LastUsedAt:  row.LastUsedAt,
-		ExpiresAt:   row.ExpiresAt,
 	}
 }
 
-type postgresQueries struct {
-	q *sqlcpostgres.Queries
+func postgresServiceGrant(row sqlcpostgres.ServiceGrant) ServiceGrant {
+	return ServiceGrant{
+		ID:        row.ID,
+		ServiceID: row.ServiceID,
+		Subject:   row.Subject,
+		CreatedAt: row.CreatedAt,
+	}
 }
 
-func (p *postgresQueries) CreateWonderNet(ctx context.Context, arg CreateWonderNetParams) error {
-	return p.q.CreateWonderNet(ctx, sqlcpostgres.CreateWonderNetParams{
-		ID:            arg.ID,
-		OwnerID:       arg.OwnerID,
-		HeadscaleUser: arg.HeadscaleUser,
-		DisplayName:   arg.DisplayName,
-		MeshType:      arg.MeshType,
-	})
+func postgresAccessRequest(row sqlcpostgres.AccessRequest) AccessRequest {
+	return AccessRequest{
+		ID:              row.ID,
+		WonderNetID:     row.WonderNetID,
+		ServiceID:       row.ServiceID,
+		ServiceName:     row.ServiceName,
+		Subject:         row.Subject,
+		Requester:       row.Requester,
+		Reason:          row.Reason,
+		DurationSeconds: row.DurationSeconds,
+		Status:          row.Status,
+		DecidedBy:       row.DecidedBy,
+		GrantID:         row.GrantID,
+		DecidedAt:       row.DecidedAt,
+		ExpiresAt:       row.ExpiresAt,
+		EndedAt:         row.EndedAt,
+		CreatedAt:       row.CreatedAt,
+	}
 }
 
-func (p *postgresQueries) GetWonderNet(ctx context.Context, id string) (WonderNet, error) {
-	row, err := p.q.GetWonderNet(ctx, id)
-	if err != nil {
-		return WonderNet{}, err
+func postgresAPIKeyUsage(row sqlcpostgres.ApiKeyUsage) APIKeyUsage {
+	return APIKeyUsage{
+		APIKeyID:     row.ApiKeyID,
+		Endpoint:     row.Endpoint,
+		RequestCount: row.RequestCount,
+		LastUsedAt:   row.LastUsedAt,
 	}
-	return postgresWonderNet(row), nil
 }
 
-func (p *postgresQueries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error) {
-	row, err := p.q.GetWonderNetByHeadscaleUser(ctx, headscaleUser)
-	if err != nil {
-		return WonderNet{}, err
+func postgresNodeHeartbeat(row sqlcpostgres.NodeHeartbeat) NodeHeartbeat {
+	return NodeHeartbeat{
+		NodeID:               row.NodeID,
+		WonderNetID:          row.WonderNetID,
+		OS:                   row.Os,
+		TailscaleVersion:     row.TailscaleVersion,
+		DiskTotalBytes:       row.DiskTotalBytes,
+		DiskFreeBytes:        row.DiskFreeBytes,
+		MemoryTotalBytes:     row.MemoryTotalBytes,
+		MemoryAvailableBytes: row.MemoryAvailableBytes,
+		Connectivity:         row.Connectivity,
+		ReportedAt:           row.ReportedAt,
 	}
-	return postgresWonderNet(row), nil
 }
 
-func (p *postgresQueries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]WonderNet, error) {
-	rows, err := p.q.ListWonderNetsByOwner(ctx, ownerID)
-	if err != nil {
-		return nil, err
+func postgresNodeDecommission(row sqlcpostgres.NodeDecommission) NodeDecommission {
+	return NodeDecommission{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		NodeID:      row.NodeID,
+		NodeName:    row.NodeName,
+		Hostname:    row.Hostname,
+		Addresses:   row.Addresses,
+		OS:          row.Os,
+		LastSeenAt:  row.LastSeenAt,
+		RequestedBy: row.RequestedBy,
+		Reason:      row.Reason,
+		Status:      row.Status,
+		WipeStatus:  row.WipeStatus,
+		WipeOutput:  row.WipeOutput,
+		Error:       row.Error,
+		CreatedAt:   row.CreatedAt,
+		CompletedAt: row.CompletedAt,
 	}
-	items := make([]WonderNet, len(rows))
-	for i, row := range rows {
-		items[i] = postgresWonderNet(row)
+}
+
+func postgresWonderNetMember(row sqlcpostgres.WonderNetMember) WonderNetMember {
+	return WonderNetMember{
+		WonderNetID: row.WonderNetID,
+		UserID:      row.UserID,
+		Email:       row.Email,
+		Role:        row.Role,
+		AddedBy:     row.AddedBy,
+		CreatedAt:   row.CreatedAt,
 	}
-	return items, nil
 }
 
-func (p *postgresQueries) UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams) error {
-	return p.q.UpdateWonderNet(ctx, sqlcpostgres.UpdateWonderNetParams{
-		DisplayName: arg.DisplayName,
-		ID:          arg.ID,
-	})
+func postgresWonderNetInvite(row sqlcpostgres.WonderNetInvite) WonderNetInvite {
+	return WonderNetInvite{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Email:       row.Email,
+		UserID:      row.UserID,
+		Role:        row.Role,
+		InvitedBy:   row.InvitedBy,
+		Status:      row.Status,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+		DecidedAt:   row.DecidedAt,
+	}
 }
 
-func (p *postgresQueries) DeleteWonderNet(ctx context.Context, id string) error {
-	return p.q.DeleteWonderNet(ctx, id)
+func postgresJoinToken(row sqlcpostgres.JoinToken) JoinToken {
+	return JoinToken{
+		Jti:          row.Jti,
+		WonderNetID:  row.WonderNetID,
+		CreatedBy:    row.CreatedBy,
+		MaxUses:      row.MaxUses,
+		Uses:         row.Uses,
+		CreatedAt:    row.CreatedAt,
+		ExpiresAt:    row.ExpiresAt,
+		LastUsedAt:   row.LastUsedAt,
+		AllowedCidrs: row.AllowedCidrs,
+	}
 }
 
-func (p *postgresQueries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
-	rows, err := p.q.ListWonderNets(ctx)
-	if err != nil {
-		return nil, err
+func postgresWorkerToken(row sqlcpostgres.WorkerToken) WorkerToken {
+	return WorkerToken{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		AuthKeyID:   row.AuthKeyID,
+		NodeID:      row.NodeID,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+		LastUsedAt:  row.LastUsedAt,
+		RevokedAt:   row.RevokedAt,
 	}
-	items := make([]WonderNet, len(rows))
-	for i, row := range rows {
-		items[i] = postgresWonderNet(row)
+}
+
+func postgresWebhook(row sqlcpostgres.Webhook) Webhook {
+	return Webhook{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Url:         row.Url,
+		Secret:      row.Secret,
+		Events:      row.Events,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
 	}
-	return items, nil
 }
 
-func (p *postgresQueries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (APIKey, error) {
-	row, err := p.q.CreateAPIKey(ctx, sqlcpostgres.CreateAPIKeyParams{
-		ID:          arg.ID,
-		WonderNetID: arg.WonderNetID,
-		Name:        arg.Name,
-		KeyHash:     arg.KeyHash,
-		KeyPrefix:   arg.KeyPrefix,
-		ExpiresAt:   arg.ExpiresAt,
-	})
-	if err != nil {
-		return APIKey{}, err
+func postgresWebhookDelivery(row sqlcpostgres.WebhookDelivery) WebhookDelivery {
+	return WebhookDelivery{
+		ID:             row.ID,
+		WebhookID:      row.WebhookID,
+		WonderNetID:    row.WonderNetID,
+		Event:          row.Event,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       row.Attempts,
+		ResponseStatus: row.ResponseStatus,
+		LastError:      row.LastError,
+		NextAttemptAt:  row.NextAttemptAt,
+		CreatedAt:      row.CreatedAt,
+		DeliveredAt:    row.DeliveredAt,
 	}
-	return postgresAPIKey(row), nil
 }
 
-func (p *postgresQueries) GetAPIKeyByHash(ctx context.Context, keyHash string) (APIKey, error) {
-	row, err := p.q.GetAPIKeyByHash(ctx, keyHash)
-	if err != nil {
-		return APIKey{}, err
+func postgresWonderNetQuota(row sqlcpostgres.WonderNetQuota) WonderNetQuota {
+	return WonderNetQuota{
+		WonderNetID:     row.WonderNetID,
+		MaxNodes:        row.MaxNodes,
+		MaxApiKeys:      row.MaxApiKeys,
+		AuthkeysPerHour: row.AuthkeysPerHour,
+		UpdatedAt:       row.UpdatedAt,
 	}
-	return postgresAPIKey(row), nil
 }
 
-func (p *postgresQueries) GetAPIKeyByID(ctx context.Context, id string) (APIKey, error) {
-	row, err := p.q.GetAPIKeyByID(ctx, id)
-	if err != nil {
-		return APIKey{}, err
+func postgresDNSDomain(row sqlcpostgres.DnsDomain) DNSDomain {
+	return DNSDomain{
+		WonderNetID: row.WonderNetID,
+		Domain:      row.Domain,
+		UpdatedAt:   row.UpdatedAt,
 	}
-	return postgresAPIKey(row), nil
 }
 
-func (p *postgresQueries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]APIKey, error) {
-	rows, err := p.q.ListAPIKeysByWonderNet(ctx, wonderNetID)
-	if err != nil {
-		return nil, err
+func postgresDNSRecord(row sqlcpostgres.DnsRecord) DNSRecord {
+	return DNSRecord{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		Type:        row.Type,
+		Value:       row.Value,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
 	}
-	items := make([]APIKey, len(rows))
-	for i, row := range rows {
-		items[i] = postgresAPIKey(row)
+}
+
+func postgresNodeApproval(row sqlcpostgres.NodeApproval) NodeApproval {
+	return NodeApproval{
+		NodeID:      row.NodeID,
+		WonderNetID: row.WonderNetID,
+		Status:      row.Status,
+		DecidedBy:   row.DecidedBy,
+		CreatedAt:   row.CreatedAt,
+		DecidedAt:   row.DecidedAt,
 	}
-	return items, nil
 }
 
-func (p *postgresQueries) DeleteAPIKey(ctx context.Context, id string) error {
-	return p.q.DeleteAPIKey(ctx, id)
+func postgresNodeName(row sqlcpostgres.NodeName) NodeName {
+	return NodeName{
+		NodeID:      row.NodeID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		RenamedBy:   row.RenamedBy,
+		RenamedAt:   row.RenamedAt,
+	}
 }
 
-func (p *postgresQueries) UpdateAPIKeyLastUsed(ctx context.Context, id string) error {
-	return p.q.UpdateAPIKeyLastUsed(ctx, id)
+func postgresExecSession(row sqlcpostgres.ExecSession) ExecSession {
+	return ExecSession{
+		ID:              row.ID,
+		WonderNetID:     row.WonderNetID,
+		NodeID:          row.NodeID,
+		NodeName:        row.NodeName,
+		Address:         row.Address,
+		Command:         row.Command,
+		Stdout:          row.Stdout,
+		Stderr:          row.Stderr,
+		OutputTruncated: row.OutputTruncated,
+		ExitCode:        row.ExitCode,
+		Error:           row.Error,
+		RecordedBy:      row.RecordedBy,
+		StartedAt:       row.StartedAt,
+		DurationMs:      row.DurationMs,
+		CreatedAt:       row.CreatedAt,
+	}
 }
 
-func postgresWonderNet(row sqlcpostgres.WonderNet) WonderNet {
-	return WonderNet{
+func postgresNotificationChannel(row sqlcpostgres.NotificationChannel) NotificationChannel {
+	return NotificationChannel{
 		ID:            row.ID,
-		OwnerID:       row.OwnerID,
-		HeadscaleUser: row.HeadscaleUser,
-		DisplayName:   row.DisplayName,
-		MeshType:      row.MeshType,
+		WonderNetID:   row.WonderNetID,
+		Kind:          row.Kind,
+		Target:        row.Target,
+		Events:        row.Events,
+		DigestSeconds: row.DigestSeconds,
+		CreatedBy:     row.CreatedBy,
 		CreatedAt:     row.CreatedAt,
-		UpdatedAt:     row.UpdatedAt,
 	}
 }
 
-func postgresAPIKey(row sqlcpostgres.ApiKey) APIKey {
-	return APIKey{
-		ID:          row.ID,
-		WonderNetID: row.WonderNetID,
-		Name:        row.Name,
-		KeyHash:     row.KeyHash,
-		KeyPrefix:   row.KeyPrefix,
-		CreatedAt:   row.CreatedAt,
-		LastUsedAt:  row.LastUsedAt,
-		ExpiresAt:   row.ExpiresAt,
+func postgresUser(row sqlcpostgres.User) User {
+	return User{
+		ID:           row.ID,
+		Provider:     row.Provider,
+		Subject:      row.Subject,
+		Username:     row.Username,
+		Email:        row.Email,
+		Name:         row.Name,
+		PasswordHash: row.PasswordHash,
+		CreatedAt:    row.CreatedAt,
 	}
 }
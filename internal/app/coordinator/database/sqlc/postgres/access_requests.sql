@@ -0,0 +1,25 @@
+-- name: CreateAccessRequest :one
+INSERT INTO access_requests (id, wonder_net_id, service_id, service_name, subject, requester, reason, duration_seconds)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
+RETURNING *;
+
+-- name: GetAccessRequestByID :one
+SELECT * FROM access_requests WHERE id = $1;
+
+-- name: ListAccessRequestsByWonderNet :many
+SELECT * FROM access_requests WHERE wonder_net_id = $1 ORDER BY created_at DESC, id DESC;
+
+-- name: DecideAccessRequest :execrows
+UPDATE access_requests
+SET status = $1, decided_by = $2, grant_id = $3, expires_at = $4, decided_at = CURRENT_TIMESTAMP
+WHERE id = $5 AND status = 'pending';
+
+-- name: EndAccessRequest :execrows
+UPDATE access_requests
+SET status = $1, ended_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND status = 'approved';
+
+-- name: ListExpiredAccessRequests :many
+SELECT * FROM access_requests
+WHERE status = 'approved' AND expires_at <= $1
+ORDER BY expires_at;
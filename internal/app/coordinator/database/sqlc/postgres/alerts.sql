@@ -0,0 +1,46 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (id, wonder_net_id, name, kind, threshold_seconds)
+VALUES ($1, $2, $3, $4, $5)
+RETURNING *;
+
+-- name: GetAlertRuleByID :one
+SELECT * FROM alert_rules WHERE id = $1;
+
+-- name: ListAlertRulesByWonderNet :many
+SELECT * FROM alert_rules WHERE wonder_net_id = $1 ORDER BY created_at DESC;
+
+-- name: ListAlertRules :many
+SELECT * FROM alert_rules ORDER BY wonder_net_id, created_at;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules WHERE id = $1;
+
+-- name: CreateAlertSilence :one
+INSERT INTO alert_silences (id, wonder_net_id, rule_id, node_name, reason, ends_at)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetAlertSilenceByID :one
+SELECT * FROM alert_silences WHERE id = $1;
+
+-- name: ListAlertSilencesByWonderNet :many
+SELECT * FROM alert_silences WHERE wonder_net_id = $1 ORDER BY created_at DESC;
+
+-- name: DeleteAlertSilence :exec
+DELETE FROM alert_silences WHERE id = $1;
+
+-- name: ListFiringAlerts :many
+SELECT * FROM firing_alerts ORDER BY starts_at;
+
+-- name: UpsertFiringAlert :exec
+INSERT INTO firing_alerts (rule_id, node_name, wonder_net_id, node_id, starts_at, silenced)
+VALUES ($1, $2, $3, $4, $5, $6)
+ON CONFLICT (rule_id, node_name) DO UPDATE
+SET node_id = excluded.node_id,
+    silenced = excluded.silenced;
+
+-- name: DeleteFiringAlert :exec
+DELETE FROM firing_alerts WHERE rule_id = $1 AND node_name = $2;
+
+-- name: DeleteFiringAlertsByRule :exec
+DELETE FROM firing_alerts WHERE rule_id = $1;
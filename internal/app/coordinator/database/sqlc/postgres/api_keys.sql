@@ -1,6 +1,6 @@
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at)
-VALUES ($1, $2, $3, $4, $5, $6)
+INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at, scopes, allowed_cidrs)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8)
 RETURNING *;
 
 -- name: GetAPIKeyByHash :one
@@ -17,3 +17,20 @@ DELETE FROM api_keys WHERE id = $1;
 
 -- name: UpdateAPIKeyLastUsed :exec
 UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = $1;
+
+-- name: DeprecateAPIKey :exec
+UPDATE api_keys SET deprecated_at = CURRENT_TIMESTAMP, successor_id = $1, expires_at = $2 WHERE id = $3;
+
+-- name: RecordAPIKeyUsage :exec
+INSERT INTO api_key_usage (api_key_id, endpoint, request_count, last_used_at)
+VALUES ($1, $2, $3, CURRENT_TIMESTAMP)
+ON CONFLICT (api_key_id, endpoint) DO UPDATE
+SET request_count = api_key_usage.request_count + excluded.request_count, last_used_at = CURRENT_TIMESTAMP;
+
+-- name: ListAPIKeyUsageByWonderNet :many
+SELECT * FROM api_key_usage
+WHERE api_key_id IN (SELECT id FROM api_keys WHERE wonder_net_id = $1)
+ORDER BY api_key_id, request_count DESC, endpoint;
+
+-- name: DeleteAPIKeyUsage :exec
+DELETE FROM api_key_usage WHERE api_key_id = $1;
@@ -0,0 +1,17 @@
+-- name: CreateExecSession :one
+INSERT INTO exec_sessions (id, wonder_net_id, node_id, node_name, address, command, stdout, stderr, output_truncated, exit_code, error, recorded_by, started_at, duration_ms)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+RETURNING *;
+
+-- name: GetExecSessionByID :one
+SELECT * FROM exec_sessions WHERE id = $1;
+
+-- name: ListExecSessionsByWonderNet :many
+SELECT * FROM exec_sessions
+WHERE wonder_net_id = $1
+ORDER BY started_at DESC, id DESC
+LIMIT $2;
+
+-- name: DeleteExecSessionsBefore :execrows
+DELETE FROM exec_sessions
+WHERE started_at < $1;
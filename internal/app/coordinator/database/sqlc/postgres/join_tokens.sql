@@ -0,0 +1,16 @@
+-- name: CreateJoinToken :exec
+INSERT INTO join_tokens (jti, wonder_net_id, created_by, max_uses, expires_at, allowed_cidrs)
+VALUES ($1, $2, $3, $4, $5, $6);
+
+-- name: GetJoinToken :one
+SELECT * FROM join_tokens WHERE jti = $1;
+
+-- name: UseJoinToken :execrows
+UPDATE join_tokens
+SET uses = uses + 1, last_used_at = CURRENT_TIMESTAMP
+WHERE jti = $1 AND uses < max_uses;
+
+-- name: ReleaseJoinToken :exec
+UPDATE join_tokens
+SET uses = uses - 1
+WHERE jti = $1 AND uses > 0;
@@ -9,23 +9,288 @@ import (
 	"time"
 )
 
+type AccessRequest struct {
+	ID              string       `json:"id"`
+	WonderNetID     string       `json:"wonder_net_id"`
+	ServiceID       string       `json:"service_id"`
+	ServiceName     string       `json:"service_name"`
+	Subject         string       `json:"subject"`
+	Requester       string       `json:"requester"`
+	Reason          string       `json:"reason"`
+	DurationSeconds int64        `json:"duration_seconds"`
+	Status          string       `json:"status"`
+	DecidedBy       string       `json:"decided_by"`
+	GrantID         string       `json:"grant_id"`
+	DecidedAt       sql.NullTime `json:"decided_at"`
+	ExpiresAt       sql.NullTime `json:"expires_at"`
+	EndedAt         sql.NullTime `json:"ended_at"`
+	CreatedAt       time.Time    `json:"created_at"`
+}
+
+type AlertRule struct {
+	ID               string    `json:"id"`
+	WonderNetID      string    `json:"wonder_net_id"`
+	Name             string    `json:"name"`
+	Kind             string    `json:"kind"`
+	ThresholdSeconds int64     `json:"threshold_seconds"`
+	CreatedAt        time.Time `json:"created_at"`
+}
+
+type AlertSilence struct {
+	ID          string    `json:"id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	RuleID      string    `json:"rule_id"`
+	NodeName    string    `json:"node_name"`
+	Reason      string    `json:"reason"`
+	EndsAt      time.Time `json:"ends_at"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
 type ApiKey struct {
+	ID           string       `json:"id"`
+	WonderNetID  string       `json:"wonder_net_id"`
+	Name         string       `json:"name"`
+	KeyHash      string       `json:"key_hash"`
+	KeyPrefix    string       `json:"key_prefix"`
+	CreatedAt    time.Time    `json:"created_at"`
+	LastUsedAt   sql.NullTime `json:"last_used_at"`
+	ExpiresAt    sql.NullTime `json:"expires_at"`
+	Scopes       string       `json:"scopes"`
+	AllowedCidrs string       `json:"allowed_cidrs"`
+	DeprecatedAt sql.NullTime `json:"deprecated_at"`
+	SuccessorID  string       `json:"successor_id"`
+}
+
+type ApiKeyUsage struct {
+	ApiKeyID     string    `json:"api_key_id"`
+	Endpoint     string    `json:"endpoint"`
+	RequestCount int64     `json:"request_count"`
+	LastUsedAt   time.Time `json:"last_used_at"`
+}
+
+type DnsDomain struct {
+	WonderNetID string    `json:"wonder_net_id"`
+	Domain      string    `json:"domain"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+type DnsRecord struct {
+	ID          string    `json:"id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	Name        string    `json:"name"`
+	Type        string    `json:"type"`
+	Value       string    `json:"value"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ExecSession struct {
+	ID              string    `json:"id"`
+	WonderNetID     string    `json:"wonder_net_id"`
+	NodeID          string    `json:"node_id"`
+	NodeName        string    `json:"node_name"`
+	Address         string    `json:"address"`
+	Command         string    `json:"command"`
+	Stdout          string    `json:"stdout"`
+	Stderr          string    `json:"stderr"`
+	OutputTruncated bool      `json:"output_truncated"`
+	ExitCode        int64     `json:"exit_code"`
+	Error           string    `json:"error"`
+	RecordedBy      string    `json:"recorded_by"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMs      int64     `json:"duration_ms"`
+	CreatedAt       time.Time `json:"created_at"`
+}
+
+type FiringAlert struct {
+	RuleID      string    `json:"rule_id"`
+	NodeName    string    `json:"node_name"`
+	WonderNetID string    `json:"wonder_net_id"`
+	NodeID      int64     `json:"node_id"`
+	StartsAt    time.Time `json:"starts_at"`
+	Silenced    bool      `json:"silenced"`
+}
+
+type JoinToken struct {
+	Jti          string       `json:"jti"`
+	WonderNetID  string       `json:"wonder_net_id"`
+	CreatedBy    string       `json:"created_by"`
+	MaxUses      int64        `json:"max_uses"`
+	Uses         int64        `json:"uses"`
+	CreatedAt    time.Time    `json:"created_at"`
+	ExpiresAt    time.Time    `json:"expires_at"`
+	LastUsedAt   sql.NullTime `json:"last_used_at"`
+	AllowedCidrs string       `json:"allowed_cidrs"`
+}
+
+type NodeApproval struct {
+	NodeID      string       `json:"node_id"`
+	WonderNetID string       `json:"wonder_net_id"`
+	Status      string       `json:"status"`
+	DecidedBy   string       `json:"decided_by"`
+	CreatedAt   time.Time    `json:"created_at"`
+	DecidedAt   sql.NullTime `json:"decided_at"`
+}
+
+type NodeDecommission struct {
 	ID          string       `json:"id"`
 	WonderNetID string       `json:"wonder_net_id"`
-	Name        string       `json:"name"`
-	KeyHash     string       `json:"key_hash"`
-	KeyPrefix   string       `json:"key_prefix"`
+	NodeID      string       `json:"node_id"`
+	NodeName    string       `json:"node_name"`
+	Hostname    string       `json:"hostname"`
+	Addresses   string       `json:"addresses"`
+	Os          string       `json:"os"`
+	LastSeenAt  sql.NullTime `json:"last_seen_at"`
+	RequestedBy string       `json:"requested_by"`
+	Reason      string       `json:"reason"`
+	Status      string       `json:"status"`
+	WipeStatus  string       `json:"wipe_status"`
+	WipeOutput  string       `json:"wipe_output"`
+	Error       string       `json:"error"`
 	CreatedAt   time.Time    `json:"created_at"`
-	LastUsedAt  sql.NullTime `json:"last_used_at"`
-	ExpiresAt   sql.NullTime `json:"expires_at"`
+	CompletedAt sql.NullTime `json:"completed_at"`
 }
 
-type WonderNet struct {
+type NodeHeartbeat struct {
+	NodeID               string    `json:"node_id"`
+	WonderNetID          string    `json:"wonder_net_id"`
+	Os                   string    `json:"os"`
+	TailscaleVersion     string    `json:"tailscale_version"`
+	DiskTotalBytes       int64     `json:"disk_total_bytes"`
+	DiskFreeBytes        int64     `json:"disk_free_bytes"`
+	MemoryTotalBytes     int64     `json:"memory_total_bytes"`
+	MemoryAvailableBytes int64     `json:"memory_available_bytes"`
+	Connectivity         string    `json:"connectivity"`
+	ReportedAt           time.Time `json:"reported_at"`
+}
+
+type NodeName struct {
+	NodeID      string    `json:"node_id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	Name        string    `json:"name"`
+	RenamedBy   string    `json:"renamed_by"`
+	RenamedAt   time.Time `json:"renamed_at"`
+}
+
+type NotificationChannel struct {
 	ID            string    `json:"id"`
-	OwnerID       string    `json:"owner_id"`
-	HeadscaleUser string    `json:"headscale_user"`
-	DisplayName   string    `json:"display_name"`
-	MeshType      string    `json:"mesh_type"`
+	WonderNetID   string    `json:"wonder_net_id"`
+	Kind          string    `json:"kind"`
+	Target        string    `json:"target"`
+	Events        string    `json:"events"`
+	DigestSeconds int64     `json:"digest_seconds"`
+	CreatedBy     string    `json:"created_by"`
 	CreatedAt     time.Time `json:"created_at"`
-	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+type Service struct {
+	ID          string    `json:"id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	Name        string    `json:"name"`
+	NodeID      string    `json:"node_id"`
+	Port        int32     `json:"port"`
+	Protocol    string    `json:"protocol"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type ServiceGrant struct {
+	ID        string    `json:"id"`
+	ServiceID string    `json:"service_id"`
+	Subject   string    `json:"subject"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type User struct {
+	ID           string    `json:"id"`
+	Provider     string    `json:"provider"`
+	Subject      string    `json:"subject"`
+	Username     string    `json:"username"`
+	Email        string    `json:"email"`
+	Name         string    `json:"name"`
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+type Webhook struct {
+	ID          string    `json:"id"`
+	WonderNetID string    `json:"wonder_net_id"`
+	Url         string    `json:"url"`
+	Secret      string    `json:"secret"`
+	Events      string    `json:"events"`
+	CreatedBy   string    `json:"created_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WebhookDelivery struct {
+	ID             string       `json:"id"`
+	WebhookID      string       `json:"webhook_id"`
+	WonderNetID    string       `json:"wonder_net_id"`
+	Event          string       `json:"event"`
+	Payload        string       `json:"payload"`
+	Status         string       `json:"status"`
+	Attempts       int64        `json:"attempts"`
+	ResponseStatus int64        `json:"response_status"`
+	LastError      string       `json:"last_error"`
+	NextAttemptAt  time.Time    `json:"next_attempt_at"`
+	CreatedAt      time.Time    `json:"created_at"`
+	DeliveredAt    sql.NullTime `json:"delivered_at"`
+}
+
+type WonderNet struct {
+	ID                         string    `json:"id"`
+	OwnerID                    string    `json:"owner_id"`
+	HeadscaleUser              string    `json:"headscale_user"`
+	DisplayName                string    `json:"display_name"`
+	MeshType                   string    `json:"mesh_type"`
+	IsDefault                  bool      `json:"is_default"`
+	NodeNameTemplate           string    `json:"node_name_template"`
+	AclRules                   string    `json:"acl_rules"`
+	PublicUrl                  string    `json:"public_url"`
+	RequireNodeApproval        bool      `json:"require_node_approval"`
+	NodeExpiryDays             int64     `json:"node_expiry_days"`
+	JoinTokenDefaultTtlSeconds int64     `json:"join_token_default_ttl_seconds"`
+	JoinTokenMaxTtlSeconds     int64     `json:"join_token_max_ttl_seconds"`
+	CreatedAt                  time.Time `json:"created_at"`
+	UpdatedAt                  time.Time `json:"updated_at"`
+}
+
+type WonderNetInvite struct {
+	ID          string       `json:"id"`
+	WonderNetID string       `json:"wonder_net_id"`
+	Email       string       `json:"email"`
+	UserID      string       `json:"user_id"`
+	Role        string       `json:"role"`
+	InvitedBy   string       `json:"invited_by"`
+	Status      string       `json:"status"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	DecidedAt   sql.NullTime `json:"decided_at"`
+}
+
+type WonderNetMember struct {
+	WonderNetID string    `json:"wonder_net_id"`
+	UserID      string    `json:"user_id"`
+	Email       string    `json:"email"`
+	Role        string    `json:"role"`
+	AddedBy     string    `json:"added_by"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+type WonderNetQuota struct {
+	WonderNetID     string    `json:"wonder_net_id"`
+	MaxNodes        int64     `json:"max_nodes"`
+	MaxApiKeys      int64     `json:"max_api_keys"`
+	AuthkeysPerHour int64     `json:"authkeys_per_hour"`
+	UpdatedAt       time.Time `json:"updated_at"`
+}
+
+type WorkerToken struct {
+	ID          string       `json:"id"`
+	WonderNetID string       `json:"wonder_net_id"`
+	AuthKeyID   string       `json:"auth_key_id"`
+	NodeID      string       `json:"node_id"`
+	CreatedAt   time.Time    `json:"created_at"`
+	ExpiresAt   time.Time    `json:"expires_at"`
+	LastUsedAt  sql.NullTime `json:"last_used_at"`
+	RevokedAt   sql.NullTime `json:"revoked_at"`
 }
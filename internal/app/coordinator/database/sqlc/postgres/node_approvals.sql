@@ -0,0 +1,21 @@
+-- name: CreateNodeApproval :exec
+INSERT INTO node_approvals (node_id, wonder_net_id, status, decided_by, created_at, decided_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP, $5)
+ON CONFLICT (node_id) DO NOTHING;
+
+-- name: GetNodeApproval :one
+SELECT * FROM node_approvals WHERE node_id = $1;
+
+-- name: ListNodeApprovalsByWonderNet :many
+SELECT * FROM node_approvals WHERE wonder_net_id = $1 ORDER BY created_at, node_id;
+
+-- name: ListPendingNodeApprovalsByWonderNet :many
+SELECT * FROM node_approvals WHERE wonder_net_id = $1 AND status = 'pending' ORDER BY created_at, node_id;
+
+-- name: DecideNodeApproval :exec
+UPDATE node_approvals
+SET status = $1, decided_by = $2, decided_at = CURRENT_TIMESTAMP
+WHERE node_id = $3;
+
+-- name: DeleteNodeApproval :exec
+DELETE FROM node_approvals WHERE node_id = $1;
@@ -0,0 +1,31 @@
+-- name: CreateNodeDecommission :one
+INSERT INTO node_decommissions (id, wonder_net_id, node_id, node_name, hostname, addresses, os, last_seen_at, requested_by, reason, wipe_status)
+VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+RETURNING *;
+
+-- name: GetNodeDecommissionByID :one
+SELECT * FROM node_decommissions WHERE id = $1;
+
+-- name: GetActiveNodeDecommissionByNode :one
+SELECT * FROM node_decommissions
+WHERE node_id = $1 AND status = 'in_progress'
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListNodeDecommissionsByWonderNet :many
+SELECT * FROM node_decommissions WHERE wonder_net_id = $1 ORDER BY created_at DESC, id DESC;
+
+-- name: FinishNodeWipe :execrows
+UPDATE node_decommissions
+SET wipe_status = $1, wipe_output = $2
+WHERE id = $3 AND status = 'in_progress' AND wipe_status = 'pending';
+
+-- name: EndNodeDecommission :execrows
+UPDATE node_decommissions
+SET status = $1, error = $2, completed_at = CURRENT_TIMESTAMP
+WHERE id = $3 AND status = 'in_progress';
+
+-- name: ListStaleNodeWipes :many
+SELECT * FROM node_decommissions
+WHERE status = 'in_progress' AND wipe_status = 'pending' AND created_at <= $1
+ORDER BY created_at;
@@ -0,0 +1,13 @@
+-- name: UpsertNodeName :exec
+INSERT INTO node_names (node_id, wonder_net_id, name, renamed_by, renamed_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+ON CONFLICT (node_id) DO UPDATE
+SET name = excluded.name,
+    renamed_by = excluded.renamed_by,
+    renamed_at = CURRENT_TIMESTAMP;
+
+-- name: ListNodeNamesByWonderNet :many
+SELECT * FROM node_names WHERE wonder_net_id = $1 ORDER BY node_id;
+
+-- name: DeleteNodeName :exec
+DELETE FROM node_names WHERE node_id = $1;
@@ -0,0 +1,37 @@
+-- name: CreateService :one
+INSERT INTO services (id, wonder_net_id, name, node_id, port, protocol)
+VALUES ($1, $2, $3, $4, $5, $6)
+RETURNING *;
+
+-- name: GetServiceByID :one
+SELECT * FROM services WHERE id = $1;
+
+-- name: ListServicesByWonderNet :many
+SELECT * FROM services WHERE wonder_net_id = $1 ORDER BY name;
+
+-- name: ListServices :many
+SELECT * FROM services ORDER BY wonder_net_id, name;
+
+-- name: DeleteService :exec
+DELETE FROM services WHERE id = $1;
+
+-- name: CreateServiceGrant :one
+INSERT INTO service_grants (id, service_id, subject)
+VALUES ($1, $2, $3)
+RETURNING *;
+
+-- name: GetServiceGrantByID :one
+SELECT * FROM service_grants WHERE id = $1;
+
+-- name: ListServiceGrantsByWonderNet :many
+SELECT service_grants.id, service_grants.service_id, service_grants.subject, service_grants.created_at
+FROM service_grants
+JOIN services ON services.id = service_grants.service_id
+WHERE services.wonder_net_id = $1
+ORDER BY service_grants.created_at;
+
+-- name: DeleteServiceGrant :exec
+DELETE FROM service_grants WHERE id = $1;
+
+-- name: DeleteServiceGrantsByService :exec
+DELETE FROM service_grants WHERE service_id = $1;
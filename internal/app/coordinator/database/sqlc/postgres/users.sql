@@ -0,0 +1,25 @@
+-- name: CreateUser :one
+INSERT INTO users (id, provider, subject, username, email, name, password_hash)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = $1;
+
+-- name: GetUserByProviderSubject :one
+SELECT * FROM users WHERE provider = $1 AND subject = $2;
+
+-- name: ListUsersByEmail :many
+SELECT * FROM users WHERE email = $1 ORDER BY created_at;
+
+-- name: ListUsers :many
+SELECT * FROM users ORDER BY provider, subject;
+
+-- name: UpdateUserProfile :exec
+UPDATE users SET username = $1, email = $2, name = $3 WHERE id = $4;
+
+-- name: UpdateUserPasswordHash :exec
+UPDATE users SET password_hash = $1 WHERE id = $2;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = $1;
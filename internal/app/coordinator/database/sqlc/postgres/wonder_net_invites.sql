@@ -0,0 +1,28 @@
+-- name: CreateWonderNetInvite :one
+INSERT INTO wonder_net_invites (id, wonder_net_id, email, user_id, role, invited_by, expires_at)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING *;
+
+-- name: GetWonderNetInvite :one
+SELECT * FROM wonder_net_invites WHERE id = $1;
+
+-- name: GetPendingWonderNetInvite :one
+SELECT * FROM wonder_net_invites
+WHERE wonder_net_id = $1 AND user_id = $2 AND status = 'pending'
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListPendingWonderNetInvitesByWonderNet :many
+SELECT * FROM wonder_net_invites
+WHERE wonder_net_id = $1 AND status = 'pending'
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListPendingWonderNetInvitesByUser :many
+SELECT * FROM wonder_net_invites
+WHERE user_id = $1 AND status = 'pending'
+ORDER BY created_at DESC, id DESC;
+
+-- name: DecideWonderNetInvite :execrows
+UPDATE wonder_net_invites
+SET status = $1, decided_at = CURRENT_TIMESTAMP
+WHERE id = $2 AND status = 'pending';
@@ -0,0 +1,19 @@
+-- name: AddWonderNetMember :exec
+INSERT INTO wonder_net_members (wonder_net_id, user_id, email, role, added_by)
+VALUES ($1, $2, $3, $4, $5)
+ON CONFLICT (wonder_net_id, user_id) DO UPDATE SET email = excluded.email, role = excluded.role;
+
+-- name: GetWonderNetMember :one
+SELECT * FROM wonder_net_members WHERE wonder_net_id = $1 AND user_id = $2;
+
+-- name: ListWonderNetMembers :many
+SELECT * FROM wonder_net_members WHERE wonder_net_id = $1 ORDER BY created_at, user_id;
+
+-- name: ListWonderNetMembershipsByUser :many
+SELECT * FROM wonder_net_members WHERE user_id = $1 ORDER BY created_at, wonder_net_id;
+
+-- name: UpdateWonderNetMemberRole :execrows
+UPDATE wonder_net_members SET role = $1 WHERE wonder_net_id = $2 AND user_id = $3;
+
+-- name: DeleteWonderNetMember :execrows
+DELETE FROM wonder_net_members WHERE wonder_net_id = $1 AND user_id = $2;
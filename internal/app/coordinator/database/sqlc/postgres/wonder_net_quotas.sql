@@ -0,0 +1,15 @@
+-- name: GetWonderNetQuotas :one
+SELECT * FROM wonder_net_quotas WHERE wonder_net_id = $1;
+
+-- name: UpsertWonderNetQuotas :one
+INSERT INTO wonder_net_quotas (wonder_net_id, max_nodes, max_api_keys, authkeys_per_hour, updated_at)
+VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP)
+ON CONFLICT (wonder_net_id) DO UPDATE
+SET max_nodes = excluded.max_nodes,
+    max_api_keys = excluded.max_api_keys,
+    authkeys_per_hour = excluded.authkeys_per_hour,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: DeleteWonderNetQuotas :exec
+DELETE FROM wonder_net_quotas WHERE wonder_net_id = $1;
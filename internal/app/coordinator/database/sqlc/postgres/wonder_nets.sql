@@ -21,3 +21,85 @@ DELETE FROM wonder_nets WHERE id = $1;
 
 -- name: ListWonderNets :many
 SELECT * FROM wonder_nets ORDER BY created_at DESC;
+
+-- name: CountWonderNets :one
+SELECT COUNT(*) FROM wonder_nets;
+
+-- name: CountWonderNetsByMeshType :many
+SELECT mesh_type, COUNT(*) AS count FROM wonder_nets GROUP BY mesh_type ORDER BY mesh_type;
+
+-- name: GetDefaultWonderNetByOwner :one
+SELECT * FROM wonder_nets WHERE owner_id = $1 AND is_default ORDER BY created_at LIMIT 1;
+
+-- name: SetDefaultWonderNet :exec
+UPDATE wonder_nets
+SET is_default = (id = $1), updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = $2;
+
+-- name: ListWonderNetsPageDesc :many
+SELECT * FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF($1, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF($2, ''), mesh_type)
+  AND created_at >= $3
+  AND created_at < $4
+  AND (created_at, id) < ($5, $6)
+ORDER BY created_at DESC, id DESC
+LIMIT $7;
+
+-- name: ListWonderNetsPageAsc :many
+SELECT * FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF($1, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF($2, ''), mesh_type)
+  AND created_at >= $3
+  AND created_at < $4
+  AND (created_at, id) > ($5, $6)
+ORDER BY created_at ASC, id ASC
+LIMIT $7;
+
+-- name: UpdateWonderNetNodeNameTemplate :exec
+UPDATE wonder_nets
+SET node_name_template = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: ListWonderNetsWithNodeNameTemplate :many
+SELECT * FROM wonder_nets WHERE node_name_template <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetACLRules :exec
+UPDATE wonder_nets
+SET acl_rules = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: ListWonderNetsWithACLRules :many
+SELECT * FROM wonder_nets WHERE acl_rules <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetPublicURL :exec
+UPDATE wonder_nets
+SET public_url = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: GetWonderNetByPublicURL :one
+SELECT * FROM wonder_nets WHERE public_url = $1;
+
+-- name: ListWonderNetsWithPublicURL :many
+SELECT * FROM wonder_nets WHERE public_url <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetRequireNodeApproval :exec
+UPDATE wonder_nets
+SET require_node_approval = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: ListWonderNetsRequiringNodeApproval :many
+SELECT * FROM wonder_nets WHERE require_node_approval ORDER BY created_at;
+
+-- name: UpdateWonderNetNodeExpiryDays :exec
+UPDATE wonder_nets
+SET node_expiry_days = $1, updated_at = CURRENT_TIMESTAMP
+WHERE id = $2;
+
+-- name: UpdateWonderNetJoinTokenTTL :exec
+UPDATE wonder_nets
+SET join_token_default_ttl_seconds = $1, join_token_max_ttl_seconds = $2, updated_at = CURRENT_TIMESTAMP
+WHERE id = $3;
+
+-- name: ListWonderNetsWithNodeExpiry :many
+SELECT * FROM wonder_nets WHERE node_expiry_days > 0 ORDER BY created_at;
@@ -0,0 +1,20 @@
+-- name: CreateWorkerToken :exec
+INSERT INTO worker_tokens (id, wonder_net_id, auth_key_id, expires_at)
+VALUES ($1, $2, $3, $4);
+
+-- name: GetWorkerToken :one
+SELECT * FROM worker_tokens WHERE id = $1;
+
+-- name: TouchWorkerToken :exec
+UPDATE worker_tokens
+SET node_id = $1, expires_at = $2, last_used_at = CURRENT_TIMESTAMP
+WHERE id = $3;
+
+-- name: UpdateWorkerTokenAuthKey :exec
+UPDATE worker_tokens SET auth_key_id = $1 WHERE id = $2;
+
+-- name: RevokeWorkerTokensByNode :exec
+UPDATE worker_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE wonder_net_id = $1 AND revoked_at IS NULL
+  AND (node_id = $2 OR (auth_key_id != '' AND auth_key_id = $3));
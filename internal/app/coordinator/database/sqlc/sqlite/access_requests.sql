@@ -0,0 +1,25 @@
+-- name: CreateAccessRequest :one
+INSERT INTO access_requests (id, wonder_net_id, service_id, service_name, subject, requester, reason, duration_seconds)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAccessRequestByID :one
+SELECT * FROM access_requests WHERE id = ?;
+
+-- name: ListAccessRequestsByWonderNet :many
+SELECT * FROM access_requests WHERE wonder_net_id = ? ORDER BY created_at DESC, id DESC;
+
+-- name: DecideAccessRequest :execrows
+UPDATE access_requests
+SET status = ?, decided_by = ?, grant_id = ?, expires_at = ?, decided_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending';
+
+-- name: EndAccessRequest :execrows
+UPDATE access_requests
+SET status = ?, ended_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'approved';
+
+-- name: ListExpiredAccessRequests :many
+SELECT * FROM access_requests
+WHERE status = 'approved' AND datetime(expires_at) <= datetime(?)
+ORDER BY expires_at;
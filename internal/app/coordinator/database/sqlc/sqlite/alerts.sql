@@ -0,0 +1,46 @@
+-- name: CreateAlertRule :one
+INSERT INTO alert_rules (id, wonder_net_id, name, kind, threshold_seconds)
+VALUES (?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAlertRuleByID :one
+SELECT * FROM alert_rules WHERE id = ?;
+
+-- name: ListAlertRulesByWonderNet :many
+SELECT * FROM alert_rules WHERE wonder_net_id = ? ORDER BY created_at DESC;
+
+-- name: ListAlertRules :many
+SELECT * FROM alert_rules ORDER BY wonder_net_id, created_at;
+
+-- name: DeleteAlertRule :exec
+DELETE FROM alert_rules WHERE id = ?;
+
+-- name: CreateAlertSilence :one
+INSERT INTO alert_silences (id, wonder_net_id, rule_id, node_name, reason, ends_at)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetAlertSilenceByID :one
+SELECT * FROM alert_silences WHERE id = ?;
+
+-- name: ListAlertSilencesByWonderNet :many
+SELECT * FROM alert_silences WHERE wonder_net_id = ? ORDER BY created_at DESC;
+
+-- name: DeleteAlertSilence :exec
+DELETE FROM alert_silences WHERE id = ?;
+
+-- name: ListFiringAlerts :many
+SELECT * FROM firing_alerts ORDER BY starts_at;
+
+-- name: UpsertFiringAlert :exec
+INSERT INTO firing_alerts (rule_id, node_name, wonder_net_id, node_id, starts_at, silenced)
+VALUES (?, ?, ?, ?, ?, ?)
+ON CONFLICT (rule_id, node_name) DO UPDATE
+SET node_id = excluded.node_id,
+    silenced = excluded.silenced;
+
+-- name: DeleteFiringAlert :exec
+DELETE FROM firing_alerts WHERE rule_id = ? AND node_name = ?;
+
+-- name: DeleteFiringAlertsByRule :exec
+DELETE FROM firing_alerts WHERE rule_id = ?;
@@ -1,6 +1,6 @@
 -- name: CreateAPIKey :one
-INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at)
-VALUES (?, ?, ?, ?, ?, ?)
+INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at, scopes, allowed_cidrs)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 RETURNING *;
 
 -- name: GetAPIKeyByHash :one
@@ -17,3 +17,20 @@ DELETE FROM api_keys WHERE id = ?;
 
 -- name: UpdateAPIKeyLastUsed :exec
 UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?;
+
+-- name: DeprecateAPIKey :exec
+UPDATE api_keys SET deprecated_at = CURRENT_TIMESTAMP, successor_id = ?, expires_at = ? WHERE id = ?;
+
+-- name: RecordAPIKeyUsage :exec
+INSERT INTO api_key_usage (api_key_id, endpoint, request_count, last_used_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (api_key_id, endpoint) DO UPDATE
+SET request_count = api_key_usage.request_count + excluded.request_count, last_used_at = CURRENT_TIMESTAMP;
+
+-- name: ListAPIKeyUsageByWonderNet :many
+SELECT * FROM api_key_usage
+WHERE api_key_id IN (SELECT id FROM api_keys WHERE wonder_net_id = ?)
+ORDER BY api_key_id, request_count DESC, endpoint;
+
+-- name: DeleteAPIKeyUsage :exec
+DELETE FROM api_key_usage WHERE api_key_id = ?;
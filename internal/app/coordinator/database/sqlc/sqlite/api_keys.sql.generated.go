@@ -11,18 +11,20 @@ import (
 )
 
 const createAPIKey = `-- name: CreateAPIKey :one
-INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at)
-VALUES (?, ?, ?, ?, ?, ?)
-RETURNING id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at
+INSERT INTO api_keys (id, wonder_net_id, name, key_hash, key_prefix, expires_at, scopes, allowed_cidrs)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at, scopes, allowed_cidrs, deprecated_at, successor_id
 `
 
 type CreateAPIKeyParams struct {
-	ID          string       `json:"id"`
-	WonderNetID string       `json:"wonder_net_id"`
-	Name        string       `json:"name"`
-	KeyHash     string       `json:"key_hash"`
-	KeyPrefix   string       `json:"key_prefix"`
-	ExpiresAt   sql.NullTime `json:"expires_at"`
+	ID           string       `json:"id"`
+	WonderNetID  string       `json:"wonder_net_id"`
+	Name         string       `json:"name"`
+	KeyHash      string       `json:"key_hash"`
+	KeyPrefix    string       `json:"key_prefix"`
+	ExpiresAt    sql.NullTime `json:"expires_at"`
+	Scopes       string       `json:"scopes"`
+	AllowedCidrs string       `json:"allowed_cidrs"`
 }
 
 func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (ApiKey, error) {
@@ -33,6 +35,8 @@ func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (Api
 		arg.KeyHash,
 		arg.KeyPrefix,
 		arg.ExpiresAt,
+		arg.Scopes,
+		arg.AllowedCidrs,
 	)
 	var i ApiKey
 	err := row.Scan(
@@ -44,6 +48,10 @@ func (q *Queries) CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (Api
 		&i.CreatedAt,
 		&i.LastUsedAt,
 		&i.ExpiresAt,
+		&i.Scopes,
+		&i.AllowedCidrs,
+		&i.DeprecatedAt,
+		&i.SuccessorID,
 	)
 	return i, err
 }
@@ -57,8 +65,32 @@ func (q *Queries) DeleteAPIKey(ctx context.Context, id string) error {
 	return err
 }
 
+const deleteAPIKeyUsage = `-- name: DeleteAPIKeyUsage :exec
+DELETE FROM api_key_usage WHERE api_key_id = ?
+`
+
+func (q *Queries) DeleteAPIKeyUsage(ctx context.Context, apiKeyID string) error {
+	_, err := q.db.ExecContext(ctx, deleteAPIKeyUsage, apiKeyID)
+	return err
+}
+
+const deprecateAPIKey = `-- name: DeprecateAPIKey :exec
+UPDATE api_keys SET deprecated_at = CURRENT_TIMESTAMP, successor_id = ?, expires_at = ? WHERE id = ?
+`
+
+type DeprecateAPIKeyParams struct {
+	SuccessorID string       `json:"successor_id"`
+	ExpiresAt   sql.NullTime `json:"expires_at"`
+	ID          string       `json:"id"`
+}
+
+func (q *Queries) DeprecateAPIKey(ctx context.Context, arg DeprecateAPIKeyParams) error {
+	_, err := q.db.ExecContext(ctx, deprecateAPIKey, arg.SuccessorID, arg.ExpiresAt, arg.ID)
+	return err
+}
+
 const getAPIKeyByHash = `-- name: GetAPIKeyByHash :one
-SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at FROM api_keys WHERE key_hash = ?
+SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at, scopes, allowed_cidrs, deprecated_at, successor_id FROM api_keys WHERE key_hash = ?
 `
 
 func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey, error) {
@@ -73,12 +105,16 @@ func (q *Queries) GetAPIKeyByHash(ctx context.Context, keyHash string) (ApiKey,
 		&i.CreatedAt,
 		&i.LastUsedAt,
 		&i.ExpiresAt,
+		&i.Scopes,
+		&i.AllowedCidrs,
+		&i.DeprecatedAt,
+		&i.SuccessorID,
 	)
 	return i, err
 }
 
 const getAPIKeyByID = `-- name: GetAPIKeyByID :one
-SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at FROM api_keys WHERE id = ?
+SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at, scopes, allowed_cidrs, deprecated_at, successor_id FROM api_keys WHERE id = ?
 `
 
 func (q *Queries) GetAPIKeyByID(ctx context.Context, id string) (ApiKey, error) {
@@ -93,12 +129,50 @@ func (q *Queries) GetAPIKeyByID(ctx context.Context, id string) (ApiKey, error)
 		&i.CreatedAt,
 		&i.LastUsedAt,
 		&i.ExpiresAt,
+		&i.Scopes,
+		&i.AllowedCidrs,
+		&i.DeprecatedAt,
+		&i.SuccessorID,
 	)
 	return i, err
 }
 
+const listAPIKeyUsageByWonderNet = `-- name: ListAPIKeyUsageByWonderNet :many
+SELECT api_key_id, endpoint, request_count, last_used_at FROM api_key_usage
+WHERE api_key_id IN (SELECT id FROM api_keys WHERE wonder_net_id = ?)
+ORDER BY api_key_id, request_count DESC, endpoint
+`
+
+func (q *Queries) ListAPIKeyUsageByWonderNet(ctx context.Context, wonderNetID string) ([]ApiKeyUsage, error) {
+	rows, err := q.db.QueryContext(ctx, listAPIKeyUsageByWonderNet, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []ApiKeyUsage{}
+	for rows.Next() {
+		var i ApiKeyUsage
+		if err := rows.Scan(
+			&i.ApiKeyID,
+			&i.Endpoint,
+			&i.RequestCount,
+			&i.LastUsedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const listAPIKeysByWonderNet = `-- name: ListAPIKeysByWonderNet :many
-SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at FROM api_keys WHERE wonder_net_id = ? ORDER BY created_at DESC
+SELECT id, wonder_net_id, name, key_hash, key_prefix, created_at, last_used_at, expires_at, scopes, allowed_cidrs, deprecated_at, successor_id FROM api_keys WHERE wonder_net_id = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string) ([]ApiKey, error) {
@@ -119,6 +193,10 @@ func (q *Queries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string
 			&i.CreatedAt,
 			&i.LastUsedAt,
 			&i.ExpiresAt,
+			&i.Scopes,
+			&i.AllowedCidrs,
+			&i.DeprecatedAt,
+			&i.SuccessorID,
 		); err != nil {
 			return nil, err
 		}
@@ -133,6 +211,24 @@ func (q *Queries) ListAPIKeysByWonderNet(ctx context.Context, wonderNetID string
 	return items, nil
 }
 
+const recordAPIKeyUsage = `-- name: RecordAPIKeyUsage :exec
+INSERT INTO api_key_usage (api_key_id, endpoint, request_count, last_used_at)
+VALUES (?, ?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (api_key_id, endpoint) DO UPDATE
+SET request_count = api_key_usage.request_count + excluded.request_count, last_used_at = CURRENT_TIMESTAMP
+`
+
+type RecordAPIKeyUsageParams struct {
+	ApiKeyID     string `json:"api_key_id"`
+	Endpoint     string `json:"endpoint"`
+	RequestCount int64  `json:"request_count"`
+}
+
+func (q *Queries) RecordAPIKeyUsage(ctx context.Context, arg RecordAPIKeyUsageParams) error {
+	_, err := q.db.ExecContext(ctx, recordAPIKeyUsage, arg.ApiKeyID, arg.Endpoint, arg.RequestCount)
+	return err
+}
+
 const updateAPIKeyLastUsed = `-- name: UpdateAPIKeyLastUsed :exec
 UPDATE api_keys SET last_used_at = CURRENT_TIMESTAMP WHERE id = ?
 `
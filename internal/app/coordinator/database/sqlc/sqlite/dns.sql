@@ -0,0 +1,33 @@
+-- name: GetDNSDomain :one
+SELECT * FROM dns_domains WHERE wonder_net_id = ?;
+
+-- name: GetDNSDomainByName :one
+SELECT * FROM dns_domains WHERE domain = ?;
+
+-- name: ListDNSDomains :many
+SELECT * FROM dns_domains ORDER BY domain;
+
+-- name: UpsertDNSDomain :one
+INSERT INTO dns_domains (wonder_net_id, domain, updated_at)
+VALUES (?, ?, CURRENT_TIMESTAMP)
+ON CONFLICT (wonder_net_id) DO UPDATE
+SET domain = excluded.domain,
+    updated_at = CURRENT_TIMESTAMP
+RETURNING *;
+
+-- name: CreateDNSRecord :one
+INSERT INTO dns_records (id, wonder_net_id, name, type, value, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetDNSRecordByID :one
+SELECT * FROM dns_records WHERE id = ?;
+
+-- name: ListDNSRecordsByWonderNet :many
+SELECT * FROM dns_records WHERE wonder_net_id = ? ORDER BY name, created_at;
+
+-- name: ListDNSRecords :many
+SELECT * FROM dns_records ORDER BY wonder_net_id, name, created_at;
+
+-- name: DeleteDNSRecord :exec
+DELETE FROM dns_records WHERE id = ?;
@@ -0,0 +1,17 @@
+-- name: CreateExecSession :one
+INSERT INTO exec_sessions (id, wonder_net_id, node_id, node_name, address, command, stdout, stderr, output_truncated, exit_code, error, recorded_by, started_at, duration_ms)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetExecSessionByID :one
+SELECT * FROM exec_sessions WHERE id = ?;
+
+-- name: ListExecSessionsByWonderNet :many
+SELECT * FROM exec_sessions
+WHERE wonder_net_id = ?
+ORDER BY started_at DESC, id DESC
+LIMIT ?;
+
+-- name: DeleteExecSessionsBefore :execrows
+DELETE FROM exec_sessions
+WHERE datetime(started_at) < datetime(?);
@@ -0,0 +1,21 @@
+-- name: CreateNodeApproval :exec
+INSERT INTO node_approvals (node_id, wonder_net_id, status, decided_by, created_at, decided_at)
+VALUES (?, ?, ?, ?, CURRENT_TIMESTAMP, ?)
+ON CONFLICT (node_id) DO NOTHING;
+
+-- name: GetNodeApproval :one
+SELECT * FROM node_approvals WHERE node_id = ?;
+
+-- name: ListNodeApprovalsByWonderNet :many
+SELECT * FROM node_approvals WHERE wonder_net_id = ? ORDER BY created_at, node_id;
+
+-- name: ListPendingNodeApprovalsByWonderNet :many
+SELECT * FROM node_approvals WHERE wonder_net_id = ? AND status = 'pending' ORDER BY created_at, node_id;
+
+-- name: DecideNodeApproval :exec
+UPDATE node_approvals
+SET status = ?, decided_by = ?, decided_at = CURRENT_TIMESTAMP
+WHERE node_id = ?;
+
+-- name: DeleteNodeApproval :exec
+DELETE FROM node_approvals WHERE node_id = ?;
@@ -0,0 +1,31 @@
+-- name: CreateNodeDecommission :one
+INSERT INTO node_decommissions (id, wonder_net_id, node_id, node_name, hostname, addresses, os, last_seen_at, requested_by, reason, wipe_status)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetNodeDecommissionByID :one
+SELECT * FROM node_decommissions WHERE id = ?;
+
+-- name: GetActiveNodeDecommissionByNode :one
+SELECT * FROM node_decommissions
+WHERE node_id = ? AND status = 'in_progress'
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListNodeDecommissionsByWonderNet :many
+SELECT * FROM node_decommissions WHERE wonder_net_id = ? ORDER BY created_at DESC, id DESC;
+
+-- name: FinishNodeWipe :execrows
+UPDATE node_decommissions
+SET wipe_status = ?, wipe_output = ?
+WHERE id = ? AND status = 'in_progress' AND wipe_status = 'pending';
+
+-- name: EndNodeDecommission :execrows
+UPDATE node_decommissions
+SET status = ?, error = ?, completed_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'in_progress';
+
+-- name: ListStaleNodeWipes :many
+SELECT * FROM node_decommissions
+WHERE status = 'in_progress' AND wipe_status = 'pending' AND datetime(created_at) <= datetime(?)
+ORDER BY created_at;
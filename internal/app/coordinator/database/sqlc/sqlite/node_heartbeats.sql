@@ -0,0 +1,25 @@
+-- name: UpsertNodeHeartbeat :exec
+INSERT INTO node_heartbeats (
+    node_id, wonder_net_id, os, tailscale_version,
+    disk_total_bytes, disk_free_bytes, memory_total_bytes, memory_available_bytes, connectivity, reported_at
+)
+VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+ON CONFLICT (node_id) DO UPDATE
+SET wonder_net_id = excluded.wonder_net_id,
+    os = excluded.os,
+    tailscale_version = excluded.tailscale_version,
+    disk_total_bytes = excluded.disk_total_bytes,
+    disk_free_bytes = excluded.disk_free_bytes,
+    memory_total_bytes = excluded.memory_total_bytes,
+    memory_available_bytes = excluded.memory_available_bytes,
+    connectivity = excluded.connectivity,
+    reported_at = excluded.reported_at;
+
+-- name: GetNodeHeartbeat :one
+SELECT * FROM node_heartbeats WHERE node_id = ?;
+
+-- name: ListNodeHeartbeatsByWonderNet :many
+SELECT * FROM node_heartbeats WHERE wonder_net_id = ? ORDER BY node_id;
+
+-- name: DeleteNodeHeartbeat :exec
+DELETE FROM node_heartbeats WHERE node_id = ?;
@@ -0,0 +1,16 @@
+-- name: CreateNotificationChannel :one
+INSERT INTO notification_channels (id, wonder_net_id, kind, target, events, digest_seconds, created_by)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetNotificationChannelByID :one
+SELECT * FROM notification_channels WHERE id = ?;
+
+-- name: ListNotificationChannelsByWonderNet :many
+SELECT * FROM notification_channels WHERE wonder_net_id = ? ORDER BY created_at;
+
+-- name: ListNotificationChannels :many
+SELECT * FROM notification_channels ORDER BY wonder_net_id, created_at;
+
+-- name: DeleteNotificationChannel :exec
+DELETE FROM notification_channels WHERE id = ?;
@@ -0,0 +1,37 @@
+-- name: CreateService :one
+INSERT INTO services (id, wonder_net_id, name, node_id, port, protocol)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetServiceByID :one
+SELECT * FROM services WHERE id = ?;
+
+-- name: ListServicesByWonderNet :many
+SELECT * FROM services WHERE wonder_net_id = ? ORDER BY name;
+
+-- name: ListServices :many
+SELECT * FROM services ORDER BY wonder_net_id, name;
+
+-- name: DeleteService :exec
+DELETE FROM services WHERE id = ?;
+
+-- name: CreateServiceGrant :one
+INSERT INTO service_grants (id, service_id, subject)
+VALUES (?, ?, ?)
+RETURNING *;
+
+-- name: GetServiceGrantByID :one
+SELECT * FROM service_grants WHERE id = ?;
+
+-- name: ListServiceGrantsByWonderNet :many
+SELECT service_grants.id, service_grants.service_id, service_grants.subject, service_grants.created_at
+FROM service_grants
+JOIN services ON services.id = service_grants.service_id
+WHERE services.wonder_net_id = ?
+ORDER BY service_grants.created_at;
+
+-- name: DeleteServiceGrant :exec
+DELETE FROM service_grants WHERE id = ?;
+
+-- name: DeleteServiceGrantsByService :exec
+DELETE FROM service_grants WHERE service_id = ?;
@@ -0,0 +1,25 @@
+-- name: CreateUser :one
+INSERT INTO users (id, provider, subject, username, email, name, password_hash)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetUserByID :one
+SELECT * FROM users WHERE id = ?;
+
+-- name: GetUserByProviderSubject :one
+SELECT * FROM users WHERE provider = ? AND subject = ?;
+
+-- name: ListUsersByEmail :many
+SELECT * FROM users WHERE email = ? ORDER BY created_at;
+
+-- name: ListUsers :many
+SELECT * FROM users ORDER BY provider, subject;
+
+-- name: UpdateUserProfile :exec
+UPDATE users SET username = ?, email = ?, name = ? WHERE id = ?;
+
+-- name: UpdateUserPasswordHash :exec
+UPDATE users SET password_hash = ? WHERE id = ?;
+
+-- name: DeleteUser :exec
+DELETE FROM users WHERE id = ?;
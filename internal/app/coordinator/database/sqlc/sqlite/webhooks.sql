@@ -0,0 +1,47 @@
+-- name: CreateWebhook :one
+INSERT INTO webhooks (id, wonder_net_id, url, secret, events, created_by)
+VALUES (?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWebhookByID :one
+SELECT * FROM webhooks WHERE id = ?;
+
+-- name: ListWebhooksByWonderNet :many
+SELECT * FROM webhooks WHERE wonder_net_id = ? ORDER BY created_at DESC;
+
+-- name: ListWebhooks :many
+SELECT * FROM webhooks ORDER BY wonder_net_id, created_at;
+
+-- name: DeleteWebhook :exec
+DELETE FROM webhooks WHERE id = ?;
+
+-- name: CreateWebhookDelivery :exec
+INSERT INTO webhook_deliveries (id, webhook_id, wonder_net_id, event, payload, next_attempt_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: ListDueWebhookDeliveries :many
+SELECT * FROM webhook_deliveries
+WHERE id IN (
+    SELECT id FROM (
+        SELECT id, ROW_NUMBER() OVER (PARTITION BY webhook_id ORDER BY next_attempt_at) AS position
+        FROM webhook_deliveries
+        WHERE status = 'pending' AND datetime(next_attempt_at) <= datetime(?)
+    ) due
+    WHERE position <= 10
+)
+ORDER BY next_attempt_at
+LIMIT 1000;
+
+-- name: ListWebhookDeliveriesByWebhook :many
+SELECT * FROM webhook_deliveries
+WHERE webhook_id = ?
+ORDER BY created_at DESC
+LIMIT 100;
+
+-- name: RecordWebhookDeliveryAttempt :exec
+UPDATE webhook_deliveries
+SET status = ?, attempts = ?, response_status = ?, last_error = ?, next_attempt_at = ?, delivered_at = ?
+WHERE id = ?;
+
+-- name: DeleteWebhookDeliveriesByWebhook :exec
+DELETE FROM webhook_deliveries WHERE webhook_id = ?;
@@ -0,0 +1,28 @@
+-- name: CreateWonderNetInvite :one
+INSERT INTO wonder_net_invites (id, wonder_net_id, email, user_id, role, invited_by, expires_at)
+VALUES (?, ?, ?, ?, ?, ?, ?)
+RETURNING *;
+
+-- name: GetWonderNetInvite :one
+SELECT * FROM wonder_net_invites WHERE id = ?;
+
+-- name: GetPendingWonderNetInvite :one
+SELECT * FROM wonder_net_invites
+WHERE wonder_net_id = ? AND user_id = ? AND status = 'pending'
+ORDER BY created_at DESC, id DESC
+LIMIT 1;
+
+-- name: ListPendingWonderNetInvitesByWonderNet :many
+SELECT * FROM wonder_net_invites
+WHERE wonder_net_id = ? AND status = 'pending'
+ORDER BY created_at DESC, id DESC;
+
+-- name: ListPendingWonderNetInvitesByUser :many
+SELECT * FROM wonder_net_invites
+WHERE user_id = ? AND status = 'pending'
+ORDER BY created_at DESC, id DESC;
+
+-- name: DecideWonderNetInvite :execrows
+UPDATE wonder_net_invites
+SET status = ?, decided_at = CURRENT_TIMESTAMP
+WHERE id = ? AND status = 'pending';
@@ -0,0 +1,19 @@
+-- name: AddWonderNetMember :exec
+INSERT INTO wonder_net_members (wonder_net_id, user_id, email, role, added_by)
+VALUES (?, ?, ?, ?, ?)
+ON CONFLICT (wonder_net_id, user_id) DO UPDATE SET email = excluded.email, role = excluded.role;
+
+-- name: GetWonderNetMember :one
+SELECT * FROM wonder_net_members WHERE wonder_net_id = ? AND user_id = ?;
+
+-- name: ListWonderNetMembers :many
+SELECT * FROM wonder_net_members WHERE wonder_net_id = ? ORDER BY created_at, user_id;
+
+-- name: ListWonderNetMembershipsByUser :many
+SELECT * FROM wonder_net_members WHERE user_id = ? ORDER BY created_at, wonder_net_id;
+
+-- name: UpdateWonderNetMemberRole :execrows
+UPDATE wonder_net_members SET role = ? WHERE wonder_net_id = ? AND user_id = ?;
+
+-- name: DeleteWonderNetMember :execrows
+DELETE FROM wonder_net_members WHERE wonder_net_id = ? AND user_id = ?;
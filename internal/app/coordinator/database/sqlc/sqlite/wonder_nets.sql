@@ -21,3 +21,85 @@ DELETE FROM wonder_nets WHERE id = ?;
 
 -- name: ListWonderNets :many
 SELECT * FROM wonder_nets ORDER BY created_at DESC;
+
+-- name: CountWonderNets :one
+SELECT COUNT(*) FROM wonder_nets;
+
+-- name: CountWonderNetsByMeshType :many
+SELECT mesh_type, COUNT(*) AS count FROM wonder_nets GROUP BY mesh_type ORDER BY mesh_type;
+
+-- name: GetDefaultWonderNetByOwner :one
+SELECT * FROM wonder_nets WHERE owner_id = ? AND is_default ORDER BY created_at LIMIT 1;
+
+-- name: SetDefaultWonderNet :exec
+UPDATE wonder_nets
+SET is_default = (id = ?), updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = ?;
+
+-- name: ListWonderNetsPageDesc :many
+SELECT * FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF(?, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF(?, ''), mesh_type)
+  AND created_at >= datetime(?)
+  AND created_at < datetime(?)
+  AND (created_at, id) < (datetime(?), ?)
+ORDER BY created_at DESC, id DESC
+LIMIT ?;
+
+-- name: ListWonderNetsPageAsc :many
+SELECT * FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF(?, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF(?, ''), mesh_type)
+  AND created_at >= datetime(?)
+  AND created_at < datetime(?)
+  AND (created_at, id) > (datetime(?), ?)
+ORDER BY created_at ASC, id ASC
+LIMIT ?;
+
+-- name: UpdateWonderNetNodeNameTemplate :exec
+UPDATE wonder_nets
+SET node_name_template = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListWonderNetsWithNodeNameTemplate :many
+SELECT * FROM wonder_nets WHERE node_name_template <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetACLRules :exec
+UPDATE wonder_nets
+SET acl_rules = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListWonderNetsWithACLRules :many
+SELECT * FROM wonder_nets WHERE acl_rules <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetPublicURL :exec
+UPDATE wonder_nets
+SET public_url = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: GetWonderNetByPublicURL :one
+SELECT * FROM wonder_nets WHERE public_url = ?;
+
+-- name: ListWonderNetsWithPublicURL :many
+SELECT * FROM wonder_nets WHERE public_url <> '' ORDER BY created_at;
+
+-- name: UpdateWonderNetRequireNodeApproval :exec
+UPDATE wonder_nets
+SET require_node_approval = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListWonderNetsRequiringNodeApproval :many
+SELECT * FROM wonder_nets WHERE require_node_approval ORDER BY created_at;
+
+-- name: UpdateWonderNetNodeExpiryDays :exec
+UPDATE wonder_nets
+SET node_expiry_days = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateWonderNetJoinTokenTTL :exec
+UPDATE wonder_nets
+SET join_token_default_ttl_seconds = ?, join_token_max_ttl_seconds = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: ListWonderNetsWithNodeExpiry :many
+SELECT * FROM wonder_nets WHERE node_expiry_days > 0 ORDER BY created_at;
@@ -7,8 +7,55 @@ package sqlcsqlite
 
 import (
 	"context"
+	"time"
 )
 
+const countWonderNets = `-- name: CountWonderNets :one
+SELECT COUNT(*) FROM wonder_nets
+`
+
+func (q *Queries) CountWonderNets(ctx context.Context) (int64, error) {
+	row := q.db.QueryRowContext(ctx, countWonderNets)
+	var count int64
+	err := row.Scan(&count)
+	return count, err
+}
+
+const countWonderNetsByMeshType = `-- name: CountWonderNetsByMeshType :many
+SELECT mesh_type, COUNT(*) AS count FROM wonder_nets GROUP BY mesh_type ORDER BY mesh_type
+`
+
+type CountWonderNetsByMeshTypeRow struct {
+	MeshType string `json:"mesh_type"`
+	Count    int64  `json:"count"`
+}
+
+func (q *Queries) CountWonderNetsByMeshType(ctx context.Context) ([]CountWonderNetsByMeshTypeRow, error) {
+	rows, err := q.db.QueryContext(ctx, countWonderNetsByMeshType)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []CountWonderNetsByMeshTypeRow{}
+	for rows.Next() {
+		var i CountWonderNetsByMeshTypeRow
+		if err := rows.Scan(
+			&i.MeshType,
+			&i.Count,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
 const createWonderNet = `-- name: CreateWonderNet :exec
 INSERT INTO wonder_nets (id, owner_id, headscale_user, display_name, mesh_type, created_at, updated_at)
 VALUES (?, ?, ?, ?, ?, CURRENT_TIMESTAMP, CURRENT_TIMESTAMP)
@@ -42,8 +89,35 @@ func (q *Queries) DeleteWonderNet(ctx context.Context, id string) error {
 	return err
 }
 
+const getDefaultWonderNetByOwner = `-- name: GetDefaultWonderNetByOwner :one
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE owner_id = ? AND is_default ORDER BY created_at LIMIT 1
+`
+
+func (q *Queries) GetDefaultWonderNetByOwner(ctx context.Context, ownerID string) (WonderNet, error) {
+	row := q.db.QueryRowContext(ctx, getDefaultWonderNetByOwner, ownerID)
+	var i WonderNet
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.HeadscaleUser,
+		&i.DisplayName,
+		&i.MeshType,
+		&i.IsDefault,
+		&i.NodeNameTemplate,
+		&i.AclRules,
+		&i.PublicUrl,
+		&i.RequireNodeApproval,
+		&i.NodeExpiryDays,
+		&i.JoinTokenDefaultTtlSeconds,
+		&i.JoinTokenMaxTtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
 const getWonderNet = `-- name: GetWonderNet :one
-SELECT id, owner_id, headscale_user, display_name, mesh_type, created_at, updated_at FROM wonder_nets WHERE id = ?
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE id = ?
 `
 
 func (q *Queries) GetWonderNet(ctx context.Context, id string) (WonderNet, error) {
@@ -55,6 +129,14 @@ func (q *Queries) GetWonderNet(ctx context.Context, id string) (WonderNet, error
 		&i.HeadscaleUser,
 		&i.DisplayName,
 		&i.MeshType,
+		&i.IsDefault,
+		&i.NodeNameTemplate,
+		&i.AclRules,
+		&i.PublicUrl,
+		&i.RequireNodeApproval,
+		&i.NodeExpiryDays,
+		&i.JoinTokenDefaultTtlSeconds,
+		&i.JoinTokenMaxTtlSeconds,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -62,7 +144,7 @@ func (q *Queries) GetWonderNet(ctx context.Context, id string) (WonderNet, error
 }
 
 const getWonderNetByHeadscaleUser = `-- name: GetWonderNetByHeadscaleUser :one
-SELECT id, owner_id, headscale_user, display_name, mesh_type, created_at, updated_at FROM wonder_nets WHERE headscale_user = ?
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE headscale_user = ?
 `
 
 func (q *Queries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser string) (WonderNet, error) {
@@ -74,6 +156,41 @@ func (q *Queries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser
 		&i.HeadscaleUser,
 		&i.DisplayName,
 		&i.MeshType,
+		&i.IsDefault,
+		&i.NodeNameTemplate,
+		&i.AclRules,
+		&i.PublicUrl,
+		&i.RequireNodeApproval,
+		&i.NodeExpiryDays,
+		&i.JoinTokenDefaultTtlSeconds,
+		&i.JoinTokenMaxTtlSeconds,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+	)
+	return i, err
+}
+
+const getWonderNetByPublicURL = `-- name: GetWonderNetByPublicURL :one
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE public_url = ?
+`
+
+func (q *Queries) GetWonderNetByPublicURL(ctx context.Context, publicUrl string) (WonderNet, error) {
+	row := q.db.QueryRowContext(ctx, getWonderNetByPublicURL, publicUrl)
+	var i WonderNet
+	err := row.Scan(
+		&i.ID,
+		&i.OwnerID,
+		&i.HeadscaleUser,
+		&i.DisplayName,
+		&i.MeshType,
+		&i.IsDefault,
+		&i.NodeNameTemplate,
+		&i.AclRules,
+		&i.PublicUrl,
+		&i.RequireNodeApproval,
+		&i.NodeExpiryDays,
+		&i.JoinTokenDefaultTtlSeconds,
+		&i.JoinTokenMaxTtlSeconds,
 		&i.CreatedAt,
 		&i.UpdatedAt,
 	)
@@ -81,7 +198,7 @@ func (q *Queries) GetWonderNetByHeadscaleUser(ctx context.Context, headscaleUser
 }
 
 const listWonderNets = `-- name: ListWonderNets :many
-SELECT id, owner_id, headscale_user, display_name, mesh_type, created_at, updated_at FROM wonder_nets ORDER BY created_at DESC
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets ORDER BY created_at DESC
 `
 
 func (q *Queries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
@@ -99,6 +216,14 @@ func (q *Queries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
 			&i.HeadscaleUser,
 			&i.DisplayName,
 			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -116,7 +241,7 @@ func (q *Queries) ListWonderNets(ctx context.Context) ([]WonderNet, error) {
 }
 
 const listWonderNetsByOwner = `-- name: ListWonderNetsByOwner :many
-SELECT id, owner_id, headscale_user, display_name, mesh_type, created_at, updated_at FROM wonder_nets WHERE owner_id = ? ORDER BY created_at DESC
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE owner_id = ? ORDER BY created_at DESC
 `
 
 func (q *Queries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]WonderNet, error) {
@@ -134,6 +259,365 @@ func (q *Queries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]
 			&i.HeadscaleUser,
 			&i.DisplayName,
 			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsPageAsc = `-- name: ListWonderNetsPageAsc :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF(?, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF(?, ''), mesh_type)
+  AND created_at >= datetime(?)
+  AND created_at < datetime(?)
+  AND (created_at, id) > (datetime(?), ?)
+ORDER BY created_at ASC, id ASC
+LIMIT ?
+`
+
+type ListWonderNetsPageAscParams struct {
+	OwnerID         string    `json:"owner_id"`
+	MeshType        string    `json:"mesh_type"`
+	CreatedAfter    time.Time `json:"created_after"`
+	CreatedBefore   time.Time `json:"created_before"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        string    `json:"cursor_id"`
+	Limit           int64     `json:"limit"`
+}
+
+func (q *Queries) ListWonderNetsPageAsc(ctx context.Context, arg ListWonderNetsPageAscParams) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsPageAsc,
+		arg.OwnerID,
+		arg.MeshType,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsPageDesc = `-- name: ListWonderNetsPageDesc :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets
+WHERE owner_id = COALESCE(NULLIF(?, ''), owner_id)
+  AND mesh_type = COALESCE(NULLIF(?, ''), mesh_type)
+  AND created_at >= datetime(?)
+  AND created_at < datetime(?)
+  AND (created_at, id) < (datetime(?), ?)
+ORDER BY created_at DESC, id DESC
+LIMIT ?
+`
+
+type ListWonderNetsPageDescParams struct {
+	OwnerID         string    `json:"owner_id"`
+	MeshType        string    `json:"mesh_type"`
+	CreatedAfter    time.Time `json:"created_after"`
+	CreatedBefore   time.Time `json:"created_before"`
+	CursorCreatedAt time.Time `json:"cursor_created_at"`
+	CursorID        string    `json:"cursor_id"`
+	Limit           int64     `json:"limit"`
+}
+
+func (q *Queries) ListWonderNetsPageDesc(ctx context.Context, arg ListWonderNetsPageDescParams) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsPageDesc,
+		arg.OwnerID,
+		arg.MeshType,
+		arg.CreatedAfter,
+		arg.CreatedBefore,
+		arg.CursorCreatedAt,
+		arg.CursorID,
+		arg.Limit,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsRequiringNodeApproval = `-- name: ListWonderNetsRequiringNodeApproval :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE require_node_approval ORDER BY created_at
+`
+
+func (q *Queries) ListWonderNetsRequiringNodeApproval(ctx context.Context) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsRequiringNodeApproval)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsWithACLRules = `-- name: ListWonderNetsWithACLRules :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE acl_rules <> '' ORDER BY created_at
+`
+
+func (q *Queries) ListWonderNetsWithACLRules(ctx context.Context) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsWithACLRules)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsWithNodeExpiry = `-- name: ListWonderNetsWithNodeExpiry :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE node_expiry_days > 0 ORDER BY created_at
+`
+
+func (q *Queries) ListWonderNetsWithNodeExpiry(ctx context.Context) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsWithNodeExpiry)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsWithNodeNameTemplate = `-- name: ListWonderNetsWithNodeNameTemplate :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE node_name_template <> '' ORDER BY created_at
+`
+
+func (q *Queries) ListWonderNetsWithNodeNameTemplate(ctx context.Context) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsWithNodeNameTemplate)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
+			&i.CreatedAt,
+			&i.UpdatedAt,
+		); err != nil {
+			return nil, err
+		}
+		items = append(items, i)
+	}
+	if err := rows.Close(); err != nil {
+		return nil, err
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+const listWonderNetsWithPublicURL = `-- name: ListWonderNetsWithPublicURL :many
+SELECT id, owner_id, headscale_user, display_name, mesh_type, is_default, node_name_template, acl_rules, public_url, require_node_approval, node_expiry_days, join_token_default_ttl_seconds, join_token_max_ttl_seconds, created_at, updated_at FROM wonder_nets WHERE public_url <> '' ORDER BY created_at
+`
+
+func (q *Queries) ListWonderNetsWithPublicURL(ctx context.Context) ([]WonderNet, error) {
+	rows, err := q.db.QueryContext(ctx, listWonderNetsWithPublicURL)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	items := []WonderNet{}
+	for rows.Next() {
+		var i WonderNet
+		if err := rows.Scan(
+			&i.ID,
+			&i.OwnerID,
+			&i.HeadscaleUser,
+			&i.DisplayName,
+			&i.MeshType,
+			&i.IsDefault,
+			&i.NodeNameTemplate,
+			&i.AclRules,
+			&i.PublicUrl,
+			&i.RequireNodeApproval,
+			&i.NodeExpiryDays,
+			&i.JoinTokenDefaultTtlSeconds,
+			&i.JoinTokenMaxTtlSeconds,
 			&i.CreatedAt,
 			&i.UpdatedAt,
 		); err != nil {
@@ -150,6 +634,22 @@ func (q *Queries) ListWonderNetsByOwner(ctx context.Context, ownerID string) ([]
 	return items, nil
 }
 
+const setDefaultWonderNet = `-- name: SetDefaultWonderNet :exec
+UPDATE wonder_nets
+SET is_default = (id = ?), updated_at = CURRENT_TIMESTAMP
+WHERE owner_id = ?
+`
+
+type SetDefaultWonderNetParams struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"owner_id"`
+}
+
+func (q *Queries) SetDefaultWonderNet(ctx context.Context, arg SetDefaultWonderNetParams) error {
+	_, err := q.db.ExecContext(ctx, setDefaultWonderNet, arg.ID, arg.OwnerID)
+	return err
+}
+
 const updateWonderNet = `-- name: UpdateWonderNet :exec
 UPDATE wonder_nets
 SET display_name = ?, updated_at = CURRENT_TIMESTAMP
@@ -165,3 +665,100 @@ func (q *Queries) UpdateWonderNet(ctx context.Context, arg UpdateWonderNetParams
 	_, err := q.db.ExecContext(ctx, updateWonderNet, arg.DisplayName, arg.ID)
 	return err
 }
+
+const updateWonderNetACLRules = `-- name: UpdateWonderNetACLRules :exec
+UPDATE wonder_nets
+SET acl_rules = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetACLRulesParams struct {
+	AclRules string `json:"acl_rules"`
+	ID       string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetACLRules(ctx context.Context, arg UpdateWonderNetACLRulesParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetACLRules, arg.AclRules, arg.ID)
+	return err
+}
+
+const updateWonderNetJoinTokenTTL = `-- name: UpdateWonderNetJoinTokenTTL :exec
+UPDATE wonder_nets
+SET join_token_default_ttl_seconds = ?, join_token_max_ttl_seconds = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetJoinTokenTTLParams struct {
+	JoinTokenDefaultTtlSeconds int64  `json:"join_token_default_ttl_seconds"`
+	JoinTokenMaxTtlSeconds     int64  `json:"join_token_max_ttl_seconds"`
+	ID                         string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetJoinTokenTTL(ctx context.Context, arg UpdateWonderNetJoinTokenTTLParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetJoinTokenTTL, arg.JoinTokenDefaultTtlSeconds, arg.JoinTokenMaxTtlSeconds, arg.ID)
+	return err
+}
+
+const updateWonderNetNodeExpiryDays = `-- name: UpdateWonderNetNodeExpiryDays :exec
+UPDATE wonder_nets
+SET node_expiry_days = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetNodeExpiryDaysParams struct {
+	NodeExpiryDays int64  `json:"node_expiry_days"`
+	ID             string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetNodeExpiryDays(ctx context.Context, arg UpdateWonderNetNodeExpiryDaysParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetNodeExpiryDays, arg.NodeExpiryDays, arg.ID)
+	return err
+}
+
+const updateWonderNetNodeNameTemplate = `-- name: UpdateWonderNetNodeNameTemplate :exec
+UPDATE wonder_nets
+SET node_name_template = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetNodeNameTemplateParams struct {
+	NodeNameTemplate string `json:"node_name_template"`
+	ID               string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetNodeNameTemplate(ctx context.Context, arg UpdateWonderNetNodeNameTemplateParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetNodeNameTemplate, arg.NodeNameTemplate, arg.ID)
+	return err
+}
+
+const updateWonderNetPublicURL = `-- name: UpdateWonderNetPublicURL :exec
+UPDATE wonder_nets
+SET public_url = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetPublicURLParams struct {
+	PublicUrl string `json:"public_url"`
+	ID        string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetPublicURL(ctx context.Context, arg UpdateWonderNetPublicURLParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetPublicURL, arg.PublicUrl, arg.ID)
+	return err
+}
+
+const updateWonderNetRequireNodeApproval = `-- name: UpdateWonderNetRequireNodeApproval :exec
+UPDATE wonder_nets
+SET require_node_approval = ?, updated_at = CURRENT_TIMESTAMP
+WHERE id = ?
+`
+
+type UpdateWonderNetRequireNodeApprovalParams struct {
+	RequireNodeApproval bool   `json:"require_node_approval"`
+	ID                  string `json:"id"`
+}
+
+func (q *Queries) UpdateWonderNetRequireNodeApproval(ctx context.Context, arg UpdateWonderNetRequireNodeApprovalParams) error {
+	_, err := q.db.ExecContext(ctx, updateWonderNetRequireNodeApproval, arg.RequireNodeApproval, arg.ID)
+	return err
+}
@@ -0,0 +1,20 @@
+-- name: CreateWorkerToken :exec
+INSERT INTO worker_tokens (id, wonder_net_id, auth_key_id, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetWorkerToken :one
+SELECT * FROM worker_tokens WHERE id = ?;
+
+-- name: TouchWorkerToken :exec
+UPDATE worker_tokens
+SET node_id = ?, expires_at = ?, last_used_at = CURRENT_TIMESTAMP
+WHERE id = ?;
+
+-- name: UpdateWorkerTokenAuthKey :exec
+UPDATE worker_tokens SET auth_key_id = ? WHERE id = ?;
+
+-- name: RevokeWorkerTokensByNode :exec
+UPDATE worker_tokens
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE wonder_net_id = ? AND revoked_at IS NULL
+  AND (node_id = ? OR (auth_key_id != '' AND auth_key_id = ?));
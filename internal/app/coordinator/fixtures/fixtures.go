@@ -0,0 +1,82 @@
+// Package fixtures populates a development coordinator with synthetic
+// wonder nets and nodes, so the web UI, pagination, metrics, and the admin
+// API can be exercised at a realistic size without running hundreds of
+// workers.
+//
+// Fixture wonder nets are stored in the database like real ones, under IDs
+// and Headscale users starting with Prefix. Their nodes are never created in
+// Headscale: Mesh serves them from memory and passes every other realm
+// through to the real mesh backend.
+package fixtures
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+// Prefix starts the ID and Headscale user of every fixture wonder net, and
+// the ID of every fixture owner.
+const Prefix = "fixture-"
+
+// wonderNetsPerOwner is how many fixture wonder nets each fixture owner has.
+const wonderNetsPerOwner = 3
+
+// WonderNetID returns the ID, which is also the Headscale user, of the i-th
+// fixture wonder net, counting from zero.
+func WonderNetID(i int) string {
+	return fmt.Sprintf("%s%04d", Prefix, i+1)
+}
+
+// OwnerID returns the owner of the i-th fixture wonder net.
+func OwnerID(i int) string {
+	return fmt.Sprintf("%sowner-%03d", Prefix, i/wonderNetsPerOwner+1)
+}
+
+// IsFixtureRealm reports whether a realm belongs to a fixture wonder net.
+func IsFixtureRealm(realm string) bool {
+	return strings.HasPrefix(realm, Prefix)
+}
+
+// Seed creates the first count fixture wonder nets that do not exist yet.
+// It is idempotent, so it can run on every start. The first wonder net of
+// each fixture owner is made the owner's default.
+func Seed(ctx context.Context, wonderNets *repository.WonderNetRepository, count int) (int, error) {
+	created := 0
+	for i := range count {
+		id := WonderNetID(i)
+		existing, err := wonderNets.Get(ctx, id)
+		if err != nil {
+			return created, fmt.Errorf("get fixture wonder net %s: %w", id, err)
+		}
+		if existing != nil {
+			continue
+		}
+
+		ownerID := OwnerID(i)
+		if err := wonderNets.Create(ctx, &repository.WonderNet{
+			ID:            id,
+			OwnerID:       ownerID,
+			HeadscaleUser: id,
+			DisplayName:   fmt.Sprintf("%s %s", fixtureTeams[i%len(fixtureTeams)], fixtureStages[(i/len(fixtureTeams))%len(fixtureStages)]),
+			MeshType:      string(meshbackend.MeshTypeTailscale),
+		}); err != nil {
+			return created, fmt.Errorf("create fixture wonder net %s: %w", id, err)
+		}
+		if i%wonderNetsPerOwner == 0 {
+			if err := wonderNets.SetDefault(ctx, ownerID, id); err != nil {
+				return created, fmt.Errorf("set default fixture wonder net %s: %w", id, err)
+			}
+		}
+		created++
+	}
+	return created, nil
+}
+
+var (
+	fixtureTeams  = []string{"acme", "globex", "initech", "umbrella", "hooli", "stark", "wayne", "tyrell", "cyberdyne", "aperture", "soylent"}
+	fixtureStages = []string{"production", "staging", "dev", "lab", "edge"}
+)
@@ -0,0 +1,279 @@
+package fixtures
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+// nodeIDBase is the first fixture node ID. It is far above the IDs Headscale
+// hands out, so fixture and real nodes can be told apart by ID alone.
+const nodeIDBase = 1 << 40
+
+// ErrFixtureJoin is returned when join credentials are requested for a
+// fixture wonder net, which has no Headscale user to join.
+var ErrFixtureJoin = errors.New("fixture wonder nets cannot be joined")
+
+var (
+	hostRoles = []string{"web", "api", "db", "cache", "worker", "build", "gpu", "edge", "bastion", "laptop"}
+	exitRoute = []string{"0.0.0.0/0", "::/0"}
+)
+
+// Mesh is a mesh backend that serves the nodes of fixture wonder nets from
+// memory and delegates everything else to the wrapped backend. Fixture
+// nodes are generated deterministically, so every start shows the same
+// inventory; deleting, expiring, renaming, tagging, and approving routes
+// change the in-memory copy until the coordinator restarts.
+type Mesh struct {
+	meshbackend.MeshBackend
+
+	mu      sync.RWMutex
+	nodes   map[string]*meshbackend.Node
+	byRealm map[string][]string
+}
+
+// NewMesh wraps backend with nodesPerWonderNet generated nodes for each of
+// the first wonderNets fixture wonder nets.
+func NewMesh(backend meshbackend.MeshBackend, wonderNets, nodesPerWonderNet int) *Mesh {
+	m := &Mesh{
+		MeshBackend: backend,
+		nodes:       make(map[string]*meshbackend.Node, wonderNets*nodesPerWonderNet),
+		byRealm:     make(map[string][]string, wonderNets),
+	}
+
+	now := time.Now().UTC()
+	r := rand.New(rand.NewPCG(1, 2))
+	for i := range wonderNets {
+		realm := WonderNetID(i)
+		for j := range nodesPerWonderNet {
+			n := i*nodesPerWonderNet + j
+			node := generateNode(r, now, realm, n, j)
+			m.nodes[node.ID] = node
+			m.byRealm[realm] = append(m.byRealm[realm], node.ID)
+		}
+	}
+	return m
+}
+
+// generateNode returns the n-th fixture node overall, which is the j-th node
+// of realm. About 70% of the nodes are online; the rest were last seen
+// within the past 30 days. Some nodes advertise a subnet route or act as an
+// exit node, with about half of those routes approved.
+func generateNode(r *rand.Rand, now time.Time, realm string, n, j int) *meshbackend.Node {
+	hostname := fmt.Sprintf("%s-%02d", hostRoles[r.IntN(len(hostRoles))], j+1)
+	// Count addresses from 100.64.0.1 so that every node gets its own.
+	k := n + 1
+	node := &meshbackend.Node{
+		ID:       strconv.FormatUint(nodeIDBase+uint64(n), 10),
+		Name:     hostname,
+		Hostname: hostname,
+		Addresses: []string{
+			fmt.Sprintf("100.%d.%d.%d", 64+(k>>16), (k>>8)&0xff, k&0xff),
+			fmt.Sprintf("fd7a:115c:a1e0::%x", k),
+		},
+		Online: r.Float64() < 0.7,
+		Realm:  realm,
+	}
+
+	lastSeen := now
+	if !node.Online {
+		lastSeen = now.Add(-time.Duration(r.Int64N(int64(30 * 24 * time.Hour))))
+	}
+	node.LastSeen = &lastSeen
+	createdAt := lastSeen.Add(-time.Duration(r.Int64N(int64(365 * 24 * time.Hour))))
+	node.CreatedAt = &createdAt
+
+	switch p := r.Float64(); {
+	case p < 0.03:
+		node.AdvertisedRoutes = slices.Clone(exitRoute)
+	case p < 0.13:
+		node.AdvertisedRoutes = []string{fmt.Sprintf("10.%d.%d.0/24", (n>>8)&0xff, n&0xff)}
+	}
+	if len(node.AdvertisedRoutes) > 0 && r.IntN(2) == 0 {
+		node.ApprovedRoutes = slices.Clone(node.AdvertisedRoutes)
+		if node.Online {
+			node.ServingRoutes = slices.Clone(node.AdvertisedRoutes)
+		}
+	}
+	return node
+}
+
+// CountNodes returns the number of online and offline fixture nodes.
+func (m *Mesh) CountNodes() (online, offline int) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, node := range m.nodes {
+		if node.Online {
+			online++
+		} else {
+			offline++
+		}
+	}
+	return online, offline
+}
+
+// CreateRealm does nothing for fixture realms, which only exist in the
+// coordinator's database.
+func (m *Mesh) CreateRealm(ctx context.Context, name string) error {
+	if IsFixtureRealm(name) {
+		return nil
+	}
+	return m.MeshBackend.CreateRealm(ctx, name)
+}
+
+// GetRealm reports fixture realms as existing.
+func (m *Mesh) GetRealm(ctx context.Context, name string) (bool, error) {
+	if IsFixtureRealm(name) {
+		return true, nil
+	}
+	return m.MeshBackend.GetRealm(ctx, name)
+}
+
+// CreateJoinCredentials refuses fixture realms.
+func (m *Mesh) CreateJoinCredentials(ctx context.Context, realmName string, opts meshbackend.JoinOptions) (map[string]any, error) {
+	if IsFixtureRealm(realmName) {
+		return nil, ErrFixtureJoin
+	}
+	return m.MeshBackend.CreateJoinCredentials(ctx, realmName, opts)
+}
+
+// ListNodes returns all nodes in a realm.
+func (m *Mesh) ListNodes(ctx context.Context, realmName string) ([]*meshbackend.Node, error) {
+	if !IsFixtureRealm(realmName) {
+		return m.MeshBackend.ListNodes(ctx, realmName)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	nodes := make([]*meshbackend.Node, 0, len(m.byRealm[realmName]))
+	for _, id := range m.byRealm[realmName] {
+		if node, ok := m.nodes[id]; ok {
+			nodes = append(nodes, cloneNode(node))
+		}
+	}
+	return nodes, nil
+}
+
+// GetNode retrieves a single node by its ID.
+func (m *Mesh) GetNode(ctx context.Context, nodeID string) (*meshbackend.Node, error) {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.GetNode(ctx, nodeID)
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return nil, fmt.Errorf("get node: fixture node %s not found", nodeID)
+	}
+	return cloneNode(node), nil
+}
+
+// DeleteNode removes a node from the mesh network.
+func (m *Mesh) DeleteNode(ctx context.Context, nodeID string) error {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.DeleteNode(ctx, nodeID)
+	}
+	return m.update(nodeID, "delete node", func(node *meshbackend.Node) {
+		delete(m.nodes, nodeID)
+	})
+}
+
+// ExpireNode takes a fixture node offline.
+func (m *Mesh) ExpireNode(ctx context.Context, nodeID string) error {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.ExpireNode(ctx, nodeID)
+	}
+	return m.update(nodeID, "expire node", func(node *meshbackend.Node) {
+		node.Online = false
+		node.ServingRoutes = nil
+	})
+}
+
+// RenameNode changes the name a node is known by in the mesh.
+func (m *Mesh) RenameNode(ctx context.Context, nodeID, name string) error {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.RenameNode(ctx, nodeID, name)
+	}
+	return m.update(nodeID, "rename node", func(node *meshbackend.Node) {
+		node.Name = name
+	})
+}
+
+// SetApprovedRoutes replaces the set of advertised routes the node is
+// allowed to serve. An online fixture node serves the approved routes it
+// advertises right away.
+func (m *Mesh) SetApprovedRoutes(ctx context.Context, nodeID string, routes []string) error {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.SetApprovedRoutes(ctx, nodeID, routes)
+	}
+	return m.update(nodeID, "set approved routes", func(node *meshbackend.Node) {
+		node.ApprovedRoutes = slices.Clone(routes)
+		node.ServingRoutes = nil
+		if !node.Online {
+			return
+		}
+		for _, route := range routes {
+			if slices.Contains(node.AdvertisedRoutes, route) {
+				node.ServingRoutes = append(node.ServingRoutes, route)
+			}
+		}
+	})
+}
+
+// SetTags replaces the tags of a fixture node.
+func (m *Mesh) SetTags(ctx context.Context, nodeID string, tags []string) error {
+	if !isFixtureNode(nodeID) {
+		return m.MeshBackend.SetTags(ctx, nodeID, tags)
+	}
+	return m.update(nodeID, "set tags", func(node *meshbackend.Node) {
+		node.Tags = slices.Clone(tags)
+	})
+}
+
+// update applies fn to a fixture node under the write lock.
+func (m *Mesh) update(nodeID, action string, fn func(node *meshbackend.Node)) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	node, ok := m.nodes[nodeID]
+	if !ok {
+		return fmt.Errorf("%s: fixture node %s not found", action, nodeID)
+	}
+	fn(node)
+	return nil
+}
+
+// isFixtureNode reports whether a node ID is in the fixture range.
+func isFixtureNode(nodeID string) bool {
+	id, err := strconv.ParseUint(nodeID, 10, 64)
+	return err == nil && id >= nodeIDBase
+}
+
+func cloneNode(node *meshbackend.Node) *meshbackend.Node {
+	c := *node
+	c.Addresses = slices.Clone(node.Addresses)
+	c.AdvertisedRoutes = slices.Clone(node.AdvertisedRoutes)
+	c.ApprovedRoutes = slices.Clone(node.ApprovedRoutes)
+	c.ServingRoutes = slices.Clone(node.ServingRoutes)
+	c.Tags = slices.Clone(node.Tags)
+	if node.LastSeen != nil {
+		t := *node.LastSeen
+		c.LastSeen = &t
+	}
+	if node.CreatedAt != nil {
+		t := *node.CreatedAt
+		c.CreatedAt = &t
+	}
+	return &c
+}
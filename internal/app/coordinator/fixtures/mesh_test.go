@@ -0,0 +1,101 @@
+package fixtures
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func TestNewMeshIsDeterministic(t *testing.T) {
+	ctx := context.Background()
+	a := NewMesh(nil, 5, 4)
+	b := NewMesh(nil, 5, 4)
+
+	for i := range 5 {
+		realm := WonderNetID(i)
+		nodesA, err := a.ListNodes(ctx, realm)
+		if err != nil {
+			t.Fatalf("ListNodes(%s) error = %v", realm, err)
+		}
+		nodesB, _ := b.ListNodes(ctx, realm)
+		if len(nodesA) != 4 {
+			t.Fatalf("ListNodes(%s) returned %d nodes, want 4", realm, len(nodesA))
+		}
+		for j := range nodesA {
+			// LastSeen and CreatedAt are relative to the time the mesh was
+			// created.
+			nodesA[j].LastSeen, nodesB[j].LastSeen = nil, nil
+			nodesA[j].CreatedAt, nodesB[j].CreatedAt = nil, nil
+			if !reflect.DeepEqual(nodesA[j], nodesB[j]) {
+				t.Errorf("node %d of %s differs between meshes: %+v != %+v", j, realm, nodesA[j], nodesB[j])
+			}
+			if nodesA[j].Realm != realm {
+				t.Errorf("node %s realm = %q, want %q", nodesA[j].ID, nodesA[j].Realm, realm)
+			}
+		}
+	}
+
+	online, offline := a.CountNodes()
+	if online+offline != 20 {
+		t.Errorf("CountNodes() = %d online, %d offline, want 20 in total", online, offline)
+	}
+}
+
+func TestNewMeshAssignsUniqueAddresses(t *testing.T) {
+	m := NewMesh(nil, 10, 100)
+
+	seen := make(map[string]string)
+	for id, node := range m.nodes {
+		for _, addr := range node.Addresses {
+			if other, ok := seen[addr]; ok {
+				t.Fatalf("nodes %s and %s share address %s", other, id, addr)
+			}
+			seen[addr] = id
+		}
+	}
+}
+
+func TestMeshUpdatesFixtureNodes(t *testing.T) {
+	ctx := context.Background()
+	m := NewMesh(nil, 1, 2)
+
+	nodes, _ := m.ListNodes(ctx, WonderNetID(0))
+	id := nodes[0].ID
+
+	if err := m.RenameNode(ctx, id, "renamed"); err != nil {
+		t.Fatalf("RenameNode() error = %v", err)
+	}
+	if err := m.ExpireNode(ctx, id); err != nil {
+		t.Fatalf("ExpireNode() error = %v", err)
+	}
+	node, err := m.GetNode(ctx, id)
+	if err != nil {
+		t.Fatalf("GetNode() error = %v", err)
+	}
+	if node.Name != "renamed" || node.Online {
+		t.Errorf("GetNode() = name %q online %v, want renamed and offline", node.Name, node.Online)
+	}
+
+	if err := m.DeleteNode(ctx, id); err != nil {
+		t.Fatalf("DeleteNode() error = %v", err)
+	}
+	if _, err := m.GetNode(ctx, id); err == nil {
+		t.Error("GetNode() after DeleteNode() succeeded, want error")
+	}
+	if nodes, _ := m.ListNodes(ctx, WonderNetID(0)); len(nodes) != 1 {
+		t.Errorf("ListNodes() after DeleteNode() returned %d nodes, want 1", len(nodes))
+	}
+}
+
+func TestIsFixtureNode(t *testing.T) {
+	for id, want := range map[string]bool{
+		"1":             false,
+		"4096":          false,
+		"1099511627776": true,
+		"not-a-number":  false,
+	} {
+		if got := isFixtureNode(id); got != want {
+			t.Errorf("isFixtureNode(%q) = %v, want %v", id, got, want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
+// LogLevelResponse is the current log level of the coordinator.
+type LogLevelResponse struct {
+	Level string `json:"level"`
+}
+
+// SetLogLevelRequest changes the log level of the coordinator.
+type SetLogLevelRequest struct {
+	Level string `json:"level"`
+}
+
+// handleGetLogLevel returns the current log level.
+func (s *Server) handleGetLogLevel(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(LogLevelResponse{Level: logging.LevelName(logging.Level())})
+}
+
+// handleSetLogLevel changes the log level until the coordinator restarts or
+// a config reload changes log_level.
+func (s *Server) handleSetLogLevel(w http.ResponseWriter, r *http.Request) {
+	var req SetLogLevelRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "invalid request body", http.StatusBadRequest)
+		return
+	}
+	if req.Level == "" {
+		http.Error(w, "level is required", http.StatusBadRequest)
+		return
+	}
+	level, err := logging.ParseLevel(req.Level)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	previous := logging.Level()
+	logging.SetLevel(level)
+	slog.Warn("log level changed via admin API",
+		"from", logging.LevelName(previous), "to", logging.LevelName(level))
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(LogLevelResponse{Level: logging.LevelName(level)})
+}
@@ -0,0 +1,85 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
+func TestHandleSetLogLevel(t *testing.T) {
+	defer logging.SetLevel(logging.Level())
+	logging.SetLevel(slog.LevelInfo)
+
+	s := &Server{config: testReloadConfig()}
+
+	tests := []struct {
+		name       string
+		body       string
+		wantStatus int
+		wantLevel  slog.Level
+	}{
+		{name: "debug", body: `{"level":"debug"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelDebug},
+		{name: "uppercase", body: `{"level":"WARN"}`, wantStatus: http.StatusOK, wantLevel: slog.LevelWarn},
+		{name: "unknown level", body: `{"level":"verbose"}`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelWarn},
+		{name: "missing level", body: `{}`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelWarn},
+		{name: "invalid body", body: `level=debug`, wantStatus: http.StatusBadRequest, wantLevel: slog.LevelWarn},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			req := httptest.NewRequest(http.MethodPut, "/coordinator/admin/api/v1/log-level", strings.NewReader(tt.body))
+			s.handleSetLogLevel(rec, req)
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", rec.Code, tt.wantStatus)
+			}
+			if got := logging.Level(); got != tt.wantLevel {
+				t.Errorf("level = %v, want %v", got, tt.wantLevel)
+			}
+			if rec.Code != http.StatusOK {
+				return
+			}
+
+			var response LogLevelResponse
+			if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+				t.Fatalf("decode response: %v", err)
+			}
+			if response.Level != logging.LevelName(tt.wantLevel) {
+				t.Errorf("response level = %q, want %q", response.Level, logging.LevelName(tt.wantLevel))
+			}
+		})
+	}
+}
+
+func TestReload_LogLevel(t *testing.T) {
+	defer logging.SetLevel(logging.Level())
+	logging.SetLevel(slog.LevelInfo)
+
+	s := &Server{config: testReloadConfig()}
+
+	next := testReloadConfig()
+	next.LogLevel = "debug"
+	if err := s.Reload(context.Background(), next); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := logging.Level(); got != slog.LevelDebug {
+		t.Errorf("level = %v, want debug", got)
+	}
+
+	// A reload that leaves log_level unchanged keeps a level set through
+	// the admin API.
+	logging.SetLevel(slog.LevelError)
+	if err := s.Reload(context.Background(), next); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+	if got := logging.Level(); got != slog.LevelError {
+		t.Errorf("level = %v, want error", got)
+	}
+}
@@ -0,0 +1,74 @@
+package meshproxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// handshakeHTTP authenticates an HTTP CONNECT client by the API key in its
+// Proxy-Authorization header, either as a bearer token or as the password
+// of basic credentials, and connects it to the requested node.
+func (s *Server) handshakeHTTP(ctx context.Context, br *bufio.Reader, conn net.Conn) (net.Conn, error) {
+	req, err := http.ReadRequest(br)
+	if err != nil {
+		return nil, err
+	}
+	if req.Method != http.MethodConnect {
+		writeHTTPStatus(conn, http.StatusMethodNotAllowed, "only CONNECT is supported")
+		return nil, fmt.Errorf("unsupported method %s", req.Method)
+	}
+
+	wonderNet, err := s.authorize(ctx, proxyAPIKey(req), conn.RemoteAddr())
+	if err != nil {
+		_, _ = fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nProxy-Authenticate: Basic realm=\"wonder\"\r\nContent-Length: 0\r\n\r\n",
+			http.StatusProxyAuthRequired, http.StatusText(http.StatusProxyAuthRequired))
+		return nil, err
+	}
+
+	host, port, err := net.SplitHostPort(req.Host)
+	if err != nil {
+		writeHTTPStatus(conn, http.StatusBadRequest, "target must be host:port")
+		return nil, err
+	}
+
+	target, err := s.connect(ctx, wonderNet, host, port)
+	if err != nil {
+		switch {
+		case errors.Is(err, ErrForbidden):
+			writeHTTPStatus(conn, http.StatusForbidden, err.Error())
+		case isDialError(err):
+			writeHTTPStatus(conn, http.StatusBadGateway, "dial target")
+		default:
+			writeHTTPStatus(conn, http.StatusInternalServerError, "connect target")
+		}
+		return nil, err
+	}
+	if _, err := fmt.Fprint(conn, "HTTP/1.1 200 Connection Established\r\n\r\n"); err != nil {
+		_ = target.Close()
+		return nil, err
+	}
+	return target, nil
+}
+
+// proxyAPIKey returns the API key of a CONNECT request.
+func proxyAPIKey(req *http.Request) string {
+	header := req.Header.Get("Proxy-Authorization")
+	if token, ok := strings.CutPrefix(header, "Bearer "); ok {
+		return token
+	}
+	req.Header.Set("Authorization", header)
+	if _, password, ok := req.BasicAuth(); ok {
+		return password
+	}
+	return ""
+}
+
+func writeHTTPStatus(conn net.Conn, status int, message string) {
+	_, _ = fmt.Fprintf(conn, "HTTP/1.1 %d %s\r\nContent-Type: text/plain; charset=utf-8\r\nContent-Length: %d\r\n\r\n%s\n",
+		status, http.StatusText(status), len(message)+1, message)
+}
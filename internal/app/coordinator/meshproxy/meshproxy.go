@@ -0,0 +1,287 @@
+// Package meshproxy lets deployers reach the nodes of their wonder net
+// through the coordinator, without joining the mesh themselves.
+//
+// A single listener accepts both SOCKS5 (RFC 1928, with username/password
+// authentication) and HTTP CONNECT clients. Either way the client presents
+// a wonder net API key, as the SOCKS5 password or in Proxy-Authorization,
+// and may only open TCP connections to nodes of that wonder net, addressed
+// by mesh IP or node name.
+package meshproxy
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net"
+	"net/netip"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+	"github.com/strrl/wonder-mesh-net/pkg/apikey"
+)
+
+// Endpoint identifies proxy use in API key usage statistics.
+const Endpoint = "CONNECT mesh-proxy"
+
+// handshakeTimeout bounds the time a client has to authenticate and name
+// its target.
+const handshakeTimeout = 30 * time.Second
+
+var (
+	ErrUnauthorized = errors.New("invalid api key")
+	// ErrForbidden is returned for targets that are not a node of the
+	// caller's wonder net.
+	ErrForbidden = errors.New("target is not a node of the wonder net")
+)
+
+// DialFunc opens a connection to a mesh address.
+type DialFunc func(ctx context.Context, network, addr string) (net.Conn, error)
+
+// Server is the mesh proxy.
+type Server struct {
+	authenticate func(ctx context.Context, apiKey string, clientIP netip.Addr) (*repository.WonderNet, error)
+	listNodes    func(ctx context.Context, wonderNet *repository.WonderNet) ([]*service.Node, error)
+	dial         DialFunc
+
+	mu       sync.Mutex
+	listener net.Listener
+	// conns are the open client connections, handshaking or tunneling.
+	// ctx is cancelled by Close to abort their handshakes.
+	conns  map[net.Conn]struct{}
+	ctx    context.Context
+	cancel context.CancelFunc
+	closed bool
+	wg     sync.WaitGroup
+}
+
+// NewServer creates a mesh proxy that authenticates clients with API keys
+// granting the deployer:join scope and dials nodes with dial. Clients
+// connect directly, so an API key's allowed ranges are checked against the
+// address of the connection.
+func NewServer(apiKeyService *service.APIKeyService, nodesService *service.NodesService, dial DialFunc) *Server {
+	return &Server{
+		authenticate: func(ctx context.Context, apiKey string, clientIP netip.Addr) (*repository.WonderNet, error) {
+			wonderNet, _, err := apiKeyService.ValidateAPIKey(ctx, apiKey, Endpoint, service.APIKeyScopeDeployerJoin, clientIP)
+			return wonderNet, err
+		},
+		listNodes: nodesService.ListNodes,
+		dial:      dial,
+	}
+}
+
+// UpstreamDialer returns how the coordinator reaches mesh nodes: "" or
+// "direct" dials them from the coordinator host, which must then be on the
+// mesh, and "socks5://host:port" goes through the SOCKS5 proxy of a
+// userspace tailscaled, logged in as a node of a privileged network.
+func UpstreamDialer(upstream string) (DialFunc, error) {
+	if upstream == "" || upstream == "direct" {
+		var d net.Dialer
+		return d.DialContext, nil
+	}
+
+	u, err := url.Parse(upstream)
+	if err != nil || u.Scheme != "socks5" || u.Host == "" {
+		return nil, fmt.Errorf("invalid mesh proxy upstream %q: want direct or socks5://host:port", upstream)
+	}
+	var auth *proxy.Auth
+	if u.User != nil {
+		password, _ := u.User.Password()
+		auth = &proxy.Auth{User: u.User.Username(), Password: password}
+	}
+	d, err := proxy.SOCKS5("tcp", u.Host, auth, proxy.Direct)
+	if err != nil {
+		return nil, fmt.Errorf("create socks5 dialer: %w", err)
+	}
+	return d.(proxy.ContextDialer).DialContext, nil
+}
+
+// Serve accepts proxy clients on ln until Close is called.
+func (s *Server) Serve(ln net.Listener) error {
+	s.mu.Lock()
+	if s.closed {
+		s.mu.Unlock()
+		return ln.Close()
+	}
+	s.listener = ln
+	s.mu.Unlock()
+
+	for {
+		conn, err := ln.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return nil
+			}
+			return err
+		}
+		ctx, ok := s.track(conn)
+		if !ok {
+			_ = conn.Close()
+			return nil
+		}
+		go s.handle(ctx, conn)
+	}
+}
+
+// Close stops accepting clients, closes the open tunnels, and waits for
+// their handlers to return.
+func (s *Server) Close() error {
+	s.mu.Lock()
+	s.closed = true
+	if s.cancel != nil {
+		s.cancel()
+	}
+	var err error
+	if s.listener != nil {
+		err = s.listener.Close()
+	}
+	for conn := range s.conns {
+		_ = conn.Close()
+	}
+	s.mu.Unlock()
+
+	s.wg.Wait()
+	return err
+}
+
+// track records conn as open, unless the server is closed, and returns the
+// context its handshake runs in.
+func (s *Server) track(conn net.Conn) (context.Context, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.closed {
+		return nil, false
+	}
+	if s.conns == nil {
+		s.conns = make(map[net.Conn]struct{})
+		s.ctx, s.cancel = context.WithCancel(context.Background())
+	}
+	s.conns[conn] = struct{}{}
+	s.wg.Add(1)
+	return s.ctx, true
+}
+
+// untrack forgets conn once its handler is done.
+func (s *Server) untrack(conn net.Conn) {
+	s.mu.Lock()
+	delete(s.conns, conn)
+	s.mu.Unlock()
+	s.wg.Done()
+}
+
+// handle serves one client, telling SOCKS5 from HTTP by its first byte.
+// Closing conn, as Close does, ends the tunnel and closes the target.
+func (s *Server) handle(ctx context.Context, conn net.Conn) {
+	defer s.untrack(conn)
+	defer func() { _ = conn.Close() }()
+
+	_ = conn.SetDeadline(time.Now().Add(handshakeTimeout))
+	ctx, cancel := context.WithTimeout(ctx, handshakeTimeout)
+	defer cancel()
+	br := bufio.NewReader(conn)
+	first, err := br.Peek(1)
+	if err != nil {
+		return
+	}
+
+	var target net.Conn
+	if first[0] == socks5Version {
+		target, err = s.handshakeSOCKS5(ctx, br, conn)
+	} else {
+		target, err = s.handshakeHTTP(ctx, br, conn)
+	}
+	if err != nil {
+		slog.Debug("mesh proxy handshake failed", "remote", conn.RemoteAddr().String(), "error", err)
+		return
+	}
+	defer func() { _ = target.Close() }()
+	_ = conn.SetDeadline(time.Time{})
+
+	tunnel(br, conn, target)
+}
+
+// authorize returns the wonder net of apiKey, used by the client at remote.
+func (s *Server) authorize(ctx context.Context, apiKey string, remote net.Addr) (*repository.WonderNet, error) {
+	if !apikey.IsAPIKey(apiKey) {
+		return nil, ErrUnauthorized
+	}
+	var clientIP netip.Addr
+	if addr, err := netip.ParseAddrPort(remote.String()); err == nil {
+		clientIP = addr.Addr().Unmap()
+	}
+	wonderNet, err := s.authenticate(ctx, apiKey, clientIP)
+	if err != nil || wonderNet == nil {
+		return nil, ErrUnauthorized
+	}
+	return wonderNet, nil
+}
+
+// connect dials host:port if host is a node of the wonder net.
+func (s *Server) connect(ctx context.Context, wonderNet *repository.WonderNet, host, port string) (net.Conn, error) {
+	nodes, err := s.listNodes(ctx, wonderNet)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	addr, ok := resolveNode(nodes, host)
+	if !ok {
+		return nil, ErrForbidden
+	}
+
+	conn, err := s.dial(ctx, "tcp", net.JoinHostPort(addr, port))
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("mesh proxy tunnel opened", "wonder_net_id", wonderNet.ID, "target", net.JoinHostPort(host, port))
+	return conn, nil
+}
+
+// resolveNode returns the address to dial for host, which is either one of
+// the nodes' mesh IPs or a node name. Names resolve to the node's first
+// IPv4 address.
+func resolveNode(nodes []*service.Node, host string) (string, bool) {
+	for _, node := range nodes {
+		for _, ip := range node.IPAddrs {
+			if ip == host {
+				return ip, true
+			}
+		}
+	}
+	for _, node := range nodes {
+		if !strings.EqualFold(node.Name, host) {
+			continue
+		}
+		for _, ip := range node.IPAddrs {
+			if parsed := net.ParseIP(ip); parsed != nil && parsed.To4() != nil {
+				return ip, true
+			}
+		}
+		if len(node.IPAddrs) > 0 {
+			return node.IPAddrs[0], true
+		}
+	}
+	return "", false
+}
+
+// tunnel copies between the client, whose buffered bytes are read from br,
+// and the target until either side is done. The caller closes both
+// connections, which ends the other direction.
+func tunnel(br *bufio.Reader, client, target net.Conn) {
+	done := make(chan struct{}, 2)
+	go func() {
+		_, _ = io.Copy(target, br)
+		done <- struct{}{}
+	}()
+	go func() {
+		_, _ = io.Copy(client, target)
+		done <- struct{}{}
+	}()
+	<-done
+}
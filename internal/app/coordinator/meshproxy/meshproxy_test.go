@@ -0,0 +1,211 @@
+package meshproxy
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/netip"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/proxy"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
+)
+
+const testAPIKey = "wmn_test"
+
+// startTestProxy serves a mesh proxy whose wonder net has one node,
+// worker-1 at 100.64.0.5, and returns its address. Dials to the node reach
+// an echo server; the returned channel receives every dialed address.
+func startTestProxy(t *testing.T) (*Server, string, <-chan string) {
+	t.Helper()
+
+	echo, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen echo: %v", err)
+	}
+	t.Cleanup(func() { _ = echo.Close() })
+	go func() {
+		for {
+			conn, err := echo.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				defer func() { _ = conn.Close() }()
+				_, _ = io.Copy(conn, conn)
+			}()
+		}
+	}()
+
+	dialed := make(chan string, 10)
+	s := &Server{
+		authenticate: func(ctx context.Context, apiKey string, clientIP netip.Addr) (*repository.WonderNet, error) {
+			if apiKey != testAPIKey {
+				return nil, ErrUnauthorized
+			}
+			return &repository.WonderNet{ID: "wn-1"}, nil
+		},
+		listNodes: func(ctx context.Context, wonderNet *repository.WonderNet) ([]*service.Node, error) {
+			return []*service.Node{{Name: "worker-1", IPAddrs: []string{"fd7a:115c:a1e0::5", "100.64.0.5"}}}, nil
+		},
+		dial: func(ctx context.Context, network, addr string) (net.Conn, error) {
+			dialed <- addr
+			var d net.Dialer
+			return d.DialContext(ctx, network, echo.Addr().String())
+		},
+	}
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen proxy: %v", err)
+	}
+	go func() { _ = s.Serve(ln) }()
+	t.Cleanup(func() { _ = s.Close() })
+	return s, ln.Addr().String(), dialed
+}
+
+func assertEcho(t *testing.T, conn net.Conn) {
+	t.Helper()
+	if _, err := conn.Write([]byte("ping\n")); err != nil {
+		t.Fatalf("write: %v", err)
+	}
+	line, err := bufio.NewReader(conn).ReadString('\n')
+	if err != nil {
+		t.Fatalf("read: %v", err)
+	}
+	if line != "ping\n" {
+		t.Errorf("echo = %q, want %q", line, "ping\n")
+	}
+}
+
+func TestSOCKS5(t *testing.T) {
+	_, addr, dialed := startTestProxy(t)
+
+	tests := []struct {
+		name       string
+		apiKey     string
+		target     string
+		wantDialed string
+		wantErr    bool
+	}{
+		{name: "node by IP", apiKey: testAPIKey, target: "100.64.0.5:22", wantDialed: "100.64.0.5:22"},
+		{name: "node by name", apiKey: testAPIKey, target: "worker-1:6443", wantDialed: "100.64.0.5:6443"},
+		{name: "address outside the wonder net", apiKey: testAPIKey, target: "100.64.0.9:22", wantErr: true},
+		{name: "invalid api key", apiKey: "wmn_other", target: "100.64.0.5:22", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dialer, err := proxy.SOCKS5("tcp", addr, &proxy.Auth{User: "wonder", Password: tt.apiKey}, proxy.Direct)
+			if err != nil {
+				t.Fatalf("SOCKS5() error = %v", err)
+			}
+			conn, err := dialer.Dial("tcp", tt.target)
+			if tt.wantErr {
+				if err == nil {
+					_ = conn.Close()
+					t.Fatal("Dial() error = nil, want error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Dial() error = %v", err)
+			}
+			defer func() { _ = conn.Close() }()
+
+			if got := <-dialed; got != tt.wantDialed {
+				t.Errorf("dialed %q, want %q", got, tt.wantDialed)
+			}
+			assertEcho(t, conn)
+		})
+	}
+}
+
+func TestHTTPConnect(t *testing.T) {
+	_, addr, dialed := startTestProxy(t)
+
+	tests := []struct {
+		name       string
+		target     string
+		auth       string
+		wantStatus int
+	}{
+		{name: "bearer api key", target: "100.64.0.5:22", auth: "Bearer " + testAPIKey, wantStatus: http.StatusOK},
+		{name: "basic api key", target: "worker-1:22", auth: "Basic d29uZGVyOndtbl90ZXN0", wantStatus: http.StatusOK},
+		{name: "missing credentials", target: "100.64.0.5:22", wantStatus: http.StatusProxyAuthRequired},
+		{name: "address outside the wonder net", target: "10.0.0.1:22", auth: "Bearer " + testAPIKey, wantStatus: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			conn, err := net.Dial("tcp", addr)
+			if err != nil {
+				t.Fatalf("dial proxy: %v", err)
+			}
+			defer func() { _ = conn.Close() }()
+
+			request := fmt.Sprintf("CONNECT %s HTTP/1.1\r\nHost: %s\r\n", tt.target, tt.target)
+			if tt.auth != "" {
+				request += "Proxy-Authorization: " + tt.auth + "\r\n"
+			}
+			if _, err := io.WriteString(conn, request+"\r\n"); err != nil {
+				t.Fatalf("write request: %v", err)
+			}
+
+			br := bufio.NewReader(conn)
+			resp, err := http.ReadResponse(br, &http.Request{Method: http.MethodConnect})
+			if err != nil {
+				t.Fatalf("read response: %v", err)
+			}
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", resp.StatusCode, tt.wantStatus)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+
+			<-dialed
+			if _, err := conn.Write([]byte("ping\n")); err != nil {
+				t.Fatalf("write: %v", err)
+			}
+			line, err := br.ReadString('\n')
+			if err != nil {
+				t.Fatalf("read: %v", err)
+			}
+			if !strings.HasPrefix(line, "ping") {
+				t.Errorf("echo = %q, want %q", line, "ping\n")
+			}
+		})
+	}
+}
+
+func TestCloseEndsTunnels(t *testing.T) {
+	s, addr, dialed := startTestProxy(t)
+
+	dialer, err := proxy.SOCKS5("tcp", addr, &proxy.Auth{User: "wonder", Password: testAPIKey}, proxy.Direct)
+	if err != nil {
+		t.Fatalf("SOCKS5() error = %v", err)
+	}
+	conn, err := dialer.Dial("tcp", "100.64.0.5:22")
+	if err != nil {
+		t.Fatalf("Dial() error = %v", err)
+	}
+	defer func() { _ = conn.Close() }()
+	<-dialed
+	assertEcho(t, conn)
+
+	if err := s.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	_ = conn.SetReadDeadline(time.Now().Add(5 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Errorf("Read() after Close error = %v, want %v", err, io.EOF)
+	}
+}
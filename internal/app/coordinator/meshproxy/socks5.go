@@ -0,0 +1,157 @@
+package meshproxy
+
+import (
+	"bufio"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"slices"
+	"strconv"
+)
+
+// SOCKS5 protocol constants, see RFC 1928 and RFC 1929.
+const (
+	socks5Version       = 0x05
+	socks5AuthVersion   = 0x01
+	socks5MethodUserPwd = 0x02
+	socks5NoMethod      = 0xff
+	socks5CmdConnect    = 0x01
+
+	socks5AddrIPv4   = 0x01
+	socks5AddrDomain = 0x03
+	socks5AddrIPv6   = 0x04
+
+	socks5Succeeded           = 0x00
+	socks5GeneralFailure      = 0x01
+	socks5NotAllowed          = 0x02
+	socks5HostUnreachable     = 0x04
+	socks5CmdNotSupported     = 0x07
+	socks5AddrTypeUnsupported = 0x08
+)
+
+// handshakeSOCKS5 authenticates a SOCKS5 client by the API key sent as its
+// password, and connects it to the requested node. Only the CONNECT
+// command is supported.
+func (s *Server) handshakeSOCKS5(ctx context.Context, br *bufio.Reader, conn net.Conn) (net.Conn, error) {
+	// Greeting: VER NMETHODS METHODS...
+	header := make([]byte, 2)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, err
+	}
+	methods := make([]byte, header[1])
+	if _, err := io.ReadFull(br, methods); err != nil {
+		return nil, err
+	}
+	if !slices.Contains(methods, socks5MethodUserPwd) {
+		_, _ = conn.Write([]byte{socks5Version, socks5NoMethod})
+		return nil, errors.New("client does not offer username/password authentication")
+	}
+	if _, err := conn.Write([]byte{socks5Version, socks5MethodUserPwd}); err != nil {
+		return nil, err
+	}
+
+	// Username/password: VER ULEN UNAME PLEN PASSWD. The username is ignored.
+	authVersion, err := br.ReadByte()
+	if err != nil {
+		return nil, err
+	}
+	if authVersion != socks5AuthVersion {
+		return nil, fmt.Errorf("unsupported authentication version %d", authVersion)
+	}
+	if _, err := readSOCKS5String(br); err != nil {
+		return nil, err
+	}
+	apiKey, err := readSOCKS5String(br)
+	if err != nil {
+		return nil, err
+	}
+	wonderNet, err := s.authorize(ctx, apiKey, conn.RemoteAddr())
+	if err != nil {
+		_, _ = conn.Write([]byte{socks5AuthVersion, 0x01})
+		return nil, err
+	}
+	if _, err := conn.Write([]byte{socks5AuthVersion, 0x00}); err != nil {
+		return nil, err
+	}
+
+	// Request: VER CMD RSV ATYP DST.ADDR DST.PORT
+	request := make([]byte, 4)
+	if _, err := io.ReadFull(br, request); err != nil {
+		return nil, err
+	}
+	var host string
+	switch request[3] {
+	case socks5AddrIPv4, socks5AddrIPv6:
+		size := net.IPv4len
+		if request[3] == socks5AddrIPv6 {
+			size = net.IPv6len
+		}
+		ip := make(net.IP, size)
+		if _, err := io.ReadFull(br, ip); err != nil {
+			return nil, err
+		}
+		host = ip.String()
+	case socks5AddrDomain:
+		host, err = readSOCKS5String(br)
+		if err != nil {
+			return nil, err
+		}
+	default:
+		_ = writeSOCKS5Reply(conn, socks5AddrTypeUnsupported)
+		return nil, fmt.Errorf("unsupported address type %d", request[3])
+	}
+	portBytes := make([]byte, 2)
+	if _, err := io.ReadFull(br, portBytes); err != nil {
+		return nil, err
+	}
+	port := strconv.Itoa(int(binary.BigEndian.Uint16(portBytes)))
+
+	if request[1] != socks5CmdConnect {
+		_ = writeSOCKS5Reply(conn, socks5CmdNotSupported)
+		return nil, fmt.Errorf("unsupported command %d", request[1])
+	}
+
+	target, err := s.connect(ctx, wonderNet, host, port)
+	if err != nil {
+		code := byte(socks5HostUnreachable)
+		switch {
+		case errors.Is(err, ErrForbidden):
+			code = socks5NotAllowed
+		case !isDialError(err):
+			code = socks5GeneralFailure
+		}
+		_ = writeSOCKS5Reply(conn, code)
+		return nil, err
+	}
+	if err := writeSOCKS5Reply(conn, socks5Succeeded); err != nil {
+		_ = target.Close()
+		return nil, err
+	}
+	return target, nil
+}
+
+func readSOCKS5String(br *bufio.Reader) (string, error) {
+	size, err := br.ReadByte()
+	if err != nil {
+		return "", err
+	}
+	buf := make([]byte, size)
+	if _, err := io.ReadFull(br, buf); err != nil {
+		return "", err
+	}
+	return string(buf), nil
+}
+
+// writeSOCKS5Reply sends a reply with an unspecified bound address.
+func writeSOCKS5Reply(conn net.Conn, code byte) error {
+	_, err := conn.Write([]byte{socks5Version, code, 0x00, socks5AddrIPv4, 0, 0, 0, 0, 0, 0})
+	return err
+}
+
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr)
+}
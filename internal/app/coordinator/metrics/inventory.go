@@ -0,0 +1,102 @@
+package metrics
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+const inventoryTimeout = 5 * time.Second
+
+// WonderNetCounter reports the number of wonder nets per mesh type.
+type WonderNetCounter interface {
+	CountWonderNetsByMeshType(ctx context.Context) (map[string]int, error)
+}
+
+// NodeCounter reports nodes that are not in Headscale, such as development
+// fixtures, to be counted along with the Headscale nodes.
+type NodeCounter interface {
+	CountNodes() (online, offline int)
+}
+
+var (
+	wonderNetsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "wonder_nets"),
+		"Number of wonder nets by mesh type.",
+		[]string{"mesh_type"}, nil,
+	)
+	nodesDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "nodes"),
+		"Number of mesh nodes by mesh type and online state.",
+		[]string{"mesh_type", "online"}, nil,
+	)
+)
+
+// inventoryCollector queries wonder net and node counts at scrape time.
+// Nodes are counted from Headscale and the extra node counters, and are all
+// reported under the tailscale mesh type.
+type inventoryCollector struct {
+	wonderNets      WonderNetCounter
+	headscaleClient v1.HeadscaleServiceClient
+	nodeCounters    []NodeCounter
+}
+
+// RegisterInventory registers a collector exposing wonder net and node
+// counts. Nodes reported by nodeCounters are added to the Headscale nodes.
+// With a nil headscaleClient, as with the Tailscale control plane, only
+// wonder nets are counted.
+func RegisterInventory(wonderNets WonderNetCounter, headscaleClient v1.HeadscaleServiceClient, nodeCounters ...NodeCounter) error {
+	return Registry.Register(&inventoryCollector{
+		wonderNets:      wonderNets,
+		headscaleClient: headscaleClient,
+		nodeCounters:    nodeCounters,
+	})
+}
+
+func (c *inventoryCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- wonderNetsDesc
+	ch <- nodesDesc
+}
+
+func (c *inventoryCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), inventoryTimeout)
+	defer cancel()
+
+	if counts, err := c.wonderNets.CountWonderNetsByMeshType(ctx); err == nil {
+		for meshType, count := range counts {
+			ch <- prometheus.MustNewConstMetric(wonderNetsDesc, prometheus.GaugeValue, float64(count), meshType)
+		}
+	} else {
+		slog.Warn("count wonder nets for metrics", "error", err)
+	}
+
+	if c.headscaleClient == nil {
+		return
+	}
+
+	resp, err := c.headscaleClient.ListNodes(ctx, &v1.ListNodesRequest{})
+	if err != nil {
+		slog.Warn("list nodes for metrics", "error", err)
+		return
+	}
+	var online, offline int
+	for _, node := range resp.GetNodes() {
+		if node.GetOnline() {
+			online++
+		} else {
+			offline++
+		}
+	}
+	for _, counter := range c.nodeCounters {
+		on, off := counter.CountNodes()
+		online += on
+		offline += off
+	}
+	meshType := string(meshbackend.MeshTypeTailscale)
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(online), meshType, "true")
+	ch <- prometheus.MustNewConstMetric(nodesDesc, prometheus.GaugeValue, float64(offline), meshType, "false")
+}
@@ -0,0 +1,129 @@
+// Package metrics defines the Prometheus metrics exported by the coordinator
+// at /coordinator/metrics.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+const namespace = "wonder_coordinator"
+
+// Registry holds every coordinator metric plus the Go runtime and process collectors.
+var Registry = prometheus.NewRegistry()
+
+var (
+	httpRequestDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "http_request_duration_seconds",
+		Help:      "HTTP request latency by route pattern, method and status code.",
+		Buckets:   prometheus.DefBuckets,
+	}, []string{"route", "method", "code"})
+
+	headscaleRequests = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "headscale_grpc_requests_total",
+		Help:      "Headscale gRPC calls by method and status code.",
+	}, []string{"method", "code"})
+
+	// JoinTokensIssued counts join tokens issued to users and admins.
+	JoinTokensIssued = prometheus.NewCounter(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "join_tokens_issued_total",
+		Help:      "Number of worker join tokens issued.",
+	})
+
+	// WorkerJoins counts join token exchanges by result ("success", "renewed", "invalid_token", "token_used", "ip_not_allowed", "quota_exceeded", or "error").
+	WorkerJoins = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "worker_joins_total",
+		Help:      "Worker join token exchanges by result.",
+	}, []string{"result"})
+)
+
+func init() {
+	Registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		httpRequestDuration,
+		headscaleRequests,
+		JoinTokensIssued,
+		WorkerJoins,
+	)
+}
+
+// Handler returns the HTTP handler serving the coordinator registry.
+func Handler() http.Handler {
+	return promhttp.HandlerFor(Registry, promhttp.HandlerOpts{Registry: Registry})
+}
+
+type routeKey struct{}
+
+// InstrumentHandler records request latency labelled by the ServeMux route
+// pattern that matched the request. Unmatched requests use "unmatched" so
+// arbitrary paths cannot blow up label cardinality.
+//
+// Middleware between InstrumentHandler and the ServeMux may replace the
+// request, so the pattern is reported back by RecordRoute, which must wrap
+// the ServeMux itself.
+func InstrumentHandler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		var route string
+		next.ServeHTTP(rec, r.WithContext(context.WithValue(r.Context(), routeKey{}, &route)))
+
+		if route == "" {
+			route = "unmatched"
+		}
+		httpRequestDuration.WithLabelValues(route, r.Method, strconv.Itoa(rec.status)).Observe(time.Since(start).Seconds())
+	})
+}
+
+// RecordRoute reports the route pattern the ServeMux mux matched to the
+// enclosing InstrumentHandler.
+func RecordRoute(mux http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mux.ServeHTTP(w, r)
+		if route, ok := r.Context().Value(routeKey{}).(*string); ok {
+			*route = r.Pattern
+		}
+	})
+}
+
+// UnaryClientInterceptor counts Headscale gRPC calls by method and status code.
+func UnaryClientInterceptor() grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		err := invoker(ctx, method, req, reply, cc, opts...)
+		headscaleRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+		return err
+	}
+}
+
+type statusRecorder struct {
+	http.ResponseWriter
+	status int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	r.status = code
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
@@ -0,0 +1,43 @@
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestInstrumentHandlerLabelsMatchedRoute(t *testing.T) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /coordinator/api/v1/nodes/{id}", func(w http.ResponseWriter, r *http.Request) {})
+
+	// The middleware between the two replaces the request, as withClientIP does.
+	handler := InstrumentHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		RecordRoute(mux).ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), struct{}{}, "client")))
+	}))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/coordinator/api/v1/nodes/7", nil))
+	handler.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/nowhere", nil))
+
+	families, err := Registry.Gather()
+	if err != nil {
+		t.Fatal(err)
+	}
+	routes := map[string]bool{}
+	for _, family := range families {
+		if family.GetName() != namespace+"_http_request_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			for _, label := range metric.GetLabel() {
+				if label.GetName() == "route" {
+					routes[label.GetValue()] = true
+				}
+			}
+		}
+	}
+	for _, want := range []string{"GET /coordinator/api/v1/nodes/{id}", "unmatched"} {
+		if !routes[want] {
+			t.Errorf("recorded routes = %v, want %q", routes, want)
+		}
+	}
+}
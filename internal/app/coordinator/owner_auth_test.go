@@ -0,0 +1,35 @@
+package coordinator
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/controller"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestRequireOwner(t *testing.T) {
+	s := &Server{}
+	handler := s.requireOwner(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	for role, want := range map[string]int{
+		repository.RoleOwner:    http.StatusOK,
+		repository.RoleMember:   http.StatusForbidden,
+		repository.RoleReadOnly: http.StatusForbidden,
+		"":                      http.StatusForbidden,
+	} {
+		req := httptest.NewRequest(http.MethodPut, "/coordinator/api/v1/acl", nil)
+		req = req.WithContext(context.WithValue(req.Context(), controller.ContextKeyWonderNetRole, role))
+		rec := httptest.NewRecorder()
+
+		handler.ServeHTTP(rec, req)
+
+		if rec.Code != want {
+			t.Errorf("role %q: status = %d, want %d", role, rec.Code, want)
+		}
+	}
+}
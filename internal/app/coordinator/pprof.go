@@ -0,0 +1,20 @@
+package coordinator
+
+import (
+	"net/http"
+	"net/http/pprof"
+)
+
+// registerProfilingRoutes exposes the runtime profiler under
+// /coordinator/debug/pprof/, guarded by admin authentication.
+func (s *Server) registerProfilingRoutes(mux *http.ServeMux) {
+	// pprof.Index resolves profile names relative to /debug/pprof/.
+	index := http.StripPrefix("/coordinator", http.HandlerFunc(pprof.Index))
+
+	mux.HandleFunc("GET /coordinator/debug/pprof/", s.requireAdminAuth(index.ServeHTTP))
+	mux.HandleFunc("GET /coordinator/debug/pprof/cmdline", s.requireAdminAuth(pprof.Cmdline))
+	mux.HandleFunc("GET /coordinator/debug/pprof/profile", s.requireAdminAuth(pprof.Profile))
+	mux.HandleFunc("GET /coordinator/debug/pprof/symbol", s.requireAdminAuth(pprof.Symbol))
+	mux.HandleFunc("POST /coordinator/debug/pprof/symbol", s.requireAdminAuth(pprof.Symbol))
+	mux.HandleFunc("GET /coordinator/debug/pprof/trace", s.requireAdminAuth(pprof.Trace))
+}
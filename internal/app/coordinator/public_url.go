@@ -0,0 +1,107 @@
+package coordinator
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/controller"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"golang.org/x/crypto/acme/autocert"
+)
+
+// routeByHost serves requests sent to the custom public URL of a wonder net.
+// There, only the worker, API, and Headscale endpoints of that wonder net are
+// served: login and the web UI redirect to the coordinator's public URL, the
+// admin, metrics, and profiling endpoints are hidden, and the wonder net is
+// added to the request context for the auth middlewares to enforce.
+// Requests to any other host are passed through unchanged.
+func (s *Server) routeByHost(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		publicURL := s.currentConfig().PublicURL
+		if r.Host == "" || strings.EqualFold(r.Host, urlHost(publicURL)) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		wonderNet, err := s.wonderNetService.GetWonderNetByHost(r.Context(), r.Host)
+		if err != nil {
+			slog.Error("get wonder net by host", "host", r.Host, "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if wonderNet == nil {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		path := r.URL.Path
+		switch {
+		case strings.HasPrefix(path, "/coordinator/oidc/"), path == "/ui", strings.HasPrefix(path, "/ui/"):
+			http.Redirect(w, r, strings.TrimRight(publicURL, "/")+r.URL.RequestURI(), http.StatusFound)
+			return
+		case strings.HasPrefix(path, "/coordinator/admin/"),
+			strings.HasPrefix(path, "/coordinator/debug/"),
+			path == "/coordinator/metrics":
+			http.NotFound(w, r)
+			return
+		}
+
+		ctx := context.WithValue(r.Context(), controller.ContextKeyHostWonderNet, wonderNet)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// servedOnHost reports whether the wonder net may be used through the host
+// of the request: any wonder net on the coordinator's own URL, and only the
+// mapped one on a custom public URL.
+func servedOnHost(r *http.Request, wonderNet *repository.WonderNet) bool {
+	host := controller.HostWonderNetFromContext(r)
+	return host == nil || host.ID == wonderNet.ID
+}
+
+// newAutocertManager returns an ACME certificate manager that obtains
+// certificates for the coordinator's public host and the custom public URLs
+// of wonder nets, caching them in config.TLSAutocertCacheDir.
+func (s *Server) newAutocertManager(config *Config) *autocert.Manager {
+	return &autocert.Manager{
+		Prompt: autocert.AcceptTOS,
+		Cache:  autocert.DirCache(config.TLSAutocertCacheDir),
+		Email:  config.TLSAutocertEmail,
+		HostPolicy: func(ctx context.Context, host string) error {
+			if strings.EqualFold(host, urlHostname(s.currentConfig().PublicURL)) {
+				return nil
+			}
+			wonderNet, err := s.wonderNetService.GetWonderNetByHost(ctx, host)
+			if err != nil {
+				return fmt.Errorf("look up public URL host: %w", err)
+			}
+			if wonderNet == nil || !strings.HasPrefix(wonderNet.PublicURL, "https://") {
+				return errors.New("host is not a coordinator or wonder net public URL")
+			}
+			return nil
+		},
+	}
+}
+
+// urlHost returns the host, including any port, of a URL.
+func urlHost(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Host
+}
+
+// urlHostname returns the host of a URL without the port.
+func urlHostname(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return ""
+	}
+	return u.Hostname()
+}
@@ -0,0 +1,327 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"slices"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/pkg/logging"
+)
+
+// redactedValue replaces secrets in the effective configuration.
+const redactedValue = "[redacted]"
+
+// configSetting describes one coordinator setting for reloads and for the
+// admin config endpoint.
+type configSetting struct {
+	key string
+	// reloadable settings are applied by Reload; all others are reported
+	// as pending until the coordinator restarts.
+	reloadable bool
+	value      func(c *Config) any
+	// redact, when set, returns the value shown by the config endpoint.
+	redact func(c *Config) any
+}
+
+// configSettings lists the coordinator settings in the order they are
+// reported. The public URL is not reloadable: the embedded Headscale serves
+// the same URL as server_url and has to be restarted along with it.
+var configSettings = []configSetting{
+	{key: "listen", value: func(c *Config) any { return c.Listen }},
+	{key: "public_url", value: func(c *Config) any { return c.PublicURL }},
+	{
+		key:    "jwt_secret",
+		value:  func(c *Config) any { return c.JWTSecret },
+		redact: func(c *Config) any { return redactSecret(c.JWTSecret) },
+	},
+	{key: "database_driver", value: func(c *Config) any { return c.DatabaseDriver }},
+	{
+		key:    "database_dsn",
+		value:  func(c *Config) any { return c.DatabaseDSN },
+		redact: func(c *Config) any { return redactConfiguredDSN(c.DatabaseDSN) },
+	},
+	{key: "database_max_open_conns", value: func(c *Config) any { return c.DatabaseMaxOpenConns }},
+	{key: "database_max_idle_conns", value: func(c *Config) any { return c.DatabaseMaxIdleConns }},
+	{key: "database_conn_max_lifetime", value: func(c *Config) any { return c.DatabaseConnMaxLifetime.String() }},
+	{key: "database_conn_max_idle_time", value: func(c *Config) any { return c.DatabaseConnMaxIdleTime.String() }},
+	{key: "mesh_backend", value: func(c *Config) any { return c.MeshBackend }},
+	{
+		key:    "tailscale_api_key",
+		value:  func(c *Config) any { return c.TailscaleAPIKey },
+		redact: func(c *Config) any { return redactSecret(c.TailscaleAPIKey) },
+	},
+	{key: "tailscale_tailnet", value: func(c *Config) any { return c.TailscaleTailnet }},
+	{key: "tailscale_api_url", value: func(c *Config) any { return c.TailscaleAPIURL }},
+	{key: "headscale_url", value: func(c *Config) any { return c.HeadscaleURL }},
+	{key: "headscale_unix_socket", value: func(c *Config) any { return c.HeadscaleUnixSocket }},
+	{key: "auth_mode", value: func(c *Config) any { return c.AuthMode }},
+	{key: "github_client_id", value: func(c *Config) any { return c.GitHubClientID }},
+	{
+		key:    "github_client_secret",
+		value:  func(c *Config) any { return c.GitHubClientSecret },
+		redact: func(c *Config) any { return redactSecret(c.GitHubClientSecret) },
+	},
+	{key: "google_client_id", value: func(c *Config) any { return c.GoogleClientID }},
+	{
+		key:    "google_client_secret",
+		value:  func(c *Config) any { return c.GoogleClientSecret },
+		redact: func(c *Config) any { return redactSecret(c.GoogleClientSecret) },
+	},
+	{key: "auth_allowed_emails", value: func(c *Config) any { return c.AuthAllowedEmails }},
+	{key: "keycloak_url", reloadable: true, value: func(c *Config) any { return c.KeycloakURL }},
+	{key: "keycloak_realm", reloadable: true, value: func(c *Config) any { return c.KeycloakRealm }},
+	{key: "keycloak_client_id", reloadable: true, value: func(c *Config) any { return c.KeycloakClientID }},
+	{
+		key:        "keycloak_client_secret",
+		reloadable: true,
+		value:      func(c *Config) any { return c.KeycloakClientSecret },
+		redact:     func(c *Config) any { return redactSecret(c.KeycloakClientSecret) },
+	},
+	{key: "keycloak_audiences", reloadable: true, value: func(c *Config) any { return c.KeycloakAudiences }},
+	{key: "keycloak_trusted_issuers", reloadable: true, value: func(c *Config) any { return c.KeycloakTrustedIssuers }},
+	{key: "enable_admin_api", value: func(c *Config) any { return c.EnableAdminAPI }},
+	{
+		key:        "admin_api_auth_token",
+		reloadable: true,
+		value:      func(c *Config) any { return c.AdminAPIAuthToken },
+		redact:     func(c *Config) any { return redactSecret(c.AdminAPIAuthToken) },
+	},
+	{key: "trusted_proxies", value: func(c *Config) any { return c.TrustedProxies }},
+	{key: "cors_allowed_origins", value: func(c *Config) any { return c.CORSAllowedOrigins }},
+	{key: "cors_allow_credentials", value: func(c *Config) any { return c.CORSAllowCredentials }},
+	{key: "cors_max_age", value: func(c *Config) any { return c.CORSMaxAge }},
+	{key: "request_timeout", value: func(c *Config) any { return c.RequestTimeout }},
+	{key: "admin_request_timeout", value: func(c *Config) any { return c.AdminRequestTimeout }},
+	{key: "privileged_networks", value: func(c *Config) any { return c.PrivilegedNetworks }},
+	{key: "use_tagged_acl", value: func(c *Config) any { return c.UseTaggedACL }},
+	{key: "strict_privileged_tags", value: func(c *Config) any { return c.StrictPrivilegedTags }},
+	{key: "mesh_proxy_listen", value: func(c *Config) any { return c.MeshProxyListen }},
+	{
+		key:    "mesh_proxy_upstream",
+		value:  func(c *Config) any { return c.MeshProxyUpstream },
+		redact: func(c *Config) any { return redactURL(c.MeshProxyUpstream) },
+	},
+	{key: "dns_records_path", value: func(c *Config) any { return c.DNSRecordsPath }},
+	{key: "dns_base_domain", value: func(c *Config) any { return c.DNSBaseDomain }},
+	{key: "quota_max_nodes", reloadable: true, value: func(c *Config) any { return c.QuotaMaxNodes }},
+	{key: "quota_max_api_keys", reloadable: true, value: func(c *Config) any { return c.QuotaMaxAPIKeys }},
+	{key: "quota_authkeys_per_hour", reloadable: true, value: func(c *Config) any { return c.QuotaAuthKeysPerHour }},
+	{key: "exec_session_retention_days", value: func(c *Config) any { return c.ExecSessionRetentionDays }},
+	{key: "exec_session_max_output_bytes", value: func(c *Config) any { return c.ExecSessionMaxOutputBytes }},
+	{key: "smtp_addr", value: func(c *Config) any { return c.SMTPAddr }},
+	{key: "smtp_username", value: func(c *Config) any { return c.SMTPUsername }},
+	{
+		key:    "smtp_password",
+		value:  func(c *Config) any { return c.SMTPPassword },
+		redact: func(c *Config) any { return redactSecret(c.SMTPPassword) },
+	},
+	{key: "smtp_from", value: func(c *Config) any { return c.SMTPFrom }},
+	{key: "node_offline_after", value: func(c *Config) any { return c.NodeOfflineAfter }},
+	{key: "webhook_allow_http", value: func(c *Config) any { return c.WebhookAllowHTTP }},
+	{key: "log_level", reloadable: true, value: func(c *Config) any { return c.LogLevel }},
+	{key: "log_format", value: func(c *Config) any { return c.LogFormat }},
+	{key: "log_output", value: func(c *Config) any { return c.LogOutput }},
+	{key: "log_max_size_mb", value: func(c *Config) any { return c.LogMaxSizeMB }},
+	{key: "log_max_backups", value: func(c *Config) any { return c.LogMaxBackups }},
+	{key: "log_max_age_days", value: func(c *Config) any { return c.LogMaxAgeDays }},
+	{key: "tls_autocert_cache_dir", value: func(c *Config) any { return c.TLSAutocertCacheDir }},
+	{key: "tls_autocert_email", value: func(c *Config) any { return c.TLSAutocertEmail }},
+	{key: "fixtures", value: func(c *Config) any { return c.Fixtures }},
+	{key: "fixture_wonder_nets", value: func(c *Config) any { return c.FixtureWonderNets }},
+	{key: "fixture_nodes_per_wonder_net", value: func(c *Config) any { return c.FixtureNodesPerWonderNet }},
+	{key: "strict", value: func(c *Config) any { return c.Strict }},
+}
+
+// ConfigSettingResponse is one setting of the effective configuration.
+type ConfigSettingResponse struct {
+	Key            string `json:"key"`
+	Value          any    `json:"value"`
+	Reloadable     bool   `json:"reloadable"`
+	PendingRestart bool   `json:"pending_restart"`
+}
+
+// ConfigResponse is the effective coordinator configuration with secrets
+// redacted.
+type ConfigResponse struct {
+	Settings []ConfigSettingResponse `json:"settings"`
+}
+
+// currentConfig returns the configuration the server is running with.
+func (s *Server) currentConfig() *Config {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
+// Reload applies the reloadable settings of next to the running server:
+// the Keycloak settings used for login and token validation, the admin API
+// token, the default quotas, and the log level. A changed log level replaces one set through
+// the admin API. Changes to other settings are logged and reported as pending
+// until the next restart. If the new Keycloak realm cannot be reached, the
+// reload fails and nothing changes.
+func (s *Server) Reload(ctx context.Context, next *Config) error {
+	s.reloadMu.Lock()
+	defer s.reloadMu.Unlock()
+
+	current := s.currentConfig()
+	if err := validateReloadableConfig(current, next); err != nil {
+		return err
+	}
+
+	var changed, pending []string
+	for _, setting := range configSettings {
+		if settingEqual(setting, current, next) {
+			continue
+		}
+		if setting.reloadable {
+			changed = append(changed, setting.key)
+		} else {
+			pending = append(pending, setting.key)
+		}
+	}
+
+	updated := *current
+	updated.KeycloakURL = next.KeycloakURL
+	updated.KeycloakRealm = next.KeycloakRealm
+	updated.KeycloakClientID = next.KeycloakClientID
+	updated.KeycloakClientSecret = next.KeycloakClientSecret
+	updated.KeycloakAudiences = next.KeycloakAudiences
+	updated.KeycloakTrustedIssuers = next.KeycloakTrustedIssuers
+	updated.AdminAPIAuthToken = next.AdminAPIAuthToken
+	updated.QuotaMaxNodes = next.QuotaMaxNodes
+	updated.QuotaMaxAPIKeys = next.QuotaMaxAPIKeys
+	updated.QuotaAuthKeysPerHour = next.QuotaAuthKeysPerHour
+	updated.LogLevel = next.LogLevel
+
+	if current.AuthMode != AuthModeEmbedded && keycloakChanged(current, &updated) {
+		validatorConfig, err := jwtValidatorConfig(&updated, nil)
+		if err != nil {
+			return err
+		}
+		if err := s.jwtValidator.Reconfigure(ctx, validatorConfig); err != nil {
+			return fmt.Errorf("reconfigure JWT validator: %w", err)
+		}
+		s.oidcService.SetConfig(oidcConfig(&updated))
+	}
+	if quotaDefaults(&updated) != quotaDefaults(current) {
+		s.quotaService.SetDefaults(quotaDefaults(&updated))
+	}
+	if updated.LogLevel != current.LogLevel {
+		// Validated above.
+		level, _ := logging.ParseLevel(updated.LogLevel)
+		logging.SetLevel(level)
+	}
+
+	s.configMu.Lock()
+	s.config = &updated
+	s.pendingRestart = pending
+	s.configMu.Unlock()
+
+	if len(changed) > 0 {
+		slog.Info("coordinator settings reloaded", "settings", changed)
+	}
+	if len(pending) > 0 {
+		slog.Warn("coordinator settings changed but require a restart", "settings", pending)
+	}
+	return nil
+}
+
+// handleGetConfig handles GET /coordinator/admin/api/v1/config requests.
+func (s *Server) handleGetConfig(w http.ResponseWriter, r *http.Request) {
+	s.configMu.RLock()
+	config := s.config
+	pending := s.pendingRestart
+	s.configMu.RUnlock()
+
+	response := ConfigResponse{Settings: make([]ConfigSettingResponse, len(configSettings))}
+	for i, setting := range configSettings {
+		value := setting.value
+		if setting.redact != nil {
+			value = setting.redact
+		}
+		response.Settings[i] = ConfigSettingResponse{
+			Key:            setting.key,
+			Value:          value(config),
+			Reloadable:     setting.reloadable,
+			PendingRestart: slices.Contains(pending, setting.key),
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(response)
+}
+
+// validateReloadableConfig checks the reloadable settings of next with the
+// same rules applied at startup.
+func validateReloadableConfig(current, next *Config) error {
+	if current.AuthMode != AuthModeEmbedded {
+		if next.KeycloakURL == "" {
+			return errors.New("keycloak URL is required")
+		}
+		if next.KeycloakClientSecret == "" {
+			return errors.New("keycloak client secret is required")
+		}
+		if _, err := trustedIssuers(next); err != nil {
+			return err
+		}
+	}
+	if current.EnableAdminAPI && len(next.AdminAPIAuthToken) < 32 {
+		return errors.New("admin API auth token must be at least 32 characters")
+	}
+	if next.QuotaMaxNodes < 0 || next.QuotaMaxAPIKeys < 0 || next.QuotaAuthKeysPerHour < 0 {
+		return errors.New("quotas must not be negative")
+	}
+	if _, err := logging.ParseLevel(next.LogLevel); err != nil {
+		return err
+	}
+	return nil
+}
+
+func keycloakChanged(a, b *Config) bool {
+	return a.KeycloakURL != b.KeycloakURL ||
+		a.KeycloakRealm != b.KeycloakRealm ||
+		a.KeycloakClientID != b.KeycloakClientID ||
+		a.KeycloakClientSecret != b.KeycloakClientSecret ||
+		!slices.Equal(a.KeycloakAudiences, b.KeycloakAudiences) ||
+		!slices.Equal(a.KeycloakTrustedIssuers, b.KeycloakTrustedIssuers)
+}
+
+func settingEqual(setting configSetting, a, b *Config) bool {
+	return fmt.Sprint(setting.value(a)) == fmt.Sprint(setting.value(b))
+}
+
+// redactSecret hides a secret while still showing whether it is set.
+func redactSecret(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return redactedValue
+}
+
+// redactConfiguredDSN hides the password of a database DSN. Unlike
+// redactDSN, it keeps an empty DSN empty so the default is recognizable.
+// redactURL hides the password of a URL setting. Values that are not
+// URLs are returned as is.
+func redactURL(value string) string {
+	if !strings.Contains(value, "://") {
+		return value
+	}
+	u, err := url.Parse(value)
+	if err != nil {
+		return redactedValue
+	}
+	return u.Redacted()
+}
+
+func redactConfiguredDSN(dsn string) string {
+	if dsn == "" {
+		return ""
+	}
+	return redactDSN(dsn)
+}
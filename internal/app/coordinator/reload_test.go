@@ -0,0 +1,150 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func testReloadConfig() *Config {
+	return &Config{
+		Listen:               ":9080",
+		PublicURL:            "https://wonder.example.com",
+		JWTSecret:            "jwt-secret-that-is-at-least-32-bytes",
+		DatabaseDriver:       "postgres",
+		DatabaseDSN:          "postgres://wonder:hunter2@db:5432/wonder",
+		KeycloakURL:          "https://auth.example.com",
+		KeycloakRealm:        "wonder-mesh",
+		KeycloakClientID:     "coordinator",
+		KeycloakClientSecret: "client-secret",
+		EnableAdminAPI:       true,
+		AdminAPIAuthToken:    "test-admin-token-32-chars-long!!",
+	}
+}
+
+func TestReload(t *testing.T) {
+	s := &Server{config: testReloadConfig()}
+
+	next := testReloadConfig()
+	next.AdminAPIAuthToken = "rotated-admin-token-32-chars-long"
+	next.PublicURL = "https://mesh.example.com"
+
+	if err := s.Reload(context.Background(), next); err != nil {
+		t.Fatalf("Reload() error = %v", err)
+	}
+
+	config := s.currentConfig()
+	if config.AdminAPIAuthToken != next.AdminAPIAuthToken {
+		t.Errorf("AdminAPIAuthToken = %q, want %q", config.AdminAPIAuthToken, next.AdminAPIAuthToken)
+	}
+	if config.PublicURL != "https://wonder.example.com" {
+		t.Errorf("PublicURL = %q, want the startup value", config.PublicURL)
+	}
+	if len(s.pendingRestart) != 1 || s.pendingRestart[0] != "public_url" {
+		t.Errorf("pendingRestart = %v, want [public_url]", s.pendingRestart)
+	}
+}
+
+func TestReload_InvalidConfigKeepsCurrent(t *testing.T) {
+	s := &Server{config: testReloadConfig()}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+	}{
+		{name: "short admin token", modify: func(c *Config) { c.AdminAPIAuthToken = "short" }},
+		{name: "missing keycloak URL", modify: func(c *Config) { c.KeycloakURL = "" }},
+		{name: "missing client secret", modify: func(c *Config) { c.KeycloakClientSecret = "" }},
+		{name: "unknown log level", modify: func(c *Config) { c.LogLevel = "verbose" }},
+		{name: "invalid trusted issuer", modify: func(c *Config) { c.KeycloakTrustedIssuers = []string{"auth.example.com/realms/other"} }},
+		{name: "negative quota", modify: func(c *Config) { c.QuotaMaxNodes = -1 }},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			next := testReloadConfig()
+			tt.modify(next)
+			if err := s.Reload(context.Background(), next); err == nil {
+				t.Fatal("Reload() error = nil, want error")
+			}
+			if got := s.currentConfig().AdminAPIAuthToken; got != "test-admin-token-32-chars-long!!" {
+				t.Errorf("AdminAPIAuthToken = %q, want unchanged", got)
+			}
+		})
+	}
+}
+
+func TestHandleGetConfig_RedactsSecrets(t *testing.T) {
+	s := &Server{config: testReloadConfig(), pendingRestart: []string{"listen"}}
+
+	rec := httptest.NewRecorder()
+	s.handleGetConfig(rec, httptest.NewRequest(http.MethodGet, "/coordinator/admin/api/v1/config", nil))
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusOK)
+	}
+
+	var response ConfigResponse
+	if err := json.NewDecoder(rec.Body).Decode(&response); err != nil {
+		t.Fatalf("decode response: %v", err)
+	}
+
+	settings := make(map[string]ConfigSettingResponse)
+	for _, setting := range response.Settings {
+		settings[setting.Key] = setting
+	}
+
+	want := map[string]any{
+		"jwt_secret":             redactedValue,
+		"keycloak_client_secret": redactedValue,
+		"admin_api_auth_token":   redactedValue,
+		"database_dsn":           "postgres://wonder:xxxxx@db:5432/wonder",
+		"keycloak_url":           "https://auth.example.com",
+	}
+	for key, value := range want {
+		if got := settings[key].Value; got != value {
+			t.Errorf("setting %s = %v, want %v", key, got, value)
+		}
+	}
+	if !settings["listen"].PendingRestart {
+		t.Error("listen pending_restart = false, want true")
+	}
+	if !settings["keycloak_client_secret"].Reloadable || settings["public_url"].Reloadable {
+		t.Error("reloadable flags do not match the reloadable settings")
+	}
+}
+
+func TestTrustedIssuers(t *testing.T) {
+	config := testReloadConfig()
+	config.KeycloakAudiences = []string{"wonder-cli"}
+	config.KeycloakTrustedIssuers = []string{
+		"https://login.example.com/realms/wonder-mesh=http://keycloak:8080/realms/wonder-mesh/protocol/openid-connect/certs",
+		"https://auth.example.com/realms/partners/",
+	}
+
+	issuers, err := trustedIssuers(config)
+	if err != nil {
+		t.Fatalf("trustedIssuers() error = %v", err)
+	}
+	if len(issuers) != 2 {
+		t.Fatalf("trustedIssuers() = %d issuers, want 2", len(issuers))
+	}
+
+	tests := []struct {
+		issuer  string
+		jwksURL string
+	}{
+		{"https://login.example.com/realms/wonder-mesh", "http://keycloak:8080/realms/wonder-mesh/protocol/openid-connect/certs"},
+		{"https://auth.example.com/realms/partners/", "https://auth.example.com/realms/partners/protocol/openid-connect/certs"},
+	}
+	for i, tt := range tests {
+		if issuers[i].Issuer != tt.issuer || issuers[i].JWKSURL != tt.jwksURL {
+			t.Errorf("issuer %d = %s %s, want %s %s", i, issuers[i].Issuer, issuers[i].JWKSURL, tt.issuer, tt.jwksURL)
+		}
+		if got := issuers[i].Audiences; len(got) != 2 || got[0] != "coordinator" || got[1] != "wonder-cli" {
+			t.Errorf("issuer %d audiences = %v, want [coordinator wonder-cli]", i, got)
+		}
+	}
+}
@@ -0,0 +1,168 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Access request statuses. A request starts pending, is approved or denied
+// by the wonder net owner, and an approved request ends as expired or
+// revoked. Requests are kept after they end as an audit trail.
+const (
+	AccessRequestPending  = "pending"
+	AccessRequestApproved = "approved"
+	AccessRequestDenied   = "denied"
+	AccessRequestExpired  = "expired"
+	AccessRequestRevoked  = "revoked"
+)
+
+// AccessRequest is a request for temporary access to a service. While it is
+// approved, GrantID names the service grant that gives the access.
+type AccessRequest struct {
+	ID          string
+	WonderNetID string
+	ServiceID   string
+	ServiceName string
+	Subject     string
+	Requester   string
+	Reason      string
+	Duration    time.Duration
+	Status      string
+	DecidedBy   string
+	GrantID     string
+	DecidedAt   *time.Time
+	ExpiresAt   *time.Time
+	EndedAt     *time.Time
+	CreatedAt   time.Time
+}
+
+// AccessRequestRepository handles access request persistence.
+type AccessRequestRepository struct {
+	queries database.Queries
+}
+
+// NewAccessRequestRepository creates a new AccessRequestRepository.
+func NewAccessRequestRepository(queries database.Queries) *AccessRequestRepository {
+	return &AccessRequestRepository{queries: queries}
+}
+
+// Create creates a new pending access request.
+func (r *AccessRequestRepository) Create(ctx context.Context, req *AccessRequest) (*AccessRequest, error) {
+	row, err := r.queries.CreateAccessRequest(ctx, database.CreateAccessRequestParams{
+		ID:              req.ID,
+		WonderNetID:     req.WonderNetID,
+		ServiceID:       req.ServiceID,
+		ServiceName:     req.ServiceName,
+		Subject:         req.Subject,
+		Requester:       req.Requester,
+		Reason:          req.Reason,
+		DurationSeconds: int64(req.Duration / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return accessRequestFromRow(row), nil
+}
+
+// Get retrieves an access request by ID.
+func (r *AccessRequestRepository) Get(ctx context.Context, id string) (*AccessRequest, error) {
+	row, err := r.queries.GetAccessRequestByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return accessRequestFromRow(row), nil
+}
+
+// ListByWonderNet lists all access requests of a wonder net, newest first.
+func (r *AccessRequestRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*AccessRequest, error) {
+	rows, err := r.queries.ListAccessRequestsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]*AccessRequest, len(rows))
+	for i, row := range rows {
+		requests[i] = accessRequestFromRow(row)
+	}
+	return requests, nil
+}
+
+// Approve marks a pending access request approved. It reports false if the
+// request is no longer pending.
+func (r *AccessRequestRepository) Approve(ctx context.Context, id, decidedBy, grantID string, expiresAt time.Time) (bool, error) {
+	n, err := r.queries.DecideAccessRequest(ctx, database.DecideAccessRequestParams{
+		Status:    AccessRequestApproved,
+		DecidedBy: decidedBy,
+		GrantID:   grantID,
+		ExpiresAt: sql.NullTime{Time: expiresAt.UTC(), Valid: true},
+		ID:        id,
+	})
+	return n > 0, err
+}
+
+// Deny marks a pending access request denied. It reports false if the
+// request is no longer pending.
+func (r *AccessRequestRepository) Deny(ctx context.Context, id, decidedBy string) (bool, error) {
+	n, err := r.queries.DecideAccessRequest(ctx, database.DecideAccessRequestParams{
+		Status:    AccessRequestDenied,
+		DecidedBy: decidedBy,
+		ID:        id,
+	})
+	return n > 0, err
+}
+
+// End moves an approved access request to status, which is expired or
+// revoked. It reports false if the request is no longer approved.
+func (r *AccessRequestRepository) End(ctx context.Context, id, status string) (bool, error) {
+	n, err := r.queries.EndAccessRequest(ctx, database.EndAccessRequestParams{
+		Status: status,
+		ID:     id,
+	})
+	return n > 0, err
+}
+
+// ListExpired lists approved access requests that expired at or before now.
+func (r *AccessRequestRepository) ListExpired(ctx context.Context, now time.Time) ([]*AccessRequest, error) {
+	rows, err := r.queries.ListExpiredAccessRequests(ctx, sql.NullTime{Time: now.UTC(), Valid: true})
+	if err != nil {
+		return nil, err
+	}
+	requests := make([]*AccessRequest, len(rows))
+	for i, row := range rows {
+		requests[i] = accessRequestFromRow(row)
+	}
+	return requests, nil
+}
+
+func accessRequestFromRow(row database.AccessRequest) *AccessRequest {
+	req := &AccessRequest{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		ServiceID:   row.ServiceID,
+		ServiceName: row.ServiceName,
+		Subject:     row.Subject,
+		Requester:   row.Requester,
+		Reason:      row.Reason,
+		Duration:    time.Duration(row.DurationSeconds) * time.Second,
+		Status:      row.Status,
+		DecidedBy:   row.DecidedBy,
+		GrantID:     row.GrantID,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.DecidedAt.Valid {
+		req.DecidedAt = &row.DecidedAt.Time
+	}
+	if row.ExpiresAt.Valid {
+		req.ExpiresAt = &row.ExpiresAt.Time
+	}
+	if row.EndedAt.Valid {
+		req.EndedAt = &row.EndedAt.Time
+	}
+	return req
+}
@@ -0,0 +1,224 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// AlertRule is a per-wonder-net rule evaluated against node data.
+type AlertRule struct {
+	ID          string
+	WonderNetID string
+	Name        string
+	Kind        string
+	Threshold   time.Duration
+	CreatedAt   time.Time
+}
+
+// AlertSilence suppresses notifications for matching alerts until EndsAt.
+// An empty RuleID or NodeName matches any rule or node.
+type AlertSilence struct {
+	ID          string
+	WonderNetID string
+	RuleID      string
+	NodeName    string
+	Reason      string
+	EndsAt      time.Time
+	CreatedAt   time.Time
+}
+
+// FiringAlert is the stored state of an alert that is firing for a node,
+// kept so that a restart neither repeats nor loses its notifications.
+type FiringAlert struct {
+	RuleID      string
+	NodeName    string
+	WonderNetID string
+	NodeID      uint64
+	StartsAt    time.Time
+	Silenced    bool
+}
+
+// AlertRepository handles alert rule, silence and firing alert persistence.
+type AlertRepository struct {
+	queries database.Queries
+}
+
+// NewAlertRepository creates a new AlertRepository.
+func NewAlertRepository(queries database.Queries) *AlertRepository {
+	return &AlertRepository{queries: queries}
+}
+
+// CreateRule creates a new alert rule.
+func (r *AlertRepository) CreateRule(ctx context.Context, rule *AlertRule) (*AlertRule, error) {
+	row, err := r.queries.CreateAlertRule(ctx, database.CreateAlertRuleParams{
+		ID:               rule.ID,
+		WonderNetID:      rule.WonderNetID,
+		Name:             rule.Name,
+		Kind:             rule.Kind,
+		ThresholdSeconds: int64(rule.Threshold / time.Second),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alertRuleFromRow(row), nil
+}
+
+// GetRule retrieves an alert rule by ID.
+func (r *AlertRepository) GetRule(ctx context.Context, id string) (*AlertRule, error) {
+	row, err := r.queries.GetAlertRuleByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return alertRuleFromRow(row), nil
+}
+
+// ListRulesByWonderNet lists all alert rules for a wonder net.
+func (r *AlertRepository) ListRulesByWonderNet(ctx context.Context, wonderNetID string) ([]*AlertRule, error) {
+	rows, err := r.queries.ListAlertRulesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*AlertRule, len(rows))
+	for i, row := range rows {
+		rules[i] = alertRuleFromRow(row)
+	}
+	return rules, nil
+}
+
+// ListRules lists alert rules across all wonder nets.
+func (r *AlertRepository) ListRules(ctx context.Context) ([]*AlertRule, error) {
+	rows, err := r.queries.ListAlertRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rules := make([]*AlertRule, len(rows))
+	for i, row := range rows {
+		rules[i] = alertRuleFromRow(row)
+	}
+	return rules, nil
+}
+
+// DeleteRule deletes an alert rule by ID along with its firing alerts.
+func (r *AlertRepository) DeleteRule(ctx context.Context, id string) error {
+	if err := r.queries.DeleteFiringAlertsByRule(ctx, id); err != nil {
+		return err
+	}
+	return r.queries.DeleteAlertRule(ctx, id)
+}
+
+// CreateSilence creates a new alert silence.
+func (r *AlertRepository) CreateSilence(ctx context.Context, silence *AlertSilence) (*AlertSilence, error) {
+	row, err := r.queries.CreateAlertSilence(ctx, database.CreateAlertSilenceParams{
+		ID:          silence.ID,
+		WonderNetID: silence.WonderNetID,
+		RuleID:      silence.RuleID,
+		NodeName:    silence.NodeName,
+		Reason:      silence.Reason,
+		EndsAt:      silence.EndsAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return alertSilenceFromRow(row), nil
+}
+
+// GetSilence retrieves an alert silence by ID.
+func (r *AlertRepository) GetSilence(ctx context.Context, id string) (*AlertSilence, error) {
+	row, err := r.queries.GetAlertSilenceByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return alertSilenceFromRow(row), nil
+}
+
+// ListSilencesByWonderNet lists all alert silences for a wonder net.
+func (r *AlertRepository) ListSilencesByWonderNet(ctx context.Context, wonderNetID string) ([]*AlertSilence, error) {
+	rows, err := r.queries.ListAlertSilencesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	silences := make([]*AlertSilence, len(rows))
+	for i, row := range rows {
+		silences[i] = alertSilenceFromRow(row)
+	}
+	return silences, nil
+}
+
+// DeleteSilence deletes an alert silence by ID.
+func (r *AlertRepository) DeleteSilence(ctx context.Context, id string) error {
+	return r.queries.DeleteAlertSilence(ctx, id)
+}
+
+// ListFiring lists the firing alerts across all wonder nets.
+func (r *AlertRepository) ListFiring(ctx context.Context) ([]*FiringAlert, error) {
+	rows, err := r.queries.ListFiringAlerts(ctx)
+	if err != nil {
+		return nil, err
+	}
+	alerts := make([]*FiringAlert, len(rows))
+	for i, row := range rows {
+		alerts[i] = &FiringAlert{
+			RuleID:      row.RuleID,
+			NodeName:    row.NodeName,
+			WonderNetID: row.WonderNetID,
+			NodeID:      uint64(row.NodeID),
+			StartsAt:    row.StartsAt,
+			Silenced:    row.Silenced,
+		}
+	}
+	return alerts, nil
+}
+
+// SaveFiring records a firing alert, keeping the start time of an alert
+// that is already recorded.
+func (r *AlertRepository) SaveFiring(ctx context.Context, alert *FiringAlert) error {
+	return r.queries.UpsertFiringAlert(ctx, database.UpsertFiringAlertParams{
+		RuleID:      alert.RuleID,
+		NodeName:    alert.NodeName,
+		WonderNetID: alert.WonderNetID,
+		NodeID:      int64(alert.NodeID),
+		StartsAt:    alert.StartsAt.UTC(),
+		Silenced:    alert.Silenced,
+	})
+}
+
+// DeleteFiring deletes the firing alert of a rule for a node.
+func (r *AlertRepository) DeleteFiring(ctx context.Context, ruleID, nodeName string) error {
+	return r.queries.DeleteFiringAlert(ctx, database.DeleteFiringAlertParams{
+		RuleID:   ruleID,
+		NodeName: nodeName,
+	})
+}
+
+func alertRuleFromRow(row database.AlertRule) *AlertRule {
+	return &AlertRule{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		Kind:        row.Kind,
+		Threshold:   time.Duration(row.ThresholdSeconds) * time.Second,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func alertSilenceFromRow(row database.AlertSilence) *AlertSilence {
+	return &AlertSilence{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		RuleID:      row.RuleID,
+		NodeName:    row.NodeName,
+		Reason:      row.Reason,
+		EndsAt:      row.EndsAt,
+		CreatedAt:   row.CreatedAt,
+	}
+}
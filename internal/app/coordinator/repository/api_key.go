@@ -4,21 +4,38 @@ import (
 	"context"
 	"database/sql"
 	"errors"
+	"strings"
 	"time"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
 )
 
-// APIKey represents an API key for third-party integrations.
+// APIKey represents an API key for third-party integrations. Scopes limits
+// what the key may do.
+// AllowedCIDRs limits the client addresses it may be used from; empty
+// allows any. DeprecatedAt is set when the key was rotated, with
+// SuccessorID the key that replaces it.
 type APIKey struct {
-	ID          string
-	WonderNetID string
-	Name        string
-	KeyHash     string
-	KeyPrefix   string
-	CreatedAt   time.Time
-	LastUsedAt  *time.Time
-	ExpiresAt   *time.Time
+	ID           string
+	WonderNetID  string
+	Name         string
+	KeyHash      string
+	KeyPrefix    string
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+	ExpiresAt    *time.Time
+	Scopes       []string
+	AllowedCIDRs []string
+	DeprecatedAt *time.Time
+	SuccessorID  string
+}
+
+// APIKeyUsage counts the requests an API key made to one endpoint.
+type APIKeyUsage struct {
+	APIKeyID     string
+	Endpoint     string
+	RequestCount int64
+	LastUsedAt   time.Time
 }
 
 // APIKeyRepository handles API key persistence.
@@ -32,19 +49,21 @@ func NewAPIKeyRepository(queries database.Queries) *APIKeyRepository {
 }
 
 // Create creates a new API key.
-func (r *APIKeyRepository) Create(ctx context.Context, id, wonderNetID, name, keyHash, keyPrefix string, expiresAt *time.Time) (*APIKey, error) {
+func (r *APIKeyRepository) Create(ctx context.Context, id, wonderNetID, name, keyHash, keyPrefix string, expiresAt *time.Time, scopes, allowedCIDRs []string) (*APIKey, error) {
 	var expiresAtSQL sql.NullTime
 	if expiresAt != nil {
 		expiresAtSQL = sql.NullTime{Time: *expiresAt, Valid: true}
 	}
 
 	row, err := r.queries.CreateAPIKey(ctx, database.CreateAPIKeyParams{
-		ID:          id,
-		WonderNetID: wonderNetID,
-		Name:        name,
-		KeyHash:     keyHash,
-		KeyPrefix:   keyPrefix,
-		ExpiresAt:   expiresAtSQL,
+		ID:           id,
+		WonderNetID:  wonderNetID,
+		Name:         name,
+		KeyHash:      keyHash,
+		KeyPrefix:    keyPrefix,
+		ExpiresAt:    expiresAtSQL,
+		Scopes:       strings.Join(scopes, ","),
+		AllowedCidrs: strings.Join(allowedCIDRs, ","),
 	})
 	if err != nil {
 		return nil, err
@@ -91,8 +110,11 @@ func (r *APIKeyRepository) ListByWonderNet(ctx context.Context, wonderNetID stri
 	return keys, nil
 }
 
-// Delete deletes an API key by ID.
+// Delete deletes an API key and its usage counts by ID.
 func (r *APIKeyRepository) Delete(ctx context.Context, id string) error {
+	if err := r.queries.DeleteAPIKeyUsage(ctx, id); err != nil {
+		return err
+	}
 	return r.queries.DeleteAPIKey(ctx, id)
 }
 
@@ -101,6 +123,45 @@ func (r *APIKeyRepository) UpdateLastUsed(ctx context.Context, id string) error
 	return r.queries.UpdateAPIKeyLastUsed(ctx, id)
 }
 
+// Deprecate marks an API key as replaced by successorID and sets when it
+// expires.
+func (r *APIKeyRepository) Deprecate(ctx context.Context, id, successorID string, expiresAt time.Time) error {
+	return r.queries.DeprecateAPIKey(ctx, database.DeprecateAPIKeyParams{
+		SuccessorID: successorID,
+		ExpiresAt:   sql.NullTime{Time: expiresAt, Valid: true},
+		ID:          id,
+	})
+}
+
+// RecordUsage counts count requests by an API key to an endpoint.
+func (r *APIKeyRepository) RecordUsage(ctx context.Context, id, endpoint string, count int64) error {
+	return r.queries.RecordAPIKeyUsage(ctx, database.RecordAPIKeyUsageParams{
+		APIKeyID:     id,
+		Endpoint:     endpoint,
+		RequestCount: count,
+	})
+}
+
+// ListUsageByWonderNet lists the per-endpoint usage of all API keys of a
+// wonder net, busiest endpoint first for each key.
+func (r *APIKeyRepository) ListUsageByWonderNet(ctx context.Context, wonderNetID string) ([]*APIKeyUsage, error) {
+	rows, err := r.queries.ListAPIKeyUsageByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]*APIKeyUsage, len(rows))
+	for i, row := range rows {
+		usage[i] = &APIKeyUsage{
+			APIKeyID:     row.APIKeyID,
+			Endpoint:     row.Endpoint,
+			RequestCount: row.RequestCount,
+			LastUsedAt:   row.LastUsedAt,
+		}
+	}
+	return usage, nil
+}
+
 func apiKeyFromRow(row database.APIKey) *APIKey {
 	key := &APIKey{
 		ID:          row.ID,
@@ -109,6 +170,7 @@ func apiKeyFromRow(row database.APIKey) *APIKey {
 		KeyHash:     row.KeyHash,
 		KeyPrefix:   row.KeyPrefix,
 		CreatedAt:   row.CreatedAt,
+		SuccessorID: row.SuccessorID,
 	}
 	if row.LastUsedAt.Valid {
 		key.LastUsedAt = &row.LastUsedAt.Time
@@ -116,5 +178,14 @@ func apiKeyFromRow(row database.APIKey) *APIKey {
 	if row.ExpiresAt.Valid {
 		key.ExpiresAt = &row.ExpiresAt.Time
 	}
+	if row.DeprecatedAt.Valid {
+		key.DeprecatedAt = &row.DeprecatedAt.Time
+	}
+	if row.Scopes != "" {
+		key.Scopes = strings.Split(row.Scopes, ",")
+	}
+	if row.AllowedCidrs != "" {
+		key.AllowedCIDRs = strings.Split(row.AllowedCidrs, ",")
+	}
 	return key
 }
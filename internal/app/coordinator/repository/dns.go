@@ -0,0 +1,169 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// DNSDomain is the DNS domain a wonder net's names live under. Domains are
+// unique across wonder nets.
+type DNSDomain struct {
+	WonderNetID string
+	Domain      string
+	UpdatedAt   time.Time
+}
+
+// DNSRecord is an extra DNS record of a wonder net. Name is relative to the
+// wonder net's domain.
+type DNSRecord struct {
+	ID          string
+	WonderNetID string
+	Name        string
+	Type        string
+	Value       string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// DNSRepository handles wonder net DNS domains and records.
+type DNSRepository struct {
+	queries database.Queries
+}
+
+// NewDNSRepository creates a new DNSRepository.
+func NewDNSRepository(queries database.Queries) *DNSRepository {
+	return &DNSRepository{queries: queries}
+}
+
+// GetDomain retrieves the domain of a wonder net, or nil if it has none.
+func (r *DNSRepository) GetDomain(ctx context.Context, wonderNetID string) (*DNSDomain, error) {
+	row, err := r.queries.GetDNSDomain(ctx, wonderNetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dnsDomainFromRow(row), nil
+}
+
+// GetDomainByName retrieves the wonder net domain named domain, or nil if
+// no wonder net uses it.
+func (r *DNSRepository) GetDomainByName(ctx context.Context, domain string) (*DNSDomain, error) {
+	row, err := r.queries.GetDNSDomainByName(ctx, domain)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dnsDomainFromRow(row), nil
+}
+
+// ListDomains lists the domains of all wonder nets.
+func (r *DNSRepository) ListDomains(ctx context.Context) ([]*DNSDomain, error) {
+	rows, err := r.queries.ListDNSDomains(ctx)
+	if err != nil {
+		return nil, err
+	}
+	domains := make([]*DNSDomain, len(rows))
+	for i, row := range rows {
+		domains[i] = dnsDomainFromRow(row)
+	}
+	return domains, nil
+}
+
+// SetDomain creates or replaces the domain of a wonder net.
+func (r *DNSRepository) SetDomain(ctx context.Context, wonderNetID, domain string) (*DNSDomain, error) {
+	row, err := r.queries.UpsertDNSDomain(ctx, database.UpsertDNSDomainParams{
+		WonderNetID: wonderNetID,
+		Domain:      domain,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dnsDomainFromRow(row), nil
+}
+
+// CreateRecord creates a new DNS record.
+func (r *DNSRepository) CreateRecord(ctx context.Context, record *DNSRecord) (*DNSRecord, error) {
+	row, err := r.queries.CreateDNSRecord(ctx, database.CreateDNSRecordParams{
+		ID:          record.ID,
+		WonderNetID: record.WonderNetID,
+		Name:        record.Name,
+		Type:        record.Type,
+		Value:       record.Value,
+		CreatedBy:   record.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return dnsRecordFromRow(row), nil
+}
+
+// GetRecord retrieves a DNS record by ID.
+func (r *DNSRepository) GetRecord(ctx context.Context, id string) (*DNSRecord, error) {
+	row, err := r.queries.GetDNSRecordByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dnsRecordFromRow(row), nil
+}
+
+// ListRecordsByWonderNet lists the DNS records of a wonder net.
+func (r *DNSRepository) ListRecordsByWonderNet(ctx context.Context, wonderNetID string) ([]*DNSRecord, error) {
+	rows, err := r.queries.ListDNSRecordsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*DNSRecord, len(rows))
+	for i, row := range rows {
+		records[i] = dnsRecordFromRow(row)
+	}
+	return records, nil
+}
+
+// ListRecords lists DNS records across all wonder nets.
+func (r *DNSRepository) ListRecords(ctx context.Context) ([]*DNSRecord, error) {
+	rows, err := r.queries.ListDNSRecords(ctx)
+	if err != nil {
+		return nil, err
+	}
+	records := make([]*DNSRecord, len(rows))
+	for i, row := range rows {
+		records[i] = dnsRecordFromRow(row)
+	}
+	return records, nil
+}
+
+// DeleteRecord deletes a DNS record.
+func (r *DNSRepository) DeleteRecord(ctx context.Context, id string) error {
+	return r.queries.DeleteDNSRecord(ctx, id)
+}
+
+func dnsDomainFromRow(row database.DNSDomain) *DNSDomain {
+	return &DNSDomain{
+		WonderNetID: row.WonderNetID,
+		Domain:      row.Domain,
+		UpdatedAt:   row.UpdatedAt,
+	}
+}
+
+func dnsRecordFromRow(row database.DNSRecord) *DNSRecord {
+	return &DNSRecord{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		Type:        row.Type,
+		Value:       row.Value,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
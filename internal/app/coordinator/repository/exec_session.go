@@ -0,0 +1,122 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// ExecSession is the record of a command run on a node of a wonder net,
+// kept as an audit trail of what was run across the fleet.
+type ExecSession struct {
+	ID          string
+	WonderNetID string
+	NodeID      string
+	NodeName    string
+	Address     string
+	Command     string
+	Stdout      string
+	Stderr      string
+	// OutputTruncated is set if Stdout or Stderr was cut to the coordinator's
+	// output limit.
+	OutputTruncated bool
+	ExitCode        int
+	// Error is why the command could not be run, if it could not.
+	Error      string
+	RecordedBy string
+	StartedAt  time.Time
+	Duration   time.Duration
+	CreatedAt  time.Time
+}
+
+// ExecSessionRepository handles exec session persistence.
+type ExecSessionRepository struct {
+	queries database.Queries
+}
+
+// NewExecSessionRepository creates a new ExecSessionRepository.
+func NewExecSessionRepository(queries database.Queries) *ExecSessionRepository {
+	return &ExecSessionRepository{queries: queries}
+}
+
+// Create records an exec session.
+func (r *ExecSessionRepository) Create(ctx context.Context, s *ExecSession) (*ExecSession, error) {
+	row, err := r.queries.CreateExecSession(ctx, database.CreateExecSessionParams{
+		ID:              s.ID,
+		WonderNetID:     s.WonderNetID,
+		NodeID:          s.NodeID,
+		NodeName:        s.NodeName,
+		Address:         s.Address,
+		Command:         s.Command,
+		Stdout:          s.Stdout,
+		Stderr:          s.Stderr,
+		OutputTruncated: s.OutputTruncated,
+		ExitCode:        int64(s.ExitCode),
+		Error:           s.Error,
+		RecordedBy:      s.RecordedBy,
+		StartedAt:       s.StartedAt.UTC(),
+		DurationMs:      s.Duration.Milliseconds(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return execSessionFromRow(row), nil
+}
+
+// Get retrieves an exec session by ID.
+func (r *ExecSessionRepository) Get(ctx context.Context, id string) (*ExecSession, error) {
+	row, err := r.queries.GetExecSessionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return execSessionFromRow(row), nil
+}
+
+// ListByWonderNet lists the latest exec sessions of a wonder net, most
+// recent first, up to limit.
+func (r *ExecSessionRepository) ListByWonderNet(ctx context.Context, wonderNetID string, limit int) ([]*ExecSession, error) {
+	rows, err := r.queries.ListExecSessionsByWonderNet(ctx, database.ListExecSessionsByWonderNetParams{
+		WonderNetID: wonderNetID,
+		Limit:       int32(limit),
+	})
+	if err != nil {
+		return nil, err
+	}
+	sessions := make([]*ExecSession, len(rows))
+	for i, row := range rows {
+		sessions[i] = execSessionFromRow(row)
+	}
+	return sessions, nil
+}
+
+// DeleteBefore deletes the exec sessions started before t and returns how
+// many were deleted.
+func (r *ExecSessionRepository) DeleteBefore(ctx context.Context, t time.Time) (int64, error) {
+	return r.queries.DeleteExecSessionsBefore(ctx, t.UTC())
+}
+
+func execSessionFromRow(row database.ExecSession) *ExecSession {
+	return &ExecSession{
+		ID:              row.ID,
+		WonderNetID:     row.WonderNetID,
+		NodeID:          row.NodeID,
+		NodeName:        row.NodeName,
+		Address:         row.Address,
+		Command:         row.Command,
+		Stdout:          row.Stdout,
+		Stderr:          row.Stderr,
+		OutputTruncated: row.OutputTruncated,
+		ExitCode:        int(row.ExitCode),
+		Error:           row.Error,
+		RecordedBy:      row.RecordedBy,
+		StartedAt:       row.StartedAt,
+		Duration:        time.Duration(row.DurationMs) * time.Millisecond,
+		CreatedAt:       row.CreatedAt,
+	}
+}
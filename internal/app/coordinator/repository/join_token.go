@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// JoinToken is the usage record of an issued join token, keyed by the
+// token's jti claim. AllowedCIDRs limits the addresses workers may join
+// from; empty allows any.
+type JoinToken struct {
+	JTI          string
+	WonderNetID  string
+	CreatedBy    string
+	MaxUses      int
+	Uses         int
+	CreatedAt    time.Time
+	ExpiresAt    time.Time
+	LastUsedAt   *time.Time
+	AllowedCIDRs []string
+}
+
+// JoinTokenRepository handles join token usage persistence.
+type JoinTokenRepository struct {
+	queries database.Queries
+}
+
+// NewJoinTokenRepository creates a new JoinTokenRepository.
+func NewJoinTokenRepository(queries database.Queries) *JoinTokenRepository {
+	return &JoinTokenRepository{queries: queries}
+}
+
+// Create records a newly issued join token with no uses.
+func (r *JoinTokenRepository) Create(ctx context.Context, token *JoinToken) error {
+	return r.queries.CreateJoinToken(ctx, database.CreateJoinTokenParams{
+		Jti:          token.JTI,
+		WonderNetID:  token.WonderNetID,
+		CreatedBy:    token.CreatedBy,
+		MaxUses:      int64(token.MaxUses),
+		ExpiresAt:    token.ExpiresAt.UTC(),
+		AllowedCidrs: strings.Join(token.AllowedCIDRs, ","),
+	})
+}
+
+// Get retrieves a join token by its jti.
+func (r *JoinTokenRepository) Get(ctx context.Context, jti string) (*JoinToken, error) {
+	row, err := r.queries.GetJoinToken(ctx, jti)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	token := &JoinToken{
+		JTI:         row.Jti,
+		WonderNetID: row.WonderNetID,
+		CreatedBy:   row.CreatedBy,
+		MaxUses:     int(row.MaxUses),
+		Uses:        int(row.Uses),
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.LastUsedAt.Valid {
+		token.LastUsedAt = &row.LastUsedAt.Time
+	}
+	if row.AllowedCidrs != "" {
+		token.AllowedCIDRs = strings.Split(row.AllowedCidrs, ",")
+	}
+	return token, nil
+}
+
+// Use counts one join with the token. It reports false, without counting,
+// if the token is unknown or already used up.
+func (r *JoinTokenRepository) Use(ctx context.Context, jti string) (bool, error) {
+	n, err := r.queries.UseJoinToken(ctx, jti)
+	return n > 0, err
+}
+
+// Release gives back a use counted by Use for a join that did not complete.
+func (r *JoinTokenRepository) Release(ctx context.Context, jti string) error {
+	return r.queries.ReleaseJoinToken(ctx, jti)
+}
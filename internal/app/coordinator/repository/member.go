@@ -0,0 +1,272 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Member roles, from most to least privileged. Owners manage members and
+// invites; members may also create tokens and remove nodes; read-only
+// members may only look. The user in wonder_nets.owner_id is always an
+// owner and has no member record.
+const (
+	RoleOwner    = "owner"
+	RoleMember   = "member"
+	RoleReadOnly = "read_only"
+)
+
+// Invite statuses. An invite is pending until the invited user accepts or
+// declines it, or an owner revokes it. Pending invites past their expiry
+// are marked expired when they are next looked at.
+const (
+	InvitePending  = "pending"
+	InviteAccepted = "accepted"
+	InviteDeclined = "declined"
+	InviteRevoked  = "revoked"
+	InviteExpired  = "expired"
+)
+
+// WonderNetMember is a user a wonder net is shared with.
+type WonderNetMember struct {
+	WonderNetID string
+	UserID      string
+	Email       string
+	Role        string
+	AddedBy     string
+	CreatedAt   time.Time
+}
+
+// WonderNetInvite is an invitation for a user to become a member of a wonder
+// net. UserID is the Keycloak user the email belonged to when the invite was
+// made; only that user can accept it.
+type WonderNetInvite struct {
+	ID          string
+	WonderNetID string
+	Email       string
+	UserID      string
+	Role        string
+	InvitedBy   string
+	Status      string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	DecidedAt   *time.Time
+}
+
+// MemberRepository handles wonder net member and invite persistence.
+type MemberRepository struct {
+	queries database.Queries
+}
+
+// NewMemberRepository creates a new MemberRepository.
+func NewMemberRepository(queries database.Queries) *MemberRepository {
+	return &MemberRepository{queries: queries}
+}
+
+// GetMember retrieves the membership of a user in a wonder net.
+func (r *MemberRepository) GetMember(ctx context.Context, wonderNetID, userID string) (*WonderNetMember, error) {
+	row, err := r.queries.GetWonderNetMember(ctx, database.GetWonderNetMemberParams{
+		WonderNetID: wonderNetID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return memberFromRow(row), nil
+}
+
+// ListMembers lists the members of a wonder net, oldest first.
+func (r *MemberRepository) ListMembers(ctx context.Context, wonderNetID string) ([]*WonderNetMember, error) {
+	rows, err := r.queries.ListWonderNetMembers(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	return membersFromRows(rows), nil
+}
+
+// ListMembershipsByUser lists the wonder nets shared with a user.
+func (r *MemberRepository) ListMembershipsByUser(ctx context.Context, userID string) ([]*WonderNetMember, error) {
+	rows, err := r.queries.ListWonderNetMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return membersFromRows(rows), nil
+}
+
+// UpdateMemberRole changes the role of a member. It reports false if the
+// user is not a member.
+func (r *MemberRepository) UpdateMemberRole(ctx context.Context, wonderNetID, userID, role string) (bool, error) {
+	n, err := r.queries.UpdateWonderNetMemberRole(ctx, database.UpdateWonderNetMemberRoleParams{
+		Role:        role,
+		WonderNetID: wonderNetID,
+		UserID:      userID,
+	})
+	return n > 0, err
+}
+
+// RemoveMember removes a member from a wonder net. It reports false if the
+// user was not a member.
+func (r *MemberRepository) RemoveMember(ctx context.Context, wonderNetID, userID string) (bool, error) {
+	n, err := r.queries.DeleteWonderNetMember(ctx, database.DeleteWonderNetMemberParams{
+		WonderNetID: wonderNetID,
+		UserID:      userID,
+	})
+	return n > 0, err
+}
+
+// CreateInvite creates a new pending invite.
+func (r *MemberRepository) CreateInvite(ctx context.Context, inv *WonderNetInvite) (*WonderNetInvite, error) {
+	row, err := r.queries.CreateWonderNetInvite(ctx, database.CreateWonderNetInviteParams{
+		ID:          inv.ID,
+		WonderNetID: inv.WonderNetID,
+		Email:       inv.Email,
+		UserID:      inv.UserID,
+		Role:        inv.Role,
+		InvitedBy:   inv.InvitedBy,
+		ExpiresAt:   inv.ExpiresAt.UTC(),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return inviteFromRow(row), nil
+}
+
+// GetInvite retrieves an invite by ID.
+func (r *MemberRepository) GetInvite(ctx context.Context, id string) (*WonderNetInvite, error) {
+	row, err := r.queries.GetWonderNetInvite(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return inviteFromRow(row), nil
+}
+
+// GetPendingInvite retrieves the pending invite of a user to a wonder net,
+// if any.
+func (r *MemberRepository) GetPendingInvite(ctx context.Context, wonderNetID, userID string) (*WonderNetInvite, error) {
+	row, err := r.queries.GetPendingWonderNetInvite(ctx, database.GetPendingWonderNetInviteParams{
+		WonderNetID: wonderNetID,
+		UserID:      userID,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return inviteFromRow(row), nil
+}
+
+// ListPendingInvitesByWonderNet lists the pending invites of a wonder net,
+// newest first.
+func (r *MemberRepository) ListPendingInvitesByWonderNet(ctx context.Context, wonderNetID string) ([]*WonderNetInvite, error) {
+	rows, err := r.queries.ListPendingWonderNetInvitesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	return invitesFromRows(rows), nil
+}
+
+// ListPendingInvitesByUser lists the pending invites addressed to a user,
+// newest first.
+func (r *MemberRepository) ListPendingInvitesByUser(ctx context.Context, userID string) ([]*WonderNetInvite, error) {
+	rows, err := r.queries.ListPendingWonderNetInvitesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return invitesFromRows(rows), nil
+}
+
+// DecideInvite moves a pending invite to status. It reports false if the
+// invite is no longer pending.
+func (r *MemberRepository) DecideInvite(ctx context.Context, id, status string) (bool, error) {
+	n, err := r.queries.DecideWonderNetInvite(ctx, database.DecideWonderNetInviteParams{
+		Status: status,
+		ID:     id,
+	})
+	return n > 0, err
+}
+
+// AcceptInvite moves a pending invite to accepted and adds userID as a
+// member with the role of the invite, in one transaction. It reports false,
+// adding no member, if the invite is no longer pending.
+func (r *MemberRepository) AcceptInvite(ctx context.Context, inv *WonderNetInvite, userID string) (bool, error) {
+	var accepted bool
+	err := r.queries.InTx(ctx, func(q database.Queries) error {
+		n, err := q.DecideWonderNetInvite(ctx, database.DecideWonderNetInviteParams{
+			Status: InviteAccepted,
+			ID:     inv.ID,
+		})
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			return nil
+		}
+		accepted = true
+		return q.AddWonderNetMember(ctx, database.AddWonderNetMemberParams{
+			WonderNetID: inv.WonderNetID,
+			UserID:      userID,
+			Email:       inv.Email,
+			Role:        inv.Role,
+			AddedBy:     inv.InvitedBy,
+		})
+	})
+	if err != nil {
+		return false, err
+	}
+	return accepted, nil
+}
+
+func membersFromRows(rows []database.WonderNetMember) []*WonderNetMember {
+	members := make([]*WonderNetMember, len(rows))
+	for i, row := range rows {
+		members[i] = memberFromRow(row)
+	}
+	return members
+}
+
+func memberFromRow(row database.WonderNetMember) *WonderNetMember {
+	return &WonderNetMember{
+		WonderNetID: row.WonderNetID,
+		UserID:      row.UserID,
+		Email:       row.Email,
+		Role:        row.Role,
+		AddedBy:     row.AddedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func invitesFromRows(rows []database.WonderNetInvite) []*WonderNetInvite {
+	invites := make([]*WonderNetInvite, len(rows))
+	for i, row := range rows {
+		invites[i] = inviteFromRow(row)
+	}
+	return invites
+}
+
+func inviteFromRow(row database.WonderNetInvite) *WonderNetInvite {
+	inv := &WonderNetInvite{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Email:       row.Email,
+		UserID:      row.UserID,
+		Role:        row.Role,
+		InvitedBy:   row.InvitedBy,
+		Status:      row.Status,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.DecidedAt.Valid {
+		inv.DecidedAt = &row.DecidedAt.Time
+	}
+	return inv
+}
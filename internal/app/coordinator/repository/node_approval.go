@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Statuses of a node approval. Pending nodes are tagged so that no ACL rule
+// of their wonder net matches them until they are approved.
+const (
+	NodeApprovalPending  = "pending"
+	NodeApprovalApproved = "approved"
+)
+
+// NodeApproval records whether a node of a wonder net that requires node
+// approval has been approved. Nodes without a record have not been seen by
+// the approval reconciler yet.
+type NodeApproval struct {
+	NodeID      string
+	WonderNetID string
+	Status      string
+	DecidedBy   string
+	CreatedAt   time.Time
+	DecidedAt   *time.Time
+}
+
+// NodeApprovalRepository handles node approval persistence.
+type NodeApprovalRepository struct {
+	queries database.Queries
+}
+
+// NewNodeApprovalRepository creates a new NodeApprovalRepository.
+func NewNodeApprovalRepository(queries database.Queries) *NodeApprovalRepository {
+	return &NodeApprovalRepository{queries: queries}
+}
+
+// Create records a node with the given status. A node that already has a
+// record keeps it. decidedBy and decidedAt are only meaningful for nodes
+// that are recorded as approved right away.
+func (r *NodeApprovalRepository) Create(ctx context.Context, nodeID, wonderNetID, status, decidedBy string, decidedAt *time.Time) error {
+	var decided sql.NullTime
+	if decidedAt != nil {
+		decided = sql.NullTime{Time: decidedAt.UTC(), Valid: true}
+	}
+	return r.queries.CreateNodeApproval(ctx, database.CreateNodeApprovalParams{
+		NodeID:      nodeID,
+		WonderNetID: wonderNetID,
+		Status:      status,
+		DecidedBy:   decidedBy,
+		DecidedAt:   decided,
+	})
+}
+
+// Get retrieves the approval record of a node.
+func (r *NodeApprovalRepository) Get(ctx context.Context, nodeID string) (*NodeApproval, error) {
+	row, err := r.queries.GetNodeApproval(ctx, nodeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nodeApprovalFromRow(row), nil
+}
+
+// ListByWonderNet lists the approval records of a wonder net's nodes.
+func (r *NodeApprovalRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*NodeApproval, error) {
+	rows, err := r.queries.ListNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	return nodeApprovalsFromRows(rows), nil
+}
+
+// ListPendingByWonderNet lists the nodes of a wonder net that wait for
+// approval, oldest first.
+func (r *NodeApprovalRepository) ListPendingByWonderNet(ctx context.Context, wonderNetID string) ([]*NodeApproval, error) {
+	rows, err := r.queries.ListPendingNodeApprovalsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	return nodeApprovalsFromRows(rows), nil
+}
+
+// Decide sets the status of a node and records who decided it.
+func (r *NodeApprovalRepository) Decide(ctx context.Context, nodeID, status, decidedBy string) error {
+	return r.queries.DecideNodeApproval(ctx, database.DecideNodeApprovalParams{
+		Status:    status,
+		DecidedBy: decidedBy,
+		NodeID:    nodeID,
+	})
+}
+
+// Delete deletes the approval record of a node.
+func (r *NodeApprovalRepository) Delete(ctx context.Context, nodeID string) error {
+	return r.queries.DeleteNodeApproval(ctx, nodeID)
+}
+
+func nodeApprovalsFromRows(rows []database.NodeApproval) []*NodeApproval {
+	approvals := make([]*NodeApproval, len(rows))
+	for i, row := range rows {
+		approvals[i] = nodeApprovalFromRow(row)
+	}
+	return approvals
+}
+
+func nodeApprovalFromRow(row database.NodeApproval) *NodeApproval {
+	approval := &NodeApproval{
+		NodeID:      row.NodeID,
+		WonderNetID: row.WonderNetID,
+		Status:      row.Status,
+		DecidedBy:   row.DecidedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.DecidedAt.Valid {
+		approval.DecidedAt = &row.DecidedAt.Time
+	}
+	return approval
+}
@@ -0,0 +1,189 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Node decommission statuses. A decommission is in progress until the node
+// has been removed from the mesh (completed) or teardown stopped short of
+// that (failed), for example because the wipe job failed.
+const (
+	NodeDecommissionInProgress = "in_progress"
+	NodeDecommissionCompleted  = "completed"
+	NodeDecommissionFailed     = "failed"
+)
+
+// Wipe statuses of a node decommission. Skipped means no wipe was asked for;
+// pending means the node's worker has not reported the wipe result yet.
+const (
+	NodeWipeSkipped   = "skipped"
+	NodeWipePending   = "pending"
+	NodeWipeSucceeded = "succeeded"
+	NodeWipeFailed    = "failed"
+	NodeWipeTimedOut  = "timed_out"
+)
+
+// NodeDecommission is the archived record of a node taken out of service:
+// what the node was when it was decommissioned, and how the teardown went.
+// Records are kept after the node is gone.
+type NodeDecommission struct {
+	ID          string
+	WonderNetID string
+	NodeID      string
+	NodeName    string
+	Hostname    string
+	Addresses   []string
+	OS          string
+	LastSeenAt  *time.Time
+	RequestedBy string
+	Reason      string
+	Status      string
+	WipeStatus  string
+	WipeOutput  string
+	Error       string
+	CreatedAt   time.Time
+	CompletedAt *time.Time
+}
+
+// NodeDecommissionRepository handles node decommission persistence.
+type NodeDecommissionRepository struct {
+	queries database.Queries
+}
+
+// NewNodeDecommissionRepository creates a new NodeDecommissionRepository.
+func NewNodeDecommissionRepository(queries database.Queries) *NodeDecommissionRepository {
+	return &NodeDecommissionRepository{queries: queries}
+}
+
+// Create creates a new in-progress node decommission.
+func (r *NodeDecommissionRepository) Create(ctx context.Context, d *NodeDecommission) (*NodeDecommission, error) {
+	var lastSeenAt sql.NullTime
+	if d.LastSeenAt != nil {
+		lastSeenAt = sql.NullTime{Time: d.LastSeenAt.UTC(), Valid: true}
+	}
+	row, err := r.queries.CreateNodeDecommission(ctx, database.CreateNodeDecommissionParams{
+		ID:          d.ID,
+		WonderNetID: d.WonderNetID,
+		NodeID:      d.NodeID,
+		NodeName:    d.NodeName,
+		Hostname:    d.Hostname,
+		Addresses:   strings.Join(d.Addresses, ","),
+		OS:          d.OS,
+		LastSeenAt:  lastSeenAt,
+		RequestedBy: d.RequestedBy,
+		Reason:      d.Reason,
+		WipeStatus:  d.WipeStatus,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return nodeDecommissionFromRow(row), nil
+}
+
+// Get retrieves a node decommission by ID.
+func (r *NodeDecommissionRepository) Get(ctx context.Context, id string) (*NodeDecommission, error) {
+	row, err := r.queries.GetNodeDecommissionByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nodeDecommissionFromRow(row), nil
+}
+
+// GetActiveByNode retrieves the in-progress decommission of a node, if any.
+func (r *NodeDecommissionRepository) GetActiveByNode(ctx context.Context, nodeID string) (*NodeDecommission, error) {
+	row, err := r.queries.GetActiveNodeDecommissionByNode(ctx, nodeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nodeDecommissionFromRow(row), nil
+}
+
+// ListByWonderNet lists all node decommissions of a wonder net, newest first.
+func (r *NodeDecommissionRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*NodeDecommission, error) {
+	rows, err := r.queries.ListNodeDecommissionsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	return nodeDecommissionsFromRows(rows), nil
+}
+
+// FinishWipe records the result of a pending wipe. It reports false if the
+// wipe is no longer pending.
+func (r *NodeDecommissionRepository) FinishWipe(ctx context.Context, id, wipeStatus, wipeOutput string) (bool, error) {
+	n, err := r.queries.FinishNodeWipe(ctx, database.FinishNodeWipeParams{
+		WipeStatus: wipeStatus,
+		WipeOutput: wipeOutput,
+		ID:         id,
+	})
+	return n > 0, err
+}
+
+// End moves an in-progress decommission to status, which is completed or
+// failed, with errMsg describing a failure. It reports false if the
+// decommission is no longer in progress.
+func (r *NodeDecommissionRepository) End(ctx context.Context, id, status, errMsg string) (bool, error) {
+	n, err := r.queries.EndNodeDecommission(ctx, database.EndNodeDecommissionParams{
+		Status: status,
+		Error:  errMsg,
+		ID:     id,
+	})
+	return n > 0, err
+}
+
+// ListStaleWipes lists in-progress decommissions still waiting for a wipe
+// result that were started at or before createdBefore.
+func (r *NodeDecommissionRepository) ListStaleWipes(ctx context.Context, createdBefore time.Time) ([]*NodeDecommission, error) {
+	rows, err := r.queries.ListStaleNodeWipes(ctx, createdBefore.UTC())
+	if err != nil {
+		return nil, err
+	}
+	return nodeDecommissionsFromRows(rows), nil
+}
+
+func nodeDecommissionsFromRows(rows []database.NodeDecommission) []*NodeDecommission {
+	decommissions := make([]*NodeDecommission, len(rows))
+	for i, row := range rows {
+		decommissions[i] = nodeDecommissionFromRow(row)
+	}
+	return decommissions
+}
+
+func nodeDecommissionFromRow(row database.NodeDecommission) *NodeDecommission {
+	d := &NodeDecommission{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		NodeID:      row.NodeID,
+		NodeName:    row.NodeName,
+		Hostname:    row.Hostname,
+		OS:          row.OS,
+		RequestedBy: row.RequestedBy,
+		Reason:      row.Reason,
+		Status:      row.Status,
+		WipeStatus:  row.WipeStatus,
+		WipeOutput:  row.WipeOutput,
+		Error:       row.Error,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.Addresses != "" {
+		d.Addresses = strings.Split(row.Addresses, ",")
+	}
+	if row.LastSeenAt.Valid {
+		d.LastSeenAt = &row.LastSeenAt.Time
+	}
+	if row.CompletedAt.Valid {
+		d.CompletedAt = &row.CompletedAt.Time
+	}
+	return d
+}
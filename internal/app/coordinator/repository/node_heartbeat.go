@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// NodeHeartbeat is the latest health report a worker sent for its node.
+type NodeHeartbeat struct {
+	NodeID               string
+	WonderNetID          string
+	OS                   string
+	TailscaleVersion     string
+	DiskTotalBytes       int64
+	DiskFreeBytes        int64
+	MemoryTotalBytes     int64
+	MemoryAvailableBytes int64
+	// Connectivity is the node's view of the mesh as JSON: its DERP region,
+	// DERP latencies, and how it reaches its peers. Empty if not reported.
+	Connectivity string
+	ReportedAt   time.Time
+}
+
+// NodeHeartbeatRepository handles node heartbeat persistence. Only the
+// latest heartbeat of each node is kept.
+type NodeHeartbeatRepository struct {
+	queries database.Queries
+}
+
+// NewNodeHeartbeatRepository creates a new NodeHeartbeatRepository.
+func NewNodeHeartbeatRepository(queries database.Queries) *NodeHeartbeatRepository {
+	return &NodeHeartbeatRepository{queries: queries}
+}
+
+// Upsert stores a heartbeat, replacing the previous one of the same node.
+func (r *NodeHeartbeatRepository) Upsert(ctx context.Context, hb *NodeHeartbeat) error {
+	return r.queries.UpsertNodeHeartbeat(ctx, database.UpsertNodeHeartbeatParams{
+		NodeID:               hb.NodeID,
+		WonderNetID:          hb.WonderNetID,
+		OS:                   hb.OS,
+		TailscaleVersion:     hb.TailscaleVersion,
+		DiskTotalBytes:       hb.DiskTotalBytes,
+		DiskFreeBytes:        hb.DiskFreeBytes,
+		MemoryTotalBytes:     hb.MemoryTotalBytes,
+		MemoryAvailableBytes: hb.MemoryAvailableBytes,
+		Connectivity:         hb.Connectivity,
+		ReportedAt:           hb.ReportedAt.UTC(),
+	})
+}
+
+// Get retrieves the latest heartbeat of a node.
+func (r *NodeHeartbeatRepository) Get(ctx context.Context, nodeID string) (*NodeHeartbeat, error) {
+	row, err := r.queries.GetNodeHeartbeat(ctx, nodeID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return nodeHeartbeatFromRow(row), nil
+}
+
+// ListByWonderNet lists the latest heartbeat of every node of a wonder net
+// that has reported one.
+func (r *NodeHeartbeatRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*NodeHeartbeat, error) {
+	rows, err := r.queries.ListNodeHeartbeatsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	heartbeats := make([]*NodeHeartbeat, len(rows))
+	for i, row := range rows {
+		heartbeats[i] = nodeHeartbeatFromRow(row)
+	}
+	return heartbeats, nil
+}
+
+// Delete deletes the heartbeat of a node.
+func (r *NodeHeartbeatRepository) Delete(ctx context.Context, nodeID string) error {
+	return r.queries.DeleteNodeHeartbeat(ctx, nodeID)
+}
+
+func nodeHeartbeatFromRow(row database.NodeHeartbeat) *NodeHeartbeat {
+	return &NodeHeartbeat{
+		NodeID:               row.NodeID,
+		WonderNetID:          row.WonderNetID,
+		OS:                   row.OS,
+		TailscaleVersion:     row.TailscaleVersion,
+		DiskTotalBytes:       row.DiskTotalBytes,
+		DiskFreeBytes:        row.DiskFreeBytes,
+		MemoryTotalBytes:     row.MemoryTotalBytes,
+		MemoryAvailableBytes: row.MemoryAvailableBytes,
+		Connectivity:         row.Connectivity,
+		ReportedAt:           row.ReportedAt,
+	}
+}
@@ -0,0 +1,62 @@
+package repository
+
+import (
+	"context"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// NodeName is a name set for a node through the rename API. It takes the
+// place of the name the node naming policy would give it.
+type NodeName struct {
+	NodeID      string
+	WonderNetID string
+	Name        string
+	RenamedBy   string
+	RenamedAt   time.Time
+}
+
+// NodeNameRepository handles node name persistence.
+type NodeNameRepository struct {
+	queries database.Queries
+}
+
+// NewNodeNameRepository creates a new NodeNameRepository.
+func NewNodeNameRepository(queries database.Queries) *NodeNameRepository {
+	return &NodeNameRepository{queries: queries}
+}
+
+// Set records the name of a node, replacing a name set before.
+func (r *NodeNameRepository) Set(ctx context.Context, nodeID, wonderNetID, name, renamedBy string) error {
+	return r.queries.UpsertNodeName(ctx, database.UpsertNodeNameParams{
+		NodeID:      nodeID,
+		WonderNetID: wonderNetID,
+		Name:        name,
+		RenamedBy:   renamedBy,
+	})
+}
+
+// ListByWonderNet lists the names set for a wonder net's nodes.
+func (r *NodeNameRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*NodeName, error) {
+	rows, err := r.queries.ListNodeNamesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	names := make([]*NodeName, len(rows))
+	for i, row := range rows {
+		names[i] = &NodeName{
+			NodeID:      row.NodeID,
+			WonderNetID: row.WonderNetID,
+			Name:        row.Name,
+			RenamedBy:   row.RenamedBy,
+			RenamedAt:   row.RenamedAt,
+		}
+	}
+	return names, nil
+}
+
+// Delete deletes the name set for a node, returning it to the naming policy.
+func (r *NodeNameRepository) Delete(ctx context.Context, nodeID string) error {
+	return r.queries.DeleteNodeName(ctx, nodeID)
+}
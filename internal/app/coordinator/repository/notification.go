@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Notification channel kinds.
+const (
+	NotificationChannelEmail = "email"
+	NotificationChannelSlack = "slack"
+)
+
+// NotificationChannel is a destination for a wonder net's notifications:
+// email addresses for email channels, or an incoming webhook URL for Slack
+// channels. Notifications are batched into one message per Digest; zero
+// sends each one on its own. An empty Events list subscribes to every event.
+type NotificationChannel struct {
+	ID          string
+	WonderNetID string
+	Kind        string
+	Target      string
+	Events      []string
+	Digest      time.Duration
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// NotificationRepository handles notification channel persistence.
+type NotificationRepository struct {
+	queries database.Queries
+}
+
+// NewNotificationRepository creates a new NotificationRepository.
+func NewNotificationRepository(queries database.Queries) *NotificationRepository {
+	return &NotificationRepository{queries: queries}
+}
+
+// Create creates a new notification channel.
+func (r *NotificationRepository) Create(ctx context.Context, channel *NotificationChannel) (*NotificationChannel, error) {
+	row, err := r.queries.CreateNotificationChannel(ctx, database.CreateNotificationChannelParams{
+		ID:            channel.ID,
+		WonderNetID:   channel.WonderNetID,
+		Kind:          channel.Kind,
+		Target:        channel.Target,
+		Events:        strings.Join(channel.Events, ","),
+		DigestSeconds: int64(channel.Digest / time.Second),
+		CreatedBy:     channel.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return notificationChannelFromRow(row), nil
+}
+
+// Get retrieves a notification channel by ID.
+func (r *NotificationRepository) Get(ctx context.Context, id string) (*NotificationChannel, error) {
+	row, err := r.queries.GetNotificationChannelByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return notificationChannelFromRow(row), nil
+}
+
+// ListByWonderNet lists all notification channels of a wonder net.
+func (r *NotificationRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*NotificationChannel, error) {
+	rows, err := r.queries.ListNotificationChannelsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]*NotificationChannel, len(rows))
+	for i, row := range rows {
+		channels[i] = notificationChannelFromRow(row)
+	}
+	return channels, nil
+}
+
+// List lists notification channels across all wonder nets.
+func (r *NotificationRepository) List(ctx context.Context) ([]*NotificationChannel, error) {
+	rows, err := r.queries.ListNotificationChannels(ctx)
+	if err != nil {
+		return nil, err
+	}
+	channels := make([]*NotificationChannel, len(rows))
+	for i, row := range rows {
+		channels[i] = notificationChannelFromRow(row)
+	}
+	return channels, nil
+}
+
+// Delete deletes a notification channel.
+func (r *NotificationRepository) Delete(ctx context.Context, id string) error {
+	return r.queries.DeleteNotificationChannel(ctx, id)
+}
+
+func notificationChannelFromRow(row database.NotificationChannel) *NotificationChannel {
+	channel := &NotificationChannel{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Kind:        row.Kind,
+		Target:      row.Target,
+		Digest:      time.Duration(row.DigestSeconds) * time.Second,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.Events != "" {
+		channel.Events = strings.Split(row.Events, ",")
+	}
+	return channel
+}
@@ -0,0 +1,166 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Service is a named port on a node that a wonder net publishes to the mesh.
+type Service struct {
+	ID          string
+	WonderNetID string
+	Name        string
+	NodeID      string
+	Port        int
+	Protocol    string
+	CreatedAt   time.Time
+}
+
+// ServiceGrant allows a subject, such as a tag or a node of the same wonder
+// net, to reach a service.
+type ServiceGrant struct {
+	ID        string
+	ServiceID string
+	Subject   string
+	CreatedAt time.Time
+}
+
+// ServiceRepository handles service and service grant persistence.
+type ServiceRepository struct {
+	queries database.Queries
+}
+
+// NewServiceRepository creates a new ServiceRepository.
+func NewServiceRepository(queries database.Queries) *ServiceRepository {
+	return &ServiceRepository{queries: queries}
+}
+
+// Create creates a new service.
+func (r *ServiceRepository) Create(ctx context.Context, svc *Service) (*Service, error) {
+	row, err := r.queries.CreateService(ctx, database.CreateServiceParams{
+		ID:          svc.ID,
+		WonderNetID: svc.WonderNetID,
+		Name:        svc.Name,
+		NodeID:      svc.NodeID,
+		Port:        int32(svc.Port),
+		Protocol:    svc.Protocol,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return serviceFromRow(row), nil
+}
+
+// Get retrieves a service by ID.
+func (r *ServiceRepository) Get(ctx context.Context, id string) (*Service, error) {
+	row, err := r.queries.GetServiceByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return serviceFromRow(row), nil
+}
+
+// ListByWonderNet lists all services of a wonder net, ordered by name.
+func (r *ServiceRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*Service, error) {
+	rows, err := r.queries.ListServicesByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]*Service, len(rows))
+	for i, row := range rows {
+		services[i] = serviceFromRow(row)
+	}
+	return services, nil
+}
+
+// List lists services across all wonder nets.
+func (r *ServiceRepository) List(ctx context.Context) ([]*Service, error) {
+	rows, err := r.queries.ListServices(ctx)
+	if err != nil {
+		return nil, err
+	}
+	services := make([]*Service, len(rows))
+	for i, row := range rows {
+		services[i] = serviceFromRow(row)
+	}
+	return services, nil
+}
+
+// Delete deletes a service and its grants.
+func (r *ServiceRepository) Delete(ctx context.Context, id string) error {
+	if err := r.queries.DeleteServiceGrantsByService(ctx, id); err != nil {
+		return err
+	}
+	return r.queries.DeleteService(ctx, id)
+}
+
+// CreateGrant creates a new service grant.
+func (r *ServiceRepository) CreateGrant(ctx context.Context, grant *ServiceGrant) (*ServiceGrant, error) {
+	row, err := r.queries.CreateServiceGrant(ctx, database.CreateServiceGrantParams{
+		ID:        grant.ID,
+		ServiceID: grant.ServiceID,
+		Subject:   grant.Subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return serviceGrantFromRow(row), nil
+}
+
+// GetGrant retrieves a service grant by ID.
+func (r *ServiceRepository) GetGrant(ctx context.Context, id string) (*ServiceGrant, error) {
+	row, err := r.queries.GetServiceGrantByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return serviceGrantFromRow(row), nil
+}
+
+// ListGrantsByWonderNet lists the grants on all services of a wonder net.
+func (r *ServiceRepository) ListGrantsByWonderNet(ctx context.Context, wonderNetID string) ([]*ServiceGrant, error) {
+	rows, err := r.queries.ListServiceGrantsByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	grants := make([]*ServiceGrant, len(rows))
+	for i, row := range rows {
+		grants[i] = serviceGrantFromRow(row)
+	}
+	return grants, nil
+}
+
+// DeleteGrant deletes a service grant by ID.
+func (r *ServiceRepository) DeleteGrant(ctx context.Context, id string) error {
+	return r.queries.DeleteServiceGrant(ctx, id)
+}
+
+func serviceFromRow(row database.Service) *Service {
+	return &Service{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		Name:        row.Name,
+		NodeID:      row.NodeID,
+		Port:        int(row.Port),
+		Protocol:    row.Protocol,
+		CreatedAt:   row.CreatedAt,
+	}
+}
+
+func serviceGrantFromRow(row database.ServiceGrant) *ServiceGrant {
+	return &ServiceGrant{
+		ID:        row.ID,
+		ServiceID: row.ServiceID,
+		Subject:   row.Subject,
+		CreatedAt: row.CreatedAt,
+	}
+}
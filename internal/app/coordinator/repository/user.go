@@ -0,0 +1,148 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// User providers of the embedded identity provider.
+const (
+	UserProviderLocal  = "local"
+	UserProviderGitHub = "github"
+	UserProviderGoogle = "google"
+)
+
+// User is an account of the embedded identity provider. Subject is the
+// username of local users, who log in with a password, and the account ID
+// at GitHub or Google for users who log in there; Username is their login
+// there. PasswordHash is the bcrypt hash of local users' passwords.
+type User struct {
+	ID           string
+	Provider     string
+	Subject      string
+	Username     string
+	Email        string
+	Name         string
+	PasswordHash string
+	CreatedAt    time.Time
+}
+
+// UserRepository handles embedded identity provider user persistence.
+type UserRepository struct {
+	queries database.Queries
+}
+
+// NewUserRepository creates a new UserRepository.
+func NewUserRepository(queries database.Queries) *UserRepository {
+	return &UserRepository{queries: queries}
+}
+
+// Create creates a new user.
+func (r *UserRepository) Create(ctx context.Context, user *User) (*User, error) {
+	row, err := r.queries.CreateUser(ctx, database.CreateUserParams{
+		ID:           user.ID,
+		Provider:     user.Provider,
+		Subject:      user.Subject,
+		Username:     user.Username,
+		Email:        user.Email,
+		Name:         user.Name,
+		PasswordHash: user.PasswordHash,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return userFromRow(row), nil
+}
+
+// Get retrieves a user by ID.
+func (r *UserRepository) Get(ctx context.Context, id string) (*User, error) {
+	row, err := r.queries.GetUserByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return userFromRow(row), nil
+}
+
+// GetByProviderSubject retrieves the user of a provider's account.
+func (r *UserRepository) GetByProviderSubject(ctx context.Context, provider, subject string) (*User, error) {
+	row, err := r.queries.GetUserByProviderSubject(ctx, database.GetUserByProviderSubjectParams{
+		Provider: provider,
+		Subject:  subject,
+	})
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return userFromRow(row), nil
+}
+
+// ListByEmail lists the users with an email address, oldest first.
+func (r *UserRepository) ListByEmail(ctx context.Context, email string) ([]*User, error) {
+	rows, err := r.queries.ListUsersByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*User, len(rows))
+	for i, row := range rows {
+		users[i] = userFromRow(row)
+	}
+	return users, nil
+}
+
+// List lists all users.
+func (r *UserRepository) List(ctx context.Context) ([]*User, error) {
+	rows, err := r.queries.ListUsers(ctx)
+	if err != nil {
+		return nil, err
+	}
+	users := make([]*User, len(rows))
+	for i, row := range rows {
+		users[i] = userFromRow(row)
+	}
+	return users, nil
+}
+
+// UpdateProfile updates a user's username, email address, and name.
+func (r *UserRepository) UpdateProfile(ctx context.Context, id, username, email, name string) error {
+	return r.queries.UpdateUserProfile(ctx, database.UpdateUserProfileParams{
+		Username: username,
+		Email:    email,
+		Name:     name,
+		ID:       id,
+	})
+}
+
+// UpdatePasswordHash replaces a local user's password hash.
+func (r *UserRepository) UpdatePasswordHash(ctx context.Context, id, passwordHash string) error {
+	return r.queries.UpdateUserPasswordHash(ctx, database.UpdateUserPasswordHashParams{
+		PasswordHash: passwordHash,
+		ID:           id,
+	})
+}
+
+// Delete deletes a user.
+func (r *UserRepository) Delete(ctx context.Context, id string) error {
+	return r.queries.DeleteUser(ctx, id)
+}
+
+func userFromRow(row database.User) *User {
+	return &User{
+		ID:           row.ID,
+		Provider:     row.Provider,
+		Subject:      row.Subject,
+		Username:     row.Username,
+		Email:        row.Email,
+		Name:         row.Name,
+		PasswordHash: row.PasswordHash,
+		CreatedAt:    row.CreatedAt,
+	}
+}
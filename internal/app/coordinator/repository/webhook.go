@@ -0,0 +1,212 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// Webhook delivery statuses. A delivery starts pending and is retried until
+// the endpoint accepts it or it runs out of attempts.
+const (
+	WebhookDeliveryPending   = "pending"
+	WebhookDeliveryDelivered = "delivered"
+	WebhookDeliveryFailed    = "failed"
+)
+
+// Webhook is an endpoint that receives a wonder net's events. Secret signs
+// each delivery. An empty Events list subscribes to every event.
+type Webhook struct {
+	ID          string
+	WonderNetID string
+	URL         string
+	Secret      string
+	Events      []string
+	CreatedBy   string
+	CreatedAt   time.Time
+}
+
+// WebhookDelivery is one event sent, or to be sent, to a webhook. Payload is
+// the JSON request body.
+type WebhookDelivery struct {
+	ID             string
+	WebhookID      string
+	WonderNetID    string
+	Event          string
+	Payload        string
+	Status         string
+	Attempts       int
+	ResponseStatus int
+	LastError      string
+	NextAttemptAt  time.Time
+	CreatedAt      time.Time
+	DeliveredAt    *time.Time
+}
+
+// WebhookRepository handles webhook and webhook delivery persistence.
+type WebhookRepository struct {
+	queries database.Queries
+}
+
+// NewWebhookRepository creates a new WebhookRepository.
+func NewWebhookRepository(queries database.Queries) *WebhookRepository {
+	return &WebhookRepository{queries: queries}
+}
+
+// Create creates a new webhook.
+func (r *WebhookRepository) Create(ctx context.Context, webhook *Webhook) (*Webhook, error) {
+	row, err := r.queries.CreateWebhook(ctx, database.CreateWebhookParams{
+		ID:          webhook.ID,
+		WonderNetID: webhook.WonderNetID,
+		Url:         webhook.URL,
+		Secret:      webhook.Secret,
+		Events:      strings.Join(webhook.Events, ","),
+		CreatedBy:   webhook.CreatedBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return webhookFromRow(row), nil
+}
+
+// Get retrieves a webhook by ID.
+func (r *WebhookRepository) Get(ctx context.Context, id string) (*Webhook, error) {
+	row, err := r.queries.GetWebhookByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return webhookFromRow(row), nil
+}
+
+// ListByWonderNet lists all webhooks of a wonder net.
+func (r *WebhookRepository) ListByWonderNet(ctx context.Context, wonderNetID string) ([]*Webhook, error) {
+	rows, err := r.queries.ListWebhooksByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = webhookFromRow(row)
+	}
+	return webhooks, nil
+}
+
+// List lists webhooks across all wonder nets.
+func (r *WebhookRepository) List(ctx context.Context) ([]*Webhook, error) {
+	rows, err := r.queries.ListWebhooks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	webhooks := make([]*Webhook, len(rows))
+	for i, row := range rows {
+		webhooks[i] = webhookFromRow(row)
+	}
+	return webhooks, nil
+}
+
+// Delete deletes a webhook and its deliveries.
+func (r *WebhookRepository) Delete(ctx context.Context, id string) error {
+	if err := r.queries.DeleteWebhookDeliveriesByWebhook(ctx, id); err != nil {
+		return err
+	}
+	return r.queries.DeleteWebhook(ctx, id)
+}
+
+// CreateDelivery queues a pending delivery, first attempted at NextAttemptAt.
+func (r *WebhookRepository) CreateDelivery(ctx context.Context, delivery *WebhookDelivery) error {
+	return r.queries.CreateWebhookDelivery(ctx, database.CreateWebhookDeliveryParams{
+		ID:            delivery.ID,
+		WebhookID:     delivery.WebhookID,
+		WonderNetID:   delivery.WonderNetID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		NextAttemptAt: delivery.NextAttemptAt.UTC(),
+	})
+}
+
+// ListDueDeliveries lists the pending deliveries whose next attempt is at
+// or before now, oldest first: up to 10 per webhook and 1000 in all.
+func (r *WebhookRepository) ListDueDeliveries(ctx context.Context, now time.Time) ([]*WebhookDelivery, error) {
+	rows, err := r.queries.ListDueWebhookDeliveries(ctx, now.UTC())
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = webhookDeliveryFromRow(row)
+	}
+	return deliveries, nil
+}
+
+// ListDeliveriesByWebhook lists the 100 most recent deliveries of a webhook.
+func (r *WebhookRepository) ListDeliveriesByWebhook(ctx context.Context, webhookID string) ([]*WebhookDelivery, error) {
+	rows, err := r.queries.ListWebhookDeliveriesByWebhook(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	deliveries := make([]*WebhookDelivery, len(rows))
+	for i, row := range rows {
+		deliveries[i] = webhookDeliveryFromRow(row)
+	}
+	return deliveries, nil
+}
+
+// RecordAttempt stores the outcome of a delivery attempt: its status,
+// attempt count, response, and next attempt time.
+func (r *WebhookRepository) RecordAttempt(ctx context.Context, delivery *WebhookDelivery) error {
+	var deliveredAt sql.NullTime
+	if delivery.DeliveredAt != nil {
+		deliveredAt = sql.NullTime{Time: delivery.DeliveredAt.UTC(), Valid: true}
+	}
+	return r.queries.RecordWebhookDeliveryAttempt(ctx, database.RecordWebhookDeliveryAttemptParams{
+		Status:         delivery.Status,
+		Attempts:       int64(delivery.Attempts),
+		ResponseStatus: int64(delivery.ResponseStatus),
+		LastError:      delivery.LastError,
+		NextAttemptAt:  delivery.NextAttemptAt.UTC(),
+		DeliveredAt:    deliveredAt,
+		ID:             delivery.ID,
+	})
+}
+
+func webhookFromRow(row database.Webhook) *Webhook {
+	webhook := &Webhook{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		URL:         row.Url,
+		Secret:      row.Secret,
+		CreatedBy:   row.CreatedBy,
+		CreatedAt:   row.CreatedAt,
+	}
+	if row.Events != "" {
+		webhook.Events = strings.Split(row.Events, ",")
+	}
+	return webhook
+}
+
+func webhookDeliveryFromRow(row database.WebhookDelivery) *WebhookDelivery {
+	delivery := &WebhookDelivery{
+		ID:             row.ID,
+		WebhookID:      row.WebhookID,
+		WonderNetID:    row.WonderNetID,
+		Event:          row.Event,
+		Payload:        row.Payload,
+		Status:         row.Status,
+		Attempts:       int(row.Attempts),
+		ResponseStatus: int(row.ResponseStatus),
+		LastError:      row.LastError,
+		NextAttemptAt:  row.NextAttemptAt,
+		CreatedAt:      row.CreatedAt,
+	}
+	if row.DeliveredAt.Valid {
+		delivery.DeliveredAt = &row.DeliveredAt.Time
+	}
+	return delivery
+}
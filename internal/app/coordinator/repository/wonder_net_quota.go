@@ -0,0 +1,71 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// WonderNetQuota overrides the coordinator's default quotas for one wonder
+// net. A zero limit means unlimited.
+type WonderNetQuota struct {
+	WonderNetID     string
+	MaxNodes        int
+	MaxAPIKeys      int
+	AuthKeysPerHour int
+	UpdatedAt       time.Time
+}
+
+// WonderNetQuotaRepository handles per-wonder-net quota overrides.
+type WonderNetQuotaRepository struct {
+	queries database.Queries
+}
+
+// NewWonderNetQuotaRepository creates a new WonderNetQuotaRepository.
+func NewWonderNetQuotaRepository(queries database.Queries) *WonderNetQuotaRepository {
+	return &WonderNetQuotaRepository{queries: queries}
+}
+
+// Get retrieves the quota override of a wonder net, or nil if it has none.
+func (r *WonderNetQuotaRepository) Get(ctx context.Context, wonderNetID string) (*WonderNetQuota, error) {
+	row, err := r.queries.GetWonderNetQuotas(ctx, wonderNetID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return wonderNetQuotaFromRow(row), nil
+}
+
+// Set creates or replaces the quota override of a wonder net.
+func (r *WonderNetQuotaRepository) Set(ctx context.Context, quota *WonderNetQuota) (*WonderNetQuota, error) {
+	row, err := r.queries.UpsertWonderNetQuotas(ctx, database.UpsertWonderNetQuotasParams{
+		WonderNetID:     quota.WonderNetID,
+		MaxNodes:        int64(quota.MaxNodes),
+		MaxApiKeys:      int64(quota.MaxAPIKeys),
+		AuthkeysPerHour: int64(quota.AuthKeysPerHour),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return wonderNetQuotaFromRow(row), nil
+}
+
+// Delete removes the quota override of a wonder net.
+func (r *WonderNetQuotaRepository) Delete(ctx context.Context, wonderNetID string) error {
+	return r.queries.DeleteWonderNetQuotas(ctx, wonderNetID)
+}
+
+func wonderNetQuotaFromRow(row database.WonderNetQuota) *WonderNetQuota {
+	return &WonderNetQuota{
+		WonderNetID:     row.WonderNetID,
+		MaxNodes:        int(row.MaxNodes),
+		MaxAPIKeys:      int(row.MaxApiKeys),
+		AuthKeysPerHour: int(row.AuthkeysPerHour),
+		UpdatedAt:       row.UpdatedAt,
+	}
+}
@@ -11,15 +11,53 @@ import (
 
 // WonderNet represents a wonder net (project/namespace) in the coordinator.
 type WonderNet struct {
-	ID            string
+	ID               string
+	OwnerID          string
+	HeadscaleUser    string
+	DisplayName      string
+	MeshType         string
+	IsDefault        bool
+	NodeNameTemplate string
+	ACLRules         string
+	PublicURL        string
+	// RequireNodeApproval holds newly registered nodes in a pending state
+	// until the owner approves them.
+	RequireNodeApproval bool
+	// NodeExpiryDays removes nodes that have been offline for longer than
+	// this many days. Zero keeps offline nodes.
+	NodeExpiryDays int
+	// JoinTokenDefaultTTL and JoinTokenMaxTTL are how long join tokens of
+	// the wonder net are valid by default and at most. Zero uses the
+	// coordinator's defaults.
+	JoinTokenDefaultTTL time.Duration
+	JoinTokenMaxTTL     time.Duration
+	CreatedAt           time.Time
+	UpdatedAt           time.Time
+}
+
+// WonderNetCursor is a position in the (created_at, id) ordering used by ListPage.
+type WonderNetCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// WonderNetListOptions filters and orders a page of wonder nets.
+// Zero-valued fields do not filter.
+type WonderNetListOptions struct {
 	OwnerID       string
-	HeadscaleUser string
-	DisplayName   string
 	MeshType      string
-	CreatedAt     time.Time
-	UpdatedAt     time.Time
+	CreatedAfter  time.Time
+	CreatedBefore time.Time
+	Ascending     bool
+	After         *WonderNetCursor
+	Limit         int
 }
 
+var (
+	minCreatedAt = time.Unix(0, 0).UTC()
+	maxCreatedAt = time.Date(9999, 12, 31, 0, 0, 0, 0, time.UTC)
+)
+
 // WonderNetRepository provides wonder net storage operations.
 type WonderNetRepository struct {
 	queries database.Queries
@@ -86,7 +124,8 @@ func (r *WonderNetRepository) Update(ctx context.Context, wn *WonderNet) error {
 	})
 }
 
-// Delete deletes a wonder net.
+// Delete deletes a wonder net and, through the foreign keys, every record
+// referencing it.
 func (r *WonderNetRepository) Delete(ctx context.Context, id string) error {
 	return r.queries.DeleteWonderNet(ctx, id)
 }
@@ -104,14 +143,235 @@ func (r *WonderNetRepository) List(ctx context.Context) ([]*WonderNet, error) {
 	return wonderNets, nil
 }
 
+// ListPage lists up to opts.Limit wonder nets after opts.After, ordered by
+// creation time and then ID.
+func (r *WonderNetRepository) ListPage(ctx context.Context, opts WonderNetListOptions) ([]*WonderNet, error) {
+	params := database.ListWonderNetsPageParams{
+		OwnerID:       opts.OwnerID,
+		MeshType:      opts.MeshType,
+		CreatedAfter:  minCreatedAt,
+		CreatedBefore: maxCreatedAt,
+		Limit:         int32(opts.Limit),
+		Ascending:     opts.Ascending,
+	}
+	if !opts.CreatedAfter.IsZero() {
+		params.CreatedAfter = opts.CreatedAfter.UTC()
+	}
+	if !opts.CreatedBefore.IsZero() {
+		params.CreatedBefore = opts.CreatedBefore.UTC()
+	}
+
+	switch {
+	case opts.After != nil:
+		params.CursorCreatedAt = opts.After.CreatedAt.UTC()
+		params.CursorID = opts.After.ID
+	case opts.Ascending:
+		params.CursorCreatedAt = minCreatedAt
+	default:
+		params.CursorCreatedAt = maxCreatedAt
+	}
+
+	rows, err := r.queries.ListWonderNetsPage(ctx, params)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// GetDefaultByOwner retrieves the wonder net marked as default for an owner.
+func (r *WonderNetRepository) GetDefaultByOwner(ctx context.Context, ownerID string) (*WonderNet, error) {
+	row, err := r.queries.GetDefaultWonderNetByOwner(ctx, ownerID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dbWonderNetToWonderNet(row), nil
+}
+
+// SetDefault marks a wonder net as the owner's default and clears the flag
+// on the owner's other wonder nets.
+func (r *WonderNetRepository) SetDefault(ctx context.Context, ownerID, id string) error {
+	return r.queries.SetDefaultWonderNet(ctx, database.SetDefaultWonderNetParams{
+		ID:      id,
+		OwnerID: ownerID,
+	})
+}
+
+// UpdateNodeNameTemplate sets the node name template of a wonder net.
+func (r *WonderNetRepository) UpdateNodeNameTemplate(ctx context.Context, id, template string) error {
+	return r.queries.UpdateWonderNetNodeNameTemplate(ctx, database.UpdateWonderNetNodeNameTemplateParams{
+		NodeNameTemplate: template,
+		ID:               id,
+	})
+}
+
+// UpdateACLRules sets the JSON-encoded ACL rules of a wonder net.
+func (r *WonderNetRepository) UpdateACLRules(ctx context.Context, id, rules string) error {
+	return r.queries.UpdateWonderNetACLRules(ctx, database.UpdateWonderNetACLRulesParams{
+		ACLRules: rules,
+		ID:       id,
+	})
+}
+
+// ListWithACLRules lists the wonder nets that have ACL rules.
+func (r *WonderNetRepository) ListWithACLRules(ctx context.Context) ([]*WonderNet, error) {
+	rows, err := r.queries.ListWonderNetsWithACLRules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// UpdatePublicURL sets the custom public URL of a wonder net.
+func (r *WonderNetRepository) UpdatePublicURL(ctx context.Context, id, publicURL string) error {
+	return r.queries.UpdateWonderNetPublicURL(ctx, database.UpdateWonderNetPublicURLParams{
+		PublicURL: publicURL,
+		ID:        id,
+	})
+}
+
+// GetByPublicURL retrieves the wonder net with the given custom public URL.
+func (r *WonderNetRepository) GetByPublicURL(ctx context.Context, publicURL string) (*WonderNet, error) {
+	row, err := r.queries.GetWonderNetByPublicURL(ctx, publicURL)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return dbWonderNetToWonderNet(row), nil
+}
+
+// ListWithPublicURL lists the wonder nets that have a custom public URL.
+func (r *WonderNetRepository) ListWithPublicURL(ctx context.Context) ([]*WonderNet, error) {
+	rows, err := r.queries.ListWonderNetsWithPublicURL(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// UpdateRequireNodeApproval sets whether new nodes of a wonder net need
+// approval.
+func (r *WonderNetRepository) UpdateRequireNodeApproval(ctx context.Context, id string, require bool) error {
+	return r.queries.UpdateWonderNetRequireNodeApproval(ctx, database.UpdateWonderNetRequireNodeApprovalParams{
+		RequireNodeApproval: require,
+		ID:                  id,
+	})
+}
+
+// ListWithNodeNameTemplate lists the wonder nets that have a node name
+// template.
+func (r *WonderNetRepository) ListWithNodeNameTemplate(ctx context.Context) ([]*WonderNet, error) {
+	rows, err := r.queries.ListWonderNetsWithNodeNameTemplate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// ListRequiringNodeApproval lists the wonder nets whose new nodes need
+// approval.
+func (r *WonderNetRepository) ListRequiringNodeApproval(ctx context.Context) ([]*WonderNet, error) {
+	rows, err := r.queries.ListWonderNetsRequiringNodeApproval(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// UpdateNodeExpiryDays sets after how many days offline the nodes of a
+// wonder net are removed.
+func (r *WonderNetRepository) UpdateNodeExpiryDays(ctx context.Context, id string, days int) error {
+	return r.queries.UpdateWonderNetNodeExpiryDays(ctx, database.UpdateWonderNetNodeExpiryDaysParams{
+		NodeExpiryDays: int64(days),
+		ID:             id,
+	})
+}
+
+// UpdateJoinTokenTTL sets the default and maximum TTL of the join tokens of
+// a wonder net.
+func (r *WonderNetRepository) UpdateJoinTokenTTL(ctx context.Context, id string, defaultTTL, maxTTL time.Duration) error {
+	return r.queries.UpdateWonderNetJoinTokenTTL(ctx, database.UpdateWonderNetJoinTokenTTLParams{
+		JoinTokenDefaultTTLSeconds: int64(defaultTTL / time.Second),
+		JoinTokenMaxTTLSeconds:     int64(maxTTL / time.Second),
+		ID:                         id,
+	})
+}
+
+// ListWithNodeExpiry lists the wonder nets that remove long offline nodes.
+func (r *WonderNetRepository) ListWithNodeExpiry(ctx context.Context) ([]*WonderNet, error) {
+	rows, err := r.queries.ListWonderNetsWithNodeExpiry(ctx)
+	if err != nil {
+		return nil, err
+	}
+	wonderNets := make([]*WonderNet, len(rows))
+	for i, row := range rows {
+		wonderNets[i] = dbWonderNetToWonderNet(row)
+	}
+	return wonderNets, nil
+}
+
+// Count returns the number of wonder nets.
+func (r *WonderNetRepository) Count(ctx context.Context) (int, error) {
+	count, err := r.queries.CountWonderNets(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return int(count), nil
+}
+
+// CountByMeshType returns the number of wonder nets per mesh type.
+func (r *WonderNetRepository) CountByMeshType(ctx context.Context) (map[string]int, error) {
+	rows, err := r.queries.CountWonderNetsByMeshType(ctx)
+	if err != nil {
+		return nil, err
+	}
+	counts := make(map[string]int, len(rows))
+	for _, row := range rows {
+		counts[row.MeshType] = int(row.Count)
+	}
+	return counts, nil
+}
+
 func dbWonderNetToWonderNet(row database.WonderNet) *WonderNet {
 	return &WonderNet{
-		ID:            row.ID,
-		OwnerID:       row.OwnerID,
-		HeadscaleUser: row.HeadscaleUser,
-		DisplayName:   row.DisplayName,
-		MeshType:      row.MeshType,
-		CreatedAt:     row.CreatedAt,
-		UpdatedAt:     row.UpdatedAt,
+		ID:                  row.ID,
+		OwnerID:             row.OwnerID,
+		HeadscaleUser:       row.HeadscaleUser,
+		DisplayName:         row.DisplayName,
+		MeshType:            row.MeshType,
+		IsDefault:           row.IsDefault,
+		NodeNameTemplate:    row.NodeNameTemplate,
+		ACLRules:            row.ACLRules,
+		PublicURL:           row.PublicURL,
+		RequireNodeApproval: row.RequireNodeApproval,
+		NodeExpiryDays:      int(row.NodeExpiryDays),
+		JoinTokenDefaultTTL: time.Duration(row.JoinTokenDefaultTTLSeconds) * time.Second,
+		JoinTokenMaxTTL:     time.Duration(row.JoinTokenMaxTTLSeconds) * time.Second,
+		CreatedAt:           row.CreatedAt,
+		UpdatedAt:           row.UpdatedAt,
 	}
 }
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+)
+
+// WorkerToken is the record of an issued worker token, keyed by the token's
+// jti claim. AuthKeyID is the mesh authkey last issued with the token, and
+// NodeID the node the token is bound to once the worker joined with it.
+type WorkerToken struct {
+	ID          string
+	WonderNetID string
+	AuthKeyID   string
+	NodeID      string
+	CreatedAt   time.Time
+	ExpiresAt   time.Time
+	LastUsedAt  *time.Time
+	RevokedAt   *time.Time
+}
+
+// WorkerTokenRepository handles worker token persistence.
+type WorkerTokenRepository struct {
+	queries database.Queries
+}
+
+// NewWorkerTokenRepository creates a new WorkerTokenRepository.
+func NewWorkerTokenRepository(queries database.Queries) *WorkerTokenRepository {
+	return &WorkerTokenRepository{queries: queries}
+}
+
+// Create records a newly issued worker token.
+func (r *WorkerTokenRepository) Create(ctx context.Context, token *WorkerToken) error {
+	return r.queries.CreateWorkerToken(ctx, database.CreateWorkerTokenParams{
+		ID:          token.ID,
+		WonderNetID: token.WonderNetID,
+		AuthKeyID:   token.AuthKeyID,
+		ExpiresAt:   token.ExpiresAt.UTC(),
+	})
+}
+
+// Get retrieves a worker token by its jti.
+func (r *WorkerTokenRepository) Get(ctx context.Context, id string) (*WorkerToken, error) {
+	row, err := r.queries.GetWorkerToken(ctx, id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	token := &WorkerToken{
+		ID:          row.ID,
+		WonderNetID: row.WonderNetID,
+		AuthKeyID:   row.AuthKeyID,
+		NodeID:      row.NodeID,
+		CreatedAt:   row.CreatedAt,
+		ExpiresAt:   row.ExpiresAt,
+	}
+	if row.LastUsedAt.Valid {
+		token.LastUsedAt = &row.LastUsedAt.Time
+	}
+	if row.RevokedAt.Valid {
+		token.RevokedAt = &row.RevokedAt.Time
+	}
+	return token, nil
+}
+
+// Touch records a use of the token, binds it to nodeID, and moves its
+// expiry to expiresAt.
+func (r *WorkerTokenRepository) Touch(ctx context.Context, id, nodeID string, expiresAt time.Time) error {
+	return r.queries.TouchWorkerToken(ctx, database.TouchWorkerTokenParams{
+		NodeID:    nodeID,
+		ExpiresAt: expiresAt.UTC(),
+		ID:        id,
+	})
+}
+
+// UpdateAuthKey records the mesh authkey last issued with the token.
+func (r *WorkerTokenRepository) UpdateAuthKey(ctx context.Context, id, authKeyID string) error {
+	return r.queries.UpdateWorkerTokenAuthKey(ctx, database.UpdateWorkerTokenAuthKeyParams{
+		AuthKeyID: authKeyID,
+		ID:        id,
+	})
+}
+
+// RevokeByNode revokes the worker tokens of a wonder net that are bound to
+// the node, or were issued with the authkey it joined with.
+func (r *WorkerTokenRepository) RevokeByNode(ctx context.Context, wonderNetID, nodeID, authKeyID string) error {
+	return r.queries.RevokeWorkerTokensByNode(ctx, database.RevokeWorkerTokensByNodeParams{
+		WonderNetID: wonderNetID,
+		NodeID:      nodeID,
+		AuthKeyID:   authKeyID,
+	})
+}
@@ -2,20 +2,31 @@ package coordinator
 
 import (
 	"context"
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/sha256"
 	"crypto/subtle"
+	"errors"
 	"fmt"
 	"log/slog"
+	"net"
 	"net/http"
+	"net/netip"
 	"net/url"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
+	"sync"
 	"syscall"
 	"time"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/controller"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/database"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/fixtures"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/meshproxy"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/metrics"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/service"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/webui"
@@ -24,32 +35,99 @@ import (
 	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
 	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend/tailnet"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend/tailscale"
+	"github.com/strrl/wonder-mesh-net/pkg/oidc"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials/insecure"
 )
 
-const minJWTSecretLength = 32
+const (
+	minJWTSecretLength       = 32
+	alertEvaluationInterval  = time.Minute
+	nodeNamingInterval       = 30 * time.Second
+	nodeApprovalInterval     = 10 * time.Second
+	serviceReconcileInterval = time.Minute
+	accessExpiryInterval     = 30 * time.Second
+	nodeWipeExpiryInterval   = time.Minute
+	nodeExpiryInterval       = time.Hour
+	webhookDeliveryInterval  = 10 * time.Second
+	notificationInterval     = time.Minute
+	nodeWatchInterval        = 10 * time.Second
+	apiKeyUsageFlushInterval = 10 * time.Second
+	dnsSyncInterval          = time.Minute
+	execSessionPurgeInterval = time.Hour
+
+	// shutdownTimeout bounds how long in-flight requests and background
+	// tasks are waited for on SIGINT or SIGTERM.
+	shutdownTimeout = 10 * time.Second
+
+	// Headscale calls fail fast for headscaleBreakerCooldown after
+	// headscaleBreakerThreshold consecutive connection failures.
+	headscaleBreakerThreshold = 5
+	headscaleBreakerCooldown  = 10 * time.Second
+)
 
 // Server is the coordinator server that manages multi-tenant wonder net access.
 type Server struct {
-	config          *Config
-	db              *database.Manager
-	headscaleConn   *grpc.ClientConn
-	headscaleClient v1.HeadscaleServiceClient
+	config   *Config
+	configMu sync.RWMutex
+	reloadMu sync.Mutex
+	// pendingRestart lists the settings whose reloaded value differs from
+	// the running one but only takes effect after a restart.
+	pendingRestart []string
+
+	db *database.Manager
+	// headscaleConn and headscaleClient are nil with the Tailscale control
+	// plane.
+	headscaleConn    *grpc.ClientConn
+	headscaleClient  v1.HeadscaleServiceClient
+	headscaleBreaker *headscale.CircuitBreaker
 
 	jwtValidator *jwtauth.Validator
 	oidcService  *service.OIDCService
+	// embeddedAuthService is the identity provider in the embedded auth
+	// mode, nil with Keycloak.
+	embeddedAuthService *service.EmbeddedAuthService
+
+	// trustedProxies are the networks of reverse proxies whose
+	// X-Forwarded-For header is used to find the client address.
+	trustedProxies []netip.Prefix
+	// corsPolicy lets browsers on other origins call the API; nil
+	// disables CORS.
+	corsPolicy *corsPolicy
 
 	meshBackend meshbackend.MeshBackend
 
 	wonderNetRepository *repository.WonderNetRepository
 	apiKeyRepository    *repository.APIKeyRepository
-
-	wonderNetService *service.WonderNetService
-	workerService    *service.WorkerService
-	nodesService     *service.NodesService
-	apiKeyService    *service.APIKeyService
+	alertRepository     *repository.AlertRepository
+	serviceRepository   *repository.ServiceRepository
+	accessRequestRepo   *repository.AccessRequestRepository
+
+	wonderNetService      *service.WonderNetService
+	workerService         *service.WorkerService
+	nodesService          *service.NodesService
+	apiKeyService         *service.APIKeyService
+	apiKeyUsageRecorder   *service.APIKeyUsageRecorder
+	alertService          *service.AlertService
+	nodeNamingService     *service.NodeNamingService
+	nodeApprovalService   *service.NodeApprovalService
+	serviceCatalogService *service.ServiceCatalogService
+	accessRequestService  *service.AccessRequestService
+	decommissionService   *service.NodeDecommissionService
+	nodeExpiryService     *service.NodeExpiryService
+	memberService         *service.MemberService
+	statsService          *service.StatsService
+	routesService         *service.RoutesService
+	webhookService        *service.WebhookService
+	notificationService   *service.NotificationService
+	nodeWatcher           *service.NodeWatcher
+	quotaService          *service.QuotaService
+	dnsService            *service.DNSService
+	execSessionService    *service.ExecSessionService
+	introspectionService  *service.TokenIntrospectionService
+	sshCAService          *service.SSHCAService
 }
 
 // BootstrapNewServer creates a new coordinator server.
@@ -57,6 +135,30 @@ func BootstrapNewServer(config *Config) (*Server, error) {
 	if len(config.JWTSecret) < minJWTSecretLength {
 		return nil, fmt.Errorf("JWT secret must be at least %d bytes", minJWTSecretLength)
 	}
+	if config.AuthMode != "" && config.AuthMode != AuthModeKeycloak && config.AuthMode != AuthModeEmbedded {
+		return nil, fmt.Errorf("unknown auth mode %q, want %s or %s", config.AuthMode, AuthModeKeycloak, AuthModeEmbedded)
+	}
+	if config.MeshBackend != "" && config.MeshBackend != MeshBackendHeadscale && config.MeshBackend != MeshBackendTailscale {
+		return nil, fmt.Errorf("unknown mesh backend %q, want %s or %s", config.MeshBackend, MeshBackendHeadscale, MeshBackendTailscale)
+	}
+	if config.ExecSessionRetentionDays < 0 || config.ExecSessionMaxOutputBytes < 0 {
+		return nil, errors.New("exec session retention and output limit must not be negative")
+	}
+	if config.RequestTimeout < 0 || config.AdminRequestTimeout < 0 {
+		return nil, errors.New("request timeouts must not be negative")
+	}
+	if config.NodeOfflineAfter < 0 {
+		return nil, errors.New("node offline threshold must not be negative")
+	}
+
+	trustedProxies, err := service.ParseCIDRs(config.TrustedProxies)
+	if err != nil {
+		return nil, fmt.Errorf("parse trusted proxies: %w", err)
+	}
+	corsPolicy, err := newCORSPolicy(config.CORSAllowedOrigins, config.CORSAllowCredentials, config.CORSMaxAge)
+	if err != nil {
+		return nil, err
+	}
 
 	if err := os.MkdirAll(DefaultCoordinatorDataDir, 0755); err != nil {
 		return nil, fmt.Errorf("create coordinator data dir: %w", err)
@@ -77,8 +179,12 @@ func BootstrapNewServer(config *Config) (*Server, error) {
 	}
 
 	db, err := database.NewManager(database.Config{
-		Driver: driver,
-		DSN:    dsn,
+		Driver:          driver,
+		DSN:             dsn,
+		MaxOpenConns:    config.DatabaseMaxOpenConns,
+		MaxIdleConns:    config.DatabaseMaxIdleConns,
+		ConnMaxLifetime: config.DatabaseConnMaxLifetime,
+		ConnMaxIdleTime: config.DatabaseConnMaxIdleTime,
 	})
 	if err != nil {
 		return nil, fmt.Errorf("initialize database: %w", err)
@@ -88,16 +194,44 @@ func BootstrapNewServer(config *Config) (*Server, error) {
 	ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
 	defer cancel()
 
-	slog.Info("connecting to Headscale", "socket", config.HeadscaleUnixSocket)
-	headscaleConn, err := grpc.NewClient(
-		"unix://"+config.HeadscaleUnixSocket,
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
-	)
-	if err != nil {
-		_ = db.Close()
-		return nil, fmt.Errorf("connect to headscale: %w", err)
+	headscaleBreaker := headscale.NewCircuitBreaker(headscaleBreakerThreshold, headscaleBreakerCooldown)
+	var headscaleConn *grpc.ClientConn
+	var headscaleClient v1.HeadscaleServiceClient
+	var wonderNetManager *headscale.WonderNetManager
+	var aclManager *headscale.ACLManager
+	var realms service.RealmManager
+	var meshBackend meshbackend.MeshBackend
+
+	if config.MeshBackend == MeshBackendTailscale {
+		// Create mesh backend (Tailscale control plane), which also keeps
+		// the isolation policy of the wonder nets
+		slog.Info("using the Tailscale control plane", "tailnet", config.TailscaleTailnet)
+		tailnetMesh := tailnet.NewTailnetMesh(tailnet.NewClient(config.TailscaleAPIURL, config.TailscaleTailnet, config.TailscaleAPIKey), "")
+		realms = tailnetMesh
+		meshBackend = tailnetMesh
+	} else {
+		slog.Info("connecting to Headscale", "socket", config.HeadscaleUnixSocket)
+		headscaleConn, err = grpc.NewClient(
+			"unix://"+config.HeadscaleUnixSocket,
+			grpc.WithTransportCredentials(insecure.NewCredentials()),
+			grpc.WithChainUnaryInterceptor(
+				headscale.UnaryClientInterceptor(headscale.DefaultRetryPolicy, headscaleBreaker),
+				metrics.UnaryClientInterceptor(),
+			),
+		)
+		if err != nil {
+			_ = db.Close()
+			return nil, fmt.Errorf("connect to headscale: %w", err)
+		}
+		headscaleClient = v1.NewHeadscaleServiceClient(headscaleConn)
+
+		// Create Headscale managers
+		wonderNetManager = headscale.NewWonderNetManager(headscaleClient)
+		aclManager = headscale.NewACLManager(headscaleClient)
+
+		// Create mesh backend (Tailscale via Headscale)
+		meshBackend = tailscale.NewTailscaleMesh(headscaleClient, config.PublicURL)
 	}
-	headscaleClient := v1.NewHeadscaleServiceClient(headscaleConn)
 
 	// Create token generator for join tokens
 	tokenGenerator := jointoken.NewGenerator(config.JWTSecret, config.PublicURL)
@@ -105,60 +239,255 @@ func BootstrapNewServer(config *Config) (*Server, error) {
 	// Create repositories
 	wonderNetRepository := repository.NewWonderNetRepository(db.Queries())
 	apiKeyRepository := repository.NewAPIKeyRepository(db.Queries())
-
-	// Create Headscale managers
-	wonderNetManager := headscale.NewWonderNetManager(headscaleClient)
-	aclManager := headscale.NewACLManager(headscaleClient)
-
-	// Create mesh backend (Tailscale via Headscale)
-	meshBackend := tailscale.NewTailscaleMesh(headscaleClient, config.PublicURL)
+	alertRepository := repository.NewAlertRepository(db.Queries())
+	serviceRepository := repository.NewServiceRepository(db.Queries())
+	accessRequestRepo := repository.NewAccessRequestRepository(db.Queries())
+	nodeHeartbeatRepo := repository.NewNodeHeartbeatRepository(db.Queries())
+	decommissionRepo := repository.NewNodeDecommissionRepository(db.Queries())
+	nodeApprovalRepo := repository.NewNodeApprovalRepository(db.Queries())
+	nodeNameRepo := repository.NewNodeNameRepository(db.Queries())
+	memberRepo := repository.NewMemberRepository(db.Queries())
+	joinTokenRepo := repository.NewJoinTokenRepository(db.Queries())
+	workerTokenRepo := repository.NewWorkerTokenRepository(db.Queries())
+	webhookRepo := repository.NewWebhookRepository(db.Queries())
+	quotaRepo := repository.NewWonderNetQuotaRepository(db.Queries())
+	dnsRepo := repository.NewDNSRepository(db.Queries())
+	notificationRepo := repository.NewNotificationRepository(db.Queries())
+	execSessionRepo := repository.NewExecSessionRepository(db.Queries())
+
+	var nodeCounters []metrics.NodeCounter
+	if config.Fixtures {
+		slog.Warn("fixtures enabled: populating synthetic wonder nets and nodes, do not use in production",
+			"wonder_nets", config.FixtureWonderNets, "nodes_per_wonder_net", config.FixtureNodesPerWonderNet)
+		created, err := fixtures.Seed(ctx, wonderNetRepository, config.FixtureWonderNets)
+		if err != nil {
+			closeConn(headscaleConn)
+			_ = db.Close()
+			return nil, fmt.Errorf("seed fixtures: %w", err)
+		}
+		slog.Info("fixture wonder nets seeded", "created", created)
+		fixtureMesh := fixtures.NewMesh(meshBackend, config.FixtureWonderNets, config.FixtureNodesPerWonderNet)
+		meshBackend = fixtureMesh
+		nodeCounters = append(nodeCounters, fixtureMesh)
+	}
 
 	// Create services
-	wonderNetService := service.NewWonderNetService(wonderNetRepository, wonderNetManager, aclManager, config.PublicURL, config.PrivilegedNetworks, config.UseTaggedACL, config.StrictPrivilegedTags)
-	workerService := service.NewWorkerService(tokenGenerator, config.JWTSecret, wonderNetRepository, meshBackend)
-	nodesService := service.NewNodesService(meshBackend)
-	apiKeyService := service.NewAPIKeyService(apiKeyRepository, wonderNetRepository)
-
-	// Create JWT validator for Keycloak tokens
-	jwksURL := fmt.Sprintf("%s/realms/%s/protocol/openid-connect/certs", config.KeycloakURL, config.KeycloakRealm)
-	issuer := fmt.Sprintf("%s/realms/%s", config.KeycloakURL, config.KeycloakRealm)
-	jwtValidator := jwtauth.NewValidator(jwtauth.ValidatorConfig{
-		JWKSURL:         jwksURL,
-		Issuer:          issuer,
-		Audience:        config.KeycloakClientID,
-		RefreshInterval: 5 * time.Minute,
+	wonderNetService := service.NewWonderNetService(wonderNetRepository, memberRepo, wonderNetManager, aclManager, config.PublicURL, config.PrivilegedNetworks, config.UseTaggedACL, config.StrictPrivilegedTags, realms)
+	nodesService := service.NewNodesService(meshBackend, nodeHeartbeatRepo, workerTokenRepo)
+	webhookService := service.NewWebhookService(webhookRepo, config.WebhookAllowHTTP)
+	notificationService := service.NewNotificationService(notificationRepo, wonderNetRepository, apiKeyRepository, service.SMTPConfig{
+		Addr:     config.SMTPAddr,
+		Username: config.SMTPUsername,
+		Password: config.SMTPPassword,
+		From:     config.SMTPFrom,
 	})
+	nodeWatcher := service.NewNodeWatcher(wonderNetRepository, nodesService, config.NodeOfflineAfter, webhookService, notificationService)
+	quotaService := service.NewQuotaService(quotaRepo, apiKeyRepository, meshBackend, quotaDefaults(config))
+	sshCAService := service.NewSSHCAService(config.JWTSecret)
+	workerService := service.NewWorkerService(tokenGenerator, config.JWTSecret, wonderNetRepository, nodeHeartbeatRepo, joinTokenRepo, workerTokenRepo, decommissionRepo, meshBackend, webhookService, quotaService, sshCAService)
+	apiKeyUsageRecorder := service.NewAPIKeyUsageRecorder(apiKeyRepository, apiKeyUsageFlushInterval)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, wonderNetRepository, quotaService, apiKeyUsageRecorder)
+	alertService := service.NewAlertService(alertRepository, wonderNetRepository, nodesService, service.AlertNotifiers{service.LogAlertNotifier{}, webhookService, notificationService})
+	nodeNamingService := service.NewNodeNamingService(wonderNetRepository, nodeNameRepo, meshBackend)
+	nodeApprovalService := service.NewNodeApprovalService(wonderNetRepository, nodeApprovalRepo, meshBackend)
+	serviceCatalogService := service.NewServiceCatalogService(serviceRepository, wonderNetRepository, meshBackend, aclManager)
+	accessRequestService := service.NewAccessRequestService(accessRequestRepo, wonderNetRepository, serviceCatalogService)
+	decommissionService := service.NewNodeDecommissionService(decommissionRepo, nodeHeartbeatRepo, workerTokenRepo, serviceCatalogService, meshBackend)
+	nodeExpiryService := service.NewNodeExpiryService(wonderNetRepository, nodesService, decommissionService, webhookService)
+	statsService := service.NewStatsService(nodesService, alertService, apiKeyRepository, serviceRepository, accessRequestRepo)
+	routesService := service.NewRoutesService(meshBackend)
+	dnsService := service.NewDNSService(dnsRepo, wonderNetRepository, nodesService, config.DNSRecordsPath, config.DNSBaseDomain)
+	execSessionService := service.NewExecSessionService(execSessionRepo, time.Duration(config.ExecSessionRetentionDays)*24*time.Hour, config.ExecSessionMaxOutputBytes)
+	introspectionService := service.NewTokenIntrospectionService(apiKeyRepository, joinTokenRepo, config.JWTSecret)
+
+	var embeddedAuthService *service.EmbeddedAuthService
+	var embeddedAuthSigner *jwtauth.Signer
+	if config.AuthMode == AuthModeEmbedded {
+		embeddedAuthSigner, err = newEmbeddedAuthSigner(config)
+		if err != nil {
+			closeConn(headscaleConn)
+			_ = db.Close()
+			return nil, err
+		}
+		embeddedAuthService = service.NewEmbeddedAuthService(repository.NewUserRepository(db.Queries()), embeddedAuthSigner, loginProviders(config), config.AuthAllowedEmails)
+		slog.Info("embedded identity provider enabled", "issuer", embeddedAuthSigner.Issuer(), "providers", embeddedAuthService.Providers())
+	}
+
+	// Create JWT validator for Keycloak tokens, or those of the embedded
+	// identity provider
+	validatorConfig, err := jwtValidatorConfig(config, embeddedAuthSigner)
+	if err != nil {
+		closeConn(headscaleConn)
+		_ = db.Close()
+		return nil, err
+	}
+	jwtValidator := jwtauth.NewValidator(validatorConfig)
 
 	if err := jwtValidator.Start(ctx); err != nil {
-		_ = headscaleConn.Close()
+		closeConn(headscaleConn)
 		_ = db.Close()
 		return nil, fmt.Errorf("start JWT validator: %w", err)
 	}
-	slog.Info("JWT validator started", "jwks_url", jwksURL)
+	slog.Info("JWT validator started", "jwks_url", validatorConfig.JWKSURL)
+
+	if err := metrics.RegisterInventory(wonderNetService, headscaleClient, nodeCounters...); err != nil {
+		closeConn(headscaleConn)
+		_ = db.Close()
+		return nil, fmt.Errorf("register inventory metrics: %w", err)
+	}
 
-	oidcService := service.NewOIDCService(service.OIDCConfig{
+	oidcService := service.NewOIDCService(oidcConfig(config), jwtValidator)
+	var users service.UserDirectory = oidcService
+	if embeddedAuthService != nil {
+		users = embeddedAuthService
+	}
+	memberService := service.NewMemberService(memberRepo, wonderNetRepository, users)
+
+	return &Server{
+		config:                config,
+		db:                    db,
+		headscaleConn:         headscaleConn,
+		headscaleClient:       headscaleClient,
+		headscaleBreaker:      headscaleBreaker,
+		jwtValidator:          jwtValidator,
+		oidcService:           oidcService,
+		embeddedAuthService:   embeddedAuthService,
+		trustedProxies:        trustedProxies,
+		corsPolicy:            corsPolicy,
+		meshBackend:           meshBackend,
+		wonderNetRepository:   wonderNetRepository,
+		apiKeyRepository:      apiKeyRepository,
+		alertRepository:       alertRepository,
+		serviceRepository:     serviceRepository,
+		accessRequestRepo:     accessRequestRepo,
+		wonderNetService:      wonderNetService,
+		workerService:         workerService,
+		nodesService:          nodesService,
+		apiKeyService:         apiKeyService,
+		apiKeyUsageRecorder:   apiKeyUsageRecorder,
+		alertService:          alertService,
+		nodeNamingService:     nodeNamingService,
+		nodeApprovalService:   nodeApprovalService,
+		serviceCatalogService: serviceCatalogService,
+		accessRequestService:  accessRequestService,
+		decommissionService:   decommissionService,
+		nodeExpiryService:     nodeExpiryService,
+		memberService:         memberService,
+		statsService:          statsService,
+		routesService:         routesService,
+		webhookService:        webhookService,
+		notificationService:   notificationService,
+		nodeWatcher:           nodeWatcher,
+		quotaService:          quotaService,
+		dnsService:            dnsService,
+		execSessionService:    execSessionService,
+		introspectionService:  introspectionService,
+		sshCAService:          sshCAService,
+	}, nil
+}
+
+// quotaDefaults returns the quotas of wonder nets without an override.
+func quotaDefaults(config *Config) service.Quotas {
+	return service.Quotas{
+		MaxNodes:        config.QuotaMaxNodes,
+		MaxAPIKeys:      config.QuotaMaxAPIKeys,
+		AuthKeysPerHour: config.QuotaAuthKeysPerHour,
+	}
+}
+
+// jwtValidatorConfig returns the settings for validating tokens issued by
+// the configured Keycloak realm, or by embeddedAuthSigner, the signer of
+// the embedded identity provider, if it is set.
+func jwtValidatorConfig(config *Config, embeddedAuthSigner *jwtauth.Signer) (jwtauth.ValidatorConfig, error) {
+	if embeddedAuthSigner != nil {
+		return jwtauth.ValidatorConfig{
+			KeySet:   embeddedAuthSigner.KeySet(),
+			Issuer:   embeddedAuthSigner.Issuer(),
+			Audience: service.EmbeddedAuthAudience,
+		}, nil
+	}
+
+	issuers, err := trustedIssuers(config)
+	if err != nil {
+		return jwtauth.ValidatorConfig{}, err
+	}
+	return jwtauth.ValidatorConfig{
+		JWKSURL:         keycloakJWKSURL(fmt.Sprintf("%s/realms/%s", config.KeycloakURL, config.KeycloakRealm)),
+		Issuer:          fmt.Sprintf("%s/realms/%s", config.KeycloakURL, config.KeycloakRealm),
+		Audience:        config.KeycloakClientID,
+		Audiences:       config.KeycloakAudiences,
+		Issuers:         issuers,
+		RefreshInterval: 5 * time.Minute,
+	}, nil
+}
+
+// trustedIssuers parses the additional trusted issuers, given as an issuer
+// URL or "issuer=jwks_url". They accept the same audiences as the realm.
+func trustedIssuers(config *Config) ([]jwtauth.IssuerConfig, error) {
+	audiences := append([]string{config.KeycloakClientID}, config.KeycloakAudiences...)
+	audiences = slices.DeleteFunc(audiences, func(aud string) bool { return aud == "" })
+
+	issuers := make([]jwtauth.IssuerConfig, 0, len(config.KeycloakTrustedIssuers))
+	for _, entry := range config.KeycloakTrustedIssuers {
+		issuer, jwksURL, ok := strings.Cut(entry, "=")
+		if !ok {
+			jwksURL = keycloakJWKSURL(issuer)
+		}
+		for _, raw := range []string{issuer, jwksURL} {
+			if u, err := url.Parse(raw); err != nil || u.Host == "" || (u.Scheme != "http" && u.Scheme != "https") {
+				return nil, fmt.Errorf("trusted issuer %q: %q is not an http or https URL", entry, raw)
+			}
+		}
+		issuers = append(issuers, jwtauth.IssuerConfig{
+			Issuer:    issuer,
+			JWKSURL:   jwksURL,
+			Audiences: audiences,
+		})
+	}
+	return issuers, nil
+}
+
+// keycloakJWKSURL returns the JWKS URL of a Keycloak realm issuer.
+func keycloakJWKSURL(issuer string) string {
+	return strings.TrimSuffix(issuer, "/") + "/protocol/openid-connect/certs"
+}
+
+// newEmbeddedAuthSigner returns the signer of the embedded identity
+// provider. Its Ed25519 key is derived from the JWT secret, so tokens stay
+// valid across restarts and replicas sharing the secret.
+func newEmbeddedAuthSigner(config *Config) (*jwtauth.Signer, error) {
+	mac := hmac.New(sha256.New, []byte(config.JWTSecret))
+	mac.Write([]byte("wonder-mesh-net embedded auth signing key"))
+	key := ed25519.NewKeyFromSeed(mac.Sum(nil))
+	signer, err := jwtauth.NewSigner(config.PublicURL+controller.EmbeddedAuthPath, key)
+	if err != nil {
+		return nil, fmt.Errorf("create embedded auth signer: %w", err)
+	}
+	return signer, nil
+}
+
+// loginProviders returns the providers users of the embedded identity
+// provider can log in with.
+func loginProviders(config *Config) []*oidc.Provider {
+	var providers []*oidc.Provider
+	if config.GitHubClientID != "" {
+		providers = append(providers, oidc.GitHub(config.GitHubClientID, config.GitHubClientSecret))
+	}
+	if config.GoogleClientID != "" {
+		providers = append(providers, oidc.Google(config.GoogleClientID, config.GoogleClientSecret))
+	}
+	return providers
+}
+
+// oidcConfig returns the settings for the Keycloak login flow.
+func oidcConfig(config *Config) service.OIDCConfig {
+	return service.OIDCConfig{
 		KeycloakURL:  config.KeycloakURL,
 		Realm:        config.KeycloakRealm,
 		ClientID:     config.KeycloakClientID,
 		ClientSecret: config.KeycloakClientSecret,
 		RedirectURI:  config.PublicURL + "/coordinator/oidc/callback",
-	}, jwtValidator)
-
-	return &Server{
-		config:              config,
-		db:                  db,
-		headscaleConn:       headscaleConn,
-		headscaleClient:     headscaleClient,
-		jwtValidator:        jwtValidator,
-		oidcService:         oidcService,
-		meshBackend:         meshBackend,
-		wonderNetRepository: wonderNetRepository,
-		apiKeyRepository:    apiKeyRepository,
-		wonderNetService:    wonderNetService,
-		workerService:       workerService,
-		nodesService:        nodesService,
-		apiKeyService:       apiKeyService,
-	}, nil
+	}
 }
 
 func redactDSN(dsn string) string {
@@ -214,7 +543,9 @@ func (s *Server) requireAuth(next http.HandlerFunc) http.HandlerFunc {
 }
 
 // requireWonderNet wraps a handler to resolve the WonderNet from JWT claims.
-// For regular users, it auto-creates a WonderNet if none exists.
+// For regular users, it auto-creates a WonderNet if none exists. The user's
+// role in the WonderNet is added to the context, and read-only members are
+// limited to GET requests.
 // Must be used after requireAuth.
 func (s *Server) requireWonderNet(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
@@ -224,21 +555,80 @@ func (s *Server) requireWonderNet(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		wonderNet, err := s.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims)
+		wonderNet, err := s.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims, requestedNetwork(r))
+		if errors.Is(err, service.ErrNoWonderNet) {
+			http.Error(w, "wonder net not found", http.StatusNotFound)
+			return
+		}
 		if err != nil {
 			slog.Error("get or create wonder net", "error", err)
 			http.Error(w, "internal error", http.StatusInternalServerError)
 			return
 		}
 
+		if !servedOnHost(r, wonderNet) {
+			http.Error(w, "wonder net not found", http.StatusNotFound)
+			return
+		}
+
+		role, err := s.memberService.Role(r.Context(), wonderNet, claims.Subject)
+		if err != nil {
+			slog.Error("get wonder net role", "error", err)
+			http.Error(w, "internal error", http.StatusInternalServerError)
+			return
+		}
+		if role == repository.RoleReadOnly && r.Method != http.MethodGet && r.Method != http.MethodHead {
+			http.Error(w, "read-only members cannot change the wonder net", http.StatusForbidden)
+			return
+		}
+
 		ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
+		ctx = context.WithValue(ctx, controller.ContextKeyWonderNetRole, role)
 		next.ServeHTTP(w, r.WithContext(ctx))
 	}
 }
 
+// requireMember wraps a handler to reject read-only members, for endpoints
+// that hand out credentials or remove nodes. Must be used after
+// requireWonderNet.
+func (s *Server) requireMember(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if controller.WonderNetRoleFromContext(r) == repository.RoleReadOnly {
+			http.Error(w, "read-only members cannot do this", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requireOwner wraps a handler to allow only owners of the WonderNet, for
+// endpoints that change who and what can be reached in it. Must be used
+// after requireWonderNet.
+func (s *Server) requireOwner(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if controller.WonderNetRoleFromContext(r) != repository.RoleOwner {
+			http.Error(w, "only owners of the wonder net can do this", http.StatusForbidden)
+			return
+		}
+		next.ServeHTTP(w, r)
+	}
+}
+
+// requestedNetwork returns the WonderNet selected by the request, either by
+// the X-Wonder-Net header or the network query parameter. An empty result
+// selects the user's default WonderNet. API keys are bound to a single
+// WonderNet and ignore this selection.
+func requestedNetwork(r *http.Request) string {
+	if network := r.Header.Get("X-Wonder-Net"); network != "" {
+		return network
+	}
+	return r.URL.Query().Get("network")
+}
+
 // requireAPIKey wraps a handler with API key authentication.
-// It validates the API key and adds the associated WonderNet to the context.
-func (s *Server) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
+// It validates that the API key grants scope and adds the key and its
+// associated WonderNet to the context.
+func (s *Server) requireAPIKey(scope string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token := extractBearerToken(r)
 		if token == "" {
@@ -251,36 +641,63 @@ func (s *Server) requireAPIKey(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		wonderNet, err := s.apiKeyService.ValidateAPIKey(r.Context(), token)
-		if err != nil {
-			slog.Debug("API key validation failed", "error", err)
-			http.Error(w, "invalid api key", http.StatusUnauthorized)
+		s.serveAPIKey(w, r, token, scope, next)
+	}
+}
+
+// requireAPIKeyOr wraps a handler that accepts API keys granting scope as
+// well as the authentication of session, the handler chain used for all
+// requests that do not carry an API key.
+func (s *Server) requireAPIKeyOr(scope string, session, next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if token := extractBearerToken(r); token != "" && apikey.IsAPIKey(token) {
+			s.serveAPIKey(w, r, token, scope, next)
 			return
 		}
+		session.ServeHTTP(w, r)
+	}
+}
 
-		ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// serveAPIKey validates an API key for scope and serves the request with
+// the key and its WonderNet in the context.
+func (s *Server) serveAPIKey(w http.ResponseWriter, r *http.Request, token, scope string, next http.HandlerFunc) {
+	wonderNet, key, err := s.apiKeyService.ValidateAPIKey(r.Context(), token, r.Pattern, scope, controller.ClientIPFromContext(r))
+	if errors.Is(err, service.ErrAPIKeyMissingScope) {
+		http.Error(w, err.Error(), http.StatusForbidden)
+		return
+	}
+	if errors.Is(err, service.ErrClientIPNotAllowed) {
+		http.Error(w, "api key may not be used from this address", http.StatusForbidden)
+		return
+	}
+	if err != nil {
+		slog.Debug("API key validation failed", "error", err)
+		http.Error(w, "invalid api key", http.StatusUnauthorized)
+		return
 	}
+
+	if !servedOnHost(r, wonderNet) {
+		http.Error(w, "wonder net not found", http.StatusNotFound)
+		return
+	}
+	ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
+	ctx = context.WithValue(ctx, controller.ContextKeyAPIKey, key)
+	next.ServeHTTP(w, r.WithContext(ctx))
 }
 
 // requireAuthOrAPIKey wraps a handler that accepts JWT session auth, session cookie, or API key auth.
-// For JWT/session auth, it validates the token and resolves the WonderNet from claims.
-// For API key auth, it validates the key and uses the associated WonderNet.
-// This is used for read-only endpoints that should be accessible to both users and third-party integrations.
-func (s *Server) requireAuthOrAPIKey(next http.HandlerFunc) http.HandlerFunc {
+// For JWT/session auth, it validates the token and resolves the WonderNet and
+// the user's role in it like requireWonderNet, so read-only members are
+// limited to GET requests.
+// For API key auth, it validates that the key grants scope and uses the associated WonderNet.
+// This is used for endpoints that should be accessible to both users and third-party integrations.
+func (s *Server) requireAuthOrAPIKey(scope string, next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		token := extractBearerToken(r)
 
 		// Check if it's an API key
 		if token != "" && apikey.IsAPIKey(token) {
-			wonderNet, err := s.apiKeyService.ValidateAPIKey(r.Context(), token)
-			if err != nil {
-				slog.Debug("API key validation failed", "error", err)
-				http.Error(w, "invalid api key", http.StatusUnauthorized)
-				return
-			}
-			ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			s.serveAPIKey(w, r, token, scope, next)
 			return
 		}
 
@@ -293,15 +710,8 @@ func (s *Server) requireAuthOrAPIKey(next http.HandlerFunc) http.HandlerFunc {
 				return
 			}
 
-			wonderNet, err := s.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims)
-			if err != nil {
-				slog.Error("resolve wonder net from claims", "error", err)
-				http.Error(w, "internal error", http.StatusInternalServerError)
-				return
-			}
-
-			ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
-			next.ServeHTTP(w, r.WithContext(ctx))
+			ctx := context.WithValue(r.Context(), jwtauth.ContextKeyClaims, claims)
+			s.requireWonderNet(next).ServeHTTP(w, r.WithContext(ctx))
 			return
 		}
 
@@ -312,13 +722,9 @@ func (s *Server) requireAuthOrAPIKey(next http.HandlerFunc) http.HandlerFunc {
 			if err == nil {
 				claims, err := s.jwtValidator.Validate(session.AccessToken)
 				if err == nil {
-					wonderNet, err := s.wonderNetService.ResolveWonderNetFromClaims(r.Context(), claims)
-					if err == nil {
-						ctx := context.WithValue(r.Context(), controller.ContextKeyWonderNet, wonderNet)
-						next.ServeHTTP(w, r.WithContext(ctx))
-						return
-					}
-					slog.Error("resolve wonder net from claims", "error", err)
+					ctx := context.WithValue(r.Context(), jwtauth.ContextKeyClaims, claims)
+					s.requireWonderNet(next).ServeHTTP(w, r.WithContext(ctx))
+					return
 				}
 				slog.Debug("session access token validation failed", "error", err)
 			}
@@ -338,7 +744,7 @@ func (s *Server) requireAdminAuth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		if subtle.ConstantTimeCompare([]byte(token), []byte(s.config.AdminAPIAuthToken)) != 1 {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(s.currentConfig().AdminAPIAuthToken)) != 1 {
 			http.Error(w, "invalid admin token", http.StatusUnauthorized)
 			return
 		}
@@ -363,70 +769,240 @@ func extractBearerToken(r *http.Request) string {
 // It registers all API routes, starts listening on the configured address,
 // and handles graceful shutdown on SIGINT or SIGTERM with a 10-second timeout.
 func (s *Server) Run() error {
-	healthController := controller.NewHealthController(s.headscaleClient)
-	workerController := controller.NewWorkerController(s.workerService)
+	config := s.currentConfig()
+
+	healthController := controller.NewHealthController(s.db, s.headscaleBreaker, s.meshBackend)
+	workerController := controller.NewWorkerController(s.workerService, s.decommissionService)
 	joinTokenController := controller.NewJoinTokenController(s.workerService)
-	nodesController := controller.NewNodesController(s.nodesService)
+	// draining is closed when the HTTP server starts shutting down, ending
+	// long-lived node watches so that the shutdown is not held up by them.
+	draining := make(chan struct{})
+	nodesController := controller.NewNodesController(s.nodesService, s.nodeNamingService, s.nodeWatcher, draining)
 	apiKeyController := controller.NewAPIKeyController(s.apiKeyService)
-	deployerController := controller.NewDeployerController(s.meshBackend)
-
-	secureCookie := strings.HasPrefix(s.config.PublicURL, "https://")
+	deployerController := controller.NewDeployerController(s.meshBackend, s.quotaService, s.sshCAService)
+	alertController := controller.NewAlertController(s.alertService)
+	wonderNetController := controller.NewWonderNetController(s.wonderNetService, s.nodeNamingService, s.nodeApprovalService, s.nodeExpiryService)
+	aclController := controller.NewACLController(s.wonderNetService)
+	servicesController := controller.NewServicesController(s.serviceCatalogService)
+	accessRequestController := controller.NewAccessRequestController(s.accessRequestService)
+	decommissionController := controller.NewNodeDecommissionController(s.decommissionService, s.workerService)
+	statsController := controller.NewStatsController(s.statsService)
+	memberController := controller.NewMemberController(s.memberService)
+	routesController := controller.NewRoutesController(s.routesService)
+	webhookController := controller.NewWebhookController(s.webhookService)
+	notificationController := controller.NewNotificationController(s.notificationService)
+	dnsController := controller.NewDNSController(s.dnsService)
+	nodeApprovalController := controller.NewNodeApprovalController(s.nodeApprovalService)
+	execSessionController := controller.NewExecSessionController(s.execSessionService)
+	introspectionController := controller.NewIntrospectionController(s.introspectionService)
+	sshCAController := controller.NewSSHCAController(s.sshCAService)
+
+	secureCookie := strings.HasPrefix(config.PublicURL, "https://")
 	oidcController := controller.NewOIDCController(
 		s.oidcService,
 		s.wonderNetService,
-		s.config.PublicURL,
+		config.PublicURL,
 		secureCookie,
 	)
 
-	headscaleProxy, err := controller.NewHeadscaleProxyController(s.config.HeadscaleURL)
-	if err != nil {
-		return err
+	// The Headscale control protocol is served on the public URL, unless
+	// nodes join the Tailscale control plane.
+	var headscaleProxy *controller.HeadscaleProxyController
+	if s.headscaleConn != nil {
+		proxy, err := controller.NewHeadscaleProxyController(config.HeadscaleURL)
+		if err != nil {
+			return err
+		}
+		headscaleProxy = proxy
 	}
 
 	mux := http.NewServeMux()
 	mux.HandleFunc("GET /coordinator/health", healthController.ServeHTTP)
-
-	// OIDC authentication endpoints (no auth required)
-	mux.HandleFunc("GET /coordinator/oidc/login", oidcController.HandleLogin)
-	mux.HandleFunc("GET /coordinator/oidc/callback", oidcController.HandleCallback)
+	mux.Handle("GET /coordinator/metrics", metrics.Handler())
+
+	// OIDC authentication endpoints (no auth required); the embedded
+	// identity provider serves its own login page instead of Keycloak's
+	var embeddedAuthController *controller.EmbeddedAuthController
+	if s.embeddedAuthService != nil {
+		embeddedAuthController = controller.NewEmbeddedAuthController(s.embeddedAuthService, s.oidcService, config.PublicURL, secureCookie)
+		mux.HandleFunc("GET /coordinator/oidc/login", embeddedAuthController.HandleLoginPage)
+		mux.HandleFunc("POST /coordinator/auth/login", embeddedAuthController.HandlePasswordLogin)
+		mux.HandleFunc("POST /coordinator/auth/token", embeddedAuthController.HandleToken)
+		mux.HandleFunc("GET /coordinator/auth/jwks", embeddedAuthController.HandleJWKS)
+		mux.HandleFunc("GET /coordinator/auth/.well-known/openid-configuration", embeddedAuthController.HandleDiscovery)
+		mux.HandleFunc("GET /coordinator/auth/{provider}/login", embeddedAuthController.HandleProviderLogin)
+		mux.HandleFunc("GET /coordinator/auth/{provider}/callback", embeddedAuthController.HandleProviderCallback)
+	} else {
+		mux.HandleFunc("GET /coordinator/oidc/login", oidcController.HandleLogin)
+		mux.HandleFunc("GET /coordinator/oidc/callback", oidcController.HandleCallback)
+	}
 	mux.HandleFunc("GET /coordinator/oidc/logout", oidcController.HandleLogout)
 
 	// Worker endpoints (join token exchange doesn't require auth)
 	mux.HandleFunc("POST /coordinator/api/v1/worker/join", workerController.HandleWorkerJoin)
-
-	// Protected endpoints - require JWT authentication and WonderNet
-	mux.HandleFunc("GET /coordinator/api/v1/join-token", s.requireAuth(s.requireWonderNet(joinTokenController.HandleCreateJoinToken)))
+	mux.HandleFunc("POST /coordinator/api/v1/worker/renew", workerController.HandleRenew)
+	mux.HandleFunc("POST /coordinator/api/v1/worker/heartbeat", workerController.HandleHeartbeat)
+	mux.HandleFunc("POST /coordinator/api/v1/worker/wipe-result", decommissionController.HandleWipeResult)
+
+	// Protected endpoints - require JWT authentication and WonderNet; join tokens can also be created with API keys
+	mux.HandleFunc("GET /coordinator/api/v1/join-token", s.requireAPIKeyOr(service.APIKeyScopeTokensCreate,
+		s.requireAuth(s.requireWonderNet(s.requireMember(joinTokenController.HandleCreateJoinToken))),
+		joinTokenController.HandleCreateJoinToken))
+	mux.HandleFunc("POST /coordinator/api/v1/join-token", s.requireAPIKeyOr(service.APIKeyScopeTokensCreate,
+		s.requireAuth(s.requireWonderNet(s.requireMember(joinTokenController.HandleCreateJoinToken))),
+		joinTokenController.HandleCreateJoinToken))
+	mux.HandleFunc("GET /coordinator/api/v1/join-token/qr", s.requireAPIKeyOr(service.APIKeyScopeTokensCreate,
+		s.requireAuth(s.requireWonderNet(s.requireMember(joinTokenController.HandleJoinTokenQR))),
+		joinTokenController.HandleJoinTokenQR))
+	mux.HandleFunc("GET /coordinator/api/v1/join-token/{jti}", s.requireAuth(s.requireWonderNet(joinTokenController.HandleGetJoinTokenStatus)))
 
 	// Read-only endpoints - support both JWT session auth and API key auth
-	mux.HandleFunc("GET /coordinator/api/v1/nodes", s.requireAuthOrAPIKey(nodesController.HandleListNodes))
+	mux.HandleFunc("GET /coordinator/api/v1/nodes", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, nodesController.HandleListNodes))
+	mux.HandleFunc("GET /coordinator/api/v1/nodes/watch", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, nodesController.HandleWatchNodes))
+	mux.HandleFunc("GET /coordinator/api/v1/nodes/topology", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, nodesController.HandleTopology))
+	mux.HandleFunc("GET /coordinator/api/v1/nodes/pending", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, nodeApprovalController.HandleListPending))
+	mux.HandleFunc("GET /coordinator/api/v1/nodes/{id}", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, nodesController.HandleGetNode))
+	mux.HandleFunc("PATCH /coordinator/api/v1/nodes/{id}", s.requireAuthOrAPIKey(service.APIKeyScopeNodesWrite, nodesController.HandleRenameNode))
 
 	// API key management - JWT auth only (no API key auth to prevent privilege escalation)
-	mux.HandleFunc("POST /coordinator/api/v1/api-keys", s.requireAuth(s.requireWonderNet(apiKeyController.HandleCreate)))
+	mux.HandleFunc("POST /coordinator/api/v1/api-keys", s.requireAuth(s.requireWonderNet(s.requireMember(apiKeyController.HandleCreate))))
 	mux.HandleFunc("GET /coordinator/api/v1/api-keys", s.requireAuth(s.requireWonderNet(apiKeyController.HandleList)))
-	mux.HandleFunc("DELETE /coordinator/api/v1/api-keys/{id}", s.requireAuth(s.requireWonderNet(apiKeyController.HandleDelete)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/api-keys/{id}", s.requireAuth(s.requireWonderNet(s.requireMember(apiKeyController.HandleDelete))))
+	mux.HandleFunc("POST /coordinator/api/v1/api-keys/{id}/rotate", s.requireAuth(s.requireWonderNet(s.requireMember(apiKeyController.HandleRotate))))
+
+	// Alert rules and silences - JWT auth only; alert state is read-only and also accepts API keys
+	mux.HandleFunc("POST /coordinator/api/v1/alert-rules", s.requireAuth(s.requireWonderNet(alertController.HandleCreateRule)))
+	mux.HandleFunc("GET /coordinator/api/v1/alert-rules", s.requireAuth(s.requireWonderNet(alertController.HandleListRules)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/alert-rules/{id}", s.requireAuth(s.requireWonderNet(alertController.HandleDeleteRule)))
+	mux.HandleFunc("POST /coordinator/api/v1/alert-silences", s.requireAuth(s.requireWonderNet(alertController.HandleCreateSilence)))
+	mux.HandleFunc("GET /coordinator/api/v1/alert-silences", s.requireAuth(s.requireWonderNet(alertController.HandleListSilences)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/alert-silences/{id}", s.requireAuth(s.requireWonderNet(alertController.HandleDeleteSilence)))
+	mux.HandleFunc("GET /coordinator/api/v1/alerts", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, alertController.HandleListAlerts))
+
+	// Webhooks - JWT auth only, since webhooks carry signing secrets and send wonder net events out
+	mux.HandleFunc("POST /coordinator/api/v1/webhooks", s.requireAuth(s.requireWonderNet(s.requireMember(webhookController.HandleCreate))))
+	mux.HandleFunc("GET /coordinator/api/v1/webhooks", s.requireAuth(s.requireWonderNet(webhookController.HandleList)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/webhooks/{id}", s.requireAuth(s.requireWonderNet(s.requireMember(webhookController.HandleDelete))))
+	mux.HandleFunc("GET /coordinator/api/v1/webhooks/{id}/deliveries", s.requireAuth(s.requireWonderNet(webhookController.HandleListDeliveries)))
+
+	// Notification channels - JWT auth only, since they send wonder net events out by email or Slack
+	mux.HandleFunc("POST /coordinator/api/v1/notification-channels", s.requireAuth(s.requireWonderNet(s.requireMember(notificationController.HandleCreate))))
+	mux.HandleFunc("GET /coordinator/api/v1/notification-channels", s.requireAuth(s.requireWonderNet(notificationController.HandleList)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/notification-channels/{id}", s.requireAuth(s.requireWonderNet(s.requireMember(notificationController.HandleDelete))))
+
+	// DNS names - reading also accepts API keys, changes require JWT auth
+	mux.HandleFunc("GET /coordinator/api/v1/dns", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, dnsController.HandleGet))
+	mux.HandleFunc("POST /coordinator/api/v1/dns", s.requireAuth(s.requireWonderNet(s.requireMember(dnsController.HandleSetDomain))))
+	mux.HandleFunc("POST /coordinator/api/v1/dns/records", s.requireAuth(s.requireWonderNet(s.requireMember(dnsController.HandleCreateRecord))))
+	mux.HandleFunc("DELETE /coordinator/api/v1/dns/records/{id}", s.requireAuth(s.requireWonderNet(s.requireMember(dnsController.HandleDeleteRecord))))
+
+	// Per-WonderNet ACL rules - JWT auth only, and changes are limited to owners, since rules widen access within the WonderNet
+	mux.HandleFunc("GET /coordinator/api/v1/acl", s.requireAuth(s.requireWonderNet(aclController.HandleGet)))
+	mux.HandleFunc("PUT /coordinator/api/v1/acl", s.requireAuth(s.requireWonderNet(s.requireOwner(aclController.HandlePut))))
+
+	// Published services and access grants - JWT auth only and limited to owners; the listing is read-only and also accepts API keys
+	mux.HandleFunc("POST /coordinator/api/v1/services", s.requireAuth(s.requireWonderNet(s.requireOwner(servicesController.HandleCreate))))
+	mux.HandleFunc("GET /coordinator/api/v1/services", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, servicesController.HandleList))
+	mux.HandleFunc("DELETE /coordinator/api/v1/services/{id}", s.requireAuth(s.requireWonderNet(s.requireOwner(servicesController.HandleDelete))))
+	mux.HandleFunc("POST /coordinator/api/v1/services/{id}/grants", s.requireAuth(s.requireWonderNet(s.requireOwner(servicesController.HandleCreateGrant))))
+	mux.HandleFunc("DELETE /coordinator/api/v1/services/{id}/grants/{grant_id}", s.requireAuth(s.requireWonderNet(s.requireOwner(servicesController.HandleDeleteGrant))))
+
+	// Just-in-time access requests - requesting and listing also accept API keys; decisions are JWT auth only and limited to owners
+	mux.HandleFunc("POST /coordinator/api/v1/access-requests", s.requireAuthOrAPIKey(service.APIKeyScopeNodesWrite, accessRequestController.HandleCreate))
+	mux.HandleFunc("GET /coordinator/api/v1/access-requests", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, accessRequestController.HandleList))
+	mux.HandleFunc("POST /coordinator/api/v1/access-requests/{id}/approve", s.requireAuth(s.requireWonderNet(s.requireOwner(accessRequestController.HandleApprove))))
+	mux.HandleFunc("POST /coordinator/api/v1/access-requests/{id}/deny", s.requireAuth(s.requireWonderNet(s.requireOwner(accessRequestController.HandleDeny))))
+	mux.HandleFunc("POST /coordinator/api/v1/access-requests/{id}/revoke", s.requireAuth(s.requireWonderNet(s.requireOwner(accessRequestController.HandleRevoke))))
+
+	// Node decommissioning - JWT auth only, since it removes the node; the archive is read-only and also accepts API keys
+	mux.HandleFunc("POST /coordinator/api/v1/nodes/{id}/decommission", s.requireAuth(s.requireWonderNet(s.requireMember(decommissionController.HandleDecommission))))
+	mux.HandleFunc("GET /coordinator/api/v1/node-decommissions", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, decommissionController.HandleList))
+	mux.HandleFunc("GET /coordinator/api/v1/node-decommissions/{id}", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, decommissionController.HandleGet))
+
+	// Exec session audit trail - recorded by the clients that run commands on nodes, with a JWT session or an API key
+	mux.HandleFunc("POST /coordinator/api/v1/exec-sessions", s.requireAuthOrAPIKey(service.APIKeyScopeNodesWrite, execSessionController.HandleRecord))
+	mux.HandleFunc("GET /coordinator/api/v1/exec-sessions", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, execSessionController.HandleList))
+	mux.HandleFunc("GET /coordinator/api/v1/exec-sessions/{id}", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, execSessionController.HandleGet))
+
+	// SSH certificate authority - the CA key is read-only; certificates open nodes, so sessions need the member role
+	mux.HandleFunc("GET /coordinator/api/v1/ssh-ca", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, sshCAController.HandleGetCA))
+	mux.HandleFunc("POST /coordinator/api/v1/ssh-cert", s.requireAPIKeyOr(service.APIKeyScopeNodesWrite,
+		s.requireAuth(s.requireWonderNet(s.requireMember(sshCAController.HandleSignCert))),
+		sshCAController.HandleSignCert))
+
+	// Node approval - JWT auth only; approving is limited to the owner
+	mux.HandleFunc("POST /coordinator/api/v1/nodes/{id}/approve", s.requireAuth(s.requireWonderNet(s.requireOwner(nodeApprovalController.HandleApprove))))
+
+	// Summary counts - read-only, JWT session or API key auth
+	mux.HandleFunc("GET /coordinator/api/v1/stats", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, statsController.HandleGet))
+
+	// Subnet and exit routes - listing also accepts API keys; approval is JWT auth only and limited to owners
+	mux.HandleFunc("GET /coordinator/api/v1/routes", s.requireAuthOrAPIKey(service.APIKeyScopeNodesRead, routesController.HandleList))
+	mux.HandleFunc("POST /coordinator/api/v1/routes/{id}/approve", s.requireAuth(s.requireWonderNet(s.requireOwner(routesController.HandleApprove))))
+	mux.HandleFunc("POST /coordinator/api/v1/routes/{id}/reject", s.requireAuth(s.requireWonderNet(s.requireOwner(routesController.HandleReject))))
+
+	// WonderNet management endpoints - require JWT authentication
+	mux.HandleFunc("GET /coordinator/api/v1/wonder-nets", s.requireAuth(s.requireWonderNet(wonderNetController.HandleList)))
+	mux.HandleFunc("POST /coordinator/api/v1/wonder-nets", s.requireAuth(s.requireWonderNet(wonderNetController.HandleCreate)))
+	mux.HandleFunc("PATCH /coordinator/api/v1/wonder-nets/{id}", s.requireAuth(s.requireWonderNet(wonderNetController.HandleUpdate)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/wonder-nets/{id}", s.requireAuth(s.requireWonderNet(wonderNetController.HandleDelete)))
+	mux.HandleFunc("PUT /coordinator/api/v1/wonder-nets/{id}/default", s.requireAuth(s.requireWonderNet(wonderNetController.HandleSetDefault)))
+
+	// WonderNet sharing - JWT auth only; members and invites of the selected WonderNet are managed by its owners
+	mux.HandleFunc("GET /coordinator/api/v1/members", s.requireAuth(s.requireWonderNet(memberController.HandleListMembers)))
+	mux.HandleFunc("PUT /coordinator/api/v1/members/{user_id}", s.requireAuth(s.requireWonderNet(memberController.HandleUpdateMember)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/members/{user_id}", s.requireAuth(s.requireWonderNet(memberController.HandleRemoveMember)))
+	mux.HandleFunc("POST /coordinator/api/v1/members/invites", s.requireAuth(s.requireWonderNet(memberController.HandleCreateInvite)))
+	mux.HandleFunc("GET /coordinator/api/v1/members/invites", s.requireAuth(s.requireWonderNet(memberController.HandleListInvites)))
+	mux.HandleFunc("DELETE /coordinator/api/v1/members/invites/{id}", s.requireAuth(s.requireWonderNet(memberController.HandleRevokeInvite)))
+	mux.HandleFunc("GET /coordinator/api/v1/invites", s.requireAuth(memberController.HandleListMyInvites))
+	mux.HandleFunc("POST /coordinator/api/v1/invites/{id}/accept", s.requireAuth(memberController.HandleAcceptInvite))
+	mux.HandleFunc("POST /coordinator/api/v1/invites/{id}/decline", s.requireAuth(memberController.HandleDeclineInvite))
 
 	// Deployer endpoints - API key auth only
-	mux.HandleFunc("POST /coordinator/api/v1/deployer/join", s.requireAPIKey(deployerController.HandleDeployerJoin))
+	mux.HandleFunc("POST /coordinator/api/v1/deployer/join", s.requireAPIKey(service.APIKeyScopeDeployerJoin, deployerController.HandleDeployerJoin))
+
+	// Token introspection (RFC 7662) for integrations - API key auth only
+	mux.HandleFunc("POST /coordinator/api/v1/introspect", s.requireAPIKey(service.APIKeyScopeIntrospect, introspectionController.HandleIntrospect))
 
 	// Admin API endpoints - only registered if enabled
-	if s.config.EnableAdminAPI {
+	if config.EnableAdminAPI {
 		adminController := controller.NewAdminController(
 			s.wonderNetService,
 			s.nodesService,
 			s.workerService,
 			s.apiKeyService,
+			s.quotaService,
+			s.nodeExpiryService,
 			s.meshBackend,
 		)
 		mux.HandleFunc("GET /coordinator/admin/api/v1/wonder-nets", s.requireAdminAuth(adminController.HandleListWonderNets))
 		mux.HandleFunc("POST /coordinator/admin/api/v1/wonder-nets", s.requireAdminAuth(adminController.HandleAdminCreateWonderNet))
+		mux.HandleFunc("DELETE /coordinator/admin/api/v1/wonder-nets/{id}", s.requireAdminAuth(adminController.HandleAdminDeleteWonderNet))
 		mux.HandleFunc("GET /coordinator/admin/api/v1/wonder-nets/{id}/nodes", s.requireAdminAuth(adminController.HandleListWonderNetNodes))
 		mux.HandleFunc("GET /coordinator/admin/api/v1/users/{user_id}/wonder-nets", s.requireAdminAuth(adminController.HandleListWonderNetsByUser))
 		mux.HandleFunc("GET /coordinator/admin/api/v1/nodes", s.requireAdminAuth(adminController.HandleListAllNodes))
+		mux.HandleFunc("GET /coordinator/admin/api/v1/mesh-types", s.requireAdminAuth(adminController.HandleMeshTypeSummary))
 		mux.HandleFunc("POST /coordinator/admin/api/v1/wonder-nets/{id}/join-token", s.requireAdminAuth(adminController.HandleAdminCreateJoinToken))
 		mux.HandleFunc("POST /coordinator/admin/api/v1/wonder-nets/{id}/api-keys", s.requireAdminAuth(adminController.HandleAdminCreateAPIKey))
 		mux.HandleFunc("POST /coordinator/admin/api/v1/wonder-nets/{id}/deployer/join", s.requireAdminAuth(adminController.HandleAdminDeployerJoin))
+		mux.HandleFunc("PUT /coordinator/admin/api/v1/wonder-nets/{id}/public-url", s.requireAdminAuth(adminController.HandleSetPublicURL))
+		mux.HandleFunc("GET /coordinator/admin/api/v1/wonder-nets/{id}/quotas", s.requireAdminAuth(adminController.HandleGetQuotas))
+		mux.HandleFunc("PUT /coordinator/admin/api/v1/wonder-nets/{id}/quotas", s.requireAdminAuth(adminController.HandleSetQuotas))
+		mux.HandleFunc("DELETE /coordinator/admin/api/v1/wonder-nets/{id}/quotas", s.requireAdminAuth(adminController.HandleResetQuotas))
 		mux.HandleFunc("GET /coordinator/admin/api/v1/wonder-nets/{id}/nodes/{node_id}", s.requireAdminAuth(adminController.HandleGetNode))
 		mux.HandleFunc("DELETE /coordinator/admin/api/v1/wonder-nets/{id}/nodes/{node_id}", s.requireAdminAuth(adminController.HandleDeleteNode))
+		mux.HandleFunc("GET /coordinator/admin/api/v1/wonder-nets/{id}/stale-nodes", s.requireAdminAuth(adminController.HandleListStaleNodes))
+		if embeddedAuthController != nil {
+			mux.HandleFunc("GET /coordinator/admin/api/v1/users", s.requireAdminAuth(embeddedAuthController.HandleListUsers))
+			mux.HandleFunc("POST /coordinator/admin/api/v1/users", s.requireAdminAuth(embeddedAuthController.HandleCreateUser))
+			mux.HandleFunc("DELETE /coordinator/admin/api/v1/users/{user_id}", s.requireAdminAuth(embeddedAuthController.HandleDeleteUser))
+			mux.HandleFunc("PUT /coordinator/admin/api/v1/users/{user_id}/password", s.requireAdminAuth(embeddedAuthController.HandleSetPassword))
+		}
+		mux.HandleFunc("GET /coordinator/admin/api/v1/config", s.requireAdminAuth(s.handleGetConfig))
+		mux.HandleFunc("GET /coordinator/admin/api/v1/log-level", s.requireAdminAuth(s.handleGetLogLevel))
+		mux.HandleFunc("PUT /coordinator/admin/api/v1/log-level", s.requireAdminAuth(s.handleSetLogLevel))
+		s.registerProfilingRoutes(mux)
 		slog.Info("admin API routes registered")
 	}
 
@@ -441,12 +1017,20 @@ func (s *Server) Run() error {
 	}
 	mux.Handle("/ui/", http.StripPrefix("/ui", uiHandler))
 
-	mux.Handle("/", headscaleProxy)
+	if headscaleProxy != nil {
+		mux.Handle("/", headscaleProxy)
+	}
 
 	slog.Info("initializing ACL policy")
 	ctx := context.Background()
 	var aclErr error
 	for i := 0; i < 10; i++ {
+		if err := s.serviceCatalogService.LoadACLPolicies(ctx); err != nil {
+			aclErr = err
+			slog.Warn("load service ACL policies, retrying", "error", err, "attempt", i+1)
+			time.Sleep(time.Duration(i+1) * time.Second)
+			continue
+		}
 		if err := s.wonderNetService.InitializeACLPolicy(ctx); err != nil {
 			aclErr = err
 			slog.Warn("initialize ACL policy, retrying", "error", err, "attempt", i+1)
@@ -461,18 +1045,85 @@ func (s *Server) Run() error {
 		slog.Error("initialize ACL policy, giving up after retries", "error", aclErr)
 	}
 
+	// Background loops are waited for on shutdown, so that none of them is
+	// cut off by the database being closed.
+	backgroundCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	var background sync.WaitGroup
+	background.Go(func() { s.alertService.Run(backgroundCtx, alertEvaluationInterval) })
+	background.Go(func() { s.nodeNamingService.Run(backgroundCtx, nodeNamingInterval) })
+	background.Go(func() { s.nodeApprovalService.Run(backgroundCtx, nodeApprovalInterval) })
+	background.Go(func() { s.serviceCatalogService.Run(backgroundCtx, serviceReconcileInterval) })
+	background.Go(func() { s.accessRequestService.Run(backgroundCtx, accessExpiryInterval) })
+	background.Go(func() { s.decommissionService.Run(backgroundCtx, nodeWipeExpiryInterval) })
+	background.Go(func() { s.nodeExpiryService.Run(backgroundCtx, nodeExpiryInterval) })
+	background.Go(func() { s.webhookService.Run(backgroundCtx, webhookDeliveryInterval) })
+	background.Go(func() { s.notificationService.Run(backgroundCtx, notificationInterval) })
+	background.Go(func() { s.nodeWatcher.Run(backgroundCtx, nodeWatchInterval) })
+	background.Go(func() { s.execSessionService.Run(backgroundCtx, execSessionPurgeInterval) })
+	if s.dnsService.Enabled() {
+		background.Go(func() { s.dnsService.Run(backgroundCtx, dnsSyncInterval) })
+	}
+
+	var meshProxy *meshproxy.Server
+	if config.MeshProxyListen != "" {
+		dial, err := meshproxy.UpstreamDialer(config.MeshProxyUpstream)
+		if err != nil {
+			return err
+		}
+		ln, err := net.Listen("tcp", config.MeshProxyListen)
+		if err != nil {
+			return fmt.Errorf("listen mesh proxy: %w", err)
+		}
+		meshProxy = meshproxy.NewServer(s.apiKeyService, s.nodesService, dial)
+		go func() {
+			slog.Info("starting mesh proxy", "listen", config.MeshProxyListen, "upstream", redactURL(config.MeshProxyUpstream))
+			if err := meshProxy.Serve(ln); err != nil {
+				slog.Error("mesh proxy error", "error", err)
+			}
+		}()
+	}
+
+	// Serve HTTP/1.1 and prior-knowledge HTTP/2 over cleartext: TLS is
+	// normally terminated by the ingress, which can then multiplex large node
+	// listings over a single upstream connection. HTTP/2 over TLS is used
+	// when the coordinator terminates TLS itself.
+	protocols := new(http.Protocols)
+	protocols.SetHTTP1(true)
+	protocols.SetUnencryptedHTTP2(true)
+	protocols.SetHTTP2(true)
+
 	httpServer := &http.Server{
-		Addr:    s.config.Listen,
-		Handler: mux,
+		Addr:              config.Listen,
+		Handler:           metrics.InstrumentHandler(compressJSON(s.withCORS(s.withClientIP(s.routeByHost(withRequestTimeout(config.RequestTimeout, config.AdminRequestTimeout, metrics.RecordRoute(mux))))))),
+		Protocols:         protocols,
+		ReadHeaderTimeout: 10 * time.Second,
+		HTTP2: &http.HTTP2Config{
+			MaxConcurrentStreams: 250,
+			SendPingTimeout:      30 * time.Second,
+			PingTimeout:          15 * time.Second,
+		},
 	}
+	httpServer.RegisterOnShutdown(func() { close(draining) })
 
 	go func() {
 		slog.Info("starting coordinator",
-			"listen", s.config.Listen,
-			"coordinator_api", s.config.PublicURL+"/coordinator/*",
-			"headscale", s.config.PublicURL+"/*",
-			"keycloak", s.config.KeycloakURL)
-		if err := httpServer.ListenAndServe(); err != http.ErrServerClosed {
+			"listen", config.Listen,
+			"coordinator_api", config.PublicURL+"/coordinator/*",
+			"headscale", config.PublicURL+"/*",
+			"auth_mode", config.AuthMode,
+			"keycloak", config.KeycloakURL,
+			"tls_autocert", config.TLSAutocertCacheDir != "")
+		var err error
+		if config.TLSAutocertCacheDir != "" {
+			// Certificates are obtained with the TLS-ALPN-01 challenge, so
+			// Listen must be reachable on port 443 of every served host.
+			httpServer.TLSConfig = s.newAutocertManager(config).TLSConfig()
+			err = httpServer.ListenAndServeTLS("", "")
+		} else {
+			err = httpServer.ListenAndServe()
+		}
+		if err != http.ErrServerClosed {
 			slog.Error("server error", "error", err)
 			os.Exit(1)
 		}
@@ -483,22 +1134,52 @@ func (s *Server) Run() error {
 	<-sigCh
 
 	slog.Info("shutting down")
-	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	stopBackground()
+	if meshProxy != nil {
+		_ = meshProxy.Close()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
 	defer cancel()
 
+	// Shutdown stops accepting connections and waits for in-flight requests,
+	// such as join token exchanges, to finish; node watches are ended with a
+	// retriable "server_restarting" event.
 	if err := httpServer.Shutdown(ctx); err != nil {
 		return err
 	}
 
+	backgroundDone := make(chan struct{})
+	go func() {
+		background.Wait()
+		close(backgroundDone)
+	}()
+	select {
+	case <-backgroundDone:
+	case <-ctx.Done():
+		slog.Warn("background tasks still running at shutdown timeout")
+	}
+
+	slog.Info("shutdown complete")
 	return s.Close()
 }
 
 func (s *Server) Close() error {
-	if s.headscaleConn != nil {
-		_ = s.headscaleConn.Close()
+	// The recorder writes the API key uses of the requests that finished
+	// before shutdown, so it is closed before the database.
+	if s.apiKeyUsageRecorder != nil {
+		s.apiKeyUsageRecorder.Close()
 	}
+	closeConn(s.headscaleConn)
 	if s.db != nil {
 		return s.db.Close()
 	}
 	return nil
 }
+
+// closeConn closes the Headscale connection, which is nil with the Tailscale
+// control plane.
+func closeConn(conn *grpc.ClientConn) {
+	if conn != nil {
+		_ = conn.Close()
+	}
+}
@@ -0,0 +1,247 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+var (
+	ErrAccessRequestNotFound   = errors.New("access request not found")
+	ErrAccessRequestNotPending = errors.New("access request is not pending")
+	ErrAccessRequestNotActive  = errors.New("access request is not approved")
+	ErrInvalidAccessRequest    = errors.New("invalid access request")
+)
+
+const (
+	// MinAccessRequestDuration and MaxAccessRequestDuration bound how long
+	// an approved access request lasts.
+	MinAccessRequestDuration = time.Minute
+	MaxAccessRequestDuration = 7 * 24 * time.Hour
+
+	maxAccessRequestReasonLength = 500
+)
+
+// AccessRequestService implements just-in-time access: a requester asks for
+// temporary access to a service, the wonder net owner approves or denies it,
+// and an approved request is turned into a service grant that is removed
+// again when the request expires. Requests are kept after they end so that
+// who asked for what, who decided, and when access ended can be audited.
+type AccessRequestService struct {
+	accessRequestRepository *repository.AccessRequestRepository
+	wonderNetRepository     *repository.WonderNetRepository
+	serviceCatalogService   *ServiceCatalogService
+}
+
+// NewAccessRequestService creates a new AccessRequestService.
+func NewAccessRequestService(
+	accessRequestRepository *repository.AccessRequestRepository,
+	wonderNetRepository *repository.WonderNetRepository,
+	serviceCatalogService *ServiceCatalogService,
+) *AccessRequestService {
+	return &AccessRequestService{
+		accessRequestRepository: accessRequestRepository,
+		wonderNetRepository:     wonderNetRepository,
+		serviceCatalogService:   serviceCatalogService,
+	}
+}
+
+// RequestAccess files a pending request for subject to reach a service of
+// the wonder net for duration, counted from approval. Subjects use the same
+// forms as service grants.
+func (s *AccessRequestService) RequestAccess(ctx context.Context, wonderNet *repository.WonderNet, serviceID, subject, requester, reason string, duration time.Duration) (*repository.AccessRequest, error) {
+	if err := validateAccessRequest(requester, reason, duration); err != nil {
+		return nil, err
+	}
+
+	svc, err := s.serviceCatalogService.getOwnedService(ctx, wonderNet, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.serviceCatalogService.validateSubject(ctx, wonderNet, subject); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidAccessRequest, err)
+	}
+
+	req, err := s.accessRequestRepository.Create(ctx, &repository.AccessRequest{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNet.ID,
+		ServiceID:   svc.ID,
+		ServiceName: svc.Name,
+		Subject:     subject,
+		Requester:   requester,
+		Reason:      reason,
+		Duration:    duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created access request", "id", req.ID, "wonder_net_id", wonderNet.ID, "service_id", svc.ID, "subject", subject, "requester", requester, "duration", duration)
+	return req, nil
+}
+
+// ListRequests lists the access requests of a wonder net, newest first.
+func (s *AccessRequestService) ListRequests(ctx context.Context, wonderNet *repository.WonderNet) ([]*repository.AccessRequest, error) {
+	return s.accessRequestRepository.ListByWonderNet(ctx, wonderNet.ID)
+}
+
+// Approve grants the access asked for by a pending request until its
+// duration has passed.
+func (s *AccessRequestService) Approve(ctx context.Context, wonderNet *repository.WonderNet, requestID, decidedBy string) (*repository.AccessRequest, error) {
+	req, err := s.getOwnedRequest(ctx, wonderNet, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != repository.AccessRequestPending {
+		return nil, ErrAccessRequestNotPending
+	}
+
+	grant, err := s.serviceCatalogService.GrantAccess(ctx, wonderNet, req.ServiceID, req.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	expiresAt := time.Now().Add(req.Duration)
+	approved, err := s.accessRequestRepository.Approve(ctx, req.ID, decidedBy, grant.ID, expiresAt)
+	if err == nil && !approved {
+		err = ErrAccessRequestNotPending
+	}
+	if err != nil {
+		if revokeErr := s.serviceCatalogService.RevokeAccess(ctx, wonderNet, req.ServiceID, grant.ID); revokeErr != nil {
+			slog.Error("revoke service grant after failed approval", "grant_id", grant.ID, "error", revokeErr)
+		}
+		return nil, err
+	}
+
+	slog.Info("approved access request", "id", req.ID, "wonder_net_id", wonderNet.ID, "decided_by", decidedBy, "grant_id", grant.ID, "expires_at", expiresAt)
+	return s.accessRequestRepository.Get(ctx, req.ID)
+}
+
+// Deny rejects a pending request.
+func (s *AccessRequestService) Deny(ctx context.Context, wonderNet *repository.WonderNet, requestID, decidedBy string) (*repository.AccessRequest, error) {
+	req, err := s.getOwnedRequest(ctx, wonderNet, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	denied, err := s.accessRequestRepository.Deny(ctx, req.ID, decidedBy)
+	if err != nil {
+		return nil, err
+	}
+	if !denied {
+		return nil, ErrAccessRequestNotPending
+	}
+
+	slog.Info("denied access request", "id", req.ID, "wonder_net_id", wonderNet.ID, "decided_by", decidedBy)
+	return s.accessRequestRepository.Get(ctx, req.ID)
+}
+
+// Revoke ends an approved request before it expires.
+func (s *AccessRequestService) Revoke(ctx context.Context, wonderNet *repository.WonderNet, requestID string) (*repository.AccessRequest, error) {
+	req, err := s.getOwnedRequest(ctx, wonderNet, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Status != repository.AccessRequestApproved {
+		return nil, ErrAccessRequestNotActive
+	}
+
+	if err := s.end(ctx, wonderNet, req, repository.AccessRequestRevoked); err != nil {
+		return nil, err
+	}
+	return s.accessRequestRepository.Get(ctx, req.ID)
+}
+
+// ExpireDue removes the grants of approved requests whose time is up.
+// Requests whose grant cannot be removed stay approved and are retried on
+// the next call.
+func (s *AccessRequestService) ExpireDue(ctx context.Context) error {
+	requests, err := s.accessRequestRepository.ListExpired(ctx, time.Now())
+	if err != nil {
+		return fmt.Errorf("list expired access requests: %w", err)
+	}
+
+	for _, req := range requests {
+		wonderNet, err := s.wonderNetRepository.Get(ctx, req.WonderNetID)
+		if err != nil {
+			slog.Warn("get wonder net of expired access request", "id", req.ID, "error", err)
+			continue
+		}
+		if wonderNet == nil {
+			continue
+		}
+		if err := s.end(ctx, wonderNet, req, repository.AccessRequestExpired); err != nil {
+			slog.Warn("expire access request", "id", req.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Run expires due access requests every interval until ctx is cancelled.
+func (s *AccessRequestService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireDue(ctx); err != nil {
+				slog.Error("expire access requests", "error", err)
+			}
+		}
+	}
+}
+
+// end removes the grant of an approved request and records why access ended.
+// A grant that is already gone, for example because the service was
+// deleted, counts as removed.
+func (s *AccessRequestService) end(ctx context.Context, wonderNet *repository.WonderNet, req *repository.AccessRequest, status string) error {
+	err := s.serviceCatalogService.RevokeAccess(ctx, wonderNet, req.ServiceID, req.GrantID)
+	if err != nil && !errors.Is(err, ErrServiceNotFound) && !errors.Is(err, ErrServiceGrantNotFound) {
+		return fmt.Errorf("revoke service grant: %w", err)
+	}
+
+	ended, err := s.accessRequestRepository.End(ctx, req.ID, status)
+	if err != nil {
+		return err
+	}
+	if !ended {
+		return ErrAccessRequestNotActive
+	}
+
+	slog.Info("ended access request", "id", req.ID, "wonder_net_id", wonderNet.ID, "status", status)
+	return nil
+}
+
+func (s *AccessRequestService) getOwnedRequest(ctx context.Context, wonderNet *repository.WonderNet, requestID string) (*repository.AccessRequest, error) {
+	req, err := s.accessRequestRepository.Get(ctx, requestID)
+	if err != nil {
+		return nil, err
+	}
+	if req == nil || req.WonderNetID != wonderNet.ID {
+		return nil, ErrAccessRequestNotFound
+	}
+	return req, nil
+}
+
+// validateAccessRequest checks the parts of an access request that do not
+// depend on the wonder net.
+func validateAccessRequest(requester, reason string, duration time.Duration) error {
+	if requester == "" {
+		return fmt.Errorf("%w: requester is required", ErrInvalidAccessRequest)
+	}
+	if len(reason) > maxAccessRequestReasonLength {
+		return fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidAccessRequest, maxAccessRequestReasonLength)
+	}
+	if duration < MinAccessRequestDuration || duration > MaxAccessRequestDuration {
+		return fmt.Errorf("%w: duration must be between %s and %s", ErrInvalidAccessRequest, MinAccessRequestDuration, MaxAccessRequestDuration)
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestValidateAccessRequest(t *testing.T) {
+	tests := []struct {
+		name      string
+		requester string
+		reason    string
+		duration  time.Duration
+		wantErr   bool
+	}{
+		{"valid", "alice", "debug slow queries", 4 * time.Hour, false},
+		{"no reason", "alice", "", time.Hour, false},
+		{"shortest duration", "alice", "", MinAccessRequestDuration, false},
+		{"longest duration", "alice", "", MaxAccessRequestDuration, false},
+		{"missing requester", "", "", time.Hour, true},
+		{"reason too long", "alice", strings.Repeat("a", maxAccessRequestReasonLength+1), time.Hour, true},
+		{"duration too short", "alice", "", 30 * time.Second, true},
+		{"duration too long", "alice", "", MaxAccessRequestDuration + time.Second, true},
+		{"negative duration", "alice", "", -time.Hour, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateAccessRequest(tt.requester, tt.reason, tt.duration)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateAccessRequest() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidAccessRequest) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidAccessRequest)
+			}
+		})
+	}
+}
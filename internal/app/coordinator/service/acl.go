@@ -0,0 +1,96 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+)
+
+var ErrInvalidACLRules = errors.New("invalid acl rules")
+
+// GetACLRules returns the owner-defined ACL rules of a wonder net, in the
+// form they were submitted.
+func (s *WonderNetService) GetACLRules(wonderNet *repository.WonderNet) ([]headscale.ACLRule, error) {
+	return decodeACLRules(wonderNet.ACLRules)
+}
+
+// SetACLRules validates rules, merges them into the global Headscale policy,
+// and stores them. Rules can only reference the wonder net's own nodes, and
+// they are additive to the isolation policy. An empty list removes them.
+// They need Headscale, as they select nodes by Headscale user.
+func (s *WonderNetService) SetACLRules(ctx context.Context, wonderNet *repository.WonderNet, rules []headscale.ACLRule) error {
+	if s.realms != nil {
+		return ErrUnsupportedByMeshBackend
+	}
+
+	policy, err := headscale.ScopeWonderNetRules(wonderNet.HeadscaleUser, rules)
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidACLRules, err)
+	}
+
+	encoded := ""
+	if len(rules) > 0 {
+		data, err := json.Marshal(rules)
+		if err != nil {
+			return fmt.Errorf("encode acl rules: %w", err)
+		}
+		encoded = string(data)
+	}
+
+	if err := s.aclManager.SetWonderNetPolicy(ctx, wonderNet.HeadscaleUser, policy); err != nil {
+		return err
+	}
+	if err := s.wonderNetRepository.UpdateACLRules(ctx, wonderNet.ID, encoded); err != nil {
+		return fmt.Errorf("update acl rules: %w", err)
+	}
+	wonderNet.ACLRules = encoded
+	return nil
+}
+
+// loadACLRules hands the stored rules of every wonder net to the ACL manager
+// so they are merged into the next policy write. Rules that no longer
+// validate are skipped rather than blocking the whole policy.
+func (s *WonderNetService) loadACLRules(ctx context.Context) error {
+	if s.aclManager == nil {
+		return nil
+	}
+
+	wonderNets, err := s.wonderNetRepository.ListWithACLRules(ctx)
+	if err != nil {
+		return fmt.Errorf("list wonder nets with acl rules: %w", err)
+	}
+
+	policies := make(map[string]*headscale.ACLPolicy, len(wonderNets))
+	for _, wonderNet := range wonderNets {
+		rules, err := decodeACLRules(wonderNet.ACLRules)
+		if err != nil {
+			slog.Warn("decode acl rules", "wonder_net_id", wonderNet.ID, "error", err)
+			continue
+		}
+		policy, err := headscale.ScopeWonderNetRules(wonderNet.HeadscaleUser, rules)
+		if err != nil {
+			slog.Warn("skip invalid acl rules", "wonder_net_id", wonderNet.ID, "error", err)
+			continue
+		}
+		policies[wonderNet.HeadscaleUser] = policy
+	}
+
+	s.aclManager.LoadWonderNetPolicies(policies)
+	return nil
+}
+
+func decodeACLRules(encoded string) ([]headscale.ACLRule, error) {
+	rules := []headscale.ACLRule{}
+	if encoded == "" {
+		return rules, nil
+	}
+	if err := json.Unmarshal([]byte(encoded), &rules); err != nil {
+		return nil, fmt.Errorf("decode acl rules: %w", err)
+	}
+	return rules, nil
+}
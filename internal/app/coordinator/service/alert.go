@@ -0,0 +1,462 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// Alert rule kinds.
+const (
+	// AlertKindNodeOffline fires when a node has been offline for longer than the threshold.
+	AlertKindNodeOffline = "node_offline"
+	// AlertKindHeartbeatMissing fires when a node has not been seen by the mesh
+	// for longer than the threshold, regardless of its reported online state.
+	AlertKindHeartbeatMissing = "heartbeat_missing"
+)
+
+var (
+	ErrAlertRuleNotFound    = errors.New("alert rule not found")
+	ErrAlertSilenceNotFound = errors.New("alert silence not found")
+	ErrUnsupportedAlertKind = errors.New("unsupported alert kind")
+)
+
+// Alert is a currently firing alert for a single node.
+type Alert struct {
+	RuleID      string
+	RuleName    string
+	Kind        string
+	WonderNetID string
+	NodeID      uint64
+	NodeName    string
+	StartsAt    time.Time
+	Silenced    bool
+}
+
+// AlertEvent is delivered to an AlertNotifier when an alert fires or resolves.
+type AlertEvent struct {
+	Alert    Alert
+	Resolved bool
+}
+
+// AlertNotifier delivers alert events to an external destination.
+type AlertNotifier interface {
+	Notify(ctx context.Context, event AlertEvent) error
+}
+
+// LogAlertNotifier writes alert events to the structured log.
+type LogAlertNotifier struct{}
+
+// Notify logs the alert event.
+func (LogAlertNotifier) Notify(_ context.Context, event AlertEvent) error {
+	state := "firing"
+	if event.Resolved {
+		state = "resolved"
+	}
+	slog.Warn("alert "+state,
+		"rule_id", event.Alert.RuleID,
+		"rule_name", event.Alert.RuleName,
+		"kind", event.Alert.Kind,
+		"wonder_net_id", event.Alert.WonderNetID,
+		"node", event.Alert.NodeName,
+		"starts_at", event.Alert.StartsAt)
+	return nil
+}
+
+// AlertNotifiers delivers alert events to each of its notifiers in turn.
+type AlertNotifiers []AlertNotifier
+
+// Notify delivers the event to every notifier, even if some of them fail.
+func (n AlertNotifiers) Notify(ctx context.Context, event AlertEvent) error {
+	var errs []error
+	for _, notifier := range n {
+		if err := notifier.Notify(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// AlertService manages alert rules and silences, and evaluates rules
+// against node data in the background. Firing alerts are stored, so a
+// restart neither notifies them again nor misses that they resolved.
+type AlertService struct {
+	alertRepository     *repository.AlertRepository
+	wonderNetRepository *repository.WonderNetRepository
+	nodesService        *NodesService
+	notifier            AlertNotifier
+
+	mu     sync.Mutex
+	firing map[string]*Alert
+	// restored reports whether the stored firing alerts have been loaded.
+	restored bool
+}
+
+// NewAlertService creates a new AlertService.
+func NewAlertService(
+	alertRepository *repository.AlertRepository,
+	wonderNetRepository *repository.WonderNetRepository,
+	nodesService *NodesService,
+	notifier AlertNotifier,
+) *AlertService {
+	return &AlertService{
+		alertRepository:     alertRepository,
+		wonderNetRepository: wonderNetRepository,
+		nodesService:        nodesService,
+		notifier:            notifier,
+		firing:              make(map[string]*Alert),
+	}
+}
+
+// CreateRule creates a new alert rule for a wonder net.
+func (s *AlertService) CreateRule(ctx context.Context, wonderNetID, name, kind string, threshold time.Duration) (*repository.AlertRule, error) {
+	if kind != AlertKindNodeOffline && kind != AlertKindHeartbeatMissing {
+		return nil, ErrUnsupportedAlertKind
+	}
+
+	rule, err := s.alertRepository.CreateRule(ctx, &repository.AlertRule{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNetID,
+		Name:        name,
+		Kind:        kind,
+		Threshold:   threshold,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created alert rule", "id", rule.ID, "wonder_net_id", wonderNetID, "kind", kind, "threshold", threshold)
+	return rule, nil
+}
+
+// ListRules lists all alert rules for a wonder net.
+func (s *AlertService) ListRules(ctx context.Context, wonderNetID string) ([]*repository.AlertRule, error) {
+	return s.alertRepository.ListRulesByWonderNet(ctx, wonderNetID)
+}
+
+// DeleteRule deletes an alert rule and drops any alerts it was firing.
+func (s *AlertService) DeleteRule(ctx context.Context, wonderNetID, ruleID string) error {
+	rule, err := s.alertRepository.GetRule(ctx, ruleID)
+	if err != nil {
+		return err
+	}
+	if rule == nil || rule.WonderNetID != wonderNetID {
+		return ErrAlertRuleNotFound
+	}
+
+	if err := s.alertRepository.DeleteRule(ctx, ruleID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	for key, alert := range s.firing {
+		if alert.RuleID == ruleID {
+			delete(s.firing, key)
+		}
+	}
+	s.mu.Unlock()
+
+	slog.Info("deleted alert rule", "id", ruleID, "wonder_net_id", wonderNetID)
+	return nil
+}
+
+// CreateSilence creates a silence for a wonder net. Empty ruleID or nodeName
+// match any rule or node.
+func (s *AlertService) CreateSilence(ctx context.Context, wonderNetID, ruleID, nodeName, reason string, endsAt time.Time) (*repository.AlertSilence, error) {
+	if ruleID != "" {
+		rule, err := s.alertRepository.GetRule(ctx, ruleID)
+		if err != nil {
+			return nil, err
+		}
+		if rule == nil || rule.WonderNetID != wonderNetID {
+			return nil, ErrAlertRuleNotFound
+		}
+	}
+
+	silence, err := s.alertRepository.CreateSilence(ctx, &repository.AlertSilence{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNetID,
+		RuleID:      ruleID,
+		NodeName:    nodeName,
+		Reason:      reason,
+		EndsAt:      endsAt,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created alert silence", "id", silence.ID, "wonder_net_id", wonderNetID, "ends_at", endsAt)
+	return silence, nil
+}
+
+// ListSilences lists all alert silences for a wonder net.
+func (s *AlertService) ListSilences(ctx context.Context, wonderNetID string) ([]*repository.AlertSilence, error) {
+	return s.alertRepository.ListSilencesByWonderNet(ctx, wonderNetID)
+}
+
+// DeleteSilence deletes an alert silence.
+func (s *AlertService) DeleteSilence(ctx context.Context, wonderNetID, silenceID string) error {
+	silence, err := s.alertRepository.GetSilence(ctx, silenceID)
+	if err != nil {
+		return err
+	}
+	if silence == nil || silence.WonderNetID != wonderNetID {
+		return ErrAlertSilenceNotFound
+	}
+
+	if err := s.alertRepository.DeleteSilence(ctx, silenceID); err != nil {
+		return err
+	}
+
+	slog.Info("deleted alert silence", "id", silenceID, "wonder_net_id", wonderNetID)
+	return nil
+}
+
+// ListAlerts returns the currently firing alerts for a wonder net.
+func (s *AlertService) ListAlerts(wonderNetID string) []Alert {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alerts := make([]Alert, 0)
+	for _, alert := range s.firing {
+		if alert.WonderNetID == wonderNetID {
+			alerts = append(alerts, *alert)
+		}
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		return alerts[i].StartsAt.Before(alerts[j].StartsAt)
+	})
+	return alerts
+}
+
+// Run evaluates all alert rules every interval until ctx is cancelled.
+func (s *AlertService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Evaluate(ctx); err != nil {
+				slog.Error("evaluate alert rules", "error", err)
+			}
+		}
+	}
+}
+
+// Evaluate checks every alert rule against the current node state and
+// notifies on alerts that start firing or resolve.
+func (s *AlertService) Evaluate(ctx context.Context) error {
+	rules, err := s.alertRepository.ListRules(ctx)
+	if err != nil {
+		return err
+	}
+
+	if err := s.restore(ctx, rules); err != nil {
+		return fmt.Errorf("restore firing alerts: %w", err)
+	}
+
+	rulesByWonderNet := make(map[string][]*repository.AlertRule)
+	for _, rule := range rules {
+		rulesByWonderNet[rule.WonderNetID] = append(rulesByWonderNet[rule.WonderNetID], rule)
+	}
+
+	now := time.Now()
+	active := make(map[string]*Alert)
+	evaluated := make(map[string]bool)
+
+	for wonderNetID, wonderNetRules := range rulesByWonderNet {
+		wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+		if err != nil {
+			slog.Warn("get wonder net for alert evaluation", "wonder_net_id", wonderNetID, "error", err)
+			continue
+		}
+		if wonderNet == nil {
+			continue
+		}
+
+		nodes, err := s.nodesService.ListNodes(ctx, wonderNet)
+		if err != nil {
+			slog.Warn("list nodes for alert evaluation", "wonder_net_id", wonderNetID, "error", err)
+			continue
+		}
+
+		silences, err := s.alertRepository.ListSilencesByWonderNet(ctx, wonderNetID)
+		if err != nil {
+			slog.Warn("list alert silences", "wonder_net_id", wonderNetID, "error", err)
+		}
+
+		evaluated[wonderNetID] = true
+		for _, rule := range wonderNetRules {
+			for _, node := range nodes {
+				if !ruleMatches(rule, node, now) {
+					continue
+				}
+				alert := &Alert{
+					RuleID:      rule.ID,
+					RuleName:    rule.Name,
+					Kind:        rule.Kind,
+					WonderNetID: wonderNetID,
+					NodeID:      node.ID,
+					NodeName:    node.Name,
+					StartsAt:    now,
+					Silenced:    isSilenced(silences, rule.ID, node.Name, now),
+				}
+				active[alertKey(rule.ID, node.Name)] = alert
+			}
+		}
+	}
+
+	var events []AlertEvent
+	var changed, resolved []*Alert
+
+	s.mu.Lock()
+	for key, alert := range active {
+		if prev, ok := s.firing[key]; ok {
+			alert.StartsAt = prev.StartsAt
+			if alert.Silenced != prev.Silenced || alert.NodeID != prev.NodeID {
+				changed = append(changed, alert)
+			}
+		} else {
+			changed = append(changed, alert)
+			if !alert.Silenced {
+				events = append(events, AlertEvent{Alert: *alert})
+			}
+		}
+		s.firing[key] = alert
+	}
+	for key, alert := range s.firing {
+		if _, ok := active[key]; ok {
+			continue
+		}
+		if !evaluated[alert.WonderNetID] && rulesByWonderNet[alert.WonderNetID] != nil {
+			continue
+		}
+		delete(s.firing, key)
+		resolved = append(resolved, alert)
+		if !alert.Silenced {
+			events = append(events, AlertEvent{Alert: *alert, Resolved: true})
+		}
+	}
+	s.mu.Unlock()
+
+	for _, alert := range changed {
+		if err := s.alertRepository.SaveFiring(ctx, firingAlert(alert)); err != nil {
+			slog.Warn("save firing alert", "rule_id", alert.RuleID, "node", alert.NodeName, "error", err)
+		}
+	}
+	for _, alert := range resolved {
+		if err := s.alertRepository.DeleteFiring(ctx, alert.RuleID, alert.NodeName); err != nil {
+			slog.Warn("delete resolved alert", "rule_id", alert.RuleID, "node", alert.NodeName, "error", err)
+		}
+	}
+
+	for _, event := range events {
+		if err := s.notifier.Notify(ctx, event); err != nil {
+			slog.Warn("notify alert", "rule_id", event.Alert.RuleID, "node", event.Alert.NodeName, "error", err)
+		}
+	}
+
+	return nil
+}
+
+// restore loads the alerts that were firing when the coordinator last
+// stopped, once, before the first evaluation. Stored alerts of rules that
+// no longer exist are skipped.
+func (s *AlertService) restore(ctx context.Context, rules []*repository.AlertRule) error {
+	s.mu.Lock()
+	restored := s.restored
+	s.mu.Unlock()
+	if restored {
+		return nil
+	}
+
+	stored, err := s.alertRepository.ListFiring(ctx)
+	if err != nil {
+		return err
+	}
+	rulesByID := make(map[string]*repository.AlertRule, len(rules))
+	for _, rule := range rules {
+		rulesByID[rule.ID] = rule
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, firing := range stored {
+		rule := rulesByID[firing.RuleID]
+		if rule == nil {
+			continue
+		}
+		key := alertKey(firing.RuleID, firing.NodeName)
+		if _, ok := s.firing[key]; ok {
+			continue
+		}
+		s.firing[key] = &Alert{
+			RuleID:      rule.ID,
+			RuleName:    rule.Name,
+			Kind:        rule.Kind,
+			WonderNetID: firing.WonderNetID,
+			NodeID:      firing.NodeID,
+			NodeName:    firing.NodeName,
+			StartsAt:    firing.StartsAt,
+			Silenced:    firing.Silenced,
+		}
+	}
+	s.restored = true
+	slog.Info("restored firing alerts", "count", len(s.firing))
+	return nil
+}
+
+func firingAlert(alert *Alert) *repository.FiringAlert {
+	return &repository.FiringAlert{
+		RuleID:      alert.RuleID,
+		NodeName:    alert.NodeName,
+		WonderNetID: alert.WonderNetID,
+		NodeID:      alert.NodeID,
+		StartsAt:    alert.StartsAt,
+		Silenced:    alert.Silenced,
+	}
+}
+
+func alertKey(ruleID, nodeName string) string {
+	return ruleID + "/" + nodeName
+}
+
+func ruleMatches(rule *repository.AlertRule, node *Node, now time.Time) bool {
+	switch rule.Kind {
+	case AlertKindNodeOffline:
+		if node.Online {
+			return false
+		}
+		return node.LastSeen == nil || now.Sub(*node.LastSeen) > rule.Threshold
+	case AlertKindHeartbeatMissing:
+		return node.LastSeen == nil || now.Sub(*node.LastSeen) > rule.Threshold
+	default:
+		return false
+	}
+}
+
+func isSilenced(silences []*repository.AlertSilence, ruleID, nodeName string, now time.Time) bool {
+	for _, silence := range silences {
+		if !now.Before(silence.EndsAt) {
+			continue
+		}
+		if silence.RuleID != "" && silence.RuleID != ruleID {
+			continue
+		}
+		if silence.NodeName != "" && silence.NodeName != nodeName {
+			continue
+		}
+		return true
+	}
+	return false
+}
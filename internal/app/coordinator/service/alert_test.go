@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestRuleMatches(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-5 * time.Minute)
+	stale := now.Add(-2 * time.Hour)
+
+	offline := &repository.AlertRule{Kind: AlertKindNodeOffline, Threshold: 30 * time.Minute}
+	heartbeat := &repository.AlertRule{Kind: AlertKindHeartbeatMissing, Threshold: 30 * time.Minute}
+
+	tests := []struct {
+		name string
+		rule *repository.AlertRule
+		node *Node
+		want bool
+	}{
+		{"offline rule, online node", offline, &Node{Online: true, LastSeen: &stale}, false},
+		{"offline rule, recently offline", offline, &Node{Online: false, LastSeen: &recent}, false},
+		{"offline rule, offline past threshold", offline, &Node{Online: false, LastSeen: &stale}, true},
+		{"offline rule, never seen", offline, &Node{Online: false}, true},
+		{"heartbeat rule, recent", heartbeat, &Node{Online: true, LastSeen: &recent}, false},
+		{"heartbeat rule, stale but online", heartbeat, &Node{Online: true, LastSeen: &stale}, true},
+		{"unknown kind", &repository.AlertRule{Kind: "disk_usage"}, &Node{}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := ruleMatches(tt.rule, tt.node, now); got != tt.want {
+				t.Errorf("ruleMatches() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestIsSilenced(t *testing.T) {
+	now := time.Now()
+	future := now.Add(time.Hour)
+	past := now.Add(-time.Hour)
+
+	tests := []struct {
+		name     string
+		silences []*repository.AlertSilence
+		want     bool
+	}{
+		{"no silences", nil, false},
+		{"expired silence", []*repository.AlertSilence{{EndsAt: past}}, false},
+		{"wildcard silence", []*repository.AlertSilence{{EndsAt: future}}, true},
+		{"matching rule", []*repository.AlertSilence{{RuleID: "rule-1", EndsAt: future}}, true},
+		{"other rule", []*repository.AlertSilence{{RuleID: "rule-2", EndsAt: future}}, false},
+		{"matching node", []*repository.AlertSilence{{NodeName: "node-a", EndsAt: future}}, true},
+		{"other node", []*repository.AlertSilence{{NodeName: "node-b", EndsAt: future}}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isSilenced(tt.silences, "rule-1", "node-a", now); got != tt.want {
+				t.Errorf("isSilenced() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+type recordingAlertNotifier struct {
+	events []AlertEvent
+	err    error
+}
+
+func (n *recordingAlertNotifier) Notify(_ context.Context, event AlertEvent) error {
+	n.events = append(n.events, event)
+	return n.err
+}
+
+func TestAlertNotifiers(t *testing.T) {
+	failing := &recordingAlertNotifier{err: errors.New("smtp down")}
+	working := &recordingAlertNotifier{}
+	event := AlertEvent{Alert: Alert{RuleID: "rule-1", NodeName: "db-1"}}
+
+	err := AlertNotifiers{failing, working}.Notify(context.Background(), event)
+	if err == nil || !strings.Contains(err.Error(), "smtp down") {
+		t.Errorf("Notify() error = %v, want the failing notifier's error", err)
+	}
+	if len(working.events) != 1 || working.events[0].Alert.RuleID != "rule-1" {
+		t.Errorf("notifier after a failing one got %+v, want the event", working.events)
+	}
+}
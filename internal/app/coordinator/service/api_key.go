@@ -3,7 +3,10 @@ package service
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"net/netip"
+	"slices"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,88 +15,237 @@ import (
 )
 
 var (
-	ErrAPIKeyNotFound = errors.New("api key not found")
-	ErrAPIKeyExpired  = errors.New("api key expired")
+	ErrAPIKeyNotFound     = errors.New("api key not found")
+	ErrAPIKeyExpired      = errors.New("api key expired")
+	ErrInvalidAPIKeyScope = errors.New("invalid api key scope")
+	ErrAPIKeyMissingScope = errors.New("api key lacks scope")
+	ErrAPIKeyRotated      = errors.New("api key already rotated")
 )
 
+// API key scopes. A key may only be used for requests that need one of its
+// scopes; APIKeyScopeAdmin grants all of them.
+const (
+	// APIKeyScopeNodesRead allows reading nodes and the other read-only
+	// wonder net state: alerts, DNS, services, access requests,
+	// decommissions, stats, and routes.
+	APIKeyScopeNodesRead = "nodes:read"
+	// APIKeyScopeNodesWrite allows requesting access to nodes.
+	APIKeyScopeNodesWrite = "nodes:write"
+	// APIKeyScopeTokensCreate allows creating worker join tokens.
+	APIKeyScopeTokensCreate = "tokens:create"
+	// APIKeyScopeDeployerJoin allows joining deployers and using the mesh proxy.
+	APIKeyScopeDeployerJoin = "deployer:join"
+	// APIKeyScopeIntrospect allows checking whether API keys and join
+	// tokens of the wonder net are valid.
+	APIKeyScopeIntrospect = "introspect"
+	// APIKeyScopeAdmin grants every scope. Keys only have it when it was
+	// asked for.
+	APIKeyScopeAdmin = "admin"
+)
+
+// APIKeyScopes lists the scopes an API key can be created with.
+// Keys created without scopes are read-only: they get APIKeyScopeNodesRead.
+// Keys created before scopes existed were given APIKeyScopeAdmin by the
+// 002 migration, keeping the access they had.
+var APIKeyScopes = []string{
+	APIKeyScopeNodesRead,
+	APIKeyScopeNodesWrite,
+	APIKeyScopeTokensCreate,
+	APIKeyScopeDeployerJoin,
+	APIKeyScopeIntrospect,
+	APIKeyScopeAdmin,
+}
+
+// StaleAPIKeyAge is how long an API key can go unused before it is flagged
+// as a candidate for revocation.
+const StaleAPIKeyAge = 90 * 24 * time.Hour
+
+// DefaultAPIKeyRotationOverlap is how long a rotated API key keeps working
+// alongside its successor unless the rotation asks otherwise, and
+// MaxAPIKeyRotationOverlap the longest it may.
+const (
+	DefaultAPIKeyRotationOverlap = 24 * time.Hour
+	MaxAPIKeyRotationOverlap     = 30 * 24 * time.Hour
+)
+
+// apiKeyUsageTimeout bounds writing a batch of API key uses.
+const apiKeyUsageTimeout = 30 * time.Second
+
 // APIKeyDetails contains the details of a newly created API key.
 // The raw key is only available at creation time.
 type APIKeyDetails struct {
-	ID        string
-	Name      string
-	Key       string
-	KeyPrefix string
-	ExpiresAt *time.Time
+	ID           string
+	Name         string
+	Key          string
+	KeyPrefix    string
+	ExpiresAt    *time.Time
+	Scopes       []string
+	AllowedCIDRs []string
 }
 
 // APIKeyInfo contains information about an existing API key (no raw key).
+// Stale is set when the key has not been used for StaleAPIKeyAge, and
+// Expiring when it expires within NotificationAPIKeyExpiryWarning and has
+// not been rotated. DeprecatedAt and SuccessorID are set for rotated keys.
 type APIKeyInfo struct {
-	ID         string
-	Name       string
-	KeyPrefix  string
-	CreatedAt  time.Time
-	LastUsedAt *time.Time
-	ExpiresAt  *time.Time
+	ID           string
+	Name         string
+	KeyPrefix    string
+	CreatedAt    time.Time
+	LastUsedAt   *time.Time
+	ExpiresAt    *time.Time
+	Scopes       []string
+	AllowedCIDRs []string
+	RequestCount int64
+	Endpoints    []*APIKeyEndpointUsage
+	Stale        bool
+	Expiring     bool
+	DeprecatedAt *time.Time
+	SuccessorID  string
+}
+
+// APIKeyRotation is the outcome of rotating an API key: its successor, and
+// when the rotated key stops working.
+type APIKeyRotation struct {
+	Successor           *APIKeyDetails
+	RotatedKeyExpiresAt time.Time
+}
+
+// APIKeyEndpointUsage counts the requests an API key made to one endpoint,
+// identified by its route pattern such as "GET /coordinator/api/v1/nodes".
+type APIKeyEndpointUsage struct {
+	Endpoint     string
+	RequestCount int64
+	LastUsedAt   time.Time
 }
 
 // APIKeyService manages API keys for third-party integrations.
 type APIKeyService struct {
 	apiKeyRepository    *repository.APIKeyRepository
 	wonderNetRepository *repository.WonderNetRepository
+	quotaService        *QuotaService
+	usageRecorder       *APIKeyUsageRecorder
 }
 
 // NewAPIKeyService creates a new APIKeyService.
 func NewAPIKeyService(
 	apiKeyRepository *repository.APIKeyRepository,
 	wonderNetRepository *repository.WonderNetRepository,
+	quotaService *QuotaService,
+	usageRecorder *APIKeyUsageRecorder,
 ) *APIKeyService {
 	return &APIKeyService{
 		apiKeyRepository:    apiKeyRepository,
 		wonderNetRepository: wonderNetRepository,
+		quotaService:        quotaService,
+		usageRecorder:       usageRecorder,
+	}
+}
+
+// CreateAPIKey creates a new API key for a wonder net with the given scopes,
+// or APIKeyScopeNodesRead if none are given. A non-empty allowedCIDRs limits
+// the client addresses the key may be used from. It returns an error
+// wrapping ErrQuotaExceeded if the wonder net is at its API key limit.
+func (s *APIKeyService) CreateAPIKey(ctx context.Context, wonderNetID, name string, expiresAt *time.Time, scopes, allowedCIDRs []string) (*APIKeyDetails, error) {
+	for _, scope := range scopes {
+		if !slices.Contains(APIKeyScopes, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidAPIKeyScope, scope)
+		}
+	}
+	if len(scopes) == 0 {
+		scopes = []string{APIKeyScopeNodesRead}
+	}
+	scopes = slices.Compact(slices.Sorted(slices.Values(scopes)))
+
+	allowedCIDRs, err := NormalizeAllowedCIDRs(allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.quotaService.CheckAPIKeys(ctx, wonderNetID); err != nil {
+		return nil, err
 	}
+
+	details, err := s.createAPIKey(ctx, wonderNetID, name, expiresAt, scopes, allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created api key", "id", details.ID, "wonder_net_id", wonderNetID, "name", name, "scopes", scopes, "allowed_cidrs", allowedCIDRs)
+	return details, nil
 }
 
-// CreateAPIKey creates a new API key for a wonder net.
-func (s *APIKeyService) CreateAPIKey(ctx context.Context, wonderNetID, name string, expiresAt *time.Time) (*APIKeyDetails, error) {
+// createAPIKey generates and stores a key with validated scopes and ranges.
+func (s *APIKeyService) createAPIKey(ctx context.Context, wonderNetID, name string, expiresAt *time.Time, scopes, allowedCIDRs []string) (*APIKeyDetails, error) {
 	key, err := apikey.Generate()
 	if err != nil {
 		return nil, err
 	}
 
 	id := uuid.New().String()
-	_, err = s.apiKeyRepository.Create(ctx, id, wonderNetID, name, key.Hash, key.Prefix, expiresAt)
+	_, err = s.apiKeyRepository.Create(ctx, id, wonderNetID, name, key.Hash, key.Prefix, expiresAt, scopes, allowedCIDRs)
 	if err != nil {
 		return nil, err
 	}
 
-	slog.Info("created api key", "id", id, "wonder_net_id", wonderNetID, "name", name)
-
 	return &APIKeyDetails{
-		ID:        id,
-		Name:      name,
-		Key:       key.Raw,
-		KeyPrefix: key.Prefix,
-		ExpiresAt: expiresAt,
+		ID:           id,
+		Name:         name,
+		Key:          key.Raw,
+		KeyPrefix:    key.Prefix,
+		ExpiresAt:    expiresAt,
+		Scopes:       scopes,
+		AllowedCIDRs: allowedCIDRs,
 	}, nil
 }
 
-// ListAPIKeys lists all API keys for a wonder net (without raw keys).
-func (s *APIKeyService) ListAPIKeys(ctx context.Context, wonderNetID string) ([]*APIKeyInfo, error) {
+// ListAPIKeys lists all API keys for a wonder net (without raw keys), with
+// their request counts per endpoint. A positive expiringWithin lists only
+// the keys that expire within it and have not been rotated.
+func (s *APIKeyService) ListAPIKeys(ctx context.Context, wonderNetID string, expiringWithin time.Duration) ([]*APIKeyInfo, error) {
 	keys, err := s.apiKeyRepository.ListByWonderNet(ctx, wonderNetID)
 	if err != nil {
 		return nil, err
 	}
+	now := time.Now()
+	if expiringWithin > 0 {
+		keys = expiringAPIKeys(keys, expiringWithin, now)
+	}
+
+	usage, err := s.apiKeyRepository.ListUsageByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	endpoints := make(map[string][]*APIKeyEndpointUsage)
+	for _, u := range usage {
+		endpoints[u.APIKeyID] = append(endpoints[u.APIKeyID], &APIKeyEndpointUsage{
+			Endpoint:     u.Endpoint,
+			RequestCount: u.RequestCount,
+			LastUsedAt:   u.LastUsedAt,
+		})
+	}
 
 	infos := make([]*APIKeyInfo, len(keys))
 	for i, key := range keys {
-		infos[i] = &APIKeyInfo{
-			ID:         key.ID,
-			Name:       key.Name,
-			KeyPrefix:  key.KeyPrefix,
-			CreatedAt:  key.CreatedAt,
-			LastUsedAt: key.LastUsedAt,
-			ExpiresAt:  key.ExpiresAt,
+		info := &APIKeyInfo{
+			ID:           key.ID,
+			Name:         key.Name,
+			KeyPrefix:    key.KeyPrefix,
+			CreatedAt:    key.CreatedAt,
+			LastUsedAt:   key.LastUsedAt,
+			ExpiresAt:    key.ExpiresAt,
+			Scopes:       apiKeyScopes(key),
+			AllowedCIDRs: key.AllowedCIDRs,
+			Endpoints:    endpoints[key.ID],
+			Stale:        isStaleAPIKey(key, now),
+			Expiring:     isExpiringAPIKey(key, NotificationAPIKeyExpiryWarning, now),
+			DeprecatedAt: key.DeprecatedAt,
+			SuccessorID:  key.SuccessorID,
 		}
+		for _, e := range info.Endpoints {
+			info.RequestCount += e.RequestCount
+		}
+		infos[i] = info
 	}
 	return infos, nil
 }
@@ -119,34 +271,120 @@ func (s *APIKeyService) DeleteAPIKey(ctx context.Context, wonderNetID, keyID str
 	return nil
 }
 
-// ValidateAPIKey validates an API key and returns the associated wonder net.
-func (s *APIKeyService) ValidateAPIKey(ctx context.Context, rawKey string) (*repository.WonderNet, error) {
+// RotateAPIKey issues a successor to an API key, with the same name,
+// scopes, and allowed ranges and, if the key expires, the same lifetime
+// from now. The key is marked deprecated and keeps working for overlap, so
+// that clients can switch to the successor, unless it expires sooner. The
+// successor does not count against the API key quota, as it replaces the
+// key. It returns ErrAPIKeyRotated if the key was rotated before.
+func (s *APIKeyService) RotateAPIKey(ctx context.Context, wonderNetID, keyID string, overlap time.Duration) (*APIKeyRotation, error) {
+	key, err := s.apiKeyRepository.GetByID(ctx, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if key == nil || key.WonderNetID != wonderNetID {
+		return nil, ErrAPIKeyNotFound
+	}
+	if key.DeprecatedAt != nil {
+		return nil, ErrAPIKeyRotated
+	}
+	now := time.Now()
+	if key.ExpiresAt != nil && now.After(*key.ExpiresAt) {
+		return nil, ErrAPIKeyExpired
+	}
+
+	var expiresAt *time.Time
+	if key.ExpiresAt != nil {
+		t := now.Add(key.ExpiresAt.Sub(key.CreatedAt))
+		expiresAt = &t
+	}
+	successor, err := s.createAPIKey(ctx, wonderNetID, key.Name, expiresAt, apiKeyScopes(key), key.AllowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	rotatedExpiresAt := now.Add(overlap)
+	if key.ExpiresAt != nil && key.ExpiresAt.Before(rotatedExpiresAt) {
+		rotatedExpiresAt = *key.ExpiresAt
+	}
+	if err := s.apiKeyRepository.Deprecate(ctx, key.ID, successor.ID, rotatedExpiresAt); err != nil {
+		return nil, fmt.Errorf("deprecate rotated api key: %w", err)
+	}
+
+	slog.Info("rotated api key", "id", key.ID, "successor_id", successor.ID, "wonder_net_id", wonderNetID, "expires_at", rotatedExpiresAt)
+	return &APIKeyRotation{Successor: successor, RotatedKeyExpiresAt: rotatedExpiresAt}, nil
+}
+
+// ValidateAPIKey validates an API key for a request that needs scope and
+// returns the key and its wonder net. It returns an error wrapping
+// ErrAPIKeyMissingScope if the key lacks the scope, or ErrClientIPNotAllowed
+// if clientIP is outside the key's allowed ranges. endpoint is the route
+// pattern of the request; it is counted towards the key's usage unless empty.
+func (s *APIKeyService) ValidateAPIKey(ctx context.Context, rawKey, endpoint, scope string, clientIP netip.Addr) (*repository.WonderNet, *repository.APIKey, error) {
 	keyHash := apikey.Hash(rawKey)
 	key, err := s.apiKeyRepository.GetByHash(ctx, keyHash)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if key == nil {
-		return nil, ErrAPIKeyNotFound
+		return nil, nil, ErrAPIKeyNotFound
 	}
 
 	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
-		return nil, ErrAPIKeyExpired
+		return nil, nil, ErrAPIKeyExpired
 	}
 
-	go func() {
-		if err := s.apiKeyRepository.UpdateLastUsed(context.Background(), key.ID); err != nil {
-			slog.Warn("update api key last used", "error", err, "id", key.ID)
-		}
-	}()
+	if !hasAPIKeyScope(key, scope) {
+		return nil, nil, fmt.Errorf("%w: %s", ErrAPIKeyMissingScope, scope)
+	}
+
+	if err := checkClientIP(key.AllowedCIDRs, clientIP); err != nil {
+		slog.Warn("api key used from outside its allowed ranges", "id", key.ID, "client_ip", clientIP)
+		return nil, nil, err
+	}
+
+	s.usageRecorder.Record(key.ID, endpoint)
 
 	wonderNet, err := s.wonderNetRepository.Get(ctx, key.WonderNetID)
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 	if wonderNet == nil {
-		return nil, ErrNoWonderNet
+		return nil, nil, ErrNoWonderNet
 	}
 
-	return wonderNet, nil
+	return wonderNet, key, nil
+}
+
+// apiKeyScopes returns the scopes of a key, treating a key stored without
+// scopes as a read-only key.
+func apiKeyScopes(key *repository.APIKey) []string {
+	if len(key.Scopes) == 0 {
+		return []string{APIKeyScopeNodesRead}
+	}
+	return key.Scopes
+}
+
+// hasAPIKeyScope reports whether a key grants scope.
+func hasAPIKeyScope(key *repository.APIKey, scope string) bool {
+	scopes := apiKeyScopes(key)
+	return slices.Contains(scopes, APIKeyScopeAdmin) || slices.Contains(scopes, scope)
+}
+
+// isExpiringAPIKey reports whether a key expires after now but within d.
+// Rotated keys are left out: their expiry ends the overlap with their
+// successor and needs no action.
+func isExpiringAPIKey(key *repository.APIKey, d time.Duration, now time.Time) bool {
+	return key.DeprecatedAt == nil && key.ExpiresAt != nil &&
+		key.ExpiresAt.After(now) && !key.ExpiresAt.After(now.Add(d))
+}
+
+// isStaleAPIKey reports whether a key has gone unused for StaleAPIKeyAge.
+// Keys that were never used count from their creation.
+func isStaleAPIKey(key *repository.APIKey, now time.Time) bool {
+	lastActivity := key.CreatedAt
+	if key.LastUsedAt != nil {
+		lastActivity = *key.LastUsedAt
+	}
+	return now.Sub(lastActivity) >= StaleAPIKeyAge
 }
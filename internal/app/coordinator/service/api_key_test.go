@@ -0,0 +1,74 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestIsStaleAPIKey(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-24 * time.Hour)
+	old := now.Add(-StaleAPIKeyAge - time.Hour)
+
+	tests := []struct {
+		name string
+		key  *repository.APIKey
+		want bool
+	}{
+		{name: "new and unused", key: &repository.APIKey{CreatedAt: recent}, want: false},
+		{name: "old and never used", key: &repository.APIKey{CreatedAt: old}, want: true},
+		{name: "old but used recently", key: &repository.APIKey{CreatedAt: old, LastUsedAt: &recent}, want: false},
+		{name: "last used long ago", key: &repository.APIKey{CreatedAt: old, LastUsedAt: &old}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := isStaleAPIKey(tt.key, now); got != tt.want {
+				t.Errorf("isStaleAPIKey() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestHasAPIKeyScope(t *testing.T) {
+	tests := []struct {
+		name   string
+		scopes []string
+		scope  string
+		want   bool
+	}{
+		{name: "granted scope", scopes: []string{APIKeyScopeNodesRead}, scope: APIKeyScopeNodesRead, want: true},
+		{name: "missing scope", scopes: []string{APIKeyScopeNodesRead}, scope: APIKeyScopeTokensCreate, want: false},
+		{name: "one of several", scopes: []string{APIKeyScopeDeployerJoin, APIKeyScopeNodesRead}, scope: APIKeyScopeDeployerJoin, want: true},
+		{name: "admin grants all", scopes: []string{APIKeyScopeAdmin}, scope: APIKeyScopeTokensCreate, want: true},
+		{name: "key without scopes is read-only", scopes: nil, scope: APIKeyScopeDeployerJoin, want: false},
+		{name: "key without scopes reads nodes", scopes: nil, scope: APIKeyScopeNodesRead, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			key := &repository.APIKey{Scopes: tt.scopes}
+			if got := hasAPIKeyScope(key, tt.scope); got != tt.want {
+				t.Errorf("hasAPIKeyScope(%v, %q) = %v, want %v", tt.scopes, tt.scope, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAPIKeyUsageRecorderIsBounded(t *testing.T) {
+	r := &APIKeyUsageRecorder{pending: make(map[apiKeyUse]int64)}
+	for i := range maxPendingAPIKeyUsage + 5 {
+		r.Record(fmt.Sprintf("key-%d", i), "GET /coordinator/api/v1/nodes")
+	}
+	r.Record("key-0", "GET /coordinator/api/v1/nodes")
+
+	if len(r.pending) != maxPendingAPIKeyUsage || r.dropped != 5 {
+		t.Errorf("recorder holds %d uses and dropped %d, want %d and 5", len(r.pending), r.dropped, maxPendingAPIKeyUsage)
+	}
+	if got := r.pending[apiKeyUse{keyID: "key-0", endpoint: "GET /coordinator/api/v1/nodes"}]; got != 2 {
+		t.Errorf("key-0 count = %d, want 2", got)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// maxPendingAPIKeyUsage bounds the key and endpoint pairs held between
+// flushes; uses of further pairs are dropped until the next flush.
+const maxPendingAPIKeyUsage = 10000
+
+// apiKeyUse identifies the counter of an API key's requests to an endpoint.
+// An empty endpoint only marks the key as used.
+type apiKeyUse struct {
+	keyID    string
+	endpoint string
+}
+
+// APIKeyUsageRecorder counts the uses of API keys in memory and writes them
+// in batches from a single worker, so that requests neither wait on nor
+// start goroutines for usage writes. Close writes what is left.
+type APIKeyUsageRecorder struct {
+	apiKeyRepository *repository.APIKeyRepository
+
+	mu      sync.Mutex
+	pending map[apiKeyUse]int64
+	dropped int64
+
+	stop      chan struct{}
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// NewAPIKeyUsageRecorder creates a new APIKeyUsageRecorder and starts its
+// worker, which writes the recorded uses every interval.
+func NewAPIKeyUsageRecorder(apiKeyRepository *repository.APIKeyRepository, interval time.Duration) *APIKeyUsageRecorder {
+	r := &APIKeyUsageRecorder{
+		apiKeyRepository: apiKeyRepository,
+		pending:          make(map[apiKeyUse]int64),
+		stop:             make(chan struct{}),
+		done:             make(chan struct{}),
+	}
+	go r.run(interval)
+	return r
+}
+
+// Record counts a use of an API key for endpoint, which may be empty.
+func (r *APIKeyUsageRecorder) Record(keyID, endpoint string) {
+	use := apiKeyUse{keyID: keyID, endpoint: endpoint}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.pending[use]; !ok && len(r.pending) >= maxPendingAPIKeyUsage {
+		r.dropped++
+		return
+	}
+	r.pending[use]++
+}
+
+// Close stops the worker once it has written the uses recorded so far.
+func (r *APIKeyUsageRecorder) Close() {
+	r.closeOnce.Do(func() { close(r.stop) })
+	<-r.done
+}
+
+func (r *APIKeyUsageRecorder) run(interval time.Duration) {
+	defer close(r.done)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-r.stop:
+			r.flush()
+			return
+		case <-ticker.C:
+			r.flush()
+		}
+	}
+}
+
+// flush writes the pending uses: the last use of every key, and the request
+// count of every key and endpoint pair.
+func (r *APIKeyUsageRecorder) flush() {
+	r.mu.Lock()
+	pending, dropped := r.pending, r.dropped
+	r.pending, r.dropped = make(map[apiKeyUse]int64), 0
+	r.mu.Unlock()
+
+	if dropped > 0 {
+		slog.Warn("dropped api key usage", "uses", dropped)
+	}
+	if len(pending) == 0 {
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), apiKeyUsageTimeout)
+	defer cancel()
+
+	used := make(map[string]bool)
+	for use, count := range pending {
+		if !used[use.keyID] {
+			used[use.keyID] = true
+			if err := r.apiKeyRepository.UpdateLastUsed(ctx, use.keyID); err != nil {
+				slog.Warn("update api key last used", "error", err, "id", use.keyID)
+			}
+		}
+		if use.endpoint == "" {
+			continue
+		}
+		if err := r.apiKeyRepository.RecordUsage(ctx, use.keyID, use.endpoint, count); err != nil {
+			slog.Warn("record api key usage", "error", err, "id", use.keyID, "endpoint", use.endpoint)
+		}
+	}
+}
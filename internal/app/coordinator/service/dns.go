@@ -0,0 +1,437 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// Supported DNS record types.
+const (
+	DNSRecordA    = "A"
+	DNSRecordAAAA = "AAAA"
+)
+
+var (
+	ErrDNSDisabled       = errors.New("dns management is not enabled on this coordinator")
+	ErrInvalidDNSDomain  = errors.New("invalid dns domain")
+	ErrDNSDomainTaken    = errors.New("dns domain is used by another wonder net")
+	ErrDNSDomainNotSet   = errors.New("wonder net has no dns domain")
+	ErrInvalidDNSRecord  = errors.New("invalid dns record")
+	ErrDNSRecordNotFound = errors.New("dns record not found")
+)
+
+// DNSName is a name resolving to the mesh addresses of a node.
+type DNSName struct {
+	Node      string
+	FQDN      string
+	Addresses []string
+}
+
+// WonderNetDNS is the DNS configuration of a wonder net. Nodes and Records
+// are empty until the wonder net has a domain.
+type WonderNetDNS struct {
+	Domain     string
+	BaseDomain string
+	Nodes      []DNSName
+	Records    []*repository.DNSRecord
+}
+
+// extraRecord is an entry of Headscale's extra records file.
+type extraRecord struct {
+	Name  string `json:"Name"`
+	Type  string `json:"Type"`
+	Value string `json:"Value"`
+}
+
+// DNSService manages wonder net DNS names. Each wonder net may claim a
+// domain, under which its nodes are named <node>.<domain> and extra records
+// can be added. All names are published to Headscale as MagicDNS extra
+// records by rewriting the file at dns.extra_records_path, which Headscale
+// watches and reloads without a restart.
+type DNSService struct {
+	dnsRepository       *repository.DNSRepository
+	wonderNetRepository *repository.WonderNetRepository
+	nodesService        *NodesService
+	recordsPath         string
+	baseDomain          string
+
+	mu sync.Mutex
+	// written is the file content of the last sync, so that unchanged
+	// records are not rewritten.
+	written []byte
+}
+
+// NewDNSService creates a new DNSService writing extra records to
+// recordsPath. An empty recordsPath disables DNS management. A non-empty
+// baseDomain requires wonder net domains to be below it.
+func NewDNSService(
+	dnsRepository *repository.DNSRepository,
+	wonderNetRepository *repository.WonderNetRepository,
+	nodesService *NodesService,
+	recordsPath string,
+	baseDomain string,
+) *DNSService {
+	return &DNSService{
+		dnsRepository:       dnsRepository,
+		wonderNetRepository: wonderNetRepository,
+		nodesService:        nodesService,
+		recordsPath:         recordsPath,
+		baseDomain:          normalizeDNSName(baseDomain),
+	}
+}
+
+// Enabled reports whether DNS management is configured.
+func (s *DNSService) Enabled() bool {
+	return s.recordsPath != ""
+}
+
+// GetDNS returns the domain, node names and extra records of a wonder net.
+func (s *DNSService) GetDNS(ctx context.Context, wonderNet *repository.WonderNet) (*WonderNetDNS, error) {
+	if !s.Enabled() {
+		return nil, ErrDNSDisabled
+	}
+
+	result := &WonderNetDNS{BaseDomain: s.baseDomain, Nodes: []DNSName{}, Records: []*repository.DNSRecord{}}
+	domain, err := s.dnsRepository.GetDomain(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil {
+		return result, nil
+	}
+	result.Domain = domain.Domain
+
+	nodes, err := s.nodesService.ListNodes(ctx, wonderNet)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	result.Nodes = nodeDNSNames(domain.Domain, nodes)
+
+	result.Records, err = s.dnsRepository.ListRecordsByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// SetDomain sets the domain of a wonder net and publishes its names.
+func (s *DNSService) SetDomain(ctx context.Context, wonderNetID, domain string) (*repository.DNSDomain, error) {
+	if !s.Enabled() {
+		return nil, ErrDNSDisabled
+	}
+
+	domain = normalizeDNSName(domain)
+	if err := validateDNSDomain(domain, s.baseDomain); err != nil {
+		return nil, err
+	}
+	existing, err := s.dnsRepository.GetDomainByName(ctx, domain)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.WonderNetID != wonderNetID {
+		return nil, ErrDNSDomainTaken
+	}
+
+	result, err := s.dnsRepository.SetDomain(ctx, wonderNetID, domain)
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("set wonder net dns domain", "wonder_net_id", wonderNetID, "domain", domain)
+	s.syncAfterChange(ctx)
+	return result, nil
+}
+
+// CreateRecord adds an extra record named name under the wonder net's
+// domain and publishes it. Only A and AAAA records are supported.
+func (s *DNSService) CreateRecord(ctx context.Context, wonderNetID, name, recordType, value, createdBy string) (*repository.DNSRecord, error) {
+	if !s.Enabled() {
+		return nil, ErrDNSDisabled
+	}
+
+	name = normalizeDNSName(name)
+	recordType = strings.ToUpper(recordType)
+	value, err := validateDNSRecord(name, recordType, value)
+	if err != nil {
+		return nil, err
+	}
+	domain, err := s.dnsRepository.GetDomain(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	if domain == nil {
+		return nil, ErrDNSDomainNotSet
+	}
+
+	record, err := s.dnsRepository.CreateRecord(ctx, &repository.DNSRecord{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNetID,
+		Name:        name,
+		Type:        recordType,
+		Value:       value,
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created dns record", "id", record.ID, "wonder_net_id", wonderNetID, "name", name+"."+domain.Domain, "type", recordType)
+	s.syncAfterChange(ctx)
+	return record, nil
+}
+
+// DeleteRecord deletes an extra record of a wonder net and unpublishes it.
+func (s *DNSService) DeleteRecord(ctx context.Context, wonderNetID, recordID string) error {
+	if !s.Enabled() {
+		return ErrDNSDisabled
+	}
+
+	record, err := s.dnsRepository.GetRecord(ctx, recordID)
+	if err != nil {
+		return err
+	}
+	if record == nil || record.WonderNetID != wonderNetID {
+		return ErrDNSRecordNotFound
+	}
+	if err := s.dnsRepository.DeleteRecord(ctx, recordID); err != nil {
+		return err
+	}
+
+	slog.Info("deleted dns record", "id", recordID, "wonder_net_id", wonderNetID)
+	s.syncAfterChange(ctx)
+	return nil
+}
+
+// syncAfterChange publishes a change right away. A failure is only logged,
+// since the change is stored and Run publishes it on its next pass.
+func (s *DNSService) syncAfterChange(ctx context.Context) {
+	if err := s.Sync(ctx); err != nil {
+		slog.Error("sync dns records", "error", err)
+	}
+}
+
+// Run publishes DNS records every interval until ctx is cancelled, which
+// keeps node names current as nodes join, leave and change addresses.
+func (s *DNSService) Run(ctx context.Context, interval time.Duration) {
+	if err := s.Sync(ctx); err != nil {
+		slog.Error("sync dns records", "error", err)
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Sync(ctx); err != nil {
+				slog.Error("sync dns records", "error", err)
+			}
+		}
+	}
+}
+
+// Sync writes the node names and extra records of every wonder net with a
+// domain to the extra records file. The file is replaced atomically, so
+// Headscale never reads a partial write.
+func (s *DNSService) Sync(ctx context.Context) error {
+	if !s.Enabled() {
+		return nil
+	}
+
+	domains, err := s.dnsRepository.ListDomains(ctx)
+	if err != nil {
+		return fmt.Errorf("list dns domains: %w", err)
+	}
+	records, err := s.dnsRepository.ListRecords(ctx)
+	if err != nil {
+		return fmt.Errorf("list dns records: %w", err)
+	}
+	recordsByWonderNet := make(map[string][]*repository.DNSRecord)
+	for _, record := range records {
+		recordsByWonderNet[record.WonderNetID] = append(recordsByWonderNet[record.WonderNetID], record)
+	}
+
+	extra := []extraRecord{}
+	for _, domain := range domains {
+		wonderNet, err := s.wonderNetRepository.Get(ctx, domain.WonderNetID)
+		if err != nil {
+			return fmt.Errorf("get wonder net %s: %w", domain.WonderNetID, err)
+		}
+		if wonderNet == nil {
+			continue
+		}
+		nodes, err := s.nodesService.ListNodes(ctx, wonderNet)
+		if err != nil {
+			return fmt.Errorf("list nodes of wonder net %s: %w", wonderNet.ID, err)
+		}
+		extra = append(extra, buildExtraRecords(domain.Domain, nodes, recordsByWonderNet[domain.WonderNetID])...)
+	}
+
+	content, err := json.MarshalIndent(extra, "", "  ")
+	if err != nil {
+		return fmt.Errorf("encode dns records: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if bytes.Equal(content, s.written) {
+		return nil
+	}
+	if err := writeFileAtomic(s.recordsPath, content); err != nil {
+		return fmt.Errorf("write dns records: %w", err)
+	}
+	s.written = content
+
+	slog.Info("published dns records", "path", s.recordsPath, "records", len(extra))
+	return nil
+}
+
+// buildExtraRecords returns the records of a wonder net with the given
+// domain: an A or AAAA record per node address, followed by the extra
+// records, sorted by name.
+func buildExtraRecords(domain string, nodes []*Node, records []*repository.DNSRecord) []extraRecord {
+	var result []extraRecord
+	for _, name := range nodeDNSNames(domain, nodes) {
+		for _, addr := range name.Addresses {
+			result = append(result, extraRecord{Name: name.FQDN, Type: dnsRecordType(addr), Value: addr})
+		}
+	}
+	for _, record := range records {
+		result = append(result, extraRecord{Name: record.Name + "." + domain, Type: record.Type, Value: record.Value})
+	}
+	slices.SortStableFunc(result, func(a, b extraRecord) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+	return result
+}
+
+// nodeDNSNames returns the name of each node under domain.
+func nodeDNSNames(domain string, nodes []*Node) []DNSName {
+	names := make([]DNSName, 0, len(nodes))
+	for _, node := range nodes {
+		name := normalizeDNSName(node.Name)
+		if !isDNSLabel(name) {
+			continue
+		}
+		var addrs []string
+		for _, addr := range node.IPAddrs {
+			if dnsRecordType(addr) != "" {
+				addrs = append(addrs, addr)
+			}
+		}
+		names = append(names, DNSName{Node: node.Name, FQDN: name + "." + domain, Addresses: addrs})
+	}
+	return names
+}
+
+// dnsRecordType returns the record type for an IP address, or "" if addr
+// is not one.
+func dnsRecordType(addr string) string {
+	ip, err := netip.ParseAddr(addr)
+	if err != nil {
+		return ""
+	}
+	if ip.Is4() {
+		return DNSRecordA
+	}
+	return DNSRecordAAAA
+}
+
+func normalizeDNSName(name string) string {
+	return strings.TrimSuffix(strings.ToLower(strings.TrimSpace(name)), ".")
+}
+
+// validateDNSDomain checks a normalized wonder net domain. When baseDomain
+// is set the domain must be a proper subdomain of it, so that wonder net
+// names never shadow the MagicDNS names Headscale assigns itself.
+func validateDNSDomain(domain, baseDomain string) error {
+	if domain == "" || len(domain) > 253 {
+		return fmt.Errorf("%w: must be 1 to 253 characters", ErrInvalidDNSDomain)
+	}
+	for label := range strings.SplitSeq(domain, ".") {
+		if !isDNSLabel(label) {
+			return fmt.Errorf("%w: %q is not a valid label", ErrInvalidDNSDomain, label)
+		}
+	}
+	if baseDomain != "" && !strings.HasSuffix(domain, "."+baseDomain) {
+		return fmt.Errorf("%w: must be a subdomain of %s", ErrInvalidDNSDomain, baseDomain)
+	}
+	return nil
+}
+
+// validateDNSRecord checks a normalized record name, which is relative to
+// the wonder net domain, and returns the canonical form of value.
+func validateDNSRecord(name, recordType, value string) (string, error) {
+	if name == "" {
+		return "", fmt.Errorf("%w: name is required", ErrInvalidDNSRecord)
+	}
+	for label := range strings.SplitSeq(name, ".") {
+		if !isDNSLabel(label) {
+			return "", fmt.Errorf("%w: %q is not a valid label", ErrInvalidDNSRecord, label)
+		}
+	}
+	if recordType != DNSRecordA && recordType != DNSRecordAAAA {
+		return "", fmt.Errorf("%w: unsupported type %q, want A or AAAA", ErrInvalidDNSRecord, recordType)
+	}
+	family := "IPv4"
+	if recordType == DNSRecordAAAA {
+		family = "IPv6"
+	}
+	ip, err := netip.ParseAddr(value)
+	if err != nil || dnsRecordType(value) != recordType || ip.Zone() != "" {
+		return "", fmt.Errorf("%w: value must be an %s address", ErrInvalidDNSRecord, family)
+	}
+	return ip.String(), nil
+}
+
+// isDNSLabel reports whether label is a valid lowercase DNS label.
+func isDNSLabel(label string) bool {
+	if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+		return false
+	}
+	for _, c := range label {
+		if (c < 'a' || c > 'z') && (c < '0' || c > '9') && c != '-' {
+			return false
+		}
+	}
+	return true
+}
+
+// writeFileAtomic replaces path with content through a temporary file in
+// the same directory.
+func writeFileAtomic(path string, content []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+".*")
+	if err != nil {
+		return err
+	}
+	defer func() { _ = os.Remove(tmp.Name()) }()
+
+	if _, err := tmp.Write(content); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Chmod(0o644); err != nil {
+		_ = tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
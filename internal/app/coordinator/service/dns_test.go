@@ -0,0 +1,95 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestValidateDNSDomain(t *testing.T) {
+	tests := []struct {
+		name       string
+		domain     string
+		baseDomain string
+		wantErr    bool
+	}{
+		{"subdomain of base", "acme.mesh.example.com", "mesh.example.com", false},
+		{"no base domain", "acme.internal", "", false},
+		{"base domain itself", "mesh.example.com", "mesh.example.com", true},
+		{"outside base domain", "acme.example.org", "mesh.example.com", true},
+		{"suffix without dot", "acmemesh.example.com", "mesh.example.com", true},
+		{"empty", "", "", true},
+		{"invalid label", "ac_me.internal", "", true},
+		{"leading hyphen", "-acme.internal", "", true},
+		{"empty label", "acme..internal", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := validateDNSDomain(tt.domain, tt.baseDomain)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDNSDomain(%q, %q) error = %v, wantErr %v", tt.domain, tt.baseDomain, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidDNSDomain) {
+				t.Errorf("validateDNSDomain() error = %v, want %v", err, ErrInvalidDNSDomain)
+			}
+		})
+	}
+}
+
+func TestValidateDNSRecord(t *testing.T) {
+	tests := []struct {
+		name       string
+		recordName string
+		recordType string
+		value      string
+		want       string
+		wantErr    bool
+	}{
+		{"A record", "db", DNSRecordA, "100.64.0.10", "100.64.0.10", false},
+		{"nested name", "api.staging", DNSRecordA, "10.0.0.1", "10.0.0.1", false},
+		{"AAAA record is canonicalized", "db", DNSRecordAAAA, "FD7A:115C:A1E0:0::10", "fd7a:115c:a1e0::10", false},
+		{"A with IPv6 value", "db", DNSRecordA, "fd7a:115c:a1e0::10", "", true},
+		{"AAAA with IPv4 value", "db", DNSRecordAAAA, "100.64.0.10", "", true},
+		{"unsupported type", "db", "CNAME", "other.example.com", "", true},
+		{"empty name", "", DNSRecordA, "100.64.0.10", "", true},
+		{"invalid name", "d b", DNSRecordA, "100.64.0.10", "", true},
+		{"invalid value", "db", DNSRecordA, "not-an-ip", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := validateDNSRecord(tt.recordName, tt.recordType, tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateDNSRecord() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("validateDNSRecord() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestBuildExtraRecords(t *testing.T) {
+	nodes := []*Node{
+		{Name: "worker-2", IPAddrs: []string{"100.64.0.2"}},
+		{Name: "Worker-1", IPAddrs: []string{"100.64.0.1", "fd7a:115c:a1e0::1"}},
+		{Name: "not a label", IPAddrs: []string{"100.64.0.3"}},
+	}
+	records := []*repository.DNSRecord{
+		{Name: "db", Type: DNSRecordA, Value: "100.64.0.2"},
+	}
+
+	got := buildExtraRecords("acme.mesh.example.com", nodes, records)
+	want := []extraRecord{
+		{Name: "db.acme.mesh.example.com", Type: DNSRecordA, Value: "100.64.0.2"},
+		{Name: "worker-1.acme.mesh.example.com", Type: DNSRecordA, Value: "100.64.0.1"},
+		{Name: "worker-1.acme.mesh.example.com", Type: DNSRecordAAAA, Value: "fd7a:115c:a1e0::1"},
+		{Name: "worker-2.acme.mesh.example.com", Type: DNSRecordA, Value: "100.64.0.2"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("buildExtraRecords() = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,503 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/netip"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+	"github.com/strrl/wonder-mesh-net/pkg/oidc"
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// EmbeddedAuthAudience is the audience of tokens issued by the embedded
+	// identity provider.
+	EmbeddedAuthAudience = "wonder-coordinator"
+	// EmbeddedTokenTTL is how long tokens of the embedded identity provider,
+	// and the web sessions holding them, are valid.
+	EmbeddedTokenTTL = 12 * time.Hour
+	// MinPasswordLength is the shortest password of a local user.
+	MinPasswordLength = 8
+)
+
+// Password login limits. After its free failures, a username or client
+// address has to wait loginBackoff before its next login, doubling with
+// every further failure up to maxLoginBackoff. Client addresses get more
+// free failures, as users behind one NAT share them. Failures are
+// forgotten after loginFailureTTL without another one, and a successful
+// login clears those of the username.
+const (
+	userLoginFreeFailures = 5
+	ipLoginFreeFailures   = 20
+	loginBackoff          = time.Second
+	maxLoginBackoff       = 15 * time.Minute
+	loginFailureTTL       = time.Hour
+)
+
+// Embedded identity provider errors.
+var (
+	ErrInvalidCredentials = errors.New("invalid username or password")
+	ErrInvalidUser        = errors.New("invalid user")
+	ErrUserExists         = errors.New("user already exists")
+	ErrUnknownProvider    = errors.New("unknown login provider")
+	ErrEmailNotAllowed    = errors.New("email is not allowed to log in")
+	ErrLoginThrottled     = errors.New("too many failed logins")
+)
+
+// LoginThrottledError is returned for a password login attempted while its
+// username or client address is backing off. It wraps ErrLoginThrottled.
+type LoginThrottledError struct {
+	RetryAfter time.Duration
+}
+
+func (e *LoginThrottledError) Error() string {
+	return fmt.Sprintf("%s, try again in %s", ErrLoginThrottled, e.RetryAfter)
+}
+
+func (e *LoginThrottledError) Unwrap() error {
+	return ErrLoginThrottled
+}
+
+// localUsernamePattern matches the usernames of local users.
+var localUsernamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9._-]{0,63}$`)
+
+// providerLogin is a pending login at a provider.
+type providerLogin struct {
+	provider     string
+	codeVerifier string
+	redirectTo   string
+	expiresAt    time.Time
+}
+
+// EmbeddedAuthService is the coordinator's own identity provider, used
+// instead of Keycloak. Local users log in with a bcrypt-hashed password;
+// others log in with GitHub or Google, where their verified email address
+// must match allowedEmails. Either way the service issues tokens signed by
+// signer, which the coordinator's JWT validator accepts.
+type EmbeddedAuthService struct {
+	userRepository *repository.UserRepository
+	signer         *jwtauth.Signer
+	providers      map[string]*oidc.Provider
+	allowedEmails  []string
+
+	logins  map[string]*providerLogin
+	loginMu sync.Mutex
+
+	userLimiter *loginLimiter
+	ipLimiter   *loginLimiter
+
+	// dummyHash is compared against for unknown usernames, so logins take
+	// as long whether or not the user exists.
+	dummyHash []byte
+}
+
+// NewEmbeddedAuthService creates a new EmbeddedAuthService. allowedEmails
+// are email addresses, or domains written as "@example.com", of users who
+// may log in with a provider.
+func NewEmbeddedAuthService(
+	userRepository *repository.UserRepository,
+	signer *jwtauth.Signer,
+	providers []*oidc.Provider,
+	allowedEmails []string,
+) *EmbeddedAuthService {
+	byName := make(map[string]*oidc.Provider, len(providers))
+	for _, p := range providers {
+		byName[p.Name] = p
+	}
+	dummyHash, _ := bcrypt.GenerateFromPassword([]byte("not a password"), bcrypt.DefaultCost)
+	return &EmbeddedAuthService{
+		userRepository: userRepository,
+		signer:         signer,
+		providers:      byName,
+		allowedEmails:  allowedEmails,
+		logins:         make(map[string]*providerLogin),
+		userLimiter:    newLoginLimiter(userLoginFreeFailures),
+		ipLimiter:      newLoginLimiter(ipLoginFreeFailures),
+		dummyHash:      dummyHash,
+	}
+}
+
+// Signer returns the signer of the service's tokens.
+func (s *EmbeddedAuthService) Signer() *jwtauth.Signer {
+	return s.signer
+}
+
+// Providers returns the names of the configured login providers.
+func (s *EmbeddedAuthService) Providers() []string {
+	names := make([]string, 0, len(s.providers))
+	for name := range s.providers {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// CreateLocalUser creates a local user who logs in with username and
+// password.
+func (s *EmbeddedAuthService) CreateLocalUser(ctx context.Context, username, password, email, name string) (*repository.User, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	if !localUsernamePattern.MatchString(username) || strings.HasPrefix(username, "service-account-") {
+		return nil, fmt.Errorf("%w: username must be 1 to 64 lowercase letters, digits, dots, dashes, or underscores", ErrInvalidUser)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.userRepository.GetByProviderSubject(ctx, repository.UserProviderLocal, username)
+	if err != nil {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+	if existing != nil {
+		return nil, ErrUserExists
+	}
+
+	user, err := s.userRepository.Create(ctx, &repository.User{
+		ID:           uuid.New().String(),
+		Provider:     repository.UserProviderLocal,
+		Subject:      username,
+		Username:     username,
+		Email:        strings.ToLower(strings.TrimSpace(email)),
+		Name:         strings.TrimSpace(name),
+		PasswordHash: hash,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create user: %w", err)
+	}
+	return user, nil
+}
+
+// ListUsers lists all users of the embedded identity provider.
+func (s *EmbeddedAuthService) ListUsers(ctx context.Context) ([]*repository.User, error) {
+	return s.userRepository.List(ctx)
+}
+
+// SetPassword replaces the password of a local user.
+func (s *EmbeddedAuthService) SetPassword(ctx context.Context, userID, password string) error {
+	user, err := s.userRepository.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	if user.Provider != repository.UserProviderLocal {
+		return fmt.Errorf("%w: %s users log in with %s and have no password", ErrInvalidUser, user.Provider, user.Provider)
+	}
+	hash, err := hashPassword(password)
+	if err != nil {
+		return err
+	}
+	return s.userRepository.UpdatePasswordHash(ctx, user.ID, hash)
+}
+
+// DeleteUser deletes a user. Tokens already issued to the user stay valid
+// until they expire.
+func (s *EmbeddedAuthService) DeleteUser(ctx context.Context, userID string) error {
+	user, err := s.userRepository.Get(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		return ErrUserNotFound
+	}
+	return s.userRepository.Delete(ctx, user.ID)
+}
+
+// PasswordLogin logs a local user in from clientIP and issues a token. It
+// returns a *LoginThrottledError, without checking the password, while the
+// username or clientIP is backing off after failed logins.
+func (s *EmbeddedAuthService) PasswordLogin(ctx context.Context, username, password string, clientIP netip.Addr) (*TokenResponse, *jwtauth.Claims, error) {
+	username = strings.ToLower(strings.TrimSpace(username))
+	ip := ""
+	if clientIP.IsValid() {
+		ip = clientIP.Unmap().String()
+	}
+
+	// The login counts as failed until the password checks out, so that
+	// concurrent guesses cannot all get past the limits while bcrypt runs.
+	now := time.Now()
+	if wait := s.userLimiter.attempt(username, now); wait > 0 {
+		return nil, nil, &LoginThrottledError{RetryAfter: wait}
+	}
+	if wait := s.ipLimiter.attempt(ip, now); wait > 0 {
+		return nil, nil, &LoginThrottledError{RetryAfter: wait}
+	}
+
+	user, err := s.userRepository.GetByProviderSubject(ctx, repository.UserProviderLocal, username)
+	if err != nil {
+		return nil, nil, fmt.Errorf("get user: %w", err)
+	}
+	passwordHash := s.dummyHash
+	if user != nil {
+		passwordHash = []byte(user.PasswordHash)
+	}
+	if err := bcrypt.CompareHashAndPassword(passwordHash, []byte(password)); err != nil || user == nil {
+		return nil, nil, ErrInvalidCredentials
+	}
+	s.userLimiter.reset(username)
+	s.ipLimiter.forgive(ip)
+	return s.issueToken(user)
+}
+
+// ProviderAuthURL starts a login with a provider and returns the URL to
+// send the user to. After the login, ProviderCallback returns redirectTo.
+func (s *EmbeddedAuthService) ProviderAuthURL(providerName, redirectURI, redirectTo string) (string, error) {
+	provider, ok := s.providers[providerName]
+	if !ok {
+		return "", ErrUnknownProvider
+	}
+
+	state, err := generateRandomString(stateLength)
+	if err != nil {
+		return "", fmt.Errorf("generate state: %w", err)
+	}
+	codeVerifier, err := generateRandomString(codeVerifierLength)
+	if err != nil {
+		return "", fmt.Errorf("generate code verifier: %w", err)
+	}
+
+	now := time.Now()
+	s.loginMu.Lock()
+	for key, login := range s.logins {
+		if now.After(login.expiresAt) {
+			delete(s.logins, key)
+		}
+	}
+	s.logins[state] = &providerLogin{
+		provider:     providerName,
+		codeVerifier: codeVerifier,
+		redirectTo:   redirectTo,
+		expiresAt:    now.Add(stateTTL),
+	}
+	s.loginMu.Unlock()
+
+	return provider.AuthCodeURL(state, redirectURI, codeChallenge(codeVerifier)), nil
+}
+
+// ProviderCallback completes a login with a provider: it redeems the code,
+// creates the user on their first login, and issues a token. It also
+// returns the redirectTo of the login.
+func (s *EmbeddedAuthService) ProviderCallback(ctx context.Context, providerName, state, code, redirectURI string) (*TokenResponse, *jwtauth.Claims, string, error) {
+	s.loginMu.Lock()
+	login, ok := s.logins[state]
+	delete(s.logins, state)
+	s.loginMu.Unlock()
+	if !ok || login.provider != providerName {
+		return nil, nil, "", ErrInvalidState
+	}
+	if time.Now().After(login.expiresAt) {
+		return nil, nil, "", ErrStateExpired
+	}
+
+	identity, err := s.providers[providerName].Exchange(ctx, code, redirectURI, login.codeVerifier)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	email := strings.ToLower(identity.Email)
+	if !identity.EmailVerified || !emailAllowed(email, s.allowedEmails) {
+		return nil, nil, "", fmt.Errorf("%w: %q", ErrEmailNotAllowed, identity.Email)
+	}
+
+	user, err := s.userRepository.GetByProviderSubject(ctx, providerName, identity.Subject)
+	if err != nil {
+		return nil, nil, "", fmt.Errorf("get user: %w", err)
+	}
+	if user == nil {
+		user, err = s.userRepository.Create(ctx, &repository.User{
+			ID:       uuid.New().String(),
+			Provider: providerName,
+			Subject:  identity.Subject,
+			Username: identity.Username,
+			Email:    email,
+			Name:     identity.Name,
+		})
+		if err != nil {
+			return nil, nil, "", fmt.Errorf("create user: %w", err)
+		}
+	} else if user.Username != identity.Username || user.Email != email || user.Name != identity.Name {
+		if err := s.userRepository.UpdateProfile(ctx, user.ID, identity.Username, email, identity.Name); err != nil {
+			return nil, nil, "", fmt.Errorf("update user: %w", err)
+		}
+		user.Username, user.Email, user.Name = identity.Username, email, identity.Name
+	}
+
+	tokenResp, claims, err := s.issueToken(user)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	return tokenResp, claims, login.redirectTo, nil
+}
+
+// LookupUserByEmail finds the oldest user with the given email, making the
+// embedded identity provider the user directory of wonder net sharing.
+func (s *EmbeddedAuthService) LookupUserByEmail(ctx context.Context, email string) (*DirectoryUser, error) {
+	users, err := s.userRepository.ListByEmail(ctx, strings.ToLower(email))
+	if err != nil {
+		return nil, fmt.Errorf("look up user: %w", err)
+	}
+	if len(users) == 0 {
+		return nil, ErrUserNotFound
+	}
+	return &DirectoryUser{ID: users[0].ID, Username: users[0].Username, Email: users[0].Email}, nil
+}
+
+// issueToken issues a token for user, whose ID is its subject.
+func (s *EmbeddedAuthService) issueToken(user *repository.User) (*TokenResponse, *jwtauth.Claims, error) {
+	now := time.Now()
+	claims := &jwtauth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   user.ID,
+			Audience:  jwt.ClaimStrings{EmbeddedAuthAudience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(EmbeddedTokenTTL)),
+			ID:        uuid.New().String(),
+		},
+		PreferredUsername: user.Username,
+		Email:             user.Email,
+		EmailVerified:     user.Email != "" && user.Provider != repository.UserProviderLocal,
+		Name:              user.Name,
+		Azp:               EmbeddedAuthAudience,
+	}
+	token, err := s.signer.Sign(claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	return &TokenResponse{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresIn:   int(EmbeddedTokenTTL / time.Second),
+	}, claims, nil
+}
+
+// loginLimiter tracks the failed password logins of usernames or of
+// client addresses, keyed by either.
+type loginLimiter struct {
+	freeFailures int
+
+	mu       sync.Mutex
+	failures map[string]*loginFailures
+	pruned   time.Time
+}
+
+// loginFailures are the recent failed logins of one key.
+type loginFailures struct {
+	count int
+	last  time.Time
+}
+
+func newLoginLimiter(freeFailures int) *loginLimiter {
+	return &loginLimiter{
+		freeFailures: freeFailures,
+		failures:     make(map[string]*loginFailures),
+	}
+}
+
+// attempt returns how long key has to wait before its next login. If it
+// need not wait, the login is recorded as a failed one. Every
+// loginFailureTTL, keys without a failure for that long are dropped.
+func (l *loginLimiter) attempt(key string, now time.Time) time.Duration {
+	if key == "" {
+		return 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	f, ok := l.failures[key]
+	if ok {
+		if wait := f.last.Add(loginBackoffAfter(f.count, l.freeFailures)).Sub(now); wait > 0 {
+			return wait
+		}
+	}
+
+	if now.Sub(l.pruned) >= loginFailureTTL {
+		for k, f := range l.failures {
+			if now.Sub(f.last) >= loginFailureTTL {
+				delete(l.failures, k)
+			}
+		}
+		l.pruned = now
+	}
+	f, ok = l.failures[key]
+	if !ok || now.Sub(f.last) >= loginFailureTTL {
+		f = &loginFailures{}
+		l.failures[key] = f
+	}
+	f.count++
+	f.last = now
+	return 0
+}
+
+// reset clears the failed logins of key.
+func (l *loginLimiter) reset(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	delete(l.failures, key)
+}
+
+// forgive takes back the failed login recorded by the last attempt of key.
+func (l *loginLimiter) forgive(key string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if f, ok := l.failures[key]; ok {
+		f.count--
+		if f.count <= 0 {
+			delete(l.failures, key)
+		}
+	}
+}
+
+// loginBackoffAfter returns the wait after failures failed logins, the
+// first freeFailures of which are free.
+func loginBackoffAfter(failures, freeFailures int) time.Duration {
+	n := failures - freeFailures
+	if n < 0 {
+		return 0
+	}
+	if n >= 30 {
+		return maxLoginBackoff
+	}
+	return min(loginBackoff<<n, maxLoginBackoff)
+}
+
+// hashPassword checks that a password is long enough and hashes it with
+// bcrypt.
+func hashPassword(password string) (string, error) {
+	if len(password) < MinPasswordLength {
+		return "", fmt.Errorf("%w: password must be at least %d characters", ErrInvalidUser, MinPasswordLength)
+	}
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		// Passwords longer than 72 bytes are rejected by bcrypt.
+		return "", fmt.Errorf("%w: %s", ErrInvalidUser, err)
+	}
+	return string(hash), nil
+}
+
+// emailAllowed reports whether email matches one of the allowed addresses
+// or "@domain" entries.
+func emailAllowed(email string, allowed []string) bool {
+	if email == "" {
+		return false
+	}
+	for _, entry := range allowed {
+		entry = strings.ToLower(strings.TrimSpace(entry))
+		if strings.HasPrefix(entry, "@") {
+			if strings.HasSuffix(email, entry) {
+				return true
+			}
+		} else if email == entry {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,145 @@
+package service
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+func TestEmailAllowed(t *testing.T) {
+	allowed := []string{"alice@example.com", "@corp.example.com", " Bob@Example.org "}
+
+	tests := []struct {
+		email string
+		want  bool
+	}{
+		{"alice@example.com", true},
+		{"carol@corp.example.com", true},
+		{"bob@example.org", true},
+		{"mallory@example.com", false},
+		{"mallory@evilcorp.example.com.attacker.net", false},
+		{"mallory@notcorp.example.com", false},
+		{"", false},
+	}
+	for _, tt := range tests {
+		if got := emailAllowed(tt.email, allowed); got != tt.want {
+			t.Errorf("emailAllowed(%q) = %v, want %v", tt.email, got, tt.want)
+		}
+	}
+}
+
+func TestCreateLocalUserValidation(t *testing.T) {
+	// Invalid users are rejected before any lookup, so no repository is needed.
+	svc := NewEmbeddedAuthService(nil, nil, nil, nil)
+
+	tests := []struct {
+		name     string
+		username string
+		password string
+	}{
+		{"empty username", "", "long enough"},
+		{"space in username", "alice smith", "long enough"},
+		{"service account username", "service-account-ci", "long enough"},
+		{"short password", "alice", "short"},
+		{"password too long for bcrypt", "alice", strings.Repeat("x", 73)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := svc.CreateLocalUser(context.Background(), tt.username, tt.password, "", ""); !errors.Is(err, ErrInvalidUser) {
+				t.Errorf("CreateLocalUser() error = %v, want %v", err, ErrInvalidUser)
+			}
+		})
+	}
+}
+
+func TestEmbeddedAuthIssueToken(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := jwtauth.NewSigner("https://mesh.example.com/coordinator/auth", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := jwtauth.NewValidator(jwtauth.ValidatorConfig{
+		KeySet:   signer.KeySet(),
+		Issuer:   signer.Issuer(),
+		Audience: EmbeddedAuthAudience,
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := validator.Start(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	svc := NewEmbeddedAuthService(nil, signer, nil, nil)
+	user := &repository.User{ID: "user-1", Provider: repository.UserProviderGitHub, Subject: "4242", Username: "octocat", Email: "octocat@example.com"}
+	tokenResp, _, err := svc.issueToken(user)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	claims, err := validator.Validate(tokenResp.AccessToken)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if claims.Subject != "user-1" || claims.PreferredUsername != "octocat" || claims.Email != "octocat@example.com" {
+		t.Errorf("claims = %+v, want the user's", claims)
+	}
+	if claims.IsServiceAccount() {
+		t.Error("IsServiceAccount() = true for a user token")
+	}
+}
+
+func TestLoginLimiter(t *testing.T) {
+	l := newLoginLimiter(2)
+	now := time.Now()
+
+	for i := range 2 {
+		if wait := l.attempt("alice", now); wait != 0 {
+			t.Fatalf("attempt %d waits %v, want the free failures to pass", i+1, wait)
+		}
+	}
+	if wait := l.attempt("alice", now); wait != loginBackoff {
+		t.Fatalf("attempt after the free failures waits %v, want %v", wait, loginBackoff)
+	}
+	if wait := l.attempt("bob", now); wait != 0 {
+		t.Errorf("other key waits %v, want 0", wait)
+	}
+
+	now = now.Add(loginBackoff)
+	if wait := l.attempt("alice", now); wait != 0 {
+		t.Fatalf("attempt after the backoff waits %v, want 0", wait)
+	}
+	if wait := l.attempt("alice", now); wait != 2*loginBackoff {
+		t.Errorf("attempt after another failure waits %v, want %v", wait, 2*loginBackoff)
+	}
+
+	l.reset("alice")
+	if wait := l.attempt("alice", now); wait != 0 {
+		t.Errorf("attempt after reset waits %v, want 0", wait)
+	}
+	if wait := l.attempt("carol", now.Add(loginFailureTTL)); wait != 0 || len(l.failures) != 1 {
+		t.Errorf("attempt after loginFailureTTL waits %v and keeps %d keys, want 0 and only carol", wait, len(l.failures))
+	}
+}
+
+func TestLoginBackoffAfter(t *testing.T) {
+	for failures, want := range map[int]time.Duration{
+		4:   0,
+		5:   loginBackoff,
+		7:   4 * loginBackoff,
+		100: maxLoginBackoff,
+	} {
+		if got := loginBackoffAfter(failures, 5); got != want {
+			t.Errorf("loginBackoffAfter(%d, 5) = %v, want %v", failures, got, want)
+		}
+	}
+}
@@ -8,11 +8,18 @@ var (
 	ErrStateExpired    = errors.New("state expired")
 	ErrTokenExchange   = errors.New("token exchange failed")
 	ErrInvalidIDToken  = errors.New("invalid ID token")
+	ErrUserInfo        = errors.New("userinfo request failed")
 	ErrSessionNotFound = errors.New("session not found")
 	ErrSessionExpired  = errors.New("session expired")
 )
 
 // Worker service errors.
 var (
-	ErrInvalidToken = errors.New("invalid or expired token")
+	ErrInvalidToken         = errors.New("invalid or expired token")
+	ErrJoinTokenUsed        = errors.New("join token has no uses left")
+	ErrJoinTokenNotFound    = errors.New("join token not found")
+	ErrInvalidJoinTokenUses = errors.New("invalid join token max uses")
+	ErrInvalidJoinTokenTTL  = errors.New("invalid join token TTL")
+	ErrRenewalNotAllowed    = errors.New("worker node is online or being decommissioned")
+	ErrWorkerNotJoined      = errors.New("worker token is not bound to a node; join again with a new join token")
 )
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+	"unicode/utf8"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+var (
+	ErrExecSessionNotFound = errors.New("exec session not found")
+	ErrInvalidExecSession  = errors.New("invalid exec session")
+)
+
+const (
+	// DefaultExecSessionMaxOutputBytes is how much of each of the stdout and
+	// stderr of an exec session is kept by default.
+	DefaultExecSessionMaxOutputBytes = 1 << 20
+
+	// DefaultExecSessionListLimit and MaxExecSessionListLimit bound how many
+	// exec sessions are listed at once.
+	DefaultExecSessionListLimit = 100
+	MaxExecSessionListLimit     = 1000
+
+	maxExecSessionCommandLength = 16 << 10
+	maxExecSessionFieldLength   = 255
+)
+
+// ExecSessionInput is an exec session reported by the client that ran the
+// command, such as an SDK MeshExecutor.
+type ExecSessionInput struct {
+	NodeID    string
+	NodeName  string
+	Address   string
+	Command   string
+	Stdout    string
+	Stderr    string
+	ExitCode  int
+	Error     string
+	StartedAt time.Time
+	Duration  time.Duration
+}
+
+// ExecSessionService keeps an audit trail of the commands run on the nodes
+// of wonder nets. Clients that run commands over the mesh report each exec
+// session with its full output, which is cut to maxOutputBytes per stream.
+// Sessions older than the retention are purged.
+type ExecSessionService struct {
+	execSessionRepository *repository.ExecSessionRepository
+	retention             time.Duration
+	maxOutputBytes        int
+}
+
+// NewExecSessionService creates a new ExecSessionService. A retention of
+// zero keeps exec sessions until their wonder net is deleted; a
+// maxOutputBytes of zero uses DefaultExecSessionMaxOutputBytes.
+func NewExecSessionService(execSessionRepository *repository.ExecSessionRepository, retention time.Duration, maxOutputBytes int) *ExecSessionService {
+	if maxOutputBytes <= 0 {
+		maxOutputBytes = DefaultExecSessionMaxOutputBytes
+	}
+	return &ExecSessionService{
+		execSessionRepository: execSessionRepository,
+		retention:             retention,
+		maxOutputBytes:        maxOutputBytes,
+	}
+}
+
+// Record records an exec session of the wonder net, reported by recordedBy.
+func (s *ExecSessionService) Record(ctx context.Context, wonderNet *repository.WonderNet, input ExecSessionInput, recordedBy string) (*repository.ExecSession, error) {
+	switch {
+	case input.Command == "":
+		return nil, fmt.Errorf("%w: command is required", ErrInvalidExecSession)
+	case len(input.Command) > maxExecSessionCommandLength:
+		return nil, fmt.Errorf("%w: command must be at most %d bytes", ErrInvalidExecSession, maxExecSessionCommandLength)
+	case input.NodeID == "" && input.NodeName == "" && input.Address == "":
+		return nil, fmt.Errorf("%w: node_id, node_name, or address is required", ErrInvalidExecSession)
+	case len(input.NodeID) > maxExecSessionFieldLength, len(input.NodeName) > maxExecSessionFieldLength, len(input.Address) > maxExecSessionFieldLength:
+		return nil, fmt.Errorf("%w: node_id, node_name, and address must be at most %d characters", ErrInvalidExecSession, maxExecSessionFieldLength)
+	case input.Duration < 0:
+		return nil, fmt.Errorf("%w: duration must not be negative", ErrInvalidExecSession)
+	}
+
+	startedAt := input.StartedAt
+	if startedAt.IsZero() {
+		startedAt = time.Now().Add(-input.Duration)
+	}
+	stdout, stdoutTruncated := truncateOutput(input.Stdout, s.maxOutputBytes)
+	stderr, stderrTruncated := truncateOutput(input.Stderr, s.maxOutputBytes)
+	errMsg, _ := truncateOutput(input.Error, maxExecSessionCommandLength)
+
+	session, err := s.execSessionRepository.Create(ctx, &repository.ExecSession{
+		ID:              uuid.New().String(),
+		WonderNetID:     wonderNet.ID,
+		NodeID:          input.NodeID,
+		NodeName:        input.NodeName,
+		Address:         input.Address,
+		Command:         input.Command,
+		Stdout:          stdout,
+		Stderr:          stderr,
+		OutputTruncated: stdoutTruncated || stderrTruncated,
+		ExitCode:        input.ExitCode,
+		Error:           errMsg,
+		RecordedBy:      recordedBy,
+		StartedAt:       startedAt,
+		Duration:        input.Duration,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("recorded exec session",
+		"wonder_net_id", wonderNet.ID,
+		"id", session.ID,
+		"node", session.NodeName,
+		"exit_code", session.ExitCode,
+		"recorded_by", recordedBy)
+	return session, nil
+}
+
+// List lists the latest exec sessions of the wonder net, most recent first.
+// A limit of zero uses DefaultExecSessionListLimit.
+func (s *ExecSessionService) List(ctx context.Context, wonderNet *repository.WonderNet, limit int) ([]*repository.ExecSession, error) {
+	if limit < 0 || limit > MaxExecSessionListLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidExecSession, MaxExecSessionListLimit)
+	}
+	if limit == 0 {
+		limit = DefaultExecSessionListLimit
+	}
+	return s.execSessionRepository.ListByWonderNet(ctx, wonderNet.ID, limit)
+}
+
+// Get returns an exec session of the wonder net.
+func (s *ExecSessionService) Get(ctx context.Context, wonderNet *repository.WonderNet, id string) (*repository.ExecSession, error) {
+	session, err := s.execSessionRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if session == nil || session.WonderNetID != wonderNet.ID {
+		return nil, ErrExecSessionNotFound
+	}
+	return session, nil
+}
+
+// PurgeExpired deletes the exec sessions started before the retention.
+func (s *ExecSessionService) PurgeExpired(ctx context.Context) error {
+	if s.retention <= 0 {
+		return nil
+	}
+	deleted, err := s.execSessionRepository.DeleteBefore(ctx, time.Now().Add(-s.retention))
+	if err != nil {
+		return err
+	}
+	if deleted > 0 {
+		slog.Info("purged exec sessions", "deleted", deleted, "retention", s.retention)
+	}
+	return nil
+}
+
+// Run purges expired exec sessions every interval until ctx is cancelled.
+func (s *ExecSessionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.PurgeExpired(ctx); err != nil {
+				slog.Error("purge exec sessions", "error", err)
+			}
+		}
+	}
+}
+
+// truncateOutput cuts output to at most maxBytes, on a UTF-8 character
+// boundary, and reports whether it was cut.
+func truncateOutput(output string, maxBytes int) (string, bool) {
+	if len(output) <= maxBytes {
+		return output, false
+	}
+	cut := maxBytes
+	for cut > 0 && !utf8.RuneStart(output[cut]) {
+		cut--
+	}
+	return output[:cut], true
+}
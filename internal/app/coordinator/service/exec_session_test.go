@@ -0,0 +1,24 @@
+package service
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestTruncateOutput(t *testing.T) {
+	if got, truncated := truncateOutput("ok\n", 10); got != "ok\n" || truncated {
+		t.Errorf("truncateOutput() = %q, %v, want the output unchanged", got, truncated)
+	}
+
+	got, truncated := truncateOutput(strings.Repeat("a", 20), 10)
+	if got != strings.Repeat("a", 10) || !truncated {
+		t.Errorf("truncateOutput() = %q, %v, want the first 10 bytes", got, truncated)
+	}
+
+	// "é" is two bytes; cutting at 3 would split the second one.
+	got, truncated = truncateOutput("éééé", 3)
+	if got != "é" || !truncated || !utf8.ValidString(got) {
+		t.Errorf("truncateOutput() = %q, %v, want %q", got, truncated, "é")
+	}
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/apikey"
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+)
+
+// Token types reported by token introspection.
+const (
+	TokenTypeAPIKey    = "api_key"
+	TokenTypeJoinToken = "join_token"
+)
+
+// TokenIntrospection describes a token as in RFC 7662. Inactive tokens
+// carry no other information. Scopes are set for API keys; JTI, MaxUses,
+// and Uses for join tokens whose joins are counted.
+type TokenIntrospection struct {
+	Active      bool
+	TokenType   string
+	Scopes      []string
+	WonderNetID string
+	IssuedAt    time.Time
+	ExpiresAt   *time.Time
+	JTI         string
+	MaxUses     int
+	Uses        int
+}
+
+// TokenIntrospectionService tells integrations whether API keys and join
+// tokens issued by the coordinator are valid.
+type TokenIntrospectionService struct {
+	apiKeyRepository    *repository.APIKeyRepository
+	joinTokenRepository *repository.JoinTokenRepository
+	jwtSecret           string
+}
+
+// NewTokenIntrospectionService creates a new TokenIntrospectionService.
+func NewTokenIntrospectionService(apiKeyRepository *repository.APIKeyRepository, joinTokenRepository *repository.JoinTokenRepository, jwtSecret string) *TokenIntrospectionService {
+	return &TokenIntrospectionService{
+		apiKeyRepository:    apiKeyRepository,
+		joinTokenRepository: joinTokenRepository,
+		jwtSecret:           jwtSecret,
+	}
+}
+
+// Introspect describes a token for a caller of the wonder net. API keys
+// are active until they expire or are deleted; join tokens while their
+// signature is valid, they have not expired, and they have joins left.
+// Tokens of other wonder nets are reported as inactive, so that callers
+// learn nothing about them. Introspecting an API key does not count as
+// using it.
+func (s *TokenIntrospectionService) Introspect(ctx context.Context, wonderNet *repository.WonderNet, token string) (*TokenIntrospection, error) {
+	if apikey.IsAPIKey(token) {
+		return s.introspectAPIKey(ctx, wonderNet, token)
+	}
+	if jointoken.IsPayload(token) {
+		_, joinToken, err := jointoken.ParsePayload(token)
+		if err != nil {
+			return &TokenIntrospection{}, nil
+		}
+		token = joinToken
+	}
+	return s.introspectJoinToken(ctx, wonderNet, token)
+}
+
+func (s *TokenIntrospectionService) introspectAPIKey(ctx context.Context, wonderNet *repository.WonderNet, rawKey string) (*TokenIntrospection, error) {
+	key, err := s.apiKeyRepository.GetByHash(ctx, apikey.Hash(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("get api key: %w", err)
+	}
+	if key == nil || key.WonderNetID != wonderNet.ID {
+		return &TokenIntrospection{}, nil
+	}
+	if key.ExpiresAt != nil && time.Now().After(*key.ExpiresAt) {
+		return &TokenIntrospection{}, nil
+	}
+	return &TokenIntrospection{
+		Active:      true,
+		TokenType:   TokenTypeAPIKey,
+		Scopes:      apiKeyScopes(key),
+		WonderNetID: key.WonderNetID,
+		IssuedAt:    key.CreatedAt,
+		ExpiresAt:   key.ExpiresAt,
+	}, nil
+}
+
+func (s *TokenIntrospectionService) introspectJoinToken(ctx context.Context, wonderNet *repository.WonderNet, token string) (*TokenIntrospection, error) {
+	claims, err := jointoken.NewValidator(s.jwtSecret).Validate(token)
+	if err != nil || claims.WonderNetID != wonderNet.ID {
+		return &TokenIntrospection{}, nil
+	}
+
+	result := &TokenIntrospection{
+		Active:      true,
+		TokenType:   TokenTypeJoinToken,
+		WonderNetID: claims.WonderNetID,
+		JTI:         claims.ID,
+		MaxUses:     claims.AllowedUses(),
+	}
+	if claims.IssuedAt != nil {
+		result.IssuedAt = claims.IssuedAt.Time
+	}
+	if claims.ExpiresAt != nil {
+		expiresAt := claims.ExpiresAt.Time
+		result.ExpiresAt = &expiresAt
+	}
+
+	// Tokens issued before joins were counted carry no jti; they stay
+	// valid until they expire.
+	if claims.ID == "" {
+		return result, nil
+	}
+	record, err := s.joinTokenRepository.Get(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get join token: %w", err)
+	}
+	if record == nil || record.WonderNetID != wonderNet.ID || record.Uses >= record.MaxUses {
+		return &TokenIntrospection{}, nil
+	}
+	result.MaxUses = record.MaxUses
+	result.Uses = record.Uses
+	return result, nil
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
+)
+
+func TestIntrospectRejectsForeignJoinTokens(t *testing.T) {
+	const secret = "test-secret-test-secret-test-secret"
+	s := NewTokenIntrospectionService(nil, nil, secret)
+	wonderNet := &repository.WonderNet{ID: "wn-1"}
+
+	foreign, err := jointoken.NewGenerator(secret, "https://wonder.example.com").Generate("wn-2", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged, err := jointoken.NewGenerator("another-secret", "https://wonder.example.com").Generate("wn-1", time.Hour)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expired, err := jointoken.NewGenerator(secret, "https://wonder.example.com").Generate("wn-1", -time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for name, token := range map[string]string{
+		"other wonder net": foreign,
+		"other payload":    jointoken.Payload("https://wonder.example.com", foreign),
+		"bad signature":    forged,
+		"expired":          expired,
+		"garbage":          "not-a-token",
+	} {
+		t.Run(name, func(t *testing.T) {
+			got, err := s.Introspect(context.Background(), wonderNet, token)
+			if err != nil {
+				t.Fatalf("Introspect() error = %v", err)
+			}
+			if got.Active || got.WonderNetID != "" {
+				t.Errorf("Introspect() = %+v, want an inactive token without details", got)
+			}
+		})
+	}
+}
@@ -0,0 +1,88 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+)
+
+var (
+	ErrInvalidAllowedCIDR = errors.New("invalid allowed cidr")
+	ErrClientIPNotAllowed = errors.New("client ip not allowed")
+)
+
+// MaxAllowedCIDRs is the most address ranges an API key or join token may
+// be limited to.
+const MaxAllowedCIDRs = 32
+
+// NormalizeAllowedCIDRs validates an allowlist of address ranges and
+// returns it in canonical form, sorted and without duplicates. A bare
+// address is taken as a single-address range. An empty list allows any
+// client.
+func NormalizeAllowedCIDRs(cidrs []string) ([]string, error) {
+	if len(cidrs) > MaxAllowedCIDRs {
+		return nil, fmt.Errorf("%w: at most %d ranges", ErrInvalidAllowedCIDR, MaxAllowedCIDRs)
+	}
+
+	normalized := make([]string, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := parseAllowedCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		normalized = append(normalized, prefix.String())
+	}
+	return slices.Compact(slices.Sorted(slices.Values(normalized))), nil
+}
+
+// parseAllowedCIDR parses one allowlist entry, masking host bits.
+func parseAllowedCIDR(cidr string) (netip.Prefix, error) {
+	cidr = strings.TrimSpace(cidr)
+	if !strings.Contains(cidr, "/") {
+		addr, err := netip.ParseAddr(cidr)
+		if err != nil {
+			return netip.Prefix{}, fmt.Errorf("%w: %q", ErrInvalidAllowedCIDR, cidr)
+		}
+		addr = addr.Unmap()
+		return netip.PrefixFrom(addr, addr.BitLen()), nil
+	}
+
+	prefix, err := netip.ParsePrefix(cidr)
+	if err != nil {
+		return netip.Prefix{}, fmt.Errorf("%w: %q", ErrInvalidAllowedCIDR, cidr)
+	}
+	return prefix.Masked(), nil
+}
+
+// ParseCIDRs parses address ranges such as trusted proxy networks, taking
+// bare addresses as single-address ranges.
+func ParseCIDRs(cidrs []string) ([]netip.Prefix, error) {
+	prefixes := make([]netip.Prefix, 0, len(cidrs))
+	for _, cidr := range cidrs {
+		prefix, err := parseAllowedCIDR(cidr)
+		if err != nil {
+			return nil, err
+		}
+		prefixes = append(prefixes, prefix)
+	}
+	return prefixes, nil
+}
+
+// checkClientIP returns ErrClientIPNotAllowed unless the allowlist is empty
+// or one of its ranges contains clientIP. Entries were validated when they
+// were stored; an unknown client IP is only allowed by an empty list.
+func checkClientIP(allowed []string, clientIP netip.Addr) error {
+	if len(allowed) == 0 {
+		return nil
+	}
+	clientIP = clientIP.Unmap()
+	for _, cidr := range allowed {
+		prefix, err := netip.ParsePrefix(cidr)
+		if err == nil && prefix.Contains(clientIP) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s", ErrClientIPNotAllowed, clientIP)
+}
@@ -0,0 +1,74 @@
+package service
+
+import (
+	"errors"
+	"net/netip"
+	"reflect"
+	"testing"
+)
+
+func TestNormalizeAllowedCIDRs(t *testing.T) {
+	tests := []struct {
+		name    string
+		cidrs   []string
+		want    []string
+		wantErr bool
+	}{
+		{name: "empty", cidrs: nil, want: nil},
+		{name: "bare ipv4", cidrs: []string{"203.0.113.7"}, want: []string{"203.0.113.7/32"}},
+		{name: "bare ipv6", cidrs: []string{"2001:db8::1"}, want: []string{"2001:db8::1/128"}},
+		{name: "host bits masked", cidrs: []string{" 10.1.2.3/8 "}, want: []string{"10.0.0.0/8"}},
+		{name: "sorted and deduplicated", cidrs: []string{"192.168.0.0/16", "10.0.0.0/8", "10.0.0.1/8"}, want: []string{"10.0.0.0/8", "192.168.0.0/16"}},
+		{name: "invalid", cidrs: []string{"10.0.0.0/33"}, wantErr: true},
+		{name: "not an address", cidrs: []string{"ci.example.com"}, wantErr: true},
+		{name: "too many", cidrs: make([]string, MaxAllowedCIDRs+1), wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizeAllowedCIDRs(tt.cidrs)
+			if tt.wantErr {
+				if !errors.Is(err, ErrInvalidAllowedCIDR) {
+					t.Errorf("NormalizeAllowedCIDRs() error = %v, want %v", err, ErrInvalidAllowedCIDR)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("NormalizeAllowedCIDRs() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NormalizeAllowedCIDRs() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestCheckClientIP(t *testing.T) {
+	allowed := []string{"10.0.0.0/8", "2001:db8::/32"}
+	tests := []struct {
+		name     string
+		allowed  []string
+		clientIP netip.Addr
+		want     bool
+	}{
+		{name: "empty list allows all", allowed: nil, clientIP: netip.MustParseAddr("203.0.113.7"), want: true},
+		{name: "empty list allows unknown", allowed: nil, clientIP: netip.Addr{}, want: true},
+		{name: "ipv4 inside", allowed: allowed, clientIP: netip.MustParseAddr("10.20.30.40"), want: true},
+		{name: "ipv4-mapped inside", allowed: allowed, clientIP: netip.MustParseAddr("::ffff:10.20.30.40"), want: true},
+		{name: "ipv6 inside", allowed: allowed, clientIP: netip.MustParseAddr("2001:db8::5"), want: true},
+		{name: "outside", allowed: allowed, clientIP: netip.MustParseAddr("203.0.113.7"), want: false},
+		{name: "unknown", allowed: allowed, clientIP: netip.Addr{}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := checkClientIP(tt.allowed, tt.clientIP)
+			if tt.want && err != nil {
+				t.Errorf("checkClientIP() error = %v, want nil", err)
+			}
+			if !tt.want && !errors.Is(err, ErrClientIPNotAllowed) {
+				t.Errorf("checkClientIP() error = %v, want %v", err, ErrClientIPNotAllowed)
+			}
+		})
+	}
+}
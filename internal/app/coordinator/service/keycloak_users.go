@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// ErrUserNotFound is returned when no user has the looked up email.
+var ErrUserNotFound = errors.New("user not found")
+
+// DirectoryUser is a user account found in a UserDirectory, such as the
+// Keycloak realm. ID is the subject of the user's tokens.
+type DirectoryUser struct {
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+}
+
+// LookupUserByEmail finds the Keycloak user with the given email through the
+// Keycloak admin API. It authenticates as the coordinator client's service
+// account, which needs service accounts enabled and the realm-management
+// view-users role.
+func (s *OIDCService) LookupUserByEmail(ctx context.Context, email string) (*DirectoryUser, error) {
+	config := s.getConfig()
+	token, err := s.serviceAccountToken(ctx, config)
+	if err != nil {
+		return nil, err
+	}
+
+	usersURL := fmt.Sprintf(
+		"%s/admin/realms/%s/users?%s",
+		config.KeycloakURL,
+		url.PathEscape(config.Realm),
+		url.Values{"email": {email}, "exact": {"true"}}.Encode(),
+	)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, usersURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create users request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("users request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read users response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("look up user: status %d, body: %s", resp.StatusCode, string(body))
+	}
+
+	var users []DirectoryUser
+	if err := json.Unmarshal(body, &users); err != nil {
+		return nil, fmt.Errorf("parse users response: %w", err)
+	}
+	for _, u := range users {
+		if strings.EqualFold(u.Email, email) {
+			return &u, nil
+		}
+	}
+	return nil, ErrUserNotFound
+}
+
+// serviceAccountToken obtains an access token for the coordinator client's
+// service account with the client credentials grant.
+func (s *OIDCService) serviceAccountToken(ctx context.Context, config OIDCConfig) (string, error) {
+	data := url.Values{}
+	data.Set("grant_type", "client_credentials")
+	data.Set("client_id", config.ClientID)
+	data.Set("client_secret", config.ClientSecret)
+
+	tokenResp, err := s.requestToken(ctx, config, data)
+	if err != nil {
+		return "", err
+	}
+	return tokenResp.AccessToken, nil
+}
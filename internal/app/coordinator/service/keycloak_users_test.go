@@ -0,0 +1,60 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestOIDCService_LookupUserByEmail(t *testing.T) {
+	var gotGrant, gotAuth, gotEmail string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/realms/wonder-mesh/protocol/openid-connect/token":
+			if err := r.ParseForm(); err != nil {
+				t.Errorf("parse token request: %v", err)
+			}
+			gotGrant = r.PostForm.Get("grant_type")
+			_, _ = w.Write([]byte(`{"access_token":"service-token","token_type":"Bearer"}`))
+		case "/admin/realms/wonder-mesh/users":
+			gotAuth = r.Header.Get("Authorization")
+			gotEmail = r.URL.Query().Get("email")
+			if gotEmail == "bob@example.com" {
+				_, _ = w.Write([]byte(`[{"id":"user-1","username":"bob","email":"Bob@example.com"}]`))
+				return
+			}
+			_, _ = w.Write([]byte(`[]`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer server.Close()
+
+	svc := NewOIDCService(OIDCConfig{
+		KeycloakURL:  server.URL,
+		Realm:        "wonder-mesh",
+		ClientID:     "coordinator",
+		ClientSecret: "secret",
+	}, nil)
+
+	user, err := svc.LookupUserByEmail(context.Background(), "bob@example.com")
+	if err != nil {
+		t.Fatalf("LookupUserByEmail: %v", err)
+	}
+	if user.ID != "user-1" {
+		t.Errorf("user ID = %q, want %q", user.ID, "user-1")
+	}
+	if gotGrant != "client_credentials" {
+		t.Errorf("grant_type = %q, want client_credentials", gotGrant)
+	}
+	if gotAuth != "Bearer service-token" {
+		t.Errorf("Authorization = %q, want the service account token", gotAuth)
+	}
+
+	if _, err := svc.LookupUserByEmail(context.Background(), "nobody@example.com"); !errors.Is(err, ErrUserNotFound) {
+		t.Errorf("LookupUserByEmail(unknown) = %v, want %v", err, ErrUserNotFound)
+	}
+}
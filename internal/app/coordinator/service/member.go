@@ -0,0 +1,318 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+var (
+	ErrNotWonderNetOwner = errors.New("only owners of the wonder net can manage its members")
+	ErrMemberNotFound    = errors.New("member not found")
+	ErrAlreadyMember     = errors.New("user is already a member of the wonder net")
+	ErrInvalidMember     = errors.New("invalid member")
+	ErrInviteNotFound    = errors.New("invite not found")
+	ErrInvitePending     = errors.New("user already has a pending invite to the wonder net")
+	ErrInviteNotPending  = errors.New("invite is no longer pending")
+)
+
+// InviteTTL is how long an invite can be accepted.
+const InviteTTL = 7 * 24 * time.Hour
+
+// UserDirectory looks up users by email, such as the Keycloak realm or the
+// users of the embedded identity provider.
+type UserDirectory interface {
+	LookupUserByEmail(ctx context.Context, email string) (*DirectoryUser, error)
+}
+
+// MemberService shares wonder nets with other users. An owner invites a user
+// by email with a role; the email is looked up in the user directory, and
+// only that user can accept the invite and become a member.
+type MemberService struct {
+	memberRepository    *repository.MemberRepository
+	wonderNetRepository *repository.WonderNetRepository
+	users               UserDirectory
+}
+
+// NewMemberService creates a new MemberService.
+func NewMemberService(
+	memberRepository *repository.MemberRepository,
+	wonderNetRepository *repository.WonderNetRepository,
+	users UserDirectory,
+) *MemberService {
+	return &MemberService{
+		memberRepository:    memberRepository,
+		wonderNetRepository: wonderNetRepository,
+		users:               users,
+	}
+}
+
+// Role returns the role of a user in a wonder net, or an empty string if the
+// user has no access to it.
+func (s *MemberService) Role(ctx context.Context, wonderNet *repository.WonderNet, userID string) (string, error) {
+	if wonderNet.OwnerID == userID {
+		return repository.RoleOwner, nil
+	}
+	m, err := s.memberRepository.GetMember(ctx, wonderNet.ID, userID)
+	if err != nil {
+		return "", fmt.Errorf("get wonder net member: %w", err)
+	}
+	if m == nil {
+		return "", nil
+	}
+	return m.Role, nil
+}
+
+// ListMembers lists the users with access to a wonder net, starting with the
+// user who owns it.
+func (s *MemberService) ListMembers(ctx context.Context, wonderNet *repository.WonderNet) ([]*repository.WonderNetMember, error) {
+	members, err := s.memberRepository.ListMembers(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	owner := &repository.WonderNetMember{
+		WonderNetID: wonderNet.ID,
+		UserID:      wonderNet.OwnerID,
+		Role:        repository.RoleOwner,
+		CreatedAt:   wonderNet.CreatedAt,
+	}
+	return append([]*repository.WonderNetMember{owner}, members...), nil
+}
+
+// Invite invites the user with the given email to the wonder net with role.
+// callerRole is the inviting user's role, which must be owner.
+func (s *MemberService) Invite(ctx context.Context, wonderNet *repository.WonderNet, callerRole, invitedBy, email, role string) (*repository.WonderNetInvite, error) {
+	if callerRole != repository.RoleOwner {
+		return nil, ErrNotWonderNetOwner
+	}
+	email = strings.TrimSpace(email)
+	if !strings.Contains(email, "@") {
+		return nil, fmt.Errorf("%w: email is required", ErrInvalidMember)
+	}
+	if err := validateMemberRole(role); err != nil {
+		return nil, err
+	}
+
+	user, err := s.users.LookupUserByEmail(ctx, email)
+	if err != nil {
+		return nil, err
+	}
+
+	existingRole, err := s.Role(ctx, wonderNet, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if existingRole != "" {
+		return nil, ErrAlreadyMember
+	}
+
+	pending, err := s.memberRepository.GetPendingInvite(ctx, wonderNet.ID, user.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get pending invite: %w", err)
+	}
+	if pending != nil {
+		if !s.expire(ctx, pending) {
+			return nil, ErrInvitePending
+		}
+	}
+
+	inv, err := s.memberRepository.CreateInvite(ctx, &repository.WonderNetInvite{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNet.ID,
+		Email:       email,
+		UserID:      user.ID,
+		Role:        role,
+		InvitedBy:   invitedBy,
+		ExpiresAt:   time.Now().Add(InviteTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create invite: %w", err)
+	}
+	slog.Info("invited wonder net member", "id", inv.ID, "wonder_net_id", wonderNet.ID, "user_id", user.ID, "role", role, "invited_by", invitedBy)
+	return inv, nil
+}
+
+// ListInvites lists the pending invites of a wonder net, newest first.
+func (s *MemberService) ListInvites(ctx context.Context, wonderNet *repository.WonderNet) ([]*repository.WonderNetInvite, error) {
+	invites, err := s.memberRepository.ListPendingInvitesByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withoutExpired(ctx, invites), nil
+}
+
+// RevokeInvite withdraws a pending invite of the wonder net. callerRole must
+// be owner.
+func (s *MemberService) RevokeInvite(ctx context.Context, wonderNet *repository.WonderNet, callerRole, id string) error {
+	if callerRole != repository.RoleOwner {
+		return ErrNotWonderNetOwner
+	}
+	inv, err := s.memberRepository.GetInvite(ctx, id)
+	if err != nil {
+		return err
+	}
+	if inv == nil || inv.WonderNetID != wonderNet.ID {
+		return ErrInviteNotFound
+	}
+	revoked, err := s.memberRepository.DecideInvite(ctx, id, repository.InviteRevoked)
+	if err != nil {
+		return err
+	}
+	if !revoked {
+		return ErrInviteNotPending
+	}
+	return nil
+}
+
+// ListInvitesForUser lists the pending invites addressed to a user, newest
+// first.
+func (s *MemberService) ListInvitesForUser(ctx context.Context, userID string) ([]*repository.WonderNetInvite, error) {
+	invites, err := s.memberRepository.ListPendingInvitesByUser(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return s.withoutExpired(ctx, invites), nil
+}
+
+// AcceptInvite makes the invited user a member of the wonder net with the
+// role of the invite, and returns the wonder net. The invite is claimed
+// together with adding the member, so an invite revoked, declined, or
+// accepted concurrently returns ErrInviteNotPending and adds no member.
+func (s *MemberService) AcceptInvite(ctx context.Context, userID, id string) (*repository.WonderNet, error) {
+	inv, err := s.invitedUserInvite(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	wonderNet, err := s.wonderNetRepository.Get(ctx, inv.WonderNetID)
+	if err != nil {
+		return nil, fmt.Errorf("get wonder net: %w", err)
+	}
+	if wonderNet == nil {
+		return nil, ErrInviteNotFound
+	}
+
+	accepted, err := s.memberRepository.AcceptInvite(ctx, inv, userID)
+	if err != nil {
+		return nil, fmt.Errorf("accept invite: %w", err)
+	}
+	if !accepted {
+		return nil, ErrInviteNotPending
+	}
+	slog.Info("accepted wonder net invite", "id", inv.ID, "wonder_net_id", inv.WonderNetID, "user_id", userID, "role", inv.Role)
+	return wonderNet, nil
+}
+
+// DeclineInvite declines an invite addressed to the user.
+func (s *MemberService) DeclineInvite(ctx context.Context, userID, id string) error {
+	inv, err := s.invitedUserInvite(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	declined, err := s.memberRepository.DecideInvite(ctx, inv.ID, repository.InviteDeclined)
+	if err != nil {
+		return err
+	}
+	if !declined {
+		return ErrInviteNotPending
+	}
+	return nil
+}
+
+// UpdateMemberRole changes the role of a member of the wonder net.
+// callerRole must be owner. The user who owns the wonder net always keeps
+// the owner role.
+func (s *MemberService) UpdateMemberRole(ctx context.Context, wonderNet *repository.WonderNet, callerRole, userID, role string) error {
+	if callerRole != repository.RoleOwner {
+		return ErrNotWonderNetOwner
+	}
+	if err := validateMemberRole(role); err != nil {
+		return err
+	}
+	if userID == wonderNet.OwnerID {
+		return fmt.Errorf("%w: the role of the wonder net's owner cannot be changed", ErrInvalidMember)
+	}
+	updated, err := s.memberRepository.UpdateMemberRole(ctx, wonderNet.ID, userID, role)
+	if err != nil {
+		return err
+	}
+	if !updated {
+		return ErrMemberNotFound
+	}
+	return nil
+}
+
+// RemoveMember removes a member from the wonder net. Owners can remove any
+// member, and every member can remove themselves to leave the wonder net.
+// The user who owns the wonder net cannot be removed.
+func (s *MemberService) RemoveMember(ctx context.Context, wonderNet *repository.WonderNet, callerID, callerRole, userID string) error {
+	if callerRole != repository.RoleOwner && callerID != userID {
+		return ErrNotWonderNetOwner
+	}
+	if userID == wonderNet.OwnerID {
+		return fmt.Errorf("%w: the wonder net's owner cannot be removed", ErrInvalidMember)
+	}
+	removed, err := s.memberRepository.RemoveMember(ctx, wonderNet.ID, userID)
+	if err != nil {
+		return err
+	}
+	if !removed {
+		return ErrMemberNotFound
+	}
+	slog.Info("removed wonder net member", "wonder_net_id", wonderNet.ID, "user_id", userID, "removed_by", callerID)
+	return nil
+}
+
+// invitedUserInvite returns the pending invite id if it is addressed to
+// userID.
+func (s *MemberService) invitedUserInvite(ctx context.Context, userID, id string) (*repository.WonderNetInvite, error) {
+	inv, err := s.memberRepository.GetInvite(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil || inv.UserID != userID {
+		return nil, ErrInviteNotFound
+	}
+	if inv.Status != repository.InvitePending || s.expire(ctx, inv) {
+		return nil, ErrInviteNotPending
+	}
+	return inv, nil
+}
+
+// withoutExpired drops the invites past their expiry, marking them expired.
+func (s *MemberService) withoutExpired(ctx context.Context, invites []*repository.WonderNetInvite) []*repository.WonderNetInvite {
+	live := invites[:0]
+	for _, inv := range invites {
+		if !s.expire(ctx, inv) {
+			live = append(live, inv)
+		}
+	}
+	return live
+}
+
+// expire marks a pending invite past its expiry as expired, and reports
+// whether it was past its expiry.
+func (s *MemberService) expire(ctx context.Context, inv *repository.WonderNetInvite) bool {
+	if time.Now().Before(inv.ExpiresAt) {
+		return false
+	}
+	if _, err := s.memberRepository.DecideInvite(ctx, inv.ID, repository.InviteExpired); err != nil {
+		slog.Warn("expire wonder net invite", "id", inv.ID, "error", err)
+	}
+	return true
+}
+
+func validateMemberRole(role string) error {
+	switch role {
+	case repository.RoleOwner, repository.RoleMember, repository.RoleReadOnly:
+		return nil
+	default:
+		return fmt.Errorf("%w: role must be %s, %s, or %s", ErrInvalidMember, repository.RoleOwner, repository.RoleMember, repository.RoleReadOnly)
+	}
+}
@@ -0,0 +1,55 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestValidateMemberRole(t *testing.T) {
+	for _, role := range []string{repository.RoleOwner, repository.RoleMember, repository.RoleReadOnly} {
+		if err := validateMemberRole(role); err != nil {
+			t.Errorf("validateMemberRole(%q) = %v, want nil", role, err)
+		}
+	}
+	for _, role := range []string{"", "admin", "Owner"} {
+		if err := validateMemberRole(role); !errors.Is(err, ErrInvalidMember) {
+			t.Errorf("validateMemberRole(%q) = %v, want %v", role, err, ErrInvalidMember)
+		}
+	}
+}
+
+func TestMemberServiceOwnerOnly(t *testing.T) {
+	ctx := context.Background()
+	// Permission checks come before any lookup, so no repositories are needed.
+	svc := NewMemberService(nil, nil, nil)
+	wonderNet := &repository.WonderNet{ID: "wn", OwnerID: "alice"}
+
+	if role, err := svc.Role(ctx, wonderNet, "alice"); err != nil || role != repository.RoleOwner {
+		t.Errorf("Role(owner) = %q, %v, want %q", role, err, repository.RoleOwner)
+	}
+
+	for _, role := range []string{repository.RoleMember, repository.RoleReadOnly} {
+		if _, err := svc.Invite(ctx, wonderNet, role, "bob", "carol@example.com", repository.RoleMember); !errors.Is(err, ErrNotWonderNetOwner) {
+			t.Errorf("Invite() as %s = %v, want %v", role, err, ErrNotWonderNetOwner)
+		}
+		if err := svc.UpdateMemberRole(ctx, wonderNet, role, "carol", repository.RoleOwner); !errors.Is(err, ErrNotWonderNetOwner) {
+			t.Errorf("UpdateMemberRole() as %s = %v, want %v", role, err, ErrNotWonderNetOwner)
+		}
+		if err := svc.RemoveMember(ctx, wonderNet, "bob", role, "carol"); !errors.Is(err, ErrNotWonderNetOwner) {
+			t.Errorf("RemoveMember() of another member as %s = %v, want %v", role, err, ErrNotWonderNetOwner)
+		}
+		if err := svc.RevokeInvite(ctx, wonderNet, role, "invite"); !errors.Is(err, ErrNotWonderNetOwner) {
+			t.Errorf("RevokeInvite() as %s = %v, want %v", role, err, ErrNotWonderNetOwner)
+		}
+	}
+
+	if err := svc.RemoveMember(ctx, wonderNet, "alice", repository.RoleOwner, "alice"); !errors.Is(err, ErrInvalidMember) {
+		t.Errorf("RemoveMember(owner) = %v, want %v", err, ErrInvalidMember)
+	}
+	if err := svc.UpdateMemberRole(ctx, wonderNet, repository.RoleOwner, "alice", repository.RoleReadOnly); !errors.Is(err, ErrInvalidMember) {
+		t.Errorf("UpdateMemberRole(owner) = %v, want %v", err, ErrInvalidMember)
+	}
+}
@@ -0,0 +1,307 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+var ErrNodeNotPending = errors.New("node is not waiting for approval")
+
+// PendingNode is a node that registered with a wonder net requiring node
+// approval and has not been approved yet. SeenAt is when the approval
+// reconciler put it on hold.
+type PendingNode struct {
+	ID       uint64
+	Name     string
+	Hostname string
+	IPAddrs  []string
+	Online   bool
+	SeenAt   time.Time
+}
+
+// NodeApprovalService holds newly registered nodes of wonder nets that
+// require node approval until an owner approves them. The mesh does not
+// notify the coordinator when nodes register, so new nodes are found by
+// periodic reconciliation and tagged with the wonder net's pending tag,
+// which takes them out of every ACL rule of their wonder net.
+type NodeApprovalService struct {
+	wonderNetRepository *repository.WonderNetRepository
+	approvalRepository  *repository.NodeApprovalRepository
+	meshBackend         meshbackend.MeshBackend
+
+	// mu serializes reconciliation with turning approval on and off, so
+	// that a node is never put on hold after approval was turned off.
+	mu sync.Mutex
+}
+
+// NewNodeApprovalService creates a new NodeApprovalService.
+func NewNodeApprovalService(
+	wonderNetRepository *repository.WonderNetRepository,
+	approvalRepository *repository.NodeApprovalRepository,
+	meshBackend meshbackend.MeshBackend,
+) *NodeApprovalService {
+	return &NodeApprovalService{
+		wonderNetRepository: wonderNetRepository,
+		approvalRepository:  approvalRepository,
+		meshBackend:         meshBackend,
+	}
+}
+
+// pendingNodeTag returns the tag that holds the pending nodes of a wonder net.
+func pendingNodeTag(wonderNet *repository.WonderNet) string {
+	return headscale.WonderNetTag(wonderNet.HeadscaleUser, headscale.PendingNodeTagName)
+}
+
+// SetRequired turns node approval on or off for a wonder net owned by
+// ownerID. Turning it on records the nodes the wonder net already has as
+// approved, so only nodes that register afterwards are held. Turning it off
+// approves every pending node.
+func (s *NodeApprovalService) SetRequired(ctx context.Context, ownerID, wonderNetID string, required bool, decidedBy string) (*repository.WonderNet, error) {
+	wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	if wonderNet == nil || wonderNet.OwnerID != ownerID {
+		return nil, ErrNoWonderNet
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if required {
+		if err := s.approveExisting(ctx, wonderNet, decidedBy); err != nil {
+			return nil, err
+		}
+	}
+	if err := s.wonderNetRepository.UpdateRequireNodeApproval(ctx, wonderNet.ID, required); err != nil {
+		return nil, err
+	}
+	wonderNet.RequireNodeApproval = required
+	if !required {
+		if err := s.approvePending(ctx, wonderNet, decidedBy); err != nil {
+			return nil, err
+		}
+	}
+
+	slog.Info("set node approval", "wonder_net_id", wonderNet.ID, "owner_id", ownerID, "required", required)
+	return wonderNet, nil
+}
+
+// approveExisting records the current nodes of a wonder net as approved.
+// Nodes that already have a record keep it.
+func (s *NodeApprovalService) approveExisting(ctx context.Context, wonderNet *repository.WonderNet, decidedBy string) error {
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, node := range nodes {
+		if err := s.approvalRepository.Create(ctx, node.ID, wonderNet.ID, repository.NodeApprovalApproved, decidedBy, &now); err != nil {
+			return fmt.Errorf("record node %s: %w", node.ID, err)
+		}
+	}
+	return nil
+}
+
+// approvePending approves every pending node of a wonder net.
+func (s *NodeApprovalService) approvePending(ctx context.Context, wonderNet *repository.WonderNet, decidedBy string) error {
+	pending, err := s.approvalRepository.ListPendingByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+	for _, approval := range pending {
+		if err := s.approve(ctx, wonderNet, approval.NodeID, decidedBy); err != nil {
+			errs = append(errs, fmt.Errorf("approve node %s: %w", approval.NodeID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// ListPending lists the nodes of a wonder net that wait for approval, oldest
+// first. Nodes that left the mesh in the meantime are skipped.
+func (s *NodeApprovalService) ListPending(ctx context.Context, wonderNet *repository.WonderNet) ([]*PendingNode, error) {
+	pending, err := s.approvalRepository.ListPendingByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	if len(pending) == 0 {
+		return []*PendingNode{}, nil
+	}
+
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]*meshbackend.Node, len(nodes))
+	for _, node := range nodes {
+		byID[node.ID] = node
+	}
+
+	result := make([]*PendingNode, 0, len(pending))
+	for _, approval := range pending {
+		node, ok := byID[approval.NodeID]
+		if !ok {
+			continue
+		}
+		pendingNode := &PendingNode{
+			Name:     node.Name,
+			Hostname: node.Hostname,
+			IPAddrs:  node.Addresses,
+			Online:   node.Online,
+			SeenAt:   approval.CreatedAt,
+		}
+		if id, err := strconv.ParseUint(node.ID, 10, 64); err == nil {
+			pendingNode.ID = id
+		}
+		result = append(result, pendingNode)
+	}
+	return result, nil
+}
+
+// Approve activates a pending node of a wonder net. callerRole is the
+// approving user's role, which must be owner.
+func (s *NodeApprovalService) Approve(ctx context.Context, wonderNet *repository.WonderNet, callerRole, nodeID, decidedBy string) (*repository.NodeApproval, error) {
+	if callerRole != repository.RoleOwner {
+		return nil, ErrNotWonderNetOwner
+	}
+
+	approval, err := s.approvalRepository.Get(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if approval == nil || approval.WonderNetID != wonderNet.ID {
+		return nil, ErrNodeNotFound
+	}
+	if approval.Status != repository.NodeApprovalPending {
+		return nil, ErrNodeNotPending
+	}
+
+	if err := s.approve(ctx, wonderNet, nodeID, decidedBy); err != nil {
+		return nil, err
+	}
+
+	slog.Info("approved node", "wonder_net_id", wonderNet.ID, "node_id", nodeID, "decided_by", decidedBy)
+	return s.approvalRepository.Get(ctx, nodeID)
+}
+
+// approve removes the pending tag from a node and records it as approved.
+func (s *NodeApprovalService) approve(ctx context.Context, wonderNet *repository.WonderNet, nodeID, decidedBy string) error {
+	node, err := s.meshBackend.GetNode(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	tag := pendingNodeTag(wonderNet)
+	if slices.Contains(node.Tags, tag) {
+		tags := slices.DeleteFunc(slices.Clone(node.Tags), func(t string) bool { return t == tag })
+		if err := s.meshBackend.SetTags(ctx, nodeID, tags); err != nil {
+			return err
+		}
+	}
+	return s.approvalRepository.Decide(ctx, nodeID, repository.NodeApprovalApproved, decidedBy)
+}
+
+// Apply puts the nodes of a wonder net that registered since the last run on
+// hold, keeps pending nodes tagged, and forgets nodes that left the mesh.
+func (s *NodeApprovalService) Apply(ctx context.Context, wonderNet *repository.WonderNet) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current, err := s.wonderNetRepository.Get(ctx, wonderNet.ID)
+	if err != nil {
+		return err
+	}
+	if current == nil || !current.RequireNodeApproval {
+		return nil
+	}
+
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return err
+	}
+	approvals, err := s.approvalRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return err
+	}
+	statuses := make(map[string]string, len(approvals))
+	for _, approval := range approvals {
+		statuses[approval.NodeID] = approval.Status
+	}
+
+	tag := pendingNodeTag(wonderNet)
+	var errs []error
+	for _, node := range nodes {
+		status, known := statuses[node.ID]
+		delete(statuses, node.ID)
+		if known && status != repository.NodeApprovalPending {
+			continue
+		}
+
+		if !slices.Contains(node.Tags, tag) {
+			if err := s.meshBackend.SetTags(ctx, node.ID, append(slices.Clone(node.Tags), tag)); err != nil {
+				errs = append(errs, fmt.Errorf("tag node %s: %w", node.ID, err))
+				continue
+			}
+		}
+		if !known {
+			if err := s.approvalRepository.Create(ctx, node.ID, wonderNet.ID, repository.NodeApprovalPending, "", nil); err != nil {
+				errs = append(errs, fmt.Errorf("record node %s: %w", node.ID, err))
+				continue
+			}
+			slog.Info("node waiting for approval", "wonder_net_id", wonderNet.ID, "node_id", node.ID, "hostname", node.Hostname)
+		}
+	}
+
+	// Whatever is left belongs to nodes that are no longer in the mesh.
+	for nodeID := range statuses {
+		if err := s.approvalRepository.Delete(ctx, nodeID); err != nil {
+			errs = append(errs, fmt.Errorf("forget node %s: %w", nodeID, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Reconcile applies node approval to every wonder net that requires it.
+func (s *NodeApprovalService) Reconcile(ctx context.Context) error {
+	wonderNets, err := s.wonderNetRepository.ListRequiringNodeApproval(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, wonderNet := range wonderNets {
+		if err := s.Apply(ctx, wonderNet); err != nil {
+			slog.Warn("apply node approval", "wonder_net_id", wonderNet.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Run reconciles node approval every interval until ctx is cancelled.
+func (s *NodeApprovalService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				slog.Error("reconcile node approval", "error", err)
+			}
+		}
+	}
+}
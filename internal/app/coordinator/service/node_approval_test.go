@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestApproveNodeRequiresOwner(t *testing.T) {
+	s := NewNodeApprovalService(nil, nil, nil)
+	wonderNet := &repository.WonderNet{ID: "wn-1", HeadscaleUser: "hs-1"}
+
+	for _, role := range []string{repository.RoleMember, repository.RoleReadOnly, ""} {
+		t.Run(role, func(t *testing.T) {
+			_, err := s.Approve(context.Background(), wonderNet, role, "1", "someone@example.com")
+			if !errors.Is(err, ErrNotWonderNetOwner) {
+				t.Errorf("Approve() as %q error = %v, want %v", role, err, ErrNotWonderNetOwner)
+			}
+		})
+	}
+}
+
+func TestPendingNodeTag(t *testing.T) {
+	got := pendingNodeTag(&repository.WonderNet{HeadscaleUser: "hs-1"})
+	if want := "tag:wn-hs-1-pending"; got != want {
+		t.Errorf("pendingNodeTag() = %q, want %q", got, want)
+	}
+}
@@ -0,0 +1,298 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+var (
+	ErrNodeDecommissionNotFound   = errors.New("node decommission not found")
+	ErrNodeDecommissionInProgress = errors.New("node is already being decommissioned")
+	ErrNodeWipeNotPending         = errors.New("node decommission is not waiting for a wipe result")
+	ErrInvalidNodeDecommission    = errors.New("invalid node decommission")
+)
+
+const (
+	// NodeWipeTimeout is how long a decommission waits for the node's worker
+	// to run the wipe job and report back before it fails.
+	NodeWipeTimeout = time.Hour
+
+	maxNodeDecommissionReasonLength = 500
+
+	// maxNodeWipeOutputLength caps the wipe job output kept in the archive;
+	// longer output keeps its end, where errors usually are.
+	maxNodeWipeOutputLength = 64 << 10
+)
+
+// NodeDecommissionService takes nodes out of service in one step: it cordons
+// the node by withdrawing its approved routes and removing it from service
+// publications and grants, optionally has the node's worker run its wipe job,
+// expires the node's key, deletes the node from the mesh, and archives what
+// the node was.
+//
+// A decommission with a wipe is finished asynchronously: the worker picks
+// the wipe up with its next heartbeat and reports the result, and only a
+// successful wipe lets the teardown continue. A failed or timed out wipe
+// leaves the node cordoned but in the mesh, so it can be looked at and
+// decommissioned again.
+type NodeDecommissionService struct {
+	decommissionRepository  *repository.NodeDecommissionRepository
+	nodeHeartbeatRepository *repository.NodeHeartbeatRepository
+	workerTokenRepository   *repository.WorkerTokenRepository
+	serviceCatalogService   *ServiceCatalogService
+	meshBackend             meshbackend.MeshBackend
+}
+
+// NewNodeDecommissionService creates a new NodeDecommissionService.
+func NewNodeDecommissionService(
+	decommissionRepository *repository.NodeDecommissionRepository,
+	nodeHeartbeatRepository *repository.NodeHeartbeatRepository,
+	workerTokenRepository *repository.WorkerTokenRepository,
+	serviceCatalogService *ServiceCatalogService,
+	meshBackend meshbackend.MeshBackend,
+) *NodeDecommissionService {
+	return &NodeDecommissionService{
+		decommissionRepository:  decommissionRepository,
+		nodeHeartbeatRepository: nodeHeartbeatRepository,
+		workerTokenRepository:   workerTokenRepository,
+		serviceCatalogService:   serviceCatalogService,
+		meshBackend:             meshBackend,
+	}
+}
+
+// Decommission starts decommissioning a node of the wonder net. Without a
+// wipe the node is torn down right away and the returned decommission is
+// completed; with a wipe it stays in progress until the worker reports the
+// wipe result. Teardown failures are recorded on the decommission and
+// returned.
+func (s *NodeDecommissionService) Decommission(ctx context.Context, wonderNet *repository.WonderNet, nodeID, requestedBy, reason string, wipe bool) (*repository.NodeDecommission, error) {
+	if len(reason) > maxNodeDecommissionReasonLength {
+		return nil, fmt.Errorf("%w: reason must be at most %d characters", ErrInvalidNodeDecommission, maxNodeDecommissionReasonLength)
+	}
+
+	node, err := s.meshBackend.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Realm != wonderNet.HeadscaleUser {
+		return nil, ErrNodeNotFound
+	}
+
+	active, err := s.decommissionRepository.GetActiveByNode(ctx, node.ID)
+	if err != nil {
+		return nil, err
+	}
+	if active != nil {
+		return nil, ErrNodeDecommissionInProgress
+	}
+
+	hb, err := s.nodeHeartbeatRepository.Get(ctx, node.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get node heartbeat: %w", err)
+	}
+
+	record := &repository.NodeDecommission{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNet.ID,
+		NodeID:      node.ID,
+		NodeName:    node.Name,
+		Hostname:    node.Hostname,
+		Addresses:   node.Addresses,
+		LastSeenAt:  node.LastSeen,
+		RequestedBy: requestedBy,
+		Reason:      reason,
+		WipeStatus:  repository.NodeWipeSkipped,
+	}
+	if hb != nil {
+		record.OS = hb.OS
+	}
+	if wipe {
+		record.WipeStatus = repository.NodeWipePending
+	}
+	d, err := s.decommissionRepository.Create(ctx, record)
+	if err != nil {
+		return nil, err
+	}
+	slog.Info("started node decommission", "id", d.ID, "wonder_net_id", wonderNet.ID, "node_id", node.ID, "requested_by", requestedBy, "wipe", wipe)
+
+	if err := s.cordon(ctx, wonderNet, node); err != nil {
+		return nil, s.fail(ctx, d, fmt.Errorf("cordon node: %w", err))
+	}
+	if wipe {
+		return d, nil
+	}
+	return s.teardown(ctx, d)
+}
+
+// ListDecommissions lists the node decommissions of a wonder net, newest first.
+func (s *NodeDecommissionService) ListDecommissions(ctx context.Context, wonderNet *repository.WonderNet) ([]*repository.NodeDecommission, error) {
+	return s.decommissionRepository.ListByWonderNet(ctx, wonderNet.ID)
+}
+
+// GetDecommission returns a node decommission of the wonder net.
+func (s *NodeDecommissionService) GetDecommission(ctx context.Context, wonderNet *repository.WonderNet, id string) (*repository.NodeDecommission, error) {
+	d, err := s.decommissionRepository.Get(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.WonderNetID != wonderNet.ID {
+		return nil, ErrNodeDecommissionNotFound
+	}
+	return d, nil
+}
+
+// PendingWipe returns the decommission waiting for a node's worker to run
+// its wipe job, or nil if there is none.
+func (s *NodeDecommissionService) PendingWipe(ctx context.Context, nodeID string) (*repository.NodeDecommission, error) {
+	d, err := s.decommissionRepository.GetActiveByNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil || d.WipeStatus != repository.NodeWipePending {
+		return nil, nil
+	}
+	return d, nil
+}
+
+// CompleteWipe records the wipe result the worker of node nodeID reported.
+// A successful wipe continues the teardown of the node; a failed one ends
+// the decommission as failed. Either way the updated decommission is
+// returned. Decommissions of other nodes return ErrNodeDecommissionNotFound.
+func (s *NodeDecommissionService) CompleteWipe(ctx context.Context, wonderNet *repository.WonderNet, nodeID, id string, succeeded bool, output string) (*repository.NodeDecommission, error) {
+	d, err := s.GetDecommission(ctx, wonderNet, id)
+	if err != nil {
+		return nil, err
+	}
+	if d.NodeID != nodeID {
+		return nil, ErrNodeDecommissionNotFound
+	}
+
+	wipeStatus := repository.NodeWipeFailed
+	if succeeded {
+		wipeStatus = repository.NodeWipeSucceeded
+	}
+	finished, err := s.decommissionRepository.FinishWipe(ctx, d.ID, wipeStatus, truncateWipeOutput(output))
+	if err != nil {
+		return nil, err
+	}
+	if !finished {
+		return nil, ErrNodeWipeNotPending
+	}
+	slog.Info("node wipe finished", "id", d.ID, "wonder_net_id", wonderNet.ID, "node_id", d.NodeID, "wipe_status", wipeStatus)
+
+	if !succeeded {
+		_ = s.fail(ctx, d, errors.New("wipe job failed, the node was left cordoned"))
+	} else if _, err := s.teardown(ctx, d); err != nil {
+		// The wipe result was recorded, so the report itself succeeded; a
+		// teardown failure is recorded on the decommission instead.
+		slog.Warn("tear down node after wipe", "id", d.ID, "error", err)
+	}
+	return s.decommissionRepository.Get(ctx, d.ID)
+}
+
+// ExpireStaleWipes fails decommissions whose worker did not report a wipe
+// result within NodeWipeTimeout.
+func (s *NodeDecommissionService) ExpireStaleWipes(ctx context.Context) error {
+	stale, err := s.decommissionRepository.ListStaleWipes(ctx, time.Now().Add(-NodeWipeTimeout))
+	if err != nil {
+		return fmt.Errorf("list stale node wipes: %w", err)
+	}
+
+	for _, d := range stale {
+		finished, err := s.decommissionRepository.FinishWipe(ctx, d.ID, repository.NodeWipeTimedOut, "")
+		if err != nil {
+			slog.Warn("time out node wipe", "id", d.ID, "error", err)
+			continue
+		}
+		if finished {
+			_ = s.fail(ctx, d, fmt.Errorf("worker did not report a wipe result within %s, the node was left cordoned", NodeWipeTimeout))
+		}
+	}
+	return nil
+}
+
+// Run fails stale node wipes every interval until ctx is cancelled.
+func (s *NodeDecommissionService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireStaleWipes(ctx); err != nil {
+				slog.Error("expire node wipes", "error", err)
+			}
+		}
+	}
+}
+
+// cordon withdraws the node's approved routes and removes it from the
+// wonder net's services, so no traffic is sent through or to it anymore.
+func (s *NodeDecommissionService) cordon(ctx context.Context, wonderNet *repository.WonderNet, node *meshbackend.Node) error {
+	if len(node.ApprovedRoutes) > 0 {
+		if err := s.meshBackend.SetApprovedRoutes(ctx, node.ID, nil); err != nil {
+			return fmt.Errorf("withdraw approved routes: %w", err)
+		}
+	}
+	return s.serviceCatalogService.RemoveNode(ctx, wonderNet, node.ID)
+}
+
+// teardown revokes the worker tokens of the node, expires its key, deletes
+// it from the mesh, and completes the decommission.
+func (s *NodeDecommissionService) teardown(ctx context.Context, d *repository.NodeDecommission) (*repository.NodeDecommission, error) {
+	var authKeyID string
+	if node, err := s.meshBackend.GetNode(ctx, d.NodeID); err == nil {
+		authKeyID = node.AuthKeyID
+	}
+	if err := s.workerTokenRepository.RevokeByNode(ctx, d.WonderNetID, d.NodeID, authKeyID); err != nil {
+		return nil, s.fail(ctx, d, fmt.Errorf("revoke worker tokens: %w", err))
+	}
+	if err := s.meshBackend.ExpireNode(ctx, d.NodeID); err != nil {
+		return nil, s.fail(ctx, d, fmt.Errorf("expire node key: %w", err))
+	}
+	if err := s.meshBackend.DeleteNode(ctx, d.NodeID); err != nil {
+		return nil, s.fail(ctx, d, fmt.Errorf("delete node: %w", err))
+	}
+	if err := s.nodeHeartbeatRepository.Delete(ctx, d.NodeID); err != nil {
+		slog.Warn("delete node heartbeat", "node_id", d.NodeID, "error", err)
+	}
+
+	ended, err := s.decommissionRepository.End(ctx, d.ID, repository.NodeDecommissionCompleted, "")
+	if err != nil {
+		return nil, err
+	}
+	if !ended {
+		return nil, ErrNodeDecommissionNotFound
+	}
+
+	slog.Info("completed node decommission", "id", d.ID, "wonder_net_id", d.WonderNetID, "node_id", d.NodeID)
+	return s.decommissionRepository.Get(ctx, d.ID)
+}
+
+// fail ends the decommission as failed with cause as its error and returns
+// cause.
+func (s *NodeDecommissionService) fail(ctx context.Context, d *repository.NodeDecommission, cause error) error {
+	if _, err := s.decommissionRepository.End(ctx, d.ID, repository.NodeDecommissionFailed, cause.Error()); err != nil {
+		slog.Error("record node decommission failure", "id", d.ID, "error", err)
+	}
+	slog.Warn("node decommission failed", "id", d.ID, "wonder_net_id", d.WonderNetID, "node_id", d.NodeID, "error", cause)
+	return cause
+}
+
+// truncateWipeOutput keeps the last maxNodeWipeOutputLength bytes of the
+// wipe job output.
+func truncateWipeOutput(output string) string {
+	if len(output) <= maxNodeWipeOutputLength {
+		return output
+	}
+	return output[len(output)-maxNodeWipeOutputLength:]
+}
@@ -0,0 +1,22 @@
+package service
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestTruncateWipeOutput(t *testing.T) {
+	short := "wiped /var/lib/app"
+	if got := truncateWipeOutput(short); got != short {
+		t.Errorf("truncateWipeOutput() = %q, want %q", got, short)
+	}
+
+	long := strings.Repeat("a", maxNodeWipeOutputLength) + "error: disk busy"
+	got := truncateWipeOutput(long)
+	if len(got) != maxNodeWipeOutputLength {
+		t.Errorf("len(truncateWipeOutput()) = %d, want %d", len(got), maxNodeWipeOutputLength)
+	}
+	if !strings.HasSuffix(got, "error: disk busy") {
+		t.Errorf("truncateWipeOutput() dropped the end of the output")
+	}
+}
@@ -0,0 +1,60 @@
+package service
+
+import "sort"
+
+// NodeEventType identifies a change in a wonder net's node topology.
+type NodeEventType string
+
+// Node event types.
+const (
+	NodeEventJoin    NodeEventType = "join"
+	NodeEventLeave   NodeEventType = "leave"
+	NodeEventOnline  NodeEventType = "online"
+	NodeEventOffline NodeEventType = "offline"
+	// NodeEventAuthorized is found by NodeWatcher only, when an owner
+	// approves a node held for node approval.
+	NodeEventAuthorized NodeEventType = "authorized"
+)
+
+// NodeEvent is a single node topology change.
+type NodeEvent struct {
+	Type NodeEventType
+	Node *Node
+}
+
+// DiffNodes returns the events that turn the prev node listing into curr,
+// ordered by node ID. A node that joins produces only a join event, whatever
+// its online state; the node itself carries that state.
+func DiffNodes(prev, curr []*Node) []NodeEvent {
+	before := make(map[uint64]*Node, len(prev))
+	for _, node := range prev {
+		before[node.ID] = node
+	}
+	after := make(map[uint64]*Node, len(curr))
+	for _, node := range curr {
+		after[node.ID] = node
+	}
+
+	var events []NodeEvent
+	for id, node := range after {
+		old, ok := before[id]
+		switch {
+		case !ok:
+			events = append(events, NodeEvent{Type: NodeEventJoin, Node: node})
+		case !old.Online && node.Online:
+			events = append(events, NodeEvent{Type: NodeEventOnline, Node: node})
+		case old.Online && !node.Online:
+			events = append(events, NodeEvent{Type: NodeEventOffline, Node: node})
+		}
+	}
+	for id, node := range before {
+		if _, ok := after[id]; !ok {
+			events = append(events, NodeEvent{Type: NodeEventLeave, Node: node})
+		}
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].Node.ID < events[j].Node.ID
+	})
+	return events
+}
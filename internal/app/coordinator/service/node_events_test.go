@@ -0,0 +1,42 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestDiffNodes(t *testing.T) {
+	a := &Node{ID: 1, Name: "a", Online: true}
+	b := &Node{ID: 2, Name: "b", Online: true}
+	bOffline := &Node{ID: 2, Name: "b", Online: false}
+	c := &Node{ID: 3, Name: "c", Online: false}
+	cOnline := &Node{ID: 3, Name: "c", Online: true}
+
+	tests := []struct {
+		name string
+		prev []*Node
+		curr []*Node
+		want []NodeEvent
+	}{
+		{"no change", []*Node{a, b}, []*Node{a, b}, nil},
+		{"join", []*Node{a}, []*Node{a, b}, []NodeEvent{{NodeEventJoin, b}}},
+		{"join offline", nil, []*Node{c}, []NodeEvent{{NodeEventJoin, c}}},
+		{"leave", []*Node{a, b}, []*Node{a}, []NodeEvent{{NodeEventLeave, b}}},
+		{"offline", []*Node{a, b}, []*Node{a, bOffline}, []NodeEvent{{NodeEventOffline, bOffline}}},
+		{"online", []*Node{c}, []*Node{cOnline}, []NodeEvent{{NodeEventOnline, cOnline}}},
+		{
+			"mixed, ordered by id",
+			[]*Node{b, c},
+			[]*Node{cOnline, a},
+			[]NodeEvent{{NodeEventJoin, a}, {NodeEventLeave, b}, {NodeEventOnline, cOnline}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := DiffNodes(tt.prev, tt.curr); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("DiffNodes() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
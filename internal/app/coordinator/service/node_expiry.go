@@ -0,0 +1,173 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// ErrInvalidNodeExpiry is returned for a node expiry outside 0 to
+// MaxNodeExpiryDays days.
+var ErrInvalidNodeExpiry = errors.New("invalid node expiry")
+
+const (
+	// MaxNodeExpiryDays is the longest node expiry a wonder net can set.
+	MaxNodeExpiryDays = 3650
+
+	// NodeExpiryRequestedBy is recorded as the requester of the
+	// decommissions that remove expired nodes.
+	NodeExpiryRequestedBy = "node-expiry"
+)
+
+// NodeExpiryService removes nodes that have been offline for longer than
+// their wonder net's node expiry. Expired nodes are decommissioned without
+// a wipe, so each removal is archived with the node's last known state, and
+// a node.expired webhook event is emitted for it.
+type NodeExpiryService struct {
+	wonderNetRepository *repository.WonderNetRepository
+	nodesService        *NodesService
+	decommissionService *NodeDecommissionService
+	webhookService      *WebhookService
+
+	// mu serializes expiry runs, so a node is not decommissioned twice.
+	mu sync.Mutex
+}
+
+// NewNodeExpiryService creates a new NodeExpiryService.
+func NewNodeExpiryService(
+	wonderNetRepository *repository.WonderNetRepository,
+	nodesService *NodesService,
+	decommissionService *NodeDecommissionService,
+	webhookService *WebhookService,
+) *NodeExpiryService {
+	return &NodeExpiryService{
+		wonderNetRepository: wonderNetRepository,
+		nodesService:        nodesService,
+		decommissionService: decommissionService,
+		webhookService:      webhookService,
+	}
+}
+
+// SetExpiryDays sets after how many days offline the nodes of a wonder net
+// owned by ownerID are removed. Zero keeps offline nodes.
+func (s *NodeExpiryService) SetExpiryDays(ctx context.Context, ownerID, wonderNetID string, days int) (*repository.WonderNet, error) {
+	if days < 0 || days > MaxNodeExpiryDays {
+		return nil, fmt.Errorf("%w: must be between 0 and %d days", ErrInvalidNodeExpiry, MaxNodeExpiryDays)
+	}
+
+	wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	if wonderNet == nil || wonderNet.OwnerID != ownerID {
+		return nil, ErrNoWonderNet
+	}
+
+	if err := s.wonderNetRepository.UpdateNodeExpiryDays(ctx, wonderNet.ID, days); err != nil {
+		return nil, err
+	}
+	wonderNet.NodeExpiryDays = days
+
+	slog.Info("set node expiry", "wonder_net_id", wonderNet.ID, "owner_id", ownerID, "days", days)
+	return wonderNet, nil
+}
+
+// StaleNodes returns the nodes of the wonder net that have been offline for
+// longer than days, the nodes an expiry after that many days would remove.
+func (s *NodeExpiryService) StaleNodes(ctx context.Context, wonderNet *repository.WonderNet, days int) ([]*Node, error) {
+	if days <= 0 || days > MaxNodeExpiryDays {
+		return nil, fmt.Errorf("%w: must be between 1 and %d days", ErrInvalidNodeExpiry, MaxNodeExpiryDays)
+	}
+
+	nodes, err := s.nodesService.ListNodes(ctx, wonderNet)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	return staleNodes(nodes, days, time.Now()), nil
+}
+
+// ExpireStaleNodes removes the stale nodes of every wonder net with a node
+// expiry. Failures are logged per node, so one node does not hold up the
+// others.
+func (s *NodeExpiryService) ExpireStaleNodes(ctx context.Context) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	wonderNets, err := s.wonderNetRepository.ListWithNodeExpiry(ctx)
+	if err != nil {
+		return fmt.Errorf("list wonder nets with node expiry: %w", err)
+	}
+
+	for _, wonderNet := range wonderNets {
+		stale, err := s.StaleNodes(ctx, wonderNet, wonderNet.NodeExpiryDays)
+		if err != nil {
+			slog.Warn("list stale nodes", "wonder_net_id", wonderNet.ID, "error", err)
+			continue
+		}
+		for _, node := range stale {
+			s.expire(ctx, wonderNet, node)
+		}
+	}
+	return nil
+}
+
+// expire decommissions a stale node and emits its node.expired event.
+func (s *NodeExpiryService) expire(ctx context.Context, wonderNet *repository.WonderNet, node *Node) {
+	reason := fmt.Sprintf("offline for more than %d days", wonderNet.NodeExpiryDays)
+	_, err := s.decommissionService.Decommission(ctx, wonderNet, strconv.FormatUint(node.ID, 10), NodeExpiryRequestedBy, reason, false)
+	if errors.Is(err, ErrNodeDecommissionInProgress) {
+		return
+	}
+	if err != nil {
+		slog.Warn("expire node", "wonder_net_id", wonderNet.ID, "node_id", node.ID, "error", err)
+		return
+	}
+	slog.Info("expired node", "wonder_net_id", wonderNet.ID, "node_id", node.ID, "name", node.Name, "last_seen", node.LastSeen)
+
+	if err := s.webhookService.Emit(ctx, wonderNet.ID, WebhookEventNodeExpired, webhookNode(node)); err != nil {
+		slog.Warn("emit node webhook event", "wonder_net_id", wonderNet.ID, "event", WebhookEventNodeExpired, "error", err)
+	}
+}
+
+// Run removes stale nodes every interval until ctx is cancelled.
+func (s *NodeExpiryService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.ExpireStaleNodes(ctx); err != nil {
+				slog.Error("expire stale nodes", "error", err)
+			}
+		}
+	}
+}
+
+// staleNodes returns the nodes that have been offline for longer than days
+// at now. Nodes that were never seen are kept, as their age is unknown.
+func staleNodes(nodes []*Node, days int, now time.Time) []*Node {
+	return nodesOfflineFor(nodes, time.Duration(days)*24*time.Hour, now)
+}
+
+// nodesOfflineFor returns the nodes that have been offline for longer than
+// d at now, leaving out nodes that were never seen.
+func nodesOfflineFor(nodes []*Node, d time.Duration, now time.Time) []*Node {
+	cutoff := now.Add(-d)
+	var stale []*Node
+	for _, node := range nodes {
+		if node.Online || node.LastSeen == nil || !node.LastSeen.Before(cutoff) {
+			continue
+		}
+		stale = append(stale, node)
+	}
+	return stale
+}
@@ -0,0 +1,48 @@
+package service
+
+import (
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestStaleNodes(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	ago := func(d time.Duration) *time.Time {
+		t := now.Add(-d)
+		return &t
+	}
+	day := 24 * time.Hour
+
+	nodes := []*Node{
+		{ID: 1, Name: "online", Online: true, LastSeen: ago(30 * day)},
+		{ID: 2, Name: "recent", LastSeen: ago(6 * day)},
+		{ID: 3, Name: "exactly-7-days", LastSeen: ago(7 * day)},
+		{ID: 4, Name: "stale", LastSeen: ago(7*day + time.Minute)},
+		{ID: 5, Name: "never-seen"},
+		{ID: 6, Name: "long-gone", LastSeen: ago(400 * day)},
+	}
+
+	tests := []struct {
+		name string
+		days int
+		want []uint64
+	}{
+		{name: "seven days", days: 7, want: []uint64{4, 6}},
+		{name: "one day", days: 1, want: []uint64{2, 3, 4, 6}},
+		{name: "one year", days: 365, want: []uint64{6}},
+		{name: "longer than any", days: 500, want: nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []uint64
+			for _, node := range staleNodes(nodes, tt.days, now) {
+				got = append(got, node.ID)
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("staleNodes(%d) = %v, want %v", tt.days, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+const (
+	// nodeNameHostnamePlaceholder is replaced by the hostname a node registers with.
+	nodeNameHostnamePlaceholder = "{hostname}"
+
+	// maxNodeNameLength is the DNS label limit that mesh node names must fit in.
+	maxNodeNameLength = 63
+	// maxNodeNameTemplateAffix bounds the fixed part of a template so that the
+	// hostname is not truncated away.
+	maxNodeNameTemplateAffix = 32
+)
+
+var (
+	ErrInvalidNodeNameTemplate = errors.New("invalid node name template")
+	ErrInvalidNodeName         = errors.New("invalid node name")
+	ErrNodeNameTaken           = errors.New("node name already taken")
+)
+
+// ValidateNodeNameTemplate checks a node name template such as "acme-{hostname}".
+// The empty template is valid and names nodes after their hostname. Otherwise the template
+// must contain {hostname}, and its fixed part may only use letters, digits,
+// and hyphens.
+func ValidateNodeNameTemplate(template string) error {
+	if template == "" {
+		return nil
+	}
+	if !strings.Contains(template, nodeNameHostnamePlaceholder) {
+		return fmt.Errorf("%w: must contain %s", ErrInvalidNodeNameTemplate, nodeNameHostnamePlaceholder)
+	}
+
+	affix := strings.ReplaceAll(template, nodeNameHostnamePlaceholder, "")
+	if len(affix) > maxNodeNameTemplateAffix {
+		return fmt.Errorf("%w: at most %d characters besides %s", ErrInvalidNodeNameTemplate, maxNodeNameTemplateAffix, nodeNameHostnamePlaceholder)
+	}
+	for _, r := range affix {
+		if !isNodeNameRune(r) && !(r >= 'A' && r <= 'Z') {
+			return fmt.Errorf("%w: unsupported character %q", ErrInvalidNodeNameTemplate, r)
+		}
+	}
+	return nil
+}
+
+// RenderNodeName applies a node name template to a hostname and normalizes
+// the result into a valid DNS label: lowercase, with characters other than
+// letters, digits, and hyphens replaced by hyphens, at most 63 characters.
+func RenderNodeName(template, hostname string) string {
+	name := strings.ToLower(strings.ReplaceAll(template, nodeNameHostnamePlaceholder, hostname))
+
+	var b strings.Builder
+	for _, r := range name {
+		if isNodeNameRune(r) {
+			b.WriteRune(r)
+		} else {
+			b.WriteByte('-')
+		}
+	}
+
+	result := strings.Trim(b.String(), "-")
+	if len(result) > maxNodeNameLength {
+		result = strings.TrimRight(result[:maxNodeNameLength], "-")
+	}
+	return result
+}
+
+// ValidateNodeName checks a name set through the rename API. It must be a
+// valid DNS label: 1 to 63 lowercase letters, digits, and hyphens, not
+// starting or ending with a hyphen.
+func ValidateNodeName(name string) error {
+	if name == "" || len(name) > maxNodeNameLength {
+		return fmt.Errorf("%w: must be 1 to %d characters", ErrInvalidNodeName, maxNodeNameLength)
+	}
+	for _, r := range name {
+		if !isNodeNameRune(r) {
+			return fmt.Errorf("%w: unsupported character %q, use lowercase letters, digits, and hyphens", ErrInvalidNodeName, r)
+		}
+	}
+	if strings.HasPrefix(name, "-") || strings.HasSuffix(name, "-") {
+		return fmt.Errorf("%w: must not start or end with a hyphen", ErrInvalidNodeName)
+	}
+	return nil
+}
+
+// uniqueNodeName returns base for n = 1 and base with the suffix -n after
+// that, shortening base so that the name fits in a DNS label.
+func uniqueNodeName(base string, n int) string {
+	if n <= 1 {
+		return base
+	}
+	suffix := "-" + strconv.Itoa(n)
+	if len(base)+len(suffix) > maxNodeNameLength {
+		base = strings.TrimRight(base[:maxNodeNameLength-len(suffix)], "-")
+	}
+	return base + suffix
+}
+
+// inNodeNameSeries reports whether uniqueNodeName gives name for base and
+// some n.
+func inNodeNameSeries(base, name string) bool {
+	if name == base {
+		return true
+	}
+	i := strings.LastIndexByte(name, '-')
+	if i < 0 {
+		return false
+	}
+	n, err := strconv.Atoi(name[i+1:])
+	return err == nil && n > 1 && uniqueNodeName(base, n) == name
+}
+
+// assignNodeNames returns the name each node of a wonder net should have.
+// Nodes renamed through the API keep the names in renamed, keyed by node ID.
+// The others are named after their hostname with the template, or with
+// "{hostname}" if there is none, and nodes whose names would collide get
+// the suffixes -2, -3, and so on. A node keeps a suffix it already has as
+// long as no older node needs the name, so that names do not shift around
+// when nodes come and go. Nodes without a hostname keep their names.
+func assignNodeNames(nodes []*meshbackend.Node, template string, renamed map[string]string) map[string]string {
+	if template == "" {
+		template = nodeNameHostnamePlaceholder
+	}
+	ordered := slices.SortedFunc(slices.Values(nodes), func(a, b *meshbackend.Node) int {
+		return cmp.Or(cmp.Compare(len(a.ID), len(b.ID)), cmp.Compare(a.ID, b.ID))
+	})
+
+	names := make(map[string]string, len(nodes))
+	taken := make(map[string]bool, len(nodes))
+	bases := make(map[string]string, len(nodes))
+	for _, node := range ordered {
+		if name, ok := renamed[node.ID]; ok {
+			names[node.ID] = name
+			taken[name] = true
+			continue
+		}
+		base := ""
+		if node.Hostname != "" {
+			base = RenderNodeName(template, node.Hostname)
+		}
+		if base == "" {
+			names[node.ID] = node.Name
+			taken[node.Name] = true
+			continue
+		}
+		bases[node.ID] = base
+	}
+
+	// Nodes that already have a name of their series keep it.
+	for _, node := range ordered {
+		base, ok := bases[node.ID]
+		if ok && !taken[node.Name] && inNodeNameSeries(base, node.Name) {
+			names[node.ID] = node.Name
+			taken[node.Name] = true
+		}
+	}
+
+	for _, node := range ordered {
+		base, ok := bases[node.ID]
+		if !ok {
+			continue
+		}
+		if _, ok := names[node.ID]; ok {
+			continue
+		}
+		for n := 1; ; n++ {
+			if name := uniqueNodeName(base, n); !taken[name] {
+				names[node.ID] = name
+				taken[name] = true
+				break
+			}
+		}
+	}
+	return names
+}
+
+func isNodeNameRune(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-'
+}
+
+// NodeNamingService names the nodes of each wonder net: after the hostname
+// they registered with, formatted by the wonder net's node name template,
+// and unique within the wonder net. Names set through the rename API take
+// precedence. The mesh does not notify the coordinator when nodes register,
+// so names are applied by periodic reconciliation of the wonder nets with a
+// template. Without one, the mesh already names nodes after their hostname,
+// and template changes and renames are applied when they are made.
+type NodeNamingService struct {
+	wonderNetRepository *repository.WonderNetRepository
+	nodeNameRepository  *repository.NodeNameRepository
+	meshBackend         meshbackend.MeshBackend
+}
+
+// NewNodeNamingService creates a new NodeNamingService.
+func NewNodeNamingService(wonderNetRepository *repository.WonderNetRepository, nodeNameRepository *repository.NodeNameRepository, meshBackend meshbackend.MeshBackend) *NodeNamingService {
+	return &NodeNamingService{
+		wonderNetRepository: wonderNetRepository,
+		nodeNameRepository:  nodeNameRepository,
+		meshBackend:         meshBackend,
+	}
+}
+
+// Apply renames the nodes of a wonder net whose names differ from the ones
+// assignNodeNames gives them. Names are derived from the nodes' hostnames,
+// so applying them again is a no-op. Names set for nodes that no longer
+// exist are forgotten.
+func (s *NodeNamingService) Apply(ctx context.Context, wonderNet *repository.WonderNet) error {
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return err
+	}
+	renamed, err := s.renamedNodes(ctx, wonderNet, nodes)
+	if err != nil {
+		return err
+	}
+
+	names := assignNodeNames(nodes, wonderNet.NodeNameTemplate, renamed)
+	var errs []error
+	for _, node := range nodes {
+		name := names[node.ID]
+		if name == "" || name == node.Name {
+			continue
+		}
+		if err := s.meshBackend.RenameNode(ctx, node.ID, name); err != nil {
+			errs = append(errs, fmt.Errorf("rename node %s to %s: %w", node.ID, name, err))
+			continue
+		}
+		slog.Info("renamed node", "wonder_net_id", wonderNet.ID, "node_id", node.ID, "from", node.Name, "to", name)
+	}
+	return errors.Join(errs...)
+}
+
+// renamedNodes returns the names set through the rename API for the nodes
+// of a wonder net, keyed by node ID, and deletes those of nodes that are
+// gone.
+func (s *NodeNamingService) renamedNodes(ctx context.Context, wonderNet *repository.WonderNet, nodes []*meshbackend.Node) (map[string]string, error) {
+	records, err := s.nodeNameRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list node names: %w", err)
+	}
+
+	renamed := make(map[string]string, len(records))
+	for _, record := range records {
+		if !slices.ContainsFunc(nodes, func(node *meshbackend.Node) bool { return node.ID == record.NodeID }) {
+			if err := s.nodeNameRepository.Delete(ctx, record.NodeID); err != nil {
+				slog.Warn("delete name of removed node", "node_id", record.NodeID, "error", err)
+			}
+			continue
+		}
+		renamed[record.NodeID] = record.Name
+	}
+	return renamed, nil
+}
+
+// Rename sets the name of a node of the wonder net in the mesh, keeping it
+// from then on instead of the name the naming policy gives it. An empty
+// name returns the node to the naming policy. Returns ErrInvalidNodeName
+// for names that are not valid DNS labels, ErrNodeNotFound if the node is
+// not in the wonder net, and ErrNodeNameTaken if another node of the wonder
+// net has the name.
+func (s *NodeNamingService) Rename(ctx context.Context, wonderNet *repository.WonderNet, nodeID, name, renamedBy string) error {
+	if name != "" {
+		if err := ValidateNodeName(name); err != nil {
+			return err
+		}
+	}
+
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(nodes, func(node *meshbackend.Node) bool { return node.ID == nodeID }) {
+		return ErrNodeNotFound
+	}
+
+	if name == "" {
+		if err := s.nodeNameRepository.Delete(ctx, nodeID); err != nil {
+			return fmt.Errorf("delete node name: %w", err)
+		}
+		slog.Info("reset node name", "wonder_net_id", wonderNet.ID, "node_id", nodeID, "by", renamedBy)
+		return s.Apply(ctx, wonderNet)
+	}
+
+	renamed, err := s.renamedNodes(ctx, wonderNet, nodes)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if node.ID != nodeID && (node.Name == name || renamed[node.ID] == name) {
+			return fmt.Errorf("%w: %s", ErrNodeNameTaken, name)
+		}
+	}
+
+	if err := s.nodeNameRepository.Set(ctx, nodeID, wonderNet.ID, name, renamedBy); err != nil {
+		return fmt.Errorf("set node name: %w", err)
+	}
+	if err := s.meshBackend.RenameNode(ctx, nodeID, name); err != nil {
+		return fmt.Errorf("rename node: %w", err)
+	}
+	slog.Info("renamed node", "wonder_net_id", wonderNet.ID, "node_id", nodeID, "to", name, "by", renamedBy)
+	return nil
+}
+
+// Reconcile applies the node names of every wonder net with a node name
+// template.
+func (s *NodeNamingService) Reconcile(ctx context.Context) error {
+	wonderNets, err := s.wonderNetRepository.ListWithNodeNameTemplate(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, wonderNet := range wonderNets {
+		if err := s.Apply(ctx, wonderNet); err != nil {
+			slog.Warn("apply node names", "wonder_net_id", wonderNet.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Run reconciles node names every interval until ctx is cancelled.
+func (s *NodeNamingService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				slog.Error("reconcile node names", "error", err)
+			}
+		}
+	}
+}
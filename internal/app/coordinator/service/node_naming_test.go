@@ -0,0 +1,170 @@
+package service
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+func TestValidateNodeNameTemplate(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		wantErr  bool
+	}{
+		{"empty names nodes after their hostname", "", false},
+		{"prefix", "acme-{hostname}", false},
+		{"suffix", "{hostname}-prod", false},
+		{"uppercase prefix", "ACME-{hostname}", false},
+		{"hostname only", "{hostname}", false},
+		{"missing placeholder", "acme-node", true},
+		{"unknown placeholder", "{tenant}-{hostname}", true},
+		{"dot in prefix", "acme.{hostname}", true},
+		{"prefix too long", strings.Repeat("a", 33) + "{hostname}", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNodeNameTemplate(tt.template)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateNodeNameTemplate(%q) error = %v, wantErr %v", tt.template, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidNodeNameTemplate) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidNodeNameTemplate)
+			}
+		})
+	}
+}
+
+func TestRenderNodeName(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		hostname string
+		want     string
+	}{
+		{"prefix", "acme-{hostname}", "web-1", "acme-web-1"},
+		{"lowercases", "ACME-{hostname}", "Web-1", "acme-web-1"},
+		{"replaces invalid characters", "acme-{hostname}", "web_1.local", "acme-web-1-local"},
+		{"trims hyphens", "{hostname}", "-web-", "web"},
+		{"truncates to 63 characters", "acme-{hostname}", strings.Repeat("x", 70), "acme-" + strings.Repeat("x", 58)},
+		{"no trailing hyphen after truncation", "acme-{hostname}", strings.Repeat("x", 57) + "-yyyy", "acme-" + strings.Repeat("x", 57)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RenderNodeName(tt.template, tt.hostname); got != tt.want {
+				t.Errorf("RenderNodeName(%q, %q) = %q, want %q", tt.template, tt.hostname, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestValidateNodeName(t *testing.T) {
+	tests := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{"simple", "web-1", false},
+		{"digits only", "42", false},
+		{"max length", strings.Repeat("a", 63), false},
+		{"empty", "", true},
+		{"too long", strings.Repeat("a", 64), true},
+		{"uppercase", "Web-1", true},
+		{"dot", "web.local", true},
+		{"leading hyphen", "-web", true},
+		{"trailing hyphen", "web-", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateNodeName(tt.input)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ValidateNodeName(%q) error = %v, wantErr %v", tt.input, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidNodeName) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidNodeName)
+			}
+		})
+	}
+}
+
+func TestUniqueNodeName(t *testing.T) {
+	if got := uniqueNodeName("web", 1); got != "web" {
+		t.Errorf("uniqueNodeName(web, 1) = %q, want %q", got, "web")
+	}
+	if got := uniqueNodeName("web", 12); got != "web-12" {
+		t.Errorf("uniqueNodeName(web, 12) = %q, want %q", got, "web-12")
+	}
+	long := strings.Repeat("x", 60) + "-yy"
+	if got, want := uniqueNodeName(long, 2), strings.Repeat("x", 60)+"-2"; got != want {
+		t.Errorf("uniqueNodeName(long, 2) = %q, want %q", got, want)
+	}
+}
+
+func TestAssignNodeNames(t *testing.T) {
+	tests := []struct {
+		name     string
+		template string
+		nodes    []*meshbackend.Node
+		renamed  map[string]string
+		want     map[string]string
+	}{
+		{
+			name: "colliding hostnames get suffixes in ID order",
+			nodes: []*meshbackend.Node{
+				{ID: "10", Name: "raspberrypi-x7k2m9qa", Hostname: "raspberrypi"},
+				{ID: "2", Name: "raspberrypi", Hostname: "raspberrypi"},
+				{ID: "9", Name: "raspberrypi-a1b2c3d4", Hostname: "raspberrypi"},
+			},
+			want: map[string]string{"2": "raspberrypi", "9": "raspberrypi-2", "10": "raspberrypi-3"},
+		},
+		{
+			name:     "template",
+			template: "acme-{hostname}",
+			nodes: []*meshbackend.Node{
+				{ID: "1", Name: "web", Hostname: "web"},
+				{ID: "2", Name: "Web", Hostname: "Web"},
+			},
+			want: map[string]string{"1": "acme-web", "2": "acme-web-2"},
+		},
+		{
+			name: "existing suffix is kept after an older node left",
+			nodes: []*meshbackend.Node{
+				{ID: "2", Name: "web-2", Hostname: "web"},
+				{ID: "3", Name: "web-3", Hostname: "web"},
+			},
+			want: map[string]string{"2": "web-2", "3": "web-3"},
+		},
+		{
+			name: "renamed nodes keep their names and win collisions",
+			nodes: []*meshbackend.Node{
+				{ID: "1", Name: "db", Hostname: "db"},
+				{ID: "2", Name: "db-primary", Hostname: "db"},
+			},
+			renamed: map[string]string{"2": "db"},
+			want:    map[string]string{"1": "db-2", "2": "db"},
+		},
+		{
+			name: "nodes without hostname keep their names",
+			nodes: []*meshbackend.Node{
+				{ID: "1", Name: "gateway"},
+				{ID: "2", Name: "gateway-old", Hostname: "gateway"},
+			},
+			want: map[string]string{"1": "gateway", "2": "gateway-2"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := assignNodeNames(tt.nodes, tt.template, tt.renamed)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("assignNodeNames() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
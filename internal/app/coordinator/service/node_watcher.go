@@ -0,0 +1,233 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// NodeEventHandler receives the node events found by a NodeWatcher.
+type NodeEventHandler interface {
+	// WatchedWonderNets returns the IDs of the wonder nets the handler
+	// wants node events of.
+	WatchedWonderNets(ctx context.Context) (map[string]bool, error)
+	// HandleNodeEvents handles the events of a wonder net found by one check.
+	HandleNodeEvents(ctx context.Context, wonderNet *repository.WonderNet, events []NodeEvent)
+}
+
+// NodeWatcher is the single source of the node events sent to webhooks and
+// notification channels. The mesh does not push node changes, so it lists
+// the nodes of every wonder net one of its handlers watches and compares
+// them with the previous listing:
+//
+//   - join and leave when a node appears or disappears,
+//   - online when an offline node comes back,
+//   - offline once a node has been offline for offlineAfter,
+//   - authorized when an owner approves a node held for node approval,
+//     which shows as the node losing its wonder net's pending tag.
+//
+// The first listing of a wonder net only sets the baseline, so restarts do
+// not replay existing nodes as joins.
+//
+// Node watch streams subscribe to the listings of their wonder net rather
+// than polling the mesh themselves, so a wonder net is listed once per
+// check however many streams watch it.
+type NodeWatcher struct {
+	wonderNetRepository *repository.WonderNetRepository
+	nodesService        *NodesService
+	offlineAfter        time.Duration
+	handlers            []NodeEventHandler
+
+	mu sync.Mutex
+	// nodes is the last node listing of each watched wonder net.
+	nodes map[string][]*Node
+	// offline holds the nodes of each watched wonder net that were offline
+	// for offlineAfter at the last check.
+	offline map[string]map[uint64]bool
+	// subscribers holds the channels of the node watch streams of each
+	// wonder net.
+	subscribers map[string]map[chan []*Node]struct{}
+}
+
+// NewNodeWatcher creates a new NodeWatcher sending events to handlers. A
+// zero offlineAfter sends offline events as soon as a node goes offline.
+func NewNodeWatcher(
+	wonderNetRepository *repository.WonderNetRepository,
+	nodesService *NodesService,
+	offlineAfter time.Duration,
+	handlers ...NodeEventHandler,
+) *NodeWatcher {
+	return &NodeWatcher{
+		wonderNetRepository: wonderNetRepository,
+		nodesService:        nodesService,
+		offlineAfter:        offlineAfter,
+		handlers:            handlers,
+		nodes:               make(map[string][]*Node),
+		offline:             make(map[string]map[uint64]bool),
+		subscribers:         make(map[string]map[chan []*Node]struct{}),
+	}
+}
+
+// Subscribe returns a channel receiving the node listing of a wonder net
+// after every check, and a function ending the subscription. A subscriber
+// that falls behind only gets the latest listing.
+func (w *NodeWatcher) Subscribe(wonderNetID string) (<-chan []*Node, func()) {
+	ch := make(chan []*Node, 1)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if w.subscribers[wonderNetID] == nil {
+		w.subscribers[wonderNetID] = make(map[chan []*Node]struct{})
+	}
+	w.subscribers[wonderNetID][ch] = struct{}{}
+
+	return ch, func() {
+		w.mu.Lock()
+		defer w.mu.Unlock()
+		delete(w.subscribers[wonderNetID], ch)
+		if len(w.subscribers[wonderNetID]) == 0 {
+			delete(w.subscribers, wonderNetID)
+		}
+	}
+}
+
+// publish sends a node listing of a wonder net to its subscribers,
+// replacing any listing they have not received yet. w.mu must be held.
+func (w *NodeWatcher) publish(wonderNetID string, nodes []*Node) {
+	for ch := range w.subscribers[wonderNetID] {
+		select {
+		case <-ch:
+		default:
+		}
+		ch <- nodes
+	}
+}
+
+// Run checks for node events every interval until ctx is cancelled.
+func (w *NodeWatcher) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := w.Check(ctx, time.Now()); err != nil {
+				slog.Error("watch nodes", "error", err)
+			}
+		}
+	}
+}
+
+// Check lists the nodes of every watched wonder net, hands the events
+// since the previous check to the handlers watching it, and sends the
+// listing to its subscribers.
+func (w *NodeWatcher) Check(ctx context.Context, now time.Time) error {
+	watchers := make(map[string][]NodeEventHandler)
+	for _, handler := range w.handlers {
+		wonderNetIDs, err := handler.WatchedWonderNets(ctx)
+		if err != nil {
+			return err
+		}
+		for wonderNetID := range wonderNetIDs {
+			watchers[wonderNetID] = append(watchers[wonderNetID], handler)
+		}
+	}
+
+	w.mu.Lock()
+	for wonderNetID := range w.subscribers {
+		if _, ok := watchers[wonderNetID]; !ok {
+			watchers[wonderNetID] = nil
+		}
+	}
+	for wonderNetID := range w.nodes {
+		if _, ok := watchers[wonderNetID]; !ok {
+			delete(w.nodes, wonderNetID)
+			delete(w.offline, wonderNetID)
+		}
+	}
+	w.mu.Unlock()
+
+	for wonderNetID, handlers := range watchers {
+		wonderNet, err := w.wonderNetRepository.Get(ctx, wonderNetID)
+		if err != nil {
+			slog.Warn("get wonder net for node events", "wonder_net_id", wonderNetID, "error", err)
+			continue
+		}
+		if wonderNet == nil {
+			continue
+		}
+
+		nodes, err := w.nodesService.ListNodes(ctx, wonderNet)
+		if err != nil {
+			slog.Warn("list nodes for node events", "wonder_net_id", wonderNetID, "error", err)
+			continue
+		}
+
+		offline := make(map[uint64]bool)
+		for _, node := range nodesOfflineFor(nodes, w.offlineAfter, now) {
+			offline[node.ID] = true
+		}
+
+		w.mu.Lock()
+		prev, seen := w.nodes[wonderNetID]
+		prevOffline := w.offline[wonderNetID]
+		w.nodes[wonderNetID] = nodes
+		w.offline[wonderNetID] = offline
+		w.publish(wonderNetID, nodes)
+		w.mu.Unlock()
+		if !seen || len(handlers) == 0 {
+			continue
+		}
+
+		events := watchNodeEvents(prev, nodes, prevOffline, offline, pendingNodeTag(wonderNet))
+		if len(events) == 0 {
+			continue
+		}
+		for _, handler := range handlers {
+			handler.HandleNodeEvents(ctx, wonderNet, events)
+		}
+	}
+	return nil
+}
+
+// watchNodeEvents returns the events that turn the prev node listing into
+// curr, ordered by node ID. Offline events come from the offline sets
+// rather than the online flag, and a node that lost pendingTag is
+// authorized.
+func watchNodeEvents(prev, curr []*Node, prevOffline, offline map[uint64]bool, pendingTag string) []NodeEvent {
+	events := slices.DeleteFunc(DiffNodes(prev, curr), func(event NodeEvent) bool {
+		return event.Type == NodeEventOffline
+	})
+
+	before := make(map[uint64]*Node, len(prev))
+	for _, node := range prev {
+		before[node.ID] = node
+	}
+	for _, node := range curr {
+		old, ok := before[node.ID]
+		if !ok {
+			continue
+		}
+		if offline[node.ID] && !prevOffline[node.ID] {
+			events = append(events, NodeEvent{Type: NodeEventOffline, Node: node})
+		}
+		if slices.Contains(old.Tags, pendingTag) && !slices.Contains(node.Tags, pendingTag) {
+			events = append(events, NodeEvent{Type: NodeEventAuthorized, Node: node})
+		}
+	}
+
+	if len(events) == 0 {
+		return nil
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Node.ID < events[j].Node.ID
+	})
+	return events
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestWatchNodeEvents(t *testing.T) {
+	const pending = "tag:wonder-net-pending"
+	a := &Node{ID: 1, Name: "a", Online: true}
+	aOffline := &Node{ID: 1, Name: "a", Online: false}
+	b := &Node{ID: 2, Name: "b", Online: true, Tags: []string{pending}}
+	bApproved := &Node{ID: 2, Name: "b", Online: true}
+	c := &Node{ID: 3, Name: "c", Online: true}
+
+	tests := []struct {
+		name        string
+		prev, curr  []*Node
+		prevOffline map[uint64]bool
+		offline     map[uint64]bool
+		want        []NodeEvent
+	}{
+		{"no change", []*Node{a, b}, []*Node{a, b}, nil, nil, nil},
+		{"join", []*Node{a}, []*Node{a, c}, nil, nil, []NodeEvent{{NodeEventJoin, c}}},
+		{"offline within threshold", []*Node{a}, []*Node{aOffline}, nil, nil, nil},
+		{"offline past threshold", []*Node{aOffline}, []*Node{aOffline}, nil, map[uint64]bool{1: true}, []NodeEvent{{NodeEventOffline, aOffline}}},
+		{"still offline", []*Node{aOffline}, []*Node{aOffline}, map[uint64]bool{1: true}, map[uint64]bool{1: true}, nil},
+		{"online again", []*Node{aOffline}, []*Node{a}, map[uint64]bool{1: true}, nil, []NodeEvent{{NodeEventOnline, a}}},
+		{"authorized", []*Node{a, b}, []*Node{a, bApproved}, nil, nil, []NodeEvent{{NodeEventAuthorized, bApproved}}},
+		{"joined already approved", []*Node{a}, []*Node{a, bApproved}, nil, nil, []NodeEvent{{NodeEventJoin, bApproved}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := watchNodeEvents(tt.prev, tt.curr, tt.prevOffline, tt.offline, pending)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("watchNodeEvents() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeWatcherSubscribe(t *testing.T) {
+	w := NewNodeWatcher(nil, nil, 0)
+	first, unsubscribeFirst := w.Subscribe("wn-1")
+	second, unsubscribeSecond := w.Subscribe("wn-1")
+	defer unsubscribeSecond()
+
+	stale := []*Node{{ID: 1}}
+	latest := []*Node{{ID: 1}, {ID: 2}}
+	w.mu.Lock()
+	w.publish("wn-1", stale)
+	w.publish("wn-1", latest)
+	w.publish("wn-2", stale)
+	w.mu.Unlock()
+
+	for _, ch := range []<-chan []*Node{first, second} {
+		if got := <-ch; !reflect.DeepEqual(got, latest) {
+			t.Errorf("listing = %v, want the latest %v", got, latest)
+		}
+	}
+
+	unsubscribeFirst()
+	w.mu.Lock()
+	w.publish("wn-1", stale)
+	w.mu.Unlock()
+	select {
+	case got := <-first:
+		t.Errorf("unsubscribed stream got %v", got)
+	default:
+	}
+	if got := <-second; !reflect.DeepEqual(got, stale) {
+		t.Errorf("listing = %v, want %v", got, stale)
+	}
+}
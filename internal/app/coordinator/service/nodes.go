@@ -1,51 +1,350 @@
 package service
 
 import (
+	"cmp"
 	"context"
+	"encoding/base64"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
 )
 
-// Node represents a mesh network node.
+// ErrNodeNotFound is returned when a node does not exist in a wonder net.
+var ErrNodeNotFound = errors.New("node not found")
+
+// NodeHeartbeatTimeout is how long a node counts as healthy after its
+// worker's last heartbeat. Workers report every minute by default, so a
+// node is unhealthy after missing a few heartbeats in a row.
+const NodeHeartbeatTimeout = 3 * time.Minute
+
+// Node represents a mesh network node. PrimaryRoutes are the approved routes
+// the mesh currently sends through this node; when several nodes serve the
+// same route, only one of them is primary for it. ExitNode is set when the
+// node advertises an approved exit route.
+//
+// Hostname, Tags, and CreatedAt, when the node registered with the mesh,
+// come from the mesh's node record. Headscale's API does not expose the
+// client's host info, so OS and TailscaleVersion are those the node's
+// worker last reported, empty without a heartbeat.
 type Node struct {
-	ID       uint64
-	Name     string
-	IPAddrs  []string
-	Online   bool
-	LastSeen *time.Time
+	ID               uint64
+	Name             string
+	Hostname         string
+	IPAddrs          []string
+	Online           bool
+	LastSeen         *time.Time
+	CreatedAt        *time.Time
+	Tags             []string
+	OS               string
+	TailscaleVersion string
+	AdvertisedRoutes []string
+	ApprovedRoutes   []string
+	PrimaryRoutes    []string
+	ExitNode         bool
+	// Health is the latest heartbeat of the node's worker, nil if it never
+	// sent one.
+	Health *NodeHealth
+}
+
+// NodeHealth is the health a worker last reported for its node. Healthy is
+// set while the report is not older than NodeHeartbeatTimeout.
+type NodeHealth struct {
+	Healthy              bool
+	OS                   string
+	TailscaleVersion     string
+	DiskTotalBytes       int64
+	DiskFreeBytes        int64
+	MemoryTotalBytes     int64
+	MemoryAvailableBytes int64
+	ReportedAt           time.Time
+}
+
+// NodeFilter narrows a node listing. The zero value matches every node.
+type NodeFilter struct {
+	// Online restricts results to online (true) or offline (false) nodes. Nil matches both.
+	Online *bool
+	// LastSeenWithin keeps only nodes seen within this duration. Online nodes
+	// always count as seen. Zero disables the check.
+	LastSeenWithin time.Duration
+	// Healthy restricts results to nodes with (true) or without (false) a
+	// recent worker heartbeat. Nil matches both.
+	Healthy *bool
+	// OS keeps only nodes whose worker reported this operating system,
+	// ignoring case. Empty matches every node.
+	OS string
+	// Tag keeps only nodes with this tag, with or without its "tag:"
+	// prefix. Empty matches every node.
+	Tag string
+	// Search keeps only nodes whose name or hostname contains it, ignoring
+	// case. Empty matches every node.
+	Search string
+}
+
+// Match reports whether the node passes the filter at the given time.
+func (f NodeFilter) Match(node *Node, now time.Time) bool {
+	if f.Online != nil && node.Online != *f.Online {
+		return false
+	}
+	if f.LastSeenWithin > 0 && !node.Online {
+		if node.LastSeen == nil || now.Sub(*node.LastSeen) > f.LastSeenWithin {
+			return false
+		}
+	}
+	if f.Healthy != nil && node.Healthy() != *f.Healthy {
+		return false
+	}
+	if f.OS != "" && !strings.EqualFold(node.OS, f.OS) {
+		return false
+	}
+	if f.Tag != "" {
+		tag := f.Tag
+		if !strings.HasPrefix(tag, "tag:") {
+			tag = "tag:" + tag
+		}
+		if !slices.Contains(node.Tags, tag) {
+			return false
+		}
+	}
+	if f.Search != "" {
+		search := strings.ToLower(f.Search)
+		if !strings.Contains(strings.ToLower(node.Name), search) && !strings.Contains(strings.ToLower(node.Hostname), search) {
+			return false
+		}
+	}
+	return true
+}
+
+// Healthy reports whether the node's worker sent a heartbeat recently.
+func (n *Node) Healthy() bool {
+	return n.Health != nil && n.Health.Healthy
+}
+
+// FilterNodes returns the nodes that match the filter.
+func FilterNodes(nodes []*Node, filter NodeFilter) []*Node {
+	now := time.Now()
+	result := make([]*Node, 0, len(nodes))
+	for _, node := range nodes {
+		if filter.Match(node, now) {
+			result = append(result, node)
+		}
+	}
+	return result
+}
+
+// NodeSort is the order of a node listing.
+type NodeSort string
+
+// Node listing orders. Ties are broken by node ID.
+const (
+	NodeSortID       NodeSort = "id"
+	NodeSortName     NodeSort = "name"
+	NodeSortLastSeen NodeSort = "last_seen"
+)
+
+// ParseNodeSort parses a sort query value: id, name, or last_seen, with a
+// leading "-" for descending order. Empty sorts by ascending ID.
+func ParseNodeSort(v string) (NodeSort, bool, error) {
+	desc := strings.HasPrefix(v, "-")
+	switch sort := NodeSort(strings.TrimPrefix(v, "-")); sort {
+	case "":
+		if desc {
+			break
+		}
+		return NodeSortID, false, nil
+	case NodeSortID, NodeSortName, NodeSortLastSeen:
+		return sort, desc, nil
+	}
+	return "", false, fmt.Errorf("invalid sort %q, want id, name, or last_seen, optionally prefixed with -", v)
+}
+
+// NodePageOptions selects one page of a node listing.
+type NodePageOptions struct {
+	Sort       NodeSort
+	Descending bool
+	// Limit is the page size; zero returns all remaining nodes.
+	Limit int
+	// Cursor is the NextCursor of the previous page, or empty for the first
+	// page. It must be used with the same ordering it was issued for.
+	Cursor string
+}
+
+// NodePage is one page of a node listing. NextCursor is empty on the last
+// page.
+type NodePage struct {
+	Nodes      []*Node
+	NextCursor string
+}
+
+// nodeSortKey is the position of a node in a listing order.
+type nodeSortKey struct {
+	name     string
+	lastSeen int64
+	id       uint64
+}
+
+func sortKeyOf(n *Node) nodeSortKey {
+	key := nodeSortKey{name: n.Name, id: n.ID}
+	if n.LastSeen != nil {
+		key.lastSeen = n.LastSeen.UnixNano()
+	}
+	return key
+}
+
+// compareNodeKeys orders a and b by sort, then by ID, in ascending order.
+func compareNodeKeys(sort NodeSort, a, b nodeSortKey) int {
+	var c int
+	switch sort {
+	case NodeSortName:
+		c = strings.Compare(a.name, b.name)
+	case NodeSortLastSeen:
+		c = cmp.Compare(a.lastSeen, b.lastSeen)
+	}
+	if c != 0 {
+		return c
+	}
+	return cmp.Compare(a.id, b.id)
+}
+
+// PageNodes sorts nodes as opts asks and returns up to opts.Limit of those
+// after opts.Cursor. Nodes never seen sort before all others by last_seen.
+// It returns ErrInvalidCursor for a cursor issued for another ordering.
+func PageNodes(nodes []*Node, opts NodePageOptions) (*NodePage, error) {
+	sort := opts.Sort
+	if sort == "" {
+		sort = NodeSortID
+	}
+	compare := func(a, b nodeSortKey) int {
+		if opts.Descending {
+			return compareNodeKeys(sort, b, a)
+		}
+		return compareNodeKeys(sort, a, b)
+	}
+
+	sorted := slices.Clone(nodes)
+	slices.SortFunc(sorted, func(a, b *Node) int {
+		return compare(sortKeyOf(a), sortKeyOf(b))
+	})
+	if opts.Cursor != "" {
+		after, err := decodeNodeCursor(opts.Cursor, sort, opts.Descending)
+		if err != nil {
+			return nil, err
+		}
+		start, _ := slices.BinarySearchFunc(sorted, after, func(n *Node, key nodeSortKey) int {
+			if compare(sortKeyOf(n), key) <= 0 {
+				return -1
+			}
+			return 1
+		})
+		sorted = sorted[start:]
+	}
+
+	page := &NodePage{Nodes: sorted}
+	if opts.Limit > 0 && len(sorted) > opts.Limit {
+		page.Nodes = sorted[:opts.Limit]
+		page.NextCursor = encodeNodeCursor(sortKeyOf(page.Nodes[opts.Limit-1]), sort, opts.Descending)
+	}
+	return page, nil
+}
+
+// encodeNodeCursor encodes the ordering and the key of the last node of a
+// page. Only the key of the sort is kept, with the node ID.
+func encodeNodeCursor(key nodeSortKey, sort NodeSort, desc bool) string {
+	order := string(sort)
+	if desc {
+		order = "-" + order
+	}
+	raw := order + ":" + strconv.FormatUint(key.id, 10)
+	switch sort {
+	case NodeSortName:
+		raw += ":" + key.name
+	case NodeSortLastSeen:
+		raw += ":" + strconv.FormatInt(key.lastSeen, 10)
+	}
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeNodeCursor(cursor string, sort NodeSort, desc bool) (nodeSortKey, error) {
+	var key nodeSortKey
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return key, ErrInvalidCursor
+	}
+	order := string(sort)
+	if desc {
+		order = "-" + order
+	}
+	rest, ok := strings.CutPrefix(string(raw), order+":")
+	if !ok {
+		return key, ErrInvalidCursor
+	}
+	id, value, _ := strings.Cut(rest, ":")
+	if key.id, err = strconv.ParseUint(id, 10, 64); err != nil {
+		return key, ErrInvalidCursor
+	}
+	switch sort {
+	case NodeSortName:
+		key.name = value
+	case NodeSortLastSeen:
+		if key.lastSeen, err = strconv.ParseInt(value, 10, 64); err != nil {
+			return key, ErrInvalidCursor
+		}
+	}
+	return key, nil
 }
 
 // NodesService handles node listing operations.
 type NodesService struct {
-	meshBackend meshbackend.MeshBackend
+	meshBackend             meshbackend.MeshBackend
+	nodeHeartbeatRepository *repository.NodeHeartbeatRepository
+	workerTokenRepository   *repository.WorkerTokenRepository
 }
 
 // NewNodesService creates a new NodesService.
-func NewNodesService(meshBackend meshbackend.MeshBackend) *NodesService {
+func NewNodesService(meshBackend meshbackend.MeshBackend, nodeHeartbeatRepository *repository.NodeHeartbeatRepository, workerTokenRepository *repository.WorkerTokenRepository) *NodesService {
 	return &NodesService{
-		meshBackend: meshBackend,
+		meshBackend:             meshBackend,
+		nodeHeartbeatRepository: nodeHeartbeatRepository,
+		workerTokenRepository:   workerTokenRepository,
 	}
 }
 
-// ListNodes returns all nodes in the given wonder net.
+// ListNodes returns all nodes in the given wonder net, with the latest
+// heartbeat of each node's worker.
 func (s *NodesService) ListNodes(ctx context.Context, wonderNet *repository.WonderNet) ([]*Node, error) {
 	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
 	if err != nil {
 		return nil, err
 	}
 
+	heartbeats, err := s.nodeHeartbeatRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list node heartbeats: %w", err)
+	}
+	heartbeatByNode := make(map[string]*repository.NodeHeartbeat, len(heartbeats))
+	for _, hb := range heartbeats {
+		heartbeatByNode[hb.NodeID] = hb
+	}
+	now := time.Now()
+
 	result := make([]*Node, len(nodes))
 	for i, node := range nodes {
 		n := &Node{
-			Name:    node.Name,
-			IPAddrs: node.Addresses,
-			Online:  node.Online,
+			Name:      node.Name,
+			Hostname:  node.Hostname,
+			IPAddrs:   node.Addresses,
+			Online:    node.Online,
+			LastSeen:  node.LastSeen,
+			CreatedAt: node.CreatedAt,
+			Tags:      node.Tags,
 		}
+		setNodeRoutes(n, node)
 
 		// Parse ID from string to uint64
 		if id, err := strconv.ParseUint(node.ID, 10, 64); err == nil {
@@ -54,9 +353,7 @@ func (s *NodesService) ListNodes(ctx context.Context, wonderNet *repository.Wond
 			slog.Warn("parse node ID", "node_name", node.Name, "raw_id", node.ID, "error", err)
 		}
 
-		if node.LastSeen != nil {
-			n.LastSeen = node.LastSeen
-		}
+		setNodeHeartbeat(n, heartbeatByNode[node.ID], now)
 		result[i] = n
 	}
 
@@ -73,35 +370,94 @@ func (s *NodesService) GetNode(ctx context.Context, headscaleUser string, nodeID
 	}
 
 	if node.Realm != headscaleUser {
-		return nil, fmt.Errorf("node does not belong to this wonder net")
+		return nil, ErrNodeNotFound
 	}
 
 	n := &Node{
-		Name:     node.Name,
-		IPAddrs:  node.Addresses,
-		Online:   node.Online,
-		LastSeen: node.LastSeen,
+		Name:      node.Name,
+		Hostname:  node.Hostname,
+		IPAddrs:   node.Addresses,
+		Online:    node.Online,
+		LastSeen:  node.LastSeen,
+		CreatedAt: node.CreatedAt,
+		Tags:      node.Tags,
 	}
+	setNodeRoutes(n, node)
 
 	if id, err := strconv.ParseUint(node.ID, 10, 64); err == nil {
 		n.ID = id
 	}
 
+	hb, err := s.nodeHeartbeatRepository.Get(ctx, node.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get node heartbeat: %w", err)
+	}
+	setNodeHeartbeat(n, hb, time.Now())
+
 	return n, nil
 }
 
 // DeleteNode deletes a node from a wonder net.
-// It verifies that the node belongs to the specified wonder net before deletion.
-// headscaleUser is the Headscale user/namespace from the wonder net record.
-func (s *NodesService) DeleteNode(ctx context.Context, headscaleUser string, nodeID string) error {
+// It verifies that the node belongs to the wonder net before deletion, and
+// revokes the worker tokens of the node so its worker cannot join again.
+func (s *NodesService) DeleteNode(ctx context.Context, wonderNet *repository.WonderNet, nodeID string) error {
 	node, err := s.meshBackend.GetNode(ctx, nodeID)
 	if err != nil {
 		return fmt.Errorf("get node: %w", err)
 	}
 
-	if node.Realm != headscaleUser {
+	if node.Realm != wonderNet.HeadscaleUser {
 		return fmt.Errorf("node does not belong to this wonder net")
 	}
 
-	return s.meshBackend.DeleteNode(ctx, nodeID)
+	if err := s.workerTokenRepository.RevokeByNode(ctx, wonderNet.ID, nodeID, node.AuthKeyID); err != nil {
+		return fmt.Errorf("revoke worker tokens: %w", err)
+	}
+	if err := s.meshBackend.DeleteNode(ctx, nodeID); err != nil {
+		return err
+	}
+	if err := s.nodeHeartbeatRepository.Delete(ctx, nodeID); err != nil {
+		slog.Warn("delete node heartbeat", "node_id", nodeID, "error", err)
+	}
+	return nil
+}
+
+// setNodeHeartbeat copies the health and inventory the node's worker last
+// reported onto n.
+func setNodeHeartbeat(n *Node, hb *repository.NodeHeartbeat, now time.Time) {
+	n.Health = nodeHealth(hb, now)
+	if hb != nil {
+		n.OS = hb.OS
+		n.TailscaleVersion = hb.TailscaleVersion
+	}
+}
+
+// nodeHealth converts a stored heartbeat into the node's health at now.
+func nodeHealth(hb *repository.NodeHeartbeat, now time.Time) *NodeHealth {
+	if hb == nil {
+		return nil
+	}
+	return &NodeHealth{
+		Healthy:              now.Sub(hb.ReportedAt) <= NodeHeartbeatTimeout,
+		OS:                   hb.OS,
+		TailscaleVersion:     hb.TailscaleVersion,
+		DiskTotalBytes:       hb.DiskTotalBytes,
+		DiskFreeBytes:        hb.DiskFreeBytes,
+		MemoryTotalBytes:     hb.MemoryTotalBytes,
+		MemoryAvailableBytes: hb.MemoryAvailableBytes,
+		ReportedAt:           hb.ReportedAt,
+	}
+}
+
+// setNodeRoutes copies the routing state of a mesh node onto n.
+func setNodeRoutes(n *Node, node *meshbackend.Node) {
+	n.AdvertisedRoutes = node.AdvertisedRoutes
+	n.ApprovedRoutes = node.ApprovedRoutes
+	n.PrimaryRoutes = node.ServingRoutes
+	for _, route := range node.ApprovedRoutes {
+		if isExitRoute(route) && slices.Contains(node.AdvertisedRoutes, route) {
+			n.ExitNode = true
+			break
+		}
+	}
 }
@@ -0,0 +1,236 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+func TestNodeFilter_Match(t *testing.T) {
+	now := time.Now()
+	recent := now.Add(-time.Hour)
+	stale := now.Add(-48 * time.Hour)
+	yes, no := true, false
+
+	tests := []struct {
+		name   string
+		filter NodeFilter
+		node   *Node
+		want   bool
+	}{
+		{"zero filter, offline node", NodeFilter{}, &Node{Online: false}, true},
+		{"online only, online node", NodeFilter{Online: &yes}, &Node{Online: true}, true},
+		{"online only, offline node", NodeFilter{Online: &yes}, &Node{Online: false}, false},
+		{"offline only, offline node", NodeFilter{Online: &no}, &Node{Online: false}, true},
+		{"seen within, recent offline", NodeFilter{LastSeenWithin: 24 * time.Hour}, &Node{LastSeen: &recent}, true},
+		{"seen within, stale offline", NodeFilter{LastSeenWithin: 24 * time.Hour}, &Node{LastSeen: &stale}, false},
+		{"seen within, never seen", NodeFilter{LastSeenWithin: 24 * time.Hour}, &Node{}, false},
+		{"seen within, online with stale last seen", NodeFilter{LastSeenWithin: 24 * time.Hour}, &Node{Online: true, LastSeen: &stale}, true},
+		{"healthy only, healthy node", NodeFilter{Healthy: &yes}, &Node{Health: &NodeHealth{Healthy: true}}, true},
+		{"healthy only, stale heartbeat", NodeFilter{Healthy: &yes}, &Node{Health: &NodeHealth{Healthy: false}}, false},
+		{"healthy only, no heartbeat", NodeFilter{Healthy: &yes}, &Node{Online: true}, false},
+		{"unhealthy only, no heartbeat", NodeFilter{Healthy: &no}, &Node{}, true},
+		{"os, matching node", NodeFilter{OS: "linux"}, &Node{OS: "Linux"}, true},
+		{"os, other node", NodeFilter{OS: "linux"}, &Node{OS: "windows"}, false},
+		{"os, no heartbeat", NodeFilter{OS: "linux"}, &Node{}, false},
+		{"tag, tagged node", NodeFilter{Tag: "worker"}, &Node{Tags: []string{"tag:worker"}}, true},
+		{"tag with prefix, tagged node", NodeFilter{Tag: "tag:worker"}, &Node{Tags: []string{"tag:db", "tag:worker"}}, true},
+		{"tag, other tags", NodeFilter{Tag: "worker"}, &Node{Tags: []string{"tag:db"}}, false},
+		{"tag, untagged node", NodeFilter{Tag: "worker"}, &Node{}, false},
+		{"search, name", NodeFilter{Search: "Web"}, &Node{Name: "web-1", Hostname: "ip-10-0-0-1"}, true},
+		{"search, hostname", NodeFilter{Search: "10-0"}, &Node{Name: "web-1", Hostname: "ip-10-0-0-1"}, true},
+		{"search, no match", NodeFilter{Search: "db"}, &Node{Name: "web-1", Hostname: "ip-10-0-0-1"}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.filter.Match(tt.node, now); got != tt.want {
+				t.Errorf("Match() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeHealth(t *testing.T) {
+	now := time.Now()
+
+	if got := nodeHealth(nil, now); got != nil {
+		t.Errorf("nodeHealth(nil) = %v, want nil", got)
+	}
+
+	tests := []struct {
+		name       string
+		reportedAt time.Time
+		want       bool
+	}{
+		{"just reported", now.Add(-time.Minute), true},
+		{"at timeout", now.Add(-NodeHeartbeatTimeout), true},
+		{"past timeout", now.Add(-NodeHeartbeatTimeout - time.Second), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hb := &repository.NodeHeartbeat{NodeID: "1", OS: "Debian GNU/Linux 12 (bookworm)", ReportedAt: tt.reportedAt}
+			got := nodeHealth(hb, now)
+			if got.Healthy != tt.want {
+				t.Errorf("nodeHealth().Healthy = %v, want %v", got.Healthy, tt.want)
+			}
+			if got.OS != hb.OS {
+				t.Errorf("nodeHealth().OS = %q, want %q", got.OS, hb.OS)
+			}
+		})
+	}
+}
+
+func TestSetNodeRoutes(t *testing.T) {
+	tests := []struct {
+		name         string
+		node         *meshbackend.Node
+		wantExitNode bool
+	}{
+		{
+			name: "approved exit route",
+			node: &meshbackend.Node{
+				AdvertisedRoutes: []string{"0.0.0.0/0", "::/0"},
+				ApprovedRoutes:   []string{"0.0.0.0/0", "::/0"},
+			},
+			wantExitNode: true,
+		},
+		{
+			name: "unapproved exit route",
+			node: &meshbackend.Node{
+				AdvertisedRoutes: []string{"0.0.0.0/0", "::/0"},
+			},
+			wantExitNode: false,
+		},
+		{
+			name: "exit route approved but no longer advertised",
+			node: &meshbackend.Node{
+				AdvertisedRoutes: []string{"10.0.0.0/24"},
+				ApprovedRoutes:   []string{"10.0.0.0/24", "0.0.0.0/0"},
+				ServingRoutes:    []string{"10.0.0.0/24"},
+			},
+			wantExitNode: false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			n := &Node{}
+			setNodeRoutes(n, tt.node)
+			if n.ExitNode != tt.wantExitNode {
+				t.Errorf("setNodeRoutes() ExitNode = %v, want %v", n.ExitNode, tt.wantExitNode)
+			}
+			if !slices.Equal(n.PrimaryRoutes, tt.node.ServingRoutes) {
+				t.Errorf("setNodeRoutes() PrimaryRoutes = %v, want %v", n.PrimaryRoutes, tt.node.ServingRoutes)
+			}
+		})
+	}
+}
+
+func TestPageNodes(t *testing.T) {
+	seen := func(minutes int) *time.Time {
+		t := time.Date(2026, 1, 1, 0, minutes, 0, 0, time.UTC)
+		return &t
+	}
+	nodes := []*Node{
+		{ID: 5, Name: "db", LastSeen: seen(3)},
+		{ID: 1, Name: "web", LastSeen: seen(1)},
+		{ID: 9, Name: "app"},
+		{ID: 3, Name: "cache", LastSeen: seen(3)},
+		{ID: 7, Name: "app", LastSeen: seen(2)},
+	}
+	ids := func(nodes []*Node) []uint64 {
+		result := make([]uint64, len(nodes))
+		for i, n := range nodes {
+			result[i] = n.ID
+		}
+		return result
+	}
+
+	tests := []struct {
+		sort    NodeSort
+		desc    bool
+		wantIDs []uint64
+	}{
+		{sort: NodeSortID, wantIDs: []uint64{1, 3, 5, 7, 9}},
+		{sort: NodeSortID, desc: true, wantIDs: []uint64{9, 7, 5, 3, 1}},
+		{sort: NodeSortName, wantIDs: []uint64{7, 9, 3, 5, 1}},
+		{sort: NodeSortName, desc: true, wantIDs: []uint64{1, 5, 3, 9, 7}},
+		{sort: NodeSortLastSeen, wantIDs: []uint64{9, 1, 7, 3, 5}},
+		{sort: NodeSortLastSeen, desc: true, wantIDs: []uint64{5, 3, 7, 1, 9}},
+	}
+
+	for _, tt := range tests {
+		for _, limit := range []int{0, 1, 2, 5} {
+			opts := NodePageOptions{Sort: tt.sort, Descending: tt.desc, Limit: limit}
+			var got []uint64
+			for pages := 0; ; pages++ {
+				if pages > len(nodes) {
+					t.Fatalf("sort %s desc %v limit %d: cursor does not advance", tt.sort, tt.desc, limit)
+				}
+				page, err := PageNodes(nodes, opts)
+				if err != nil {
+					t.Fatalf("sort %s desc %v limit %d: PageNodes() error = %v", tt.sort, tt.desc, limit, err)
+				}
+				if limit > 0 && len(page.Nodes) > limit {
+					t.Errorf("sort %s limit %d: page of %d nodes", tt.sort, limit, len(page.Nodes))
+				}
+				got = append(got, ids(page.Nodes)...)
+				if page.NextCursor == "" {
+					break
+				}
+				opts.Cursor = page.NextCursor
+			}
+			if !slices.Equal(got, tt.wantIDs) {
+				t.Errorf("sort %s desc %v limit %d = %v, want %v", tt.sort, tt.desc, limit, got, tt.wantIDs)
+			}
+		}
+	}
+
+	if nodes[0].ID != 5 {
+		t.Errorf("PageNodes() reordered its input")
+	}
+}
+
+func TestPageNodes_CursorOfOtherOrder(t *testing.T) {
+	nodes := []*Node{{ID: 1, Name: "a"}, {ID: 2, Name: "b"}}
+	page, err := PageNodes(nodes, NodePageOptions{Sort: NodeSortName, Limit: 1})
+	if err != nil {
+		t.Fatalf("PageNodes() error = %v", err)
+	}
+	for _, opts := range []NodePageOptions{
+		{Sort: NodeSortID, Cursor: page.NextCursor},
+		{Sort: NodeSortName, Descending: true, Cursor: page.NextCursor},
+		{Sort: NodeSortName, Cursor: "not a cursor"},
+	} {
+		if _, err := PageNodes(nodes, opts); !errors.Is(err, ErrInvalidCursor) {
+			t.Errorf("PageNodes(%+v) error = %v, want ErrInvalidCursor", opts, err)
+		}
+	}
+}
+
+func TestParseNodeSort(t *testing.T) {
+	tests := []struct {
+		in       string
+		wantSort NodeSort
+		wantDesc bool
+		wantErr  bool
+	}{
+		{in: "", wantSort: NodeSortID},
+		{in: "name", wantSort: NodeSortName},
+		{in: "-last_seen", wantSort: NodeSortLastSeen, wantDesc: true},
+		{in: "-", wantErr: true},
+		{in: "created_at", wantErr: true},
+	}
+	for _, tt := range tests {
+		sort, desc, err := ParseNodeSort(tt.in)
+		if (err != nil) != tt.wantErr || sort != tt.wantSort || desc != tt.wantDesc {
+			t.Errorf("ParseNodeSort(%q) = %q, %v, %v", tt.in, sort, desc, err)
+		}
+	}
+}
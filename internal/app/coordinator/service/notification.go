@@ -0,0 +1,651 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime"
+	"net"
+	"net/http"
+	"net/mail"
+	"net/smtp"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// Notification event types.
+const (
+	// NotificationEventNodeJoined fires when a new device joins the wonder net.
+	NotificationEventNodeJoined = "node.joined"
+	// NotificationEventNodeAuthorized fires when an owner approves a new
+	// device held for node approval.
+	NotificationEventNodeAuthorized = "node.authorized"
+	// NotificationEventNodeOffline fires once a node has been offline for
+	// the coordinator's node offline threshold.
+	NotificationEventNodeOffline = "node.offline"
+	// NotificationEventAPIKeyExpiring fires once an API key expires within
+	// NotificationAPIKeyExpiryWarning.
+	NotificationEventAPIKeyExpiring = "api_key.expiring"
+	// NotificationEventAlertFiring fires when an alert rule starts firing
+	// for a node.
+	NotificationEventAlertFiring = "alert.firing"
+	// NotificationEventAlertResolved fires when a firing alert resolves.
+	NotificationEventAlertResolved = "alert.resolved"
+)
+
+// NotificationEvents lists the events a notification channel can subscribe to.
+var NotificationEvents = []string{
+	NotificationEventNodeJoined,
+	NotificationEventNodeAuthorized,
+	NotificationEventNodeOffline,
+	NotificationEventAPIKeyExpiring,
+	NotificationEventAlertFiring,
+	NotificationEventAlertResolved,
+}
+
+const (
+	// NotificationAPIKeyExpiryWarning is how long before its expiry an API
+	// key is notified.
+	NotificationAPIKeyExpiryWarning = 7 * 24 * time.Hour
+	// DefaultNotificationDigest is the digest of channels created without one.
+	DefaultNotificationDigest = 15 * time.Minute
+	// MaxNotificationDigest is the longest digest a channel can have.
+	MaxNotificationDigest = 24 * time.Hour
+
+	// notificationTimeout bounds sending a single message.
+	notificationTimeout = 10 * time.Second
+	// maxPendingNotifications caps the notifications held for a channel
+	// whose messages keep failing; the oldest are dropped first.
+	maxPendingNotifications = 200
+	// slackWebhookHost is the host of Slack incoming webhooks, the only
+	// host Slack channels post to.
+	slackWebhookHost = "hooks.slack.com"
+)
+
+var (
+	ErrNotificationChannelNotFound  = errors.New("notification channel not found")
+	ErrInvalidNotificationChannel   = errors.New("invalid notification channel")
+	ErrUnsupportedNotificationEvent = errors.New("unsupported notification event")
+	ErrEmailNotConfigured           = errors.New("email notifications are not configured on this coordinator")
+)
+
+// SMTPConfig is the mail server email notifications are sent through. An
+// empty Addr or From disables email channels. Username and Password, if
+// set, authenticate with PLAIN auth, which net/smtp only sends over TLS or
+// to localhost.
+type SMTPConfig struct {
+	Addr     string
+	Username string
+	Password string
+	From     string
+}
+
+// Notification is one event to be sent to the channels of a wonder net.
+type Notification struct {
+	Event       string
+	WonderNetID string
+	Text        string
+	At          time.Time
+}
+
+// notificationDigest holds the notifications waiting to be sent to a channel.
+type notificationDigest struct {
+	channel       *repository.NotificationChannel
+	notifications []Notification
+}
+
+// NotificationService manages notification channels and sends a wonder
+// net's notable events to them by email or Slack. Events are batched per
+// channel into one message per digest period, so that an outage that takes
+// down many nodes sends one message rather than one per node.
+//
+// Node events come from the NodeWatcher that also feeds webhooks. Expiring
+// API keys are found by polling their expiry; the first check of a wonder
+// net only sets the baseline, so a restart does not repeat notifications
+// for keys that were already expiring. Pending digests are kept in memory
+// and lost on restart.
+type NotificationService struct {
+	notificationRepository *repository.NotificationRepository
+	wonderNetRepository    *repository.WonderNetRepository
+	apiKeyRepository       *repository.APIKeyRepository
+	smtp                   SMTPConfig
+	client                 *http.Client
+	sendMail               func(addr string, auth smtp.Auth, from string, to []string, msg []byte) error
+
+	mu sync.Mutex
+	// expiring holds the API keys of each watched wonder net that were
+	// about to expire at the last check.
+	expiring map[string]map[string]bool
+	// pending holds the notifications waiting for each channel's digest.
+	pending map[string]*notificationDigest
+}
+
+// NewNotificationService creates a new NotificationService.
+func NewNotificationService(
+	notificationRepository *repository.NotificationRepository,
+	wonderNetRepository *repository.WonderNetRepository,
+	apiKeyRepository *repository.APIKeyRepository,
+	smtpConfig SMTPConfig,
+) *NotificationService {
+	return &NotificationService{
+		notificationRepository: notificationRepository,
+		wonderNetRepository:    wonderNetRepository,
+		apiKeyRepository:       apiKeyRepository,
+		smtp:                   smtpConfig,
+		client:                 newOutboundClient(notificationTimeout),
+		sendMail:               smtp.SendMail,
+		expiring:               make(map[string]map[string]bool),
+		pending:                make(map[string]*notificationDigest),
+	}
+}
+
+// EmailEnabled reports whether email channels can be created.
+func (s *NotificationService) EmailEnabled() bool {
+	return s.smtp.Addr != "" && s.smtp.From != ""
+}
+
+// CreateChannel creates a notification channel for a wonder net. The target
+// is a comma-separated list of email addresses for email channels, or the
+// incoming webhook URL of Slack channels. An empty events list subscribes
+// to every event.
+func (s *NotificationService) CreateChannel(ctx context.Context, wonderNetID, kind, target string, events []string, digest time.Duration, createdBy string) (*repository.NotificationChannel, error) {
+	switch kind {
+	case repository.NotificationChannelEmail:
+		if !s.EmailEnabled() {
+			return nil, ErrEmailNotConfigured
+		}
+		addresses, err := mail.ParseAddressList(target)
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid email addresses: %v", ErrInvalidNotificationChannel, err)
+		}
+		recipients := make([]string, len(addresses))
+		for i, address := range addresses {
+			recipients[i] = address.Address
+		}
+		target = strings.Join(recipients, ",")
+	case repository.NotificationChannelSlack:
+		if err := validateSlackWebhookURL(target); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("%w: kind must be %s or %s", ErrInvalidNotificationChannel, repository.NotificationChannelEmail, repository.NotificationChannelSlack)
+	}
+	if digest < 0 || digest > MaxNotificationDigest {
+		return nil, fmt.Errorf("%w: digest must be between 0 and %s", ErrInvalidNotificationChannel, MaxNotificationDigest)
+	}
+	for _, event := range events {
+		if !slices.Contains(NotificationEvents, event) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedNotificationEvent, event)
+		}
+	}
+
+	channel, err := s.notificationRepository.Create(ctx, &repository.NotificationChannel{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNetID,
+		Kind:        kind,
+		Target:      target,
+		Events:      slices.Compact(slices.Sorted(slices.Values(events))),
+		Digest:      digest.Truncate(time.Second),
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created notification channel", "id", channel.ID, "wonder_net_id", wonderNetID, "kind", kind, "events", channel.Events, "digest", channel.Digest)
+	return channel, nil
+}
+
+// ListChannels lists all notification channels of a wonder net.
+func (s *NotificationService) ListChannels(ctx context.Context, wonderNetID string) ([]*repository.NotificationChannel, error) {
+	return s.notificationRepository.ListByWonderNet(ctx, wonderNetID)
+}
+
+// DeleteChannel deletes a notification channel of a wonder net, dropping
+// its pending notifications.
+func (s *NotificationService) DeleteChannel(ctx context.Context, wonderNetID, channelID string) error {
+	channel, err := s.notificationRepository.Get(ctx, channelID)
+	if err != nil {
+		return err
+	}
+	if channel == nil || channel.WonderNetID != wonderNetID {
+		return ErrNotificationChannelNotFound
+	}
+	if err := s.notificationRepository.Delete(ctx, channelID); err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	delete(s.pending, channelID)
+	s.mu.Unlock()
+
+	slog.Info("deleted notification channel", "id", channelID, "wonder_net_id", wonderNetID)
+	return nil
+}
+
+// Run checks for events and sends due digests every interval until ctx is
+// cancelled.
+func (s *NotificationService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Check(ctx, time.Now()); err != nil {
+				slog.Error("check notification events", "error", err)
+			}
+			s.Flush(ctx, time.Now())
+		}
+	}
+}
+
+// Check looks for newly expiring API keys in every wonder net with a
+// notification channel and queues them for the channels subscribed to them.
+func (s *NotificationService) Check(ctx context.Context, now time.Time) error {
+	channels, err := s.notificationRepository.List(ctx)
+	if err != nil {
+		return err
+	}
+
+	byWonderNet := make(map[string][]*repository.NotificationChannel)
+	byID := make(map[string]*repository.NotificationChannel)
+	for _, channel := range channels {
+		byWonderNet[channel.WonderNetID] = append(byWonderNet[channel.WonderNetID], channel)
+		byID[channel.ID] = channel
+	}
+
+	s.mu.Lock()
+	for wonderNetID := range s.expiring {
+		if byWonderNet[wonderNetID] == nil {
+			delete(s.expiring, wonderNetID)
+		}
+	}
+	for channelID, digest := range s.pending {
+		if byID[channelID] == nil {
+			delete(s.pending, channelID)
+		} else {
+			digest.channel = byID[channelID]
+		}
+	}
+	s.mu.Unlock()
+
+	for wonderNetID, channels := range byWonderNet {
+		if !notificationSubscribed(channels, NotificationEventAPIKeyExpiring) {
+			continue
+		}
+		wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+		if err != nil {
+			slog.Warn("get wonder net for notifications", "wonder_net_id", wonderNetID, "error", err)
+			continue
+		}
+		if wonderNet == nil {
+			continue
+		}
+
+		notifications, err := s.checkAPIKeys(ctx, wonderNet, now)
+		if err != nil {
+			slog.Warn("check API keys for notifications", "wonder_net_id", wonderNetID, "error", err)
+		}
+		s.queue(channels, notifications)
+	}
+	return nil
+}
+
+// WatchedWonderNets returns the wonder nets with a notification channel
+// subscribed to node events.
+func (s *NotificationService) WatchedWonderNets(ctx context.Context) (map[string]bool, error) {
+	channels, err := s.notificationRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make(map[string]bool)
+	for _, channel := range channels {
+		if channelSubscribed(channel, NotificationEventNodeJoined) ||
+			channelSubscribed(channel, NotificationEventNodeAuthorized) ||
+			channelSubscribed(channel, NotificationEventNodeOffline) {
+			watched[channel.WonderNetID] = true
+		}
+	}
+	return watched, nil
+}
+
+// HandleNodeEvents queues the notifications of node events for the
+// channels of the wonder net subscribed to them.
+func (s *NotificationService) HandleNodeEvents(ctx context.Context, wonderNet *repository.WonderNet, events []NodeEvent) {
+	channels, err := s.notificationRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		slog.Warn("list notification channels for node events", "wonder_net_id", wonderNet.ID, "error", err)
+		return
+	}
+	s.queue(channels, nodeNotifications(wonderNet.ID, events, time.Now()))
+}
+
+// checkAPIKeys returns the API keys of the wonder net that came within
+// NotificationAPIKeyExpiryWarning of their expiry since the last check.
+func (s *NotificationService) checkAPIKeys(ctx context.Context, wonderNet *repository.WonderNet, now time.Time) ([]Notification, error) {
+	keys, err := s.apiKeyRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	expiring := expiringAPIKeys(keys, NotificationAPIKeyExpiryWarning, now)
+	current := make(map[string]bool)
+	for _, key := range expiring {
+		current[key.ID] = true
+	}
+
+	s.mu.Lock()
+	prev, seen := s.expiring[wonderNet.ID]
+	s.expiring[wonderNet.ID] = current
+	s.mu.Unlock()
+	if !seen {
+		return nil, nil
+	}
+
+	var notifications []Notification
+	for _, key := range expiring {
+		if prev[key.ID] {
+			continue
+		}
+		notifications = append(notifications, Notification{
+			Event:       NotificationEventAPIKeyExpiring,
+			WonderNetID: wonderNet.ID,
+			Text:        fmt.Sprintf("API key %s (%s...) expires at %s", key.Name, key.KeyPrefix, key.ExpiresAt.UTC().Format(time.RFC3339)),
+			At:          now,
+		})
+	}
+	return notifications, nil
+}
+
+// Notify queues an alert event for the channels of the alert's wonder net,
+// so alert rules are delivered by email and Slack.
+func (s *NotificationService) Notify(ctx context.Context, event AlertEvent) error {
+	channels, err := s.notificationRepository.ListByWonderNet(ctx, event.Alert.WonderNetID)
+	if err != nil {
+		return err
+	}
+	s.queue(channels, []Notification{alertNotification(event, time.Now())})
+	return nil
+}
+
+// queue adds notifications to the pending digests of the channels
+// subscribed to them.
+func (s *NotificationService) queue(channels []*repository.NotificationChannel, notifications []Notification) {
+	if len(notifications) == 0 {
+		return
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, channel := range channels {
+		for _, notification := range notifications {
+			if !channelSubscribed(channel, notification.Event) {
+				continue
+			}
+			digest := s.pending[channel.ID]
+			if digest == nil {
+				digest = &notificationDigest{channel: channel}
+				s.pending[channel.ID] = digest
+			}
+			digest.notifications = append(digest.notifications, notification)
+			if len(digest.notifications) > maxPendingNotifications {
+				digest.notifications = digest.notifications[len(digest.notifications)-maxPendingNotifications:]
+			}
+		}
+	}
+}
+
+// Flush sends the digests whose oldest notification has waited for the
+// channel's digest period at now. A digest that fails to send is kept and
+// tried again on the next flush.
+func (s *NotificationService) Flush(ctx context.Context, now time.Time) {
+	s.mu.Lock()
+	var due []*notificationDigest
+	for channelID, digest := range s.pending {
+		if len(digest.notifications) == 0 || now.Sub(digest.notifications[0].At) < digest.channel.Digest {
+			continue
+		}
+		due = append(due, digest)
+		delete(s.pending, channelID)
+	}
+	s.mu.Unlock()
+
+	for _, digest := range due {
+		channel := digest.channel
+		wonderNetName := channel.WonderNetID
+		if wonderNet, err := s.wonderNetRepository.Get(ctx, channel.WonderNetID); err == nil && wonderNet != nil && wonderNet.DisplayName != "" {
+			wonderNetName = wonderNet.DisplayName
+		}
+		subject, body := formatNotificationDigest(wonderNetName, digest.notifications)
+
+		if err := s.send(ctx, channel, subject, body); err != nil {
+			slog.Warn("send notification digest", "channel_id", channel.ID, "wonder_net_id", channel.WonderNetID, "kind", channel.Kind, "notifications", len(digest.notifications), "error", err)
+			s.requeue(digest)
+			continue
+		}
+		slog.Info("sent notification digest", "channel_id", channel.ID, "wonder_net_id", channel.WonderNetID, "kind", channel.Kind, "notifications", len(digest.notifications))
+	}
+}
+
+// requeue puts the notifications of a digest that failed to send back in
+// front of those queued since.
+func (s *NotificationService) requeue(digest *notificationDigest) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if current := s.pending[digest.channel.ID]; current != nil {
+		digest.notifications = append(digest.notifications, current.notifications...)
+	}
+	if len(digest.notifications) > maxPendingNotifications {
+		digest.notifications = digest.notifications[len(digest.notifications)-maxPendingNotifications:]
+	}
+	s.pending[digest.channel.ID] = digest
+}
+
+// send delivers a message to a channel.
+func (s *NotificationService) send(ctx context.Context, channel *repository.NotificationChannel, subject, body string) error {
+	switch channel.Kind {
+	case repository.NotificationChannelEmail:
+		return s.sendEmail(channel, subject, body)
+	case repository.NotificationChannelSlack:
+		return s.sendSlack(ctx, channel, subject, body)
+	default:
+		return fmt.Errorf("unknown notification channel kind %q", channel.Kind)
+	}
+}
+
+// sendEmail mails a message to the addresses of an email channel.
+func (s *NotificationService) sendEmail(channel *repository.NotificationChannel, subject, body string) error {
+	if !s.EmailEnabled() {
+		return ErrEmailNotConfigured
+	}
+
+	from, err := mail.ParseAddress(s.smtp.From)
+	if err != nil {
+		return fmt.Errorf("invalid sender address: %w", err)
+	}
+	recipients := strings.Split(channel.Target, ",")
+	var auth smtp.Auth
+	if s.smtp.Username != "" {
+		host, _, err := net.SplitHostPort(s.smtp.Addr)
+		if err != nil {
+			return fmt.Errorf("invalid SMTP address: %w", err)
+		}
+		auth = smtp.PlainAuth("", s.smtp.Username, s.smtp.Password, host)
+	}
+	return s.sendMail(s.smtp.Addr, auth, from.Address, recipients, emailMessage(from.String(), recipients, subject, body, time.Now()))
+}
+
+// sendSlack posts a message to the incoming webhook of a Slack channel.
+func (s *NotificationService) sendSlack(ctx context.Context, channel *repository.NotificationChannel, subject, body string) error {
+	if err := validateSlackWebhookURL(channel.Target); err != nil {
+		return err
+	}
+	payload, err := json.Marshal(map[string]string{"text": "*" + subject + "*\n" + body})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, channel.Target, bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("slack returned %s", resp.Status)
+	}
+	return nil
+}
+
+// validateSlackWebhookURL checks that rawURL is a Slack incoming webhook
+// URL. Channels created before this check may hold other URLs, so it is
+// also done before every message.
+func validateSlackWebhookURL(rawURL string) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Scheme != "https" || u.Host != slackWebhookHost || u.User != nil {
+		return fmt.Errorf("%w: Slack webhook URL must start with https://%s/", ErrInvalidNotificationChannel, slackWebhookHost)
+	}
+	return nil
+}
+
+// formatNotificationDigest returns the subject and plain text body of a
+// message carrying notifications of a wonder net.
+func formatNotificationDigest(wonderNetName string, notifications []Notification) (string, string) {
+	var subject string
+	if len(notifications) == 1 {
+		subject = fmt.Sprintf("[wonder] %s: %s", wonderNetName, notifications[0].Text)
+	} else {
+		subject = fmt.Sprintf("[wonder] %s: %d notifications", wonderNetName, len(notifications))
+	}
+
+	var body strings.Builder
+	for _, notification := range notifications {
+		fmt.Fprintf(&body, "%s  %s\n", notification.At.UTC().Format("2006-01-02 15:04 MST"), notification.Text)
+	}
+	return subject, body.String()
+}
+
+// emailMessage returns a plain text email ready for SMTP.
+func emailMessage(from string, to []string, subject, body string, date time.Time) []byte {
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", from)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(to, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", mimeHeader(subject))
+	fmt.Fprintf(&msg, "Date: %s\r\n", date.Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/plain; charset=utf-8\r\n")
+	msg.WriteString("\r\n")
+	msg.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+	return msg.Bytes()
+}
+
+// mimeHeader encodes a header value that is not plain ASCII and strips
+// line breaks that would start a new header.
+func mimeHeader(value string) string {
+	value = strings.NewReplacer("\r", " ", "\n", " ").Replace(value)
+	for _, r := range value {
+		if r > 127 {
+			return mime.QEncoding.Encode("utf-8", value)
+		}
+	}
+	return value
+}
+
+// expiringAPIKeys returns the keys that expire after now but within d,
+// leaving out rotated keys (see isExpiringAPIKey).
+func expiringAPIKeys(keys []*repository.APIKey, d time.Duration, now time.Time) []*repository.APIKey {
+	var expiring []*repository.APIKey
+	for _, key := range keys {
+		if isExpiringAPIKey(key, d, now) {
+			expiring = append(expiring, key)
+		}
+	}
+	return expiring
+}
+
+func channelSubscribed(channel *repository.NotificationChannel, event string) bool {
+	return len(channel.Events) == 0 || slices.Contains(channel.Events, event)
+}
+
+func notificationSubscribed(channels []*repository.NotificationChannel, event string) bool {
+	return slices.ContainsFunc(channels, func(channel *repository.NotificationChannel) bool {
+		return channelSubscribed(channel, event)
+	})
+}
+
+// nodeNotifications returns the notifications of the node events of a
+// wonder net at now.
+func nodeNotifications(wonderNetID string, events []NodeEvent, now time.Time) []Notification {
+	var notifications []Notification
+	for _, event := range events {
+		notification := Notification{WonderNetID: wonderNetID, At: now}
+		switch event.Type {
+		case NodeEventJoin:
+			notification.Event = NotificationEventNodeJoined
+			notification.Text = fmt.Sprintf("New device %s joined", nodeLabel(event.Node))
+		case NodeEventAuthorized:
+			notification.Event = NotificationEventNodeAuthorized
+			notification.Text = fmt.Sprintf("New device %s authorized", nodeLabel(event.Node))
+		case NodeEventOffline:
+			notification.Event = NotificationEventNodeOffline
+			notification.Text = fmt.Sprintf("Node %s went offline", nodeLabel(event.Node))
+			if event.Node.LastSeen != nil {
+				notification.Text = fmt.Sprintf("Node %s has been offline since %s", nodeLabel(event.Node), event.Node.LastSeen.UTC().Format(time.RFC3339))
+			}
+		default:
+			continue
+		}
+		notifications = append(notifications, notification)
+	}
+	return notifications
+}
+
+// alertNotification returns the notification of an alert event at now.
+func alertNotification(event AlertEvent, now time.Time) Notification {
+	rule := event.Alert.RuleName
+	if rule == "" {
+		rule = event.Alert.Kind
+	}
+	if event.Resolved {
+		return Notification{
+			Event:       NotificationEventAlertResolved,
+			WonderNetID: event.Alert.WonderNetID,
+			Text:        fmt.Sprintf("Alert %s resolved for node %s", rule, event.Alert.NodeName),
+			At:          now,
+		}
+	}
+	return Notification{
+		Event:       NotificationEventAlertFiring,
+		WonderNetID: event.Alert.WonderNetID,
+		Text:        fmt.Sprintf("Alert %s firing for node %s since %s", rule, event.Alert.NodeName, event.Alert.StartsAt.UTC().Format(time.RFC3339)),
+		At:          now,
+	}
+}
+
+// nodeLabel names a node in notification text.
+func nodeLabel(node *Node) string {
+	if len(node.IPAddrs) == 0 {
+		return node.Name
+	}
+	return fmt.Sprintf("%s (%s)", node.Name, node.IPAddrs[0])
+}
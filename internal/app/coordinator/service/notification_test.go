@@ -0,0 +1,200 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestFormatNotificationDigest(t *testing.T) {
+	at := time.Date(2025, 6, 30, 12, 5, 0, 0, time.UTC)
+	joined := Notification{Event: NotificationEventNodeJoined, Text: "New device web-1 joined", At: at}
+	offline := Notification{Event: NotificationEventNodeOffline, Text: "Node db-1 has been offline since 2025-06-30T11:00:00Z", At: at.Add(time.Minute)}
+
+	tests := []struct {
+		name          string
+		notifications []Notification
+		wantSubject   string
+		wantBody      string
+	}{
+		{
+			name:          "single",
+			notifications: []Notification{joined},
+			wantSubject:   "[wonder] prod: New device web-1 joined",
+			wantBody:      "2025-06-30 12:05 UTC  New device web-1 joined\n",
+		},
+		{
+			name:          "digest",
+			notifications: []Notification{joined, offline},
+			wantSubject:   "[wonder] prod: 2 notifications",
+			wantBody: "2025-06-30 12:05 UTC  New device web-1 joined\n" +
+				"2025-06-30 12:06 UTC  Node db-1 has been offline since 2025-06-30T11:00:00Z\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			subject, body := formatNotificationDigest("prod", tt.notifications)
+			if subject != tt.wantSubject {
+				t.Errorf("subject = %q, want %q", subject, tt.wantSubject)
+			}
+			if body != tt.wantBody {
+				t.Errorf("body = %q, want %q", body, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestNodeNotifications(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	lastSeen := now.Add(-2 * time.Hour)
+	events := []NodeEvent{
+		{Type: NodeEventJoin, Node: &Node{ID: 1, Name: "web-1", IPAddrs: []string{"100.64.0.1"}}},
+		{Type: NodeEventAuthorized, Node: &Node{ID: 2, Name: "web-2"}},
+		{Type: NodeEventOffline, Node: &Node{ID: 3, Name: "db-1", LastSeen: &lastSeen}},
+		{Type: NodeEventLeave, Node: &Node{ID: 4, Name: "old"}},
+	}
+
+	var got []string
+	for _, notification := range nodeNotifications("wn", events, now) {
+		got = append(got, notification.Event+": "+notification.Text)
+	}
+	want := []string{
+		"node.joined: New device web-1 (100.64.0.1) joined",
+		"node.authorized: New device web-2 authorized",
+		"node.offline: Node db-1 has been offline since 2025-06-30T10:00:00Z",
+	}
+	if !slices.Equal(got, want) {
+		t.Errorf("nodeNotifications() = %q, want %q", got, want)
+	}
+}
+
+func TestAlertNotification(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	alert := Alert{RuleName: "db down", Kind: AlertKindNodeOffline, NodeName: "db-1", StartsAt: now.Add(-time.Hour)}
+
+	firing := alertNotification(AlertEvent{Alert: alert}, now)
+	if firing.Event != NotificationEventAlertFiring || firing.Text != "Alert db down firing for node db-1 since 2025-06-30T11:00:00Z" {
+		t.Errorf("firing notification = %+v", firing)
+	}
+
+	alert.RuleName = ""
+	resolved := alertNotification(AlertEvent{Alert: alert, Resolved: true}, now)
+	if resolved.Event != NotificationEventAlertResolved || resolved.Text != "Alert node_offline resolved for node db-1" {
+		t.Errorf("resolved notification = %+v", resolved)
+	}
+}
+
+func TestExpiringAPIKeys(t *testing.T) {
+	now := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	in := func(d time.Duration) *time.Time {
+		t := now.Add(d)
+		return &t
+	}
+	day := 24 * time.Hour
+
+	keys := []*repository.APIKey{
+		{ID: "never"},
+		{ID: "expired", ExpiresAt: in(-time.Minute)},
+		{ID: "tomorrow", ExpiresAt: in(day)},
+		{ID: "exactly-7-days", ExpiresAt: in(7 * day)},
+		{ID: "next-month", ExpiresAt: in(30 * day)},
+		{ID: "rotated", ExpiresAt: in(day), DeprecatedAt: in(-time.Hour), SuccessorID: "tomorrow"},
+	}
+
+	var got []string
+	for _, key := range expiringAPIKeys(keys, 7*day, now) {
+		got = append(got, key.ID)
+	}
+	if want := []string{"tomorrow", "exactly-7-days"}; !slices.Equal(got, want) {
+		t.Errorf("expiringAPIKeys() = %v, want %v", got, want)
+	}
+}
+
+func TestEmailMessage(t *testing.T) {
+	date := time.Date(2025, 6, 30, 12, 0, 0, 0, time.UTC)
+	msg := string(emailMessage("wonder@example.com", []string{"a@example.com", "b@example.com"}, "Node down\r\nBcc: x@example.com", "line 1\nline 2\n", date))
+
+	for _, want := range []string{
+		"From: wonder@example.com\r\n",
+		"To: a@example.com, b@example.com\r\n",
+		"Subject: Node down  Bcc: x@example.com\r\n",
+		"Date: Mon, 30 Jun 2025 12:00:00 +0000\r\n",
+		"\r\n\r\nline 1\r\nline 2\r\n",
+	} {
+		if !strings.Contains(msg, want) {
+			t.Errorf("message %q does not contain %q", msg, want)
+		}
+	}
+}
+
+func TestNotificationSendSlack(t *testing.T) {
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"accepted", http.StatusOK, false},
+		{"rejected", http.StatusNotFound, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				var payload struct {
+					Text string `json:"text"`
+				}
+				if err := json.NewDecoder(r.Body).Decode(&payload); err != nil {
+					t.Errorf("decode payload: %v", err)
+				}
+				if want := "*subject*\nbody"; payload.Text != want {
+					t.Errorf("text = %q, want %q", payload.Text, want)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			// Messages to hooks.slack.com reach the test server instead.
+			client := srv.Client()
+			transport := client.Transport.(*http.Transport)
+			transport.TLSClientConfig.InsecureSkipVerify = true
+			transport.DialContext = func(ctx context.Context, network, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, network, srv.Listener.Addr().String())
+			}
+			s := &NotificationService{client: client}
+			channel := &repository.NotificationChannel{Kind: repository.NotificationChannelSlack, Target: "https://hooks.slack.com/services/T0/B0/x"}
+			if err := s.send(context.Background(), channel, "subject", "body"); (err != nil) != tt.wantErr {
+				t.Errorf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestValidateSlackWebhookURL(t *testing.T) {
+	tests := []struct {
+		url     string
+		wantErr bool
+	}{
+		{"https://hooks.slack.com/services/T0/B0/x", false},
+		{"http://hooks.slack.com/services/T0/B0/x", true},
+		{"https://hooks.slack.com.attacker.net/services", true},
+		{"https://hooks.slack.com:8443/services", true},
+		{"https://user@hooks.slack.com/services", true},
+		{"https://169.254.169.254/latest/meta-data", true},
+		{"", true},
+	}
+	for _, tt := range tests {
+		if err := validateSlackWebhookURL(tt.url); (err != nil) != tt.wantErr {
+			t.Errorf("validateSlackWebhookURL(%q) error = %v, wantErr %v", tt.url, err, tt.wantErr)
+		}
+	}
+}
@@ -19,11 +19,12 @@ import (
 )
 
 const (
-	stateLength       = 32
-	stateTTL          = 10 * time.Minute
-	sessionCookieName = "wonder_session"
-	sessionTTL        = 24 * time.Hour
-	cleanupInterval   = 5 * time.Minute
+	stateLength        = 32
+	codeVerifierLength = 64
+	stateTTL           = 10 * time.Minute
+	sessionCookieName  = "wonder_session"
+	sessionTTL         = 24 * time.Hour
+	cleanupInterval    = 5 * time.Minute
 )
 
 type OIDCConfig struct {
@@ -51,13 +52,22 @@ type SessionData struct {
 	ExpiresAt    time.Time
 }
 
+// authState is a pending login. CodeVerifier is the PKCE secret whose
+// challenge was sent with the authorization request; it has to be presented
+// when exchanging the code.
+type authState struct {
+	expiresAt    time.Time
+	codeVerifier string
+}
+
 // OIDCService handles OIDC authentication flow.
 type OIDCService struct {
 	config       OIDCConfig
+	configMu     sync.RWMutex
 	jwtValidator *jwtauth.Validator
 	httpClient   *http.Client
 
-	states  map[string]time.Time
+	states  map[string]*authState
 	stateMu sync.RWMutex
 
 	sessions  map[string]*SessionData
@@ -73,7 +83,7 @@ func NewOIDCService(config OIDCConfig, jwtValidator *jwtauth.Validator) *OIDCSer
 		httpClient: &http.Client{
 			Timeout: 10 * time.Second,
 		},
-		states:      make(map[string]time.Time),
+		states:      make(map[string]*authState),
 		sessions:    make(map[string]*SessionData),
 		stopCleanup: make(chan struct{}),
 	}
@@ -100,66 +110,125 @@ func (s *OIDCService) Stop() {
 	close(s.stopCleanup)
 }
 
-// GenerateAuthURL generates the Keycloak authorization URL with a new state parameter.
+// SetConfig replaces the Keycloak settings used for new logins and code
+// exchanges. Existing sessions are kept.
+func (s *OIDCService) SetConfig(config OIDCConfig) {
+	s.configMu.Lock()
+	s.config = config
+	s.configMu.Unlock()
+}
+
+func (s *OIDCService) getConfig() OIDCConfig {
+	s.configMu.RLock()
+	defer s.configMu.RUnlock()
+	return s.config
+}
+
+// GenerateAuthURL generates the Keycloak authorization URL with a new state
+// parameter and a PKCE (RFC 7636) S256 code challenge.
 func (s *OIDCService) GenerateAuthURL() (string, string, error) {
 	state, err := generateRandomString(stateLength)
 	if err != nil {
 		return "", "", fmt.Errorf("generate state: %w", err)
 	}
+	codeVerifier, err := generateRandomString(codeVerifierLength)
+	if err != nil {
+		return "", "", fmt.Errorf("generate code verifier: %w", err)
+	}
 
 	s.stateMu.Lock()
-	s.states[state] = time.Now().Add(stateTTL)
+	s.states[state] = &authState{
+		expiresAt:    time.Now().Add(stateTTL),
+		codeVerifier: codeVerifier,
+	}
 	s.stateMu.Unlock()
 
+	config := s.getConfig()
 	authURL := fmt.Sprintf(
 		"%s/realms/%s/protocol/openid-connect/auth",
-		s.config.KeycloakURL,
-		s.config.Realm,
+		config.KeycloakURL,
+		config.Realm,
 	)
 
 	params := url.Values{}
-	params.Set("client_id", s.config.ClientID)
+	params.Set("client_id", config.ClientID)
 	params.Set("response_type", "code")
 	params.Set("scope", "openid profile email")
-	params.Set("redirect_uri", s.config.RedirectURI)
+	params.Set("redirect_uri", config.RedirectURI)
 	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge(codeVerifier))
+	params.Set("code_challenge_method", "S256")
 
 	return authURL + "?" + params.Encode(), state, nil
 }
 
-// ValidateState checks if the state parameter is valid and not expired.
-func (s *OIDCService) ValidateState(state string) error {
+// ValidateState checks if the state parameter is valid and not expired, and
+// returns the PKCE code verifier of the login it belongs to. A state can
+// only be used once.
+func (s *OIDCService) ValidateState(state string) (string, error) {
 	s.stateMu.Lock()
 	defer s.stateMu.Unlock()
 
-	expiresAt, exists := s.states[state]
+	pending, exists := s.states[state]
 	if !exists {
-		return ErrInvalidState
+		return "", ErrInvalidState
 	}
 
 	delete(s.states, state)
 
-	if time.Now().After(expiresAt) {
-		return ErrStateExpired
+	if time.Now().After(pending.expiresAt) {
+		return "", ErrStateExpired
 	}
 
-	return nil
+	return pending.codeVerifier, nil
 }
 
-// ExchangeCode exchanges the authorization code for tokens.
-func (s *OIDCService) ExchangeCode(ctx context.Context, code string) (*TokenResponse, error) {
-	tokenURL := fmt.Sprintf(
-		"%s/realms/%s/protocol/openid-connect/token",
-		s.config.KeycloakURL,
-		s.config.Realm,
-	)
+// ExchangeCode exchanges the authorization code for tokens, proving with the
+// PKCE code verifier that this coordinator started the login. The response
+// keeps the refresh token for RefreshUserInfo.
+func (s *OIDCService) ExchangeCode(ctx context.Context, code, codeVerifier string) (*TokenResponse, error) {
+	config := s.getConfig()
 
 	data := url.Values{}
 	data.Set("grant_type", "authorization_code")
-	data.Set("client_id", s.config.ClientID)
-	data.Set("client_secret", s.config.ClientSecret)
+	data.Set("client_id", config.ClientID)
+	data.Set("client_secret", config.ClientSecret)
 	data.Set("code", code)
-	data.Set("redirect_uri", s.config.RedirectURI)
+	data.Set("redirect_uri", config.RedirectURI)
+	data.Set("code_verifier", codeVerifier)
+
+	return s.requestToken(ctx, config, data)
+}
+
+// RefreshUserInfo redeems a refresh token for new tokens and returns them
+// together with the user's claims, as UserClaims resolves them.
+func (s *OIDCService) RefreshUserInfo(ctx context.Context, refreshToken string) (*TokenResponse, *jwtauth.Claims, error) {
+	config := s.getConfig()
+
+	data := url.Values{}
+	data.Set("grant_type", "refresh_token")
+	data.Set("client_id", config.ClientID)
+	data.Set("client_secret", config.ClientSecret)
+	data.Set("refresh_token", refreshToken)
+
+	tokenResp, err := s.requestToken(ctx, config, data)
+	if err != nil {
+		return nil, nil, err
+	}
+	claims, err := s.UserClaims(ctx, tokenResp)
+	if err != nil {
+		return nil, nil, err
+	}
+	return tokenResp, claims, nil
+}
+
+// requestToken posts a grant to the token endpoint.
+func (s *OIDCService) requestToken(ctx context.Context, config OIDCConfig, data url.Values) (*TokenResponse, error) {
+	tokenURL := fmt.Sprintf(
+		"%s/realms/%s/protocol/openid-connect/token",
+		config.KeycloakURL,
+		config.Realm,
+	)
 
 	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(data.Encode()))
 	if err != nil {
@@ -190,6 +259,101 @@ func (s *OIDCService) ExchangeCode(ctx context.Context, code string) (*TokenResp
 	return &tokenResp, nil
 }
 
+// UserClaims validates the ID token of a token response and returns its
+// claims. ID tokens issued for minimal scopes may lack the email and name
+// claims; those are then filled in from the userinfo endpoint, using the
+// access token.
+func (s *OIDCService) UserClaims(ctx context.Context, tokenResp *TokenResponse) (*jwtauth.Claims, error) {
+	claims, err := s.ValidateIDToken(tokenResp.IDToken)
+	if err != nil {
+		return nil, err
+	}
+	if claims.Email != "" && claims.Name != "" {
+		return claims, nil
+	}
+
+	info, err := s.fetchUserInfo(ctx, tokenResp.AccessToken)
+	if err != nil {
+		return nil, err
+	}
+	if err := mergeUserInfo(claims, info); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// mergeUserInfo fills the profile claims missing from an ID token with the
+// userinfo of the same subject.
+func mergeUserInfo(claims *jwtauth.Claims, info *userInfo) error {
+	if info.Subject != claims.Subject {
+		return fmt.Errorf("%w: userinfo subject %q does not match ID token subject %q", ErrUserInfo, info.Subject, claims.Subject)
+	}
+	if claims.Email == "" {
+		claims.Email = info.Email
+		claims.EmailVerified = info.EmailVerified
+	}
+	if claims.Name == "" {
+		claims.Name = info.Name
+	}
+	if claims.PreferredUsername == "" {
+		claims.PreferredUsername = info.PreferredUsername
+	}
+	if claims.GivenName == "" {
+		claims.GivenName = info.GivenName
+	}
+	if claims.FamilyName == "" {
+		claims.FamilyName = info.FamilyName
+	}
+	return nil
+}
+
+// userInfo is the response of the OIDC userinfo endpoint.
+type userInfo struct {
+	Subject           string `json:"sub"`
+	Email             string `json:"email"`
+	EmailVerified     bool   `json:"email_verified"`
+	Name              string `json:"name"`
+	PreferredUsername string `json:"preferred_username"`
+	GivenName         string `json:"given_name"`
+	FamilyName        string `json:"family_name"`
+}
+
+// fetchUserInfo asks the userinfo endpoint about the owner of accessToken.
+func (s *OIDCService) fetchUserInfo(ctx context.Context, accessToken string) (*userInfo, error) {
+	config := s.getConfig()
+	userInfoURL := fmt.Sprintf(
+		"%s/realms/%s/protocol/openid-connect/userinfo",
+		config.KeycloakURL,
+		config.Realm,
+	)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, userInfoURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create userinfo request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("userinfo request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read userinfo response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%w: status %d, body: %s", ErrUserInfo, resp.StatusCode, string(body))
+	}
+
+	var info userInfo
+	if err := json.Unmarshal(body, &info); err != nil {
+		return nil, fmt.Errorf("parse userinfo response: %w", err)
+	}
+	return &info, nil
+}
+
 // ValidateIDToken validates the ID token and returns the claims.
 func (s *OIDCService) ValidateIDToken(idToken string) (*jwtauth.Claims, error) {
 	claims, err := s.jwtValidator.Validate(idToken)
@@ -264,8 +428,8 @@ func (s *OIDCService) CleanupExpiredStates() {
 	defer s.stateMu.Unlock()
 
 	now := time.Now()
-	for state, expiresAt := range s.states {
-		if now.After(expiresAt) {
+	for state, pending := range s.states {
+		if now.After(pending.expiresAt) {
 			delete(s.states, state)
 		}
 	}
@@ -301,6 +465,12 @@ func generateRandomString(length int) (string, error) {
 	return base64.RawURLEncoding.EncodeToString(bytes)[:length], nil
 }
 
+// codeChallenge derives the PKCE S256 code challenge from a code verifier.
+func codeChallenge(codeVerifier string) string {
+	hash := sha256.Sum256([]byte(codeVerifier))
+	return base64.RawURLEncoding.EncodeToString(hash[:])
+}
+
 // hashSessionID creates a SHA256 hash of the session ID for storage.
 func hashSessionID(sessionID string) string {
 	hash := sha256.Sum256([]byte(sessionID))
@@ -1,10 +1,17 @@
 package service
 
 import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
 	"net/url"
 	"strings"
 	"testing"
 	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+	keycloakfake "github.com/strrl/wonder-mesh-net/pkg/keycloak/fake"
 )
 
 func TestOIDCService_GenerateAuthURL(t *testing.T) {
@@ -69,19 +76,165 @@ func TestOIDCService_ValidateState(t *testing.T) {
 		t.Fatalf("GenerateAuthURL: %v", err)
 	}
 
-	if err := svc.ValidateState(validState); err != nil {
+	if _, err := svc.ValidateState(validState); err != nil {
 		t.Errorf("ValidateState(validState): %v", err)
 	}
 
-	if err := svc.ValidateState(validState); err != ErrInvalidState {
+	if _, err := svc.ValidateState(validState); err != ErrInvalidState {
 		t.Errorf("ValidateState(validState) second time = %v, want ErrInvalidState", err)
 	}
 
-	if err := svc.ValidateState("invalid-state"); err != ErrInvalidState {
+	if _, err := svc.ValidateState("invalid-state"); err != ErrInvalidState {
 		t.Errorf("ValidateState(invalid-state) = %v, want ErrInvalidState", err)
 	}
 }
 
+func TestOIDCService_PKCE(t *testing.T) {
+	var gotVerifier string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse token request: %v", err)
+		}
+		gotVerifier = r.PostForm.Get("code_verifier")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","id_token":"id"}`))
+	}))
+	defer tokenServer.Close()
+
+	config := OIDCConfig{
+		KeycloakURL:  tokenServer.URL,
+		Realm:        "wonder-mesh",
+		ClientID:     "coordinator",
+		ClientSecret: "secret",
+		RedirectURI:  "https://coordinator.example.com/coordinator/oidc/callback",
+	}
+	svc := NewOIDCService(config, nil)
+
+	authURL, state, err := svc.GenerateAuthURL()
+	if err != nil {
+		t.Fatalf("GenerateAuthURL: %v", err)
+	}
+	parsed, err := url.Parse(authURL)
+	if err != nil {
+		t.Fatalf("parse auth URL: %v", err)
+	}
+	query := parsed.Query()
+	if got := query.Get("code_challenge_method"); got != "S256" {
+		t.Errorf("code_challenge_method = %q, want %q", got, "S256")
+	}
+
+	codeVerifier, err := svc.ValidateState(state)
+	if err != nil {
+		t.Fatalf("ValidateState: %v", err)
+	}
+	if len(codeVerifier) < 43 {
+		t.Errorf("code verifier length = %d, want at least 43", len(codeVerifier))
+	}
+	if got := query.Get("code_challenge"); got != codeChallenge(codeVerifier) {
+		t.Errorf("code_challenge = %q, want S256 of the code verifier %q", got, codeChallenge(codeVerifier))
+	}
+
+	if _, err := svc.ExchangeCode(context.Background(), "code", codeVerifier); err != nil {
+		t.Fatalf("ExchangeCode: %v", err)
+	}
+	if gotVerifier != codeVerifier {
+		t.Errorf("token request code_verifier = %q, want %q", gotVerifier, codeVerifier)
+	}
+}
+
+func TestOIDCService_RefreshToken(t *testing.T) {
+	var gotGrant, gotRefreshToken string
+	tokenServer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := r.ParseForm(); err != nil {
+			t.Errorf("parse token request: %v", err)
+		}
+		gotGrant = r.PostForm.Get("grant_type")
+		gotRefreshToken = r.PostForm.Get("refresh_token")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"access_token":"access","token_type":"Bearer","refresh_token":"rotated","id_token":"id"}`))
+	}))
+	defer tokenServer.Close()
+
+	svc := NewOIDCService(OIDCConfig{KeycloakURL: tokenServer.URL, Realm: "wonder-mesh", ClientID: "coordinator"}, nil)
+
+	// Without a JWT validator the new ID token cannot be validated, but the
+	// grant has been sent by then.
+	if _, _, err := svc.RefreshUserInfo(context.Background(), "refresh"); err == nil {
+		t.Fatal("RefreshUserInfo() error = nil, want ID token validation error")
+	}
+	if gotGrant != "refresh_token" {
+		t.Errorf("grant_type = %q, want %q", gotGrant, "refresh_token")
+	}
+	if gotRefreshToken != "refresh" {
+		t.Errorf("refresh_token = %q, want %q", gotRefreshToken, "refresh")
+	}
+}
+
+func TestOIDCService_FetchUserInfo(t *testing.T) {
+	var gotAuth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/realms/wonder-mesh/protocol/openid-connect/userinfo" {
+			http.NotFound(w, r)
+			return
+		}
+		gotAuth = r.Header.Get("Authorization")
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write([]byte(`{"sub":"user-123","email":"alice@example.com","email_verified":true,"name":"Alice"}`))
+	}))
+	defer server.Close()
+
+	svc := NewOIDCService(OIDCConfig{KeycloakURL: server.URL, Realm: "wonder-mesh"}, nil)
+	info, err := svc.fetchUserInfo(context.Background(), "access")
+	if err != nil {
+		t.Fatalf("fetchUserInfo: %v", err)
+	}
+	if gotAuth != "Bearer access" {
+		t.Errorf("Authorization = %q, want %q", gotAuth, "Bearer access")
+	}
+	if info.Subject != "user-123" || info.Email != "alice@example.com" || !info.EmailVerified || info.Name != "Alice" {
+		t.Errorf("fetchUserInfo() = %+v", info)
+	}
+}
+
+func TestMergeUserInfo(t *testing.T) {
+	info := &userInfo{
+		Subject:           "user-123",
+		Email:             "alice@example.com",
+		EmailVerified:     true,
+		Name:              "Alice Liddell",
+		PreferredUsername: "alice",
+	}
+
+	claims := &jwtauth.Claims{Name: "Alice"}
+	claims.Subject = "user-123"
+	if err := mergeUserInfo(claims, info); err != nil {
+		t.Fatalf("mergeUserInfo: %v", err)
+	}
+	if claims.Email != "alice@example.com" || !claims.EmailVerified {
+		t.Errorf("email = %q (verified %v), want alice@example.com (verified)", claims.Email, claims.EmailVerified)
+	}
+	if claims.Name != "Alice" {
+		t.Errorf("name = %q, want the ID token's %q", claims.Name, "Alice")
+	}
+	if claims.PreferredUsername != "alice" {
+		t.Errorf("preferred_username = %q, want %q", claims.PreferredUsername, "alice")
+	}
+
+	other := &jwtauth.Claims{}
+	other.Subject = "user-456"
+	if err := mergeUserInfo(other, info); !errors.Is(err, ErrUserInfo) {
+		t.Errorf("mergeUserInfo(other subject) error = %v, want %v", err, ErrUserInfo)
+	}
+}
+
+func TestCodeChallenge(t *testing.T) {
+	// Example from RFC 7636 appendix B.
+	got := codeChallenge("dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk")
+	if want := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"; got != want {
+		t.Errorf("codeChallenge() = %q, want %q", got, want)
+	}
+}
+
 func TestOIDCService_Session(t *testing.T) {
 	config := OIDCConfig{
 		KeycloakURL:  "https://auth.example.com",
@@ -178,12 +331,12 @@ func TestOIDCService_CleanupExpiredStates(t *testing.T) {
 	}
 
 	svc.stateMu.Lock()
-	svc.states[state] = time.Now().Add(-1 * time.Hour)
+	svc.states[state].expiresAt = time.Now().Add(-1 * time.Hour)
 	svc.stateMu.Unlock()
 
 	svc.CleanupExpiredStates()
 
-	if err := svc.ValidateState(state); err != ErrInvalidState {
+	if _, err := svc.ValidateState(state); err != ErrInvalidState {
 		t.Errorf("ValidateState after cleanup = %v, want ErrInvalidState", err)
 	}
 }
@@ -222,3 +375,70 @@ func TestHashSessionID(t *testing.T) {
 		t.Errorf("hash length = %d, want 64 (SHA256 hex)", len(hash1))
 	}
 }
+
+func TestOIDCService_LoginWithKeycloak(t *testing.T) {
+	kc, err := keycloakfake.New("wonder-mesh", keycloakfake.Client{ID: "coordinator", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("start Keycloak: %v", err)
+	}
+	defer kc.Close()
+	kc.AddUser(keycloakfake.User{ID: "user-123", Username: "alice", Email: "alice@example.com", Name: "Alice Liddell"})
+	kc.SignIn("user-123")
+
+	validator := jwtauth.NewValidator(kc.ValidatorConfig("coordinator"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := validator.Start(ctx); err != nil {
+		t.Fatalf("start validator: %v", err)
+	}
+	svc := NewOIDCService(OIDCConfig{
+		KeycloakURL:  kc.URL(),
+		Realm:        kc.Realm(),
+		ClientID:     "coordinator",
+		ClientSecret: "secret",
+		RedirectURI:  "https://coordinator.example.com/coordinator/oidc/callback",
+	}, validator)
+	defer svc.Stop()
+
+	authURL, _, err := svc.GenerateAuthURL()
+	if err != nil {
+		t.Fatalf("GenerateAuthURL() error = %v", err)
+	}
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	codeVerifier, err := svc.ValidateState(callback.Query().Get("state"))
+	if err != nil {
+		t.Fatalf("ValidateState() error = %v", err)
+	}
+
+	tokens, err := svc.ExchangeCode(ctx, callback.Query().Get("code"), codeVerifier)
+	if err != nil {
+		t.Fatalf("ExchangeCode() error = %v", err)
+	}
+	claims, err := svc.UserClaims(ctx, tokens)
+	if err != nil {
+		t.Fatalf("UserClaims() error = %v", err)
+	}
+	if claims.Subject != "user-123" || claims.Email != "alice@example.com" {
+		t.Errorf("claims = %+v, want user-123 alice@example.com", claims)
+	}
+
+	if _, _, err := svc.RefreshUserInfo(ctx, tokens.RefreshToken); err != nil {
+		t.Errorf("RefreshUserInfo() error = %v", err)
+	}
+	user, err := svc.LookupUserByEmail(ctx, "Alice@example.com")
+	if err != nil {
+		t.Fatalf("LookupUserByEmail() error = %v", err)
+	}
+	if user.ID != "user-123" {
+		t.Errorf("LookupUserByEmail() = %+v, want user-123", user)
+	}
+}
@@ -0,0 +1,71 @@
+package service
+
+import (
+	"errors"
+	"net"
+	"net/http"
+	"net/netip"
+	"syscall"
+	"time"
+)
+
+// ErrForbiddenDestination is returned for requests to tenant-supplied URLs
+// that resolve to an address of the coordinator's own network.
+var ErrForbiddenDestination = errors.New("destination is not a public address")
+
+// nonPublicPrefixes are the ranges, besides loopback, link-local, private,
+// unspecified, and multicast addresses, that tenant-supplied URLs must not
+// reach: "this network", the shared address space the mesh hands out node
+// addresses from, IETF protocol assignments, and benchmarking.
+var nonPublicPrefixes = []netip.Prefix{
+	netip.MustParsePrefix("0.0.0.0/8"),
+	netip.MustParsePrefix("100.64.0.0/10"),
+	netip.MustParsePrefix("192.0.0.0/24"),
+	netip.MustParsePrefix("198.18.0.0/15"),
+}
+
+// newOutboundClient returns the HTTP client for requests to URLs supplied
+// by tenants, such as webhooks. It only connects to public addresses,
+// checked on the resolved address of every connection so that DNS
+// rebinding cannot get around it, ignores proxy settings, which would
+// connect on its behalf, and does not follow redirects.
+func newOutboundClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: timeout, Control: dialPublicOnly}
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+	return &http.Client{
+		Timeout:   timeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// dialPublicOnly is a net.Dialer Control function refusing connections to
+// addresses that are not public.
+func dialPublicOnly(network, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !isPublicAddr(addrPort.Addr()) {
+		return ErrForbiddenDestination
+	}
+	return nil
+}
+
+// isPublicAddr reports whether ip is a public unicast address.
+func isPublicAddr(ip netip.Addr) bool {
+	ip = ip.Unmap()
+	if !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return false
+	}
+	for _, prefix := range nonPublicPrefixes {
+		if prefix.Contains(ip) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+var (
+	ErrInvalidPublicURL = errors.New("invalid public URL")
+	ErrPublicURLInUse   = errors.New("public URL already in use")
+)
+
+// NormalizePublicURL checks a wonder net public URL such as
+// "https://mesh.acme.com" and returns it with a lowercase host and no
+// trailing slash. The empty URL is valid and selects the coordinator's
+// public URL. Otherwise the URL must be http(s) with a host and nothing
+// else, and must not be the coordinator's own host.
+func NormalizePublicURL(publicURL, coordinatorURL string) (string, error) {
+	if publicURL == "" {
+		return "", nil
+	}
+
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return "", fmt.Errorf("%w: %v", ErrInvalidPublicURL, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return "", fmt.Errorf("%w: scheme must be http or https", ErrInvalidPublicURL)
+	}
+	if u.Hostname() == "" {
+		return "", fmt.Errorf("%w: host is required", ErrInvalidPublicURL)
+	}
+	if u.User != nil || (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" {
+		return "", fmt.Errorf("%w: only a scheme and host are allowed", ErrInvalidPublicURL)
+	}
+
+	host := strings.ToLower(u.Host)
+	if coordinator, err := url.Parse(coordinatorURL); err == nil && strings.EqualFold(coordinator.Hostname(), u.Hostname()) {
+		return "", fmt.Errorf("%w: %s is the coordinator's own host", ErrInvalidPublicURL, u.Hostname())
+	}
+	return u.Scheme + "://" + host, nil
+}
+
+// SetPublicURL maps a custom domain to a wonder net. Join tokens and join
+// credentials issued for the wonder net then point workers at that URL
+// instead of the coordinator's public URL. An empty URL removes the mapping;
+// workers that already joined keep using the URL they joined with.
+func (s *WonderNetService) SetPublicURL(ctx context.Context, wonderNetID, publicURL string) (*repository.WonderNet, error) {
+	publicURL, err := NormalizePublicURL(publicURL, s.publicURL)
+	if err != nil {
+		return nil, err
+	}
+
+	wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	if wonderNet == nil {
+		return nil, ErrNoWonderNet
+	}
+
+	if publicURL != "" {
+		existing, err := s.wonderNetRepository.GetByPublicURL(ctx, publicURL)
+		if err != nil {
+			return nil, err
+		}
+		if existing != nil && existing.ID != wonderNet.ID {
+			return nil, ErrPublicURLInUse
+		}
+	}
+
+	if err := s.wonderNetRepository.UpdatePublicURL(ctx, wonderNet.ID, publicURL); err != nil {
+		return nil, err
+	}
+	wonderNet.PublicURL = publicURL
+
+	slog.Info("set wonder net public URL", "id", wonderNet.ID, "public_url", publicURL)
+	return wonderNet, nil
+}
+
+// GetWonderNetByHost returns the wonder net whose public URL has the given
+// host, which may include a port, or nil if there is none.
+func (s *WonderNetService) GetWonderNetByHost(ctx context.Context, host string) (*repository.WonderNet, error) {
+	host = strings.ToLower(host)
+	for _, scheme := range []string{"https", "http"} {
+		wonderNet, err := s.wonderNetRepository.GetByPublicURL(ctx, scheme+"://"+host)
+		if err != nil || wonderNet != nil {
+			return wonderNet, err
+		}
+	}
+	return nil, nil
+}
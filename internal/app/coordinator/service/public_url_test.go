@@ -0,0 +1,43 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestNormalizePublicURL(t *testing.T) {
+	const coordinatorURL = "https://wonder.example.com"
+
+	tests := []struct {
+		name      string
+		publicURL string
+		want      string
+		wantErr   bool
+	}{
+		{"empty uses coordinator URL", "", "", false},
+		{"https host", "https://mesh.acme.com", "https://mesh.acme.com", false},
+		{"uppercase host and trailing slash", "https://Mesh.Acme.com/", "https://mesh.acme.com", false},
+		{"http with port", "http://mesh.acme.com:8080", "http://mesh.acme.com:8080", false},
+		{"missing scheme", "mesh.acme.com", "", true},
+		{"unsupported scheme", "ftp://mesh.acme.com", "", true},
+		{"path", "https://mesh.acme.com/join", "", true},
+		{"query", "https://mesh.acme.com?x=1", "", true},
+		{"user info", "https://admin@mesh.acme.com", "", true},
+		{"coordinator host", "https://WONDER.example.com", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := NormalizePublicURL(tt.publicURL, coordinatorURL)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("NormalizePublicURL(%q) error = %v, wantErr %v", tt.publicURL, err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, ErrInvalidPublicURL) {
+				t.Errorf("error = %v, want %v", err, ErrInvalidPublicURL)
+			}
+			if got != tt.want {
+				t.Errorf("NormalizePublicURL(%q) = %q, want %q", tt.publicURL, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+var (
+	// ErrQuotaExceeded is returned when an operation would take a wonder
+	// net over one of its quotas. Errors wrapping it name the limit.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	ErrInvalidQuota  = errors.New("quota limits must not be negative")
+)
+
+// authKeyWindow is the window the authkey issuance rate is measured over.
+const authKeyWindow = time.Hour
+
+// Quotas are the limits a wonder net is held to. A zero limit means
+// unlimited.
+type Quotas struct {
+	// MaxNodes caps the nodes registered in the wonder net. It is checked
+	// when mesh credentials are issued, so a key issued below the limit can
+	// still be used after others joined; AuthKeysPerHour bounds how far
+	// that overshoots.
+	MaxNodes        int
+	MaxAPIKeys      int
+	AuthKeysPerHour int
+}
+
+// WonderNetQuotas are the quotas in effect for a wonder net. Overridden is
+// set when an admin replaced the coordinator defaults for it.
+type WonderNetQuotas struct {
+	Quotas
+	Overridden bool
+	UpdatedAt  *time.Time
+}
+
+// QuotaService enforces per-wonder-net quotas so that a single tenant
+// cannot exhaust a shared coordinator, most importantly the mesh address
+// pool. Authkey issuance is counted in memory and starts over when the
+// coordinator restarts.
+type QuotaService struct {
+	quotaRepository  *repository.WonderNetQuotaRepository
+	apiKeyRepository *repository.APIKeyRepository
+	meshBackend      meshbackend.MeshBackend
+
+	mu       sync.Mutex
+	defaults Quotas
+	// authKeys holds the issuance times within authKeyWindow per wonder net.
+	authKeys map[string][]time.Time
+	now      func() time.Time
+}
+
+// NewQuotaService creates a new QuotaService applying defaults to wonder
+// nets without an override.
+func NewQuotaService(
+	quotaRepository *repository.WonderNetQuotaRepository,
+	apiKeyRepository *repository.APIKeyRepository,
+	meshBackend meshbackend.MeshBackend,
+	defaults Quotas,
+) *QuotaService {
+	return &QuotaService{
+		quotaRepository:  quotaRepository,
+		apiKeyRepository: apiKeyRepository,
+		meshBackend:      meshBackend,
+		defaults:         defaults,
+		authKeys:         make(map[string][]time.Time),
+		now:              time.Now,
+	}
+}
+
+// SetDefaults replaces the quotas of wonder nets without an override.
+func (s *QuotaService) SetDefaults(defaults Quotas) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.defaults = defaults
+}
+
+// GetQuotas returns the quotas in effect for a wonder net.
+func (s *QuotaService) GetQuotas(ctx context.Context, wonderNetID string) (*WonderNetQuotas, error) {
+	override, err := s.quotaRepository.Get(ctx, wonderNetID)
+	if err != nil {
+		return nil, fmt.Errorf("get quotas: %w", err)
+	}
+	if override == nil {
+		s.mu.Lock()
+		defer s.mu.Unlock()
+		return &WonderNetQuotas{Quotas: s.defaults}, nil
+	}
+	return wonderNetQuotas(override), nil
+}
+
+// SetQuotas overrides the coordinator defaults for a wonder net.
+func (s *QuotaService) SetQuotas(ctx context.Context, wonderNetID string, quotas Quotas) (*WonderNetQuotas, error) {
+	if quotas.MaxNodes < 0 || quotas.MaxAPIKeys < 0 || quotas.AuthKeysPerHour < 0 {
+		return nil, ErrInvalidQuota
+	}
+	override, err := s.quotaRepository.Set(ctx, &repository.WonderNetQuota{
+		WonderNetID:     wonderNetID,
+		MaxNodes:        quotas.MaxNodes,
+		MaxAPIKeys:      quotas.MaxAPIKeys,
+		AuthKeysPerHour: quotas.AuthKeysPerHour,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set quotas: %w", err)
+	}
+	return wonderNetQuotas(override), nil
+}
+
+// ResetQuotas removes the override of a wonder net, returning it to the
+// coordinator defaults.
+func (s *QuotaService) ResetQuotas(ctx context.Context, wonderNetID string) error {
+	if err := s.quotaRepository.Delete(ctx, wonderNetID); err != nil {
+		return fmt.Errorf("reset quotas: %w", err)
+	}
+	return nil
+}
+
+// CheckAPIKeys returns an error wrapping ErrQuotaExceeded if the wonder net
+// has no API keys left to create.
+func (s *QuotaService) CheckAPIKeys(ctx context.Context, wonderNetID string) error {
+	quotas, err := s.GetQuotas(ctx, wonderNetID)
+	if err != nil {
+		return err
+	}
+	if quotas.MaxAPIKeys == 0 {
+		return nil
+	}
+
+	keys, err := s.apiKeyRepository.ListByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return fmt.Errorf("count api keys: %w", err)
+	}
+	if len(keys) >= quotas.MaxAPIKeys {
+		return fmt.Errorf("%w: wonder net has %d of %d api keys", ErrQuotaExceeded, len(keys), quotas.MaxAPIKeys)
+	}
+	return nil
+}
+
+// ReserveAuthKey checks that the wonder net may be issued another mesh
+// authkey and counts the issuance. It returns an error wrapping
+// ErrQuotaExceeded if the wonder net is at its node limit or issued
+// AuthKeysPerHour keys within the last hour.
+func (s *QuotaService) ReserveAuthKey(ctx context.Context, wonderNet *repository.WonderNet) error {
+	quotas, err := s.GetQuotas(ctx, wonderNet.ID)
+	if err != nil {
+		return err
+	}
+
+	if quotas.MaxNodes > 0 {
+		nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+		if err != nil {
+			return fmt.Errorf("count nodes: %w", err)
+		}
+		if len(nodes) >= quotas.MaxNodes {
+			return fmt.Errorf("%w: wonder net has %d of %d nodes", ErrQuotaExceeded, len(nodes), quotas.MaxNodes)
+		}
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	issued := recentAuthKeys(s.authKeys[wonderNet.ID], now)
+	if quotas.AuthKeysPerHour > 0 && len(issued) >= quotas.AuthKeysPerHour {
+		s.authKeys[wonderNet.ID] = issued
+		return fmt.Errorf("%w: wonder net issued %d of %d authkeys in the last hour", ErrQuotaExceeded, len(issued), quotas.AuthKeysPerHour)
+	}
+	s.authKeys[wonderNet.ID] = append(issued, now)
+	return nil
+}
+
+// recentAuthKeys drops the issuance times that fell out of authKeyWindow.
+// Times are appended in order, so the recent ones are a suffix.
+func recentAuthKeys(issued []time.Time, now time.Time) []time.Time {
+	cutoff := now.Add(-authKeyWindow)
+	for i, t := range issued {
+		if t.After(cutoff) {
+			return issued[i:]
+		}
+	}
+	return nil
+}
+
+func wonderNetQuotas(override *repository.WonderNetQuota) *WonderNetQuotas {
+	return &WonderNetQuotas{
+		Quotas: Quotas{
+			MaxNodes:        override.MaxNodes,
+			MaxAPIKeys:      override.MaxAPIKeys,
+			AuthKeysPerHour: override.AuthKeysPerHour,
+		},
+		Overridden: true,
+		UpdatedAt:  &override.UpdatedAt,
+	}
+}
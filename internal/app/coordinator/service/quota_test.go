@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestRecentAuthKeys(t *testing.T) {
+	now := time.Date(2026, 1, 2, 12, 0, 0, 0, time.UTC)
+	issued := []time.Time{
+		now.Add(-2 * time.Hour),
+		now.Add(-time.Hour),
+		now.Add(-59 * time.Minute),
+		now.Add(-time.Minute),
+	}
+
+	tests := []struct {
+		name   string
+		issued []time.Time
+		want   int
+	}{
+		{"none issued", nil, 0},
+		{"drops keys older than the window", issued, 2},
+		{"all expired", issued[:2], 0},
+		{"all recent", issued[2:], 2},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := recentAuthKeys(tt.issued, now); len(got) != tt.want {
+				t.Errorf("recentAuthKeys() = %v, want %d keys", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestSetQuotasRejectsNegativeLimits(t *testing.T) {
+	s := NewQuotaService(nil, nil, nil, Quotas{})
+
+	tests := []Quotas{
+		{MaxNodes: -1},
+		{MaxAPIKeys: -1},
+		{AuthKeysPerHour: -1},
+	}
+
+	for _, quotas := range tests {
+		if _, err := s.SetQuotas(context.Background(), "wn-1", quotas); !errors.Is(err, ErrInvalidQuota) {
+			t.Errorf("SetQuotas(%+v) error = %v, want %v", quotas, err, ErrInvalidQuota)
+		}
+	}
+}
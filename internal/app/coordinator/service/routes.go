@@ -0,0 +1,183 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+var (
+	ErrRouteNotFound  = errors.New("route not found")
+	ErrInvalidRouteID = errors.New("invalid route id")
+)
+
+// Route is a subnet or exit route advertised by a node. Its ID is the node
+// ID and the prefix joined by "_", with the prefix's "/" also written as "_"
+// so that the ID fits in a URL path segment, for example "12_10.0.0.0_24".
+type Route struct {
+	ID       string
+	NodeID   uint64
+	NodeName string
+	Prefix   string
+	ExitNode bool
+	Approved bool
+	Serving  bool
+}
+
+// RoutesService lists the routes advertised in a wonder net and approves or
+// rejects them. Nodes may only serve the routes they advertise once the
+// mesh has approved them.
+type RoutesService struct {
+	meshBackend meshbackend.MeshBackend
+}
+
+// NewRoutesService creates a new RoutesService.
+func NewRoutesService(meshBackend meshbackend.MeshBackend) *RoutesService {
+	return &RoutesService{
+		meshBackend: meshBackend,
+	}
+}
+
+// ListRoutes returns the routes advertised by the nodes of a wonder net.
+func (s *RoutesService) ListRoutes(ctx context.Context, wonderNet *repository.WonderNet) ([]*Route, error) {
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return nil, err
+	}
+
+	var routes []*Route
+	for _, node := range nodes {
+		routes = append(routes, nodeRoutes(node)...)
+	}
+	return routes, nil
+}
+
+// ApproveRoute allows a node to serve a route it advertises. Approving one
+// half of an exit route approves the other address family too, since a node
+// acts as an exit node for both or neither.
+func (s *RoutesService) ApproveRoute(ctx context.Context, wonderNet *repository.WonderNet, routeID string) (*Route, error) {
+	return s.updateRoute(ctx, wonderNet, routeID, true)
+}
+
+// RejectRoute withdraws the approval of a route, or declines a route that
+// has not been approved yet.
+func (s *RoutesService) RejectRoute(ctx context.Context, wonderNet *repository.WonderNet, routeID string) (*Route, error) {
+	return s.updateRoute(ctx, wonderNet, routeID, false)
+}
+
+func (s *RoutesService) updateRoute(ctx context.Context, wonderNet *repository.WonderNet, routeID string, approve bool) (*Route, error) {
+	nodeID, prefix, err := parseRouteID(routeID)
+	if err != nil {
+		return nil, err
+	}
+
+	node, err := s.meshBackend.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, fmt.Errorf("get node: %w", err)
+	}
+	if node.Realm != wonderNet.HeadscaleUser || !slices.Contains(node.AdvertisedRoutes, prefix) {
+		return nil, ErrRouteNotFound
+	}
+
+	approved := updatedApprovedRoutes(node, prefix, approve)
+	if err := s.meshBackend.SetApprovedRoutes(ctx, nodeID, approved); err != nil {
+		return nil, err
+	}
+	node.ApprovedRoutes = approved
+
+	action := "rejected route"
+	if approve {
+		action = "approved route"
+	}
+	slog.Info(action, "wonder_net_id", wonderNet.ID, "node_id", nodeID, "prefix", prefix)
+
+	for _, route := range nodeRoutes(node) {
+		if route.Prefix == prefix {
+			return route, nil
+		}
+	}
+	return nil, ErrRouteNotFound
+}
+
+// nodeRoutes returns the routes a node advertises.
+func nodeRoutes(node *meshbackend.Node) []*Route {
+	id, err := strconv.ParseUint(node.ID, 10, 64)
+	if err != nil {
+		slog.Warn("parse node ID", "node_name", node.Name, "raw_id", node.ID, "error", err)
+	}
+
+	routes := make([]*Route, 0, len(node.AdvertisedRoutes))
+	for _, prefix := range node.AdvertisedRoutes {
+		routes = append(routes, &Route{
+			ID:       routeID(node.ID, prefix),
+			NodeID:   id,
+			NodeName: node.Name,
+			Prefix:   prefix,
+			ExitNode: isExitRoute(prefix),
+			Approved: slices.Contains(node.ApprovedRoutes, prefix),
+			Serving:  slices.Contains(node.ServingRoutes, prefix),
+		})
+	}
+	return routes
+}
+
+// updatedApprovedRoutes returns the approved routes of a node after
+// approving or rejecting prefix. Exit routes are changed as a pair.
+func updatedApprovedRoutes(node *meshbackend.Node, prefix string, approve bool) []string {
+	affected := []string{prefix}
+	if isExitRoute(prefix) {
+		for _, advertised := range node.AdvertisedRoutes {
+			if isExitRoute(advertised) && advertised != prefix {
+				affected = append(affected, advertised)
+			}
+		}
+	}
+
+	result := make([]string, 0, len(node.ApprovedRoutes)+len(affected))
+	for _, route := range node.ApprovedRoutes {
+		if !slices.Contains(affected, route) {
+			result = append(result, route)
+		}
+	}
+	if approve {
+		result = append(result, affected...)
+	}
+	return result
+}
+
+// isExitRoute reports whether prefix is a default route, which makes the
+// node serving it an exit node.
+func isExitRoute(prefix string) bool {
+	p, err := netip.ParsePrefix(prefix)
+	return err == nil && p.Bits() == 0
+}
+
+func routeID(nodeID, prefix string) string {
+	return nodeID + "_" + strings.ReplaceAll(prefix, "/", "_")
+}
+
+// parseRouteID splits a route ID into the node ID and the route prefix.
+func parseRouteID(id string) (nodeID, prefix string, err error) {
+	nodeID, rest, ok := strings.Cut(id, "_")
+	i := strings.LastIndex(rest, "_")
+	if !ok || i < 0 {
+		return "", "", ErrInvalidRouteID
+	}
+	if _, err := strconv.ParseUint(nodeID, 10, 64); err != nil {
+		return "", "", ErrInvalidRouteID
+	}
+
+	prefix = rest[:i] + "/" + rest[i+1:]
+	if _, err := netip.ParsePrefix(prefix); err != nil {
+		return "", "", ErrInvalidRouteID
+	}
+	return nodeID, prefix, nil
+}
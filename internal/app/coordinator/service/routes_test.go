@@ -0,0 +1,98 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+func TestParseRouteID(t *testing.T) {
+	tests := []struct {
+		id         string
+		wantNodeID string
+		wantPrefix string
+		wantErr    bool
+	}{
+		{id: "12_10.0.0.0_24", wantNodeID: "12", wantPrefix: "10.0.0.0/24"},
+		{id: "3_0.0.0.0_0", wantNodeID: "3", wantPrefix: "0.0.0.0/0"},
+		{id: "3_::_0", wantNodeID: "3", wantPrefix: "::/0"},
+		{id: "7_fd7a:115c::_48", wantNodeID: "7", wantPrefix: "fd7a:115c::/48"},
+		{id: "12", wantErr: true},
+		{id: "abc_10.0.0.0_24", wantErr: true},
+		{id: "12_10.0.0.0", wantErr: true},
+		{id: "12_not-an-ip_24", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		nodeID, prefix, err := parseRouteID(tt.id)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidRouteID) {
+				t.Errorf("parseRouteID(%q) error = %v, want ErrInvalidRouteID", tt.id, err)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("parseRouteID(%q) error = %v", tt.id, err)
+			continue
+		}
+		if nodeID != tt.wantNodeID || prefix != tt.wantPrefix {
+			t.Errorf("parseRouteID(%q) = %q, %q, want %q, %q", tt.id, nodeID, prefix, tt.wantNodeID, tt.wantPrefix)
+		}
+		if got := routeID(nodeID, prefix); got != tt.id {
+			t.Errorf("routeID(%q, %q) = %q, want %q", nodeID, prefix, got, tt.id)
+		}
+	}
+}
+
+func TestUpdatedApprovedRoutes(t *testing.T) {
+	node := &meshbackend.Node{
+		ID:               "5",
+		AdvertisedRoutes: []string{"10.0.0.0/24", "0.0.0.0/0", "::/0"},
+		ApprovedRoutes:   []string{"10.0.0.0/24"},
+	}
+
+	tests := []struct {
+		name    string
+		prefix  string
+		approve bool
+		want    []string
+	}{
+		{name: "approve exit approves both families", prefix: "::/0", approve: true, want: []string{"10.0.0.0/24", "::/0", "0.0.0.0/0"}},
+		{name: "reject subnet", prefix: "10.0.0.0/24", approve: false, want: []string{}},
+		{name: "approve approved subnet is idempotent", prefix: "10.0.0.0/24", approve: true, want: []string{"10.0.0.0/24"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := updatedApprovedRoutes(node, tt.prefix, tt.approve)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("updatedApprovedRoutes() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNodeRoutes(t *testing.T) {
+	node := &meshbackend.Node{
+		ID:               "5",
+		Name:             "gateway",
+		AdvertisedRoutes: []string{"10.0.0.0/24", "0.0.0.0/0"},
+		ApprovedRoutes:   []string{"10.0.0.0/24"},
+		ServingRoutes:    []string{"10.0.0.0/24"},
+	}
+
+	routes := nodeRoutes(node)
+	if len(routes) != 2 {
+		t.Fatalf("nodeRoutes() returned %d routes, want 2", len(routes))
+	}
+
+	want := Route{ID: "5_10.0.0.0_24", NodeID: 5, NodeName: "gateway", Prefix: "10.0.0.0/24", Approved: true, Serving: true}
+	if *routes[0] != want {
+		t.Errorf("nodeRoutes()[0] = %+v, want %+v", *routes[0], want)
+	}
+	if !routes[1].ExitNode || routes[1].Approved {
+		t.Errorf("nodeRoutes()[1] = %+v, want unapproved exit route", *routes[1])
+	}
+}
@@ -0,0 +1,516 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/headscale"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+var (
+	ErrServiceNotFound      = errors.New("service not found")
+	ErrServiceNameTaken     = errors.New("service name already in use")
+	ErrServiceGrantNotFound = errors.New("service grant not found")
+	ErrServiceGrantExists   = errors.New("service grant already exists")
+	ErrInvalidService       = errors.New("invalid service")
+)
+
+const (
+	// serviceSubjectNodePrefix selects a single node of the wonder net by ID.
+	serviceSubjectNodePrefix = "node:"
+
+	// servicePolicyKeySuffix distinguishes a wonder net's service policy
+	// fragment from its owner-defined ACL rules.
+	servicePolicyKeySuffix = "/services"
+)
+
+var serviceNamePattern = regexp.MustCompile(`^[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ServiceWithGrants is a service together with the grants on it.
+type ServiceWithGrants struct {
+	*repository.Service
+	Grants []*repository.ServiceGrant
+}
+
+// ServiceCatalogService manages the services a wonder net publishes and who
+// may reach them. Each grant becomes an ACL rule that allows its subject to
+// reach only the service's node, port, and protocol, rather than the whole
+// node.
+//
+// Rules address service nodes by their mesh IPs, which the coordinator is not
+// notified about when nodes are deleted, so the rules are rebuilt by periodic
+// reconciliation as well as on every change.
+type ServiceCatalogService struct {
+	serviceRepository *repository.ServiceRepository
+	wonderNetRepo     *repository.WonderNetRepository
+	meshBackend       meshbackend.MeshBackend
+	aclManager        *headscale.ACLManager
+}
+
+// NewServiceCatalogService creates a new ServiceCatalogService.
+func NewServiceCatalogService(
+	serviceRepository *repository.ServiceRepository,
+	wonderNetRepo *repository.WonderNetRepository,
+	meshBackend meshbackend.MeshBackend,
+	aclManager *headscale.ACLManager,
+) *ServiceCatalogService {
+	return &ServiceCatalogService{
+		serviceRepository: serviceRepository,
+		wonderNetRepo:     wonderNetRepo,
+		meshBackend:       meshBackend,
+		aclManager:        aclManager,
+	}
+}
+
+// CreateService publishes a port on one of the wonder net's nodes under a
+// name. Protocol defaults to tcp. A new service is reachable by nobody
+// outside the wonder net's normal isolation policy until access is granted.
+func (s *ServiceCatalogService) CreateService(ctx context.Context, wonderNet *repository.WonderNet, name, nodeID string, port int, protocol string) (*repository.Service, error) {
+	if s.aclManager == nil {
+		return nil, ErrUnsupportedByMeshBackend
+	}
+	protocol, err := validateServiceSpec(name, port, protocol)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkNodeInWonderNet(ctx, wonderNet, nodeID); err != nil {
+		return nil, err
+	}
+
+	existing, err := s.serviceRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, svc := range existing {
+		if svc.Name == name {
+			return nil, ErrServiceNameTaken
+		}
+	}
+
+	svc, err := s.serviceRepository.Create(ctx, &repository.Service{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNet.ID,
+		Name:        name,
+		NodeID:      nodeID,
+		Port:        port,
+		Protocol:    protocol,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created service", "id", svc.ID, "wonder_net_id", wonderNet.ID, "name", name, "node_id", nodeID, "port", port, "protocol", protocol)
+	return svc, nil
+}
+
+// ListServices lists the services of a wonder net with their grants.
+func (s *ServiceCatalogService) ListServices(ctx context.Context, wonderNet *repository.WonderNet) ([]*ServiceWithGrants, error) {
+	services, err := s.serviceRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	grants, err := s.serviceRepository.ListGrantsByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	byService := make(map[string][]*repository.ServiceGrant)
+	for _, grant := range grants {
+		byService[grant.ServiceID] = append(byService[grant.ServiceID], grant)
+	}
+
+	result := make([]*ServiceWithGrants, len(services))
+	for i, svc := range services {
+		result[i] = &ServiceWithGrants{Service: svc, Grants: byService[svc.ID]}
+	}
+	return result, nil
+}
+
+// DeleteService unpublishes a service and revokes all access to it.
+func (s *ServiceCatalogService) DeleteService(ctx context.Context, wonderNet *repository.WonderNet, serviceID string) error {
+	if _, err := s.getOwnedService(ctx, wonderNet, serviceID); err != nil {
+		return err
+	}
+
+	if err := s.serviceRepository.Delete(ctx, serviceID); err != nil {
+		return err
+	}
+	if err := s.Apply(ctx, wonderNet); err != nil {
+		return fmt.Errorf("apply service policy: %w", err)
+	}
+
+	slog.Info("deleted service", "id", serviceID, "wonder_net_id", wonderNet.ID)
+	return nil
+}
+
+// RemoveNode unpublishes the services a node hosts and revokes the grants
+// that name it as subject, so that the node neither serves nor reaches any
+// service anymore.
+func (s *ServiceCatalogService) RemoveNode(ctx context.Context, wonderNet *repository.WonderNet, nodeID string) error {
+	services, err := s.ListServices(ctx, wonderNet)
+	if err != nil {
+		return err
+	}
+	serviceIDs, grantIDs := nodeServiceReferences(services, nodeID)
+	if len(serviceIDs) == 0 && len(grantIDs) == 0 {
+		return nil
+	}
+
+	for _, id := range serviceIDs {
+		if err := s.serviceRepository.Delete(ctx, id); err != nil {
+			return err
+		}
+	}
+	for _, id := range grantIDs {
+		if err := s.serviceRepository.DeleteGrant(ctx, id); err != nil {
+			return err
+		}
+	}
+	if err := s.Apply(ctx, wonderNet); err != nil {
+		return fmt.Errorf("apply service policy: %w", err)
+	}
+
+	slog.Info("removed node from services", "wonder_net_id", wonderNet.ID, "node_id", nodeID, "services", len(serviceIDs), "grants", len(grantIDs))
+	return nil
+}
+
+// GrantAccess allows a subject to reach a service. Subjects are "*" (all
+// untagged nodes of the wonder net), "tag:<name>" (nodes of the wonder net
+// advertising that tag), or "node:<id>" (a single node of the wonder net).
+func (s *ServiceCatalogService) GrantAccess(ctx context.Context, wonderNet *repository.WonderNet, serviceID, subject string) (*repository.ServiceGrant, error) {
+	if s.aclManager == nil {
+		return nil, ErrUnsupportedByMeshBackend
+	}
+	if _, err := s.getOwnedService(ctx, wonderNet, serviceID); err != nil {
+		return nil, err
+	}
+	if err := s.validateSubject(ctx, wonderNet, subject); err != nil {
+		return nil, err
+	}
+
+	grants, err := s.serviceRepository.ListGrantsByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	for _, grant := range grants {
+		if grant.ServiceID == serviceID && grant.Subject == subject {
+			return nil, ErrServiceGrantExists
+		}
+	}
+
+	grant, err := s.serviceRepository.CreateGrant(ctx, &repository.ServiceGrant{
+		ID:        uuid.New().String(),
+		ServiceID: serviceID,
+		Subject:   subject,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Apply(ctx, wonderNet); err != nil {
+		if deleteErr := s.serviceRepository.DeleteGrant(ctx, grant.ID); deleteErr != nil {
+			slog.Error("delete service grant after failed apply", "id", grant.ID, "error", deleteErr)
+		}
+		return nil, fmt.Errorf("apply service policy: %w", err)
+	}
+
+	slog.Info("granted service access", "id", grant.ID, "wonder_net_id", wonderNet.ID, "service_id", serviceID, "subject", subject)
+	return grant, nil
+}
+
+// RevokeAccess removes a grant from one of the wonder net's services.
+func (s *ServiceCatalogService) RevokeAccess(ctx context.Context, wonderNet *repository.WonderNet, serviceID, grantID string) error {
+	if _, err := s.getOwnedService(ctx, wonderNet, serviceID); err != nil {
+		return err
+	}
+	grant, err := s.serviceRepository.GetGrant(ctx, grantID)
+	if err != nil {
+		return err
+	}
+	if grant == nil || grant.ServiceID != serviceID {
+		return ErrServiceGrantNotFound
+	}
+
+	if err := s.serviceRepository.DeleteGrant(ctx, grantID); err != nil {
+		return err
+	}
+	if err := s.Apply(ctx, wonderNet); err != nil {
+		return fmt.Errorf("apply service policy: %w", err)
+	}
+
+	slog.Info("revoked service access", "id", grantID, "wonder_net_id", wonderNet.ID, "service_id", grant.ServiceID)
+	return nil
+}
+
+// Apply rebuilds the ACL rules for a wonder net's service grants and merges
+// them into the Headscale policy. Without Headscale there are no rules to
+// apply.
+func (s *ServiceCatalogService) Apply(ctx context.Context, wonderNet *repository.WonderNet) error {
+	if s.aclManager == nil {
+		return nil
+	}
+
+	policy, err := s.buildPolicy(ctx, wonderNet)
+	if err != nil {
+		return err
+	}
+	return s.aclManager.SetWonderNetPolicy(ctx, servicePolicyKey(wonderNet.HeadscaleUser), policy)
+}
+
+// LoadACLPolicies builds the service rules of every wonder net and hands them
+// to the ACL manager without writing the policy, so that the initial policy
+// write at startup already includes them.
+func (s *ServiceCatalogService) LoadACLPolicies(ctx context.Context) error {
+	if s.aclManager == nil {
+		return nil
+	}
+
+	wonderNets, err := s.wonderNetsWithServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	policies := make(map[string]*headscale.ACLPolicy, len(wonderNets))
+	for _, wonderNet := range wonderNets {
+		policy, err := s.buildPolicy(ctx, wonderNet)
+		if err != nil {
+			return fmt.Errorf("build service policy for wonder net %s: %w", wonderNet.ID, err)
+		}
+		if policy != nil {
+			policies[servicePolicyKey(wonderNet.HeadscaleUser)] = policy
+		}
+	}
+
+	s.aclManager.LoadWonderNetPolicies(policies)
+	return nil
+}
+
+// Reconcile re-applies the service rules of every wonder net that has
+// services. Unchanged rules are not written.
+func (s *ServiceCatalogService) Reconcile(ctx context.Context) error {
+	if s.aclManager == nil {
+		return nil
+	}
+
+	wonderNets, err := s.wonderNetsWithServices(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, wonderNet := range wonderNets {
+		if err := s.Apply(ctx, wonderNet); err != nil {
+			slog.Warn("apply service policy", "wonder_net_id", wonderNet.ID, "error", err)
+		}
+	}
+	return nil
+}
+
+// Run reconciles service rules every interval until ctx is cancelled.
+func (s *ServiceCatalogService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Reconcile(ctx); err != nil {
+				slog.Error("reconcile service policies", "error", err)
+			}
+		}
+	}
+}
+
+// buildPolicy returns the ACL fragment for a wonder net's service grants, or
+// nil if there is nothing to allow. Services whose node is gone or has moved
+// to another wonder net, and node subjects that no longer resolve, are
+// skipped so that a reused IP never inherits a grant.
+func (s *ServiceCatalogService) buildPolicy(ctx context.Context, wonderNet *repository.WonderNet) (*headscale.ACLPolicy, error) {
+	services, err := s.ListServices(ctx, wonderNet)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &headscale.ACLPolicy{}
+	for _, svc := range services {
+		if len(svc.Grants) == 0 {
+			continue
+		}
+
+		addresses, err := s.nodeAddresses(ctx, wonderNet, svc.NodeID)
+		if err != nil {
+			slog.Warn("skip service with unresolvable node", "service_id", svc.ID, "node_id", svc.NodeID, "error", err)
+			continue
+		}
+		destinations := make([]string, len(addresses))
+		for i, addr := range addresses {
+			destinations[i] = fmt.Sprintf("%s:%d", addr, svc.Port)
+		}
+
+		for _, grant := range svc.Grants {
+			sources, tag, err := s.resolveSubject(ctx, wonderNet, grant.Subject)
+			if err != nil {
+				slog.Warn("skip unresolvable service grant", "grant_id", grant.ID, "subject", grant.Subject, "error", err)
+				continue
+			}
+			if tag != "" {
+				if policy.TagOwners == nil {
+					policy.TagOwners = make(map[string][]string)
+				}
+				policy.TagOwners[tag] = []string{wonderNet.HeadscaleUser + "@"}
+			}
+			policy.ACLs = append(policy.ACLs, headscale.ACLRule{
+				Action:       "accept",
+				Sources:      sources,
+				Destinations: destinations,
+				Protocol:     svc.Protocol,
+			})
+		}
+	}
+
+	if len(policy.ACLs) == 0 {
+		return nil, nil
+	}
+	return policy, nil
+}
+
+// resolveSubject translates a grant subject into ACL sources. For tag
+// subjects it also returns the scoped tag so that its owner can be declared.
+func (s *ServiceCatalogService) resolveSubject(ctx context.Context, wonderNet *repository.WonderNet, subject string) ([]string, string, error) {
+	if nodeID, ok := strings.CutPrefix(subject, serviceSubjectNodePrefix); ok {
+		addresses, err := s.nodeAddresses(ctx, wonderNet, nodeID)
+		if err != nil {
+			return nil, "", err
+		}
+		return addresses, "", nil
+	}
+
+	selector, isTag, err := headscale.ScopeWonderNetSelector(wonderNet.HeadscaleUser, subject)
+	if err != nil {
+		return nil, "", err
+	}
+	if isTag {
+		return []string{selector}, selector, nil
+	}
+	return []string{selector}, "", nil
+}
+
+func (s *ServiceCatalogService) validateSubject(ctx context.Context, wonderNet *repository.WonderNet, subject string) error {
+	if nodeID, ok := strings.CutPrefix(subject, serviceSubjectNodePrefix); ok {
+		return s.checkNodeInWonderNet(ctx, wonderNet, nodeID)
+	}
+	if _, _, err := headscale.ScopeWonderNetSelector(wonderNet.HeadscaleUser, subject); err != nil {
+		return fmt.Errorf("%w: subject must be *, tag:<name>, or node:<id>", ErrInvalidService)
+	}
+	return nil
+}
+
+func (s *ServiceCatalogService) checkNodeInWonderNet(ctx context.Context, wonderNet *repository.WonderNet, nodeID string) error {
+	if _, err := s.nodeAddresses(ctx, wonderNet, nodeID); err != nil {
+		return fmt.Errorf("%w: node %s not found in this wonder net", ErrInvalidService, nodeID)
+	}
+	return nil
+}
+
+// nodeAddresses returns the mesh IPs of a node, failing if the node does not
+// belong to the wonder net.
+func (s *ServiceCatalogService) nodeAddresses(ctx context.Context, wonderNet *repository.WonderNet, nodeID string) ([]string, error) {
+	node, err := s.meshBackend.GetNode(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	if node.Realm != wonderNet.HeadscaleUser {
+		return nil, fmt.Errorf("node %s belongs to another wonder net", nodeID)
+	}
+	if len(node.Addresses) == 0 {
+		return nil, fmt.Errorf("node %s has no addresses", nodeID)
+	}
+	return node.Addresses, nil
+}
+
+func (s *ServiceCatalogService) getOwnedService(ctx context.Context, wonderNet *repository.WonderNet, serviceID string) (*repository.Service, error) {
+	svc, err := s.serviceRepository.Get(ctx, serviceID)
+	if err != nil {
+		return nil, err
+	}
+	if svc == nil || svc.WonderNetID != wonderNet.ID {
+		return nil, ErrServiceNotFound
+	}
+	return svc, nil
+}
+
+func (s *ServiceCatalogService) wonderNetsWithServices(ctx context.Context) ([]*repository.WonderNet, error) {
+	services, err := s.serviceRepository.List(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+
+	var wonderNets []*repository.WonderNet
+	seen := make(map[string]bool)
+	for _, svc := range services {
+		if seen[svc.WonderNetID] {
+			continue
+		}
+		seen[svc.WonderNetID] = true
+
+		wonderNet, err := s.wonderNetRepo.Get(ctx, svc.WonderNetID)
+		if err != nil {
+			return nil, fmt.Errorf("get wonder net: %w", err)
+		}
+		if wonderNet != nil {
+			wonderNets = append(wonderNets, wonderNet)
+		}
+	}
+	return wonderNets, nil
+}
+
+// nodeServiceReferences returns the services hosted on a node and the grants
+// of other services that name the node as subject. Grants of the node's own
+// services are left out, as they go away with the service.
+func nodeServiceReferences(services []*ServiceWithGrants, nodeID string) (serviceIDs, grantIDs []string) {
+	subject := serviceSubjectNodePrefix + nodeID
+	for _, svc := range services {
+		if svc.NodeID == nodeID {
+			serviceIDs = append(serviceIDs, svc.ID)
+			continue
+		}
+		for _, grant := range svc.Grants {
+			if grant.Subject == subject {
+				grantIDs = append(grantIDs, grant.ID)
+			}
+		}
+	}
+	return serviceIDs, grantIDs
+}
+
+// validateServiceSpec checks a service's name, port, and protocol and
+// returns the protocol with the tcp default applied.
+func validateServiceSpec(name string, port int, protocol string) (string, error) {
+	if !serviceNamePattern.MatchString(name) {
+		return "", fmt.Errorf("%w: name must be a lowercase DNS label", ErrInvalidService)
+	}
+	if port < 1 || port > 65535 {
+		return "", fmt.Errorf("%w: port must be between 1 and 65535", ErrInvalidService)
+	}
+	if protocol == "" {
+		protocol = "tcp"
+	}
+	if protocol != "tcp" && protocol != "udp" {
+		return "", fmt.Errorf("%w: protocol must be tcp or udp", ErrInvalidService)
+	}
+	return protocol, nil
+}
+
+// servicePolicyKey is the ACL manager key of a wonder net's service rules.
+func servicePolicyKey(headscaleUser string) string {
+	return headscaleUser + servicePolicyKeySuffix
+}
@@ -0,0 +1,83 @@
+package service
+
+import (
+	"errors"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestValidateServiceSpec(t *testing.T) {
+	tests := []struct {
+		name        string
+		serviceName string
+		port        int
+		protocol    string
+		expected    string
+		wantErr     bool
+	}{
+		{"defaults to tcp", "postgres", 5432, "", "tcp", false},
+		{"udp", "dns", 53, "udp", "udp", false},
+		{"hyphenated name", "web-api", 8080, "tcp", "tcp", false},
+		{"uppercase name", "Postgres", 5432, "", "", true},
+		{"leading hyphen", "-web", 80, "", "", true},
+		{"name too long", strings.Repeat("a", 64), 80, "", "", true},
+		{"empty name", "", 80, "", "", true},
+		{"port zero", "web", 0, "", "", true},
+		{"port too high", "web", 65536, "", "", true},
+		{"unknown protocol", "web", 80, "sctp", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			protocol, err := validateServiceSpec(tt.serviceName, tt.port, tt.protocol)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("validateServiceSpec() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				if !errors.Is(err, ErrInvalidService) {
+					t.Errorf("error = %v, want %v", err, ErrInvalidService)
+				}
+				return
+			}
+			if protocol != tt.expected {
+				t.Errorf("validateServiceSpec() = %q, want %q", protocol, tt.expected)
+			}
+		})
+	}
+}
+
+func TestNodeServiceReferences(t *testing.T) {
+	services := []*ServiceWithGrants{
+		{
+			Service: &repository.Service{ID: "svc-db", NodeID: "1"},
+			Grants: []*repository.ServiceGrant{
+				{ID: "g1", ServiceID: "svc-db", Subject: "node:2"},
+				{ID: "g2", ServiceID: "svc-db", Subject: "node:1"},
+			},
+		},
+		{
+			Service: &repository.Service{ID: "svc-web", NodeID: "2"},
+			Grants: []*repository.ServiceGrant{
+				{ID: "g3", ServiceID: "svc-web", Subject: "node:1"},
+				{ID: "g4", ServiceID: "svc-web", Subject: "node:10"},
+				{ID: "g5", ServiceID: "svc-web", Subject: "*"},
+			},
+		},
+	}
+
+	serviceIDs, grantIDs := nodeServiceReferences(services, "1")
+	if !slices.Equal(serviceIDs, []string{"svc-db"}) {
+		t.Errorf("serviceIDs = %v, want [svc-db]", serviceIDs)
+	}
+	if !slices.Equal(grantIDs, []string{"g3"}) {
+		t.Errorf("grantIDs = %v, want [g3]", grantIDs)
+	}
+
+	serviceIDs, grantIDs = nodeServiceReferences(services, "3")
+	if len(serviceIDs) != 0 || len(grantIDs) != 0 {
+		t.Errorf("unreferenced node: serviceIDs = %v, grantIDs = %v, want none", serviceIDs, grantIDs)
+	}
+}
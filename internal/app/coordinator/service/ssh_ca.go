@@ -0,0 +1,160 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"golang.org/x/crypto/ssh"
+)
+
+// ErrInvalidSSHCertRequest is returned when a certificate request has no
+// valid public key, principals, or lifetime.
+var ErrInvalidSSHCertRequest = errors.New("invalid ssh certificate request")
+
+// DefaultSSHCertTTL is how long an SSH certificate is valid unless the
+// request asks otherwise, and MaxSSHCertTTL the longest it may be.
+const (
+	DefaultSSHCertTTL = time.Hour
+	MaxSSHCertTTL     = 24 * time.Hour
+)
+
+// sshCertClockSkew backdates certificates so that workers whose clocks run
+// a little behind accept them right away.
+const sshCertClockSkew = time.Minute
+
+// maxSSHCertPrincipals bounds the principals of one certificate.
+const maxSSHCertPrincipals = 16
+
+// sshPrincipalPattern matches the login names a certificate may be valid
+// for, as useradd accepts them.
+var sshPrincipalPattern = regexp.MustCompile(`^[a-z_][a-z0-9_.-]{0,31}$`)
+
+// SSHCertRequest asks for a certificate of PublicKey, an authorized_keys
+// line, to log in to the wonder net's nodes as one of Principals. A zero
+// TTL uses DefaultSSHCertTTL.
+type SSHCertRequest struct {
+	PublicKey  string
+	Principals []string
+	TTL        time.Duration
+}
+
+// SSHCertificate is a signed SSH user certificate.
+type SSHCertificate struct {
+	// Certificate is the certificate as an authorized_keys line, the
+	// format of id_ed25519-cert.pub files.
+	Certificate string
+	Serial      uint64
+	KeyID       string
+	Principals  []string
+	ValidAfter  time.Time
+	ValidBefore time.Time
+}
+
+// SSHCAService is the SSH certificate authority of the wonder nets. Each
+// wonder net has its own CA key, so a certificate only opens the nodes of
+// the wonder net it was issued for. Keys are derived from the JWT secret,
+// so they stay the same across restarts and replicas sharing the secret.
+type SSHCAService struct {
+	jwtSecret string
+	now       func() time.Time
+}
+
+// NewSSHCAService creates a new SSHCAService.
+func NewSSHCAService(jwtSecret string) *SSHCAService {
+	return &SSHCAService{jwtSecret: jwtSecret, now: time.Now}
+}
+
+// PublicKey returns the CA key of the wonder net as an authorized_keys
+// line, for sshd's TrustedUserCAKeys.
+func (s *SSHCAService) PublicKey(wonderNetID string) (string, error) {
+	signer, err := s.signer(wonderNetID)
+	if err != nil {
+		return "", err
+	}
+	line := strings.TrimSpace(string(ssh.MarshalAuthorizedKey(signer.PublicKey())))
+	return line + " wonder-mesh-net-ca@" + wonderNetID, nil
+}
+
+// SignUserCertificate signs a user certificate for the nodes of the wonder
+// net. keyID names who asked for it; sshd logs it with every login.
+func (s *SSHCAService) SignUserCertificate(wonderNet *repository.WonderNet, req SSHCertRequest, keyID string) (*SSHCertificate, error) {
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+	if err != nil {
+		return nil, fmt.Errorf("%w: public key: %v", ErrInvalidSSHCertRequest, err)
+	}
+	if _, ok := pub.(*ssh.Certificate); ok {
+		return nil, fmt.Errorf("%w: public key is a certificate", ErrInvalidSSHCertRequest)
+	}
+	if len(req.Principals) == 0 || len(req.Principals) > maxSSHCertPrincipals {
+		return nil, fmt.Errorf("%w: between 1 and %d principals are required", ErrInvalidSSHCertRequest, maxSSHCertPrincipals)
+	}
+	for _, p := range req.Principals {
+		if !sshPrincipalPattern.MatchString(p) {
+			return nil, fmt.Errorf("%w: principal %q is not a valid user name", ErrInvalidSSHCertRequest, p)
+		}
+	}
+	ttl := req.TTL
+	if ttl == 0 {
+		ttl = DefaultSSHCertTTL
+	}
+	if ttl < 0 || ttl > MaxSSHCertTTL {
+		return nil, fmt.Errorf("%w: ttl must be between 0s and %s", ErrInvalidSSHCertRequest, MaxSSHCertTTL)
+	}
+
+	signer, err := s.signer(wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
+	var serial [8]byte
+	if _, err := rand.Read(serial[:]); err != nil {
+		return nil, fmt.Errorf("generate serial: %w", err)
+	}
+	now := s.now().Truncate(time.Second)
+	cert := &ssh.Certificate{
+		Key:             pub,
+		Serial:          binary.BigEndian.Uint64(serial[:]),
+		CertType:        ssh.UserCert,
+		KeyId:           keyID,
+		ValidPrincipals: req.Principals,
+		ValidAfter:      uint64(now.Add(-sshCertClockSkew).Unix()),
+		ValidBefore:     uint64(now.Add(ttl).Unix()),
+		Permissions: ssh.Permissions{
+			Extensions: map[string]string{
+				"permit-pty":             "",
+				"permit-port-forwarding": "",
+			},
+		},
+	}
+	if err := cert.SignCert(rand.Reader, signer); err != nil {
+		return nil, fmt.Errorf("sign certificate: %w", err)
+	}
+
+	return &SSHCertificate{
+		Certificate: strings.TrimSpace(string(ssh.MarshalAuthorizedKey(cert))),
+		Serial:      cert.Serial,
+		KeyID:       keyID,
+		Principals:  req.Principals,
+		ValidAfter:  time.Unix(int64(cert.ValidAfter), 0),
+		ValidBefore: time.Unix(int64(cert.ValidBefore), 0),
+	}, nil
+}
+
+// signer returns the CA key of the wonder net.
+func (s *SSHCAService) signer(wonderNetID string) (ssh.Signer, error) {
+	mac := hmac.New(sha256.New, []byte(s.jwtSecret))
+	mac.Write([]byte("wonder-mesh-net ssh ca key " + wonderNetID))
+	signer, err := ssh.NewSignerFromKey(ed25519.NewKeyFromSeed(mac.Sum(nil)))
+	if err != nil {
+		return nil, fmt.Errorf("create ssh ca signer: %w", err)
+	}
+	return signer, nil
+}
@@ -0,0 +1,123 @@
+package service
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"golang.org/x/crypto/ssh"
+)
+
+// trustingCA returns a CertChecker that, like sshd with TrustedUserCAKeys,
+// accepts user certificates of the given CA key.
+func trustingCA(t *testing.T, caLine string) *ssh.CertChecker {
+	t.Helper()
+	ca, _, _, _, err := ssh.ParseAuthorizedKey([]byte(caLine))
+	if err != nil {
+		t.Fatalf("parse CA key %q: %v", caLine, err)
+	}
+	return &ssh.CertChecker{
+		IsUserAuthority: func(auth ssh.PublicKey) bool {
+			return string(auth.Marshal()) == string(ca.Marshal())
+		},
+	}
+}
+
+func TestSSHCAService_SignUserCertificate(t *testing.T) {
+	s := NewSSHCAService("0123456789abcdef0123456789abcdef")
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	s.now = func() time.Time { return now }
+
+	pub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	sshPub, err := ssh.NewPublicKey(pub)
+	if err != nil {
+		t.Fatalf("ssh public key: %v", err)
+	}
+	wonderNet := &repository.WonderNet{ID: "wn-1"}
+
+	cert, err := s.SignUserCertificate(wonderNet, SSHCertRequest{
+		PublicKey:  string(ssh.MarshalAuthorizedKey(sshPub)),
+		Principals: []string{"root", "deploy"},
+	}, "alice@example.com")
+	if err != nil {
+		t.Fatalf("SignUserCertificate() error = %v", err)
+	}
+	if !cert.ValidBefore.Equal(now.Add(DefaultSSHCertTTL)) || !cert.ValidAfter.Before(now) {
+		t.Errorf("validity = %s to %s, want from before %s for %s", cert.ValidAfter, cert.ValidBefore, now, DefaultSSHCertTTL)
+	}
+
+	parsed, _, _, _, err := ssh.ParseAuthorizedKey([]byte(cert.Certificate))
+	if err != nil {
+		t.Fatalf("parse certificate: %v", err)
+	}
+	sshCert := parsed.(*ssh.Certificate)
+	if sshCert.KeyId != "alice@example.com" {
+		t.Errorf("KeyId = %q, want alice@example.com", sshCert.KeyId)
+	}
+
+	caKey, err := s.PublicKey("wn-1")
+	if err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+	checker := trustingCA(t, caKey)
+	checker.Clock = func() time.Time { return now }
+	if err := checker.CheckCert("deploy", sshCert); err != nil {
+		t.Errorf("CheckCert(deploy) error = %v", err)
+	}
+	if err := checker.CheckCert("admin", sshCert); err == nil {
+		t.Error("CheckCert(admin) error = nil for a principal the certificate is not valid for")
+	}
+	if _, err := checker.Authenticate(fakeConnMetadata{user: "root"}, sshCert); err != nil {
+		t.Errorf("Authenticate(root) error = %v", err)
+	}
+
+	otherKey, err := s.PublicKey("wn-2")
+	if err != nil {
+		t.Fatalf("PublicKey() error = %v", err)
+	}
+	other := trustingCA(t, otherKey)
+	other.Clock = checker.Clock
+	if _, err := other.Authenticate(fakeConnMetadata{user: "root"}, sshCert); err == nil {
+		t.Error("a node of another wonder net accepted the certificate")
+	}
+}
+
+func TestSSHCAService_InvalidRequests(t *testing.T) {
+	s := NewSSHCAService("0123456789abcdef0123456789abcdef")
+	pub, _, _ := ed25519.GenerateKey(rand.Reader)
+	sshPub, _ := ssh.NewPublicKey(pub)
+	key := string(ssh.MarshalAuthorizedKey(sshPub))
+
+	for _, tc := range []struct {
+		name string
+		req  SSHCertRequest
+	}{
+		{"no key", SSHCertRequest{Principals: []string{"root"}}},
+		{"no principals", SSHCertRequest{PublicKey: key}},
+		{"bad principal", SSHCertRequest{PublicKey: key, Principals: []string{"root,admin"}}},
+		{"too long", SSHCertRequest{PublicKey: key, Principals: []string{"root"}, TTL: MaxSSHCertTTL + time.Second}},
+	} {
+		_, err := s.SignUserCertificate(&repository.WonderNet{ID: "wn-1"}, tc.req, "test")
+		if !errors.Is(err, ErrInvalidSSHCertRequest) {
+			t.Errorf("%s: error = %v, want ErrInvalidSSHCertRequest", tc.name, err)
+		}
+	}
+}
+
+// fakeConnMetadata is the metadata of an SSH login as user.
+type fakeConnMetadata struct {
+	ssh.ConnMetadata
+	user string
+}
+
+func (m fakeConnMetadata) User() string          { return m.user }
+func (m fakeConnMetadata) RemoteAddr() net.Addr  { return &net.TCPAddr{IP: net.IPv4(100, 64, 0, 1)} }
+func (m fakeConnMetadata) SessionID() []byte     { return nil }
+func (m fakeConnMetadata) ClientVersion() []byte { return nil }
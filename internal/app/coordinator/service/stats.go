@@ -0,0 +1,113 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// recentNodeWindow is how far back a node's last contact counts as recent.
+const recentNodeWindow = 24 * time.Hour
+
+// WonderNetStats are aggregate counts for a wonder net's summary view.
+type WonderNetStats struct {
+	Nodes                 int
+	OnlineNodes           int
+	RecentNodes           int
+	APIKeys               int
+	StaleAPIKeys          int
+	Services              int
+	PendingAccessRequests int
+	ActiveAccessRequests  int
+	FiringAlerts          int
+}
+
+// StatsService aggregates counts from the other services so that a summary
+// view can be painted with one request.
+type StatsService struct {
+	nodesService            *NodesService
+	alertService            *AlertService
+	apiKeyRepository        *repository.APIKeyRepository
+	serviceRepository       *repository.ServiceRepository
+	accessRequestRepository *repository.AccessRequestRepository
+}
+
+// NewStatsService creates a new StatsService.
+func NewStatsService(
+	nodesService *NodesService,
+	alertService *AlertService,
+	apiKeyRepository *repository.APIKeyRepository,
+	serviceRepository *repository.ServiceRepository,
+	accessRequestRepository *repository.AccessRequestRepository,
+) *StatsService {
+	return &StatsService{
+		nodesService:            nodesService,
+		alertService:            alertService,
+		apiKeyRepository:        apiKeyRepository,
+		serviceRepository:       serviceRepository,
+		accessRequestRepository: accessRequestRepository,
+	}
+}
+
+// GetStats returns the aggregate counts for a wonder net.
+func (s *StatsService) GetStats(ctx context.Context, wonderNet *repository.WonderNet) (*WonderNetStats, error) {
+	now := time.Now()
+	stats := &WonderNetStats{}
+
+	nodes, err := s.nodesService.ListNodes(ctx, wonderNet)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	countNodes(stats, nodes, now)
+
+	keys, err := s.apiKeyRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	for _, key := range keys {
+		if key.ExpiresAt == nil || key.ExpiresAt.After(now) {
+			stats.APIKeys++
+			if isStaleAPIKey(key, now) {
+				stats.StaleAPIKeys++
+			}
+		}
+	}
+
+	services, err := s.serviceRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list services: %w", err)
+	}
+	stats.Services = len(services)
+
+	requests, err := s.accessRequestRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list access requests: %w", err)
+	}
+	for _, req := range requests {
+		switch req.Status {
+		case repository.AccessRequestPending:
+			stats.PendingAccessRequests++
+		case repository.AccessRequestApproved:
+			stats.ActiveAccessRequests++
+		}
+	}
+
+	stats.FiringAlerts = len(s.alertService.ListAlerts(wonderNet.ID))
+	return stats, nil
+}
+
+// countNodes fills in the node counts of stats.
+func countNodes(stats *WonderNetStats, nodes []*Node, now time.Time) {
+	recent := NodeFilter{LastSeenWithin: recentNodeWindow}
+	stats.Nodes = len(nodes)
+	for _, node := range nodes {
+		if node.Online {
+			stats.OnlineNodes++
+		}
+		if recent.Match(node, now) {
+			stats.RecentNodes++
+		}
+	}
+}
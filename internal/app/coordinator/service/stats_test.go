@@ -0,0 +1,27 @@
+package service
+
+import (
+	"testing"
+	"time"
+)
+
+func TestCountNodes(t *testing.T) {
+	now := time.Now()
+	hourAgo := now.Add(-time.Hour)
+	weekAgo := now.Add(-7 * 24 * time.Hour)
+
+	nodes := []*Node{
+		{ID: 1, Online: true},
+		{ID: 2, Online: false, LastSeen: &hourAgo},
+		{ID: 3, Online: false, LastSeen: &weekAgo},
+		{ID: 4, Online: false},
+	}
+
+	var stats WonderNetStats
+	countNodes(&stats, nodes, now)
+
+	expected := WonderNetStats{Nodes: 4, OnlineNodes: 1, RecentNodes: 2}
+	if stats != expected {
+		t.Errorf("countNodes() = %+v, want %+v", stats, expected)
+	}
+}
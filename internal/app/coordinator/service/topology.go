@@ -0,0 +1,187 @@
+package service
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+const (
+	// maxConnectivityPeers and maxConnectivityDERPRegions bound how much
+	// of a worker's connectivity report is stored.
+	maxConnectivityPeers       = 1024
+	maxConnectivityDERPRegions = 256
+)
+
+// NodeConnectivity is how a node reaches the mesh, as its worker reads it
+// from the local Tailscale client. Headscale's API exposes neither DERP
+// nor path information, so it is only known for nodes with a worker.
+//
+// DERPRegion is the region code of the node's home DERP server, and
+// DERPLatencyMs the latency to each DERP region the client measured, keyed
+// by region ID.
+type NodeConnectivity struct {
+	DERPRegion    string             `json:"derp_region,omitempty"`
+	DERPLatencyMs map[string]float64 `json:"derp_latency_ms,omitempty"`
+	Peers         []PeerConnection   `json:"peers,omitempty"`
+}
+
+// PeerConnection is the path from a node to one of its peers, identified
+// by the peer's mesh addresses. Direct is set when traffic flows over
+// Endpoint, a UDP address of the peer; otherwise it is relayed through the
+// DERP region Relay.
+type PeerConnection struct {
+	Addresses []string `json:"addresses"`
+	Direct    bool     `json:"direct"`
+	Relay     string   `json:"relay,omitempty"`
+	Endpoint  string   `json:"endpoint,omitempty"`
+}
+
+// NodeTopology is a node of a wonder net with the connectivity its worker
+// last reported. RelayBound is set when the node has peers but reaches
+// none of them directly. Nodes without a report have a nil ReportedAt and
+// no connectivity.
+type NodeTopology struct {
+	ID            uint64
+	Name          string
+	Online        bool
+	DERPRegion    string
+	DERPLatencyMs map[string]float64
+	Peers         []TopologyPeer
+	RelayBound    bool
+	ReportedAt    *time.Time
+}
+
+// TopologyPeer is the path from a node to another node of the wonder net.
+type TopologyPeer struct {
+	ID       uint64
+	Name     string
+	Direct   bool
+	Relay    string
+	Endpoint string
+}
+
+// encodeConnectivity returns the connectivity to store with a heartbeat,
+// with its peers and DERP latencies cut to the stored maximum, or an empty
+// string if the worker did not report any.
+func encodeConnectivity(c *NodeConnectivity) (string, error) {
+	if c == nil {
+		return "", nil
+	}
+	stored := *c
+	if len(stored.Peers) > maxConnectivityPeers {
+		stored.Peers = stored.Peers[:maxConnectivityPeers]
+	}
+	if len(stored.DERPLatencyMs) > maxConnectivityDERPRegions {
+		regions := make([]string, 0, len(stored.DERPLatencyMs))
+		for region := range stored.DERPLatencyMs {
+			regions = append(regions, region)
+		}
+		slices.SortFunc(regions, func(a, b string) int {
+			return cmp.Compare(stored.DERPLatencyMs[a], stored.DERPLatencyMs[b])
+		})
+		stored.DERPLatencyMs = make(map[string]float64, maxConnectivityDERPRegions)
+		for _, region := range regions[:maxConnectivityDERPRegions] {
+			stored.DERPLatencyMs[region] = c.DERPLatencyMs[region]
+		}
+	}
+	data, err := json.Marshal(stored)
+	if err != nil {
+		return "", fmt.Errorf("encode connectivity: %w", err)
+	}
+	return string(data), nil
+}
+
+// Topology returns every node of the wonder net with the connectivity its
+// worker last reported, in ID order. Peers are resolved to the nodes of
+// the wonder net by their mesh addresses; peers outside it are left out.
+func (s *NodesService) Topology(ctx context.Context, wonderNet *repository.WonderNet) ([]*NodeTopology, error) {
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return nil, err
+	}
+	heartbeats, err := s.nodeHeartbeatRepository.ListByWonderNet(ctx, wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list node heartbeats: %w", err)
+	}
+	return buildTopology(nodes, heartbeats), nil
+}
+
+// buildTopology joins the nodes of a wonder net with the connectivity in
+// their heartbeats.
+func buildTopology(nodes []*meshbackend.Node, heartbeats []*repository.NodeHeartbeat) []*NodeTopology {
+	heartbeatByNode := make(map[string]*repository.NodeHeartbeat, len(heartbeats))
+	for _, hb := range heartbeats {
+		heartbeatByNode[hb.NodeID] = hb
+	}
+
+	topology := make([]*NodeTopology, len(nodes))
+	byAddress := make(map[string]*NodeTopology)
+	for i, node := range nodes {
+		t := &NodeTopology{Name: node.Name, Online: node.Online}
+		if id, err := strconv.ParseUint(node.ID, 10, 64); err == nil {
+			t.ID = id
+		}
+		for _, addr := range node.Addresses {
+			byAddress[addr] = t
+		}
+		topology[i] = t
+	}
+
+	for i, node := range nodes {
+		hb := heartbeatByNode[node.ID]
+		if hb == nil || hb.Connectivity == "" {
+			continue
+		}
+		var c NodeConnectivity
+		if err := json.Unmarshal([]byte(hb.Connectivity), &c); err != nil {
+			slog.Warn("decode node connectivity", "node_id", node.ID, "error", err)
+			continue
+		}
+
+		t := topology[i]
+		reportedAt := hb.ReportedAt
+		t.ReportedAt = &reportedAt
+		t.DERPRegion = c.DERPRegion
+		t.DERPLatencyMs = c.DERPLatencyMs
+		direct := false
+		for _, p := range c.Peers {
+			peer := findTopologyNode(byAddress, p.Addresses)
+			if peer == nil || peer == t {
+				continue
+			}
+			t.Peers = append(t.Peers, TopologyPeer{
+				ID:       peer.ID,
+				Name:     peer.Name,
+				Direct:   p.Direct,
+				Relay:    p.Relay,
+				Endpoint: p.Endpoint,
+			})
+			direct = direct || p.Direct
+		}
+		t.RelayBound = len(t.Peers) > 0 && !direct
+	}
+
+	slices.SortFunc(topology, func(a, b *NodeTopology) int {
+		return cmp.Compare(a.ID, b.ID)
+	})
+	return topology
+}
+
+// findTopologyNode returns the node that has the first known address.
+func findTopologyNode(byAddress map[string]*NodeTopology, addresses []string) *NodeTopology {
+	for _, addr := range addresses {
+		if t, ok := byAddress[addr]; ok {
+			return t
+		}
+	}
+	return nil
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+func TestBuildTopology(t *testing.T) {
+	nodes := []*meshbackend.Node{
+		{ID: "3", Name: "relayed", Online: true, Addresses: []string{"100.64.0.3"}},
+		{ID: "1", Name: "gateway", Online: true, Addresses: []string{"100.64.0.1", "fd7a:115c:a1e0::1"}},
+		{ID: "2", Name: "silent", Addresses: []string{"100.64.0.2"}},
+	}
+	connectivity := func(c NodeConnectivity) string {
+		data, err := json.Marshal(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return string(data)
+	}
+	reportedAt := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	heartbeats := []*repository.NodeHeartbeat{
+		{NodeID: "1", ReportedAt: reportedAt, Connectivity: connectivity(NodeConnectivity{
+			DERPRegion:    "fra",
+			DERPLatencyMs: map[string]float64{"4": 12.5},
+			Peers: []PeerConnection{
+				{Addresses: []string{"100.64.0.3"}, Direct: true, Endpoint: "203.0.113.7:41641"},
+				{Addresses: []string{"100.64.9.9"}, Direct: true},
+			},
+		})},
+		{NodeID: "3", ReportedAt: reportedAt, Connectivity: connectivity(NodeConnectivity{
+			DERPRegion: "nyc",
+			Peers: []PeerConnection{
+				{Addresses: []string{"fd7a:115c:a1e0::1"}, Relay: "nyc"},
+			},
+		})},
+		{NodeID: "2", ReportedAt: reportedAt},
+	}
+
+	topology := buildTopology(nodes, heartbeats)
+	if len(topology) != 3 {
+		t.Fatalf("buildTopology() returned %d nodes, want 3", len(topology))
+	}
+	for i, node := range topology {
+		if want := uint64(i + 1); node.ID != want {
+			t.Errorf("topology[%d].ID = %d, want %d", i, node.ID, want)
+		}
+	}
+
+	gateway := topology[0]
+	if gateway.DERPRegion != "fra" || gateway.DERPLatencyMs["4"] != 12.5 || gateway.ReportedAt == nil {
+		t.Errorf("gateway = %+v, want its reported DERP region and latencies", gateway)
+	}
+	if len(gateway.Peers) != 1 || gateway.Peers[0].Name != "relayed" || !gateway.Peers[0].Direct {
+		t.Errorf("gateway.Peers = %+v, want a direct path to relayed only", gateway.Peers)
+	}
+	if gateway.RelayBound {
+		t.Error("gateway.RelayBound = true, want false")
+	}
+
+	if silent := topology[1]; silent.ReportedAt != nil || silent.Peers != nil {
+		t.Errorf("silent = %+v, want no connectivity", silent)
+	}
+
+	relayed := topology[2]
+	if len(relayed.Peers) != 1 || relayed.Peers[0].ID != 1 || relayed.Peers[0].Relay != "nyc" {
+		t.Errorf("relayed.Peers = %+v, want a path to gateway through nyc", relayed.Peers)
+	}
+	if !relayed.RelayBound {
+		t.Error("relayed.RelayBound = false, want true")
+	}
+}
+
+func TestEncodeConnectivityBoundsReport(t *testing.T) {
+	if got, err := encodeConnectivity(nil); got != "" || err != nil {
+		t.Errorf("encodeConnectivity(nil) = %q, %v, want empty", got, err)
+	}
+
+	report := &NodeConnectivity{
+		DERPLatencyMs: make(map[string]float64),
+		Peers:         make([]PeerConnection, maxConnectivityPeers+10),
+	}
+	for i := range maxConnectivityDERPRegions + 10 {
+		report.DERPLatencyMs[strconv.Itoa(i)] = float64(i)
+	}
+
+	data, err := encodeConnectivity(report)
+	if err != nil {
+		t.Fatalf("encodeConnectivity() error = %v", err)
+	}
+	var stored NodeConnectivity
+	if err := json.Unmarshal([]byte(data), &stored); err != nil {
+		t.Fatal(err)
+	}
+	if len(stored.Peers) != maxConnectivityPeers {
+		t.Errorf("stored %d peers, want %d", len(stored.Peers), maxConnectivityPeers)
+	}
+	if len(stored.DERPLatencyMs) != maxConnectivityDERPRegions {
+		t.Errorf("stored %d DERP latencies, want %d", len(stored.DERPLatencyMs), maxConnectivityDERPRegions)
+	}
+	if _, ok := stored.DERPLatencyMs[strconv.Itoa(maxConnectivityDERPRegions)]; ok {
+		t.Error("kept a slower DERP region over a faster one")
+	}
+	if len(report.Peers) != maxConnectivityPeers+10 {
+		t.Error("encodeConnectivity() modified the report")
+	}
+}
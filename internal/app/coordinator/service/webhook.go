@@ -0,0 +1,524 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+// Webhook event types.
+const (
+	// WebhookEventNodeJoined fires when a node appears in the wonder net.
+	WebhookEventNodeJoined = "node.joined"
+	// WebhookEventNodeOffline fires once a node has been offline for the
+	// coordinator's node offline threshold.
+	WebhookEventNodeOffline = "node.offline"
+	// WebhookEventNodeAuthorized fires when an owner approves a node held
+	// for node approval.
+	WebhookEventNodeAuthorized = "node.authorized"
+	// WebhookEventNodeExpired fires when a node is removed for having been
+	// offline for longer than the wonder net's node expiry.
+	WebhookEventNodeExpired = "node.expired"
+	// WebhookEventTokenCreated fires when a join token is issued.
+	WebhookEventTokenCreated = "token.created"
+	// WebhookEventAlertFiring fires when an alert rule starts firing for a node.
+	WebhookEventAlertFiring = "alert.firing"
+	// WebhookEventAlertResolved fires when a firing alert resolves.
+	WebhookEventAlertResolved = "alert.resolved"
+)
+
+// WebhookEvents lists the events a webhook can subscribe to.
+var WebhookEvents = []string{
+	WebhookEventNodeJoined,
+	WebhookEventNodeOffline,
+	WebhookEventNodeAuthorized,
+	WebhookEventNodeExpired,
+	WebhookEventTokenCreated,
+	WebhookEventAlertFiring,
+	WebhookEventAlertResolved,
+}
+
+// Webhook delivery headers.
+const (
+	WebhookEventHeader     = "X-Wonder-Event"
+	WebhookDeliveryHeader  = "X-Wonder-Delivery"
+	WebhookSignatureHeader = "X-Wonder-Signature-256"
+)
+
+const (
+	// webhookMaxAttempts is how often a delivery is tried before it fails.
+	webhookMaxAttempts = 8
+	// webhookInitialBackoff is the wait after the first failed attempt; it
+	// doubles after each further failure up to webhookMaxBackoff.
+	webhookInitialBackoff = 30 * time.Second
+	webhookMaxBackoff     = time.Hour
+	// webhookTimeout bounds a single delivery attempt.
+	webhookTimeout = 10 * time.Second
+	// webhookSecretLength is the length of the random part of a signing secret.
+	webhookSecretLength = 32
+	// webhookWorkers bounds the webhooks delivered to at the same time.
+	webhookWorkers = 8
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrInvalidWebhookURL       = errors.New("webhook URL must be an absolute https URL of a public host")
+	ErrUnsupportedWebhookEvent = errors.New("unsupported webhook event")
+)
+
+// WebhookPayload is the JSON body POSTed to a webhook.
+type WebhookPayload struct {
+	ID          string    `json:"id"`
+	Event       string    `json:"event"`
+	WonderNetID string    `json:"wonder_net_id"`
+	CreatedAt   time.Time `json:"created_at"`
+	Data        any       `json:"data"`
+}
+
+// WebhookNode is the data of node events.
+type WebhookNode struct {
+	ID        uint64     `json:"id"`
+	Name      string     `json:"name"`
+	Addresses []string   `json:"ip_addresses"`
+	Online    bool       `json:"online"`
+	LastSeen  *time.Time `json:"last_seen,omitempty"`
+}
+
+// WebhookJoinToken is the data of token.created events. It never carries
+// the token itself.
+type WebhookJoinToken struct {
+	JTI       string    `json:"jti"`
+	CreatedBy string    `json:"created_by,omitempty"`
+	MaxUses   int       `json:"max_uses"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// WebhookAlert is the data of alert events.
+type WebhookAlert struct {
+	RuleID   string    `json:"rule_id"`
+	RuleName string    `json:"rule_name,omitempty"`
+	Kind     string    `json:"kind"`
+	NodeID   uint64    `json:"node_id"`
+	NodeName string    `json:"node_name"`
+	StartsAt time.Time `json:"starts_at"`
+}
+
+// WebhookService manages webhooks and delivers wonder net events to them.
+// Events are queued as deliveries in the database and sent in the
+// background, so a slow or failing endpoint never blocks the API and
+// deliveries survive restarts. Node events come from the NodeWatcher.
+//
+// Webhook URLs are chosen by tenants, so deliveries only go to public
+// addresses and do not follow redirects; see newOutboundClient.
+//
+// Each webhook is delivered to by one worker at a time, and up to
+// webhookWorkers webhooks at once, so a slow endpoint only holds up its
+// own deliveries.
+type WebhookService struct {
+	webhookRepository *repository.WebhookRepository
+	client            *http.Client
+	allowHTTP         bool
+
+	workers chan struct{}
+	wg      sync.WaitGroup
+	mu      sync.Mutex
+	// busy holds the webhooks a worker is delivering to.
+	busy map[string]bool
+}
+
+// NewWebhookService creates a new WebhookService. Webhook URLs must use
+// https unless allowHTTP is set.
+func NewWebhookService(webhookRepository *repository.WebhookRepository, allowHTTP bool) *WebhookService {
+	return &WebhookService{
+		webhookRepository: webhookRepository,
+		client:            newOutboundClient(webhookTimeout),
+		allowHTTP:         allowHTTP,
+		workers:           make(chan struct{}, webhookWorkers),
+		busy:              make(map[string]bool),
+	}
+}
+
+// CreateWebhook registers a webhook for a wonder net with a new signing
+// secret. An empty events list subscribes to every event.
+func (s *WebhookService) CreateWebhook(ctx context.Context, wonderNetID, rawURL string, events []string, createdBy string) (*repository.Webhook, error) {
+	if err := validateWebhookURL(rawURL, s.allowHTTP); err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if !slices.Contains(WebhookEvents, event) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupportedWebhookEvent, event)
+		}
+	}
+
+	secret, err := generateRandomString(webhookSecretLength)
+	if err != nil {
+		return nil, fmt.Errorf("generate webhook secret: %w", err)
+	}
+
+	webhook, err := s.webhookRepository.Create(ctx, &repository.Webhook{
+		ID:          uuid.New().String(),
+		WonderNetID: wonderNetID,
+		URL:         rawURL,
+		Secret:      "whsec_" + secret,
+		Events:      slices.Compact(slices.Sorted(slices.Values(events))),
+		CreatedBy:   createdBy,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("created webhook", "id", webhook.ID, "wonder_net_id", wonderNetID, "events", webhook.Events)
+	return webhook, nil
+}
+
+// ListWebhooks lists all webhooks of a wonder net.
+func (s *WebhookService) ListWebhooks(ctx context.Context, wonderNetID string) ([]*repository.Webhook, error) {
+	return s.webhookRepository.ListByWonderNet(ctx, wonderNetID)
+}
+
+// DeleteWebhook deletes a webhook of a wonder net and its deliveries.
+func (s *WebhookService) DeleteWebhook(ctx context.Context, wonderNetID, webhookID string) error {
+	if _, err := s.getWebhook(ctx, wonderNetID, webhookID); err != nil {
+		return err
+	}
+	if err := s.webhookRepository.Delete(ctx, webhookID); err != nil {
+		return err
+	}
+
+	slog.Info("deleted webhook", "id", webhookID, "wonder_net_id", wonderNetID)
+	return nil
+}
+
+// ListDeliveries lists the most recent deliveries of a webhook of a wonder net.
+func (s *WebhookService) ListDeliveries(ctx context.Context, wonderNetID, webhookID string) ([]*repository.WebhookDelivery, error) {
+	if _, err := s.getWebhook(ctx, wonderNetID, webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhookRepository.ListDeliveriesByWebhook(ctx, webhookID)
+}
+
+func (s *WebhookService) getWebhook(ctx context.Context, wonderNetID, webhookID string) (*repository.Webhook, error) {
+	webhook, err := s.webhookRepository.Get(ctx, webhookID)
+	if err != nil {
+		return nil, err
+	}
+	if webhook == nil || webhook.WonderNetID != wonderNetID {
+		return nil, ErrWebhookNotFound
+	}
+	return webhook, nil
+}
+
+// Emit queues an event for every webhook of the wonder net subscribed to it.
+// The deliveries are sent by Run.
+func (s *WebhookService) Emit(ctx context.Context, wonderNetID, event string, data any) error {
+	webhooks, err := s.webhookRepository.ListByWonderNet(ctx, wonderNetID)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, webhook := range webhooks {
+		if !webhookSubscribed(webhook, event) {
+			continue
+		}
+		id := uuid.New().String()
+		payload, err := json.Marshal(WebhookPayload{
+			ID:          id,
+			Event:       event,
+			WonderNetID: wonderNetID,
+			CreatedAt:   now,
+			Data:        data,
+		})
+		if err != nil {
+			return fmt.Errorf("encode webhook payload: %w", err)
+		}
+		err = s.webhookRepository.CreateDelivery(ctx, &repository.WebhookDelivery{
+			ID:            id,
+			WebhookID:     webhook.ID,
+			WonderNetID:   wonderNetID,
+			Event:         event,
+			Payload:       string(payload),
+			NextAttemptAt: now,
+		})
+		if err != nil {
+			return fmt.Errorf("queue webhook delivery: %w", err)
+		}
+	}
+	return nil
+}
+
+// Notify queues an alert.firing or alert.resolved event for the alert's
+// wonder net, so alert rules are delivered to webhooks.
+func (s *WebhookService) Notify(ctx context.Context, event AlertEvent) error {
+	name := WebhookEventAlertFiring
+	if event.Resolved {
+		name = WebhookEventAlertResolved
+	}
+	return s.Emit(ctx, event.Alert.WonderNetID, name, WebhookAlert{
+		RuleID:   event.Alert.RuleID,
+		RuleName: event.Alert.RuleName,
+		Kind:     event.Alert.Kind,
+		NodeID:   event.Alert.NodeID,
+		NodeName: event.Alert.NodeName,
+		StartsAt: event.Alert.StartsAt,
+	})
+}
+
+// Run sends due deliveries every interval until ctx is cancelled, then
+// waits for the workers to stop.
+func (s *WebhookService) Run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			s.wg.Wait()
+			return
+		case <-ticker.C:
+			if err := s.Deliver(ctx); err != nil {
+				slog.Error("deliver webhooks", "error", err)
+			}
+		}
+	}
+}
+
+// webhookNodeEvents maps the node events webhooks can subscribe to to
+// their webhook events.
+var webhookNodeEvents = map[NodeEventType]string{
+	NodeEventJoin:       WebhookEventNodeJoined,
+	NodeEventOffline:    WebhookEventNodeOffline,
+	NodeEventAuthorized: WebhookEventNodeAuthorized,
+}
+
+// WatchedWonderNets returns the wonder nets with a webhook subscribed to
+// node events.
+func (s *WebhookService) WatchedWonderNets(ctx context.Context) (map[string]bool, error) {
+	webhooks, err := s.webhookRepository.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	watched := make(map[string]bool)
+	for _, webhook := range webhooks {
+		for _, event := range webhookNodeEvents {
+			if webhookSubscribed(webhook, event) {
+				watched[webhook.WonderNetID] = true
+			}
+		}
+	}
+	return watched, nil
+}
+
+// HandleNodeEvents emits the webhook events of node events.
+func (s *WebhookService) HandleNodeEvents(ctx context.Context, wonderNet *repository.WonderNet, events []NodeEvent) {
+	for _, event := range events {
+		name, ok := webhookNodeEvents[event.Type]
+		if !ok {
+			continue
+		}
+		if err := s.Emit(ctx, wonderNet.ID, name, webhookNode(event.Node)); err != nil {
+			slog.Warn("emit node webhook event", "wonder_net_id", wonderNet.ID, "event", name, "error", err)
+		}
+	}
+}
+
+// Deliver hands the deliveries that are due to workers, one per webhook,
+// and returns without waiting for them. Webhooks that already have a
+// worker, or that find every worker busy, are left for the next call. A
+// delivery is done once the endpoint answers with a 2xx status; otherwise
+// it is retried with exponential backoff until it runs out of attempts.
+func (s *WebhookService) Deliver(ctx context.Context) error {
+	deliveries, err := s.webhookRepository.ListDueDeliveries(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+
+	var webhookIDs []string
+	byWebhook := make(map[string][]*repository.WebhookDelivery)
+	for _, delivery := range deliveries {
+		if _, ok := byWebhook[delivery.WebhookID]; !ok {
+			webhookIDs = append(webhookIDs, delivery.WebhookID)
+		}
+		byWebhook[delivery.WebhookID] = append(byWebhook[delivery.WebhookID], delivery)
+	}
+
+	for _, webhookID := range webhookIDs {
+		s.mu.Lock()
+		busy := s.busy[webhookID]
+		s.mu.Unlock()
+		if busy {
+			continue
+		}
+		select {
+		case s.workers <- struct{}{}:
+		default:
+			return nil
+		}
+
+		s.mu.Lock()
+		s.busy[webhookID] = true
+		s.mu.Unlock()
+		s.wg.Add(1)
+		go func() {
+			defer func() {
+				s.mu.Lock()
+				delete(s.busy, webhookID)
+				s.mu.Unlock()
+				<-s.workers
+				s.wg.Done()
+			}()
+			s.deliverTo(ctx, webhookID, byWebhook[webhookID])
+		}()
+	}
+	return nil
+}
+
+// deliverTo sends deliveries of one webhook in order. It stops at the
+// first failed attempt, leaving the rest for the next call of Deliver, so
+// an endpoint that is down costs one timeout per call rather than one per
+// delivery.
+func (s *WebhookService) deliverTo(ctx context.Context, webhookID string, deliveries []*repository.WebhookDelivery) {
+	webhook, err := s.webhookRepository.Get(ctx, webhookID)
+	if err != nil {
+		slog.Warn("get webhook for delivery", "webhook_id", webhookID, "error", err)
+		return
+	}
+	if webhook == nil {
+		return
+	}
+
+	for _, delivery := range deliveries {
+		status, err := s.send(ctx, webhook, delivery)
+		recordWebhookAttempt(delivery, status, err, time.Now())
+		if err != nil {
+			slog.Warn("webhook delivery attempt failed",
+				"id", delivery.ID,
+				"webhook_id", webhook.ID,
+				"event", delivery.Event,
+				"attempt", delivery.Attempts,
+				"status", delivery.Status,
+				"error", err)
+		}
+		if err := s.webhookRepository.RecordAttempt(ctx, delivery); err != nil {
+			slog.Error("record webhook delivery attempt", "id", delivery.ID, "error", err)
+			return
+		}
+		if delivery.Status != repository.WebhookDeliveryDelivered {
+			return
+		}
+	}
+}
+
+// send POSTs a delivery to its webhook and returns the response status. A
+// non-2xx status is returned along with an error.
+func (s *WebhookService) send(ctx context.Context, webhook *repository.Webhook, delivery *repository.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, webhook.URL, bytes.NewReader([]byte(delivery.Payload)))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "wonder-mesh-net-webhook")
+	req.Header.Set(WebhookEventHeader, delivery.Event)
+	req.Header.Set(WebhookDeliveryHeader, delivery.ID)
+	req.Header.Set(WebhookSignatureHeader, SignWebhookPayload(webhook.Secret, []byte(delivery.Payload)))
+
+	resp, err := s.client.Do(req)
+	if errors.Is(err, ErrForbiddenDestination) {
+		// The error would name the address the host resolved to.
+		return 0, ErrForbiddenDestination
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return resp.StatusCode, fmt.Errorf("endpoint returned %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+// SignWebhookPayload returns the signature header value of a payload:
+// "sha256=" followed by the hex HMAC-SHA256 of the body keyed with the
+// webhook secret.
+func SignWebhookPayload(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// recordWebhookAttempt updates a delivery with the outcome of an attempt
+// made at now.
+func recordWebhookAttempt(delivery *repository.WebhookDelivery, status int, err error, now time.Time) {
+	delivery.Attempts++
+	delivery.ResponseStatus = status
+	if err == nil {
+		delivery.Status = repository.WebhookDeliveryDelivered
+		delivery.LastError = ""
+		delivery.DeliveredAt = &now
+		return
+	}
+
+	delivery.LastError = err.Error()
+	if delivery.Attempts >= webhookMaxAttempts {
+		delivery.Status = repository.WebhookDeliveryFailed
+		return
+	}
+	delivery.NextAttemptAt = now.Add(webhookBackoff(delivery.Attempts))
+}
+
+// webhookBackoff returns the wait after the given number of failed attempts.
+func webhookBackoff(attempts int) time.Duration {
+	backoff := webhookInitialBackoff
+	for i := 1; i < attempts && backoff < webhookMaxBackoff; i++ {
+		backoff *= 2
+	}
+	return min(backoff, webhookMaxBackoff)
+}
+
+func webhookSubscribed(webhook *repository.Webhook, event string) bool {
+	return len(webhook.Events) == 0 || slices.Contains(webhook.Events, event)
+}
+
+// validateWebhookURL checks that rawURL is an absolute https URL, or http
+// one if allowHTTP is set, whose host is not a non-public IP address.
+// Hostnames are checked when connecting, against the address they resolve
+// to then.
+func validateWebhookURL(rawURL string, allowHTTP bool) error {
+	u, err := url.Parse(rawURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && (u.Scheme != "http" || !allowHTTP)) {
+		return ErrInvalidWebhookURL
+	}
+	if ip, err := netip.ParseAddr(u.Hostname()); err == nil && !isPublicAddr(ip) {
+		return fmt.Errorf("%w: %w", ErrInvalidWebhookURL, ErrForbiddenDestination)
+	}
+	return nil
+}
+
+func webhookNode(node *Node) WebhookNode {
+	return WebhookNode{
+		ID:        node.ID,
+		Name:      node.Name,
+		Addresses: node.IPAddrs,
+		Online:    node.Online,
+		LastSeen:  node.LastSeen,
+	}
+}
@@ -0,0 +1,156 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestSignWebhookPayload(t *testing.T) {
+	// echo -n '{"event":"node.joined"}' | openssl dgst -sha256 -hmac whsec_test
+	got := SignWebhookPayload("whsec_test", []byte(`{"event":"node.joined"}`))
+	want := "sha256=8d54ba7a7dc0b54a1c7f9b1e23e395a414edecf3ea269c2c7cffaefd4bbcb99d"
+	if got != want {
+		t.Errorf("SignWebhookPayload() = %q, want %q", got, want)
+	}
+}
+
+func TestWebhookBackoff(t *testing.T) {
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, 30 * time.Second},
+		{2, time.Minute},
+		{3, 2 * time.Minute},
+		{7, 32 * time.Minute},
+		{8, time.Hour},
+		{100, time.Hour},
+	}
+
+	for _, tt := range tests {
+		if got := webhookBackoff(tt.attempts); got != tt.want {
+			t.Errorf("webhookBackoff(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestRecordWebhookAttempt(t *testing.T) {
+	now := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	failure := errors.New("endpoint returned 500 Internal Server Error")
+
+	tests := []struct {
+		name            string
+		attempts        int
+		status          int
+		err             error
+		wantStatus      string
+		wantNextAttempt time.Time
+	}{
+		{"delivered", 0, http.StatusOK, nil, repository.WebhookDeliveryDelivered, time.Time{}},
+		{"first failure is retried", 0, http.StatusInternalServerError, failure, repository.WebhookDeliveryPending, now.Add(30 * time.Second)},
+		{"last attempt fails the delivery", webhookMaxAttempts - 1, 0, failure, repository.WebhookDeliveryFailed, time.Time{}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			delivery := &repository.WebhookDelivery{Status: repository.WebhookDeliveryPending, Attempts: tt.attempts}
+			recordWebhookAttempt(delivery, tt.status, tt.err, now)
+
+			if delivery.Status != tt.wantStatus {
+				t.Errorf("Status = %q, want %q", delivery.Status, tt.wantStatus)
+			}
+			if delivery.Attempts != tt.attempts+1 {
+				t.Errorf("Attempts = %d, want %d", delivery.Attempts, tt.attempts+1)
+			}
+			if delivery.ResponseStatus != tt.status {
+				t.Errorf("ResponseStatus = %d, want %d", delivery.ResponseStatus, tt.status)
+			}
+			if !tt.wantNextAttempt.IsZero() && !delivery.NextAttemptAt.Equal(tt.wantNextAttempt) {
+				t.Errorf("NextAttemptAt = %v, want %v", delivery.NextAttemptAt, tt.wantNextAttempt)
+			}
+			if (delivery.DeliveredAt != nil) != (tt.err == nil) {
+				t.Errorf("DeliveredAt = %v, want set only on success", delivery.DeliveredAt)
+			}
+		})
+	}
+}
+
+func TestValidateWebhookURL(t *testing.T) {
+	tests := []struct {
+		url       string
+		allowHTTP bool
+		wantErr   bool
+	}{
+		{url: "https://hooks.example.com/wonder"},
+		{url: "https://203.0.113.7/hook"},
+		{url: "http://hooks.example.com/wonder", wantErr: true},
+		{url: "http://hooks.example.com/wonder", allowHTTP: true},
+		{url: "https://10.0.0.5:8080/hook", wantErr: true},
+		{url: "http://169.254.169.254/latest/meta-data", allowHTTP: true, wantErr: true},
+		{url: "https://[::1]/hook", wantErr: true},
+		{url: "ftp://example.com/hook", allowHTTP: true, wantErr: true},
+		{url: "/relative/path", wantErr: true},
+		{url: "https://", wantErr: true},
+		{url: "", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		if err := validateWebhookURL(tt.url, tt.allowHTTP); (err != nil) != tt.wantErr {
+			t.Errorf("validateWebhookURL(%q, %v) error = %v, wantErr %v", tt.url, tt.allowHTTP, err, tt.wantErr)
+		}
+	}
+}
+
+func TestWebhookSend(t *testing.T) {
+	payload := `{"id":"d-1","event":"token.created"}`
+	webhook := &repository.Webhook{Secret: "whsec_test"}
+	delivery := &repository.WebhookDelivery{ID: "d-1", Event: WebhookEventTokenCreated, Payload: payload}
+
+	tests := []struct {
+		name    string
+		status  int
+		wantErr bool
+	}{
+		{"accepted", http.StatusNoContent, false},
+		{"rejected", http.StatusBadGateway, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				body, _ := io.ReadAll(r.Body)
+				if string(body) != payload {
+					t.Errorf("body = %q, want %q", body, payload)
+				}
+				if got := r.Header.Get(WebhookEventHeader); got != WebhookEventTokenCreated {
+					t.Errorf("%s = %q, want %q", WebhookEventHeader, got, WebhookEventTokenCreated)
+				}
+				if got := r.Header.Get(WebhookDeliveryHeader); got != "d-1" {
+					t.Errorf("%s = %q, want %q", WebhookDeliveryHeader, got, "d-1")
+				}
+				if got, want := r.Header.Get(WebhookSignatureHeader), SignWebhookPayload("whsec_test", body); got != want {
+					t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+				}
+				w.WriteHeader(tt.status)
+			}))
+			defer srv.Close()
+
+			webhook.URL = srv.URL
+			s := &WebhookService{client: srv.Client()}
+			status, err := s.send(context.Background(), webhook, delivery)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("send() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if status != tt.status {
+				t.Errorf("send() status = %d, want %d", status, tt.status)
+			}
+		})
+	}
+}
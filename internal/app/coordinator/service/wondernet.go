@@ -2,9 +2,12 @@ package service
 
 import (
 	"context"
+	"encoding/base64"
 	"errors"
 	"fmt"
 	"log/slog"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
@@ -14,14 +17,48 @@ import (
 )
 
 var (
-	ErrNoWonderNet = errors.New("no wonder net found")
+	ErrNoWonderNet            = errors.New("no wonder net found")
+	ErrWonderNetNameTaken     = errors.New("wonder net name already in use")
+	ErrDeleteDefaultWonderNet = errors.New("cannot delete the default wonder net")
+	ErrInvalidCursor          = errors.New("invalid cursor")
+
+	// ErrUnsupportedByMeshBackend is returned for features that need the
+	// embedded Headscale, such as owner-defined ACL rules, when the
+	// coordinator uses another control plane.
+	ErrUnsupportedByMeshBackend = errors.New("not supported by the mesh backend")
 )
 
+// RealmManager provisions wonder nets on a control plane other than the
+// embedded Headscale, such as the Tailscale control plane, which keeps the
+// isolation policy of its realms itself.
+type RealmManager interface {
+	// CreateRealm creates the realm of a wonder net and its isolation
+	// policy. It is idempotent.
+	CreateRealm(ctx context.Context, name string) error
+	// DeleteRealm removes the nodes of a realm, then the realm.
+	DeleteRealm(ctx context.Context, name string) error
+	// CreateAuthKey creates an auth key that joins nodes to the realm.
+	CreateAuthKey(ctx context.Context, realmName string, ttl time.Duration, reusable bool, ephemeral bool) (string, error)
+	// SetPrivilegedRealms replaces the realms whose nodes reach every node.
+	SetPrivilegedRealms(ctx context.Context, realms []string) error
+}
+
+// WonderNetPage is one page of a wonder net listing. NextCursor is empty on
+// the last page.
+type WonderNetPage struct {
+	WonderNets []*repository.WonderNet
+	NextCursor string
+}
+
 // WonderNetService manages wonder net provisioning and Headscale integration.
 type WonderNetService struct {
-	wonderNetRepository  *repository.WonderNetRepository
-	wonderNetManager     *headscale.WonderNetManager
-	aclManager           *headscale.ACLManager
+	wonderNetRepository *repository.WonderNetRepository
+	memberRepo          *repository.MemberRepository
+	wonderNetManager    *headscale.WonderNetManager
+	aclManager          *headscale.ACLManager
+	// realms replaces wonderNetManager and aclManager when the coordinator
+	// does not use Headscale; nil with Headscale.
+	realms               RealmManager
 	publicURL            string
 	privilegedNetworks   []string
 	useTaggedACL         bool
@@ -31,17 +68,21 @@ type WonderNetService struct {
 // NewWonderNetService creates a new WonderNetService.
 func NewWonderNetService(
 	wonderNetRepository *repository.WonderNetRepository,
+	memberRepo *repository.MemberRepository,
 	wonderNetManager *headscale.WonderNetManager,
 	aclManager *headscale.ACLManager,
 	publicURL string,
 	privilegedNetworks []string,
 	useTaggedACL bool,
 	strictPrivilegedTags bool,
+	realms RealmManager,
 ) *WonderNetService {
 	return &WonderNetService{
 		wonderNetRepository:  wonderNetRepository,
+		memberRepo:           memberRepo,
 		wonderNetManager:     wonderNetManager,
 		aclManager:           aclManager,
+		realms:               realms,
 		publicURL:            publicURL,
 		privilegedNetworks:   privilegedNetworks,
 		useTaggedACL:         useTaggedACL,
@@ -50,6 +91,7 @@ func NewWonderNetService(
 }
 
 // ProvisionWonderNet creates a new wonder net for a user, including Headscale namespace.
+// The first wonder net provisioned for a user becomes their default.
 func (s *WonderNetService) ProvisionWonderNet(ctx context.Context, userID, displayName string) (*repository.WonderNet, error) {
 	wonderNetID, hsUser := headscale.NewWonderNetIdentifiers()
 
@@ -66,6 +108,24 @@ func (s *WonderNetService) ProvisionWonderNet(ctx context.Context, userID, displ
 		return nil, err
 	}
 
+	defaultWonderNet, err := s.wonderNetRepository.GetDefaultByOwner(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if defaultWonderNet == nil {
+		if err := s.wonderNetRepository.SetDefault(ctx, userID, wonderNetID); err != nil {
+			return nil, err
+		}
+		newWonderNet.IsDefault = true
+	}
+
+	if s.realms != nil {
+		if err := s.realms.CreateRealm(ctx, hsUser); err != nil {
+			return nil, err
+		}
+		return newWonderNet, nil
+	}
+
 	hsUserObj, err := s.wonderNetManager.GetOrCreateWonderNet(ctx, hsUser)
 	if err != nil {
 		return nil, err
@@ -83,8 +143,168 @@ func (s *WonderNetService) ProvisionWonderNet(ctx context.Context, userID, displ
 	return newWonderNet, nil
 }
 
+// CreateWonderNet provisions an additional wonder net for a user.
+// Display names must be unique per owner. If makeDefault is true, the new
+// wonder net replaces the owner's current default.
+func (s *WonderNetService) CreateWonderNet(ctx context.Context, ownerID, displayName string, makeDefault bool) (*repository.WonderNet, error) {
+	existing, err := s.wonderNetRepository.ListByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("list wonder nets by owner: %w", err)
+	}
+	for _, wn := range existing {
+		if wn.DisplayName == displayName {
+			return nil, ErrWonderNetNameTaken
+		}
+	}
+
+	wonderNet, err := s.ProvisionWonderNet(ctx, ownerID, displayName)
+	if err != nil {
+		return nil, err
+	}
+
+	if makeDefault && !wonderNet.IsDefault {
+		if err := s.wonderNetRepository.SetDefault(ctx, ownerID, wonderNet.ID); err != nil {
+			return nil, err
+		}
+		wonderNet.IsDefault = true
+	}
+
+	slog.Info("created wonder net", "id", wonderNet.ID, "owner_id", ownerID, "default", wonderNet.IsDefault)
+	return wonderNet, nil
+}
+
+// SetDefaultWonderNet makes a wonder net the owner's default.
+func (s *WonderNetService) SetDefaultWonderNet(ctx context.Context, ownerID, wonderNetID string) (*repository.WonderNet, error) {
+	wonderNet, err := s.getOwnedWonderNet(ctx, ownerID, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.wonderNetRepository.SetDefault(ctx, ownerID, wonderNet.ID); err != nil {
+		return nil, err
+	}
+	wonderNet.IsDefault = true
+
+	slog.Info("set default wonder net", "id", wonderNet.ID, "owner_id", ownerID)
+	return wonderNet, nil
+}
+
+// DeleteWonderNet deletes a wonder net owned by a user together with its
+// Headscale user and nodes. Deleting the wonder net record cascades, in
+// one statement, to every record of the wonder net, such as its API keys,
+// services, members, and webhooks. The default wonder net cannot be
+// deleted; another wonder net must be made default first.
+func (s *WonderNetService) DeleteWonderNet(ctx context.Context, ownerID, wonderNetID string) error {
+	wonderNet, err := s.getOwnedWonderNet(ctx, ownerID, wonderNetID)
+	if err != nil {
+		return err
+	}
+	if wonderNet.IsDefault {
+		return ErrDeleteDefaultWonderNet
+	}
+
+	if s.realms != nil {
+		if err := s.realms.DeleteRealm(ctx, wonderNet.HeadscaleUser); err != nil {
+			return fmt.Errorf("delete realm: %w", err)
+		}
+	} else {
+		if err := s.aclManager.SetWonderNetPolicy(ctx, servicePolicyKey(wonderNet.HeadscaleUser), nil); err != nil {
+			return fmt.Errorf("remove service rules: %w", err)
+		}
+		if wonderNet.ACLRules != "" {
+			if err := s.aclManager.SetWonderNetPolicy(ctx, wonderNet.HeadscaleUser, nil); err != nil {
+				return fmt.Errorf("remove acl rules: %w", err)
+			}
+		}
+		if err := s.wonderNetManager.DeleteWonderNet(ctx, wonderNet.HeadscaleUser); err != nil {
+			return fmt.Errorf("delete headscale user: %w", err)
+		}
+	}
+	if err := s.wonderNetRepository.Delete(ctx, wonderNet.ID); err != nil {
+		return err
+	}
+
+	// The per-WonderNet policy only references existing Headscale users, so
+	// rebuild it to drop the deleted one. Tagged mode needs no change.
+	if s.realms == nil && !s.useTaggedACL {
+		if err := s.InitializeACLPolicy(ctx); err != nil {
+			slog.Warn("rebuild acl policy after wonder net deletion", "error", err)
+		}
+	}
+
+	slog.Info("deleted wonder net", "id", wonderNet.ID, "owner_id", ownerID)
+	return nil
+}
+
+// SetNodeNameTemplate sets the node name template of a wonder net owned by a
+// user. An empty template names nodes after their hostname; nodes renamed
+// through the rename API keep their names either way.
+func (s *WonderNetService) SetNodeNameTemplate(ctx context.Context, ownerID, wonderNetID, template string) (*repository.WonderNet, error) {
+	if err := ValidateNodeNameTemplate(template); err != nil {
+		return nil, err
+	}
+
+	wonderNet, err := s.getOwnedWonderNet(ctx, ownerID, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.wonderNetRepository.UpdateNodeNameTemplate(ctx, wonderNet.ID, template); err != nil {
+		return nil, err
+	}
+	wonderNet.NodeNameTemplate = template
+
+	slog.Info("set node name template", "id", wonderNet.ID, "owner_id", ownerID, "template", template)
+	return wonderNet, nil
+}
+
+// SetJoinTokenTTLPolicy sets how long the join tokens of a wonder net owned
+// by ownerID are valid by default and at most. A nil TTL is not changed,
+// and zero resets it to the coordinator's.
+func (s *WonderNetService) SetJoinTokenTTLPolicy(ctx context.Context, ownerID, wonderNetID string, defaultTTL, maxTTL *time.Duration) (*repository.WonderNet, error) {
+	wonderNet, err := s.getOwnedWonderNet(ctx, ownerID, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+
+	newDefault, newMax := wonderNet.JoinTokenDefaultTTL, wonderNet.JoinTokenMaxTTL
+	if defaultTTL != nil {
+		newDefault = *defaultTTL
+	}
+	if maxTTL != nil {
+		newMax = *maxTTL
+	}
+	if err := ValidateJoinTokenTTLPolicy(newDefault, newMax); err != nil {
+		return nil, err
+	}
+
+	if err := s.wonderNetRepository.UpdateJoinTokenTTL(ctx, wonderNet.ID, newDefault, newMax); err != nil {
+		return nil, err
+	}
+	wonderNet.JoinTokenDefaultTTL = newDefault
+	wonderNet.JoinTokenMaxTTL = newMax
+
+	slog.Info("set join token TTL policy", "id", wonderNet.ID, "owner_id", ownerID, "default_ttl", newDefault, "max_ttl", newMax)
+	return wonderNet, nil
+}
+
+func (s *WonderNetService) getOwnedWonderNet(ctx context.Context, ownerID, wonderNetID string) (*repository.WonderNet, error) {
+	wonderNet, err := s.wonderNetRepository.Get(ctx, wonderNetID)
+	if err != nil {
+		return nil, err
+	}
+	if wonderNet == nil || wonderNet.OwnerID != ownerID {
+		return nil, ErrNoWonderNet
+	}
+	return wonderNet, nil
+}
+
 // EnsureHeadscaleWonderNet ensures the Headscale wonder net exists and ACL is configured.
 func (s *WonderNetService) EnsureHeadscaleWonderNet(ctx context.Context, headscaleUser string) error {
+	if s.realms != nil {
+		return s.realms.CreateRealm(ctx, headscaleUser)
+	}
+
 	hsUserObj, err := s.wonderNetManager.GetOrCreateWonderNet(ctx, headscaleUser)
 	if err != nil {
 		return err
@@ -99,6 +319,10 @@ func (s *WonderNetService) EnsureHeadscaleWonderNet(ctx context.Context, headsca
 
 // CreateAuthKey creates a Headscale auth key for a wonder net.
 func (s *WonderNetService) CreateAuthKey(ctx context.Context, wonderNet *repository.WonderNet, ttl time.Duration, reusable bool) (string, error) {
+	if s.realms != nil {
+		return s.realms.CreateAuthKey(ctx, wonderNet.HeadscaleUser, ttl, reusable, false)
+	}
+
 	key, err := s.wonderNetManager.CreateAuthKeyByName(ctx, wonderNet.HeadscaleUser, ttl, reusable)
 	if err != nil {
 		return "", err
@@ -113,8 +337,18 @@ func (s *WonderNetService) GetPublicURL() string {
 
 // InitializeACLPolicy rebuilds the full ACL policy from all existing Headscale users.
 // When a privileged network is configured, a hub-spoke policy is used;
-// otherwise, pure isolation policy is applied.
+// otherwise, pure isolation policy is applied. Owner-defined wonder net rules
+// are merged in after the generated rules. Without Headscale, only the
+// privileged realms are set, as the realms keep their own isolation rules.
 func (s *WonderNetService) InitializeACLPolicy(ctx context.Context) error {
+	if s.realms != nil {
+		return s.realms.SetPrivilegedRealms(ctx, s.privilegedNetworks)
+	}
+
+	if err := s.loadACLRules(ctx); err != nil {
+		return err
+	}
+
 	if s.useTaggedACL {
 		// Tag existing privileged nodes first so there is no transient window
 		// in which a privileged node lacks tag:privileged under the new policy,
@@ -138,8 +372,18 @@ func (s *WonderNetService) InitializeACLPolicy(ctx context.Context) error {
 	return s.aclManager.SetWonderNetIsolationPolicy(ctx)
 }
 
-// GetWonderNetByOwner returns the first wonder net owned by a user.
+// GetWonderNetByOwner returns the default wonder net owned by a user.
+// Owners without a default (created before defaults existed) get their
+// oldest wonder net promoted to default.
 func (s *WonderNetService) GetWonderNetByOwner(ctx context.Context, userID string) (*repository.WonderNet, error) {
+	wonderNet, err := s.wonderNetRepository.GetDefaultByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("get default wonder net: %w", err)
+	}
+	if wonderNet != nil {
+		return wonderNet, nil
+	}
+
 	wonderNets, err := s.wonderNetRepository.ListByOwner(ctx, userID)
 	if err != nil {
 		return nil, fmt.Errorf("list wonder nets by owner: %w", err)
@@ -147,13 +391,102 @@ func (s *WonderNetService) GetWonderNetByOwner(ctx context.Context, userID strin
 	if len(wonderNets) == 0 {
 		return nil, nil
 	}
-	return wonderNets[0], nil
+
+	wonderNet = wonderNets[0]
+	if err := s.wonderNetRepository.SetDefault(ctx, userID, wonderNet.ID); err != nil {
+		return nil, fmt.Errorf("set default wonder net: %w", err)
+	}
+	wonderNet.IsDefault = true
+	return wonderNet, nil
+}
+
+// FindWonderNetByOwner returns the wonder net owned by a user whose ID or
+// display name matches network.
+func (s *WonderNetService) FindWonderNetByOwner(ctx context.Context, userID, network string) (*repository.WonderNet, error) {
+	wonderNets, err := s.wonderNetRepository.ListByOwner(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list wonder nets by owner: %w", err)
+	}
+	for _, wn := range wonderNets {
+		if wn.ID == network {
+			return wn, nil
+		}
+	}
+	for _, wn := range wonderNets {
+		if wn.DisplayName == network {
+			return wn, nil
+		}
+	}
+	return nil, ErrNoWonderNet
 }
 
-// ListAllWonderNets returns all wonder nets in the system.
+// ListWonderNetsPage returns one page of wonder nets across the system.
+// cursor is the NextCursor of the previous page, or empty for the first page;
+// it must be used with the same ordering it was issued for.
 // This is intended for admin API use only.
-func (s *WonderNetService) ListAllWonderNets(ctx context.Context) ([]*repository.WonderNet, error) {
-	return s.wonderNetRepository.List(ctx)
+func (s *WonderNetService) ListWonderNetsPage(ctx context.Context, opts repository.WonderNetListOptions, cursor string) (*WonderNetPage, error) {
+	if cursor != "" {
+		after, err := decodeWonderNetCursor(cursor)
+		if err != nil {
+			return nil, err
+		}
+		opts.After = after
+	}
+
+	limit := opts.Limit
+	if limit <= 0 {
+		return nil, fmt.Errorf("page limit must be positive")
+	}
+	opts.Limit = limit + 1
+	wonderNets, err := s.wonderNetRepository.ListPage(ctx, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &WonderNetPage{WonderNets: wonderNets}
+	if len(wonderNets) > limit {
+		page.WonderNets = wonderNets[:limit]
+		last := page.WonderNets[limit-1]
+		page.NextCursor = encodeWonderNetCursor(&repository.WonderNetCursor{
+			CreatedAt: last.CreatedAt,
+			ID:        last.ID,
+		})
+	}
+	return page, nil
+}
+
+func encodeWonderNetCursor(c *repository.WonderNetCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeWonderNetCursor(cursor string) (*repository.WonderNetCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok || id == "" {
+		return nil, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &repository.WonderNetCursor{
+		CreatedAt: time.Unix(0, n).UTC(),
+		ID:        id,
+	}, nil
+}
+
+// CountWonderNets returns the number of wonder nets in the system.
+func (s *WonderNetService) CountWonderNets(ctx context.Context) (int, error) {
+	return s.wonderNetRepository.Count(ctx)
+}
+
+// CountWonderNetsByMeshType returns the number of wonder nets per mesh type.
+func (s *WonderNetService) CountWonderNetsByMeshType(ctx context.Context) (map[string]int, error) {
+	return s.wonderNetRepository.CountByMeshType(ctx)
 }
 
 // GetWonderNetByID returns a wonder net by its ID.
@@ -186,13 +519,24 @@ func (s *WonderNetService) GetOrCreateWonderNet(ctx context.Context, ownerID, di
 }
 
 // ResolveWonderNetFromClaims returns the wonder net for a user based on JWT claims.
-// It auto-creates a WonderNet if none exists for the user.
+// If network is non-empty, it selects the user's wonder net with that ID or
+// display name, falling back to the wonder nets shared with the user, and
+// returns ErrNoWonderNet if there is none. Otherwise it
+// returns the user's default wonder net, auto-creating one if none exists.
 // Service account tokens are rejected since service account support was removed.
-func (s *WonderNetService) ResolveWonderNetFromClaims(ctx context.Context, claims *jwtauth.Claims) (*repository.WonderNet, error) {
+func (s *WonderNetService) ResolveWonderNetFromClaims(ctx context.Context, claims *jwtauth.Claims, network string) (*repository.WonderNet, error) {
 	if claims.IsServiceAccount() {
 		return nil, fmt.Errorf("service account tokens are not supported")
 	}
 
+	if network != "" {
+		wonderNet, err := s.FindWonderNetByOwner(ctx, claims.Subject, network)
+		if errors.Is(err, ErrNoWonderNet) {
+			return s.findSharedWonderNet(ctx, claims.Subject, network)
+		}
+		return wonderNet, err
+	}
+
 	displayName := claims.PreferredUsername
 	if displayName == "" {
 		displayName = claims.Name
@@ -202,3 +546,49 @@ func (s *WonderNetService) ResolveWonderNetFromClaims(ctx context.Context, claim
 	}
 	return s.GetOrCreateWonderNet(ctx, claims.Subject, displayName)
 }
+
+// SharedWonderNet is a wonder net shared with a user, with the user's role.
+type SharedWonderNet struct {
+	WonderNet *repository.WonderNet
+	Role      string
+}
+
+// ListSharedWonderNets lists the wonder nets other users shared with a user.
+func (s *WonderNetService) ListSharedWonderNets(ctx context.Context, userID string) ([]*SharedWonderNet, error) {
+	memberships, err := s.memberRepo.ListMembershipsByUser(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("list memberships: %w", err)
+	}
+
+	shared := make([]*SharedWonderNet, 0, len(memberships))
+	for _, m := range memberships {
+		wonderNet, err := s.wonderNetRepository.Get(ctx, m.WonderNetID)
+		if err != nil {
+			return nil, fmt.Errorf("get wonder net: %w", err)
+		}
+		if wonderNet != nil {
+			shared = append(shared, &SharedWonderNet{WonderNet: wonderNet, Role: m.Role})
+		}
+	}
+	return shared, nil
+}
+
+// findSharedWonderNet returns the wonder net shared with a user whose ID or
+// display name matches network.
+func (s *WonderNetService) findSharedWonderNet(ctx context.Context, userID, network string) (*repository.WonderNet, error) {
+	shared, err := s.ListSharedWonderNets(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	for _, sw := range shared {
+		if sw.WonderNet.ID == network {
+			return sw.WonderNet, nil
+		}
+	}
+	for _, sw := range shared {
+		if sw.WonderNet.DisplayName == network {
+			return sw.WonderNet, nil
+		}
+	}
+	return nil, ErrNoWonderNet
+}
@@ -0,0 +1,47 @@
+package service
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+)
+
+func TestWonderNetCursorRoundTrip(t *testing.T) {
+	want := &repository.WonderNetCursor{
+		CreatedAt: time.Date(2025, 6, 1, 12, 30, 0, 123456000, time.UTC),
+		ID:        "3f0c9a52-1b7e-4c1d-9f5a-0d2e6b8c4a71",
+	}
+
+	got, err := decodeWonderNetCursor(encodeWonderNetCursor(want))
+	if err != nil {
+		t.Fatalf("decodeWonderNetCursor() error = %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) {
+		t.Errorf("CreatedAt = %v, want %v", got.CreatedAt, want.CreatedAt)
+	}
+	if got.ID != want.ID {
+		t.Errorf("ID = %q, want %q", got.ID, want.ID)
+	}
+}
+
+func TestDecodeWonderNetCursor_Invalid(t *testing.T) {
+	tests := []struct {
+		name   string
+		cursor string
+	}{
+		{"not base64", "!!!"},
+		{"missing separator", "MTIz"},
+		{"missing id", "MTIzOg"},
+		{"non-numeric time", "YWJjOmlk"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := decodeWonderNetCursor(tt.cursor); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("decodeWonderNetCursor(%q) error = %v, want %v", tt.cursor, err, ErrInvalidCursor)
+			}
+		})
+	}
+}
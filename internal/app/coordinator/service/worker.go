@@ -2,25 +2,55 @@ package service
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/netip"
+	"slices"
 	"time"
 
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/metrics"
 	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
 	"github.com/strrl/wonder-mesh-net/pkg/jointoken"
 	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
 )
 
 // JoinCredentials contains the credentials for a worker to join the mesh.
+// WorkerToken authenticates the worker's heartbeats after it joined.
+// SSHCAPublicKey is the wonder net's SSH CA key, which workers can have sshd
+// trust for user certificates.
 type JoinCredentials struct {
-	MeshType string
-	Metadata map[string]any
+	MeshType       string
+	Metadata       map[string]any
+	WorkerToken    string
+	SSHCAPublicKey string
 }
 
-// WorkerService handles worker join token operations.
+// Heartbeat is a health report sent by a worker. Connectivity is nil if the
+// worker could not read it from its Tailscale client.
+type Heartbeat struct {
+	OS                   string
+	TailscaleVersion     string
+	DiskTotalBytes       int64
+	DiskFreeBytes        int64
+	MemoryTotalBytes     int64
+	MemoryAvailableBytes int64
+	Connectivity         *NodeConnectivity
+}
+
+// WorkerService handles worker join token operations and worker heartbeats.
 type WorkerService struct {
-	tokenGenerator      *jointoken.Generator
-	jwtSecret           string
-	wonderNetRepository *repository.WonderNetRepository
-	meshBackend         meshbackend.MeshBackend
+	tokenGenerator          *jointoken.Generator
+	jwtSecret               string
+	wonderNetRepository     *repository.WonderNetRepository
+	nodeHeartbeatRepository *repository.NodeHeartbeatRepository
+	joinTokenRepository     *repository.JoinTokenRepository
+	workerTokenRepository   *repository.WorkerTokenRepository
+	decommissionRepository  *repository.NodeDecommissionRepository
+	meshBackend             meshbackend.MeshBackend
+	webhookService          *WebhookService
+	quotaService            *QuotaService
+	sshCAService            *SSHCAService
 }
 
 // NewWorkerService creates a new WorkerService.
@@ -28,45 +58,508 @@ func NewWorkerService(
 	tokenGenerator *jointoken.Generator,
 	jwtSecret string,
 	wonderNetRepository *repository.WonderNetRepository,
+	nodeHeartbeatRepository *repository.NodeHeartbeatRepository,
+	joinTokenRepository *repository.JoinTokenRepository,
+	workerTokenRepository *repository.WorkerTokenRepository,
+	decommissionRepository *repository.NodeDecommissionRepository,
 	meshBackend meshbackend.MeshBackend,
+	webhookService *WebhookService,
+	quotaService *QuotaService,
+	sshCAService *SSHCAService,
 ) *WorkerService {
 	return &WorkerService{
-		tokenGenerator:      tokenGenerator,
-		jwtSecret:           jwtSecret,
-		wonderNetRepository: wonderNetRepository,
-		meshBackend:         meshBackend,
+		tokenGenerator:          tokenGenerator,
+		jwtSecret:               jwtSecret,
+		wonderNetRepository:     wonderNetRepository,
+		nodeHeartbeatRepository: nodeHeartbeatRepository,
+		joinTokenRepository:     joinTokenRepository,
+		workerTokenRepository:   workerTokenRepository,
+		decommissionRepository:  decommissionRepository,
+		meshBackend:             meshBackend,
+		webhookService:          webhookService,
+		quotaService:            quotaService,
+		sshCAService:            sshCAService,
+	}
+}
+
+// MaxJoinTokenUses is the most joins a single join token may allow.
+const MaxJoinTokenUses = 1000
+
+// WorkerTokenIdleTTL is how long a worker token stays valid without being
+// used. Tokens bound to a node are extended on every use; others expire
+// this long after they were issued.
+const WorkerTokenIdleTTL = 30 * 24 * time.Hour
+
+const (
+	// DefaultJoinTokenTTL is how long join tokens are valid unless their
+	// wonder net sets another default.
+	DefaultJoinTokenTTL = 8 * time.Hour
+	// MinJoinTokenTTL and MaxJoinTokenTTL bound the TTL of join tokens and
+	// the join token policy of wonder nets.
+	MinJoinTokenTTL = time.Minute
+	MaxJoinTokenTTL = 30 * 24 * time.Hour
+)
+
+// JoinTokenTTLPolicy returns how long join tokens of the wonder net are
+// valid by default and at most. The default is DefaultJoinTokenTTL, cut to
+// the wonder net's maximum, unless the wonder net sets one.
+func JoinTokenTTLPolicy(wonderNet *repository.WonderNet) (defaultTTL, maxTTL time.Duration) {
+	maxTTL = wonderNet.JoinTokenMaxTTL
+	if maxTTL <= 0 {
+		maxTTL = MaxJoinTokenTTL
+	}
+	defaultTTL = wonderNet.JoinTokenDefaultTTL
+	if defaultTTL <= 0 {
+		defaultTTL = min(DefaultJoinTokenTTL, maxTTL)
 	}
+	return defaultTTL, maxTTL
 }
 
-// GenerateJoinToken creates a JWT for a worker to join the mesh.
-func (s *WorkerService) GenerateJoinToken(ctx context.Context, wonderNet *repository.WonderNet, ttl time.Duration) (string, error) {
-	return s.tokenGenerator.Generate(wonderNet.ID, ttl)
+// ValidateJoinTokenTTLPolicy checks a default and maximum join token TTL
+// for a wonder net. Zero leaves either to the coordinator.
+func ValidateJoinTokenTTLPolicy(defaultTTL, maxTTL time.Duration) error {
+	for _, ttl := range []time.Duration{defaultTTL, maxTTL} {
+		if ttl != 0 && (ttl < MinJoinTokenTTL || ttl > MaxJoinTokenTTL) {
+			return fmt.Errorf("%w: must be between %s and %s", ErrInvalidJoinTokenTTL, MinJoinTokenTTL, MaxJoinTokenTTL)
+		}
+	}
+	if maxTTL != 0 && defaultTTL > maxTTL {
+		return fmt.Errorf("%w: default %s exceeds the maximum %s", ErrInvalidJoinTokenTTL, defaultTTL, maxTTL)
+	}
+	return nil
+}
+
+// IssuedJoinToken is a newly created join token. JTI identifies the token
+// for its usage status.
+type IssuedJoinToken struct {
+	Token string
+	// CoordinatorURL is the URL the token directs workers to.
+	CoordinatorURL string
+	JTI            string
+	MaxUses        int
+	TTL            time.Duration
+	ExpiresAt      time.Time
+	AllowedCIDRs   []string
+}
+
+// GenerateJoinToken creates a JWT for up to maxUses workers to join the mesh,
+// and records it so its joins can be counted. A ttl of zero uses the wonder
+// net's default, and longer ttls than its maximum are rejected with
+// ErrInvalidJoinTokenTTL. A maxUses of zero allows one join. A non-empty
+// allowedCIDRs limits the addresses workers may join from. Tokens of a
+// wonder net with a custom public URL direct the worker to that URL.
+func (s *WorkerService) GenerateJoinToken(ctx context.Context, wonderNet *repository.WonderNet, createdBy string, ttl time.Duration, maxUses int, allowedCIDRs []string) (*IssuedJoinToken, error) {
+	defaultTTL, maxTTL := JoinTokenTTLPolicy(wonderNet)
+	switch {
+	case ttl == 0:
+		ttl = defaultTTL
+	case ttl < MinJoinTokenTTL:
+		return nil, fmt.Errorf("%w: must be at least %s", ErrInvalidJoinTokenTTL, MinJoinTokenTTL)
+	case ttl > maxTTL:
+		return nil, fmt.Errorf("%w: %s exceeds the maximum of %s for this wonder net", ErrInvalidJoinTokenTTL, ttl, maxTTL)
+	}
+	if maxUses == 0 {
+		maxUses = jointoken.DefaultMaxUses
+	}
+	if maxUses < 1 || maxUses > MaxJoinTokenUses {
+		return nil, fmt.Errorf("%w: must be between 1 and %d", ErrInvalidJoinTokenUses, MaxJoinTokenUses)
+	}
+	allowedCIDRs, err := NormalizeAllowedCIDRs(allowedCIDRs)
+	if err != nil {
+		return nil, err
+	}
+
+	token, claims, err := s.tokenGenerator.Issue(jointoken.Options{
+		WonderNetID:    wonderNet.ID,
+		CoordinatorURL: wonderNet.PublicURL,
+		TTL:            ttl,
+		MaxUses:        maxUses,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.joinTokenRepository.Create(ctx, &repository.JoinToken{
+		JTI:          claims.ID,
+		WonderNetID:  wonderNet.ID,
+		CreatedBy:    createdBy,
+		MaxUses:      maxUses,
+		ExpiresAt:    claims.ExpiresAt.Time,
+		AllowedCIDRs: allowedCIDRs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record join token: %w", err)
+	}
+
+	metrics.JoinTokensIssued.Inc()
+	err = s.webhookService.Emit(ctx, wonderNet.ID, WebhookEventTokenCreated, WebhookJoinToken{
+		JTI:       claims.ID,
+		CreatedBy: createdBy,
+		MaxUses:   maxUses,
+		ExpiresAt: claims.ExpiresAt.Time,
+	})
+	if err != nil {
+		slog.Warn("emit token.created webhook event", "wonder_net_id", wonderNet.ID, "error", err)
+	}
+	return &IssuedJoinToken{
+		Token:          token,
+		CoordinatorURL: claims.CoordinatorURL,
+		JTI:            claims.ID,
+		MaxUses:        maxUses,
+		TTL:            ttl,
+		ExpiresAt:      claims.ExpiresAt.Time,
+		AllowedCIDRs:   allowedCIDRs,
+	}, nil
+}
+
+// JoinTokenStatus returns the usage record of a join token of the wonder net.
+// Returns ErrJoinTokenNotFound if the token was issued for another wonder net
+// or is unknown.
+func (s *WorkerService) JoinTokenStatus(ctx context.Context, wonderNet *repository.WonderNet, jti string) (*repository.JoinToken, error) {
+	token, err := s.joinTokenRepository.Get(ctx, jti)
+	if err != nil {
+		return nil, fmt.Errorf("get join token: %w", err)
+	}
+	if token == nil || token.WonderNetID != wonderNet.ID {
+		return nil, ErrJoinTokenNotFound
+	}
+	return token, nil
 }
 
 // ExchangeJoinToken validates a JWT and returns credentials for joining the mesh.
-func (s *WorkerService) ExchangeJoinToken(ctx context.Context, token string) (*JoinCredentials, error) {
+// hostWonderNetID is the wonder net whose custom domain received the request,
+// if any; tokens of other wonder nets are rejected there.
+//
+// Each exchange counts as one use of the token, and ErrJoinTokenUsed is
+// returned once it has none left. Tokens issued before uses were tracked
+// carry no jti and are accepted until they expire. Tokens limited to
+// address ranges return ErrClientIPNotAllowed for any other clientIP.
+func (s *WorkerService) ExchangeJoinToken(ctx context.Context, token, hostWonderNetID string, clientIP netip.Addr) (*JoinCredentials, error) {
 	validator := jointoken.NewValidator(s.jwtSecret)
 	claims, err := validator.Validate(token)
 	if err != nil {
+		metrics.WorkerJoins.WithLabelValues("invalid_token").Inc()
+		return nil, ErrInvalidToken
+	}
+
+	if hostWonderNetID != "" && claims.WonderNetID != hostWonderNetID {
+		metrics.WorkerJoins.WithLabelValues("invalid_token").Inc()
 		return nil, ErrInvalidToken
 	}
 
 	wonderNet, err := s.wonderNetRepository.Get(ctx, claims.WonderNetID)
 	if err != nil || wonderNet == nil {
+		metrics.WorkerJoins.WithLabelValues("invalid_token").Inc()
 		return nil, ErrInvalidToken
 	}
 
+	if claims.ID != "" {
+		if err := s.useJoinToken(ctx, claims.ID, clientIP); err != nil {
+			return nil, err
+		}
+	}
+	credentials, err := s.joinCredentials(ctx, wonderNet)
+	if err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			metrics.WorkerJoins.WithLabelValues("quota_exceeded").Inc()
+		} else {
+			metrics.WorkerJoins.WithLabelValues("error").Inc()
+		}
+		if claims.ID != "" {
+			if releaseErr := s.joinTokenRepository.Release(ctx, claims.ID); releaseErr != nil {
+				slog.Warn("release join token use", "jti", claims.ID, "error", releaseErr)
+			}
+		}
+		return nil, err
+	}
+
+	metrics.WorkerJoins.WithLabelValues("success").Inc()
+	return credentials, nil
+}
+
+// useJoinToken counts one use of the join token with the given jti by a
+// worker at clientIP.
+func (s *WorkerService) useJoinToken(ctx context.Context, jti string, clientIP netip.Addr) error {
+	record, err := s.joinTokenRepository.Get(ctx, jti)
+	if err != nil {
+		metrics.WorkerJoins.WithLabelValues("error").Inc()
+		return fmt.Errorf("get join token: %w", err)
+	}
+	if record == nil {
+		metrics.WorkerJoins.WithLabelValues("invalid_token").Inc()
+		return ErrInvalidToken
+	}
+	if err := checkClientIP(record.AllowedCIDRs, clientIP); err != nil {
+		metrics.WorkerJoins.WithLabelValues("ip_not_allowed").Inc()
+		slog.Warn("join token used from outside its allowed ranges", "jti", jti, "client_ip", clientIP)
+		return err
+	}
+
+	used, err := s.joinTokenRepository.Use(ctx, jti)
+	if err != nil {
+		metrics.WorkerJoins.WithLabelValues("error").Inc()
+		return fmt.Errorf("use join token: %w", err)
+	}
+	if used {
+		return nil
+	}
+	metrics.WorkerJoins.WithLabelValues("token_used").Inc()
+	return ErrJoinTokenUsed
+}
+
+// joinCredentials creates mesh credentials and a worker token for a worker
+// joining the wonder net, within the wonder net's quotas. The token is
+// recorded with the authkey, so that it binds to the node joining with it.
+func (s *WorkerService) joinCredentials(ctx context.Context, wonderNet *repository.WonderNet) (*JoinCredentials, error) {
+	credentials, err := s.meshCredentials(ctx, wonderNet)
+	if err != nil {
+		return nil, err
+	}
+
+	workerToken, claims, err := s.tokenGenerator.IssueWorkerToken(wonderNet.ID)
+	if err != nil {
+		return nil, fmt.Errorf("generate worker token: %w", err)
+	}
+	err = s.workerTokenRepository.Create(ctx, &repository.WorkerToken{
+		ID:          claims.ID,
+		WonderNetID: wonderNet.ID,
+		AuthKeyID:   authKeyID(credentials.Metadata),
+		ExpiresAt:   time.Now().Add(WorkerTokenIdleTTL),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("record worker token: %w", err)
+	}
+	credentials.WorkerToken = workerToken
+	return credentials, nil
+}
+
+// authKeyID returns the authkey_id of mesh credentials, or "" if the mesh
+// backend doesn't report one.
+func authKeyID(metadata map[string]any) string {
+	id, _ := metadata["authkey_id"].(string)
+	return id
+}
+
+// meshCredentials creates a single-use authkey for the wonder net, within
+// its quotas.
+func (s *WorkerService) meshCredentials(ctx context.Context, wonderNet *repository.WonderNet) (*JoinCredentials, error) {
+	if err := s.quotaService.ReserveAuthKey(ctx, wonderNet); err != nil {
+		return nil, err
+	}
+
 	metadata, err := s.meshBackend.CreateJoinCredentials(ctx, wonderNet.HeadscaleUser, meshbackend.JoinOptions{
-		TTL:       24 * time.Hour,
-		Reusable:  false,
-		Ephemeral: false,
+		TTL:        24 * time.Hour,
+		Reusable:   false,
+		Ephemeral:  false,
+		ControlURL: wonderNet.PublicURL,
 	})
 	if err != nil {
 		return nil, err
 	}
 
+	caKey, err := s.sshCAService.PublicKey(wonderNet.ID)
+	if err != nil {
+		return nil, err
+	}
 	return &JoinCredentials{
-		MeshType: string(s.meshBackend.MeshType()),
-		Metadata: metadata,
+		MeshType:       string(s.meshBackend.MeshType()),
+		Metadata:       metadata,
+		SSHCAPublicKey: caKey,
 	}, nil
 }
+
+// RenewJoinCredentials creates fresh mesh credentials for a worker that
+// already joined, authenticated by its worker token as in
+// AuthenticateWorker. Workers use it to log in again when their authkey
+// expired before it was used or their node key expired. The worker keeps
+// its token, so none is returned.
+//
+// A token bound to a node that was removed from the wonder net is revoked
+// and ErrInvalidToken returned. Renewal is refused with
+// ErrRenewalNotAllowed while the bound node is online or being
+// decommissioned, and with ErrWorkerNotJoined for a token not bound to a
+// node, which would otherwise mint authkeys without limit. A worker whose
+// authkey expired before it joined needs a new join token.
+func (s *WorkerService) RenewJoinCredentials(ctx context.Context, workerToken, hostWonderNetID string, clientIP netip.Addr) (*JoinCredentials, error) {
+	worker, err := s.AuthenticateWorker(ctx, workerToken, hostWonderNetID, clientIP)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.checkRenewal(ctx, worker); err != nil {
+		return nil, err
+	}
+
+	credentials, err := s.meshCredentials(ctx, worker.WonderNet)
+	if err != nil {
+		if errors.Is(err, ErrQuotaExceeded) {
+			metrics.WorkerJoins.WithLabelValues("quota_exceeded").Inc()
+		} else {
+			metrics.WorkerJoins.WithLabelValues("error").Inc()
+		}
+		return nil, err
+	}
+	if err := s.workerTokenRepository.UpdateAuthKey(ctx, worker.Token.ID, authKeyID(credentials.Metadata)); err != nil {
+		return nil, fmt.Errorf("record worker token authkey: %w", err)
+	}
+
+	metrics.WorkerJoins.WithLabelValues("renewed").Inc()
+	slog.Info("renewed worker join credentials", "wonder_net_id", worker.WonderNet.ID, "node_id", worker.NodeID)
+	return credentials, nil
+}
+
+// checkRenewal checks that the worker is bound to a node and that the node
+// may log in again.
+func (s *WorkerService) checkRenewal(ctx context.Context, worker *Worker) error {
+	if worker.NodeID == "" {
+		return ErrWorkerNotJoined
+	}
+	nodes, err := s.meshBackend.ListNodes(ctx, worker.WonderNet.HeadscaleUser)
+	if err != nil {
+		return fmt.Errorf("list nodes: %w", err)
+	}
+	i := slices.IndexFunc(nodes, func(n *meshbackend.Node) bool { return n.ID == worker.NodeID })
+	if i < 0 {
+		if err := s.workerTokenRepository.RevokeByNode(ctx, worker.WonderNet.ID, worker.NodeID, ""); err != nil {
+			return fmt.Errorf("revoke worker tokens: %w", err)
+		}
+		slog.Warn("refused renewal for a removed node", "wonder_net_id", worker.WonderNet.ID, "node_id", worker.NodeID)
+		return ErrInvalidToken
+	}
+	if nodes[i].Online {
+		return ErrRenewalNotAllowed
+	}
+
+	decommission, err := s.decommissionRepository.GetActiveByNode(ctx, worker.NodeID)
+	if err != nil {
+		return fmt.Errorf("get node decommission: %w", err)
+	}
+	if decommission != nil {
+		return ErrRenewalNotAllowed
+	}
+	return nil
+}
+
+// Worker is a worker authenticated by its worker token. NodeID is the node
+// the token is bound to, empty until the worker's node has been seen.
+type Worker struct {
+	WonderNet *repository.WonderNet
+	Token     *repository.WorkerToken
+	NodeID    string
+}
+
+// AuthenticateWorker validates a worker token and returns the worker.
+// Returns ErrInvalidToken if the token is invalid, unknown, revoked or
+// expired, its wonder net is gone, or it belongs to another wonder net than
+// hostWonderNetID (see ExchangeJoinToken).
+//
+// A token not yet bound to a node is bound to the node of its wonder net
+// that joined with the token's authkey or, for mesh backends that don't
+// report authkeys, that has clientIP as its mesh address.
+func (s *WorkerService) AuthenticateWorker(ctx context.Context, workerToken, hostWonderNetID string, clientIP netip.Addr) (*Worker, error) {
+	validator := jointoken.NewValidator(s.jwtSecret)
+	claims, err := validator.ValidateWorkerToken(workerToken)
+	if err != nil {
+		return nil, ErrInvalidToken
+	}
+	if hostWonderNetID != "" && claims.WonderNetID != hostWonderNetID {
+		return nil, ErrInvalidToken
+	}
+
+	record, err := s.workerTokenRepository.Get(ctx, claims.ID)
+	if err != nil {
+		return nil, fmt.Errorf("get worker token: %w", err)
+	}
+	now := time.Now()
+	if record == nil || record.WonderNetID != claims.WonderNetID || record.RevokedAt != nil || now.After(record.ExpiresAt) {
+		return nil, ErrInvalidToken
+	}
+
+	wonderNet, err := s.wonderNetRepository.Get(ctx, claims.WonderNetID)
+	if err != nil {
+		return nil, fmt.Errorf("get wonder net: %w", err)
+	}
+	if wonderNet == nil {
+		return nil, ErrInvalidToken
+	}
+
+	worker := &Worker{WonderNet: wonderNet, Token: record, NodeID: record.NodeID}
+	if worker.NodeID == "" {
+		nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+		if err != nil {
+			return nil, fmt.Errorf("list nodes: %w", err)
+		}
+		node := workerNode(nodes, record.AuthKeyID, clientIP)
+		if node == nil {
+			return worker, nil
+		}
+		worker.NodeID = node.ID
+		slog.Info("bound worker token to node", "wonder_net_id", wonderNet.ID, "node_id", node.ID)
+	}
+
+	if err := s.workerTokenRepository.Touch(ctx, record.ID, worker.NodeID, now.Add(WorkerTokenIdleTTL)); err != nil {
+		return nil, fmt.Errorf("touch worker token: %w", err)
+	}
+	return worker, nil
+}
+
+// workerNode returns the node that joined with the authkey authKeyID or,
+// failing that, the node with clientIP as a mesh address.
+func workerNode(nodes []*meshbackend.Node, authKeyID string, clientIP netip.Addr) *meshbackend.Node {
+	if authKeyID != "" {
+		if i := slices.IndexFunc(nodes, func(n *meshbackend.Node) bool { return n.AuthKeyID == authKeyID }); i >= 0 {
+			return nodes[i]
+		}
+	}
+	if clientIP.IsValid() {
+		if i := slices.IndexFunc(nodes, func(n *meshbackend.Node) bool { return slices.Contains(n.Addresses, clientIP.String()) }); i >= 0 {
+			return nodes[i]
+		}
+	}
+	return nil
+}
+
+// RecordHeartbeat validates a worker token and stores the heartbeat for the
+// node the token is bound to, and returns the node's ID. Returns
+// ErrInvalidToken if the token is rejected by AuthenticateWorker, and
+// ErrNodeNotFound if the token is not bound yet or its node is no longer in
+// the wonder net.
+func (s *WorkerService) RecordHeartbeat(ctx context.Context, workerToken, hostWonderNetID string, clientIP netip.Addr, hb *Heartbeat) (string, error) {
+	worker, err := s.AuthenticateWorker(ctx, workerToken, hostWonderNetID, clientIP)
+	if err != nil {
+		return "", err
+	}
+	if worker.NodeID == "" {
+		return "", ErrNodeNotFound
+	}
+	wonderNet := worker.WonderNet
+
+	nodes, err := s.meshBackend.ListNodes(ctx, wonderNet.HeadscaleUser)
+	if err != nil {
+		return "", fmt.Errorf("list nodes: %w", err)
+	}
+	if !slices.ContainsFunc(nodes, func(n *meshbackend.Node) bool { return n.ID == worker.NodeID }) {
+		return "", ErrNodeNotFound
+	}
+
+	connectivity, err := encodeConnectivity(hb.Connectivity)
+	if err != nil {
+		return "", err
+	}
+
+	err = s.nodeHeartbeatRepository.Upsert(ctx, &repository.NodeHeartbeat{
+		NodeID:               worker.NodeID,
+		WonderNetID:          wonderNet.ID,
+		OS:                   hb.OS,
+		TailscaleVersion:     hb.TailscaleVersion,
+		DiskTotalBytes:       hb.DiskTotalBytes,
+		DiskFreeBytes:        hb.DiskFreeBytes,
+		MemoryTotalBytes:     hb.MemoryTotalBytes,
+		MemoryAvailableBytes: hb.MemoryAvailableBytes,
+		Connectivity:         connectivity,
+		ReportedAt:           time.Now(),
+	})
+	if err != nil {
+		return "", err
+	}
+	return worker.NodeID, nil
+}
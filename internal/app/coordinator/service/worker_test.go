@@ -0,0 +1,122 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/netip"
+	"testing"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/internal/app/coordinator/repository"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+func TestWorkerNode(t *testing.T) {
+	nodes := []*meshbackend.Node{
+		{ID: "1", AuthKeyID: "7", Addresses: []string{"100.64.0.1"}},
+		{ID: "2", AuthKeyID: "8", Addresses: []string{"100.64.0.2"}},
+		{ID: "3", Addresses: []string{"100.64.0.3"}},
+	}
+
+	tests := []struct {
+		name      string
+		authKeyID string
+		clientIP  netip.Addr
+		wantID    string
+	}{
+		{"authkey", "8", netip.Addr{}, "2"},
+		{"authkey before client IP", "8", netip.MustParseAddr("100.64.0.1"), "2"},
+		{"client IP on the mesh", "", netip.MustParseAddr("100.64.0.3"), "3"},
+		{"unused authkey", "9", netip.MustParseAddr("203.0.113.5"), ""},
+		{"nothing to go by", "", netip.Addr{}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var gotID string
+			if node := workerNode(nodes, tt.authKeyID, tt.clientIP); node != nil {
+				gotID = node.ID
+			}
+			if gotID != tt.wantID {
+				t.Errorf("workerNode() = %q, want %q", gotID, tt.wantID)
+			}
+		})
+	}
+}
+
+func TestCheckRenewalRefusesUnboundWorker(t *testing.T) {
+	// Unbound workers are refused before the mesh is asked for their node,
+	// so no backend is needed.
+	s := &WorkerService{}
+	worker := &Worker{WonderNet: &repository.WonderNet{ID: "wn-1"}, Token: &repository.WorkerToken{ID: "jti-1"}}
+	if err := s.checkRenewal(context.Background(), worker); !errors.Is(err, ErrWorkerNotJoined) {
+		t.Errorf("checkRenewal() error = %v, want %v", err, ErrWorkerNotJoined)
+	}
+}
+
+func TestGenerateJoinTokenRejectsInvalidMaxUses(t *testing.T) {
+	// The check comes before the token is issued or recorded.
+	s := &WorkerService{}
+	for _, maxUses := range []int{-1, MaxJoinTokenUses + 1} {
+		if _, err := s.GenerateJoinToken(context.Background(), &repository.WonderNet{ID: "wn"}, "me", time.Hour, maxUses, nil); !errors.Is(err, ErrInvalidJoinTokenUses) {
+			t.Errorf("GenerateJoinToken(maxUses=%d) error = %v, want %v", maxUses, err, ErrInvalidJoinTokenUses)
+		}
+	}
+}
+
+func TestGenerateJoinTokenRejectsInvalidAllowedCIDRs(t *testing.T) {
+	s := &WorkerService{}
+	if _, err := s.GenerateJoinToken(context.Background(), &repository.WonderNet{ID: "wn"}, "me", time.Hour, 1, []string{"10.0.0.0/33"}); !errors.Is(err, ErrInvalidAllowedCIDR) {
+		t.Errorf("GenerateJoinToken() error = %v, want %v", err, ErrInvalidAllowedCIDR)
+	}
+}
+
+func TestGenerateJoinTokenRejectsTTLOutsidePolicy(t *testing.T) {
+	s := &WorkerService{}
+	wonderNet := &repository.WonderNet{ID: "wn", JoinTokenMaxTTL: 72 * time.Hour}
+	for _, ttl := range []time.Duration{time.Second, 73 * time.Hour} {
+		if _, err := s.GenerateJoinToken(context.Background(), wonderNet, "me", ttl, 1, nil); !errors.Is(err, ErrInvalidJoinTokenTTL) {
+			t.Errorf("GenerateJoinToken(ttl=%s) error = %v, want %v", ttl, err, ErrInvalidJoinTokenTTL)
+		}
+	}
+}
+
+func TestJoinTokenTTLPolicy(t *testing.T) {
+	tests := []struct {
+		name                 string
+		wonderNet            repository.WonderNet
+		wantDefault, wantMax time.Duration
+	}{
+		{name: "coordinator defaults", wantDefault: DefaultJoinTokenTTL, wantMax: MaxJoinTokenTTL},
+		{name: "custom", wonderNet: repository.WonderNet{JoinTokenDefaultTTL: 24 * time.Hour, JoinTokenMaxTTL: 72 * time.Hour}, wantDefault: 24 * time.Hour, wantMax: 72 * time.Hour},
+		{name: "maximum below default", wonderNet: repository.WonderNet{JoinTokenMaxTTL: time.Hour}, wantDefault: time.Hour, wantMax: time.Hour},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			gotDefault, gotMax := JoinTokenTTLPolicy(&tt.wonderNet)
+			if gotDefault != tt.wantDefault || gotMax != tt.wantMax {
+				t.Errorf("JoinTokenTTLPolicy() = %s, %s, want %s, %s", gotDefault, gotMax, tt.wantDefault, tt.wantMax)
+			}
+		})
+	}
+}
+
+func TestValidateJoinTokenTTLPolicy(t *testing.T) {
+	tests := []struct {
+		defaultTTL, maxTTL time.Duration
+		wantErr            bool
+	}{
+		{0, 0, false},
+		{24 * time.Hour, 72 * time.Hour, false},
+		{0, 72 * time.Hour, false},
+		{72 * time.Hour, 24 * time.Hour, true},
+		{time.Second, 0, true},
+		{0, MaxJoinTokenTTL + time.Hour, true},
+	}
+	for _, tt := range tests {
+		err := ValidateJoinTokenTTLPolicy(tt.defaultTTL, tt.maxTTL)
+		if (err != nil) != tt.wantErr || (err != nil && !errors.Is(err, ErrInvalidJoinTokenTTL)) {
+			t.Errorf("ValidateJoinTokenTTLPolicy(%s, %s) error = %v, want error %v", tt.defaultTTL, tt.maxTTL, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,81 @@
+package coordinator
+
+import (
+	"net"
+	"net/url"
+	"strings"
+)
+
+// minSecretDistinctChars is the fewest distinct characters a secret may have
+// in strict mode. Random hex has 16; repeated or patterned values have few.
+const minSecretDistinctChars = 10
+
+// weakSecretWords are placeholder words found in example and test secrets.
+var weakSecretWords = []string{"secret", "changeme", "change-me", "example", "password", "default", "test"}
+
+// StrictViolations returns the production hardening checks the configuration
+// fails, one message per check, or nil if it passes all of them. Strict mode
+// refuses to start the coordinator with any violation instead of running
+// with the weak setting.
+func StrictViolations(config *Config) []string {
+	var violations []string
+
+	if reason := weakSecret(config.JWTSecret); reason != "" {
+		violations = append(violations, "JWT_SECRET "+reason+"; generate one with: openssl rand -hex 32")
+	}
+	if config.EnableAdminAPI {
+		if reason := weakSecret(config.AdminAPIAuthToken); reason != "" {
+			violations = append(violations, "ADMIN_API_AUTH_TOKEN "+reason)
+		}
+	}
+
+	if reason := plaintextPublicURL(config.PublicURL); reason != "" {
+		violations = append(violations, "public URL "+config.PublicURL+" "+reason)
+	}
+
+	if config.Fixtures {
+		violations = append(violations, "fixtures are enabled; they serve synthetic WonderNets and nodes")
+	}
+
+	return violations
+}
+
+// weakSecret describes why secret is weak, or returns an empty string.
+func weakSecret(secret string) string {
+	lower := strings.ToLower(secret)
+	for _, word := range weakSecretWords {
+		if strings.Contains(lower, word) {
+			return "contains the placeholder word " + word
+		}
+	}
+
+	distinct := make(map[rune]struct{})
+	for _, r := range secret {
+		distinct[r] = struct{}{}
+	}
+	if len(distinct) < minSecretDistinctChars {
+		return "is too repetitive to be random"
+	}
+	return ""
+}
+
+// plaintextPublicURL describes why the public URL sends sessions and join
+// tokens in plain text, or returns an empty string. Loopback URLs are
+// allowed since their traffic never leaves the host.
+func plaintextPublicURL(publicURL string) string {
+	u, err := url.Parse(publicURL)
+	if err != nil {
+		return "is invalid"
+	}
+	if u.Scheme == "https" {
+		return ""
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return ""
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return ""
+	}
+	return "is not HTTPS"
+}
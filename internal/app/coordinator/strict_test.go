@@ -0,0 +1,72 @@
+package coordinator
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestStrictViolations(t *testing.T) {
+	strong := func() *Config {
+		return &Config{
+			PublicURL:         "https://wonder.example.com",
+			JWTSecret:         "9f2c4e71b8a05d36e1f7c2a9b4d80e5f3a6c1b7d9e2f4a08c5b3d6e1f7a2c9b4",
+			EnableAdminAPI:    true,
+			AdminAPIAuthToken: "Qm7vX2pL9kR4tW8zN3cJ6hF1dS5gB0yA",
+		}
+	}
+
+	tests := []struct {
+		name   string
+		modify func(c *Config)
+		want   []string
+	}{
+		{name: "hardened", modify: func(c *Config) {}},
+		{name: "loopback http public URL", modify: func(c *Config) { c.PublicURL = "http://localhost:9080" }},
+		{name: "loopback IP http public URL", modify: func(c *Config) { c.PublicURL = "http://127.0.0.1:9080" }},
+		{
+			name:   "plaintext public URL",
+			modify: func(c *Config) { c.PublicURL = "http://wonder.example.com" },
+			want:   []string{"public URL"},
+		},
+		{
+			name:   "placeholder JWT secret",
+			modify: func(c *Config) { c.JWTSecret = "jwt-secret-that-is-at-least-32-bytes" },
+			want:   []string{"JWT_SECRET"},
+		},
+		{
+			name:   "repetitive JWT secret",
+			modify: func(c *Config) { c.JWTSecret = strings.Repeat("ab", 20) },
+			want:   []string{"JWT_SECRET"},
+		},
+		{
+			name:   "weak admin token ignored without admin API",
+			modify: func(c *Config) { c.EnableAdminAPI = false; c.AdminAPIAuthToken = strings.Repeat("a", 32) },
+		},
+		{
+			name: "every check",
+			modify: func(c *Config) {
+				c.PublicURL = "http://wonder.example.com"
+				c.JWTSecret = strings.Repeat("x", 32)
+				c.AdminAPIAuthToken = "changeme-changeme-changeme-changeme"
+				c.Fixtures = true
+			},
+			want: []string{"JWT_SECRET", "ADMIN_API_AUTH_TOKEN", "public URL", "fixtures"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			config := strong()
+			tt.modify(config)
+			got := StrictViolations(config)
+			if len(got) != len(tt.want) {
+				t.Fatalf("StrictViolations() = %q, want %d violations", got, len(tt.want))
+			}
+			for i, prefix := range tt.want {
+				if !strings.HasPrefix(got[i], prefix) {
+					t.Errorf("StrictViolations()[%d] = %q, want prefix %q", i, got[i], prefix)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,111 @@
+package coordinator
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Default request timeouts. Admin requests such as listing the nodes of
+// every wonder net call Headscale once per wonder net, so they get longer.
+const (
+	DefaultRequestTimeout      = 30 * time.Second
+	DefaultAdminRequestTimeout = 2 * time.Minute
+)
+
+// TimeoutErrorResponse is the body of a 504 Gateway Timeout response.
+type TimeoutErrorResponse struct {
+	Error   string `json:"error"`
+	Message string `json:"message"`
+}
+
+// requestTimeout returns how long a request to path may take, or zero for
+// no limit. Node watches stream for as long as the client stays connected,
+// and the web UI and Headscale proxy are not bounded here.
+func requestTimeout(path string, apiTimeout, adminTimeout time.Duration) time.Duration {
+	switch {
+	case path == "/coordinator/api/v1/nodes/watch":
+		return 0
+	case strings.HasPrefix(path, "/coordinator/admin/api/"):
+		return adminTimeout
+	case strings.HasPrefix(path, "/coordinator/api/"),
+		strings.HasPrefix(path, "/coordinator/auth/"),
+		strings.HasPrefix(path, "/coordinator/oidc/"):
+		return apiTimeout
+	}
+	return 0
+}
+
+// withRequestTimeout sets a deadline on the context of API requests, so
+// that handlers waiting on a slow Headscale, Keycloak, or database give up
+// instead of hanging. A handler that fails with a server error once the
+// deadline has passed answers 504 Gateway Timeout with a
+// TimeoutErrorResponse instead. A zero timeout disables the deadline.
+func withRequestTimeout(apiTimeout, adminTimeout time.Duration, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		timeout := requestTimeout(r.URL.Path, apiTimeout, adminTimeout)
+		if timeout <= 0 {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+		tw := &timeoutResponseWriter{ResponseWriter: w, ctx: ctx, timeout: timeout}
+		next.ServeHTTP(tw, r.WithContext(ctx))
+		if tw.timedOut {
+			slog.Warn("request timed out", "method", r.Method, "path", r.URL.Path, "timeout", timeout)
+		}
+	})
+}
+
+// timeoutResponseWriter replaces a server error written after the request
+// deadline with a 504 response.
+type timeoutResponseWriter struct {
+	http.ResponseWriter
+	ctx         context.Context
+	timeout     time.Duration
+	wroteHeader bool
+	timedOut    bool
+}
+
+func (w *timeoutResponseWriter) WriteHeader(code int) {
+	if w.wroteHeader {
+		return
+	}
+	w.wroteHeader = true
+
+	if code < http.StatusInternalServerError || !errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.ResponseWriter.WriteHeader(code)
+		return
+	}
+	w.timedOut = true
+	h := w.Header()
+	h.Del("Content-Length")
+	h.Set("Content-Type", "application/json")
+	w.ResponseWriter.WriteHeader(http.StatusGatewayTimeout)
+	_ = json.NewEncoder(w.ResponseWriter).Encode(TimeoutErrorResponse{
+		Error:   "upstream_timeout",
+		Message: "the request did not complete within " + w.timeout.String(),
+	})
+}
+
+// Write drops the handler's error message once it was replaced.
+func (w *timeoutResponseWriter) Write(b []byte) (int, error) {
+	if !w.wroteHeader {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.timedOut {
+		return len(b), nil
+	}
+	return w.ResponseWriter.Write(b)
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (w *timeoutResponseWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
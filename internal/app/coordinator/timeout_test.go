@@ -0,0 +1,76 @@
+package coordinator
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+// slowUpstream waits for the request to be cancelled, as a handler whose
+// Headscale call hangs would, and fails like the controllers do.
+var slowUpstream = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	<-r.Context().Done()
+	http.Error(w, "list nodes", http.StatusInternalServerError)
+})
+
+func TestWithRequestTimeout_GatewayTimeout(t *testing.T) {
+	handler := withRequestTimeout(10*time.Millisecond, time.Hour, slowUpstream)
+
+	req := httptest.NewRequest(http.MethodGet, "/coordinator/api/v1/nodes", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusGatewayTimeout {
+		t.Fatalf("status = %d, want %d", rec.Code, http.StatusGatewayTimeout)
+	}
+	if got := rec.Header().Get("Content-Type"); got != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", got)
+	}
+	var body TimeoutErrorResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &body); err != nil {
+		t.Fatalf("decode body %q: %v", rec.Body.String(), err)
+	}
+	if body.Error != "upstream_timeout" || body.Message == "" {
+		t.Errorf("body = %+v, want an upstream_timeout error", body)
+	}
+}
+
+func TestWithRequestTimeout_KeepsOtherResponses(t *testing.T) {
+	handler := withRequestTimeout(time.Hour, time.Hour, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := r.Context().Deadline(); !ok {
+			t.Error("API request has no deadline")
+		}
+		http.Error(w, "list nodes", http.StatusInternalServerError)
+	}))
+
+	req := httptest.NewRequest(http.MethodGet, "/coordinator/api/v1/nodes", nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusInternalServerError || rec.Body.String() != "list nodes\n" {
+		t.Errorf("response = %d %q, want the handler's 500", rec.Code, rec.Body.String())
+	}
+}
+
+func TestRequestTimeout(t *testing.T) {
+	const api, admin = 30 * time.Second, 2 * time.Minute
+	tests := []struct {
+		path string
+		want time.Duration
+	}{
+		{"/coordinator/api/v1/nodes", api},
+		{"/coordinator/api/v1/worker/join", api},
+		{"/coordinator/oidc/callback", api},
+		{"/coordinator/admin/api/v1/nodes", admin},
+		{"/coordinator/api/v1/nodes/watch", 0},
+		{"/ui/", 0},
+		{"/ts2021", 0},
+	}
+	for _, tt := range tests {
+		if got := requestTimeout(tt.path, api, admin); got != tt.want {
+			t.Errorf("requestTimeout(%q) = %s, want %s", tt.path, got, tt.want)
+		}
+	}
+}
@@ -3,14 +3,27 @@ package headscale
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
+	"reflect"
 	"slices"
 	"sync"
+	"time"
 
 	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
 )
 
+// ErrPolicyConflict is returned when the policy stored in Headscale changed
+// since the ACLManager last read or wrote it, such as by another coordinator
+// replica.
+var ErrPolicyConflict = errors.New("ACL policy changed concurrently")
+
+// maxPolicyUpdateAttempts is how often read-modify-write policy updates are
+// tried before giving up on concurrent changes.
+const maxPolicyUpdateAttempts = 5
+
 // PrivilegedTag is the Headscale tag carried by nodes that belong to a
 // privileged network. Nodes assigned this tag (via forced_tags) reach every
 // node in the mesh.
@@ -41,6 +54,7 @@ type ACLRule struct {
 	Action       string   `json:"action"`
 	Sources      []string `json:"src"`
 	Destinations []string `json:"dst"`
+	Protocol     string   `json:"proto,omitempty"`
 }
 
 // GenerateWonderNetIsolationPolicy generates an ACL policy that isolates wonder nets
@@ -140,15 +154,184 @@ func GenerateTaggedHubSpokePolicy(privilegedTagOwners []string) *ACLPolicy {
 	return policy
 }
 
-// ACLManager manages ACL policies in Headscale
+// ACLManager manages ACL policies in Headscale.
+//
+// Updates within a process are serialized by mu. Headscale has no
+// compare-and-set for policies, so updates that build on the stored policy
+// use its update time as a version: before writing, the manager checks that
+// the stored policy is still the one it last read or wrote, and otherwise
+// reads it again and retries, rather than dropping the rules another
+// coordinator replica added in between.
 type ACLManager struct {
 	client v1.HeadscaleServiceClient
 	mu     sync.Mutex
+
+	// base is the last policy generated by the coordinator, before the
+	// wonder net policies are merged in.
+	base *ACLPolicy
+	// version is the update time of the policy stored in Headscale when
+	// the manager last read or wrote it, zero before that.
+	version time.Time
+	// wonderNetPolicies holds the policy fragments merged after base, keyed
+	// by the Headscale user they belong to plus an optional suffix when a
+	// wonder net has more than one fragment.
+	wonderNetPolicies map[string]*ACLPolicy
 }
 
 // NewACLManager creates a new ACLManager
 func NewACLManager(client v1.HeadscaleServiceClient) *ACLManager {
-	return &ACLManager{client: client}
+	return &ACLManager{
+		client:            client,
+		wonderNetPolicies: make(map[string]*ACLPolicy),
+	}
+}
+
+// MergeWonderNetPolicies returns base with the wonder net policy fragments
+// appended after its own rules, in key order so the result is deterministic.
+// Neither input is modified.
+func MergeWonderNetPolicies(base *ACLPolicy, wonderNetPolicies map[string]*ACLPolicy) *ACLPolicy {
+	merged := &ACLPolicy{
+		ACLs:   slices.Clone(base.ACLs),
+		Groups: base.Groups,
+		Hosts:  base.Hosts,
+	}
+	if len(base.TagOwners) > 0 {
+		merged.TagOwners = maps.Clone(base.TagOwners)
+	}
+
+	for _, user := range slices.Sorted(maps.Keys(wonderNetPolicies)) {
+		fragment := wonderNetPolicies[user]
+		merged.ACLs = append(merged.ACLs, fragment.ACLs...)
+		for tag, owners := range fragment.TagOwners {
+			if merged.TagOwners == nil {
+				merged.TagOwners = make(map[string][]string)
+			}
+			merged.TagOwners[tag] = owners
+		}
+	}
+	return merged
+}
+
+// applyPolicy records base as the coordinator-generated policy and writes it
+// to Headscale merged with the wonder net policies. With checkVersion set,
+// it first returns ErrPolicyConflict if the stored policy changed since the
+// manager last read or wrote it; the check and the write are not atomic, so
+// this narrows rather than closes the window for lost updates. Callers must
+// hold am.mu.
+func (am *ACLManager) applyPolicy(ctx context.Context, base *ACLPolicy, checkVersion bool) error {
+	policyJSON, err := json.Marshal(MergeWonderNetPolicies(base, am.wonderNetPolicies))
+	if err != nil {
+		return fmt.Errorf("marshal policy: %w", err)
+	}
+
+	if checkVersion && !am.version.IsZero() {
+		resp, err := am.client.GetPolicy(ctx, &v1.GetPolicyRequest{})
+		if err != nil {
+			return fmt.Errorf("get policy: %w", err)
+		}
+		if !resp.GetUpdatedAt().AsTime().Equal(am.version) {
+			return ErrPolicyConflict
+		}
+	}
+
+	resp, err := am.client.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: string(policyJSON)})
+	if err != nil {
+		return err
+	}
+	am.base = base
+	am.version = resp.GetUpdatedAt().AsTime()
+	return nil
+}
+
+// storedBase reads the policy stored in Headscale and returns it without
+// the rules and tag owners of the wonder net policies and of extra, which
+// the manager merges in itself. Callers must hold am.mu.
+func (am *ACLManager) storedBase(ctx context.Context, extra ...*ACLPolicy) (*ACLPolicy, error) {
+	resp, err := am.client.GetPolicy(ctx, &v1.GetPolicyRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("get policy: %w", err)
+	}
+
+	var policy ACLPolicy
+	if policyStr := resp.GetPolicy(); policyStr != "" {
+		if err := json.Unmarshal([]byte(policyStr), &policy); err != nil {
+			return nil, fmt.Errorf("unmarshal policy: %w", err)
+		}
+	}
+
+	fragments := append(slices.Collect(maps.Values(am.wonderNetPolicies)), extra...)
+	for _, fragment := range fragments {
+		if fragment == nil {
+			continue
+		}
+		policy.ACLs = slices.DeleteFunc(policy.ACLs, func(rule ACLRule) bool {
+			return slices.ContainsFunc(fragment.ACLs, func(r ACLRule) bool { return reflect.DeepEqual(r, rule) })
+		})
+		for tag := range fragment.TagOwners {
+			delete(policy.TagOwners, tag)
+		}
+	}
+
+	am.version = resp.GetUpdatedAt().AsTime()
+	return &policy, nil
+}
+
+// LoadWonderNetPolicies adds or replaces wonder net policy fragments without
+// writing to Headscale. It is meant to be called at startup, before the base
+// policy is set, so that the first write already includes them.
+func (am *ACLManager) LoadWonderNetPolicies(policies map[string]*ACLPolicy) {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	maps.Copy(am.wonderNetPolicies, policies)
+}
+
+// SetWonderNetPolicy replaces one wonder net policy fragment and writes the
+// merged policy to Headscale. A nil or empty policy removes the fragment.
+// Setting an unchanged fragment does not write. If Headscale rejects the
+// merged policy, the previous fragment is kept.
+func (am *ACLManager) SetWonderNetPolicy(ctx context.Context, key string, policy *ACLPolicy) error {
+	am.mu.Lock()
+	defer am.mu.Unlock()
+
+	if am.base == nil {
+		return fmt.Errorf("ACL policy not initialized")
+	}
+
+	if policy != nil && len(policy.ACLs) == 0 {
+		policy = nil
+	}
+	previous, hadPrevious := am.wonderNetPolicies[key]
+	if reflect.DeepEqual(previous, policy) {
+		return nil
+	}
+
+	if policy == nil {
+		delete(am.wonderNetPolicies, key)
+	} else {
+		am.wonderNetPolicies[key] = policy
+	}
+
+	base := am.base
+	for attempt := 1; ; attempt++ {
+		err := am.applyPolicy(ctx, base, true)
+		if err == nil {
+			return nil
+		}
+		if errors.Is(err, ErrPolicyConflict) && attempt < maxPolicyUpdateAttempts {
+			slog.Info("ACL policy changed concurrently, retrying", "wonder_net_policy", key, "attempt", attempt)
+			if base, err = am.storedBase(ctx, previous); err == nil {
+				continue
+			}
+		}
+
+		if hadPrevious {
+			am.wonderNetPolicies[key] = previous
+		} else {
+			delete(am.wonderNetPolicies, key)
+		}
+		return fmt.Errorf("set policy: %w", err)
+	}
 }
 
 // SetWonderNetIsolationPolicy sets the wonder net isolation ACL policy
@@ -168,13 +351,7 @@ func (am *ACLManager) SetWonderNetIsolationPolicy(ctx context.Context) error {
 	}
 
 	policy := GenerateWonderNetIsolationPolicy(usernames)
-	policyJSON, err := json.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf("marshal policy: %w", err)
-	}
-
-	_, err = am.client.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: string(policyJSON)})
-	return err
+	return am.applyPolicy(ctx, policy, false)
 }
 
 // SetHubSpokePolicy sets an ACL policy where privileged namespaces can access
@@ -202,13 +379,7 @@ func (am *ACLManager) SetHubSpokePolicy(ctx context.Context, privilegedUsers []s
 	}
 
 	policy := GenerateHubSpokePolicy(privilegedUsers, normalUsers)
-	policyJSON, err := json.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf("marshal policy: %w", err)
-	}
-
-	_, err = am.client.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: string(policyJSON)})
-	return err
+	return am.applyPolicy(ctx, policy, false)
 }
 
 // SetTaggedHubSpokePolicy writes the constant-size tag-based policy. It does
@@ -218,13 +389,7 @@ func (am *ACLManager) SetTaggedHubSpokePolicy(ctx context.Context, privilegedUse
 	defer am.mu.Unlock()
 
 	policy := GenerateTaggedHubSpokePolicy(privilegedUsers)
-	policyJSON, err := json.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf("marshal policy: %w", err)
-	}
-
-	_, err = am.client.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: string(policyJSON)})
-	return err
+	return am.applyPolicy(ctx, policy, false)
 }
 
 // EnsurePrivilegedTags assigns PrivilegedTag to every node owned by a user in
@@ -278,7 +443,10 @@ func (am *ACLManager) EnsurePrivilegedTags(ctx context.Context, privilegedUsers
 	return nil
 }
 
-// AddWonderNetToPolicy adds a wonder net to the isolation policy.
+// AddWonderNetToPolicy adds a wonder net to the isolation policy. If the
+// stored policy changed concurrently, such as by another coordinator replica
+// adding its wonder net, it reads the policy again and retries, so that
+// neither addition is lost.
 //
 // Only the legacy per-user policy path calls this. When UseTaggedACL is
 // enabled the constant-size policy covers every WonderNet via autogroup:self,
@@ -288,17 +456,32 @@ func (am *ACLManager) AddWonderNetToPolicy(ctx context.Context, username string)
 	am.mu.Lock()
 	defer am.mu.Unlock()
 
-	resp, err := am.client.GetPolicy(ctx, &v1.GetPolicyRequest{})
-	if err != nil {
-		return fmt.Errorf("get policy: %w", err)
+	for attempt := 1; ; attempt++ {
+		err := am.addWonderNetRule(ctx, username)
+		if !errors.Is(err, ErrPolicyConflict) || attempt == maxPolicyUpdateAttempts {
+			return err
+		}
+		slog.Info("ACL policy changed concurrently, retrying", "user", username, "attempt", attempt)
+		// Build on the stored policy, which has the other writer's changes.
+		am.base = nil
 	}
+}
 
-	policyStr := resp.GetPolicy()
+// addWonderNetRule makes one attempt of AddWonderNetToPolicy. Callers must
+// hold am.mu.
+func (am *ACLManager) addWonderNetRule(ctx context.Context, username string) error {
+	// Until the coordinator has written a policy in this process, the policy
+	// currently stored in Headscale is the base.
 	var policy ACLPolicy
-	if policyStr != "" {
-		if err := json.Unmarshal([]byte(policyStr), &policy); err != nil {
-			return fmt.Errorf("unmarshal policy: %w", err)
+	if am.base != nil {
+		policy = *am.base
+		policy.ACLs = slices.Clone(am.base.ACLs)
+	} else {
+		stored, err := am.storedBase(ctx)
+		if err != nil {
+			return err
 		}
+		policy = *stored
 	}
 
 	newRule := ACLRule{
@@ -315,11 +498,5 @@ func (am *ACLManager) AddWonderNetToPolicy(ctx context.Context, username string)
 
 	policy.ACLs = append(policy.ACLs, newRule)
 
-	policyJSON, err := json.Marshal(policy)
-	if err != nil {
-		return fmt.Errorf("marshal policy: %w", err)
-	}
-
-	_, err = am.client.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: string(policyJSON)})
-	return err
+	return am.applyPolicy(ctx, &policy, true)
 }
@@ -0,0 +1,151 @@
+package headscale
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// fakePolicyStore is the policy of a Headscale shared by several ACLManagers.
+type fakePolicyStore struct {
+	v1.HeadscaleServiceClient
+
+	mu        sync.Mutex
+	policy    string
+	updatedAt time.Time
+	sets      int
+}
+
+func (s *fakePolicyStore) GetPolicy(context.Context, *v1.GetPolicyRequest, ...grpc.CallOption) (*v1.GetPolicyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return &v1.GetPolicyResponse{Policy: s.policy, UpdatedAt: timestamppb.New(s.updatedAt)}, nil
+}
+
+func (s *fakePolicyStore) SetPolicy(_ context.Context, req *v1.SetPolicyRequest, _ ...grpc.CallOption) (*v1.SetPolicyResponse, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.policy = req.GetPolicy()
+	s.updatedAt = s.updatedAt.Add(time.Second)
+	s.sets++
+	return &v1.SetPolicyResponse{Policy: s.policy, UpdatedAt: timestamppb.New(s.updatedAt)}, nil
+}
+
+func (s *fakePolicyStore) stored(t *testing.T) *ACLPolicy {
+	t.Helper()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var policy ACLPolicy
+	if err := json.Unmarshal([]byte(s.policy), &policy); err != nil {
+		t.Fatalf("unmarshal stored policy: %v", err)
+	}
+	return &policy
+}
+
+func assertHasWonderNetRules(t *testing.T, policy *ACLPolicy, usernames ...string) {
+	t.Helper()
+	for _, username := range usernames {
+		found := false
+		for _, rule := range policy.ACLs {
+			if len(rule.Sources) == 1 && rule.Sources[0] == username+"@" {
+				found = true
+				break
+			}
+		}
+		if !found {
+			t.Errorf("stored policy has no rule for %s: %+v", username, policy.ACLs)
+		}
+	}
+}
+
+func TestAddWonderNetToPolicy_ConcurrentReplicas(t *testing.T) {
+	ctx := context.Background()
+	store := &fakePolicyStore{}
+	replicaA := NewACLManager(store)
+	replicaB := NewACLManager(store)
+
+	if err := replicaA.AddWonderNetToPolicy(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.AddWonderNetToPolicy(ctx, "user2"); err != nil {
+		t.Fatal(err)
+	}
+	// replicaA last wrote the policy before replicaB added user2, so it must
+	// notice the change instead of writing its stale policy.
+	if err := replicaA.AddWonderNetToPolicy(ctx, "user3"); err != nil {
+		t.Fatal(err)
+	}
+
+	assertHasWonderNetRules(t, store.stored(t), "user1", "user2", "user3")
+}
+
+func TestSetWonderNetPolicy_RetriesOnConflict(t *testing.T) {
+	ctx := context.Background()
+	store := &fakePolicyStore{}
+	replicaA := NewACLManager(store)
+	replicaB := NewACLManager(store)
+
+	if err := replicaA.AddWonderNetToPolicy(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.AddWonderNetToPolicy(ctx, "user2"); err != nil {
+		t.Fatal(err)
+	}
+
+	fragment := &ACLPolicy{ACLs: []ACLRule{{Action: "accept", Sources: []string{"tag:web"}, Destinations: []string{"tag:db:5432"}}}}
+	if err := replicaA.SetWonderNetPolicy(ctx, "net-1", fragment); err != nil {
+		t.Fatal(err)
+	}
+
+	stored := store.stored(t)
+	assertHasWonderNetRules(t, stored, "user1", "user2")
+	if got := len(stored.ACLs); got != 3 {
+		t.Errorf("stored policy has %d rules, want 3: %+v", got, stored.ACLs)
+	}
+
+	// Replacing the fragment after another conflict must not keep the old
+	// fragment's rule as part of the base.
+	if err := replicaB.AddWonderNetToPolicy(ctx, "user5"); err != nil {
+		t.Fatal(err)
+	}
+	fragment = &ACLPolicy{ACLs: []ACLRule{{Action: "accept", Sources: []string{"tag:web"}, Destinations: []string{"tag:db:5433"}}}}
+	if err := replicaA.SetWonderNetPolicy(ctx, "net-1", fragment); err != nil {
+		t.Fatal(err)
+	}
+	stored = store.stored(t)
+	assertHasWonderNetRules(t, stored, "user1", "user2", "user5")
+	if got := len(stored.ACLs); got != 4 {
+		t.Errorf("stored policy has %d rules, want 4: %+v", got, stored.ACLs)
+	}
+}
+
+func TestApplyPolicy_Conflict(t *testing.T) {
+	ctx := context.Background()
+	store := &fakePolicyStore{}
+	replicaA := NewACLManager(store)
+	replicaB := NewACLManager(store)
+
+	if err := replicaA.AddWonderNetToPolicy(ctx, "user1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := replicaB.AddWonderNetToPolicy(ctx, "user2"); err != nil {
+		t.Fatal(err)
+	}
+
+	replicaA.mu.Lock()
+	defer replicaA.mu.Unlock()
+	sets := store.sets
+	if err := replicaA.applyPolicy(ctx, replicaA.base, true); !errors.Is(err, ErrPolicyConflict) {
+		t.Fatalf("applyPolicy() error = %v, want %v", err, ErrPolicyConflict)
+	}
+	if store.sets != sets {
+		t.Error("applyPolicy() wrote the policy despite the conflict")
+	}
+}
@@ -0,0 +1,167 @@
+package headscale
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+const (
+	// wonderNetSelectorAll selects every untagged node of a wonder net.
+	wonderNetSelectorAll = "*"
+
+	// PendingNodeTagName is the wonder net tag carried by nodes that wait
+	// for approval. It is reserved so that owners cannot write rules that
+	// reach pending nodes.
+	PendingNodeTagName = "pending"
+
+	// MaxWonderNetACLRules bounds how many rules one wonder net may add to the
+	// global policy.
+	MaxWonderNetACLRules = 100
+)
+
+var wonderNetTagNamePattern = regexp.MustCompile(`^[a-z0-9][a-z0-9-]{0,31}$`)
+
+// WonderNetTag returns the Headscale tag that a wonder net's "tag:<name>"
+// selector refers to. Tags are namespaced by Headscale user so that wonder
+// nets cannot select each other's nodes.
+func WonderNetTag(headscaleUser, name string) string {
+	return "tag:wn-" + headscaleUser + "-" + name
+}
+
+// ScopeWonderNetRules validates rules written by a wonder net owner and
+// translates them into a policy fragment that can only match the wonder
+// net's own nodes. Selectors are either "*" (all untagged nodes of the wonder
+// net) or "tag:<name>"; destinations add ports as "<selector>:<ports>", where
+// ports is "*" or a comma-separated list of ports and ranges such as
+// "22,8000-8100". The protocol may be left empty or set to "tcp" or "udp".
+// Each referenced tag is owned by the wonder net's Headscale user so its
+// nodes can advertise it.
+func ScopeWonderNetRules(headscaleUser string, rules []ACLRule) (*ACLPolicy, error) {
+	if len(rules) > MaxWonderNetACLRules {
+		return nil, fmt.Errorf("at most %d rules are allowed", MaxWonderNetACLRules)
+	}
+
+	policy := &ACLPolicy{ACLs: make([]ACLRule, 0, len(rules))}
+	owner := []string{headscaleUser + "@"}
+	addTag := func(tag string) {
+		if policy.TagOwners == nil {
+			policy.TagOwners = make(map[string][]string)
+		}
+		policy.TagOwners[tag] = owner
+	}
+
+	for i, rule := range rules {
+		if rule.Action != "accept" {
+			return nil, fmt.Errorf("rule %d: action must be \"accept\"", i)
+		}
+		if len(rule.Sources) == 0 {
+			return nil, fmt.Errorf("rule %d: src is required", i)
+		}
+		if len(rule.Destinations) == 0 {
+			return nil, fmt.Errorf("rule %d: dst is required", i)
+		}
+		if err := ValidateProtocol(rule.Protocol); err != nil {
+			return nil, fmt.Errorf("rule %d: %w", i, err)
+		}
+
+		scoped := ACLRule{
+			Action:       rule.Action,
+			Sources:      make([]string, len(rule.Sources)),
+			Destinations: make([]string, len(rule.Destinations)),
+			Protocol:     rule.Protocol,
+		}
+		for j, src := range rule.Sources {
+			selector, tag, err := ScopeWonderNetSelector(headscaleUser, src)
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: src %q: %w", i, src, err)
+			}
+			if tag {
+				addTag(selector)
+			}
+			scoped.Sources[j] = selector
+		}
+		for j, dst := range rule.Destinations {
+			idx := strings.LastIndex(dst, ":")
+			if idx <= 0 {
+				return nil, fmt.Errorf("rule %d: dst %q: must be <selector>:<ports>", i, dst)
+			}
+			selector, tag, err := ScopeWonderNetSelector(headscaleUser, dst[:idx])
+			if err != nil {
+				return nil, fmt.Errorf("rule %d: dst %q: %w", i, dst, err)
+			}
+			if err := validatePorts(dst[idx+1:]); err != nil {
+				return nil, fmt.Errorf("rule %d: dst %q: %w", i, dst, err)
+			}
+			if tag {
+				addTag(selector)
+			}
+			scoped.Destinations[j] = selector + ":" + dst[idx+1:]
+		}
+		policy.ACLs = append(policy.ACLs, scoped)
+	}
+
+	return policy, nil
+}
+
+// ScopeWonderNetSelector translates a wonder net selector ("*" or
+// "tag:<name>") into a Headscale one and reports whether it is a tag.
+func ScopeWonderNetSelector(headscaleUser, selector string) (string, bool, error) {
+	if selector == wonderNetSelectorAll {
+		return headscaleUser + "@", false, nil
+	}
+	name, ok := strings.CutPrefix(selector, "tag:")
+	if !ok {
+		return "", false, fmt.Errorf("selector must be %q or tag:<name>", wonderNetSelectorAll)
+	}
+	if !wonderNetTagNamePattern.MatchString(name) {
+		return "", false, fmt.Errorf("tag name must be 1-32 lowercase letters, digits, or hyphens")
+	}
+	if name == PendingNodeTagName {
+		return "", false, fmt.Errorf("tag:%s is reserved for nodes waiting for approval", PendingNodeTagName)
+	}
+	return WonderNetTag(headscaleUser, name), true, nil
+}
+
+// ValidateProtocol checks a rule protocol. Empty means any protocol.
+func ValidateProtocol(protocol string) error {
+	switch protocol {
+	case "", "tcp", "udp":
+		return nil
+	default:
+		return fmt.Errorf("protocol must be \"tcp\" or \"udp\"")
+	}
+}
+
+func validatePorts(ports string) error {
+	if ports == "*" {
+		return nil
+	}
+	for _, item := range strings.Split(ports, ",") {
+		low, high, isRange := strings.Cut(item, "-")
+		first, err := parsePort(low)
+		if err != nil {
+			return err
+		}
+		if !isRange {
+			continue
+		}
+		last, err := parsePort(high)
+		if err != nil {
+			return err
+		}
+		if first > last {
+			return fmt.Errorf("invalid port range %q", item)
+		}
+	}
+	return nil
+}
+
+func parsePort(s string) (int, error) {
+	port, err := strconv.Atoi(s)
+	if err != nil || port < 1 || port > 65535 {
+		return 0, fmt.Errorf("invalid port %q", s)
+	}
+	return port, nil
+}
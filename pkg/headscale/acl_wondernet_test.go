@@ -0,0 +1,147 @@
+package headscale
+
+import (
+	"slices"
+	"testing"
+)
+
+func TestScopeWonderNetRules(t *testing.T) {
+	policy, err := ScopeWonderNetRules("u1", []ACLRule{
+		{Action: "accept", Sources: []string{"tag:web"}, Destinations: []string{"tag:db:5432", "*:22,8000-8100"}},
+		{Action: "accept", Sources: []string{"*"}, Destinations: []string{"tag:web:*"}},
+	})
+	if err != nil {
+		t.Fatalf("ScopeWonderNetRules() error = %v", err)
+	}
+
+	if len(policy.ACLs) != 2 {
+		t.Fatalf("expected 2 rules, got %d", len(policy.ACLs))
+	}
+	assertRule(t, policy.ACLs[0], "accept", []string{"tag:wn-u1-web"}, []string{"tag:wn-u1-db:5432", "u1@:22,8000-8100"})
+	assertRule(t, policy.ACLs[1], "accept", []string{"u1@"}, []string{"tag:wn-u1-web:*"})
+
+	for _, tag := range []string{"tag:wn-u1-web", "tag:wn-u1-db"} {
+		if owners := policy.TagOwners[tag]; !slices.Equal(owners, []string{"u1@"}) {
+			t.Errorf("TagOwners[%q] = %v, want [u1@]", tag, owners)
+		}
+	}
+}
+
+func TestScopeWonderNetRules_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule ACLRule
+	}{
+		{
+			name: "deny action",
+			rule: ACLRule{Action: "deny", Sources: []string{"*"}, Destinations: []string{"*:*"}},
+		},
+		{
+			name: "other user source",
+			rule: ACLRule{Action: "accept", Sources: []string{"u2@"}, Destinations: []string{"*:*"}},
+		},
+		{
+			name: "wildcard destination",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"*"}},
+		},
+		{
+			name: "autogroup destination",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"autogroup:member:*"}},
+		},
+		{
+			name: "uppercase tag",
+			rule: ACLRule{Action: "accept", Sources: []string{"tag:Web"}, Destinations: []string{"*:*"}},
+		},
+		{
+			name: "reserved pending tag",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"tag:pending:*"}},
+		},
+		{
+			name: "port out of range",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"*:70000"}},
+		},
+		{
+			name: "reversed port range",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"*:90-80"}},
+		},
+		{
+			name: "unknown protocol",
+			rule: ACLRule{Action: "accept", Sources: []string{"*"}, Destinations: []string{"*:53"}, Protocol: "sctp"},
+		},
+		{
+			name: "missing sources",
+			rule: ACLRule{Action: "accept", Destinations: []string{"*:*"}},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ScopeWonderNetRules("u1", []ACLRule{tt.rule}); err == nil {
+				t.Errorf("ScopeWonderNetRules() error = nil, want error")
+			}
+		})
+	}
+}
+
+func TestMergeWonderNetPolicies(t *testing.T) {
+	base := GenerateTaggedHubSpokePolicy([]string{"admin"})
+	fragments := map[string]*ACLPolicy{
+		"u2": {
+			ACLs:      []ACLRule{{Action: "accept", Sources: []string{"tag:wn-u2-a"}, Destinations: []string{"u2@:22"}}},
+			TagOwners: map[string][]string{"tag:wn-u2-a": {"u2@"}},
+		},
+		"u1": {
+			ACLs: []ACLRule{{Action: "accept", Sources: []string{"u1@"}, Destinations: []string{"u1@:80"}}},
+		},
+	}
+
+	merged := MergeWonderNetPolicies(base, fragments)
+
+	if len(merged.ACLs) != len(base.ACLs)+2 {
+		t.Fatalf("expected %d rules, got %d", len(base.ACLs)+2, len(merged.ACLs))
+	}
+	assertRule(t, merged.ACLs[len(base.ACLs)], "accept", []string{"u1@"}, []string{"u1@:80"})
+	assertRule(t, merged.ACLs[len(base.ACLs)+1], "accept", []string{"tag:wn-u2-a"}, []string{"u2@:22"})
+
+	if _, ok := merged.TagOwners[PrivilegedTag]; !ok {
+		t.Errorf("merged policy lost %s owners", PrivilegedTag)
+	}
+	if _, ok := merged.TagOwners["tag:wn-u2-a"]; !ok {
+		t.Errorf("merged policy missing tag:wn-u2-a owners")
+	}
+	if _, ok := base.TagOwners["tag:wn-u2-a"]; ok {
+		t.Errorf("MergeWonderNetPolicies modified base")
+	}
+}
+
+func TestMergeWonderNetPolicies_AcceptedByHeadscale(t *testing.T) {
+	fragment, err := ScopeWonderNetRules("normuser", []ACLRule{
+		{Action: "accept", Sources: []string{"tag:web"}, Destinations: []string{"tag:db:5432"}},
+		{Action: "accept", Sources: []string{"*"}, Destinations: []string{"tag:web:80,443"}},
+	})
+	if err != nil {
+		t.Fatalf("ScopeWonderNetRules() error = %v", err)
+	}
+
+	merged := MergeWonderNetPolicies(
+		GenerateTaggedHubSpokePolicy([]string{privilegedUserName}),
+		map[string]*ACLPolicy{"normuser": fragment},
+	)
+	newTaggedTwoNodePolicy(t, merged)
+}
+
+func TestMergeWonderNetPolicies_ServiceRulesAcceptedByHeadscale(t *testing.T) {
+	fragment := &ACLPolicy{
+		ACLs: []ACLRule{
+			{Action: "accept", Sources: []string{"tag:wn-normuser-web"}, Destinations: []string{"100.64.0.2:5432", "fd7a:115c:a1e0::2:5432"}, Protocol: "tcp"},
+			{Action: "accept", Sources: []string{"100.64.0.3"}, Destinations: []string{"100.64.0.2:53"}, Protocol: "udp"},
+		},
+		TagOwners: map[string][]string{"tag:wn-normuser-web": {"normuser@"}},
+	}
+
+	merged := MergeWonderNetPolicies(
+		GenerateTaggedHubSpokePolicy([]string{privilegedUserName}),
+		map[string]*ACLPolicy{"normuser/services": fragment},
+	)
+	newTaggedTwoNodePolicy(t, merged)
+}
@@ -0,0 +1,694 @@
+// Package fake provides an in-memory Headscale for tests. Headscale
+// implements v1.HeadscaleServiceClient, so it can stand in for the gRPC
+// client anywhere the coordinator takes one, without a Headscale server.
+//
+// It models users, pre-auth keys, nodes, API keys, and the policy closely
+// enough for the coordinator's use of them; it does not evaluate policies or
+// talk to Tailscale clients. Nodes join with Join, which spends a pre-auth
+// key as tailscale up --authkey would.
+package fake
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"net/netip"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Mesh address ranges nodes are numbered from, as Headscale's defaults.
+var (
+	prefixV4 = netip.MustParsePrefix("100.64.0.0/10")
+	prefixV6 = netip.MustParsePrefix("fd7a:115c:a1e0::/48")
+)
+
+// Headscale is an in-memory Headscale. The zero value is not usable; create
+// one with New. It is safe for concurrent use.
+type Headscale struct {
+	mu          sync.Mutex
+	now         func() time.Time
+	nextID      uint64
+	users       []*v1.User
+	preAuthKeys []*v1.PreAuthKey
+	nodes       []*v1.Node
+	apiKeys     []*v1.ApiKey
+	policy      string
+	policyAt    time.Time
+	err         error
+}
+
+var _ v1.HeadscaleServiceClient = (*Headscale)(nil)
+
+// New returns an empty Headscale.
+func New() *Headscale {
+	return &Headscale{now: time.Now, nextID: 1}
+}
+
+// SetClock makes the Headscale read the time from now, for tests of expiry.
+func (h *Headscale) SetClock(now func() time.Time) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.now = now
+}
+
+// FailWith makes every call return err until it is called again with nil,
+// for tests of Headscale outages. Use a gRPC status error, such as
+// status.Error(codes.Unavailable, "..."), to look like a real failure.
+func (h *Headscale) FailWith(err error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.err = err
+}
+
+// Join registers a node of the pre-auth key's user, as a Tailscale client
+// running tailscale up --authkey would. The node is online and advertises
+// routes. It fails if the key is unknown, expired, or already used.
+func (h *Headscale) Join(authKey, hostname string, routes ...string) (*v1.Node, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	i := slices.IndexFunc(h.preAuthKeys, func(k *v1.PreAuthKey) bool { return k.GetKey() == authKey })
+	if i < 0 {
+		return nil, status.Error(codes.NotFound, "pre-auth key not found")
+	}
+	key := h.preAuthKeys[i]
+	if key.GetExpiration() != nil && !h.now().Before(key.GetExpiration().AsTime()) {
+		return nil, status.Error(codes.PermissionDenied, "pre-auth key expired")
+	}
+	if key.GetUsed() && !key.GetReusable() {
+		return nil, status.Error(codes.PermissionDenied, "pre-auth key already used")
+	}
+	key.Used = true
+
+	node := h.addNode(key.GetUser(), hostname, v1.RegisterMethod_REGISTER_METHOD_AUTH_KEY)
+	node.PreAuthKey = proto.CloneOf(key)
+	node.ForcedTags = slices.Clone(key.GetAclTags())
+	node.AvailableRoutes = slices.Clone(routes)
+	return proto.CloneOf(node), nil
+}
+
+// SetOnline marks a node online or offline. Going offline sets its last
+// seen time.
+func (h *Headscale) SetOnline(nodeID uint64, online bool) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	node, err := h.node(nodeID)
+	if err != nil {
+		return err
+	}
+	node.Online = online
+	node.LastSeen = timestamppb.New(h.now())
+	return nil
+}
+
+// Policy returns the policy last set, or an empty string.
+func (h *Headscale) Policy() string {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.policy
+}
+
+// --- Users ---
+
+func (h *Headscale) CreateUser(_ context.Context, in *v1.CreateUserRequest, _ ...grpc.CallOption) (*v1.CreateUserResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if in.GetName() == "" {
+		return nil, status.Error(codes.InvalidArgument, "user name is required")
+	}
+	if h.userByName(in.GetName()) != nil {
+		return nil, status.Errorf(codes.AlreadyExists, "user %q already exists", in.GetName())
+	}
+	user := &v1.User{
+		Id:            h.id(),
+		Name:          in.GetName(),
+		DisplayName:   in.GetDisplayName(),
+		Email:         in.GetEmail(),
+		ProfilePicUrl: in.GetPictureUrl(),
+		CreatedAt:     timestamppb.New(h.now()),
+	}
+	h.users = append(h.users, user)
+	return &v1.CreateUserResponse{User: proto.CloneOf(user)}, nil
+}
+
+func (h *Headscale) RenameUser(_ context.Context, in *v1.RenameUserRequest, _ ...grpc.CallOption) (*v1.RenameUserResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	user, err := h.user(in.GetOldId())
+	if err != nil {
+		return nil, err
+	}
+	if other := h.userByName(in.GetNewName()); other != nil && other != user {
+		return nil, status.Errorf(codes.AlreadyExists, "user %q already exists", in.GetNewName())
+	}
+	user.Name = in.GetNewName()
+	h.refreshOwners(user)
+	return &v1.RenameUserResponse{User: proto.CloneOf(user)}, nil
+}
+
+func (h *Headscale) DeleteUser(_ context.Context, in *v1.DeleteUserRequest, _ ...grpc.CallOption) (*v1.DeleteUserResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if _, err := h.user(in.GetId()); err != nil {
+		return nil, err
+	}
+	if slices.ContainsFunc(h.nodes, func(n *v1.Node) bool { return n.GetUser().GetId() == in.GetId() }) {
+		return nil, status.Error(codes.FailedPrecondition, "user has nodes")
+	}
+	h.users = slices.DeleteFunc(h.users, func(u *v1.User) bool { return u.GetId() == in.GetId() })
+	h.preAuthKeys = slices.DeleteFunc(h.preAuthKeys, func(k *v1.PreAuthKey) bool { return k.GetUser().GetId() == in.GetId() })
+	return &v1.DeleteUserResponse{}, nil
+}
+
+func (h *Headscale) ListUsers(_ context.Context, in *v1.ListUsersRequest, _ ...grpc.CallOption) (*v1.ListUsersResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	resp := &v1.ListUsersResponse{}
+	for _, u := range h.users {
+		if (in.GetId() != 0 && u.GetId() != in.GetId()) ||
+			(in.GetName() != "" && u.GetName() != in.GetName()) ||
+			(in.GetEmail() != "" && u.GetEmail() != in.GetEmail()) {
+			continue
+		}
+		resp.Users = append(resp.Users, proto.CloneOf(u))
+	}
+	return resp, nil
+}
+
+// --- Pre-auth keys ---
+
+func (h *Headscale) CreatePreAuthKey(_ context.Context, in *v1.CreatePreAuthKeyRequest, _ ...grpc.CallOption) (*v1.CreatePreAuthKeyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	user, err := h.user(in.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range in.GetAclTags() {
+		if !strings.HasPrefix(tag, "tag:") {
+			return nil, status.Errorf(codes.InvalidArgument, "tag %q must start with tag:", tag)
+		}
+	}
+	key := &v1.PreAuthKey{
+		User:       proto.CloneOf(user),
+		Id:         h.id(),
+		Key:        randomHex(24),
+		Reusable:   in.GetReusable(),
+		Ephemeral:  in.GetEphemeral(),
+		Expiration: in.GetExpiration(),
+		CreatedAt:  timestamppb.New(h.now()),
+		AclTags:    slices.Clone(in.GetAclTags()),
+	}
+	h.preAuthKeys = append(h.preAuthKeys, key)
+	return &v1.CreatePreAuthKeyResponse{PreAuthKey: proto.CloneOf(key)}, nil
+}
+
+func (h *Headscale) ExpirePreAuthKey(_ context.Context, in *v1.ExpirePreAuthKeyRequest, _ ...grpc.CallOption) (*v1.ExpirePreAuthKeyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	i := slices.IndexFunc(h.preAuthKeys, func(k *v1.PreAuthKey) bool {
+		return k.GetKey() == in.GetKey() && k.GetUser().GetId() == in.GetUser()
+	})
+	if i < 0 {
+		return nil, status.Error(codes.NotFound, "pre-auth key not found")
+	}
+	h.preAuthKeys[i].Expiration = timestamppb.New(h.now())
+	return &v1.ExpirePreAuthKeyResponse{}, nil
+}
+
+func (h *Headscale) ListPreAuthKeys(_ context.Context, in *v1.ListPreAuthKeysRequest, _ ...grpc.CallOption) (*v1.ListPreAuthKeysResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if _, err := h.user(in.GetUser()); err != nil {
+		return nil, err
+	}
+	resp := &v1.ListPreAuthKeysResponse{}
+	for _, k := range h.preAuthKeys {
+		if k.GetUser().GetId() == in.GetUser() {
+			resp.PreAuthKeys = append(resp.PreAuthKeys, proto.CloneOf(k))
+		}
+	}
+	return resp, nil
+}
+
+// --- Nodes ---
+
+func (h *Headscale) DebugCreateNode(_ context.Context, in *v1.DebugCreateNodeRequest, _ ...grpc.CallOption) (*v1.DebugCreateNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	user := h.userByName(in.GetUser())
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %q not found", in.GetUser())
+	}
+	node := h.addNode(user, in.GetName(), v1.RegisterMethod_REGISTER_METHOD_CLI)
+	node.AvailableRoutes = slices.Clone(in.GetRoutes())
+	return &v1.DebugCreateNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) GetNode(_ context.Context, in *v1.GetNodeRequest, _ ...grpc.CallOption) (*v1.GetNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	return &v1.GetNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) SetTags(_ context.Context, in *v1.SetTagsRequest, _ ...grpc.CallOption) (*v1.SetTagsResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	for _, tag := range in.GetTags() {
+		if !strings.HasPrefix(tag, "tag:") {
+			return nil, status.Errorf(codes.InvalidArgument, "tag %q must start with tag:", tag)
+		}
+	}
+	node.ForcedTags = slices.Clone(in.GetTags())
+	return &v1.SetTagsResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) SetApprovedRoutes(_ context.Context, in *v1.SetApprovedRoutesRequest, _ ...grpc.CallOption) (*v1.SetApprovedRoutesResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	for _, route := range in.GetRoutes() {
+		if _, err := netip.ParsePrefix(route); err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid route %q", route)
+		}
+	}
+	node.ApprovedRoutes = slices.Clone(in.GetRoutes())
+	// A route is served once it is both advertised and approved.
+	node.SubnetRoutes = nil
+	for _, route := range node.GetAvailableRoutes() {
+		if slices.Contains(node.ApprovedRoutes, route) {
+			node.SubnetRoutes = append(node.SubnetRoutes, route)
+		}
+	}
+	return &v1.SetApprovedRoutesResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) RegisterNode(_ context.Context, in *v1.RegisterNodeRequest, _ ...grpc.CallOption) (*v1.RegisterNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	user := h.userByName(in.GetUser())
+	if user == nil {
+		return nil, status.Errorf(codes.NotFound, "user %q not found", in.GetUser())
+	}
+	if in.GetKey() == "" {
+		return nil, status.Error(codes.InvalidArgument, "registration key is required")
+	}
+	node := h.addNode(user, "node-"+in.GetKey(), v1.RegisterMethod_REGISTER_METHOD_CLI)
+	return &v1.RegisterNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) DeleteNode(_ context.Context, in *v1.DeleteNodeRequest, _ ...grpc.CallOption) (*v1.DeleteNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if _, err := h.node(in.GetNodeId()); err != nil {
+		return nil, err
+	}
+	h.nodes = slices.DeleteFunc(h.nodes, func(n *v1.Node) bool { return n.GetId() == in.GetNodeId() })
+	return &v1.DeleteNodeResponse{}, nil
+}
+
+func (h *Headscale) ExpireNode(_ context.Context, in *v1.ExpireNodeRequest, _ ...grpc.CallOption) (*v1.ExpireNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	expiry := in.GetExpiry()
+	if expiry == nil {
+		expiry = timestamppb.New(h.now())
+	}
+	node.Expiry = expiry
+	if !h.now().Before(expiry.AsTime()) {
+		node.Online = false
+	}
+	return &v1.ExpireNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) RenameNode(_ context.Context, in *v1.RenameNodeRequest, _ ...grpc.CallOption) (*v1.RenameNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	name := in.GetNewName()
+	if name == "" {
+		name = h.givenName(node.GetName(), node.GetId())
+	} else if h.givenNameTaken(name, node.GetId()) {
+		return nil, status.Errorf(codes.AlreadyExists, "name %q is in use", name)
+	}
+	node.GivenName = name
+	return &v1.RenameNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) ListNodes(_ context.Context, in *v1.ListNodesRequest, _ ...grpc.CallOption) (*v1.ListNodesResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	resp := &v1.ListNodesResponse{}
+	for _, n := range h.nodes {
+		if in.GetUser() != "" && n.GetUser().GetName() != in.GetUser() {
+			continue
+		}
+		resp.Nodes = append(resp.Nodes, proto.CloneOf(n))
+	}
+	return resp, nil
+}
+
+func (h *Headscale) MoveNode(_ context.Context, in *v1.MoveNodeRequest, _ ...grpc.CallOption) (*v1.MoveNodeResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	node, err := h.node(in.GetNodeId())
+	if err != nil {
+		return nil, err
+	}
+	user, err := h.user(in.GetUser())
+	if err != nil {
+		return nil, err
+	}
+	node.User = proto.CloneOf(user)
+	return &v1.MoveNodeResponse{Node: proto.CloneOf(node)}, nil
+}
+
+func (h *Headscale) BackfillNodeIPs(_ context.Context, _ *v1.BackfillNodeIPsRequest, _ ...grpc.CallOption) (*v1.BackfillNodeIPsResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+	// Every node is numbered when it is added, so there is nothing to fill.
+	return &v1.BackfillNodeIPsResponse{}, nil
+}
+
+// --- API keys ---
+
+func (h *Headscale) CreateApiKey(_ context.Context, in *v1.CreateApiKeyRequest, _ ...grpc.CallOption) (*v1.CreateApiKeyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	prefix := randomHex(5)
+	h.apiKeys = append(h.apiKeys, &v1.ApiKey{
+		Id:         h.id(),
+		Prefix:     prefix,
+		Expiration: in.GetExpiration(),
+		CreatedAt:  timestamppb.New(h.now()),
+	})
+	return &v1.CreateApiKeyResponse{ApiKey: prefix + "." + randomHex(32)}, nil
+}
+
+func (h *Headscale) ExpireApiKey(_ context.Context, in *v1.ExpireApiKeyRequest, _ ...grpc.CallOption) (*v1.ExpireApiKeyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	key, err := h.apiKey(in.GetPrefix())
+	if err != nil {
+		return nil, err
+	}
+	key.Expiration = timestamppb.New(h.now())
+	return &v1.ExpireApiKeyResponse{}, nil
+}
+
+func (h *Headscale) ListApiKeys(_ context.Context, _ *v1.ListApiKeysRequest, _ ...grpc.CallOption) (*v1.ListApiKeysResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	resp := &v1.ListApiKeysResponse{}
+	for _, k := range h.apiKeys {
+		resp.ApiKeys = append(resp.ApiKeys, proto.CloneOf(k))
+	}
+	return resp, nil
+}
+
+func (h *Headscale) DeleteApiKey(_ context.Context, in *v1.DeleteApiKeyRequest, _ ...grpc.CallOption) (*v1.DeleteApiKeyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	if _, err := h.apiKey(in.GetPrefix()); err != nil {
+		return nil, err
+	}
+	h.apiKeys = slices.DeleteFunc(h.apiKeys, func(k *v1.ApiKey) bool { return k.GetPrefix() == in.GetPrefix() })
+	return &v1.DeleteApiKeyResponse{}, nil
+}
+
+// --- Policy ---
+
+func (h *Headscale) GetPolicy(_ context.Context, _ *v1.GetPolicyRequest, _ ...grpc.CallOption) (*v1.GetPolicyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	resp := &v1.GetPolicyResponse{Policy: h.policy}
+	if !h.policyAt.IsZero() {
+		resp.UpdatedAt = timestamppb.New(h.policyAt)
+	}
+	return resp, nil
+}
+
+// SetPolicy stores the policy without checking it, unlike Headscale.
+func (h *Headscale) SetPolicy(_ context.Context, in *v1.SetPolicyRequest, _ ...grpc.CallOption) (*v1.SetPolicyResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	h.policy = in.GetPolicy()
+	h.policyAt = h.now()
+	return &v1.SetPolicyResponse{Policy: h.policy, UpdatedAt: timestamppb.New(h.policyAt)}, nil
+}
+
+// --- Health ---
+
+func (h *Headscale) Health(_ context.Context, _ *v1.HealthRequest, _ ...grpc.CallOption) (*v1.HealthResponse, error) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.err != nil {
+		return nil, h.err
+	}
+	return &v1.HealthResponse{DatabaseConnectivity: true}, nil
+}
+
+// id returns the next ID. Users, keys, and nodes share one sequence, so
+// an ID of the wrong kind is never found by accident.
+func (h *Headscale) id() uint64 {
+	id := h.nextID
+	h.nextID++
+	return id
+}
+
+func (h *Headscale) user(id uint64) (*v1.User, error) {
+	i := slices.IndexFunc(h.users, func(u *v1.User) bool { return u.GetId() == id })
+	if i < 0 {
+		return nil, status.Errorf(codes.NotFound, "user %d not found", id)
+	}
+	return h.users[i], nil
+}
+
+func (h *Headscale) userByName(name string) *v1.User {
+	i := slices.IndexFunc(h.users, func(u *v1.User) bool { return u.GetName() == name })
+	if i < 0 {
+		return nil
+	}
+	return h.users[i]
+}
+
+func (h *Headscale) node(id uint64) (*v1.Node, error) {
+	i := slices.IndexFunc(h.nodes, func(n *v1.Node) bool { return n.GetId() == id })
+	if i < 0 {
+		return nil, status.Errorf(codes.NotFound, "node %d not found", id)
+	}
+	return h.nodes[i], nil
+}
+
+func (h *Headscale) apiKey(prefix string) (*v1.ApiKey, error) {
+	i := slices.IndexFunc(h.apiKeys, func(k *v1.ApiKey) bool { return k.GetPrefix() == prefix })
+	if i < 0 {
+		return nil, status.Errorf(codes.NotFound, "api key %q not found", prefix)
+	}
+	return h.apiKeys[i], nil
+}
+
+// refreshOwners updates the copies of a renamed user held by its nodes
+// and pre-auth keys.
+func (h *Headscale) refreshOwners(user *v1.User) {
+	for _, n := range h.nodes {
+		if n.GetUser().GetId() == user.GetId() {
+			n.User = proto.CloneOf(user)
+		}
+	}
+	for _, k := range h.preAuthKeys {
+		if k.GetUser().GetId() == user.GetId() {
+			k.User = proto.CloneOf(user)
+		}
+	}
+}
+
+// addNode adds an online node of user with the next mesh addresses.
+func (h *Headscale) addNode(user *v1.User, hostname string, method v1.RegisterMethod) *v1.Node {
+	id := h.id()
+	now := timestamppb.New(h.now())
+	node := &v1.Node{
+		Id:             id,
+		MachineKey:     "mkey:" + randomHex(32),
+		NodeKey:        "nodekey:" + randomHex(32),
+		DiscoKey:       "discokey:" + randomHex(32),
+		IpAddresses:    nodeAddresses(id),
+		Name:           hostname,
+		GivenName:      h.givenName(hostname, id),
+		User:           proto.CloneOf(user),
+		LastSeen:       now,
+		CreatedAt:      now,
+		RegisterMethod: method,
+		Online:         true,
+	}
+	h.nodes = append(h.nodes, node)
+	return node
+}
+
+// givenName returns hostname as a DNS label, with a suffix if another node
+// has it, as Headscale names nodes.
+func (h *Headscale) givenName(hostname string, id uint64) string {
+	name := strings.Trim(strings.Map(func(r rune) rune {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9', r == '-':
+			return r
+		case r >= 'A' && r <= 'Z':
+			return r + 'a' - 'A'
+		default:
+			return '-'
+		}
+	}, hostname), "-")
+	if name == "" {
+		name = "node"
+	}
+	if h.givenNameTaken(name, id) {
+		name = fmt.Sprintf("%s-%s", name, randomHex(4))
+	}
+	return name
+}
+
+func (h *Headscale) givenNameTaken(name string, id uint64) bool {
+	return slices.ContainsFunc(h.nodes, func(n *v1.Node) bool {
+		return n.GetId() != id && n.GetGivenName() == name
+	})
+}
+
+// nodeAddresses returns the IPv4 and IPv6 mesh address numbered n.
+func nodeAddresses(n uint64) []string {
+	v4 := prefixV4.Addr().As4()
+	v4[2], v4[3] = byte(n>>8), byte(n)
+	v6 := prefixV6.Addr().As16()
+	v6[14], v6[15] = byte(n>>8), byte(n)
+	return []string{netip.AddrFrom4(v4).String(), netip.AddrFrom16(v6).String()}
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
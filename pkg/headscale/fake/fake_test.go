@@ -0,0 +1,126 @@
+package fake
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"testing"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend/tailscale"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestTailscaleMeshLifecycle(t *testing.T) {
+	ctx := context.Background()
+	hs := New()
+	mesh := tailscale.NewTailscaleMesh(hs, "https://hs.example.com")
+
+	creds, err := mesh.CreateJoinCredentials(ctx, "net-a", meshbackend.JoinOptions{TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("CreateJoinCredentials() error = %v", err)
+	}
+	authKey := creds["authkey"].(string)
+	joined, err := hs.Join(authKey, "Worker 1", "10.0.0.0/24")
+	if err != nil {
+		t.Fatalf("Join() error = %v", err)
+	}
+	if _, err := hs.Join(authKey, "worker-2"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Join() with a used single-use key error = %v, want PermissionDenied", err)
+	}
+
+	other, err := mesh.CreateJoinCredentials(ctx, "net-b", meshbackend.JoinOptions{TTL: time.Hour, Reusable: true})
+	if err != nil {
+		t.Fatalf("CreateJoinCredentials() error = %v", err)
+	}
+	for i := range 2 {
+		if _, err := hs.Join(other["authkey"].(string), fmt.Sprintf("other-%d", i)); err != nil {
+			t.Fatalf("Join() with a reusable key error = %v", err)
+		}
+	}
+
+	nodes, err := mesh.ListNodes(ctx, "net-a")
+	if err != nil {
+		t.Fatalf("ListNodes() error = %v", err)
+	}
+	if len(nodes) != 1 {
+		t.Fatalf("ListNodes() = %d nodes, want 1", len(nodes))
+	}
+	node := nodes[0]
+	if node.Name != "worker-1" || node.Hostname != "Worker 1" || !node.Online || len(node.Addresses) != 2 {
+		t.Errorf("node = %+v, want online worker-1 with two addresses", node)
+	}
+
+	id := fmt.Sprint(joined.GetId())
+	if err := mesh.SetApprovedRoutes(ctx, id, []string{"10.0.0.0/24"}); err != nil {
+		t.Fatalf("SetApprovedRoutes() error = %v", err)
+	}
+	if err := mesh.RenameNode(ctx, id, "db"); err != nil {
+		t.Fatalf("RenameNode() error = %v", err)
+	}
+	got, err := mesh.GetNode(ctx, id)
+	if err != nil {
+		t.Fatalf("GetNode() error = %v", err)
+	}
+	if got.Name != "db" || got.Realm != "net-a" || !slices.Equal(got.ServingRoutes, []string{"10.0.0.0/24"}) {
+		t.Errorf("GetNode() = %+v, want db in net-a serving 10.0.0.0/24", got)
+	}
+
+	if err := mesh.DeleteNode(ctx, id); err != nil {
+		t.Fatalf("DeleteNode() error = %v", err)
+	}
+	if _, err := mesh.GetNode(ctx, id); status.Code(errors.Unwrap(err)) != codes.NotFound {
+		t.Errorf("GetNode() after delete error = %v, want NotFound", err)
+	}
+}
+
+func TestJoinExpiredKey(t *testing.T) {
+	ctx := context.Background()
+	hs := New()
+	now := time.Now()
+	hs.SetClock(func() time.Time { return now })
+	mesh := tailscale.NewTailscaleMesh(hs, "https://hs.example.com")
+
+	creds, err := mesh.CreateJoinCredentials(ctx, "net-a", meshbackend.JoinOptions{TTL: time.Minute})
+	if err != nil {
+		t.Fatalf("CreateJoinCredentials() error = %v", err)
+	}
+	now = now.Add(2 * time.Minute)
+	if _, err := hs.Join(creds["authkey"].(string), "late"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("Join() with an expired key error = %v, want PermissionDenied", err)
+	}
+}
+
+func TestFailWith(t *testing.T) {
+	hs := New()
+	mesh := tailscale.NewTailscaleMesh(hs, "https://hs.example.com")
+
+	hs.FailWith(status.Error(codes.Unavailable, "connection refused"))
+	if err := mesh.Healthy(context.Background()); err == nil {
+		t.Error("Healthy() error = nil during an outage")
+	}
+	hs.FailWith(nil)
+	if err := mesh.Healthy(context.Background()); err != nil {
+		t.Errorf("Healthy() error = %v after the outage", err)
+	}
+}
+
+func TestPolicy(t *testing.T) {
+	ctx := context.Background()
+	hs := New()
+
+	if _, err := hs.SetPolicy(ctx, &v1.SetPolicyRequest{Policy: `{"acls":[]}`}); err != nil {
+		t.Fatalf("SetPolicy() error = %v", err)
+	}
+	resp, err := hs.GetPolicy(ctx, &v1.GetPolicyRequest{})
+	if err != nil {
+		t.Fatalf("GetPolicy() error = %v", err)
+	}
+	if resp.GetPolicy() != `{"acls":[]}` || resp.GetUpdatedAt() == nil || hs.Policy() != resp.GetPolicy() {
+		t.Errorf("GetPolicy() = %v, want the policy set", resp)
+	}
+}
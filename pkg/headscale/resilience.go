@@ -0,0 +1,224 @@
+package headscale
+
+import (
+	"context"
+	"log/slog"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// DefaultCallTimeout bounds a Headscale call, including its retries, when
+// the caller's context has no deadline of its own.
+const DefaultCallTimeout = 15 * time.Second
+
+// Circuit breaker states, as reported by CircuitBreaker.State.
+const (
+	CircuitClosed   = "closed"
+	CircuitOpen     = "open"
+	CircuitHalfOpen = "half_open"
+)
+
+// RetryPolicy controls how idempotent Headscale calls are retried while
+// Headscale is unavailable, e.g. during a restart.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times a call is sent, including the
+	// first. Values below 2 disable retries.
+	MaxAttempts int
+	// InitialBackoff is the delay before the first retry. It doubles with
+	// every further retry, up to MaxBackoff, and is randomized by up to half.
+	InitialBackoff time.Duration
+	MaxBackoff     time.Duration
+}
+
+// DefaultRetryPolicy retries for about two seconds, long enough to ride out
+// a Headscale restart.
+var DefaultRetryPolicy = RetryPolicy{
+	MaxAttempts:    4,
+	InitialBackoff: 200 * time.Millisecond,
+	MaxBackoff:     2 * time.Second,
+}
+
+// idempotentMethods are the Headscale calls that are safe to send again.
+// Creating users, keys, and nodes is not retried, as a call that timed out
+// may still have taken effect.
+var idempotentMethods = map[string]bool{
+	v1.HeadscaleService_ListUsers_FullMethodName:         true,
+	v1.HeadscaleService_ListNodes_FullMethodName:         true,
+	v1.HeadscaleService_ListPreAuthKeys_FullMethodName:   true,
+	v1.HeadscaleService_ListApiKeys_FullMethodName:       true,
+	v1.HeadscaleService_GetNode_FullMethodName:           true,
+	v1.HeadscaleService_GetPolicy_FullMethodName:         true,
+	v1.HeadscaleService_SetPolicy_FullMethodName:         true,
+	v1.HeadscaleService_SetTags_FullMethodName:           true,
+	v1.HeadscaleService_SetApprovedRoutes_FullMethodName: true,
+	v1.HeadscaleService_Health_FullMethodName:            true,
+}
+
+// errCircuitOpen is returned without calling Headscale while the circuit
+// breaker is open.
+var errCircuitOpen = status.Error(codes.Unavailable, "headscale unavailable: circuit breaker open")
+
+// CircuitBreaker stops calls to Headscale after repeated connection
+// failures, so that requests fail fast instead of each waiting for a
+// timeout. After the cooldown one probe call is let through; its success
+// closes the circuit again.
+type CircuitBreaker struct {
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+
+	mu       sync.Mutex
+	state    string
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+// NewCircuitBreaker creates a CircuitBreaker that opens after threshold
+// consecutive failures and stays open for cooldown.
+func NewCircuitBreaker(threshold int, cooldown time.Duration) *CircuitBreaker {
+	return &CircuitBreaker{
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+		state:     CircuitClosed,
+	}
+}
+
+// State returns CircuitClosed, CircuitOpen, or CircuitHalfOpen.
+func (b *CircuitBreaker) State() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.state
+}
+
+// allow reports whether a call may be sent.
+func (b *CircuitBreaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	switch b.state {
+	case CircuitOpen:
+		if b.now().Sub(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.state = CircuitHalfOpen
+		b.probing = true
+		return true
+	case CircuitHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+// record updates the breaker with the result of an allowed call.
+func (b *CircuitBreaker) record(err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.probing = false
+	if status.Code(err) == codes.Canceled {
+		return
+	}
+	if !connectionFailure(err) {
+		if b.state != CircuitClosed {
+			slog.Info("headscale circuit breaker closed")
+		}
+		b.state = CircuitClosed
+		b.failures = 0
+		return
+	}
+
+	b.failures++
+	if b.state == CircuitHalfOpen || b.failures >= b.threshold {
+		if b.state == CircuitClosed {
+			slog.Warn("headscale circuit breaker opened", "failures", b.failures, "error", err)
+		}
+		b.state = CircuitOpen
+		b.openedAt = b.now()
+	}
+}
+
+// connectionFailure reports whether err means Headscale could not be
+// reached, as opposed to Headscale rejecting the call.
+func connectionFailure(err error) bool {
+	switch status.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded:
+		return true
+	default:
+		return false
+	}
+}
+
+// UnaryClientInterceptor makes Headscale calls resilient to restarts. It
+// applies DefaultCallTimeout to calls without a deadline, retries
+// idempotent calls that fail with Unavailable according to policy, and
+// fails calls fast while breaker is open.
+func UnaryClientInterceptor(policy RetryPolicy, breaker *CircuitBreaker) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, DefaultCallTimeout)
+			defer cancel()
+		}
+
+		attempts := 1
+		if idempotentMethods[method] {
+			attempts = max(policy.MaxAttempts, 1)
+		}
+
+		var err error
+		for attempt := 1; ; attempt++ {
+			if !breaker.allow() {
+				return errCircuitOpen
+			}
+			err = invoker(ctx, method, req, reply, cc, opts...)
+			breaker.record(err)
+			if err == nil || attempt >= attempts || status.Code(err) != codes.Unavailable {
+				return err
+			}
+
+			if waitErr := sleep(ctx, policy.backoff(attempt)); waitErr != nil {
+				return err
+			}
+		}
+	}
+}
+
+// backoff returns the delay before retry number attempt, counting from 1.
+func (p RetryPolicy) backoff(attempt int) time.Duration {
+	d := p.InitialBackoff
+	for i := 1; i < attempt && d < p.MaxBackoff; i++ {
+		d *= 2
+	}
+	if d > p.MaxBackoff {
+		d = p.MaxBackoff
+	}
+	if d > 0 {
+		d = d/2 + rand.N(d/2+1)
+	}
+	return d
+}
+
+// sleep waits for d or until ctx is done.
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,132 @@
+package headscale
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	v1 "github.com/juanfont/headscale/gen/go/headscale/v1"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+var testRetryPolicy = RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: time.Millisecond}
+
+// failingInvoker fails the first failures calls with code and counts calls.
+func failingInvoker(failures int, code codes.Code, calls *int) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		*calls++
+		if *calls <= failures {
+			return status.Error(code, "failed")
+		}
+		return nil
+	}
+}
+
+func TestUnaryClientInterceptorRetries(t *testing.T) {
+	tests := []struct {
+		name      string
+		method    string
+		failures  int
+		code      codes.Code
+		wantCalls int
+		wantCode  codes.Code
+	}{
+		{"idempotent call recovers", v1.HeadscaleService_ListUsers_FullMethodName, 2, codes.Unavailable, 3, codes.OK},
+		{"idempotent call gives up", v1.HeadscaleService_ListUsers_FullMethodName, 5, codes.Unavailable, 3, codes.Unavailable},
+		{"create is not retried", v1.HeadscaleService_CreatePreAuthKey_FullMethodName, 1, codes.Unavailable, 1, codes.Unavailable},
+		{"rejection is not retried", v1.HeadscaleService_ListUsers_FullMethodName, 1, codes.InvalidArgument, 1, codes.InvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			interceptor := UnaryClientInterceptor(testRetryPolicy, NewCircuitBreaker(10, time.Minute))
+			err := interceptor(context.Background(), tt.method, nil, nil, nil, failingInvoker(tt.failures, tt.code, &calls))
+
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if got := status.Code(err); got != tt.wantCode {
+				t.Errorf("code = %v, want %v", got, tt.wantCode)
+			}
+		})
+	}
+}
+
+func TestUnaryClientInterceptorSetsDeadline(t *testing.T) {
+	interceptor := UnaryClientInterceptor(testRetryPolicy, NewCircuitBreaker(10, time.Minute))
+	invoker := func(ctx context.Context, method string, req, reply any, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if _, ok := ctx.Deadline(); !ok {
+			t.Error("call has no deadline")
+		}
+		return nil
+	}
+	if err := interceptor(context.Background(), v1.HeadscaleService_ListUsers_FullMethodName, nil, nil, nil, invoker); err != nil {
+		t.Fatalf("interceptor() error = %v", err)
+	}
+}
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Now()
+	breaker := NewCircuitBreaker(2, time.Minute)
+	breaker.now = func() time.Time { return now }
+	unavailable := status.Error(codes.Unavailable, "connection refused")
+
+	breaker.record(unavailable)
+	if got := breaker.State(); got != CircuitClosed {
+		t.Fatalf("state after 1 failure = %s, want %s", got, CircuitClosed)
+	}
+	breaker.record(status.Error(codes.NotFound, "no such user"))
+	breaker.record(unavailable)
+	if got := breaker.State(); got != CircuitClosed {
+		t.Fatalf("state after non-consecutive failures = %s, want %s", got, CircuitClosed)
+	}
+
+	breaker.record(unavailable)
+	if got := breaker.State(); got != CircuitOpen {
+		t.Fatalf("state after 2 failures = %s, want %s", got, CircuitOpen)
+	}
+	if breaker.allow() {
+		t.Fatal("open breaker allowed a call")
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("breaker did not allow a probe after the cooldown")
+	}
+	if breaker.allow() {
+		t.Fatal("half-open breaker allowed a second call during the probe")
+	}
+	breaker.record(unavailable)
+	if got := breaker.State(); got != CircuitOpen {
+		t.Fatalf("state after failed probe = %s, want %s", got, CircuitOpen)
+	}
+
+	now = now.Add(time.Minute)
+	if !breaker.allow() {
+		t.Fatal("breaker did not allow a probe after the cooldown")
+	}
+	breaker.record(nil)
+	if got := breaker.State(); got != CircuitClosed {
+		t.Fatalf("state after successful probe = %s, want %s", got, CircuitClosed)
+	}
+}
+
+func TestUnaryClientInterceptorFailsFastWhenOpen(t *testing.T) {
+	breaker := NewCircuitBreaker(1, time.Minute)
+	interceptor := UnaryClientInterceptor(RetryPolicy{MaxAttempts: 1}, breaker)
+
+	calls := 0
+	invoker := failingInvoker(10, codes.Unavailable, &calls)
+	_ = interceptor(context.Background(), v1.HeadscaleService_ListUsers_FullMethodName, nil, nil, nil, invoker)
+	err := interceptor(context.Background(), v1.HeadscaleService_ListUsers_FullMethodName, nil, nil, nil, invoker)
+
+	if calls != 1 {
+		t.Errorf("calls = %d, want 1", calls)
+	}
+	if got := status.Code(err); got != codes.Unavailable {
+		t.Errorf("code = %v, want %v", got, codes.Unavailable)
+	}
+}
@@ -50,6 +50,37 @@ func (m *WonderNetManager) GetOrCreateWonderNet(ctx context.Context, wonderNetNa
 	return createResp.GetUser(), nil
 }
 
+// DeleteWonderNet deletes all nodes of a wonder net and then its Headscale user.
+// It is a no-op if the user does not exist.
+func (m *WonderNetManager) DeleteWonderNet(ctx context.Context, wonderNetName string) error {
+	listResp, err := m.headscaleClient.ListUsers(ctx, &v1.ListUsersRequest{Name: wonderNetName})
+	if err != nil {
+		return fmt.Errorf("list users: %w", err)
+	}
+
+	for _, u := range listResp.GetUsers() {
+		if u.GetName() != wonderNetName {
+			continue
+		}
+
+		nodesResp, err := m.headscaleClient.ListNodes(ctx, &v1.ListNodesRequest{User: wonderNetName})
+		if err != nil {
+			return fmt.Errorf("list nodes: %w", err)
+		}
+		for _, node := range nodesResp.GetNodes() {
+			if _, err := m.headscaleClient.DeleteNode(ctx, &v1.DeleteNodeRequest{NodeId: node.GetId()}); err != nil {
+				return fmt.Errorf("delete node %d: %w", node.GetId(), err)
+			}
+		}
+
+		if _, err := m.headscaleClient.DeleteUser(ctx, &v1.DeleteUserRequest{Id: u.GetId()}); err != nil {
+			return fmt.Errorf("delete user: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // CreateAuthKey creates a pre-auth key for a wonder net by user ID
 func (m *WonderNetManager) CreateAuthKey(ctx context.Context, userID uint64, ttl time.Duration, reusable bool) (*v1.PreAuthKey, error) {
 	expiration := time.Now().Add(ttl)
@@ -0,0 +1,43 @@
+package jointoken
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// payloadPrefix starts every join payload.
+const payloadPrefix = "wonder://join?"
+
+// Payload returns the join payload of a token: a wonder://join URI with the
+// coordinator URL and the token, which join token QR codes encode so that
+// enrolling devices can scan it instead of typing the token. The coordinator
+// URL is also in the token's claims; it is repeated for scanners that do not
+// decode JWTs.
+func Payload(coordinatorURL, token string) string {
+	return payloadPrefix + url.Values{
+		"coordinator": {coordinatorURL},
+		"token":       {token},
+	}.Encode()
+}
+
+// IsPayload reports whether s is a join payload rather than a bare token.
+func IsPayload(s string) bool {
+	return strings.HasPrefix(s, payloadPrefix)
+}
+
+// ParsePayload returns the coordinator URL and token of a join payload.
+func ParsePayload(payload string) (coordinatorURL, token string, err error) {
+	if !IsPayload(payload) {
+		return "", "", fmt.Errorf("not a join payload")
+	}
+	query, err := url.ParseQuery(strings.TrimPrefix(payload, payloadPrefix))
+	if err != nil {
+		return "", "", fmt.Errorf("parse join payload: %w", err)
+	}
+	token = query.Get("token")
+	if token == "" {
+		return "", "", fmt.Errorf("join payload has no token")
+	}
+	return query.Get("coordinator"), token, nil
+}
@@ -0,0 +1,24 @@
+package jointoken
+
+import "testing"
+
+func TestPayload(t *testing.T) {
+	payload := Payload("https://wonder.example.com", "eyJhbGciOiJIUzI1NiJ9.e30.sig")
+	if !IsPayload(payload) {
+		t.Fatalf("IsPayload(%q) = false", payload)
+	}
+
+	coordinatorURL, token, err := ParsePayload(payload)
+	if err != nil {
+		t.Fatalf("ParsePayload() error = %v", err)
+	}
+	if coordinatorURL != "https://wonder.example.com" || token != "eyJhbGciOiJIUzI1NiJ9.e30.sig" {
+		t.Errorf("ParsePayload() = %q, %q", coordinatorURL, token)
+	}
+
+	for _, bad := range []string{"eyJhbGciOiJIUzI1NiJ9.e30.sig", "wonder://join?coordinator=https%3A%2F%2Fwonder.example.com"} {
+		if _, _, err := ParsePayload(bad); err == nil {
+			t.Errorf("ParsePayload(%q) error = nil, want error", bad)
+		}
+	}
+}
@@ -17,6 +17,12 @@
 //
 // Tokens are signed using HMAC-SHA256 with a shared secret between coordinator
 // instances. The token TTL is typically short (hours) to limit exposure if leaked.
+//
+// Exchanging a join token also yields a worker token: a long-lived JWT signed
+// with the same key that lets the worker report heartbeats for its node.
+// Worker tokens carry the WorkerTokenAudience audience, so they can never be
+// used as join tokens, and a jti by which the coordinator binds each token to
+// a node and expires or revokes it.
 package jointoken
 
 import (
@@ -26,6 +32,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
+	"github.com/google/uuid"
 )
 
 // Claims represents the JWT claims for a join token.
@@ -44,6 +51,34 @@ type Claims struct {
 	// WonderNetID is the unique identifier for the wonder net (tenant namespace)
 	// that this worker will join. Used for multi-tenant isolation.
 	WonderNetID string `json:"wonder_net_id"`
+
+	// MaxUses is how many workers may join with this token. Zero means
+	// DefaultMaxUses. The coordinator counts the joins of each token by its
+	// ID (jti).
+	MaxUses int `json:"max_uses,omitempty"`
+}
+
+// DefaultMaxUses is the number of joins allowed by a token without a
+// max_uses claim.
+const DefaultMaxUses = 1
+
+// AllowedUses returns how many workers may join with the token.
+func (c *Claims) AllowedUses() int {
+	if c.MaxUses > 0 {
+		return c.MaxUses
+	}
+	return DefaultMaxUses
+}
+
+// WorkerTokenAudience is the audience of worker tokens.
+const WorkerTokenAudience = "wonder-mesh-net-worker"
+
+// WorkerClaims represents the JWT claims for a worker token.
+type WorkerClaims struct {
+	jwt.RegisteredClaims
+
+	// WonderNetID is the wonder net the worker joined.
+	WonderNetID string `json:"wonder_net_id"`
 }
 
 // Generator creates signed join tokens for worker nodes.
@@ -81,22 +116,81 @@ func NewGenerator(signingKey, coordinatorURL string) *Generator {
 // Returns the signed JWT string, or an error if signing fails.
 //
 // The generated token includes:
-//   - Standard JWT claims: iat (issued at), exp (expiration), iss (issuer)
+//   - Standard JWT claims: iat (issued at), exp (expiration), iss (issuer), jti (token ID)
 //   - Custom claims: coordinator URL, wonder net ID
 func (g *Generator) Generate(wonderNetID string, ttl time.Duration) (string, error) {
+	token, _, err := g.Issue(Options{WonderNetID: wonderNetID, TTL: ttl})
+	return token, err
+}
+
+// GenerateWithCoordinatorURL creates a join token like Generate, but directs
+// the worker to coordinatorURL, such as the custom domain of a wonder net,
+// instead of the generator's URL.
+func (g *Generator) GenerateWithCoordinatorURL(wonderNetID, coordinatorURL string, ttl time.Duration) (string, error) {
+	token, _, err := g.Issue(Options{WonderNetID: wonderNetID, CoordinatorURL: coordinatorURL, TTL: ttl})
+	return token, err
+}
+
+// Options describes a join token to issue.
+type Options struct {
+	WonderNetID string
+	// CoordinatorURL overrides the generator's URL, such as with the custom
+	// domain of a wonder net.
+	CoordinatorURL string
+	TTL            time.Duration
+	// MaxUses is how many workers may join with the token. Zero leaves the
+	// claim out, which allows DefaultMaxUses joins.
+	MaxUses int
+}
+
+// Issue creates a new signed join token and returns it with its claims. The
+// claims' ID is a random jti that identifies the token to the coordinator.
+func (g *Generator) Issue(opts Options) (string, *Claims, error) {
+	coordinatorURL := opts.CoordinatorURL
+	if coordinatorURL == "" {
+		coordinatorURL = g.coordinatorURL
+	}
+
 	now := time.Now()
 	claims := &Claims{
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        uuid.New().String(),
 			IssuedAt:  jwt.NewNumericDate(now),
-			ExpiresAt: jwt.NewNumericDate(now.Add(ttl)),
+			ExpiresAt: jwt.NewNumericDate(now.Add(opts.TTL)),
 			Issuer:    "wonder-mesh-net",
 		},
-		CoordinatorURL: g.coordinatorURL,
-		WonderNetID:    wonderNetID,
+		CoordinatorURL: coordinatorURL,
+		WonderNetID:    opts.WonderNetID,
+		MaxUses:        opts.MaxUses,
+	}
+
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(g.signingKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
+}
+
+// IssueWorkerToken creates a signed worker token for the specified wonder
+// net and returns it with its claims. The claims' ID is a random jti that
+// identifies the token to the coordinator, which keeps the token's expiry
+// and revocation; the JWT itself does not expire.
+func (g *Generator) IssueWorkerToken(wonderNetID string) (string, *WorkerClaims, error) {
+	claims := &WorkerClaims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:       uuid.New().String(),
+			IssuedAt: jwt.NewNumericDate(time.Now()),
+			Issuer:   "wonder-mesh-net",
+			Audience: jwt.ClaimStrings{WorkerTokenAudience},
+		},
+		WonderNetID: wonderNetID,
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	return token.SignedString(g.signingKey)
+	token, err := jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString(g.signingKey)
+	if err != nil {
+		return "", nil, err
+	}
+	return token, claims, nil
 }
 
 // Validator validates join tokens and extracts their claims.
@@ -120,18 +214,15 @@ func NewValidator(signingKey string) *Validator {
 //
 // This method performs full validation:
 //   - Verifies the HMAC-SHA256 signature matches
-//   - Checks that the token has not expired
+//   - Checks that the token has an expiry and has not expired
 //   - Ensures the signing method is HMAC (prevents algorithm confusion attacks)
 //
+// Requiring an expiry also rejects worker tokens, which have none.
+//
 // Returns the decoded Claims on success, or an error if validation fails.
 // Common error cases include: expired token, invalid signature, malformed token.
 func (v *Validator) Validate(tokenString string) (*Claims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return v.signingKey, nil
-	})
+	token, err := jwt.ParseWithClaims(tokenString, &Claims{}, v.keyFunc, jwt.WithExpirationRequired())
 
 	if err != nil {
 		return nil, fmt.Errorf("invalid token: %w", err)
@@ -145,6 +236,32 @@ func (v *Validator) Validate(tokenString string) (*Claims, error) {
 	return claims, nil
 }
 
+// ValidateWorkerToken verifies a worker token's signature and audience,
+// returning the claims. Tokens without a jti, issued before the coordinator
+// tracked worker tokens, are rejected.
+func (v *Validator) ValidateWorkerToken(tokenString string) (*WorkerClaims, error) {
+	token, err := jwt.ParseWithClaims(tokenString, &WorkerClaims{}, v.keyFunc, jwt.WithAudience(WorkerTokenAudience))
+	if err != nil {
+		return nil, fmt.Errorf("invalid worker token: %w", err)
+	}
+
+	claims, ok := token.Claims.(*WorkerClaims)
+	if !ok || !token.Valid || claims.WonderNetID == "" || claims.ID == "" {
+		return nil, fmt.Errorf("invalid worker token claims")
+	}
+
+	return claims, nil
+}
+
+// keyFunc returns the signing key after checking that the token is signed
+// with HMAC, which prevents algorithm confusion attacks.
+func (v *Validator) keyFunc(token *jwt.Token) (interface{}, error) {
+	if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return v.signingKey, nil
+}
+
 // ParseUnsafe parses a token without validating the signature or expiration.
 //
 // This is used by the worker CLI to extract the coordinator URL from a token
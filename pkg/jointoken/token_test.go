@@ -0,0 +1,129 @@
+package jointoken
+
+import (
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const testSecret = "test-secret-that-is-at-least-32-bytes"
+
+func TestWorkerToken(t *testing.T) {
+	generator := NewGenerator(testSecret, "https://wonder.example.com")
+	validator := NewValidator(testSecret)
+
+	workerToken, issued, err := generator.IssueWorkerToken("wn-1")
+	if err != nil {
+		t.Fatalf("IssueWorkerToken() error = %v", err)
+	}
+
+	claims, err := validator.ValidateWorkerToken(workerToken)
+	if err != nil {
+		t.Fatalf("ValidateWorkerToken() error = %v", err)
+	}
+	if claims.WonderNetID != "wn-1" {
+		t.Errorf("ValidateWorkerToken().WonderNetID = %q, want %q", claims.WonderNetID, "wn-1")
+	}
+	if claims.ID == "" || claims.ID != issued.ID {
+		t.Errorf("ValidateWorkerToken().ID = %q, want the issued jti %q", claims.ID, issued.ID)
+	}
+
+	if _, err := validator.Validate(workerToken); err == nil {
+		t.Error("Validate(worker token) error = nil, want error")
+	}
+
+	joinToken, err := generator.Generate("wn-1", time.Hour)
+	if err != nil {
+		t.Fatalf("Generate() error = %v", err)
+	}
+	if _, err := validator.ValidateWorkerToken(joinToken); err == nil {
+		t.Error("ValidateWorkerToken(join token) error = nil, want error")
+	}
+
+	if _, err := NewValidator("another-secret-that-is-32-bytes-long").ValidateWorkerToken(workerToken); err == nil {
+		t.Error("ValidateWorkerToken() with another key error = nil, want error")
+	}
+
+	legacy, err := jwt.NewWithClaims(jwt.SigningMethodHS256, &WorkerClaims{
+		RegisteredClaims: jwt.RegisteredClaims{Audience: jwt.ClaimStrings{WorkerTokenAudience}},
+		WonderNetID:      "wn-1",
+	}).SignedString([]byte(testSecret))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := validator.ValidateWorkerToken(legacy); err == nil {
+		t.Error("ValidateWorkerToken(token without jti) error = nil, want error")
+	}
+}
+
+func TestGenerateWithCoordinatorURL(t *testing.T) {
+	generator := NewGenerator(testSecret, "https://wonder.example.com")
+	validator := NewValidator(testSecret)
+
+	tests := []struct {
+		name     string
+		generate func() (string, error)
+		wantURL  string
+	}{
+		{
+			name:     "generator URL",
+			generate: func() (string, error) { return generator.Generate("wn-1", time.Hour) },
+			wantURL:  "https://wonder.example.com",
+		},
+		{
+			name: "custom URL",
+			generate: func() (string, error) {
+				return generator.GenerateWithCoordinatorURL("wn-1", "https://mesh.acme.com", time.Hour)
+			},
+			wantURL: "https://mesh.acme.com",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := tt.generate()
+			if err != nil {
+				t.Fatalf("generate error = %v", err)
+			}
+			claims, err := validator.Validate(token)
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if claims.CoordinatorURL != tt.wantURL {
+				t.Errorf("CoordinatorURL = %q, want %q", claims.CoordinatorURL, tt.wantURL)
+			}
+		})
+	}
+}
+
+func TestIssue(t *testing.T) {
+	generator := NewGenerator(testSecret, "https://wonder.example.com")
+	validator := NewValidator(testSecret)
+
+	token, issued, err := generator.Issue(Options{WonderNetID: "wn-1", TTL: time.Hour, MaxUses: 3})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	claims, err := validator.Validate(token)
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if claims.ID == "" || claims.ID != issued.ID {
+		t.Errorf("jti = %q, want the issued %q", claims.ID, issued.ID)
+	}
+	if claims.AllowedUses() != 3 {
+		t.Errorf("AllowedUses() = %d, want 3", claims.AllowedUses())
+	}
+
+	_, other, err := generator.Issue(Options{WonderNetID: "wn-1", TTL: time.Hour})
+	if err != nil {
+		t.Fatalf("Issue() error = %v", err)
+	}
+	if other.ID == issued.ID {
+		t.Errorf("two tokens share jti %q", other.ID)
+	}
+	if other.AllowedUses() != DefaultMaxUses {
+		t.Errorf("AllowedUses() without max_uses = %d, want %d", other.AllowedUses(), DefaultMaxUses)
+	}
+}
@@ -0,0 +1,65 @@
+package jwtauth
+
+import (
+	"crypto/ed25519"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// Signer issues EdDSA-signed tokens for an issuer of its own, such as a
+// coordinator acting as identity provider. A Validator whose configuration
+// has the signer's KeySet and Issuer accepts them.
+type Signer struct {
+	issuer string
+	kid    string
+	key    ed25519.PrivateKey
+	keySet jwk.Set
+}
+
+// NewSigner creates a Signer for issuer. The key ID is derived from the
+// public key, so the same key always has the same ID.
+func NewSigner(issuer string, key ed25519.PrivateKey) (*Signer, error) {
+	pub := key.Public().(ed25519.PublicKey)
+	sum := sha256.Sum256(pub)
+	kid := hex.EncodeToString(sum[:8])
+
+	jwkKey, err := jwk.FromRaw(pub)
+	if err != nil {
+		return nil, fmt.Errorf("create JWK: %w", err)
+	}
+	_ = jwkKey.Set(jwk.KeyIDKey, kid)
+	_ = jwkKey.Set(jwk.AlgorithmKey, jwt.SigningMethodEdDSA.Alg())
+	_ = jwkKey.Set(jwk.KeyUsageKey, "sig")
+	keySet := jwk.NewSet()
+	if err := keySet.AddKey(jwkKey); err != nil {
+		return nil, fmt.Errorf("add JWK: %w", err)
+	}
+
+	return &Signer{issuer: issuer, kid: kid, key: key, keySet: keySet}, nil
+}
+
+// Issuer returns the iss claim of the signer's tokens.
+func (s *Signer) Issuer() string {
+	return s.issuer
+}
+
+// KeySet returns the public key of the signer as a JWKS.
+func (s *Signer) KeySet() jwk.Set {
+	return s.keySet
+}
+
+// Sign sets the issuer of claims and returns them as a signed token.
+func (s *Signer) Sign(claims *Claims) (string, error) {
+	claims.Issuer = s.issuer
+	token := jwt.NewWithClaims(jwt.SigningMethodEdDSA, claims)
+	token.Header["kid"] = s.kid
+	signed, err := token.SignedString(s.key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signed, nil
+}
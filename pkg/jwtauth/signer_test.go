@@ -0,0 +1,73 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+func TestSignerTokensValidate(t *testing.T) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := NewSigner("https://mesh.example.com/coordinator/auth", key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, otherKey, _ := ed25519.GenerateKey(rand.Reader)
+	other, err := NewSigner("https://mesh.example.com/coordinator/auth", otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Start does not fetch anything for an issuer with a key set.
+	v := NewValidator(ValidatorConfig{
+		KeySet:   signer.KeySet(),
+		Issuer:   signer.Issuer(),
+		Audience: "wonder-coordinator",
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := v.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	claims := func(exp time.Duration) *Claims {
+		return &Claims{
+			RegisteredClaims: jwt.RegisteredClaims{
+				Subject:   "user-1",
+				Audience:  jwt.ClaimStrings{"wonder-coordinator"},
+				ExpiresAt: jwt.NewNumericDate(time.Now().Add(exp)),
+			},
+			Email: "alice@example.com",
+		}
+	}
+	sign := func(s *Signer, c *Claims) string {
+		token, err := s.Sign(c)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+
+	got, err := v.Validate(sign(signer, claims(time.Hour)))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if got.Subject != "user-1" || got.Email != "alice@example.com" || got.Issuer != signer.Issuer() {
+		t.Errorf("Validate() = %+v, want the signed claims", got)
+	}
+
+	if _, err := v.Validate(sign(signer, claims(-time.Minute))); !errors.Is(err, ErrExpiredToken) {
+		t.Errorf("expired token: Validate() error = %v, want ErrExpiredToken", err)
+	}
+	if _, err := v.Validate(sign(other, claims(time.Hour))); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("token of another key: Validate() error = %v, want ErrInvalidToken", err)
+	}
+}
@@ -4,6 +4,7 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -51,17 +52,82 @@ type Claims struct {
 }
 
 // ValidatorConfig holds configuration for the JWT validator.
+//
+// JWKSURL, Issuer, and Audience describe the primary issuer, normally the
+// Keycloak realm the coordinator logs users in with. Issuers adds further
+// trusted issuers, such as the same realm behind another URL or another
+// realm. A token is checked against the issuer named by its iss claim.
+//
+// KeySet, when set, holds the primary issuer's signing keys instead of
+// fetching them from JWKSURL, e.g. the keys of a Signer.
 type ValidatorConfig struct {
-	JWKSURL         string
-	Issuer          string
-	Audience        string
+	JWKSURL  string
+	KeySet   jwk.Set
+	Issuer   string
+	Audience string
+	// Audiences are accepted by the primary issuer in addition to Audience.
+	Audiences       []string
+	Issuers         []IssuerConfig
 	RefreshInterval time.Duration
 }
 
+// IssuerConfig is a trusted token issuer with the JWKS URL its signing keys
+// are fetched from, or the keys themselves in KeySet. An empty Issuer
+// accepts tokens of any issuer, and an empty Audiences list accepts any
+// audience.
+type IssuerConfig struct {
+	Issuer    string
+	JWKSURL   string
+	KeySet    jwk.Set
+	Audiences []string
+}
+
+// issuers returns the primary issuer followed by the additional issuers.
+func (c ValidatorConfig) issuers() []IssuerConfig {
+	primary := IssuerConfig{Issuer: c.Issuer, JWKSURL: c.JWKSURL, KeySet: c.KeySet}
+	for _, aud := range append([]string{c.Audience}, c.Audiences...) {
+		if aud != "" {
+			primary.Audiences = append(primary.Audiences, aud)
+		}
+	}
+	return append([]IssuerConfig{primary}, c.Issuers...)
+}
+
+// issuer returns the trusted issuer for a token's iss claim. An exact match
+// wins over an issuer that accepts any iss.
+func (c ValidatorConfig) issuer(iss string) (IssuerConfig, bool) {
+	var wildcard *IssuerConfig
+	for _, issuer := range c.issuers() {
+		if issuer.Issuer == iss {
+			return issuer, true
+		}
+		if issuer.Issuer == "" && wildcard == nil {
+			wildcard = &issuer
+		}
+	}
+	if wildcard != nil {
+		return *wildcard, true
+	}
+	return IssuerConfig{}, false
+}
+
+// jwksURLs returns the distinct JWKS URLs of all issuers whose keys are
+// fetched.
+func (c ValidatorConfig) jwksURLs() []string {
+	var urls []string
+	for _, issuer := range c.issuers() {
+		if issuer.KeySet == nil && !slices.Contains(urls, issuer.JWKSURL) {
+			urls = append(urls, issuer.JWKSURL)
+		}
+	}
+	return urls
+}
+
 // Validator validates JWTs using JWKS from Keycloak.
 type Validator struct {
-	config  ValidatorConfig
-	keySet  jwk.Set
+	config ValidatorConfig
+	// keySets holds the key set of each JWKS URL of config.
+	keySets map[string]jwk.Set
 	mu      sync.RWMutex
 	lastErr error
 }
@@ -73,11 +139,13 @@ func NewValidator(config ValidatorConfig) *Validator {
 	}
 
 	return &Validator{
-		config: config,
+		config:  config,
+		keySets: make(map[string]jwk.Set),
 	}
 }
 
-// Start begins the background JWKS refresh goroutine.
+// Start begins the background JWKS refresh goroutine. It fails unless the
+// key sets of all issuers can be fetched.
 func (v *Validator) Start(ctx context.Context) error {
 	if err := v.refreshJWKS(ctx); err != nil {
 		return fmt.Errorf("initial JWKS fetch: %w", err)
@@ -88,7 +156,11 @@ func (v *Validator) Start(ctx context.Context) error {
 }
 
 func (v *Validator) refreshLoop(ctx context.Context) {
-	ticker := time.NewTicker(v.config.RefreshInterval)
+	v.mu.RLock()
+	interval := v.config.RefreshInterval
+	v.mu.RUnlock()
+
+	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
 	for {
@@ -105,26 +177,81 @@ func (v *Validator) refreshLoop(ctx context.Context) {
 	}
 }
 
+// refreshJWKS fetches the key set of every issuer. A key set that cannot be
+// fetched keeps its previous keys.
 func (v *Validator) refreshJWKS(ctx context.Context) error {
-	keySet, err := jwk.Fetch(ctx, v.config.JWKSURL)
-	if err != nil {
-		return fmt.Errorf("fetch JWKS: %w", err)
+	v.mu.RLock()
+	urls := v.config.jwksURLs()
+	v.mu.RUnlock()
+
+	var errs []error
+	for _, jwksURL := range urls {
+		keySet, err := jwk.Fetch(ctx, jwksURL)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("fetch JWKS %s: %w", jwksURL, err))
+			continue
+		}
+
+		v.mu.Lock()
+		// Drop the result if Reconfigure removed this JWKS URL meanwhile.
+		if slices.Contains(v.config.jwksURLs(), jwksURL) {
+			v.keySets[jwksURL] = keySet
+		}
+		v.mu.Unlock()
+	}
+
+	if err := errors.Join(errs...); err != nil {
+		return err
+	}
+	v.mu.Lock()
+	v.lastErr = nil
+	v.mu.Unlock()
+	return nil
+}
+
+// Reconfigure switches the validator to new issuers and audiences. The new
+// key sets are fetched before anything changes, so a failed fetch leaves the
+// validator as it was. The refresh interval cannot be changed.
+func (v *Validator) Reconfigure(ctx context.Context, config ValidatorConfig) error {
+	keySets := make(map[string]jwk.Set)
+	for _, jwksURL := range config.jwksURLs() {
+		keySet, err := jwk.Fetch(ctx, jwksURL)
+		if err != nil {
+			return fmt.Errorf("fetch JWKS %s: %w", jwksURL, err)
+		}
+		keySets[jwksURL] = keySet
 	}
 
 	v.mu.Lock()
-	v.keySet = keySet
+	config.RefreshInterval = v.config.RefreshInterval
+	v.config = config
+	v.keySets = keySets
 	v.lastErr = nil
 	v.mu.Unlock()
 
 	return nil
 }
 
-// Validate validates a JWT token and returns the claims.
+// Validate validates a JWT token and returns the claims. The token's iss
+// claim selects the trusted issuer whose keys and audiences it is checked
+// against.
 func (v *Validator) Validate(tokenString string) (*Claims, error) {
+	unverified := &Claims{}
+	if _, _, err := jwt.NewParser().ParseUnverified(tokenString, unverified); err != nil {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidToken, err.Error())
+	}
+
 	v.mu.RLock()
-	keySet := v.keySet
+	issuer, trusted := v.config.issuer(unverified.Issuer)
+	keySet := issuer.KeySet
+	if keySet == nil {
+		keySet = v.keySets[issuer.JWKSURL]
+	}
 	v.mu.RUnlock()
 
+	if !trusted {
+		return nil, fmt.Errorf("%w: %s is not a trusted issuer", ErrInvalidIssuer, unverified.Issuer)
+	}
 	if keySet == nil {
 		return nil, ErrJWKSFetchFailed
 	}
@@ -160,25 +287,18 @@ func (v *Validator) Validate(tokenString string) (*Claims, error) {
 		return nil, ErrInvalidToken
 	}
 
-	if v.config.Issuer != "" && claims.Issuer != v.config.Issuer {
-		return nil, fmt.Errorf("%w: got %s, want %s", ErrInvalidIssuer, claims.Issuer, v.config.Issuer)
-	}
-
-	if v.config.Audience != "" {
-		found := false
+	if len(issuer.Audiences) > 0 {
+		// Keycloak doesn't include aud claim by default, but always includes azp (authorized party)
+		// which contains the client ID that requested the token
+		found := slices.Contains(issuer.Audiences, claims.Azp)
 		for _, aud := range claims.Audience {
-			if aud == v.config.Audience {
+			if slices.Contains(issuer.Audiences, aud) {
 				found = true
 				break
 			}
 		}
-		// Keycloak doesn't include aud claim by default, but always includes azp (authorized party)
-		// which contains the client ID that requested the token
-		if !found && claims.Azp == v.config.Audience {
-			found = true
-		}
 		if !found {
-			return nil, fmt.Errorf("%w: %s not in aud=%v azp=%s", ErrInvalidAudience, v.config.Audience, claims.Audience, claims.Azp)
+			return nil, fmt.Errorf("%w: none of %v in aud=%v azp=%s", ErrInvalidAudience, issuer.Audiences, claims.Audience, claims.Azp)
 		}
 	}
 
@@ -0,0 +1,175 @@
+package jwtauth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+)
+
+// testIssuer is a token issuer with its own signing key, serving its JWKS.
+type testIssuer struct {
+	kid    string
+	key    *rsa.PrivateKey
+	server *httptest.Server
+}
+
+func newTestIssuer(t *testing.T, kid string) *testIssuer {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	pub, err := jwk.FromRaw(key.Public())
+	if err != nil {
+		t.Fatalf("jwk from key: %v", err)
+	}
+	_ = pub.Set(jwk.KeyIDKey, kid)
+	_ = pub.Set(jwk.AlgorithmKey, "RS256")
+	set := jwk.NewSet()
+	_ = set.AddKey(pub)
+	body, err := json.Marshal(set)
+	if err != nil {
+		t.Fatalf("encode JWKS: %v", err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		_, _ = w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+	return &testIssuer{kid: kid, key: key, server: server}
+}
+
+func (i *testIssuer) token(t *testing.T, iss, azp string, aud ...string) string {
+	t.Helper()
+
+	claims := Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    iss,
+			Subject:   "user-1",
+			Audience:  aud,
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+		Azp: azp,
+	}
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = i.kid
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatalf("sign token: %v", err)
+	}
+	return signed
+}
+
+func TestValidateMultipleIssuers(t *testing.T) {
+	realm := newTestIssuer(t, "realm-key")
+	other := newTestIssuer(t, "other-key")
+
+	v := NewValidator(ValidatorConfig{
+		JWKSURL:   realm.server.URL,
+		Issuer:    "http://keycloak:8080/realms/wonder",
+		Audience:  "wonder-mesh-net",
+		Audiences: []string{"wonder-cli"},
+		Issuers: []IssuerConfig{
+			// The same realm behind its external URL.
+			{Issuer: "https://auth.example.com/realms/wonder", JWKSURL: realm.server.URL, Audiences: []string{"wonder-mesh-net"}},
+			// Another realm, accepting any audience.
+			{Issuer: "https://auth.example.com/realms/partners", JWKSURL: other.server.URL},
+		},
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := v.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	tests := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{
+			name:  "primary issuer, client audience in azp",
+			token: realm.token(t, "http://keycloak:8080/realms/wonder", "wonder-mesh-net"),
+		},
+		{
+			name:  "primary issuer, additional audience",
+			token: realm.token(t, "http://keycloak:8080/realms/wonder", "", "wonder-cli"),
+		},
+		{
+			name:    "primary issuer, unknown audience",
+			token:   realm.token(t, "http://keycloak:8080/realms/wonder", "grafana", "grafana"),
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:  "external URL of the same realm",
+			token: realm.token(t, "https://auth.example.com/realms/wonder", "wonder-mesh-net"),
+		},
+		{
+			name:    "external URL does not accept the additional primary audience",
+			token:   realm.token(t, "https://auth.example.com/realms/wonder", "wonder-cli"),
+			wantErr: ErrInvalidAudience,
+		},
+		{
+			name:  "other realm, any audience",
+			token: other.token(t, "https://auth.example.com/realms/partners", "partner-app"),
+		},
+		{
+			name:    "other realm token signed with the wrong key",
+			token:   realm.token(t, "https://auth.example.com/realms/partners", "partner-app"),
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name:    "untrusted issuer",
+			token:   other.token(t, "https://evil.example.com/realms/wonder", "wonder-mesh-net"),
+			wantErr: ErrInvalidIssuer,
+		},
+		{
+			name:    "malformed token",
+			token:   "not-a-jwt",
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			claims, err := v.Validate(tt.token)
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Errorf("Validate() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Validate() error = %v", err)
+			}
+			if claims.Subject != "user-1" {
+				t.Errorf("Validate() subject = %q, want %q", claims.Subject, "user-1")
+			}
+		})
+	}
+}
+
+func TestValidateWithoutIssuerCheck(t *testing.T) {
+	realm := newTestIssuer(t, "realm-key")
+	v := NewValidator(ValidatorConfig{JWKSURL: realm.server.URL})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := v.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+
+	if _, err := v.Validate(realm.token(t, "https://anything.example.com", "any")); err != nil {
+		t.Errorf("Validate() error = %v, want nil", err)
+	}
+}
@@ -0,0 +1,460 @@
+// Package fake provides a Keycloak realm for tests. Keycloak serves the
+// endpoints of one realm that the coordinator, the wonder CLI, and the SDK
+// call: the JWKS, the authorization code flow with PKCE, the token endpoint,
+// userinfo, and the admin API user lookup.
+//
+// It signs tokens with its own RSA key, so they validate against its JWKS
+// with jwtauth. It keeps no sessions: the user signed in to the
+// authorization endpoint is the one set with SignIn.
+package fake
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/lestrrat-go/jwx/v2/jwk"
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+// TokenTTL is the lifetime of the access and ID tokens issued.
+const TokenTTL = 5 * time.Minute
+
+// User is a user account of the realm.
+type User struct {
+	// ID is the subject of the user's tokens.
+	ID       string `json:"id"`
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	Name     string `json:"-"`
+	Password string `json:"-"`
+}
+
+// Client is an OAuth client of the realm. A client with a secret is
+// confidential and has a service account for the client credentials grant.
+type Client struct {
+	ID     string
+	Secret string
+}
+
+// Keycloak is a Keycloak realm served over HTTP. Create one with New and
+// stop it with Close.
+type Keycloak struct {
+	realm  string
+	kid    string
+	key    *rsa.PrivateKey
+	jwks   []byte
+	server *httptest.Server
+
+	mu       sync.Mutex
+	clients  map[string]Client
+	users    []User
+	signedIn string
+	codes    map[string]authCode
+	refresh  map[string]grant
+}
+
+// authCode is an authorization code waiting to be exchanged.
+type authCode struct {
+	grant
+	redirectURI   string
+	codeChallenge string
+}
+
+// grant is what a code or refresh token can be redeemed for.
+type grant struct {
+	clientID string
+	userID   string
+}
+
+// New starts a Keycloak serving realm with the given clients.
+func New(realm string, clients ...Client) (*Keycloak, error) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("generate key: %w", err)
+	}
+	kc := &Keycloak{
+		realm:   realm,
+		kid:     randomHex(8),
+		key:     key,
+		clients: make(map[string]Client),
+		codes:   make(map[string]authCode),
+		refresh: make(map[string]grant),
+	}
+	for _, c := range clients {
+		kc.clients[c.ID] = c
+	}
+
+	pub, err := jwk.FromRaw(key.Public())
+	if err != nil {
+		return nil, fmt.Errorf("jwk from key: %w", err)
+	}
+	_ = pub.Set(jwk.KeyIDKey, kc.kid)
+	_ = pub.Set(jwk.AlgorithmKey, "RS256")
+	_ = pub.Set(jwk.KeyUsageKey, "sig")
+	set := jwk.NewSet()
+	_ = set.AddKey(pub)
+	if kc.jwks, err = json.Marshal(set); err != nil {
+		return nil, fmt.Errorf("encode JWKS: %w", err)
+	}
+
+	realmPath := "/realms/" + realm + "/protocol/openid-connect/"
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET "+realmPath+"certs", kc.handleCerts)
+	mux.HandleFunc("GET "+realmPath+"auth", kc.handleAuth)
+	mux.HandleFunc("POST "+realmPath+"token", kc.handleToken)
+	mux.HandleFunc("GET "+realmPath+"userinfo", kc.handleUserInfo)
+	mux.HandleFunc("GET /admin/realms/"+realm+"/users", kc.handleUsers)
+	kc.server = httptest.NewServer(mux)
+	return kc, nil
+}
+
+// Close stops the server.
+func (kc *Keycloak) Close() {
+	kc.server.Close()
+}
+
+// URL returns the base URL of the server, the coordinator's Keycloak URL.
+func (kc *Keycloak) URL() string {
+	return kc.server.URL
+}
+
+// Realm returns the name of the realm.
+func (kc *Keycloak) Realm() string {
+	return kc.realm
+}
+
+// Issuer returns the issuer of the realm's tokens.
+func (kc *Keycloak) Issuer() string {
+	return kc.server.URL + "/realms/" + kc.realm
+}
+
+// JWKSURL returns the URL of the realm's signing keys.
+func (kc *Keycloak) JWKSURL() string {
+	return kc.Issuer() + "/protocol/openid-connect/certs"
+}
+
+// ValidatorConfig returns the configuration of a validator accepting the
+// realm's tokens for audience.
+func (kc *Keycloak) ValidatorConfig(audience string) jwtauth.ValidatorConfig {
+	return jwtauth.ValidatorConfig{
+		JWKSURL:  kc.JWKSURL(),
+		Issuer:   kc.Issuer(),
+		Audience: audience,
+	}
+}
+
+// AddUser adds a user to the realm.
+func (kc *Keycloak) AddUser(u User) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.users = append(kc.users, u)
+}
+
+// SignIn makes the user with the given ID the one signed in to the
+// authorization endpoint, which then approves every login for them. An
+// empty ID signs out, so logins are refused.
+func (kc *Keycloak) SignIn(userID string) {
+	kc.mu.Lock()
+	defer kc.mu.Unlock()
+	kc.signedIn = userID
+}
+
+// Token returns an access token of the user with the given ID for
+// clientID, as the realm would issue it.
+func (kc *Keycloak) Token(userID, clientID string) (string, error) {
+	kc.mu.Lock()
+	user, ok := kc.user(userID)
+	kc.mu.Unlock()
+	if !ok {
+		return "", fmt.Errorf("user %q not found", userID)
+	}
+	return kc.sign(kc.claims(user, clientID))
+}
+
+func (kc *Keycloak) handleCerts(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	_, _ = w.Write(kc.jwks)
+}
+
+// handleAuth approves the login for the signed in user, redirecting back to
+// the client with a code.
+func (kc *Keycloak) handleAuth(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	if _, ok := kc.clients[q.Get("client_id")]; !ok {
+		http.Error(w, "unknown client", http.StatusBadRequest)
+		return
+	}
+	redirectURI, err := url.Parse(q.Get("redirect_uri"))
+	if err != nil || redirectURI.Host == "" || q.Get("response_type") != "code" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	if q.Get("code_challenge") != "" && q.Get("code_challenge_method") != "S256" {
+		http.Error(w, "unsupported code challenge method", http.StatusBadRequest)
+		return
+	}
+
+	kc.mu.Lock()
+	userID := kc.signedIn
+	code := randomHex(16)
+	if userID != "" {
+		kc.codes[code] = authCode{
+			grant:         grant{clientID: q.Get("client_id"), userID: userID},
+			redirectURI:   q.Get("redirect_uri"),
+			codeChallenge: q.Get("code_challenge"),
+		}
+	}
+	kc.mu.Unlock()
+
+	params := redirectURI.Query()
+	if userID == "" {
+		params.Set("error", "access_denied")
+	} else {
+		params.Set("code", code)
+	}
+	if state := q.Get("state"); state != "" {
+		params.Set("state", state)
+	}
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (kc *Keycloak) handleToken(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		writeOAuthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	clientID, secret, ok := r.BasicAuth()
+	if !ok {
+		clientID, secret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	client, ok := kc.clients[clientID]
+	if !ok || client.Secret != secret {
+		writeOAuthError(w, http.StatusUnauthorized, "unauthorized_client")
+		return
+	}
+
+	kc.mu.Lock()
+	g, errCode := kc.redeem(client, r.PostForm)
+	var user User
+	if errCode == "" && g.userID != "" {
+		if user, ok = kc.user(g.userID); !ok {
+			errCode = "invalid_grant"
+		}
+	}
+	refreshToken := ""
+	if errCode == "" && g.userID != "" {
+		refreshToken = randomHex(24)
+		kc.refresh[refreshToken] = g
+	}
+	kc.mu.Unlock()
+	if errCode != "" {
+		writeOAuthError(w, http.StatusBadRequest, errCode)
+		return
+	}
+
+	if g.userID == "" {
+		// Service account of the client.
+		user = User{ID: "service-account-" + client.ID, Username: "service-account-" + client.ID}
+	}
+	claims := kc.claims(user, client.ID)
+	access, err := kc.sign(claims)
+	if err != nil {
+		writeOAuthError(w, http.StatusInternalServerError, "server_error")
+		return
+	}
+	resp := map[string]any{
+		"access_token": access,
+		"token_type":   "Bearer",
+		"expires_in":   int(TokenTTL.Seconds()),
+	}
+	if refreshToken != "" {
+		resp["refresh_token"] = refreshToken
+		// The ID token is the access token, which carries the same claims.
+		resp["id_token"] = access
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// redeem checks a token request of client and returns the grant it is for,
+// with no user for the service account, or an OAuth error code. The caller
+// holds kc.mu.
+func (kc *Keycloak) redeem(client Client, form url.Values) (grant, string) {
+	switch form.Get("grant_type") {
+	case "authorization_code":
+		code, ok := kc.codes[form.Get("code")]
+		delete(kc.codes, form.Get("code"))
+		if !ok || code.clientID != client.ID || code.redirectURI != form.Get("redirect_uri") {
+			return grant{}, "invalid_grant"
+		}
+		if code.codeChallenge != "" && pkceChallenge(form.Get("code_verifier")) != code.codeChallenge {
+			return grant{}, "invalid_grant"
+		}
+		return code.grant, ""
+	case "refresh_token":
+		g, ok := kc.refresh[form.Get("refresh_token")]
+		delete(kc.refresh, form.Get("refresh_token"))
+		if !ok || g.clientID != client.ID {
+			return grant{}, "invalid_grant"
+		}
+		return g, ""
+	case "password":
+		i := slices.IndexFunc(kc.users, func(u User) bool {
+			return u.Username == form.Get("username") && u.Password == form.Get("password")
+		})
+		if i < 0 {
+			return grant{}, "invalid_grant"
+		}
+		return grant{clientID: client.ID, userID: kc.users[i].ID}, ""
+	case "client_credentials":
+		if client.Secret == "" {
+			return grant{}, "unauthorized_client"
+		}
+		return grant{clientID: client.ID}, ""
+	default:
+		return grant{}, "unsupported_grant_type"
+	}
+}
+
+func (kc *Keycloak) handleUserInfo(w http.ResponseWriter, r *http.Request) {
+	claims, ok := kc.bearer(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(map[string]any{
+		"sub":                claims.Subject,
+		"email":              claims.Email,
+		"email_verified":     claims.EmailVerified,
+		"name":               claims.Name,
+		"preferred_username": claims.PreferredUsername,
+		"given_name":         claims.GivenName,
+		"family_name":        claims.FamilyName,
+	})
+}
+
+// handleUsers serves the admin API user search, by exact email or
+// username, to service accounts.
+func (kc *Keycloak) handleUsers(w http.ResponseWriter, r *http.Request) {
+	claims, ok := kc.bearer(r)
+	if !ok {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+	if !strings.HasPrefix(claims.Subject, "service-account-") {
+		w.WriteHeader(http.StatusForbidden)
+		return
+	}
+
+	q := r.URL.Query()
+	exact := q.Get("exact") == "true"
+	matches := func(field, want string) bool {
+		if want == "" {
+			return true
+		}
+		if exact {
+			return strings.EqualFold(field, want)
+		}
+		return strings.Contains(strings.ToLower(field), strings.ToLower(want))
+	}
+
+	kc.mu.Lock()
+	users := []User{}
+	for _, u := range kc.users {
+		if matches(u.Email, q.Get("email")) && matches(u.Username, q.Get("username")) {
+			users = append(users, u)
+		}
+	}
+	kc.mu.Unlock()
+
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(users)
+}
+
+// bearer returns the claims of the request's bearer token, if the realm
+// issued it and it has not expired.
+func (kc *Keycloak) bearer(r *http.Request) (*jwtauth.Claims, bool) {
+	raw, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if !ok {
+		return nil, false
+	}
+	claims := &jwtauth.Claims{}
+	_, err := jwt.ParseWithClaims(raw, claims, func(*jwt.Token) (any, error) {
+		return &kc.key.PublicKey, nil
+	}, jwt.WithValidMethods([]string{"RS256"}), jwt.WithIssuer(kc.Issuer()))
+	return claims, err == nil
+}
+
+func (kc *Keycloak) user(id string) (User, bool) {
+	i := slices.IndexFunc(kc.users, func(u User) bool { return u.ID == id })
+	if i < 0 {
+		return User{}, false
+	}
+	return kc.users[i], true
+}
+
+func (kc *Keycloak) claims(u User, clientID string) *jwtauth.Claims {
+	now := time.Now()
+	given, family, _ := strings.Cut(u.Name, " ")
+	return &jwtauth.Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        randomHex(16),
+			Issuer:    kc.Issuer(),
+			Subject:   u.ID,
+			Audience:  jwt.ClaimStrings{clientID},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(TokenTTL)),
+		},
+		PreferredUsername: u.Username,
+		Email:             u.Email,
+		EmailVerified:     u.Email != "",
+		Name:              u.Name,
+		GivenName:         given,
+		FamilyName:        family,
+		Azp:               clientID,
+		Scope:             "openid profile email",
+	}
+}
+
+func (kc *Keycloak) sign(claims *jwtauth.Claims) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kc.kid
+	signed, err := token.SignedString(kc.key)
+	if err != nil {
+		return "", fmt.Errorf("sign token: %w", err)
+	}
+	return signed, nil
+}
+
+func writeOAuthError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
+
+// pkceChallenge returns the S256 code challenge of a PKCE code verifier.
+func pkceChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
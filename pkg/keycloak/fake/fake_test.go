@@ -0,0 +1,90 @@
+package fake
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/strrl/wonder-mesh-net/pkg/jwtauth"
+)
+
+func TestPasswordGrant(t *testing.T) {
+	kc, err := New("wonder", Client{ID: "wonder-cli"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer kc.Close()
+	kc.AddUser(User{ID: "user-1", Username: "alice", Email: "alice@example.com", Password: "pw"})
+
+	tokenURL := kc.Issuer() + "/protocol/openid-connect/token"
+	post := func(form url.Values) (*http.Response, map[string]any) {
+		t.Helper()
+		resp, err := http.Post(tokenURL, "application/x-www-form-urlencoded", strings.NewReader(form.Encode()))
+		if err != nil {
+			t.Fatalf("token request: %v", err)
+		}
+		defer resp.Body.Close()
+		var body map[string]any
+		_ = json.NewDecoder(resp.Body).Decode(&body)
+		return resp, body
+	}
+
+	resp, body := post(url.Values{"grant_type": {"password"}, "client_id": {"wonder-cli"}, "username": {"alice"}, "password": {"wrong"}})
+	if resp.StatusCode != http.StatusBadRequest || body["error"] != "invalid_grant" {
+		t.Errorf("wrong password: status %d, body %v, want invalid_grant", resp.StatusCode, body)
+	}
+	resp, body = post(url.Values{"grant_type": {"client_credentials"}, "client_id": {"wonder-cli"}})
+	if resp.StatusCode != http.StatusBadRequest || body["error"] != "unauthorized_client" {
+		t.Errorf("public client credentials: status %d, body %v, want unauthorized_client", resp.StatusCode, body)
+	}
+
+	resp, body = post(url.Values{"grant_type": {"password"}, "client_id": {"wonder-cli"}, "username": {"alice"}, "password": {"pw"}})
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("password grant: status %d, body %v", resp.StatusCode, body)
+	}
+
+	v := jwtauth.NewValidator(kc.ValidatorConfig("wonder-cli"))
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := v.Start(ctx); err != nil {
+		t.Fatalf("Start() error = %v", err)
+	}
+	claims, err := v.Validate(body["access_token"].(string))
+	if err != nil {
+		t.Fatalf("Validate() error = %v", err)
+	}
+	if claims.Subject != "user-1" || claims.PreferredUsername != "alice" {
+		t.Errorf("claims = %+v, want user-1 alice", claims)
+	}
+}
+
+func TestAuthSignedOut(t *testing.T) {
+	kc, err := New("wonder", Client{ID: "coordinator", Secret: "secret"})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer kc.Close()
+
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	authURL := kc.Issuer() + "/protocol/openid-connect/auth?" + url.Values{
+		"client_id":     {"coordinator"},
+		"response_type": {"code"},
+		"redirect_uri":  {"https://app.example.com/callback"},
+		"state":         {"xyz"},
+	}.Encode()
+	resp, err := client.Get(authURL)
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	_ = resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("parse callback: %v", err)
+	}
+	if callback.Query().Get("error") != "access_denied" || callback.Query().Get("state") != "xyz" {
+		t.Errorf("callback = %s, want access_denied with the state", callback)
+	}
+}
@@ -0,0 +1,161 @@
+// Package logging configures the process-wide slog logger of the wonder
+// binaries.
+//
+// Records are written as text or JSON to stderr, stdout, a size-rotated
+// file, or syslog (local or remote). The level is held in a shared
+// slog.LevelVar, so it can be changed at runtime with SetLevel without
+// rebuilding the logger.
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"net/url"
+	"os"
+	"strings"
+
+	"gopkg.in/natefinch/lumberjack.v2"
+)
+
+// Output formats.
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Config selects the handler of the default logger.
+type Config struct {
+	// Level is debug, info, warn, or error. Empty means info.
+	Level string
+	// Format is text (default) or json.
+	Format string
+	// Output is where records go:
+	//   - "" or "stderr", "stdout"
+	//   - "syslog" for the local syslog daemon
+	//   - "syslog://host:port" (UDP) or "syslog+tcp://host:port" for a remote one
+	//   - any other value is a file path, rotated by size
+	Output string
+
+	// MaxSizeMB is the size at which a log file is rotated. Zero means 100.
+	MaxSizeMB int
+	// MaxBackups is the number of rotated files kept. Zero keeps all.
+	MaxBackups int
+	// MaxAgeDays removes rotated files older than this. Zero keeps them.
+	MaxAgeDays int
+}
+
+var level = new(slog.LevelVar)
+
+// Setup installs a logger built from cfg as the slog default. tag names the
+// program in syslog messages. The returned closer releases the output and
+// must be called before exit to flush files and syslog connections.
+func Setup(cfg Config, tag string) (io.Closer, error) {
+	lvl, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := openOutput(cfg, tag)
+	if err != nil {
+		return nil, err
+	}
+
+	opts := &slog.HandlerOptions{Level: level}
+	var handler slog.Handler
+	switch cfg.Format {
+	case "", FormatText:
+		handler = slog.NewTextHandler(w, opts)
+	case FormatJSON:
+		handler = slog.NewJSONHandler(w, opts)
+	default:
+		_ = w.Close()
+		return nil, fmt.Errorf("unknown log format %q, want %s or %s", cfg.Format, FormatText, FormatJSON)
+	}
+
+	level.Set(lvl)
+	slog.SetDefault(slog.New(handler))
+	return w, nil
+}
+
+// ParseLevel parses a level name such as "debug" or "WARN". An empty name
+// is info.
+func ParseLevel(name string) (slog.Level, error) {
+	var lvl slog.Level
+	if name == "" {
+		return slog.LevelInfo, nil
+	}
+	if err := lvl.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("unknown log level %q, want debug, info, warn, or error", name)
+	}
+	return lvl, nil
+}
+
+// Level returns the current level of the default logger.
+func Level() slog.Level {
+	return level.Level()
+}
+
+// SetLevel changes the level of the default logger.
+func SetLevel(lvl slog.Level) {
+	level.Set(lvl)
+}
+
+// LevelName returns the lowercase name of a level, as accepted by ParseLevel.
+func LevelName(lvl slog.Level) string {
+	return strings.ToLower(lvl.String())
+}
+
+// openOutput opens the writer named by cfg.Output.
+func openOutput(cfg Config, tag string) (io.WriteCloser, error) {
+	switch {
+	case cfg.Output == "" || cfg.Output == "stderr":
+		return nopCloser{os.Stderr}, nil
+	case cfg.Output == "stdout":
+		return nopCloser{os.Stdout}, nil
+	case cfg.Output == "syslog":
+		w, err := dialSyslog("", "", tag)
+		if err != nil {
+			return nil, fmt.Errorf("connect to syslog: %w", err)
+		}
+		return w, nil
+	case strings.HasPrefix(cfg.Output, "syslog://"), strings.HasPrefix(cfg.Output, "syslog+tcp://"):
+		u, err := url.Parse(cfg.Output)
+		if err != nil || u.Host == "" {
+			return nil, fmt.Errorf("invalid syslog address %q, want syslog://host:port or syslog+tcp://host:port", cfg.Output)
+		}
+		network := "udp"
+		if u.Scheme == "syslog+tcp" {
+			network = "tcp"
+		}
+		w, err := dialSyslog(network, u.Host, tag)
+		if err != nil {
+			return nil, fmt.Errorf("connect to syslog at %s: %w", u.Host, err)
+		}
+		return w, nil
+	default:
+		maxSize := cfg.MaxSizeMB
+		if maxSize <= 0 {
+			maxSize = 100
+		}
+		w := &lumberjack.Logger{
+			Filename:   cfg.Output,
+			MaxSize:    maxSize,
+			MaxBackups: cfg.MaxBackups,
+			MaxAge:     cfg.MaxAgeDays,
+		}
+		// An empty write opens the file, so a bad path fails here rather
+		// than silently dropping every record.
+		if _, err := w.Write(nil); err != nil {
+			return nil, fmt.Errorf("open log file: %w", err)
+		}
+		return w, nil
+	}
+}
+
+// nopCloser keeps the standard streams open when the logger is closed.
+type nopCloser struct {
+	io.Writer
+}
+
+func (nopCloser) Close() error { return nil }
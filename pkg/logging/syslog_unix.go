@@ -0,0 +1,14 @@
+//go:build !windows && !plan9
+
+package logging
+
+import (
+	"io"
+	"log/syslog"
+)
+
+// dialSyslog connects to the local syslog daemon if network is empty, or
+// to the one at addr otherwise.
+func dialSyslog(network, addr, tag string) (io.WriteCloser, error) {
+	return syslog.Dial(network, addr, syslog.LOG_INFO|syslog.LOG_DAEMON, tag)
+}
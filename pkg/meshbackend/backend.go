@@ -50,6 +50,8 @@ type MeshBackend interface {
 	//   - login_server: the Headscale control URL
 	//   - authkey: the PreAuthKey
 	//   - headscale_user: the Headscale user/namespace
+	//   - authkey_id: the PreAuthKey's ID, matching Node.AuthKeyID of the
+	//     nodes that join with it
 	//
 	// For Netbird (future), this might return:
 	//   - setup_key: the setup key
@@ -67,6 +69,24 @@ type MeshBackend interface {
 	// nodeID is the backend-specific node identifier.
 	DeleteNode(ctx context.Context, nodeID string) error
 
+	// ExpireNode expires a node's key, disconnecting it from the mesh until
+	// it logs in again. The node itself is kept.
+	ExpireNode(ctx context.Context, nodeID string) error
+
+	// RenameNode changes the name a node is known by in the mesh.
+	// The device's own hostname is not changed.
+	RenameNode(ctx context.Context, nodeID, name string) error
+
+	// SetApprovedRoutes replaces the set of advertised routes the node is
+	// allowed to serve. Routes are CIDR prefixes; approving 0.0.0.0/0 and ::/0
+	// makes the node an exit node.
+	SetApprovedRoutes(ctx context.Context, nodeID string, routes []string) error
+
+	// SetTags replaces the tags the control server assigns to a node.
+	// Tagged nodes lose the identity of the realm they registered with, so
+	// ACL rules that select the realm's nodes no longer match them.
+	SetTags(ctx context.Context, nodeID string, tags []string) error
+
 	// Healthy performs a health check on the backend.
 	Healthy(ctx context.Context) error
 }
@@ -82,6 +102,10 @@ type JoinOptions struct {
 	// Ephemeral indicates if nodes using this credential should be ephemeral
 	// (automatically removed when they go offline).
 	Ephemeral bool
+
+	// ControlURL overrides the control server URL returned to the node,
+	// for realms served on their own domain. Empty uses the backend's URL.
+	ControlURL string
 }
 
 // Node represents a device connected to the mesh network.
@@ -90,9 +114,13 @@ type Node struct {
 	// ID is a unique identifier for the node within the backend.
 	ID string
 
-	// Name is the human-readable name of the node.
+	// Name is the human-readable name of the node in the mesh.
 	Name string
 
+	// Hostname is the hostname reported by the device itself. It differs from
+	// Name once the node has been renamed.
+	Hostname string
+
 	// Addresses are the mesh network IP addresses assigned to this node.
 	Addresses []string
 
@@ -106,4 +134,27 @@ type Node struct {
 	// Realm is the realm/namespace this node belongs to (e.g., Headscale user).
 	// This is populated by GetNode and used for ownership verification.
 	Realm string
+
+	// AdvertisedRoutes are the subnet and exit routes the node offers to serve.
+	AdvertisedRoutes []string
+
+	// ApprovedRoutes are the routes the node has been allowed to serve.
+	// A route takes effect only once it is both advertised and approved.
+	ApprovedRoutes []string
+
+	// ServingRoutes are the approved routes the node is currently serving.
+	ServingRoutes []string
+
+	// Tags are the tags the control server assigns to the node.
+	Tags []string
+
+	// AuthKeyID identifies the credential the node last joined with, as the
+	// authkey_id returned by CreateJoinCredentials. Empty if the backend
+	// doesn't report it.
+	AuthKeyID string
+
+	// CreatedAt is when the node registered with the control server, that is
+	// when its machine key was first seen. May be nil if the backend doesn't
+	// track this.
+	CreatedAt *time.Time
 }
@@ -0,0 +1,263 @@
+package tailnet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// DefaultAPIURL is the base URL of the Tailscale API.
+const DefaultAPIURL = "https://api.tailscale.com"
+
+// ErrPolicyConflict is returned by SetPolicy when the tailnet policy file
+// changed since it was read, such as by an admin in the Tailscale console.
+var ErrPolicyConflict = errors.New("tailnet policy changed concurrently")
+
+// Client calls the Tailscale API v2 for one tailnet, authenticated with an
+// API access token.
+type Client struct {
+	baseURL    string
+	tailnet    string
+	apiKey     string
+	httpClient *http.Client
+}
+
+// NewClient creates a client for the tailnet, such as "example.com" or "-"
+// for the tailnet of the API key. An empty baseURL uses DefaultAPIURL.
+func NewClient(baseURL, tailnet, apiKey string) *Client {
+	if baseURL == "" {
+		baseURL = DefaultAPIURL
+	}
+	return &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		tailnet:    tailnet,
+		apiKey:     apiKey,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+	}
+}
+
+// APIError is a non-2xx response of the Tailscale API.
+type APIError struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("tailscale api: %d %s", e.StatusCode, e.Message)
+}
+
+// Device is a device of the tailnet, as returned with fields=all.
+type Device struct {
+	ID                 string    `json:"id"`
+	Name               string    `json:"name"`
+	Hostname           string    `json:"hostname"`
+	Addresses          []string  `json:"addresses"`
+	Tags               []string  `json:"tags"`
+	Created            time.Time `json:"created"`
+	LastSeen           time.Time `json:"lastSeen"`
+	ConnectedToControl bool      `json:"connectedToControl"`
+	AdvertisedRoutes   []string  `json:"advertisedRoutes"`
+	EnabledRoutes      []string  `json:"enabledRoutes"`
+}
+
+// AuthKeyRequest describes an auth key to create.
+type AuthKeyRequest struct {
+	Reusable      bool
+	Ephemeral     bool
+	Preauthorized bool
+	// Tags are applied to every device that logs in with the key. They must
+	// have a tag owner in the tailnet policy.
+	Tags        []string
+	Expiry      time.Duration
+	Description string
+}
+
+// CreateAuthKey creates an auth key and returns its secret.
+func (c *Client) CreateAuthKey(ctx context.Context, req AuthKeyRequest) (string, error) {
+	body := map[string]any{
+		"capabilities": map[string]any{
+			"devices": map[string]any{
+				"create": map[string]any{
+					"reusable":      req.Reusable,
+					"ephemeral":     req.Ephemeral,
+					"preauthorized": req.Preauthorized,
+					"tags":          req.Tags,
+				},
+			},
+		},
+		"expirySeconds": int64(req.Expiry.Seconds()),
+		"description":   req.Description,
+	}
+	var resp struct {
+		Key string `json:"key"`
+	}
+	if _, err := c.do(ctx, http.MethodPost, c.tailnetPath("keys"), nil, body, &resp); err != nil {
+		return "", fmt.Errorf("create auth key: %w", err)
+	}
+	return resp.Key, nil
+}
+
+// ListDevices returns every device of the tailnet.
+func (c *Client) ListDevices(ctx context.Context) ([]Device, error) {
+	var resp struct {
+		Devices []Device `json:"devices"`
+	}
+	if _, err := c.do(ctx, http.MethodGet, c.tailnetPath("devices")+"?fields=all", nil, nil, &resp); err != nil {
+		return nil, fmt.Errorf("list devices: %w", err)
+	}
+	return resp.Devices, nil
+}
+
+// GetDevice returns one device.
+func (c *Client) GetDevice(ctx context.Context, id string) (*Device, error) {
+	var device Device
+	if _, err := c.do(ctx, http.MethodGet, devicePath(id, "")+"?fields=all", nil, nil, &device); err != nil {
+		return nil, fmt.Errorf("get device: %w", err)
+	}
+	return &device, nil
+}
+
+// DeleteDevice removes a device from the tailnet.
+func (c *Client) DeleteDevice(ctx context.Context, id string) error {
+	if _, err := c.do(ctx, http.MethodDelete, devicePath(id, ""), nil, nil, nil); err != nil {
+		return fmt.Errorf("delete device: %w", err)
+	}
+	return nil
+}
+
+// ExpireDevice expires the key of a device.
+func (c *Client) ExpireDevice(ctx context.Context, id string) error {
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id, "expire"), nil, nil, nil); err != nil {
+		return fmt.Errorf("expire device: %w", err)
+	}
+	return nil
+}
+
+// SetDeviceName sets the machine name of a device in the tailnet.
+func (c *Client) SetDeviceName(ctx context.Context, id, name string) error {
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id, "name"), nil, map[string]string{"name": name}, nil); err != nil {
+		return fmt.Errorf("set device name: %w", err)
+	}
+	return nil
+}
+
+// SetDeviceRoutes replaces the enabled subnet routes of a device.
+func (c *Client) SetDeviceRoutes(ctx context.Context, id string, routes []string) error {
+	if routes == nil {
+		routes = []string{}
+	}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id, "routes"), nil, map[string][]string{"routes": routes}, nil); err != nil {
+		return fmt.Errorf("set device routes: %w", err)
+	}
+	return nil
+}
+
+// SetDeviceTags replaces the tags of a device.
+func (c *Client) SetDeviceTags(ctx context.Context, id string, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+	if _, err := c.do(ctx, http.MethodPost, devicePath(id, "tags"), nil, map[string][]string{"tags": tags}, nil); err != nil {
+		return fmt.Errorf("set device tags: %w", err)
+	}
+	return nil
+}
+
+// Policy returns the tailnet policy file as JSON, with its comments
+// dropped, and its ETag for SetPolicy.
+func (c *Client) Policy(ctx context.Context) (map[string]any, string, error) {
+	policy := map[string]any{}
+	header, err := c.do(ctx, http.MethodGet, c.tailnetPath("acl"), nil, nil, &policy)
+	if err != nil {
+		return nil, "", fmt.Errorf("get policy: %w", err)
+	}
+	return policy, header.Get("ETag"), nil
+}
+
+// SetPolicy replaces the tailnet policy file. With a non-empty etag, it
+// returns ErrPolicyConflict unless the stored policy still has that ETag.
+func (c *Client) SetPolicy(ctx context.Context, policy map[string]any, etag string) error {
+	header := http.Header{}
+	if etag != "" {
+		header.Set("If-Match", etag)
+	}
+	_, err := c.do(ctx, http.MethodPost, c.tailnetPath("acl"), header, policy, nil)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusPreconditionFailed {
+		return ErrPolicyConflict
+	}
+	if err != nil {
+		return fmt.Errorf("set policy: %w", err)
+	}
+	return nil
+}
+
+func (c *Client) tailnetPath(resource string) string {
+	return "/api/v2/tailnet/" + url.PathEscape(c.tailnet) + "/" + resource
+}
+
+func devicePath(id, action string) string {
+	path := "/api/v2/device/" + url.PathEscape(id)
+	if action != "" {
+		path += "/" + action
+	}
+	return path
+}
+
+// do sends a request with body encoded as JSON and decodes a JSON response
+// into out, unless it is nil. It returns the response header.
+func (c *Client) do(ctx context.Context, method, path string, header http.Header, body, out any) (http.Header, error) {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("encode request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Authorization", "Bearer "+c.apiKey)
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		data, _ := io.ReadAll(io.LimitReader(resp.Body, 4096))
+		var apiResp struct {
+			Message string `json:"message"`
+		}
+		message := strings.TrimSpace(string(data))
+		if json.Unmarshal(data, &apiResp) == nil && apiResp.Message != "" {
+			message = apiResp.Message
+		}
+		return nil, &APIError{StatusCode: resp.StatusCode, Message: message}
+	}
+
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp.Header, nil
+}
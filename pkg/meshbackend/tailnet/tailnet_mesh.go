@@ -0,0 +1,380 @@
+// Package tailnet implements the MeshBackend interface using the Tailscale
+// control plane, for deployments that use an existing tailnet instead of
+// the embedded Headscale.
+//
+// Tailscale has no namespaces like Headscale users, so each realm is a tag:
+// devices join with an auth key that applies RealmTag of their realm and
+// CommonTag, and the backend keeps a tag owner and an isolation rule for
+// every realm in the tailnet policy file. Other entries of the policy file,
+// and devices without these tags, are left alone.
+package tailnet
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/strrl/wonder-mesh-net/pkg/meshbackend"
+)
+
+const (
+	// DefaultControlURL is the login server of the Tailscale control plane.
+	DefaultControlURL = "https://controlplane.tailscale.com"
+
+	// CommonTag is carried by every device that joined through the
+	// coordinator. Privileged realms reach the devices with this tag.
+	CommonTag = "tag:wonder"
+
+	// realmTagPrefix prefixes the realm name in the tag of a realm.
+	realmTagPrefix = "tag:wonder-"
+
+	// tagOwner may apply the coordinator's tags. Auth keys are created with
+	// an API access token of a tailnet admin.
+	tagOwner = "autogroup:admin"
+
+	// maxPolicyUpdateAttempts is how often a policy update is tried before
+	// giving up on concurrent changes.
+	maxPolicyUpdateAttempts = 5
+)
+
+// RealmTag returns the tag of the devices of a realm.
+func RealmTag(realm string) string {
+	return realmTagPrefix + realm
+}
+
+// TailnetMesh implements MeshBackend using the Tailscale control plane.
+type TailnetMesh struct {
+	client     *Client
+	controlURL string
+
+	// policyMu serializes the read-modify-write updates of the policy file
+	// within the process.
+	policyMu sync.Mutex
+}
+
+// NewTailnetMesh creates a new TailnetMesh backend.
+//
+// Parameters:
+//   - client: Tailscale API client for the tailnet
+//   - controlURL: the login server workers connect to, empty for DefaultControlURL
+func NewTailnetMesh(client *Client, controlURL string) *TailnetMesh {
+	if controlURL == "" {
+		controlURL = DefaultControlURL
+	}
+	return &TailnetMesh{
+		client:     client,
+		controlURL: controlURL,
+	}
+}
+
+// MeshType returns the mesh type identifier. Workers join the tailnet with
+// the same tailscale client as a Headscale mesh.
+func (m *TailnetMesh) MeshType() meshbackend.MeshType {
+	return meshbackend.MeshTypeTailscale
+}
+
+// CreateRealm adds the realm's tag owner and isolation rule to the tailnet
+// policy. This method is idempotent.
+func (m *TailnetMesh) CreateRealm(ctx context.Context, name string) error {
+	tag := RealmTag(name)
+	return m.updatePolicy(ctx, func(policy map[string]any) bool {
+		changed := setTagOwner(policy, CommonTag)
+		changed = setTagOwner(policy, tag) || changed
+		return addRule(policy, []string{tag}, []string{tag + ":*"}) || changed
+	})
+}
+
+// GetRealm checks if the realm's tag has an owner in the tailnet policy.
+func (m *TailnetMesh) GetRealm(ctx context.Context, name string) (bool, error) {
+	policy, _, err := m.client.Policy(ctx)
+	if err != nil {
+		return false, err
+	}
+	tagOwners, _ := policy["tagOwners"].(map[string]any)
+	_, ok := tagOwners[RealmTag(name)]
+	return ok, nil
+}
+
+// DeleteRealm removes the devices of a realm and then its tag owner and
+// rules from the tailnet policy. It is a no-op if the realm does not exist.
+func (m *TailnetMesh) DeleteRealm(ctx context.Context, name string) error {
+	nodes, err := m.ListNodes(ctx, name)
+	if err != nil {
+		return err
+	}
+	for _, node := range nodes {
+		if err := m.client.DeleteDevice(ctx, node.ID); err != nil {
+			return fmt.Errorf("delete device %s: %w", node.ID, err)
+		}
+	}
+
+	tag := RealmTag(name)
+	return m.updatePolicy(ctx, func(policy map[string]any) bool {
+		changed := false
+		if tagOwners, ok := policy["tagOwners"].(map[string]any); ok {
+			if _, ok := tagOwners[tag]; ok {
+				delete(tagOwners, tag)
+				changed = true
+			}
+		}
+		return removeRules(policy, func(src, _ []string) bool { return slices.Equal(src, []string{tag}) }) || changed
+	})
+}
+
+// SetPrivilegedRealms replaces the realms whose devices reach every device
+// that joined through the coordinator, in any realm.
+func (m *TailnetMesh) SetPrivilegedRealms(ctx context.Context, realms []string) error {
+	return m.updatePolicy(ctx, func(policy map[string]any) bool {
+		changed := setTagOwner(policy, CommonTag)
+		changed = removeRules(policy, func(src, dst []string) bool {
+			return len(src) == 1 && strings.HasPrefix(src[0], realmTagPrefix) &&
+				slices.Equal(dst, []string{CommonTag + ":*"}) &&
+				!slices.Contains(realms, strings.TrimPrefix(src[0], realmTagPrefix))
+		}) || changed
+		for _, realm := range realms {
+			changed = setTagOwner(policy, RealmTag(realm)) || changed
+			changed = addRule(policy, []string{RealmTag(realm)}, []string{CommonTag + ":*"}) || changed
+		}
+		return changed
+	})
+}
+
+// CreateAuthKey creates a preauthorized auth key that joins devices to the
+// realm, and returns its secret.
+func (m *TailnetMesh) CreateAuthKey(ctx context.Context, realmName string, ttl time.Duration, reusable bool, ephemeral bool) (string, error) {
+	return m.client.CreateAuthKey(ctx, AuthKeyRequest{
+		Reusable:      reusable,
+		Ephemeral:     ephemeral,
+		Preauthorized: true,
+		Tags:          []string{CommonTag, RealmTag(realmName)},
+		Expiry:        ttl,
+		Description:   "wonder-mesh-net",
+	})
+}
+
+// CreateJoinCredentials creates a Tailscale auth key for the realm and
+// returns Tailscale-specific metadata.
+//
+// The returned metadata contains:
+//   - login_server: the Tailscale control URL
+//   - authkey: the auth key for tailscale up --authkey
+//   - headscale_user: the realm name, under the key workers already read
+//
+// opts.ControlURL is ignored: custom public URLs of wonder nets front the
+// embedded Headscale, which this backend does not use.
+func (m *TailnetMesh) CreateJoinCredentials(ctx context.Context, realmName string, opts meshbackend.JoinOptions) (map[string]any, error) {
+	if err := m.CreateRealm(ctx, realmName); err != nil {
+		return nil, err
+	}
+
+	key, err := m.CreateAuthKey(ctx, realmName, opts.TTL, opts.Reusable, opts.Ephemeral)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]any{
+		"login_server":   m.controlURL,
+		"authkey":        key,
+		"headscale_user": realmName,
+	}, nil
+}
+
+// ListNodes returns the devices of a realm.
+func (m *TailnetMesh) ListNodes(ctx context.Context, realmName string) ([]*meshbackend.Node, error) {
+	devices, err := m.client.ListDevices(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tag := RealmTag(realmName)
+	nodes := make([]*meshbackend.Node, 0, len(devices))
+	for i := range devices {
+		if slices.Contains(devices[i].Tags, tag) {
+			nodes = append(nodes, deviceNode(&devices[i]))
+		}
+	}
+	return nodes, nil
+}
+
+// GetNode retrieves a single device by its ID.
+func (m *TailnetMesh) GetNode(ctx context.Context, nodeID string) (*meshbackend.Node, error) {
+	device, err := m.client.GetDevice(ctx, nodeID)
+	if err != nil {
+		return nil, err
+	}
+	return deviceNode(device), nil
+}
+
+// DeleteNode removes a device from the tailnet.
+func (m *TailnetMesh) DeleteNode(ctx context.Context, nodeID string) error {
+	return m.client.DeleteDevice(ctx, nodeID)
+}
+
+// ExpireNode expires the key of a device.
+func (m *TailnetMesh) ExpireNode(ctx context.Context, nodeID string) error {
+	return m.client.ExpireDevice(ctx, nodeID)
+}
+
+// RenameNode sets the machine name of a device.
+func (m *TailnetMesh) RenameNode(ctx context.Context, nodeID, name string) error {
+	return m.client.SetDeviceName(ctx, nodeID, name)
+}
+
+// SetApprovedRoutes sets the enabled routes of a device.
+func (m *TailnetMesh) SetApprovedRoutes(ctx context.Context, nodeID string, routes []string) error {
+	return m.client.SetDeviceRoutes(ctx, nodeID, routes)
+}
+
+// SetTags replaces the tags of a device other than the coordinator's own,
+// so that, unlike with Headscale, tagged devices stay in their realm. The
+// tags must have an owner in the tailnet policy.
+func (m *TailnetMesh) SetTags(ctx context.Context, nodeID string, tags []string) error {
+	device, err := m.client.GetDevice(ctx, nodeID)
+	if err != nil {
+		return err
+	}
+
+	managed := slices.DeleteFunc(slices.Clone(device.Tags), func(tag string) bool { return !isManagedTag(tag) })
+	return m.client.SetDeviceTags(ctx, nodeID, append(managed, tags...))
+}
+
+// Healthy checks if the Tailscale API is reachable with the API key.
+func (m *TailnetMesh) Healthy(ctx context.Context) error {
+	if _, _, err := m.client.Policy(ctx); err != nil {
+		return fmt.Errorf("tailscale health check: %w", err)
+	}
+	return nil
+}
+
+// updatePolicy applies fn to the tailnet policy and writes it back if fn
+// reports a change. If the policy changed in between, it reads the policy
+// again and retries, so that neither change is lost.
+func (m *TailnetMesh) updatePolicy(ctx context.Context, fn func(policy map[string]any) bool) error {
+	m.policyMu.Lock()
+	defer m.policyMu.Unlock()
+
+	for attempt := 1; ; attempt++ {
+		policy, etag, err := m.client.Policy(ctx)
+		if err != nil {
+			return err
+		}
+		if !fn(policy) {
+			return nil
+		}
+		err = m.client.SetPolicy(ctx, policy, etag)
+		if !errors.Is(err, ErrPolicyConflict) || attempt == maxPolicyUpdateAttempts {
+			return err
+		}
+		slog.Info("tailnet policy changed concurrently, retrying", "attempt", attempt)
+	}
+}
+
+// setTagOwner gives tag an owner in the policy unless it has one, and
+// reports whether it changed the policy.
+func setTagOwner(policy map[string]any, tag string) bool {
+	tagOwners, ok := policy["tagOwners"].(map[string]any)
+	if !ok {
+		tagOwners = map[string]any{}
+		policy["tagOwners"] = tagOwners
+	}
+	if _, ok := tagOwners[tag]; ok {
+		return false
+	}
+	tagOwners[tag] = []any{tagOwner}
+	return true
+}
+
+// addRule appends an accept rule from src to dst unless the policy has it,
+// and reports whether it changed the policy.
+func addRule(policy map[string]any, src, dst []string) bool {
+	acls, _ := policy["acls"].([]any)
+	for _, entry := range acls {
+		ruleSrc, ruleDst := ruleSelectors(entry)
+		if slices.Equal(ruleSrc, src) && slices.Equal(ruleDst, dst) {
+			return false
+		}
+	}
+	policy["acls"] = append(acls, map[string]any{
+		"action": "accept",
+		"src":    src,
+		"dst":    dst,
+	})
+	return true
+}
+
+// removeRules removes the rules for which match returns true, and reports
+// whether it changed the policy.
+func removeRules(policy map[string]any, match func(src, dst []string) bool) bool {
+	acls, ok := policy["acls"].([]any)
+	if !ok {
+		return false
+	}
+	kept := slices.DeleteFunc(slices.Clone(acls), func(entry any) bool {
+		return match(ruleSelectors(entry))
+	})
+	if len(kept) == len(acls) {
+		return false
+	}
+	policy["acls"] = kept
+	return true
+}
+
+// ruleSelectors returns the sources and destinations of a policy rule.
+func ruleSelectors(entry any) (src, dst []string) {
+	rule, _ := entry.(map[string]any)
+	return stringSlice(rule["src"]), stringSlice(rule["dst"])
+}
+
+func stringSlice(v any) []string {
+	items, _ := v.([]any)
+	out := make([]string, 0, len(items))
+	for _, item := range items {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// isManagedTag reports whether the coordinator assigns the tag itself.
+func isManagedTag(tag string) bool {
+	return tag == CommonTag || strings.HasPrefix(tag, realmTagPrefix)
+}
+
+// deviceNode converts a Tailscale device to a Node. Its name is the first
+// label of its MagicDNS name, and its tags exclude the coordinator's own.
+func deviceNode(d *Device) *meshbackend.Node {
+	node := &meshbackend.Node{
+		ID:               d.ID,
+		Name:             strings.SplitN(d.Name, ".", 2)[0],
+		Hostname:         d.Hostname,
+		Addresses:        d.Addresses,
+		Online:           d.ConnectedToControl,
+		AdvertisedRoutes: d.AdvertisedRoutes,
+		ApprovedRoutes:   d.EnabledRoutes,
+		ServingRoutes: slices.DeleteFunc(slices.Clone(d.EnabledRoutes), func(route string) bool {
+			return !slices.Contains(d.AdvertisedRoutes, route)
+		}),
+	}
+	for _, tag := range d.Tags {
+		if strings.HasPrefix(tag, realmTagPrefix) {
+			node.Realm = strings.TrimPrefix(tag, realmTagPrefix)
+		} else if !isManagedTag(tag) {
+			node.Tags = append(node.Tags, tag)
+		}
+	}
+	if !d.LastSeen.IsZero() {
+		t := d.LastSeen
+		node.LastSeen = &t
+	}
+	if !d.Created.IsZero() {
+		t := d.Created
+		node.CreatedAt = &t
+	}
+	return node
+}
@@ -0,0 +1,169 @@
+package tailnet
+
+import (
+	"context"
+	"encoding/json"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"strconv"
+	"sync"
+	"testing"
+)
+
+// fakeTailnet serves the parts of the Tailscale API the backend uses, for
+// one tailnet whose policy file has a version that changes on every write.
+type fakeTailnet struct {
+	mu      sync.Mutex
+	policy  map[string]any
+	version int
+	devices map[string]*Device
+	// concurrentWrites is how many times the policy is changed behind the
+	// backend's back between its read and its write.
+	concurrentWrites int
+}
+
+func (f *fakeTailnet) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	switch {
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/tailnet/-/acl":
+		w.Header().Set("ETag", strconv.Quote(strconv.Itoa(f.version)))
+		_ = json.NewEncoder(w).Encode(f.policy)
+		if f.concurrentWrites > 0 {
+			f.concurrentWrites--
+			f.version++
+		}
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v2/tailnet/-/acl":
+		if r.Header.Get("If-Match") != strconv.Quote(strconv.Itoa(f.version)) {
+			http.Error(w, `{"message":"precondition failed"}`, http.StatusPreconditionFailed)
+			return
+		}
+		var policy map[string]any
+		_ = json.NewDecoder(r.Body).Decode(&policy)
+		f.policy = policy
+		f.version++
+		_ = json.NewEncoder(w).Encode(policy)
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/tailnet/-/devices":
+		devices := []*Device{}
+		for _, id := range slices.Sorted(maps.Keys(f.devices)) {
+			devices = append(devices, f.devices[id])
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"devices": devices})
+	case r.Method == http.MethodGet && r.URL.Path == "/api/v2/device/1":
+		_ = json.NewEncoder(w).Encode(f.devices["1"])
+	case r.Method == http.MethodPost && r.URL.Path == "/api/v2/device/1/tags":
+		var req struct {
+			Tags []string `json:"tags"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		f.devices["1"].Tags = req.Tags
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newTestMesh(t *testing.T, fake *fakeTailnet) *TailnetMesh {
+	t.Helper()
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+	return NewTailnetMesh(NewClient(server.URL, "-", "tskey-api-test"), "")
+}
+
+func TestCreateRealmKeepsOtherPolicyEntries(t *testing.T) {
+	fake := &fakeTailnet{policy: map[string]any{
+		"acls":   []any{map[string]any{"action": "accept", "src": []any{"group:admins"}, "dst": []any{"*:*"}}},
+		"groups": map[string]any{"group:admins": []any{"alice@example.com"}},
+	}}
+	mesh := newTestMesh(t, fake)
+
+	if err := mesh.CreateRealm(context.Background(), "net-a"); err != nil {
+		t.Fatalf("CreateRealm() error = %v", err)
+	}
+	version := fake.version
+	if err := mesh.CreateRealm(context.Background(), "net-a"); err != nil {
+		t.Fatalf("CreateRealm() again error = %v", err)
+	}
+	if fake.version != version {
+		t.Errorf("CreateRealm() of an existing realm wrote the policy")
+	}
+
+	if _, ok := fake.policy["groups"]; !ok {
+		t.Errorf("policy lost its groups: %v", fake.policy)
+	}
+	acls := fake.policy["acls"].([]any)
+	if len(acls) != 2 {
+		t.Fatalf("policy has %d rules, want 2: %v", len(acls), acls)
+	}
+	src, dst := ruleSelectors(acls[1])
+	if !slices.Equal(src, []string{"tag:wonder-net-a"}) || !slices.Equal(dst, []string{"tag:wonder-net-a:*"}) {
+		t.Errorf("realm rule = %v -> %v", src, dst)
+	}
+	exists, err := mesh.GetRealm(context.Background(), "net-a")
+	if err != nil || !exists {
+		t.Errorf("GetRealm() = %v, %v, want true", exists, err)
+	}
+}
+
+func TestCreateRealmRetriesConcurrentPolicyChanges(t *testing.T) {
+	fake := &fakeTailnet{policy: map[string]any{}, concurrentWrites: 2}
+	mesh := newTestMesh(t, fake)
+
+	if err := mesh.CreateRealm(context.Background(), "net-a"); err != nil {
+		t.Fatalf("CreateRealm() error = %v", err)
+	}
+	if exists, _ := mesh.GetRealm(context.Background(), "net-a"); !exists {
+		t.Errorf("realm not created after retries")
+	}
+}
+
+func TestSetPrivilegedRealmsReplacesRules(t *testing.T) {
+	fake := &fakeTailnet{policy: map[string]any{}}
+	mesh := newTestMesh(t, fake)
+	ctx := context.Background()
+
+	if err := mesh.SetPrivilegedRealms(ctx, []string{"hub-a"}); err != nil {
+		t.Fatalf("SetPrivilegedRealms() error = %v", err)
+	}
+	if err := mesh.SetPrivilegedRealms(ctx, []string{"hub-b"}); err != nil {
+		t.Fatalf("SetPrivilegedRealms() error = %v", err)
+	}
+
+	var sources []string
+	for _, rule := range fake.policy["acls"].([]any) {
+		src, _ := ruleSelectors(rule)
+		sources = append(sources, src...)
+	}
+	if !slices.Equal(sources, []string{"tag:wonder-hub-b"}) {
+		t.Errorf("privileged rule sources = %v, want only tag:wonder-hub-b", sources)
+	}
+}
+
+func TestListNodesAndSetTagsUseRealmTag(t *testing.T) {
+	fake := &fakeTailnet{devices: map[string]*Device{
+		"1": {ID: "1", Name: "web.example.ts.net", Hostname: "web", Tags: []string{CommonTag, "tag:wonder-net-a"}, ConnectedToControl: true},
+		"2": {ID: "2", Name: "laptop.example.ts.net", Hostname: "laptop"},
+	}}
+	mesh := newTestMesh(t, fake)
+	ctx := context.Background()
+
+	nodes, err := mesh.ListNodes(ctx, "net-a")
+	if err != nil {
+		t.Fatalf("ListNodes() error = %v", err)
+	}
+	if len(nodes) != 1 || nodes[0].ID != "1" || nodes[0].Name != "web" || nodes[0].Realm != "net-a" || !nodes[0].Online {
+		t.Fatalf("ListNodes() = %+v, want device 1 named web", nodes)
+	}
+	if len(nodes[0].Tags) != 0 {
+		t.Errorf("node tags = %v, want the managed tags hidden", nodes[0].Tags)
+	}
+
+	if err := mesh.SetTags(ctx, "1", []string{"tag:db"}); err != nil {
+		t.Fatalf("SetTags() error = %v", err)
+	}
+	if want := []string{CommonTag, "tag:wonder-net-a", "tag:db"}; !slices.Equal(fake.devices["1"].Tags, want) {
+		t.Errorf("device tags = %v, want %v", fake.devices["1"].Tags, want)
+	}
+}
@@ -92,9 +92,10 @@ func (m *TailscaleMesh) getOrCreateRealm(ctx context.Context, name string) (*v1.
 // CreateJoinCredentials creates a Headscale PreAuthKey and returns Tailscale-specific metadata.
 //
 // The returned metadata contains:
-//   - login_server: the Headscale control URL
+//   - login_server: the Headscale control URL, or opts.ControlURL if set
 //   - authkey: the PreAuthKey for tailscale up --authkey
 //   - headscale_user: the Headscale user/namespace name
+//   - authkey_id: the PreAuthKey's ID
 func (m *TailscaleMesh) CreateJoinCredentials(ctx context.Context, realmName string, opts meshbackend.JoinOptions) (map[string]any, error) {
 	user, err := m.getOrCreateRealm(ctx, realmName)
 	if err != nil {
@@ -112,10 +113,16 @@ func (m *TailscaleMesh) CreateJoinCredentials(ctx context.Context, realmName str
 		return nil, fmt.Errorf("create pre-auth key: %w", err)
 	}
 
+	loginServer := m.controlURL
+	if opts.ControlURL != "" {
+		loginServer = opts.ControlURL
+	}
+
 	return map[string]any{
-		"login_server":   m.controlURL,
+		"login_server":   loginServer,
 		"authkey":        keyResp.GetPreAuthKey().GetKey(),
 		"headscale_user": realmName,
+		"authkey_id":     fmt.Sprintf("%d", keyResp.GetPreAuthKey().GetId()),
 	}, nil
 }
 
@@ -129,15 +136,27 @@ func (m *TailscaleMesh) ListNodes(ctx context.Context, realmName string) ([]*mes
 	nodes := make([]*meshbackend.Node, 0, len(resp.GetNodes()))
 	for _, n := range resp.GetNodes() {
 		node := &meshbackend.Node{
-			ID:        fmt.Sprintf("%d", n.GetId()),
-			Name:      n.GetName(),
-			Addresses: n.GetIpAddresses(),
-			Online:    n.GetOnline(),
+			ID:               fmt.Sprintf("%d", n.GetId()),
+			Name:             nodeName(n),
+			Hostname:         n.GetName(),
+			Addresses:        n.GetIpAddresses(),
+			Online:           n.GetOnline(),
+			AdvertisedRoutes: n.GetAvailableRoutes(),
+			ApprovedRoutes:   n.GetApprovedRoutes(),
+			ServingRoutes:    n.GetSubnetRoutes(),
+			Tags:             n.GetForcedTags(),
+		}
+		if n.GetPreAuthKey() != nil {
+			node.AuthKeyID = fmt.Sprintf("%d", n.GetPreAuthKey().GetId())
 		}
 		if n.GetLastSeen() != nil {
 			t := n.GetLastSeen().AsTime()
 			node.LastSeen = &t
 		}
+		if n.GetCreatedAt() != nil {
+			t := n.GetCreatedAt().AsTime()
+			node.CreatedAt = &t
+		}
 		nodes = append(nodes, node)
 	}
 
@@ -158,16 +177,28 @@ func (m *TailscaleMesh) GetNode(ctx context.Context, nodeID string) (*meshbacken
 
 	hsNode := resp.GetNode()
 	node := &meshbackend.Node{
-		ID:        fmt.Sprintf("%d", hsNode.GetId()),
-		Name:      hsNode.GetName(),
-		Addresses: hsNode.GetIpAddresses(),
-		Online:    hsNode.GetOnline(),
+		ID:               fmt.Sprintf("%d", hsNode.GetId()),
+		Name:             nodeName(hsNode),
+		Hostname:         hsNode.GetName(),
+		Addresses:        hsNode.GetIpAddresses(),
+		Online:           hsNode.GetOnline(),
+		AdvertisedRoutes: hsNode.GetAvailableRoutes(),
+		ApprovedRoutes:   hsNode.GetApprovedRoutes(),
+		ServingRoutes:    hsNode.GetSubnetRoutes(),
+		Tags:             hsNode.GetForcedTags(),
 	}
 
+	if hsNode.GetPreAuthKey() != nil {
+		node.AuthKeyID = fmt.Sprintf("%d", hsNode.GetPreAuthKey().GetId())
+	}
 	if hsNode.GetLastSeen() != nil {
 		t := hsNode.GetLastSeen().AsTime()
 		node.LastSeen = &t
 	}
+	if hsNode.GetCreatedAt() != nil {
+		t := hsNode.GetCreatedAt().AsTime()
+		node.CreatedAt = &t
+	}
 
 	// Store the realm (Headscale user) in a custom field
 	// This is needed for verification in DeleteNode
@@ -193,6 +224,70 @@ func (m *TailscaleMesh) DeleteNode(ctx context.Context, nodeID string) error {
 	return nil
 }
 
+// ExpireNode expires a node's key in Headscale.
+func (m *TailscaleMesh) ExpireNode(ctx context.Context, nodeID string) error {
+	var id uint64
+	if _, err := fmt.Sscanf(nodeID, "%d", &id); err != nil {
+		return fmt.Errorf("parse node ID: %w", err)
+	}
+
+	_, err := m.client.ExpireNode(ctx, &v1.ExpireNodeRequest{NodeId: id})
+	if err != nil {
+		return fmt.Errorf("expire node: %w", err)
+	}
+	return nil
+}
+
+// RenameNode sets the Headscale given name of a node.
+func (m *TailscaleMesh) RenameNode(ctx context.Context, nodeID, name string) error {
+	var id uint64
+	if _, err := fmt.Sscanf(nodeID, "%d", &id); err != nil {
+		return fmt.Errorf("parse node ID: %w", err)
+	}
+
+	_, err := m.client.RenameNode(ctx, &v1.RenameNodeRequest{NodeId: id, NewName: name})
+	if err != nil {
+		return fmt.Errorf("rename node: %w", err)
+	}
+	return nil
+}
+
+// SetApprovedRoutes sets the routes Headscale allows a node to serve.
+func (m *TailscaleMesh) SetApprovedRoutes(ctx context.Context, nodeID string, routes []string) error {
+	var id uint64
+	if _, err := fmt.Sscanf(nodeID, "%d", &id); err != nil {
+		return fmt.Errorf("parse node ID: %w", err)
+	}
+
+	_, err := m.client.SetApprovedRoutes(ctx, &v1.SetApprovedRoutesRequest{NodeId: id, Routes: routes})
+	if err != nil {
+		return fmt.Errorf("set approved routes: %w", err)
+	}
+	return nil
+}
+
+// SetTags replaces the forced tags of a node.
+func (m *TailscaleMesh) SetTags(ctx context.Context, nodeID string, tags []string) error {
+	var id uint64
+	if _, err := fmt.Sscanf(nodeID, "%d", &id); err != nil {
+		return fmt.Errorf("parse node ID: %w", err)
+	}
+
+	if _, err := m.client.SetTags(ctx, &v1.SetTagsRequest{NodeId: id, Tags: tags}); err != nil {
+		return fmt.Errorf("set tags: %w", err)
+	}
+	return nil
+}
+
+// nodeName returns the name a node is known by in the mesh: its given name,
+// falling back to the hostname it registered with.
+func nodeName(n *v1.Node) string {
+	if n.GetGivenName() != "" {
+		return n.GetGivenName()
+	}
+	return n.GetName()
+}
+
 // Healthy checks if the Headscale server is reachable.
 func (m *TailscaleMesh) Healthy(ctx context.Context) error {
 	_, err := m.client.ListUsers(ctx, &v1.ListUsersRequest{})
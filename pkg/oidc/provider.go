@@ -0,0 +1,252 @@
+// Package oidc logs users in with GitHub and Google through the OAuth 2.0
+// authorization code flow, for a coordinator that is its own identity
+// provider.
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Provider names.
+const (
+	ProviderGitHub = "github"
+	ProviderGoogle = "google"
+)
+
+var (
+	ErrTokenExchange = errors.New("token exchange failed")
+	ErrUserInfo      = errors.New("user info request failed")
+)
+
+// Identity is a user account at a provider, as reported after login.
+// Subject is the provider's stable account ID; usernames and emails can
+// change.
+type Identity struct {
+	Subject       string
+	Username      string
+	Email         string
+	EmailVerified bool
+	Name          string
+}
+
+// Provider is an OAuth 2.0 provider users log in with. The endpoint URLs
+// default to the provider's public ones.
+type Provider struct {
+	Name         string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+
+	AuthURL     string
+	TokenURL    string
+	UserInfoURL string
+	// EmailsURL lists a GitHub user's email addresses with their
+	// verification status, which the user endpoint lacks.
+	EmailsURL string
+
+	HTTPClient *http.Client
+}
+
+// GitHub returns the provider for a GitHub OAuth app.
+func GitHub(clientID, clientSecret string) *Provider {
+	return &Provider{
+		Name:         ProviderGitHub,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"read:user", "user:email"},
+		AuthURL:      "https://github.com/login/oauth/authorize",
+		TokenURL:     "https://github.com/login/oauth/access_token",
+		UserInfoURL:  "https://api.github.com/user",
+		EmailsURL:    "https://api.github.com/user/emails",
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Google returns the provider for a Google OAuth client, using the userinfo
+// endpoint of Google's OpenID Connect API.
+func Google(clientID, clientSecret string) *Provider {
+	return &Provider{
+		Name:         ProviderGoogle,
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Scopes:       []string{"openid", "email", "profile"},
+		AuthURL:      "https://accounts.google.com/o/oauth2/v2/auth",
+		TokenURL:     "https://oauth2.googleapis.com/token",
+		UserInfoURL:  "https://openidconnect.googleapis.com/v1/userinfo",
+		HTTPClient:   &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// AuthCodeURL returns the URL to send the user's browser to for logging in.
+// The provider redirects back to redirectURI with state and a code, which
+// Exchange redeems with the PKCE verifier of codeChallenge.
+func (p *Provider) AuthCodeURL(state, redirectURI, codeChallenge string) string {
+	params := url.Values{}
+	params.Set("client_id", p.ClientID)
+	params.Set("response_type", "code")
+	params.Set("scope", strings.Join(p.Scopes, " "))
+	params.Set("redirect_uri", redirectURI)
+	params.Set("state", state)
+	params.Set("code_challenge", codeChallenge)
+	params.Set("code_challenge_method", "S256")
+	return p.AuthURL + "?" + params.Encode()
+}
+
+// Exchange redeems an authorization code and returns the identity of the
+// user who logged in.
+func (p *Provider) Exchange(ctx context.Context, code, redirectURI, codeVerifier string) (*Identity, error) {
+	accessToken, err := p.exchangeCode(ctx, code, redirectURI, codeVerifier)
+	if err != nil {
+		return nil, err
+	}
+	if p.Name == ProviderGitHub {
+		return p.gitHubIdentity(ctx, accessToken)
+	}
+	return p.openIDIdentity(ctx, accessToken)
+}
+
+// exchangeCode redeems an authorization code for an access token.
+func (p *Provider) exchangeCode(ctx context.Context, code, redirectURI, codeVerifier string) (string, error) {
+	data := url.Values{}
+	data.Set("grant_type", "authorization_code")
+	data.Set("client_id", p.ClientID)
+	data.Set("client_secret", p.ClientSecret)
+	data.Set("code", code)
+	data.Set("redirect_uri", redirectURI)
+	data.Set("code_verifier", codeVerifier)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.TokenURL, strings.NewReader(data.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("create token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	// GitHub answers with a form-encoded body unless asked for JSON.
+	req.Header.Set("Accept", "application/json")
+
+	var token struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := p.doJSON(req, &token); err != nil {
+		return "", fmt.Errorf("%w: %s", ErrTokenExchange, err)
+	}
+	// GitHub reports errors with status 200.
+	if token.Error != "" {
+		return "", fmt.Errorf("%w: %s: %s", ErrTokenExchange, token.Error, token.ErrorDescription)
+	}
+	if token.AccessToken == "" {
+		return "", fmt.Errorf("%w: no access token in response", ErrTokenExchange)
+	}
+	return token.AccessToken, nil
+}
+
+// openIDIdentity reads the identity from an OpenID Connect userinfo
+// endpoint.
+func (p *Provider) openIDIdentity(ctx context.Context, accessToken string) (*Identity, error) {
+	var info struct {
+		Subject       string `json:"sub"`
+		Email         string `json:"email"`
+		EmailVerified bool   `json:"email_verified"`
+		Name          string `json:"name"`
+	}
+	if err := p.get(ctx, p.UserInfoURL, accessToken, &info); err != nil {
+		return nil, err
+	}
+	if info.Subject == "" {
+		return nil, fmt.Errorf("%w: no sub in response", ErrUserInfo)
+	}
+	return &Identity{
+		Subject:       info.Subject,
+		Username:      info.Email,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		Name:          info.Name,
+	}, nil
+}
+
+// gitHubIdentity reads the identity from the GitHub API. The email is the
+// user's primary address if it is verified.
+func (p *Provider) gitHubIdentity(ctx context.Context, accessToken string) (*Identity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.get(ctx, p.UserInfoURL, accessToken, &user); err != nil {
+		return nil, err
+	}
+	if user.ID == 0 {
+		return nil, fmt.Errorf("%w: no id in response", ErrUserInfo)
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.get(ctx, p.EmailsURL, accessToken, &emails); err != nil {
+		return nil, err
+	}
+
+	identity := &Identity{
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Username: user.Login,
+		Name:     user.Name,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+		}
+	}
+	return identity, nil
+}
+
+// get fetches a JSON resource with the user's access token.
+func (p *Provider) get(ctx context.Context, resourceURL, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, resourceURL, nil)
+	if err != nil {
+		return fmt.Errorf("create user info request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/json")
+	if err := p.doJSON(req, v); err != nil {
+		return fmt.Errorf("%w: %s", ErrUserInfo, err)
+	}
+	return nil
+}
+
+// doJSON sends req and decodes a successful JSON response into v.
+func (p *Provider) doJSON(req *http.Request, v any) error {
+	client := p.HTTPClient
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("%s: status %d, body: %s", req.URL.Path, resp.StatusCode, string(body))
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		return fmt.Errorf("parse %s response: %w", req.URL.Path, err)
+	}
+	return nil
+}
@@ -0,0 +1,129 @@
+package oidc
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+)
+
+// fakeProvider serves the token and user endpoints of GitHub and Google.
+func fakeProvider(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "good-code" || r.FormValue("code_verifier") != "verifier" || r.FormValue("client_secret") != "secret" {
+			// GitHub's way of rejecting a code.
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		_ = json.NewEncoder(w).Encode(map[string]string{"access_token": "user-token"})
+	})
+	authorized := func(next http.HandlerFunc) http.HandlerFunc {
+		return func(w http.ResponseWriter, r *http.Request) {
+			if r.Header.Get("Authorization") != "Bearer user-token" {
+				http.Error(w, "unauthorized", http.StatusUnauthorized)
+				return
+			}
+			next(w, r)
+		}
+	}
+	mux.HandleFunc("GET /user", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"id": 4242, "login": "octocat", "name": "The Octocat"})
+	}))
+	mux.HandleFunc("GET /user/emails", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode([]map[string]any{
+			{"email": "old@example.com", "primary": false, "verified": true},
+			{"email": "octocat@example.com", "primary": true, "verified": true},
+		})
+	}))
+	mux.HandleFunc("GET /userinfo", authorized(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(map[string]any{"sub": "1093", "email": "alice@example.com", "email_verified": true, "name": "Alice"})
+	}))
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestProviderExchange(t *testing.T) {
+	server := fakeProvider(t)
+	at := func(p *Provider) *Provider {
+		p.TokenURL = server.URL + "/token"
+		p.UserInfoURL = server.URL + "/user"
+		if p.Name == ProviderGitHub {
+			p.EmailsURL = server.URL + "/user/emails"
+		} else {
+			p.UserInfoURL = server.URL + "/userinfo"
+		}
+		return p
+	}
+
+	tests := []struct {
+		name     string
+		provider *Provider
+		code     string
+		want     Identity
+		wantErr  error
+	}{
+		{
+			name:     "github",
+			provider: at(GitHub("id", "secret")),
+			code:     "good-code",
+			want:     Identity{Subject: "4242", Username: "octocat", Email: "octocat@example.com", EmailVerified: true, Name: "The Octocat"},
+		},
+		{
+			name:     "google",
+			provider: at(Google("id", "secret")),
+			code:     "good-code",
+			want:     Identity{Subject: "1093", Username: "alice@example.com", Email: "alice@example.com", EmailVerified: true, Name: "Alice"},
+		},
+		{
+			name:     "rejected code",
+			provider: at(GitHub("id", "secret")),
+			code:     "bad-code",
+			wantErr:  ErrTokenExchange,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tt.provider.Exchange(context.Background(), tt.code, "https://mesh.example.com/callback", "verifier")
+			if tt.wantErr != nil {
+				if !errors.Is(err, tt.wantErr) {
+					t.Fatalf("Exchange() error = %v, want %v", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Exchange() error = %v", err)
+			}
+			if *got != tt.want {
+				t.Errorf("Exchange() = %+v, want %+v", *got, tt.want)
+			}
+		})
+	}
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	p := Google("client-1", "secret")
+	u, err := url.Parse(p.AuthCodeURL("state-1", "https://mesh.example.com/cb", "challenge"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	q := u.Query()
+	for key, want := range map[string]string{
+		"client_id":             "client-1",
+		"redirect_uri":          "https://mesh.example.com/cb",
+		"state":                 "state-1",
+		"scope":                 "openid email profile",
+		"code_challenge":        "challenge",
+		"code_challenge_method": "S256",
+	} {
+		if got := q.Get(key); got != want {
+			t.Errorf("%s = %q, want %q", key, got, want)
+		}
+	}
+}
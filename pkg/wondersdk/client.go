@@ -1,11 +1,17 @@
 package wondersdk
 
 import (
+	"bufio"
+	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -14,97 +20,894 @@ type Client struct {
 	baseURL    string
 	apiKey     string
 	httpClient *http.Client
+	retry      RetryPolicy
+	network    string
 }
 
-// NewClient creates a new SDK client
+// ClientOptions configures a client created with NewClientWithOptions.
+type ClientOptions struct {
+	// Timeout bounds each attempt of a request, including reading the
+	// response. Zero uses 30 seconds. It does not apply to WatchNodes.
+	Timeout time.Duration
+	// Retry controls retries of idempotent requests. The zero value sends
+	// every request once.
+	Retry RetryPolicy
+	// HTTPClient, if set, sends the requests instead of a new client. Its
+	// Timeout is replaced by Timeout.
+	HTTPClient *http.Client
+	// Network, if set, selects the wonder net of requests authenticated
+	// with a session token, by ID or display name, instead of the user's
+	// default one. A network query parameter of a request takes precedence.
+	// API keys are bound to their wonder net and ignore it.
+	Network string
+}
+
+// NewClient creates a new SDK client with a 30 second request timeout and
+// DefaultRetryPolicy. Services authenticate with an API key created with
+// the scopes they need; the coordinator rejects Keycloak service account
+// tokens.
 func NewClient(coordinatorURL, apiKey string) *Client {
+	return NewClientWithOptions(coordinatorURL, apiKey, ClientOptions{Retry: DefaultRetryPolicy})
+}
+
+// NewClientWithOptions creates a new SDK client with the given timeout,
+// retry policy, and HTTP client.
+func NewClientWithOptions(coordinatorURL, apiKey string, opts ClientOptions) *Client {
+	httpClient := &http.Client{}
+	if opts.HTTPClient != nil {
+		copied := *opts.HTTPClient
+		httpClient = &copied
+	}
+	httpClient.Timeout = opts.Timeout
+	if httpClient.Timeout == 0 {
+		httpClient.Timeout = 30 * time.Second
+	}
+
 	return &Client{
-		baseURL: coordinatorURL,
-		apiKey:  apiKey,
-		httpClient: &http.Client{
-			Timeout: 30 * time.Second,
-		},
+		baseURL:    coordinatorURL,
+		apiKey:     apiKey,
+		httpClient: httpClient,
+		retry:      opts.Retry,
+		network:    opts.Network,
 	}
 }
 
-// Node represents a node in the mesh
+// Node represents a node in the mesh. Addresses lists all its mesh
+// addresses, and IP has one of each IP family; see Address to pick one.
+// CreatedAt is when the node registered with the mesh; OS and
+// TailscaleVersion are those its worker last reported.
 type Node struct {
-	ID        uint64   `json:"id"`
-	Name      string   `json:"name"`
-	Addresses []string `json:"ip_addresses"`
-	Online    bool     `json:"online"`
-	LastSeen  string   `json:"last_seen,omitempty"`
+	ID               uint64   `json:"id"`
+	Name             string   `json:"name"`
+	Hostname         string   `json:"hostname,omitempty"`
+	Addresses        []string `json:"ip_addresses"`
+	IP               NodeIP   `json:"ip"`
+	Online           bool     `json:"online"`
+	LastSeen         string   `json:"last_seen,omitempty"`
+	CreatedAt        string   `json:"created_at,omitempty"`
+	Tags             []string `json:"tags,omitempty"`
+	OS               string   `json:"os,omitempty"`
+	TailscaleVersion string   `json:"tailscale_version,omitempty"`
+	// Health is the worker's last heartbeat, nil if it never sent one.
+	Health *NodeHealth `json:"health,omitempty"`
+}
+
+// NodeHealth is the health a node's worker last reported. Healthy is false
+// once the report is older than the coordinator's heartbeat timeout.
+type NodeHealth struct {
+	Healthy              bool   `json:"healthy"`
+	OS                   string `json:"os"`
+	TailscaleVersion     string `json:"tailscale_version"`
+	DiskTotalBytes       int64  `json:"disk_total_bytes"`
+	DiskFreeBytes        int64  `json:"disk_free_bytes"`
+	MemoryTotalBytes     int64  `json:"memory_total_bytes"`
+	MemoryAvailableBytes int64  `json:"memory_available_bytes"`
+	ReportedAt           string `json:"reported_at"`
+}
+
+// ListNodesOptions filters the nodes returned by ListNodesWithOptions.
+type ListNodesOptions struct {
+	// Online restricts results to online (true) or offline (false) nodes. Nil returns both.
+	Online *bool
+	// LastSeenWithin keeps only nodes seen within this duration. Zero disables the filter.
+	LastSeenWithin time.Duration
+	// Healthy restricts results to nodes with (true) or without (false) a
+	// recent worker heartbeat. Nil returns both.
+	Healthy *bool
+	// OS keeps only nodes whose worker reported this operating system, such
+	// as "linux". Empty returns all.
+	OS string
+	// Tag keeps only nodes with this tag, with or without the "tag:" prefix.
+	// Empty returns all.
+	Tag string
+	// Search keeps only nodes whose name or hostname contains it, ignoring
+	// case. Empty returns all.
+	Search string
+	// Sort orders the nodes by "id" (the default), "name", or "last_seen",
+	// prefixed with "-" for descending order.
+	Sort string
+	// Limit is the page size for ListNodesPage, at most 1000. Zero returns
+	// all nodes in one page. ListNodesWithOptions fetches every page.
+	Limit int
+	// Cursor continues a listing from the NextCursor of the previous page.
+	Cursor string
+}
+
+// NodePage is one page of a node listing, in the order of
+// ListNodesOptions.Sort. NextCursor is empty on the last page.
+type NodePage struct {
+	Nodes      []Node
+	NextCursor string
 }
 
 // ListNodes returns all nodes for a user session or API key.
 // If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
 func (c *Client) ListNodes(ctx context.Context, token string) ([]Node, error) {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v1/nodes", nil)
-	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+	return c.ListNodesWithOptions(ctx, token, ListNodesOptions{})
+}
+
+// ListNodesWithOptions returns nodes matching the given filters, including
+// offline nodes unless opts.Online says otherwise. With opts.Limit set, the
+// nodes are fetched that many at a time.
+func (c *Client) ListNodesWithOptions(ctx context.Context, token string, opts ListNodesOptions) ([]Node, error) {
+	var nodes []Node
+	for {
+		page, err := c.ListNodesPage(ctx, token, opts)
+		if err != nil {
+			return nil, err
+		}
+		nodes = append(nodes, page.Nodes...)
+		if page.NextCursor == "" {
+			return nodes, nil
+		}
+		opts.Cursor = page.NextCursor
 	}
+}
 
-	bearerToken := token
-	if bearerToken == "" {
-		bearerToken = c.apiKey
+// ListNodesPage returns one page of the nodes matching the given filters,
+// starting at opts.Cursor.
+func (c *Client) ListNodesPage(ctx context.Context, token string, opts ListNodesOptions) (*NodePage, error) {
+	query := url.Values{}
+	if opts.Online != nil {
+		query.Set("online", strconv.FormatBool(*opts.Online))
 	}
-	if bearerToken != "" {
-		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	if opts.LastSeenWithin > 0 {
+		query.Set("last_seen_within", opts.LastSeenWithin.String())
 	}
-
-	resp, err := c.httpClient.Do(req)
-	if err != nil {
-		return nil, fmt.Errorf("send request: %w", err)
+	if opts.Healthy != nil {
+		query.Set("healthy", strconv.FormatBool(*opts.Healthy))
+	}
+	if opts.OS != "" {
+		query.Set("os", opts.OS)
+	}
+	if opts.Tag != "" {
+		query.Set("tag", opts.Tag)
+	}
+	if opts.Search != "" {
+		query.Set("search", opts.Search)
+	}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
 	}
-	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("request failed: status %d, body: %s", resp.StatusCode, string(body))
+	path := "/api/v1/nodes"
+	if len(query) > 0 {
+		path += "?" + query.Encode()
 	}
 
 	var result struct {
-		Nodes []Node `json:"nodes"`
+		Nodes      []Node `json:"nodes"`
+		NextCursor string `json:"next_cursor"`
 	}
-	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
-		return nil, fmt.Errorf("decode response: %w", err)
+	if err := c.doJSON(ctx, http.MethodGet, path, token, nil, &result); err != nil {
+		return nil, err
 	}
-
-	return result.Nodes, nil
+	return &NodePage{Nodes: result.Nodes, NextCursor: result.NextCursor}, nil
 }
 
 // GetOnlineNodes returns only online nodes for a user session or API key.
 func (c *Client) GetOnlineNodes(ctx context.Context, token string) ([]Node, error) {
-	nodes, err := c.ListNodes(ctx, token)
+	online := true
+	return c.ListNodesWithOptions(ctx, token, ListNodesOptions{Online: &online})
+}
+
+// GetHealthyNodes returns the nodes whose workers sent a heartbeat recently.
+func (c *Client) GetHealthyNodes(ctx context.Context, token string) ([]Node, error) {
+	healthy := true
+	return c.ListNodesWithOptions(ctx, token, ListNodesOptions{Healthy: &healthy})
+}
+
+// GetRecentNodes returns online and offline nodes seen within the given duration.
+func (c *Client) GetRecentNodes(ctx context.Context, token string, within time.Duration) ([]Node, error) {
+	return c.ListNodesWithOptions(ctx, token, ListNodesOptions{LastSeenWithin: within})
+}
+
+// NodeDetail is a node with its routing state. PrimaryRoutes are the
+// approved routes the mesh currently sends through the node, and ExitNode is
+// set when the node serves as an exit node.
+type NodeDetail struct {
+	Node
+	AdvertisedRoutes []string `json:"advertised_routes"`
+	ApprovedRoutes   []string `json:"approved_routes"`
+	PrimaryRoutes    []string `json:"primary_routes"`
+	ExitNode         bool     `json:"exit_node"`
+}
+
+// GetNode returns a single node with its advertised, approved, and primary routes.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) GetNode(ctx context.Context, token string, id uint64) (*NodeDetail, error) {
+	var result NodeDetail
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/nodes/"+strconv.FormatUint(id, 10), token, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NodeTopology is a node with the connectivity its worker last reported:
+// its home DERP region, its latency to each DERP region in milliseconds
+// keyed by region ID, and how it reaches the other nodes. RelayBound is set
+// when the node reaches none of its peers directly. ReportedAt is nil for
+// nodes without a report.
+type NodeTopology struct {
+	ID            uint64             `json:"id"`
+	Name          string             `json:"name"`
+	Online        bool               `json:"online"`
+	DERPRegion    string             `json:"derp_region,omitempty"`
+	DERPLatencyMs map[string]float64 `json:"derp_latency_ms,omitempty"`
+	Peers         []TopologyPeer     `json:"peers"`
+	RelayBound    bool               `json:"relay_bound"`
+	ReportedAt    *time.Time         `json:"reported_at,omitempty"`
+}
+
+// TopologyPeer is the path from a node to a peer. Endpoint is the peer's
+// UDP address on direct paths; Relay is the DERP region of relayed ones.
+type TopologyPeer struct {
+	ID       uint64 `json:"id"`
+	Name     string `json:"name"`
+	Direct   bool   `json:"direct"`
+	Relay    string `json:"relay,omitempty"`
+	Endpoint string `json:"endpoint,omitempty"`
+}
+
+// GetNodeTopology returns the connectivity map of the wonder net, one entry
+// per node in ID order.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) GetNodeTopology(ctx context.Context, token string) ([]NodeTopology, error) {
+	var result struct {
+		Nodes []NodeTopology `json:"nodes"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/nodes/topology", token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result.Nodes, nil
+}
+
+// RenameNode sets the name of a node in the mesh. Names must be valid DNS
+// labels and unique within the wonder net; the coordinator keeps the name
+// instead of the one its naming policy would give the node. An empty name
+// returns the node to the naming policy.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) RenameNode(ctx context.Context, token string, id uint64, name string) (*NodeDetail, error) {
+	var result NodeDetail
+	in := map[string]string{"name": name}
+	if err := c.doJSON(ctx, http.MethodPatch, "/api/v1/nodes/"+strconv.FormatUint(id, 10), token, in, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// PendingNode is a node that waits for approval in a wonder net that
+// requires node approval. It cannot reach or be reached by other nodes until
+// it is approved.
+type PendingNode struct {
+	ID        uint64    `json:"id"`
+	Name      string    `json:"name"`
+	Hostname  string    `json:"hostname,omitempty"`
+	Addresses []string  `json:"ip_addresses"`
+	Online    bool      `json:"online"`
+	SeenAt    time.Time `json:"seen_at"`
+}
+
+// NodeApproval is the approval state of a node.
+type NodeApproval struct {
+	NodeID    uint64     `json:"node_id"`
+	Status    string     `json:"status"`
+	DecidedBy string     `json:"decided_by,omitempty"`
+	DecidedAt *time.Time `json:"decided_at,omitempty"`
+}
+
+// ListPendingNodes returns the nodes that wait for approval, oldest first.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) ListPendingNodes(ctx context.Context, token string) ([]PendingNode, error) {
+	var result []PendingNode
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/nodes/pending", token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApproveNode activates a pending node. It requires a session token of the
+// wonder net's owner.
+func (c *Client) ApproveNode(ctx context.Context, token string, id uint64) (*NodeApproval, error) {
+	var result NodeApproval
+	path := "/api/v1/nodes/" + strconv.FormatUint(id, 10) + "/approve"
+	if err := c.doJSON(ctx, http.MethodPost, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NodeEventType identifies a change reported by WatchNodes.
+type NodeEventType string
+
+// Node event types.
+const (
+	// NodeEventSnapshot is the first event of every watch and carries the full node list.
+	NodeEventSnapshot NodeEventType = "snapshot"
+	NodeEventJoin     NodeEventType = "join"
+	NodeEventLeave    NodeEventType = "leave"
+	NodeEventOnline   NodeEventType = "online"
+	NodeEventOffline  NodeEventType = "offline"
+	// NodeEventServerRestarting is the last event of a watch ended by the
+	// coordinator shutting down; watch again after RetryAfter.
+	NodeEventServerRestarting NodeEventType = "server_restarting"
+)
+
+// NodeEvent is a node topology change. Snapshot events set Nodes;
+// server_restarting events set RetryAfter; all other events set Node.
+type NodeEvent struct {
+	Type       NodeEventType
+	Node       Node
+	Nodes      []Node
+	RetryAfter time.Duration
+}
+
+// WatchNodes streams node topology changes for a user session or API key.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+//
+// The returned channel first receives a snapshot of all nodes, then one event
+// per change. It is closed when ctx is cancelled or the stream ends; callers
+// that need to keep watching should call WatchNodes again and reconcile
+// against the new snapshot. A stream ended by a coordinator restart closes
+// after a NodeEventServerRestarting event.
+func (c *Client) WatchNodes(ctx context.Context, token string) (<-chan NodeEvent, error) {
+	// The stream is long-lived, so the client-wide timeout must not apply.
+	streamClient := *c.httpClient
+	streamClient.Timeout = 0
+
+	resp, err := c.send(ctx, &streamClient, http.MethodGet, "/api/v1/nodes/watch", token, nil, "text/event-stream")
 	if err != nil {
 		return nil, err
 	}
 
-	online := make([]Node, 0)
-	for _, n := range nodes {
-		if n.Online {
-			online = append(online, n)
+	events := make(chan NodeEvent)
+	go func() {
+		defer close(events)
+		defer func() { _ = resp.Body.Close() }()
+
+		scanner := bufio.NewScanner(resp.Body)
+		scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+		var eventType, data string
+		for scanner.Scan() {
+			line := scanner.Text()
+			switch {
+			case line == "":
+				if eventType != "" && data != "" {
+					event, err := parseNodeEvent(NodeEventType(eventType), data)
+					if err == nil {
+						select {
+						case events <- event:
+						case <-ctx.Done():
+							return
+						}
+					}
+				}
+				eventType, data = "", ""
+			case strings.HasPrefix(line, ":"):
+			case strings.HasPrefix(line, "event:"):
+				eventType = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+			case strings.HasPrefix(line, "data:"):
+				data += strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+			}
+		}
+	}()
+
+	return events, nil
+}
+
+func parseNodeEvent(eventType NodeEventType, data string) (NodeEvent, error) {
+	event := NodeEvent{Type: eventType}
+	if eventType == NodeEventSnapshot {
+		var snapshot struct {
+			Nodes []Node `json:"nodes"`
+		}
+		if err := json.Unmarshal([]byte(data), &snapshot); err != nil {
+			return event, err
 		}
+		event.Nodes = snapshot.Nodes
+		return event, nil
+	}
+	if eventType == NodeEventServerRestarting {
+		var restarting struct {
+			RetryAfterSeconds int `json:"retry_after_seconds"`
+		}
+		if err := json.Unmarshal([]byte(data), &restarting); err != nil {
+			return event, err
+		}
+		event.RetryAfter = time.Duration(restarting.RetryAfterSeconds) * time.Second
+		return event, nil
+	}
+	if err := json.Unmarshal([]byte(data), &event.Node); err != nil {
+		return event, err
 	}
-	return online, nil
+	return event, nil
 }
 
-// Health checks if the coordinator is healthy
-func (c *Client) Health(ctx context.Context) error {
-	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/health", nil)
-	if err != nil {
-		return fmt.Errorf("create request: %w", err)
+// Service is a named port on a node that the wonder net publishes, together
+// with the subjects allowed to reach it.
+type Service struct {
+	ID       string         `json:"id"`
+	Name     string         `json:"name"`
+	NodeID   string         `json:"node_id"`
+	Port     int            `json:"port"`
+	Protocol string         `json:"protocol"`
+	Grants   []ServiceGrant `json:"grants"`
+}
+
+// ServiceGrant allows a subject ("*", "tag:<name>", or "node:<id>") to reach
+// a service.
+type ServiceGrant struct {
+	ID      string `json:"id"`
+	Subject string `json:"subject"`
+}
+
+// ListServices returns the services published in the wonder net.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) ListServices(ctx context.Context, token string) ([]Service, error) {
+	var services []Service
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/services", token, nil, &services); err != nil {
+		return nil, err
+	}
+	return services, nil
+}
+
+// AccessRequest is a request for temporary access to a service. Once the
+// wonder net owner approves it, the subject can reach the service until
+// ExpiresAt.
+type AccessRequest struct {
+	ID          string     `json:"id"`
+	ServiceID   string     `json:"service_id"`
+	ServiceName string     `json:"service_name"`
+	Subject     string     `json:"subject"`
+	Requester   string     `json:"requester"`
+	Reason      string     `json:"reason,omitempty"`
+	Duration    string     `json:"duration"`
+	Status      string     `json:"status"`
+	DecidedBy   string     `json:"decided_by,omitempty"`
+	DecidedAt   *time.Time `json:"decided_at,omitempty"`
+	ExpiresAt   *time.Time `json:"expires_at,omitempty"`
+	EndedAt     *time.Time `json:"ended_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// CreateAccessRequestInput describes the access to request. Subject is "*",
+// "tag:<name>", or "node:<id>".
+type CreateAccessRequestInput struct {
+	ServiceID string        `json:"service_id"`
+	Subject   string        `json:"subject"`
+	Requester string        `json:"requester"`
+	Reason    string        `json:"reason,omitempty"`
+	Duration  time.Duration `json:"-"`
+}
+
+// RequestAccess files a pending access request for the owner to decide.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) RequestAccess(ctx context.Context, token string, input CreateAccessRequestInput) (*AccessRequest, error) {
+	body := struct {
+		CreateAccessRequestInput
+		Duration string `json:"duration"`
+	}{input, input.Duration.String()}
+
+	var result AccessRequest
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/access-requests", token, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListAccessRequests returns the access requests of the wonder net, newest first.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) ListAccessRequests(ctx context.Context, token string) ([]AccessRequest, error) {
+	var result []AccessRequest
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/access-requests", token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// DecideAccessRequest applies an owner decision ("approve", "deny", or
+// "revoke") to an access request. Decisions require a user session token.
+func (c *Client) DecideAccessRequest(ctx context.Context, token, id, decision string) (*AccessRequest, error) {
+	var result AccessRequest
+	path := "/api/v1/access-requests/" + url.PathEscape(id) + "/" + url.PathEscape(decision)
+	if err := c.doJSON(ctx, http.MethodPost, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// Stats holds aggregate counts for a wonder net. RecentNodes counts nodes
+// that are online or were seen in the last 24 hours; StaleAPIKeys counts
+// unexpired API keys unused for 90 days.
+type Stats struct {
+	Nodes                 int `json:"nodes"`
+	OnlineNodes           int `json:"online_nodes"`
+	RecentNodes           int `json:"recent_nodes"`
+	APIKeys               int `json:"api_keys"`
+	StaleAPIKeys          int `json:"stale_api_keys"`
+	Services              int `json:"services"`
+	PendingAccessRequests int `json:"pending_access_requests"`
+	ActiveAccessRequests  int `json:"active_access_requests"`
+	FiringAlerts          int `json:"firing_alerts"`
+}
+
+// GetStats returns aggregate counts for the wonder net in one call.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) GetStats(ctx context.Context, token string) (*Stats, error) {
+	var result Stats
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/stats", token, nil, &result); err != nil {
+		return nil, err
 	}
+	return &result, nil
+}
+
+// Route is a subnet or exit route advertised by a node. A node serves a
+// route only once it is approved.
+type Route struct {
+	ID       string `json:"id"`
+	NodeID   uint64 `json:"node_id"`
+	NodeName string `json:"node_name"`
+	Prefix   string `json:"prefix"`
+	ExitNode bool   `json:"exit_node"`
+	Approved bool   `json:"approved"`
+	Serving  bool   `json:"serving"`
+}
+
+// ListRoutes returns the routes advertised by the nodes of the wonder net.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) ListRoutes(ctx context.Context, token string) ([]Route, error) {
+	var result []Route
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/routes", token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// ApproveRoute allows a node to serve a route. Approval requires a user session token.
+func (c *Client) ApproveRoute(ctx context.Context, token, id string) (*Route, error) {
+	return c.updateRoute(ctx, token, id, "approve")
+}
+
+// RejectRoute withdraws or declines the approval of a route. It requires a
+// user session token.
+func (c *Client) RejectRoute(ctx context.Context, token, id string) (*Route, error) {
+	return c.updateRoute(ctx, token, id, "reject")
+}
+
+func (c *Client) updateRoute(ctx context.Context, token, id, action string) (*Route, error) {
+	var result Route
+	path := "/api/v1/routes/" + url.PathEscape(id) + "/" + action
+	if err := c.doJSON(ctx, http.MethodPost, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// WonderNet is one of the caller's wonder nets. JoinTokenDefaultTTL and
+// JoinTokenMaxTTL are how long its join tokens are valid by default and at
+// most, as durations such as "72h0m0s".
+type WonderNet struct {
+	ID                  string    `json:"id"`
+	DisplayName         string    `json:"display_name"`
+	MeshType            string    `json:"mesh_type"`
+	IsDefault           bool      `json:"is_default"`
+	RequireNodeApproval bool      `json:"require_node_approval,omitempty"`
+	NodeExpiryDays      int       `json:"node_expiry_days,omitempty"`
+	JoinTokenDefaultTTL string    `json:"join_token_default_ttl,omitempty"`
+	JoinTokenMaxTTL     string    `json:"join_token_max_ttl,omitempty"`
+	CreatedAt           time.Time `json:"created_at"`
+}
 
-	resp, err := c.httpClient.Do(req)
+// ListWonderNets returns the wonder nets owned by the caller. It requires a
+// user session token.
+func (c *Client) ListWonderNets(ctx context.Context, token string) ([]WonderNet, error) {
+	var result []WonderNet
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/wonder-nets", token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// JoinToken is a join token for wonder worker join. JTI identifies the
+// token for its usage status.
+type JoinToken struct {
+	Token        string   `json:"token"`
+	ExpiresIn    int      `json:"expires_in"`
+	JTI          string   `json:"jti"`
+	MaxUses      int      `json:"max_uses"`
+	AllowedCIDRs []string `json:"allowed_cidrs,omitempty"`
+}
+
+// CreateJoinTokenOptions describes a join token to create.
+type CreateJoinTokenOptions struct {
+	// Network selects the wonder net by ID or display name; empty selects
+	// the caller's default wonder net.
+	Network string
+	// TTL is how long the token is valid. Zero uses the wonder net's
+	// default; the coordinator rejects TTLs over the wonder net's maximum.
+	TTL time.Duration
+	// MaxUses is how many workers may join with the token. Zero allows one.
+	MaxUses int
+	// AllowedCIDRs limits the addresses workers may join from.
+	AllowedCIDRs []string
+}
+
+// CreateJoinToken creates a join token with a user session token or an API
+// key with the tokens:create scope.
+func (c *Client) CreateJoinToken(ctx context.Context, token string, opts CreateJoinTokenOptions) (*JoinToken, error) {
+	query := url.Values{"allowed_cidr": opts.AllowedCIDRs}
+	if opts.Network != "" {
+		query.Set("network", opts.Network)
+	}
+	if opts.TTL > 0 {
+		query.Set("ttl", opts.TTL.String())
+	}
+	if opts.MaxUses > 0 {
+		query.Set("max_uses", strconv.Itoa(opts.MaxUses))
+	}
+
+	path := "/api/v1/join-token"
+	if encoded := query.Encode(); encoded != "" {
+		path += "?" + encoded
+	}
+	var result JoinToken
+	if err := c.doJSON(ctx, http.MethodGet, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// APIKey describes an API key. The key itself is only shown when it is
+// created. Expiring keys expire within 7 days and should be rotated; rotated
+// keys have DeprecatedAt and the ID of their successor.
+type APIKey struct {
+	ID           string     `json:"id"`
+	Name         string     `json:"name"`
+	KeyPrefix    string     `json:"key_prefix"`
+	CreatedAt    time.Time  `json:"created_at"`
+	LastUsedAt   *time.Time `json:"last_used_at,omitempty"`
+	ExpiresAt    *time.Time `json:"expires_at,omitempty"`
+	Scopes       []string   `json:"scopes"`
+	AllowedCIDRs []string   `json:"allowed_cidrs,omitempty"`
+	RequestCount int64      `json:"request_count"`
+	Stale        bool       `json:"stale"`
+	Expiring     bool       `json:"expiring"`
+	DeprecatedAt *time.Time `json:"deprecated_at,omitempty"`
+	SuccessorID  string     `json:"successor_id,omitempty"`
+}
+
+// ListAPIKeys returns the API keys of a wonder net, selected by ID or display
+// name; an empty network selects the caller's default wonder net. It requires
+// a user session token.
+func (c *Client) ListAPIKeys(ctx context.Context, token, network string) ([]APIKey, error) {
+	path := "/api/v1/api-keys"
+	if network != "" {
+		path += "?network=" + url.QueryEscape(network)
+	}
+	var result []APIKey
+	if err := c.doJSON(ctx, http.MethodGet, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// RotatedAPIKey is the successor issued by RotateAPIKey. Key is only shown
+// once; the rotated key keeps working until RotatedKeyExpiresAt.
+type RotatedAPIKey struct {
+	ID                  string     `json:"id"`
+	Name                string     `json:"name"`
+	Key                 string     `json:"key"`
+	KeyPrefix           string     `json:"key_prefix"`
+	ExpiresAt           *time.Time `json:"expires_at,omitempty"`
+	Scopes              []string   `json:"scopes"`
+	AllowedCIDRs        []string   `json:"allowed_cidrs,omitempty"`
+	RotatedKeyID        string     `json:"rotated_key_id"`
+	RotatedKeyExpiresAt time.Time  `json:"rotated_key_expires_at"`
+}
+
+// RotateAPIKey issues a successor to an API key of a wonder net, selected
+// as for ListAPIKeys. The rotated key keeps working for overlap; a zero
+// overlap uses the coordinator's default of 24 hours. It requires a user
+// session token.
+func (c *Client) RotateAPIKey(ctx context.Context, token, network, id string, overlap time.Duration) (*RotatedAPIKey, error) {
+	path := "/api/v1/api-keys/" + url.PathEscape(id) + "/rotate"
+	if network != "" {
+		path += "?network=" + url.QueryEscape(network)
+	}
+	var body struct {
+		Overlap string `json:"overlap,omitempty"`
+	}
+	if overlap > 0 {
+		body.Overlap = overlap.String()
+	}
+	var result RotatedAPIKey
+	if err := c.doJSON(ctx, http.MethodPost, path, token, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ExecSession is a command run on a node, as kept in the coordinator's exec
+// session audit trail. At least one of NodeID, NodeName, and Address
+// identifies the node; Error is set if the command could not be run.
+type ExecSession struct {
+	ID              string    `json:"id,omitempty"`
+	NodeID          string    `json:"node_id,omitempty"`
+	NodeName        string    `json:"node_name,omitempty"`
+	Address         string    `json:"address,omitempty"`
+	Command         string    `json:"command"`
+	Stdout          string    `json:"stdout"`
+	Stderr          string    `json:"stderr"`
+	OutputTruncated bool      `json:"output_truncated,omitempty"`
+	ExitCode        int       `json:"exit_code"`
+	Error           string    `json:"error,omitempty"`
+	RecordedBy      string    `json:"recorded_by,omitempty"`
+	StartedAt       time.Time `json:"started_at"`
+	DurationMs      int64     `json:"duration_ms"`
+	CreatedAt       time.Time `json:"created_at,omitzero"`
+}
+
+// RecordExecSession adds a command run on a node to the exec session audit
+// trail, with a user session token or an API key with the nodes:write scope.
+// Output beyond the coordinator's limit is cut.
+func (c *Client) RecordExecSession(ctx context.Context, token string, session ExecSession) (*ExecSession, error) {
+	var result ExecSession
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/exec-sessions", token, session, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ListExecSessions returns the latest exec sessions of the wonder net, most
+// recent first. A limit of zero returns the coordinator's default of 100.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) ListExecSessions(ctx context.Context, token string, limit int) ([]ExecSession, error) {
+	path := "/api/v1/exec-sessions"
+	if limit > 0 {
+		path += "?limit=" + strconv.Itoa(limit)
+	}
+	var result []ExecSession
+	if err := c.doJSON(ctx, http.MethodGet, path, token, nil, &result); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// doJSON sends a request with an optional JSON body and decodes the JSON
+// response into out.
+func (c *Client) doJSON(ctx context.Context, method, path, token string, in, out any) error {
+	var body []byte
+	if in != nil {
+		data, err := json.Marshal(in)
+		if err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+		body = data
+	}
+
+	resp, err := c.send(ctx, c.httpClient, method, path, token, body, "")
 	if err != nil {
-		return fmt.Errorf("send request: %w", err)
+		return err
 	}
 	defer func() { _ = resp.Body.Close() }()
 
-	if resp.StatusCode != http.StatusOK {
-		return fmt.Errorf("health check failed: status %d", resp.StatusCode)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
+
+// maxErrorBodySize limits how much of an error response is kept in APIError.
+const maxErrorBodySize = 4096
+
+// send sends a request with an optional JSON body, retrying idempotent
+// requests according to the client's retry policy, and returns the response
+// if its status is 2xx. Other statuses are returned as *APIError. If token
+// is empty, the client's API key is used.
+func (c *Client) send(ctx context.Context, httpClient *http.Client, method, path, token string, body []byte, accept string) (*http.Response, error) {
+	bearerToken := token
+	if bearerToken == "" {
+		bearerToken = c.apiKey
+	}
+
+	attempts := 1
+	if idempotent(method) && c.retry.MaxAttempts > 1 {
+		attempts = c.retry.MaxAttempts
+	}
+
+	for attempt := 1; ; attempt++ {
+		resp, err := c.sendOnce(ctx, httpClient, method, path, bearerToken, body, accept)
+		if err == nil {
+			return resp, nil
+		}
+		if attempt >= attempts || !retryable(err) || ctx.Err() != nil {
+			return nil, err
+		}
+
+		var retryAfter time.Duration
+		var apiErr *APIError
+		if errors.As(err, &apiErr) {
+			retryAfter = apiErr.RetryAfter
+		}
+		if err := sleep(ctx, c.retry.backoff(attempt, retryAfter)); err != nil {
+			return nil, err
+		}
+	}
+}
+
+func (c *Client) sendOnce(ctx context.Context, httpClient *http.Client, method, path, bearerToken string, body []byte, accept string) (*http.Response, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if accept != "" {
+		req.Header.Set("Accept", accept)
+	}
+	if bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+bearerToken)
+	}
+	if c.network != "" && !req.URL.Query().Has("network") {
+		req.Header.Set("X-Wonder-Net", c.network)
+	}
+
+	resp, err := httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("send request: %w", err)
 	}
 
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		defer func() { _ = resp.Body.Close() }()
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBodySize))
+		return nil, &APIError{
+			StatusCode: resp.StatusCode,
+			Body:       string(respBody),
+			RetryAfter: parseRetryAfter(resp.Header.Get("Retry-After"), time.Now()),
+		}
+	}
+	return resp, nil
+}
+
+// Health checks if the coordinator is healthy
+func (c *Client) Health(ctx context.Context) error {
+	resp, err := c.send(ctx, c.httpClient, http.MethodGet, "/health", "", nil, "")
+	if err != nil {
+		return fmt.Errorf("health check: %w", err)
+	}
+	_ = resp.Body.Close()
 	return nil
 }
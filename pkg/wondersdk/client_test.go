@@ -0,0 +1,206 @@
+package wondersdk
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func testRetryPolicy() RetryPolicy {
+	return RetryPolicy{MaxAttempts: 3, InitialBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+}
+
+func TestClientTypedErrors(t *testing.T) {
+	tests := []struct {
+		status int
+		want   error
+	}{
+		{status: http.StatusUnauthorized, want: ErrUnauthorized},
+		{status: http.StatusForbidden, want: ErrUnauthorized},
+		{status: http.StatusNotFound, want: ErrNotFound},
+		{status: http.StatusTooManyRequests, want: ErrRateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(http.StatusText(tt.status), func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				http.Error(w, "nope", tt.status)
+			}))
+			defer server.Close()
+
+			client := NewClientWithOptions(server.URL, "key", ClientOptions{})
+			_, err := client.GetStats(context.Background(), "")
+			if !errors.Is(err, tt.want) {
+				t.Fatalf("GetStats() error = %v, want %v", err, tt.want)
+			}
+			var apiErr *APIError
+			if !errors.As(err, &apiErr) || apiErr.StatusCode != tt.status {
+				t.Errorf("GetStats() error = %#v, want APIError with status %d", err, tt.status)
+			}
+		})
+	}
+}
+
+func TestClientRetriesIdempotentRequests(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			w.Header().Set("Retry-After", "0")
+			http.Error(w, "restarting", http.StatusServiceUnavailable)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(Stats{Nodes: 4})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(server.URL, "key", ClientOptions{Retry: testRetryPolicy()})
+	stats, err := client.GetStats(context.Background(), "")
+	if err != nil {
+		t.Fatalf("GetStats() error = %v", err)
+	}
+	if stats.Nodes != 4 || calls.Load() != 3 {
+		t.Errorf("GetStats() = %d nodes after %d calls, want 4 nodes after 3 calls", stats.Nodes, calls.Load())
+	}
+}
+
+func TestClientDoesNotRetryPost(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "restarting", http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(server.URL, "key", ClientOptions{Retry: testRetryPolicy()})
+	if _, err := client.ApproveRoute(context.Background(), "", "route"); err == nil {
+		t.Fatal("ApproveRoute() succeeded, want error")
+	}
+	if calls.Load() != 1 {
+		t.Errorf("ApproveRoute() sent %d requests, want 1", calls.Load())
+	}
+}
+
+func TestClientDoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		http.Error(w, "not found", http.StatusNotFound)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(server.URL, "key", ClientOptions{Retry: testRetryPolicy()})
+	if _, err := client.GetNode(context.Background(), "", 1); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("GetNode() error = %v, want %v", err, ErrNotFound)
+	}
+	if calls.Load() != 1 {
+		t.Errorf("GetNode() sent %d requests, want 1", calls.Load())
+	}
+}
+
+func TestClientNetwork(t *testing.T) {
+	var gotHeader, gotQuery string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader, gotQuery = r.Header.Get("X-Wonder-Net"), r.URL.Query().Get("network")
+		_ = json.NewEncoder(w).Encode(JoinToken{Token: "join"})
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(server.URL, "key", ClientOptions{Network: "staging"})
+	if _, err := client.CreateJoinToken(context.Background(), "", CreateJoinTokenOptions{}); err != nil {
+		t.Fatalf("CreateJoinToken() error = %v", err)
+	}
+	if gotHeader != "staging" {
+		t.Errorf("X-Wonder-Net = %q, want staging", gotHeader)
+	}
+
+	if _, err := client.CreateJoinToken(context.Background(), "", CreateJoinTokenOptions{Network: "prod"}); err != nil {
+		t.Fatalf("CreateJoinToken() error = %v", err)
+	}
+	if gotHeader != "" || gotQuery != "prod" {
+		t.Errorf("X-Wonder-Net = %q, network = %q, want only the network parameter prod", gotHeader, gotQuery)
+	}
+}
+
+func TestListNodesWithOptionsFetchesEveryPage(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("limit") != "2" {
+			t.Errorf("limit = %q, want 2", r.URL.Query().Get("limit"))
+		}
+		after, _ := strconv.ParseUint(r.URL.Query().Get("cursor"), 10, 64)
+
+		var response struct {
+			Nodes      []Node `json:"nodes"`
+			NextCursor string `json:"next_cursor,omitempty"`
+		}
+		for id := after + 1; id <= 5 && len(response.Nodes) < 2; id++ {
+			response.Nodes = append(response.Nodes, Node{ID: id})
+		}
+		if last := response.Nodes[len(response.Nodes)-1].ID; last < 5 {
+			response.NextCursor = strconv.FormatUint(last, 10)
+		}
+		_ = json.NewEncoder(w).Encode(response)
+	}))
+	defer server.Close()
+
+	client := NewClientWithOptions(server.URL, "key", ClientOptions{})
+	nodes, err := client.ListNodesWithOptions(context.Background(), "", ListNodesOptions{Limit: 2})
+	if err != nil {
+		t.Fatalf("ListNodesWithOptions() error = %v", err)
+	}
+	if len(nodes) != 5 {
+		t.Fatalf("ListNodesWithOptions() returned %d nodes, want 5", len(nodes))
+	}
+	for i, node := range nodes {
+		if node.ID != uint64(i+1) {
+			t.Errorf("nodes[%d].ID = %d, want %d", i, node.ID, i+1)
+		}
+	}
+}
+
+func TestWatchNodesEndsOnServerRestarting(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/event-stream")
+		_, _ = w.Write([]byte("event: snapshot\ndata: {\"nodes\":[{\"id\":1}]}\n\n" +
+			"event: server_restarting\ndata: {\"retry_after_seconds\":5}\n\n"))
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL, "key")
+	events, err := client.WatchNodes(context.Background(), "")
+	if err != nil {
+		t.Fatalf("WatchNodes() error = %v", err)
+	}
+	var got []NodeEvent
+	for event := range events {
+		got = append(got, event)
+	}
+	if len(got) != 2 || got[0].Type != NodeEventSnapshot || len(got[0].Nodes) != 1 {
+		t.Fatalf("WatchNodes() events = %+v, want a snapshot of one node first", got)
+	}
+	if got[1].Type != NodeEventServerRestarting || got[1].RetryAfter != 5*time.Second {
+		t.Errorf("last event = %+v, want server_restarting with RetryAfter 5s", got[1])
+	}
+}
+
+func TestRetryPolicyBackoff(t *testing.T) {
+	p := RetryPolicy{InitialBackoff: 100 * time.Millisecond, MaxBackoff: time.Second}
+	for attempt, want := range map[int]time.Duration{1: 100 * time.Millisecond, 2: 200 * time.Millisecond, 5: time.Second} {
+		for range 20 {
+			if d := p.backoff(attempt, 0); d < want/2 || d > want {
+				t.Fatalf("backoff(%d) = %v, want between %v and %v", attempt, d, want/2, want)
+			}
+		}
+	}
+	if d := p.backoff(1, 700*time.Millisecond); d != 700*time.Millisecond {
+		t.Errorf("backoff() with Retry-After = %v, want 700ms", d)
+	}
+	if d := p.backoff(1, time.Minute); d != time.Second {
+		t.Errorf("backoff() with long Retry-After = %v, want MaxBackoff", d)
+	}
+}
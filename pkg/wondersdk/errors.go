@@ -0,0 +1,65 @@
+package wondersdk
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+// Errors matched by APIError through errors.Is.
+var (
+	// ErrUnauthorized is returned when the token is missing, invalid,
+	// expired, or not allowed to make the request (401 or 403).
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrNotFound is returned when the requested resource does not exist (404).
+	ErrNotFound = errors.New("not found")
+	// ErrRateLimited is returned when the coordinator throttles the caller (429).
+	ErrRateLimited = errors.New("rate limited")
+)
+
+// APIError is returned when the coordinator answers with a non-2xx status.
+// Use errors.Is with ErrUnauthorized, ErrNotFound, or ErrRateLimited to
+// check for common failures.
+type APIError struct {
+	StatusCode int
+	// Body is the start of the response body, usually a plain text message.
+	Body string
+	// RetryAfter is the delay the coordinator asked for in a Retry-After
+	// header, or zero.
+	RetryAfter time.Duration
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("request failed: status %d, body: %s", e.StatusCode, e.Body)
+}
+
+// Is reports whether the error matches one of the package's sentinel errors.
+func (e *APIError) Is(target error) bool {
+	switch target {
+	case ErrUnauthorized:
+		return e.StatusCode == http.StatusUnauthorized || e.StatusCode == http.StatusForbidden
+	case ErrNotFound:
+		return e.StatusCode == http.StatusNotFound
+	case ErrRateLimited:
+		return e.StatusCode == http.StatusTooManyRequests
+	default:
+		return false
+	}
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds or as an HTTP
+// date.
+func parseRetryAfter(header string, now time.Time) time.Duration {
+	if header == "" {
+		return 0
+	}
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+	if t, err := http.ParseTime(header); err == nil && t.After(now) {
+		return t.Sub(now)
+	}
+	return 0
+}
@@ -0,0 +1,331 @@
+package wondersdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/net/proxy"
+)
+
+// MeshExecutorConfig configures a MeshExecutor.
+type MeshExecutorConfig struct {
+	// Token authenticates the node listings. Empty uses the client's API key.
+	Token string
+
+	// User is the SSH user. Auth lists the SSH authentication methods, e.g.
+	// ssh.PublicKeys or ssh.Password.
+	User string
+	Auth []ssh.AuthMethod
+	// HostKeyCallback verifies the nodes' host keys, e.g. with
+	// golang.org/x/crypto/ssh/knownhosts. It is required.
+	HostKeyCallback ssh.HostKeyCallback
+	// Port is the SSH port of the nodes. Zero uses 22.
+	Port int
+	// IPFamily is the family of the mesh address connected to. The zero
+	// value prefers IPv4.
+	IPFamily IPFamily
+
+	// SOCKS5Addr is a SOCKS5 proxy that reaches the mesh, such as the one of
+	// a userspace tailscaled or the coordinator's mesh proxy. Empty dials
+	// the nodes directly, for machines that are part of the mesh.
+	SOCKS5Addr string
+	// SOCKS5APIKey, if set, is sent as the SOCKS5 password, as the
+	// coordinator's mesh proxy expects.
+	SOCKS5APIKey string
+
+	// Timeout bounds connecting to a node, including the SSH handshake.
+	// Zero uses 30 seconds. Commands run until they exit or ctx is done.
+	Timeout time.Duration
+	// Retry controls how often connecting to a node is retried, as nodes
+	// that just joined may not be reachable until the mesh converges. A
+	// command that started is never run again. The zero value connects once.
+	Retry RetryPolicy
+	// Concurrency is how many nodes RunOnAll runs the command on at once.
+	// Zero uses 8.
+	Concurrency int
+
+	// RecordSessions reports every command run, with its output, to the
+	// coordinator's exec session audit trail. Token, or the client's API
+	// key, must allow nodes:write.
+	RecordSessions bool
+}
+
+// ExecResult is the result of a command on one node. Err is set if the
+// command could not be run; a command that ran and failed has a non-zero
+// ExitCode instead.
+type ExecResult struct {
+	// Node is the node the command ran on. Only Name and Addresses are set
+	// for RunOnAddress.
+	Node     Node
+	Address  string
+	Stdout   string
+	Stderr   string
+	ExitCode int
+	// Attempts is how many times connecting to the node was tried.
+	Attempts int
+	Duration time.Duration
+	Err      error
+	// RecordErr is set if RecordSessions is on and the session could not be
+	// recorded.
+	RecordErr error
+}
+
+// MeshExecutor runs commands on the nodes of a wonder net over SSH. Nodes
+// are looked up through the coordinator's nodes API and reached at their
+// mesh address, directly or through a SOCKS5 proxy.
+type MeshExecutor struct {
+	client *Client
+	config MeshExecutorConfig
+	dialer proxy.ContextDialer
+}
+
+// NewMeshExecutor creates a MeshExecutor that looks up nodes with client.
+func NewMeshExecutor(client *Client, config MeshExecutorConfig) (*MeshExecutor, error) {
+	if config.HostKeyCallback == nil {
+		return nil, errors.New("host key callback is required")
+	}
+	if config.Port == 0 {
+		config.Port = 22
+	}
+	if config.Timeout == 0 {
+		config.Timeout = 30 * time.Second
+	}
+	if config.Concurrency <= 0 {
+		config.Concurrency = 8
+	}
+
+	var dialer proxy.ContextDialer = &net.Dialer{}
+	if config.SOCKS5Addr != "" {
+		var auth *proxy.Auth
+		if config.SOCKS5APIKey != "" {
+			auth = &proxy.Auth{User: "wonder", Password: config.SOCKS5APIKey}
+		}
+		socks, err := proxy.SOCKS5("tcp", config.SOCKS5Addr, auth, &net.Dialer{})
+		if err != nil {
+			return nil, fmt.Errorf("create SOCKS5 dialer: %w", err)
+		}
+		contextDialer, ok := socks.(proxy.ContextDialer)
+		if !ok {
+			return nil, errors.New("SOCKS5 dialer does not support contexts")
+		}
+		dialer = contextDialer
+	}
+
+	return &MeshExecutor{client: client, config: config, dialer: dialer}, nil
+}
+
+// RunOnNode runs command on the node with the given name, or numeric ID if
+// no node has that name. It returns an error if the node cannot be found;
+// other failures are reported in the result's Err.
+func (e *MeshExecutor) RunOnNode(ctx context.Context, name, command string) (*ExecResult, error) {
+	nodes, err := e.client.ListNodes(ctx, e.config.Token)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+	node, err := findNode(nodes, name)
+	if err != nil {
+		return nil, err
+	}
+	result := e.run(ctx, *node, e.config.IPFamily, command)
+	return &result, nil
+}
+
+// RunOnAll runs command on every node that matches opts, Concurrency nodes
+// at a time, and returns the results in node order. Offline nodes are
+// skipped unless opts.Online is set. The error reports only a failed node
+// listing; check each result's Err and ExitCode.
+func (e *MeshExecutor) RunOnAll(ctx context.Context, opts ListNodesOptions, command string) ([]ExecResult, error) {
+	if opts.Online == nil {
+		online := true
+		opts.Online = &online
+	}
+	nodes, err := e.client.ListNodesWithOptions(ctx, e.config.Token, opts)
+	if err != nil {
+		return nil, fmt.Errorf("list nodes: %w", err)
+	}
+
+	results := make([]ExecResult, len(nodes))
+	sem := make(chan struct{}, e.config.Concurrency)
+	var wg sync.WaitGroup
+	for i := range nodes {
+		wg.Go(func() {
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			results[i] = e.run(ctx, nodes[i], e.config.IPFamily, command)
+		})
+	}
+	wg.Wait()
+	return results, nil
+}
+
+// RunOnAddress runs command on the node at a mesh address without looking
+// it up, e.g. for addresses kept from an earlier listing. The address is
+// used whatever its IP family.
+func (e *MeshExecutor) RunOnAddress(ctx context.Context, address, command string) ExecResult {
+	return e.run(ctx, Node{Name: address, Addresses: []string{address}}, IPFamilyAuto, command)
+}
+
+// run runs command on node, at its address of the IP family, and records
+// the session if RecordSessions is on.
+func (e *MeshExecutor) run(ctx context.Context, node Node, family IPFamily, command string) ExecResult {
+	start := time.Now()
+	result := e.exec(ctx, node, family, command)
+	result.Duration = time.Since(start)
+	if e.config.RecordSessions {
+		result.RecordErr = e.record(ctx, command, start, result)
+	}
+	return result
+}
+
+// record reports an exec session to the coordinator. It is sent even if ctx
+// is done, so commands that were cut short are recorded too.
+func (e *MeshExecutor) record(ctx context.Context, command string, start time.Time, result ExecResult) error {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), e.config.Timeout)
+	defer cancel()
+
+	session := ExecSession{
+		NodeName:   result.Node.Name,
+		Address:    result.Address,
+		Command:    command,
+		Stdout:     result.Stdout,
+		Stderr:     result.Stderr,
+		ExitCode:   result.ExitCode,
+		StartedAt:  start,
+		DurationMs: result.Duration.Milliseconds(),
+	}
+	if result.Node.ID != 0 {
+		session.NodeID = strconv.FormatUint(result.Node.ID, 10)
+	}
+	if result.Err != nil {
+		session.Error = result.Err.Error()
+	}
+	if _, err := e.client.RecordExecSession(ctx, e.config.Token, session); err != nil {
+		return fmt.Errorf("record exec session: %w", err)
+	}
+	return nil
+}
+
+// exec connects to node, retrying according to the retry policy, and runs
+// command in a new session.
+func (e *MeshExecutor) exec(ctx context.Context, node Node, family IPFamily, command string) ExecResult {
+	result := ExecResult{Node: node}
+
+	host, err := node.Address(family)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+	result.Address = net.JoinHostPort(host, strconv.Itoa(e.config.Port))
+
+	attempts := max(e.config.Retry.MaxAttempts, 1)
+	var client *ssh.Client
+	for result.Attempts = 1; ; result.Attempts++ {
+		client, err = e.connect(ctx, result.Address)
+		if err == nil {
+			break
+		}
+		if result.Attempts >= attempts || ctx.Err() != nil {
+			result.Err = fmt.Errorf("connect to %s (%s): %w", node.Name, result.Address, err)
+			return result
+		}
+		if err := sleep(ctx, e.config.Retry.backoff(result.Attempts, 0)); err != nil {
+			result.Err = err
+			return result
+		}
+	}
+	defer func() { _ = client.Close() }()
+
+	var stdout, stderr strings.Builder
+	result.ExitCode, result.Err = runSession(ctx, client, command, &stdout, &stderr)
+	result.Stdout, result.Stderr = stdout.String(), stderr.String()
+	return result
+}
+
+// connect dials addr and completes the SSH handshake within the timeout.
+func (e *MeshExecutor) connect(ctx context.Context, addr string) (*ssh.Client, error) {
+	ctx, cancel := context.WithTimeout(ctx, e.config.Timeout)
+	defer cancel()
+
+	conn, err := e.dialer.DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return nil, err
+	}
+	// The handshake does not take a context, so bound it by the deadline.
+	if deadline, ok := ctx.Deadline(); ok {
+		_ = conn.SetDeadline(deadline)
+	}
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, &ssh.ClientConfig{
+		User:            e.config.User,
+		Auth:            e.config.Auth,
+		HostKeyCallback: e.config.HostKeyCallback,
+	})
+	if err != nil {
+		_ = conn.Close()
+		return nil, fmt.Errorf("ssh handshake: %w", err)
+	}
+	_ = conn.SetDeadline(time.Time{})
+	return ssh.NewClient(sshConn, chans, reqs), nil
+}
+
+// runSession runs command in a new session on client and returns its exit
+// code. If ctx is done first, the remote command is killed and the context
+// error is returned.
+func runSession(ctx context.Context, client *ssh.Client, command string, stdout, stderr *strings.Builder) (int, error) {
+	session, err := client.NewSession()
+	if err != nil {
+		return 0, fmt.Errorf("create session: %w", err)
+	}
+	defer func() { _ = session.Close() }()
+
+	session.Stdout = stdout
+	session.Stderr = stderr
+	if err := session.Start(command); err != nil {
+		return 0, fmt.Errorf("start command: %w", err)
+	}
+
+	done := make(chan error, 1)
+	go func() { done <- session.Wait() }()
+
+	select {
+	case <-ctx.Done():
+		// Not every server honours signals, so also close the connection
+		// to make sure Wait returns.
+		_ = session.Signal(ssh.SIGKILL)
+		_ = client.Close()
+		<-done
+		return 0, ctx.Err()
+	case err := <-done:
+		var exitErr *ssh.ExitError
+		if errors.As(err, &exitErr) {
+			return exitErr.ExitStatus(), nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("run command: %w", err)
+		}
+		return 0, nil
+	}
+}
+
+// findNode finds a node by name, falling back to its numeric ID.
+func findNode(nodes []Node, name string) (*Node, error) {
+	for i := range nodes {
+		if nodes[i].Name == name {
+			return &nodes[i], nil
+		}
+	}
+	if id, err := strconv.ParseUint(name, 10, 64); err == nil {
+		for i := range nodes {
+			if nodes[i].ID == id {
+				return &nodes[i], nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("node %q: %w", name, ErrNotFound)
+}
@@ -0,0 +1,232 @@
+package wondersdk
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// startTestSSHServer serves SSH on a loopback port. Each command is echoed
+// to stdout, and "fail" exits with status 3.
+func startTestSSHServer(t *testing.T) (port int, hostKey ssh.PublicKey) {
+	t.Helper()
+
+	_, priv, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := ssh.NewSignerFromKey(priv)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &ssh.ServerConfig{
+		PasswordCallback: func(conn ssh.ConnMetadata, password []byte) (*ssh.Permissions, error) {
+			if conn.User() == "deploy" && string(password) == "secret" {
+				return nil, nil
+			}
+			return nil, errors.New("denied")
+		},
+	}
+	config.AddHostKey(signer)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = listener.Close() })
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			go serveTestSSHConn(conn, config)
+		}
+	}()
+	return listener.Addr().(*net.TCPAddr).Port, signer.PublicKey()
+}
+
+func serveTestSSHConn(conn net.Conn, config *ssh.ServerConfig) {
+	_, chans, reqs, err := ssh.NewServerConn(conn, config)
+	if err != nil {
+		return
+	}
+	go ssh.DiscardRequests(reqs)
+	for newChannel := range chans {
+		channel, requests, err := newChannel.Accept()
+		if err != nil {
+			continue
+		}
+		go func() {
+			defer func() { _ = channel.Close() }()
+			for req := range requests {
+				if req.Type != "exec" || len(req.Payload) < 4 {
+					_ = req.Reply(false, nil)
+					continue
+				}
+				command := string(req.Payload[4:])
+				_ = req.Reply(true, nil)
+				_, _ = channel.Write([]byte(command))
+				status := uint32(0)
+				if command == "fail" {
+					status = 3
+				}
+				_, _ = channel.SendRequest("exit-status", false, binary.BigEndian.AppendUint32(nil, status))
+				return
+			}
+		}()
+	}
+}
+
+func TestMeshExecutor(t *testing.T) {
+	port, hostKey := startTestSSHServer(t)
+	nodes := []Node{
+		{ID: 1, Name: "web-1", Addresses: []string{"fd7a:115c:a1e0::1", "127.0.0.1"}, Online: true},
+		{ID: 2, Name: "web-2", Addresses: []string{"127.0.0.1"}, Online: true},
+		{ID: 3, Name: "down", Addresses: []string{"127.0.0.2"}, Online: false},
+	}
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		list := nodes
+		if r.URL.Query().Get("online") == "true" {
+			list = nodes[:2]
+		}
+		_ = json.NewEncoder(w).Encode(map[string]any{"nodes": list, "count": len(list)})
+	}))
+	defer api.Close()
+
+	executor, err := NewMeshExecutor(NewClientWithOptions(api.URL, "key", ClientOptions{}), MeshExecutorConfig{
+		User:            "deploy",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Port:            port,
+		Timeout:         5 * time.Second,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	ctx := context.Background()
+
+	t.Run("RunOnNode", func(t *testing.T) {
+		result, err := executor.RunOnNode(ctx, "web-1", "uptime")
+		if err != nil {
+			t.Fatal(err)
+		}
+		wantAddr := net.JoinHostPort("127.0.0.1", strconv.Itoa(port))
+		if result.Err != nil || result.Stdout != "uptime" || result.ExitCode != 0 || result.Address != wantAddr || result.Attempts != 1 {
+			t.Errorf("RunOnNode() = %+v, want stdout uptime on %s", result, wantAddr)
+		}
+	})
+
+	t.Run("RunOnNode by ID", func(t *testing.T) {
+		result, err := executor.RunOnNode(ctx, "2", "fail")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.Node.Name != "web-2" || result.ExitCode != 3 || result.Err != nil {
+			t.Errorf("RunOnNode() = %+v, want exit code 3 on web-2", result)
+		}
+	})
+
+	t.Run("RunOnNode unknown node", func(t *testing.T) {
+		if _, err := executor.RunOnNode(ctx, "db-1", "uptime"); !errors.Is(err, ErrNotFound) {
+			t.Errorf("RunOnNode() error = %v, want ErrNotFound", err)
+		}
+	})
+
+	t.Run("RunOnAll", func(t *testing.T) {
+		results, err := executor.RunOnAll(ctx, ListNodesOptions{}, "hostname")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(results) != 2 {
+			t.Fatalf("RunOnAll() returned %d results, want the 2 online nodes", len(results))
+		}
+		for i, result := range results {
+			if result.Node.ID != nodes[i].ID || result.Err != nil || result.Stdout != "hostname" {
+				t.Errorf("RunOnAll()[%d] = %+v, want stdout hostname from node %d", i, result, nodes[i].ID)
+			}
+		}
+	})
+}
+
+func TestMeshExecutorRecordsSessions(t *testing.T) {
+	port, hostKey := startTestSSHServer(t)
+	var recorded []ExecSession
+	api := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/api/v1/exec-sessions" {
+			http.NotFound(w, r)
+			return
+		}
+		var session ExecSession
+		_ = json.NewDecoder(r.Body).Decode(&session)
+		recorded = append(recorded, session)
+		w.WriteHeader(http.StatusCreated)
+		_ = json.NewEncoder(w).Encode(session)
+	}))
+	defer api.Close()
+
+	executor, err := NewMeshExecutor(NewClientWithOptions(api.URL, "key", ClientOptions{}), MeshExecutorConfig{
+		User:            "deploy",
+		Auth:            []ssh.AuthMethod{ssh.Password("secret")},
+		HostKeyCallback: ssh.FixedHostKey(hostKey),
+		Port:            port,
+		Timeout:         5 * time.Second,
+		RecordSessions:  true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := executor.RunOnAddress(context.Background(), "127.0.0.1", "fail")
+	if result.Err != nil || result.RecordErr != nil {
+		t.Fatalf("RunOnAddress() = %+v, want the command run and recorded", result)
+	}
+	if len(recorded) != 1 {
+		t.Fatalf("recorded %d sessions, want 1", len(recorded))
+	}
+	got := recorded[0]
+	if got.Command != "fail" || got.Stdout != "fail" || got.ExitCode != 3 || got.Address != result.Address || got.StartedAt.IsZero() {
+		t.Errorf("recorded session = %+v, want the command, its output, and its exit code", got)
+	}
+}
+
+func TestMeshExecutorRetriesConnecting(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	_ = listener.Close()
+
+	executor, err := NewMeshExecutor(NewClient("http://unused", ""), MeshExecutorConfig{
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Port:            port,
+		Retry:           testRetryPolicy(),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result := executor.RunOnAddress(context.Background(), "127.0.0.1", "true")
+	if result.Err == nil || result.Attempts != 3 {
+		t.Errorf("RunOnAddress() = %+v, want a connection error after 3 attempts", result)
+	}
+}
+
+func TestNewMeshExecutorRequiresHostKeyCallback(t *testing.T) {
+	if _, err := NewMeshExecutor(NewClient("http://unused", ""), MeshExecutorConfig{}); err == nil {
+		t.Error("NewMeshExecutor() error = nil, want an error without a host key callback")
+	}
+}
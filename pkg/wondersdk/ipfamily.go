@@ -0,0 +1,85 @@
+package wondersdk
+
+import (
+	"fmt"
+	"net/netip"
+)
+
+// IPFamily selects which of a node's mesh addresses to connect to.
+type IPFamily string
+
+// IP families.
+const (
+	// IPFamilyAuto prefers IPv4 and falls back to the first address.
+	IPFamilyAuto IPFamily = ""
+	IPFamilyIPv4 IPFamily = "ipv4"
+	IPFamilyIPv6 IPFamily = "ipv6"
+)
+
+// ParseIPFamily parses an IP family flag value: auto (or empty), ipv4, or
+// ipv6.
+func ParseIPFamily(s string) (IPFamily, error) {
+	switch s {
+	case "", "auto":
+		return IPFamilyAuto, nil
+	case "ipv4", "4":
+		return IPFamilyIPv4, nil
+	case "ipv6", "6":
+		return IPFamilyIPv6, nil
+	}
+	return "", fmt.Errorf("unknown IP family %q, want auto, ipv4, or ipv6", s)
+}
+
+// NodeIP holds a node's mesh address of each IP family. Either is empty if
+// the node has no address of that family.
+type NodeIP struct {
+	IPv4 string `json:"ipv4,omitempty"`
+	IPv6 string `json:"ipv6,omitempty"`
+}
+
+// Address returns the node's mesh address of the family, or an error if
+// it has none. IPFamilyAuto prefers IPv4.
+func (n Node) Address(family IPFamily) (string, error) {
+	addr := SelectAddress(n.Addresses, family)
+	if addr == "" {
+		if family == IPFamilyAuto {
+			return "", fmt.Errorf("node %s has no mesh address", n.Name)
+		}
+		return "", fmt.Errorf("node %s has no %s mesh address", n.Name, family)
+	}
+	return addr, nil
+}
+
+// SelectAddress returns the first of addresses of the family, or an empty
+// string if there is none. IPFamilyAuto prefers IPv4 and falls back to the
+// first address. Use net.JoinHostPort to add a port, which brackets IPv6
+// addresses.
+func SelectAddress(addresses []string, family IPFamily) string {
+	want := family
+	if want == IPFamilyAuto {
+		want = IPFamilyIPv4
+	}
+	for _, addr := range addresses {
+		if addressFamily(addr) == want {
+			return addr
+		}
+	}
+	if family == IPFamilyAuto && len(addresses) > 0 {
+		return addresses[0]
+	}
+	return ""
+}
+
+// addressFamily returns the IP family of an address, or IPFamilyAuto if it
+// is not an IP address. IPv4-mapped IPv6 addresses count as IPv4.
+func addressFamily(addr string) IPFamily {
+	ip, err := netip.ParseAddr(addr)
+	switch {
+	case err != nil:
+		return IPFamilyAuto
+	case ip.Unmap().Is4():
+		return IPFamilyIPv4
+	default:
+		return IPFamilyIPv6
+	}
+}
@@ -0,0 +1,40 @@
+package wondersdk
+
+import "testing"
+
+func TestNodeAddress(t *testing.T) {
+	dualStack := Node{Name: "web-1", Addresses: []string{"fd7a:115c:a1e0::1", "100.64.0.1"}}
+	ipv6Only := Node{Name: "web-2", Addresses: []string{"fd7a:115c:a1e0::2"}}
+
+	tests := []struct {
+		node    Node
+		family  IPFamily
+		want    string
+		wantErr bool
+	}{
+		{node: dualStack, family: IPFamilyAuto, want: "100.64.0.1"},
+		{node: dualStack, family: IPFamilyIPv4, want: "100.64.0.1"},
+		{node: dualStack, family: IPFamilyIPv6, want: "fd7a:115c:a1e0::1"},
+		{node: ipv6Only, family: IPFamilyAuto, want: "fd7a:115c:a1e0::2"},
+		{node: ipv6Only, family: IPFamilyIPv4, wantErr: true},
+		{node: Node{Name: "db-1"}, family: IPFamilyAuto, wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := tt.node.Address(tt.family)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("%s.Address(%q) = %q, %v, want %q, error %v", tt.node.Name, tt.family, got, err, tt.want, tt.wantErr)
+		}
+	}
+
+}
+
+func TestParseIPFamily(t *testing.T) {
+	for in, want := range map[string]IPFamily{"": IPFamilyAuto, "auto": IPFamilyAuto, "ipv4": IPFamilyIPv4, "6": IPFamilyIPv6} {
+		if got, err := ParseIPFamily(in); err != nil || got != want {
+			t.Errorf("ParseIPFamily(%q) = %q, %v, want %q", in, got, err, want)
+		}
+	}
+	if _, err := ParseIPFamily("ipx"); err == nil {
+		t.Error("ParseIPFamily(\"ipx\") error = nil, want an error")
+	}
+}
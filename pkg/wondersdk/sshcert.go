@@ -0,0 +1,93 @@
+package wondersdk
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+// SSHCertificate is an SSH user certificate signed by the wonder net's SSH
+// CA. Certificate is in the format of id_ed25519-cert.pub files.
+type SSHCertificate struct {
+	Certificate string    `json:"certificate"`
+	Serial      uint64    `json:"serial"`
+	KeyID       string    `json:"key_id"`
+	Principals  []string  `json:"principals"`
+	ValidAfter  time.Time `json:"valid_after"`
+	ValidBefore time.Time `json:"valid_before"`
+}
+
+// GetSSHCA returns the wonder net's SSH CA key as an authorized_keys line,
+// the key workers installed with wonder worker install --ssh-ca trust.
+// If token is provided, it is used as Bearer token; otherwise falls back to client's apiKey.
+func (c *Client) GetSSHCA(ctx context.Context, token string) (string, error) {
+	var result struct {
+		PublicKey string `json:"public_key"`
+	}
+	if err := c.doJSON(ctx, http.MethodGet, "/api/v1/ssh-ca", token, nil, &result); err != nil {
+		return "", err
+	}
+	return result.PublicKey, nil
+}
+
+// SignSSHCertificate has the wonder net's SSH CA sign publicKey for logging
+// in to its nodes as one of principals. A zero ttl uses the coordinator's
+// default of 1 hour; it may be up to 24 hours. It requires a member's
+// session token or an API key with the nodes:write scope.
+func (c *Client) SignSSHCertificate(ctx context.Context, token string, publicKey ssh.PublicKey, principals []string, ttl time.Duration) (*SSHCertificate, error) {
+	body := struct {
+		PublicKey  string   `json:"public_key"`
+		Principals []string `json:"principals"`
+		TTL        string   `json:"ttl,omitempty"`
+	}{
+		PublicKey:  strings.TrimSpace(string(ssh.MarshalAuthorizedKey(publicKey))),
+		Principals: principals,
+	}
+	if ttl > 0 {
+		body.TTL = ttl.String()
+	}
+	var result SSHCertificate
+	if err := c.doJSON(ctx, http.MethodPost, "/api/v1/ssh-cert", token, body, &result); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// NewSSHCertSigner generates a key pair held only in memory and returns it
+// with a certificate for user signed by the wonder net's SSH CA, for
+// MeshExecutorConfig.Auth as ssh.PublicKeys. The signer stops working when
+// the certificate expires after ttl.
+func (c *Client) NewSSHCertSigner(ctx context.Context, token, user string, ttl time.Duration) (ssh.Signer, error) {
+	_, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("generate ssh key: %w", err)
+	}
+	signer, err := ssh.NewSignerFromKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("create ssh signer: %w", err)
+	}
+
+	signed, err := c.SignSSHCertificate(ctx, token, signer.PublicKey(), []string{user}, ttl)
+	if err != nil {
+		return nil, err
+	}
+	pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(signed.Certificate))
+	if err != nil {
+		return nil, fmt.Errorf("parse ssh certificate: %w", err)
+	}
+	cert, ok := pub.(*ssh.Certificate)
+	if !ok {
+		return nil, fmt.Errorf("coordinator returned a %s key instead of a certificate", pub.Type())
+	}
+	certSigner, err := ssh.NewCertSigner(cert, signer)
+	if err != nil {
+		return nil, fmt.Errorf("use ssh certificate: %w", err)
+	}
+	return certSigner, nil
+}
@@ -0,0 +1,73 @@
+package wondersdk
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+)
+
+func TestNewSSHCertSigner(t *testing.T) {
+	_, caKey, _ := ed25519.GenerateKey(rand.Reader)
+	ca, err := ssh.NewSignerFromKey(caKey)
+	if err != nil {
+		t.Fatalf("CA signer: %v", err)
+	}
+
+	var gotTTL string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/coordinator/api/v1/ssh-cert" {
+			http.NotFound(w, r)
+			return
+		}
+		var req struct {
+			PublicKey  string   `json:"public_key"`
+			Principals []string `json:"principals"`
+			TTL        string   `json:"ttl"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		gotTTL = req.TTL
+		pub, _, _, _, err := ssh.ParseAuthorizedKey([]byte(req.PublicKey))
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		cert := &ssh.Certificate{
+			Key:             pub,
+			CertType:        ssh.UserCert,
+			KeyId:           "test",
+			ValidPrincipals: req.Principals,
+			ValidBefore:     ssh.CertTimeInfinity,
+		}
+		_ = cert.SignCert(rand.Reader, ca)
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(SSHCertificate{Certificate: string(ssh.MarshalAuthorizedKey(cert))})
+	}))
+	defer server.Close()
+
+	client := NewClient(server.URL+"/coordinator", "wmn_test")
+	signer, err := client.NewSSHCertSigner(context.Background(), "", "root", 30*time.Minute)
+	if err != nil {
+		t.Fatalf("NewSSHCertSigner() error = %v", err)
+	}
+	if gotTTL != "30m0s" {
+		t.Errorf("requested ttl = %q, want 30m0s", gotTTL)
+	}
+
+	cert, ok := signer.PublicKey().(*ssh.Certificate)
+	if !ok {
+		t.Fatalf("signer key is a %T, want a certificate", signer.PublicKey())
+	}
+	checker := &ssh.CertChecker{IsUserAuthority: func(auth ssh.PublicKey) bool {
+		return string(auth.Marshal()) == string(ca.PublicKey().Marshal())
+	}}
+	if err := checker.CheckCert("root", cert); err != nil {
+		t.Errorf("CheckCert(root) error = %v", err)
+	}
+}
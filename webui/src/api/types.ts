@@ -24,6 +24,15 @@ export interface ApiKeyInfo {
   created_at: string
   last_used_at?: string
   expires_at?: string
+  request_count?: number
+  endpoints?: ApiKeyEndpointUsage[]
+  stale?: boolean
+}
+
+export interface ApiKeyEndpointUsage {
+  endpoint: string
+  request_count: number
+  last_used_at: string
 }
 
 
@@ -51,6 +51,7 @@ export default function ApiKeyTable({ apiKeys, onDelete }: ApiKeyTableProps) {
           <th>Key Prefix</th>
           <th>Created</th>
           <th>Last Used</th>
+          <th>Requests</th>
           <th>Expires</th>
           <th style={{ width: '120px' }}>Actions</th>
         </tr>
@@ -65,7 +66,20 @@ export default function ApiKeyTable({ apiKeys, onDelete }: ApiKeyTableProps) {
               </code>
             </td>
             <td>{formatDate(key.created_at)}</td>
-            <td>{formatDate(key.last_used_at)}</td>
+            <td>
+              {formatDate(key.last_used_at)}
+              {key.stale && (
+                <span
+                  title="Unused for 90 days; consider revoking"
+                  style={{ marginLeft: '0.5rem', color: '#b45309', fontSize: '0.75rem' }}
+                >
+                  stale
+                </span>
+              )}
+            </td>
+            <td title={key.endpoints?.map((e) => `${e.endpoint}: ${e.request_count}`).join('\n')}>
+              {key.request_count ?? 0}
+            </td>
             <td>{key.expires_at ? formatDate(key.expires_at) : 'Never'}</td>
             <td>
               {confirmId === key.id ? (